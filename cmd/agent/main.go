@@ -0,0 +1,110 @@
+// Command agent tails log files on a host and ships their lines to a log
+// analyzer server, resuming from its saved cursor after a restart
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/agent"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/watcher"
+)
+
+func main() {
+	configFile := flag.String("config", "agent.yaml", "Path to configuration file")
+	flag.Parse()
+
+	cfg, err := config.LoadAgent(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	labels := make(map[string]string, len(cfg.Labels)+1)
+	for name, value := range cfg.Labels {
+		labels[name] = value
+	}
+	if labels["host"] == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			labels["host"] = hostname
+		}
+	}
+
+	shipper, err := agent.NewShipper(cfg.Server, agent.Options{
+		Labels:        labels,
+		BatchSize:     cfg.BatchSize,
+		MaxBatchBytes: cfg.MaxBatchSize << 10,
+		Timeout:       time.Duration(cfg.Timeout) * time.Second,
+		MinBackoff:    time.Duration(cfg.MinBackoff) * time.Second,
+		MaxBackoff:    time.Duration(cfg.MaxBackoff) * time.Second,
+		OnRetry: func(err error, wait time.Duration) {
+			logger.Warnf("Failed to ship logs, retrying in %s: %v", wait.Round(time.Millisecond), err)
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create shipper: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Lines are read again after a failure, so the cursor only moves past
+	// lines the server accepted or refused for good
+	sink := func(r io.Reader, logType string) error {
+		err := shipper.Ship(ctx, r, logType)
+		var permanent *agent.PermanentError
+		if errors.As(err, &permanent) {
+			logger.Errorf("Dropping %s lines the server refused: %v", logType, err)
+			return nil
+		}
+		return err
+	}
+
+	sources := make([]watcher.Source, 0, len(cfg.Watch))
+	for _, watch := range cfg.Watch {
+		sources = append(sources, watcher.Source{Dir: watch.Path, Pattern: watch.Pattern, LogType: watch.LogType})
+	}
+	w, err := watcher.New(sources, sink, watcher.Options{
+		OffsetsFile:   cfg.OffsetsFile,
+		PollInterval:  time.Duration(cfg.PollInterval) * time.Second,
+		FromBeginning: cfg.FromBeginning,
+		Retry:         true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to watch log directories: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx, func(err error) {
+			if ctx.Err() == nil {
+				logger.Errorf("Log collection error: %v", err)
+			}
+		})
+	}()
+	for _, source := range sources {
+		logger.Infof("Shipping %s logs from %s to %s", source.LogType, source.Dir, cfg.Server)
+	}
+
+	// Wait for interrupt signal, then save the cursor
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down agent...")
+	cancel()
+	<-done
+	logger.Info("Agent stopped")
+}
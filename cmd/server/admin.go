@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// getEffectiveConfigHandler shows the configuration after profiles,
+// environment variables and defaults are merged, with secrets masked
+func (s *Server) getEffectiveConfigHandler(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"env":     s.config.Env,
+		"sources": s.config.Sources,
+		"config":  s.config.Effective(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
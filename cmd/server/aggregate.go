@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+const (
+	// defaultAggregateRows and maxAggregateRows bound the rows of an
+	// aggregate
+	defaultAggregateRows = 1000
+	maxAggregateRows     = 10000
+)
+
+// parseAggregateQuery reads an aggregate from query parameters: the
+// filters of /api/v1/logs, with start and end for start_time and
+// end_time, and group_by, metric, interval and limit
+func parseAggregateQuery(query url.Values) (*models.AggregateQuery, error) {
+	aggregate := &models.AggregateQuery{Filter: *logFilterFromQuery(query), Limit: defaultAggregateRows}
+	if err := parseRange(query, &aggregate.Filter); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if aggregate.GroupBy, err = listParam(query.Get("group_by"), models.AggregateGroups, "group_by field"); err != nil {
+		return nil, err
+	}
+	if aggregate.Metrics, err = listParam(query.Get("metric"), models.AggregateMetrics, "metric"); err != nil {
+		return nil, err
+	}
+	if len(aggregate.Metrics) == 0 {
+		aggregate.Metrics = []string{"count"}
+	}
+
+	if interval := query.Get("interval"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < time.Second || d%time.Second != 0 {
+			return nil, fmt.Errorf("invalid interval %q: must be a whole number of seconds, such as 30s, 5m or 1h", interval)
+		}
+		aggregate.Interval = d
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxAggregateRows {
+			return nil, fmt.Errorf("invalid limit: must be from 1 to %d", maxAggregateRows)
+		}
+		aggregate.Limit = n
+	}
+	return aggregate, nil
+}
+
+// parseRange bounds a filter by the start and end parameters, which stand
+// for start_time and end_time
+func parseRange(query url.Values, filter *models.LogFilter) error {
+	for param, bound := range map[string]**time.Time{"start": &filter.StartTime, "end": &filter.EndTime} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: must be an RFC 3339 time", param)
+			}
+			*bound = &t
+		}
+	}
+	return nil
+}
+
+// listParam splits a comma-separated parameter whose values must each be
+// one of allowed, dropping repeats
+func listParam(param string, allowed []string, name string) ([]string, error) {
+	values := []string{}
+	for _, value := range strings.Split(param, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" || slices.Contains(values, value) {
+			continue
+		}
+		if !slices.Contains(allowed, value) {
+			return nil, fmt.Errorf("invalid %s %q. Must be one of: %s", name, value, strings.Join(allowed, ", "))
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// aggregateLogsHandler computes metrics over groups of entries in the
+// database, so dashboards need not fetch the entries to break them down
+func (s *Server) aggregateLogsHandler(w http.ResponseWriter, r *http.Request) {
+	aggregate, err := parseAggregateQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := s.db.AggregateLogs(r.Context(), aggregate)
+	if err != nil {
+		s.logger.Errorf("Failed to aggregate logs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if rows == nil {
+		rows = []models.AggregateRow{}
+	}
+
+	response := map[string]interface{}{
+		"rows":     rows,
+		"count":    len(rows),
+		"group_by": aggregate.GroupBy,
+		"metrics":  aggregate.Metrics,
+	}
+	if aggregate.Interval > 0 {
+		response["interval"] = aggregate.Interval.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
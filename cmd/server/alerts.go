@@ -0,0 +1,664 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/notify"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/versioning"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// setupAlerting starts the streaming evaluator and keeps its rules in sync
+// with the alert_rules table
+func (s *Server) setupAlerting() error {
+	s.alerts = alerting.NewStreamEvaluator(s.handleAlert)
+
+	if err := s.reloadAlertRules(); err != nil {
+		s.logger.Errorf("Failed to load alert rules: %v", err)
+	}
+
+	// Learn the message patterns already stored so new_pattern rules don't
+	// report every pattern again after a restart
+	s.seedAlertPatterns()
+	s.alerts.SetPatternLearningPeriod(time.Duration(s.config.Alerting.PatternLearningPeriod) * time.Second)
+
+	// Pick up rules edited directly in the database
+	if _, err := s.cron.AddFunc("@every 1m", func() {
+		if err := s.reloadAlertRules(); err != nil {
+			s.logger.Errorf("Failed to reload alert rules: %v", err)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid alert rule reload schedule: %w", err)
+	}
+
+	// Store evaluations for tuning rules, pruned past their retention
+	history := s.config.Alerting.EvaluationHistory
+	sampler := newEvaluationSampler(time.Duration(history.Interval) * time.Second)
+	s.alerts.OnEvaluate(func(evaluations []models.RuleEvaluation) {
+		if stored := sampler.sample(evaluations); len(stored) > 0 {
+			if err := s.db.InsertRuleEvaluations(s.ctx, stored); err != nil {
+				s.logger.Errorf("Failed to store rule evaluations: %v", err)
+			}
+		}
+	})
+	if _, err := s.cron.AddFunc("@every 1h", func() {
+		cutoff := time.Now().AddDate(0, 0, -history.Retention)
+		if _, err := s.db.DeleteRuleEvaluations(s.ctx, cutoff); err != nil {
+			s.logger.Errorf("Failed to delete old rule evaluations: %v", err)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid rule evaluation cleanup schedule: %w", err)
+	}
+
+	interval := time.Duration(s.config.Alerting.EvaluationInterval) * time.Second
+	go s.alerts.Run(s.ctx, interval)
+
+	escalation := s.config.Alerting.Escalation
+	if escalation.RepeatInterval > 0 {
+		policy := alerting.EscalationPolicy{
+			RepeatInterval: time.Duration(escalation.RepeatInterval) * time.Second,
+			EscalateAfter:  escalation.EscalateAfter,
+		}
+		resolved := func(ruleID int64) bool {
+			return s.alerts.State(ruleID) != models.RuleStateFiring
+		}
+		s.escalator = alerting.NewEscalator(policy, resolved, s.remindAlert)
+		go s.escalator.Run(s.ctx, interval)
+	}
+	return nil
+}
+
+// evaluationSampler picks the rule evaluations to store: one per rule
+// every interval, and each that changes a rule's state
+type evaluationSampler struct {
+	interval time.Duration
+	stored   map[int64]models.RuleEvaluation
+}
+
+func newEvaluationSampler(interval time.Duration) *evaluationSampler {
+	return &evaluationSampler{interval: interval, stored: make(map[int64]models.RuleEvaluation)}
+}
+
+// sample returns the evaluations to store. Evaluations come from one
+// goroutine, so the sampler needs no lock.
+func (e *evaluationSampler) sample(evaluations []models.RuleEvaluation) []models.RuleEvaluation {
+	var stored []models.RuleEvaluation
+	for _, evaluation := range evaluations {
+		last, ok := e.stored[evaluation.RuleID]
+		if ok && last.State == evaluation.State && evaluation.EvaluatedAt.Sub(last.EvaluatedAt) < e.interval {
+			continue
+		}
+		e.stored[evaluation.RuleID] = evaluation
+		stored = append(stored, evaluation)
+	}
+	return stored
+}
+
+func (s *Server) reloadAlertRules() error {
+	rules, err := s.db.GetAlertRules(true)
+	if err != nil {
+		return err
+	}
+	s.alerts.SetRules(rules)
+
+	budgets, err := s.db.GetLatencyBudgets()
+	if err != nil {
+		return err
+	}
+	s.alerts.SetLatencyBudgets(budgets)
+	return nil
+}
+
+// seedAlertPatterns feeds the last day of application log messages to the
+// evaluator's pattern miner
+func (s *Server) seedAlertPatterns() {
+	now := time.Now()
+	entries, err := s.db.GetLogMessages(logprocessor.MessageLogTypes, now.Add(-24*time.Hour), now, maxPatternMessages)
+	if err != nil {
+		s.logger.Errorf("Failed to load log messages for pattern alerts: %v", err)
+		return
+	}
+
+	// Oldest first, as they were ingested
+	slices.Reverse(entries)
+	s.alerts.SeedPatterns(entries)
+}
+
+// handleAlert persists and logs a fired alert
+func (s *Server) handleAlert(event *models.AlertEvent) {
+	s.logger.WithFields(logrus.Fields{
+		"rule_id":   event.RuleID,
+		"severity":  event.Severity,
+		"value":     event.Value,
+		"threshold": event.Threshold,
+	}).Warn(event.Message)
+
+	stored := true
+	if err := s.db.InsertAlertEvent(event); err != nil {
+		s.logger.Errorf("Failed to record alert: %v", err)
+		stored = false
+	} else {
+		s.recordAudit(audit.ActionAlertFired, auditActorSystem, fmt.Sprintf("alert:%d", event.ID), map[string]interface{}{
+			"rule_id":   event.RuleID,
+			"severity":  event.Severity,
+			"message":   event.Message,
+			"value":     event.Value,
+			"threshold": event.Threshold,
+		})
+	}
+
+	// Planned work is recorded but nobody is paged for it
+	if window := s.activeSilence(); window != nil {
+		s.logger.WithFields(logrus.Fields{
+			"alert_id":           event.ID,
+			"maintenance_window": window.Name,
+		}).Info("Alert silenced by maintenance window")
+		return
+	}
+
+	if stored && s.escalator != nil {
+		// Only stored alerts can be acknowledged, so only they are repeated
+		s.escalator.Track(event)
+	}
+
+	if len(s.notifier.Channels()) > 0 {
+		go s.notifyAlert(alerting.ActiveAlert{Event: event})
+	}
+}
+
+// remindAlert re-sends an alert that is still firing unacknowledged
+func (s *Server) remindAlert(alert alerting.ActiveAlert) {
+	s.logger.WithFields(logrus.Fields{
+		"alert_id":  alert.Event.ID,
+		"rule_id":   alert.Event.RuleID,
+		"repeats":   alert.Repeats,
+		"escalated": alert.Escalated,
+	}).Warn("Alert still unacknowledged")
+
+	go s.notifyAlert(alert)
+}
+
+// notifyAlert delivers a fired alert to the configured notification channels
+func (s *Server) notifyAlert(alert alerting.ActiveAlert) {
+	event := alert.Event
+	var rule *models.AlertRule
+	for _, r := range s.alerts.Rules() {
+		if r.ID == event.RuleID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		s.logger.Warnf("Alert rule %d no longer loaded, skipping notification", event.RuleID)
+		return
+	}
+
+	data := s.alertContext(rule, event)
+	data.Repeat = alert.Repeats
+	data.Escalated = alert.Escalated
+	if err := s.notifier.Notify(data); err != nil {
+		s.logger.Errorf("Failed to send alert notification: %v", err)
+	}
+}
+
+// alertContext gathers the template data for an alert: the rule, the paths
+// and IPs behind it over the rule's window, and links back into the API
+func (s *Server) alertContext(rule *models.AlertRule, event *models.AlertEvent) *notify.AlertContext {
+	window := time.Duration(rule.TimeWindow) * time.Second
+	data := &notify.AlertContext{
+		Rule:  rule,
+		Event: event,
+		Links: notify.NewLinks(s.config.Server.PublicURL, event, window),
+	}
+
+	since := event.TriggeredAt.Add(-window)
+	paths, ips, err := s.db.GetTopOffenders(since, alerting.OffenderStatusFloor(rule.ConditionType), 5)
+	if err != nil {
+		s.logger.Errorf("Failed to get top offenders for alert: %v", err)
+		return data
+	}
+	data.TopPaths = paths
+	data.TopIPs = ips
+	return data
+}
+
+func (s *Server) listAlertRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.db.GetAlertRules(false)
+	if err != nil {
+		s.logger.Errorf("Failed to get alert rules: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"rules": rules,
+		"count": len(rules),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) createAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	rule := models.AlertRule{IsActive: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := alerting.ValidateRule(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.CreateAlertRule(&rule); err != nil {
+		s.logger.Errorf("Failed to create alert rule: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionAlertRuleCreated, requestActor(r), fmt.Sprintf("alert_rule:%d", rule.ID), map[string]interface{}{
+		"name":            rule.Name,
+		"condition_type":  rule.ConditionType,
+		"threshold_value": rule.ThresholdValue,
+		"time_window":     rule.TimeWindow,
+	})
+	s.recordConfigVersion(kindAlertRule, strconv.FormatInt(rule.ID, 10), versioning.ActionCreated, requestActor(r), nil, alertRuleState(&rule))
+
+	if s.alerts != nil {
+		if err := s.reloadAlertRules(); err != nil {
+			s.logger.Errorf("Failed to reload alert rules: %v", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// updateAlertRuleHandler replaces a rule, keeping its previous version so
+// the change can be rolled back
+func (s *Server) updateAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	rule := models.AlertRule{IsActive: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.ID = id
+
+	if err := alerting.ValidateRule(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous, err := s.findAlertRule(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+	if err == nil {
+		err = s.db.UpdateAlertRule(&rule)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to update alert rule: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	actor := requestActor(r)
+	version := s.recordConfigVersion(kindAlertRule, strconv.FormatInt(id, 10), versioning.ActionUpdated, actor,
+		alertRuleState(previous), alertRuleState(&rule))
+	details := map[string]interface{}{
+		"name":            rule.Name,
+		"condition_type":  rule.ConditionType,
+		"threshold_value": rule.ThresholdValue,
+		"time_window":     rule.TimeWindow,
+	}
+	if version != nil {
+		details["version"] = version.Version
+	}
+	s.recordAudit(audit.ActionAlertRuleUpdated, actor, fmt.Sprintf("alert_rule:%d", id), details)
+
+	if s.alerts != nil {
+		if err := s.reloadAlertRules(); err != nil {
+			s.logger.Errorf("Failed to reload alert rules: %v", err)
+		}
+	}
+
+	// Read it back for the timestamps the backend set
+	if updated, err := s.findAlertRule(id); err == nil {
+		rule = *updated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// findAlertRule returns the stored rule with the ID, active or not
+func (s *Server) findAlertRule(id int64) (*models.AlertRule, error) {
+	rules, err := s.db.GetAlertRules(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// maxRuleEvaluations bounds the limit of the rule evaluations endpoint
+const maxRuleEvaluations = 10000
+
+// getRuleEvaluationsHandler returns a rule's stored evaluations since
+// start_time, the last limit of them oldest first
+func (s *Server) getRuleEvaluationsHandler(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if s.alerts == nil {
+		http.Error(w, "Alerting is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := alerting.HistorySize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxRuleEvaluations)
+	}
+	since := time.Now().AddDate(0, 0, -s.config.Alerting.EvaluationHistory.Retention)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		since = t
+	}
+
+	evaluations, err := s.db.GetRuleEvaluations(r.Context(), ruleID, since, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to get rule evaluations: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	response := map[string]interface{}{
+		"rule_id":     ruleID,
+		"state":       s.alerts.State(ruleID),
+		"evaluations": evaluations,
+		"count":       len(evaluations),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getAlertHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	events, err := s.db.GetAlertHistory(limit)
+	if err != nil {
+		s.logger.Errorf("Failed to get alert history: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"alerts": events,
+		"count":  len(events),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// acknowledgeAlert records the acknowledgment and stops further reminders
+func (s *Server) acknowledgeAlert(id int64, by string) error {
+	if err := s.db.AcknowledgeAlertEvent(id, by, time.Now()); err != nil {
+		return err
+	}
+	s.recordAudit(audit.ActionAlertAcknowledged, by, fmt.Sprintf("alert:%d", id), nil)
+	if s.escalator != nil {
+		s.escalator.Acknowledge(id)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"alert_id": id,
+		"by":       by,
+	}).Info("Alert acknowledged")
+	return nil
+}
+
+func (s *Server) acknowledgeAlertHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid alert ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		By string `json:"by"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.By == "" {
+		req.By = "api"
+	}
+
+	if err := s.acknowledgeAlert(id, req.By); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Alert not found or already acknowledged", http.StatusNotFound)
+			return
+		}
+		s.logger.Errorf("Failed to acknowledge alert: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":              id,
+		"acknowledged_by": req.By,
+		"message":         "Alert acknowledged",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getActiveAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	alerts := []alerting.ActiveAlert{}
+	if s.escalator != nil {
+		alerts = s.escalator.Active()
+	}
+
+	response := map[string]interface{}{
+		"alerts": alerts,
+		"count":  len(alerts),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// slackActionsHandler receives Acknowledge button clicks from Slack messages.
+// The Slack app's interactivity request URL must point here.
+func (s *Server) slackActionsHandler(w http.ResponseWriter, r *http.Request) {
+	secret := s.config.Alerting.SlackSigningSecret
+	if secret == "" {
+		http.Error(w, "Slack actions are not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := notify.VerifySlackSignature(secret, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	action, err := notify.ParseSlackAcknowledge(form.Get("payload"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects a 200 even when the alert was already acknowledged
+	if err := s.acknowledgeAlert(action.EventID, action.User); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.logger.Errorf("Failed to acknowledge alert: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) listNotificationChannelsHandler(w http.ResponseWriter, r *http.Request) {
+	channels := s.notifier.Channels()
+	configs := make([]config.NotificationChannel, 0, len(channels))
+	for _, ch := range channels {
+		configs = append(configs, ch.NotificationChannel)
+	}
+
+	response := map[string]interface{}{
+		"channels": configs,
+		"count":    len(configs),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// testRenderChannelHandler renders a channel's template without sending it.
+// The request may name a rule to render against and a template to try in
+// place of the configured one.
+func (s *Server) testRenderChannelHandler(w http.ResponseWriter, r *http.Request) {
+	channel, ok := s.notifier.Channel(mux.Vars(r)["name"])
+	if !ok {
+		http.Error(w, "Notification channel not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		RuleID   int64  `json:"rule_id"`
+		Template string `json:"template"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Template != "" {
+		cfg := channel.NotificationChannel
+		cfg.Template = req.Template
+		override, err := notify.NewChannel(cfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		channel = override
+	}
+
+	rule := &models.AlertRule{
+		Name:           "Sample error rate rule",
+		ConditionType:  alerting.ConditionErrorRate,
+		ThresholdValue: 5,
+		TimeWindow:     300,
+	}
+	if req.RuleID != 0 {
+		rules, err := s.db.GetAlertRules(false)
+		if err != nil {
+			s.logger.Errorf("Failed to get alert rules: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		rule = nil
+		for _, candidate := range rules {
+			if candidate.ID == req.RuleID {
+				rule = candidate
+				break
+			}
+		}
+		if rule == nil {
+			http.Error(w, "Alert rule not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	payload, err := channel.Render(s.alertContext(rule, sampleAlertEvent(rule)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{
+		"channel": channel.Name,
+		"type":    channel.Type,
+		"body":    string(payload),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// sampleAlertEvent builds an event for a rule breaching by half again its
+// threshold, used to preview notification templates
+func sampleAlertEvent(rule *models.AlertRule) *models.AlertEvent {
+	value := rule.ThresholdValue * 1.5
+	return &models.AlertEvent{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Message:     fmt.Sprintf("%s: %s is %.2f over the last %ds (threshold %.2f)", rule.Name, rule.ConditionType, value, rule.TimeWindow, rule.ThresholdValue),
+		Severity:    "warning",
+		Value:       value,
+		Threshold:   rule.ThresholdValue,
+		TriggeredAt: time.Now(),
+		Window: map[string]float64{
+			alerting.ConditionRequestCount:     1200,
+			alerting.ConditionErrorCount:       150,
+			alerting.ConditionServerErrorCount: 90,
+			alerting.ConditionErrorRate:        12.5,
+			alerting.ConditionAvgResponseTime:  0.42,
+			alerting.ConditionRequestsPerMin:   240,
+		},
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/auth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/cache"
+)
+
+// Cookies of signed-in users and of sign-ins in progress
+const (
+	sessionCookie = "log_analyzer_session"
+	loginCookie   = "log_analyzer_login"
+)
+
+// identityKey keys the signed-in user in a request's context
+type identityKey struct{}
+
+// setupAuth signs users in with the configured OpenID Connect provider
+func (s *Server) setupAuth() error {
+	cfg := s.config.Auth.OIDC
+	authenticator, err := auth.New(auth.Config{
+		Issuer:        cfg.Issuer,
+		ClientID:      cfg.ClientID,
+		ClientSecret:  cfg.ClientSecret,
+		RedirectURL:   cfg.RedirectURL,
+		Scopes:        cfg.Scopes,
+		Audiences:     cfg.Audiences,
+		SessionSecret: []byte(cfg.SessionSecret),
+		SessionTTL:    time.Duration(cfg.SessionTTL) * time.Minute,
+		Denylist:      sessionDenylist{s.cache},
+	})
+	if err != nil {
+		return err
+	}
+	s.auth = authenticator
+	return nil
+}
+
+// sessionDenylist keeps signed out sessions in the cache until they
+// expire, so with Redis every replica refuses them
+type sessionDenylist struct {
+	cache cache.Cache
+}
+
+func (d sessionDenylist) Revoke(ctx context.Context, id string, expires time.Time) error {
+	return d.cache.Set(ctx, cache.Key("revoked-session", id), []byte{1}, time.Until(expires))
+}
+
+func (d sessionDenylist) Revoked(ctx context.Context, id string) (bool, error) {
+	_, ok, err := d.cache.Get(ctx, cache.Key("revoked-session", id))
+	return ok, err
+}
+
+// requestIdentity is the user who made a request; nil without sign-in
+func requestIdentity(r *http.Request) *auth.Identity {
+	identity, _ := r.Context().Value(identityKey{}).(*auth.Identity)
+	return identity
+}
+
+// authMiddleware requires a session cookie or a bearer token from the
+// provider, except on public paths. Browsers are sent to sign in; other
+// clients get a 401.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil || s.isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := auth.BearerToken(r)
+		bearer := token != ""
+		if !bearer {
+			if cookie, err := r.Cookie(sessionCookie); err == nil {
+				token = cookie.Value
+			}
+		}
+		if token != "" {
+			identity, err := s.auth.Authenticate(r.Context(), token, time.Now())
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+				return
+			}
+			if !auth.IsInvalidToken(err) {
+				s.logger.Errorf("Failed to verify token: %v", err)
+				http.Error(w, "Identity provider unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		if !bearer && r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(w, r, "/auth/login?redirect="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="log-analyzer"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// isPublicPath reports whether a path is served without signing in: the
+// sign-in routes and the configured public paths and those below them
+func (s *Server) isPublicPath(path string) bool {
+	if strings.HasPrefix(path, "/auth/") {
+		return true
+	}
+	for _, public := range s.config.Auth.OIDC.PublicPaths {
+		if underPath(path, public) {
+			return true
+		}
+	}
+	return false
+}
+
+// secureCookies reports whether cookies are only sent over HTTPS, which
+// they are when the server is reached over HTTPS
+func (s *Server) secureCookies() bool {
+	return strings.HasPrefix(s.config.Auth.OIDC.RedirectURL, "https://")
+}
+
+// loginHandler sends the user to the provider to sign in. redirect is
+// where they return afterwards, a path on this server.
+func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	// Only paths on this server, so sign-in cannot be used to send users
+	// elsewhere
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		redirect = "/"
+	}
+
+	target, login, err := s.auth.Login(r.Context(), redirect, time.Now())
+	if err != nil {
+		s.logger.Errorf("Failed to start sign-in: %v", err)
+		http.Error(w, "Identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     loginCookie,
+		Value:    login,
+		Path:     "/auth/",
+		MaxAge:   int(auth.LoginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   s.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// callbackHandler completes a sign-in when the provider sends the user
+// back, starting their session
+func (s *Server) callbackHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		if description := query.Get("error_description"); description != "" {
+			reason += ": " + description
+		}
+		http.Error(w, "Sign-in failed: "+reason, http.StatusUnauthorized)
+		return
+	}
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil {
+		http.Error(w, "Sign-in expired or was not started here", http.StatusBadRequest)
+		return
+	}
+
+	identity, session, redirect, err := s.auth.Callback(r.Context(), query.Get("code"), query.Get("state"), cookie.Value, time.Now())
+	if err != nil {
+		s.logger.Warnf("Failed sign-in from %s: %v", requestActor(r), err)
+		http.Error(w, "Sign-in failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.recordSignIn(identity, time.Now().UTC()); err != nil {
+		s.logger.Errorf("Failed to record sign-in of %s: %v", identity.Actor(), err)
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: loginCookie, Path: "/auth/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session,
+		Path:     "/",
+		Expires:  identity.Expires,
+		HttpOnly: true,
+		Secure:   s.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	s.recordAudit(audit.ActionSignedIn, identity.Actor(), "user:"+identity.Subject, map[string]interface{}{
+		"name":       identity.Name,
+		"expires_at": identity.Expires,
+	})
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// logoutHandler ends the user's session, revoking its token so a copy of
+// the cookie no longer works, and their session with the provider when it
+// has an end-session endpoint
+func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		identity, err := s.auth.Revoke(r.Context(), cookie.Value, time.Now())
+		if err == nil {
+			s.recordAudit(audit.ActionSignedOut, identity.Actor(), "user:"+identity.Subject, nil)
+		} else if !auth.IsInvalidToken(err) {
+			s.logger.Errorf("Failed to revoke session: %v", err)
+			http.Error(w, "Failed to sign out", http.StatusInternalServerError)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if target := s.auth.LogoutURL(r.Context()); target != "" {
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("Signed out\n"))
+}
+
+// currentUserHandler returns the signed-in user and their role
+func (s *Server) currentUserHandler(w http.ResponseWriter, r *http.Request) {
+	identity := requestIdentity(r)
+	if identity == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(identity)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/backup"
+)
+
+// liftDeadlines lets long transfers, such as backups, report downloads and
+// upload chunks, run past the server's read and write timeouts, which are
+// meant for ordinary requests
+func (s *Server) liftDeadlines(w http.ResponseWriter) {
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		s.logger.Warnf("Failed to lift the read deadline: %v", err)
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		s.logger.Warnf("Failed to lift the write deadline: %v", err)
+	}
+}
+
+// backupHandler streams a gzip-compressed NDJSON backup of the log entries
+// and configuration. Headers are sent before the entries are read, so a
+// failure part way cuts the backup short; restoring it then fails as the
+// backup is incomplete.
+func (s *Server) backupHandler(w http.ResponseWriter, r *http.Request) {
+	s.liftDeadlines(w)
+
+	filename := fmt.Sprintf("backup_%s.%s", time.Now().UTC().Format("20060102_150405"), backup.Format)
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	counts, err := backup.Dump(r.Context(), s.db, w, s.config.Database.Type)
+	if err != nil {
+		s.logger.Errorf("Backup failed after %d log entries: %v", counts[backup.TableLogEntries], err)
+		return
+	}
+
+	s.recordAudit(audit.ActionBackupCreated, requestActor(r), "database", countDetails(counts))
+	s.logger.Infof("Backed up %d log entries", counts[backup.TableLogEntries])
+}
+
+// restoreHandler restores a backup posted as the request body into an
+// empty database. Restored entries bypass the pipeline, so they neither
+// fire alerts nor are forwarded.
+func (s *Server) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	s.liftDeadlines(w)
+
+	counts, err := backup.Restore(r.Context(), s.db, r.Body)
+	if errors.Is(err, backup.ErrNotEmpty) {
+		http.Error(w, fmt.Sprintf("Cannot restore: %v", err), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Restore failed after %d log entries: %v", counts[backup.TableLogEntries], err)
+	}
+	// Whatever was restored is put to use, even if the rest failed
+	if counts[backup.TableAlertRules] > 0 || counts[backup.TableLatencyBudgets] > 0 {
+		if s.alerts != nil {
+			if err := s.reloadAlertRules(); err != nil {
+				s.logger.Errorf("Failed to reload alert rules: %v", err)
+			}
+		}
+	}
+	if counts[backup.TableFeatureOverrides] > 0 {
+		overrides, err := s.db.GetFeatureOverrides()
+		if err != nil {
+			s.logger.Errorf("Failed to reload feature overrides: %v", err)
+		} else {
+			s.features.SetOverrides(overrides)
+		}
+	}
+	if counts[backup.TableLogEntries] > 0 && s.config.Rollups.Enabled {
+		s.backfillRollups()
+	}
+
+	if err == nil || countRows(counts) > 0 {
+		details := countDetails(counts)
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		s.recordAudit(audit.ActionBackupRestored, requestActor(r), "database", details)
+	}
+
+	if err != nil {
+		status, message := http.StatusInternalServerError, "Internal server error"
+		if errors.Is(err, backup.ErrInvalid) {
+			status, message = http.StatusBadRequest, fmt.Sprintf("Cannot restore: %v", err)
+		}
+		if restored := countRows(counts); restored > 0 {
+			message += fmt.Sprintf("; %d records were restored, so empty the database before restoring again", restored)
+		}
+		http.Error(w, message, status)
+		return
+	}
+
+	s.logger.Infof("Restored %d log entries from a backup", counts[backup.TableLogEntries])
+
+	response := map[string]interface{}{
+		"restored": counts,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// countDetails records the rows of each table in an audit record
+func countDetails(counts backup.Counts) map[string]interface{} {
+	details := make(map[string]interface{}, len(counts))
+	for table, count := range counts {
+		details[table] = count
+	}
+	return details
+}
+
+func countRows(counts backup.Counts) int64 {
+	var rows int64
+	for _, count := range counts {
+		rows += count
+	}
+	return rows
+}
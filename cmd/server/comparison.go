@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+)
+
+// maxComparisonEntries bounds how many entries of each period a
+// comparison report covers
+const maxComparisonEntries = 200000
+
+// comparisonPeriod reads the entries of a period to compare and, when
+// the traffic rollups cover it, its totals
+func (s *Server) comparisonPeriod(ctx context.Context, start, end time.Time, logType string) (*reporting.ComparisonPeriod, error) {
+	filter := &models.LogFilter{StartTime: &start, EndTime: &end, LogType: logType, Limit: maxComparisonEntries}
+	entries, err := s.db.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &reporting.ReportData{}
+	s.attachTraffic(totals, filter)
+	return &reporting.ComparisonPeriod{Start: start, End: end, Entries: entries, Traffic: totals.Traffic}, nil
+}
+
+// comparePeriods compares [start, end) with [previousStart, previousEnd)
+func (s *Server) comparePeriods(ctx context.Context, start, end, previousStart, previousEnd time.Time, logType string, opts reporting.ComparisonOptions) (*reporting.ComparisonSummary, error) {
+	current, err := s.comparisonPeriod(ctx, start, end, logType)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.comparisonPeriod(ctx, previousStart, previousEnd, logType)
+	if err != nil {
+		return nil, err
+	}
+	return reporting.ComparePeriods(current, previous, opts), nil
+}
+
+// comparisonReportRequest is the body of POST /api/v1/reports/comparison
+type comparisonReportRequest struct {
+	ReportName        string     `json:"report_name"`
+	StartTime         *time.Time `json:"start_time"`
+	EndTime           *time.Time `json:"end_time"`
+	PreviousStartTime *time.Time `json:"previous_start_time"`
+	PreviousEndTime   *time.Time `json:"previous_end_time"`
+	LogType           string     `json:"log_type"`
+	ErrorRatePoints   float64    `json:"error_rate_points"`
+	LatencyIncrease   float64    `json:"latency_increase"`
+	TrafficDrop       float64    `json:"traffic_drop"`
+	Format            string     `json:"format"` // html, json
+}
+
+func (s *Server) generateComparisonReportHandler(w http.ResponseWriter, r *http.Request) {
+	var request comparisonReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.ReportName == "" {
+		request.ReportName = "comparison"
+	}
+	if request.Format == "" {
+		request.Format = "html"
+	}
+	if request.Format != "html" && request.Format != "json" {
+		http.Error(w, "Format must be html or json", http.StatusBadRequest)
+		return
+	}
+	if request.ErrorRatePoints < 0 || request.LatencyIncrease < 0 || request.TrafficDrop < 0 {
+		http.Error(w, "error_rate_points, latency_increase and traffic_drop must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	// Default to the last week, compared with the week before it
+	end := time.Now().Truncate(time.Hour)
+	start := end.AddDate(0, 0, -7)
+	if request.StartTime != nil {
+		start = *request.StartTime
+	}
+	if request.EndTime != nil {
+		end = *request.EndTime
+	}
+	if !end.After(start) {
+		http.Error(w, "end_time must be after start_time", http.StatusBadRequest)
+		return
+	}
+	previousEnd := start
+	if request.PreviousEndTime != nil {
+		previousEnd = *request.PreviousEndTime
+	}
+	previousStart := previousEnd.Add(-end.Sub(start))
+	if request.PreviousStartTime != nil {
+		previousStart = *request.PreviousStartTime
+	}
+	if !previousEnd.After(previousStart) {
+		http.Error(w, "previous_end_time must be after previous_start_time", http.StatusBadRequest)
+		return
+	}
+
+	opts := reporting.ComparisonOptions{
+		ErrorRatePoints: request.ErrorRatePoints,
+		LatencyIncrease: request.LatencyIncrease,
+		TrafficDrop:     request.TrafficDrop,
+	}
+	summary, err := s.comparePeriods(r.Context(), start, end, previousStart, previousEnd, request.LogType, opts)
+	if err != nil {
+		s.logger.Errorf("Failed to get entries to compare: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	response := map[string]interface{}{
+		"summary":  summary,
+		"log_type": request.LogType,
+	}
+
+	if request.Format == "html" {
+		reportFile, err := s.reporter.GenerateComparisonReport(&reporting.ComparisonReportData{
+			Title:       request.ReportName,
+			GeneratedAt: time.Now(),
+			Summary:     summary,
+		}, request.ReportName)
+		if err != nil {
+			s.logger.Errorf("Failed to generate comparison report: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response["generated_files"] = []string{reportFile}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledWeeklyComparison(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	weekly := scheduledJob(t, s, weeklyReportSchedule)
+	saturday := time.Date(2024, 1, 6, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 1, 7, 3, 0, 0, 0, time.Local), weekly.Schedule.Next(saturday), "the weekly report runs on Sundays")
+
+	now := time.Now().Truncate(time.Hour)
+	weekStart := now.AddDate(0, 0, -int(now.Weekday())-7)
+	for _, at := range []time.Time{weekStart.Add(time.Hour), weekStart.Add(2 * time.Hour), weekStart.AddDate(0, 0, -3)} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: at, LogType: "nginx", SourceIP: "10.0.0.1", Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+
+	weekly.Job.Run()
+
+	objects, err := s.reporter.Store().List("")
+	require.NoError(t, err)
+	var comparison string
+	for _, object := range objects {
+		if strings.HasPrefix(object.Name, "weekly_comparison_") {
+			comparison = object.Name
+		}
+	}
+	require.NotEmpty(t, comparison, "the weekly run writes a comparison with the week before")
+
+	r, _, err := s.reporter.Store().Open(comparison)
+	require.NoError(t, err)
+	defer r.Close()
+	html, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Weekly Log Analysis Report")
+}
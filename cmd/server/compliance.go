@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/gorilla/mux"
+)
+
+// maxAdminEntries bounds how many administrative requests a pack reviews
+const maxAdminEntries = 500000
+
+// retentionCleanupGrace tolerates entries this many days past retention,
+// since cleanup runs monthly by default
+const retentionCleanupGrace = 31
+
+func (s *Server) complianceLocation() *time.Location {
+	// The timezone is validated when the config is loaded
+	loc, err := time.LoadLocation(s.config.Compliance.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (s *Server) complianceOptions() reporting.ComplianceOptions {
+	cfg := s.config.Compliance
+	opts := reporting.DefaultComplianceOptions()
+	if len(cfg.AdminPaths) > 0 {
+		opts.AdminPaths = cfg.AdminPaths
+	}
+	opts.BusinessStart = cfg.BusinessHoursStart
+	opts.BusinessEnd = cfg.BusinessHoursEnd
+	if days, err := cfg.Weekdays(); err == nil && len(days) > 0 {
+		opts.BusinessDays = days
+	}
+	opts.Location = s.complianceLocation()
+	return opts
+}
+
+// generateCompliancePack reviews a YYYY-MM month and archives the result
+func (s *Server) generateCompliancePack(period, actor string) (string, []string, error) {
+	opts := s.complianceOptions()
+	start, end, err := reporting.MonthPeriod(period, opts.Location)
+	if err != nil {
+		return "", nil, err
+	}
+
+	entries, err := s.db.GetEntriesByPathPrefix(opts.AdminPaths, start, end, maxAdminEntries)
+	if err != nil {
+		return "", nil, err
+	}
+	admin, offHours := reporting.AnalyzeAdminAccess(entries, opts)
+
+	lookback := s.config.Compliance.CountryLookback
+	countries, err := s.db.GetCountryActivity(start.AddDate(0, 0, -lookback), start, end)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		return "", nil, err
+	}
+	// The default cutoff comes last, after those of log types with their own
+	var retention []*reporting.RetentionAttestation
+	for _, cutoff := range policy.Cutoffs(now) {
+		stats, err := s.db.GetRetentionStats(cutoff)
+		if err != nil {
+			return "", nil, err
+		}
+		retention = append(retention, reporting.AttestLogTypeRetention(cutoff.LogType, stats,
+			policy.Days(cutoff.LogType), retentionCleanupGrace, now))
+	}
+
+	data := &reporting.ComplianceReportData{
+		Title:              "Compliance Access Review",
+		GeneratedAt:        now,
+		Period:             period,
+		PeriodStart:        start,
+		PeriodEnd:          end,
+		AdminAccess:        admin,
+		OffHours:           offHours,
+		NewCountries:       reporting.NewCountries(countries, start, lookback),
+		Retention:          retention[len(retention)-1],
+		RetentionByLogType: retention[:len(retention)-1],
+	}
+	dir, files, err := s.reporter.GenerateCompliancePack(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.recordAudit(audit.ActionComplianceGenerated, actor, "compliance:"+period, map[string]interface{}{
+		"directory": dir,
+		"files":     files,
+	})
+	return dir, files, nil
+}
+
+// complianceReportRequest is the body of POST /api/v1/reports/compliance
+type complianceReportRequest struct {
+	Period string `json:"period"` // YYYY-MM, default the previous month
+}
+
+func (s *Server) generateComplianceReportHandler(w http.ResponseWriter, r *http.Request) {
+	var request complianceReportRequest
+	// An empty body generates the previous month
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	loc := s.complianceLocation()
+	if request.Period == "" {
+		now := time.Now().In(loc)
+		request.Period = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, loc).Format("2006-01")
+	}
+	start, _, err := reporting.MonthPeriod(request.Period, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if start.After(time.Now()) {
+		http.Error(w, "Period has not started", http.StatusBadRequest)
+		return
+	}
+
+	dir, files, err := s.generateCompliancePack(request.Period, requestActor(r))
+	if errors.Is(err, os.ErrExist) {
+		http.Error(w, fmt.Sprintf("Compliance pack for %s is already archived", request.Period), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to generate compliance pack: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"period":    request.Period,
+		"directory": dir,
+		"files":     files,
+		"manifest":  reporting.ManifestFile,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) listCompliancePacksHandler(w http.ResponseWriter, r *http.Request) {
+	periods, err := s.reporter.CompliancePacks()
+	if err != nil {
+		s.logger.Errorf("Failed to list compliance packs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"periods": periods,
+		"count":   len(periods),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) verifyCompliancePackHandler(w http.ResponseWriter, r *http.Request) {
+	period := mux.Vars(r)["period"]
+
+	mismatches, err := s.reporter.VerifyCompliancePack(period)
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "Compliance pack not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to verify compliance pack %s: %v", period, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"period":     period,
+		"intact":     len(mismatches) == 0,
+		"mismatches": mismatches,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
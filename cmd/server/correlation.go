@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+)
+
+// maxCorrelationEntries bounds how many entries of each tier a correlation
+// report covers
+const maxCorrelationEntries = 200000
+
+// correlationReportRequest is the body of POST /api/v1/reports/correlation
+type correlationReportRequest struct {
+	ReportName  string     `json:"report_name"`
+	StartTime   *time.Time `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+	WebLogTypes []string   `json:"web_log_types"`
+	AppLogTypes []string   `json:"app_log_types"`
+	Bucket      string     `json:"bucket"`
+	MinErrors   int64      `json:"min_errors"`
+	Factor      float64    `json:"factor"`
+	Slack       string     `json:"slack"`
+	Format      string     `json:"format"` // html, json
+}
+
+func (s *Server) generateCorrelationReportHandler(w http.ResponseWriter, r *http.Request) {
+	var request correlationReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.ReportName == "" {
+		request.ReportName = "correlation"
+	}
+	if request.Format == "" {
+		request.Format = "html"
+	}
+	if request.Format != "html" && request.Format != "json" {
+		http.Error(w, "Format must be html or json", http.StatusBadRequest)
+		return
+	}
+	if len(request.WebLogTypes) == 0 {
+		request.WebLogTypes = reporting.WebLogTypes
+	}
+	if len(request.AppLogTypes) == 0 {
+		request.AppLogTypes = logprocessor.MessageLogTypes
+	}
+
+	opts := reporting.DefaultCorrelationOptions()
+	if request.Bucket != "" {
+		bucket, err := time.ParseDuration(request.Bucket)
+		if err != nil || bucket <= 0 {
+			http.Error(w, "Invalid bucket", http.StatusBadRequest)
+			return
+		}
+		opts.Bucket = bucket
+	}
+	if request.Slack != "" {
+		slack, err := time.ParseDuration(request.Slack)
+		if err != nil || slack < 0 {
+			http.Error(w, "Invalid slack", http.StatusBadRequest)
+			return
+		}
+		opts.Slack = slack
+	}
+	if request.MinErrors < 0 || request.Factor < 0 {
+		http.Error(w, "min_errors and factor must not be negative", http.StatusBadRequest)
+		return
+	}
+	if request.MinErrors > 0 {
+		opts.MinErrors = request.MinErrors
+	}
+	if request.Factor > 0 {
+		opts.Factor = request.Factor
+	}
+
+	// Default to the last day
+	end := time.Now()
+	start := end.AddDate(0, 0, -1)
+	if request.StartTime != nil {
+		start = *request.StartTime
+	}
+	if request.EndTime != nil {
+		end = *request.EndTime
+	}
+	if !end.After(start) {
+		http.Error(w, "end_time must be after start_time", http.StatusBadRequest)
+		return
+	}
+
+	web, err := s.db.GetEntriesByTypeOrStatus(request.WebLogTypes, nil, start, end, maxCorrelationEntries)
+	if err != nil {
+		s.logger.Errorf("Failed to get web tier entries: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	// Application logs around the range's edges can match spikes there
+	app, err := s.db.GetEntriesByTypeOrStatus(request.AppLogTypes, nil, start.Add(-opts.Slack), end.Add(opts.Slack), maxCorrelationEntries)
+	if err != nil {
+		s.logger.Errorf("Failed to get application entries: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	summary := reporting.AnalyzeCorrelation(web, app, start, end, opts)
+	response := map[string]interface{}{
+		"summary":       summary,
+		"count":         len(summary.Spikes),
+		"web_log_types": request.WebLogTypes,
+		"app_log_types": request.AppLogTypes,
+		"start_time":    start,
+		"end_time":      end,
+	}
+
+	if request.Format == "html" {
+		reportFile, err := s.reporter.GenerateCorrelationReport(&reporting.CorrelationReportData{
+			Title:       request.ReportName,
+			GeneratedAt: time.Now(),
+			TimeRange:   fmt.Sprintf("%s - %s", start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			WebLogTypes: request.WebLogTypes,
+			AppLogTypes: request.AppLogTypes,
+			Summary:     summary,
+		}, request.ReportName)
+		if err != nil {
+			s.logger.Errorf("Failed to generate correlation report: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response["generated_files"] = []string{reportFile}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+)
+
+// fileDigest returns the SHA-256 of the file's content and its size
+func fileDigest(r io.Reader) (string, int64, error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// duplicateWarning describes why an uploaded file is a duplicate: one with
+// the same content was already processed in full. It returns "" for a new
+// file, or when ingest.duplicate_files allows duplicates. Re-uploaded
+// files, such as a rotated log sent again, would otherwise count the same
+// traffic twice.
+func (s *Server) duplicateWarning(filename, sum string) (string, error) {
+	if s.config.Ingest.DuplicateFiles == "allow" {
+		return "", nil
+	}
+	previous, err := s.db.GetIngestedFile(sum)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s has the same content as %s, processed at %s", filename, previous.Filename,
+		previous.IngestedAt.UTC().Format(time.RFC3339)), nil
+}
+
+// recordIngestedFile remembers a file that was processed and stored in full
+func (s *Server) recordIngestedFile(filename, logType, sum string, size int64) {
+	err := s.db.RecordIngestedFile(&models.IngestedFile{
+		SHA256:     sum,
+		Filename:   filename,
+		LogType:    logType,
+		Size:       size,
+		IngestedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to record ingested file %s: %v", filename, err)
+	}
+}
+
+// storedFiles follows the entries of files from the processor's queue to
+// storage, so a file is only remembered as ingested once the writers
+// stored all of its entries
+type storedFiles struct {
+	mu      sync.Mutex
+	pending map[*models.LogEntry]*storedFile
+}
+
+// storedFile counts the entries of a file waiting for the writers
+type storedFile struct {
+	files   *storedFiles
+	waiting int
+	failed  int
+	// changed is signalled whenever entries were stored
+	changed chan struct{}
+}
+
+// track starts following the entries of a file; pass queued as the
+// processor's Queued option
+func (f *storedFiles) track() *storedFile {
+	return &storedFile{files: f, changed: make(chan struct{}, 1)}
+}
+
+func (file *storedFile) queued(entry *models.LogEntry) {
+	f := file.files
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending == nil {
+		f.pending = make(map[*models.LogEntry]*storedFile)
+	}
+	f.pending[entry] = file
+	file.waiting++
+}
+
+// stored acknowledges a batch the writers are done with. ok is false when
+// some of its entries failed to store; entries plugins dropped count as
+// stored.
+func (f *storedFiles) stored(batch []*models.LogEntry, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range batch {
+		file, found := f.pending[entry]
+		if !found {
+			continue
+		}
+		delete(f.pending, entry)
+		file.waiting--
+		if !ok {
+			file.failed++
+		}
+		select {
+		case file.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// wait blocks until every entry of the file queued so far was stored. It
+// fails when some were not, or once the context ends.
+func (file *storedFile) wait(ctx context.Context) error {
+	for {
+		file.files.mu.Lock()
+		waiting, failed := file.waiting, file.failed
+		file.files.mu.Unlock()
+		if waiting == 0 {
+			if failed > 0 {
+				return fmt.Errorf("%d entries are in batches that failed to store", failed)
+			}
+			return nil
+		}
+		select {
+		case <-file.changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
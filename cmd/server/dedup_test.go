@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoredFiles(t *testing.T) {
+	var files storedFiles
+	first, second := &models.LogEntry{}, &models.LogEntry{}
+	file := files.track()
+	file.queued(first)
+	file.queued(second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, file.wait(ctx), context.DeadlineExceeded, "the file waits for its entries")
+
+	files.stored([]*models.LogEntry{first, {}}, true)
+	done := make(chan error)
+	go func() { done <- file.wait(context.Background()) }()
+	files.stored([]*models.LogEntry{second}, true)
+	require.NoError(t, <-done)
+	assert.Empty(t, files.pending)
+
+	// A file with entries in a batch that failed is not stored in full
+	file = files.track()
+	file.queued(first)
+	files.stored([]*models.LogEntry{first}, false)
+	assert.EqualError(t, file.wait(context.Background()), "1 entries are in batches that failed to store")
+
+	// A file without entries has nothing to wait for
+	assert.NoError(t, files.track().wait(context.Background()))
+}
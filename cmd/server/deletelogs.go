@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// hasConditions reports whether a filter narrows the entries at all
+func hasConditions(filter *models.LogFilter) bool {
+	return filter.StartTime != nil || filter.EndTime != nil || filter.LogType != "" ||
+		filter.StatusCode != nil || filter.MinStatusCode > 0 || filter.SourceIP != "" ||
+		filter.Path != "" || filter.Method != ""
+}
+
+// matchedRange returns the time range [start, end) holding the entries a
+// filter matches, bounded by the filter's own times where it has them.
+// ok is false when nothing matches.
+func (s *Server) matchedRange(ctx context.Context, filter *models.LogFilter) (start, end time.Time, ok bool, err error) {
+	edge := func(ascending bool) (time.Time, bool, error) {
+		query := *filter
+		query.Limit, query.Offset = 1, 0
+		query.SortBy, query.SortAscending = "timestamp", ascending
+		entries, err := s.db.Find(ctx, &query)
+		if err != nil || len(entries) == 0 {
+			return time.Time{}, false, err
+		}
+		return entries[0].Timestamp, true, nil
+	}
+
+	if filter.StartTime != nil {
+		start = *filter.StartTime
+	} else if start, ok, err = edge(true); err != nil || !ok {
+		return start, end, ok, err
+	}
+	if filter.EndTime != nil {
+		end = *filter.EndTime
+	} else {
+		newest, found, err := edge(false)
+		if err != nil || !found {
+			return start, end, found, err
+		}
+		end = newest.Add(time.Nanosecond)
+	}
+	return start, end, true, nil
+}
+
+// deleteLogsHandler removes the entries matching a filter, such as those
+// of a file uploaded by mistake. With dry_run it only counts them. A
+// filter matching everything is refused; retention removes old entries.
+func (s *Server) deleteLogsHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		models.LogFilter
+		DryRun bool `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	filter := &req.LogFilter
+	if !hasConditions(filter) {
+		http.Error(w, "A filter is required: start_time, end_time, log_type, status_code, min_status_code, source_ip, path or method", http.StatusBadRequest)
+		return
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && !filter.StartTime.Before(*filter.EndTime) {
+		http.Error(w, "start_time must be before end_time", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if req.DryRun {
+		matched, err := s.db.Count(r.Context(), filter)
+		if err != nil {
+			s.logger.Errorf("Failed to count logs: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"dry_run": true,
+			"matched": matched,
+		})
+		return
+	}
+
+	// The range is taken before deleting, while the entries can be found
+	var start, end time.Time
+	var matched bool
+	if s.config.Rollups.Enabled {
+		var err error
+		if start, end, matched, err = s.matchedRange(r.Context(), filter); err != nil {
+			s.logger.Errorf("Failed to find the time range of logs to delete: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	deleted, err := s.db.DeleteMatching(r.Context(), filter)
+	if err != nil {
+		s.logger.Errorf("Failed to delete logs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	response := map[string]interface{}{
+		"dry_run": false,
+		"deleted": deleted,
+	}
+	if matched && deleted > 0 {
+		id := s.startRollupRebuild(start, end).Snapshot().ID
+		response["rollup_job_id"] = id
+		response["rollup_status_url"] = "/api/v1/rollups/rebuild/" + id
+	}
+	actor := requestActor(r)
+	s.logger.Infof("%s deleted %d log entries by filter", actor, deleted)
+	s.recordAudit(audit.ActionLogsDeleted, actor, "logs", map[string]interface{}{
+		"filter":  filter,
+		"deleted": deleted,
+	})
+
+	json.NewEncoder(w).Encode(response)
+}
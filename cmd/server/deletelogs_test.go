@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteLogs(t *testing.T) {
+	s := newTestServer(t, nil)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, ip := range []string{"10.0.0.1", "10.0.0.1", "10.0.0.2"} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute), LogType: "nginx", SourceIP: ip,
+			Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+	remove := func(body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+		w := serve(s, httptest.NewRequest(http.MethodDelete, "/api/v1/logs", strings.NewReader(body)))
+		var response map[string]interface{}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w, response
+	}
+	remaining := func() int64 {
+		t.Helper()
+		count, err := s.db.Count(s.ctx, &models.LogFilter{})
+		require.NoError(t, err)
+		return count
+	}
+
+	// Filters that match everything are refused
+	for _, body := range []string{`{}`, `{"dry_run": true}`, `{"limit": 10, "offset": 2}`} {
+		w, _ := remove(body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "A filter is required", body)
+	}
+	w, _ := remove(`{"start_time": "2024-03-01T13:00:00Z", "end_time": "2024-03-01T12:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = remove(`not json`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, int64(3), remaining())
+
+	// A dry run counts without deleting
+	w, response := remove(`{"source_ip": "10.0.0.1", "dry_run": true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]interface{}{"dry_run": true, "matched": 2.0}, response)
+	assert.Equal(t, int64(3), remaining())
+
+	records, err := s.db.GetAuditRecords(0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, records, "dry runs are not audited")
+
+	w, response = remove(`{"source_ip": "10.0.0.1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, false, response["dry_run"])
+	assert.Equal(t, 2.0, response["deleted"])
+	assert.NotContains(t, response, "rollup_job_id", "no rollups to rebuild")
+	assert.Equal(t, int64(1), remaining())
+
+	// The deletion is audited with its filter
+	records, err = s.db.GetAuditRecords(0, 100)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, audit.ActionLogsDeleted, records[0].Action)
+	assert.Equal(t, "logs", records[0].Subject)
+	assert.NotEmpty(t, records[0].Actor)
+	var details struct {
+		Deleted int64            `json:"deleted"`
+		Filter  models.LogFilter `json:"filter"`
+	}
+	require.NoError(t, json.Unmarshal(records[0].Details, &details))
+	assert.Equal(t, int64(2), details.Deleted)
+	assert.Equal(t, "10.0.0.1", details.Filter.SourceIP)
+}
+
+func TestMatchedRange(t *testing.T) {
+	s := newTestServer(t, nil)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, minute := range []int{5, 10, 20} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: base.Add(time.Duration(minute) * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1",
+			Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+
+	// Without times, the range runs from the oldest to just past the newest
+	start, end, ok, err := s.matchedRange(s.ctx, &models.LogFilter{LogType: "nginx"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, base.Add(5*time.Minute).Equal(start))
+	assert.True(t, base.Add(20*time.Minute+time.Nanosecond).Equal(end))
+
+	// The filter's own times bound it
+	until := base.Add(15 * time.Minute)
+	start, end, ok, err = s.matchedRange(s.ctx, &models.LogFilter{LogType: "nginx", EndTime: &until})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, base.Add(5*time.Minute).Equal(start))
+	assert.True(t, until.Equal(end))
+
+	_, _, ok, err = s.matchedRange(s.ctx, &models.LogFilter{LogType: "apache"})
+	require.NoError(t, err)
+	assert.False(t, ok, "nothing matches")
+}
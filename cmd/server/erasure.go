@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/erasure"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/gorilla/mux"
+)
+
+// erasureJobKind tells erasure jobs apart from other jobs
+const erasureJobKind = "erasure"
+
+// setupErasure loads the key erasure certificates are signed with
+func (s *Server) setupErasure() error {
+	key, err := erasure.LoadOrCreateKey(s.config.Compliance.Erasure.SigningKeyFile)
+	if err != nil {
+		return err
+	}
+	s.erasureKey = key
+	s.logger.Infof("Erasure certificates are signed with key %s", erasure.KeyID(key.Public().(ed25519.PublicKey)))
+	return nil
+}
+
+// requestErasureHandler starts purging or anonymizing every stored entry
+// of an IP address or user identifier, and the saved reports naming it
+func (s *Server) requestErasureHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		IP         string `json:"ip"`
+		Identifier string `json:"identifier"`
+		Mode       string `json:"mode"` // purge (default) or anonymize
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Mode == "" {
+		request.Mode = erasure.ModePurge
+	}
+	if request.Mode != erasure.ModePurge && request.Mode != erasure.ModeAnonymize {
+		http.Error(w, fmt.Sprintf("mode must be %s or %s", erasure.ModePurge, erasure.ModeAnonymize), http.StatusBadRequest)
+		return
+	}
+
+	var subject *erasure.Subject
+	var err error
+	switch {
+	case request.IP != "" && request.Identifier != "":
+		err = fmt.Errorf("give either ip or identifier, not both")
+	case request.IP != "":
+		subject, err = erasure.NewIPSubject(request.IP)
+	default:
+		subject, err = erasure.NewIdentifierSubject(request.Identifier, s.config.Compliance.Erasure.IdentifierFields)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	certificate := &erasure.Certificate{
+		SubjectType:   subject.Kind,
+		SubjectSHA256: subject.SHA256(),
+		Mode:          request.Mode,
+		RequestedBy:   requestActor(r),
+		RequestedAt:   time.Now().UTC(),
+	}
+	// Job details are listed to anyone polling, so they name the subject
+	// only by hash
+	details := map[string]interface{}{
+		"subject_type":   certificate.SubjectType,
+		"subject_sha256": certificate.SubjectSHA256,
+		"mode":           certificate.Mode,
+	}
+	// Jobs outlive the request and stop when the server shuts down
+	job := s.jobs.Start(s.ctx, erasureJobKind, details, func(ctx context.Context, job *jobs.Job) error {
+		return s.runErasure(ctx, job, subject, certificate)
+	})
+	id := job.Snapshot().ID
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/compliance/erasures/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// runErasure erases the subject from the stored entries and saved reports,
+// then signs and saves the certificate as the job's result
+func (s *Server) runErasure(ctx context.Context, job *jobs.Job, subject *erasure.Subject, certificate *erasure.Certificate) error {
+	total, err := s.db.Count(ctx, &models.LogFilter{})
+	if err != nil {
+		return err
+	}
+	job.SetTotal(total)
+
+	var scanned int64
+	result, err := erasure.Erase(ctx, s.db, subject, certificate.Mode, s.config.Compliance.Erasure.BatchSize, func(progress erasure.Result) {
+		for ; scanned < progress.Scanned; scanned++ {
+			job.Advance(0, nil)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	archiveFiles, archived, err := s.archiver.Rewrite(ctx, subject.Archived(certificate.Mode))
+	if err != nil {
+		return err
+	}
+
+	redacted, retained, err := s.reporter.RedactReports(subject.Redact)
+	if err != nil {
+		return err
+	}
+
+	certificate.ID = job.Snapshot().ID
+	certificate.Result = *result
+	certificate.ReportsRedacted = redacted
+	certificate.ReportsRetained = retained
+	certificate.ArchivedMatched = archived
+	certificate.ArchiveFiles = archiveFiles
+	certificate.CompletedAt = time.Now().UTC()
+	if err := certificate.Sign(s.erasureKey); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(certificate, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := s.reporter.SaveErasureCertificate(certificate.ID, data); err != nil {
+		return err
+	}
+	job.SetResult(certificate)
+
+	s.recordAudit(audit.ActionErasureCompleted, certificate.RequestedBy, "erasure:"+certificate.ID, map[string]interface{}{
+		"subject_type":     certificate.SubjectType,
+		"subject_sha256":   certificate.SubjectSHA256,
+		"mode":             certificate.Mode,
+		"entries_matched":  result.Matched,
+		"archived_matched": archived,
+		"reports_redacted": len(redacted),
+	})
+	s.logger.Infof("Erasure %s matched %d of %d entries and redacted %d reports", certificate.ID, result.Matched, result.Scanned, len(redacted))
+	return nil
+}
+
+// getErasureHandler returns a running erasure's progress, or once it has
+// completed its certificate. Certificates are kept after the job is gone.
+func (s *Server) getErasureHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if job, ok := s.jobs.Get(id); ok && job.Snapshot().Kind == erasureJobKind {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job.Snapshot())
+		return
+	}
+
+	// Job IDs are hex, which keeps other names out of the report store
+	if _, err := hex.DecodeString(id); err != nil {
+		http.Error(w, "Erasure not found", http.StatusNotFound)
+		return
+	}
+	data, err := s.reporter.ErasureCertificate(id)
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "Erasure not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to read erasure certificate %s: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":     id,
+		"kind":   erasureJobKind,
+		"status": jobs.StatusCompleted,
+		"result": json.RawMessage(data),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getErasurePublicKeyHandler returns the key certificates are verified with
+func (s *Server) getErasurePublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	pub := s.erasureKey.Public().(ed25519.PublicKey)
+	encoded, err := erasure.PublicKeyPEM(pub)
+	if err != nil {
+		s.logger.Errorf("Failed to encode erasure public key: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"key_id":     erasure.KeyID(pub),
+		"algorithm":  "Ed25519",
+		"public_key": string(encoded),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// verifyErasureHandler checks that a certificate was signed by this
+// server and has not been altered since
+func (s *Server) verifyErasureHandler(w http.ResponseWriter, r *http.Request) {
+	var certificate erasure.Certificate
+	if err := json.NewDecoder(r.Body).Decode(&certificate); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":     certificate.ID,
+		"valid":  certificate.Verify(s.erasureKey.Public().(ed25519.PublicKey)),
+		"key_id": certificate.KeyID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
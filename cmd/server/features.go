@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/features"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/versioning"
+	"github.com/gorilla/mux"
+)
+
+// maxProjectLength is the longest project name overrides are stored for
+const maxProjectLength = 100
+
+// loadFeatures applies the configured flags and the stored overrides
+func loadFeatures(cfg config.FeaturesConfig, db storage.FeatureStore) (*features.Set, error) {
+	set := features.NewSet(cfg.ProjectField)
+
+	names := make([]string, 0, len(cfg.Flags))
+	for name := range cfg.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := cfg.Flags[name]
+		if err := set.Configure(name, features.Setting{Enabled: flag.Enabled, Projects: flag.Projects}); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides, err := db.GetFeatureOverrides()
+	if err != nil {
+		return nil, err
+	}
+	set.SetOverrides(overrides)
+	return set, nil
+}
+
+// getFeaturesHandler lists every flag with its configuration and overrides.
+// With a project it also reports whether each flag is on for it.
+func (s *Server) getFeaturesHandler(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+
+	states := s.features.States()
+	flags := make([]map[string]interface{}, 0, len(states))
+	for _, state := range states {
+		flag := map[string]interface{}{
+			"name":        state.Name,
+			"description": state.Description,
+			"default":     state.Default,
+			"enabled":     state.Enabled,
+			"projects":    state.Projects,
+			"overrides":   state.Overrides,
+		}
+		if project != "" {
+			flag["enabled_for_project"] = s.features.Enabled(state.Name, project)
+		}
+		flags = append(flags, flag)
+	}
+
+	response := map[string]interface{}{
+		"project_field": s.config.Features.ProjectField,
+		"flags":         flags,
+	}
+	if project != "" {
+		response["project"] = project
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// setFeatureOverrideHandler turns a flag on or off for a project, or for
+// all projects when none is given
+func (s *Server) setFeatureOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["flag"]
+	if _, ok := features.Lookup(name); !ok {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+
+	var request struct {
+		Project string `json:"project"`
+		Enabled *bool  `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Enabled == nil {
+		http.Error(w, "enabled is required", http.StatusBadRequest)
+		return
+	}
+	project := request.Project
+	if len(project) > maxProjectLength {
+		http.Error(w, fmt.Sprintf("project must be at most %d characters", maxProjectLength), http.StatusBadRequest)
+		return
+	}
+
+	previous, err := s.findFeatureOverride(name, project)
+	if err != nil {
+		s.logger.Errorf("Failed to get feature overrides: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	override := &models.FeatureOverride{
+		Flag:      name,
+		Project:   project,
+		Enabled:   *request.Enabled,
+		UpdatedBy: requestActor(r),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.db.SetFeatureOverride(override); err != nil {
+		s.logger.Errorf("Failed to set feature override: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.features.Override(override)
+	s.recordAudit(audit.ActionFeatureOverridden, override.UpdatedBy, featureSubject(name, project), map[string]interface{}{
+		"enabled": override.Enabled,
+	})
+	action, before := versioning.ActionCreated, json.RawMessage(nil)
+	if previous != nil {
+		action, before = versioning.ActionUpdated, featureOverrideState(previous)
+	}
+	s.recordConfigVersion(kindFeatureOverride, featureObjectID(name, project), action, override.UpdatedBy,
+		before, featureOverrideState(override))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+// deleteFeatureOverrideHandler removes an override, returning the flag of
+// the project, or of all projects without one, to its configuration
+func (s *Server) deleteFeatureOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["flag"]
+	if _, ok := features.Lookup(name); !ok {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+	project := r.URL.Query().Get("project")
+
+	previous, err := s.findFeatureOverride(name, project)
+	if err != nil {
+		s.logger.Errorf("Failed to get feature overrides: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	found, err := s.db.DeleteFeatureOverride(name, project)
+	if err != nil {
+		s.logger.Errorf("Failed to delete feature override: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Feature override not found", http.StatusNotFound)
+		return
+	}
+	s.features.RemoveOverride(name, project)
+	s.recordAudit(audit.ActionFeatureRestored, requestActor(r), featureSubject(name, project), nil)
+	if previous != nil {
+		s.recordConfigVersion(kindFeatureOverride, featureObjectID(name, project), versioning.ActionDeleted, requestActor(r),
+			featureOverrideState(previous), nil)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findFeatureOverride returns the stored override of a flag for a project,
+// or nil if there is none
+func (s *Server) findFeatureOverride(flag, project string) (*models.FeatureOverride, error) {
+	overrides, err := s.db.GetFeatureOverrides()
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if override.Flag == flag && override.Project == project {
+			return override, nil
+		}
+	}
+	return nil, nil
+}
+
+func featureSubject(name, project string) string {
+	if project == "" {
+		return "feature:" + name
+	}
+	return "feature:" + name + ":" + project
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (s *Server) getForwardingStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := s.forwarder.Stats()
+
+	response := map[string]interface{}{
+		"destinations": stats,
+		"count":        len(stats),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
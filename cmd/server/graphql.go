@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/graphql"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// errGraphQLInternal is what GraphQL clients see of a failed query; the
+// cause is logged
+var errGraphQLInternal = errors.New("Internal server error")
+
+// setupGraphQL resolves the GraphQL schema over stored logs and stats and
+// loads the persisted queries
+func (s *Server) setupGraphQL() error {
+	cfg := s.config.GraphQL
+	persisted := graphql.NewPersistedQueries(cfg.MaxPersistedQueries, cfg.PersistedOnly)
+	if cfg.PersistedQueriesDir != "" {
+		count, err := persisted.AddDir(cfg.PersistedQueriesDir)
+		if err != nil {
+			return err
+		}
+		s.logger.Infof("Loaded %d persisted GraphQL queries from %s", count, cfg.PersistedQueriesDir)
+	}
+
+	s.graphql = graphql.NewExecutor(&graphqlResolver{s}, graphql.Limits{MaxDepth: cfg.MaxDepth, MaxComplexity: cfg.MaxComplexity}, persisted)
+	return nil
+}
+
+// graphqlHandler runs a GraphQL query sent as a JSON body or, so persisted
+// queries can be cached by proxies, as GET parameters
+func (s *Server) graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	var request graphql.Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		for name, target := range map[string]interface{}{"variables": &request.Variables, "extensions": &request.Extensions} {
+			if value := query.Get(name); value != "" {
+				decoder := json.NewDecoder(strings.NewReader(value))
+				decoder.UseNumber()
+				if err := decoder.Decode(target); err != nil {
+					http.Error(w, fmt.Sprintf("Invalid %s parameter", name), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := s.graphql.Execute(r.Context(), &request)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// graphqlSchemaHandler describes the GraphQL schema in SDL
+func (s *Server) graphqlSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, graphql.SDL)
+}
+
+// graphqlResolver resolves the fields of the GraphQL schema that are not
+// read straight from models
+type graphqlResolver struct {
+	s *Server
+}
+
+func (r *graphqlResolver) LogEntry() graphql.LogEntryResolver { return r }
+func (r *graphqlResolver) Query() graphql.QueryResolver       { return r }
+
+func (r *graphqlResolver) ID(_ context.Context, entry *models.LogEntry) (*string, error) {
+	id := fmt.Sprint(entry.ID)
+	return &id, nil
+}
+
+func (r *graphqlResolver) Metadata(_ context.Context, entry *models.LogEntry, key *string) (interface{}, error) {
+	if key != nil {
+		return entry.Metadata[*key], nil
+	}
+	return entry.Metadata, nil
+}
+
+// logFilter narrows the entries logs and facets cover, as the query
+// parameters of GET /api/v1/logs do
+func logFilter(startTime, endTime *time.Time, logType *string, statusCode *int, sourceIP, path, method *string) *models.LogFilter {
+	return &models.LogFilter{
+		StartTime:  startTime,
+		EndTime:    endTime,
+		LogType:    valueOf(logType),
+		StatusCode: statusCode,
+		SourceIP:   valueOf(sourceIP),
+		Path:       valueOf(path),
+		Method:     valueOf(method),
+	}
+}
+
+func (r *graphqlResolver) Logs(ctx context.Context, startTime, endTime *time.Time, logType *string, statusCode *int, sourceIP, path, method *string, limit, offset int) ([]*models.LogEntry, error) {
+	if limit < 1 || offset < 0 {
+		return nil, errors.New("limit must be positive and offset must not be negative")
+	}
+	filter := logFilter(startTime, endTime, logType, statusCode, sourceIP, path, method)
+	filter.Limit, filter.Offset = limit, offset
+	logs, err := r.s.db.Find(ctx, filter)
+	if err != nil {
+		r.s.logger.Errorf("Failed to query logs: %v", err)
+		return nil, errGraphQLInternal
+	}
+	return logs, nil
+}
+
+func (r *graphqlResolver) Facets(ctx context.Context, field string, startTime, endTime *time.Time, logType *string, statusCode *int, sourceIP, path, method *string, limit int) ([]*models.FacetCount, error) {
+	if limit < 1 {
+		return nil, errors.New("limit must be positive")
+	}
+	field = strings.ToLower(field)
+	facets, err := r.s.db.Aggregate(ctx, logFilter(startTime, endTime, logType, statusCode, sourceIP, path, method), field, limit)
+	if err != nil {
+		r.s.logger.Errorf("Failed to get %s facets: %v", field, err)
+		return nil, errGraphQLInternal
+	}
+	return pointers(facets), nil
+}
+
+func (r *graphqlResolver) MethodStats(_ context.Context, startTime, endTime *time.Time, logType, path *string, groupBy string, limit int) ([]*models.MethodStats, error) {
+	if limit < 1 {
+		return nil, errors.New("limit must be positive")
+	}
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if startTime != nil {
+		start = *startTime
+	}
+	if endTime != nil {
+		end = *endTime
+	}
+	stats, err := r.s.db.GetMethodStats(start, end, valueOf(logType), valueOf(path), groupBy == "PATH", limit)
+	if err != nil {
+		r.s.logger.Errorf("Failed to get method stats: %v", err)
+		return nil, errGraphQLInternal
+	}
+	return pointers(stats), nil
+}
+
+func (r *graphqlResolver) Stats(ctx context.Context) (*graphql.Stats, error) {
+	stats, err := r.s.databaseStats(ctx)
+	if err != nil {
+		r.s.logger.Errorf("Failed to get database stats: %v", err)
+		return nil, errGraphQLInternal
+	}
+	result := &graphql.Stats{TotalLogs: number(stats["total_logs"]), TotalSize: number(stats["total_size"])}
+	if databaseType, ok := stats["database_type"].(string); ok {
+		result.DatabaseType = &databaseType
+	}
+	if connected, ok := stats["connected"].(bool); ok {
+		result.Connected = &connected
+	}
+	return result, nil
+}
+
+func (r *graphqlResolver) Processing(context.Context) (*graphql.Processing, error) {
+	p := r.s.processingSnapshot()
+	totalProcessed, errors, batchSize := float64(p.TotalProcessed), float64(p.Errors), int(p.BatchSize)
+	return &graphql.Processing{
+		TotalProcessed: &totalProcessed,
+		Errors:         &errors,
+		StartTime:      &p.StartTime,
+		Workers:        &p.Workers,
+		BatchSize:      &batchSize,
+		QueueFill:      &p.QueueFill,
+	}, nil
+}
+
+// number is a stats value as a GraphQL Float, whether it was counted or
+// read back from the cache
+func number(value interface{}) *float64 {
+	var n float64
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return nil
+	}
+	return &n
+}
+
+// valueOf is the value of an optional argument, or its zero value when the
+// query left it out
+func valueOf[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return *value
+}
+
+// pointers lists pointers to the values, as the schema's list fields
+// resolve them
+func pointers[T any](values []T) []*T {
+	result := make([]*T, len(values))
+	for i := range values {
+		result[i] = &values[i]
+	}
+	return result
+}
+
+// processingSnapshot is the processing stats and pipeline settings at one
+// moment
+type processingSnapshot struct {
+	TotalProcessed int64
+	Errors         int64
+	StartTime      time.Time
+	Workers        int
+	BatchSize      int64
+	QueueFill      float64
+}
+
+func (s *Server) processingSnapshot() processingSnapshot {
+	stats := s.processor.GetStats()
+	load, _ := s.pipeline.latest()
+	return processingSnapshot{
+		TotalProcessed: stats.TotalProcessed,
+		Errors:         stats.Errors,
+		StartTime:      stats.StartTime,
+		Workers:        s.processor.Workers(),
+		BatchSize:      s.pipeline.batchSize.Load(),
+		QueueFill:      load.QueueFill,
+	}
+}
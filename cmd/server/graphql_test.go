@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQL(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.GraphQL = config.GraphQLConfig{Enabled: true, MaxDepth: 8, MaxComplexity: 5000, MaxPersistedQueries: 10}
+	})
+
+	now := time.Now().Truncate(time.Second)
+	for _, status := range []int{200, 500, 200} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: now.Add(-time.Minute), LogType: "nginx", SourceIP: "10.0.0.1", Method: "GET", Path: "/",
+			StatusCode: status, Metadata: map[string]interface{}{"upstream": "app-1"},
+		}))
+	}
+
+	query := `query($status: Int) {
+		logs(statusCode: $status) { statusCode upstream: metadata(key: "upstream") }
+		facets(field: STATUS_CODE) { value count }
+		methodStats { method requests }
+		stats { totalLogs }
+	}`
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": map[string]interface{}{"status": 500}})
+	require.NoError(t, err)
+	w := serve(s, httptest.NewRequest(http.MethodPost, "/api/graphql", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {
+		"logs": [{"statusCode": 500, "upstream": "app-1"}],
+		"facets": [{"value": "200", "count": 2}, {"value": "500", "count": 1}],
+		"methodStats": [{"method": "GET", "requests": 3}],
+		"stats": {"totalLogs": 3}
+	}, "extensions": {"complexity": 325}}`, w.Body.String())
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/graphql?query="+url.QueryEscape(`{ logs(limit: 0) { path } }`), nil))
+	require.Equal(t, http.StatusOK, w.Code, "errors are returned in the response")
+	assert.Contains(t, w.Body.String(), "limit must be positive")
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/graphql/schema", nil))
+	assert.Contains(t, w.Body.String(), "type LogEntry {")
+}
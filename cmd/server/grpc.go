@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/auth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/grpcapi"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// defaultFacetLimit is the number of values of each facet Stats counts
+// unless the request asks for another
+const defaultFacetLimit = 10
+
+// grpcRoles are the roles the methods of the gRPC API need, as the
+// matching REST routes do
+var grpcRoles = map[string]string{
+	grpcapi.LogService_Ingest_FullMethodName:         auth.RoleAnalyst,
+	grpcapi.LogService_Query_FullMethodName:          auth.RoleViewer,
+	grpcapi.LogService_Stats_FullMethodName:          auth.RoleViewer,
+	grpcapi.LogService_GenerateReport_FullMethodName: auth.RoleAnalyst,
+}
+
+// grpcService implements the gRPC API over the server's storage,
+// processor and jobs
+type grpcService struct {
+	grpcapi.UnimplementedLogServiceServer
+	*Server
+}
+
+// setupGRPC binds the gRPC API's address, so a taken port fails startup.
+// Start serves it.
+func (s *Server) setupGRPC() error {
+	cfg := s.config.GRPC
+	listener, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+	}
+	s.grpcListener = listener
+	s.grpcServer = grpcapi.NewServer(grpcService{Server: s},
+		grpc.MaxRecvMsgSize(cfg.MaxMessageSize<<20),
+		grpc.UnaryInterceptor(s.grpcUnaryInterceptor),
+		grpc.StreamInterceptor(s.grpcStreamInterceptor),
+	)
+	return nil
+}
+
+// stopGRPC lets calls in progress finish until ctx ends, then cancels them
+func (s *Server) stopGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+func (s *Server) grpcUnaryInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorizeCall(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (s *Server) grpcStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorizeCall(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorizedStream is a stream whose context carries the caller
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorizeCall checks a call as the auth and rate limit middleware check
+// requests: with sign-in, the call needs a bearer token in its
+// authorization metadata from a user whose role allows the method. The
+// returned context carries the user.
+func (s *Server) authorizeCall(ctx context.Context, method string) (context.Context, error) {
+	var identity *auth.Identity
+	if s.auth != nil {
+		var token string
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			if scheme, value, ok := strings.Cut(values[0], " "); ok && strings.EqualFold(scheme, "Bearer") {
+				token = strings.TrimSpace(value)
+			}
+		}
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+
+		var err error
+		identity, err = s.auth.Authenticate(ctx, token, time.Now())
+		if auth.IsInvalidToken(err) {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+		if err != nil {
+			s.logger.Errorf("Failed to verify token: %v", err)
+			return nil, status.Error(codes.Unavailable, "Identity provider unavailable")
+		}
+
+		role, err := s.roleOf(identity)
+		if err != nil {
+			s.logger.Errorf("Failed to get role of %s: %v", identity.Actor(), err)
+			return nil, status.Error(codes.Internal, "Internal server error")
+		}
+		identity.Role = role
+		required, ok := grpcRoles[method]
+		if !ok {
+			required = auth.RoleAdmin
+		}
+		if !auth.RoleAllows(role, required) {
+			return nil, status.Errorf(codes.PermissionDenied, "Forbidden: this needs the %s role", required)
+		}
+		ctx = context.WithValue(ctx, identityKey{}, identity)
+	}
+
+	limiter := s.queryLimits
+	if method == grpcapi.LogService_Ingest_FullMethodName {
+		limiter = s.ingestLimits
+	}
+	if limiter != nil {
+		if ok, wait := limiter.Allow(ctx, grpcRateLimitKey(ctx, identity), time.Now()); !ok {
+			return nil, status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry in %s", wait.Round(time.Second))
+		}
+	}
+	return ctx, nil
+}
+
+// grpcRateLimitKey identifies who a call counts against: the signed-in
+// user or otherwise its client address
+func grpcRateLimitKey(ctx context.Context, identity *auth.Identity) string {
+	if identity != nil {
+		return "user:" + identity.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:"
+}
+
+// grpcError is the status a call fails with: that of an ended context or
+// one already chosen, and otherwise an internal error, which is logged
+func (s *Server) grpcError(err error, action string) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	s.logger.Errorf("Failed to %s: %v", action, err)
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// Ingest parses and stores each batch as it arrives. Batches wait while
+// the server is overloaded, which holds the client back through flow
+// control rather than failing its stream.
+func (g grpcService) Ingest(stream grpcapi.LogService_IngestServer) error {
+	ctx := stream.Context()
+	g.startStoring()
+
+	response := &grpcapi.IngestResponse{}
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(response)
+		}
+		if err != nil {
+			return err
+		}
+		if request.LogType != "" && !g.processor.SupportsLogType(request.LogType) {
+			return status.Error(codes.InvalidArgument, "Invalid log type. Must be one of: "+strings.Join(g.processor.LogTypes(), ", "))
+		}
+		if request.LogType == "" && len(request.Lines) > 0 {
+			return status.Error(codes.InvalidArgument, "log_type is required to parse lines")
+		}
+		if err := g.waitForCapacity(ctx); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		entries := make([]*models.LogEntry, 0, len(request.Lines)+len(request.Entries))
+		for _, line := range request.Lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			entry, err := g.processor.ParseLine(line, request.LogType)
+			if err != nil || entry == nil {
+				response.Rejected++
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		now := time.Now()
+		for _, parsed := range request.Entries {
+			entry := parsed.Model()
+			if entry.LogType == "" {
+				entry.LogType = request.LogType
+			}
+			if entry.LogType == "" {
+				response.Rejected++
+				continue
+			}
+			// Entries are stored as new ones
+			entry.ID = 0
+			if entry.Timestamp.IsZero() {
+				entry.Timestamp = now
+			}
+			entry.CreatedAt, entry.UpdatedAt = now, now
+			entries = append(entries, entry)
+		}
+
+		g.processor.Submit(entries)
+		response.Accepted += int64(len(entries))
+	}
+}
+
+// Query streams matching entries from a cursor, so a query of every entry
+// is never held in memory at once
+func (g grpcService) Query(request *grpcapi.QueryRequest, stream grpcapi.LogService_QueryServer) error {
+	if request.Limit < 0 || request.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+	filter := request.Filter.Model()
+	filter.Limit = int(request.Limit)
+	filter.Offset = int(request.Offset)
+	if err := parseSort(request.Sort, filter); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := g.db.Stream(stream.Context(), filter, func(entry *models.LogEntry) error {
+		return stream.Send(grpcapi.FromEntry(entry))
+	})
+	if err != nil {
+		return g.grpcError(err, "stream logs")
+	}
+	return nil
+}
+
+// Stats counts the stored entries, and the matching ones by each facet
+func (g grpcService) Stats(ctx context.Context, request *grpcapi.StatsRequest) (*grpcapi.StatsResponse, error) {
+	for _, field := range request.Facets {
+		if !slices.Contains(models.FacetFields, field) {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid facet %s. Must be one of: %s", field, strings.Join(models.FacetFields, ", "))
+		}
+	}
+	if request.FacetLimit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "facet_limit must not be negative")
+	}
+	limit := int(request.FacetLimit)
+	if limit == 0 {
+		limit = defaultFacetLimit
+	}
+
+	stats, err := g.databaseStats(ctx)
+	if err != nil {
+		return nil, g.grpcError(err, "get database stats")
+	}
+	filter := request.Filter.Model()
+	matching, err := g.db.Count(ctx, filter)
+	if err != nil {
+		return nil, g.grpcError(err, "count logs")
+	}
+
+	procStats := g.processor.GetStats()
+	response := &grpcapi.StatsResponse{
+		TotalLogs: statInt(stats["total_logs"]),
+		TotalSize: statInt(stats["total_size"]),
+		Matching:  matching,
+		Processing: &grpcapi.ProcessingStats{
+			TotalProcessed: procStats.TotalProcessed,
+			Errors:         procStats.Errors,
+			StartTime:      grpcapi.Timestamp(procStats.StartTime),
+		},
+	}
+	response.DatabaseType, _ = stats["database_type"].(string)
+	for _, field := range request.Facets {
+		counts, err := g.db.Aggregate(ctx, filter, field, limit)
+		if err != nil {
+			return nil, g.grpcError(err, "get "+field+" facets")
+		}
+		facet := &grpcapi.Facet{Field: field}
+		for _, count := range counts {
+			facet.Values = append(facet.Values, &grpcapi.FacetCount{Value: count.Value, Count: count.Count})
+		}
+		response.Facets = append(response.Facets, facet)
+	}
+	return response, nil
+}
+
+// statInt reads a count of the database stats, which are numbers of any
+// type once they have been cached as JSON
+func statInt(value interface{}) int64 {
+	switch n := value.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// GenerateReport starts a report job as POST /api/v1/reports/generate
+// does, then streams the job as it changes until it finishes
+func (g grpcService) GenerateReport(request *grpcapi.ReportRequest, stream grpcapi.LogService_GenerateReportServer) error {
+	report := reportRequest{
+		ReportName: request.ReportName,
+		ReportType: request.ReportType,
+		Format:     request.Format,
+		Stream:     request.Stream,
+		Compress:   request.Compress,
+	}
+	if request.Filters != nil {
+		report.Filters = request.Filters.Model()
+	}
+	files, err := reportFiles(&report)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	job := g.startReportJob(report, files, nil)
+
+	ctx := stream.Context()
+	for {
+		// Taken first, so a change made while sending is not missed
+		changed := job.Changed()
+		snapshot := job.Snapshot()
+		if err := stream.Send(grpcJob(snapshot)); err != nil {
+			return err
+		}
+		if snapshot.FinishedAt != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-g.ctx.Done():
+			return status.Error(codes.Unavailable, "Server is shutting down")
+		case <-changed:
+		}
+		// Changes made meanwhile are sent together
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(jobEventsInterval):
+		}
+	}
+}
+
+// grpcJob converts a job's snapshot, encoding its result as JSON
+func grpcJob(snapshot jobs.Snapshot) *grpcapi.Job {
+	job := &grpcapi.Job{
+		Id:        snapshot.ID,
+		Kind:      snapshot.Kind,
+		Status:    snapshot.Status,
+		Total:     snapshot.Total,
+		Done:      snapshot.Done,
+		Failed:    snapshot.Failed,
+		Errors:    snapshot.Errors,
+		Error:     snapshot.Error,
+		StartedAt: grpcapi.Timestamp(snapshot.StartedAt),
+	}
+	if snapshot.FinishedAt != nil {
+		job.FinishedAt = grpcapi.Timestamp(*snapshot.FinishedAt)
+	}
+	if snapshot.Result != nil {
+		if data, err := json.Marshal(snapshot.Result); err == nil {
+			job.ResultJson = string(data)
+		}
+	}
+	return job
+}
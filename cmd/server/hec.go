@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/hec"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// hecEventHandler accepts Splunk HTTP Event Collector event requests.
+// Events are parsed as their sourcetype when it names a log type, or else
+// as the log type of the request's token. Events without a known log type
+// are stored as messages.
+func (s *Server) hecEventHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config.Ingest.HEC
+
+	token, status, code, text := s.hecToken(r)
+	if token == nil {
+		writeHECResponse(w, status, code, text)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodySize<<20))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeHECResponse(w, http.StatusRequestEntityTooLarge, hec.CodeInvalidFormat, fmt.Sprintf("Request exceeds %d MB", cfg.MaxBodySize))
+		return
+	}
+	if err != nil {
+		writeHECResponse(w, http.StatusBadRequest, hec.CodeInvalidFormat, "Failed to read request body")
+		return
+	}
+
+	events, err := hec.Decode(body, r.Header.Get("Content-Encoding"))
+	var hecErr *hec.Error
+	if errors.As(err, &hecErr) {
+		response := map[string]interface{}{"text": hecErr.Text, "code": hecErr.Code}
+		if hecErr.Event >= 0 {
+			response["invalid-event-number"] = hecErr.Event
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	defaultLogType := token.LogType
+	if !s.processor.SupportsLogType(defaultLogType) {
+		defaultLogType = ""
+	}
+	entries := make([]*models.LogEntry, 0, len(events))
+	for _, event := range events {
+		logType := event.SourceType
+		if !s.processor.SupportsLogType(logType) {
+			logType = defaultLogType
+		}
+		entries = append(entries, hec.ToLogEntry(event, logType, s.processor.ParseLine))
+	}
+
+	s.startStoring()
+	s.processor.Submit(entries)
+
+	writeHECResponse(w, http.StatusOK, hec.CodeSuccess, "Success")
+}
+
+// hecHealthHandler reports whether events are accepted, for load balancers
+// and clients that check HEC health before sending
+func (s *Server) hecHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if s.guard != nil {
+		if overloaded, _ := s.guard.Overloaded(); overloaded {
+			writeHECResponse(w, http.StatusServiceUnavailable, hec.CodeServerBusy, "Server is busy")
+			return
+		}
+	}
+	writeHECResponse(w, http.StatusOK, hec.CodeHealthy, "HEC is healthy")
+}
+
+// hecToken returns the configured token a request carries, either as
+// "Authorization: Splunk <token>" or as the password of basic
+// authentication. Without one it returns the response to reject it with.
+func (s *Server) hecToken(r *http.Request) (*config.HECTokenConfig, int, int, string) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, http.StatusUnauthorized, hec.CodeTokenRequired, "Token is required"
+	}
+
+	var presented string
+	if scheme, value, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Splunk") {
+		presented = strings.TrimSpace(value)
+	} else if _, password, ok := r.BasicAuth(); ok {
+		presented = password
+	} else {
+		return nil, http.StatusUnauthorized, hec.CodeInvalidAuth, "Invalid authorization"
+	}
+
+	tokens := s.config.Ingest.HEC.Tokens
+	for i := range tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(tokens[i].Token)) == 1 {
+			return &tokens[i], 0, 0, ""
+		}
+	}
+	return nil, http.StatusForbidden, hec.CodeInvalidToken, "Invalid token"
+}
+
+// writeHECResponse writes a response body in the form HEC clients expect
+func writeHECResponse(w http.ResponseWriter, status, code int, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"text": text, "code": code})
+}
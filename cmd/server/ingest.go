@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/syslog"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/watcher"
+)
+
+// setupIngest tails the configured directories and starts the syslog
+// listeners, feeding both into the processor
+func (s *Server) setupIngest() error {
+	cfg := s.config.Ingest
+
+	var w *watcher.Watcher
+	if len(cfg.Watch) > 0 {
+		sources := make([]watcher.Source, 0, len(cfg.Watch))
+		for _, watch := range cfg.Watch {
+			if !s.processor.SupportsLogType(watch.LogType) {
+				return fmt.Errorf("watch %s: unsupported log type: %s", watch.Path, watch.LogType)
+			}
+			sources = append(sources, watcher.Source{Dir: watch.Path, Pattern: watch.Pattern, LogType: watch.LogType})
+		}
+
+		var err error
+		w, err = watcher.New(sources, s.ingestSink, watcher.Options{
+			OffsetsFile:   cfg.OffsetsFile,
+			PollInterval:  time.Duration(cfg.PollInterval) * time.Second,
+			FromBeginning: cfg.FromBeginning,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	receivers := make([]*syslog.Receiver, 0, len(cfg.Syslog))
+	for _, listener := range cfg.Syslog {
+		if listener.LogType != "" && !s.processor.SupportsLogType(listener.LogType) {
+			return fmt.Errorf("syslog listener %s: unsupported log type: %s", listener.Address, listener.LogType)
+		}
+		r, err := syslog.New(syslog.Listener{Protocol: listener.Protocol, Address: listener.Address, LogType: listener.LogType}, s.ingestSink)
+		if err != nil {
+			return err
+		}
+		receivers = append(receivers, r)
+	}
+
+	// Ports are bound before anything runs so a taken port fails startup
+	for _, r := range receivers {
+		if err := r.Start(s.ctx, func(err error) {
+			s.logger.Errorf("Failed to ingest syslog messages: %v", err)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Ingested entries are stored as they arrive rather than after an upload
+	s.startStoring()
+
+	if w != nil {
+		go w.Run(s.ctx, func(err error) {
+			s.logger.Errorf("Failed to ingest watched logs: %v", err)
+		})
+		for _, watch := range cfg.Watch {
+			s.logger.Infof("Watching %s for %s logs", watch.Path, watch.LogType)
+		}
+	}
+	for i, r := range receivers {
+		s.logger.Infof("Receiving syslog on %s/%s", r.Addr(), cfg.Syslog[i].Protocol)
+	}
+	return nil
+}
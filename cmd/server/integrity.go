@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/integrity"
+)
+
+// setupIntegrity creates the data integrity checker and, when enabled,
+// runs it on its schedule
+func (s *Server) setupIntegrity() error {
+	cfg := s.config.Integrity
+	s.integrity = integrity.NewChecker(s.db, s.reporter, integrity.Options{
+		FutureSkew:      time.Duration(cfg.FutureSkew) * time.Second,
+		RollupTolerance: cfg.RollupTolerance / 100,
+		RollupLookback:  time.Duration(cfg.RollupLookback) * time.Hour,
+	})
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if _, err := s.cron.AddFunc(cfg.Schedule, func() {
+		s.runIntegrityCheck()
+	}); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", cfg.Schedule, err)
+	}
+	return nil
+}
+
+// runIntegrityCheck runs every check and hands the findings to integrity
+// alert rules
+func (s *Server) runIntegrityCheck() *integrity.Result {
+	result := s.integrity.Run()
+	for check, err := range result.Errors {
+		s.logger.Errorf("Integrity check %s failed: %v", check, err)
+	}
+	for _, finding := range result.Findings {
+		s.logger.Warnf("Integrity check %s: %s. Repair: %s", finding.Check, finding.Message, finding.Repair)
+	}
+
+	if s.alerts != nil {
+		s.alerts.SetIntegrityFindings(result.Findings)
+	}
+	return result
+}
+
+func (s *Server) getIntegrityHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.integrity.Last()
+	if result == nil {
+		http.Error(w, "Integrity checks have not run yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) runIntegrityCheckHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.runIntegrityCheck()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
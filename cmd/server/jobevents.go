@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/gorilla/mux"
+)
+
+// jobEventsInterval is the least time between two progress events of a
+// job, so a file read line by line does not flood the client
+const jobEventsInterval = 250 * time.Millisecond
+
+// ingestJobEventsHandler streams an ingestion job's progress as
+// server-sent events, such as the lines of an upload read so far
+func (s *Server) ingestJobEventsHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	s.streamJob(w, r, job)
+}
+
+// reportJobEventsHandler streams a report job's progress through its
+// files as server-sent events
+func (s *Server) reportJobEventsHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok || job.Snapshot().Kind != reportJobKind {
+		http.Error(w, "Report job not found", http.StatusNotFound)
+		return
+	}
+	s.streamJob(w, r, job)
+}
+
+// streamJob sends the job as it is now, then again each time it changes,
+// as progress events. Once the job has finished it sends a done event and
+// ends the stream.
+func (s *Server) streamJob(w http.ResponseWriter, r *http.Request, job *jobs.Job) {
+	rc, err := s.startEventStream(w)
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(s.config.Tail.Heartbeat) * time.Second)
+	defer heartbeat.Stop()
+	for {
+		// Taken first, so a change made while sending is not missed
+		changed := job.Changed()
+		snapshot := job.Snapshot()
+		event := "progress"
+		if snapshot.FinishedAt != nil {
+			event = "done"
+		}
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			s.logger.Errorf("Failed to encode job %s: %v", snapshot.ID, err)
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		if err := rc.Flush(); err != nil || event == "done" {
+			return
+		}
+
+	wait:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-s.ctx.Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			case <-changed:
+				break wait
+			}
+		}
+
+		// Changes made meanwhile are sent together
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(jobEventsInterval):
+		}
+	}
+}
+
+// startEventStream starts a response of server-sent events and returns
+// the controller for flushing them. Streams last longer than the server's
+// write timeout, so it is cleared.
+func (s *Server) startEventStream(w http.ResponseWriter) (*http.ResponseController, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		s.logger.Warnf("Failed to clear the write deadline of an event stream: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Proxies such as nginx would otherwise hold events back
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	return rc, rc.Flush()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/latency"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/gorilla/mux"
+)
+
+// attachLatencyBudgets loads the latency budgets so the report can check
+// its response times against them
+func (s *Server) attachLatencyBudgets(data *reporting.ReportData) {
+	budgets, err := s.db.GetLatencyBudgets()
+	if err != nil {
+		s.logger.Errorf("Failed to get latency budgets for report: %v", err)
+		return
+	}
+	data.LatencyBudgets = budgets
+}
+
+// reloadLatencyBudgets hands edited budgets to the streaming evaluator
+func (s *Server) reloadLatencyBudgets() {
+	if s.alerts != nil {
+		if err := s.reloadAlertRules(); err != nil {
+			s.logger.Errorf("Failed to reload alert rules: %v", err)
+		}
+	}
+}
+
+func (s *Server) listLatencyBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	budgets, err := s.db.GetLatencyBudgets()
+	if err != nil {
+		s.logger.Errorf("Failed to get latency budgets: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"budgets": budgets,
+		"count":   len(budgets),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) createLatencyBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	budget := models.LatencyBudget{Percentile: 95}
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if budget.Path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+	if budget.Percentile <= 0 || budget.Percentile > 100 {
+		http.Error(w, "percentile must be above 0 and at most 100", http.StatusBadRequest)
+		return
+	}
+	if budget.ThresholdMs <= 0 {
+		http.Error(w, "threshold_ms must be positive", http.StatusBadRequest)
+		return
+	}
+	// Budgets apply to every request the path normalizes from
+	budget.Path = latency.NormalizePath(budget.Path)
+
+	if err := s.db.CreateLatencyBudget(&budget); err != nil {
+		s.logger.Errorf("Failed to create latency budget: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionLatencyBudgetCreated, requestActor(r), fmt.Sprintf("latency_budget:%d", budget.ID), map[string]interface{}{
+		"path":         budget.Path,
+		"percentile":   budget.Percentile,
+		"threshold_ms": budget.ThresholdMs,
+		"team":         budget.Team,
+	})
+	s.reloadLatencyBudgets()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(budget)
+}
+
+func (s *Server) deleteLatencyBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid latency budget ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := s.db.DeleteLatencyBudget(id)
+	if err != nil {
+		s.logger.Errorf("Failed to delete latency budget: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Latency budget not found", http.StatusNotFound)
+		return
+	}
+	s.recordAudit(audit.ActionLatencyBudgetDeleted, requestActor(r), fmt.Sprintf("latency_budget:%d", id), nil)
+	s.reloadLatencyBudgets()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// latencyBudgetStatusHandler checks every budget against the stored
+// entries of a time range, the last 24 hours by default. At most
+// maxLatencyEntries are read, the most recent ones.
+func (s *Server) latencyBudgetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, r.URL.Query().Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, r.URL.Query().Get("end_time")); err == nil {
+		end = t
+	}
+
+	budgets, err := s.db.GetLatencyBudgets()
+	if err != nil {
+		s.logger.Errorf("Failed to get latency budgets: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logs, err := s.db.Find(r.Context(), &models.LogFilter{StartTime: &start, EndTime: &end, Limit: maxLatencyEntries})
+	if err != nil {
+		s.logger.Errorf("Failed to get logs for latency budgets: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	results := latency.Check(budgets, logs)
+	if results == nil {
+		results = []latency.Result{}
+	}
+	response := map[string]interface{}{
+		"start_time": start,
+		"end_time":   end,
+		"results":    results,
+		"violations": len(latency.Violations(results)),
+		"entries":    len(logs),
+		"truncated":  len(logs) == maxLatencyEntries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/loadshed"
+)
+
+// setupLoadShedding starts measuring heap and CPU usage
+func (s *Server) setupLoadShedding() {
+	cfg := s.config.Ingest.LoadShedding
+	s.guard = loadshed.New(loadshed.Limits{
+		MaxHeapBytes: uint64(cfg.MaxHeap) << 20,
+		MaxCPU:       float64(cfg.MaxCPU) / 100,
+	})
+
+	go s.guard.Run(s.ctx, time.Duration(cfg.Interval)*time.Second, func(status loadshed.Status) {
+		if status.Overloaded {
+			s.logger.Warnf("Pausing ingestion: %s", status.Reason)
+		} else {
+			s.logger.Info("Resuming ingestion")
+		}
+	})
+}
+
+// shedLoad refuses ingestion requests with 503 and a Retry-After while
+// the server is overloaded
+func (s *Server) shedLoad(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.guard != nil {
+			if overloaded, reason := s.guard.Overloaded(); overloaded {
+				w.Header().Set("Retry-After", strconv.Itoa(s.config.Ingest.LoadShedding.RetryAfter))
+				http.Error(w, "Ingestion paused: "+reason, http.StatusServiceUnavailable)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// waitForCapacity blocks background ingestion while the server is
+// overloaded. It returns the context's error once the context ends.
+func (s *Server) waitForCapacity(ctx context.Context) error {
+	if s.guard != nil {
+		if err := s.guard.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// ingestSink processes streamed batches, holding each while the server is
+// overloaded
+func (s *Server) ingestSink(r io.Reader, logType string) error {
+	if s.guard != nil {
+		if err := s.guard.Wait(s.ctx); err != nil {
+			return err
+		}
+	}
+	return s.processor.ProcessFile(r, logType)
+}
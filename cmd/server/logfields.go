@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// logFields are the fields of entries in responses, as they are named in
+// JSON
+var logFields = []string{
+	"id", "timestamp", "log_type", "source_ip", "method", "path", "status_code", "response_size",
+	"user_agent", "referer", "processing_time", "raw_log", "metadata", "file_hash", "line_number",
+	"created_at", "updated_at",
+}
+
+// parseSort sets the order of a filter from a sort parameter: a field of
+// models.SortFields, optionally followed by ":asc" or ":desc"
+func parseSort(sort string, filter *models.LogFilter) error {
+	if sort == "" {
+		return nil
+	}
+	field, direction, _ := strings.Cut(sort, ":")
+	if !slices.Contains(models.SortFields, field) {
+		return fmt.Errorf("invalid sort field %q. Must be one of: %s", field, strings.Join(models.SortFields, ", "))
+	}
+	if direction != "" && direction != "asc" && direction != "desc" {
+		return fmt.Errorf("invalid sort direction %q. Must be asc or desc", direction)
+	}
+	filter.SortBy, filter.SortAscending = field, direction == "asc"
+	return nil
+}
+
+// parseFields returns the fields a fields parameter asks for, in its
+// order, or none for every field
+func parseFields(param string) ([]string, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(logFields, field) {
+			return nil, fmt.Errorf("invalid field %q. Must be one of: %s", field, strings.Join(logFields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// projectedEntry is an entry with only some of its fields, which it
+// encodes in their order
+type projectedEntry struct {
+	fields []string
+	values map[string]json.RawMessage
+}
+
+// project keeps the fields of entries, or returns them whole without any
+func project(entries []*models.LogEntry, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return entries, nil
+	}
+	projected := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		var err error
+		if projected[i], err = projectEntry(entry, fields); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
+
+// projectEntry keeps the fields of an entry, or returns it whole without
+// any
+func projectEntry(entry *models.LogEntry, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return entry, nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	projected := projectedEntry{fields: fields}
+	if err := json.Unmarshal(data, &projected.values); err != nil {
+		return nil, err
+	}
+	return projected, nil
+}
+
+func (p projectedEntry) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range p.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		b.Write(key)
+		b.WriteByte(':')
+		// Fields left out of an entry when empty are null
+		if value, ok := p.values[field]; ok {
+			b.Write(value)
+		} else {
+			b.WriteString("null")
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
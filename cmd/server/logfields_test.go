@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort      string
+		by        string
+		ascending bool
+		err       string
+	}{
+		{sort: ""},
+		{sort: "status_code", by: "status_code"},
+		{sort: "response_size:desc", by: "response_size"},
+		{sort: "processing_time:asc", by: "processing_time", ascending: true},
+		{sort: "timestamp:asc", by: "timestamp", ascending: true},
+		{sort: "path", err: `invalid sort field "path". Must be one of: timestamp, status_code, response_size, processing_time`},
+		{sort: "Status_Code", err: `invalid sort field "Status_Code"`},
+		{sort: ":asc", err: `invalid sort field ""`},
+		{sort: "status_code:up", err: `invalid sort direction "up". Must be asc or desc`},
+		{sort: "status_code:DESC", err: `invalid sort direction "DESC"`},
+		{sort: "status_code:asc:desc", err: `invalid sort direction "asc:desc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			filter := &models.LogFilter{}
+			err := parseSort(tt.sort, filter)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				assert.Empty(t, filter.SortBy, "a bad sort leaves the filter alone")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.by, filter.SortBy)
+			assert.Equal(t, tt.ascending, filter.SortAscending)
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		param  string
+		fields []string
+		err    string
+	}{
+		{param: ""},
+		{param: "timestamp", fields: []string{"timestamp"}},
+		{param: "status_code,path,timestamp", fields: []string{"status_code", "path", "timestamp"}},
+		{param: " path , status_code ", fields: []string{"path", "status_code"}},
+		{param: "path,status_code,path", fields: []string{"path", "status_code"}},
+		{param: "path,password", err: `invalid field "password"`},
+		{param: "path,,status_code", err: `invalid field ""`},
+		{param: "Path", err: `invalid field "Path"`},
+		{param: "metadata.user", err: `invalid field "metadata.user"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			fields, err := parseFields(tt.param)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	entries := []*models.LogEntry{{ID: 7, Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Path: "/", StatusCode: 200}}
+
+	whole, err := project(entries, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entries, whole)
+
+	projected, err := project(entries, []string{"status_code", "id", "file_hash"})
+	require.NoError(t, err)
+	data, err := json.Marshal(projected)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"status_code":200,"id":7,"file_hash":null}]`, string(data), "fields keep their order and empty ones are null")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/loki"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// lokiPushHandler accepts Loki push requests. Lines are parsed as the log
+// type named by their stream's log type label or, failing that, the
+// log_type query parameter, so a Promtail client URL can set it for all
+// streams. Lines without a known log type are stored as messages.
+func (s *Server) lokiPushHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config.Ingest.Loki
+
+	defaultLogType := r.URL.Query().Get("log_type")
+	if defaultLogType != "" && !s.processor.SupportsLogType(defaultLogType) {
+		http.Error(w, "Invalid log type. Must be one of: "+strings.Join(s.processor.LogTypes(), ", "), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodySize<<20))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, fmt.Sprintf("Push exceeds %d MB", cfg.MaxBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	streams, err := loki.Decode(body, r.Header.Get("Content-Type"), r.Header.Get("Content-Encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var entries []*models.LogEntry
+	for _, stream := range streams {
+		logType := stream.Labels[cfg.LogTypeLabel]
+		if logType == "" {
+			logType = defaultLogType
+		}
+		if !s.processor.SupportsLogType(logType) {
+			logType = ""
+		}
+		for _, entry := range stream.Entries {
+			entries = append(entries, loki.ToLogEntry(stream.Labels, entry, logType, s.processor.ParseLine))
+		}
+	}
+
+	s.startStoring()
+	s.processor.Submit(entries)
+
+	w.WriteHeader(http.StatusNoContent)
+}
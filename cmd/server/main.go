@@ -2,38 +2,104 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"html/template"
+	"io"
 	"log"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/archive"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/auth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/cache"
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
-	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/database"
+	_ "github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/database"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/encryption"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/features"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/forward"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/geoip"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/graphql"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/integrity"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/loadshed"
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/notify"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/plugin"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ratelimit"
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/tail"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/upload"
+	_ "github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
 )
 
 type Server struct {
 	config     *config.Config
-	db         *database.Database
+	db         storage.Storage
 	processor  *logprocessor.Processor
 	reporter   *reporting.Reporter
+	archiver   *archive.Archiver
 	cron       *cron.Cron
 	router     *mux.Router
 	logger     *logrus.Logger
+	alerts     *alerting.StreamEvaluator
+	notifier   *notify.Notifier
+	// reportDelivery posts scheduled report summaries to chat channels
+	reportDelivery *notify.ReportDeliverer
+	escalator  *alerting.Escalator
+	forwarder  *forward.Forwarder
+	// tail streams stored entries to live tail clients
+	tail       *tail.Hub
+	jobs       *jobs.Tracker
+	// reportSlots bounds how many report jobs run at once
+	reportSlots chan struct{}
+	uploads    *upload.Store
+	features   *features.Set
+	pipeline   pipelineStats
+	guard      *loadshed.Guard
+	cache      cache.Cache
+	graphql    *graphql.Executor
+	// grpcServer serves the gRPC API on grpcListener; nil unless enabled
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	// auth signs users in with the OpenID provider; nil without sign-in
+	auth       *auth.Authenticator
+	// ingestLimits and queryLimits are the rate limits of clients; nil
+	// without rate limiting
+	ingestLimits *ratelimit.Limiter
+	queryLimits  *ratelimit.Limiter
+	plugins    *plugin.Manager
+	integrity  *integrity.Checker
+	rollups    rollupState
+	erasureKey ed25519.PrivateKey
+	storing    sync.Once
+	// storedFiles tells uploads when their entries are stored
+	storedFiles storedFiles
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
@@ -43,38 +109,249 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	logger.SetLevel(logrus.InfoLevel)
 
 	// Initialize database
-	db, err := database.NewDatabase(cfg)
+	db, err := storage.Open(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
+	// Encrypt the raw logs of the configured projects at rest
+	if enc := cfg.Database.Encryption; enc.Enabled {
+		master, err := encryption.NewMasterKey(enc.MasterKey)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize encryption: %w", err)
+		}
+		db = encryption.NewStore(db, encryption.NewEncryptor(master, db, cfg.Features.ProjectField, enc.Projects, enc.MetadataKeys))
+	}
 
 	// Initialize log processor
-	processor := logprocessor.NewProcessor(10) // 10 workers
+	processor := logprocessor.NewProcessorWithQueue(cfg.Processing.Workers, cfg.Processing.QueueSize)
+	if cfg.Processing.ApacheFormat != "" {
+		if err := processor.SetAccessLogFormat("apache", cfg.Processing.ApacheFormat); err != nil {
+			return nil, fmt.Errorf("invalid apache log format: %w", err)
+		}
+	}
+	if cfg.Processing.NginxFormat != "" {
+		if err := processor.SetAccessLogFormat("nginx", cfg.Processing.NginxFormat); err != nil {
+			return nil, fmt.Errorf("invalid nginx log format: %w", err)
+		}
+	}
 
-	// Initialize reporter
-	reporter, err := reporting.NewReporter("web/templates", "reports")
+	// Initialize reporter, keeping reports on disk or in object storage
+	reportStore, err := reportstore.New(cfg.Reports.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize report storage: %w", err)
+	}
+	reporter, err := reporting.NewReporter("web/templates", reportStore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize reporter: %w", err)
 	}
+	// Record each report's size and checksum, which downloads use as ETags
+	reporter.SetCatalog(db)
+	// Link report rows back to the entries behind them
+	reporter.SetPublicURL(cfg.Server.PublicURL)
+	// Break reports down by the country and city of client IPs
+	if cfg.Reports.GeoIP.Database != "" {
+		locator, err := geoip.Open(cfg.Reports.GeoIP.Database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize GeoIP: %w", err)
+		}
+		reporter.SetLocator(locator)
+	}
+	// Show dates and numbers the way the deployment's customers write them
+	if err := reporter.SetLocale(cfg.Reports.Locale); err != nil {
+		return nil, fmt.Errorf("invalid reports locale: %w", err)
+	}
+	branding := reporting.Branding{Title: cfg.Reports.Branding.Title, Footer: cfg.Reports.Branding.Footer}
+	if cfg.Reports.Branding.Logo != "" {
+		branding.Logo, err = reporting.LoadLogo(cfg.Reports.Branding.Logo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load report logo: %w", err)
+		}
+	}
+	reporter.SetBranding(branding)
+
+	// Expiring entries are archived to cold storage, kept like reports
+	archiveStore, err := reportstore.New(cfg.Archive.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize archive storage: %w", err)
+	}
+
+	// Initialize alert notification channels
+	notifier, err := notify.NewNotifier(cfg.Alerting.Channels, cfg.Alerting.Escalation.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize notification channels: %w", err)
+	}
+	reportDelivery, err := notify.NewReportDeliverer(cfg.Reports.Delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize report delivery: %w", err)
+	}
+
+	// Initialize SIEM forwarding
+	forwarder, err := forward.NewForwarder(cfg.Forwarding.Destinations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize forwarding: %w", err)
+	}
+
+	// Initialize chunked upload storage
+	uploads, err := upload.NewStore(cfg.Ingest.Uploads.Dir, cfg.Ingest.Uploads.MaxSize<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize uploads: %w", err)
+	}
+
+	// Initialize feature flags
+	flags, err := loadFeatures(cfg.Features, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature flags: %w", err)
+	}
+
+	// Initialize the cache, shared between replicas with Redis
+	statsCache, err := cache.New(cfg.Cache, func(err error) {
+		if err != nil {
+			logger.Warnf("Redis cache unavailable, using in-process cache: %v", err)
+		} else {
+			logger.Info("Redis cache available again")
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
 
 	// Initialize cron scheduler
 	cronScheduler := cron.New(cron.WithSeconds())
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	server := &Server{
 		config:    cfg,
 		db:        db,
 		processor: processor,
 		reporter:  reporter,
+		archiver:  archive.New(archiveStore),
 		cron:      cronScheduler,
 		router:    mux.NewRouter(),
 		logger:    logger,
+		notifier:  notifier,
+		reportDelivery: reportDelivery,
+		forwarder: forwarder,
+		tail:      tail.NewHub(cfg.Tail.MaxClients, cfg.Tail.Buffer),
+		jobs:      jobs.NewTracker(jobRetention),
+		reportSlots: make(chan struct{}, cfg.Reports.MaxConcurrentJobs),
+		uploads:   uploads,
+		features:  flags,
+		cache:     statsCache,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	// Limit how often each client may ingest and query
+	if cfg.RateLimit.Enabled {
+		server.setupRateLimits()
+	}
+
+	// Pause ingestion while heap or CPU usage is too high
+	if cfg.Ingest.LoadShedding.Enabled {
+		server.setupLoadShedding()
+	}
+
+	// Measure the pipeline and, with autotune, adjust workers and batch size
+	server.pipeline.batchSize.Store(int64(cfg.Processing.BatchSize))
+	go server.measurePipeline()
+
+	if forwarder.Enabled() {
+		forwarder.Run(ctx, func(dest string, err error) {
+			logger.Errorf("Failed to forward logs to %s: %v", dest, err)
+		})
+	}
+
+	// Start plugins, which add parsers, enrichers, notification channels
+	// and exporters
+	if len(cfg.Plugins) > 0 {
+		if err := server.setupPlugins(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize plugins: %w", err)
+		}
+	}
+
+	// Initialize streaming alert evaluation
+	if cfg.Alerting.Enabled {
+		if err := server.setupAlerting(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize alerting: %w", err)
+		}
+	}
+
+	// Initialize continuous ingestion of watched directories and syslog
+	if len(cfg.Ingest.Watch) > 0 || len(cfg.Ingest.Syslog) > 0 {
+		if err := server.setupIngest(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize log ingestion: %w", err)
+		}
+	}
+
+	// Schedule recurring pulls of remote log files
+	if len(cfg.Ingest.Pull.Schedules) > 0 {
+		if err := server.setupPulls(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to schedule log pulls: %w", err)
+		}
+	}
+
+	// Serve GraphQL queries over stored logs and stats
+	if cfg.GraphQL.Enabled {
+		if err := server.setupGraphQL(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize GraphQL: %w", err)
+		}
+	}
+
+	// Require signing in with the OpenID provider
+	if cfg.Auth.OIDC.Enabled {
+		if err := server.setupAuth(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize sign-in: %w", err)
+		}
+	}
+
+	// Serve the gRPC API, which checks callers as the routes do
+	if cfg.GRPC.Enabled {
+		if err := server.setupGRPC(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to initialize gRPC: %w", err)
+		}
+	}
+
+	// Check stored data for broken invariants
+	if err := server.setupIntegrity(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to schedule integrity checks: %w", err)
+	}
+
+	// Expire entries past the retention of their log type
+	if err := server.setupRetention(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to schedule retention cleanup: %w", err)
+	}
+
+	// Keep hourly and daily traffic totals for stats and reports
+	if err := server.setupRollups(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to schedule traffic rollups: %w", err)
+	}
+
+	// Sign the certificates of right-to-erasure requests
+	if err := server.setupErasure(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to load erasure signing key: %w", err)
 	}
 
 	// Setup routes
 	server.setupRoutes()
 
 	// Setup cron jobs
-	server.setupCronJobs()
+	if err := server.setupCronJobs(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to schedule jobs: %w", err)
+	}
 
 	return server, nil
 }
@@ -89,54 +366,241 @@ func (s *Server) setupRoutes() {
 	// API routes
 	api := s.router.PathPrefix("/api/v1").Subrouter()
 	
+	// Build information and capabilities
+	api.HandleFunc("/meta", s.getMetaHandler).Methods("GET")
+	api.HandleFunc("/openapi.json", s.openAPIHandler).Methods("GET")
+
+	// Sign-in with the OpenID provider
+	if s.auth != nil {
+		s.router.HandleFunc("/auth/login", s.loginHandler).Methods("GET")
+		s.router.HandleFunc("/auth/callback", s.callbackHandler).Methods("GET")
+		s.router.HandleFunc("/auth/logout", s.logoutHandler).Methods("GET", "POST")
+		api.HandleFunc("/auth/me", s.currentUserHandler).Methods("GET")
+
+		// Users and their roles
+		api.HandleFunc("/roles", s.listRolesHandler).Methods("GET")
+		api.HandleFunc("/users", s.listUsersHandler).Methods("GET")
+		api.HandleFunc("/users/{subject}", s.saveUserHandler).Methods("PUT")
+		api.HandleFunc("/users/{subject}", s.deleteUserHandler).Methods("DELETE")
+	}
+	
 	// Log processing
-	api.HandleFunc("/logs/upload", s.uploadLogHandler).Methods("POST")
-	api.HandleFunc("/logs", s.getLogsHandler).Methods("GET")
-	api.HandleFunc("/logs/stats", s.getLogStatsHandler).Methods("GET")
+	api.HandleFunc("/logs/upload", s.limitIngest(s.shedLoad(s.uploadLogHandler))).Methods("POST")
+	api.HandleFunc("/logs/uploads", s.limitIngest(s.shedLoad(s.createUploadHandler))).Methods("POST")
+	api.HandleFunc("/logs/uploads/{id}", s.getUploadHandler).Methods("GET")
+	api.HandleFunc("/logs/uploads/{id}", s.limitIngest(s.shedLoad(s.appendUploadHandler))).Methods("PUT")
+	api.HandleFunc("/logs/uploads/{id}", s.deleteUploadHandler).Methods("DELETE")
+	api.HandleFunc("/logs/uploads/{id}/complete", s.limitIngest(s.shedLoad(s.completeUploadHandler))).Methods("POST")
+	api.HandleFunc("/logs/ingest/s3", s.limitIngest(s.shedLoad(s.ingestS3Handler))).Methods("POST")
+	api.HandleFunc("/logs/ingest/url", s.limitIngest(s.shedLoad(s.ingestURLHandler))).Methods("POST")
+	api.HandleFunc("/logs/ingest/jobs/{id}", s.getIngestJobHandler).Methods("GET")
+	api.HandleFunc("/logs/ingest/jobs/{id}/events", s.ingestJobEventsHandler).Methods("GET")
+	api.HandleFunc("/logs", s.limitQuery(s.getLogsHandler)).Methods("GET")
+	api.HandleFunc("/logs", s.deleteLogsHandler).Methods("DELETE")
+	api.HandleFunc("/logs/stats", s.limitQuery(s.getLogStatsHandler)).Methods("GET")
+	api.HandleFunc("/logs/stats/methods", s.limitQuery(s.getMethodStatsHandler)).Methods("GET")
+	api.HandleFunc("/logs/stats/latency", s.limitQuery(s.getLatencyStatsHandler)).Methods("GET")
+	api.HandleFunc("/logs/patterns", s.limitQuery(s.getLogPatternsHandler)).Methods("GET")
+	api.HandleFunc("/logs/aggregate", s.limitQuery(s.aggregateLogsHandler)).Methods("GET")
+	api.HandleFunc("/logs/timeseries", s.limitQuery(s.timeseriesHandler)).Methods("GET")
+	api.HandleFunc("/logs/tail", s.limitQuery(s.tailLogsHandler)).Methods("GET")
 	
 	// Reports
 	api.HandleFunc("/reports/generate", s.generateReportHandler).Methods("POST")
+	api.HandleFunc("/reports/jobs/{id}", s.getReportJobHandler).Methods("GET")
+	api.HandleFunc("/reports/jobs/{id}/events", s.reportJobEventsHandler).Methods("GET")
+	api.HandleFunc("/reports/robots", s.generateCrawlReportHandler).Methods("POST")
+	api.HandleFunc("/reports/correlation", s.generateCorrelationReportHandler).Methods("POST")
+	api.HandleFunc("/reports/comparison", s.generateComparisonReportHandler).Methods("POST")
+	api.HandleFunc("/reports/security", s.generateSecurityReportHandler).Methods("POST")
+	api.HandleFunc("/reports/compliance", s.generateComplianceReportHandler).Methods("POST")
+	api.HandleFunc("/reports/compliance", s.listCompliancePacksHandler).Methods("GET")
+	api.HandleFunc("/reports/compliance/{period}/verify", s.verifyCompliancePackHandler).Methods("GET")
+	api.HandleFunc("/compliance/erasures", s.requestErasureHandler).Methods("POST")
+	api.HandleFunc("/compliance/erasures/public-key", s.getErasurePublicKeyHandler).Methods("GET")
+	api.HandleFunc("/compliance/erasures/verify", s.verifyErasureHandler).Methods("POST")
+	api.HandleFunc("/compliance/erasures/{id}", s.getErasureHandler).Methods("GET")
+	api.HandleFunc("/reports/metrics", s.reportMetricsHandler).Methods("GET")
+	api.HandleFunc("/reports/templates", s.listReportTemplatesHandler).Methods("GET")
+	api.HandleFunc("/reports/templates/{report_type}/{name}", s.getReportTemplateHandler).Methods("GET")
+	api.HandleFunc("/reports/templates/{report_type}/{name}", s.saveReportTemplateHandler).Methods("PUT")
+	api.HandleFunc("/reports/templates/{report_type}/{name}", s.deleteReportTemplateHandler).Methods("DELETE")
+	api.HandleFunc("/searches", s.listSavedSearchesHandler).Methods("GET")
+	api.HandleFunc("/searches/{name}", s.getSavedSearchHandler).Methods("GET")
+	api.HandleFunc("/searches/{name}", s.saveSavedSearchHandler).Methods("PUT")
+	api.HandleFunc("/searches/{name}", s.deleteSavedSearchHandler).Methods("DELETE")
 	api.HandleFunc("/reports", s.listReportsHandler).Methods("GET")
 	api.HandleFunc("/reports/{id}", s.downloadReportHandler).Methods("GET")
+	api.HandleFunc("/reports/{id}/bundle", s.downloadReportBundleHandler).Methods("GET")
 	
 	// Database stats
-	api.HandleFunc("/stats", s.getDatabaseStatsHandler).Methods("GET")
+	api.HandleFunc("/stats", s.limitQuery(s.getDatabaseStatsHandler)).Methods("GET")
+
+	// Alerting
+	api.HandleFunc("/alerts/rules", s.listAlertRulesHandler).Methods("GET")
+	api.HandleFunc("/alerts/rules", s.createAlertRuleHandler).Methods("POST")
+	api.HandleFunc("/alerts/rules/{id}", s.updateAlertRuleHandler).Methods("PUT")
+	api.HandleFunc("/alerts/rules/{id}/evaluations", s.getRuleEvaluationsHandler).Methods("GET")
+	api.HandleFunc("/alerts/replay", s.replayAlertRulesHandler).Methods("POST")
+	api.HandleFunc("/alerts/replay/{id}", s.getAlertReplayHandler).Methods("GET")
+	api.HandleFunc("/alerts/history", s.getAlertHistoryHandler).Methods("GET")
+	api.HandleFunc("/alerts/history/{id}/acknowledge", s.acknowledgeAlertHandler).Methods("POST")
+	api.HandleFunc("/alerts/active", s.getActiveAlertsHandler).Methods("GET")
+	api.HandleFunc("/alerts/slack/actions", s.slackActionsHandler).Methods("POST")
+	api.HandleFunc("/alerts/channels", s.listNotificationChannelsHandler).Methods("GET")
+	api.HandleFunc("/alerts/channels/{name}/test-render", s.testRenderChannelHandler).Methods("POST")
+
+	// Maintenance windows
+	api.HandleFunc("/maintenance", s.listMaintenanceWindowsHandler).Methods("GET")
+	api.HandleFunc("/maintenance", s.createMaintenanceWindowHandler).Methods("POST")
+	api.HandleFunc("/maintenance/{id}", s.deleteMaintenanceWindowHandler).Methods("DELETE")
+
+	// Latency budgets
+	api.HandleFunc("/latency-budgets", s.listLatencyBudgetsHandler).Methods("GET")
+	api.HandleFunc("/latency-budgets", s.createLatencyBudgetHandler).Methods("POST")
+	api.HandleFunc("/latency-budgets/status", s.latencyBudgetStatusHandler).Methods("GET")
+	api.HandleFunc("/latency-budgets/{id}", s.deleteLatencyBudgetHandler).Methods("DELETE")
+
+	// Data retention
+	api.HandleFunc("/retention", s.getRetentionHandler).Methods("GET")
+	api.HandleFunc("/retention/cleanup", s.runRetentionCleanupHandler).Methods("POST")
+	api.HandleFunc("/retention/{log_type}", s.setRetentionPolicyHandler).Methods("PUT")
+	api.HandleFunc("/retention/{log_type}", s.deleteRetentionPolicyHandler).Methods("DELETE")
+
+	// Cold storage archives of expired entries
+	api.HandleFunc("/archives", s.listArchivesHandler).Methods("GET")
+	api.HandleFunc("/archives/rehydrate", s.rehydrateArchiveHandler).Methods("POST")
+	api.HandleFunc("/archives/rehydrate/{id}", s.getRehydrationHandler).Methods("GET")
+	api.HandleFunc("/archives/{id}", s.getArchiveHandler).Methods("GET")
+
+	// Rebuilding traffic rollups
+	api.HandleFunc("/rollups/rebuild", s.rebuildRollupsHandler).Methods("POST")
+	api.HandleFunc("/rollups/rebuild/{id}", s.getRollupRebuildHandler).Methods("GET")
+
+	// Data integrity
+	api.HandleFunc("/integrity", s.getIntegrityHandler).Methods("GET")
+	api.HandleFunc("/integrity/check", s.runIntegrityCheckHandler).Methods("POST")
+
+	// Security
+	api.HandleFunc("/security/scores", s.limitQuery(s.getIPScoresHandler)).Methods("GET")
+	api.HandleFunc("/security/events/export", s.limitQuery(s.exportSecurityEventsHandler)).Methods("GET")
+
+	// SIEM forwarding
+	api.HandleFunc("/forwarding", s.getForwardingStatsHandler).Methods("GET")
+
+	// Audit log
+	api.HandleFunc("/audit/export", s.exportAuditLogHandler).Methods("GET")
+	api.HandleFunc("/audit/verify", s.verifyAuditLogHandler).Methods("GET")
+	api.HandleFunc("/audit/verify", s.verifyAuditExportHandler).Methods("POST")
+
+	// Configuration history
+	api.HandleFunc("/config/versions", s.listConfigVersionsHandler).Methods("GET")
+	api.HandleFunc("/config/versions/diff", s.diffConfigVersionsHandler).Methods("GET")
+	api.HandleFunc("/config/versions/rollback", s.rollbackConfigHandler).Methods("POST")
+
+	// Administration
+	api.HandleFunc("/admin/config", s.getEffectiveConfigHandler).Methods("GET")
+	api.HandleFunc("/admin/features", s.getFeaturesHandler).Methods("GET")
+	api.HandleFunc("/admin/features/{flag}", s.setFeatureOverrideHandler).Methods("PUT")
+	api.HandleFunc("/admin/features/{flag}", s.deleteFeatureOverrideHandler).Methods("DELETE")
+	api.HandleFunc("/admin/generate-sample-data", s.generateSampleDataHandler).Methods("POST")
+	api.HandleFunc("/admin/plugins", s.listPluginsHandler).Methods("GET")
+	api.HandleFunc("/admin/plugins/{name}/restart", s.restartPluginHandler).Methods("POST")
+	api.HandleFunc("/admin/backup", s.backupHandler).Methods("POST")
+	api.HandleFunc("/admin/restore", s.restoreHandler).Methods("POST")
 	
+	// GraphQL queries over logs, their facets and stats
+	if s.config.GraphQL.Enabled {
+		s.router.HandleFunc("/api/graphql", s.limitQuery(s.graphqlHandler)).Methods("GET", "POST")
+		s.router.HandleFunc("/api/graphql/schema", s.graphqlSchemaHandler).Methods("GET")
+	}
+
+	// Loki push API, for Promtail and other Loki clients
+	if s.config.Ingest.Loki.Enabled {
+		s.router.HandleFunc("/loki/api/v1/push", s.limitIngest(s.shedLoad(s.lokiPushHandler))).Methods("POST")
+	}
+
+	// OTLP/HTTP logs receiver, for OpenTelemetry SDKs and collectors
+	if s.config.Ingest.OTLP.Enabled {
+		s.router.HandleFunc("/v1/logs", s.limitIngest(s.shedLoad(s.otlpLogsHandler))).Methods("POST")
+	}
+
+	// Splunk HTTP Event Collector, for forwarders and logging drivers
+	// configured for HEC
+	if s.config.Ingest.HEC.Enabled {
+		for _, path := range []string{"/services/collector", "/services/collector/event", "/services/collector/event/1.0"} {
+			s.router.HandleFunc(path, s.limitIngest(s.shedLoad(s.hecEventHandler))).Methods("POST")
+		}
+		s.router.HandleFunc("/services/collector/health", s.hecHealthHandler).Methods("GET")
+	}
+
 	// Static files (reports)
-	s.router.PathPrefix("/reports/").Handler(http.StripPrefix("/reports/", http.FileServer(http.Dir("reports"))))
+	s.router.PathPrefix("/reports/").HandlerFunc(s.serveReportFileHandler).Methods("GET", "HEAD")
 	
 	// Middleware
 	s.router.Use(s.loggingMiddleware)
 	s.router.Use(s.corsMiddleware)
+	s.router.Use(s.authMiddleware)
+	s.router.Use(s.authorizeMiddleware)
 }
 
-func (s *Server) setupCronJobs() {
+// Schedules of the built-in report runs. The scheduler takes a seconds
+// field first.
+const (
+	dailyReportSchedule  = "0 0 2 * * *"
+	weeklyReportSchedule = "0 0 3 * * 0"
+)
+
+func (s *Server) setupCronJobs() error {
 	// Daily report generation at 2 AM
-	s.cron.AddFunc("0 2 * * *", func() {
+	if _, err := s.cron.AddFunc(dailyReportSchedule, func() {
 		s.logger.Info("Starting scheduled daily report generation")
 		if err := s.generateDailyReport(); err != nil {
 			s.logger.Errorf("Failed to generate daily report: %v", err)
 		}
-	})
+	}); err != nil {
+		return fmt.Errorf("invalid daily report schedule: %w", err)
+	}
 
 	// Weekly summary report every Sunday at 3 AM
-	s.cron.AddFunc("0 3 * * 0", func() {
+	if _, err := s.cron.AddFunc(weeklyReportSchedule, func() {
 		s.logger.Info("Starting scheduled weekly report generation")
 		if err := s.generateWeeklyReport(); err != nil {
 			s.logger.Errorf("Failed to generate weekly report: %v", err)
 		}
-	})
+	}); err != nil {
+		return fmt.Errorf("invalid weekly report schedule: %w", err)
+	}
 
-	// Database cleanup every month (remove logs older than 90 days)
-	s.cron.AddFunc("0 4 1 * *", func() {
-		s.logger.Info("Starting scheduled database cleanup")
-		if err := s.cleanupOldLogs(); err != nil {
-			s.logger.Errorf("Failed to cleanup old logs: %v", err)
+	// Archive the previous month's compliance pack on the 1st at 5 AM
+	if s.config.Compliance.Enabled {
+		if _, err := s.cron.AddFunc("0 0 5 1 * *", func() {
+			period := time.Now().In(s.complianceLocation()).AddDate(0, -1, 0).Format("2006-01")
+			s.logger.Infof("Starting scheduled compliance pack generation for %s", period)
+			if _, _, err := s.generateCompliancePack(period, auditActorSystem); err != nil {
+				s.logger.Errorf("Failed to generate compliance pack: %v", err)
+			}
+		}); err != nil {
+			return fmt.Errorf("invalid compliance pack schedule: %w", err)
 		}
-	})
+	}
+
+	// Remove chunked uploads abandoned by their clients, hourly
+	if _, err := s.cron.AddFunc("0 0 * * * *", func() {
+		cutoff := time.Now().Add(-time.Duration(s.config.Ingest.Uploads.ExpireAfter) * time.Hour)
+		removed, err := s.uploads.Expire(cutoff)
+		if err != nil {
+			s.logger.Errorf("Failed to expire uploads: %v", err)
+		} else if removed > 0 {
+			s.logger.Infof("Removed %d expired uploads", removed)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid upload expiry schedule: %w", err)
+	}
 
 	s.cron.Start()
 	s.logger.Info("Cron scheduler started")
+	return nil
 }
 
 // HTTP Handlers
@@ -144,7 +608,19 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	health := map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"version":   version,
+	}
+
+	// Ingestion stays paused while overloaded, but the server is healthy
+	if s.guard != nil {
+		health["load_shedding"] = s.guard.Status()
+	}
+
+	// The in-process cache stands in while Redis fails
+	if fallback, ok := s.cache.(*cache.Fallback); ok {
+		if err := fallback.Err(); err != nil {
+			health["cache_error"] = err.Error()
+		}
 	}
 
 	// Check database health
@@ -187,6 +663,7 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
         .status { padding: 10px; border-radius: 4px; margin-bottom: 20px; }
         .status.healthy { background: #d4edda; color: #155724; border: 1px solid #c3e6cb; }
         .status.unhealthy { background: #f8d7da; color: #721c24; border: 1px solid #f5c6cb; }
+        .build { text-align: center; color: #888; font-size: 13px; }
     </style>
 </head>
 <body>
@@ -199,15 +676,26 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
             <h2>📊 Log Upload</h2>
             <form id="uploadForm">
                 <div class="form-group">
-                    <label for="logfile">Select Log File:</label>
-                    <input type="file" id="logfile" name="logfile" accept=".log,.txt" required>
+                    <label for="logfile">Select Log Files:</label>
+                    <input type="file" id="logfile" name="logfile" accept=".log,.txt,.gz" multiple required>
                 </div>
                 <div class="form-group">
                     <label for="logType">Log Type:</label>
                     <select id="logType" name="logType">
                         <option value="apache">Apache</option>
                         <option value="nginx">Nginx</option>
+                        <option value="envoy">Envoy</option>
+                        <option value="traefik">Traefik</option>
                         <option value="generic">Generic</option>
+                        <option value="logfmt">logfmt</option>
+                        <option value="ltsv">LTSV</option>
+                        <option value="cef">CEF (ArcSight)</option>
+                        <option value="leef">LEEF (QRadar)</option>
+                        <option value="docker">Docker JSON</option>
+                        <option value="kubernetes">Kubernetes</option>
+                        <option value="windows_event">Windows Event Log (XML)</option>
+                        <option value="aws_vpc_flow">AWS VPC Flow Logs</option>
+                        <option value="syslog">Syslog</option>
                     </select>
                 </div>
                 <button type="submit">Upload & Process Log</button>
@@ -233,6 +721,10 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
                     <strong>🗄️ Database Stats</strong><br>
                     GET /api/v1/stats
                 </a>
+                <a href="/api/v1/meta" class="api-link">
+                    <strong>🧭 Capabilities</strong><br>
+                    GET /api/v1/meta
+                </a>
                 <a href="/health" class="api-link">
                     <strong>💚 Health Check</strong><br>
                     GET /health
@@ -249,6 +741,8 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
                 <li><strong>Monitor Health:</strong> Check /health for system status</li>
             </ol>
         </div>
+        
+        <p id="build" class="build"></p>
     </div>
     
     <script>
@@ -271,6 +765,31 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
                 statusDiv.innerHTML = '❌ Server Status: <strong>Unreachable</strong> - Cannot connect to server';
             });
         
+        // Offer the log types this deployment parses, including custom
+        // formats, and show its build
+        fetch('/api/v1/meta')
+            .then(response => response.json())
+            .then(meta => {
+                const select = document.getElementById('logType');
+                const labels = {};
+                for (const option of select.options) {
+                    labels[option.value] = option.text;
+                }
+                select.innerHTML = '';
+                for (const logType of meta.log_types) {
+                    select.add(new Option(labels[logType] || logType, logType));
+                }
+                let build = 'Version ' + meta.version;
+                if (meta.commit) {
+                    build += ' (' + meta.commit.substring(0, 12) + ')';
+                }
+                if (meta.build_date) {
+                    build += ', built ' + meta.build_date;
+                }
+                document.getElementById('build').textContent = build + ' · ' + meta.database_type + ' database';
+            })
+            .catch(() => {});
+        
         // Handle file upload
         document.getElementById('uploadForm').addEventListener('submit', function(e) {
             e.preventDefault();
@@ -284,14 +803,16 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
                 return;
             }
             
-            formData.append('logfile', fileInput.files[0]);
+            for (const file of fileInput.files) {
+                formData.append('logfile', file);
+            }
             formData.append('log_type', logType);
             
             fetch('/api/v1/logs/upload', {
                 method: 'POST',
                 body: formData
             })
-            .then(response => response.json())
+            .then(response => response.ok ? response.json() : response.text().then(text => { throw new Error(text); }))
             .then(data => {
                 alert('Log uploaded successfully! ' + data.message);
                 fileInput.value = '';
@@ -307,6 +828,9 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(html))
 }
 
+// uploadLogHandler accepts one or more logfile parts, each processed by
+// its own job. log_type applies to every part unless a log_type[<filename>]
+// field overrides it for one. max_error_rate applies to every part.
 func (s *Server) uploadLogHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
@@ -314,53 +838,196 @@ func (s *Server) uploadLogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, header, err := r.FormFile("logfile")
-	if err != nil {
+	headers := r.MultipartForm.File["logfile"]
+	if len(headers) == 0 {
 		http.Error(w, "No log file provided", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	logType := r.FormValue("log_type")
-	if logType == "" {
-		logType = "generic"
+	defaultType := r.FormValue("log_type")
+	if defaultType == "" {
+		defaultType = "generic"
 	}
 
-	// Validate log type
-	if logType != "apache" && logType != "nginx" && logType != "generic" {
-		http.Error(w, "Invalid log type. Must be apache, nginx, or generic", http.StatusBadRequest)
+	var rate *float64
+	if raw := r.FormValue("max_error_rate"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			http.Error(w, "max_error_rate must be a number", http.StatusBadRequest)
+			return
+		}
+		rate = &parsed
+	}
+	maxErrorRate, err := s.uploadErrorRate(rate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	s.logger.Infof("Processing log file: %s, type: %s", header.Filename, logType)
+	// Every part is checked before any is processed, so a request with an
+	// invalid or rejected part processes none of them. Parts are closed
+	// by their jobs; those spooled to disk stay readable after the request
+	// removes them.
+	var parts []*uploadedFile
+	closeAll := func() {
+		for _, part := range parts {
+			part.file.Close()
+		}
+	}
+	seen := make(map[string]string)
+	for _, header := range headers {
+		logType := r.FormValue("log_type[" + header.Filename + "]")
+		if logType == "" {
+			logType = defaultType
+		}
+		if !s.processor.SupportsLogType(logType) {
+			closeAll()
+			http.Error(w, fmt.Sprintf("Invalid log type for %s. Must be one of: %s", header.Filename, strings.Join(s.processor.LogTypes(), ", ")), http.StatusBadRequest)
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			closeAll()
+			s.logger.Errorf("Failed to open uploaded file %s: %v", header.Filename, err)
+			http.Error(w, "Failed to read log file", http.StatusInternalServerError)
+			return
+		}
+		part := &uploadedFile{file: file, filename: header.Filename, logType: logType, maxErrorRate: maxErrorRate}
+		parts = append(parts, part)
 
-	// Process the log file
-	go func() {
-		if err := s.processLogFile(file, logType); err != nil {
-			s.logger.Errorf("Failed to process log file: %v", err)
+		if part.sha256, part.size, err = fileDigest(file); err != nil {
+			closeAll()
+			s.logger.Errorf("Failed to read log file %s: %v", header.Filename, err)
+			http.Error(w, "Failed to read log file", http.StatusInternalServerError)
+			return
 		}
-	}()
+		if part.warning, err = s.duplicateWarning(header.Filename, part.sha256); err != nil {
+			closeAll()
+			s.logger.Errorf("Failed to look up ingested file: %v", err)
+			http.Error(w, "Failed to check for duplicate file", http.StatusInternalServerError)
+			return
+		}
+		if first, ok := seen[part.sha256]; ok && part.warning == "" && s.config.Ingest.DuplicateFiles != "allow" {
+			part.warning = fmt.Sprintf("%s has the same content as %s in this upload", header.Filename, first)
+		}
+		seen[part.sha256] = header.Filename
+
+		if part.warning != "" && s.config.Ingest.DuplicateFiles == "reject" {
+			closeAll()
+			http.Error(w, part.warning, http.StatusConflict)
+			return
+		}
+	}
+
+	s.startStoring()
+	jobIDs := []string{}
+	files := make([]map[string]interface{}, 0, len(parts))
+	for _, part := range parts {
+		result := map[string]interface{}{
+			"filename": part.filename,
+			"log_type": part.logType,
+			"sha256":   part.sha256,
+		}
+		files = append(files, result)
+
+		if part.warning != "" {
+			part.file.Close()
+			s.logger.Warnf("Skipping duplicate upload: %s", part.warning)
+			result["status"] = "skipped"
+			result["warning"] = part.warning
+			continue
+		}
+
+		s.logger.Infof("Processing log file: %s, type: %s", part.filename, part.logType)
+		// Jobs outlive the request and stop when the server shuts down
+		job := s.jobs.Start(s.ctx, "upload", map[string]interface{}{
+			"filename": part.filename,
+			"log_type": part.logType,
+			"sha256":   part.sha256,
+		}, s.processUploadedFile(part))
+		id := job.Snapshot().ID
+		jobIDs = append(jobIDs, id)
+
+		s.recordAudit(audit.ActionLogsUploaded, requestActor(r), part.filename, map[string]interface{}{
+			"log_type": part.logType,
+			"size":     part.size,
+			"sha256":   part.sha256,
+			"job_id":   id,
+		})
+
+		result["status"] = "processing"
+		result["job_id"] = id
+		result["status_url"] = "/api/v1/logs/ingest/jobs/" + id
+	}
 
 	response := map[string]interface{}{
-		"message":   "Log file uploaded successfully",
-		"filename":  header.Filename,
-		"log_type":  logType,
-		"status":    "processing",
+		"message": fmt.Sprintf("%d of %d log files queued for processing", len(jobIDs), len(parts)),
+		"job_ids": jobIDs,
+		"files":   files,
+	}
+	// A single file's fields are also given at the top level, as before
+	// uploads could hold several
+	if len(files) == 1 {
+		for key, value := range files[0] {
+			response[key] = value
+		}
+		response["message"] = "Log file uploaded successfully"
+		if len(jobIDs) == 0 {
+			response["message"] = "Log file was already processed"
+		}
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	w.Header().Set("Content-Type", "application/json")
+	if len(jobIDs) > 0 {
+		w.WriteHeader(http.StatusAccepted)
+	}
 	json.NewEncoder(w).Encode(response)
 }
 
+// logFilterFromQuery reads the conditions of a log filter from query
+// parameters, leaving out those that do not parse
+func logFilterFromQuery(query url.Values) *models.LogFilter {
+	return applyFilterQuery(&models.LogFilter{}, query)
+}
+
+// applyFilterQuery sets the conditions of a filter that query parameters
+// give, keeping the others
+func applyFilterQuery(filter *models.LogFilter, query url.Values) *models.LogFilter {
+	for param, field := range map[string]*string{
+		"log_type":  &filter.LogType,
+		"source_ip": &filter.SourceIP,
+		"path":      &filter.Path,
+		"method":    &filter.Method,
+	} {
+		if value := query.Get(param); value != "" {
+			*field = value
+		}
+	}
+	if statusCodeStr := query.Get("status_code"); statusCodeStr != "" {
+		if statusCode, err := strconv.Atoi(statusCodeStr); err == nil {
+			filter.StatusCode = &statusCode
+		}
+	}
+	if minStatusCode, err := strconv.Atoi(query.Get("min_status_code")); err == nil {
+		filter.MinStatusCode = minStatusCode
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		filter.StartTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		filter.EndTime = &t
+	}
+	if query.Has("exact_path") {
+		filter.ExactPath = query.Get("exact_path") == "true"
+	}
+	return filter
+}
+
 func (s *Server) getLogsHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
-	logType := r.URL.Query().Get("log_type")
-	statusCodeStr := r.URL.Query().Get("status_code")
-	sourceIP := r.URL.Query().Get("source_ip")
-	path := r.URL.Query().Get("path")
-	method := r.URL.Query().Get("method")
 
 	limit := 100 // default limit
 	if limitStr != "" {
@@ -376,75 +1043,68 @@ func (s *Server) getLogsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Build query
-	query := "SELECT * FROM log_entries WHERE 1=1"
-	args := []interface{}{}
-	argCount := 0
-
-	if logType != "" {
-		query += " AND log_type = ?"
-		args = append(args, logType)
-		argCount++
-	}
-
-	if statusCodeStr != "" {
-		if statusCode, err := strconv.Atoi(statusCodeStr); err == nil {
-			query += " AND status_code = ?"
-			args = append(args, statusCode)
-			argCount++
+	// A saved search gives the conditions the parameters do not
+	filter := &models.LogFilter{}
+	if name := r.URL.Query().Get("saved"); name != "" {
+		search, ok := s.requestSavedSearch(w, r, name)
+		if !ok {
+			return
 		}
+		filter = &search.Filter
 	}
-
-	if sourceIP != "" {
-		query += " AND source_ip = ?"
-		args = append(args, sourceIP)
-		argCount++
+	applyFilterQuery(filter, r.URL.Query())
+	filter.Limit = limit
+	filter.Offset = offset
+	if err := parseSort(r.URL.Query().Get("sort"), filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	if path != "" {
-		query += " AND path LIKE ?"
-		args = append(args, "%"+path+"%")
-		argCount++
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	if method != "" {
-		query += " AND method = ?"
-		args = append(args, method)
-		argCount++
+	// A cursor pages by position, which stays fast however deep the page
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		if !sortedByTime(filter) {
+			http.Error(w, "Cursors page entries sorted by timestamp; use offset with other sorts", http.StatusBadRequest)
+			return
+		}
+		if err := decodeCursor(cursor, filter); err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		filter.Offset, offset = 0, 0
 	}
 
-	query += " ORDER BY timestamp DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
-
-	// Execute query
-	rows, err := s.db.DB.Query(query, args...)
+	page, err := s.findPage(r.Context(), filter)
 	if err != nil {
 		s.logger.Errorf("Failed to query logs: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var logs []*models.LogEntry
-	for rows.Next() {
-		var entry models.LogEntry
-		if err := rows.Scan(
-			&entry.ID, &entry.Timestamp, &entry.LogType, &entry.SourceIP,
-			&entry.Method, &entry.Path, &entry.StatusCode, &entry.ResponseSize,
-			&entry.UserAgent, &entry.Referer, &entry.ProcessingTime,
-			&entry.RawLog, &entry.Metadata, &entry.CreatedAt, &entry.UpdatedAt,
-		); err != nil {
-			s.logger.Errorf("Failed to scan log entry: %v", err)
-			continue
-		}
-		logs = append(logs, &entry)
+	total, err := s.db.Count(r.Context(), filter)
+	if err != nil {
+		s.logger.Errorf("Failed to count logs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logs, err := project(page.Logs, fields)
+	if err != nil {
+		s.logger.Errorf("Failed to project logs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
 	response := map[string]interface{}{
-		"logs":   logs,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(logs),
+		"logs":        logs,
+		"limit":       limit,
+		"offset":      offset,
+		"count":       len(page.Logs),
+		"total_count": total,
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -453,7 +1113,7 @@ func (s *Server) getLogsHandler(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) getLogStatsHandler(w http.ResponseWriter, r *http.Request) {
 	// Get basic stats from database
-	stats, err := s.db.GetStats()
+	stats, err := s.databaseStats(r.Context())
 	if err != nil {
 		s.logger.Errorf("Failed to get database stats: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -472,28 +1132,103 @@ func (s *Server) getLogStatsHandler(w http.ResponseWriter, r *http.Request) {
 			"generic_processed": procStats.GenericProcessed,
 			"errors":           procStats.Errors,
 			"start_time":       procStats.StartTime,
+			"pipeline":         s.pipelineMetrics(),
 		},
 	}
 
+	// Traffic totals come from the rollups rather than every entry
+	if s.config.Rollups.Enabled {
+		traffic, err := s.trafficStats(r.Context(), r.URL.Query())
+		if errors.Is(err, errBadStatsQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			s.logger.Errorf("Failed to get traffic stats: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response["traffic"] = traffic
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// reportRequest is the body of POST /api/v1/reports/generate
+type reportRequest struct {
+	ReportName string           `json:"report_name"`
+	LogType    string           `json:"log_type"`
+	StartTime  *time.Time       `json:"start_time"`
+	EndTime    *time.Time       `json:"end_time"`
+	Format     string           `json:"format"` // html, csv, both, xlsx, json, ndjson, markdown
+	ReportType string           `json:"report_type"` // standard, errors
+	Filters    *models.LogFilter `json:"filters"`
+	// Stream exports every matching entry to the CSV or NDJSON
+	// file, and Compress gzips it
+	Stream   bool `json:"stream"`
+	Compress bool `json:"compress"`
+	// Template names an uploaded template of the report type to
+	// render the HTML file with instead of the built-in one
+	Template string `json:"template"`
+	// SavedSearch names a saved search of the requester to use as
+	// the filters
+	SavedSearch string `json:"saved_search"`
+}
+
 func (s *Server) generateReportHandler(w http.ResponseWriter, r *http.Request) {
-	var request struct {
-		ReportName string           `json:"report_name"`
-		LogType    string           `json:"log_type"`
-		StartTime  *time.Time       `json:"start_time"`
-		EndTime    *time.Time       `json:"end_time"`
-		Format     string           `json:"format"` // html, csv, both
-		Filters    *models.LogFilter `json:"filters"`
-	}
+	var request reportRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	files, err := reportFiles(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.SavedSearch != "" {
+		if request.Filters != nil {
+			http.Error(w, "Give either filters or saved_search, not both", http.StatusBadRequest)
+			return
+		}
+		search, ok := s.requestSavedSearch(w, r, request.SavedSearch)
+		if !ok {
+			return
+		}
+		request.Filters = &search.Filter
+	}
+
+	var custom *template.Template
+	if request.Template != "" {
+		if !slices.Contains(files, "html") && !slices.Contains(files, "errors") {
+			http.Error(w, "template needs a format with an HTML file: html or both", http.StatusBadRequest)
+			return
+		}
+		var ok bool
+		if custom, ok = s.requestTemplate(w, templateReportType(request.ReportType), request.Template); !ok {
+			return
+		}
+	}
+
+	id := s.startReportJob(request, files, custom).Snapshot().ID
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/reports/jobs/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// reportFiles fills in the defaults of a report request and returns the
+// files of its type and format, or why the request is invalid
+func reportFiles(request *reportRequest) ([]string, error) {
 	if request.ReportName == "" {
 		request.ReportName = "log_analysis"
 	}
@@ -502,76 +1237,133 @@ func (s *Server) generateReportHandler(w http.ResponseWriter, r *http.Request) {
 		request.Format = "both"
 	}
 
-	// Get logs based on filters
-	logs, err := s.getLogsForReport(request.Filters)
-	if err != nil {
-		s.logger.Errorf("Failed to get logs for report: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+	formats, ok := reportTypes[request.ReportType]
+	if !ok {
+		return nil, errors.New("Invalid report_type. Must be one of: standard, errors")
+	}
+	files, ok := formats[request.Format]
+	if !ok && request.ReportType == "errors" {
+		return nil, errors.New("Invalid format for an error report. Must be one of: html, csv, both")
+	}
+	if !ok {
+		return nil, errors.New("Invalid format. Must be one of: html, csv, both, xlsx, json, ndjson, markdown")
 	}
 
-	// Prepare report data
-	reportData := &reporting.ReportData{
-		Title:      request.ReportName,
-		GeneratedAt: time.Now(),
-		LogEntries:  logs,
-		Filters:     request.Filters,
+	if (request.Stream || request.Compress) && (request.ReportType == "errors" || !slices.Contains(files, "csv") && !slices.Contains(files, "ndjson")) {
+		return nil, errors.New("stream and compress need a standard report with format csv, both or ndjson")
 	}
+	return files, nil
+}
+
+// startReportJob generates a report's files, rendering HTML with the
+// custom template if there is one, in a background job
+func (s *Server) startReportJob(request reportRequest, files []string, custom *template.Template) *jobs.Job {
+	export := csvExport{stream: request.Stream, filter: request.Filters, compress: request.Compress}
 
-	// Generate reports
-	var generatedFiles []string
-	if request.Format == "html" || request.Format == "both" {
-		htmlFile, err := s.reporter.GenerateHTMLReport(reportData, request.ReportName)
+	// Error reports only read 4xx and 5xx responses
+	filters := request.Filters
+	if request.ReportType == "errors" {
+		filters = errorFilter(request.Filters)
+	}
+
+	details := map[string]interface{}{
+		"report_name": request.ReportName,
+		"format":      request.Format,
+	}
+	if request.ReportType != "" {
+		details["report_type"] = request.ReportType
+	}
+	if request.Stream {
+		details["stream"] = true
+	}
+	if request.Compress {
+		details["compress"] = true
+	}
+	if request.Template != "" {
+		details["template"] = request.Template
+	}
+	// Jobs outlive the request and stop when the server shuts down
+	return s.jobs.Start(s.ctx, reportJobKind, details, func(ctx context.Context, job *jobs.Job) error {
+		release, err := job.WaitForSlot(ctx, s.reportSlots)
 		if err != nil {
-			s.logger.Errorf("Failed to generate HTML report: %v", err)
-		} else {
-			generatedFiles = append(generatedFiles, htmlFile)
+			return err
 		}
-	}
+		defer release()
+		job.SetTotal(int64(len(files)))
 
-	if request.Format == "csv" || request.Format == "both" {
-		csvFile, err := s.reporter.GenerateCSVReport(reportData, request.ReportName)
+		// Get logs based on filters
+		limit := maxReportEntries
+		if request.ReportType == "errors" {
+			limit = maxErrorReportEntries
+		}
+		logs, truncated, err := s.getLogsForReport(ctx, filters, limit)
 		if err != nil {
-			s.logger.Errorf("Failed to generate CSV report: %v", err)
-		} else {
-			generatedFiles = append(generatedFiles, csvFile)
+			s.logger.Errorf("Failed to get logs for report: %v", err)
+			return fmt.Errorf("failed to get logs for report: %w", err)
 		}
-	}
 
-	response := map[string]interface{}{
-		"message":        "Reports generated successfully",
-		"generated_files": generatedFiles,
-		"format":         request.Format,
-	}
+		// Prepare report data
+		reportData := &reporting.ReportData{
+			Title:       request.ReportName,
+			GeneratedAt: time.Now(),
+			LogEntries:  logs,
+			Truncated:   truncated,
+			Filters:     filters,
+			Template:    custom,
+		}
+		// Totals cover every request, so error reports get the error rate
+		s.attachTraffic(reportData, request.Filters)
+		s.attachMaintenance(reportData)
+		s.attachLatencyBudgets(reportData)
+
+		// Generate reports
+		var generatedFiles []string
+		for _, file := range files {
+			location, err := s.generateReportFile(ctx, reportData, request.ReportName, file, export)
+			if err != nil {
+				s.logger.Errorf("Failed to generate %s report: %v", strings.ToUpper(file), err)
+			} else {
+				generatedFiles = append(generatedFiles, location)
+			}
+			job.Advance(0, err)
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+		result := map[string]interface{}{
+			"generated_files": generatedFiles,
+			"format":          request.Format,
+			"truncated":       truncated,
+		}
+		// The run's files can be downloaded together from its bundle
+		if len(generatedFiles) > 0 {
+			if id, ok := reporting.ReportID(filepath.Base(generatedFiles[0])); ok {
+				result["report_id"] = id
+			}
+		}
+		job.SetResult(result)
+		if len(generatedFiles) == 0 {
+			return errors.New("no report files were generated")
+		}
+		return nil
+	})
 }
 
 func (s *Server) listReportsHandler(w http.ResponseWriter, r *http.Request) {
-	// List available reports from reports directory
-	reportsDir := "reports"
-	files, err := os.ReadDir(reportsDir)
+	// List available reports from the report store
+	files, err := s.reporter.Store().List("")
 	if err != nil {
-		s.logger.Errorf("Failed to read reports directory: %v", err)
+		s.logger.Errorf("Failed to list reports: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	var reports []map[string]interface{}
 	for _, file := range files {
-		if !file.IsDir() {
-			info, err := file.Info()
-			if err != nil {
-				continue
-			}
-
+		if !file.Dir {
 			reports = append(reports, map[string]interface{}{
-				"filename":    file.Name(),
-				"size":        info.Size(),
-				"created_at":  info.ModTime(),
-				"type":        strings.TrimPrefix(filepath.Ext(file.Name()), "."),
+				"filename":    file.Name,
+				"size":        file.Size,
+				"created_at":  file.ModTime,
+				"type":        strings.TrimPrefix(filepath.Ext(file.Name), "."),
 			})
 		}
 	}
@@ -589,21 +1381,11 @@ func (s *Server) downloadReportHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	reportID := vars["id"]
 
-	// Construct file path
-	filePath := filepath.Join("reports", reportID)
-	
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		http.Error(w, "Report not found", http.StatusNotFound)
-		return
-	}
-
-	// Serve the file
-	http.ServeFile(w, r, filePath)
+	s.serveReport(w, r, reportID)
 }
 
 func (s *Server) getDatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
-	stats, err := s.db.GetStats()
+	stats, err := s.databaseStats(r.Context())
 	if err != nil {
 		s.logger.Errorf("Failed to get database stats: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -615,79 +1397,129 @@ func (s *Server) getDatabaseStatsHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // Helper methods
-func (s *Server) processLogFile(file multipart.File, logType string) error {
-	// Reset file pointer
-	if _, err := file.Seek(0, 0); err != nil {
-		return fmt.Errorf("failed to seek file: %w", err)
-	}
 
-	// Process the file
-	if err := s.processor.ProcessFile(file, logType); err != nil {
-		return fmt.Errorf("failed to process file: %w", err)
-	}
+// uploadedFile is one file of a multipart upload
+type uploadedFile struct {
+	file     multipart.File
+	filename string
+	logType  string
+	sha256   string
+	size     int64
+	// maxErrorRate is the percentage of lines failing to parse that fails
+	// the file
+	maxErrorRate float64
+	// warning says why a duplicate file is skipped
+	warning string
+}
 
-	// Store processed logs in database
-	go s.storeProcessedLogs()
+// processUploadedFile runs an uploaded file through the processor
+func (s *Server) processUploadedFile(part *uploadedFile) func(ctx context.Context, job *jobs.Job) error {
+	return func(ctx context.Context, job *jobs.Job) error {
+		defer part.file.Close()
+		if err := s.waitForCapacity(ctx); err != nil {
+			return err
+		}
+		if _, err := part.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek file: %w", err)
+		}
 
-	return nil
+		return s.processUploadedLog(ctx, job, part.file, part.filename, part.logType, part.sha256, part.size, part.maxErrorRate)
+	}
+}
+
+// startStoring runs the consumers of processed entries once, for ingestion
+// that processes continuously or in the background. Each of the configured
+// writers stores a batch at a time; while they all are busy the processor's
+// queue fills and parsing waits for them.
+func (s *Server) startStoring() {
+	s.storing.Do(func() {
+		for i := 0; i < s.config.Processing.Writers; i++ {
+			go s.storeProcessedLogs()
+		}
+	})
 }
 
 func (s *Server) storeProcessedLogs() {
-	for entry := range s.processor.GetProcessedLogs() {
-		if err := s.storeLogEntry(entry); err != nil {
-			s.logger.Errorf("Failed to store log entry: %v", err)
+	logs := s.processor.GetProcessedLogs()
+	for entry := range logs {
+		// Store what is already queued along with it, up to the batch size
+		batch := []*models.LogEntry{entry}
+		limit := int(s.pipeline.batchSize.Load())
+	queued:
+		for len(batch) < limit {
+			select {
+			case next, ok := <-logs:
+				if !ok {
+					break queued
+				}
+				batch = append(batch, next)
+			default:
+				break queued
+			}
+		}
+
+		// Storing filters the batch in place and plugins may replace it, so
+		// uploads are told about the entries as queued
+		queued := append([]*models.LogEntry(nil), batch...)
+		if s.plugins != nil {
+			enriched, err := s.plugins.Enrich(batch)
+			if err != nil {
+				s.logger.Warnf("Failed to enrich log entries: %v", err)
+			}
+			if batch = enriched; len(batch) == 0 {
+				s.storedFiles.stored(queued, true)
+				continue
+			}
+		}
+
+		stored, failed := s.storeBatch(batch)
+		s.storedFiles.stored(queued, failed == 0)
+		for _, entry := range stored {
+			if s.alerts != nil && s.features.EnabledFor(features.StreamAlerts, entry) {
+				s.alerts.Observe(entry)
+			}
+
+			if s.features.EnabledFor(features.Forwarding, entry) {
+				s.forwarder.Enqueue(entry)
+			}
+		}
+		if s.plugins != nil {
+			s.plugins.Export(stored)
 		}
+		s.tail.Publish(stored)
 	}
 }
 
 func (s *Server) storeLogEntry(entry *models.LogEntry) error {
-	query := `
-		INSERT INTO log_entries (
-			timestamp, log_type, source_ip, method, path, status_code,
-			response_size, user_agent, referer, processing_time, raw_log, metadata
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	_, err := s.db.DB.Exec(query,
-		entry.Timestamp, entry.LogType, entry.SourceIP, entry.Method,
-		entry.Path, entry.StatusCode, entry.ResponseSize, entry.UserAgent,
-		entry.Referer, entry.ProcessingTime, entry.RawLog, entry.Metadata,
-	)
-
-	return err
-}
-
-func (s *Server) getLogsForReport(filters *models.LogFilter) ([]*models.LogEntry, error) {
-	// Implementation for getting logs with filters
-	// This is a simplified version - you might want to add more sophisticated filtering
-	query := "SELECT * FROM log_entries ORDER BY timestamp DESC LIMIT 1000"
-	
-	rows, err := s.db.DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var logs []*models.LogEntry
-	for rows.Next() {
-		var entry models.LogEntry
-		if err := rows.Scan(
-			&entry.ID, &entry.Timestamp, &entry.LogType, &entry.SourceIP,
-			&entry.Method, &entry.Path, &entry.StatusCode, &entry.ResponseSize,
-			&entry.UserAgent, &entry.Referer, &entry.ProcessingTime,
-			&entry.RawLog, &entry.Metadata, &entry.CreatedAt, &entry.UpdatedAt,
-		); err != nil {
-			continue
-		}
-		logs = append(logs, &entry)
-	}
+	return s.db.InsertLogEntry(entry)
+}
 
-	return logs, nil
+// maxReportEntries bounds how many entries a report covers, and
+// maxErrorReportEntries how many errors an error report analyzes
+const (
+	maxReportEntries      = 1000
+	maxErrorReportEntries = 100000
+)
+
+// getLogsForReport reads the most recent entries matching filters, at most
+// limit of them, and reports whether there were more
+func (s *Server) getLogsForReport(ctx context.Context, filters *models.LogFilter, limit int) ([]*models.LogEntry, bool, error) {
+	filter := models.LogFilter{}
+	if filters != nil {
+		filter = *filters
+	}
+	if filter.Limit <= 0 || filter.Limit > limit {
+		filter.Limit = limit
+	}
+	logs, err := s.db.Find(ctx, &filter)
+	return logs, filter.Limit == limit && len(logs) == limit, err
 }
 
 func (s *Server) generateDailyReport() error {
-	// Generate daily report for the previous day
-	yesterday := time.Now().AddDate(0, 0, -1)
+	// Generate daily report for the previous day, in whole hours so the
+	// totals can come from the traffic rollups
+	now := time.Now().Truncate(time.Hour)
+	yesterday := now.AddDate(0, 0, -1)
 	
 	reportData := &reporting.ReportData{
 		Title:       "Daily Log Analysis Report",
@@ -696,25 +1528,33 @@ func (s *Server) generateDailyReport() error {
 	}
 
 	// Get logs for yesterday
-	now := time.Now()
-	logs, err := s.getLogsForReport(&models.LogFilter{
+	filter := &models.LogFilter{
 		StartTime: &yesterday,
 		EndTime:   &now,
-	})
+	}
+	logs, truncated, err := s.getLogsForReport(context.Background(), filter, maxReportEntries)
 	if err != nil {
 		return err
 	}
 
 	reportData.LogEntries = logs
+	reportData.Truncated = truncated
+	s.attachTraffic(reportData, filter)
+	s.attachMaintenance(reportData)
+	s.attachLatencyBudgets(reportData)
 
 	// Generate report
-	_, err = s.reporter.GenerateCombinedReport(reportData, "daily")
-	return err
+	files, err := s.reporter.GenerateCombinedReport(reportData, "daily")
+	if err != nil {
+		return err
+	}
+	s.deliverReport("daily", reportData, files)
+	return s.reporter.SaveKPISnapshot("daily", reportData)
 }
 
 func (s *Server) generateWeeklyReport() error {
 	// Generate weekly report for the previous week
-	now := time.Now()
+	now := time.Now().Truncate(time.Hour)
 	weekStart := now.AddDate(0, 0, -int(now.Weekday())-7)
 	weekEnd := weekStart.AddDate(0, 0, 7)
 
@@ -725,35 +1565,41 @@ func (s *Server) generateWeeklyReport() error {
 	}
 
 	// Get logs for the week
-	logs, err := s.getLogsForReport(&models.LogFilter{
+	filter := &models.LogFilter{
 		StartTime: &weekStart,
 		EndTime:   &weekEnd,
-	})
+	}
+	logs, truncated, err := s.getLogsForReport(context.Background(), filter, maxReportEntries)
 	if err != nil {
 		return err
 	}
 
 	reportData.LogEntries = logs
+	reportData.Truncated = truncated
+	s.attachTraffic(reportData, filter)
+	s.attachMaintenance(reportData)
+	s.attachLatencyBudgets(reportData)
 
 	// Generate report
-	_, err = s.reporter.GenerateCombinedReport(reportData, "weekly")
-	return err
-}
-
-func (s *Server) cleanupOldLogs() error {
-	// Remove logs older than 90 days
-	cutoffDate := time.Now().AddDate(0, 0, -90)
-	
-	query := "DELETE FROM log_entries WHERE timestamp < ?"
-	result, err := s.db.DB.Exec(query, cutoffDate)
+	files, err := s.reporter.GenerateCombinedReport(reportData, "weekly")
 	if err != nil {
 		return err
 	}
 
-	deletedCount, _ := result.RowsAffected()
-	s.logger.Infof("Cleaned up %d old log entries", deletedCount)
-	
-	return nil
+	// Compare with the week before, so the report shows what changed
+	summary, err := s.comparePeriods(s.ctx, weekStart, weekEnd, weekStart.AddDate(0, 0, -7), weekStart, "", reporting.DefaultComparisonOptions())
+	if err == nil {
+		_, err = s.reporter.GenerateComparisonReport(&reporting.ComparisonReportData{
+			Title:       reportData.Title,
+			GeneratedAt: reportData.GeneratedAt,
+			Summary:     summary,
+		}, "weekly")
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to generate weekly comparison report: %v", err)
+	}
+	s.deliverReport("weekly", reportData, files)
+	return s.reporter.SaveKPISnapshot("weekly", reportData)
 }
 
 // Middleware
@@ -808,6 +1654,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap lets http.ResponseController reach the connection, such as to
+// lift the server's deadlines for a long transfer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (s *Server) Start() error {
 	// Create logs directory
 	if err := os.MkdirAll("logs", 0755); err != nil {
@@ -815,8 +1667,10 @@ func (s *Server) Start() error {
 	}
 
 	// Create reports directory
-	if err := os.MkdirAll("reports", 0755); err != nil {
-		return fmt.Errorf("failed to create reports directory: %w", err)
+	if storage := s.config.Reports.Storage; storage.Type == "local" {
+		if err := os.MkdirAll(storage.Dir, 0755); err != nil {
+			return fmt.Errorf("failed to create reports directory: %w", err)
+		}
 	}
 
 	// Start server
@@ -829,12 +1683,24 @@ func (s *Server) Start() error {
 
 	// Start server in goroutine
 	go func() {
+		if s.config.Env != "" {
+			s.logger.Infof("Using %s config profile", s.config.Env)
+		}
 		s.logger.Infof("Starting server on port %s", s.config.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			s.logger.Fatalf("Server failed to start: %v", err)
 		}
 	}()
 
+	if s.grpcServer != nil {
+		go func() {
+			s.logger.Infof("Serving gRPC on %s", s.grpcListener.Addr())
+			if err := s.grpcServer.Serve(s.grpcListener); err != nil {
+				s.logger.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -842,6 +1708,9 @@ func (s *Server) Start() error {
 
 	s.logger.Info("Shutting down server...")
 
+	// Stop background workers
+	s.cancel()
+
 	// Stop cron scheduler
 	ctx := s.cron.Stop()
 	<-ctx.Done()
@@ -853,11 +1722,20 @@ func (s *Server) Start() error {
 	if err := server.Shutdown(ctx); err != nil {
 		s.logger.Errorf("Server forced to shutdown: %v", err)
 	}
+	if s.grpcServer != nil {
+		s.stopGRPC(ctx)
+	}
+
+	// Shut down plugins
+	if s.plugins != nil {
+		s.plugins.Close()
+	}
 
 	// Close database connection
 	if err := s.db.Close(); err != nil {
 		s.logger.Errorf("Failed to close database: %v", err)
 	}
+	s.cache.Close()
 
 	s.logger.Info("Server stopped")
 	return nil
@@ -866,14 +1744,23 @@ func (s *Server) Start() error {
 func main() {
 	// Parse command line flags
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
+	env := flag.String("env", os.Getenv(config.EnvVar), "Config profile merged over the configuration file, e.g. prod")
 	flag.Parse()
 
 	// Load configuration
-	cfg, err := config.LoadConfig(*configFile)
+	cfg, err := config.Load(*configFile, *env)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// The migrate subcommand updates the database schema and exits
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Create and start server
 	server, err := NewServer(cfg)
 	if err != nil {
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the tests from the repository root, where the server finds
+// its report templates
+func TestMain(m *testing.M) {
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// testConfig is the configuration of test servers: the in-memory backend,
+// with every file under dir. Rollups are off, as their backfill at startup
+// would race with the entries tests store.
+const testConfig = `
+database:
+  type: memory
+rollups:
+  enabled: false
+ingest:
+  offsets_file: {dir}/ingest_offsets.json
+  uploads:
+    dir: {dir}/uploads
+  pull:
+    state_file: {dir}/pull_offsets.json
+reports:
+  storage:
+    dir: {dir}/reports
+archive:
+  storage:
+    dir: {dir}/archive
+compliance:
+  erasure:
+    signing_key_file: {dir}/erasure_signing.key
+`
+
+// newTestServer creates a server on the in-memory backend. configure, if
+// not nil, changes the configuration first.
+func newTestServer(t *testing.T, configure func(cfg *config.Config)) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(testConfig, "{dir}", dir)), 0644))
+	cfg, err := config.Load(path, "")
+	require.NoError(t, err)
+	if configure != nil {
+		configure(cfg)
+	}
+
+	s, err := NewServer(cfg)
+	require.NoError(t, err)
+	s.logger.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		s.cancel()
+		<-s.cron.Stop().Done()
+		s.db.Close()
+		s.cache.Close()
+	})
+	return s
+}
+
+// scheduledJob returns the job the server scheduled with spec
+func scheduledJob(t *testing.T, s *Server, spec string) cron.Entry {
+	t.Helper()
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	schedule, err := parser.Parse(spec)
+	require.NoError(t, err)
+	for _, entry := range s.cron.Entries() {
+		if reflect.DeepEqual(entry.Schedule, schedule) {
+			return entry
+		}
+	}
+	t.Fatalf("no job scheduled with %q", spec)
+	return cron.Entry{}
+}
+
+// serve sends a request through the server's routes
+func serve(s *Server, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/gorilla/mux"
+)
+
+// attachMaintenance loads the maintenance windows overlapping the report's
+// log entries so the report can mark them and adjust availability
+func (s *Server) attachMaintenance(data *reporting.ReportData) {
+	if len(data.LogEntries) == 0 {
+		return
+	}
+
+	start, end := data.LogEntries[0].Timestamp, data.LogEntries[0].Timestamp
+	for _, entry := range data.LogEntries {
+		if entry.Timestamp.Before(start) {
+			start = entry.Timestamp
+		}
+		if entry.Timestamp.After(end) {
+			end = entry.Timestamp
+		}
+	}
+
+	windows, err := s.db.GetMaintenanceWindows(start, end.Add(time.Second))
+	if err != nil {
+		s.logger.Errorf("Failed to get maintenance windows for report: %v", err)
+		return
+	}
+	data.Maintenance = windows
+}
+
+// activeSilence returns the maintenance window silencing alerts right now, if any
+func (s *Server) activeSilence() *models.MaintenanceWindow {
+	now := time.Now()
+	windows, err := s.db.GetMaintenanceWindows(now, now)
+	if err != nil {
+		s.logger.Errorf("Failed to check maintenance windows: %v", err)
+		return nil
+	}
+
+	for _, window := range windows {
+		if window.SilenceAlerts {
+			return window
+		}
+	}
+	return nil
+}
+
+func (s *Server) listMaintenanceWindowsHandler(w http.ResponseWriter, r *http.Request) {
+	// Default to windows from the last 30 days onwards
+	start := time.Now().AddDate(0, 0, -30)
+	end := time.Now().AddDate(10, 0, 0)
+	if t, err := time.Parse(time.RFC3339, r.URL.Query().Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, r.URL.Query().Get("end_time")); err == nil {
+		end = t
+	}
+
+	windows, err := s.db.GetMaintenanceWindows(start, end)
+	if err != nil {
+		s.logger.Errorf("Failed to get maintenance windows: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"windows": windows,
+		"count":   len(windows),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) createMaintenanceWindowHandler(w http.ResponseWriter, r *http.Request) {
+	window := models.MaintenanceWindow{SilenceAlerts: true}
+	if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if window.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if window.StartsAt.IsZero() || !window.EndsAt.After(window.StartsAt) {
+		http.Error(w, "ends_at must be after starts_at", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.CreateMaintenanceWindow(&window); err != nil {
+		s.logger.Errorf("Failed to create maintenance window: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionMaintenanceCreated, requestActor(r), fmt.Sprintf("maintenance_window:%d", window.ID), map[string]interface{}{
+		"name":           window.Name,
+		"starts_at":      window.StartsAt,
+		"ends_at":        window.EndsAt,
+		"silence_alerts": window.SilenceAlerts,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(window)
+}
+
+func (s *Server) deleteMaintenanceWindowHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid maintenance window ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := s.db.DeleteMaintenanceWindow(id)
+	if err != nil {
+		s.logger.Errorf("Failed to delete maintenance window: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Maintenance window not found", http.StatusNotFound)
+		return
+	}
+	s.recordAudit(audit.ActionMaintenanceDeleted, requestActor(r), fmt.Sprintf("maintenance_window:%d", id), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/sampledata"
+)
+
+// Build information, set with -ldflags "-X main.version=... -X
+// main.commit=... -X main.buildDate=...". Without them the commit and its
+// time come from the version control information Go embeds.
+var (
+	version   = "1.0.0"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo returns the commit and build date of the binary
+func buildInfo() (string, string) {
+	revision, date := commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && revision == "":
+				revision = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+	return revision, date
+}
+
+// getMetaHandler describes this deployment: its build, what it can parse
+// and store, which features and ingestion endpoints are on, and the limits
+// requests must stay within, so clients need not assume them
+func (s *Server) getMetaHandler(w http.ResponseWriter, r *http.Request) {
+	revision, date := buildInfo()
+	cfg := s.config
+
+	features := make(map[string]bool)
+	for _, state := range s.features.States() {
+		features[state.Name] = s.features.Enabled(state.Name, "")
+	}
+
+	response := map[string]interface{}{
+		"version":        version,
+		"commit":         revision,
+		"build_date":     date,
+		"go_version":     runtime.Version(),
+		"database_type":  cfg.Database.Type,
+		"cache_type":     cfg.Cache.Type,
+		"report_storage": cfg.Reports.Storage.Type,
+		"log_types":      s.processor.LogTypes(),
+		"features":       features,
+		"ingest": map[string]interface{}{
+			"loki":            cfg.Ingest.Loki.Enabled,
+			"otlp":            cfg.Ingest.OTLP.Enabled,
+			"hec":             cfg.Ingest.HEC.Enabled,
+			"watch":           len(cfg.Ingest.Watch),
+			"syslog":          len(cfg.Ingest.Syslog),
+			"pull_schedules":  len(cfg.Ingest.Pull.Schedules),
+			"load_shedding":   cfg.Ingest.LoadShedding.Enabled,
+			"duplicate_files": cfg.Ingest.DuplicateFiles,
+		},
+		"alerting": cfg.Alerting.Enabled,
+		"graphql":  cfg.GraphQL.Enabled,
+		"grpc":     cfg.GRPC.Enabled,
+		"limits": map[string]interface{}{
+			"chunked_upload_mb":  cfg.Ingest.Uploads.MaxSize,
+			"chunk_mb":           cfg.Ingest.Uploads.MaxChunkSize,
+			"loki_body_mb":       cfg.Ingest.Loki.MaxBodySize,
+			"otlp_body_mb":       cfg.Ingest.OTLP.MaxBodySize,
+			"hec_body_mb":        cfg.Ingest.HEC.MaxBodySize,
+			"s3_objects":         cfg.Ingest.S3.MaxObjects,
+			"report_entries":     maxReportEntries,
+			"alert_time_window":  alerting.MaxTimeWindow,
+			"sample_entries":     maxSampleEntries,
+			"sample_days":        int(sampledata.MaxSpan.Hours() / 24),
+			"graphql_depth":      cfg.GraphQL.MaxDepth,
+			"graphql_complexity": cfg.GraphQL.MaxComplexity,
+			"grpc_message_mb":    cfg.GRPC.MaxMessageSize,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/database"
+)
+
+// runMigrate runs the migrate subcommand: "migrate status" lists the schema
+// migrations and whether they are applied, "migrate" or "migrate up"
+// applies the pending ones
+func runMigrate(cfg *config.Config, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if action != "up" && action != "status" {
+		return fmt.Errorf("unknown migrate action %q, expected up or status", action)
+	}
+	switch cfg.Database.Type {
+	case "mysql", "postgres", "sqlite":
+	default:
+		return fmt.Errorf("database type %s has no schema migrations", cfg.Database.Type)
+	}
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if action == "status" {
+		migrations, err := db.Migrations()
+		if err != nil {
+			return err
+		}
+		out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(out, "VERSION\tNAME\tAPPLIED")
+		for _, m := range migrations {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		return out.Flush()
+	}
+
+	applied, err := db.Migrate()
+	for _, m := range applied {
+		fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("Schema is up to date")
+	}
+	return nil
+}
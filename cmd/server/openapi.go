@@ -0,0 +1,510 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/openapi"
+	"github.com/gorilla/mux"
+)
+
+// apiDoc describes a route in the OpenAPI document. Bodies are Go values
+// whose types are encoded, an apiList of them, or an *openapi.Schema.
+type apiDoc struct {
+	summary  string
+	query    []*openapi.Parameter
+	request  interface{}
+	response interface{}
+	// status is that of a successful response, 200 by default, and
+	// contentType its media type, JSON by default
+	status      string
+	contentType string
+}
+
+// apiList is the body listing items under key with their count, as most
+// listings are
+type apiList struct {
+	key  string
+	item interface{}
+}
+
+// stringParam is an optional query parameter
+func stringParam(name, description string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: "string"}}
+}
+
+// intParam is an optional integer query parameter
+func intParam(name, description string) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: "integer"}}
+}
+
+// filterParams are the filters of /api/v1/logs that other queries take too
+var filterParams = []*openapi.Parameter{
+	stringParam("log_type", "Entries of this log type"),
+	intParam("status_code", "Entries with this status code"),
+	intParam("min_status_code", "Entries with at least this status code, such as 400 for errors"),
+	stringParam("source_ip", "Entries from this client address"),
+	stringParam("path", "Entries whose path contains this"),
+	stringParam("exact_path", "true to match path in full"),
+	stringParam("method", "Entries with this HTTP method"),
+	stringParam("saved", "Name of a saved search to filter by"),
+}
+
+// timeParams bound a query's period with RFC 3339 times
+var timeParams = []*openapi.Parameter{
+	stringParam("start_time", "Entries at or after this RFC 3339 time"),
+	stringParam("end_time", "Entries before this RFC 3339 time"),
+}
+
+// rangeParams bound an aggregate's period with RFC 3339 times
+var rangeParams = []*openapi.Parameter{
+	stringParam("start", "Start of the range, an RFC 3339 time"),
+	stringParam("end", "End of the range, an RFC 3339 time"),
+}
+
+func params(lists ...[]*openapi.Parameter) []*openapi.Parameter {
+	var all []*openapi.Parameter
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
+
+// logsPage is the body of GET /api/v1/logs
+var logsPage = &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+	"logs":        {Type: "array", Items: openapi.Ref("LogEntry")},
+	"limit":       {Type: "integer"},
+	"offset":      {Type: "integer"},
+	"count":       {Type: "integer"},
+	"total_count": {Type: "integer", Format: "int64"},
+	"next_cursor": {Type: "string"},
+	"prev_cursor": {Type: "string"},
+}}
+
+// jobAccepted is the response of requests that start a background job
+var jobAccepted = &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+	"job_id":     {Type: "string"},
+	"status":     {Type: "string"},
+	"status_url": {Type: "string"},
+}}
+
+// eventStream is the body of server-sent events
+var eventStream = &openapi.Schema{Type: "string"}
+
+// apiDocs describe the routes by method and path template. Routes missing
+// here are still listed, with their method and path as summary.
+var apiDocs = map[string]apiDoc{
+	"GET /":                    {summary: "Web dashboard", contentType: "text/html"},
+	"GET /health":              {summary: "Health of the server and its database"},
+	"GET /api/v1/meta":         {summary: "Build, capabilities and limits of this deployment"},
+	"GET /api/v1/openapi.json": {summary: "This OpenAPI document"},
+	"GET /auth/login":          {summary: "Sign in with the OpenID provider", status: "302"},
+	"GET /auth/callback":       {summary: "Return from the OpenID provider", status: "302"},
+	"GET /auth/logout":         {summary: "Sign out", status: "302"},
+	"POST /auth/logout":        {summary: "Sign out", status: "302"},
+	"GET /api/v1/auth/me":      {summary: "The signed-in user and their role"},
+	"GET /api/v1/roles": {
+		summary: "Roles and what they allow",
+		response: struct {
+			Roles       []models.Role `json:"roles"`
+			DefaultRole string        `json:"default_role"`
+		}{},
+	},
+	"GET /api/v1/users": {summary: "Users and their roles", response: apiList{"users", models.User{}}},
+	"PUT /api/v1/users/{subject}": {
+		summary: "Set a user's role",
+		request: struct {
+			Role  string `json:"role"`
+			Email string `json:"email"`
+			Name  string `json:"name"`
+		}{},
+		response: models.User{},
+	},
+	"DELETE /api/v1/users/{subject}": {summary: "Remove a user", status: "204"},
+
+	"POST /api/v1/logs/upload": {
+		summary: "Upload log files as multipart/form-data, processed in the background",
+		request: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+			"logfile":  {Type: "string", Format: "binary"},
+			"log_type": {Type: "string"},
+		}},
+		status: "202",
+	},
+	"POST /api/v1/logs/uploads": {
+		summary: "Start a chunked upload",
+		request: struct {
+			Filename     string   `json:"filename"`
+			LogType      string   `json:"log_type"`
+			Size         int64    `json:"size"`
+			MaxErrorRate *float64 `json:"max_error_rate"`
+		}{},
+		status: "201",
+	},
+	"GET /api/v1/logs/uploads/{id}": {summary: "A chunked upload and its offset"},
+	"PUT /api/v1/logs/uploads/{id}": {
+		summary: "Append the chunk starting at offset to an upload",
+		query:   []*openapi.Parameter{{Name: "offset", In: "query", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"}}},
+		request: &openapi.Schema{Type: "string", Format: "binary"},
+	},
+	"DELETE /api/v1/logs/uploads/{id}": {summary: "Abandon an upload", status: "204"},
+	"POST /api/v1/logs/uploads/{id}/complete": {
+		summary: "Verify an upload's SHA-256 and process it in the background",
+		request: struct {
+			SHA256 string `json:"sha256"`
+		}{},
+		response: jobAccepted,
+		status:   "202",
+	},
+	"POST /api/v1/logs/ingest/s3":       {summary: "Import log objects from an S3 bucket in the background", response: jobAccepted, status: "202"},
+	"POST /api/v1/logs/ingest/url":      {summary: "Download a log file from an HTTP(S) or SFTP URL in the background", response: jobAccepted, status: "202"},
+	"GET /api/v1/logs/ingest/jobs/{id}": {summary: "Progress of an ingestion job", response: jobs.Snapshot{}},
+	"GET /api/v1/logs/ingest/jobs/{id}/events": {
+		summary:     "Stream an ingestion job's progress as server-sent events",
+		response:    eventStream,
+		contentType: "text/event-stream",
+	},
+	"GET /api/v1/logs": {
+		summary: "List log entries",
+		query: params(filterParams, timeParams, []*openapi.Parameter{
+			intParam("limit", "Entries per page, 100 by default"),
+			intParam("offset", "Entries to skip"),
+			stringParam("sort", "Field to sort by, one of "+strings.Join(models.SortFields, ", ")+", optionally followed by :asc or :desc"),
+			stringParam("fields", "Comma-separated fields of each entry, all by default"),
+			stringParam("cursor", "Page after or before another, from next_cursor or prev_cursor"),
+		}),
+		response: logsPage,
+	},
+	"DELETE /api/v1/logs": {
+		summary: "Delete the entries matching a filter, or count them with dry_run",
+		request: struct {
+			models.LogFilter
+			DryRun bool `json:"dry_run"`
+		}{},
+	},
+	"GET /api/v1/logs/stats":         {summary: "Statistics of stored entries and processing", query: params(filterParams, timeParams)},
+	"GET /api/v1/logs/stats/methods": {summary: "Requests and errors by HTTP method", query: params(filterParams, timeParams)},
+	"GET /api/v1/logs/stats/latency": {summary: "Response time percentiles", query: params(filterParams, timeParams)},
+	"GET /api/v1/logs/patterns":      {summary: "Templates of messages with their counts", query: params(filterParams, timeParams)},
+	"GET /api/v1/logs/aggregate": {
+		summary: "Metrics over groups of entries",
+		query: params(filterParams, rangeParams, []*openapi.Parameter{
+			stringParam("group_by", "Comma-separated fields to group by: "+strings.Join(models.AggregateGroups, ", ")),
+			stringParam("metric", "Comma-separated metrics, count by default: "+strings.Join(models.AggregateMetrics, ", ")),
+			stringParam("interval", "Time buckets, a whole number of seconds such as 5m"),
+			intParam("limit", "Rows to return"),
+		}),
+		response: apiList{"rows", models.AggregateRow{}},
+	},
+	"GET /api/v1/logs/timeseries": {
+		summary: "Metrics in time buckets with empty buckets zero-filled",
+		query: params(filterParams, rangeParams, []*openapi.Parameter{
+			stringParam("interval", "1m, 5m, 1h (the default) or 1d"),
+			stringParam("metric", "Comma-separated metrics: "+strings.Join(models.AggregateMetrics, ", ")),
+			stringParam("format", "grafana for Grafana's JSON data sources"),
+		}),
+	},
+	"GET /api/v1/logs/tail": {
+		summary:     "Stream new entries as server-sent events",
+		query:       params(filterParams, []*openapi.Parameter{stringParam("fields", "Comma-separated fields of each entry, all by default")}),
+		response:    eventStream,
+		contentType: "text/event-stream",
+	},
+
+	"POST /api/v1/reports/generate": {summary: "Generate a report in the background", request: reportRequest{}, response: jobAccepted, status: "202"},
+	"GET /api/v1/reports/jobs/{id}": {summary: "Progress and files of a report job", response: jobs.Snapshot{}},
+	"GET /api/v1/reports/jobs/{id}/events": {
+		summary:     "Stream a report job's progress as server-sent events",
+		response:    eventStream,
+		contentType: "text/event-stream",
+	},
+	"POST /api/v1/reports/robots":                           {summary: "Report crawler compliance with robots.txt", request: crawlReportRequest{}, status: "201"},
+	"POST /api/v1/reports/correlation":                      {summary: "Report application errors that coincide with web errors", request: correlationReportRequest{}, status: "201"},
+	"POST /api/v1/reports/comparison":                       {summary: "Compare a period with the previous one", request: comparisonReportRequest{}, status: "201"},
+	"POST /api/v1/reports/security":                         {summary: "Report suspicious requests and brute-force attempts", request: securityReportRequest{}, status: "201"},
+	"POST /api/v1/reports/compliance":                       {summary: "Generate a month's compliance pack", request: complianceReportRequest{}, status: "201"},
+	"GET /api/v1/reports/compliance":                        {summary: "List compliance packs"},
+	"GET /api/v1/reports/compliance/{period}/verify":        {summary: "Verify a compliance pack's checksums"},
+	"POST /api/v1/compliance/erasures":                      {summary: "Erase the entries of a data subject in the background and certify it", status: "202"},
+	"GET /api/v1/compliance/erasures/public-key":            {summary: "Key that signs erasure certificates"},
+	"POST /api/v1/compliance/erasures/verify":               {summary: "Verify an erasure certificate"},
+	"GET /api/v1/compliance/erasures/{id}":                  {summary: "An erasure and its certificate"},
+	"GET /api/v1/reports/metrics":                           {summary: "Report metrics in the OpenMetrics format", contentType: "text/plain"},
+	"GET /api/v1/reports/templates":                         {summary: "List report templates"},
+	"GET /api/v1/reports/templates/{report_type}/{name}":    {summary: "A report template", response: models.ReportTemplate{}},
+	"PUT /api/v1/reports/templates/{report_type}/{name}":    {summary: "Upload a report template", response: models.ReportTemplate{}},
+	"DELETE /api/v1/reports/templates/{report_type}/{name}": {summary: "Delete a report template", status: "204"},
+	"GET /api/v1/searches":                                  {summary: "List your saved searches", response: apiList{"searches", models.SavedSearch{}}},
+	"GET /api/v1/searches/{name}":                           {summary: "A saved search", response: models.SavedSearch{}},
+	"PUT /api/v1/searches/{name}": {
+		summary: "Save a search",
+		request: struct {
+			Description string           `json:"description"`
+			Filter      models.LogFilter `json:"filter"`
+		}{},
+		response: models.SavedSearch{},
+	},
+	"DELETE /api/v1/searches/{name}":  {summary: "Delete a saved search", status: "204"},
+	"GET /api/v1/reports":             {summary: "List generated reports"},
+	"GET /api/v1/reports/{id}":        {summary: "Download a report", contentType: "application/octet-stream"},
+	"GET /api/v1/reports/{id}/bundle": {summary: "Download a report run's files as a ZIP", contentType: "application/zip"},
+	"GET /api/v1/stats":               {summary: "Database statistics"},
+
+	"GET /api/v1/alerts/rules":      {summary: "List alert rules", response: apiList{"rules", models.AlertRule{}}},
+	"POST /api/v1/alerts/rules":     {summary: "Create an alert rule", request: models.AlertRule{}, response: models.AlertRule{}, status: "201"},
+	"PUT /api/v1/alerts/rules/{id}": {summary: "Update an alert rule", request: models.AlertRule{}, response: models.AlertRule{}},
+	"GET /api/v1/alerts/rules/{id}/evaluations": {
+		summary: "Stored evaluations of an alert rule, oldest first",
+		query: []*openapi.Parameter{
+			stringParam("start_time", "Evaluations at or after this RFC 3339 time, the start of the retention by default"),
+			intParam("limit", "The last evaluations to return, 300 by default"),
+		},
+		response: apiList{"evaluations", models.RuleEvaluation{}},
+	},
+	"POST /api/v1/alerts/replay":                      {summary: "Replay alert rules over stored entries in the background", response: jobAccepted, status: "202"},
+	"GET /api/v1/alerts/replay/{id}":                  {summary: "Progress and firings of an alert replay", response: jobs.Snapshot{}},
+	"GET /api/v1/alerts/history":                      {summary: "Fired alerts", response: apiList{"alerts", models.AlertEvent{}}},
+	"POST /api/v1/alerts/history/{id}/acknowledge":    {summary: "Acknowledge a fired alert"},
+	"GET /api/v1/alerts/active":                       {summary: "Alerts firing now", response: apiList{"alerts", alerting.ActiveAlert{}}},
+	"POST /api/v1/alerts/slack/actions":               {summary: "Slack interactive message actions"},
+	"GET /api/v1/alerts/channels":                     {summary: "List notification channels"},
+	"POST /api/v1/alerts/channels/{name}/test-render": {summary: "Render a channel's message for a sample alert"},
+
+	"GET /api/v1/maintenance":             {summary: "List maintenance windows", response: apiList{"windows", models.MaintenanceWindow{}}},
+	"POST /api/v1/maintenance":            {summary: "Schedule a maintenance window", request: models.MaintenanceWindow{}, response: models.MaintenanceWindow{}, status: "201"},
+	"DELETE /api/v1/maintenance/{id}":     {summary: "Delete a maintenance window", status: "204"},
+	"GET /api/v1/latency-budgets":         {summary: "List latency budgets", response: apiList{"budgets", models.LatencyBudget{}}},
+	"POST /api/v1/latency-budgets":        {summary: "Create a latency budget", request: models.LatencyBudget{}, response: models.LatencyBudget{}, status: "201"},
+	"GET /api/v1/latency-budgets/status":  {summary: "Budgets met and breached"},
+	"DELETE /api/v1/latency-budgets/{id}": {summary: "Delete a latency budget", status: "204"},
+
+	"GET /api/v1/retention":                     {summary: "Retention policies and how far back entries reach"},
+	"POST /api/v1/retention/cleanup":            {summary: "Remove expired entries now"},
+	"PUT /api/v1/retention/{log_type}":          {summary: "Set a log type's retention", response: models.RetentionPolicy{}},
+	"DELETE /api/v1/retention/{log_type}":       {summary: "Remove a log type's retention override", status: "204"},
+	"GET /api/v1/archives":                      {summary: "List cold storage archives"},
+	"POST /api/v1/archives/rehydrate":           {summary: "Restore archived entries in the background", response: jobAccepted, status: "202"},
+	"GET /api/v1/archives/rehydrate/{id}":       {summary: "Progress of a rehydration", response: jobs.Snapshot{}},
+	"GET /api/v1/archives/{id}":                 {summary: "An archive's manifest"},
+	"POST /api/v1/rollups/rebuild":              {summary: "Rebuild traffic rollups in the background", response: jobAccepted, status: "202"},
+	"GET /api/v1/rollups/rebuild/{id}":          {summary: "Progress of a rollup rebuild", response: jobs.Snapshot{}},
+	"GET /api/v1/integrity":                     {summary: "Findings of the last integrity check"},
+	"POST /api/v1/integrity/check":              {summary: "Check stored data for broken invariants now"},
+	"GET /api/v1/security/scores":               {summary: "Threat scores of client addresses"},
+	"GET /api/v1/security/events/export":        {summary: "Export security events for a SIEM"},
+	"GET /api/v1/forwarding":                    {summary: "SIEM forwarding statistics"},
+	"GET /api/v1/audit/export":                  {summary: "Export the audit log with its hash chain"},
+	"GET /api/v1/audit/verify":                  {summary: "Verify the stored audit log's hash chain"},
+	"POST /api/v1/audit/verify":                 {summary: "Verify an exported audit log"},
+	"GET /api/v1/config/versions":               {summary: "History of configuration changes"},
+	"GET /api/v1/config/versions/diff":          {summary: "Differences between two configuration versions"},
+	"POST /api/v1/config/versions/rollback":     {summary: "Roll the configuration back to a version"},
+	"GET /api/v1/admin/config":                  {summary: "Effective configuration with secrets redacted"},
+	"GET /api/v1/admin/features":                {summary: "Feature flags and their overrides"},
+	"PUT /api/v1/admin/features/{flag}":         {summary: "Override a feature flag", response: models.FeatureOverride{}},
+	"DELETE /api/v1/admin/features/{flag}":      {summary: "Remove a feature flag's override", status: "204"},
+	"POST /api/v1/admin/generate-sample-data":   {summary: "Generate sample entries in the background", response: jobAccepted, status: "202"},
+	"GET /api/v1/admin/plugins":                 {summary: "List plugins and their state"},
+	"POST /api/v1/admin/plugins/{name}/restart": {summary: "Restart a plugin", status: "202"},
+	"POST /api/v1/admin/backup":                 {summary: "Back up the database", contentType: "application/octet-stream"},
+	"POST /api/v1/admin/restore":                {summary: "Restore a backup"},
+
+	"GET /api/graphql":                   {summary: "Run a GraphQL query given as query parameters"},
+	"POST /api/graphql":                  {summary: "Run a GraphQL query"},
+	"GET /api/graphql/schema":            {summary: "GraphQL schema in SDL", contentType: "text/plain"},
+	"POST /loki/api/v1/push":             {summary: "Loki push API for Promtail and other Loki clients", status: "204"},
+	"POST /v1/logs":                      {summary: "OTLP/HTTP logs receiver"},
+	"POST /services/collector":           {summary: "Splunk HTTP Event Collector"},
+	"POST /services/collector/event":     {summary: "Splunk HTTP Event Collector"},
+	"POST /services/collector/event/1.0": {summary: "Splunk HTTP Event Collector"},
+	"GET /services/collector/health":     {summary: "Splunk HTTP Event Collector health"},
+}
+
+// openAPIHandler serves an OpenAPI 3 document of the routes this server
+// has, so client SDKs and API gateways can be generated from it
+func (s *Server) openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	doc, err := s.openAPIDocument()
+	if err != nil {
+		s.logger.Errorf("Failed to build OpenAPI document: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// openAPIDocument describes every route of the router, with the auth
+// schemes of the sign-in that is configured
+func (s *Server) openAPIDocument() (*openapi.Document, error) {
+	doc := openapi.New("Server Log Analyzer API", version)
+	doc.Info.Description = "Ingest, query and report on server logs."
+	if s.config.Server.PublicURL != "" {
+		doc.Servers = []openapi.Server{{URL: strings.TrimSuffix(s.config.Server.PublicURL, "/")}}
+	}
+	doc.Schema(models.LogEntry{})
+	doc.Schema(models.LogFilter{})
+
+	if s.auth != nil {
+		doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+			Description:  "Token issued by the OpenID provider",
+		}
+		doc.Components.SecuritySchemes["sessionCookie"] = &openapi.SecurityScheme{
+			Type:        "apiKey",
+			In:          "cookie",
+			Name:        sessionCookie,
+			Description: "Session of a user signed in at /auth/login",
+		}
+		doc.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}, {"sessionCookie": {}}}
+	}
+	if s.config.Ingest.HEC.Enabled {
+		doc.Components.SecuritySchemes["hecToken"] = &openapi.SecurityScheme{
+			Type:        "apiKey",
+			In:          "header",
+			Name:        "Authorization",
+			Description: "A HEC token as \"Splunk <token>\"",
+		}
+	}
+
+	tags := make(map[string]bool)
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		// Report files are served by path below /reports/
+		if strings.HasSuffix(path, "/") && path != "/" {
+			return nil
+		}
+		for _, method := range methods {
+			if method == http.MethodHead {
+				continue
+			}
+			op := s.apiOperation(doc, method, path)
+			tags[op.Tags[0]] = true
+			doc.Add(method, path, op)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range sortedKeys(tags) {
+		doc.Tags = append(doc.Tags, openapi.Tag{Name: tag})
+	}
+	return doc, nil
+}
+
+// apiOperation describes one method of a route
+func (s *Server) apiOperation(doc *openapi.Document, method, path string) *openapi.Operation {
+	api := apiDocs[method+" "+path]
+	op := &openapi.Operation{
+		Tags:       []string{apiTag(path)},
+		Summary:    api.summary,
+		Parameters: api.query,
+	}
+	if op.Summary == "" {
+		op.Summary = method + " " + path
+	}
+
+	if api.request != nil {
+		contentType := "application/json"
+		if strings.HasSuffix(path, "/logs/upload") {
+			contentType = "multipart/form-data"
+		} else if schema, ok := api.request.(*openapi.Schema); ok && schema.Format == "binary" {
+			contentType = "application/octet-stream"
+		}
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			contentType: {Schema: apiSchema(doc, api.request)},
+		}}
+	}
+
+	status := api.status
+	if status == "" {
+		status = "200"
+	}
+	code, _ := strconv.Atoi(status)
+	response := &openapi.Response{Description: http.StatusText(code)}
+	if status != "204" && status != "302" {
+		contentType := api.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		schema := apiSchema(doc, api.response)
+		if schema == nil {
+			schema = &openapi.Schema{}
+		}
+		response.Content = map[string]openapi.MediaType{contentType: {Schema: schema}}
+	}
+	op.Responses = map[string]*openapi.Response{status: response}
+
+	switch {
+	case s.auth == nil:
+	case s.isPublicPath(path):
+		op.Security = &[]openapi.SecurityRequirement{}
+		if strings.HasPrefix(path, "/services/collector") && s.config.Ingest.HEC.Enabled {
+			op.Security = &[]openapi.SecurityRequirement{{"hecToken": {}}}
+		}
+	default:
+		op.Description = "Requires the " + requiredRole(&http.Request{Method: method, URL: &url.URL{Path: path}}) + " role."
+		op.Responses["401"] = &openapi.Response{Description: "Not signed in"}
+		op.Responses["403"] = &openapi.Response{Description: "The user's role does not allow this"}
+	}
+	return op
+}
+
+// apiSchema returns the schema of an apiDoc body, or nil without one
+func apiSchema(doc *openapi.Document, body interface{}) *openapi.Schema {
+	switch body := body.(type) {
+	case nil:
+		return nil
+	case *openapi.Schema:
+		return body
+	case apiList:
+		return &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+			body.key: {Type: "array", Items: apiSchema(doc, body.item)},
+			"count":  {Type: "integer"},
+		}}
+	default:
+		return doc.Schema(body)
+	}
+}
+
+// apiTag groups a route by the first segment of its path below /api/v1
+func apiTag(path string) string {
+	trimmed := strings.TrimPrefix(path, "/api/v1")
+	if trimmed == path && strings.HasPrefix(path, "/api/") {
+		trimmed = strings.TrimPrefix(path, "/api")
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(trimmed, "/"), "/")
+	switch segment {
+	case "", "health", "meta", "openapi.json":
+		return "server"
+	case "loki", "v1", "services":
+		return "ingest"
+	}
+	return segment
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/otlp"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// otlpLogsHandler accepts OTLP/HTTP log exports. Bodies are parsed as the
+// log type named by the record's or resource's log type attribute or,
+// failing that, the log_type query parameter. Records without a known log
+// type are stored as messages.
+func (s *Server) otlpLogsHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config.Ingest.OTLP
+
+	defaultLogType := r.URL.Query().Get("log_type")
+	if defaultLogType != "" && !s.processor.SupportsLogType(defaultLogType) {
+		http.Error(w, "Invalid log type. Must be one of: "+strings.Join(s.processor.LogTypes(), ", "), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodySize<<20))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, fmt.Sprintf("Export exceeds %d MB", cfg.MaxBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	records, err := otlp.Decode(body, r.Header.Get("Content-Type"), r.Header.Get("Content-Encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries := make([]*models.LogEntry, 0, len(records))
+	for _, record := range records {
+		logType := record.Attribute(cfg.LogTypeAttribute)
+		if logType == "" {
+			logType = defaultLogType
+		}
+		if !s.processor.SupportsLogType(logType) {
+			logType = ""
+		}
+		entries = append(entries, otlp.ToLogEntry(record, logType, s.processor.ParseLine))
+	}
+
+	s.startStoring()
+	s.processor.Submit(entries)
+
+	// Reply with an empty ExportLogsServiceResponse in the request's encoding
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == otlp.ContentTypeJSON {
+		w.Header().Set("Content-Type", otlp.ContentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+		return
+	}
+	w.Header().Set("Content-Type", otlp.ContentTypeProtobuf)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+var errBadCursor = errors.New("invalid cursor")
+
+// logCursor is where a page of entries starts: after the position for the
+// next page or before it for the previous one. Clients pass it back as is.
+type logCursor struct {
+	models.LogPosition
+	Before bool `json:"before,omitempty"`
+}
+
+// encodeCursor returns the cursor of the entries after or before entry
+func encodeCursor(entry *models.LogEntry, before bool) string {
+	data, _ := json.Marshal(logCursor{LogPosition: *models.PositionOf(entry), Before: before})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor narrows the filter to the page a cursor starts
+func decodeCursor(cursor string, filter *models.LogFilter) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errBadCursor
+	}
+	var c logCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Timestamp.IsZero() {
+		return errBadCursor
+	}
+	if c.Before {
+		filter.Before = &c.LogPosition
+	} else {
+		filter.After = &c.LogPosition
+	}
+	return nil
+}
+
+// sortedByTime reports whether the filter lists entries by time, the
+// order cursors page through
+func sortedByTime(filter *models.LogFilter) bool {
+	return filter.SortBy == "" || filter.SortBy == "timestamp"
+}
+
+// logPage is a page of entries and the cursors of the pages around it,
+// empty where there is none
+type logPage struct {
+	Logs       []*models.LogEntry
+	NextCursor string
+	PrevCursor string
+}
+
+// findPage finds a page of entries matching the filter. One more entry
+// than the limit is read to tell whether there is a page beyond this one.
+func (s *Server) findPage(ctx context.Context, filter *models.LogFilter) (*logPage, error) {
+	extra := *filter
+	extra.Limit++
+	logs, err := s.db.Find(ctx, &extra)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(logs) > filter.Limit
+	if more && filter.Before != nil {
+		logs = logs[1:]
+	} else if more {
+		logs = logs[:filter.Limit]
+	}
+	page := &logPage{Logs: logs}
+	if len(logs) == 0 || !sortedByTime(filter) {
+		return page, nil
+	}
+
+	// A page before a position has the entry at it after its end, and
+	// any other page has entries before it unless it is the first
+	hasNext := more || filter.Before != nil
+	hasPrev := filter.After != nil || filter.Offset > 0
+	if filter.Before != nil {
+		hasPrev = more
+	}
+	if hasNext {
+		page.NextCursor = encodeCursor(logs[len(logs)-1], false)
+	}
+	if hasPrev {
+		page.PrevCursor = encodeCursor(logs[0], true)
+	}
+	return page, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursors(t *testing.T) {
+	entry := &models.LogEntry{ID: 42, Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	var filter models.LogFilter
+	require.NoError(t, decodeCursor(encodeCursor(entry, false), &filter))
+	assert.Equal(t, models.PositionOf(entry), filter.After)
+	assert.Nil(t, filter.Before)
+
+	filter = models.LogFilter{}
+	require.NoError(t, decodeCursor(encodeCursor(entry, true), &filter))
+	assert.Equal(t, models.PositionOf(entry), filter.Before)
+	assert.Nil(t, filter.After)
+
+	cursor := encodeCursor(entry, false)
+	for name, bad := range map[string]string{
+		"not base64":   "not a cursor!",
+		"padded":       base64.URLEncoding.EncodeToString([]byte(`{"timestamp":"2024-03-01T12:00:00Z","id":42}`)),
+		"not JSON":     base64.RawURLEncoding.EncodeToString([]byte("garbage")),
+		"no timestamp": base64.RawURLEncoding.EncodeToString([]byte(`{"id":42}`)),
+		"bad time":     base64.RawURLEncoding.EncodeToString([]byte(`{"timestamp":"yesterday","id":42}`)),
+		"truncated":    cursor[:len(cursor)-4],
+	} {
+		filter = models.LogFilter{}
+		assert.ErrorIs(t, decodeCursor(bad, &filter), errBadCursor, name)
+		assert.Nil(t, filter.After, name)
+		assert.Nil(t, filter.Before, name)
+	}
+}
+
+func TestFindPage(t *testing.T) {
+	s := newTestServer(t, nil)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Two entries share a time, which their IDs order; the first page ends
+	// between them
+	for _, minute := range []int{0, 1, 2, 2, 3} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: base.Add(time.Duration(minute) * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1",
+			Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+	ids := func(page *logPage) []int64 {
+		var ids []int64
+		for _, entry := range page.Logs {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+	follow := func(cursor string) *logPage {
+		t.Helper()
+		filter := &models.LogFilter{Limit: 2}
+		require.NoError(t, decodeCursor(cursor, filter))
+		page, err := s.findPage(s.ctx, filter)
+		require.NoError(t, err)
+		return page
+	}
+
+	first, err := s.findPage(s.ctx, &models.LogFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Logs, 2)
+	assert.Equal(t, base.Add(3*time.Minute), first.Logs[0].Timestamp.UTC(), "most recent first")
+	assert.Empty(t, first.PrevCursor, "the first page has nothing before it")
+	require.NotEmpty(t, first.NextCursor)
+
+	second := follow(first.NextCursor)
+	require.Len(t, second.Logs, 2)
+	assert.Equal(t, first.Logs[1].Timestamp, second.Logs[0].Timestamp)
+	assert.Greater(t, first.Logs[1].ID, second.Logs[0].ID, "ties are ordered by ID, neither skipped nor repeated")
+	assert.NotEmpty(t, second.PrevCursor)
+
+	last := follow(second.NextCursor)
+	require.Len(t, last.Logs, 1)
+	assert.Empty(t, last.NextCursor, "the last page has nothing after it")
+	assert.NotEmpty(t, last.PrevCursor)
+
+	// Going back returns the same pages
+	assert.Equal(t, ids(second), ids(follow(last.PrevCursor)))
+	back := follow(second.PrevCursor)
+	assert.Equal(t, ids(first), ids(back))
+	assert.Empty(t, back.PrevCursor, "the first page reached backwards has nothing before it")
+	assert.NotEmpty(t, back.NextCursor)
+
+	// A page ending exactly at the last entry has no next page
+	exact, err := s.findPage(s.ctx, &models.LogFilter{Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, exact.Logs, 5)
+	assert.Empty(t, exact.NextCursor)
+
+	// Past the last entry there is nothing, and no cursors
+	empty := follow(encodeCursor(&models.LogEntry{Timestamp: base.Add(-time.Hour)}, false))
+	assert.Empty(t, empty.Logs)
+	assert.Empty(t, empty.NextCursor)
+	assert.Empty(t, empty.PrevCursor)
+
+	// Other sorts page by offset only
+	sorted, err := s.findPage(s.ctx, &models.LogFilter{Limit: 2, SortBy: "path"})
+	require.NoError(t, err)
+	assert.Empty(t, sorted.NextCursor)
+}
+
+func TestGetLogsPaging(t *testing.T) {
+	s := newTestServer(t, nil)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for minute := 0; minute < 3; minute++ {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: base.Add(time.Duration(minute) * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1",
+			Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/logs?limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var page map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 3.0, page["total_count"])
+	assert.NotContains(t, page, "total")
+	assert.Equal(t, 2.0, page["count"])
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/logs?limit=2&cursor="+page["next_cursor"].(string), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 1.0, page["count"])
+	assert.Equal(t, 3.0, page["total_count"], "the total counts every page")
+	assert.Empty(t, page["next_cursor"])
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/logs?cursor=tampered", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/logs?sort=path&cursor="+page["prev_cursor"].(string), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "cursors only page by time")
+}
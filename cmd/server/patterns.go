@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/patterns"
+)
+
+// maxPatternMessages bounds how many recent messages are clustered per request
+const maxPatternMessages = 10000
+
+func (s *Server) getLogPatternsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	logTypes := logprocessor.MessageLogTypes
+	if logType := query.Get("log_type"); logType != "" {
+		if !logprocessor.IsMessageLogType(logType) {
+			http.Error(w, "log_type must be one of the application log types", http.StatusBadRequest)
+			return
+		}
+		logTypes = []string{logType}
+	}
+
+	// Default to the last 24 hours
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		end = t
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	entries, err := s.db.GetLogMessages(logTypes, start, end, maxPatternMessages)
+	if err != nil {
+		s.logger.Errorf("Failed to get log messages: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	miner := patterns.NewMiner()
+	for _, entry := range entries {
+		if entry.Path != "" {
+			miner.Add(entry.Path, entry.Timestamp)
+		}
+	}
+	top := miner.Top(limit)
+
+	response := map[string]interface{}{
+		"patterns":   top,
+		"count":      len(top),
+		"messages":   len(entries),
+		"start_time": start,
+		"end_time":   end,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
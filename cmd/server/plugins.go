@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/notify"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/plugin"
+	"github.com/gorilla/mux"
+)
+
+// setupPlugins starts the configured plugins, registers the log types
+// they parse and lets plugin notification channels deliver through them.
+// Enrichers and exporters are called as entries are stored.
+func (s *Server) setupPlugins() error {
+	manager, err := plugin.NewManager(s.config.Plugins, s.logger)
+	if err != nil {
+		return err
+	}
+	if err := manager.Start(s.ctx); err != nil {
+		return err
+	}
+
+	for _, p := range manager.Plugins() {
+		for _, logType := range p.Description().Parsers {
+			p, logType := p, logType
+			parser := logprocessor.NewParser(logType, func(line string) (*models.LogEntry, error) {
+				return p.Parse(logType, line)
+			})
+			if err := s.processor.RegisterParser(parser); err != nil {
+				manager.Close()
+				return fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+		}
+	}
+
+	for _, ch := range s.notifier.Channels() {
+		if ch.Type != notify.ChannelPlugin {
+			continue
+		}
+		if p, ok := manager.Plugin(ch.Plugin); !ok || !p.Description().Notifier {
+			manager.Close()
+			return fmt.Errorf("notification channel %s: plugin %s does not deliver notifications", ch.Name, ch.Plugin)
+		}
+	}
+	s.notifier.SetPluginSender(manager.Notify)
+
+	s.plugins = manager
+	return nil
+}
+
+// listPluginsHandler shows each plugin's state, what it provides and how
+// its calls fare
+func (s *Server) listPluginsHandler(w http.ResponseWriter, r *http.Request) {
+	statuses := []plugin.Status{}
+	if s.plugins != nil {
+		statuses = s.plugins.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plugins": statuses,
+	})
+}
+
+// restartPluginHandler restarts a plugin, or reconnects to it, as if it
+// had failed
+func (s *Server) restartPluginHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	var p *plugin.Client
+	if s.plugins != nil {
+		p, _ = s.plugins.Plugin(name)
+	}
+	if p == nil {
+		http.Error(w, "Plugin not found", http.StatusNotFound)
+		return
+	}
+
+	// Plugins that already failed are being restarted
+	if err := p.Restart(); err != nil {
+		http.Error(w, "Plugin is not running", http.StatusConflict)
+		return
+	}
+	s.recordAudit(audit.ActionPluginRestarted, requestActor(r), "plugin:"+name, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plugin": name,
+		"status": plugin.StateRestarting,
+	})
+}
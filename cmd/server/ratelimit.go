@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/cache"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ratelimit"
+)
+
+// sharedBuckets keeps rate limit buckets in Redis, failing rather than
+// keeping them in process while Redis is unavailable
+type sharedBuckets struct {
+	cache *cache.Fallback
+}
+
+func (b sharedBuckets) Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	return b.cache.TakePrimary(ctx, key, rate, burst, now)
+}
+
+// setupRateLimits builds the limiters of ingestion and query requests.
+// With a Redis cache, replicas share each client's quota; while Redis is
+// unavailable each replica limits with its own buckets.
+func (s *Server) setupRateLimits() {
+	cfg := s.config.RateLimit
+	ingest := make(map[string]ratelimit.Quota)
+	query := make(map[string]ratelimit.Quota)
+	for _, quota := range cfg.Quotas {
+		if quota.Ingest != nil {
+			ingest[quota.Key] = rateQuota(*quota.Ingest)
+		}
+		if quota.Query != nil {
+			query[quota.Key] = rateQuota(*quota.Query)
+		}
+	}
+	s.ingestLimits = ratelimit.New(rateQuota(cfg.Ingest), ingest, cfg.MaxKeys)
+	s.queryLimits = ratelimit.New(rateQuota(cfg.Query), query, cfg.MaxKeys)
+	if redis, ok := s.cache.(*cache.Fallback); ok {
+		s.ingestLimits.Share(sharedBuckets{redis}, "ingest")
+		s.queryLimits.Share(sharedBuckets{redis}, "query")
+	}
+}
+
+func rateQuota(cfg config.RateQuotaConfig) ratelimit.Quota {
+	return ratelimit.Quota{Rate: cfg.Rate, Burst: cfg.Burst}
+}
+
+// rateLimitKey identifies who a request counts against: the signed-in
+// user, the named HEC token it carries or otherwise its client address
+func (s *Server) rateLimitKey(r *http.Request) string {
+	if identity := requestIdentity(r); identity != nil {
+		return "user:" + identity.Subject
+	}
+	if s.config.Ingest.HEC.Enabled && r.Header.Get("Authorization") != "" {
+		if token, _, _, _ := s.hecToken(r); token != nil && token.Name != "" {
+			return "hec:" + token.Name
+		}
+	}
+	return "ip:" + clientAddr(r)
+}
+
+// limitIngest refuses ingestion requests of clients past their quota
+func (s *Server) limitIngest(next http.HandlerFunc) http.HandlerFunc {
+	return s.rateLimit(s.ingestLimits, next)
+}
+
+// limitQuery refuses queries of clients past their quota
+func (s *Server) limitQuery(next http.HandlerFunc) http.HandlerFunc {
+	return s.rateLimit(s.queryLimits, next)
+}
+
+// rateLimit refuses requests with 429 and a Retry-After while their
+// client's bucket is empty. Limits are set up before the routes, and a
+// nil limiter does not limit.
+func (s *Server) rateLimit(limiter *ratelimit.Limiter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if limiter != nil {
+			if ok, wait := limiter.Allow(r.Context(), s.rateLimitKey(r), time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "Rate limit exceeded, retry later", http.StatusTooManyRequests)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/gorilla/mux"
+)
+
+// replayJobKind is the kind of alert replay jobs
+const replayJobKind = "alert_replay"
+
+// replayPageSize is the most entries loaded by one query during a replay
+const replayPageSize = 10000
+
+// replayAlertRulesHandler starts replaying stored logs through alert rules
+// in the background. Stored rules are chosen by ID, all active rules by
+// default, and rules can be given inline to try them before saving them.
+func (s *Server) replayAlertRulesHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		StartTime *time.Time          `json:"start_time"`
+		EndTime   *time.Time          `json:"end_time"`
+		Speed     float64             `json:"speed"`
+		Interval  int                 `json:"interval"` // seconds of log time between evaluations
+		RuleIDs   []int64             `json:"rule_ids"`
+		Rules     []*models.AlertRule `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.StartTime == nil || request.EndTime == nil {
+		http.Error(w, "start_time and end_time are required", http.StatusBadRequest)
+		return
+	}
+	if request.Interval == 0 {
+		request.Interval = s.config.Alerting.EvaluationInterval
+	}
+	if request.Interval < 1 || request.Interval > alerting.MaxTimeWindow {
+		http.Error(w, fmt.Sprintf("interval must be between 1 and %d seconds", alerting.MaxTimeWindow), http.StatusBadRequest)
+		return
+	}
+
+	var rules []*models.AlertRule
+	if len(request.RuleIDs) > 0 || len(request.Rules) == 0 {
+		stored, err := s.db.GetAlertRules(len(request.RuleIDs) == 0)
+		if err != nil {
+			s.logger.Errorf("Failed to get alert rules: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(request.RuleIDs) == 0 {
+			rules = stored
+		}
+		for _, id := range request.RuleIDs {
+			i := slices.IndexFunc(stored, func(rule *models.AlertRule) bool { return rule.ID == id })
+			if i < 0 {
+				http.Error(w, fmt.Sprintf("Alert rule %d not found", id), http.StatusBadRequest)
+				return
+			}
+			rules = append(rules, stored[i])
+		}
+	}
+	// Inline rules are told apart from stored ones by negative IDs
+	for i, rule := range request.Rules {
+		if err := alerting.ValidateRule(rule); err != nil {
+			http.Error(w, fmt.Sprintf("rules[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		rule.ID = int64(-1 - i)
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		http.Error(w, "No rules to replay", http.StatusBadRequest)
+		return
+	}
+
+	budgets, err := s.db.GetLatencyBudgets()
+	if err != nil {
+		s.logger.Errorf("Failed to get latency budgets: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	opts := alerting.ReplayOptions{
+		Start:                 *request.StartTime,
+		End:                   *request.EndTime,
+		Interval:              time.Duration(request.Interval) * time.Second,
+		Speed:                 request.Speed,
+		Step:                  time.Minute,
+		PatternLearningPeriod: time.Duration(s.config.Alerting.PatternLearningPeriod) * time.Second,
+		LatencyBudgets:        budgets,
+	}
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details := map[string]interface{}{
+		"start_time": opts.Start,
+		"end_time":   opts.End,
+		"speed":      opts.Speed,
+		"interval":   request.Interval,
+		"rules":      len(rules),
+	}
+	// Jobs outlive the request and stop when the server shuts down
+	job := s.jobs.Start(s.ctx, replayJobKind, details, func(ctx context.Context, job *jobs.Job) error {
+		steps := opts.End.Sub(opts.Start) / opts.Step
+		if opts.End.Sub(opts.Start)%opts.Step != 0 {
+			steps++
+		}
+		job.SetTotal(int64(steps))
+		opts.Progress = func(time.Time) { job.Advance(0, nil) }
+
+		result, err := alerting.Replay(ctx, rules, s.replayEntries, opts)
+		if err != nil {
+			return err
+		}
+		job.SetResult(result)
+		s.logger.Infof("Replayed %d entries through %d alert rules: %d alerts", result.Entries, len(rules), len(result.Alerts))
+		return nil
+	})
+	id := job.Snapshot().ID
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/alerts/replay/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getAlertReplayHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok || job.Snapshot().Kind != replayJobKind {
+		http.Error(w, "Replay not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.Snapshot())
+}
+
+// replayEntries loads the entries of [start, end), oldest first. Ranges
+// holding more than a page are split in two rather than paged by offset,
+// since entries with equal timestamps have no stable order between pages.
+func (s *Server) replayEntries(ctx context.Context, start, end time.Time) ([]*models.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := s.db.Find(ctx, &models.LogFilter{StartTime: &start, EndTime: &end, Limit: replayPageSize + 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load logs for replay: %w", err)
+	}
+	if len(entries) <= replayPageSize {
+		slices.Reverse(entries)
+		return entries, nil
+	}
+	if end.Sub(start) <= time.Second {
+		// A second this busy is paged through instead
+		for offset := replayPageSize; len(entries) == offset+1; offset += replayPageSize {
+			page, err := s.db.Find(ctx, &models.LogFilter{StartTime: &start, EndTime: &end, Limit: replayPageSize + 1, Offset: offset})
+			if err != nil {
+				return nil, fmt.Errorf("failed to load logs for replay: %w", err)
+			}
+			entries = append(entries[:offset], page...)
+		}
+		slices.Reverse(entries)
+		return entries, nil
+	}
+
+	mid := start.Add(end.Sub(start) / 2)
+	earlier, err := s.replayEntries(ctx, start, mid)
+	if err != nil {
+		return nil, err
+	}
+	later, err := s.replayEntries(ctx, mid, end)
+	if err != nil {
+		return nil, err
+	}
+	return append(earlier, later...), nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogsForReport(t *testing.T) {
+	s := newTestServer(t, nil)
+	now := time.Now().Truncate(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: now.Add(-time.Duration(i) * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1",
+			Method: "GET", Path: "/", StatusCode: 200,
+		}))
+	}
+
+	logs, truncated, err := s.getLogsForReport(context.Background(), nil, 3)
+	require.NoError(t, err)
+	assert.Len(t, logs, 3)
+	assert.True(t, truncated, "a full page may leave entries out")
+
+	logs, truncated, err = s.getLogsForReport(context.Background(), nil, 4)
+	require.NoError(t, err)
+	assert.Len(t, logs, 3)
+	assert.False(t, truncated)
+
+	// A smaller limit asked for in the filters is not a truncation
+	logs, truncated, err = s.getLogsForReport(context.Background(), &models.LogFilter{Limit: 2}, 4)
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+	assert.False(t, truncated)
+}
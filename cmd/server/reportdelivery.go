@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/notify"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+)
+
+// deliverReport posts the summary of a scheduled report to the chat
+// channels subscribed to its schedule. The report is already stored, so
+// failing to post it is only logged.
+func (s *Server) deliverReport(schedule string, data *reporting.ReportData, files []string) {
+	if s.reportDelivery == nil {
+		return
+	}
+
+	summary := &notify.ReportSummary{
+		Title:     data.Title,
+		TimeRange: data.TimeRange,
+		Requests:  data.Summary.TotalRequests,
+		ErrorRate: data.Summary.ErrorRate,
+	}
+	summary.TopOffender, summary.OffenderErrors = topOffender(data)
+	if len(files) > 0 {
+		if id, ok := reporting.ReportID(filepath.Base(files[0])); ok {
+			summary.Link = strings.TrimRight(s.config.Server.PublicURL, "/") + "/api/v1/reports/" + id + "/bundle"
+		}
+	}
+
+	if err := s.reportDelivery.Deliver(schedule, summary); err != nil {
+		s.logger.Errorf("Failed to deliver %s report: %v", schedule, err)
+	}
+}
+
+// topOffender returns the source IP with the most 4xx and 5xx responses
+// among the report's entries, preferring the lower IP on a tie
+func topOffender(data *reporting.ReportData) (string, int64) {
+	errorCounts := make(map[string]int64)
+	for _, entry := range data.LogEntries {
+		if entry.StatusCode >= 400 && entry.SourceIP != "" {
+			errorCounts[entry.SourceIP]++
+		}
+	}
+
+	var offender string
+	var most int64
+	for ip, count := range errorCounts {
+		if count > most || (count == most && ip < offender) {
+			offender, most = ip, count
+		}
+	}
+	return offender, most
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledReportDelivery(t *testing.T) {
+	posted := make(chan map[string]interface{}, 1)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&message)
+		posted <- message
+	}))
+	defer slack.Close()
+
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.Server.PublicURL = "https://logs.example.com"
+		cfg.Reports.Delivery = []config.ReportDelivery{{Name: "ops", Type: "slack", URL: slack.URL, Schedules: []string{"daily"}}}
+	})
+
+	now := time.Now().Truncate(time.Hour)
+	for _, entry := range []struct {
+		ip     string
+		status int
+	}{{"10.0.0.1", 200}, {"10.0.0.9", 500}, {"10.0.0.9", 404}} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: now.Add(-time.Hour), LogType: "nginx", SourceIP: entry.ip,
+			Method: "GET", Path: "/", StatusCode: entry.status,
+		}))
+	}
+
+	scheduledJob(t, s, dailyReportSchedule).Job.Run()
+
+	select {
+	case message := <-posted:
+		text, _ := message["text"].(string)
+		assert.Contains(t, text, "Daily Log Analysis Report")
+		assert.Contains(t, text, "Total requests: 3")
+		assert.Contains(t, text, "Top offender: 10.0.0.9 (2 errors)")
+		body, _ := json.Marshal(message["blocks"])
+		assert.Contains(t, string(body), `"url":"https://logs.example.com/api/v1/reports/`)
+	default:
+		t.Fatal("the daily report was not posted")
+	}
+
+	scheduledJob(t, s, weeklyReportSchedule).Job.Run()
+	assert.Empty(t, posted, "the delivery is not subscribed to weekly reports")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+)
+
+// reportMetricsHandler renders the key numbers of the latest scheduled
+// reports in the OpenMetrics text format, for monitoring to scrape. The
+// report parameter limits the output to one kind of report.
+func (s *Server) reportMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	reports := reporting.KPISnapshotReports
+	if report := r.URL.Query().Get("report"); report != "" {
+		if !slices.Contains(reports, report) {
+			http.Error(w, "report must be daily or weekly", http.StatusBadRequest)
+			return
+		}
+		reports = []string{report}
+	}
+
+	var snapshots []*reporting.KPISnapshot
+	for _, report := range reports {
+		snapshot, err := s.reporter.LatestKPISnapshot(report)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			s.logger.Errorf("Failed to read %s report metrics: %v", report, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if len(snapshots) == 0 {
+		http.Error(w, "No scheduled report has been generated yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", reporting.OpenMetricsContentType)
+	if err := reporting.WriteOpenMetrics(w, snapshots); err != nil {
+		s.logger.Errorf("Failed to write report metrics: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledReportMetrics(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/reports/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "no scheduled report has run yet")
+
+	now := time.Now().Truncate(time.Hour)
+	for _, status := range []int{200, 200, 500} {
+		require.NoError(t, s.db.InsertLogEntry(&models.LogEntry{
+			Timestamp: now.Add(-2 * time.Hour), LogType: "nginx", SourceIP: "10.0.0.1",
+			Method: "GET", Path: "/", StatusCode: status, ProcessingTime: 0.1,
+		}))
+	}
+
+	daily := scheduledJob(t, s, dailyReportSchedule)
+	saturday := time.Date(2024, 1, 6, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 1, 7, 2, 0, 0, 0, time.Local), daily.Schedule.Next(saturday))
+	daily.Job.Run()
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/reports/metrics?report=daily", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `log_analyzer_report_requests{report="daily"} 3`)
+	assert.Contains(t, w.Body.String(), `log_analyzer_report_unique_ips{report="daily"} 1`)
+}
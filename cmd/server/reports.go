@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/gorilla/mux"
+)
+
+// reportJobKind tells report generation jobs apart from other jobs
+const reportJobKind = "report"
+
+// reportFormats lists the files generated for each report format
+var reportFormats = map[string][]string{
+	"html":     {"html"},
+	"csv":      {"csv", "latency", "useragents"},
+	"both":     {"html", "csv", "latency", "useragents"},
+	"xlsx":     {"xlsx"},
+	"json":     {"json"},
+	"ndjson":   {"ndjson"},
+	"markdown": {"markdown"},
+}
+
+// errorReportFormats lists the files generated for each format of an
+// error report
+var errorReportFormats = map[string][]string{
+	"html": {"errors"},
+	"csv":  {"errors_csv"},
+	"both": {"errors", "errors_csv"},
+}
+
+// reportTypes lists the formats of each report type
+var reportTypes = map[string]map[string][]string{
+	"":         reportFormats,
+	"standard": reportFormats,
+	"errors":   errorReportFormats,
+}
+
+// errorFilter narrows a report's filters to 4xx and 5xx responses
+func errorFilter(filters *models.LogFilter) *models.LogFilter {
+	filter := models.LogFilter{}
+	if filters != nil {
+		filter = *filters
+	}
+	filter.MinStatusCode = max(filter.MinStatusCode, 400)
+	return &filter
+}
+
+// csvExport selects how a report's CSV or NDJSON export of entries is
+// written
+type csvExport struct {
+	// stream exports every entry the filter matches, read from a database
+	// cursor, rather than the entries read for the report
+	stream bool
+	filter *models.LogFilter
+	// compress gzips the export
+	compress bool
+}
+
+// cursor returns the entries to export: every entry the filter matches
+// when streaming, and otherwise the entries read for the report
+func (e csvExport) cursor(ctx context.Context, db storage.LogRepository, data *reporting.ReportData) reporting.EntryCursor {
+	if !e.stream {
+		return reporting.SliceCursor(data.LogEntries)
+	}
+	filter := models.LogFilter{}
+	if e.filter != nil {
+		filter = *e.filter
+	}
+	return func(fn func(*models.LogEntry) error) error {
+		return db.Stream(ctx, &filter, fn)
+	}
+}
+
+// generateReportFile generates one file of a report and returns its
+// location
+func (s *Server) generateReportFile(ctx context.Context, data *reporting.ReportData, name, file string, export csvExport) (string, error) {
+	switch file {
+	case "html":
+		return s.reporter.GenerateHTMLReport(data, name)
+	case "csv":
+		if export.stream || export.compress {
+			return s.reporter.GenerateCSVStream(data, name, export.cursor(ctx, s.db, data), export.compress)
+		}
+		return s.reporter.GenerateCSVReport(data, name)
+	case "json":
+		return s.reporter.GenerateJSONReport(data, name)
+	case "markdown":
+		return s.reporter.GenerateMarkdownReport(data, name)
+	case "ndjson":
+		if export.stream || export.compress {
+			return s.reporter.GenerateNDJSONStream(data, name, export.cursor(ctx, s.db, data), export.compress)
+		}
+		return s.reporter.GenerateNDJSONReport(data, name)
+	case "latency":
+		return s.reporter.GenerateLatencyCSVReport(data, name)
+	case "useragents":
+		return s.reporter.GenerateUserAgentCSVReport(data, name)
+	case "errors":
+		return s.reporter.GenerateErrorReport(data, name)
+	case "errors_csv":
+		return s.reporter.GenerateErrorCSVReport(data, name)
+	default:
+		return s.reporter.GenerateXLSXReport(data, name)
+	}
+}
+
+// getReportJobHandler reports a report job's progress through its files
+// and, once it has finished, the files generated
+func (s *Server) getReportJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok || job.Snapshot().Kind != reportJobKind {
+		http.Error(w, "Report job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.Snapshot())
+}
+
+// serveReportFileHandler serves reports, including compliance pack files,
+// by their path under /reports/
+func (s *Server) serveReportFileHandler(w http.ResponseWriter, r *http.Request) {
+	s.serveReport(w, r, strings.TrimPrefix(r.URL.Path, "/reports/"))
+}
+
+// serveReport redirects to a signed URL when the store issues them and
+// signed_urls is on, and otherwise serves the report itself
+func (s *Server) serveReport(w http.ResponseWriter, r *http.Request, name string) {
+	store := s.reporter.Store()
+	storage := s.config.Reports.Storage
+
+	if storage.SignedURLs {
+		if _, err := store.Stat(name); err != nil {
+			s.reportError(w, name, err)
+			return
+		}
+		signed, err := store.SignedURL(name, time.Duration(storage.URLExpiry)*time.Minute)
+		if err == nil {
+			http.Redirect(w, r, signed, http.StatusFound)
+			return
+		}
+		if !errors.Is(err, reportstore.ErrNoSignedURLs) {
+			s.reportError(w, name, err)
+			return
+		}
+	}
+
+	body, info, err := store.Open(name)
+	if err != nil {
+		s.reportError(w, name, err)
+		return
+	}
+	defer body.Close()
+	s.liftDeadlines(w)
+
+	if etag := s.reportETag(name, info); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	// Every store supports range requests once the size is known
+	if info.Size >= 0 {
+		content := reportstore.NewSeeker(store, name, body, info.Size)
+		http.ServeContent(w, r, path.Base(name), info.ModTime, content)
+		return
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+	if !info.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodHead {
+		io.Copy(w, body)
+	}
+}
+
+// reportETagSkew is how much later than its metadata a report file may
+// have been modified, allowing for the clock of an object store
+const reportETagSkew = time.Minute
+
+// reportETag returns a strong ETag for a report from the SHA-256 recorded
+// when it was saved, or "" when none was recorded or the file changed
+// since. Conditional and resumed downloads then fall back to
+// Last-Modified.
+func (s *Server) reportETag(name string, info reportstore.Object) string {
+	meta, err := s.db.GetReportFile(name)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			s.logger.Warnf("Failed to get metadata of report %s: %v", name, err)
+		}
+		return ""
+	}
+	if meta.Size != info.Size || info.ModTime.After(meta.CreatedAt.Add(reportETagSkew)) {
+		return ""
+	}
+	return `"` + meta.SHA256 + `"`
+}
+
+func (s *Server) reportError(w http.ResponseWriter, name string, err error) {
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	}
+	s.logger.Errorf("Failed to serve report %s: %v", name, err)
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// downloadReportBundleHandler streams every file of a report run as one
+// ZIP with a manifest
+func (s *Server) downloadReportBundleHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	files, err := s.reporter.ReportFiles(id)
+	if err != nil {
+		s.reportError(w, id, err)
+		return
+	}
+
+	s.liftDeadlines(w)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": id + ".zip"}))
+	// The ZIP is written as it is built, so errors can only be logged
+	if err := s.reporter.WriteBundle(w, id, files); err != nil {
+		s.logger.Errorf("Failed to write bundle of report %s: %v", id, err)
+	}
+}
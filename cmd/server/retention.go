@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/archive"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/retention"
+	"github.com/gorilla/mux"
+)
+
+// maxLogTypeLength is the longest log type entries are stored with
+const maxLogTypeLength = 20
+
+// setupRetention runs the retention cleanup on its schedule
+func (s *Server) setupRetention() error {
+	schedule := s.config.Retention.Schedule
+	if _, err := s.cron.AddFunc(schedule, func() {
+		s.logger.Info("Starting scheduled database cleanup")
+		if _, _, err := s.cleanupOldLogs(context.Background()); err != nil {
+			s.logger.Errorf("Failed to cleanup old logs: %v", err)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return nil
+}
+
+// retentionPolicy returns the retention of every log type, with the stored
+// policies over the configured ones
+func (s *Server) retentionPolicy() (*retention.Policy, error) {
+	stored, err := s.db.GetRetentionPolicies()
+	if err != nil {
+		return nil, err
+	}
+	return retention.Resolve(s.config.Retention.DefaultDays, s.config.Retention.LogTypes, stored), nil
+}
+
+// cleanupOldLogs removes the entries past the retention of their log type
+// and returns how many were removed. With archiving enabled they are
+// first exported to the returned archive, nil when nothing was archived.
+func (s *Server) cleanupOldLogs(ctx context.Context) (int64, *archive.Manifest, error) {
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		return 0, nil, err
+	}
+	now := time.Now()
+
+	var writer *archive.Writer
+	if s.config.Archive.Enabled {
+		if writer, err = s.archiver.NewWriter(); err != nil {
+			return 0, nil, fmt.Errorf("failed to start archive: %w", err)
+		}
+	}
+
+	// Entries past every retention go first, so whole partitions and
+	// chunks are dropped where the database keeps them
+	cutoffs := append([]models.RetentionCutoff{{Before: policy.Earliest(now)}}, policy.Cutoffs(now)...)
+	var deletedCount int64
+	for _, cutoff := range cutoffs {
+		if writer != nil {
+			if err := s.archiveExpired(ctx, cutoff, writer); err != nil {
+				return deletedCount, nil, err
+			}
+		}
+		deleted, err := s.db.ExpireEntries(ctx, cutoff)
+		deletedCount += deleted
+		if err != nil {
+			return deletedCount, nil, err
+		}
+	}
+
+	s.logger.Infof("Cleaned up %d old log entries", deletedCount)
+	if writer == nil || writer.Manifest().Entries == 0 {
+		return deletedCount, nil, nil
+	}
+	manifest := writer.Manifest()
+	s.logger.Infof("Archived %d log entries to %s", manifest.Entries, s.archiver.Location(manifest.ID))
+	return deletedCount, manifest, nil
+}
+
+// archiveExpired exports the entries a cutoff expires, returning once
+// they are stored and can be removed
+func (s *Server) archiveExpired(ctx context.Context, cutoff models.RetentionCutoff, writer *archive.Writer) error {
+	var afterID int64
+	for {
+		entries, err := s.db.ScanExpired(ctx, cutoff, afterID, s.config.Archive.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		afterID = entries[len(entries)-1].ID
+		if _, err := writer.Add(entries); err != nil {
+			return fmt.Errorf("failed to archive expired entries: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to archive expired entries: %w", err)
+	}
+	return nil
+}
+
+// getRetentionHandler lists the default retention and that of every log
+// type with its own
+func (s *Server) getRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		s.logger.Errorf("Failed to get retention policies: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"default_days": policy.DefaultDays,
+		"policies":     policy.Rules,
+		"schedule":     s.config.Retention.Schedule,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// setRetentionPolicyHandler sets how many days entries of a log type are
+// kept, replacing its configured retention
+func (s *Server) setRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	logType := mux.Vars(r)["log_type"]
+	if len(logType) > maxLogTypeLength {
+		http.Error(w, fmt.Sprintf("log type must be at most %d characters", maxLogTypeLength), http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Days int `json:"days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Days < 1 {
+		http.Error(w, "days must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	policy := &models.RetentionPolicy{
+		LogType:   logType,
+		Days:      request.Days,
+		UpdatedBy: requestActor(r),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.db.SetRetentionPolicy(policy); err != nil {
+		s.logger.Errorf("Failed to set retention policy: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionRetentionSet, policy.UpdatedBy, "retention:"+logType, map[string]interface{}{
+		"days": policy.Days,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// deleteRetentionPolicyHandler removes the policy of a log type, returning
+// it to its configured retention or the default
+func (s *Server) deleteRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	logType := mux.Vars(r)["log_type"]
+
+	found, err := s.db.DeleteRetentionPolicy(logType)
+	if err != nil {
+		s.logger.Errorf("Failed to delete retention policy: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Retention policy not found", http.StatusNotFound)
+		return
+	}
+	s.recordAudit(audit.ActionRetentionRestored, requestActor(r), "retention:"+logType, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runRetentionCleanupHandler runs the retention cleanup now instead of at
+// its next scheduled time
+func (s *Server) runRetentionCleanupHandler(w http.ResponseWriter, r *http.Request) {
+	deleted, archived, err := s.cleanupOldLogs(r.Context())
+	if err != nil {
+		s.logger.Errorf("Failed to cleanup old logs: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	details := map[string]interface{}{
+		"deleted": deleted,
+	}
+	if archived != nil {
+		details["archive"] = archived.ID
+	}
+	s.recordAudit(audit.ActionRetentionCleanup, requestActor(r), "retention", details)
+
+	response := map[string]interface{}{
+		"deleted": deleted,
+	}
+	if archived != nil {
+		response["archive"] = map[string]interface{}{
+			"id":      archived.ID,
+			"entries": archived.Entries,
+			"files":   len(archived.Files),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/robots"
+)
+
+// maxCrawlEntries bounds how many crawler requests a crawl report covers
+const maxCrawlEntries = 200000
+
+// robotsFetchTimeout bounds fetching a site's robots.txt
+const robotsFetchTimeout = 10 * time.Second
+
+// crawlReportRequest is the body of POST /api/v1/reports/robots
+type crawlReportRequest struct {
+	ReportName string     `json:"report_name"`
+	RobotsURL  string     `json:"robots_url"`
+	RobotsTxt  string     `json:"robots_txt"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	Format     string     `json:"format"` // html, json
+}
+
+func (s *Server) generateCrawlReportHandler(w http.ResponseWriter, r *http.Request) {
+	var request crawlReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if (request.RobotsURL == "") == (request.RobotsTxt == "") {
+		http.Error(w, "Exactly one of robots_url or robots_txt is required", http.StatusBadRequest)
+		return
+	}
+	if request.ReportName == "" {
+		request.ReportName = "crawl_budget"
+	}
+	if request.Format == "" {
+		request.Format = "html"
+	}
+	if request.Format != "html" && request.Format != "json" {
+		http.Error(w, "Format must be html or json", http.StatusBadRequest)
+		return
+	}
+
+	// Default to the last day
+	end := time.Now()
+	start := end.AddDate(0, 0, -1)
+	if request.StartTime != nil {
+		start = *request.StartTime
+	}
+	if request.EndTime != nil {
+		end = *request.EndTime
+	}
+
+	source := "uploaded"
+	body := io.Reader(strings.NewReader(request.RobotsTxt))
+	if request.RobotsURL != "" {
+		source = request.RobotsURL
+		content, err := fetchRobots(request.RobotsURL)
+		if err != nil {
+			s.logger.Warnf("Failed to fetch robots.txt from %s: %v", request.RobotsURL, err)
+			http.Error(w, fmt.Sprintf("Failed to fetch robots.txt: %v", err), http.StatusBadGateway)
+			return
+		}
+		body = strings.NewReader(content)
+	}
+
+	rules, err := robots.Parse(body)
+	if err != nil {
+		http.Error(w, "Invalid robots.txt", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.db.GetUserAgentActivity(robots.Keywords(), start, end, maxCrawlEntries)
+	if err != nil {
+		s.logger.Errorf("Failed to get crawler activity: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	summary := reporting.AnalyzeCrawl(rules, entries)
+	response := map[string]interface{}{
+		"summary":       summary,
+		"count":         len(summary.Bots),
+		"robots_source": source,
+		"start_time":    start,
+		"end_time":      end,
+	}
+
+	if request.Format == "html" {
+		reportFile, err := s.reporter.GenerateCrawlReport(&reporting.CrawlReportData{
+			Title:        request.ReportName,
+			GeneratedAt:  time.Now(),
+			TimeRange:    fmt.Sprintf("%s - %s", start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			RobotsSource: source,
+			Summary:      summary,
+		}, request.ReportName)
+		if err != nil {
+			s.logger.Errorf("Failed to generate crawl report: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response["generated_files"] = []string{reportFile}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// fetchRobots downloads a robots.txt file. As RFC 9309 specifies, a 4xx
+// response means there are no restrictions, so it yields an empty file.
+func fetchRobots(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL %q", rawURL)
+	}
+
+	client := &http.Client{Timeout: robotsFetchTimeout}
+	resp, err := client.Get(parsed.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		content, err := io.ReadAll(io.LimitReader(resp.Body, robots.MaxSize))
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
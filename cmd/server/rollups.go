@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/cache"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/gorilla/mux"
+)
+
+// rollupJobKind tells rollup rebuilds apart from other jobs
+const rollupJobKind = "rollup"
+
+// rollupIntervals are refreshed in this order, hours first as they are
+// the fresher
+var rollupIntervals = []string{models.RollupHour, models.RollupDay}
+
+// rollupState tracks the scheduled refresh of traffic rollups
+type rollupState struct {
+	// running keeps a slow refresh from overlapping the next
+	running sync.Mutex
+
+	mu sync.Mutex
+	// through is when the last refresh started; rollups hold every entry
+	// stored before it
+	through time.Time
+}
+
+func (r *rollupState) refreshedThrough() (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.through, !r.through.IsZero()
+}
+
+func (r *rollupState) refreshed(through time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.through = through
+}
+
+// setupRollups refreshes the traffic rollups on their schedule, and
+// builds them from the stored entries when there are none yet
+func (s *Server) setupRollups() error {
+	cfg := s.config.Rollups
+	if !cfg.Enabled {
+		return nil
+	}
+	if _, err := s.cron.AddFunc(cfg.Schedule, func() {
+		if err := s.refreshRollups(s.ctx); err != nil {
+			s.logger.Errorf("Failed to refresh traffic rollups: %v", err)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", cfg.Schedule, err)
+	}
+
+	totals, err := s.db.SummarizeTraffic(s.ctx, &models.TrafficQuery{
+		Interval: models.RollupDay,
+		Start:    time.Now().AddDate(0, 0, -cfg.DailyDays),
+		End:      time.Now().Add(24 * time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+	if totals[0].Requests == 0 {
+		s.backfillRollups()
+		return nil
+	}
+	// Until a refresh, the freshness of the stored rollups is unknown
+	go func() {
+		if err := s.refreshRollups(s.ctx); err != nil {
+			s.logger.Errorf("Failed to refresh traffic rollups: %v", err)
+		}
+	}()
+	return nil
+}
+
+// refreshRollups recomputes the buckets of the last lookback_hours, and
+// removes rollups past their retention
+func (s *Server) refreshRollups(ctx context.Context) error {
+	if !s.rollups.running.TryLock() {
+		s.logger.Warn("Skipping traffic rollup refresh: the previous refresh has not finished")
+		return nil
+	}
+	defer s.rollups.running.Unlock()
+
+	cfg := s.config.Rollups
+	now := time.Now()
+	start := now.Add(-time.Duration(cfg.LookbackHours) * time.Hour)
+	for _, interval := range rollupIntervals {
+		if err := s.refreshBuckets(ctx, interval, start, now, nil); err != nil {
+			return err
+		}
+	}
+	s.rollups.refreshed(now)
+
+	kept := map[string]int{models.RollupHour: cfg.HourlyDays, models.RollupDay: cfg.DailyDays}
+	for _, interval := range rollupIntervals {
+		removed, err := s.db.DeleteTrafficRollups(ctx, interval, now.AddDate(0, 0, -kept[interval]))
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			s.logger.Infof("Removed %d traffic rollups of %s buckets past retention", removed, interval)
+		}
+	}
+	return nil
+}
+
+// refreshBuckets recomputes the interval's buckets overlapping [start,
+// end), calling progress after each
+func (s *Server) refreshBuckets(ctx context.Context, interval string, start, end time.Time, progress func(error)) error {
+	length := models.RollupIntervals[interval]
+	for bucket := models.RollupBucket(interval, start); bucket.Before(end); bucket = bucket.Add(length) {
+		err := s.db.RefreshTrafficRollups(ctx, interval, bucket)
+		if progress != nil {
+			progress(err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupBuckets counts the interval's buckets overlapping [start, end)
+func rollupBuckets(interval string, start, end time.Time) int64 {
+	length := models.RollupIntervals[interval]
+	first := models.RollupBucket(interval, start)
+	return int64((end.Sub(first) + length - 1) / length)
+}
+
+// backfillRollups starts a job building the rollups kept by the
+// configuration from the stored entries
+func (s *Server) backfillRollups() {
+	cfg := s.config.Rollups
+	now := time.Now()
+	starts := map[string]time.Time{
+		models.RollupHour: now.AddDate(0, 0, -cfg.HourlyDays),
+		models.RollupDay:  now.AddDate(0, 0, -cfg.DailyDays),
+	}
+	details := map[string]interface{}{"backfill": true, "end_time": now}
+	s.jobs.Start(s.ctx, rollupJobKind, details, func(ctx context.Context, job *jobs.Job) error {
+		// Refreshes wait, so rollups are not taken as current until built
+		s.rollups.running.Lock()
+		defer s.rollups.running.Unlock()
+		if err := s.rebuildRollups(ctx, job, starts, now); err != nil {
+			return err
+		}
+		s.rollups.refreshed(now)
+		return nil
+	})
+	s.logger.Info("Building traffic rollups from the stored entries")
+}
+
+// rebuildRollups recomputes every bucket from its interval's start to end
+func (s *Server) rebuildRollups(ctx context.Context, job *jobs.Job, starts map[string]time.Time, end time.Time) error {
+	var total int64
+	for _, interval := range rollupIntervals {
+		total += rollupBuckets(interval, starts[interval], end)
+	}
+	job.SetTotal(total)
+
+	for _, interval := range rollupIntervals {
+		if err := s.refreshBuckets(ctx, interval, starts[interval], end, func(err error) {
+			job.Advance(0, err)
+		}); err != nil {
+			return err
+		}
+	}
+	job.SetResult(map[string]interface{}{"buckets": total})
+	s.logger.Infof("Rebuilt %d traffic rollup buckets", total)
+	return nil
+}
+
+// startRollupRebuild starts a job recomputing the rollups of [start, end)
+func (s *Server) startRollupRebuild(start, end time.Time) *jobs.Job {
+	details := map[string]interface{}{"start_time": start, "end_time": end}
+	// Jobs outlive the request and stop when the server shuts down
+	return s.jobs.Start(s.ctx, rollupJobKind, details, func(ctx context.Context, job *jobs.Job) error {
+		starts := map[string]time.Time{models.RollupHour: start, models.RollupDay: start}
+		return s.rebuildRollups(ctx, job, starts, end)
+	})
+}
+
+// rebuildRollupsHandler starts recomputing the rollups of a time range,
+// for entries imported or removed there after it was rolled up
+func (s *Server) rebuildRollupsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.config.Rollups.Enabled {
+		http.Error(w, "Traffic rollups are disabled", http.StatusConflict)
+		return
+	}
+	var request struct {
+		StartTime *time.Time `json:"start_time"`
+		EndTime   *time.Time `json:"end_time"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.StartTime == nil || request.EndTime == nil {
+		http.Error(w, "start_time and end_time are required", http.StatusBadRequest)
+		return
+	}
+	if !request.EndTime.After(*request.StartTime) {
+		http.Error(w, "end_time must be after start_time", http.StatusBadRequest)
+		return
+	}
+
+	start, end := *request.StartTime, *request.EndTime
+	id := s.startRollupRebuild(start, end).Snapshot().ID
+
+	s.recordAudit(audit.ActionRollupsRebuilt, requestActor(r), "rollups", map[string]interface{}{
+		"job_id":     id,
+		"start_time": start,
+		"end_time":   end,
+	})
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/rollups/rebuild/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getRollupRebuildHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok || job.Snapshot().Kind != rollupJobKind {
+		http.Error(w, "Rollup rebuild not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.Snapshot())
+}
+
+// trafficStats sums the rollups of the start_time, end_time, log_type and
+// interval parameters, the last 24 hours by the hour by default. Buckets
+// starting in the range are counted whole.
+func (s *Server) trafficStats(ctx context.Context, params url.Values) (map[string]interface{}, error) {
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, params.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, params.Get("end_time")); err == nil {
+		end = t
+	}
+	interval := params.Get("interval")
+	if interval == "" {
+		interval = models.RollupHour
+		if end.Sub(start) > 7*24*time.Hour {
+			interval = models.RollupDay
+		}
+	}
+	if _, ok := models.RollupIntervals[interval]; !ok {
+		return nil, fmt.Errorf("%w: interval must be %s or %s", errBadStatsQuery, models.RollupHour, models.RollupDay)
+	}
+	start = models.RollupBucket(interval, start)
+	logType := params.Get("log_type")
+
+	// Requests with the same parameters share a result, so the default
+	// window is the last 24 hours as of when it was cached
+	key := cache.Key("stats", "traffic", url.Values{
+		"start_time": {params.Get("start_time")},
+		"end_time":   {params.Get("end_time")},
+		"interval":   {interval},
+		"log_type":   {logType},
+	}.Encode())
+	var stats map[string]interface{}
+	err := s.cachedStats(ctx, key, &stats, func() error {
+		summarize := func(limit int, groupBy ...string) ([]models.TrafficRollup, error) {
+			return s.db.SummarizeTraffic(ctx, &models.TrafficQuery{
+				Interval: interval, Start: start, End: end, LogType: logType, GroupBy: groupBy, Limit: limit,
+			})
+		}
+		totals, err := summarize(0)
+		if err != nil {
+			return err
+		}
+		series, err := summarize(0, "bucket")
+		if err != nil {
+			return err
+		}
+		logTypes, err := summarize(0, "log_type")
+		if err != nil {
+			return err
+		}
+		statusCodes, err := summarize(0, "status_code")
+		if err != nil {
+			return err
+		}
+		topPaths, err := summarize(10, "path")
+		if err != nil {
+			return err
+		}
+
+		total := totals[0]
+		stats = map[string]interface{}{
+			"interval":     interval,
+			"start_time":   start,
+			"end_time":     end,
+			"log_type":     logType,
+			"requests":     total.Requests,
+			"errors":       total.Errors,
+			"error_rate":   errorRate(total),
+			"bytes":        total.Bytes,
+			"series":       trafficRows(series, func(rollup models.TrafficRollup) (string, interface{}) { return "bucket", rollup.Bucket }),
+			"log_types":    trafficRows(logTypes, func(rollup models.TrafficRollup) (string, interface{}) { return "log_type", rollup.LogType }),
+			"status_codes": trafficRows(statusCodes, func(rollup models.TrafficRollup) (string, interface{}) { return "status_code", rollup.StatusCode }),
+			"top_paths":    trafficRows(topPaths, func(rollup models.TrafficRollup) (string, interface{}) { return "path", rollup.Path }),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Freshness is not cached with the totals
+	stats["rolled_up_through"] = nil
+	if through, ok := s.rollups.refreshedThrough(); ok {
+		stats["rolled_up_through"] = through
+	}
+	return stats, nil
+}
+
+// errBadStatsQuery marks stats parameters the client got wrong
+var errBadStatsQuery = errors.New("invalid stats query")
+
+func errorRate(rollup models.TrafficRollup) float64 {
+	if rollup.Requests == 0 {
+		return 0
+	}
+	return float64(rollup.Errors) / float64(rollup.Requests) * 100
+}
+
+// trafficRows lists summed rollups with the field they were grouped by.
+// Unique IPs are left out, as sums of them count an IP once per rollup.
+func trafficRows(rollups []models.TrafficRollup, group func(models.TrafficRollup) (string, interface{})) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(rollups))
+	for i, rollup := range rollups {
+		name, value := group(rollup)
+		rows[i] = map[string]interface{}{
+			name:         value,
+			"requests":   rollup.Requests,
+			"errors":     rollup.Errors,
+			"error_rate": errorRate(rollup),
+			"bytes":      rollup.Bytes,
+		}
+	}
+	return rows
+}
+
+// attachTraffic gives a report the rollups' totals of its period when the
+// period is whole hours the rollups have been refreshed past, and the
+// filter only selects a period and log type
+func (s *Server) attachTraffic(data *reporting.ReportData, filter *models.LogFilter) {
+	if !s.config.Rollups.Enabled || filter == nil || filter.StartTime == nil || filter.EndTime == nil ||
+		filter.StatusCode != nil || filter.MinStatusCode > 0 || filter.SourceIP != "" || filter.Path != "" || filter.Method != "" {
+		return
+	}
+	start, end := *filter.StartTime, *filter.EndTime
+	if !start.Equal(start.Truncate(time.Hour)) || !end.Equal(end.Truncate(time.Hour)) {
+		return
+	}
+	if through, ok := s.rollups.refreshedThrough(); !ok || through.Before(end) {
+		return
+	}
+
+	summarize := func(limit int, groupBy ...string) ([]models.TrafficRollup, error) {
+		return s.db.SummarizeTraffic(s.ctx, &models.TrafficQuery{
+			Interval: models.RollupHour, Start: start, End: end, LogType: filter.LogType, GroupBy: groupBy, Limit: limit,
+		})
+	}
+	totals := &reporting.TrafficTotals{}
+	err := func() (err error) {
+		if totals.StatusCodes, err = summarize(0, "status_code"); err != nil {
+			return err
+		}
+		if totals.TopPaths, err = summarize(10, "path"); err != nil {
+			return err
+		}
+		totals.Hourly, err = summarize(0, "bucket")
+		return err
+	}()
+	if err != nil {
+		s.logger.Errorf("Failed to get traffic rollups for report: %v", err)
+		return
+	}
+	for _, rollup := range totals.StatusCodes {
+		totals.Requests += rollup.Requests
+		totals.Errors += rollup.Errors
+	}
+	data.Traffic = totals
+}
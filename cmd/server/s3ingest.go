@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/s3"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/gorilla/mux"
+)
+
+// jobRetention is how long finished jobs can be polled
+const jobRetention = 24 * time.Hour
+
+// s3IngestJob describes one S3 ingestion request
+type s3IngestJob struct {
+	client     *s3.Client
+	bucket     string
+	prefix     string
+	logType    string
+	maxObjects int
+}
+
+func (s *Server) ingestS3Handler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Bucket     string `json:"bucket"`
+		Prefix     string `json:"prefix"`
+		Region     string `json:"region"`
+		LogType    string `json:"log_type"`
+		MaxObjects int    `json:"max_objects"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Bucket == "" || request.LogType == "" {
+		http.Error(w, "bucket and log_type are required", http.StatusBadRequest)
+		return
+	}
+	if !s.processor.SupportsLogType(request.LogType) {
+		http.Error(w, fmt.Sprintf("Unsupported log type: %s", request.LogType), http.StatusBadRequest)
+		return
+	}
+
+	cfg := s.config.Ingest.S3
+	if request.Region == "" {
+		request.Region = cfg.Region
+	}
+	if request.MaxObjects <= 0 || request.MaxObjects > cfg.MaxObjects {
+		request.MaxObjects = cfg.MaxObjects
+	}
+	credentials := awsauth.Credentials{AccessKeyID: cfg.AccessKeyID, SecretAccessKey: cfg.SecretAccessKey, SessionToken: cfg.SessionToken}
+	client, err := s3.NewClient(r.Context(), request.Region, credentials, cfg.Endpoint)
+	if err != nil {
+		s.logger.Errorf("Failed to create S3 client: %v", err)
+		http.Error(w, "Failed to create S3 client", http.StatusInternalServerError)
+		return
+	}
+
+	job := &s3IngestJob{
+		client:     client,
+		bucket:     request.Bucket,
+		prefix:     request.Prefix,
+		logType:    request.LogType,
+		maxObjects: request.MaxObjects,
+	}
+	details := map[string]interface{}{
+		"bucket":   request.Bucket,
+		"prefix":   request.Prefix,
+		"region":   request.Region,
+		"log_type": request.LogType,
+	}
+
+	s.startStoring()
+	// Jobs outlive the request and stop when the server shuts down
+	started := s.jobs.Start(s.ctx, "s3_ingest", details, job.run(s))
+	id := started.Snapshot().ID
+
+	s.recordAudit(audit.ActionLogsImported, requestActor(r), fmt.Sprintf("s3://%s/%s", request.Bucket, request.Prefix), map[string]interface{}{
+		"job_id":   id,
+		"region":   request.Region,
+		"log_type": request.LogType,
+	})
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/logs/ingest/jobs/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getIngestJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.Snapshot())
+}
+
+// errTooManyObjects stops the listing once more objects match than allowed
+var errTooManyObjects = errors.New("too many objects")
+
+// run lists the matching objects, then streams each through the processor.
+// A listing that exceeds maxObjects fails the job before anything is
+// imported, so a too-broad prefix is not half ingested.
+func (j *s3IngestJob) run(s *Server) func(ctx context.Context, job *jobs.Job) error {
+	return func(ctx context.Context, job *jobs.Job) error {
+		var objects []s3.Object
+		err := j.client.ListObjects(ctx, j.bucket, j.prefix, func(object s3.Object) error {
+			if len(objects) == j.maxObjects {
+				return errTooManyObjects
+			}
+			objects = append(objects, object)
+			return nil
+		})
+		if errors.Is(err, errTooManyObjects) {
+			return fmt.Errorf("more than %d objects match s3://%s/%s; narrow the prefix", j.maxObjects, j.bucket, j.prefix)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list s3://%s/%s: %w", j.bucket, j.prefix, err)
+		}
+		job.SetTotal(int64(len(objects)))
+
+		for _, object := range objects {
+			if err := s.waitForCapacity(ctx); err != nil {
+				return err
+			}
+			n, err := j.ingestObject(ctx, s, object.Key)
+			if err != nil {
+				s.logger.Errorf("Failed to ingest s3://%s/%s: %v", j.bucket, object.Key, err)
+				err = fmt.Errorf("%s: %w", object.Key, err)
+			}
+			job.Advance(n, err)
+		}
+
+		s.logger.Infof("Ingested %d objects from s3://%s/%s", len(objects), j.bucket, j.prefix)
+		return nil
+	}
+}
+
+// ingestObject processes one object and returns the bytes read from it
+// after decompression
+func (j *s3IngestJob) ingestObject(ctx context.Context, s *Server, key string) (int64, error) {
+	body, err := j.client.GetObject(ctx, j.bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	counter := &countingReader{r: body}
+	err = s.processor.ProcessFile(counter, j.logType)
+	return counter.n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
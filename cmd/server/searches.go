@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/gorilla/mux"
+)
+
+// searchNamePattern matches the names searches are saved under
+var searchNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,100}$`)
+
+// searchOwner is whose saved searches a request works with: the signed-in
+// user, or everyone's shared searches without sign-in
+func searchOwner(r *http.Request) string {
+	if identity := requestIdentity(r); identity != nil {
+		return identity.Subject
+	}
+	return ""
+}
+
+// requestSavedSearch returns the requester's saved search a request
+// names, responding with the error if there is none
+func (s *Server) requestSavedSearch(w http.ResponseWriter, r *http.Request, name string) (*models.SavedSearch, bool) {
+	search, err := s.db.GetSavedSearch(searchOwner(r), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("No saved search named %q", name), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get saved search: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return search, true
+}
+
+// searchName validates the name of a saved search's path
+func searchName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := mux.Vars(r)["name"]
+	if !searchNamePattern.MatchString(name) {
+		http.Error(w, "Search names are 1 to 100 letters, digits, underscores or hyphens", http.StatusBadRequest)
+		return "", false
+	}
+	return name, true
+}
+
+// listSavedSearchesHandler lists the requester's saved searches
+func (s *Server) listSavedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	searches, err := s.db.GetSavedSearches(searchOwner(r))
+	if err != nil {
+		s.logger.Errorf("Failed to get saved searches: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if searches == nil {
+		searches = []*models.SavedSearch{}
+	}
+
+	response := map[string]interface{}{
+		"searches": searches,
+		"count":    len(searches),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getSavedSearchHandler returns one of the requester's saved searches
+func (s *Server) getSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := searchName(w, r)
+	if !ok {
+		return
+	}
+
+	search, err := s.db.GetSavedSearch(searchOwner(r), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Saved search not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get saved search: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// saveSavedSearchHandler saves a filter under a name, replacing the
+// requester's search of that name if there is one
+func (s *Server) saveSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := searchName(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Description string           `json:"description"`
+		Filter      models.LogFilter `json:"filter"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(req.Description) > 500 {
+		http.Error(w, "description is limited to 500 characters", http.StatusBadRequest)
+		return
+	}
+	if req.Filter.SortBy != "" && !slices.Contains(models.SortFields, req.Filter.SortBy) {
+		http.Error(w, "Invalid sort_by. Must be one of: "+strings.Join(models.SortFields, ", "), http.StatusBadRequest)
+		return
+	}
+	// A search is run with the page size and page of each request
+	req.Filter.Limit, req.Filter.Offset = 0, 0
+
+	now := time.Now().UTC()
+	search := &models.SavedSearch{
+		Owner:       searchOwner(r),
+		Name:        name,
+		Description: req.Description,
+		Filter:      req.Filter,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.db.SaveSavedSearch(search); err != nil {
+		s.logger.Errorf("Failed to save saved search: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// The stored search has the time it was first saved
+	if stored, err := s.db.GetSavedSearch(search.Owner, name); err == nil {
+		search = stored
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// deleteSavedSearchHandler removes one of the requester's saved searches
+func (s *Server) deleteSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := searchName(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := s.db.DeleteSavedSearch(searchOwner(r), name)
+	if err != nil {
+		s.logger.Errorf("Failed to delete saved search: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Saved search not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
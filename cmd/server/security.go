@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/forward"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/scoring"
+)
+
+// maxScoredEntries bounds how many recent entries are profiled per request
+const maxScoredEntries = 100000
+
+func (s *Server) getIPScoresHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Default to the last hour
+	end := time.Now()
+	start := end.Add(-time.Hour)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		end = t
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	scorer := scoring.NewScorer()
+	if m, err := strconv.ParseInt(query.Get("min_requests"), 10, 64); err == nil && m > 0 {
+		scorer.MinRequests = m
+	}
+
+	entries, err := s.db.GetSourceActivity(start, end, maxScoredEntries)
+	if err != nil {
+		s.logger.Errorf("Failed to get source activity: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	scores := scorer.ScoreEntries(entries)
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+
+	response := map[string]interface{}{
+		"scores":     scores,
+		"count":      len(scores),
+		"entries":    len(entries),
+		"start_time": start,
+		"end_time":   end,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// maxExportedEvents bounds how many candidate entries one export reads
+const maxExportedEvents = 100000
+
+// exportSecurityEventsHandler downloads security-relevant entries as CEF or
+// LEEF lines for SIEMs that ingest files in those formats
+func (s *Server) exportSecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = forward.FormatCEF
+	}
+	if format != forward.FormatCEF && format != forward.FormatLEEF {
+		http.Error(w, "format must be cef or leef", http.StatusBadRequest)
+		return
+	}
+
+	// Default to the last day
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		end = t
+	}
+
+	logTypes, statusCodes := forward.SecurityCandidates()
+	entries, err := s.db.GetEntriesByTypeOrStatus(logTypes, statusCodes, start, end, maxExportedEvents)
+	if err != nil {
+		s.logger.Errorf("Failed to get security events: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("security_events_%s.%s", end.UTC().Format("20060102_150405"), format)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	out := bufio.NewWriter(w)
+	for _, entry := range entries {
+		if !forward.SecurityRelevant(entry) {
+			continue
+		}
+		line, err := forward.Format(format, entry)
+		if err != nil {
+			s.logger.Errorf("Failed to format security event %d: %v", entry.ID, err)
+			continue
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if err := out.Flush(); err != nil {
+		s.logger.Warnf("Failed to write security event export: %v", err)
+	}
+}
+
+// maxSecurityEntries bounds how many entries a security report covers
+const maxSecurityEntries = 200000
+
+// securityReportRequest is the body of POST /api/v1/reports/security
+type securityReportRequest struct {
+	ReportName          string     `json:"report_name"`
+	StartTime           *time.Time `json:"start_time"`
+	EndTime             *time.Time `json:"end_time"`
+	LogType             string     `json:"log_type"`
+	BruteForceThreshold int64      `json:"brute_force_threshold"`
+	BruteForceWindow    int        `json:"brute_force_window"` // seconds
+	Format              string     `json:"format"`             // html, pdf, json
+}
+
+func (s *Server) generateSecurityReportHandler(w http.ResponseWriter, r *http.Request) {
+	var request securityReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.ReportName == "" {
+		request.ReportName = "security"
+	}
+	if request.Format == "" {
+		request.Format = "html"
+	}
+	if request.Format != "html" && request.Format != "pdf" && request.Format != "json" {
+		http.Error(w, "Format must be html, pdf or json", http.StatusBadRequest)
+		return
+	}
+	if request.BruteForceThreshold < 0 || request.BruteForceWindow < 0 {
+		http.Error(w, "brute_force_threshold and brute_force_window must not be negative", http.StatusBadRequest)
+		return
+	}
+	opts := reporting.DefaultSecurityOptions()
+	if request.BruteForceThreshold > 0 {
+		opts.BruteForceThreshold = request.BruteForceThreshold
+	}
+	if request.BruteForceWindow > 0 {
+		opts.BruteForceWindow = time.Duration(request.BruteForceWindow) * time.Second
+	}
+
+	// Default to the last day
+	end := time.Now()
+	start := end.AddDate(0, 0, -1)
+	if request.StartTime != nil {
+		start = *request.StartTime
+	}
+	if request.EndTime != nil {
+		end = *request.EndTime
+	}
+
+	filter := &models.LogFilter{StartTime: &start, EndTime: &end, LogType: request.LogType, Limit: maxSecurityEntries}
+	entries, err := s.db.Find(r.Context(), filter)
+	if err != nil {
+		s.logger.Errorf("Failed to get entries for security report: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	summary := reporting.AnalyzeSecurity(entries, opts)
+	response := map[string]interface{}{
+		"summary":    summary,
+		"entries":    len(entries),
+		"truncated":  len(entries) == maxSecurityEntries,
+		"start_time": start,
+		"end_time":   end,
+	}
+
+	if request.Format != "json" {
+		data := &reporting.SecurityReportData{
+			Title:       request.ReportName,
+			GeneratedAt: time.Now(),
+			TimeRange:   fmt.Sprintf("%s - %s", start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			Options:     opts,
+			Summary:     summary,
+		}
+		generate := s.reporter.GenerateSecurityReport
+		if request.Format == "pdf" {
+			generate = s.reporter.GenerateSecurityPDFReport
+		}
+		reportFile, err := generate(data, request.ReportName)
+		if err != nil {
+			s.logger.Errorf("Failed to generate security report: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response["generated_files"] = []string{reportFile}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
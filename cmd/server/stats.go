@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/cache"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+)
+
+func (s *Server) getMethodStatsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Default to the last 24 hours
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		end = t
+	}
+
+	byPath := false
+	switch query.Get("group_by") {
+	case "", "method":
+	case "path":
+		byPath = true
+	default:
+		http.Error(w, "group_by must be method or path", http.StatusBadRequest)
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	// Requests with the same parameters share a result, so the default
+	// window is the last 24 hours as of when it was cached
+	key := cache.Key("stats", "methods", url.Values{
+		"start_time": {query.Get("start_time")},
+		"end_time":   {query.Get("end_time")},
+		"group_by":   {query.Get("group_by")},
+		"log_type":   {query.Get("log_type")},
+		"path":       {query.Get("path")},
+		"limit":      {strconv.Itoa(limit)},
+	}.Encode())
+	var stats []models.MethodStats
+	err := s.cachedStats(r.Context(), key, &stats, func() (err error) {
+		stats, err = s.db.GetMethodStats(start, end, query.Get("log_type"), query.Get("path"), byPath, limit)
+		return err
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get method stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"methods":    stats,
+		"count":      len(stats),
+		"start_time": start,
+		"end_time":   end,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// databaseStats returns the database's stats, cached for cache.stats_ttl
+// seconds
+func (s *Server) databaseStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	err := s.cachedStats(ctx, cache.Key("stats", "database"), &stats, func() (err error) {
+		stats, err = s.db.GetStats()
+		return err
+	})
+	return stats, err
+}
+
+// cachedStats fills result, a pointer, from the cache, or else by calling
+// load and then caches it for cache.stats_ttl seconds. Replicas sharing a
+// Redis cache reuse each other's results.
+func (s *Server) cachedStats(ctx context.Context, key string, result interface{}, load func() error) error {
+	ttl := time.Duration(s.config.Cache.StatsTTL) * time.Second
+	if ttl <= 0 {
+		return load()
+	}
+
+	if data, ok, err := s.cache.Get(ctx, key); err == nil && ok && json.Unmarshal(data, result) == nil {
+		return nil
+	}
+	if err := load(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err == nil {
+		err = s.cache.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		s.logger.Warnf("Failed to cache %s: %v", key, err)
+	}
+	return nil
+}
+
+// maxLatencyEntries bounds how many entries latency stats are worked out
+// from
+const maxLatencyEntries = 100000
+
+// latencyStats are the response time percentiles of a period
+type latencyStats struct {
+	Overall reporting.LatencyPercentiles   `json:"overall"`
+	Paths   []reporting.LatencyPercentiles `json:"paths"`
+	// Entries counts the entries read; Truncated is set when there were
+	// more than could be read, the most recent ones being kept
+	Entries   int  `json:"entries"`
+	Truncated bool `json:"truncated"`
+}
+
+func (s *Server) getLatencyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Default to the last 24 hours
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	if t, err := time.Parse(time.RFC3339, query.Get("start_time")); err == nil {
+		start = t
+	}
+	if t, err := time.Parse(time.RFC3339, query.Get("end_time")); err == nil {
+		end = t
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	key := cache.Key("stats", "latency", url.Values{
+		"start_time": {query.Get("start_time")},
+		"end_time":   {query.Get("end_time")},
+		"log_type":   {query.Get("log_type")},
+		"path":       {query.Get("path")},
+		"limit":      {strconv.Itoa(limit)},
+	}.Encode())
+	var stats latencyStats
+	err := s.cachedStats(r.Context(), key, &stats, func() error {
+		entries, err := s.db.Find(r.Context(), &models.LogFilter{
+			StartTime: &start,
+			EndTime:   &end,
+			LogType:   query.Get("log_type"),
+			Path:      query.Get("path"),
+			Limit:     maxLatencyEntries,
+		})
+		if err != nil {
+			return err
+		}
+		stats.Overall, stats.Paths = reporting.AnalyzeLatency(entries, limit)
+		stats.Entries = len(entries)
+		stats.Truncated = len(entries) == maxLatencyEntries
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get latency stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"overall":    stats.Overall,
+		"paths":      stats.Paths,
+		"count":      len(stats.Paths),
+		"entries":    stats.Entries,
+		"truncated":  stats.Truncated,
+		"start_time": start,
+		"end_time":   end,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/tail"
+)
+
+// tailLogsHandler streams entries as they are stored, as server-sent
+// events, so operators can follow their logs live during an incident.
+// It takes the filters of /api/v1/logs, a saved search and fields. Each
+// entry is a log event; a dropped event tells a client that fell behind
+// how many entries it missed.
+func (s *Server) tailLogsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.LogFilter{}
+	if name := query.Get("saved"); name != "" {
+		search, ok := s.requestSavedSearch(w, r, name)
+		if !ok {
+			return
+		}
+		filter = &search.Filter
+	}
+	applyFilterQuery(filter, query)
+	fields, err := parseFields(query.Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := s.tail.Subscribe(*filter)
+	if errors.Is(err, tail.ErrTooManySubscribers) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "Too many live tails are open, retry later", http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
+	rc, err := s.startEventStream(w)
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(s.config.Tail.Heartbeat) * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case <-heartbeat.C:
+			writeDropped(w, sub)
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case entry, ok := <-sub.Entries():
+			if !ok {
+				return
+			}
+			writeDropped(w, sub)
+			projected, err := projectEntry(entry, fields)
+			if err != nil {
+				s.logger.Errorf("Failed to encode live tail entry: %v", err)
+				return
+			}
+			data, err := json.Marshal(projected)
+			if err != nil {
+				s.logger.Errorf("Failed to encode live tail entry: %v", err)
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", entry.ID, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeDropped tells a tail client how many entries it missed since it
+// was last told
+func writeDropped(w http.ResponseWriter, sub *tail.Subscription) {
+	if dropped := sub.Dropped(); dropped > 0 {
+		fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/gorilla/mux"
+)
+
+// maxTemplateSize is the largest report template that can be uploaded
+const maxTemplateSize = 1 << 20
+
+// templateNamePattern matches the names report templates are saved under
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,100}$`)
+
+// templateReportType returns the report type of a generate request's
+// report_type, which defaults to the standard report
+func templateReportType(reportType string) string {
+	if reportType == "" {
+		return "standard"
+	}
+	return reportType
+}
+
+// requestTemplate parses the uploaded template a generate request names,
+// responding with the error if there is none or it no longer renders
+func (s *Server) requestTemplate(w http.ResponseWriter, reportType, name string) (*template.Template, bool) {
+	stored, err := s.db.GetReportTemplate(reportType, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("No %s report template named %q", reportType, name), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get report template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	tmpl, err := s.reporter.ParseTemplate(reportType, name, stored.Content)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid template: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// templateParams validates the report type and name of a template's path
+func templateParams(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	vars := mux.Vars(r)
+	reportType, name := vars["report_type"], vars["name"]
+	if !slices.Contains(reporting.TemplateReportTypes(), reportType) {
+		http.Error(w, "Invalid report type. Must be one of: "+strings.Join(reporting.TemplateReportTypes(), ", "), http.StatusBadRequest)
+		return "", "", false
+	}
+	if !templateNamePattern.MatchString(name) {
+		http.Error(w, "Template names are 1 to 100 letters, digits, underscores or hyphens", http.StatusBadRequest)
+		return "", "", false
+	}
+	return reportType, name, true
+}
+
+// listReportTemplatesHandler lists the uploaded report templates without
+// their content
+func (s *Server) listReportTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.db.GetReportTemplates()
+	if err != nil {
+		s.logger.Errorf("Failed to get report templates: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"templates":    templates,
+		"count":        len(templates),
+		"report_types": reporting.TemplateReportTypes(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getReportTemplateHandler returns a report template with its content
+func (s *Server) getReportTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	reportType, name, ok := templateParams(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := s.db.GetReportTemplate(reportType, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Report template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get report template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// saveReportTemplateHandler saves the request body as a report template,
+// replacing any of the same report type and name. Templates that do not
+// parse or do not render a sample report are refused.
+func (s *Server) saveReportTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	reportType, name, ok := templateParams(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTemplateSize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, fmt.Sprintf("Templates must be at most %d KB", maxTemplateSize>>10), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) == 0 {
+		http.Error(w, "Template is empty", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.reporter.ParseTemplate(reportType, name, string(body)); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid template: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	tmpl := &models.ReportTemplate{
+		ReportType: reportType,
+		Name:       name,
+		Content:    string(body),
+		UpdatedBy:  requestActor(r),
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if err := s.db.SaveReportTemplate(tmpl); err != nil {
+		s.logger.Errorf("Failed to save report template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionTemplateSaved, tmpl.UpdatedBy, "report_template:"+reportType+"/"+name, map[string]interface{}{
+		"size": len(body),
+	})
+
+	// The content was just sent, so it is not echoed back
+	tmpl.Content = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// deleteReportTemplateHandler removes a report template. Generate requests
+// naming it are refused from then on.
+func (s *Server) deleteReportTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	reportType, name, ok := templateParams(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := s.db.DeleteReportTemplate(reportType, name)
+	if err != nil {
+		s.logger.Errorf("Failed to delete report template: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Report template not found", http.StatusNotFound)
+		return
+	}
+	s.recordAudit(audit.ActionTemplateDeleted, requestActor(r), "report_template:"+reportType+"/"+name, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// timeseriesIntervals are the bucket lengths of time series
+var timeseriesIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// maxTimeseriesPoints bounds the buckets of a time series
+const maxTimeseriesPoints = 10000
+
+// defaultTimeseriesMetrics are the series charted unless metric says
+// otherwise
+var defaultTimeseriesMetrics = []string{"count", "errors", "avg_processing_time"}
+
+// timeseries is a chart's data: the start of each bucket and a series of
+// values per metric, one for each bucket
+type timeseries struct {
+	Interval   string             `json:"interval"`
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Timestamps []time.Time        `json:"timestamps"`
+	Series     []timeseriesSeries `json:"series"`
+}
+
+// timeseriesSeries is the values of a metric. Averages and maximums of
+// buckets without entries are null, so charts leave a gap.
+type timeseriesSeries struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+// grafanaSeries is a series as Grafana's JSON data sources expect it: each
+// datapoint is a value and a Unix time in milliseconds
+type grafanaSeries struct {
+	Target     string           `json:"target"`
+	Datapoints [][2]interface{} `json:"datapoints"`
+}
+
+// timeseriesHandler counts entries in buckets of an interval for charting.
+// Every bucket of the range is present, counting zero when it has no
+// entries. With format=grafana the series are in the form of Grafana's
+// JSON data sources.
+func (s *Server) timeseriesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("interval")
+	if name == "" {
+		name = "1h"
+	}
+	interval, ok := timeseriesIntervals[name]
+	if !ok {
+		http.Error(w, "Invalid interval. Must be one of: 1m, 5m, 1h, 1d", http.StatusBadRequest)
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "grafana" {
+		http.Error(w, "Invalid format. Must be grafana or left out", http.StatusBadRequest)
+		return
+	}
+
+	filter := logFilterFromQuery(query)
+	if err := parseRange(query, filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	metrics, err := listParam(query.Get("metric"), models.AggregateMetrics, "metric")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(metrics) == 0 {
+		metrics = defaultTimeseriesMetrics
+	}
+
+	// The last 24 hours by default, or 30 days of daily buckets. Buckets
+	// starting in the range are counted whole.
+	end := time.Now().UTC()
+	if filter.EndTime != nil {
+		end = filter.EndTime.UTC()
+	}
+	start := end.Add(-24 * time.Hour)
+	if interval == 24*time.Hour {
+		start = end.AddDate(0, 0, -30)
+	}
+	if filter.StartTime != nil {
+		start = filter.StartTime.UTC()
+	}
+	if !start.Before(end) {
+		http.Error(w, "start must be before end", http.StatusBadRequest)
+		return
+	}
+	start = start.Truncate(interval)
+	points := int((end.Sub(start) + interval - 1) / interval)
+	if points > maxTimeseriesPoints {
+		http.Error(w, fmt.Sprintf("The range has %d buckets, more than %d; use a longer interval or a shorter range", points, maxTimeseriesPoints), http.StatusBadRequest)
+		return
+	}
+	filter.StartTime, filter.EndTime = &start, &end
+
+	rows, err := s.db.AggregateLogs(r.Context(), &models.AggregateQuery{
+		Filter:   *filter,
+		Metrics:  metrics,
+		Interval: interval,
+		Limit:    points,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get time series: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	buckets := make(map[int64]models.AggregateRow, len(rows))
+	for _, row := range rows {
+		buckets[row.Bucket.Unix()] = row
+	}
+
+	result := timeseries{Interval: name, Start: start, End: end, Timestamps: make([]time.Time, points)}
+	for i := range result.Timestamps {
+		result.Timestamps[i] = start.Add(time.Duration(i) * interval)
+	}
+	for _, metric := range metrics {
+		series := timeseriesSeries{Name: metric, Values: make([]*float64, points)}
+		for i, bucket := range result.Timestamps {
+			if row, ok := buckets[bucket.Unix()]; ok {
+				value := row.Metrics[metric]
+				series.Values[i] = &value
+			} else if !strings.HasPrefix(metric, "avg_") && !strings.HasPrefix(metric, "max_") {
+				series.Values[i] = new(float64)
+			}
+		}
+		result.Series = append(result.Series, series)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if format == "grafana" {
+		json.NewEncoder(w).Encode(grafanaFormat(&result))
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// grafanaFormat converts a time series to Grafana's form
+func grafanaFormat(ts *timeseries) []grafanaSeries {
+	series := make([]grafanaSeries, len(ts.Series))
+	for i, s := range ts.Series {
+		series[i] = grafanaSeries{Target: s.Name, Datapoints: make([][2]interface{}, len(s.Values))}
+		for j, value := range s.Values {
+			series[i].Datapoints[j] = [2]interface{}{value, ts.Timestamps[j].UnixMilli()}
+		}
+	}
+	return series
+}
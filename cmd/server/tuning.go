@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// pipelineStats measures storing and keeps the latest measurements of the
+// pipeline taken while logs were ingested, which the processing stats report
+type pipelineStats struct {
+	batchSize atomic.Int64
+	entries   atomic.Int64
+	writeTime atomic.Int64 // ns
+	// skipped counts the entries of file lines stored already
+	skipped atomic.Int64
+
+	mu     sync.RWMutex
+	load   logprocessor.Load
+	writes logprocessor.WriteLoad
+}
+
+func (p *pipelineStats) recordWrite(entries int, d time.Duration) {
+	p.entries.Add(int64(entries))
+	p.writeTime.Add(int64(d))
+}
+
+func (p *pipelineStats) takeWrites() logprocessor.WriteLoad {
+	return logprocessor.WriteLoad{
+		Entries: p.entries.Swap(0),
+		Time:    time.Duration(p.writeTime.Swap(0)),
+	}
+}
+
+func (p *pipelineStats) latest() (logprocessor.Load, logprocessor.WriteLoad) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.load, p.writes
+}
+
+// measurePipeline samples parse and write latency every autotune interval
+// and, with autotune enabled, adjusts the worker count and batch size
+func (s *Server) measurePipeline() {
+	cfg := s.config.Processing.Autotune
+	var tuner *logprocessor.Tuner
+	if cfg.Enabled {
+		tuner = logprocessor.NewTuner(logprocessor.TuningBounds{
+			MinWorkers:   cfg.MinWorkers,
+			MaxWorkers:   cfg.MaxWorkers,
+			MinBatchSize: cfg.MinBatchSize,
+			MaxBatchSize: cfg.MaxBatchSize,
+		}, s.processor.Workers(), int(s.pipeline.batchSize.Load()))
+	}
+
+	ticker := time.NewTicker(time.Duration(cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		load := s.processor.TakeLoad()
+		writes := s.pipeline.takeWrites()
+		s.pipeline.mu.Lock()
+		if load.Lines > 0 {
+			s.pipeline.load = load
+		}
+		if writes.Entries > 0 {
+			s.pipeline.writes = writes
+		}
+		s.pipeline.mu.Unlock()
+
+		if tuner == nil {
+			continue
+		}
+		workers, batchSize := tuner.Adjust(load, writes)
+		if workers != load.Workers || int64(batchSize) != s.pipeline.batchSize.Load() {
+			s.logger.Infof("Autotune: %d workers, batch size %d (parse %v, write %v per entry, %.0f%% utilization)",
+				workers, batchSize, load.ParseLatency(), writes.WriteLatency(), load.Utilization()*100)
+		}
+		s.processor.SetWorkers(workers)
+		s.pipeline.batchSize.Store(int64(batchSize))
+	}
+}
+
+// pipelineMetrics reports the worker count and batch size in use and the
+// latest measurements behind them
+func (s *Server) pipelineMetrics() map[string]interface{} {
+	load, writes := s.pipeline.latest()
+	return map[string]interface{}{
+		"autotune":           s.config.Processing.Autotune.Enabled,
+		"workers":            s.processor.Workers(),
+		"batch_size":         s.pipeline.batchSize.Load(),
+		"parse_latency_ms":   milliseconds(load.ParseLatency()),
+		"write_latency_ms":   milliseconds(writes.WriteLatency()),
+		"worker_utilization": load.Utilization(),
+		"queue_fill":         load.QueueFill,
+		"queue_size":         s.processor.QueueSize(),
+		"writers":            s.config.Processing.Writers,
+		"skipped_lines":      s.pipeline.skipped.Load(),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// storeBatch stores entries in one transaction. If that fails, they are
+// stored one by one so a bad entry does not lose the rest. It returns the
+// entries stored, leaving out those of file lines that were stored
+// already so they are not alerted on or forwarded twice, and how many
+// failed to store.
+func (s *Server) storeBatch(batch []*models.LogEntry) ([]*models.LogEntry, int) {
+	start := time.Now()
+	err := s.db.InsertBatch(context.Background(), batch)
+	if err == nil {
+		s.pipeline.recordWrite(len(batch), time.Since(start))
+		return s.newlyStored(batch), 0
+	}
+	if len(batch) > 1 {
+		s.logger.Warnf("Failed to store batch of %d log entries, storing them one by one: %v", len(batch), err)
+	}
+
+	stored := batch[:0]
+	start = time.Now()
+	for _, entry := range batch {
+		if err := s.storeLogEntry(entry); err != nil {
+			s.logger.Errorf("Failed to store log entry: %v", err)
+			continue
+		}
+		stored = append(stored, entry)
+	}
+	s.pipeline.recordWrite(len(batch), time.Since(start))
+	return s.newlyStored(stored), len(batch) - len(stored)
+}
+
+// newlyStored drops the stored entries that were skipped, which storage
+// leaves without an ID
+func (s *Server) newlyStored(entries []*models.LogEntry) []*models.LogEntry {
+	stored := entries[:0]
+	for _, entry := range entries {
+		if entry.ID != 0 {
+			stored = append(stored, entry)
+		}
+	}
+	if skipped := len(entries) - len(stored); skipped > 0 {
+		s.pipeline.skipped.Add(int64(skipped))
+	}
+	return stored
+}
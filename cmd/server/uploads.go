@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/upload"
+	"github.com/gorilla/mux"
+)
+
+// chunkChecksumHeader optionally carries the SHA-256 of a chunk
+const chunkChecksumHeader = "X-Chunk-SHA256"
+
+func uploadResponse(u *upload.Upload) map[string]interface{} {
+	return map[string]interface{}{
+		"upload_id":      u.ID,
+		"filename":       u.Filename,
+		"log_type":       u.LogType,
+		"size":           u.Size,
+		"max_error_rate": u.MaxErrorRate,
+		"offset":         u.Offset,
+		"completed":      u.Completed,
+		"created_at":     u.CreatedAt,
+		"updated_at":     u.UpdatedAt,
+	}
+}
+
+// writeUploadError maps upload store errors to responses
+func (s *Server) writeUploadError(w http.ResponseWriter, err error) {
+	var offsetErr *upload.OffsetError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &offsetErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  err.Error(),
+			"offset": offsetErr.Offset,
+		})
+	case errors.Is(err, upload.ErrNotFound):
+		http.Error(w, "Upload not found", http.StatusNotFound)
+	case errors.Is(err, upload.ErrTooLarge), errors.As(err, &maxBytesErr):
+		http.Error(w, "Upload or chunk too large", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		http.Error(w, "SHA-256 does not match", http.StatusBadRequest)
+	case errors.Is(err, upload.ErrCompleted), errors.Is(err, upload.ErrIncomplete):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		s.logger.Errorf("Failed to handle upload: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// uploadErrorRate checks an upload's max_error_rate, a percentage,
+// defaulting to the configured one when it is not given
+func (s *Server) uploadErrorRate(rate *float64) (float64, error) {
+	if rate == nil {
+		return s.config.Ingest.MaxErrorRate, nil
+	}
+	if *rate < 0 || *rate > 100 {
+		return 0, errors.New("max_error_rate must be a percentage between 0 and 100")
+	}
+	return *rate, nil
+}
+
+// processUploadedLog processes an uploaded file for a job, failing it once
+// too many lines failed to parse, and reports the file's line counts as
+// the job's progress while it is read and as its result. Unless duplicate
+// files are allowed, each line of the file with SHA-256 sum is stored
+// once, so processing a file again after it was cut short only stores the
+// lines that were lost. The file is remembered for duplicate checks once
+// all of its entries are stored.
+func (s *Server) processUploadedLog(ctx context.Context, job *jobs.Job, r io.Reader, filename, logType, sum string, size int64, maxErrorRate float64) error {
+	job.SetTotal(1)
+	counter := &countingReader{r: r}
+	file := s.storedFiles.track()
+	opts := logprocessor.FileOptions{
+		MaxErrorRate:   maxErrorRate,
+		ErrorRateLines: s.config.Ingest.ErrorRateLines,
+		Progress: func(progress logprocessor.FileResult) {
+			job.SetProgress(progress)
+		},
+		Queued: file.queued,
+	}
+	if s.config.Ingest.DuplicateFiles != "allow" {
+		opts.FileHash = sum
+	}
+	result, err := s.processor.ProcessFileWithOptions(counter, logType, opts)
+	if err == nil {
+		err = file.wait(ctx)
+	}
+	job.Advance(counter.n, err)
+	job.SetResult(result)
+	if err != nil {
+		return fmt.Errorf("failed to process %s: %w", filename, err)
+	}
+	s.recordIngestedFile(filename, logType, sum, size)
+	return nil
+}
+
+func (s *Server) createUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Filename     string   `json:"filename"`
+		LogType      string   `json:"log_type"`
+		Size         int64    `json:"size"`           // optional total size in bytes
+		MaxErrorRate *float64 `json:"max_error_rate"` // percent
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.LogType == "" {
+		request.LogType = "generic"
+	}
+	if !s.processor.SupportsLogType(request.LogType) {
+		http.Error(w, "Invalid log type. Must be one of: "+strings.Join(s.processor.LogTypes(), ", "), http.StatusBadRequest)
+		return
+	}
+
+	maxErrorRate, err := s.uploadErrorRate(request.MaxErrorRate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u, err := s.uploads.Create(request.Filename, request.LogType, request.Size, maxErrorRate)
+	if err != nil {
+		s.writeUploadError(w, err)
+		return
+	}
+
+	response := uploadResponse(u)
+	response["max_chunk_size"] = s.config.Ingest.Uploads.MaxChunkSize << 20
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/logs/uploads/"+u.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getUploadHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := s.uploads.Get(mux.Vars(r)["id"])
+	if err != nil {
+		s.writeUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploadResponse(u))
+}
+
+// appendUploadHandler receives the chunk starting at ?offset=. A chunk is
+// kept only when received in full, so a failed chunk is simply sent again.
+func (s *Server) appendUploadHandler(w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "offset is required", http.StatusBadRequest)
+		return
+	}
+	// Chunks of up to max_chunk_size take longer than ordinary requests
+	// on slow links
+	s.liftDeadlines(w)
+
+	body := http.MaxBytesReader(w, r.Body, s.config.Ingest.Uploads.MaxChunkSize<<20)
+	u, err := s.uploads.Append(mux.Vars(r)["id"], offset, body, r.Header.Get(chunkChecksumHeader))
+	if err != nil {
+		s.writeUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploadResponse(u))
+}
+
+// completeUploadHandler verifies the file's SHA-256 and processes it in
+// the background. Duplicates of files processed before are refused or
+// skipped as ingest.duplicate_files says, as uploadLogHandler does.
+func (s *Server) completeUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		SHA256 string `json:"sha256"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.SHA256 == "" {
+		http.Error(w, "sha256 is required", http.StatusBadRequest)
+		return
+	}
+
+	u, err := s.uploads.Complete(mux.Vars(r)["id"], request.SHA256)
+	if err != nil {
+		s.writeUploadError(w, err)
+		return
+	}
+
+	warning, err := s.duplicateWarning(u.Filename, u.SHA256)
+	if err != nil {
+		s.logger.Errorf("Failed to look up ingested file: %v", err)
+		http.Error(w, "Failed to check for duplicate file", http.StatusInternalServerError)
+		return
+	}
+	if warning != "" {
+		if err := s.uploads.Remove(u.ID); err != nil {
+			s.logger.Errorf("Failed to remove upload %s: %v", u.ID, err)
+		}
+		if s.config.Ingest.DuplicateFiles == "reject" {
+			http.Error(w, warning, http.StatusConflict)
+			return
+		}
+		s.logger.Warnf("Skipping duplicate upload: %s", warning)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"upload_id": u.ID,
+			"sha256":    u.SHA256,
+			"status":    "skipped",
+			"warning":   warning,
+		})
+		return
+	}
+
+	s.logger.Infof("Processing uploaded log file: %s, type: %s", u.Filename, u.LogType)
+	s.startStoring()
+	job := s.jobs.Start(s.ctx, "upload", map[string]interface{}{
+		"upload_id": u.ID,
+		"filename":  u.Filename,
+		"log_type":  u.LogType,
+	}, s.processUpload(u))
+	id := job.Snapshot().ID
+
+	s.recordAudit(audit.ActionLogsUploaded, requestActor(r), u.Filename, map[string]interface{}{
+		"log_type":  u.LogType,
+		"size":      u.Offset,
+		"sha256":    u.SHA256,
+		"upload_id": u.ID,
+		"job_id":    id,
+	})
+
+	response := map[string]interface{}{
+		"upload_id":  u.ID,
+		"sha256":     u.SHA256,
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/logs/ingest/jobs/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// processUpload runs a completed upload through the processor, once the
+// server is not overloaded, and removes it afterwards
+func (s *Server) processUpload(u *upload.Upload) func(ctx context.Context, job *jobs.Job) error {
+	return func(ctx context.Context, job *jobs.Job) error {
+		if err := s.waitForCapacity(ctx); err != nil {
+			return err
+		}
+		defer func() {
+			if err := s.uploads.Remove(u.ID); err != nil {
+				s.logger.Errorf("Failed to remove upload %s: %v", u.ID, err)
+			}
+		}()
+
+		f, err := s.uploads.Open(u.ID)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return s.processUploadedLog(ctx, job, f, u.Filename, u.LogType, u.SHA256, u.Offset, u.MaxErrorRate)
+	}
+}
+
+func (s *Server) deleteUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.uploads.Remove(mux.Vars(r)["id"]); err != nil {
+		s.writeUploadError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
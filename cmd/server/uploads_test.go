@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkedUpload sends content as a chunked upload in one chunk and
+// completes it
+func chunkedUpload(t *testing.T, s *Server, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := serve(s, httptest.NewRequest(http.MethodPost, "/api/v1/logs/uploads", strings.NewReader(`{"filename": "access.log", "log_type": "nginx"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		UploadID string `json:"upload_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = serve(s, httptest.NewRequest(http.MethodPut, "/api/v1/logs/uploads/"+created.UploadID+"?offset=0", strings.NewReader(content)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	sum := sha256.Sum256([]byte(content))
+	body := fmt.Sprintf(`{"sha256": %q}`, hex.EncodeToString(sum[:]))
+	return serve(s, httptest.NewRequest(http.MethodPost, "/api/v1/logs/uploads/"+created.UploadID+"/complete", strings.NewReader(body)))
+}
+
+// waitForJob waits for a job to finish and returns its final state
+func waitForJob(t *testing.T, s *Server, id string) jobs.Snapshot {
+	t.Helper()
+	job, ok := s.jobs.Get(id)
+	require.True(t, ok, "no job %s", id)
+	timeout := time.After(10 * time.Second)
+	for {
+		changed := job.Changed()
+		if snapshot := job.Snapshot(); snapshot.FinishedAt != nil {
+			return snapshot
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			t.Fatalf("job %s did not finish", id)
+		}
+	}
+}
+
+func TestChunkedUploadDuplicates(t *testing.T) {
+	s := newTestServer(t, nil)
+	content := `192.0.2.1 - - [01/Mar/2024:10:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.4.0"` + "\n" +
+		`192.0.2.2 - - [01/Mar/2024:10:00:01 +0000] "GET /login HTTP/1.1" 302 0 "-" "curl/8.4.0"` + "\n"
+	sum := sha256.Sum256([]byte(content))
+
+	w := chunkedUpload(t, s, content)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	snapshot := waitForJob(t, s, response["job_id"].(string))
+	require.Equal(t, jobs.StatusCompleted, snapshot.Status, snapshot.Error)
+
+	// The file is remembered once its entries are stored
+	entries, err := s.db.Find(s.ctx, &models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	ingested, err := s.db.GetIngestedFile(hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), ingested.Size)
+
+	// Uploading it again is refused like a single upload
+	w = chunkedUpload(t, s, content)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "has the same content as access.log")
+
+	s.config.Ingest.DuplicateFiles = "skip"
+	w = chunkedUpload(t, s, content)
+	require.Equal(t, http.StatusOK, w.Code)
+	response = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "skipped", response["status"])
+	assert.NotEmpty(t, response["warning"])
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/api/v1/logs/uploads/"+response["upload_id"].(string), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "skipped uploads are removed")
+
+	entries, err = s.db.Find(s.ctx, &models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "duplicates are not stored")
+}
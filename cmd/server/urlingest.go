@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/pull"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/jobs"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ingestURLHandler downloads a log file from an HTTP(S) or SFTP URL and
+// processes it in the background
+func (s *Server) ingestURLHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		URL        string `json:"url"`
+		LogType    string `json:"log_type"`
+		Username   string `json:"username"`
+		Password   string `json:"password"`
+		Token      string `json:"token"`
+		PrivateKey string `json:"private_key"`
+		HostKey    string `json:"host_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.URL == "" || request.LogType == "" {
+		http.Error(w, "url and log_type are required", http.StatusBadRequest)
+		return
+	}
+	if !s.processor.SupportsLogType(request.LogType) {
+		http.Error(w, fmt.Sprintf("Unsupported log type: %s", request.LogType), http.StatusBadRequest)
+		return
+	}
+
+	src := s.pullSource(request.URL, request.HostKey)
+	src.Username = request.Username
+	src.Password = request.Password
+	src.Token = request.Token
+	if request.PrivateKey != "" {
+		src.PrivateKey = []byte(request.PrivateKey)
+	}
+	if err := src.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source := pull.Redact(request.URL)
+	s.startStoring()
+	// Jobs outlive the request and stop when the server shuts down
+	started := s.jobs.Start(s.ctx, "url_ingest", map[string]interface{}{
+		"url":      source,
+		"log_type": request.LogType,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		_, err := s.pullFile(ctx, job, src, request.LogType, nil)
+		return err
+	})
+	id := started.Snapshot().ID
+
+	s.recordAudit(audit.ActionLogsImported, requestActor(r), source, map[string]interface{}{
+		"job_id":   id,
+		"log_type": request.LogType,
+	})
+
+	response := map[string]interface{}{
+		"job_id":     id,
+		"status":     jobs.StatusRunning,
+		"status_url": "/api/v1/logs/ingest/jobs/" + id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// pullSource is a source with the configured host key checking and timeout
+func (s *Server) pullSource(rawURL, hostKey string) pull.Source {
+	cfg := s.config.Ingest.Pull
+	src := pull.Source{
+		URL:            rawURL,
+		KnownHostsFile: cfg.KnownHostsFile,
+		Timeout:        time.Duration(cfg.Timeout) * time.Second,
+	}
+	if hostKey != "" {
+		src.HostKeys = []string{hostKey}
+	}
+	return src
+}
+
+// pullFile downloads src from the given position, or in full without one,
+// and processes it. Entries record the URL they were pulled from under
+// source_url. It returns the position the next pull resumes from.
+func (s *Server) pullFile(ctx context.Context, job *jobs.Job, src pull.Source, logType string, from *pull.Position) (pull.Position, error) {
+	if err := s.waitForCapacity(ctx); err != nil {
+		return pull.Position{}, err
+	}
+	source := pull.Redact(src.URL)
+	download, err := pull.Open(ctx, src, from)
+	if err != nil {
+		return pull.Position{}, fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer download.Close()
+	job.SetTotal(1)
+
+	counter := &countingReader{r: download}
+	err = s.processor.ProcessFileWithMetadata(counter, logType, models.LogMetadata{"source_url": source})
+	job.Advance(counter.n, err)
+	if err != nil {
+		return pull.Position{}, fmt.Errorf("failed to process %s: %w", source, err)
+	}
+
+	next := download.Next()
+	job.SetResult(map[string]interface{}{
+		"offset":      download.Offset,
+		"next_offset": next.Offset,
+		"bytes":       counter.n,
+		"compressed":  download.Compressed,
+	})
+	s.logger.Infof("Pulled %d bytes of %s logs from %s", counter.n, logType, source)
+	return next, nil
+}
+
+// pullSchedule is a configured recurring pull
+type pullSchedule struct {
+	config  config.PullScheduleConfig
+	source  pull.Source
+	running sync.Mutex
+}
+
+// pullState is how far each scheduled pull has read, persisted so pulls
+// resume across restarts
+type pullState struct {
+	mu        sync.Mutex
+	path      string
+	positions map[string]pull.Position
+}
+
+func loadPullState(path string) (*pullState, error) {
+	state := &pullState{path: path, positions: make(map[string]pull.Position)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pull state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state.positions); err != nil {
+		return nil, fmt.Errorf("failed to parse pull state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// get returns a schedule's position, the start of the file before its
+// first run
+func (p *pullState) get(name string) pull.Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.positions[name]
+}
+
+// set records a schedule's position and writes the state file atomically
+func (p *pullState) set(name string, pos pull.Position) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.positions[name] = pos
+
+	data, err := json.MarshalIndent(p.positions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("failed to create pull state directory: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pull state: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("failed to write pull state: %w", err)
+	}
+	return nil
+}
+
+// setupPulls schedules the configured recurring pulls. Each run reads what
+// was appended to the file since the previous one; a run still going when
+// the next is due makes that one skip.
+func (s *Server) setupPulls() error {
+	cfg := s.config.Ingest.Pull
+	state, err := loadPullState(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+
+	for _, scheduleConfig := range cfg.Schedules {
+		if !s.processor.SupportsLogType(scheduleConfig.LogType) {
+			return fmt.Errorf("pull %s: unsupported log type: %s", scheduleConfig.Name, scheduleConfig.LogType)
+		}
+		schedule := &pullSchedule{config: scheduleConfig, source: s.pullSource(scheduleConfig.URL, scheduleConfig.HostKey)}
+		schedule.source.Username = scheduleConfig.Username
+		schedule.source.Password = scheduleConfig.Password
+		schedule.source.Token = scheduleConfig.Token
+		if scheduleConfig.PrivateKeyFile != "" {
+			key, err := os.ReadFile(scheduleConfig.PrivateKeyFile)
+			if err != nil {
+				return fmt.Errorf("pull %s: failed to read private key: %w", scheduleConfig.Name, err)
+			}
+			schedule.source.PrivateKey = key
+		}
+		if err := schedule.source.Validate(); err != nil {
+			return fmt.Errorf("pull %s: %w", scheduleConfig.Name, err)
+		}
+
+		if _, err := s.cron.AddFunc(scheduleConfig.Schedule, func() {
+			s.runPull(schedule, state)
+		}); err != nil {
+			return fmt.Errorf("pull %s: invalid schedule %q: %w", scheduleConfig.Name, scheduleConfig.Schedule, err)
+		}
+		s.logger.Infof("Pulling %s logs from %s on schedule %q", scheduleConfig.LogType, pull.Redact(scheduleConfig.URL), scheduleConfig.Schedule)
+	}
+
+	// Pulled entries are stored as they arrive rather than after an upload
+	s.startStoring()
+	return nil
+}
+
+// runPull starts a job pulling what is new in a scheduled file
+func (s *Server) runPull(schedule *pullSchedule, state *pullState) {
+	name := schedule.config.Name
+	if !schedule.running.TryLock() {
+		s.logger.Warnf("Skipping pull %s: the previous run has not finished", name)
+		return
+	}
+
+	s.jobs.Start(s.ctx, "url_ingest", map[string]interface{}{
+		"schedule": name,
+		"url":      pull.Redact(schedule.config.URL),
+		"log_type": schedule.config.LogType,
+	}, func(ctx context.Context, job *jobs.Job) error {
+		defer schedule.running.Unlock()
+
+		from := state.get(name)
+		next, err := s.pullFile(ctx, job, schedule.source, schedule.config.LogType, &from)
+		if err != nil {
+			s.logger.Errorf("Pull %s failed: %v", name, err)
+			return err
+		}
+		if err := state.set(name, next); err != nil {
+			s.logger.Errorf("Pull %s: %v", name, err)
+			return err
+		}
+		return nil
+	})
+}
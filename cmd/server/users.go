@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/auth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/gorilla/mux"
+)
+
+// accessRule is the role needed for the requests to a path and the paths
+// below it
+type accessRule struct {
+	path string
+	// writes limits the rule to methods other than GET and HEAD
+	writes bool
+	// exact limits the rule to the path itself
+	exact bool
+	role  string
+}
+
+// accessRules are checked in order and the first matching one applies.
+// Other requests need an analyst to change anything and a viewer to read.
+var accessRules = []accessRule{
+	{path: "/api/v1/admin", role: auth.RoleAdmin},
+	{path: "/api/v1/users", role: auth.RoleAdmin},
+	{path: "/api/v1/audit", role: auth.RoleAdmin},
+	{path: "/api/v1/config", role: auth.RoleAdmin},
+	{path: "/api/v1/retention", writes: true, role: auth.RoleAdmin},
+	{path: "/api/v1/archives/rehydrate", writes: true, role: auth.RoleAdmin},
+	{path: "/api/v1/rollups/rebuild", writes: true, role: auth.RoleAdmin},
+	{path: "/api/v1/integrity/check", writes: true, role: auth.RoleAdmin},
+	// Deleting entries by filter, unlike uploading them below it
+	{path: "/api/v1/logs", exact: true, writes: true, role: auth.RoleAdmin},
+	{path: "/api/v1/logs/ingest/s3", writes: true, role: auth.RoleAdmin},
+	{path: "/api/v1/logs/ingest/url", writes: true, role: auth.RoleAdmin},
+	// Verifying a certificate changes nothing, unlike erasing entries
+	{path: "/api/v1/compliance/erasures/verify", role: auth.RoleViewer},
+	{path: "/api/v1/compliance/erasures", writes: true, role: auth.RoleAdmin},
+	// GraphQL queries are posted but only read
+	{path: "/api/graphql", role: auth.RoleViewer},
+	// Everyone may keep their own saved searches
+	{path: "/api/v1/searches", role: auth.RoleViewer},
+}
+
+// requiredRole is the role a request needs
+func requiredRole(r *http.Request) string {
+	write := r.Method != http.MethodGet && r.Method != http.MethodHead
+	for _, rule := range accessRules {
+		matches := underPath(r.URL.Path, rule.path)
+		if rule.exact {
+			matches = strings.TrimSuffix(r.URL.Path, "/") == rule.path
+		}
+		if matches && (write || !rule.writes) {
+			return rule.role
+		}
+	}
+	if write {
+		return auth.RoleAnalyst
+	}
+	return auth.RoleViewer
+}
+
+// underPath reports whether path is prefix or a path below it
+func underPath(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// authorizeMiddleware refuses requests of signed-in users whose role does
+// not allow them. Without sign-in, or on public paths, there is no user
+// and every request is allowed.
+func (s *Server) authorizeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := requestIdentity(r)
+		if identity == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		role, err := s.roleOf(identity)
+		if err != nil {
+			s.logger.Errorf("Failed to get role of %s: %v", identity.Actor(), err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		identity.Role = role
+		if required := requiredRole(r); !auth.RoleAllows(role, required) {
+			http.Error(w, fmt.Sprintf("Forbidden: this needs the %s role", required), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isConfiguredAdmin reports whether auth.admins names a user, by subject
+// or email
+func (s *Server) isConfiguredAdmin(identity *auth.Identity) bool {
+	return slices.ContainsFunc(s.config.Auth.Admins, func(admin string) bool {
+		return admin == identity.Subject || (identity.Email != "" && strings.EqualFold(admin, identity.Email))
+	})
+}
+
+// roleOf is a user's role: admin for the configured admins, the stored
+// role of users and otherwise the default role
+func (s *Server) roleOf(identity *auth.Identity) (string, error) {
+	if s.isConfiguredAdmin(identity) {
+		return auth.RoleAdmin, nil
+	}
+	user, err := s.db.GetUser(identity.Subject)
+	if errors.Is(err, storage.ErrNotFound) {
+		return s.config.Auth.DefaultRole, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// recordSignIn stores a user who signed in, with the default role the
+// first time and their profile from the provider every time
+func (s *Server) recordSignIn(identity *auth.Identity, now time.Time) error {
+	user, err := s.db.GetUser(identity.Subject)
+	if errors.Is(err, storage.ErrNotFound) {
+		role := s.config.Auth.DefaultRole
+		if s.isConfiguredAdmin(identity) {
+			role = auth.RoleAdmin
+		}
+		user = &models.User{Subject: identity.Subject, Role: role, UpdatedBy: auditActorSystem, UpdatedAt: now}
+	} else if err != nil {
+		return err
+	}
+	user.Email, user.Name = identity.Email, identity.Name
+	user.LastSignInAt = &now
+	return s.db.SaveUser(user)
+}
+
+// listRolesHandler lists the roles users can have
+func (s *Server) listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := s.db.GetRoles()
+	if err != nil {
+		s.logger.Errorf("Failed to get roles: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"roles":        roles,
+		"default_role": s.config.Auth.DefaultRole,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// listUsersHandler lists the users who signed in or were given a role
+func (s *Server) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := s.db.GetUsers()
+	if err != nil {
+		s.logger.Errorf("Failed to get users: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"users": users,
+		"count": len(users),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// saveUserHandler sets the role of a user, adding them if they have not
+// signed in yet. Admins cannot change their own role, so there is always
+// one left.
+func (s *Server) saveUserHandler(w http.ResponseWriter, r *http.Request) {
+	subject := mux.Vars(r)["subject"]
+	var req struct {
+		Role  string `json:"role"`
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !auth.ValidRole(req.Role) {
+		http.Error(w, "Invalid role. Must be one of: viewer, analyst, admin", http.StatusBadRequest)
+		return
+	}
+	if identity := requestIdentity(r); identity != nil && identity.Subject == subject {
+		http.Error(w, "You cannot change your own role", http.StatusForbidden)
+		return
+	}
+
+	user, err := s.db.GetUser(subject)
+	previous := ""
+	if errors.Is(err, storage.ErrNotFound) {
+		user = &models.User{Subject: subject}
+	} else if err != nil {
+		s.logger.Errorf("Failed to get user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else {
+		previous = user.Role
+	}
+	// The provider's profile replaces these at each sign-in
+	if req.Email != "" {
+		user.Email = req.Email
+	}
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	user.Role = req.Role
+	user.UpdatedBy = requestActor(r)
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.db.SaveUser(user); err != nil {
+		s.logger.Errorf("Failed to save user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionUserSaved, user.UpdatedBy, "user:"+subject, map[string]interface{}{
+		"role":          user.Role,
+		"previous_role": previous,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// deleteUserHandler removes a user. They have the default role from then
+// on, and are added again the next time they sign in.
+func (s *Server) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	subject := mux.Vars(r)["subject"]
+	if identity := requestIdentity(r); identity != nil && identity.Subject == subject {
+		http.Error(w, "You cannot remove yourself", http.StatusForbidden)
+		return
+	}
+
+	found, err := s.db.DeleteUser(subject)
+	if err != nil {
+		s.logger.Errorf("Failed to delete user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	s.recordAudit(audit.ActionUserDeleted, requestActor(r), "user:"+subject, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/auth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		// Reading needs a viewer and changing an analyst
+		{http.MethodGet, "/api/v1/logs", auth.RoleViewer},
+		{http.MethodHead, "/api/v1/reports/daily.html", auth.RoleViewer},
+		{http.MethodPost, "/api/v1/logs/upload", auth.RoleAnalyst},
+		{http.MethodPost, "/api/v1/reports/generate", auth.RoleAnalyst},
+		{http.MethodDelete, "/api/v1/alerts/rules/1", auth.RoleAnalyst},
+
+		// Administration, reads included
+		{http.MethodGet, "/api/v1/admin/backup", auth.RoleAdmin},
+		{http.MethodGet, "/api/v1/users", auth.RoleAdmin},
+		{http.MethodPut, "/api/v1/users/alice", auth.RoleAdmin},
+		{http.MethodGet, "/api/v1/audit", auth.RoleAdmin},
+		{http.MethodGet, "/api/v1/config/", auth.RoleAdmin},
+
+		// Rules limited to writes leave reads to viewers
+		{http.MethodGet, "/api/v1/retention", auth.RoleViewer},
+		{http.MethodPut, "/api/v1/retention", auth.RoleAdmin},
+		{http.MethodPost, "/api/v1/rollups/rebuild", auth.RoleAdmin},
+		{http.MethodPost, "/api/v1/integrity/check", auth.RoleAdmin},
+		{http.MethodPost, "/api/v1/archives/rehydrate", auth.RoleAdmin},
+		{http.MethodPost, "/api/v1/logs/ingest/s3", auth.RoleAdmin},
+		{http.MethodGet, "/api/v1/logs/ingest/jobs/1", auth.RoleViewer},
+		{http.MethodPost, "/api/v1/logs/ingest/url", auth.RoleAdmin},
+
+		// Deleting by filter is the exact path only
+		{http.MethodDelete, "/api/v1/logs", auth.RoleAdmin},
+		{http.MethodDelete, "/api/v1/logs/", auth.RoleAdmin},
+		{http.MethodDelete, "/api/v1/logs/uploads/abc", auth.RoleAnalyst},
+
+		// Earlier rules win over later ones
+		{http.MethodPost, "/api/v1/compliance/erasures/verify", auth.RoleViewer},
+		{http.MethodPost, "/api/v1/compliance/erasures", auth.RoleAdmin},
+		{http.MethodGet, "/api/v1/compliance/erasures", auth.RoleViewer},
+		{http.MethodPost, "/api/graphql", auth.RoleViewer},
+		{http.MethodDelete, "/api/v1/searches/3", auth.RoleViewer},
+
+		// Prefixes match whole path segments
+		{http.MethodGet, "/api/v1/administrators", auth.RoleViewer},
+		{http.MethodPost, "/api/v1/usersettings", auth.RoleAnalyst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, requiredRole(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestRoleAccess(t *testing.T) {
+	requests := []struct {
+		method string
+		path   string
+		// allowed lists the roles that may make the request
+		allowed []string
+	}{
+		{http.MethodGet, "/api/v1/logs", []string{auth.RoleViewer, auth.RoleAnalyst, auth.RoleAdmin}},
+		{http.MethodPost, "/api/v1/logs/upload", []string{auth.RoleAnalyst, auth.RoleAdmin}},
+		{http.MethodDelete, "/api/v1/logs", []string{auth.RoleAdmin}},
+		{http.MethodGet, "/api/v1/retention", []string{auth.RoleViewer, auth.RoleAnalyst, auth.RoleAdmin}},
+		{http.MethodPut, "/api/v1/retention", []string{auth.RoleAdmin}},
+		{http.MethodGet, "/api/v1/users", []string{auth.RoleAdmin}},
+		{http.MethodPost, "/api/v1/searches", []string{auth.RoleViewer, auth.RoleAnalyst, auth.RoleAdmin}},
+	}
+	for _, request := range requests {
+		for _, role := range []string{auth.RoleViewer, auth.RoleAnalyst, auth.RoleAdmin, "unknown"} {
+			required := requiredRole(httptest.NewRequest(request.method, request.path, nil))
+			assert.Equal(t, slices.Contains(request.allowed, role), auth.RoleAllows(role, required), "%s %s as %s", request.method, request.path, role)
+		}
+	}
+}
+
+func TestAuthorizeMiddleware(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.Admins = []string{"root@example.com"}
+		cfg.Auth.DefaultRole = auth.RoleViewer
+	})
+	require.NoError(t, s.db.SaveUser(&models.User{Subject: "analyst", Role: auth.RoleAnalyst}))
+
+	handler := s.authorizeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	as := func(identity *auth.Identity, method, path string) int {
+		r := httptest.NewRequest(method, path, nil)
+		if identity != nil {
+			r = r.WithContext(context.WithValue(r.Context(), identityKey{}, identity))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	viewer := &auth.Identity{Subject: "someone"}
+	analyst := &auth.Identity{Subject: "analyst"}
+	admin := &auth.Identity{Subject: "root", Email: "Root@Example.com"}
+
+	assert.Equal(t, http.StatusNoContent, as(nil, http.MethodDelete, "/api/v1/logs"), "without sign-in every request is allowed")
+	assert.Equal(t, http.StatusNoContent, as(viewer, http.MethodGet, "/api/v1/logs"))
+	assert.Equal(t, http.StatusForbidden, as(viewer, http.MethodPost, "/api/v1/logs/upload"))
+	assert.Equal(t, http.StatusNoContent, as(analyst, http.MethodPost, "/api/v1/logs/upload"))
+	assert.Equal(t, http.StatusForbidden, as(analyst, http.MethodPut, "/api/v1/retention"))
+	assert.Equal(t, http.StatusNoContent, as(analyst, http.MethodGet, "/api/v1/retention"))
+	assert.Equal(t, http.StatusNoContent, as(admin, http.MethodPut, "/api/v1/retention"), "configured admins are matched by email")
+	assert.Equal(t, http.StatusNoContent, as(admin, http.MethodDelete, "/api/v1/logs"))
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/alerting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/features"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/versioning"
+)
+
+// Kinds of configuration objects whose versions are kept
+const (
+	kindAlertRule       = models.ConfigKindAlertRule
+	kindFeatureOverride = models.ConfigKindFeatureOverride
+)
+
+// maxConfigVersions is the most versions listed at once
+const maxConfigVersions = 1000
+
+// configVersionView is a version with its changes from the version before
+type configVersionView struct {
+	*models.ConfigVersion
+	Changes []versioning.Change `json:"changes"`
+}
+
+// configState is an object as its versions record it: its JSON without the
+// fields the version itself records, such as who changed it and when.
+// Configuration objects are plain data, so they always encode.
+func configState(object interface{}, bookkeeping ...string) json.RawMessage {
+	data, _ := json.Marshal(object)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	for _, field := range bookkeeping {
+		delete(fields, field)
+	}
+	data, _ = json.Marshal(fields)
+	return data
+}
+
+func alertRuleState(rule *models.AlertRule) json.RawMessage {
+	return configState(rule, "id", "created_at", "updated_at")
+}
+
+func featureOverrideState(override *models.FeatureOverride) json.RawMessage {
+	return configState(override, "flag", "project", "updated_by", "updated_at")
+}
+
+// featureObjectID identifies an override among the versioned objects
+func featureObjectID(flag, project string) string {
+	if project == "" {
+		return flag
+	}
+	return flag + ":" + project
+}
+
+// configSubject is the audit subject of a versioned object
+func configSubject(kind, objectID string) string {
+	if kind == kindFeatureOverride {
+		flag, project, _ := strings.Cut(objectID, ":")
+		return featureSubject(flag, project)
+	}
+	return kind + ":" + objectID
+}
+
+// recordConfigVersion stores the state of an object after a change. If the
+// object has no versions yet, its state before the change is stored first
+// so the change can be rolled back. Changes leaving the object as it was
+// are not recorded. Failures are logged rather than failing the change.
+func (s *Server) recordConfigVersion(kind, objectID, action, actor string, before, after json.RawMessage) *models.ConfigVersion {
+	if before != nil && versioning.Equal(before, after) {
+		return nil
+	}
+	if before != nil {
+		versions, err := s.db.GetConfigVersions(kind, objectID, 1)
+		if err != nil {
+			s.logger.Errorf("Failed to get versions of %s %s: %v", kind, objectID, err)
+			return nil
+		}
+		if len(versions) == 0 {
+			baseline := &models.ConfigVersion{Kind: kind, ObjectID: objectID, Action: versioning.ActionBaseline,
+				Actor: auditActorSystem, State: before}
+			if err := s.db.AppendConfigVersion(baseline); err != nil {
+				s.logger.Errorf("Failed to record the version of %s %s before it changed: %v", kind, objectID, err)
+				return nil
+			}
+		}
+	}
+
+	version := &models.ConfigVersion{Kind: kind, ObjectID: objectID, Action: action, Actor: actor, State: after}
+	if err := s.db.AppendConfigVersion(version); err != nil {
+		s.logger.Errorf("Failed to record a version of %s %s: %v", kind, objectID, err)
+		return nil
+	}
+	return version
+}
+
+// versionChanges compares each version with the one before it, looking
+// it up unless it is among the versions given
+func (s *Server) versionChanges(versions []*models.ConfigVersion) ([]configVersionView, error) {
+	type key struct {
+		kind, objectID string
+		version        int
+	}
+	known := make(map[key]*models.ConfigVersion, len(versions))
+	for _, version := range versions {
+		known[key{version.Kind, version.ObjectID, version.Version}] = version
+	}
+
+	views := make([]configVersionView, 0, len(versions))
+	for _, version := range versions {
+		var before json.RawMessage
+		if version.Version > 1 {
+			previous, ok := known[key{version.Kind, version.ObjectID, version.Version - 1}]
+			if !ok {
+				var err error
+				previous, err = s.db.GetConfigVersion(version.Kind, version.ObjectID, version.Version-1)
+				if err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return nil, err
+				}
+			}
+			if previous != nil {
+				before = previous.State
+			}
+		}
+		changes, err := versioning.Diff(before, version.State)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, configVersionView{ConfigVersion: version, Changes: changes})
+	}
+	return views, nil
+}
+
+// configVersionQuery reads the kind and object_id parameters. An object
+// needs its kind.
+func configVersionQuery(r *http.Request) (string, string, error) {
+	kind := r.URL.Query().Get("kind")
+	objectID := r.URL.Query().Get("object_id")
+	if kind != "" && kind != kindAlertRule && kind != kindFeatureOverride {
+		return "", "", fmt.Errorf("kind must be %s or %s", kindAlertRule, kindFeatureOverride)
+	}
+	if objectID != "" && kind == "" {
+		return "", "", errors.New("object_id needs a kind")
+	}
+	return kind, objectID, nil
+}
+
+// listConfigVersionsHandler lists the versions of an object, of a kind of
+// object or of all of them, most recent first, with what each changed
+func (s *Server) listConfigVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	kind, objectID, err := configVersionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxConfigVersions)
+	}
+
+	versions, err := s.db.GetConfigVersions(kind, objectID, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to get config versions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	views, err := s.versionChanges(versions)
+	if err != nil {
+		s.logger.Errorf("Failed to compare config versions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"versions": views,
+		"count":    len(views),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// diffConfigVersionsHandler compares two versions of an object: by
+// default, its latest version with the one before it. Version 0 is the
+// object before it existed.
+func (s *Server) diffConfigVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	kind, objectID, err := configVersionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if objectID == "" {
+		http.Error(w, "kind and object_id are required", http.StatusBadRequest)
+		return
+	}
+
+	versionParam := func(name string) (int, bool) {
+		raw := r.URL.Query().Get(name)
+		if raw == "" {
+			return -1, true
+		}
+		version, err := strconv.Atoi(raw)
+		return version, err == nil && version >= 0
+	}
+	from, okFrom := versionParam("from")
+	to, okTo := versionParam("to")
+	if !okFrom || !okTo {
+		http.Error(w, "from and to must be version numbers", http.StatusBadRequest)
+		return
+	}
+
+	if to < 0 {
+		latest, err := s.db.GetConfigVersions(kind, objectID, 1)
+		if err != nil {
+			s.logger.Errorf("Failed to get config versions: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(latest) == 0 {
+			http.Error(w, "No versions of this object", http.StatusNotFound)
+			return
+		}
+		to = latest[0].Version
+	}
+	if from < 0 {
+		from = max(to-1, 0)
+	}
+
+	states := make(map[int]json.RawMessage, 2)
+	for _, version := range []int{from, to} {
+		if version == 0 {
+			continue
+		}
+		stored, err := s.db.GetConfigVersion(kind, objectID, version)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, fmt.Sprintf("Version %d not found", version), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			s.logger.Errorf("Failed to get config version: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		states[version] = stored.State
+	}
+
+	changes, err := versioning.Diff(states[from], states[to])
+	if err != nil {
+		s.logger.Errorf("Failed to compare config versions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"kind":      kind,
+		"object_id": objectID,
+		"from":      from,
+		"to":        to,
+		"changes":   changes,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// errNotRestorable is returned for versions that cannot be restored, with
+// the reason
+type errNotRestorable string
+
+func (e errNotRestorable) Error() string { return string(e) }
+
+// rollbackConfigHandler returns an object to the state of one of its
+// versions and records that as its latest version
+func (s *Server) rollbackConfigHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Kind     string `json:"kind"`
+		ObjectID string `json:"object_id"`
+		Version  int    `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Kind == "" || request.ObjectID == "" || request.Version < 1 {
+		http.Error(w, "kind, object_id and version are required", http.StatusBadRequest)
+		return
+	}
+
+	target, err := s.db.GetConfigVersion(request.Kind, request.ObjectID, request.Version)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get config version: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	actor := requestActor(r)
+	switch target.Kind {
+	case kindAlertRule:
+		err = s.restoreAlertRule(target)
+	case kindFeatureOverride:
+		err = s.restoreFeatureOverride(target, actor)
+	default:
+		err = errNotRestorable("Versions of this kind cannot be rolled back")
+	}
+	var notRestorable errNotRestorable
+	if errors.As(err, &notRestorable) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "The object no longer exists", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to roll back %s %s: %v", target.Kind, target.ObjectID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	version := &models.ConfigVersion{Kind: target.Kind, ObjectID: target.ObjectID, Action: versioning.ActionRolledBack,
+		Actor: actor, State: target.State, RestoredFrom: target.Version}
+	if err := s.db.AppendConfigVersion(version); err != nil {
+		s.logger.Errorf("Failed to record a version of %s %s: %v", target.Kind, target.ObjectID, err)
+		http.Error(w, "Rolled back, but the new version was not recorded", http.StatusInternalServerError)
+		return
+	}
+	s.recordAudit(audit.ActionConfigRolledBack, actor, configSubject(target.Kind, target.ObjectID), map[string]interface{}{
+		"restored_version": target.Version,
+		"version":          version.Version,
+	})
+
+	views, err := s.versionChanges([]*models.ConfigVersion{version})
+	if err != nil {
+		s.logger.Errorf("Failed to compare config versions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views[0])
+}
+
+// restoreAlertRule replaces a rule with the one a version recorded
+func (s *Server) restoreAlertRule(version *models.ConfigVersion) error {
+	id, err := strconv.ParseInt(version.ObjectID, 10, 64)
+	if err != nil || len(version.State) == 0 || string(version.State) == "null" {
+		return errNotRestorable("This version has no alert rule to restore")
+	}
+
+	var rule models.AlertRule
+	if err := json.Unmarshal(version.State, &rule); err != nil {
+		return err
+	}
+	rule.ID = id
+	if err := alerting.ValidateRule(&rule); err != nil {
+		return errNotRestorable(fmt.Sprintf("The rule of this version is no longer valid: %v", err))
+	}
+	if err := s.db.UpdateAlertRule(&rule); err != nil {
+		return err
+	}
+
+	if s.alerts != nil {
+		if err := s.reloadAlertRules(); err != nil {
+			s.logger.Errorf("Failed to reload alert rules: %v", err)
+		}
+	}
+	return nil
+}
+
+// restoreFeatureOverride sets the override a version recorded, or removes
+// the override if the version deleted it
+func (s *Server) restoreFeatureOverride(version *models.ConfigVersion, actor string) error {
+	flag, project, _ := strings.Cut(version.ObjectID, ":")
+	if _, ok := features.Lookup(flag); !ok {
+		return errNotRestorable("The feature flag no longer exists")
+	}
+
+	if len(version.State) == 0 || string(version.State) == "null" {
+		if _, err := s.db.DeleteFeatureOverride(flag, project); err != nil {
+			return err
+		}
+		s.features.RemoveOverride(flag, project)
+		return nil
+	}
+
+	var override models.FeatureOverride
+	if err := json.Unmarshal(version.State, &override); err != nil {
+		return err
+	}
+	override.Flag, override.Project = flag, project
+	override.UpdatedBy = actor
+	override.UpdatedAt = time.Now().UTC()
+	if err := s.db.SetFeatureOverride(&override); err != nil {
+		return err
+	}
+	s.features.Override(&override)
+	return nil
+}
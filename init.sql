@@ -40,39 +40,53 @@ CREATE TABLE IF NOT EXISTS log_stats_cache (
 
 -- Create alert_rules table for monitoring
 CREATE TABLE IF NOT EXISTS alert_rules (
-    id BIGINT AUTO_INCREMENT PRIMARY KEY,
-    name VARCHAR(255) NOT NULL,
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
     description TEXT,
-    rule_type VARCHAR(50) NOT NULL,
-    conditions JSON NOT NULL,
-    threshold DECIMAL(10,2),
+    condition_type VARCHAR(20) NOT NULL,
+    threshold_value DOUBLE NOT NULL,
+    time_window INT NOT NULL,
+    for_duration INT NOT NULL DEFAULT 0,
+    recovery_threshold DOUBLE NULL,
+    expression JSON NULL,
     is_active BOOLEAN DEFAULT TRUE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    INDEX idx_rule_type (rule_type),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_is_active (is_active)
 );
 
 -- Create alert_history table for tracking alerts
 CREATE TABLE IF NOT EXISTS alert_history (
-    id BIGINT AUTO_INCREMENT PRIMARY KEY,
-    rule_id BIGINT,
-    alert_message TEXT NOT NULL,
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    rule_id INT NOT NULL,
+    message TEXT NOT NULL,
     severity VARCHAR(20) NOT NULL,
-    triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    resolved_at TIMESTAMP NULL,
-    metadata JSON,
+    triggered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at DATETIME NULL,
+    acknowledged_by VARCHAR(100) NULL,
     INDEX idx_rule_id (rule_id),
     INDEX idx_severity (severity),
     INDEX idx_triggered_at (triggered_at),
-    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE SET NULL
+    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
+
+-- Create maintenance_windows table for planned work
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    silence_alerts BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_maintenance_period (starts_at, ends_at)
 );
 
 -- Insert some sample alert rules
-INSERT INTO alert_rules (name, description, rule_type, conditions, threshold) VALUES
-('High Error Rate', 'Alert when error rate exceeds threshold', 'error_rate', '{"status_codes": [400, 401, 403, 404, 500, 502, 503, 504]}', 5.0),
-('High Response Time', 'Alert when average response time is too high', 'response_time', '{"method": "GET"}', 2.0),
-('Suspicious IP Activity', 'Alert when single IP makes too many requests', 'ip_activity', '{"time_window": 300}', 100);
+INSERT INTO alert_rules (name, description, condition_type, threshold_value, time_window, for_duration, recovery_threshold) VALUES
+('High Error Rate', 'Alert when error rate exceeds threshold', 'error_rate', 5.0, 300, 60, 3.0),
+('High Response Time', 'Alert when average response time is too high', 'avg_response_time', 2.0, 300, 60, NULL),
+('5xx Burst', 'Alert when server errors spike', 'server_error_count', 50, 60, 0, NULL);
 
 -- Create indexes for better performance
 CREATE INDEX idx_log_entries_composite ON log_entries(log_type, timestamp, status_code);
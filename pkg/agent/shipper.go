@@ -0,0 +1,242 @@
+// Package agent ships log lines from a host to the server. Lines are sent
+// in gzipped batches to the server's Loki push API and each batch is
+// retried with exponential backoff until the server accepts it.
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PushPath is where batches are sent, relative to the server's base URL
+const PushPath = "/loki/api/v1/push"
+
+// LogTypeLabel names the log type of a batch's lines
+const LogTypeLabel = "log_type"
+
+// Options tune a Shipper
+type Options struct {
+	Labels        map[string]string // added to every batch, such as host
+	BatchSize     int               // lines per request, default 1000
+	MaxBatchBytes int               // bytes of lines per request before compression, default 1 MB
+	Timeout       time.Duration     // per request, default 30s
+	MinBackoff    time.Duration     // before the first retry, default 1s
+	MaxBackoff    time.Duration     // most between retries, default 1m
+	// OnRetry, when set, is told of each failed attempt and the wait
+	// before the next one
+	OnRetry func(err error, wait time.Duration)
+}
+
+// PermanentError is a batch the server refused in a way retrying cannot
+// change, such as one it could not decode
+type PermanentError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("server refused batch with status %d: %s", e.StatusCode, e.Message)
+}
+
+// retryableError is a failed attempt, with how long the server asked the
+// client to wait
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Shipper sends lines to a server
+type Shipper struct {
+	url    string
+	opts   Options
+	client *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+	now    func() time.Time
+}
+
+// NewShipper creates a shipper for the server at a base URL
+func NewShipper(server string, opts Options) (*Shipper, error) {
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("server must be an http or https URL")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.MaxBatchBytes <= 0 {
+		opts.MaxBatchBytes = 1 << 20
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(time.Minute, opts.MinBackoff)
+	}
+	return &Shipper{
+		url:    strings.TrimRight(server, "/") + PushPath,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		sleep:  sleep,
+		now:    time.Now,
+	}, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Ship sends the lines read from r as logType, in batches. It returns once
+// every batch is accepted, with a *PermanentError if the server refused
+// one, or with the context's error if it ends first. Batches sent before
+// an error are not sent again, so a retried call may deliver lines twice.
+func (s *Shipper) Ship(ctx context.Context, r io.Reader, logType string) error {
+	reader := bufio.NewReader(r)
+	var batch []string
+	size := 0
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			if len(batch) > 0 && (len(batch) >= s.opts.BatchSize || size+len(line) > s.opts.MaxBatchBytes) {
+				if err := s.send(ctx, logType, batch); err != nil {
+					return err
+				}
+				batch, size = batch[:0], 0
+			}
+			batch = append(batch, line)
+			size += len(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.send(ctx, logType, batch)
+}
+
+// send delivers a batch, retrying with exponential backoff and jitter, or
+// for as long as the server asks with Retry-After
+func (s *Shipper) send(ctx context.Context, logType string, lines []string) error {
+	body, err := s.encode(logType, lines)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.opts.MinBackoff
+	for {
+		err := s.post(ctx, body)
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Wait between half and all of the backoff, so agents that failed
+		// together do not retry together
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		wait = max(wait, retryable.retryAfter)
+		if s.opts.OnRetry != nil {
+			s.opts.OnRetry(err, wait)
+		}
+		if err := s.sleep(ctx, wait); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, s.opts.MaxBackoff)
+	}
+}
+
+// encode builds a gzipped Loki push request holding the lines as one
+// stream
+func (s *Shipper) encode(logType string, lines []string) ([]byte, error) {
+	labels := make(map[string]string, len(s.opts.Labels)+1)
+	for name, value := range s.opts.Labels {
+		labels[name] = value
+	}
+	labels[LogTypeLabel] = logType
+
+	ts := strconv.FormatInt(s.now().UnixNano(), 10)
+	values := make([][2]string, len(lines))
+	for i, line := range lines {
+		values[i] = [2]string{ts, line}
+	}
+	push := map[string]interface{}{
+		"streams": []map[string]interface{}{{"stream": labels, "values": values}},
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(push); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// post makes one attempt at delivering a batch. Connection failures,
+// timeouts, 408, 429 and 5xx responses can be retried.
+func (s *Shipper) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("User-Agent", "log-analyzer-agent")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		var retryAfter time.Duration
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			retryAfter = min(time.Duration(seconds)*time.Second, s.opts.MaxBackoff)
+		}
+		return &retryableError{err: err, retryAfter: retryAfter}
+	default:
+		return &PermanentError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+}
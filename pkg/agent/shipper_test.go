@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/loki"
+)
+
+// pushServer decodes pushes as the server does, answering with the
+// queued statuses before accepting
+type pushServer struct {
+	mu       sync.Mutex
+	statuses []int
+	batches  [][]string
+	labels   []map[string]string
+}
+
+func (p *pushServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.URL.Path != PushPath {
+		http.NotFound(w, r)
+		return
+	}
+	if len(p.statuses) > 0 {
+		status := p.statuses[0]
+		p.statuses = p.statuses[1:]
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "try later", status)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	streams, err := loki.Decode(body, r.Header.Get("Content-Type"), r.Header.Get("Content-Encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, stream := range streams {
+		var lines []string
+		for _, entry := range stream.Entries {
+			lines = append(lines, entry.Line)
+		}
+		p.batches = append(p.batches, lines)
+		p.labels = append(p.labels, stream.Labels)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestShipper(t *testing.T, p *pushServer, opts Options) (*Shipper, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(p)
+	t.Cleanup(server.Close)
+	s, err := NewShipper(server.URL+"/", opts)
+	require.NoError(t, err)
+	var waits []time.Duration
+	s.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return s, &waits
+}
+
+func TestShipBatches(t *testing.T) {
+	p := &pushServer{}
+	s, _ := newTestShipper(t, p, Options{Labels: map[string]string{"host": "web-1"}, BatchSize: 2, MaxBatchBytes: 10})
+
+	err := s.Ship(context.Background(), strings.NewReader("one\ntwo\r\nthree\n\nfour five six\nseven"), "nginx")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"one", "two"}, {"three"}, {"four five six"}, {"seven"}}, p.batches,
+		"batches end at batch_size lines or before max_batch_size bytes")
+	assert.Equal(t, map[string]string{"host": "web-1", "log_type": "nginx"}, p.labels[0])
+
+	require.NoError(t, s.Ship(context.Background(), strings.NewReader(""), "nginx"))
+	assert.Len(t, p.batches, 4)
+}
+
+func TestShipRetries(t *testing.T) {
+	p := &pushServer{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadGateway}}
+	var retries []error
+	s, waits := newTestShipper(t, p, Options{
+		MinBackoff: 4 * time.Second,
+		MaxBackoff: 10 * time.Second,
+		OnRetry:    func(err error, wait time.Duration) { retries = append(retries, err) },
+	})
+
+	require.NoError(t, s.Ship(context.Background(), strings.NewReader("line\n"), "nginx"))
+	assert.Equal(t, [][]string{{"line"}}, p.batches)
+	assert.Len(t, retries, 3)
+	require.Len(t, *waits, 3)
+	for _, wait := range *waits {
+		assert.GreaterOrEqual(t, wait, 7*time.Second, "Retry-After is honoured")
+		assert.LessOrEqual(t, wait, 10*time.Second, "waits are capped at max_backoff")
+	}
+}
+
+func TestShipBackoffGrows(t *testing.T) {
+	s, err := NewShipper("http://127.0.0.1:1", Options{MinBackoff: time.Second, MaxBackoff: 4 * time.Second, Timeout: time.Second})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		if len(waits) == 5 {
+			cancel()
+		}
+		return nil
+	}
+
+	err = s.Ship(ctx, strings.NewReader("line\n"), "nginx")
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Len(t, waits, 5)
+	for i, ceiling := range []time.Duration{1, 2, 4, 4, 4} {
+		assert.LessOrEqual(t, waits[i], ceiling*time.Second)
+		assert.GreaterOrEqual(t, waits[i], ceiling*time.Second/2)
+	}
+}
+
+func TestShipPermanentError(t *testing.T) {
+	p := &pushServer{statuses: []int{http.StatusBadRequest}}
+	s, waits := newTestShipper(t, p, Options{})
+
+	err := s.Ship(context.Background(), strings.NewReader("line\n"), "nginx")
+	var permanent *PermanentError
+	require.True(t, errors.As(err, &permanent))
+	assert.Equal(t, http.StatusBadRequest, permanent.StatusCode)
+	assert.Empty(t, *waits, "refused batches are not retried")
+
+	_, err = NewShipper("ftp://example.com", Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ConditionComposite marks a rule evaluated through its boolean Expression
+const ConditionComposite = "composite"
+
+// maxExpressionDepth bounds nesting so a malformed rule can't blow the stack
+const maxExpressionDepth = 8
+
+// validateExpression checks the structure of a composite rule expression
+func validateExpression(cond *models.AlertCondition, depth int) error {
+	if cond == nil {
+		return fmt.Errorf("composite rules require an expression")
+	}
+	if depth > maxExpressionDepth {
+		return fmt.Errorf("expression nesting exceeds %d levels", maxExpressionDepth)
+	}
+
+	if cond.Operator != "" {
+		op := strings.ToLower(cond.Operator)
+		if op != "and" && op != "or" {
+			return fmt.Errorf("unsupported operator: %s", cond.Operator)
+		}
+		if len(cond.Conditions) == 0 {
+			return fmt.Errorf("%s expression has no conditions", op)
+		}
+		for _, child := range cond.Conditions {
+			if err := validateExpression(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, ok := metricFuncs[cond.Metric]; !ok {
+		return fmt.Errorf("unsupported metric: %s", cond.Metric)
+	}
+	if _, ok := comparators[cond.Comparator]; !ok {
+		return fmt.Errorf("unsupported comparator: %s", cond.Comparator)
+	}
+	return nil
+}
+
+var comparators = map[string]func(value, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+}
+
+// evaluateExpression evaluates the expression tree against a window and
+// records every leaf metric value in details
+func evaluateExpression(cond *models.AlertCondition, counts WindowCounts, details map[string]float64) bool {
+	switch strings.ToLower(cond.Operator) {
+	case "and":
+		result := true
+		for _, child := range cond.Conditions {
+			// Evaluate every child so details are complete for tuning
+			if !evaluateExpression(child, counts, details) {
+				result = false
+			}
+		}
+		return result
+	case "or":
+		result := false
+		for _, child := range cond.Conditions {
+			if evaluateExpression(child, counts, details) {
+				result = true
+			}
+		}
+		return result
+	}
+
+	value := metricFuncs[cond.Metric](counts)
+	details[cond.Metric] = value
+	return comparators[cond.Comparator](value, cond.Threshold)
+}
+
+func formatDetails(details map[string]float64) string {
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%.2f", key, details[key])
+	}
+	return strings.Join(parts, ", ")
+}
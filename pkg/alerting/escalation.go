@@ -0,0 +1,131 @@
+package alerting
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// EscalationPolicy controls re-notification of unacknowledged alerts
+type EscalationPolicy struct {
+	// RepeatInterval is the time between reminders for an alert that is
+	// still firing and has not been acknowledged
+	RepeatInterval time.Duration
+	// EscalateAfter is the number of unacknowledged reminders after which
+	// the escalation channel is notified as well; 0 never escalates
+	EscalateAfter int
+}
+
+// ActiveAlert is a fired alert awaiting acknowledgment or resolution
+type ActiveAlert struct {
+	Event        *models.AlertEvent `json:"event"`
+	Repeats      int                `json:"repeats"`
+	Escalated    bool               `json:"escalated"`
+	LastNotified time.Time          `json:"last_notified"`
+}
+
+// Escalator re-notifies fired alerts on an interval until they are
+// acknowledged or their rule stops firing, escalating after repeated
+// reminders go unanswered.
+type Escalator struct {
+	mu       sync.Mutex
+	policy   EscalationPolicy
+	active   map[int64]*ActiveAlert
+	resolved func(ruleID int64) bool
+	remind   func(alert ActiveAlert)
+	now      func() time.Time
+}
+
+// NewEscalator creates an escalator. resolved reports whether a rule has
+// stopped firing and remind is called for every reminder that is due.
+func NewEscalator(policy EscalationPolicy, resolved func(ruleID int64) bool, remind func(alert ActiveAlert)) *Escalator {
+	return &Escalator{
+		policy:   policy,
+		active:   make(map[int64]*ActiveAlert),
+		resolved: resolved,
+		remind:   remind,
+		now:      time.Now,
+	}
+}
+
+// Track starts reminders for a newly fired and persisted alert
+func (e *Escalator) Track(event *models.AlertEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.active[event.ID] = &ActiveAlert{Event: event, LastNotified: e.now()}
+}
+
+// Acknowledge stops reminders for an alert. It reports whether the alert
+// was being tracked.
+func (e *Escalator) Acknowledge(eventID int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.active[eventID]; !ok {
+		return false
+	}
+	delete(e.active, eventID)
+	return true
+}
+
+// Active returns the alerts still awaiting acknowledgment, oldest first
+func (e *Escalator) Active() []ActiveAlert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]ActiveAlert, 0, len(e.active))
+	for _, alert := range e.active {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Event.TriggeredAt.Before(alerts[j].Event.TriggeredAt)
+	})
+	return alerts
+}
+
+// Tick drops resolved alerts and sends the reminders that are due
+func (e *Escalator) Tick() {
+	e.mu.Lock()
+	now := e.now()
+	var due []ActiveAlert
+	for id, alert := range e.active {
+		if e.resolved(alert.Event.RuleID) {
+			delete(e.active, id)
+			continue
+		}
+		if now.Sub(alert.LastNotified) < e.policy.RepeatInterval {
+			continue
+		}
+
+		alert.Repeats++
+		alert.LastNotified = now
+		if e.policy.EscalateAfter > 0 && alert.Repeats >= e.policy.EscalateAfter {
+			alert.Escalated = true
+		}
+		due = append(due, *alert)
+	}
+	e.mu.Unlock()
+
+	for _, alert := range due {
+		e.remind(alert)
+	}
+}
+
+// Run checks for due reminders until the context is cancelled
+func (e *Escalator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Tick()
+		}
+	}
+}
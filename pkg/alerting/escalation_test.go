@@ -0,0 +1,75 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEscalator(policy EscalationPolicy, firing map[int64]bool) (*Escalator, *time.Time, *[]ActiveAlert) {
+	clock := time.Unix(1700000000, 0)
+	var reminders []ActiveAlert
+	escalator := NewEscalator(policy,
+		func(ruleID int64) bool { return !firing[ruleID] },
+		func(alert ActiveAlert) { reminders = append(reminders, alert) },
+	)
+	escalator.now = func() time.Time { return clock }
+	return escalator, &clock, &reminders
+}
+
+func TestEscalatorRepeatsAndEscalates(t *testing.T) {
+	firing := map[int64]bool{1: true}
+	escalator, clock, reminders := newTestEscalator(EscalationPolicy{RepeatInterval: time.Minute, EscalateAfter: 2}, firing)
+
+	escalator.Track(&models.AlertEvent{ID: 10, RuleID: 1, TriggeredAt: *clock})
+
+	*clock = clock.Add(30 * time.Second)
+	escalator.Tick()
+	assert.Empty(t, *reminders)
+
+	*clock = clock.Add(30 * time.Second)
+	escalator.Tick()
+	require.Len(t, *reminders, 1)
+	assert.Equal(t, 1, (*reminders)[0].Repeats)
+	assert.False(t, (*reminders)[0].Escalated)
+
+	*clock = clock.Add(time.Minute)
+	escalator.Tick()
+	require.Len(t, *reminders, 2)
+	assert.Equal(t, 2, (*reminders)[1].Repeats)
+	assert.True(t, (*reminders)[1].Escalated)
+
+	active := escalator.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, int64(10), active[0].Event.ID)
+}
+
+func TestEscalatorAcknowledge(t *testing.T) {
+	firing := map[int64]bool{1: true}
+	escalator, clock, reminders := newTestEscalator(EscalationPolicy{RepeatInterval: time.Minute}, firing)
+
+	escalator.Track(&models.AlertEvent{ID: 10, RuleID: 1})
+	assert.True(t, escalator.Acknowledge(10))
+	assert.False(t, escalator.Acknowledge(10))
+
+	*clock = clock.Add(time.Hour)
+	escalator.Tick()
+	assert.Empty(t, *reminders)
+	assert.Empty(t, escalator.Active())
+}
+
+func TestEscalatorDropsResolvedAlerts(t *testing.T) {
+	firing := map[int64]bool{1: true}
+	escalator, clock, reminders := newTestEscalator(EscalationPolicy{RepeatInterval: time.Minute, EscalateAfter: 1}, firing)
+
+	escalator.Track(&models.AlertEvent{ID: 10, RuleID: 1})
+	firing[1] = false
+
+	*clock = clock.Add(time.Hour)
+	escalator.Tick()
+	assert.Empty(t, *reminders)
+	assert.Empty(t, escalator.Active())
+}
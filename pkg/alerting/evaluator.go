@@ -0,0 +1,492 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Supported rule condition types
+const (
+	ConditionRequestCount     = "request_count"
+	ConditionErrorCount       = "error_count"
+	ConditionServerErrorCount = "server_error_count"
+	ConditionErrorRate        = "error_rate"
+	ConditionAvgResponseTime  = "avg_response_time"
+	ConditionRequestsPerMin   = "requests_per_minute"
+)
+
+// MaxTimeWindow is the longest window, in seconds, a streaming rule may use
+const MaxTimeWindow = 3600
+
+// HistorySize is the number of evaluation outcomes kept in memory per
+// rule, for replays and State. Evaluations passed to an OnEvaluate hook
+// can be stored for longer.
+const HistorySize = 300
+
+// ValidateRule checks that a rule can be evaluated by the streaming evaluator
+func ValidateRule(rule *models.AlertRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if rule.ConditionType == ConditionComposite {
+		if err := validateExpression(rule.Expression, 1); err != nil {
+			return err
+		}
+		if rule.RecoveryThreshold != nil {
+			return fmt.Errorf("recovery threshold is not supported for composite rules")
+		}
+	} else if isPatternCondition(rule.ConditionType) {
+		if err := validatePatternRule(rule); err != nil {
+			return err
+		}
+	} else if rule.ConditionType == ConditionIPScore {
+		if err := validateIPScoreRule(rule); err != nil {
+			return err
+		}
+	} else if rule.ConditionType == ConditionLatencyBudget {
+		if err := validateLatencyBudgetRule(rule); err != nil {
+			return err
+		}
+	} else if rule.ConditionType == ConditionIntegrity {
+		if err := validateIntegrityRule(rule); err != nil {
+			return err
+		}
+	} else if _, ok := metricFuncs[rule.ConditionType]; !ok {
+		return fmt.Errorf("unsupported condition type: %s", rule.ConditionType)
+	}
+	if rule.TimeWindow < 1 || rule.TimeWindow > MaxTimeWindow {
+		return fmt.Errorf("time window must be between 1 and %d seconds", MaxTimeWindow)
+	}
+	if rule.ForDuration < 0 {
+		return fmt.Errorf("for duration cannot be negative")
+	}
+	if rule.RecoveryThreshold != nil && *rule.RecoveryThreshold > rule.ThresholdValue {
+		return fmt.Errorf("recovery threshold cannot exceed the firing threshold")
+	}
+	return nil
+}
+
+var metricFuncs = map[string]func(WindowCounts) float64{
+	ConditionRequestCount:     func(c WindowCounts) float64 { return float64(c.Requests) },
+	ConditionErrorCount:       func(c WindowCounts) float64 { return float64(c.Errors) },
+	ConditionServerErrorCount: func(c WindowCounts) float64 { return float64(c.ServerErrors) },
+	ConditionErrorRate:        func(c WindowCounts) float64 { return c.ErrorRate() },
+	ConditionAvgResponseTime:  func(c WindowCounts) float64 { return c.AvgResponseTime() },
+	ConditionRequestsPerMin:   func(c WindowCounts) float64 { return c.RequestsPerMinute() },
+}
+
+// windowValues reports every metric for a window so notifications can show
+// more than the single value that breached
+func windowValues(counts WindowCounts) map[string]float64 {
+	values := make(map[string]float64, len(metricFuncs))
+	for name, fn := range metricFuncs {
+		values[name] = fn(counts)
+	}
+	return values
+}
+
+// OffenderStatusFloor returns the minimum status code of the requests that
+// contribute to a condition, used to find the paths and IPs behind an alert
+func OffenderStatusFloor(conditionType string) int {
+	switch conditionType {
+	case ConditionErrorCount, ConditionErrorRate:
+		return 400
+	case ConditionServerErrorCount:
+		return 500
+	default:
+		return 0
+	}
+}
+
+// StreamEvaluator evaluates alert rules against in-memory sliding-window
+// counters fed directly by the ingestion pipeline, so rules fire within
+// seconds of a threshold breach instead of waiting for a scheduled query.
+type StreamEvaluator struct {
+	mu       sync.Mutex
+	rules    []*models.AlertRule
+	window   *slidingWindow
+	patterns *patternTracker
+	ips      *ipTracker
+	budgets  *budgetTracker
+	findings []models.IntegrityFinding
+	states   map[int64]*ruleState
+	notify   func(*models.AlertEvent)
+	// evaluated receives the outcomes of each Evaluate
+	evaluated func([]models.RuleEvaluation)
+	now       func() time.Time
+}
+
+// ruleState tracks where a rule is in its ok -> pending -> firing lifecycle
+// along with a ring of its most recent evaluation outcomes
+type ruleState struct {
+	state        string
+	pendingSince time.Time
+	history      []models.RuleEvaluation
+	next         int
+	// reported holds the patterns, IPs, latency budgets or integrity
+	// checks a per-key rule has reported
+	reported map[string]time.Time
+}
+
+// record keeps an evaluation in the ring and returns it
+func (rs *ruleState) record(evaluation models.RuleEvaluation) models.RuleEvaluation {
+	if len(rs.history) < HistorySize {
+		rs.history = append(rs.history, evaluation)
+	} else {
+		rs.history[rs.next] = evaluation
+		rs.next = (rs.next + 1) % HistorySize
+	}
+	return evaluation
+}
+
+// latest returns the most recent evaluation
+func (rs *ruleState) latest() (models.RuleEvaluation, bool) {
+	if len(rs.history) == 0 {
+		return models.RuleEvaluation{}, false
+	}
+	if len(rs.history) < HistorySize {
+		return rs.history[len(rs.history)-1], true
+	}
+	return rs.history[(rs.next+HistorySize-1)%HistorySize], true
+}
+
+// ordered returns the recorded evaluations from oldest to newest
+func (rs *ruleState) ordered() []models.RuleEvaluation {
+	result := make([]models.RuleEvaluation, 0, len(rs.history))
+	result = append(result, rs.history[rs.next:]...)
+	result = append(result, rs.history[:rs.next]...)
+	return result
+}
+
+// NewStreamEvaluator creates an evaluator that calls notify for every fired alert
+func NewStreamEvaluator(notify func(*models.AlertEvent)) *StreamEvaluator {
+	return &StreamEvaluator{
+		window:   newSlidingWindow(MaxTimeWindow),
+		patterns: newPatternTracker(),
+		ips:      newIPTracker(),
+		budgets:  newBudgetTracker(),
+		states:   make(map[int64]*ruleState),
+		notify:   notify,
+		now:      time.Now,
+	}
+}
+
+// OnEvaluate calls fn with the outcome of every rule after each
+// evaluation, outside the evaluator's lock, such as to store them
+func (e *StreamEvaluator) OnEvaluate(fn func([]models.RuleEvaluation)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evaluated = fn
+}
+
+// SetRules replaces the set of rules being evaluated. State and history are
+// kept for rules that are still present so they don't re-notify after a reload.
+func (e *StreamEvaluator) SetRules(rules []*models.AlertRule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	active := make(map[int64]bool)
+	var kept []*models.AlertRule
+	for _, rule := range rules {
+		if !rule.IsActive || ValidateRule(rule) != nil {
+			continue
+		}
+		kept = append(kept, rule)
+		active[rule.ID] = true
+	}
+	e.rules = kept
+
+	for id := range e.states {
+		if !active[id] {
+			delete(e.states, id)
+		}
+	}
+}
+
+// Rules returns the rules currently loaded into the evaluator
+func (e *StreamEvaluator) Rules() []*models.AlertRule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rules := make([]*models.AlertRule, len(e.rules))
+	copy(rules, e.rules)
+	return rules
+}
+
+// Observe records a newly ingested entry in the windows as of its
+// timestamp. Entries without one, or stamped in the future, count as of
+// now. Entries older than the longest rule window cannot affect an
+// evaluation and are left out of the windows, though their patterns are
+// still learned.
+func (e *StreamEvaluator) Observe(entry *models.LogEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	at := entry.Timestamp
+	if at.IsZero() || at.After(now) {
+		at = now
+	}
+	if logprocessor.IsMessageLogType(entry.LogType) && entry.Path != "" {
+		e.patterns.observe(entry.Path, at, false)
+	}
+	if now.Sub(at) >= MaxTimeWindow*time.Second {
+		return
+	}
+	e.window.observe(at.Unix(), entry)
+	if entry.SourceIP != "" {
+		e.ips.observe(at, entry)
+	}
+	e.budgets.observe(at, entry)
+}
+
+// SeedPatterns teaches the evaluator the message patterns of previously
+// stored entries so they aren't reported as new after a restart
+func (e *StreamEvaluator) SeedPatterns(entries []*models.LogEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, entry := range entries {
+		if logprocessor.IsMessageLogType(entry.LogType) && entry.Path != "" {
+			e.patterns.observe(entry.Path, entry.Timestamp, true)
+		}
+	}
+}
+
+// SetPatternLearningPeriod treats patterns first seen within period from
+// now as known, giving the evaluator time to learn the normal patterns
+func (e *StreamEvaluator) SetPatternLearningPeriod(period time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.patterns.learnUntil = e.now().Add(period)
+}
+
+// History returns the retained evaluation outcomes for a rule, oldest first
+func (e *StreamEvaluator) History(ruleID int64) []models.RuleEvaluation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rs, ok := e.states[ruleID]
+	if !ok {
+		return nil
+	}
+	return rs.ordered()
+}
+
+// State returns the current lifecycle state of a rule
+func (e *StreamEvaluator) State(ruleID int64) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if rs, ok := e.states[ruleID]; ok {
+		return rs.state
+	}
+	return models.RuleStateOK
+}
+
+// Evaluate checks every rule against the current window and returns the
+// alerts that transitioned into the firing state. A breach must persist for
+// the rule's ForDuration before firing, and a firing rule only resolves once
+// the value drops to its RecoveryThreshold, so rules hovering around the
+// threshold don't flap.
+func (e *StreamEvaluator) Evaluate() []*models.AlertEvent {
+	e.mu.Lock()
+	now := e.now()
+	e.ips.prune(now)
+	e.budgets.prune(now)
+	var fired []*models.AlertEvent
+	var evaluations []models.RuleEvaluation
+	for _, rule := range e.rules {
+		counts := e.window.sum(now.Unix(), rule.TimeWindow)
+
+		rs, ok := e.states[rule.ID]
+		if !ok {
+			rs = &ruleState{state: models.RuleStateOK}
+			e.states[rule.ID] = rs
+		}
+
+		// Pattern, IP score and latency budget rules report each matching
+		// pattern, IP or budget separately and don't go through the
+		// pending state
+		if isPerKeyCondition(rule.ConditionType) {
+			events, value := e.evaluatePerKeyRule(rule, rs, now)
+			for _, event := range events {
+				event.Window = windowValues(counts)
+			}
+			fired = append(fired, events...)
+			evaluations = append(evaluations, rs.record(models.RuleEvaluation{
+				RuleID:      rule.ID,
+				EvaluatedAt: now,
+				Value:       value,
+				Threshold:   rule.ThresholdValue,
+				State:       rs.state,
+			}))
+			continue
+		}
+
+		outcome := evaluateRule(rule, counts)
+		if transition(rs, outcome, time.Duration(rule.ForDuration)*time.Second, now) {
+			event := newAlertEvent(rule, outcome, now)
+			event.Window = windowValues(counts)
+			fired = append(fired, event)
+		}
+
+		evaluations = append(evaluations, rs.record(models.RuleEvaluation{
+			RuleID:      rule.ID,
+			EvaluatedAt: now,
+			Value:       outcome.value,
+			Threshold:   rule.ThresholdValue,
+			State:       rs.state,
+			Details:     outcome.details,
+		}))
+	}
+	evaluated := e.evaluated
+	e.mu.Unlock()
+
+	if evaluated != nil && len(evaluations) > 0 {
+		evaluated(evaluations)
+	}
+
+	if e.notify != nil {
+		for _, event := range fired {
+			e.notify(event)
+		}
+	}
+	return fired
+}
+
+// Run evaluates rules on the given interval until the context is cancelled
+func (e *StreamEvaluator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate()
+		}
+	}
+}
+
+func isPerKeyCondition(conditionType string) bool {
+	return isPatternCondition(conditionType) || conditionType == ConditionIPScore ||
+		conditionType == ConditionLatencyBudget || conditionType == ConditionIntegrity
+}
+
+// evaluatePerKeyRule evaluates a pattern, IP score, latency budget or
+// integrity rule. The rule fires while any pattern, IP, budget or check
+// meets its condition.
+func (e *StreamEvaluator) evaluatePerKeyRule(rule *models.AlertRule, rs *ruleState, now time.Time) ([]*models.AlertEvent, float64) {
+	if rs.reported == nil {
+		rs.reported = make(map[string]time.Time)
+	}
+
+	var fired []*models.AlertEvent
+	var value float64
+	switch rule.ConditionType {
+	case ConditionIPScore:
+		fired, value = e.evaluateIPScoreRule(rule, rs, now)
+	case ConditionLatencyBudget:
+		fired, value = e.evaluateLatencyBudgetRule(rule, rs, now)
+	case ConditionIntegrity:
+		fired, value = e.evaluateIntegrityRule(rule, rs, now)
+	default:
+		fired, value = e.evaluatePatternRule(rule, rs, now)
+	}
+
+	rs.state = models.RuleStateOK
+	if value > 0 {
+		rs.state = models.RuleStateFiring
+	}
+	return fired, value
+}
+
+// ruleOutcome is the result of evaluating a rule against a window
+type ruleOutcome struct {
+	value     float64
+	breached  bool
+	recovered bool
+	details   map[string]float64
+}
+
+func evaluateRule(rule *models.AlertRule, counts WindowCounts) ruleOutcome {
+	if rule.ConditionType == ConditionComposite {
+		details := make(map[string]float64)
+		breached := evaluateExpression(rule.Expression, counts, details)
+		outcome := ruleOutcome{breached: breached, recovered: !breached, details: details}
+		if breached {
+			outcome.value = 1
+		}
+		return outcome
+	}
+
+	value := metricFuncs[rule.ConditionType](counts)
+	recovery := rule.ThresholdValue
+	if rule.RecoveryThreshold != nil {
+		recovery = *rule.RecoveryThreshold
+	}
+	return ruleOutcome{
+		value:     value,
+		breached:  value > rule.ThresholdValue,
+		recovered: value <= recovery,
+	}
+}
+
+// transition advances a rule's state for the latest outcome and reports
+// whether the rule has just started firing
+func transition(rs *ruleState, outcome ruleOutcome, holdFor time.Duration, now time.Time) bool {
+	switch rs.state {
+	case models.RuleStateFiring:
+		if outcome.recovered {
+			rs.state = models.RuleStateOK
+		}
+		return false
+	case models.RuleStatePending:
+		if !outcome.breached {
+			rs.state = models.RuleStateOK
+			return false
+		}
+	default:
+		if !outcome.breached {
+			return false
+		}
+		rs.state = models.RuleStatePending
+		rs.pendingSince = now
+	}
+
+	if now.Sub(rs.pendingSince) >= holdFor {
+		rs.state = models.RuleStateFiring
+		return true
+	}
+	return false
+}
+
+func newAlertEvent(rule *models.AlertRule, outcome ruleOutcome, now time.Time) *models.AlertEvent {
+	severity := "warning"
+	message := fmt.Sprintf("%s: %s is %.2f over the last %ds (threshold %.2f)", rule.Name, rule.ConditionType, outcome.value, rule.TimeWindow, rule.ThresholdValue)
+
+	if rule.ConditionType == ConditionComposite {
+		message = fmt.Sprintf("%s: composite condition met over the last %ds (%s)", rule.Name, rule.TimeWindow, formatDetails(outcome.details))
+	} else if rule.ThresholdValue > 0 && outcome.value >= rule.ThresholdValue*2 {
+		severity = "critical"
+	}
+
+	return &models.AlertEvent{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Message:     message,
+		Severity:    severity,
+		Value:       outcome.value,
+		Threshold:   rule.ThresholdValue,
+		Details:     outcome.details,
+		TriggeredAt: now,
+	}
+}
@@ -0,0 +1,251 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func newTestEvaluator(start time.Time) (*StreamEvaluator, *time.Time) {
+	clock := start
+	evaluator := NewStreamEvaluator(nil)
+	evaluator.now = func() time.Time { return clock }
+	return evaluator, &clock
+}
+
+func TestValidateRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "5xx burst", ConditionType: ConditionServerErrorCount, ThresholdValue: 50, TimeWindow: 60}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.ConditionType = "unknown"
+	assert.Error(t, ValidateRule(rule))
+
+	rule.ConditionType = ConditionErrorRate
+	rule.TimeWindow = MaxTimeWindow + 1
+	assert.Error(t, ValidateRule(rule))
+}
+
+func TestStreamEvaluatorWindowsOnEntryTimestamps(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	evaluator, _ := newTestEvaluator(now)
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "5xx burst", ConditionType: ConditionServerErrorCount, ThresholdValue: 1, TimeWindow: 60, IsActive: true},
+	})
+
+	// A backfill of old entries does not count towards the window
+	evaluator.Observe(&models.LogEntry{Timestamp: now.Add(-2 * time.Minute), StatusCode: 500})
+	evaluator.Observe(&models.LogEntry{Timestamp: now.Add(-90 * time.Second), StatusCode: 500})
+	evaluator.Observe(&models.LogEntry{Timestamp: now.Add(-2 * time.Hour), StatusCode: 500})
+	evaluator.Observe(&models.LogEntry{Timestamp: now.Add(-30 * time.Second), StatusCode: 500})
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, int64(3), evaluator.window.sum(now.Unix(), MaxTimeWindow).Requests, "entries older than the longest window are ignored")
+
+	// Entries stamped in the future count as of now
+	evaluator.Observe(&models.LogEntry{Timestamp: now.Add(time.Hour), StatusCode: 503})
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Equal(t, 2.0, fired[0].Value)
+}
+
+func TestStreamEvaluatorFiresOnBreach(t *testing.T) {
+	evaluator, _ := newTestEvaluator(time.Unix(1700000000, 0))
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "5xx burst", ConditionType: ConditionServerErrorCount, ThresholdValue: 3, TimeWindow: 60, IsActive: true},
+	})
+
+	for i := 0; i < 3; i++ {
+		evaluator.Observe(&models.LogEntry{StatusCode: 500})
+	}
+	assert.Empty(t, evaluator.Evaluate())
+
+	evaluator.Observe(&models.LogEntry{StatusCode: 502})
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Equal(t, int64(1), fired[0].RuleID)
+	assert.Equal(t, 4.0, fired[0].Value)
+	assert.Equal(t, 4.0, fired[0].Window[ConditionRequestCount])
+	assert.Equal(t, 100.0, fired[0].Window[ConditionErrorRate])
+
+	// Still breached: no duplicate notification
+	assert.Empty(t, evaluator.Evaluate())
+}
+
+func TestStreamEvaluatorWindowExpiry(t *testing.T) {
+	evaluator, clock := newTestEvaluator(time.Unix(1700000000, 0))
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "traffic", ConditionType: ConditionRequestCount, ThresholdValue: 1, TimeWindow: 10, IsActive: true},
+	})
+
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	require.Len(t, evaluator.Evaluate(), 1)
+
+	// Once the window slides past the requests the rule resolves and can fire again
+	*clock = clock.Add(11 * time.Second)
+	assert.Empty(t, evaluator.Evaluate())
+
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	assert.Len(t, evaluator.Evaluate(), 1)
+}
+
+func TestStreamEvaluatorErrorRateAndLatency(t *testing.T) {
+	evaluator, _ := newTestEvaluator(time.Unix(1700000000, 0))
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "error rate", ConditionType: ConditionErrorRate, ThresholdValue: 40, TimeWindow: 60, IsActive: true},
+		{ID: 2, Name: "slow", ConditionType: ConditionAvgResponseTime, ThresholdValue: 1.5, TimeWindow: 60, IsActive: true},
+		{ID: 3, Name: "inactive", ConditionType: ConditionRequestCount, ThresholdValue: 0, TimeWindow: 60, IsActive: false},
+	})
+
+	evaluator.Observe(&models.LogEntry{StatusCode: 200, ProcessingTime: 1.0})
+	evaluator.Observe(&models.LogEntry{StatusCode: 404, ProcessingTime: 3.0})
+
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 2)
+	assert.Equal(t, 50.0, fired[0].Value)
+	assert.Equal(t, 2.0, fired[1].Value)
+	assert.Len(t, evaluator.Rules(), 2)
+}
+
+func TestStreamEvaluatorForDuration(t *testing.T) {
+	evaluator, clock := newTestEvaluator(time.Unix(1700000000, 0))
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "sustained", ConditionType: ConditionRequestCount, ThresholdValue: 0, TimeWindow: 60, ForDuration: 5, IsActive: true},
+	})
+
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStatePending, evaluator.State(1))
+
+	*clock = clock.Add(3 * time.Second)
+	assert.Empty(t, evaluator.Evaluate())
+
+	*clock = clock.Add(2 * time.Second)
+	assert.Len(t, evaluator.Evaluate(), 1)
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+}
+
+func TestStreamEvaluatorHysteresis(t *testing.T) {
+	evaluator, clock := newTestEvaluator(time.Unix(1700000000, 0))
+	recovery := 2.0
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "errors", ConditionType: ConditionErrorCount, ThresholdValue: 4, RecoveryThreshold: &recovery, TimeWindow: 10, IsActive: true},
+	})
+
+	for i := 0; i < 5; i++ {
+		evaluator.Observe(&models.LogEntry{StatusCode: 500})
+	}
+	require.Len(t, evaluator.Evaluate(), 1)
+
+	// Three errors in the window: below the threshold but above recovery, so still firing
+	*clock = clock.Add(11 * time.Second)
+	for i := 0; i < 3; i++ {
+		evaluator.Observe(&models.LogEntry{StatusCode: 500})
+	}
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+
+	*clock = clock.Add(11 * time.Second)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+
+	history := evaluator.History(1)
+	require.Len(t, history, 3)
+	assert.Equal(t, 5.0, history[0].Value)
+	assert.Equal(t, 3.0, history[1].Value)
+	assert.Equal(t, models.RuleStateOK, history[2].State)
+}
+
+func TestRuleStateHistoryRing(t *testing.T) {
+	rs := &ruleState{}
+	for i := 0; i < HistorySize+5; i++ {
+		rs.record(models.RuleEvaluation{Value: float64(i)})
+	}
+
+	history := rs.ordered()
+	require.Len(t, history, HistorySize)
+	assert.Equal(t, 5.0, history[0].Value)
+	assert.Equal(t, float64(HistorySize+4), history[HistorySize-1].Value)
+}
+
+func TestOnEvaluate(t *testing.T) {
+	evaluator, clock := newTestEvaluator(time.Unix(1700000000, 0))
+	var batches [][]models.RuleEvaluation
+	evaluator.OnEvaluate(func(evaluations []models.RuleEvaluation) { batches = append(batches, evaluations) })
+
+	evaluator.Evaluate()
+	assert.Empty(t, batches, "nothing to report without rules")
+
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "errors", ConditionType: ConditionErrorCount, ThresholdValue: 1, TimeWindow: 10, IsActive: true},
+		{ID: 2, Name: "requests", ConditionType: ConditionRequestCount, ThresholdValue: 100, TimeWindow: 10, IsActive: true},
+	})
+	evaluator.Observe(&models.LogEntry{StatusCode: 500})
+	evaluator.Observe(&models.LogEntry{StatusCode: 502})
+	evaluator.Evaluate()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+	assert.Equal(t, int64(1), batches[0][0].RuleID)
+	assert.Equal(t, models.RuleStateFiring, batches[0][0].State)
+	assert.Equal(t, *clock, batches[0][0].EvaluatedAt)
+	assert.Equal(t, models.RuleStateOK, batches[0][1].State)
+	assert.Equal(t, batches[0][:1], evaluator.History(1))
+}
+
+func TestStreamEvaluatorCompositeRule(t *testing.T) {
+	evaluator, _ := newTestEvaluator(time.Unix(1700000000, 0))
+	rule := &models.AlertRule{
+		ID:            1,
+		Name:          "errors under load",
+		ConditionType: ConditionComposite,
+		TimeWindow:    60,
+		IsActive:      true,
+		Expression: &models.AlertCondition{
+			Operator: "and",
+			Conditions: []*models.AlertCondition{
+				{Metric: ConditionErrorRate, Comparator: ">", Threshold: 5},
+				{Metric: ConditionRequestsPerMin, Comparator: ">", Threshold: 10},
+			},
+		},
+	}
+	require.NoError(t, ValidateRule(rule))
+	evaluator.SetRules([]*models.AlertRule{rule})
+
+	// High error rate but low traffic: no alert
+	evaluator.Observe(&models.LogEntry{StatusCode: 500})
+	evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	assert.Empty(t, evaluator.Evaluate())
+
+	history := evaluator.History(1)
+	require.Len(t, history, 1)
+	assert.Equal(t, 50.0, history[0].Details[ConditionErrorRate])
+	assert.Equal(t, 2.0, history[0].Details[ConditionRequestsPerMin])
+
+	for i := 0; i < 10; i++ {
+		evaluator.Observe(&models.LogEntry{StatusCode: 200})
+	}
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Contains(t, fired[0].Message, "requests_per_minute=12.00")
+}
+
+func TestValidateCompositeRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "bad", ConditionType: ConditionComposite, TimeWindow: 60}
+	assert.Error(t, ValidateRule(rule))
+
+	rule.Expression = &models.AlertCondition{Operator: "xor", Conditions: []*models.AlertCondition{{Metric: ConditionErrorRate, Comparator: ">"}}}
+	assert.Error(t, ValidateRule(rule))
+
+	rule.Expression = &models.AlertCondition{Operator: "or", Conditions: []*models.AlertCondition{{Metric: "bogus", Comparator: ">"}}}
+	assert.Error(t, ValidateRule(rule))
+
+	rule.Expression = &models.AlertCondition{Operator: "or", Conditions: []*models.AlertCondition{{Metric: ConditionErrorRate, Comparator: "=="}}}
+	assert.Error(t, ValidateRule(rule))
+
+	rule.Expression = &models.AlertCondition{Metric: ConditionErrorRate, Comparator: ">="}
+	assert.NoError(t, ValidateRule(rule))
+}
@@ -0,0 +1,70 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ConditionIntegrity fires for each data integrity check whose latest run
+// found at least ThresholdValue violating records. Findings come from the
+// scheduled checker rather than the stream, so the rule's window is not
+// used.
+const ConditionIntegrity = "integrity"
+
+func validateIntegrityRule(rule *models.AlertRule) error {
+	if rule.ThresholdValue < 0 {
+		return fmt.Errorf("minimum finding count cannot be negative")
+	}
+	if rule.RecoveryThreshold != nil {
+		return fmt.Errorf("recovery threshold is not supported for integrity rules")
+	}
+	return nil
+}
+
+// SetIntegrityFindings replaces the findings integrity rules check
+func (e *StreamEvaluator) SetIntegrityFindings(findings []models.IntegrityFinding) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.findings = findings
+}
+
+// evaluateIntegrityRule reports checks that newly found violations and
+// returns the number of checks failing
+func (e *StreamEvaluator) evaluateIntegrityRule(rule *models.AlertRule, rs *ruleState, now time.Time) ([]*models.AlertEvent, float64) {
+	var fired []*models.AlertEvent
+
+	failing := make(map[string]bool)
+	for _, finding := range e.findings {
+		if finding.Count == 0 || float64(finding.Count) < rule.ThresholdValue {
+			continue
+		}
+		failing[finding.Check] = true
+		if _, reported := rs.reported[finding.Check]; reported {
+			continue
+		}
+		rs.reported[finding.Check] = now
+		fired = append(fired, integrityEvent(rule, finding, now))
+	}
+
+	for key := range rs.reported {
+		if !failing[key] {
+			delete(rs.reported, key)
+		}
+	}
+	return fired, float64(len(failing))
+}
+
+func integrityEvent(rule *models.AlertRule, finding models.IntegrityFinding, now time.Time) *models.AlertEvent {
+	return &models.AlertEvent{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Message:     fmt.Sprintf("%s: %s. Repair: %s", rule.Name, finding.Message, finding.Repair),
+		Severity:    finding.Severity,
+		Value:       float64(finding.Count),
+		Threshold:   rule.ThresholdValue,
+		TriggeredAt: now,
+	}
+}
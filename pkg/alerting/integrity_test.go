@@ -0,0 +1,61 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestIntegrityRule(t *testing.T) {
+	evaluator, _ := newTestEvaluator(time.Unix(1700000000, 0))
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "integrity", ConditionType: ConditionIntegrity, ThresholdValue: 5, TimeWindow: 300, IsActive: true},
+	})
+	assert.Empty(t, evaluator.Evaluate())
+
+	orphans := models.IntegrityFinding{
+		Check: "orphaned_alerts", Count: 12, Severity: "warning",
+		Message: "12 alerts in alert_history belong to rules that do not exist",
+		Repair:  "Delete them with DELETE FROM alert_history WHERE rule_id IN (42)",
+	}
+	evaluator.SetIntegrityFindings([]models.IntegrityFinding{
+		orphans,
+		{Check: "future_timestamps", Count: 2, Severity: "warning"},
+	})
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1, "too few future entries to fire")
+	assert.Contains(t, fired[0].Message, "12 alerts in alert_history")
+	assert.Contains(t, fired[0].Message, "Repair: Delete them")
+	assert.Equal(t, 12.0, fired[0].Value)
+	assert.Equal(t, "warning", fired[0].Severity)
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+
+	// Reported once while the check keeps failing
+	evaluator.SetIntegrityFindings([]models.IntegrityFinding{orphans})
+	assert.Empty(t, evaluator.Evaluate())
+
+	evaluator.SetIntegrityFindings(nil)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+
+	// A check failing again is reported again
+	evaluator.SetIntegrityFindings([]models.IntegrityFinding{orphans})
+	assert.Len(t, evaluator.Evaluate(), 1)
+}
+
+func TestValidateIntegrityRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "integrity", ConditionType: ConditionIntegrity, TimeWindow: 300}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.ThresholdValue = -1
+	assert.Error(t, ValidateRule(rule))
+
+	recovery := 0.0
+	rule.ThresholdValue = 1
+	rule.RecoveryThreshold = &recovery
+	assert.Error(t, ValidateRule(rule))
+}
@@ -0,0 +1,162 @@
+package alerting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/scoring"
+)
+
+// ConditionIPScore fires for each source IP whose suspiciousness score over
+// the rule's window exceeds ThresholdValue (0-100)
+const ConditionIPScore = "ip_score"
+
+// criticalIPScore is the score from which IP score alerts are critical
+const criticalIPScore = 90
+
+func validateIPScoreRule(rule *models.AlertRule) error {
+	if rule.ThresholdValue <= 0 || rule.ThresholdValue >= 100 {
+		return fmt.Errorf("ip score threshold must be between 0 and 100")
+	}
+	if rule.RecoveryThreshold != nil {
+		return fmt.Errorf("recovery threshold is not supported for ip score rules")
+	}
+	return nil
+}
+
+// ipTracker keeps per-minute activity for each source IP over the longest
+// rule window so IP score rules can be evaluated as logs stream in
+type ipTracker struct {
+	scorer *scoring.Scorer
+	ips    map[string]map[int64]*scoring.Activity
+}
+
+func newIPTracker() *ipTracker {
+	return &ipTracker{
+		scorer: scoring.NewScorer(),
+		ips:    make(map[string]map[int64]*scoring.Activity),
+	}
+}
+
+func (t *ipTracker) observe(now time.Time, entry *models.LogEntry) {
+	minutes, ok := t.ips[entry.SourceIP]
+	if !ok {
+		minutes = make(map[int64]*scoring.Activity)
+		t.ips[entry.SourceIP] = minutes
+	}
+
+	minute := now.Unix() / 60
+	activity, ok := minutes[minute]
+	if !ok {
+		activity = scoring.NewActivity(entry.SourceIP)
+		minutes[minute] = activity
+	}
+	activity.Add(entry)
+}
+
+// prune drops activity older than the longest rule window
+func (t *ipTracker) prune(now time.Time) {
+	oldest := now.Unix()/60 - MaxTimeWindow/60
+	for ip, minutes := range t.ips {
+		for minute := range minutes {
+			if minute < oldest {
+				delete(minutes, minute)
+			}
+		}
+		if len(minutes) == 0 {
+			delete(t.ips, ip)
+		}
+	}
+}
+
+// activities merges each IP's activity over the last window seconds
+func (t *ipTracker) activities(now time.Time, window int) []*scoring.Activity {
+	nowMinute := now.Unix() / 60
+	since := nowMinute - int64(math.Ceil(float64(window)/60)) + 1
+
+	var result []*scoring.Activity
+	for ip, minutes := range t.ips {
+		var keys []int64
+		for minute := range minutes {
+			if minute >= since && minute <= nowMinute {
+				keys = append(keys, minute)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		// In time order so geo velocity follows the IP's movements
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		merged := scoring.NewActivity(ip)
+		for _, minute := range keys {
+			merged.Merge(minutes[minute])
+		}
+		result = append(result, merged)
+	}
+	return result
+}
+
+// evaluateIPScoreRule reports IPs whose score newly exceeds the rule's
+// threshold and returns the highest score
+func (e *StreamEvaluator) evaluateIPScoreRule(rule *models.AlertRule, rs *ruleState, now time.Time) ([]*models.AlertEvent, float64) {
+	var fired []*models.AlertEvent
+	var value float64
+
+	breaching := make(map[string]bool)
+	for _, score := range e.ips.scorer.Score(e.ips.activities(now, rule.TimeWindow)) {
+		if score.Score <= rule.ThresholdValue {
+			break // sorted highest first
+		}
+		breaching[score.IP] = true
+		value = math.Max(value, score.Score)
+
+		if _, reported := rs.reported[score.IP]; reported {
+			continue
+		}
+		rs.reported[score.IP] = now
+		fired = append(fired, ipScoreEvent(rule, score, now))
+	}
+
+	for ip := range rs.reported {
+		if !breaching[ip] {
+			delete(rs.reported, ip)
+		}
+	}
+	return fired, value
+}
+
+func ipScoreEvent(rule *models.AlertRule, score scoring.IPScore, now time.Time) *models.AlertEvent {
+	severity := "warning"
+	if score.Score >= criticalIPScore {
+		severity = "critical"
+	}
+
+	message := fmt.Sprintf("%s: %s scored %.1f over the last %ds (threshold %.1f)", rule.Name, score.IP, score.Score, rule.TimeWindow, rule.ThresholdValue)
+	if len(score.Reasons) > 0 {
+		message += ": " + strings.Join(score.Reasons, ", ")
+	}
+
+	details := map[string]float64{
+		"requests":            float64(score.Requests),
+		"requests_per_minute": score.RequestsPerMinute,
+	}
+	for component, value := range score.Components {
+		details[component] = value
+	}
+
+	return &models.AlertEvent{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Message:     message,
+		Severity:    severity,
+		Value:       score.Score,
+		Threshold:   rule.ThresholdValue,
+		Details:     details,
+		TriggeredAt: now,
+	}
+}
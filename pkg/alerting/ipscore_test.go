@@ -0,0 +1,59 @@
+package alerting
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestIPScoreRule(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	evaluator, clock := newTestEvaluator(start)
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "suspicious ips", ConditionType: ConditionIPScore, ThresholdValue: 60, TimeWindow: 300, IsActive: true},
+	})
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 6; j++ {
+			evaluator.Observe(&models.LogEntry{
+				SourceIP: fmt.Sprintf("10.0.0.%d", i), Path: "/", StatusCode: 200,
+				UserAgent: "Mozilla/5.0", Timestamp: start.Add(time.Duration(j) * time.Minute),
+			})
+		}
+	}
+	assert.Empty(t, evaluator.Evaluate())
+
+	for i := 0; i < 200; i++ {
+		evaluator.Observe(&models.LogEntry{
+			SourceIP: "203.0.113.9", Path: fmt.Sprintf("/.env%d", i), StatusCode: 404,
+			UserAgent: fmt.Sprintf("scanner/%d", i%5), Timestamp: start,
+		})
+	}
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Contains(t, fired[0].Message, "203.0.113.9")
+	assert.Greater(t, fired[0].Value, 60.0)
+	assert.Equal(t, 1.0, fired[0].Details["error_ratio"])
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+
+	// Reported once while it stays above the threshold
+	assert.Empty(t, evaluator.Evaluate())
+
+	// The activity ages out of the window
+	*clock = start.Add(10 * time.Minute)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+}
+
+func TestValidateIPScoreRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "ips", ConditionType: ConditionIPScore, ThresholdValue: 70, TimeWindow: 600}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.ThresholdValue = 100
+	assert.Error(t, ValidateRule(rule))
+}
@@ -0,0 +1,169 @@
+package alerting
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/latency"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ConditionLatencyBudget fires for each latency budget violated over the
+// rule's window. ThresholdValue is the number of requests a path needs in
+// the window before its budget is checked, so a single slow request to a
+// quiet path does not fire.
+const ConditionLatencyBudget = "latency_budget"
+
+func validateLatencyBudgetRule(rule *models.AlertRule) error {
+	if rule.ThresholdValue < 0 {
+		return fmt.Errorf("minimum request count cannot be negative")
+	}
+	if rule.RecoveryThreshold != nil {
+		return fmt.Errorf("recovery threshold is not supported for latency budget rules")
+	}
+	return nil
+}
+
+// budgetTracker keeps per-minute response time histograms of the paths
+// with latency budgets over the longest rule window
+type budgetTracker struct {
+	budgets []*models.LatencyBudget
+	paths   map[string]map[int64]*latency.Histogram
+}
+
+func newBudgetTracker() *budgetTracker {
+	return &budgetTracker{paths: make(map[string]map[int64]*latency.Histogram)}
+}
+
+// setBudgets replaces the budgets, keeping the histograms of paths that
+// still have one
+func (t *budgetTracker) setBudgets(budgets []*models.LatencyBudget) {
+	t.budgets = budgets
+	paths := make(map[string]map[int64]*latency.Histogram, len(budgets))
+	for _, budget := range budgets {
+		if minutes, ok := t.paths[budget.Path]; ok {
+			paths[budget.Path] = minutes
+		} else {
+			paths[budget.Path] = make(map[int64]*latency.Histogram)
+		}
+	}
+	t.paths = paths
+}
+
+func (t *budgetTracker) observe(now time.Time, entry *models.LogEntry) {
+	if len(t.budgets) == 0 || entry.ProcessingTime <= 0 {
+		return
+	}
+	minutes, ok := t.paths[latency.NormalizePath(entry.Path)]
+	if !ok {
+		return
+	}
+
+	minute := now.Unix() / 60
+	histogram, ok := minutes[minute]
+	if !ok {
+		histogram = &latency.Histogram{}
+		minutes[minute] = histogram
+	}
+	histogram.Add(entry.ProcessingTime * 1000)
+}
+
+// prune drops histograms older than the longest rule window
+func (t *budgetTracker) prune(now time.Time) {
+	oldest := now.Unix()/60 - MaxTimeWindow/60
+	for _, minutes := range t.paths {
+		for minute := range minutes {
+			if minute < oldest {
+				delete(minutes, minute)
+			}
+		}
+	}
+}
+
+// histogram merges a path's response times over the last window seconds
+func (t *budgetTracker) histogram(path string, now time.Time, window int) *latency.Histogram {
+	nowMinute := now.Unix() / 60
+	since := nowMinute - int64(math.Ceil(float64(window)/60)) + 1
+
+	merged := &latency.Histogram{}
+	for minute, histogram := range t.paths[path] {
+		if minute >= since && minute <= nowMinute {
+			merged.Merge(histogram)
+		}
+	}
+	return merged
+}
+
+// SetLatencyBudgets replaces the budgets latency budget rules check
+func (e *StreamEvaluator) SetLatencyBudgets(budgets []*models.LatencyBudget) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.budgets.setBudgets(budgets)
+}
+
+// evaluateLatencyBudgetRule reports budgets newly violated over the rule's
+// window and returns the number of budgets violated
+func (e *StreamEvaluator) evaluateLatencyBudgetRule(rule *models.AlertRule, rs *ruleState, now time.Time) ([]*models.AlertEvent, float64) {
+	var fired []*models.AlertEvent
+
+	violated := make(map[string]bool)
+	for _, budget := range e.budgets.budgets {
+		histogram := e.budgets.histogram(budget.Path, now, rule.TimeWindow)
+		if histogram.Count() == 0 || float64(histogram.Count()) < rule.ThresholdValue {
+			continue
+		}
+		result := latency.NewResult(budget, histogram.Count(), histogram.Percentile(budget.Percentile))
+		if !result.Violated {
+			continue
+		}
+
+		key := strconv.FormatInt(budget.ID, 10)
+		violated[key] = true
+		if _, reported := rs.reported[key]; reported {
+			continue
+		}
+		rs.reported[key] = now
+		fired = append(fired, latencyBudgetEvent(rule, result, now))
+	}
+
+	for key := range rs.reported {
+		if !violated[key] {
+			delete(rs.reported, key)
+		}
+	}
+	return fired, float64(len(violated))
+}
+
+func latencyBudgetEvent(rule *models.AlertRule, result latency.Result, now time.Time) *models.AlertEvent {
+	budget := result.Budget
+	severity := "warning"
+	if result.Observed >= budget.ThresholdMs*2 {
+		severity = "critical"
+	}
+
+	message := fmt.Sprintf("%s: %s p%g is %.0fms over the last %ds (budget %.0fms)",
+		rule.Name, budget.Path, budget.Percentile, result.Observed, rule.TimeWindow, budget.ThresholdMs)
+	if budget.Team != "" {
+		message += ", owned by " + budget.Team
+	}
+
+	return &models.AlertEvent{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Message:  message,
+		Severity: severity,
+		Value:    result.Observed,
+		// The budget rather than the rule's minimum request count
+		Threshold: budget.ThresholdMs,
+		Details: map[string]float64{
+			"budget_id":  float64(budget.ID),
+			"percentile": budget.Percentile,
+			"requests":   float64(result.Requests),
+			"overage":    result.Overage,
+		},
+		TriggeredAt: now,
+	}
+}
@@ -0,0 +1,72 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestLatencyBudgetRule(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	evaluator, clock := newTestEvaluator(start)
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "budgets", ConditionType: ConditionLatencyBudget, ThresholdValue: 10, TimeWindow: 300, IsActive: true},
+	})
+	evaluator.SetLatencyBudgets([]*models.LatencyBudget{
+		{ID: 7, Path: "/api/users/{id}", Percentile: 95, ThresholdMs: 200, Team: "accounts"},
+		{ID: 8, Path: "/api/search", Percentile: 50, ThresholdMs: 500},
+	})
+
+	// Too few requests to judge the budget
+	for i := 0; i < 5; i++ {
+		evaluator.Observe(&models.LogEntry{Path: "/api/users/1", ProcessingTime: 2})
+	}
+	assert.Empty(t, evaluator.Evaluate())
+
+	for i := 0; i < 15; i++ {
+		evaluator.Observe(&models.LogEntry{Path: "/api/users/42?expand=1", ProcessingTime: 0.05})
+		evaluator.Observe(&models.LogEntry{Path: "/api/search", ProcessingTime: 0.1})
+		evaluator.Observe(&models.LogEntry{Path: "/api/other", ProcessingTime: 5})
+	}
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1, "the slowest 5 of 20 requests break the p95 budget")
+	assert.Contains(t, fired[0].Message, "/api/users/{id} p95")
+	assert.Contains(t, fired[0].Message, "accounts")
+	assert.InEpsilon(t, 2000, fired[0].Value, 0.01)
+	assert.Equal(t, 200.0, fired[0].Threshold)
+	assert.Equal(t, 20.0, fired[0].Details["requests"])
+	assert.Equal(t, "critical", fired[0].Severity)
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+
+	// Reported once while the budget stays violated
+	assert.Empty(t, evaluator.Evaluate())
+
+	// The slow requests age out of the window
+	*clock = start.Add(10 * time.Minute)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+
+	// Removing a budget stops tracking its path
+	evaluator.SetLatencyBudgets([]*models.LatencyBudget{{ID: 8, Path: "/api/search", Percentile: 50, ThresholdMs: 500}})
+	for i := 0; i < 20; i++ {
+		evaluator.Observe(&models.LogEntry{Path: "/api/users/1", ProcessingTime: 2})
+	}
+	assert.Empty(t, evaluator.Evaluate())
+}
+
+func TestValidateLatencyBudgetRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "budgets", ConditionType: ConditionLatencyBudget, TimeWindow: 300}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.ThresholdValue = -1
+	assert.Error(t, ValidateRule(rule))
+
+	recovery := 0.0
+	rule.ThresholdValue = 10
+	rule.RecoveryThreshold = &recovery
+	assert.Error(t, ValidateRule(rule))
+}
@@ -0,0 +1,223 @@
+package alerting
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/patterns"
+)
+
+// Pattern condition types watch the message patterns of application logs
+// instead of the request counters. new_pattern fires once a pattern first
+// seen within the rule's window reaches ThresholdValue occurrences;
+// pattern_spike fires when a pattern's rate over the window exceeds
+// ThresholdValue times its rate over the preceding baseline.
+const (
+	ConditionNewPattern   = "new_pattern"
+	ConditionPatternSpike = "pattern_spike"
+)
+
+// PatternBaselineMinutes is how far before a pattern_spike window the
+// baseline rate is measured
+const PatternBaselineMinutes = 60
+
+// MinSpikeCount is the fewest occurrences within a window that count as a
+// spike, so a pattern going from one line to three doesn't page anybody
+const MinSpikeCount = 10
+
+// patternRingMinutes covers the longest rule window plus the baseline
+const patternRingMinutes = MaxTimeWindow/60 + PatternBaselineMinutes + 1
+
+func isPatternCondition(conditionType string) bool {
+	return conditionType == ConditionNewPattern || conditionType == ConditionPatternSpike
+}
+
+func validatePatternRule(rule *models.AlertRule) error {
+	if rule.ConditionType == ConditionPatternSpike && rule.ThresholdValue <= 1 {
+		return fmt.Errorf("pattern spike threshold is a rate multiplier and must be greater than 1")
+	}
+	if rule.RecoveryThreshold != nil {
+		return fmt.Errorf("recovery threshold is not supported for pattern rules")
+	}
+	return nil
+}
+
+// patternTracker mines the message patterns of ingested application logs
+// and keeps per-minute counts for each so new and spiking patterns can be
+// found. Patterns seen while seeding or during the learning period after
+// startup are considered known.
+type patternTracker struct {
+	miner      *patterns.Miner
+	stats      map[string]*patternStats
+	learnUntil time.Time
+}
+
+type patternStats struct {
+	firstObserved time.Time
+	lastObserved  time.Time
+	total         int64
+	known         bool
+	minutes       []minuteCount
+}
+
+type minuteCount struct {
+	minute int64
+	count  int64
+}
+
+func newPatternTracker() *patternTracker {
+	return &patternTracker{
+		miner: patterns.NewMiner(),
+		stats: make(map[string]*patternStats),
+	}
+}
+
+func (t *patternTracker) observe(message string, at time.Time, known bool) {
+	id, _ := t.miner.Assign(message, at)
+
+	st, ok := t.stats[id]
+	if !ok {
+		st = &patternStats{
+			firstObserved: at,
+			known:         known || at.Before(t.learnUntil),
+			minutes:       make([]minuteCount, patternRingMinutes),
+		}
+		t.stats[id] = st
+	}
+	if at.Before(st.firstObserved) {
+		st.firstObserved = at
+	}
+	if at.After(st.lastObserved) {
+		st.lastObserved = at
+	}
+	st.total++
+
+	minute := at.Unix() / 60
+	slot := &st.minutes[minute%patternRingMinutes]
+	if slot.minute > minute {
+		// Older than the ring covers
+		return
+	}
+	if slot.minute != minute {
+		*slot = minuteCount{minute: minute}
+	}
+	slot.count++
+}
+
+// count sums the occurrences in minutes [from, to)
+func (st *patternStats) count(from, to int64) int64 {
+	var total int64
+	for minute := from; minute < to; minute++ {
+		if slot := st.minutes[minute%patternRingMinutes]; slot.minute == minute {
+			total += slot.count
+		}
+	}
+	return total
+}
+
+// evaluatePatternRule reports the patterns that newly meet a pattern rule's
+// condition and returns the rule's current value: the number of new patterns
+// still occurring, or the highest spike ratio
+func (e *StreamEvaluator) evaluatePatternRule(rule *models.AlertRule, rs *ruleState, now time.Time) ([]*models.AlertEvent, float64) {
+	var fired []*models.AlertEvent
+	var value float64
+	window := time.Duration(rule.TimeWindow) * time.Second
+
+	switch rule.ConditionType {
+	case ConditionNewPattern:
+		minCount := int64(math.Max(rule.ThresholdValue, 1))
+		for id, st := range e.patterns.stats {
+			if _, reported := rs.reported[id]; reported {
+				if now.Sub(st.lastObserved) <= window {
+					value++
+				}
+				continue
+			}
+			if st.known || now.Sub(st.firstObserved) > window || st.total < minCount {
+				continue
+			}
+
+			rs.reported[id] = now
+			value++
+			if pattern, ok := e.patterns.miner.Pattern(id); ok {
+				fired = append(fired, newPatternEvent(rule, pattern, st.total, now))
+			}
+		}
+
+	case ConditionPatternSpike:
+		minutes := int64(math.Ceil(window.Minutes()))
+		nowMinute := now.Unix() / 60
+		windowStart := nowMinute - minutes + 1
+		// A pattern needs a full baseline before its rate can spike; brand
+		// new patterns are the new_pattern condition's job
+		historySince := now.Add(-window - PatternBaselineMinutes*time.Minute)
+
+		for id, st := range e.patterns.stats {
+			current := st.count(windowStart, nowMinute+1)
+			if current < MinSpikeCount || (!st.known && st.firstObserved.After(historySince)) {
+				delete(rs.reported, id)
+				continue
+			}
+
+			baseline := st.count(windowStart-PatternBaselineMinutes, windowStart)
+			rate := float64(current) / float64(minutes)
+			// Treat a quiet baseline as one occurrence so the ratio stays finite
+			baselineRate := math.Max(float64(baseline), 1) / PatternBaselineMinutes
+			ratio := rate / baselineRate
+			if ratio <= rule.ThresholdValue {
+				delete(rs.reported, id)
+				continue
+			}
+
+			value = math.Max(value, ratio)
+			if _, reported := rs.reported[id]; reported {
+				continue
+			}
+			rs.reported[id] = now
+			if pattern, ok := e.patterns.miner.Pattern(id); ok {
+				fired = append(fired, patternSpikeEvent(rule, pattern, current, rate, baselineRate, now))
+			}
+		}
+	}
+
+	return fired, value
+}
+
+func newPatternEvent(rule *models.AlertRule, pattern patterns.Pattern, count int64, now time.Time) *models.AlertEvent {
+	return &models.AlertEvent{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Message:     fmt.Sprintf("%s: new log pattern %q seen %d times, e.g. %q", rule.Name, pattern.Template, count, pattern.Examples[0]),
+		Severity:    "warning",
+		Value:       float64(count),
+		Threshold:   rule.ThresholdValue,
+		Details:     map[string]float64{"count": float64(count)},
+		TriggeredAt: now,
+	}
+}
+
+func patternSpikeEvent(rule *models.AlertRule, pattern patterns.Pattern, count int64, rate, baselineRate float64, now time.Time) *models.AlertEvent {
+	ratio := rate / baselineRate
+	severity := "warning"
+	if ratio >= rule.ThresholdValue*2 {
+		severity = "critical"
+	}
+
+	return &models.AlertEvent{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Message: fmt.Sprintf("%s: log pattern %q at %.1f/min over the last %ds, %.1fx its baseline of %.2f/min",
+			rule.Name, pattern.Template, rate, rule.TimeWindow, ratio, baselineRate),
+		Severity:  severity,
+		Value:     ratio,
+		Threshold: rule.ThresholdValue,
+		Details: map[string]float64{
+			"count":               float64(count),
+			"rate_per_minute":     rate,
+			"baseline_per_minute": baselineRate,
+		},
+		TriggeredAt: now,
+	}
+}
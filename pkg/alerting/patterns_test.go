@@ -0,0 +1,117 @@
+package alerting
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func observeMessage(evaluator *StreamEvaluator, message string, times int) {
+	for i := 0; i < times; i++ {
+		evaluator.Observe(&models.LogEntry{LogType: "generic", Path: message})
+	}
+}
+
+func TestNewPatternRule(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	evaluator, clock := newTestEvaluator(start)
+	evaluator.SetPatternLearningPeriod(time.Minute)
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "new errors", ConditionType: ConditionNewPattern, ThresholdValue: 2, TimeWindow: 300, IsActive: true},
+	})
+
+	// Learned before the learning period ends
+	observeMessage(evaluator, "cache warmed in 3s", 5)
+	assert.Empty(t, evaluator.Evaluate())
+
+	*clock = start.Add(2 * time.Minute)
+	observeMessage(evaluator, "failed to connect to db1", 1)
+	assert.Empty(t, evaluator.Evaluate(), "below the occurrence threshold")
+
+	observeMessage(evaluator, "failed to connect to db2", 1)
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Contains(t, fired[0].Message, `"failed to connect to <*>"`)
+	assert.Equal(t, 2.0, fired[0].Value)
+	assert.Equal(t, models.RuleStateFiring, evaluator.State(1))
+
+	// Reported once only
+	observeMessage(evaluator, "failed to connect to db3", 1)
+	assert.Empty(t, evaluator.Evaluate())
+
+	// Resolves once the pattern stops occurring
+	*clock = clock.Add(10 * time.Minute)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+}
+
+func TestNewPatternRuleIgnoresSeededPatterns(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	evaluator, _ := newTestEvaluator(start)
+	evaluator.SeedPatterns([]*models.LogEntry{
+		{LogType: "kubernetes", Path: "worker 1 restarted", Timestamp: start.Add(-time.Hour)},
+		{LogType: "nginx", Path: "/not/a/message", Timestamp: start.Add(-time.Hour)},
+	})
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "new errors", ConditionType: ConditionNewPattern, ThresholdValue: 1, TimeWindow: 300, IsActive: true},
+	})
+
+	observeMessage(evaluator, "worker 7 restarted", 3)
+	assert.Empty(t, evaluator.Evaluate())
+
+	observeMessage(evaluator, "disk full on /var", 1)
+	assert.Len(t, evaluator.Evaluate(), 1)
+}
+
+func TestPatternSpikeRule(t *testing.T) {
+	start := time.Unix(1700000000, 0).Truncate(time.Minute)
+	evaluator, clock := newTestEvaluator(start)
+	evaluator.SetRules([]*models.AlertRule{
+		{ID: 1, Name: "spikes", ConditionType: ConditionPatternSpike, ThresholdValue: 5, TimeWindow: 60, IsActive: true},
+	})
+
+	// An hour of baseline at one timeout a minute
+	for i := 0; i < PatternBaselineMinutes+1; i++ {
+		*clock = start.Add(time.Duration(i) * time.Minute)
+		observeMessage(evaluator, fmt.Sprintf("request %d timed out", i), 1)
+		assert.Empty(t, evaluator.Evaluate())
+	}
+
+	*clock = clock.Add(time.Minute)
+	observeMessage(evaluator, "request 999 timed out", 4)
+	assert.Empty(t, evaluator.Evaluate(), "below the minimum spike count")
+
+	observeMessage(evaluator, "request 1000 timed out", 20)
+	fired := evaluator.Evaluate()
+	require.Len(t, fired, 1)
+	assert.Equal(t, 24.0, fired[0].Value)
+	assert.Equal(t, "critical", fired[0].Severity)
+	assert.Equal(t, 1.0, fired[0].Details["baseline_per_minute"])
+	assert.Contains(t, fired[0].Message, `"request <NUM> timed out"`)
+
+	// Still spiking: no duplicate
+	assert.Empty(t, evaluator.Evaluate())
+
+	*clock = clock.Add(2 * time.Minute)
+	assert.Empty(t, evaluator.Evaluate())
+	assert.Equal(t, models.RuleStateOK, evaluator.State(1))
+}
+
+func TestValidatePatternRule(t *testing.T) {
+	rule := &models.AlertRule{Name: "spikes", ConditionType: ConditionPatternSpike, ThresholdValue: 1, TimeWindow: 60}
+	assert.Error(t, ValidateRule(rule))
+
+	rule.ThresholdValue = 3
+	assert.NoError(t, ValidateRule(rule))
+
+	recovery := 2.0
+	rule.RecoveryThreshold = &recovery
+	assert.Error(t, ValidateRule(rule))
+
+	assert.NoError(t, ValidateRule(&models.AlertRule{Name: "new", ConditionType: ConditionNewPattern, TimeWindow: 600}))
+}
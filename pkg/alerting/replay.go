@@ -0,0 +1,211 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// MaxReplayRange is the longest span of log time one replay may cover
+const MaxReplayRange = 7 * 24 * time.Hour
+
+// MaxReplayAlerts bounds the alerts a replay lists; rule summaries still
+// count every alert
+const MaxReplayAlerts = 1000
+
+// ReplaySource returns the stored entries in [start, end), oldest first
+type ReplaySource func(ctx context.Context, start, end time.Time) ([]*models.LogEntry, error)
+
+// ReplayOptions control a replay
+type ReplayOptions struct {
+	Start time.Time
+	End   time.Time
+	// Interval is the log time between evaluations, default 1s
+	Interval time.Duration
+	// Speed is the log time replayed per unit of real time, so 60 replays
+	// an hour in a minute; 0 replays as fast as entries can be loaded
+	Speed float64
+	// Step is the log time loaded from the source at once, default 1m
+	Step time.Duration
+	// PatternLearningPeriod treats patterns first seen this long after
+	// Start as known, as after a server start
+	PatternLearningPeriod time.Duration
+	// LatencyBudgets are the budgets latency budget rules check
+	LatencyBudgets []*models.LatencyBudget
+	// Progress, when set, is called after each step with the log time
+	// replayed up to
+	Progress func(through time.Time)
+}
+
+// Validate checks the time range and speed
+func (opts ReplayOptions) Validate() error {
+	if !opts.End.After(opts.Start) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	if opts.End.Sub(opts.Start) > MaxReplayRange {
+		return fmt.Errorf("replays cover at most %s", MaxReplayRange)
+	}
+	if opts.Speed < 0 {
+		return fmt.Errorf("speed cannot be negative")
+	}
+	return nil
+}
+
+// ReplayResult reports what rules would have done over a time range.
+// Alert times are log times.
+type ReplayResult struct {
+	Start           time.Time            `json:"start_time"`
+	End             time.Time            `json:"end_time"`
+	Entries         int64                `json:"entries"`
+	Evaluations     int64                `json:"evaluations"`
+	Rules           []ReplayRuleSummary  `json:"rules"`
+	Alerts          []*models.AlertEvent `json:"alerts"`
+	AlertsTruncated bool                 `json:"alerts_truncated,omitempty"`
+}
+
+// ReplayRuleSummary is one rule's behaviour during a replay
+type ReplayRuleSummary struct {
+	RuleID        int64      `json:"rule_id"`
+	RuleName      string     `json:"rule_name"`
+	Fired         int        `json:"fired"`
+	FirstFiredAt  *time.Time `json:"first_fired_at,omitempty"`
+	LastFiredAt   *time.Time `json:"last_fired_at,omitempty"`
+	PeakValue     float64    `json:"peak_value"`
+	FiringSeconds float64    `json:"firing_seconds"` // log time spent firing
+}
+
+// Replay feeds the entries of a time range through a fresh evaluator with
+// its clock following the entries' timestamps, evaluating the rules every
+// Interval of log time, and reports which rules would have fired and when.
+// Rules are replayed whether or not they are active.
+func Replay(ctx context.Context, rules []*models.AlertRule, source ReplaySource, opts ReplayOptions) (*ReplayResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Step <= 0 {
+		opts.Step = time.Minute
+	}
+
+	replayed := make([]*models.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := ValidateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		active := *rule
+		active.IsActive = true
+		replayed = append(replayed, &active)
+	}
+
+	clock := opts.Start
+	e := NewStreamEvaluator(nil)
+	e.now = func() time.Time { return clock }
+	e.SetRules(replayed)
+	e.SetLatencyBudgets(opts.LatencyBudgets)
+	e.SetPatternLearningPeriod(opts.PatternLearningPeriod)
+
+	result := &ReplayResult{Start: opts.Start, End: opts.End, Alerts: []*models.AlertEvent{}}
+	summaries := make(map[int64]*ReplayRuleSummary, len(replayed))
+	for _, rule := range replayed {
+		result.Rules = append(result.Rules, ReplayRuleSummary{RuleID: rule.ID, RuleName: rule.Name})
+	}
+	for i := range result.Rules {
+		summaries[result.Rules[i].RuleID] = &result.Rules[i]
+	}
+
+	next := opts.Start.Add(opts.Interval)
+	evaluate := func(at time.Time) {
+		clock = at
+		for _, event := range e.Evaluate() {
+			summary := summaries[event.RuleID]
+			summary.Fired++
+			triggered := event.TriggeredAt
+			if summary.FirstFiredAt == nil {
+				summary.FirstFiredAt = &triggered
+			}
+			summary.LastFiredAt = &triggered
+			if len(result.Alerts) < MaxReplayAlerts {
+				result.Alerts = append(result.Alerts, event)
+			} else {
+				result.AlertsTruncated = true
+			}
+		}
+		e.mu.Lock()
+		for id, summary := range summaries {
+			rs, ok := e.states[id]
+			if !ok {
+				continue
+			}
+			if latest, ok := rs.latest(); ok && latest.Value > summary.PeakValue {
+				summary.PeakValue = latest.Value
+			}
+			if rs.state == models.RuleStateFiring {
+				summary.FiringSeconds += opts.Interval.Seconds()
+			}
+		}
+		e.mu.Unlock()
+		result.Evaluations++
+	}
+
+	began := time.Now()
+	for start := opts.Start; start.Before(opts.End); start = start.Add(opts.Step) {
+		end := start.Add(opts.Step)
+		if end.After(opts.End) {
+			end = opts.End
+		}
+		entries, err := source(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			// Evaluate at every interval the entry's time has passed
+			for !entry.Timestamp.Before(next) {
+				evaluate(next)
+				next = next.Add(opts.Interval)
+			}
+			if entry.Timestamp.After(clock) {
+				clock = entry.Timestamp
+			}
+			e.Observe(entry)
+			result.Entries++
+		}
+		for !next.After(end) {
+			evaluate(next)
+			next = next.Add(opts.Interval)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(end)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Speed > 0 {
+			due := began.Add(time.Duration(float64(end.Sub(opts.Start)) / opts.Speed))
+			if err := sleepUntil(ctx, due); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
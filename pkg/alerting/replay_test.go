@@ -0,0 +1,113 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// sliceSource serves entries from a slice, recording the ranges asked for
+type sliceSource struct {
+	entries []*models.LogEntry
+	ranges  [][2]time.Time
+}
+
+func (s *sliceSource) load(ctx context.Context, start, end time.Time) ([]*models.LogEntry, error) {
+	s.ranges = append(s.ranges, [2]time.Time{start, end})
+	var entries []*models.LogEntry
+	for _, entry := range s.entries {
+		if !entry.Timestamp.Before(start) && entry.Timestamp.Before(end) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	source := &sliceSource{}
+	// A burst of server errors 90s in, then quiet
+	for i := 0; i < 5; i++ {
+		source.entries = append(source.entries, &models.LogEntry{Timestamp: start.Add(90*time.Second + time.Duration(i)*time.Second), StatusCode: 503})
+	}
+	source.entries = append(source.entries, &models.LogEntry{Timestamp: start.Add(150 * time.Second), StatusCode: 200})
+
+	rules := []*models.AlertRule{
+		{ID: 1, Name: "5xx burst", ConditionType: ConditionServerErrorCount, ThresholdValue: 3, TimeWindow: 30},
+		{ID: 2, Name: "traffic", ConditionType: ConditionRequestCount, ThresholdValue: 100, TimeWindow: 60, IsActive: true},
+	}
+	var progress []time.Time
+	result, err := Replay(context.Background(), rules, source.load, ReplayOptions{
+		Start:    start,
+		End:      start.Add(5 * time.Minute),
+		Progress: func(through time.Time) { progress = append(progress, through) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(6), result.Entries)
+	assert.Equal(t, int64(300), result.Evaluations, "one evaluation per second of log time")
+	assert.Len(t, source.ranges, 5, "entries are loaded a minute at a time")
+	assert.Equal(t, start.Add(5*time.Minute), progress[len(progress)-1])
+
+	require.Len(t, result.Alerts, 1, "inactive rules are replayed too")
+	assert.Equal(t, int64(1), result.Alerts[0].RuleID)
+	assert.Equal(t, start.Add(94*time.Second), result.Alerts[0].TriggeredAt, "alerts fire at the log time of the breach")
+
+	burst := result.Rules[0]
+	assert.Equal(t, 1, burst.Fired)
+	assert.Equal(t, start.Add(94*time.Second), *burst.FirstFiredAt)
+	assert.Equal(t, 5.0, burst.PeakValue)
+	assert.Equal(t, 27.0, burst.FiringSeconds, "the rule resolves once the burst leaves its window")
+	assert.Equal(t, 0, result.Rules[1].Fired)
+	assert.Nil(t, result.Rules[1].FirstFiredAt)
+}
+
+func TestReplayValidates(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	source := (&sliceSource{}).load
+	rule := &models.AlertRule{ID: 1, Name: "traffic", ConditionType: ConditionRequestCount, ThresholdValue: 1, TimeWindow: 60}
+
+	for _, opts := range []ReplayOptions{
+		{Start: start, End: start},
+		{Start: start, End: start.Add(MaxReplayRange + time.Second)},
+		{Start: start, End: start.Add(time.Minute), Speed: -1},
+	} {
+		_, err := Replay(context.Background(), []*models.AlertRule{rule}, source, opts)
+		assert.Error(t, err)
+	}
+
+	invalid := &models.AlertRule{Name: "broken", ConditionType: "unknown", TimeWindow: 60}
+	_, err := Replay(context.Background(), []*models.AlertRule{invalid}, source, ReplayOptions{Start: start, End: start.Add(time.Minute)})
+	assert.ErrorContains(t, err, "broken")
+
+	failing := func(ctx context.Context, start, end time.Time) ([]*models.LogEntry, error) {
+		return nil, errors.New("database down")
+	}
+	_, err = Replay(context.Background(), []*models.AlertRule{rule}, failing, ReplayOptions{Start: start, End: start.Add(time.Minute)})
+	assert.Error(t, err)
+}
+
+func TestReplaySpeed(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	rule := &models.AlertRule{ID: 1, Name: "traffic", ConditionType: ConditionRequestCount, ThresholdValue: 1, TimeWindow: 60}
+
+	began := time.Now()
+	_, err := Replay(context.Background(), []*models.AlertRule{rule}, (&sliceSource{}).load, ReplayOptions{
+		Start: start,
+		End:   start.Add(2 * time.Minute),
+		Speed: 1200, // two minutes in 100ms
+	})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(began), 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Replay(ctx, []*models.AlertRule{rule}, (&sliceSource{}).load, ReplayOptions{Start: start, End: start.Add(time.Hour), Speed: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
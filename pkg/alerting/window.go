@@ -0,0 +1,117 @@
+package alerting
+
+import (
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// WindowCounts holds the aggregated counters for a span of time
+type WindowCounts struct {
+	Seconds      int
+	Requests     int64
+	Errors       int64
+	ServerErrors int64
+	LatencySum   float64
+	LatencyCount int64
+}
+
+// ErrorRate returns the percentage of requests with a status code >= 400
+func (c WindowCounts) ErrorRate() float64 {
+	if c.Requests == 0 {
+		return 0
+	}
+	return float64(c.Errors) / float64(c.Requests) * 100
+}
+
+// RequestsPerMinute returns the average request rate over the window
+func (c WindowCounts) RequestsPerMinute() float64 {
+	if c.Seconds == 0 {
+		return 0
+	}
+	return float64(c.Requests) / (float64(c.Seconds) / 60)
+}
+
+// AvgResponseTime returns the mean processing time of requests that reported one
+func (c WindowCounts) AvgResponseTime() float64 {
+	if c.LatencyCount == 0 {
+		return 0
+	}
+	return c.LatencySum / float64(c.LatencyCount)
+}
+
+func (c *WindowCounts) add(other WindowCounts) {
+	c.Requests += other.Requests
+	c.Errors += other.Errors
+	c.ServerErrors += other.ServerErrors
+	c.LatencySum += other.LatencySum
+	c.LatencyCount += other.LatencyCount
+}
+
+type bucket struct {
+	second int64
+	counts WindowCounts
+}
+
+// slidingWindow is a ring of one-second buckets. Buckets are reused once the
+// ring wraps, so memory stays constant regardless of ingestion volume.
+type slidingWindow struct {
+	buckets []bucket
+}
+
+func newSlidingWindow(seconds int) *slidingWindow {
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &slidingWindow{buckets: make([]bucket, seconds)}
+}
+
+func (w *slidingWindow) size() int {
+	return len(w.buckets)
+}
+
+func (w *slidingWindow) slot(second int64) *bucket {
+	idx := second % int64(len(w.buckets))
+	if idx < 0 {
+		idx += int64(len(w.buckets))
+	}
+	return &w.buckets[idx]
+}
+
+func (w *slidingWindow) observe(second int64, entry *models.LogEntry) {
+	b := w.slot(second)
+	if b.second > second {
+		// Older than the ring covers
+		return
+	}
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+
+	b.counts.Requests++
+	if entry.StatusCode >= 400 {
+		b.counts.Errors++
+	}
+	if entry.StatusCode >= 500 {
+		b.counts.ServerErrors++
+	}
+	if entry.ProcessingTime > 0 {
+		b.counts.LatencySum += entry.ProcessingTime
+		b.counts.LatencyCount++
+	}
+}
+
+// sum aggregates the buckets covering the last n seconds up to and including now
+func (w *slidingWindow) sum(now int64, n int) WindowCounts {
+	if n > len(w.buckets) {
+		n = len(w.buckets)
+	}
+
+	total := WindowCounts{Seconds: n}
+	for i := 0; i < n; i++ {
+		second := now - int64(i)
+		b := w.slot(second)
+		if b.second == second {
+			total.add(b.counts)
+		}
+	}
+	return total
+}
@@ -0,0 +1,460 @@
+// Package archive moves log entries that retention is about to remove
+// into cold storage, as gzip-compressed NDJSON files of one day each with
+// a manifest per run, and reads them back for historical investigations.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// Format is the format of archived files
+const Format = "ndjson.gz"
+
+// ManifestFile describes the files of an archive
+const ManifestFile = "manifest.json"
+
+// SourceField marks a rehydrated entry with the archive file it was read
+// from. Such entries are already archived and are not archived again.
+const SourceField = "_archive"
+
+// maxFileSize is the compressed size after which a day continues in a new
+// file, bounding the memory a file is built in
+const maxFileSize = 64 << 20
+
+// idFormat names an archive by the time it was started
+const idFormat = "20060102T150405Z"
+
+// File is an archived file of entries from one UTC day
+type File struct {
+	Name     string    `json:"name"`
+	Day      string    `json:"day"`
+	LogTypes []string  `json:"log_types"`
+	Entries  int64     `json:"entries"`
+	First    time.Time `json:"first_timestamp"`
+	Last     time.Time `json:"last_timestamp"`
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+}
+
+// Manifest lists the files of an archive, the entries archived by one
+// retention cleanup
+type Manifest struct {
+	ID        string    `json:"id"`
+	Format    string    `json:"format"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Entries   int64     `json:"entries"`
+	Files     []*File   `json:"files"`
+}
+
+// Archiver keeps archives in a store, each in its own directory
+type Archiver struct {
+	store reportstore.Store
+	now   func() time.Time
+}
+
+// New returns an archiver of the archives in store
+func New(store reportstore.Store) *Archiver {
+	return &Archiver{store: store, now: time.Now}
+}
+
+// Manifests returns the manifests of every archive, oldest first
+func (a *Archiver) Manifests() ([]*Manifest, error) {
+	objects, err := a.store.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []*Manifest{}
+	for _, object := range objects {
+		if !object.Dir {
+			continue
+		}
+		manifest, err := a.Manifest(object.Name)
+		// Files are written before their manifest, so an archive without
+		// one holds nothing that was removed from the database
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].ID < manifests[j].ID })
+	return manifests, nil
+}
+
+// Manifest returns the manifest of an archive
+func (a *Archiver) Manifest(id string) (*Manifest, error) {
+	data, err := a.read(id + "/" + ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("malformed manifest of archive %s: %w", id, err)
+	}
+	return &manifest, nil
+}
+
+func (a *Archiver) read(name string) ([]byte, error) {
+	file, _, err := a.store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Location describes where an archive is kept
+func (a *Archiver) Location(id string) string {
+	return a.store.Location(id)
+}
+
+// readFile reads the entries of an archived file, checking them against
+// the manifest
+func (a *Archiver) readFile(id string, file *File) ([]*models.LogEntry, error) {
+	data, err := a.read(id + "/" + file.Name)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != file.SHA256 {
+		return nil, fmt.Errorf("archived file %s/%s does not match its manifest", id, file.Name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived file %s/%s: %w", id, file.Name, err)
+	}
+	defer gz.Close()
+
+	var entries []*models.LogEntry
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var entry models.LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("malformed entry in archived file %s/%s: %w", id, file.Name, err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archived file %s/%s: %w", id, file.Name, err)
+	}
+	return entries, nil
+}
+
+// encoder builds an archived file in memory
+type encoder struct {
+	file     *File
+	buf      bytes.Buffer
+	gz       *gzip.Writer
+	logTypes map[string]bool
+}
+
+func newEncoder(name, day string) *encoder {
+	e := &encoder{file: &File{Name: name, Day: day}, logTypes: make(map[string]bool)}
+	e.gz = gzip.NewWriter(&e.buf)
+	return e
+}
+
+func (e *encoder) add(entry *models.LogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry %d: %w", entry.ID, err)
+	}
+	if _, err := e.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	if e.file.Entries == 0 || entry.Timestamp.Before(e.file.First) {
+		e.file.First = entry.Timestamp.UTC()
+	}
+	if e.file.Entries == 0 || entry.Timestamp.After(e.file.Last) {
+		e.file.Last = entry.Timestamp.UTC()
+	}
+	e.file.Entries++
+	e.logTypes[entry.LogType] = true
+	return nil
+}
+
+// close finishes the file, returning its contents
+func (e *encoder) close() ([]byte, error) {
+	if err := e.gz.Close(); err != nil {
+		return nil, err
+	}
+	data := e.buf.Bytes()
+	sum := sha256.Sum256(data)
+	e.file.Size = int64(len(data))
+	e.file.SHA256 = hex.EncodeToString(sum[:])
+	e.file.LogTypes = make([]string, 0, len(e.logTypes))
+	for logType := range e.logTypes {
+		e.file.LogTypes = append(e.file.LogTypes, logType)
+	}
+	sort.Strings(e.file.LogTypes)
+	return data, nil
+}
+
+// Writer adds entries to a new archive. It is not safe for concurrent use.
+type Writer struct {
+	archiver *Archiver
+	manifest *Manifest
+	open     map[string]*encoder
+	parts    map[string]int
+}
+
+// NewWriter starts an archive named by the current time
+func (a *Archiver) NewWriter() (*Writer, error) {
+	now := a.now().UTC()
+	id := now.Format(idFormat)
+	// Runs started within the same second get a sequence number
+	for i := 2; ; i++ {
+		if _, err := a.store.Stat(id + "/" + ManifestFile); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		id = fmt.Sprintf("%s-%d", now.Format(idFormat), i)
+	}
+
+	return &Writer{
+		archiver: a,
+		manifest: &Manifest{ID: id, Format: Format, CreatedAt: now, Files: []*File{}},
+		open:     make(map[string]*encoder),
+		parts:    make(map[string]int),
+	}, nil
+}
+
+// ID returns the name of the archive being written
+func (w *Writer) ID() string {
+	return w.manifest.ID
+}
+
+// Add archives entries, skipping those rehydrated from an archive, and
+// returns how many were added. They are stored by the next Flush.
+func (w *Writer) Add(entries []*models.LogEntry) (int, error) {
+	added := 0
+	for _, entry := range entries {
+		if _, ok := entry.Metadata[SourceField]; ok {
+			continue
+		}
+		day := entry.Timestamp.UTC().Format("2006-01-02")
+		e, ok := w.open[day]
+		if !ok {
+			w.parts[day]++
+			e = newEncoder(fmt.Sprintf("%s-%03d.%s", day, w.parts[day], Format), day)
+			w.open[day] = e
+		}
+		if err := e.add(entry); err != nil {
+			return added, err
+		}
+		added++
+
+		if e.buf.Len() >= maxFileSize {
+			if err := w.store(e); err != nil {
+				return added, err
+			}
+			delete(w.open, day)
+		}
+	}
+	return added, nil
+}
+
+// Flush stores the files being built and then the manifest listing every
+// file so far. Entries may be removed once it returns.
+func (w *Writer) Flush() error {
+	days := make([]string, 0, len(w.open))
+	for day := range w.open {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	for _, day := range days {
+		if err := w.store(w.open[day]); err != nil {
+			return err
+		}
+		delete(w.open, day)
+	}
+	if len(w.manifest.Files) == 0 {
+		return nil
+	}
+	return w.archiver.putManifest(w.manifest)
+}
+
+// Manifest returns the manifest of what has been flushed
+func (w *Writer) Manifest() *Manifest {
+	return w.manifest
+}
+
+func (w *Writer) store(e *encoder) error {
+	data, err := e.close()
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %w", e.file.Name, err)
+	}
+	if err := w.archiver.store.Put(w.manifest.ID+"/"+e.file.Name, data); err != nil {
+		return fmt.Errorf("failed to store %s: %w", e.file.Name, err)
+	}
+	w.manifest.Files = append(w.manifest.Files, e.file)
+	w.manifest.Entries += e.file.Entries
+	return nil
+}
+
+func (a *Archiver) putManifest(manifest *Manifest) error {
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Name < manifest.Files[j].Name })
+	manifest.UpdatedAt = a.now().UTC()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := a.store.Put(manifest.ID+"/"+ManifestFile, data); err != nil {
+		return fmt.Errorf("failed to store manifest of archive %s: %w", manifest.ID, err)
+	}
+	return nil
+}
+
+// Range selects archived entries to rehydrate: those from [Start, End),
+// of LogType if it is set
+type Range struct {
+	Start   time.Time
+	End     time.Time
+	LogType string
+}
+
+func (r Range) covers(entry *models.LogEntry) bool {
+	return !entry.Timestamp.Before(r.Start) && entry.Timestamp.Before(r.End) &&
+		(r.LogType == "" || entry.LogType == r.LogType)
+}
+
+// FileRef is an archived file in the archive it belongs to
+type FileRef struct {
+	Archive string `json:"archive"`
+	*File
+}
+
+// Find returns the archived files that may hold entries of the range
+func (a *Archiver) Find(r Range) ([]FileRef, error) {
+	manifests, err := a.Manifests()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []FileRef
+	for _, manifest := range manifests {
+		for _, file := range manifest.Files {
+			if file.Entries == 0 || !file.First.Before(r.End) || file.Last.Before(r.Start) {
+				continue
+			}
+			if r.LogType != "" && !slices.Contains(file.LogTypes, r.LogType) {
+				continue
+			}
+			refs = append(refs, FileRef{Archive: manifest.ID, File: file})
+		}
+	}
+	return refs, nil
+}
+
+// Load returns the entries of an archived file in the range, ready to be
+// stored again: without IDs and marked with the file they came from
+func (a *Archiver) Load(ctx context.Context, ref FileRef, r Range) ([]*models.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := a.readFile(ref.Archive, ref.File)
+	if err != nil {
+		return nil, err
+	}
+
+	source := ref.Archive + "/" + ref.Name
+	loaded := entries[:0]
+	for _, entry := range entries {
+		if !r.covers(entry) {
+			continue
+		}
+		entry.ID = 0
+		entry.CreatedAt, entry.UpdatedAt = time.Time{}, time.Time{}
+		if entry.Metadata == nil {
+			entry.Metadata = models.LogMetadata{}
+		}
+		entry.Metadata[SourceField] = source
+		loaded = append(loaded, entry)
+	}
+	return loaded, nil
+}
+
+// Rewrite passes every archived entry to fn, which may change it in place
+// and reports whether to keep it and whether it changed. Files with
+// removed or changed entries are rewritten with their manifests. Rewrite
+// returns those files as "<archive>/<file>" and how many entries changed
+// or were removed.
+func (a *Archiver) Rewrite(ctx context.Context, fn func(*models.LogEntry) (keep, changed bool)) ([]string, int64, error) {
+	manifests, err := a.Manifests()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rewritten := []string{}
+	var affected int64
+	for _, manifest := range manifests {
+		for i, file := range manifest.Files {
+			if err := ctx.Err(); err != nil {
+				return rewritten, affected, err
+			}
+			entries, err := a.readFile(manifest.ID, file)
+			if err != nil {
+				return rewritten, affected, err
+			}
+
+			e := newEncoder(file.Name, file.Day)
+			var fileAffected int64
+			for _, entry := range entries {
+				keep, changed := fn(entry)
+				if !keep || changed {
+					fileAffected++
+				}
+				if !keep {
+					continue
+				}
+				if err := e.add(entry); err != nil {
+					return rewritten, affected, err
+				}
+			}
+			if fileAffected == 0 {
+				continue
+			}
+
+			data, err := e.close()
+			if err != nil {
+				return rewritten, affected, fmt.Errorf("failed to compress %s: %w", file.Name, err)
+			}
+			if err := a.store.Put(manifest.ID+"/"+file.Name, data); err != nil {
+				return rewritten, affected, fmt.Errorf("failed to rewrite %s/%s: %w", manifest.ID, file.Name, err)
+			}
+			// The manifest follows each file, so checksums never disagree
+			// for longer than a rewrite
+			manifest.Entries += e.file.Entries - file.Entries
+			manifest.Files[i] = e.file
+			if err := a.putManifest(manifest); err != nil {
+				return rewritten, affected, err
+			}
+			affected += fileAffected
+			rewritten = append(rewritten, manifest.ID+"/"+file.Name)
+		}
+	}
+	return rewritten, affected, nil
+}
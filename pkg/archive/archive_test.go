@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func entry(id int64, hours int, logType, ip string) *models.LogEntry {
+	return &models.LogEntry{
+		ID:        id,
+		Timestamp: base.Add(time.Duration(hours) * time.Hour),
+		LogType:   logType,
+		SourceIP:  ip,
+		RawLog:    ip + " GET /",
+		Metadata:  models.LogMetadata{"country": "DE"},
+	}
+}
+
+func newArchiver(t *testing.T) *Archiver {
+	a := New(reportstore.NewLocal(t.TempDir()))
+	a.now = func() time.Time { return base.AddDate(0, 3, 0) }
+	return a
+}
+
+func TestWriteAndRehydrate(t *testing.T) {
+	ctx := context.Background()
+	a := newArchiver(t)
+
+	w, err := a.NewWriter()
+	require.NoError(t, err)
+	assert.Equal(t, "20240601T000000Z", w.ID())
+
+	rehydrated := entry(4, 2, "nginx", "192.0.2.9")
+	rehydrated.Metadata[SourceField] = "20240501T000000Z/2024-03-01-001.ndjson.gz"
+	added, err := w.Add([]*models.LogEntry{
+		entry(1, 1, "nginx", "192.0.2.1"),
+		entry(2, 25, "syslog", "192.0.2.2"),
+		entry(3, 3, "syslog", "192.0.2.3"),
+		rehydrated,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, added, "rehydrated entries are not archived again")
+	require.NoError(t, w.Flush())
+
+	// A second writer in the same second gets its own archive
+	second, err := a.NewWriter()
+	require.NoError(t, err)
+	assert.Equal(t, "20240601T000000Z-2", second.ID())
+	require.NoError(t, second.Flush())
+
+	manifests, err := a.Manifests()
+	require.NoError(t, err)
+	require.Len(t, manifests, 1, "empty archives have no manifest")
+	manifest := manifests[0]
+	assert.Equal(t, int64(3), manifest.Entries)
+	require.Len(t, manifest.Files, 2)
+	day := manifest.Files[0]
+	assert.Equal(t, "2024-03-01-001.ndjson.gz", day.Name)
+	assert.Equal(t, []string{"nginx", "syslog"}, day.LogTypes)
+	assert.Equal(t, int64(2), day.Entries)
+	assert.Equal(t, base.Add(time.Hour), day.First)
+	assert.Equal(t, base.Add(3*time.Hour), day.Last)
+
+	r := Range{Start: base, End: base.Add(24 * time.Hour), LogType: "syslog"}
+	refs, err := a.Find(r)
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, "2024-03-01-001.ndjson.gz", refs[0].Name)
+
+	entries, err := a.Load(ctx, refs[0], r)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(0), entries[0].ID)
+	assert.Equal(t, "192.0.2.3", entries[0].SourceIP)
+	assert.Equal(t, base.Add(3*time.Hour), entries[0].Timestamp.UTC())
+	assert.Equal(t, "DE", entries[0].Metadata["country"])
+	assert.Equal(t, "20240601T000000Z/2024-03-01-001.ndjson.gz", entries[0].Metadata[SourceField])
+
+	refs, err = a.Find(Range{Start: base.Add(48 * time.Hour), End: base.Add(72 * time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+}
+
+func TestRewrite(t *testing.T) {
+	ctx := context.Background()
+	a := newArchiver(t)
+	w, err := a.NewWriter()
+	require.NoError(t, err)
+	_, err = w.Add([]*models.LogEntry{
+		entry(1, 1, "nginx", "192.0.2.1"),
+		entry(2, 2, "nginx", "192.0.2.2"),
+		entry(3, 25, "nginx", "192.0.2.3"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+
+	rewritten, affected, err := a.Rewrite(ctx, func(e *models.LogEntry) (bool, bool) {
+		return e.SourceIP != "192.0.2.1", false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20240601T000000Z/2024-03-01-001.ndjson.gz"}, rewritten)
+	assert.Equal(t, int64(1), affected)
+
+	manifest, err := a.Manifest("20240601T000000Z")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), manifest.Entries)
+	assert.Equal(t, int64(1), manifest.Files[0].Entries)
+
+	// Rewritten files still match their manifest
+	r := Range{Start: base, End: base.AddDate(0, 0, 7)}
+	refs, err := a.Find(r)
+	require.NoError(t, err)
+	var ips []string
+	for _, ref := range refs {
+		entries, err := a.Load(ctx, ref, r)
+		require.NoError(t, err)
+		for _, e := range entries {
+			ips = append(ips, e.SourceIP)
+		}
+	}
+	assert.Equal(t, []string{"192.0.2.2", "192.0.2.3"}, ips)
+}
+
+func TestLoadDetectsTampering(t *testing.T) {
+	a := newArchiver(t)
+	w, err := a.NewWriter()
+	require.NoError(t, err)
+	_, err = w.Add([]*models.LogEntry{entry(1, 1, "nginx", "192.0.2.1")})
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+
+	require.NoError(t, a.store.Put("20240601T000000Z/2024-03-01-001.ndjson.gz", []byte("altered")))
+	r := Range{Start: base, End: base.AddDate(0, 0, 1)}
+	refs, err := a.Find(r)
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	_, err = a.Load(context.Background(), refs[0], r)
+	assert.ErrorContains(t, err, "does not match its manifest")
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain seals n records as they would be appended
+func chain(t *testing.T, n int) []*models.AuditRecord {
+	t.Helper()
+	var records []*models.AuditRecord
+	var prev *models.AuditRecord
+	for i := 0; i < n; i++ {
+		record, err := NewRecord(ActionAlertAcknowledged, "alice", "alert:1", map[string]interface{}{"n": i})
+		require.NoError(t, err)
+		Seal(record, prev)
+		records = append(records, record)
+		prev = record
+	}
+	return records
+}
+
+func verify(records []*models.AuditRecord, v *Verifier) error {
+	for _, record := range records {
+		if err := v.Add(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSeal(t *testing.T) {
+	records := chain(t, 3)
+
+	assert.Equal(t, int64(1), records[0].Seq)
+	assert.Equal(t, GenesisHash, records[0].PrevHash)
+	assert.Equal(t, int64(3), records[2].Seq)
+	assert.Equal(t, records[1].Hash, records[2].PrevHash)
+	assert.Len(t, records[0].Hash, 64)
+	assert.Zero(t, records[0].RecordedAt.Nanosecond()%1000, "databases keep microseconds")
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	records := chain(t, 5)
+
+	v := NewVerifier()
+	require.NoError(t, verify(records, v))
+	assert.Equal(t, int64(5), v.Records)
+	assert.Equal(t, int64(1), v.First)
+	assert.Equal(t, records[4], v.Last())
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]*models.AuditRecord) []*models.AuditRecord
+		seq    int64
+	}{
+		{"modified", func(r []*models.AuditRecord) []*models.AuditRecord {
+			r[2].Actor = "mallory"
+			return r
+		}, 3},
+		{"modified details", func(r []*models.AuditRecord) []*models.AuditRecord {
+			r[1].Details = json.RawMessage(`{"n":7}`)
+			return r
+		}, 2},
+		{"removed", func(r []*models.AuditRecord) []*models.AuditRecord {
+			return append(r[:2], r[3:]...)
+		}, 4},
+		{"rehashed", func(r []*models.AuditRecord) []*models.AuditRecord {
+			// Recomputing the altered record's own hash still breaks the
+			// link from the next record
+			r[1].Subject = "alert:2"
+			r[1].Hash = Hash(r[1])
+			return r
+		}, 3},
+		{"first removed", func(r []*models.AuditRecord) []*models.AuditRecord {
+			return r[1:]
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(tt.tamper(chain(t, 5)), NewVerifier())
+			var violation *Violation
+			require.ErrorAs(t, err, &violation)
+			assert.Equal(t, tt.seq, violation.Seq)
+		})
+	}
+}
+
+func TestPartialVerifier(t *testing.T) {
+	records := chain(t, 5)
+
+	// An incremental export starts after the first record
+	v := NewPartialVerifier()
+	require.NoError(t, verify(records[2:], v))
+	assert.Equal(t, int64(3), v.First)
+	assert.Equal(t, int64(3), v.Records)
+}
+
+func TestHashIgnoresDetailsFormatting(t *testing.T) {
+	record := chain(t, 1)[0]
+	hash := Hash(record)
+
+	// Details read back from an export may be re-indented
+	record.Details = json.RawMessage(`{ "n": 0 }`)
+	assert.Equal(t, hash, Hash(record))
+
+	record.RecordedAt = record.RecordedAt.In(time.FixedZone("UTC+2", 2*60*60))
+	assert.Equal(t, hash, Hash(record))
+}
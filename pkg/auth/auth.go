@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audiences of the tokens the server signs itself, so a login state cannot
+// be presented as a session
+const (
+	sessionAudience = "log-analyzer-session"
+	loginAudience   = "log-analyzer-login"
+)
+
+// LoginTTL is how long a user has to sign in with the provider, and so
+// how long the login token of Login lasts
+const LoginTTL = 10 * time.Minute
+
+// MinSessionSecret is the shortest session secret accepted, in bytes
+const MinSessionSecret = 32
+
+// Config configures sign-in with an OpenID Connect provider
+type Config struct {
+	// Issuer is the provider's issuer URL, under which its OpenID
+	// configuration is published
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the server's callback URL registered with the
+	// provider
+	RedirectURL string
+	Scopes      []string
+	// Audiences are further audiences of bearer tokens from the provider
+	// the server accepts, besides its client ID
+	Audiences []string
+	// SessionSecret signs the session tokens issued after sign-in
+	SessionSecret []byte
+	SessionTTL    time.Duration
+	// Denylist holds the sessions revoked before they expire; nil keeps
+	// them in the process
+	Denylist Denylist
+	// HTTPClient reaches the provider; nil for a client with a 10 second
+	// timeout
+	HTTPClient *http.Client
+}
+
+// Identity is a signed-in user
+type Identity struct {
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Username string    `json:"username,omitempty"`
+	Expires  time.Time `json:"expires_at"`
+	// Bearer is set when the identity came from a provider token sent
+	// with the request rather than from a session
+	Bearer bool `json:"bearer"`
+	// Role is the user's role, which the server looks up rather than
+	// taking from the token
+	Role string `json:"role,omitempty"`
+}
+
+// Actor names the user in the audit log: their email, username or
+// subject, in that order
+func (i *Identity) Actor() string {
+	switch {
+	case i.Email != "":
+		return i.Email
+	case i.Username != "":
+		return i.Username
+	}
+	return i.Subject
+}
+
+// Denylist records revoked sessions by ID until they would have expired
+type Denylist interface {
+	Revoke(ctx context.Context, id string, expires time.Time) error
+	Revoked(ctx context.Context, id string) (bool, error)
+}
+
+// Authenticator signs users in with an OpenID Connect provider and
+// verifies the tokens requests carry
+type Authenticator struct {
+	provider  *provider
+	audiences []string
+	secret    []byte
+	ttl       time.Duration
+	denylist  Denylist
+}
+
+// New returns an authenticator for a provider. The provider is not
+// contacted until a user signs in or presents a token.
+func New(config Config) (*Authenticator, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("issuer, client ID and redirect URL are required")
+	}
+	if len(config.SessionSecret) < MinSessionSecret {
+		return nil, fmt.Errorf("session secret must be at least %d bytes", MinSessionSecret)
+	}
+	if config.SessionTTL <= 0 {
+		return nil, fmt.Errorf("session TTL must be positive")
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	denylist := config.Denylist
+	if denylist == nil {
+		denylist = NewMemoryDenylist()
+	}
+	return &Authenticator{
+		provider: &provider{
+			issuer:       config.Issuer,
+			clientID:     config.ClientID,
+			clientSecret: config.ClientSecret,
+			redirectURL:  config.RedirectURL,
+			scopes:       scopes,
+			client:       client,
+		},
+		audiences: append([]string{config.ClientID}, config.Audiences...),
+		secret:    config.SessionSecret,
+		ttl:       config.SessionTTL,
+		denylist:  denylist,
+	}, nil
+}
+
+// SessionTTL is how long sessions last
+func (a *Authenticator) SessionTTL() time.Duration {
+	return a.ttl
+}
+
+// Login starts a sign-in that returns the user to redirect. It returns the
+// provider URL to send the user to and a login token, which must come
+// back with the callback, such as in a cookie.
+func (a *Authenticator) Login(ctx context.Context, redirect string, now time.Time) (string, string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	target, err := a.provider.authCodeURL(ctx, state, nonce)
+	if err != nil {
+		return "", "", err
+	}
+	login, err := signToken(loginClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{loginAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(LoginTTL)),
+		},
+		Nonce:    nonce,
+		State:    state,
+		Redirect: redirect,
+	}, a.secret)
+	if err != nil {
+		return "", "", err
+	}
+	return target, login, nil
+}
+
+// Callback completes a sign-in with the code and state the provider sent
+// back and the login token of Login. It returns the user, their session
+// token and where to send them.
+func (a *Authenticator) Callback(ctx context.Context, code, state, login string, now time.Time) (*Identity, string, string, error) {
+	var claims loginClaims
+	if err := parseToken(login, a.secret, loginAudience, &claims, now); err != nil {
+		return nil, "", "", fmt.Errorf("sign-in expired or was not started here")
+	}
+	if state == "" || state != claims.State {
+		return nil, "", "", fmt.Errorf("sign-in state does not match")
+	}
+
+	idToken, err := a.provider.exchange(ctx, code, now)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if idToken.Nonce != claims.Nonce {
+		return nil, "", "", fmt.Errorf("%w: nonce does not match", ErrInvalidToken)
+	}
+	var user profile
+	if err := idToken.Claims(&user); err != nil {
+		return nil, "", "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	id, err := randomString()
+	if err != nil {
+		return nil, "", "", err
+	}
+	expires := now.Add(a.ttl).Truncate(time.Second)
+	session, err := signToken(sessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    idToken.Issuer,
+			Subject:   idToken.Subject,
+			Audience:  jwt.ClaimStrings{sessionAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+		profile: user,
+	}, a.secret)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return identityOf(idToken.Subject, user, expires), session, claims.Redirect, nil
+}
+
+// Authenticate verifies a session token that has not been revoked, or a
+// bearer token signed by the provider for the server's client ID or one
+// of its audiences
+func (a *Authenticator) Authenticate(ctx context.Context, token string, now time.Time) (*Identity, error) {
+	self, err := signedBySelf(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if self {
+		claims, err := a.session(token, now)
+		if err != nil {
+			return nil, err
+		}
+		revoked, err := a.denylist.Revoked(ctx, claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session revocation: %w", err)
+		}
+		if revoked {
+			return nil, fmt.Errorf("%w: signed out", ErrInvalidToken)
+		}
+		return identityOf(claims.Subject, claims.profile, claims.ExpiresAt.Time), nil
+	}
+
+	idToken, err := a.provider.verify(ctx, token, a.audiences, now)
+	if err != nil {
+		return nil, err
+	}
+	var user profile
+	if err := idToken.Claims(&user); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	identity := identityOf(idToken.Subject, user, idToken.Expiry)
+	identity.Bearer = true
+	return identity, nil
+}
+
+// session verifies a session token
+func (a *Authenticator) session(token string, now time.Time) (*sessionClaims, error) {
+	var claims sessionClaims
+	if err := parseToken(token, a.secret, sessionAudience, &claims, now); err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.ID == "" {
+		return nil, fmt.Errorf("%w: not a session", ErrInvalidToken)
+	}
+	return &claims, nil
+}
+
+// Revoke signs a session out, so its token is refused from now on even
+// though it has not expired. It returns the session's user.
+func (a *Authenticator) Revoke(ctx context.Context, token string, now time.Time) (*Identity, error) {
+	claims, err := a.session(token, now)
+	if err != nil {
+		return nil, err
+	}
+	// Kept past the expiry by the leeway tokens are given
+	if err := a.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Add(clockSkew)); err != nil {
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return identityOf(claims.Subject, claims.profile, claims.ExpiresAt.Time), nil
+}
+
+// LogoutURL is the provider's end-session endpoint, to end the user's
+// session with the provider too; empty when it has none or cannot be
+// reached
+func (a *Authenticator) LogoutURL(ctx context.Context) string {
+	return a.provider.logoutURL(ctx)
+}
+
+// IsInvalidToken reports whether err is a token being refused, rather
+// than the provider failing
+func IsInvalidToken(err error) bool {
+	return errors.Is(err, ErrInvalidToken)
+}
+
+func identityOf(subject string, user profile, expires time.Time) *Identity {
+	return &Identity{
+		Subject:  subject,
+		Email:    user.Email,
+		Name:     user.Name,
+		Username: user.PreferredUsername,
+		Expires:  expires.UTC(),
+	}
+}
+
+// randomString returns 128 random bits, base64url-encoded
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// providerClaims are the claims of the tokens the fake provider signs
+type providerClaims struct {
+	jwt.RegisteredClaims
+	profile
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// fakeProvider is an OpenID provider signing ID tokens with an RSA key
+type fakeProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// nonce is put in the ID tokens the token endpoint returns
+	nonce string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/keys",
+			"end_session_endpoint":   p.server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "key-1", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		if clientID != "analyzer" || secret != "s3cret" || r.FormValue("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "bad code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": p.sign(t, providerClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer: p.server.URL, Subject: "u-1", Audience: jwt.ClaimStrings{"analyzer"}, ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			profile: profile{Email: "alice@example.com", Name: "Alice"},
+			Nonce:   p.nonce,
+		})})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// sign signs claims with RS256
+func (p *fakeProvider) sign(t *testing.T, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newAuthenticator(t *testing.T, p *fakeProvider) *Authenticator {
+	a, err := New(Config{
+		Issuer:        p.server.URL,
+		ClientID:      "analyzer",
+		ClientSecret:  "s3cret",
+		RedirectURL:   "https://logs.example.com/auth/callback",
+		Audiences:     []string{"api://log-analyzer"},
+		SessionSecret: []byte(strings.Repeat("k", MinSessionSecret)),
+		SessionTTL:    8 * time.Hour,
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestSignIn(t *testing.T) {
+	p := newFakeProvider(t)
+	a := newAuthenticator(t, p)
+	ctx := context.Background()
+
+	target, login, err := a.Login(ctx, "/reports", now)
+	require.NoError(t, err)
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	assert.Equal(t, p.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	query := u.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "analyzer", query.Get("client_id"))
+	assert.Equal(t, "https://logs.example.com/auth/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	state := query.Get("state")
+	p.nonce = query.Get("nonce")
+
+	identity, session, redirect, err := a.Callback(ctx, "good-code", state, login, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "/reports", redirect)
+	assert.Equal(t, "u-1", identity.Subject)
+	assert.Equal(t, "alice@example.com", identity.Actor())
+	assert.Equal(t, now.Add(time.Minute+8*time.Hour), identity.Expires)
+
+	// The session authenticates requests until it expires
+	identity, err = a.Authenticate(ctx, session, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", identity.Name)
+	assert.False(t, identity.Bearer)
+	_, err = a.Authenticate(ctx, session, now.Add(9*time.Hour+time.Minute))
+	assert.True(t, IsInvalidToken(err))
+
+	// The login token is not a session, and a session from another
+	// secret is refused
+	_, err = a.Authenticate(ctx, login, now)
+	assert.True(t, IsInvalidToken(err))
+	other, err := signToken(sessionClaims{RegisteredClaims: jwt.RegisteredClaims{
+		ID: "s-1", Subject: "u-1", Audience: jwt.ClaimStrings{sessionAudience}, ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}}, []byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+	_, err = a.Authenticate(ctx, other, now)
+	assert.ErrorContains(t, err, "signature is invalid")
+
+	// A signed out session is refused before it expires
+	identity, err = a.Revoke(ctx, session, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", identity.Subject)
+	_, err = a.Authenticate(ctx, session, now.Add(2*time.Hour))
+	assert.ErrorContains(t, err, "signed out")
+
+	// Sign-ins fail with another state, a replayed nonce, a bad code or
+	// an expired login
+	_, _, _, err = a.Callback(ctx, "good-code", "forged", login, now)
+	assert.ErrorContains(t, err, "state does not match")
+	p.nonce = "other"
+	_, _, _, err = a.Callback(ctx, "good-code", state, login, now)
+	assert.ErrorContains(t, err, "nonce does not match")
+	_, _, _, err = a.Callback(ctx, "bad-code", state, login, now)
+	assert.ErrorContains(t, err, "invalid_grant: bad code")
+	_, _, _, err = a.Callback(ctx, "good-code", state, login, now.Add(time.Hour))
+	assert.ErrorContains(t, err, "sign-in expired")
+
+	assert.Equal(t, p.server.URL+"/logout", a.LogoutURL(ctx))
+}
+
+func TestBearerTokens(t *testing.T) {
+	p := newFakeProvider(t)
+	a := newAuthenticator(t, p)
+	ctx := context.Background()
+	claims := providerClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer: p.server.URL, Subject: "svc-reports", Audience: jwt.ClaimStrings{"api://log-analyzer"}, ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}}
+
+	identity, err := a.Authenticate(ctx, p.sign(t, claims), now)
+	require.NoError(t, err)
+	assert.Equal(t, "svc-reports", identity.Actor())
+	assert.True(t, identity.Bearer)
+
+	wrongAudience := claims
+	wrongAudience.Audience = jwt.ClaimStrings{"another-app"}
+	_, err = a.Authenticate(ctx, p.sign(t, wrongAudience), now)
+	assert.ErrorContains(t, err, "not meant for this server")
+
+	wrongIssuer := claims
+	wrongIssuer.Issuer = "https://evil.example.com"
+	_, err = a.Authenticate(ctx, p.sign(t, wrongIssuer), now)
+	assert.ErrorContains(t, err, "issued by a different provider")
+
+	_, err = a.Authenticate(ctx, p.sign(t, claims), now.Add(2*time.Hour))
+	assert.ErrorContains(t, err, "expired")
+
+	// A tampered payload fails the signature
+	token := p.sign(t, claims)
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(providerClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer: p.server.URL, Subject: "admin", Audience: jwt.ClaimStrings{"analyzer"}, ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}})
+	_, err = a.Authenticate(ctx, parts[0]+"."+base64.RawURLEncoding.EncodeToString(forged)+"."+parts[2], now)
+	assert.True(t, IsInvalidToken(err))
+
+	// Unsigned tokens are refused
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	_, err = a.Authenticate(ctx, none, now)
+	assert.True(t, IsInvalidToken(err))
+
+	// A provider that cannot be reached is not a refused token
+	p.server.Close()
+	unreachable := newAuthenticator(t, p)
+	_, err = unreachable.Authenticate(ctx, token, now)
+	assert.Error(t, err)
+	assert.False(t, IsInvalidToken(err))
+}
+
+func TestMemoryDenylist(t *testing.T) {
+	d := NewMemoryDenylist()
+	ctx := context.Background()
+	require.NoError(t, d.Revoke(ctx, "s-1", time.Now().Add(-time.Minute)))
+	revoked, err := d.Revoked(ctx, "s-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Expired sessions are dropped as others are revoked
+	require.NoError(t, d.Revoke(ctx, "s-2", time.Now().Add(time.Hour)))
+	revoked, _ = d.Revoked(ctx, "s-1")
+	assert.False(t, revoked)
+	revoked, _ = d.Revoked(ctx, "s-2")
+	assert.True(t, revoked)
+}
+
+func TestBearerToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, "", BearerToken(r))
+	r.Header.Set("Authorization", "bearer abc.def.ghi")
+	assert.Equal(t, "abc.def.ghi", BearerToken(r))
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	assert.Equal(t, "", BearerToken(r))
+}
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, RoleAllows(RoleAdmin, RoleAnalyst))
+	assert.True(t, RoleAllows(RoleAnalyst, RoleAnalyst))
+	assert.False(t, RoleAllows(RoleViewer, RoleAnalyst))
+	assert.False(t, RoleAllows("", RoleViewer))
+	assert.False(t, RoleAllows("superuser", RoleViewer))
+	assert.False(t, ValidRole("superuser"))
+}
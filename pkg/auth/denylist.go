@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryDenylist keeps revoked sessions in the process, so they are only
+// refused by this server and until it restarts
+type MemoryDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryDenylist returns an empty denylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{revoked: make(map[string]time.Time)}
+}
+
+// Revoke adds a session, dropping those that have expired meanwhile
+func (d *MemoryDenylist) Revoke(ctx context.Context, id string, expires time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for revoked, until := range d.revoked {
+		if now.After(until) {
+			delete(d.revoked, revoked)
+		}
+	}
+	d.revoked[id] = expires
+	return nil
+}
+
+// Revoked reports whether a session was revoked
+func (d *MemoryDenylist) Revoked(ctx context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.revoked[id]
+	return ok, nil
+}
@@ -0,0 +1,73 @@
+// Package auth signs users in with an OpenID Connect provider, through
+// go-oidc and golang.org/x/oauth2, and issues them session tokens signed
+// with golang-jwt.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is wrapped by every error verifying a token
+var ErrInvalidToken = errors.New("invalid token")
+
+// clockSkew is how far the clocks of the server and the provider may
+// disagree when checking a token's times
+const clockSkew = time.Minute
+
+// profile are the standard OpenID Connect profile claims the server keeps
+type profile struct {
+	Email             string `json:"email,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+// sessionClaims are the claims of a session token. Its ID lets a signed
+// out session be revoked.
+type sessionClaims struct {
+	jwt.RegisteredClaims
+	profile
+}
+
+// loginClaims are the claims of the short-lived token that carries a
+// sign-in's state through the provider
+type loginClaims struct {
+	jwt.RegisteredClaims
+	Nonce    string `json:"nonce"`
+	State    string `json:"state"`
+	Redirect string `json:"redirect"`
+}
+
+// signToken signs claims with HMAC SHA-256
+func signToken(claims jwt.Claims, key []byte) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+// parseToken checks a token signed with signToken for an audience and its
+// times, and decodes it into claims. Tokens without an expiry are refused.
+func parseToken(token string, key []byte, audience string, claims jwt.Claims, now time.Time) error {
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return key, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return nil
+}
+
+// signedBySelf reports whether a token is signed the way the server signs
+// its own, rather than by the provider
+func signedBySelf(token string) (bool, error) {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return parsed.Method == jwt.SigningMethodHS256, nil
+}
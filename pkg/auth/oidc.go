@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// provider is an OpenID Connect provider. Its configuration is discovered
+// when first needed, so the server starts while the provider is
+// unreachable.
+type provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	mu                 sync.Mutex
+	oidc               *oidc.Provider
+	keys               oidc.KeySet
+	endSessionEndpoint string
+}
+
+// discover returns the provider, fetching its configuration the first
+// time. go-oidc checks that the issuer is the one configured, so another
+// provider's tokens cannot pass for it.
+func (p *provider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oidc != nil {
+		return p.oidc, nil
+	}
+
+	discovered, err := oidc.NewProvider(oidc.ClientContext(ctx, p.client), p.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OpenID provider: %w", err)
+	}
+	var extra struct {
+		JWKSURI            string `json:"jwks_uri"`
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := discovered.Claims(&extra); err != nil {
+		return nil, fmt.Errorf("failed to discover OpenID provider: %w", err)
+	}
+	if discovered.Endpoint().AuthURL == "" || discovered.Endpoint().TokenURL == "" || extra.JWKSURI == "" {
+		return nil, fmt.Errorf("OpenID provider configuration lacks authorization_endpoint, token_endpoint or jwks_uri")
+	}
+	// Keys are fetched in the background of the requests verifying tokens
+	p.keys = &keySet{oidc.NewRemoteKeySet(oidc.ClientContext(context.Background(), p.client), extra.JWKSURI)}
+	p.oidc, p.endSessionEndpoint = discovered, extra.EndSessionEndpoint
+	return p.oidc, nil
+}
+
+// oauth2Config is the provider's authorization code flow
+func (p *provider) oauth2Config(discovered *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.scopes,
+		Endpoint:     discovered.Endpoint(),
+	}
+}
+
+// authCodeURL is where users are sent to sign in
+func (p *provider) authCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	discovered, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.oauth2Config(discovered).AuthCodeURL(state, oidc.Nonce(nonce)), nil
+}
+
+// exchange trades an authorization code for the user's ID token and
+// verifies it
+func (p *provider) exchange(ctx context.Context, code string, now time.Time) (*oidc.IDToken, error) {
+	discovered, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.oauth2Config(discovered).Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode != "" {
+			return nil, fmt.Errorf("failed to redeem authorization code: %s: %s", retrieveErr.ErrorCode, retrieveErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no id_token")
+	}
+	return p.verify(ctx, rawIDToken, []string{p.clientID}, now)
+}
+
+// verify checks a token the provider signed: its signature, issuer,
+// audience and times
+func (p *provider) verify(ctx context.Context, rawToken string, audiences []string, now time.Time) (*oidc.IDToken, error) {
+	discovered, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	failure := &keyFailure{}
+	verifier := oidc.NewVerifier(p.issuer, keys, &oidc.Config{
+		// The audience is checked below against every accepted one
+		SkipClientIDCheck:    true,
+		SupportedSigningAlgs: supportedAlgorithms(discovered),
+		Now:                  func() time.Time { return now.Add(-clockSkew) },
+	})
+	token, err := verifier.Verify(context.WithValue(ctx, keyFailureKey{}, failure), rawToken)
+	if failure.err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenID provider keys: %w", failure.err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !slices.ContainsFunc(token.Audience, func(audience string) bool { return slices.Contains(audiences, audience) }) {
+		return nil, fmt.Errorf("%w: not meant for this server", ErrInvalidToken)
+	}
+	if token.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+	return token, nil
+}
+
+// supportedAlgorithms are the algorithms tokens may be signed with: those
+// the provider lists, or else every asymmetric one go-oidc verifies
+func supportedAlgorithms(discovered *oidc.Provider) []string {
+	var listed struct {
+		Algorithms []string `json:"id_token_signing_alg_values_supported"`
+	}
+	discovered.Claims(&listed)
+	if len(listed.Algorithms) > 0 {
+		return listed.Algorithms
+	}
+	return []string{oidc.RS256, oidc.RS384, oidc.RS512, oidc.ES256, oidc.ES384, oidc.ES512, oidc.PS256, oidc.PS384, oidc.PS512}
+}
+
+// keyFailureKey keys the keyFailure of a verification in its context
+type keyFailureKey struct{}
+
+// keyFailure records the provider's keys failing to download, which
+// go-oidc reports like a bad signature
+type keyFailure struct {
+	err error
+}
+
+// keySet is the provider's remote key set, recording download failures
+type keySet struct {
+	oidc.KeySet
+}
+
+func (k *keySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	payload, err := k.KeySet.VerifySignature(ctx, jwt)
+	var urlErr *url.Error
+	if failure, ok := ctx.Value(keyFailureKey{}).(*keyFailure); ok && errors.As(err, &urlErr) {
+		failure.err = urlErr
+	}
+	return payload, err
+}
+
+// logoutURL is the provider's end-session endpoint; empty when it has
+// none or cannot be reached
+func (p *provider) logoutURL(ctx context.Context) string {
+	if _, err := p.discover(ctx); err != nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endSessionEndpoint
+}
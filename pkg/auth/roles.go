@@ -0,0 +1,26 @@
+package auth
+
+// Roles of users, each allowed everything the roles before it are
+const (
+	// RoleViewer reads logs, stats, alerts and reports
+	RoleViewer = "viewer"
+	// RoleAnalyst also uploads logs, generates reports and manages alert
+	// rules
+	RoleAnalyst = "analyst"
+	// RoleAdmin also manages users, retention, ingestion sources and the
+	// server
+	RoleAdmin = "admin"
+)
+
+// roleLevels orders the roles, as the level column of the roles table does
+var roleLevels = map[string]int{RoleViewer: 1, RoleAnalyst: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is one of the roles
+func ValidRole(role string) bool {
+	return roleLevels[role] > 0
+}
+
+// RoleAllows reports whether a user with role may do what needs required
+func RoleAllows(role, required string) bool {
+	return ValidRole(role) && roleLevels[role] >= roleLevels[required]
+}
@@ -0,0 +1,49 @@
+// Package awsauth finds AWS credentials, through the AWS SDK, for S3
+// ingestion and report storage, KMS and RDS IAM database authentication.
+package awsauth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+)
+
+// Credentials are an access key, with a session token for temporary
+// credentials
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Config loads the SDK configuration for a region. Configured keys are
+// used when given; otherwise the SDK's default chain finds credentials in
+// the AWS_* environment variables, the shared config files or the role of
+// the instance or container.
+func Config(ctx context.Context, region string, creds Credentials) (aws.Config, error) {
+	options := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if creds.AccessKeyID != "" {
+		options = append(options, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// RDSAuthToken creates an IAM authentication token, used as the password
+// of user when connecting to the RDS database at endpoint (host:port).
+// Tokens are valid for 15 minutes.
+func RDSAuthToken(ctx context.Context, endpoint, region, user string, credentials aws.CredentialsProvider) (string, error) {
+	token, err := auth.BuildAuthToken(ctx, endpoint, region, user, credentials)
+	if err != nil {
+		return "", fmt.Errorf("failed to create RDS auth token: %w", err)
+	}
+	return token, nil
+}
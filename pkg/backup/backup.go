@@ -0,0 +1,424 @@
+// Package backup dumps the log entries and configuration objects of a
+// storage backend to a gzip-compressed NDJSON stream and restores them into
+// an empty backend. Backups go through the storage contract, so one taken
+// from one database type can be restored into another.
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+)
+
+// Format is the format of backups
+const Format = "ndjson.gz"
+
+// Version is the layout of the backups written. Backups of later versions
+// are not restored.
+const Version = 1
+
+// Tables of a backup
+const (
+	TableRetentionPolicies  = "retention_policies"
+	TableFeatureOverrides   = "feature_overrides"
+	TableLatencyBudgets     = "latency_budgets"
+	TableMaintenanceWindows = "maintenance_windows"
+	TableAlertRules         = "alert_rules"
+	TableConfigVersions     = "config_versions"
+	TableLogEntries         = "log_entries"
+)
+
+// Tables are written in this order. Configuration comes first, so it is
+// restored even if storing the entries fails, and alert rules come before
+// the versions naming them.
+var Tables = []string{
+	TableRetentionPolicies,
+	TableFeatureOverrides,
+	TableLatencyBudgets,
+	TableMaintenanceWindows,
+	TableAlertRules,
+	TableConfigVersions,
+	TableLogEntries,
+}
+
+// ErrNotEmpty is returned for a restore into a backend that already holds
+// entries or configuration, which the backup's would be mixed with
+var ErrNotEmpty = errors.New("database is not empty")
+
+// ErrInvalid is returned for a stream that is not a backup, or not all of
+// one
+var ErrInvalid = errors.New("invalid backup")
+
+// batchSize is how many entries are read or stored at once
+const batchSize = 1000
+
+// maxLineSize bounds a line of a backup, an entry with its raw log
+const maxLineSize = 16 << 20
+
+// Maintenance windows are listed by the range they overlap
+var (
+	allTimeStart = time.Unix(0, 0).UTC()
+	allTimeEnd   = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// Header is the first line of a backup
+type Header struct {
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	DatabaseType string    `json:"database_type"`
+}
+
+// Counts are the rows of each table written or restored
+type Counts map[string]int64
+
+func newCounts() Counts {
+	counts := make(Counts, len(Tables))
+	for _, table := range Tables {
+		counts[table] = 0
+	}
+	return counts
+}
+
+// line is a line of a backup: the header, a row of a table, or the
+// trailer counting the rows, which only a complete backup ends with
+type line struct {
+	Header *Header         `json:"header,omitempty"`
+	Table  string          `json:"table,omitempty"`
+	Row    json.RawMessage `json:"row,omitempty"`
+	Counts Counts          `json:"counts,omitempty"`
+}
+
+// Dump writes a backup of the store's log entries, retention policies,
+// feature overrides, latency budgets, maintenance windows, alert rules and
+// configuration versions to w. Alert history, the audit log and records
+// the server keeps for itself are left out. Entries are read a batch at a
+// time, so backups of any size are streamed.
+func Dump(ctx context.Context, store storage.Storage, w io.Writer, databaseType string) (Counts, error) {
+	gz := gzip.NewWriter(w)
+	d := &dumper{encoder: json.NewEncoder(gz), counts: newCounts()}
+
+	header := &Header{Version: Version, CreatedAt: time.Now().UTC(), DatabaseType: databaseType}
+	if err := d.encoder.Encode(line{Header: header}); err != nil {
+		return d.counts, err
+	}
+
+	policies, err := store.GetRetentionPolicies()
+	if err == nil {
+		err = writeRows(d, TableRetentionPolicies, policies)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+	overrides, err := store.GetFeatureOverrides()
+	if err == nil {
+		err = writeRows(d, TableFeatureOverrides, overrides)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+	budgets, err := store.GetLatencyBudgets()
+	if err == nil {
+		err = writeRows(d, TableLatencyBudgets, budgets)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+	windows, err := store.GetMaintenanceWindows(allTimeStart, allTimeEnd)
+	if err == nil {
+		err = writeRows(d, TableMaintenanceWindows, windows)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+	rules, err := store.GetAlertRules(false)
+	if err == nil {
+		err = writeRows(d, TableAlertRules, rules)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+	// Versions are listed most recent first and restored in the order
+	// written, which numbers them again as they were
+	versions, err := store.GetConfigVersions("", "", math.MaxInt32)
+	if err == nil {
+		slices.Reverse(versions)
+		err = writeRows(d, TableConfigVersions, versions)
+	}
+	if err != nil {
+		return d.counts, err
+	}
+
+	var afterID int64
+	for {
+		entries, err := store.ScanAfter(ctx, afterID, batchSize)
+		if err != nil {
+			return d.counts, fmt.Errorf("failed to read log entries: %w", err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		if err := writeRows(d, TableLogEntries, entries); err != nil {
+			return d.counts, err
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+
+	if err := d.encoder.Encode(line{Counts: d.counts}); err != nil {
+		return d.counts, err
+	}
+	return d.counts, gz.Close()
+}
+
+// dumper writes the lines of a backup, counting its rows
+type dumper struct {
+	encoder *json.Encoder
+	counts  Counts
+}
+
+func writeRows[T any](d *dumper, table string, rows []T) error {
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", table, err)
+		}
+		if err := d.encoder.Encode(line{Table: table, Row: data}); err != nil {
+			return err
+		}
+		d.counts[table]++
+	}
+	return nil
+}
+
+// CheckEmpty returns ErrNotEmpty if the store holds entries or any of the
+// configuration a backup restores
+func CheckEmpty(ctx context.Context, store storage.Storage) error {
+	entries, err := store.Count(ctx, &models.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to count log entries: %w", err)
+	}
+	if entries > 0 {
+		return fmt.Errorf("%w: it holds %d log entries", ErrNotEmpty, entries)
+	}
+
+	counts := make(map[string]int, len(Tables))
+	policies, err := store.GetRetentionPolicies()
+	counts[TableRetentionPolicies] = len(policies)
+	if err == nil {
+		var overrides []*models.FeatureOverride
+		overrides, err = store.GetFeatureOverrides()
+		counts[TableFeatureOverrides] = len(overrides)
+	}
+	if err == nil {
+		var budgets []*models.LatencyBudget
+		budgets, err = store.GetLatencyBudgets()
+		counts[TableLatencyBudgets] = len(budgets)
+	}
+	if err == nil {
+		var windows []*models.MaintenanceWindow
+		windows, err = store.GetMaintenanceWindows(allTimeStart, allTimeEnd)
+		counts[TableMaintenanceWindows] = len(windows)
+	}
+	if err == nil {
+		var rules []*models.AlertRule
+		rules, err = store.GetAlertRules(false)
+		counts[TableAlertRules] = len(rules)
+	}
+	if err == nil {
+		var versions []*models.ConfigVersion
+		versions, err = store.GetConfigVersions("", "", 1)
+		counts[TableConfigVersions] = len(versions)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	for _, table := range Tables {
+		if counts[table] > 0 {
+			return fmt.Errorf("%w: it holds %s", ErrNotEmpty, table)
+		}
+	}
+	return nil
+}
+
+// Restore stores the rows of a backup read from r into an empty store and
+// returns how many of each table it stored. Records get new IDs; the
+// versions of alert rules are moved to their rule's new ID. Entries are
+// stored a batch at a time as they are read, so a backup cut short or
+// failing part way leaves the rows before the failure stored, and the
+// counts say how many.
+func Restore(ctx context.Context, store storage.Storage, r io.Reader) (Counts, error) {
+	if err := CheckEmpty(ctx, store); err != nil {
+		return nil, err
+	}
+	restored := newCounts()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return restored, fmt.Errorf("%w: not gzip-compressed: %v", ErrInvalid, err)
+	}
+	defer gz.Close()
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	rs := &restorer{store: store, counts: restored, ruleIDs: make(map[int64]int64)}
+	var header *Header
+	var trailer Counts
+	for number := 1; scanner.Scan(); number++ {
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return restored, fmt.Errorf("%w: malformed line %d: %v", ErrInvalid, number, err)
+		}
+		switch {
+		case trailer != nil:
+			return restored, fmt.Errorf("%w: line %d follows the trailer", ErrInvalid, number)
+		case header == nil:
+			if l.Header == nil {
+				return restored, fmt.Errorf("%w: no header", ErrInvalid)
+			}
+			if l.Header.Version < 1 || l.Header.Version > Version {
+				return restored, fmt.Errorf("%w: unsupported version %d", ErrInvalid, l.Header.Version)
+			}
+			header = l.Header
+		case l.Counts != nil:
+			trailer = l.Counts
+		default:
+			if err := rs.restore(ctx, l.Table, l.Row); err != nil {
+				return restored, fmt.Errorf("failed to restore line %d of backup: %w", number, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return restored, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := rs.flush(ctx); err != nil {
+		return restored, err
+	}
+
+	if trailer == nil {
+		return restored, fmt.Errorf("%w: it is incomplete", ErrInvalid)
+	}
+	for _, table := range Tables {
+		if trailer[table] != restored[table] {
+			return restored, fmt.Errorf("%w: it holds %d %s but %d were read", ErrInvalid, trailer[table], table, restored[table])
+		}
+	}
+	return restored, nil
+}
+
+// restorer stores the rows of a backup, holding entries back until there
+// is a batch of them
+type restorer struct {
+	store   storage.Storage
+	counts  Counts
+	entries []*models.LogEntry
+	// ruleIDs maps the IDs of alert rules in the backup to their new IDs
+	ruleIDs map[int64]int64
+}
+
+func (rs *restorer) restore(ctx context.Context, table string, row json.RawMessage) error {
+	var err error
+	switch table {
+	case TableRetentionPolicies:
+		var policy models.RetentionPolicy
+		if err = decode(row, &policy); err == nil {
+			err = rs.store.SetRetentionPolicy(&policy)
+		}
+	case TableFeatureOverrides:
+		var override models.FeatureOverride
+		if err = decode(row, &override); err == nil {
+			err = rs.store.SetFeatureOverride(&override)
+		}
+	case TableLatencyBudgets:
+		var budget models.LatencyBudget
+		if err = decode(row, &budget); err == nil {
+			budget.ID = 0
+			err = rs.store.CreateLatencyBudget(&budget)
+		}
+	case TableMaintenanceWindows:
+		var window models.MaintenanceWindow
+		if err = decode(row, &window); err == nil {
+			window.ID = 0
+			err = rs.store.CreateMaintenanceWindow(&window)
+		}
+	case TableAlertRules:
+		var rule models.AlertRule
+		if err = decode(row, &rule); err == nil {
+			id := rule.ID
+			rule.ID = 0
+			if err = rs.store.CreateAlertRule(&rule); err == nil {
+				rs.ruleIDs[id] = rule.ID
+			}
+		}
+	case TableConfigVersions:
+		var version models.ConfigVersion
+		if err = decode(row, &version); err == nil {
+			rs.renumber(&version)
+			err = rs.store.AppendConfigVersion(&version)
+		}
+	case TableLogEntries:
+		var entry models.LogEntry
+		if err := decode(row, &entry); err != nil {
+			return err
+		}
+		entry.ID = 0
+		rs.entries = append(rs.entries, &entry)
+		if len(rs.entries) >= batchSize {
+			return rs.flush(ctx)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown table %q", ErrInvalid, table)
+	}
+	if err != nil {
+		return err
+	}
+	rs.counts[table]++
+	return nil
+}
+
+// decode reads a row, which the backup's header promised the layout of
+func decode(row json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(row, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return nil
+}
+
+// renumber moves the version of an alert rule to the rule's new ID
+func (rs *restorer) renumber(version *models.ConfigVersion) {
+	version.ID = 0
+	if version.Kind != models.ConfigKindAlertRule {
+		return
+	}
+	id, err := strconv.ParseInt(version.ObjectID, 10, 64)
+	if err != nil {
+		return
+	}
+	if newID, ok := rs.ruleIDs[id]; ok {
+		version.ObjectID = strconv.FormatInt(newID, 10)
+	}
+}
+
+// flush stores the entries held back
+func (rs *restorer) flush(ctx context.Context) error {
+	if len(rs.entries) == 0 {
+		return nil
+	}
+	if err := rs.store.InsertBatch(ctx, rs.entries); err != nil {
+		return fmt.Errorf("failed to store log entries: %w", err)
+	}
+	rs.counts[TableLogEntries] += int64(len(rs.entries))
+	rs.entries = nil
+	return nil
+}
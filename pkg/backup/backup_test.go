@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// populate stores an entry more than a batch holds and a record of each
+// configuration table
+func populate(t *testing.T, store *memory.Store) {
+	entries := make([]*models.LogEntry, batchSize+5)
+	for i := range entries {
+		entries[i] = &models.LogEntry{
+			Timestamp:  base.Add(time.Duration(i) * time.Second),
+			LogType:    "nginx",
+			SourceIP:   "192.0.2.1",
+			Path:       fmt.Sprintf("/page/%d", i),
+			StatusCode: 200,
+			RawLog:     "GET /",
+			Metadata:   models.LogMetadata{"country": "DE"},
+		}
+	}
+	require.NoError(t, store.InsertLogEntries(entries))
+
+	require.NoError(t, store.SetRetentionPolicy(&models.RetentionPolicy{LogType: "nginx", Days: 30}))
+	require.NoError(t, store.SetFeatureOverride(&models.FeatureOverride{Flag: "geoip", Enabled: true}))
+	require.NoError(t, store.CreateLatencyBudget(&models.LatencyBudget{Path: "/api", Percentile: 99, ThresholdMs: 250}))
+	require.NoError(t, store.CreateMaintenanceWindow(&models.MaintenanceWindow{
+		Name: "upgrade", StartsAt: base, EndsAt: base.Add(time.Hour)}))
+
+	// A rule whose ID differs from the one it gets when restored
+	require.NoError(t, store.CreateAlertRule(&models.AlertRule{Name: "removed", ConditionType: "error_rate"}))
+	rule := &models.AlertRule{Name: "errors", ConditionType: "error_rate", ThresholdValue: 5, IsActive: true}
+	require.NoError(t, store.CreateAlertRule(rule))
+	objectID := fmt.Sprint(rule.ID)
+	for _, threshold := range []string{`{"threshold_value":1}`, `{"threshold_value":5}`} {
+		require.NoError(t, store.AppendConfigVersion(&models.ConfigVersion{
+			Kind: models.ConfigKindAlertRule, ObjectID: objectID, Action: "update", State: json.RawMessage(threshold)}))
+	}
+}
+
+func TestDumpAndRestore(t *testing.T) {
+	ctx := context.Background()
+	source := memory.New()
+	populate(t, source)
+
+	var buf bytes.Buffer
+	dumped, err := Dump(ctx, source, &buf, "memory")
+	require.NoError(t, err)
+	assert.Equal(t, Counts{
+		TableRetentionPolicies:  1,
+		TableFeatureOverrides:   1,
+		TableLatencyBudgets:     1,
+		TableMaintenanceWindows: 1,
+		TableAlertRules:         2,
+		TableConfigVersions:     2,
+		TableLogEntries:         batchSize + 5,
+	}, dumped)
+
+	target := memory.New()
+	// Take the first ID, so restored rules are numbered differently
+	require.NoError(t, target.CreateLatencyBudget(&models.LatencyBudget{Path: "/"}))
+	_, err = target.DeleteLatencyBudget(1)
+	require.NoError(t, err)
+
+	restored, err := Restore(ctx, target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, dumped, restored)
+
+	count, err := target.Count(ctx, &models.LogFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(batchSize+5), count)
+	entries, err := target.ScanAfter(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "/page/0", entries[0].Path)
+	assert.Equal(t, "DE", entries[0].Metadata["country"])
+
+	rules, err := target.GetAlertRules(true)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "errors", rules[0].Name)
+
+	// The versions follow the rule to its new ID, numbered as before
+	versions, err := target.GetConfigVersions(models.ConfigKindAlertRule, fmt.Sprint(rules[0].ID), 10)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.JSONEq(t, `{"threshold_value":5}`, string(versions[0].State))
+
+	policies, err := target.GetRetentionPolicies()
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, 30, policies[0].Days)
+}
+
+func TestRestoreRefusesDatabaseInUse(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	_, err := Dump(ctx, memory.New(), &buf, "memory")
+	require.NoError(t, err)
+
+	target := memory.New()
+	require.NoError(t, target.SetFeatureOverride(&models.FeatureOverride{Flag: "geoip"}))
+	_, err = Restore(ctx, target, bytes.NewReader(buf.Bytes()))
+	assert.ErrorIs(t, err, ErrNotEmpty)
+	assert.ErrorContains(t, err, TableFeatureOverrides)
+}
+
+func TestRestoreDetectsIncompleteBackup(t *testing.T) {
+	ctx := context.Background()
+	source := memory.New()
+	populate(t, source)
+	var buf bytes.Buffer
+	_, err := Dump(ctx, source, &buf, "memory")
+	require.NoError(t, err)
+
+	// A dump that failed part way has no trailer
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	lines, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	lines = bytes.TrimSuffix(lines, []byte("\n"))
+	lines = lines[:bytes.LastIndexByte(lines, '\n')+1]
+	var cut bytes.Buffer
+	w := gzip.NewWriter(&cut)
+	w.Write(lines)
+	require.NoError(t, w.Close())
+
+	target := memory.New()
+	restored, err := Restore(ctx, target, &cut)
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorContains(t, err, "incomplete")
+	assert.Equal(t, int64(batchSize+5), restored[TableLogEntries], "the rows read are stored")
+
+	_, err = Restore(ctx, memory.New(), bytes.NewReader([]byte("not a backup")))
+	assert.ErrorContains(t, err, "gzip")
+}
@@ -0,0 +1,164 @@
+// Package cache keeps short-lived values, such as stats results, counters
+// and sessions, in process or in Redis. With Redis every replica of the
+// server sees the same values; while Redis is unreachable an in-process
+// cache stands in for it.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+)
+
+// Cache holds values by key until their time to live has passed. A zero
+// ttl keeps a value until it is deleted.
+type Cache interface {
+	// Get returns a value and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a value, replacing any with the same key
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes a value. Missing keys are not an error.
+	Delete(ctx context.Context, key string) error
+	// Incr adds one to a counter and returns its new value. A new counter
+	// expires after ttl, however often it is incremented, so counters
+	// count within fixed windows.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Close releases the cache's connections
+	Close() error
+}
+
+// New creates the cache the configuration selects. A Redis cache falls
+// back to an in-process cache while Redis fails, calling onChange with the
+// error when it does and with nil once Redis works again.
+func New(cfg config.CacheConfig, onChange func(err error)) (Cache, error) {
+	switch cfg.Type {
+	case "", "memory":
+		return NewMemory(), nil
+	case "redis":
+		redis := NewRedis(RedisOptions{
+			Address:   cfg.Redis.Address,
+			Username:  cfg.Redis.Username,
+			Password:  cfg.Redis.Password,
+			DB:        cfg.Redis.DB,
+			TLS:       cfg.Redis.TLS,
+			KeyPrefix: cfg.Redis.KeyPrefix,
+			Timeout:   time.Duration(cfg.Redis.Timeout) * time.Millisecond,
+			PoolSize:  cfg.Redis.PoolSize,
+		})
+		return NewFallback(redis, NewMemory(), time.Duration(cfg.Redis.RetryInterval)*time.Second, onChange), nil
+	default:
+		return nil, fmt.Errorf("unknown cache type %q", cfg.Type)
+	}
+}
+
+// Key joins parts into a key, such as Key("stats", "methods", "nginx")
+// for "stats:methods:nginx"
+func Key(parts ...string) string {
+	return strings.Join(parts, ":")
+}
+
+// Memory is an in-process cache
+type Memory struct {
+	mu        sync.Mutex
+	items     map[string]memoryItem
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryItem struct {
+	value   []byte
+	counter int64
+	expires time.Time // zero for no expiry
+}
+
+// sweepInterval is how often expired items are removed from a Memory
+// cache, besides when they are read
+const sweepInterval = time.Minute
+
+// NewMemory creates an empty in-process cache
+func NewMemory() *Memory {
+	return &Memory{items: make(map[string]memoryItem), now: time.Now}
+}
+
+// item returns a live item. The caller holds mu.
+func (m *Memory) item(key string, now time.Time) (memoryItem, bool) {
+	item, ok := m.items[key]
+	if ok && !item.expires.IsZero() && !now.Before(item.expires) {
+		delete(m.items, key)
+		return memoryItem{}, false
+	}
+	return item, ok
+}
+
+// sweep removes expired items once per sweepInterval. The caller holds mu.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, item := range m.items {
+		if !item.expires.IsZero() && !now.Before(item.expires) {
+			delete(m.items, key)
+		}
+	}
+}
+
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.item(key, m.now())
+	if !ok {
+		return nil, false, nil
+	}
+	if item.value == nil {
+		return []byte(fmt.Sprint(item.counter)), true, nil
+	}
+	return append([]byte(nil), item.value...), true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.sweep(now)
+	m.items[key] = memoryItem{value: append([]byte{}, value...), expires: expiry(now, ttl)}
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.sweep(now)
+	item, ok := m.item(key, now)
+	if !ok {
+		item = memoryItem{expires: expiry(now, ttl)}
+	} else if item.value != nil {
+		return 0, fmt.Errorf("value of %s is not a counter", key)
+	}
+	item.counter++
+	m.items[key] = item
+	return item.counter, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
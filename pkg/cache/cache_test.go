@@ -0,0 +1,382 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+	value, ok, err := m.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	now = now.Add(time.Minute)
+	_, ok, _ = m.Get(ctx, "a")
+	assert.False(t, ok, "values expire after their ttl")
+	_, ok, _ = m.Get(ctx, "b")
+	assert.True(t, ok, "values without a ttl are kept")
+
+	require.NoError(t, m.Delete(ctx, "b"))
+	_, ok, _ = m.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.NoError(t, m.Delete(ctx, "missing"))
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := m.Incr(ctx, "hits", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+		now = now.Add(10 * time.Second)
+	}
+	value, _, _ = m.Get(ctx, "hits")
+	assert.Equal(t, "3", string(value))
+	now = now.Add(30 * time.Second)
+	n, _ := m.Incr(ctx, "hits", time.Minute)
+	assert.Equal(t, int64(1), n, "counters start again once their window ends")
+
+	m.Set(ctx, "text", []byte("x"), 0)
+	_, err = m.Incr(ctx, "text", 0)
+	assert.Error(t, err)
+
+	m.Set(ctx, "old", []byte("x"), time.Second)
+	now = now.Add(2 * sweepInterval)
+	m.Set(ctx, "new", []byte("x"), 0)
+	assert.NotContains(t, m.items, "old", "expired values are swept")
+}
+
+// flaky is a cache that fails while down
+type flaky struct {
+	*Memory
+	down bool
+}
+
+var errDown = errors.New("connection refused")
+
+func (f *flaky) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if f.down {
+		return nil, false, errDown
+	}
+	return f.Memory.Get(ctx, key)
+}
+
+func (f *flaky) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.down {
+		return errDown
+	}
+	return f.Memory.Set(ctx, key, value, ttl)
+}
+
+func (f *flaky) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if f.down {
+		return 0, errDown
+	}
+	return f.Memory.Incr(ctx, key, ttl)
+}
+
+// Take allows burst requests per key, never refilling
+func (f *flaky) Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	if f.down {
+		return false, 0, errDown
+	}
+	n, _ := f.Memory.Incr(ctx, key, 0)
+	return n <= int64(burst), 0, nil
+}
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := &flaky{Memory: NewMemory()}
+	secondary := NewMemory()
+	var changes []error
+	f := NewFallback(primary, secondary, time.Minute, func(err error) { changes = append(changes, err) })
+	f.now = func() time.Time { return now }
+
+	require.NoError(t, f.Set(ctx, "k", []byte("primary"), 0))
+	value, _, _ := primary.Memory.Get(ctx, "k")
+	assert.Equal(t, "primary", string(value))
+	assert.Empty(t, changes)
+
+	primary.down = true
+	require.NoError(t, f.Set(ctx, "k", []byte("secondary"), 0), "failures are not returned")
+	value, ok, err := f.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "secondary", string(value))
+	assert.Equal(t, []error{errDown}, changes)
+	assert.ErrorIs(t, f.Err(), errDown)
+
+	primary.down = false
+	value, _, _ = f.Get(ctx, "k")
+	assert.Equal(t, "secondary", string(value), "the primary is not retried before the retry interval")
+
+	now = now.Add(time.Minute)
+	value, _, _ = f.Get(ctx, "k")
+	assert.Equal(t, "primary", string(value))
+	assert.Equal(t, []error{errDown, nil}, changes)
+	assert.NoError(t, f.Err())
+
+	primary.down = true
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = f.Get(cancelled, "k")
+	assert.ErrorIs(t, err, errDown, "calls cut short by their context do not fall back")
+	assert.NoError(t, f.Err())
+}
+
+func TestTakePrimary(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := &flaky{Memory: NewMemory()}
+	f := NewFallback(primary, NewMemory(), time.Minute, nil)
+	f.now = func() time.Time { return now }
+
+	ok, _, err := f.TakePrimary(ctx, "b", 1, 1, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	primary.down = true
+	_, _, err = f.TakePrimary(ctx, "b", 1, 1, now)
+	assert.ErrorIs(t, err, errDown)
+	primary.down = false
+	_, _, err = f.TakePrimary(ctx, "b", 1, 1, now)
+	assert.ErrorIs(t, err, errDown, "the primary is not retried before the retry interval")
+
+	now = now.Add(time.Minute)
+	ok, _, err = f.TakePrimary(ctx, "b", 1, 1, now)
+	require.NoError(t, err)
+	assert.False(t, ok, "the bucket the primary keeps is empty")
+
+	_, _, err = NewFallback(NewMemory(), NewMemory(), time.Minute, nil).TakePrimary(ctx, "b", 1, 1, now)
+	assert.Error(t, err, "a primary without buckets cannot take tokens")
+}
+
+// fakeRedis serves the commands the cache sends from a map
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	buckets  map[string][2]float64 // tokens and when they were counted, in ms
+	expires  map[string]time.Duration
+	password string
+	commands []string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	fake := &fakeRedis{data: map[string]string{}, buckets: map[string][2]float64{}, expires: map[string]time.Duration{}, password: password}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fake.serve(conn)
+		}
+	}()
+	return fake, listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		args[0] = strings.ToUpper(args[0])
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args[0])
+		var out string
+		switch {
+		case args[0] == "HELLO":
+			out = "-ERR unknown command 'HELLO'\r\n"
+		case args[0] == "AUTH":
+			authed = args[len(args)-1] == f.password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "PING":
+			out = "+PONG\r\n"
+		case args[0] == "SELECT":
+			out = "+OK\r\n"
+		case args[0] == "GET":
+			if value, ok := f.data[args[1]]; ok {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				out = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				n, _ := strconv.Atoi(args[4])
+				unit := time.Second
+				if strings.EqualFold(args[3], "px") {
+					unit = time.Millisecond
+				}
+				f.expires[args[1]] = time.Duration(n) * unit
+			}
+			out = "+OK\r\n"
+		case args[0] == "DEL":
+			delete(f.data, args[1])
+			out = ":1\r\n"
+		case args[0] == "EVALSHA":
+			out = "-NOSCRIPT No matching script.\r\n"
+		case args[0] == "EVAL" && strings.Contains(args[1], "HMGET"):
+			out = fmt.Sprintf(":%d\r\n", f.take(args[3], args[4:]))
+		case args[0] == "EVAL":
+			n, _ := strconv.Atoi(f.data[args[3]])
+			n++
+			f.data[args[3]] = strconv.Itoa(n)
+			if ms, _ := strconv.Atoi(args[4]); n == 1 && ms > 0 {
+				f.expires[args[3]] = time.Duration(ms) * time.Millisecond
+			}
+			out = fmt.Sprintf(":%d\r\n", n)
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		conn.Write([]byte(out))
+	}
+}
+
+// take runs the token bucket script on a bucket
+func (f *fakeRedis) take(key string, args []string) int64 {
+	rate, _ := strconv.ParseFloat(args[0], 64)
+	burst, _ := strconv.ParseFloat(args[1], 64)
+	now, _ := strconv.ParseFloat(args[2], 64)
+	bucket, ok := f.buckets[key]
+	if !ok {
+		bucket = [2]float64{burst, now}
+	}
+	if now > bucket[1] {
+		bucket = [2]float64{math.Min(burst, bucket[0]+(now-bucket[1])*rate/1000), now}
+	}
+	var wait int64
+	if bucket[0] < 1 {
+		wait = int64(math.Ceil((1 - bucket[0]) * 1000 / rate))
+	} else {
+		bucket[0]--
+	}
+	f.buckets[key] = bucket
+	f.expires[key] = time.Duration(math.Ceil(burst*1000/rate)) * time.Millisecond
+	return wait
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	length := func(prefix byte) (int, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		if line[0] != prefix {
+			return 0, fmt.Errorf("unexpected %q", line)
+		}
+		return strconv.Atoi(strings.TrimSpace(line[1:]))
+	}
+	n, err := length('*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		size, err := length('$')
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	fake, addr := startFakeRedis(t, "secret")
+	c := NewRedis(RedisOptions{Address: addr, Password: "secret", DB: 2, KeyPrefix: "la:"})
+	defer c.Close()
+
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.Set(ctx, "stats", []byte("line one\r\nline two"), 1500*time.Millisecond))
+	value, ok, err := c.Get(ctx, "stats")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "line one\r\nline two", string(value))
+	assert.Equal(t, 1500*time.Millisecond, fake.expires["la:stats"])
+
+	_, ok, err = c.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for want := int64(1); want <= 2; want++ {
+		n, err := c.Incr(ctx, "hits", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	assert.Equal(t, time.Minute, fake.expires["la:hits"])
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		ok, _, err := c.Take(ctx, "bucket", 4, 2, now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, wait, err := c.Take(ctx, "bucket", 4, 2, now.Add(100*time.Millisecond))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 150*time.Millisecond, wait, "until the bucket has refilled a token")
+	assert.Equal(t, 500*time.Millisecond, fake.expires["la:bucket"], "the bucket expires once it would be full")
+	ok, _, _ = c.Take(ctx, "bucket", 4, 2, now.Add(250*time.Millisecond))
+	assert.True(t, ok)
+
+	require.NoError(t, c.Delete(ctx, "stats"))
+	_, ok, _ = c.Get(ctx, "stats")
+	assert.False(t, ok)
+
+	fake.mu.Lock()
+	commands := strings.Join(fake.commands, " ")
+	assert.Regexp(t, `^HELLO .*AUTH SELECT .*PING`, commands, "the connection authenticates and selects the database first")
+	assert.Equal(t, 1, strings.Count(commands, "AUTH"), "the connection is reused")
+	fake.mu.Unlock()
+
+	wrong := NewRedis(RedisOptions{Address: addr, Password: "wrong"})
+	var redisErr redis.Error
+	assert.True(t, errors.As(wrong.Ping(ctx), &redisErr))
+	wrong.Close()
+
+	unreachable := NewRedis(RedisOptions{Address: "127.0.0.1:1", Timeout: 100 * time.Millisecond})
+	assert.Error(t, unreachable.Ping(ctx))
+}
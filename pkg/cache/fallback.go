@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Fallback uses a primary cache, such as Redis, and a secondary one while
+// the primary fails. After a failure the primary is tried again once
+// retry has passed. Values written while on the secondary are not copied
+// back.
+type Fallback struct {
+	primary   Cache
+	secondary Cache
+	retry     time.Duration
+	onChange  func(err error)
+
+	mu       sync.Mutex
+	failedAt time.Time // zero while the primary works
+	lastErr  error
+	now      func() time.Time
+}
+
+// NewFallback creates a cache that falls back to secondary. onChange, when
+// set, is called with the primary's error when falling back and with nil
+// when the primary works again.
+func NewFallback(primary, secondary Cache, retry time.Duration, onChange func(err error)) *Fallback {
+	if retry <= 0 {
+		retry = 30 * time.Second
+	}
+	return &Fallback{primary: primary, secondary: secondary, retry: retry, onChange: onChange, now: time.Now}
+}
+
+// Err returns the primary's last error while falling back, or nil
+func (f *Fallback) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastErr
+}
+
+// usePrimary reports whether to try the primary
+func (f *Fallback) usePrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedAt.IsZero() || f.now().Sub(f.failedAt) >= f.retry
+}
+
+// failed records the outcome of a call to the primary, returning whether
+// to fall back. Calls cut short by their context are not failures.
+func (f *Fallback) failed(ctx context.Context, err error) bool {
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
+
+	f.mu.Lock()
+	failing := !f.failedAt.IsZero()
+	if err != nil {
+		f.failedAt = f.now()
+		f.lastErr = err
+	} else {
+		f.failedAt = time.Time{}
+		f.lastErr = nil
+	}
+	f.mu.Unlock()
+
+	if f.onChange != nil && failing != (err != nil) {
+		f.onChange(err)
+	}
+	return err != nil
+}
+
+func (f *Fallback) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if f.usePrimary() {
+		value, ok, err := f.primary.Get(ctx, key)
+		if !f.failed(ctx, err) {
+			return value, ok, err
+		}
+	}
+	return f.secondary.Get(ctx, key)
+}
+
+func (f *Fallback) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.usePrimary() {
+		if err := f.primary.Set(ctx, key, value, ttl); !f.failed(ctx, err) {
+			return err
+		}
+	}
+	return f.secondary.Set(ctx, key, value, ttl)
+}
+
+func (f *Fallback) Delete(ctx context.Context, key string) error {
+	// Delete from both, so a value set while falling back is not served
+	// after the next failure
+	secondaryErr := f.secondary.Delete(ctx, key)
+	if f.usePrimary() {
+		if err := f.primary.Delete(ctx, key); !f.failed(ctx, err) {
+			return err
+		}
+	}
+	return secondaryErr
+}
+
+func (f *Fallback) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if f.usePrimary() {
+		n, err := f.primary.Incr(ctx, key, ttl)
+		if !f.failed(ctx, err) {
+			return n, err
+		}
+	}
+	return f.secondary.Incr(ctx, key, ttl)
+}
+
+// buckets is a cache that keeps token buckets, such as Redis
+type buckets interface {
+	Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error)
+}
+
+// TakePrimary takes a token from a token bucket kept by the primary, for
+// rate limits every replica shares. While falling back it returns the
+// primary's error, as buckets of this process's own are of no use to
+// other replicas.
+func (f *Fallback) TakePrimary(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	primary, ok := f.primary.(buckets)
+	if !ok {
+		return false, 0, errors.New("the primary cache keeps no token buckets")
+	}
+	if !f.usePrimary() {
+		return false, 0, f.Err()
+	}
+	ok, wait, err := primary.Take(ctx, key, rate, burst, now)
+	f.failed(ctx, err)
+	return ok, wait, err
+}
+
+func (f *Fallback) Close() error {
+	err := f.primary.Close()
+	if secondaryErr := f.secondary.Close(); err == nil {
+		err = secondaryErr
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions configures a Redis cache
+type RedisOptions struct {
+	Address   string // host:port
+	Username  string // ACL user, Redis 6 and later
+	Password  string
+	DB        int
+	TLS       bool
+	KeyPrefix string        // prepended to every key
+	Timeout   time.Duration // for connecting and for each command, default 1s
+	PoolSize  int           // connections kept, default 10
+}
+
+// incrScript increments a counter and sets its expiry when it is created,
+// in one step so a counter cannot be left without one
+var incrScript = redis.NewScript(`local n = redis.call('INCR', KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+return n`)
+
+// takeScript takes a token from a token bucket kept in a hash, refilling
+// it for the time since it was last updated. It returns 0 when there was
+// a token and otherwise the milliseconds until there is one. A bucket
+// expires once it would have refilled, as a missing bucket is a full one.
+var takeScript = redis.NewScript(`local rate, burst, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens, updated = tonumber(bucket[1]) or burst, tonumber(bucket[2]) or now
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate / 1000)
+  updated = now
+end
+local wait = 0
+if tokens < 1 then
+  wait = math.ceil((1 - tokens) * 1000 / rate)
+else
+  tokens = tokens - 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(updated))
+redis.call('PEXPIRE', KEYS[1], math.max(math.ceil(burst * 1000 / rate), 1))
+return wait`)
+
+// Redis is a cache on a Redis server, through a go-redis client. Error
+// replies from the server satisfy redis.Error.
+type Redis struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedis creates a Redis cache. Connections are made when first needed.
+func NewRedis(opts RedisOptions) *Redis {
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	options := &redis.Options{
+		Addr:                  opts.Address,
+		Username:              opts.Username,
+		Password:              opts.Password,
+		DB:                    opts.DB,
+		DialTimeout:           opts.Timeout,
+		ReadTimeout:           opts.Timeout,
+		WriteTimeout:          opts.Timeout,
+		ContextTimeoutEnabled: true,
+		PoolSize:              opts.PoolSize,
+	}
+	if opts.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &Redis{client: redis.NewClient(options), keyPrefix: opts.KeyPrefix}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.keyPrefix+key, value, max(ttl, 0)).Err()
+}
+
+func (c *Redis) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.keyPrefix+key).Err()
+}
+
+func (c *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var ms int64
+	if ttl > 0 {
+		ms = max(ttl.Milliseconds(), 1)
+	}
+	return incrScript.Run(ctx, c.client, []string{c.keyPrefix + key}, ms).Int64()
+}
+
+// Take takes a token from the token bucket under key, which holds up to
+// burst tokens and refills at rate tokens per second as of now. It
+// returns whether there was a token and otherwise how long until there is.
+func (c *Redis) Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	wait, err := takeScript.Run(ctx, c.client, []string{c.keyPrefix + key}, rate, burst, now.UnixMilli()).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	return wait == 0, time.Duration(wait) * time.Millisecond, nil
+}
+
+// Ping checks that Redis can be reached
+func (c *Redis) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/viper"
+)
+
+// AgentConfig configures the collection agent, which tails log files on a
+// host and ships their lines to a server
+type AgentConfig struct {
+	Server string `mapstructure:"server"` // base URL, e.g. http://loganalyzer:8080
+	// Labels are added to the metadata of every line shipped; host
+	// defaults to the hostname
+	Labels        map[string]string `mapstructure:"labels"`
+	Watch         []WatchConfig     `mapstructure:"watch"`
+	OffsetsFile   string            `mapstructure:"offsets_file"`  // the cursor, kept across restarts
+	PollInterval  int               `mapstructure:"poll_interval"` // seconds
+	FromBeginning bool              `mapstructure:"from_beginning"`
+	BatchSize     int               `mapstructure:"batch_size"`     // lines per request
+	MaxBatchSize  int               `mapstructure:"max_batch_size"` // KB of lines per request, before compression
+	Timeout       int               `mapstructure:"timeout"`        // seconds per request
+	MinBackoff    int               `mapstructure:"min_backoff"`    // seconds before the first retry
+	MaxBackoff    int               `mapstructure:"max_backoff"`    // most seconds between retries
+}
+
+// LoadAgent reads the agent's config file
+func LoadAgent(configPath string) (*AgentConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.AutomaticEnv()
+
+	v.SetDefault("offsets_file", "data/agent_offsets.json")
+	v.SetDefault("poll_interval", 1)
+	v.SetDefault("batch_size", 1000)
+	v.SetDefault("max_batch_size", 1024)
+	v.SetDefault("timeout", 30)
+	v.SetDefault("min_backoff", 1)
+	v.SetDefault("max_backoff", 60)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var config AgentConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config: %w", err)
+	}
+	if err := validateAgentConfig(&config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return &config, nil
+}
+
+func validateAgentConfig(config *AgentConfig) error {
+	if u, err := url.Parse(config.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("server must be an http or https URL")
+	}
+	if len(config.Watch) == 0 {
+		return fmt.Errorf("at least one watch directory is required")
+	}
+	for _, watch := range config.Watch {
+		if watch.Path == "" || watch.LogType == "" {
+			return fmt.Errorf("watch directories require path and log_type")
+		}
+	}
+	if config.PollInterval < 1 || config.Timeout < 1 {
+		return fmt.Errorf("poll_interval and timeout must be at least 1 second")
+	}
+	if config.BatchSize < 1 || config.MaxBatchSize < 1 {
+		return fmt.Errorf("batch_size and max_batch_size must be at least 1")
+	}
+	if config.MinBackoff < 1 || config.MaxBackoff < config.MinBackoff {
+		return fmt.Errorf("backoff requires 1 <= min_backoff <= max_backoff")
+	}
+	return nil
+}
@@ -2,14 +2,41 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Logging    LoggingConfig    `mapstructure:"logging"`
+	Alerting   AlertingConfig   `mapstructure:"alerting"`
+	Processing ProcessingConfig `mapstructure:"processing"`
+	Forwarding ForwardingConfig `mapstructure:"forwarding"`
+	Ingest     IngestConfig     `mapstructure:"ingest"`
+	Retention  RetentionConfig  `mapstructure:"retention"`
+	Archive    ArchiveConfig    `mapstructure:"archive"`
+	Rollups    RollupsConfig    `mapstructure:"rollups"`
+	Compliance ComplianceConfig `mapstructure:"compliance"`
+	Integrity  IntegrityConfig  `mapstructure:"integrity"`
+	Features   FeaturesConfig   `mapstructure:"features"`
+	Reports    ReportsConfig    `mapstructure:"reports"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	GraphQL    GraphQLConfig    `mapstructure:"graphql"`
+	GRPC       GRPCConfig       `mapstructure:"grpc"`
+	Tail       TailConfig       `mapstructure:"tail"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
+	Plugins    []PluginConfig   `mapstructure:"plugins"`
+
+	// Env is the profile merged over the base file, Sources the files read
+	Env     string   `mapstructure:"-"`
+	Sources []string `mapstructure:"-"`
+	// settings are the effective values by key, for Effective
+	settings map[string]interface{}
 }
 
 type ServerConfig struct {
@@ -17,16 +44,114 @@ type ServerConfig struct {
 	Host         string `mapstructure:"host"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	PublicURL    string `mapstructure:"public_url"` // base URL used in links sent to users
 }
 
 type DatabaseConfig struct {
-	Type     string `mapstructure:"type"` // mysql or postgres
+	Type     string `mapstructure:"type"` // mysql, postgres, sqlite or a registered storage backend such as memory
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
-	Database string `mapstructure:"database"`
-	SSLMode  string `mapstructure:"ssl_mode"`
+	Database string `mapstructure:"database"` // the database name, or the file on sqlite
+	// SSLMode is disable, require, verify-ca or verify-full; postgres also
+	// accepts allow and prefer
+	SSLMode string `mapstructure:"ssl_mode"`
+	// DSN replaces the connection string built from the fields above, such
+	// as for Cloud SQL unix sockets. Params, TLS and IAMAuth still apply.
+	DSN string `mapstructure:"dsn"`
+	// Params are extra driver parameters as a query string, e.g.
+	// "connect_timeout=10&application_name=log-analyzer"
+	Params  string            `mapstructure:"params"`
+	TLS     DatabaseTLSConfig `mapstructure:"tls"`
+	IAMAuth DatabaseIAMConfig `mapstructure:"iam_auth"`
+	// Timescale stores log entries in a TimescaleDB hypertable on postgres
+	Timescale TimescaleConfig `mapstructure:"timescale"`
+	// Partitioning splits log_entries by time on mysql and postgres
+	Partitioning PartitioningConfig `mapstructure:"partitioning"`
+	// AutoMigrate applies pending schema migrations at startup. When off,
+	// the server refuses to start until the migrate command has applied them.
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+	// QueryTimeout bounds each log query of a request in seconds, and
+	// WriteTimeout each batch insert or retention delete; 0 for none
+	QueryTimeout int `mapstructure:"query_timeout"`
+	WriteTimeout int `mapstructure:"write_timeout"`
+	// DialTimeout bounds opening each connection in seconds; 0 for none.
+	// A timeout in Params or the DSN takes precedence.
+	DialTimeout int `mapstructure:"dial_timeout"`
+	// Pool sizes the connection pool, such as to an RDS instance's
+	// max_connections
+	Pool DatabasePoolConfig `mapstructure:"pool"`
+	// Encryption encrypts the raw log and chosen metadata fields of some
+	// projects' entries at rest
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+}
+
+// EncryptionConfig encrypts the entries of the listed projects, named by
+// the features.project_field metadata field, each project with its own
+// data key wrapped by the master key
+type EncryptionConfig struct {
+	Enabled      bool            `mapstructure:"enabled"`
+	Projects     []string        `mapstructure:"projects"`
+	MetadataKeys []string        `mapstructure:"metadata_keys"` // metadata fields encrypted besides raw_log
+	MasterKey    MasterKeyConfig `mapstructure:"master_key"`
+}
+
+// MasterKeyConfig selects the key wrapping the data keys: a key file kept
+// outside the database, or an AWS KMS key that never leaves KMS
+type MasterKeyConfig struct {
+	Provider string `mapstructure:"provider"` // local or aws_kms
+	KeyFile  string `mapstructure:"key_file"` // local: a base64-encoded 32-byte key
+	KeyID    string `mapstructure:"key_id"`   // aws_kms: a key ID, ARN or alias
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint"` // replaces https://kms.<region>.amazonaws.com
+}
+
+// DatabasePoolConfig limits the connections kept open to the database.
+// Lifetimes are in seconds, 0 for connections to be reused forever.
+type DatabasePoolConfig struct {
+	MaxOpenConns    int `mapstructure:"max_open_conns"` // 0 for no limit
+	MaxIdleConns    int `mapstructure:"max_idle_conns"` // 0 for the default of 2
+	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime int `mapstructure:"conn_max_idle_time"`
+}
+
+// DatabaseTLSConfig holds PEM files for TLS connections, such as a managed
+// database provider's CA bundle. ServerName and MinVersion are only
+// supported on mysql.
+type DatabaseTLSConfig struct {
+	CAFile   string `mapstructure:"ca_file"`
+	CertFile string `mapstructure:"cert_file"` // client certificate
+	KeyFile  string `mapstructure:"key_file"`
+	// ServerName is the host name verified in the server's certificate in
+	// place of host, such as when connecting through a proxy
+	ServerName string `mapstructure:"server_name"`
+	MinVersion string `mapstructure:"min_version"` // 1.2 or 1.3
+}
+
+// DatabaseIAMConfig authenticates to Amazon RDS with IAM tokens instead of
+// a password. Tokens are signed with the AWS SDK's default credentials,
+// from the environment, shared config files or an instance role, and
+// renewed before they expire.
+type DatabaseIAMConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Region  string `mapstructure:"region"`
+}
+
+// TimescaleConfig controls TimescaleDB on postgres. In auto mode it is used
+// when the extension is installed in the database; enabled installs it.
+type TimescaleConfig struct {
+	Mode          string `mapstructure:"mode"`           // auto, enabled or disabled
+	ChunkInterval int    `mapstructure:"chunk_interval"` // hours of log entries per chunk
+	CompressAfter int    `mapstructure:"compress_after"` // days before chunks are compressed, 0 never
+}
+
+// PartitioningConfig partitions log_entries by day or month, in UTC, so
+// retention drops whole partitions instead of deleting rows. A TimescaleDB
+// hypertable is partitioned already and takes precedence.
+type PartitioningConfig struct {
+	Interval string `mapstructure:"interval"` // day or month; empty leaves log_entries unpartitioned
+	Premake  int    `mapstructure:"premake"`  // partitions created ahead of the current one
 }
 
 type LoggingConfig struct {
@@ -36,23 +161,536 @@ type LoggingConfig struct {
 	MaxBackups int    `mapstructure:"max_backups"`
 }
 
+type AlertingConfig struct {
+	Enabled               bool                    `mapstructure:"enabled"`
+	EvaluationInterval    int                     `mapstructure:"evaluation_interval"` // seconds
+	Channels              []NotificationChannel   `mapstructure:"channels"`
+	Escalation            EscalationConfig        `mapstructure:"escalation"`
+	SlackSigningSecret    string                  `mapstructure:"slack_signing_secret"`    // verifies Slack acknowledge buttons
+	PatternLearningPeriod int                     `mapstructure:"pattern_learning_period"` // seconds after startup before patterns count as new
+	EvaluationHistory     EvaluationHistoryConfig `mapstructure:"evaluation_history"`
+}
+
+// EvaluationHistoryConfig sets which rule evaluations are stored, and for
+// how long
+type EvaluationHistoryConfig struct {
+	Interval  int `mapstructure:"interval"`  // seconds between stored evaluations of a rule; state changes are always stored
+	Retention int `mapstructure:"retention"` // days
+}
+
+type EscalationConfig struct {
+	RepeatInterval int    `mapstructure:"repeat_interval"` // seconds between reminders, 0 disables
+	EscalateAfter  int    `mapstructure:"escalate_after"`  // unacknowledged reminders before escalating
+	Channel        string `mapstructure:"channel"`         // notification channel used once escalated
+}
+
+type NotificationChannel struct {
+	Name     string `mapstructure:"name" json:"name"`
+	Type     string `mapstructure:"type" json:"type"` // webhook, slack, teams or plugin
+	URL      string `mapstructure:"url" json:"-"`
+	Plugin   string `mapstructure:"plugin" json:"plugin,omitempty"`     // plugin delivering alerts for type plugin
+	Template string `mapstructure:"template" json:"template,omitempty"` // Go text/template for the message body
+}
+
+type ProcessingConfig struct {
+	// Access log formats as written in the web server config, e.g.
+	// `%h %l %u %t "%r" %>s %b %D`; empty uses the combined log format
+	ApacheFormat string `mapstructure:"apache_format"`
+	NginxFormat  string `mapstructure:"nginx_format"`
+
+	Workers   int            `mapstructure:"workers"`    // lines parsed concurrently
+	BatchSize int            `mapstructure:"batch_size"` // most entries stored per transaction
+	Autotune  AutotuneConfig `mapstructure:"autotune"`
+
+	// Parsed entries wait in a queue of queue_size for one of writers to
+	// store them; while it is full, reading uploads and streams waits too
+	QueueSize int `mapstructure:"queue_size"` // entries
+	Writers   int `mapstructure:"writers"`    // batches stored concurrently
+}
+
+// AutotuneConfig lets the server choose workers and batch_size within
+// bounds from the measured parse and write latency, starting from the
+// configured values
+type AutotuneConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	MinWorkers   int  `mapstructure:"min_workers"`
+	MaxWorkers   int  `mapstructure:"max_workers"`
+	MinBatchSize int  `mapstructure:"min_batch_size"`
+	MaxBatchSize int  `mapstructure:"max_batch_size"`
+	Interval     int  `mapstructure:"interval"` // seconds between adjustments
+}
+
+// ForwardingConfig relays parsed entries to external SIEMs
+type ForwardingConfig struct {
+	Destinations []ForwardDestination `mapstructure:"destinations"`
+}
+
+type ForwardDestination struct {
+	Name string `mapstructure:"name" json:"name"`
+	Type string `mapstructure:"type" json:"type"` // splunk_hec, elastic, sentinel or syslog
+	// URL is the HEC event endpoint, the Elasticsearch base URL or the
+	// syslog receiver as udp://host:port or tcp://host:port; Sentinel
+	// derives it from the workspace ID unless set
+	URL string `mapstructure:"url" json:"-"`
+	// Token is the HEC token, Elastic API key or Sentinel shared key
+	Token       string `mapstructure:"token" json:"-"`
+	Index       string `mapstructure:"index" json:"index,omitempty"`             // Splunk or Elastic index, Sentinel custom log type
+	SourceType  string `mapstructure:"source_type" json:"source_type,omitempty"` // Splunk sourcetype
+	WorkspaceID string `mapstructure:"workspace_id" json:"workspace_id,omitempty"`
+	Format      string `mapstructure:"format" json:"format,omitempty"` // syslog payload, cef or leef
+	// SecurityOnly forwards just security-relevant entries; LogTypes, when
+	// set, limits forwarding to those log types
+	SecurityOnly  bool     `mapstructure:"security_only" json:"security_only"`
+	LogTypes      []string `mapstructure:"log_types" json:"log_types,omitempty"`
+	BatchSize     int      `mapstructure:"batch_size" json:"batch_size"`
+	FlushInterval int      `mapstructure:"flush_interval" json:"flush_interval"` // seconds
+}
+
+// PluginConfig runs a plugin providing parsers, enrichers, notification
+// channels or exporters. The server starts command and talks to it over
+// its standard input and output, or connects to a plugin already listening
+// at address.
+type PluginConfig struct {
+	Name    string   `mapstructure:"name" json:"name"`
+	Command string   `mapstructure:"command" json:"command,omitempty"`
+	Args    []string `mapstructure:"args" json:"args,omitempty"`
+	Address string   `mapstructure:"address" json:"address,omitempty"` // host:port
+	// Config is passed to the plugin when it starts
+	Config  map[string]interface{} `mapstructure:"config" json:"-"`
+	Timeout int                    `mapstructure:"timeout" json:"timeout"` // seconds per call, 0 for 5
+}
+
+// IngestConfig tails log files in local directories as they are written,
+// receives syslog messages over the network, imports objects from S3 and
+// accepts chunked uploads
+type IngestConfig struct {
+	Watch        []WatchConfig          `mapstructure:"watch"`
+	Syslog       []SyslogListenerConfig `mapstructure:"syslog"`
+	S3           S3Config               `mapstructure:"s3"`
+	Uploads      UploadsConfig          `mapstructure:"uploads"`
+	Loki         LokiConfig             `mapstructure:"loki"`
+	OTLP         OTLPConfig             `mapstructure:"otlp"`
+	HEC          HECConfig              `mapstructure:"hec"`
+	Pull         PullConfig             `mapstructure:"pull"`
+	LoadShedding LoadSheddingConfig     `mapstructure:"load_shedding"`
+	OffsetsFile  string                 `mapstructure:"offsets_file"`  // read positions kept across restarts
+	PollInterval int                    `mapstructure:"poll_interval"` // seconds
+	// FromBeginning reads files present on first start in full instead of
+	// only lines written afterwards
+	FromBeginning bool `mapstructure:"from_beginning"`
+	// DuplicateFiles decides what happens to an uploaded file that was
+	// already processed in full: reject, skip (accepted with a warning
+	// but not processed) or allow
+	DuplicateFiles string `mapstructure:"duplicate_files"`
+	// MaxErrorRate fails an uploaded file once more than this share of its
+	// lines failed to parse, checked from error_rate_lines lines on, rather
+	// than reading the rest; 0 never does. Uploads may set their own.
+	MaxErrorRate   float64 `mapstructure:"max_error_rate"`   // percent
+	ErrorRateLines int     `mapstructure:"error_rate_lines"` // lines
+}
+
+type WatchConfig struct {
+	Path    string `mapstructure:"path"`    // directory to watch
+	Pattern string `mapstructure:"pattern"` // file name glob, default *.log
+	LogType string `mapstructure:"log_type"`
+}
+
+type SyslogListenerConfig struct {
+	Protocol string `mapstructure:"protocol"` // udp or tcp
+	Address  string `mapstructure:"address"`  // e.g. ":5514"
+	// LogType parses received messages, default syslog; other types get the
+	// message with its syslog header removed
+	LogType string `mapstructure:"log_type"`
+}
+
+// S3Config is used by S3 ingestion requests. Without an access key the
+// AWS SDK's default credentials are used: the AWS_* environment
+// variables, the shared config files or an instance role.
+type S3Config struct {
+	Region          string `mapstructure:"region"`   // used when a request names none
+	Endpoint        string `mapstructure:"endpoint"` // S3-compatible store, e.g. MinIO
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+	MaxObjects      int    `mapstructure:"max_objects"` // most objects one request imports
+}
+
+// UploadsConfig controls resumable chunked uploads
+type UploadsConfig struct {
+	Dir          string `mapstructure:"dir"`            // received chunks are kept here
+	MaxSize      int64  `mapstructure:"max_size"`       // MB per upload
+	MaxChunkSize int64  `mapstructure:"max_chunk_size"` // MB per request
+	ExpireAfter  int    `mapstructure:"expire_after"`   // hours an idle upload is kept
+}
+
+// LokiConfig controls the Loki push API at /loki/api/v1/push
+type LokiConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	LogTypeLabel string `mapstructure:"log_type_label"` // stream label naming the parser of its lines
+	MaxBodySize  int64  `mapstructure:"max_body_size"`  // MB per push
+}
+
+// OTLPConfig controls the OTLP/HTTP logs receiver at /v1/logs
+type OTLPConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	LogTypeAttribute string `mapstructure:"log_type_attribute"` // record or resource attribute naming the parser of the body
+	MaxBodySize      int64  `mapstructure:"max_body_size"`      // MB per export
+}
+
+// HECConfig controls the Splunk HTTP Event Collector endpoints under
+// /services/collector. Requests must carry one of the tokens.
+type HECConfig struct {
+	Enabled     bool             `mapstructure:"enabled"`
+	Tokens      []HECTokenConfig `mapstructure:"tokens"`
+	MaxBodySize int64            `mapstructure:"max_body_size"` // MB per request
+}
+
+type HECTokenConfig struct {
+	Token string `mapstructure:"token"`
+	// Name identifies requests with the token in rate limit quotas
+	Name string `mapstructure:"name"`
+	// LogType parses events whose sourcetype is not a known log type;
+	// without one they are stored as messages
+	LogType string `mapstructure:"log_type"`
+}
+
+// PullConfig covers downloading log files from HTTP(S) and SFTP URLs with
+// POST /api/v1/logs/ingest/url and on schedules
+type PullConfig struct {
+	KnownHostsFile string               `mapstructure:"known_hosts_file"` // OpenSSH known_hosts of SFTP servers
+	Timeout        int                  `mapstructure:"timeout"`          // seconds to connect and for each response
+	StateFile      string               `mapstructure:"state_file"`       // read positions of scheduled pulls
+	Schedules      []PullScheduleConfig `mapstructure:"schedules"`
+}
+
+// PullScheduleConfig pulls a file on a cron schedule. Each run reads only
+// what was appended since the last.
+type PullScheduleConfig struct {
+	Name     string `mapstructure:"name"`
+	URL      string `mapstructure:"url"` // http, https or sftp
+	LogType  string `mapstructure:"log_type"`
+	Schedule string `mapstructure:"schedule"` // cron spec with seconds, e.g. "0 */5 * * * *"
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"` // bearer token for HTTP(S)
+	// PrivateKeyFile is an unencrypted SSH key for SFTP logins
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	// HostKey is the SFTP server's key or its SHA256 fingerprint; without
+	// one the server must be in known_hosts_file
+	HostKey string `mapstructure:"host_key"`
+}
+
+// LoadSheddingConfig pauses ingestion while heap or CPU usage is too high.
+// Zero limits are not checked.
+type LoadSheddingConfig struct {
+	Enabled    bool  `mapstructure:"enabled"`
+	MaxHeap    int64 `mapstructure:"max_heap"`    // MB of live heap
+	MaxCPU     int   `mapstructure:"max_cpu"`     // percent of GOMAXPROCS capacity
+	Interval   int   `mapstructure:"interval"`    // seconds between measurements
+	RetryAfter int   `mapstructure:"retry_after"` // seconds refused clients are told to wait
+}
+
+// RetentionConfig controls how long log entries are kept. Policies set
+// through the API replace the LogTypes entries.
+type RetentionConfig struct {
+	DefaultDays int            `mapstructure:"default_days"` // days entries of other log types are kept
+	LogTypes    map[string]int `mapstructure:"log_types"`    // days by log type
+	Schedule    string         `mapstructure:"schedule"`     // cron spec with seconds of the cleanup
+}
+
+// ArchiveConfig controls the cold storage archives expiring entries are
+// exported to. Archives in storage can be rehydrated whether or not new
+// ones are written.
+type ArchiveConfig struct {
+	Enabled   bool                `mapstructure:"enabled"`    // archive entries before retention removes them
+	Storage   ReportStorageConfig `mapstructure:"storage"`    // where archives are kept, as for reports
+	BatchSize int                 `mapstructure:"batch_size"` // entries read or stored at a time
+}
+
+// RollupsConfig controls the hourly and daily traffic rollups that stats
+// and reports read instead of scanning every entry. Rollups are kept for
+// their own number of days, so totals outlive the entries' retention.
+type RollupsConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Schedule      string `mapstructure:"schedule"`       // cron spec with seconds of the refresh
+	LookbackHours int    `mapstructure:"lookback_hours"` // hours each refresh recomputes, for entries arriving late
+	HourlyDays    int    `mapstructure:"hourly_days"`    // days hourly rollups are kept
+	DailyDays     int    `mapstructure:"daily_days"`     // days daily rollups are kept
+}
+
+// ComplianceConfig controls the monthly compliance report pack
+type ComplianceConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`     // archive the previous month's pack on the 1st
+	AdminPaths []string `mapstructure:"admin_paths"` // path prefixes of administrative interfaces
+	// Business hours are [business_hours_start, business_hours_end) on
+	// business_days in timezone
+	BusinessHoursStart int           `mapstructure:"business_hours_start"`
+	BusinessHoursEnd   int           `mapstructure:"business_hours_end"`
+	BusinessDays       []string      `mapstructure:"business_days"` // mon, tue, ...
+	Timezone           string        `mapstructure:"timezone"`
+	CountryLookback    int           `mapstructure:"country_lookback"` // days of history countries are compared with
+	Erasure            ErasureConfig `mapstructure:"erasure"`
+}
+
+// ErasureConfig controls right-to-erasure requests
+type ErasureConfig struct {
+	SigningKeyFile   string   `mapstructure:"signing_key_file"`  // Ed25519 key certificates are signed with, generated if missing
+	IdentifierFields []string `mapstructure:"identifier_fields"` // metadata fields holding user identifiers
+	BatchSize        int      `mapstructure:"batch_size"`        // entries scanned at a time
+}
+
+// IntegrityConfig controls the scheduled data integrity checks
+type IntegrityConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`
+	Schedule        string  `mapstructure:"schedule"`         // cron spec with seconds, e.g. "0 15 * * * *"
+	FutureSkew      int     `mapstructure:"future_skew"`      // seconds an entry may be timestamped ahead of the clock
+	RollupTolerance float64 `mapstructure:"rollup_tolerance"` // percent a roll-up total may differ from its entries
+	RollupLookback  int     `mapstructure:"rollup_lookback"`  // hours of roll-ups checked
+}
+
+// ReportsConfig controls where generated reports are kept
+type ReportsConfig struct {
+	Storage ReportStorageConfig `mapstructure:"storage"`
+	// MaxConcurrentJobs bounds how many requested reports are generated
+	// at once; further ones queue until one finishes
+	MaxConcurrentJobs int `mapstructure:"max_concurrent_jobs"`
+	// Delivery posts a summary of scheduled reports to chat channels
+	Delivery []ReportDelivery `mapstructure:"delivery"`
+	// GeoIP breaks reports down by the country and city of client IPs
+	GeoIP GeoIPConfig `mapstructure:"geoip"`
+	// Locale sets how HTML and PDF reports write dates and numbers, such
+	// as de-DE; empty for ISO dates and ungrouped numbers
+	Locale   string         `mapstructure:"locale"`
+	Branding BrandingConfig `mapstructure:"branding"`
+}
+
+// BrandingConfig is shown on HTML and PDF reports, for reports handed to
+// customers
+type BrandingConfig struct {
+	Title  string `mapstructure:"title"`  // shown above each report's title
+	Logo   string `mapstructure:"logo"`   // path to a PNG, JPEG or GIF file of at most 512 KB
+	Footer string `mapstructure:"footer"` // replaces the line at the foot of reports
+}
+
+// GeoIPConfig locates client IPs in a MaxMind GeoLite2 or GeoIP2 City or
+// Country database, which MaxMind updates weekly
+type GeoIPConfig struct {
+	Database string `mapstructure:"database"` // path to the .mmdb file; empty to not locate IPs
+}
+
+// ReportDelivery posts a summary card of the reports of its schedules,
+// with a link to download them, to a Slack or Teams incoming webhook
+type ReportDelivery struct {
+	Name      string   `mapstructure:"name"`
+	Type      string   `mapstructure:"type"` // slack or teams
+	URL       string   `mapstructure:"url"`
+	Schedules []string `mapstructure:"schedules"` // daily, weekly
+}
+
+// ReportStorageConfig selects the report store. local keeps reports in Dir;
+// s3, gcs and azure keep them in Bucket, the container on Azure, under
+// Prefix so that every replica serves the same reports.
+type ReportStorageConfig struct {
+	Type   string `mapstructure:"type"` // local, s3, gcs or azure
+	Dir    string `mapstructure:"dir"`
+	Bucket string `mapstructure:"bucket"`
+	Prefix string `mapstructure:"prefix"`
+	// S3 settings, with Region defaulting to us-east-1. Without an access
+	// key the AWS SDK's default credentials are used.
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+	// CredentialsFile is a GCS service account key; without one the
+	// application default credentials are used
+	CredentialsFile string `mapstructure:"credentials_file"`
+	// Azure storage account and its base64 shared key
+	Account    string `mapstructure:"account"`
+	AccountKey string `mapstructure:"account_key"`
+	// SignedURLs redirects report downloads to URLs valid for URLExpiry
+	// minutes instead of proxying them through the server
+	SignedURLs bool `mapstructure:"signed_urls"`
+	URLExpiry  int  `mapstructure:"url_expiry"`
+}
+
+// CacheConfig selects where short-lived values such as stats results are
+// cached. Replicas sharing a Redis cache see the same values; while Redis
+// fails each falls back to its own in-process cache.
+type CacheConfig struct {
+	Type     string           `mapstructure:"type"`      // memory or redis
+	StatsTTL int              `mapstructure:"stats_ttl"` // seconds stats results are reused, 0 to not cache them
+	Redis    RedisCacheConfig `mapstructure:"redis"`
+}
+
+type RedisCacheConfig struct {
+	Address       string `mapstructure:"address"` // host:port
+	Username      string `mapstructure:"username"`
+	Password      string `mapstructure:"password"`
+	DB            int    `mapstructure:"db"`
+	TLS           bool   `mapstructure:"tls"`
+	KeyPrefix     string `mapstructure:"key_prefix"`
+	Timeout       int    `mapstructure:"timeout"`        // milliseconds per command
+	PoolSize      int    `mapstructure:"pool_size"`      // idle connections kept
+	RetryInterval int    `mapstructure:"retry_interval"` // seconds before retrying Redis after a failure
+}
+
+// GraphQLConfig serves GraphQL queries over logs and stats at
+// /api/graphql. Queries in persisted_queries_dir, one per .graphql file,
+// can be sent by their SHA-256 hash, and with persisted_only nothing else
+// is accepted.
+type GraphQLConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	MaxDepth            int    `mapstructure:"max_depth"`      // levels of nested fields
+	MaxComplexity       int    `mapstructure:"max_complexity"` // field values per query, counting each list item
+	PersistedQueriesDir string `mapstructure:"persisted_queries_dir"`
+	PersistedOnly       bool   `mapstructure:"persisted_only"`
+	MaxPersistedQueries int    `mapstructure:"max_persisted_queries"` // queries clients can register by hash
+}
+
+// GRPCConfig serves the gRPC API of pkg/grpcapi, for ingesting and
+// querying logs and generating reports, on its own address
+type GRPCConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Address        string `mapstructure:"address"`
+	MaxMessageSize int    `mapstructure:"max_message_size"` // MB per received message
+}
+
+// TailConfig bounds the live tail at /api/v1/logs/tail
+type TailConfig struct {
+	MaxClients int `mapstructure:"max_clients"` // streams open at once
+	Buffer     int `mapstructure:"buffer"`      // entries held for a client before they are dropped
+	Heartbeat  int `mapstructure:"heartbeat"`   // seconds between keep-alive comments
+}
+
+// AuthConfig protects the dashboard and API
+type AuthConfig struct {
+	OIDC OIDCConfig `mapstructure:"oidc"`
+	// DefaultRole is the role of users the first time they sign in, and
+	// of bearer tokens of subjects that are not users
+	DefaultRole string `mapstructure:"default_role"` // "viewer", "analyst" or "admin"
+	// Admins are the subjects or emails of users who are always admins,
+	// so a new deployment has someone to assign roles
+	Admins []string `mapstructure:"admins"`
+}
+
+// OIDCConfig signs users in with an OpenID Connect provider. Signed-in
+// users get a session token in a cookie; API clients can instead send a
+// token from the provider as a bearer token.
+type OIDCConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Issuer       string   `mapstructure:"issuer"` // e.g. "https://login.example.com/realms/corp"
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"` // defaults to server.public_url + /auth/callback
+	Scopes       []string `mapstructure:"scopes"`
+	// Audiences are further audiences, besides client_id, of the bearer
+	// tokens accepted from the provider
+	Audiences     []string `mapstructure:"audiences"`
+	SessionSecret string   `mapstructure:"session_secret"` // signs session tokens, at least 32 bytes
+	SessionTTL    int      `mapstructure:"session_ttl"`    // minutes a sign-in lasts
+	// PublicPaths are path prefixes served without signing in, such as
+	// the health check and log shipper endpoints
+	PublicPaths []string `mapstructure:"public_paths"`
+}
+
+// RateLimitConfig limits how often each client may call the ingestion and
+// query endpoints. Requests count against the signed-in user, the named
+// HEC token they carry or otherwise their client address.
+type RateLimitConfig struct {
+	Enabled bool            `mapstructure:"enabled"`
+	Ingest  RateQuotaConfig `mapstructure:"ingest"`
+	Query   RateQuotaConfig `mapstructure:"query"`
+	// Quotas replace the limits of particular clients
+	Quotas  []KeyQuotaConfig `mapstructure:"quotas"`
+	MaxKeys int              `mapstructure:"max_keys"` // clients tracked at once
+}
+
+// RateQuotaConfig is a token bucket. A rate of 0 does not limit.
+type RateQuotaConfig struct {
+	Rate  float64 `mapstructure:"rate"`  // requests per second
+	Burst int     `mapstructure:"burst"` // requests allowed at once
+}
+
+// KeyQuotaConfig replaces the limits of a client, identified as
+// "user:<subject>", "hec:<token name>" or "ip:<address>". Limits left
+// out keep the defaults.
+type KeyQuotaConfig struct {
+	Key    string           `mapstructure:"key"`
+	Ingest *RateQuotaConfig `mapstructure:"ingest"`
+	Query  *RateQuotaConfig `mapstructure:"query"`
+}
+
+// Weekdays parses BusinessDays
+func (c ComplianceConfig) Weekdays() ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0, len(c.BusinessDays))
+	for _, name := range c.BusinessDays {
+		day, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown business day: %s", name)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// FeaturesConfig sets feature flags, which overrides made through the API
+// take precedence over
+type FeaturesConfig struct {
+	ProjectField string                       `mapstructure:"project_field"` // metadata field naming an entry's project
+	Flags        map[string]FeatureFlagConfig `mapstructure:"flags"`
+}
+
+type FeatureFlagConfig struct {
+	Enabled  *bool    `mapstructure:"enabled"`  // unset keeps the flag's default
+	Projects []string `mapstructure:"projects"` // projects the flag is on for regardless of enabled
+}
+
 func LoadConfig(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.AutomaticEnv()
+	return Load(configPath, "")
+}
+
+// Load reads the base config file and, when env is set, merges the
+// profile next to it over it. See ProfilePath.
+func Load(configPath, env string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.AutomaticEnv()
 
 	// Set defaults
-	setDefaults()
+	setDefaults(v)
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 	}
+	sources := []string{configPath}
+
+	if env != "" {
+		profile, err := ProfilePath(configPath, env)
+		if err != nil {
+			return nil, err
+		}
+		v.SetConfigFile(profile)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading %s profile: %w", env, err)
+		}
+		sources = append(sources, profile)
+	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	config.Env = env
+	config.Sources = sources
+	config.settings = v.AllSettings()
 
 	// Validate config
 	if err := validateConfig(&config); err != nil {
@@ -62,19 +700,133 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
-func setDefaults() {
-	viper.SetDefault("server.port", "8080")
-	viper.SetDefault("server.host", "localhost")
-	viper.SetDefault("server.read_timeout", 30)
-	viper.SetDefault("server.write_timeout", 30)
-	viper.SetDefault("database.type", "mysql")
-	viper.SetDefault("database.host", "localhost")
-	viper.SetDefault("database.port", 3306)
-	viper.SetDefault("database.ssl_mode", "disable")
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.output_file", "logs/app.log")
-	viper.SetDefault("logging.max_size", 100)
-	viper.SetDefault("logging.max_backups", 3)
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.host", "localhost")
+	v.SetDefault("server.read_timeout", 30)
+	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.public_url", "http://localhost:8080")
+	v.SetDefault("database.type", "mysql")
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 3306)
+	v.SetDefault("database.ssl_mode", "disable")
+	v.SetDefault("database.auto_migrate", true)
+	v.SetDefault("database.query_timeout", 30)
+	v.SetDefault("database.write_timeout", 300)
+	v.SetDefault("database.dial_timeout", 10)
+	v.SetDefault("database.pool.max_open_conns", 25)
+	v.SetDefault("database.pool.max_idle_conns", 25)
+	v.SetDefault("database.pool.conn_max_lifetime", 300)
+	v.SetDefault("database.encryption.master_key.provider", "local")
+	v.SetDefault("database.timescale.mode", "auto")
+	v.SetDefault("database.timescale.chunk_interval", 24)
+	v.SetDefault("database.timescale.compress_after", 7)
+	v.SetDefault("database.partitioning.premake", 3)
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.output_file", "logs/app.log")
+	v.SetDefault("logging.max_size", 100)
+	v.SetDefault("logging.max_backups", 3)
+	v.SetDefault("alerting.enabled", true)
+	v.SetDefault("alerting.evaluation_interval", 1)
+	v.SetDefault("alerting.pattern_learning_period", 300)
+	v.SetDefault("alerting.evaluation_history.interval", 60)
+	v.SetDefault("alerting.evaluation_history.retention", 7)
+	v.SetDefault("alerting.escalation.repeat_interval", 0)
+	v.SetDefault("alerting.escalation.escalate_after", 0)
+	v.SetDefault("ingest.offsets_file", "data/ingest_offsets.json")
+	v.SetDefault("ingest.poll_interval", 1)
+	v.SetDefault("ingest.duplicate_files", "reject")
+	v.SetDefault("ingest.max_error_rate", 50)
+	v.SetDefault("ingest.error_rate_lines", 1000)
+	v.SetDefault("ingest.s3.region", "us-east-1")
+	v.SetDefault("ingest.s3.max_objects", 10000)
+	v.SetDefault("ingest.uploads.dir", "data/uploads")
+	v.SetDefault("ingest.uploads.max_size", 51200)
+	v.SetDefault("ingest.uploads.max_chunk_size", 64)
+	v.SetDefault("ingest.uploads.expire_after", 24)
+	v.SetDefault("ingest.loki.enabled", true)
+	v.SetDefault("ingest.loki.log_type_label", "log_type")
+	v.SetDefault("ingest.loki.max_body_size", 10)
+	v.SetDefault("ingest.otlp.enabled", true)
+	v.SetDefault("ingest.otlp.log_type_attribute", "log_type")
+	v.SetDefault("ingest.otlp.max_body_size", 10)
+	v.SetDefault("ingest.hec.enabled", false)
+	v.SetDefault("ingest.hec.max_body_size", 10)
+	v.SetDefault("ingest.pull.timeout", 30)
+	v.SetDefault("ingest.pull.state_file", "data/pull_offsets.json")
+	v.SetDefault("ingest.load_shedding.enabled", false)
+	v.SetDefault("ingest.load_shedding.interval", 1)
+	v.SetDefault("ingest.load_shedding.retry_after", 5)
+	v.SetDefault("reports.storage.type", "local")
+	v.SetDefault("reports.storage.dir", "reports")
+	v.SetDefault("reports.storage.signed_urls", true)
+	v.SetDefault("reports.storage.url_expiry", 15)
+	v.SetDefault("reports.max_concurrent_jobs", 2)
+	v.SetDefault("cache.type", "memory")
+	v.SetDefault("cache.stats_ttl", 10)
+	v.SetDefault("cache.redis.address", "localhost:6379")
+	v.SetDefault("cache.redis.key_prefix", "loganalyzer:")
+	v.SetDefault("cache.redis.timeout", 500)
+	v.SetDefault("cache.redis.pool_size", 10)
+	v.SetDefault("cache.redis.retry_interval", 30)
+	v.SetDefault("graphql.enabled", false)
+	v.SetDefault("graphql.max_depth", 8)
+	v.SetDefault("graphql.max_complexity", 5000)
+	v.SetDefault("graphql.max_persisted_queries", 1000)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.address", ":9090")
+	v.SetDefault("grpc.max_message_size", 16)
+	v.SetDefault("tail.max_clients", 100)
+	v.SetDefault("tail.buffer", 1000)
+	v.SetDefault("tail.heartbeat", 15)
+	v.SetDefault("auth.default_role", "viewer")
+	v.SetDefault("rate_limit.enabled", false)
+	v.SetDefault("rate_limit.ingest.rate", 50)
+	v.SetDefault("rate_limit.ingest.burst", 200)
+	v.SetDefault("rate_limit.query.rate", 10)
+	v.SetDefault("rate_limit.query.burst", 50)
+	v.SetDefault("rate_limit.max_keys", 10000)
+	v.SetDefault("auth.oidc.enabled", false)
+	v.SetDefault("auth.oidc.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("auth.oidc.session_ttl", 480)
+	v.SetDefault("auth.oidc.public_paths", []string{"/health", "/services/collector", "/loki/api/v1/push", "/v1/logs", "/api/v1/alerts/slack/actions"})
+	v.SetDefault("retention.default_days", 90)
+	v.SetDefault("retention.schedule", "0 0 4 1 * *")
+	v.SetDefault("archive.enabled", false)
+	v.SetDefault("archive.storage.type", "local")
+	v.SetDefault("archive.storage.dir", "archive")
+	v.SetDefault("archive.storage.url_expiry", 15)
+	v.SetDefault("archive.batch_size", 1000)
+	v.SetDefault("rollups.enabled", true)
+	v.SetDefault("rollups.schedule", "0 */10 * * * *")
+	v.SetDefault("rollups.lookback_hours", 2)
+	v.SetDefault("rollups.hourly_days", 90)
+	v.SetDefault("rollups.daily_days", 730)
+	v.SetDefault("compliance.enabled", true)
+	v.SetDefault("compliance.business_hours_start", 8)
+	v.SetDefault("compliance.business_hours_end", 18)
+	v.SetDefault("compliance.business_days", []string{"mon", "tue", "wed", "thu", "fri"})
+	v.SetDefault("compliance.timezone", "UTC")
+	v.SetDefault("compliance.country_lookback", 90)
+	v.SetDefault("compliance.erasure.signing_key_file", "data/erasure_signing.key")
+	v.SetDefault("compliance.erasure.identifier_fields", []string{"user_id", "user", "username", "email", "customer_id"})
+	v.SetDefault("compliance.erasure.batch_size", 1000)
+	v.SetDefault("integrity.enabled", true)
+	v.SetDefault("integrity.schedule", "0 15 * * * *")
+	v.SetDefault("integrity.future_skew", 300)
+	v.SetDefault("integrity.rollup_tolerance", 1)
+	v.SetDefault("integrity.rollup_lookback", 24)
+	v.SetDefault("features.project_field", "project")
+	v.SetDefault("processing.workers", 10)
+	v.SetDefault("processing.batch_size", 100)
+	v.SetDefault("processing.queue_size", 1000)
+	v.SetDefault("processing.writers", 1)
+	v.SetDefault("processing.autotune.enabled", false)
+	v.SetDefault("processing.autotune.min_workers", 2)
+	v.SetDefault("processing.autotune.max_workers", 64)
+	v.SetDefault("processing.autotune.min_batch_size", 1)
+	v.SetDefault("processing.autotune.max_batch_size", 1000)
+	v.SetDefault("processing.autotune.interval", 10)
 }
 
 func validateConfig(config *Config) error {
@@ -86,42 +838,537 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("database type is required")
 	}
 
-	if config.Database.Type != "mysql" && config.Database.Type != "postgres" {
-		return fmt.Errorf("unsupported database type: %s", config.Database.Type)
+	// Other types name storage backends, which are checked when opened
+	if config.Database.Type == "mysql" || config.Database.Type == "postgres" {
+		if err := validateSQLDatabase(&config.Database); err != nil {
+			return err
+		}
+	}
+	if config.Database.Type == "sqlite" {
+		if err := validateSQLiteDatabase(&config.Database); err != nil {
+			return err
+		}
 	}
 
-	if config.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+	switch config.Database.Timescale.Mode {
+	case "", "auto", "enabled", "disabled":
+	default:
+		return fmt.Errorf("database timescale mode must be auto, enabled or disabled")
+	}
+	if config.Database.Timescale.ChunkInterval < 0 || config.Database.Timescale.CompressAfter < 0 {
+		return fmt.Errorf("database timescale settings cannot be negative")
+	}
+	if partitioning := config.Database.Partitioning; partitioning.Interval != "" {
+		if partitioning.Interval != "day" && partitioning.Interval != "month" {
+			return fmt.Errorf("database partitioning interval must be day or month")
+		}
+		if config.Database.Type != "mysql" && config.Database.Type != "postgres" {
+			return fmt.Errorf("database partitioning is only supported on mysql and postgres")
+		}
+		if config.Database.Timescale.Mode == "enabled" {
+			return fmt.Errorf("database partitioning cannot be used with timescale enabled")
+		}
+		if partitioning.Premake < 0 {
+			return fmt.Errorf("database partitioning premake cannot be negative")
+		}
+	}
+	if config.Database.QueryTimeout < 0 || config.Database.WriteTimeout < 0 {
+		return fmt.Errorf("database query and write timeouts cannot be negative")
+	}
+	if config.Database.DialTimeout < 0 {
+		return fmt.Errorf("database dial_timeout cannot be negative")
+	}
+	if pool := config.Database.Pool; pool.MaxOpenConns < 0 || pool.MaxIdleConns < 0 ||
+		pool.ConnMaxLifetime < 0 || pool.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("database pool settings cannot be negative")
+	}
+	if config.Database.Encryption.Enabled {
+		if err := validateEncryption(&config.Database.Encryption, config.Features.ProjectField); err != nil {
+			return err
+		}
+	}
+
+	if config.Alerting.EvaluationInterval < 1 {
+		return fmt.Errorf("alerting evaluation interval must be at least 1 second")
+	}
+
+	if config.Alerting.PatternLearningPeriod < 0 {
+		return fmt.Errorf("alerting pattern learning period cannot be negative")
+	}
+
+	if history := config.Alerting.EvaluationHistory; history.Interval < 1 || history.Retention < 1 {
+		return fmt.Errorf("alerting evaluation_history interval and retention must be at least 1")
+	}
+
+	escalation := config.Alerting.Escalation
+	if escalation.RepeatInterval < 0 || escalation.EscalateAfter < 0 {
+		return fmt.Errorf("alerting escalation settings cannot be negative")
+	}
+	if escalation.EscalateAfter > 0 && (escalation.RepeatInterval == 0 || escalation.Channel == "") {
+		return fmt.Errorf("alerting escalation requires repeat_interval and channel")
+	}
+	if escalation.Channel != "" && escalation.EscalateAfter == 0 {
+		return fmt.Errorf("alerting escalation channel requires escalate_after")
 	}
 
-	if config.Database.Database == "" {
-		return fmt.Errorf("database name is required")
+	processing := config.Processing
+	if processing.Workers < 1 || processing.BatchSize < 1 {
+		return fmt.Errorf("processing workers and batch_size must be at least 1")
+	}
+	if processing.QueueSize < 1 || processing.Writers < 1 {
+		return fmt.Errorf("processing queue_size and writers must be at least 1")
+	}
+	if autotune := processing.Autotune; autotune.Enabled {
+		if autotune.MinWorkers < 1 || autotune.MaxWorkers < autotune.MinWorkers {
+			return fmt.Errorf("processing autotune requires 1 <= min_workers <= max_workers")
+		}
+		if autotune.MinBatchSize < 1 || autotune.MaxBatchSize < autotune.MinBatchSize {
+			return fmt.Errorf("processing autotune requires 1 <= min_batch_size <= max_batch_size")
+		}
+		if autotune.Interval < 1 {
+			return fmt.Errorf("processing autotune interval must be at least 1 second")
+		}
 	}
 
+	if config.Ingest.PollInterval < 1 && len(config.Ingest.Watch) > 0 {
+		return fmt.Errorf("ingest poll interval must be at least 1 second")
+	}
+	switch config.Ingest.DuplicateFiles {
+	case "reject", "skip", "allow":
+	default:
+		return fmt.Errorf("ingest duplicate_files must be reject, skip or allow")
+	}
+	if config.Ingest.MaxErrorRate < 0 || config.Ingest.MaxErrorRate > 100 {
+		return fmt.Errorf("ingest max_error_rate must be between 0 and 100")
+	}
+	if config.Ingest.ErrorRateLines < 1 {
+		return fmt.Errorf("ingest error_rate_lines must be at least 1")
+	}
+	for _, listener := range config.Ingest.Syslog {
+		if listener.Protocol != "udp" && listener.Protocol != "tcp" {
+			return fmt.Errorf("syslog listener %s: protocol must be udp or tcp", listener.Address)
+		}
+		if listener.Address == "" {
+			return fmt.Errorf("syslog listener address is required")
+		}
+	}
+	uploads := config.Ingest.Uploads
+	if uploads.Dir == "" || uploads.MaxSize < 1 || uploads.MaxChunkSize < 1 || uploads.ExpireAfter < 1 {
+		return fmt.Errorf("ingest uploads require dir and positive max_size, max_chunk_size and expire_after")
+	}
+	if config.Ingest.Loki.Enabled && config.Ingest.Loki.MaxBodySize < 1 {
+		return fmt.Errorf("ingest loki max_body_size must be at least 1")
+	}
+	if config.Ingest.OTLP.Enabled && config.Ingest.OTLP.MaxBodySize < 1 {
+		return fmt.Errorf("ingest otlp max_body_size must be at least 1")
+	}
+	if hec := config.Ingest.HEC; hec.Enabled {
+		if hec.MaxBodySize < 1 {
+			return fmt.Errorf("ingest hec max_body_size must be at least 1")
+		}
+		if len(hec.Tokens) == 0 {
+			return fmt.Errorf("ingest hec requires at least one token")
+		}
+		seen := make(map[string]bool, len(hec.Tokens))
+		for _, token := range hec.Tokens {
+			if token.Token == "" {
+				return fmt.Errorf("ingest hec tokens must not be empty")
+			}
+			if seen[token.Token] {
+				return fmt.Errorf("ingest hec tokens must be unique")
+			}
+			seen[token.Token] = true
+		}
+	}
+	pull := config.Ingest.Pull
+	if pull.Timeout < 1 {
+		return fmt.Errorf("ingest pull timeout must be at least 1 second")
+	}
+	if len(pull.Schedules) > 0 && pull.StateFile == "" {
+		return fmt.Errorf("ingest pull schedules require a state_file")
+	}
+	seen := make(map[string]bool, len(pull.Schedules))
+	for _, schedule := range pull.Schedules {
+		if schedule.Name == "" || schedule.URL == "" || schedule.LogType == "" || schedule.Schedule == "" {
+			return fmt.Errorf("ingest pull schedules require name, url, log_type and schedule")
+		}
+		if seen[schedule.Name] {
+			return fmt.Errorf("ingest pull schedule names must be unique: %s", schedule.Name)
+		}
+		seen[schedule.Name] = true
+		if u, err := url.Parse(schedule.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "sftp") || u.Host == "" {
+			return fmt.Errorf("ingest pull schedule %s: url must be an http, https or sftp URL", schedule.Name)
+		}
+	}
+	if shedding := config.Ingest.LoadShedding; shedding.Enabled {
+		if shedding.MaxHeap < 0 || shedding.MaxCPU < 0 || shedding.MaxCPU > 100 {
+			return fmt.Errorf("ingest load_shedding requires max_heap >= 0 and max_cpu between 0 and 100")
+		}
+		if shedding.MaxHeap == 0 && shedding.MaxCPU == 0 {
+			return fmt.Errorf("ingest load_shedding requires max_heap or max_cpu")
+		}
+		if shedding.Interval < 1 || shedding.RetryAfter < 1 {
+			return fmt.Errorf("ingest load_shedding interval and retry_after must be at least 1 second")
+		}
+	}
+	if config.Ingest.S3.MaxObjects < 1 {
+		return fmt.Errorf("ingest s3 max_objects must be at least 1")
+	}
+	if endpoint := config.Ingest.S3.Endpoint; endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("ingest s3 endpoint must be an http or https URL")
+		}
+	}
+
+	if err := validateStorage("reports storage", &config.Reports.Storage); err != nil {
+		return err
+	}
+	if config.Reports.MaxConcurrentJobs < 1 {
+		return fmt.Errorf("reports max_concurrent_jobs must be at least 1")
+	}
+	if err := validateStorage("archive storage", &config.Archive.Storage); err != nil {
+		return err
+	}
+	if config.Archive.BatchSize < 1 {
+		return fmt.Errorf("archive batch size must be at least 1")
+	}
+
+	cache := config.Cache
+	switch cache.Type {
+	case "memory":
+	case "redis":
+		if cache.Redis.Address == "" {
+			return fmt.Errorf("cache redis address is required")
+		}
+		if cache.Redis.DB < 0 || cache.Redis.Timeout < 1 || cache.Redis.PoolSize < 1 || cache.Redis.RetryInterval < 1 {
+			return fmt.Errorf("cache redis requires db >= 0 and positive timeout, pool_size and retry_interval")
+		}
+	default:
+		return fmt.Errorf("unsupported cache type: %s", cache.Type)
+	}
+	if cache.StatsTTL < 0 {
+		return fmt.Errorf("cache stats_ttl must not be negative")
+	}
+
+	if graphql := config.GraphQL; graphql.Enabled {
+		if graphql.MaxDepth < 1 || graphql.MaxComplexity < 1 || graphql.MaxPersistedQueries < 1 {
+			return fmt.Errorf("graphql max_depth, max_complexity and max_persisted_queries must be at least 1")
+		}
+		if graphql.PersistedOnly && graphql.PersistedQueriesDir == "" {
+			return fmt.Errorf("graphql persisted_only requires persisted_queries_dir")
+		}
+	}
+
+	if grpc := config.GRPC; grpc.Enabled {
+		if grpc.Address == "" {
+			return fmt.Errorf("grpc address is required")
+		}
+		if grpc.MaxMessageSize < 1 {
+			return fmt.Errorf("grpc max_message_size must be at least 1")
+		}
+	}
+
+	if tail := config.Tail; tail.MaxClients < 1 || tail.Buffer < 1 || tail.Heartbeat < 1 {
+		return fmt.Errorf("tail max_clients, buffer and heartbeat must be at least 1")
+	}
+
+	if limits := config.RateLimit; limits.Enabled {
+		if limits.MaxKeys < 1 {
+			return fmt.Errorf("rate_limit max_keys must be at least 1")
+		}
+		quotas := []*RateQuotaConfig{&limits.Ingest, &limits.Query}
+		for _, quota := range limits.Quotas {
+			if !strings.HasPrefix(quota.Key, "user:") && !strings.HasPrefix(quota.Key, "hec:") && !strings.HasPrefix(quota.Key, "ip:") {
+				return fmt.Errorf("rate_limit quota key %q must start with user:, hec: or ip:", quota.Key)
+			}
+			quotas = append(quotas, quota.Ingest, quota.Query)
+		}
+		for _, quota := range quotas {
+			if quota != nil && (quota.Rate < 0 || (quota.Rate > 0 && quota.Burst < 1)) {
+				return fmt.Errorf("rate_limit rates must be at least 0, and bursts at least 1")
+			}
+		}
+	}
+
+	switch config.Auth.DefaultRole {
+	case "viewer", "analyst", "admin":
+	default:
+		return fmt.Errorf("auth default_role must be viewer, analyst or admin")
+	}
+	if oidc := &config.Auth.OIDC; oidc.Enabled {
+		if u, err := url.Parse(oidc.Issuer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("auth oidc issuer must be an http or https URL")
+		}
+		if oidc.ClientID == "" || oidc.ClientSecret == "" {
+			return fmt.Errorf("auth oidc requires client_id and client_secret")
+		}
+		if len(oidc.SessionSecret) < 32 {
+			return fmt.Errorf("auth oidc session_secret must be at least 32 bytes")
+		}
+		if oidc.SessionTTL < 1 {
+			return fmt.Errorf("auth oidc session_ttl must be at least 1 minute")
+		}
+		if oidc.RedirectURL == "" {
+			if config.Server.PublicURL == "" {
+				return fmt.Errorf("auth oidc requires redirect_url or server public_url")
+			}
+			oidc.RedirectURL = strings.TrimSuffix(config.Server.PublicURL, "/") + "/auth/callback"
+		}
+		if u, err := url.Parse(oidc.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("auth oidc redirect_url must be an http or https URL")
+		}
+		for _, path := range oidc.PublicPaths {
+			if !strings.HasPrefix(path, "/") || path == "/" {
+				return fmt.Errorf("auth oidc public_paths must be paths below /: %s", path)
+			}
+		}
+	}
+
+	plugins := make(map[string]bool, len(config.Plugins))
+	for _, plugin := range config.Plugins {
+		if plugin.Name == "" {
+			return fmt.Errorf("plugin name is required")
+		}
+		if plugins[plugin.Name] {
+			return fmt.Errorf("plugin names must be unique: %s", plugin.Name)
+		}
+		plugins[plugin.Name] = true
+		if (plugin.Command == "") == (plugin.Address == "") {
+			return fmt.Errorf("plugin %s requires either command or address", plugin.Name)
+		}
+		if plugin.Timeout < 0 {
+			return fmt.Errorf("plugin %s timeout cannot be negative", plugin.Name)
+		}
+	}
+	for _, channel := range config.Alerting.Channels {
+		if channel.Plugin != "" && !plugins[channel.Plugin] {
+			return fmt.Errorf("notification channel %s: plugin %s is not configured", channel.Name, channel.Plugin)
+		}
+	}
+
+	if config.Retention.DefaultDays < 1 {
+		return fmt.Errorf("retention default_days must be at least 1")
+	}
+	for logType, days := range config.Retention.LogTypes {
+		if days < 1 {
+			return fmt.Errorf("retention of log type %s must be at least 1 day", logType)
+		}
+	}
+	if config.Retention.Schedule == "" {
+		return fmt.Errorf("retention schedule is required")
+	}
+
+	if rollups := config.Rollups; rollups.Enabled {
+		if rollups.Schedule == "" {
+			return fmt.Errorf("rollups schedule is required")
+		}
+		if rollups.LookbackHours < 1 {
+			return fmt.Errorf("rollups lookback must be at least 1 hour")
+		}
+		if rollups.HourlyDays < 1 || rollups.DailyDays < 1 {
+			return fmt.Errorf("rollups hourly_days and daily_days must be at least 1")
+		}
+	}
+
+	compliance := config.Compliance
+	if compliance.BusinessHoursStart < 0 || compliance.BusinessHoursEnd > 24 || compliance.BusinessHoursStart >= compliance.BusinessHoursEnd {
+		return fmt.Errorf("compliance business hours must satisfy 0 <= start < end <= 24")
+	}
+	if _, err := compliance.Weekdays(); err != nil {
+		return fmt.Errorf("compliance: %w", err)
+	}
+	if _, err := time.LoadLocation(compliance.Timezone); err != nil {
+		return fmt.Errorf("compliance timezone: %w", err)
+	}
+	if compliance.CountryLookback < 1 {
+		return fmt.Errorf("compliance country lookback must be at least 1 day")
+	}
+	if compliance.Erasure.SigningKeyFile == "" {
+		return fmt.Errorf("compliance erasure signing key file is required")
+	}
+	if compliance.Erasure.BatchSize < 1 {
+		return fmt.Errorf("compliance erasure batch size must be at least 1")
+	}
+
+	if integrity := config.Integrity; integrity.Enabled {
+		if integrity.Schedule == "" {
+			return fmt.Errorf("integrity schedule is required")
+		}
+		if integrity.FutureSkew < 0 || integrity.RollupTolerance < 0 {
+			return fmt.Errorf("integrity future_skew and rollup_tolerance cannot be negative")
+		}
+		if integrity.RollupLookback < 1 {
+			return fmt.Errorf("integrity rollup lookback must be at least 1 hour")
+		}
+	}
+
+	return nil
+}
+
+// validateStorage checks the settings a report or archive store type needs.
+// name describes the store in errors.
+func validateStorage(name string, storage *ReportStorageConfig) error {
+	switch storage.Type {
+	case "local":
+		if storage.Dir == "" {
+			return fmt.Errorf("%s dir is required", name)
+		}
+		return nil
+	case "s3", "gcs":
+	case "azure":
+		if storage.Account == "" || storage.AccountKey == "" {
+			return fmt.Errorf("%s on azure requires account and account_key", name)
+		}
+	default:
+		return fmt.Errorf("unsupported %s type: %s", name, storage.Type)
+	}
+
+	if storage.Bucket == "" {
+		return fmt.Errorf("%s bucket is required", name)
+	}
+	if storage.Endpoint != "" {
+		if u, err := url.Parse(storage.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s endpoint must be an http or https URL", name)
+		}
+	}
+	if storage.URLExpiry < 1 || storage.URLExpiry > 7*24*60 {
+		return fmt.Errorf("%s url_expiry must be between 1 and 10080 minutes", name)
+	}
+	return nil
+}
+
+// validateSQLiteDatabase checks the settings of a sqlite database file
+func validateSQLiteDatabase(db *DatabaseConfig) error {
+	if db.DSN == "" && db.Database == "" {
+		return fmt.Errorf("database file is required")
+	}
+	if _, err := url.ParseQuery(db.Params); err != nil {
+		return fmt.Errorf("invalid database params: %w", err)
+	}
+	if db.IAMAuth.Enabled {
+		return fmt.Errorf("database iam_auth is not supported on sqlite")
+	}
 	return nil
 }
 
+// validateEncryption checks the settings of encryption at rest.
+// projectField is the metadata field naming an entry's project, which
+// stays in plain text so entries can be decrypted.
+func validateEncryption(enc *EncryptionConfig, projectField string) error {
+	if len(enc.Projects) == 0 {
+		return fmt.Errorf("database encryption requires at least one project")
+	}
+	for _, key := range enc.MetadataKeys {
+		if key == projectField {
+			return fmt.Errorf("database encryption cannot encrypt the project field %s", key)
+		}
+	}
+
+	switch enc.MasterKey.Provider {
+	case "local":
+		if enc.MasterKey.KeyFile == "" {
+			return fmt.Errorf("database encryption master key file is required")
+		}
+	case "aws_kms":
+		if enc.MasterKey.KeyID == "" || enc.MasterKey.Region == "" {
+			return fmt.Errorf("database encryption with aws_kms requires key_id and region")
+		}
+	default:
+		return fmt.Errorf("unsupported database encryption master key provider: %s", enc.MasterKey.Provider)
+	}
+	return nil
+}
+
+// validateSQLDatabase checks the connection settings of mysql and postgres
+func validateSQLDatabase(db *DatabaseConfig) error {
+	// A DSN names the host and database itself
+	if db.DSN == "" {
+		if db.Host == "" {
+			return fmt.Errorf("database host is required")
+		}
+
+		if db.Database == "" {
+			return fmt.Errorf("database name is required")
+		}
+	}
+
+	switch db.SSLMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	case "allow", "prefer":
+		if db.Type != "postgres" {
+			return fmt.Errorf("database ssl_mode %s is only supported on postgres", db.SSLMode)
+		}
+	default:
+		return fmt.Errorf("unsupported database ssl_mode: %s", db.SSLMode)
+	}
+
+	if _, err := url.ParseQuery(db.Params); err != nil {
+		return fmt.Errorf("invalid database params: %w", err)
+	}
+	if (db.TLS.CertFile == "") != (db.TLS.KeyFile == "") {
+		return fmt.Errorf("database tls cert_file and key_file must be set together")
+	}
+	if db.TLS.ServerName != "" || db.TLS.MinVersion != "" {
+		// lib/pq offers no settings for either
+		if db.Type != "mysql" {
+			return fmt.Errorf("database tls server_name and min_version are only supported on mysql")
+		}
+		switch db.TLS.MinVersion {
+		case "", "1.2", "1.3":
+		default:
+			return fmt.Errorf("unsupported database tls min_version: %s", db.TLS.MinVersion)
+		}
+	}
+
+	if db.IAMAuth.Enabled {
+		if db.IAMAuth.Region == "" || db.Host == "" || db.Username == "" {
+			return fmt.Errorf("database iam_auth requires region, host and username")
+		}
+		// Tokens are only accepted over TLS
+		if db.DSN == "" && (db.SSLMode == "" || db.SSLMode == "disable" || db.SSLMode == "allow" || db.SSLMode == "prefer") {
+			return fmt.Errorf("database iam_auth requires ssl_mode require, verify-ca or verify-full")
+		}
+	}
+	return nil
+}
+
+// GetDSN returns the configured DSN, or one built from the connection
+// fields. Params, TLS and IAM authentication are applied when connecting.
 func (c *Config) GetDSN() string {
+	if c.Database.DSN != "" {
+		return c.Database.DSN
+	}
+
 	switch c.Database.Type {
 	case "mysql":
 		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
 			c.Database.Username, c.Database.Password,
 			c.Database.Host, c.Database.Port, c.Database.Database)
 	case "postgres":
+		// Values are quoted so empty ones and spaces are read correctly
 		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			c.Database.Host, c.Database.Port, c.Database.Username,
-			c.Database.Password, c.Database.Database, c.Database.SSLMode)
+			quoteDSNValue(c.Database.Host), c.Database.Port, quoteDSNValue(c.Database.Username),
+			quoteDSNValue(c.Database.Password), quoteDSNValue(c.Database.Database), quoteDSNValue(c.Database.SSLMode))
+	case "sqlite":
+		return c.Database.Database
 	default:
 		return ""
 	}
 }
 
+// quoteDSNValue quotes a value of a postgres key/value connection string
+func quoteDSNValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 func (c *Config) GetDriverName() string {
 	switch c.Database.Type {
 	case "mysql":
 		return "mysql"
 	case "postgres":
 		return "postgres"
+	case "sqlite":
+		return "sqlite3"
 	default:
 		return ""
 	}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// EnvVar selects the profile when none is given on the command line
+const EnvVar = "LOG_ANALYZER_ENV"
+
+// maskedValue replaces secrets in Effective
+const maskedValue = "********"
+
+var envName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// secretKeys are the settings Effective masks. Notification and forwarding
+// URLs are included, since webhook URLs carry credentials.
+var secretKeys = map[string]bool{
+	"password":             true,
+	"dsn":                  true,
+	"params":               true,
+	"token":                true,
+	"url":                  true,
+	"secret_access_key":    true,
+	"session_token":        true,
+	"slack_signing_secret": true,
+	"account_key":          true,
+	"client_secret":        true,
+	"session_secret":       true,
+}
+
+// ProfilePath returns the profile of env for a base config file: the file
+// in the same directory named <base>.<env><ext>, so config.yaml with env
+// prod reads config.prod.yaml. Profiles hold only what differs from the
+// base; maps are merged key by key and lists are replaced.
+func ProfilePath(configPath, env string) (string, error) {
+	if !envName.MatchString(env) {
+		return "", fmt.Errorf("invalid environment name %q", env)
+	}
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + env + ext, nil
+}
+
+// Effective returns the merged settings of every key, defaults included,
+// with secrets masked
+func (c *Config) Effective() map[string]interface{} {
+	masked, _ := maskSecrets("", c.settings).(map[string]interface{})
+	if masked == nil {
+		masked = map[string]interface{}{}
+	}
+	return masked
+}
+
+// maskSecrets copies a settings value, masking non-empty secrets
+func maskSecrets(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			copied[k] = maskSecrets(k, item)
+		}
+		return copied
+	case map[interface{}]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			name := fmt.Sprint(k)
+			copied[name] = maskSecrets(name, item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = maskSecrets("", item)
+		}
+		return copied
+	}
+
+	if secretKeys[strings.ToLower(key)] && fmt.Sprint(value) != "" {
+		return maskedValue
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	writeFile(t, base, `
+server:
+  port: "8080"
+  host: "localhost"
+database:
+  type: "memory"
+  password: "base-secret"
+`)
+	writeFile(t, filepath.Join(dir, "config.prod.yaml"), `
+server:
+  port: "9090"
+database:
+  password: "prod-secret"
+`)
+
+	cfg, err := Load(base, "")
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, []string{base}, cfg.Sources)
+
+	cfg, err = Load(base, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.Env)
+	assert.Equal(t, "9090", cfg.Server.Port)
+	assert.Equal(t, "localhost", cfg.Server.Host, "keys missing from the profile keep the base value")
+	assert.Equal(t, "prod-secret", cfg.Database.Password)
+	assert.Equal(t, []string{base, filepath.Join(dir, "config.prod.yaml")}, cfg.Sources)
+
+	_, err = Load(base, "staging")
+	assert.Error(t, err, "a missing profile is an error")
+}
+
+func TestProfilePath(t *testing.T) {
+	path, err := ProfilePath("/etc/analyzer/config.yaml", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/analyzer/config.prod.yaml", path)
+
+	for _, env := range []string{"", "../prod", "Prod", "prod/x"} {
+		_, err := ProfilePath("config.yaml", env)
+		assert.Error(t, err, env)
+	}
+}
+
+func TestEffectiveMasksSecrets(t *testing.T) {
+	cfg := &Config{settings: map[string]interface{}{
+		"server": map[string]interface{}{"port": "8080"},
+		"database": map[string]interface{}{
+			"password": "secret",
+			"dsn":      "",
+		},
+		"alerting": map[string]interface{}{
+			"channels": []interface{}{
+				map[string]interface{}{"type": "webhook", "url": "https://hooks.example.com/T0/abc"},
+			},
+		},
+	}}
+
+	effective := cfg.Effective()
+	assert.Equal(t, "8080", effective["server"].(map[string]interface{})["port"])
+
+	database := effective["database"].(map[string]interface{})
+	assert.Equal(t, maskedValue, database["password"])
+	assert.Equal(t, "", database["dsn"], "empty secrets show they are unset")
+
+	channel := effective["alerting"].(map[string]interface{})["channels"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, maskedValue, channel["url"])
+	assert.Equal(t, "webhook", channel["type"])
+
+	assert.Equal(t, "secret", cfg.settings["database"].(map[string]interface{})["password"], "settings are not modified")
+}
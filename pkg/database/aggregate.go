@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// aggregateGroups are the expressions of the fields aggregates group by
+var aggregateGroups = map[string]string{
+	"log_type":    "COALESCE(log_type, '')",
+	"method":      "COALESCE(method, '')",
+	"path":        "COALESCE(path, '')",
+	"status_code": "COALESCE(status_code, 0)",
+	"source_ip":   "COALESCE(source_ip, '')",
+}
+
+// aggregateMetrics are the expressions of the metrics aggregates compute
+var aggregateMetrics = map[string]string{
+	"count":               "COUNT(*)",
+	"errors":              "SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END)",
+	"unique_ips":          "COUNT(DISTINCT NULLIF(source_ip, ''))",
+	"avg_processing_time": "COALESCE(AVG(processing_time), 0)",
+	"max_processing_time": "COALESCE(MAX(processing_time), 0)",
+	"avg_response_size":   "COALESCE(AVG(response_size), 0)",
+	"sum_response_size":   "COALESCE(SUM(response_size), 0)",
+}
+
+// AggregateLogs computes the query's metrics over groups of the entries
+// matching its filter
+func (d *Database) AggregateLogs(ctx context.Context, query *models.AggregateQuery) ([]models.AggregateRow, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("no aggregate metrics")
+	}
+	if query.Interval > 0 && query.Interval < time.Second {
+		return nil, fmt.Errorf("aggregate interval under a second: %s", query.Interval)
+	}
+	// Whole hours are read from the TimescaleDB roll-up when it keeps
+	// everything the query needs
+	table, timeColumn, groupExpressions, metricExpressions := "log_entries", "timestamp", aggregateGroups, aggregateMetrics
+	if d.timescale && rollupAggregatable(query) {
+		table, timeColumn, groupExpressions, metricExpressions = entryRollup, "bucket", rollupGroups, rollupMetrics
+	}
+
+	// Expressions are only taken from the maps, never from the query
+	var columns, groups, order []string
+	bucketed := query.Interval > 0
+	if bucketed {
+		bucket := d.epochBucket(timeColumn, int64(query.Interval/time.Second))
+		columns = append(columns, bucket+" AS bucket")
+		groups = append(groups, bucket)
+		order = append(order, "bucket")
+	}
+	for i, field := range query.GroupBy {
+		expression, ok := groupExpressions[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown aggregate group: %s", field)
+		}
+		alias := "g" + strconv.Itoa(i)
+		columns = append(columns, expression+" AS "+alias)
+		groups = append(groups, expression)
+	}
+	for i, metric := range query.Metrics {
+		expression, ok := metricExpressions[metric]
+		if !ok {
+			return nil, fmt.Errorf("unknown aggregate metric: %s", metric)
+		}
+		columns = append(columns, expression+" AS m"+strconv.Itoa(i))
+	}
+	order = append(order, "m0 DESC")
+	for i := range query.GroupBy {
+		order = append(order, "g"+strconv.Itoa(i))
+	}
+
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	q := selectFrom(table, columns...)
+	if table == entryRollup {
+		rollupFilterQuery(q, &query.Filter)
+	} else {
+		filterQuery(d.dialect(), q, &query.Filter)
+	}
+	if len(groups) > 0 {
+		q.groupBy(strings.Join(groups, ", "))
+	}
+	if query.Limit > 0 {
+		q.limit(query.Limit)
+	}
+	rows, err := d.queryContext(ctx, q.orderBy(strings.Join(order, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate logs: %w", err)
+	}
+	defer rows.Close()
+
+	var result []models.AggregateRow
+	for rows.Next() {
+		var bucket int64
+		groupValues := make([]interface{}, len(query.GroupBy))
+		metricValues := make([]float64, len(query.Metrics))
+		dest := make([]interface{}, 0, 1+len(groupValues)+len(metricValues))
+		if bucketed {
+			dest = append(dest, &bucket)
+		}
+		for i, field := range query.GroupBy {
+			if field == "status_code" {
+				groupValues[i] = new(int)
+			} else {
+				groupValues[i] = new(string)
+			}
+			dest = append(dest, groupValues[i])
+		}
+		for i := range metricValues {
+			dest = append(dest, &metricValues[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+
+		row := models.AggregateRow{Group: make(map[string]interface{}), Metrics: make(map[string]float64)}
+		if bucketed {
+			start := time.Unix(bucket, 0).UTC()
+			row.Bucket = &start
+		}
+		for i, field := range query.GroupBy {
+			switch value := groupValues[i].(type) {
+			case *int:
+				row.Group[field] = *value
+			case *string:
+				row.Group[field] = *value
+			}
+		}
+		for i, metric := range query.Metrics {
+			row.Metrics[metric] = metricValues[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetAlertRules returns alert rules, optionally restricted to active ones
+func (d *Database) GetAlertRules(activeOnly bool) ([]*models.AlertRule, error) {
+	query := `SELECT id, name, COALESCE(description, ''), condition_type, threshold_value,
+		time_window, for_duration, recovery_threshold, expression, is_active, created_at, updated_at
+		FROM alert_rules`
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY id"
+
+	rows, err := d.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.AlertRule
+	for rows.Next() {
+		var rule models.AlertRule
+		var recovery sql.NullFloat64
+		if err := rows.Scan(
+			&rule.ID, &rule.Name, &rule.Description, &rule.ConditionType,
+			&rule.ThresholdValue, &rule.TimeWindow, &rule.ForDuration, &recovery,
+			&rule.Expression, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		if recovery.Valid {
+			rule.RecoveryThreshold = &recovery.Float64
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// CreateAlertRule stores a new alert rule and sets its ID
+func (d *Database) CreateAlertRule(rule *models.AlertRule) error {
+	query := `INSERT INTO alert_rules (name, description, condition_type, threshold_value,
+		time_window, for_duration, recovery_threshold, expression, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := d.insertReturningID(query,
+		rule.Name, rule.Description, rule.ConditionType, rule.ThresholdValue,
+		rule.TimeWindow, rule.ForDuration, rule.RecoveryThreshold, rule.Expression, rule.IsActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	rule.ID = id
+	return nil
+}
+
+// UpdateAlertRule replaces the stored rule with the same ID, returning
+// sql.ErrNoRows if there is none
+func (d *Database) UpdateAlertRule(rule *models.AlertRule) error {
+	query := d.rebind(`UPDATE alert_rules SET name = ?, description = ?, condition_type = ?,
+		threshold_value = ?, time_window = ?, for_duration = ?, recovery_threshold = ?,
+		expression = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`)
+
+	result, err := d.DB.Exec(query,
+		rule.Name, rule.Description, rule.ConditionType, rule.ThresholdValue,
+		rule.TimeWindow, rule.ForDuration, rule.RecoveryThreshold, rule.Expression, rule.IsActive,
+		rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// MySQL counts only rows that changed, so an update repeating the
+	// rule within a second affects none
+	var exists int
+	err = d.DB.QueryRow(d.rebind(`SELECT 1 FROM alert_rules WHERE id = ?`), rule.ID).Scan(&exists)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return err
+}
+
+// InsertAlertEvent records a fired alert in alert_history
+func (d *Database) InsertAlertEvent(event *models.AlertEvent) error {
+	query := `INSERT INTO alert_history (rule_id, message, severity, triggered_at) VALUES (?, ?, ?, ?)`
+
+	id, err := d.insertReturningID(query, event.RuleID, event.Message, event.Severity, event.TriggeredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert alert event: %w", err)
+	}
+
+	event.ID = id
+	return nil
+}
+
+// GetAlertHistory returns the most recent fired alerts
+func (d *Database) GetAlertHistory(limit int) ([]*models.AlertEvent, error) {
+	query := d.rebind(`SELECT h.id, h.rule_id, COALESCE(r.name, ''), h.message, h.severity, h.triggered_at,
+			h.acknowledged_at, COALESCE(h.acknowledged_by, '')
+		FROM alert_history h LEFT JOIN alert_rules r ON r.id = h.rule_id
+		ORDER BY h.triggered_at DESC LIMIT ?`)
+
+	rows, err := d.DB.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.AlertEvent
+	for rows.Next() {
+		var event models.AlertEvent
+		if err := rows.Scan(
+			&event.ID, &event.RuleID, &event.RuleName,
+			&event.Message, &event.Severity, &event.TriggeredAt,
+			&event.AcknowledgedAt, &event.AcknowledgedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan alert event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// AcknowledgeAlertEvent marks a fired alert as acknowledged. It returns
+// sql.ErrNoRows if the alert does not exist or was already acknowledged.
+func (d *Database) AcknowledgeAlertEvent(id int64, by string, at time.Time) error {
+	query := d.rebind(`UPDATE alert_history SET acknowledged_at = ?, acknowledged_by = ?
+		WHERE id = ? AND acknowledged_at IS NULL`)
+
+	result, err := d.DB.Exec(query, at, by, id)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetTopOffenders returns the paths and source IPs with the most requests
+// ingested since the given time, optionally restricted to a minimum status code
+func (d *Database) GetTopOffenders(since time.Time, minStatus, limit int) ([]models.PathStats, []models.IPStats, error) {
+	pathQuery := d.rebind(`SELECT path, COUNT(*) AS hits FROM log_entries
+		WHERE created_at >= ? AND status_code >= ?
+		GROUP BY path ORDER BY hits DESC LIMIT ?`)
+
+	rows, err := d.DB.Query(pathQuery, since, minStatus, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query top paths: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []models.PathStats
+	for rows.Next() {
+		var stat models.PathStats
+		if err := rows.Scan(&stat.Path, &stat.Count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan top path: %w", err)
+		}
+		paths = append(paths, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ipQuery := d.rebind(`SELECT source_ip, COUNT(*) AS hits FROM log_entries
+		WHERE created_at >= ? AND status_code >= ?
+		GROUP BY source_ip ORDER BY hits DESC LIMIT ?`)
+
+	ipRows, err := d.DB.Query(ipQuery, since, minStatus, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query top IPs: %w", err)
+	}
+	defer ipRows.Close()
+
+	var ips []models.IPStats
+	for ipRows.Next() {
+		var stat models.IPStats
+		if err := ipRows.Scan(&stat.IP, &stat.Count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan top IP: %w", err)
+		}
+		ips = append(ips, stat)
+	}
+
+	return paths, ips, ipRows.Err()
+}
+
+// insertReturningID executes an INSERT and returns the generated primary key.
+// lib/pq does not support LastInsertId, so Postgres uses RETURNING instead.
+func (d *Database) insertReturningID(query string, args ...interface{}) (int64, error) {
+	query = d.rebind(query)
+
+	if d.dialect() == postgresDialect {
+		var id int64
+		err := d.DB.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := d.DB.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+const auditColumns = `seq, recorded_at, action, actor, subject, COALESCE(details, ''), prev_hash, hash`
+
+// AppendAuditRecord seals the record onto the end of the audit chain and
+// stores it. Records are never updated or deleted.
+func (d *Database) AppendAuditRecord(record *models.AuditRecord) error {
+	d.auditMu.Lock()
+	defer d.auditMu.Unlock()
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	defer tx.Rollback()
+
+	// seq is the primary key, so another server appending concurrently
+	// makes one insert fail instead of forking the chain
+	row := tx.QueryRow(`SELECT ` + auditColumns + ` FROM audit_log ORDER BY seq DESC LIMIT 1`)
+	prev, err := scanAuditRecord(row)
+	if err == sql.ErrNoRows {
+		prev = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read audit chain: %w", err)
+	}
+	audit.Seal(record, prev)
+
+	var details interface{}
+	if len(record.Details) > 0 {
+		details = string(record.Details)
+	}
+	query := d.rebind(`INSERT INTO audit_log (seq, recorded_at, action, actor, subject, details, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if _, err := tx.Exec(query, record.Seq, record.RecordedAt, record.Action, record.Actor,
+		record.Subject, details, record.PrevHash, record.Hash); err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return nil
+}
+
+// GetAuditRecords returns up to limit records after afterSeq, in order
+func (d *Database) GetAuditRecords(afterSeq int64, limit int) ([]*models.AuditRecord, error) {
+	query := d.rebind(`SELECT ` + auditColumns + ` FROM audit_log WHERE seq > ? ORDER BY seq LIMIT ?`)
+
+	rows, err := d.DB.Query(query, afterSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*models.AuditRecord
+	for rows.Next() {
+		record, err := scanAuditRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// GetAuditRecord returns the record with the given sequence number, or
+// sql.ErrNoRows
+func (d *Database) GetAuditRecord(seq int64) (*models.AuditRecord, error) {
+	row := d.DB.QueryRow(d.rebind(`SELECT `+auditColumns+` FROM audit_log WHERE seq = ?`), seq)
+	record, err := scanAuditRecord(row)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get audit record: %w", err)
+	}
+	return record, err
+}
+
+// scanAuditRecord scans a row selected with auditColumns
+func scanAuditRecord(row interface{ Scan(...interface{}) error }) (*models.AuditRecord, error) {
+	var record models.AuditRecord
+	var details string
+	if err := row.Scan(&record.Seq, &record.RecordedAt, &record.Action, &record.Actor,
+		&record.Subject, &details, &record.PrevHash, &record.Hash); err != nil {
+		return nil, err
+	}
+	if details != "" {
+		record.Details = []byte(details)
+	}
+	return &record, nil
+}
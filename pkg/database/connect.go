@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// iamTokenLifetime is how long a token is reused. RDS accepts tokens for
+// 15 minutes; only new connections need one.
+const iamTokenLifetime = 10 * time.Minute
+
+// tlsVersions are the accepted database tls min_version settings
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newConnector connects to the configured database with its params and
+// TLS settings applied
+func newConnector(cfg *config.Config) (driver.Connector, error) {
+	db := cfg.Database
+
+	var tokens *iamTokenSource
+	if db.IAMAuth.Enabled {
+		tokens = &iamTokenSource{
+			endpoint: net.JoinHostPort(db.Host, strconv.Itoa(db.Port)),
+			region:   db.IAMAuth.Region,
+			user:     db.Username,
+		}
+	}
+
+	switch db.Type {
+	case "mysql":
+		return newMySQLConnector(cfg, tokens)
+	case "postgres":
+		return newPostgresConnector(cfg, tokens)
+	case "sqlite":
+		return newSQLiteConnector(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", db.Type)
+	}
+}
+
+// tokenConnector opens connections with a fresh IAM token as the password
+type tokenConnector struct {
+	tokens *iamTokenSource
+	open   func(password string) (driver.Connector, error)
+	driver driver.Driver
+}
+
+func (c *tokenConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	password, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := c.open(password)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *tokenConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func newMySQLConnector(cfg *config.Config, tokens *iamTokenSource) (driver.Connector, error) {
+	mc, err := mysqlConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		return mysql.NewConnector(mc)
+	}
+
+	if mc.TLS == nil {
+		return nil, fmt.Errorf("database iam_auth requires TLS")
+	}
+	// RDS receives IAM tokens through the cleartext plugin
+	mc.AllowCleartextPasswords = true
+	return &tokenConnector{
+		tokens: tokens,
+		open: func(password string) (driver.Connector, error) {
+			withToken := mc.Clone()
+			withToken.Passwd = password
+			return mysql.NewConnector(withToken)
+		},
+		driver: mysql.MySQLDriver{},
+	}, nil
+}
+
+// mysqlConfig parses the DSN with params and TLS settings applied
+func mysqlConfig(cfg *config.Config) (*mysql.Config, error) {
+	db := cfg.Database
+	dsn := cfg.GetDSN()
+	if db.Params != "" {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + db.Params
+	}
+
+	mc, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mysql DSN: %w", err)
+	}
+	// Timestamps are scanned into time.Time
+	mc.ParseTime = true
+	if mc.Timeout == 0 {
+		mc.Timeout = time.Duration(db.DialTimeout) * time.Second
+	}
+
+	// A DSN chooses TLS with its own tls parameter
+	if db.DSN == "" {
+		if mc.TLS, err = mysqlTLS(db.SSLMode); err != nil {
+			return nil, err
+		}
+	}
+	if mc.TLS != nil {
+		if err := loadTLSFiles(mc.TLS, db.TLS); err != nil {
+			return nil, err
+		}
+		if db.TLS.ServerName != "" {
+			mc.TLS.ServerName = db.TLS.ServerName
+		}
+		if version, ok := tlsVersions[db.TLS.MinVersion]; ok {
+			mc.TLS.MinVersion = version
+		}
+	}
+	return mc, nil
+}
+
+// mysqlTLS maps the postgres style ssl_mode onto the MySQL driver
+func mysqlTLS(sslMode string) (*tls.Config, error) {
+	switch sslMode {
+	case "", "disable":
+		return nil, nil
+	case "require":
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	case "verify-ca":
+		// The chain is verified below, but not the host name
+		config := &tls.Config{InsecureSkipVerify: true}
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("database server sent no certificate")
+			}
+			opts := x509.VerifyOptions{Roots: config.RootCAs, Intermediates: x509.NewCertPool()}
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(opts)
+			return err
+		}
+		return config, nil
+	case "verify-full":
+		return &tls.Config{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database ssl_mode: %s", sslMode)
+	}
+}
+
+// loadTLSFiles adds the configured CA bundle and client certificate
+func loadTLSFiles(config *tls.Config, files config.DatabaseTLSConfig) error {
+	if files.CAFile != "" {
+		pem, err := os.ReadFile(files.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read database CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("database CA file %s contains no certificates", files.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if files.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load database client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}
+
+func newPostgresConnector(cfg *config.Config, tokens *iamTokenSource) (driver.Connector, error) {
+	dsn, err := postgresDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		return pq.NewConnector(dsn)
+	}
+
+	return &tokenConnector{
+		tokens: tokens,
+		open: func(password string) (driver.Connector, error) {
+			return pq.NewConnector(appendPostgresParams(dsn, url.Values{"password": {password}}))
+		},
+		driver: &pq.Driver{},
+	}, nil
+}
+
+// postgresDSN returns the DSN in key/value form with params and TLS files
+// appended. Later settings override earlier ones of the same name.
+func postgresDSN(cfg *config.Config) (string, error) {
+	db := cfg.Database
+	dsn := cfg.GetDSN()
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			return "", fmt.Errorf("invalid postgres DSN: %w", err)
+		}
+	}
+
+	params, err := url.ParseQuery(db.Params)
+	if err != nil {
+		return "", fmt.Errorf("invalid database params: %w", err)
+	}
+	if db.DialTimeout > 0 && !params.Has("connect_timeout") && !strings.Contains(dsn, "connect_timeout=") {
+		params.Set("connect_timeout", strconv.Itoa(db.DialTimeout))
+	}
+	for name, value := range map[string]string{"sslrootcert": db.TLS.CAFile, "sslcert": db.TLS.CertFile, "sslkey": db.TLS.KeyFile} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	return appendPostgresParams(dsn, params), nil
+}
+
+// appendPostgresParams adds key=value settings to a key/value DSN
+func appendPostgresParams(dsn string, params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(params.Get(name))
+		fmt.Fprintf(&b, " %s='%s'", name, value)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// iamTokenSource creates RDS IAM tokens and reuses each for a while
+type iamTokenSource struct {
+	endpoint string
+	region   string
+	user     string
+
+	mu          sync.Mutex
+	credentials aws.CredentialsProvider
+	token       string
+	expires     time.Time
+}
+
+// Token returns a valid token, creating one when the last is too old.
+// Credentials are found when the first token is needed.
+func (s *iamTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Before(s.expires) {
+		return s.token, nil
+	}
+	if s.credentials == nil {
+		cfg, err := awsauth.Config(ctx, s.region, awsauth.Credentials{})
+		if err != nil {
+			return "", err
+		}
+		s.credentials = cfg.Credentials
+	}
+	token, err := awsauth.RDSAuthToken(ctx, s.endpoint, s.region, s.user, s.credentials)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expires = token, now.Add(iamTokenLifetime)
+	return s.token, nil
+}
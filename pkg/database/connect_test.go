@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:     "postgres",
+		Host:     "db.example.com",
+		Port:     5432,
+		Username: "loguser",
+		Database: "log analyzer",
+		SSLMode:  "verify-full",
+		Params:   "connect_timeout=10&application_name=it's",
+		TLS:      config.DatabaseTLSConfig{CAFile: "/etc/ssl/rds-ca.pem"},
+	}}
+
+	dsn, err := postgresDSN(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `host='db.example.com' port=5432 user='loguser' password='' dbname='log analyzer' sslmode='verify-full'`+
+		` application_name='it\'s' connect_timeout='10' sslrootcert='/etc/ssl/rds-ca.pem'`, dsn)
+	_, err = pq.NewConnector(dsn)
+	assert.NoError(t, err)
+
+	// A URL DSN is converted so settings can be appended
+	cfg.Database.DSN = "postgres://app@/logs?host=/cloudsql/project:region:instance"
+	cfg.Database.Params = ""
+	cfg.Database.TLS = config.DatabaseTLSConfig{}
+	dsn, err = postgresDSN(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "host='/cloudsql/project:region:instance'")
+	assert.Contains(t, dsn, "user='app'")
+	assert.NotContains(t, dsn, "db.example.com")
+
+	// The dial timeout is added unless the DSN or params set one
+	cfg.Database.DialTimeout = 15
+	dsn, err = postgresDSN(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "connect_timeout='15'")
+	cfg.Database.DSN = "host=db.example.com connect_timeout=3"
+	dsn, err = postgresDSN(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "host=db.example.com connect_timeout=3", dsn)
+}
+
+func TestMySQLConfig(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:     "mysql",
+		Host:     "db.example.com",
+		Port:     3306,
+		Username: "loguser",
+		Password: "secret",
+		Database: "log_analyzer",
+		SSLMode:  "verify-full",
+		Params:   "timeout=5s",
+		TLS:      config.DatabaseTLSConfig{ServerName: "proxy.example.com", MinVersion: "1.3"},
+		// The timeout in params wins
+		DialTimeout: 10,
+	}}
+
+	mc, err := mysqlConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com:3306", mc.Addr)
+	assert.Equal(t, 5*time.Second, mc.Timeout)
+	assert.True(t, mc.ParseTime)
+	require.NotNil(t, mc.TLS)
+	assert.False(t, mc.TLS.InsecureSkipVerify)
+	assert.Equal(t, "proxy.example.com", mc.TLS.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), mc.TLS.MinVersion)
+
+	cfg.Database.SSLMode = "disable"
+	cfg.Database.Params = ""
+	mc, err = mysqlConfig(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, mc.TLS)
+	assert.Equal(t, 10*time.Second, mc.Timeout)
+
+	// A DSN is used as written, with its own TLS choice
+	cfg.Database.SSLMode = "verify-full"
+	cfg.Database.DSN = "app@unix(/cloudsql/project:region:instance)/logs?tls=skip-verify"
+	cfg.Database.Params = ""
+	mc, err = mysqlConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", mc.Net)
+	assert.Equal(t, "logs", mc.DBName)
+	assert.True(t, mc.ParseTime)
+	require.NotNil(t, mc.TLS)
+	assert.True(t, mc.TLS.InsecureSkipVerify)
+
+	cfg.Database.DSN = ""
+	cfg.Database.TLS.CAFile = "/nonexistent/ca.pem"
+	_, err = mysqlConfig(cfg)
+	assert.ErrorContains(t, err, "CA file")
+}
+
+func TestIAMTokenSource(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	ctx := context.Background()
+	tokens := &iamTokenSource{endpoint: "db.example.com:5432", region: "eu-west-1", user: "loguser"}
+	token, err := tokens.Token(ctx)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "db.example.com:5432?Action=connect&DBUser=loguser&"), token)
+	again, _ := tokens.Token(ctx)
+	assert.Equal(t, token, again, "tokens are reused until they are old")
+
+	tokens.expires = time.Now().Add(-time.Second)
+	tokens.token = "expired"
+	again, err = tokens.Token(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, "expired", again)
+}
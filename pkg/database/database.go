@@ -3,185 +3,171 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 )
 
+func init() {
+	open := func(cfg *config.Config) (storage.Storage, error) {
+		return NewDatabase(cfg)
+	}
+	storage.Register("mysql", open)
+	storage.Register("postgres", open)
+	storage.Register("sqlite", open)
+}
+
+// Database is the MySQL, PostgreSQL and SQLite storage backend
 type Database struct {
 	DB     *sql.DB
 	Config *config.Config
+
+	// auditMu serializes appends to the audit chain
+	auditMu sync.Mutex
+	// versionMu serializes numbering configuration versions
+	versionMu sync.Mutex
+	// timescale is set when log_entries is a TimescaleDB hypertable
+	timescale bool
+	// partitions are the partitions of log_entries by name, nil unless it
+	// is partitioned
+	partitionMu sync.Mutex
+	partitions  map[string]partition
 }
 
+var _ storage.Storage = (*Database)(nil)
+
+// NewDatabase connects to the configured database and brings its schema
+// up to date
 func NewDatabase(cfg *config.Config) (*Database, error) {
-	db, err := sql.Open(cfg.GetDriverName(), cfg.GetDSN())
+	database, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize schema
+	if err := database.InitSchema(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return database, nil
+}
+
+// Connect connects to the configured database without touching its schema
+func Connect(cfg *config.Config) (*Database, error) {
+	connector, err := newConnector(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db := sql.OpenDB(connector)
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	pool := cfg.Database.Pool
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(time.Duration(pool.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(pool.ConnMaxIdleTime) * time.Second)
 
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{
+	return &Database{
 		DB:     db,
 		Config: cfg,
+	}, nil
+}
+
+// InitSchema applies pending migrations, or with auto_migrate off refuses to
+// run against a schema that is behind, and sets up TimescaleDB or
+// partitioning
+func (d *Database) InitSchema() error {
+	if d.Config.Database.AutoMigrate {
+		if _, err := d.Migrate(); err != nil {
+			return err
+		}
+	} else {
+		pending, err := d.PendingMigrations()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d schema migrations are pending, starting with %04d_%s; run the migrate command to apply them",
+				len(pending), pending[0].Version, pending[0].Name)
+		}
 	}
 
-	// Initialize schema
-	if err := database.InitSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	if d.dialect() == postgresDialect {
+		if err := d.setupTimescale(); err != nil {
+			return err
+		}
 	}
+	return d.setupPartitions()
+}
 
-	return database, nil
+// schemaColumn describes a column added after its table was first released
+type schemaColumn struct {
+	table    string
+	column   string
+	mysql    string
+	postgres string
 }
 
-func (d *Database) InitSchema() error {
-	switch d.Config.Database.Type {
-	case "mysql":
-		return d.initMySQLSchema()
-	case "postgres":
-		return d.initPostgreSQLSchema()
-	default:
-		return fmt.Errorf("unsupported database type: %s", d.Config.Database.Type)
-	}
+var addedColumns = []schemaColumn{
+	{"alert_rules", "for_duration", "INT NOT NULL DEFAULT 0", "INTEGER NOT NULL DEFAULT 0"},
+	{"alert_rules", "recovery_threshold", "DOUBLE NULL", "DOUBLE PRECISION NULL"},
+	{"alert_rules", "expression", "JSON NULL", "JSONB NULL"},
+	{"alert_history", "acknowledged_at", "DATETIME NULL", "TIMESTAMP NULL"},
+	{"alert_history", "acknowledged_by", "VARCHAR(100) NULL", "VARCHAR(100) NULL"},
 }
 
-func (d *Database) initMySQLSchema() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS log_entries (
-			id BIGINT AUTO_INCREMENT PRIMARY KEY,
-			timestamp DATETIME NOT NULL,
-			log_type VARCHAR(20) NOT NULL,
-			source_ip VARCHAR(45) NOT NULL,
-			method VARCHAR(10),
-			path TEXT,
-			status_code INT,
-			response_size BIGINT,
-			user_agent TEXT,
-			referer TEXT,
-			processing_time DOUBLE,
-			raw_log LONGTEXT,
-			metadata JSON,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-			INDEX idx_timestamp (timestamp),
-			INDEX idx_log_type (log_type),
-			INDEX idx_source_ip (source_ip),
-			INDEX idx_status_code (status_code),
-			INDEX idx_method (method)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
-		
-		`CREATE TABLE IF NOT EXISTS log_stats_cache (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			stat_type VARCHAR(50) NOT NULL,
-			stat_data JSON NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-			UNIQUE KEY unique_stat_type (stat_type)
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
-		
-		`CREATE TABLE IF NOT EXISTS alert_rules (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			name VARCHAR(100) NOT NULL,
-			description TEXT,
-			condition_type VARCHAR(20) NOT NULL,
-			threshold_value DOUBLE NOT NULL,
-			time_window INT NOT NULL,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
-		
-		`CREATE TABLE IF NOT EXISTS alert_history (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			rule_id INT NOT NULL,
-			message TEXT NOT NULL,
-			severity VARCHAR(20) NOT NULL,
-			triggered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
-		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.DB.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %s, error: %w", query, err)
+// upgradeSchema adds columns that CREATE TABLE IF NOT EXISTS cannot add to
+// tables created by an older version
+func (d *Database) upgradeSchema(q execer) error {
+	// SQLite support postdates every added column
+	if d.dialect() == sqliteDialect {
+		return nil
+	}
+	for _, col := range addedColumns {
+		definition := col.mysql
+		if d.dialect() == postgresDialect {
+			definition = col.postgres
+		}
+		if err := d.ensureColumn(q, col.table, col.column, definition); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-func (d *Database) initPostgreSQLSchema() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS log_entries (
-			id BIGSERIAL PRIMARY KEY,
-			timestamp TIMESTAMP NOT NULL,
-			log_type VARCHAR(20) NOT NULL,
-			source_ip INET NOT NULL,
-			method VARCHAR(10),
-			path TEXT,
-			status_code INTEGER,
-			response_size BIGINT,
-			user_agent TEXT,
-			referer TEXT,
-			processing_time DOUBLE PRECISION,
-			raw_log TEXT,
-			metadata JSONB,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		
-		`CREATE INDEX IF NOT EXISTS idx_log_entries_timestamp ON log_entries(timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_log_entries_log_type ON log_entries(log_type)`,
-		`CREATE INDEX IF NOT EXISTS idx_log_entries_source_ip ON log_entries(source_ip)`,
-		`CREATE INDEX IF NOT EXISTS idx_log_entries_status_code ON log_entries(status_code)`,
-		`CREATE INDEX IF NOT EXISTS idx_log_entries_method ON log_entries(method)`,
-		
-		`CREATE TABLE IF NOT EXISTS log_stats_cache (
-			id SERIAL PRIMARY KEY,
-			stat_type VARCHAR(50) NOT NULL UNIQUE,
-			stat_data JSONB NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		
-		`CREATE TABLE IF NOT EXISTS alert_rules (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(100) NOT NULL,
-			description TEXT,
-			condition_type VARCHAR(20) NOT NULL,
-			threshold_value DOUBLE PRECISION NOT NULL,
-			time_window INTEGER NOT NULL,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		
-		`CREATE TABLE IF NOT EXISTS alert_history (
-			id SERIAL PRIMARY KEY,
-			rule_id INTEGER NOT NULL,
-			message TEXT NOT NULL,
-			severity VARCHAR(20) NOT NULL,
-			triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.DB.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %s, error: %w", query, err)
-		}
+func (d *Database) ensureColumn(q execer, table, column, definition string) error {
+	schemaFunc := "DATABASE()"
+	if d.dialect() == postgresDialect {
+		schemaFunc = "current_schema()"
 	}
 
+	var count int
+	query := d.rebind(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = ` + schemaFunc + ` AND table_name = ? AND column_name = ?`)
+	if err := q.QueryRow(query, table, column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect column %s.%s: %w", table, column, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	alter := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := q.Exec(alter); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
@@ -215,6 +201,8 @@ func (d *Database) GetStats() (map[string]interface{}, error) {
 		"total_logs":   totalLogs,
 		"total_size":   totalSize,
 		"database_type": d.Config.Database.Type,
+		"timescale":    d.timescale,
+		"partitioned":  d.isPartitionedNow(),
 		"connected":    true,
 	}, nil
 }
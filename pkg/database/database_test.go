@@ -0,0 +1,94 @@
+package database
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConformance runs the storage conformance suite against a scratch
+// database. It is skipped unless LOG_ANALYZER_TEST_DB_TYPE is set; the
+// suite empties every table of the database it is given.
+func TestConformance(t *testing.T) {
+	dbType := os.Getenv("LOG_ANALYZER_TEST_DB_TYPE")
+	if dbType == "" {
+		t.Skip("LOG_ANALYZER_TEST_DB_TYPE is not set")
+	}
+	port, _ := strconv.Atoi(os.Getenv("LOG_ANALYZER_TEST_DB_PORT"))
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:     dbType,
+		Host:     os.Getenv("LOG_ANALYZER_TEST_DB_HOST"),
+		Port:     port,
+		Username: os.Getenv("LOG_ANALYZER_TEST_DB_USER"),
+		Password: os.Getenv("LOG_ANALYZER_TEST_DB_PASSWORD"),
+		Database: os.Getenv("LOG_ANALYZER_TEST_DB_NAME"),
+		SSLMode:  "disable",
+		// The suite runs against the schema as migrated
+		AutoMigrate: true,
+	}}
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		db, err := NewDatabase(cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		for _, table := range []string{"audit_log", "config_versions", "alert_history", "alert_rules", "maintenance_windows", "latency_budgets", "log_entries", "ingested_lines", "ingested_files", "rule_evaluations", "report_files", "data_keys", "retention_policies", "report_templates", "saved_searches", "users", "traffic_rollups_hourly", "traffic_rollups_daily"} {
+			_, err := db.DB.Exec("DELETE FROM " + table)
+			require.NoError(t, err)
+		}
+		return db
+	})
+}
+
+func TestHourAligned(t *testing.T) {
+	hour := time.Date(2023, 10, 9, 14, 0, 0, 0, time.UTC)
+	assert.True(t, hourAligned(hour, hour.Add(24*time.Hour)))
+	assert.True(t, hourAligned(hour.In(time.FixedZone("UTC+2", 2*60*60)), hour.Add(time.Hour)))
+	assert.False(t, hourAligned(hour.Add(time.Minute), hour.Add(time.Hour)))
+	assert.False(t, hourAligned(hour, hour.Add(time.Hour+time.Nanosecond)))
+}
+
+func TestRollupAggregatable(t *testing.T) {
+	hour := time.Date(2023, 10, 9, 14, 0, 0, 0, time.UTC)
+	end := hour.Add(6 * time.Hour)
+	status := 404
+	query := func(modify func(*models.AggregateQuery)) *models.AggregateQuery {
+		q := &models.AggregateQuery{
+			Filter:   models.LogFilter{StartTime: &hour, EndTime: &end, LogType: "nginx", StatusCode: &status, Method: "GET"},
+			GroupBy:  []string{"log_type", "method", "status_code"},
+			Metrics:  []string{"count", "errors", "avg_processing_time", "max_processing_time", "avg_response_size", "sum_response_size"},
+			Interval: 2 * time.Hour,
+		}
+		modify(q)
+		return q
+	}
+
+	assert.True(t, rollupAggregatable(query(func(q *models.AggregateQuery) {})))
+	assert.True(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Interval = 0; q.Filter = models.LogFilter{} })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Interval = 5 * time.Minute })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Interval = 90 * time.Minute })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) {
+		start := hour.Add(time.Minute)
+		q.Filter.StartTime = &start
+	})))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Filter.Path = "/api" })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Filter.SourceIP = "10.0.0.1" })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.GroupBy = []string{"path"} })))
+	assert.False(t, rollupAggregatable(query(func(q *models.AggregateQuery) { q.Metrics = []string{"unique_ips"} })))
+}
+
+func TestEpochBucket(t *testing.T) {
+	d := &Database{Config: &config.Config{Database: config.DatabaseConfig{Type: "postgres"}}}
+	assert.Equal(t, "CAST(FLOOR(EXTRACT(EPOCH FROM timestamp) / 300) * 300 AS BIGINT)", d.epochBucket("timestamp", 300))
+
+	d.timescale = true
+	assert.Equal(t, "CAST(EXTRACT(EPOCH FROM time_bucket(INTERVAL '300 seconds', timestamp, TIMESTAMP '1970-01-01')) AS BIGINT)", d.epochBucket("timestamp", 300))
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetDataKey returns the data key of a project, or sql.ErrNoRows
+func (d *Database) GetDataKey(project string) (*models.DataKey, error) {
+	var key models.DataKey
+	err := d.DB.QueryRow(d.rebind(`SELECT project, wrapped_key, master_key_id, created_at
+		FROM data_keys WHERE project = ?`), project).
+		Scan(&key.Project, &key.WrappedKey, &key.MasterKeyID, &key.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return &key, nil
+}
+
+// CreateDataKey stores a project's data key unless it already has one, so
+// servers creating a key at once all end up using the first
+func (d *Database) CreateDataKey(key *models.DataKey) error {
+	query := `INSERT IGNORE INTO data_keys (project, wrapped_key, master_key_id, created_at) VALUES (?, ?, ?, ?)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO data_keys (project, wrapped_key, master_key_id, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (project) DO NOTHING`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), key.Project, key.WrappedKey, key.MasterKeyID, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create data key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// InsertRuleEvaluations records the outcomes of an evaluation of the rules
+// in one transaction
+func (d *Database) InsertRuleEvaluations(ctx context.Context, evaluations []models.RuleEvaluation) error {
+	if len(evaluations) == 0 {
+		return nil
+	}
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to insert rule evaluations: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, d.rebind(`INSERT INTO rule_evaluations
+		(rule_id, evaluated_at, value, threshold, state, details) VALUES (?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return fmt.Errorf("failed to insert rule evaluations: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, evaluation := range evaluations {
+		var details sql.NullString
+		if len(evaluation.Details) > 0 {
+			encoded, err := json.Marshal(evaluation.Details)
+			if err != nil {
+				return fmt.Errorf("failed to encode rule evaluation details: %w", err)
+			}
+			details = sql.NullString{String: string(encoded), Valid: true}
+		}
+		if _, err := stmt.ExecContext(ctx, evaluation.RuleID, evaluation.EvaluatedAt.UTC(), evaluation.Value,
+			evaluation.Threshold, evaluation.State, details); err != nil {
+			return fmt.Errorf("failed to insert rule evaluation: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetRuleEvaluations returns the last limit evaluations of a rule at or
+// after since, oldest first
+func (d *Database) GetRuleEvaluations(ctx context.Context, ruleID int64, since time.Time, limit int) ([]models.RuleEvaluation, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	q := selectFrom("rule_evaluations", "rule_id", "evaluated_at", "value", "threshold", "state", "details").
+		where("rule_id = ?", ruleID).where("evaluated_at >= ?", since.UTC()).
+		orderBy("evaluated_at DESC").limit(limit)
+	rows, err := d.queryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rule evaluations: %w", err)
+	}
+	defer rows.Close()
+
+	var evaluations []models.RuleEvaluation
+	for rows.Next() {
+		var evaluation models.RuleEvaluation
+		var details sql.NullString
+		if err := rows.Scan(&evaluation.RuleID, &evaluation.EvaluatedAt, &evaluation.Value,
+			&evaluation.Threshold, &evaluation.State, &details); err != nil {
+			return nil, fmt.Errorf("failed to scan rule evaluation: %w", err)
+		}
+		if details.Valid {
+			if err := json.Unmarshal([]byte(details.String), &evaluation.Details); err != nil {
+				return nil, fmt.Errorf("invalid details of rule evaluation: %w", err)
+			}
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.Reverse(evaluations)
+	return evaluations, nil
+}
+
+// DeleteRuleEvaluations removes evaluations made before cutoff
+func (d *Database) DeleteRuleEvaluations(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	result, err := d.DB.ExecContext(ctx, d.rebind(`DELETE FROM rule_evaluations WHERE evaluated_at < ?`), cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete rule evaluations: %w", err)
+	}
+	return result.RowsAffected()
+}
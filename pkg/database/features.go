@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetFeatureOverrides returns feature flag overrides ordered by flag and project
+func (d *Database) GetFeatureOverrides() ([]*models.FeatureOverride, error) {
+	rows, err := d.DB.Query(`SELECT flag, project, enabled, COALESCE(updated_by, ''), updated_at
+		FROM feature_overrides ORDER BY flag, project`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feature overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*models.FeatureOverride
+	for rows.Next() {
+		var override models.FeatureOverride
+		if err := rows.Scan(&override.Flag, &override.Project, &override.Enabled,
+			&override.UpdatedBy, &override.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature override: %w", err)
+		}
+		overrides = append(overrides, &override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// SetFeatureOverride stores an override, replacing any for the same flag
+// and project
+func (d *Database) SetFeatureOverride(override *models.FeatureOverride) error {
+	query := `INSERT INTO feature_overrides (flag, project, enabled, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE enabled = VALUES(enabled), updated_by = VALUES(updated_by), updated_at = VALUES(updated_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO feature_overrides (flag, project, enabled, updated_by, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (flag, project) DO UPDATE
+			SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), override.Flag, override.Project, override.Enabled,
+		override.UpdatedBy, override.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set feature override: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeatureOverride removes an override, reporting whether it existed
+func (d *Database) DeleteFeatureOverride(flag, project string) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM feature_overrides WHERE flag = ? AND project = ?`), flag, project)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete feature override: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete feature override: %w", err)
+	}
+	return affected > 0, nil
+}
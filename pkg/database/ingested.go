@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetIngestedFile returns the processed file with the given SHA-256, or
+// sql.ErrNoRows
+func (d *Database) GetIngestedFile(sha256 string) (*models.IngestedFile, error) {
+	var file models.IngestedFile
+	err := d.DB.QueryRow(d.rebind(`SELECT sha256, filename, log_type, size, ingested_at
+		FROM ingested_files WHERE sha256 = ?`), sha256).
+		Scan(&file.SHA256, &file.Filename, &file.LogType, &file.Size, &file.IngestedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingested file: %w", err)
+	}
+	return &file, nil
+}
+
+// RecordIngestedFile stores a processed file, keeping the first record of
+// a file processed more than once
+func (d *Database) RecordIngestedFile(file *models.IngestedFile) error {
+	query := `INSERT IGNORE INTO ingested_files (sha256, filename, log_type, size, ingested_at)
+		VALUES (?, ?, ?, ?, ?)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO ingested_files (sha256, filename, log_type, size, ingested_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (sha256) DO NOTHING`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), file.SHA256, file.Filename, file.LogType, file.Size, file.IngestedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record ingested file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetOrphanedAlertEvents counts fired alerts whose rule does not exist.
+// The foreign key prevents them unless it was disabled or dropped.
+func (d *Database) GetOrphanedAlertEvents() ([]models.OrphanedAlertEvents, error) {
+	rows, err := d.DB.Query(`SELECT h.rule_id, COUNT(*)
+		FROM alert_history h LEFT JOIN alert_rules r ON r.id = h.rule_id
+		WHERE r.id IS NULL GROUP BY h.rule_id ORDER BY h.rule_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned alert events: %w", err)
+	}
+	defer rows.Close()
+
+	var orphans []models.OrphanedAlertEvents
+	for rows.Next() {
+		var orphan models.OrphanedAlertEvents
+		if err := rows.Scan(&orphan.RuleID, &orphan.Events); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned alert events: %w", err)
+		}
+		orphans = append(orphans, orphan)
+	}
+
+	return orphans, rows.Err()
+}
+
+// GetRollupTotals compares the TimescaleDB roll-up with log_entries. Only
+// hours that either has entries for are returned.
+func (d *Database) GetRollupTotals(start, end time.Time) ([]models.RollupTotal, error) {
+	if !d.timescale {
+		return nil, nil
+	}
+
+	query := `SELECT COALESCE(r.bucket, e.bucket) AS hour, COALESCE(r.requests, 0), COALESCE(e.requests, 0)
+		FROM (SELECT bucket, SUM(requests)::BIGINT AS requests FROM ` + methodRollup + `
+			WHERE bucket >= $1 AND bucket < $2 GROUP BY bucket) r
+		FULL JOIN (SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, COUNT(*) AS requests
+			FROM log_entries
+			WHERE timestamp >= $1 AND timestamp < $2
+				AND method IS NOT NULL AND method <> '' AND status_code > 0
+			GROUP BY 1) e ON e.bucket = r.bucket
+		ORDER BY hour`
+
+	rows, err := d.DB.Query(query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roll-up totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []models.RollupTotal
+	for rows.Next() {
+		var total models.RollupTotal
+		if err := rows.Scan(&total.Bucket, &total.Rollup, &total.Raw); err != nil {
+			return nil, fmt.Errorf("failed to scan roll-up totals: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// CreateLatencyBudget stores a latency budget and sets its ID
+func (d *Database) CreateLatencyBudget(budget *models.LatencyBudget) error {
+	query := `INSERT INTO latency_budgets (path, percentile, threshold_ms, team, description)
+		VALUES (?, ?, ?, ?, ?)`
+
+	id, err := d.insertReturningID(query, budget.Path, budget.Percentile,
+		budget.ThresholdMs, budget.Team, budget.Description)
+	if err != nil {
+		return fmt.Errorf("failed to create latency budget: %w", err)
+	}
+
+	budget.ID = id
+	return nil
+}
+
+// GetLatencyBudgets returns every latency budget, ordered by path and
+// percentile
+func (d *Database) GetLatencyBudgets() ([]*models.LatencyBudget, error) {
+	rows, err := d.DB.Query(`SELECT id, path, percentile, threshold_ms, COALESCE(team, ''),
+		COALESCE(description, ''), created_at
+		FROM latency_budgets ORDER BY path, percentile, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latency budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []*models.LatencyBudget
+	for rows.Next() {
+		var budget models.LatencyBudget
+		if err := rows.Scan(
+			&budget.ID, &budget.Path, &budget.Percentile, &budget.ThresholdMs,
+			&budget.Team, &budget.Description, &budget.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan latency budget: %w", err)
+		}
+		budgets = append(budgets, &budget)
+	}
+
+	return budgets, rows.Err()
+}
+
+// DeleteLatencyBudget removes a latency budget, reporting whether it existed
+func (d *Database) DeleteLatencyBudget(id int64) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM latency_budgets WHERE id = ?`), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete latency budget: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete latency budget: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,367 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetLogMessages returns the most recent entries of the given log types in
+// [start, end) with only their timestamp, log type and message populated
+func (d *Database) GetLogMessages(logTypes []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	if len(logTypes) == 0 {
+		return nil, nil
+	}
+
+	rows, err := d.query(selectFrom("log_entries", "timestamp", "log_type", "COALESCE(path, '')").
+		where("log_type IN ("+placeholders(len(logTypes))+")", anySlice(logTypes)...).
+		where("timestamp >= ? AND timestamp < ?", start, end).
+		orderBy("timestamp DESC").limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log messages: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.Timestamp, &entry.LogType, &entry.Path); err != nil {
+			return nil, fmt.Errorf("failed to scan log message: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetSourceActivity returns the most recent entries with a source IP in
+// [start, end), oldest first, with the fields used to profile client behaviour
+func (d *Database) GetSourceActivity(start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	rows, err := d.query(selectFrom("log_entries", "timestamp", "source_ip", "COALESCE(path, '')",
+		"COALESCE(status_code, 0)", "COALESCE(user_agent, '')", "metadata").
+		where("source_ip IS NOT NULL AND source_ip <> ''").
+		where("timestamp >= ? AND timestamp < ?", start, end).
+		orderBy("timestamp DESC").limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source activity: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.Timestamp, &entry.SourceIP, &entry.Path, &entry.StatusCode,
+			&entry.UserAgent, &entry.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan source activity: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// GetUserAgentActivity returns entries in [start, end) whose user agent
+// contains any of the given substrings, case-insensitively, oldest first
+func (d *Database) GetUserAgentActivity(substrings []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	if len(substrings) == 0 {
+		return nil, nil
+	}
+
+	conditions := make([]string, len(substrings))
+	args := make([]interface{}, len(substrings))
+	for i, substring := range substrings {
+		conditions[i] = "LOWER(user_agent) LIKE ?"
+		args[i] = "%" + strings.ToLower(substring) + "%"
+	}
+
+	rows, err := d.query(selectFrom("log_entries", "timestamp", "source_ip", "COALESCE(path, '')",
+		"COALESCE(status_code, 0)", "COALESCE(response_size, 0)", "user_agent").
+		whereAny(conditions, args...).
+		where("timestamp >= ? AND timestamp < ?", start, end).
+		orderBy("timestamp DESC").limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user agent activity: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.Timestamp, &entry.SourceIP, &entry.Path, &entry.StatusCode,
+			&entry.ResponseSize, &entry.UserAgent); err != nil {
+			return nil, fmt.Errorf("failed to scan user agent activity: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// entryColumns selects every log_entries column scanEntries reads
+const entryColumns = `id, timestamp, log_type, source_ip, COALESCE(method, ''), COALESCE(path, ''),
+	COALESCE(status_code, 0), COALESCE(response_size, 0), COALESCE(user_agent, ''), COALESCE(referer, ''),
+	COALESCE(processing_time, 0), COALESCE(raw_log, ''), metadata, COALESCE(file_hash, ''), COALESCE(line_number, 0)`
+
+// queryEntries returns complete entries in [start, end) meeting any of the
+// conditions, the most recent limit of them, oldest first
+func (d *Database) queryEntries(conditions []string, args []interface{}, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	rows, err := d.query(selectFrom("log_entries", entryColumns).
+		whereAny(conditions, args...).
+		where("timestamp >= ? AND timestamp < ?", start, end).
+		orderBy("timestamp DESC").limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.LogType, &entry.SourceIP, &entry.Method,
+			&entry.Path, &entry.StatusCode, &entry.ResponseSize, &entry.UserAgent, &entry.Referer,
+			&entry.ProcessingTime, &entry.RawLog, &entry.Metadata, &entry.FileHash, &entry.LineNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// GetEntriesByTypeOrStatus returns complete entries in [start, end) that
+// are of one of the given log types or have one of the given status codes,
+// oldest first
+func (d *Database) GetEntriesByTypeOrStatus(logTypes []string, statusCodes []int, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	var conditions []string
+	args := make([]interface{}, 0, len(logTypes)+len(statusCodes))
+	if len(logTypes) > 0 {
+		conditions = append(conditions, "log_type IN ("+placeholders(len(logTypes))+")")
+		args = append(args, anySlice(logTypes)...)
+	}
+	if len(statusCodes) > 0 {
+		conditions = append(conditions, "status_code IN ("+placeholders(len(statusCodes))+")")
+		args = append(args, anySlice(statusCodes)...)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	return d.queryEntries(conditions, args, start, end, limit)
+}
+
+// GetEntriesByPathPrefix returns complete entries in [start, end) whose
+// path starts with any of the given prefixes, oldest first
+func (d *Database) GetEntriesByPathPrefix(prefixes []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	if len(prefixes) == 0 {
+		return nil, nil
+	}
+
+	conditions := make([]string, len(prefixes))
+	args := make([]interface{}, len(prefixes))
+	for i, prefix := range prefixes {
+		conditions[i] = d.dialect().like("path")
+		args[i] = escapeLike(prefix) + "%"
+	}
+	return d.queryEntries(conditions, args, start, end, limit)
+}
+
+// escapeLike escapes LIKE wildcards with a backslash, the escape dialect.like
+// uses
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// metadataText extracts a top-level metadata field as text
+func (d *Database) metadataText(key string) string {
+	switch d.dialect() {
+	case postgresDialect:
+		return "metadata->>'" + key + "'"
+	case sqliteDialect:
+		// Metadata is bound as bytes, which SQLite keeps as a blob
+		return "json_extract(CAST(metadata AS TEXT), '$." + key + "')"
+	}
+	return "JSON_UNQUOTE(JSON_EXTRACT(metadata, '$." + key + "'))"
+}
+
+// GetCountryActivity returns each client country found in the country
+// metadata field of entries in [since, end), with the requests and unique
+// IPs from start onwards. Countries first seen before start are known ones.
+func (d *Database) GetCountryActivity(since, start, end time.Time) ([]models.CountryActivity, error) {
+	query := d.rebind(`SELECT country, MIN(timestamp),
+		SUM(CASE WHEN timestamp >= ? THEN 1 ELSE 0 END),
+		COUNT(DISTINCT CASE WHEN timestamp >= ? THEN source_ip END)
+		FROM (SELECT ` + d.metadataText("country") + ` AS country, timestamp, source_ip FROM log_entries
+			WHERE timestamp >= ? AND timestamp < ?) countries
+		WHERE country IS NOT NULL AND country <> ''
+		GROUP BY country ORDER BY country`)
+
+	rows, err := d.DB.Query(query, start, start, since, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query country activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activity []models.CountryActivity
+	for rows.Next() {
+		var country models.CountryActivity
+		var firstSeen sqliteTime
+		if err := rows.Scan(&country.Country, &firstSeen, &country.Requests, &country.UniqueIPs); err != nil {
+			return nil, fmt.Errorf("failed to scan country activity: %w", err)
+		}
+		country.FirstSeen = firstSeen.Time
+		activity = append(activity, country)
+	}
+	return activity, rows.Err()
+}
+
+// GetMethodStats aggregates HTTP requests in [start, end) by method, or by
+// path and method when byPath is set, busiest first. logType and path
+// narrow the requests when not empty.
+func (d *Database) GetMethodStats(start, end time.Time, logType, path string, byPath bool, limit int) ([]models.MethodStats, error) {
+	columns, groupBy := "method", "method"
+	if byPath {
+		columns, groupBy = "COALESCE(path, ''), method", "path, method"
+	}
+
+	conditions := []string{"method IS NOT NULL", "method <> ''", "status_code > 0", "timestamp >= ?", "timestamp < ?"}
+	args := []interface{}{start, end}
+	if logType != "" {
+		conditions = append(conditions, "log_type = ?")
+		args = append(args, logType)
+	}
+	if path != "" {
+		conditions = append(conditions, "path = ?")
+		args = append(args, path)
+	}
+	args = append(args, limit)
+
+	// Entries without a recorded response time are left out of the averages
+	query := d.rebind(`SELECT ` + columns + `, COUNT(*) AS requests,
+		COALESCE(AVG(CASE WHEN processing_time > 0 THEN processing_time END), 0),
+		COALESCE(MAX(processing_time), 0),
+		SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END)
+		FROM log_entries WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY ` + groupBy + ` ORDER BY requests DESC LIMIT ?`)
+
+	// Whole hours by method are read from the TimescaleDB roll-up
+	if d.timescale && !byPath && path == "" && hourAligned(start, end) {
+		conditions = []string{"bucket >= ?", "bucket < ?"}
+		if logType != "" {
+			conditions = append(conditions, "log_type = ?")
+		}
+		query = d.rebind(`SELECT method, SUM(requests)::BIGINT AS requests,
+			COALESCE(SUM(timed_total) / NULLIF(SUM(timed_requests), 0), 0),
+			COALESCE(MAX(max_processing_time), 0),
+			SUM(errors)::BIGINT, SUM(server_errors)::BIGINT
+			FROM ` + methodRollup + ` WHERE ` + strings.Join(conditions, " AND ") + `
+			GROUP BY method ORDER BY requests DESC LIMIT ?`)
+	}
+
+	rows, err := d.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query method stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []models.MethodStats
+	for rows.Next() {
+		var stat models.MethodStats
+		var errors, serverErrors int64
+		dest := []interface{}{&stat.Method, &stat.Requests, &stat.AvgResponseTime, &stat.MaxResponseTime, &errors, &serverErrors}
+		if byPath {
+			dest = append([]interface{}{&stat.Path}, dest...)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan method stats: %w", err)
+		}
+		stat.ErrorRate = float64(errors) / float64(stat.Requests) * 100
+		stat.ServerErrorRate = float64(serverErrors) / float64(stat.Requests) * 100
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// InsertLogEntry stores an entry and sets its ID, or leaves it 0 if an
+// entry of the same file line is stored already
+func (d *Database) InsertLogEntry(entry *models.LogEntry) error {
+	return d.InsertBatch(context.Background(), []*models.LogEntry{entry})
+}
+
+// InsertLogEntries stores entries in one transaction and sets their IDs
+func (d *Database) InsertLogEntries(entries []*models.LogEntry) error {
+	return d.InsertBatch(context.Background(), entries)
+}
+
+// QueryLogs returns entries matching the filter, most recent first
+func (d *Database) QueryLogs(filter *models.LogFilter) ([]*models.LogEntry, error) {
+	return d.Find(context.Background(), filter)
+}
+
+// filterQuery narrows the query to entries that match the filter
+func filterQuery(dl dialect, q *selectQuery, filter *models.LogFilter) *selectQuery {
+	if filter.StartTime != nil {
+		q.where("timestamp >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		q.where("timestamp < ?", *filter.EndTime)
+	}
+	if filter.LogType != "" {
+		q.where("log_type = ?", filter.LogType)
+	}
+	if filter.StatusCode != nil {
+		q.where("status_code = ?", *filter.StatusCode)
+	}
+	if filter.MinStatusCode > 0 {
+		q.where("status_code >= ?", filter.MinStatusCode)
+	}
+	if filter.SourceIP != "" {
+		q.where("source_ip = ?", filter.SourceIP)
+	}
+	if filter.Path != "" && filter.ExactPath {
+		q.where("path = ?", filter.Path)
+	} else if filter.Path != "" {
+		q.where(dl.like("path"), "%"+escapeLike(filter.Path)+"%")
+	}
+	if filter.Method != "" {
+		q.where("method = ?", filter.Method)
+	}
+	return q
+}
+
+// facetConditions select the entries with a value of each facet field
+var facetConditions = map[string]string{
+	"log_type":    "log_type IS NOT NULL AND log_type <> ''",
+	"method":      "method IS NOT NULL AND method <> ''",
+	"path":        "path IS NOT NULL AND path <> ''",
+	"source_ip":   "source_ip IS NOT NULL AND source_ip <> ''",
+	"status_code": "status_code > 0",
+}
+
+// GetFacets counts entries matching the filter by the value of a field,
+// most common first
+func (d *Database) GetFacets(filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error) {
+	return d.Aggregate(context.Background(), filter, field, limit)
+}
+
+// DeleteLogsBefore removes entries older than cutoff
+func (d *Database) DeleteLogsBefore(cutoff time.Time) (int64, error) {
+	return d.DeleteOlderThan(context.Background(), cutoff)
+}
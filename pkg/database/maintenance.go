@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// CreateMaintenanceWindow stores a maintenance window and sets its ID
+func (d *Database) CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	query := `INSERT INTO maintenance_windows (name, description, starts_at, ends_at, silence_alerts)
+		VALUES (?, ?, ?, ?, ?)`
+
+	id, err := d.insertReturningID(query, window.Name, window.Description,
+		window.StartsAt, window.EndsAt, window.SilenceAlerts)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+
+	window.ID = id
+	return nil
+}
+
+// GetMaintenanceWindows returns the windows overlapping [start, end),
+// ordered by start time
+func (d *Database) GetMaintenanceWindows(start, end time.Time) ([]*models.MaintenanceWindow, error) {
+	query := d.rebind(`SELECT id, name, COALESCE(description, ''), starts_at, ends_at, silence_alerts, created_at
+		FROM maintenance_windows WHERE starts_at < ? AND ends_at > ?
+		ORDER BY starts_at`)
+
+	rows, err := d.DB.Query(query, end, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*models.MaintenanceWindow
+	for rows.Next() {
+		var window models.MaintenanceWindow
+		if err := rows.Scan(
+			&window.ID, &window.Name, &window.Description, &window.StartsAt,
+			&window.EndsAt, &window.SilenceAlerts, &window.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance window: %w", err)
+		}
+		windows = append(windows, &window)
+	}
+
+	return windows, rows.Err()
+}
+
+// DeleteMaintenanceWindow removes a maintenance window, reporting whether it existed
+func (d *Database) DeleteMaintenanceWindow(id int64) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM maintenance_windows WHERE id = ?`), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the migrations of each dialect as
+// migrations/<dialect>/NNNN_name.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFilePattern matches the name of a migration file
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// migrationsLock names the lock that keeps two servers from migrating the
+// same database at once
+const migrationsLock = "log_analyzer_migrations"
+
+// Migration is a versioned change to the schema
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// AppliedAt is when the migration was applied, or nil while it is pending
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+
+	statements []string
+}
+
+// execer runs statements on a *sql.DB or *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// loadMigrations reads the migrations of a dialect in version order
+func loadMigrations(dl dialect) ([]Migration, error) {
+	dir := path.Join("migrations", string(dl))
+	files, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database type %s: %w", dl, err)
+	}
+
+	var migrations []Migration
+	for _, file := range files {
+		match := migrationFilePattern.FindStringSubmatch(file.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", file.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(path.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version:    version,
+			Name:       match[2],
+			statements: splitStatements(string(content)),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// splitStatements splits a migration into its statements. A statement ends
+// with a semicolon at the end of a line; lines starting with -- are
+// comments.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// createMigrationsTable creates the table recording applied migrations
+func (d *Database) createMigrationsTable() error {
+	appliedAt := "DATETIME"
+	if d.dialect() == postgresDialect {
+		appliedAt = "TIMESTAMP"
+	}
+	_, err := d.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at ` + appliedAt + ` NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// Migrations returns every migration of the database type in version order,
+// with when it was applied
+func (d *Database) Migrations() ([]Migration, error) {
+	migrations, err := loadMigrations(d.dialect())
+	if err != nil {
+		return nil, err
+	}
+	if err := d.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range migrations {
+		if at, ok := applied[migrations[i].Version]; ok {
+			at := at
+			migrations[i].AppliedAt = &at
+		}
+	}
+	return migrations, nil
+}
+
+// PendingMigrations returns the migrations not yet applied
+func (d *Database) PendingMigrations() ([]Migration, error) {
+	migrations, err := d.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if m.AppliedAt == nil {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies the pending migrations in version order and returns
+// those it applied. Each migration runs in a transaction, although MySQL
+// commits DDL as it runs, and servers migrating the same database at once
+// wait for each other.
+func (d *Database) Migrate() ([]Migration, error) {
+	ctx := context.Background()
+	if err := d.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	unlock, err := d.lockMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	pending, err := d.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range pending {
+		if err := d.applyMigration(ctx, conn, &m); err != nil {
+			return applied, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if m.AppliedAt != nil {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// lockMigrations takes the migrations lock on conn. SQLite needs none, as
+// its transactions take the write lock when they begin.
+func (d *Database) lockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	switch d.dialect() {
+	case postgresDialect:
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", migrationsLock); err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		return func() {
+			conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", migrationsLock)
+		}, nil
+	case mysqlDialect:
+		var locked sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", migrationsLock).Scan(&locked); err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return nil, fmt.Errorf("timed out waiting for another server to finish migrating")
+		}
+		return func() {
+			conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationsLock)
+		}, nil
+	default:
+		return func() {}, nil
+	}
+}
+
+// applyMigration runs a migration and records it, setting its AppliedAt.
+// AppliedAt stays nil when another server applied it first.
+func (d *Database) applyMigration(ctx context.Context, conn *sql.Conn, m *Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow(d.rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), m.Version).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	// Databases created before migrations existed may lack columns added
+	// after their tables were, which the initial migration cannot add
+	if m.Version == 1 {
+		if err := d.upgradeSchema(tx); err != nil {
+			return err
+		}
+	}
+
+	at := time.Now().UTC().Truncate(time.Second)
+	if _, err := tx.Exec(d.rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		m.Version, m.Name, at); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	m.AppliedAt = &at
+	return nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- A comment; not a statement
+CREATE TABLE a (
+    id INTEGER, -- trailing; comment
+    note TEXT DEFAULT 'x;y'
+);
+
+CREATE INDEX idx_a ON a (id);
+INSERT INTO a (id) VALUES (1)`
+
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n    id INTEGER, -- trailing; comment\n    note TEXT DEFAULT 'x;y'\n)",
+		"CREATE INDEX idx_a ON a (id)",
+		"INSERT INTO a (id) VALUES (1)",
+	}, splitStatements(script))
+}
+
+func TestMigrationsPerDialect(t *testing.T) {
+	var versions []int
+	for _, dl := range []dialect{mysqlDialect, postgresDialect, sqliteDialect} {
+		migrations, err := loadMigrations(dl)
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+
+		var dialectVersions []int
+		for _, m := range migrations {
+			assert.NotEmpty(t, m.statements, "%s migration %d", dl, m.Version)
+			dialectVersions = append(dialectVersions, m.Version)
+		}
+		if versions == nil {
+			versions = dialectVersions
+		}
+		assert.Equal(t, versions, dialectVersions, "every dialect has the same migrations")
+	}
+}
+
+func TestSQLiteMigrate(t *testing.T) {
+	db := openSQLite(t)
+
+	migrations, err := db.Migrations()
+	require.NoError(t, err)
+	for _, m := range migrations {
+		assert.NotNil(t, m.AppliedAt, "migration %d", m.Version)
+	}
+
+	applied, err := db.Migrate()
+	require.NoError(t, err)
+	assert.Empty(t, applied, "migrating again is a no-op")
+}
+
+func TestSQLiteMigrateManually(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:     "sqlite",
+		Database: filepath.Join(t.TempDir(), "log_analyzer.db"),
+	}}
+
+	_, err := NewDatabase(cfg)
+	assert.ErrorContains(t, err, "run the migrate command")
+
+	db, err := Connect(cfg)
+	require.NoError(t, err)
+	pending, err := db.PendingMigrations()
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending[0].Version)
+
+	applied, err := db.Migrate()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(pending))
+	require.NoError(t, db.Close())
+
+	db, err = NewDatabase(cfg)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+}
+
+func TestSQLiteAdoptExistingSchema(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:        "sqlite",
+		Database:    filepath.Join(t.TempDir(), "log_analyzer.db"),
+		AutoMigrate: true,
+	}}
+	db, err := Connect(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A database created by InitSchema before migrations existed
+	migrations, err := loadMigrations(sqliteDialect)
+	require.NoError(t, err)
+	for _, statement := range migrations[0].statements {
+		_, err := db.DB.Exec(statement)
+		require.NoError(t, err)
+	}
+	_, err = db.DB.Exec("INSERT INTO alert_rules (name, condition_type, threshold_value, time_window) VALUES ('5xx', 'error_rate', 5, 300)")
+	require.NoError(t, err)
+
+	require.NoError(t, db.InitSchema())
+	pending, err := db.PendingMigrations()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rules, err := db.GetAlertRules(false)
+	require.NoError(t, err)
+	require.Len(t, rules, 1, "existing data is kept")
+	assert.Equal(t, "5xx", rules[0].Name)
+}
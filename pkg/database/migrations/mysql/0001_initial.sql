@@ -0,0 +1,123 @@
+-- The schema as of the first versioned release. Statements are
+-- idempotent, so databases created by InitSchema before migrations
+-- existed are adopted unchanged.
+
+CREATE TABLE IF NOT EXISTS log_entries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    timestamp DATETIME NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    source_ip VARCHAR(45) NOT NULL,
+    method VARCHAR(10),
+    path TEXT,
+    status_code INT,
+    response_size BIGINT,
+    user_agent TEXT,
+    referer TEXT,
+    processing_time DOUBLE,
+    raw_log LONGTEXT,
+    metadata JSON,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_timestamp (timestamp),
+    INDEX idx_log_type (log_type),
+    INDEX idx_source_ip (source_ip),
+    INDEX idx_status_code (status_code),
+    INDEX idx_method (method)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS log_stats_cache (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    stat_type VARCHAR(50) NOT NULL,
+    stat_data JSON NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_stat_type (stat_type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    condition_type VARCHAR(20) NOT NULL,
+    threshold_value DOUBLE NOT NULL,
+    time_window INT NOT NULL,
+    for_duration INT NOT NULL DEFAULT 0,
+    recovery_threshold DOUBLE NULL,
+    expression JSON NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS alert_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    rule_id INT NOT NULL,
+    message TEXT NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    triggered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at DATETIME NULL,
+    acknowledged_by VARCHAR(100) NULL,
+    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    silence_alerts BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_maintenance_period (starts_at, ends_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS latency_budgets (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    path VARCHAR(500) NOT NULL,
+    percentile DOUBLE NOT NULL,
+    threshold_ms DOUBLE NOT NULL,
+    team VARCHAR(100),
+    description TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS feature_overrides (
+    flag VARCHAR(50) NOT NULL,
+    project VARCHAR(100) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(100) NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (flag, project)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS config_versions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    state MEDIUMTEXT NULL,
+    restored_from INT NULL,
+    created_at DATETIME(6) NOT NULL,
+    UNIQUE KEY unique_object_version (kind, object_id, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,
+    recorded_at DATETIME(6) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    details TEXT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS ingested_files (
+    sha256 CHAR(64) PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    log_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    ingested_at DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+-- Metadata of generated report files, so downloads can be validated with
+-- strong ETags without hashing the file on every request.
+
+CREATE TABLE IF NOT EXISTS report_files (
+    name VARCHAR(255) PRIMARY KEY,
+    size BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    created_at DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,9 @@
+-- Per-project keys encrypting raw_log and selected metadata fields at rest,
+-- wrapped by the master key.
+
+CREATE TABLE IF NOT EXISTS data_keys (
+    project VARCHAR(100) PRIMARY KEY,
+    wrapped_key BLOB NOT NULL,
+    master_key_id VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,9 @@
+-- Retention windows of log types set through the API, replacing the
+-- configured ones.
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+    log_type VARCHAR(20) PRIMARY KEY,
+    days INT NOT NULL,
+    updated_by VARCHAR(100) NULL,
+    updated_at DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
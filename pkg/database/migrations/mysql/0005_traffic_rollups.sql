@@ -0,0 +1,26 @@
+-- Hourly and daily totals of entries by log type, path and status code,
+-- recomputed from log_entries by the rollups refresh.
+
+CREATE TABLE IF NOT EXISTS traffic_rollups_hourly (
+    bucket DATETIME NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    status_code INT NOT NULL,
+    requests BIGINT NOT NULL,
+    errors BIGINT NOT NULL,
+    bytes BIGINT NOT NULL,
+    unique_ips BIGINT NOT NULL,
+    PRIMARY KEY (bucket, log_type, path, status_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS traffic_rollups_daily (
+    bucket DATETIME NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    status_code INT NOT NULL,
+    requests BIGINT NOT NULL,
+    errors BIGINT NOT NULL,
+    bytes BIGINT NOT NULL,
+    unique_ips BIGINT NOT NULL,
+    PRIMARY KEY (bucket, log_type, path, status_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+-- The uploaded file and line each entry was read from. Entries of the
+-- same line are stored once, so an upload cut short can be run again;
+-- entries from elsewhere leave both NULL and never conflict. The index
+-- includes timestamp as partitioned tables require.
+
+ALTER TABLE log_entries ADD COLUMN file_hash CHAR(64) NULL,
+    ADD COLUMN line_number BIGINT NULL,
+    ADD UNIQUE INDEX idx_log_entries_line (file_hash, line_number, timestamp);
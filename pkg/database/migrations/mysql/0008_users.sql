@@ -0,0 +1,25 @@
+-- Roles and the users of the dashboard and API, identified by the subject
+-- of their OpenID Connect tokens. Each role is allowed what the roles of a
+-- lower level are.
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(20) PRIMARY KEY,
+    level INT NOT NULL,
+    description VARCHAR(200) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO roles (name, level, description) VALUES
+    ('viewer', 1, 'Reads logs, stats, alerts and reports'),
+    ('analyst', 2, 'Also uploads logs, generates reports and manages alert rules'),
+    ('admin', 3, 'Also manages users, retention, ingestion sources and the server');
+
+CREATE TABLE IF NOT EXISTS users (
+    subject VARCHAR(255) PRIMARY KEY,
+    email VARCHAR(255) NULL,
+    name VARCHAR(255) NULL,
+    role VARCHAR(20) NOT NULL,
+    last_sign_in_at DATETIME NULL,
+    updated_by VARCHAR(255) NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (role) REFERENCES roles (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+-- The file lines stored so far, so that reading a file again skips them.
+-- Lines are keyed by file and line number alone: entries of lines without
+-- a timestamp take the time they are read, which differs between reads.
+-- Kept apart from log_entries, whose partitions would need the timestamp
+-- in the key. Deleting entries forgets their lines.
+
+CREATE TABLE IF NOT EXISTS ingested_lines (
+    file_hash CHAR(64) NOT NULL,
+    line_number BIGINT NOT NULL,
+    PRIMARY KEY (file_hash, line_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO ingested_lines (file_hash, line_number)
+    SELECT file_hash, line_number FROM log_entries WHERE file_hash IS NOT NULL;
+
+ALTER TABLE log_entries DROP INDEX idx_log_entries_line,
+    ADD INDEX idx_log_entries_file_line (file_hash, line_number);
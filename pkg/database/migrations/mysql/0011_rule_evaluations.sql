@@ -0,0 +1,14 @@
+-- The outcome of each evaluation of each alert rule, kept for
+-- alerting.evaluation_retention days. details holds the metric values of
+-- composite rules as JSON.
+
+CREATE TABLE IF NOT EXISTS rule_evaluations (
+    rule_id BIGINT NOT NULL,
+    evaluated_at DATETIME(3) NOT NULL,
+    value DOUBLE NOT NULL,
+    threshold DOUBLE NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    details TEXT NULL,
+    INDEX idx_rule_evaluations_rule (rule_id, evaluated_at),
+    INDEX idx_rule_evaluations_time (evaluated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
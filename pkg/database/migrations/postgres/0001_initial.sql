@@ -0,0 +1,122 @@
+-- The schema as of the first versioned release. Statements are
+-- idempotent, so databases created by InitSchema before migrations
+-- existed are adopted unchanged.
+
+CREATE TABLE IF NOT EXISTS log_entries (
+    id BIGSERIAL PRIMARY KEY,
+    timestamp TIMESTAMP NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    source_ip INET NOT NULL,
+    method VARCHAR(10),
+    path TEXT,
+    status_code INTEGER,
+    response_size BIGINT,
+    user_agent TEXT,
+    referer TEXT,
+    processing_time DOUBLE PRECISION,
+    raw_log TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_log_entries_timestamp ON log_entries(timestamp);
+CREATE INDEX IF NOT EXISTS idx_log_entries_log_type ON log_entries(log_type);
+CREATE INDEX IF NOT EXISTS idx_log_entries_source_ip ON log_entries(source_ip);
+CREATE INDEX IF NOT EXISTS idx_log_entries_status_code ON log_entries(status_code);
+CREATE INDEX IF NOT EXISTS idx_log_entries_method ON log_entries(method);
+
+CREATE TABLE IF NOT EXISTS log_stats_cache (
+    id SERIAL PRIMARY KEY,
+    stat_type VARCHAR(50) NOT NULL UNIQUE,
+    stat_data JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    condition_type VARCHAR(20) NOT NULL,
+    threshold_value DOUBLE PRECISION NOT NULL,
+    time_window INTEGER NOT NULL,
+    for_duration INTEGER NOT NULL DEFAULT 0,
+    recovery_threshold DOUBLE PRECISION NULL,
+    expression JSONB NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alert_history (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    triggered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at TIMESTAMP NULL,
+    acknowledged_by VARCHAR(100) NULL,
+    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    silence_alerts BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_period ON maintenance_windows(starts_at, ends_at);
+
+CREATE TABLE IF NOT EXISTS latency_budgets (
+    id SERIAL PRIMARY KEY,
+    path VARCHAR(500) NOT NULL,
+    percentile DOUBLE PRECISION NOT NULL,
+    threshold_ms DOUBLE PRECISION NOT NULL,
+    team VARCHAR(100),
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS feature_overrides (
+    flag VARCHAR(50) NOT NULL,
+    project VARCHAR(100) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(100) NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (flag, project)
+);
+
+CREATE TABLE IF NOT EXISTS config_versions (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    state TEXT NULL,
+    restored_from INTEGER NULL,
+    created_at TIMESTAMP(6) NOT NULL,
+    UNIQUE (kind, object_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,
+    recorded_at TIMESTAMP(6) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    details TEXT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ingested_files (
+    sha256 CHAR(64) PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    log_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    ingested_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,12 @@
+-- Named log filters users save to run again, each user's own. Without
+-- sign-in the owner is empty and every search is shared.
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    owner VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NULL,
+    filter_json TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (owner, name)
+);
@@ -0,0 +1,19 @@
+-- The file lines stored so far, so that reading a file again skips them.
+-- Lines are keyed by file and line number alone: entries of lines without
+-- a timestamp take the time they are read, which differs between reads.
+-- Kept apart from log_entries, whose partitions and hypertable chunks
+-- would need the timestamp in the key. Deleting entries forgets their
+-- lines.
+
+CREATE TABLE IF NOT EXISTS ingested_lines (
+    file_hash CHAR(64) NOT NULL,
+    line_number BIGINT NOT NULL,
+    PRIMARY KEY (file_hash, line_number)
+);
+
+INSERT INTO ingested_lines (file_hash, line_number)
+    SELECT file_hash, line_number FROM log_entries WHERE file_hash IS NOT NULL
+    ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS idx_log_entries_line;
+CREATE INDEX IF NOT EXISTS idx_log_entries_file_line ON log_entries(file_hash, line_number);
@@ -0,0 +1,122 @@
+-- The schema as of the first versioned release. Statements are
+-- idempotent, so databases created by InitSchema before migrations
+-- existed are adopted unchanged.
+
+CREATE TABLE IF NOT EXISTS log_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    timestamp DATETIME NOT NULL,
+    log_type VARCHAR(20) NOT NULL,
+    source_ip VARCHAR(45) NOT NULL,
+    method VARCHAR(10),
+    path TEXT,
+    status_code INTEGER,
+    response_size BIGINT,
+    user_agent TEXT,
+    referer TEXT,
+    processing_time DOUBLE,
+    raw_log TEXT,
+    metadata TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_log_entries_timestamp ON log_entries(timestamp);
+CREATE INDEX IF NOT EXISTS idx_log_entries_log_type ON log_entries(log_type);
+CREATE INDEX IF NOT EXISTS idx_log_entries_source_ip ON log_entries(source_ip);
+CREATE INDEX IF NOT EXISTS idx_log_entries_status_code ON log_entries(status_code);
+CREATE INDEX IF NOT EXISTS idx_log_entries_method ON log_entries(method);
+
+CREATE TABLE IF NOT EXISTS log_stats_cache (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    stat_type VARCHAR(50) NOT NULL UNIQUE,
+    stat_data TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    condition_type VARCHAR(20) NOT NULL,
+    threshold_value DOUBLE NOT NULL,
+    time_window INTEGER NOT NULL,
+    for_duration INTEGER NOT NULL DEFAULT 0,
+    recovery_threshold DOUBLE NULL,
+    expression TEXT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alert_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    triggered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at DATETIME NULL,
+    acknowledged_by VARCHAR(100) NULL,
+    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    silence_alerts BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_period ON maintenance_windows(starts_at, ends_at);
+
+CREATE TABLE IF NOT EXISTS latency_budgets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    path VARCHAR(500) NOT NULL,
+    percentile DOUBLE NOT NULL,
+    threshold_ms DOUBLE NOT NULL,
+    team VARCHAR(100),
+    description TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS feature_overrides (
+    flag VARCHAR(50) NOT NULL,
+    project VARCHAR(100) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(100) NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (flag, project)
+);
+
+CREATE TABLE IF NOT EXISTS config_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind VARCHAR(50) NOT NULL,
+    object_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    state TEXT NULL,
+    restored_from INTEGER NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (kind, object_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,
+    recorded_at DATETIME NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    details TEXT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ingested_files (
+    sha256 CHAR(64) PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    log_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    ingested_at DATETIME NOT NULL
+);
@@ -0,0 +1,7 @@
+-- The uploaded file and line each entry was read from. Entries of the
+-- same line are stored once, so an upload cut short can be run again;
+-- entries from elsewhere leave both NULL and never conflict.
+
+ALTER TABLE log_entries ADD COLUMN file_hash CHAR(64);
+ALTER TABLE log_entries ADD COLUMN line_number BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_log_entries_line ON log_entries(file_hash, line_number, timestamp);
@@ -0,0 +1,11 @@
+-- HTML templates uploaded to replace the built-in template of a report
+-- type, selected by name when a report is generated.
+
+CREATE TABLE IF NOT EXISTS report_templates (
+    report_type VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    content TEXT NOT NULL,
+    updated_by VARCHAR(100) NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (report_type, name)
+);
@@ -0,0 +1,16 @@
+-- The file lines stored so far, so that reading a file again skips them.
+-- Lines are keyed by file and line number alone: entries of lines without
+-- a timestamp take the time they are read, which differs between reads.
+-- Deleting entries forgets their lines.
+
+CREATE TABLE IF NOT EXISTS ingested_lines (
+    file_hash CHAR(64) NOT NULL,
+    line_number BIGINT NOT NULL,
+    PRIMARY KEY (file_hash, line_number)
+);
+
+INSERT OR IGNORE INTO ingested_lines (file_hash, line_number)
+    SELECT file_hash, line_number FROM log_entries WHERE file_hash IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_log_entries_line;
+CREATE INDEX IF NOT EXISTS idx_log_entries_file_line ON log_entries(file_hash, line_number);
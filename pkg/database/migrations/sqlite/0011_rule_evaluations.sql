@@ -0,0 +1,15 @@
+-- The outcome of each evaluation of each alert rule, kept for
+-- alerting.evaluation_retention days. details holds the metric values of
+-- composite rules as JSON.
+
+CREATE TABLE IF NOT EXISTS rule_evaluations (
+    rule_id BIGINT NOT NULL,
+    evaluated_at DATETIME NOT NULL,
+    value DOUBLE NOT NULL,
+    threshold DOUBLE NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    details TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rule_evaluations_rule ON rule_evaluations(rule_id, evaluated_at);
+CREATE INDEX IF NOT EXISTS idx_rule_evaluations_time ON rule_evaluations(evaluated_at);
@@ -0,0 +1,393 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// partitionPrefix starts the name of each log_entries partition, which
+	// ends with the start of its period: YYYYMMDD for a day, YYYYMM for a
+	// month
+	partitionPrefix = "log_entries_p"
+	// defaultPartition holds postgres entries no partition covers, such as
+	// those older than the first
+	defaultPartition = "log_entries_default"
+	// futurePartition holds mysql entries later than the last partition
+	futurePartition = "log_entries_future"
+)
+
+// logEntryIndexes are the indexes of log_entries, recreated on postgres
+// when it is partitioned
+var logEntryIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_timestamp ON log_entries(timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_log_type ON log_entries(log_type)`,
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_source_ip ON log_entries(source_ip)`,
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_status_code ON log_entries(status_code)`,
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_method ON log_entries(method)`,
+	`CREATE INDEX IF NOT EXISTS idx_log_entries_file_line ON log_entries(file_hash, line_number)`,
+}
+
+// partition is a log_entries partition holding the entries of [start, end)
+type partition struct {
+	name       string
+	start, end time.Time
+}
+
+// partitionFor returns the partition of an interval that holds t
+func partitionFor(interval string, t time.Time) partition {
+	t = t.UTC()
+	if interval == "month" {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return partition{name: partitionPrefix + start.Format("200601"), start: start, end: start.AddDate(0, 1, 0)}
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return partition{name: partitionPrefix + start.Format("20060102"), start: start, end: start.AddDate(0, 0, 1)}
+}
+
+// parsePartition returns the partition with a name partitionFor gave it.
+// The period is read from the name, so partitions made with another
+// interval are still recognized.
+func parsePartition(name string) (partition, bool) {
+	period, ok := strings.CutPrefix(name, partitionPrefix)
+	if !ok {
+		return partition{}, false
+	}
+	for interval, layout := range map[string]string{"day": "20060102", "month": "200601"} {
+		if len(period) != len(layout) {
+			continue
+		}
+		if t, err := time.Parse(layout, period); err == nil {
+			return partitionFor(interval, t), true
+		}
+	}
+	return partition{}, false
+}
+
+// partitionsThrough returns the partitions of an interval from the one
+// holding from through the one holding to, in order
+func partitionsThrough(interval string, from, to time.Time) []partition {
+	var partitions []partition
+	for next := partitionFor(interval, from); !next.start.After(to); next = partitionFor(interval, next.end) {
+		partitions = append(partitions, next)
+	}
+	return partitions
+}
+
+// partitionBound formats a partition boundary as a literal
+func partitionBound(t time.Time) string {
+	return "'" + t.UTC().Format("2006-01-02 15:04:05") + "'"
+}
+
+// setupPartitions partitions log_entries by the configured interval, unless
+// it is a TimescaleDB hypertable, and creates the coming partitions
+func (d *Database) setupPartitions() error {
+	interval := d.Config.Database.Partitioning.Interval
+	if interval == "" || d.timescale {
+		return nil
+	}
+
+	partitioned, err := d.isPartitioned()
+	if err != nil {
+		return err
+	}
+	if !partitioned {
+		if err := d.partitionTable(interval); err != nil {
+			return fmt.Errorf("failed to partition log_entries: %w", err)
+		}
+	}
+	if err := d.loadPartitions(); err != nil {
+		return err
+	}
+	return d.maintainPartitions()
+}
+
+// isPartitioned reports whether log_entries is partitioned already
+func (d *Database) isPartitioned() (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = 'log_entries' AND partition_name IS NOT NULL`
+	if d.dialect() == postgresDialect {
+		query = `SELECT COUNT(*) FROM pg_partitioned_table pt JOIN pg_class c ON c.oid = pt.partrelid
+			WHERE c.relname = 'log_entries' AND c.relnamespace = to_regnamespace(current_schema())`
+	}
+	var count int
+	if err := d.DB.QueryRow(query).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to inspect log_entries partitions: %w", err)
+	}
+	return count > 0, nil
+}
+
+// loadPartitions reads the partitions of log_entries
+func (d *Database) loadPartitions() error {
+	query := `SELECT partition_name FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = 'log_entries' AND partition_name IS NOT NULL`
+	if d.dialect() == postgresDialect {
+		query = `SELECT c.relname FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent
+			WHERE p.relname = 'log_entries' AND p.relnamespace = to_regnamespace(current_schema())`
+	}
+	rows, err := d.DB.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to list log_entries partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := make(map[string]partition)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if p, ok := parsePartition(name); ok {
+			partitions[name] = p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	d.partitionMu.Lock()
+	defer d.partitionMu.Unlock()
+	d.partitions = partitions
+	return nil
+}
+
+// isPartitionedNow reports whether log_entries was found partitioned
+func (d *Database) isPartitionedNow() bool {
+	d.partitionMu.Lock()
+	defer d.partitionMu.Unlock()
+	return d.partitions != nil
+}
+
+// lastPartitionEnd returns the end of the latest partition, or the zero
+// time without partitions. The caller holds partitionMu.
+func (d *Database) lastPartitionEnd() time.Time {
+	var end time.Time
+	for _, p := range d.partitions {
+		if p.end.After(end) {
+			end = p.end
+		}
+	}
+	return end
+}
+
+// maintainPartitions creates the partitions after the latest through the
+// premake ones after the current, so entries arriving now always have one.
+// It is cheap once they exist, and is called before entries are stored.
+func (d *Database) maintainPartitions() error {
+	d.partitionMu.Lock()
+	defer d.partitionMu.Unlock()
+	if d.partitions == nil {
+		return nil
+	}
+
+	interval := d.Config.Database.Partitioning.Interval
+	now := time.Now()
+	target := partitionFor(interval, now)
+	for i := 0; i < d.Config.Database.Partitioning.Premake; i++ {
+		target = partitionFor(interval, target.end)
+	}
+	from := d.lastPartitionEnd()
+	if from.After(target.start) {
+		return nil
+	}
+	if from.IsZero() {
+		from = now
+	}
+
+	// Partitions follow the latest, even one of another interval
+	for _, p := range partitionsThrough(interval, from, target.start) {
+		if err := d.createPartition(p); err != nil {
+			// Another server may have created it first
+			if exists, lookupErr := d.partitionExists(p.name); lookupErr != nil || !exists {
+				return err
+			}
+		}
+		d.partitions[p.name] = p
+	}
+	return nil
+}
+
+// partitionExists reports whether a partition of log_entries exists
+func (d *Database) partitionExists(name string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = 'log_entries' AND partition_name = ?`
+	if d.dialect() == postgresDialect {
+		query = `SELECT COUNT(*) FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent
+			WHERE p.relname = 'log_entries' AND p.relnamespace = to_regnamespace(current_schema()) AND c.relname = ?`
+	}
+	var count int
+	err := d.DB.QueryRow(d.rebind(query), name).Scan(&count)
+	return count > 0, err
+}
+
+// createPartition adds a partition after the latest. On postgres, entries
+// of its period that went to the default partition are moved into it.
+func (d *Database) createPartition(p partition) error {
+	queries := []string{
+		`ALTER TABLE log_entries REORGANIZE PARTITION ` + futurePartition + ` INTO (
+			PARTITION ` + p.name + ` VALUES LESS THAN (TO_DAYS(` + partitionBound(p.end) + `)),
+			PARTITION ` + futurePartition + ` VALUES LESS THAN MAXVALUE)`,
+	}
+	if d.dialect() == postgresDialect {
+		period := `timestamp >= ` + partitionBound(p.start) + ` AND timestamp < ` + partitionBound(p.end)
+		queries = []string{
+			`CREATE TABLE IF NOT EXISTS ` + p.name + ` (LIKE log_entries INCLUDING DEFAULTS)`,
+			`INSERT INTO ` + p.name + ` SELECT * FROM ` + defaultPartition + ` WHERE ` + period,
+			`DELETE FROM ` + defaultPartition + ` WHERE ` + period,
+			`ALTER TABLE log_entries ATTACH PARTITION ` + p.name + ` FOR VALUES FROM (` +
+				partitionBound(p.start) + `) TO (` + partitionBound(p.end) + `)`,
+		}
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to create partition %s: %w", p.name, err)
+	}
+	defer tx.Rollback()
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", p.name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// partitionTable converts log_entries into a partitioned table with a
+// partition per period from its oldest entry through the current one
+func (d *Database) partitionTable(interval string) error {
+	var oldest sql.NullTime
+	if err := d.DB.QueryRow(`SELECT MIN(timestamp) FROM log_entries`).Scan(&oldest); err != nil {
+		return err
+	}
+	first := time.Now()
+	if oldest.Valid && oldest.Time.Before(first) {
+		first = oldest.Time
+	}
+	partitions := partitionsThrough(interval, first, time.Now())
+
+	var queries []string
+	if d.dialect() == postgresDialect {
+		// Postgres cannot partition a table in place, so entries are copied
+		// into a partitioned one
+		queries = []string{
+			`ALTER TABLE log_entries RENAME TO log_entries_unpartitioned`,
+			`ALTER TABLE log_entries_unpartitioned DROP CONSTRAINT IF EXISTS log_entries_pkey`,
+			`DROP INDEX IF EXISTS idx_log_entries_timestamp, idx_log_entries_log_type, idx_log_entries_source_ip,
+				idx_log_entries_status_code, idx_log_entries_method, idx_log_entries_line`,
+			// Unique indexes of a partitioned table must include its partition key
+			`CREATE TABLE log_entries (LIKE log_entries_unpartitioned INCLUDING DEFAULTS, PRIMARY KEY (id, timestamp))
+				PARTITION BY RANGE (timestamp)`,
+			`ALTER SEQUENCE log_entries_id_seq OWNED BY log_entries.id`,
+			`CREATE TABLE ` + defaultPartition + ` PARTITION OF log_entries DEFAULT`,
+		}
+		for _, p := range partitions {
+			queries = append(queries, `CREATE TABLE `+p.name+` PARTITION OF log_entries FOR VALUES FROM (`+
+				partitionBound(p.start)+`) TO (`+partitionBound(p.end)+`)`)
+		}
+		queries = append(queries,
+			`INSERT INTO log_entries SELECT * FROM log_entries_unpartitioned`,
+			`DROP TABLE log_entries_unpartitioned`)
+		queries = append(queries, logEntryIndexes...)
+	} else {
+		definitions := make([]string, 0, len(partitions)+1)
+		for _, p := range partitions {
+			definitions = append(definitions, `PARTITION `+p.name+` VALUES LESS THAN (TO_DAYS(`+partitionBound(p.end)+`))`)
+		}
+		definitions = append(definitions, `PARTITION `+futurePartition+` VALUES LESS THAN MAXVALUE`)
+		queries = []string{
+			`ALTER TABLE log_entries DROP PRIMARY KEY, ADD PRIMARY KEY (id, timestamp)`,
+			`ALTER TABLE log_entries PARTITION BY RANGE (TO_DAYS(timestamp)) (` + strings.Join(definitions, ", ") + `)`,
+		}
+	}
+
+	// MySQL commits each ALTER TABLE as it runs
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// dropPartitionsBefore removes entries older than cutoff from a partitioned
+// log_entries. Partitions entirely before cutoff are dropped and the rest
+// deleted row by row, in one transaction with forgetting their lines. MySQL
+// commits before each DROP PARTITION, so there a failed drop leaves the
+// lines of its entries forgotten until the next run drops it.
+func (d *Database) dropPartitionsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	d.partitionMu.Lock()
+	var expired []partition
+	for _, p := range d.partitions {
+		if !p.end.After(cutoff) {
+			expired = append(expired, p)
+		}
+	}
+	d.partitionMu.Unlock()
+	sort.Slice(expired, func(i, j int) bool { return expired[i].start.Before(expired[j].start) })
+
+	var dropped []string
+	// Partitions MySQL dropped stay dropped, whether or not the rest fails
+	committed := d.dialect() == mysqlDialect
+	defer func() {
+		if committed {
+			d.partitionMu.Lock()
+			for _, name := range dropped {
+				delete(d.partitions, name)
+			}
+			d.partitionMu.Unlock()
+		}
+	}()
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old log entries: %w", err)
+	}
+	defer tx.Rollback()
+	if err := d.forgetLines(ctx, tx, "timestamp < ?", cutoff); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, p := range expired {
+		count, drop := `SELECT COUNT(*) FROM log_entries PARTITION (`+p.name+`)`, `ALTER TABLE log_entries DROP PARTITION `+p.name
+		if d.dialect() == postgresDialect {
+			count, drop = `SELECT COUNT(*) FROM `+p.name, `DROP TABLE `+p.name
+		}
+
+		// Dropping a partition reports no row count
+		var rows int64
+		if err := tx.QueryRowContext(ctx, count).Scan(&rows); err != nil {
+			return 0, fmt.Errorf("failed to count entries of partition %s: %w", p.name, err)
+		}
+		if _, err := tx.ExecContext(ctx, drop); err != nil {
+			return 0, fmt.Errorf("failed to drop partition %s: %w", p.name, err)
+		}
+		deleted += rows
+		dropped = append(dropped, p.name)
+	}
+
+	result, err := tx.ExecContext(ctx, d.rebind(`DELETE FROM log_entries WHERE timestamp < ?`), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old log entries: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old log entries: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete old log entries: %w", err)
+	}
+	committed = true
+	return deleted + rows, nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionFor(t *testing.T) {
+	at := time.Date(2024, 2, 29, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+
+	day := partitionFor("day", at)
+	assert.Equal(t, "log_entries_p20240301", day.name)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), day.start)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), day.end)
+
+	month := partitionFor("month", time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "log_entries_p202412", month.name)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), month.start)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), month.end)
+}
+
+func TestParsePartition(t *testing.T) {
+	day, ok := parsePartition("log_entries_p20240301")
+	require.True(t, ok)
+	assert.Equal(t, partitionFor("day", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)), day)
+
+	month, ok := parsePartition("log_entries_p202403")
+	require.True(t, ok)
+	assert.Equal(t, partitionFor("month", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)), month)
+
+	for _, name := range []string{"log_entries_default", "log_entries_future", "log_entries_p2024", "log_entries_p20241301", "other_p202403"} {
+		_, ok := parsePartition(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestPartitionsThrough(t *testing.T) {
+	from := time.Date(2024, 1, 30, 8, 0, 0, 0, time.UTC)
+	var names []string
+	for _, p := range partitionsThrough("day", from, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)) {
+		names = append(names, p.name)
+	}
+	assert.Equal(t, []string{"log_entries_p20240130", "log_entries_p20240131", "log_entries_p20240201", "log_entries_p20240202"}, names)
+
+	names = nil
+	for _, p := range partitionsThrough("month", from, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		names = append(names, p.name)
+	}
+	assert.Equal(t, []string{"log_entries_p202401", "log_entries_p202402", "log_entries_p202403"}, names)
+
+	assert.Empty(t, partitionsThrough("day", from, from.AddDate(0, 0, -1)))
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// dialect is the SQL flavour of a database type. Queries are written with ?
+// placeholders and converted to the dialect's form when they are run.
+type dialect string
+
+const (
+	mysqlDialect    dialect = "mysql"
+	postgresDialect dialect = "postgres"
+	sqliteDialect   dialect = "sqlite"
+)
+
+// dialect returns the SQL flavour of the configured database
+func (d *Database) dialect() dialect {
+	return dialect(d.Config.Database.Type)
+}
+
+// rebind converts ? placeholders to the form the database expects
+func (d *Database) rebind(query string) string {
+	return d.dialect().rebind(query)
+}
+
+// rebind converts ? placeholders to the dialect's form. Question marks
+// within quoted strings and identifiers are left alone.
+func (dl dialect) rebind(query string) string {
+	if dl != postgresDialect || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote rune
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			// A doubled quote escapes itself, leaving and re-entering
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(char)
+	}
+	return b.String()
+}
+
+// quote quotes an identifier such as a table or column name
+func (dl dialect) quote(identifier string) string {
+	if dl == mysqlDialect {
+		return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// limitOffset returns the clause skipping offset rows and returning at most
+// limit, with its arguments. A negative limit returns every row.
+func (dl dialect) limitOffset(limit, offset int) (string, []interface{}) {
+	switch {
+	case limit >= 0 && offset > 0:
+		return "LIMIT ? OFFSET ?", []interface{}{limit, offset}
+	case limit >= 0:
+		return "LIMIT ?", []interface{}{limit}
+	case offset <= 0:
+		return "", nil
+	case dl == mysqlDialect:
+		// MySQL has no OFFSET without LIMIT, so it takes the largest one
+		return "LIMIT 18446744073709551615 OFFSET ?", []interface{}{offset}
+	case dl == sqliteDialect:
+		return "LIMIT -1 OFFSET ?", []interface{}{offset}
+	default:
+		return "OFFSET ?", []interface{}{offset}
+	}
+}
+
+// like returns a condition matching the column against a LIKE pattern
+// placeholder escaped with escapeLike
+func (dl dialect) like(column string) string {
+	// SQLite has no default escape character
+	if dl == sqliteDialect {
+		return column + ` LIKE ? ESCAPE '\'`
+	}
+	return column + " LIKE ?"
+}
+
+// epochBucket returns the start of the bucket of a time column holding
+// its value, in seconds since the Unix epoch, for buckets of seconds
+func (dl dialect) epochBucket(column string, seconds int64) string {
+	n := strconv.FormatInt(seconds, 10)
+	switch dl {
+	case mysqlDialect:
+		// DATETIME has no time zone, so this counts from a naive epoch
+		return "TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', " + column + ") DIV " + n + " * " + n
+	case postgresDialect:
+		return "CAST(FLOOR(EXTRACT(EPOCH FROM " + column + ") / " + n + ") * " + n + " AS BIGINT)"
+	default:
+		return "CAST(strftime('%s', " + column + ") AS INTEGER) / " + n + " * " + n
+	}
+}
+
+// selectQuery builds a SELECT statement with ? placeholders
+type selectQuery struct {
+	columns    string
+	from       string
+	conditions []string
+	args       []interface{}
+	group      string
+	order      string
+	limitRows  int
+	offsetRows int
+}
+
+// selectFrom starts a query selecting columns from a table
+func selectFrom(from string, columns ...string) *selectQuery {
+	return &selectQuery{columns: strings.Join(columns, ", "), from: from, limitRows: -1}
+}
+
+// where adds a condition that rows must meet, with the arguments of its
+// placeholders
+func (q *selectQuery) where(condition string, args ...interface{}) *selectQuery {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// whereAny adds conditions of which rows must meet at least one
+func (q *selectQuery) whereAny(conditions []string, args ...interface{}) *selectQuery {
+	if len(conditions) == 1 {
+		return q.where(conditions[0], args...)
+	}
+	return q.where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// groupBy sets the GROUP BY expressions
+func (q *selectQuery) groupBy(expressions string) *selectQuery {
+	q.group = expressions
+	return q
+}
+
+// orderBy sets the ORDER BY expressions
+func (q *selectQuery) orderBy(expressions string) *selectQuery {
+	q.order = expressions
+	return q
+}
+
+// limit returns at most n rows
+func (q *selectQuery) limit(n int) *selectQuery {
+	q.limitRows = max(n, 0)
+	return q
+}
+
+// offset skips the first n rows
+func (q *selectQuery) offset(n int) *selectQuery {
+	q.offsetRows = n
+	return q
+}
+
+// build returns the statement in the dialect's form and its arguments
+func (q *selectQuery) build(dl dialect) (string, []interface{}) {
+	var b strings.Builder
+	b.WriteString("SELECT " + q.columns + " FROM " + q.from)
+	if len(q.conditions) > 0 {
+		b.WriteString(" WHERE " + strings.Join(q.conditions, " AND "))
+	}
+	if q.group != "" {
+		b.WriteString(" GROUP BY " + q.group)
+	}
+	if q.order != "" {
+		b.WriteString(" ORDER BY " + q.order)
+	}
+
+	args := append([]interface{}{}, q.args...)
+	if clause, clauseArgs := dl.limitOffset(q.limitRows, q.offsetRows); clause != "" {
+		b.WriteString(" " + clause)
+		args = append(args, clauseArgs...)
+	}
+	return dl.rebind(b.String()), args
+}
+
+// buildDelete returns a DELETE of the rows the query's conditions select,
+// in the dialect's form, and its arguments
+func (q *selectQuery) buildDelete(dl dialect) (string, []interface{}) {
+	statement := "DELETE FROM " + q.from
+	if len(q.conditions) > 0 {
+		statement += " WHERE " + strings.Join(q.conditions, " AND ")
+	}
+	return dl.rebind(statement), append([]interface{}{}, q.args...)
+}
+
+// query runs a built SELECT statement
+func (d *Database) query(q *selectQuery) (*sql.Rows, error) {
+	return d.queryContext(context.Background(), q)
+}
+
+// queryContext runs a built SELECT statement until ctx is done
+func (d *Database) queryContext(ctx context.Context, q *selectQuery) (*sql.Rows, error) {
+	query, args := q.build(d.dialect())
+	return d.DB.QueryContext(ctx, query, args...)
+}
+
+// placeholders returns n comma-separated ? placeholders, for an IN list
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// anySlice converts values to placeholder arguments
+func anySlice[T any](values []T) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
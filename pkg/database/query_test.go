@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM t WHERE a = ? AND b LIKE 'what?' AND "odd?col" = ? AND c = 'it''s?' AND d IN (?, ?)`
+	assert.Equal(t, query, mysqlDialect.rebind(query))
+	assert.Equal(t, `SELECT * FROM t WHERE a = $1 AND b LIKE 'what?' AND "odd?col" = $2 AND c = 'it''s?' AND d IN ($3, $4)`,
+		postgresDialect.rebind(query))
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "`status_code`", mysqlDialect.quote("status_code"))
+	assert.Equal(t, "`odd``name`", mysqlDialect.quote("odd`name"))
+	assert.Equal(t, `"status_code"`, postgresDialect.quote("status_code"))
+	assert.Equal(t, `"odd""name"`, postgresDialect.quote(`odd"name`))
+}
+
+func TestLimitOffset(t *testing.T) {
+	tests := []struct {
+		dialect       dialect
+		limit, offset int
+		clause        string
+		args          []interface{}
+	}{
+		{mysqlDialect, 10, 0, "LIMIT ?", []interface{}{10}},
+		{postgresDialect, 10, 20, "LIMIT ? OFFSET ?", []interface{}{10, 20}},
+		{postgresDialect, 0, 0, "LIMIT ?", []interface{}{0}},
+		{mysqlDialect, -1, 0, "", nil},
+		{mysqlDialect, -1, 20, "LIMIT 18446744073709551615 OFFSET ?", []interface{}{20}},
+		{postgresDialect, -1, 20, "OFFSET ?", []interface{}{20}},
+	}
+	for _, tc := range tests {
+		clause, args := tc.dialect.limitOffset(tc.limit, tc.offset)
+		assert.Equal(t, tc.clause, clause, "%s limit %d offset %d", tc.dialect, tc.limit, tc.offset)
+		assert.Equal(t, tc.args, args, "%s limit %d offset %d", tc.dialect, tc.limit, tc.offset)
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	status := 404
+	q := filterQuery(postgresDialect, selectFrom("log_entries", "id", "path"), &models.LogFilter{
+		StartTime:  &start,
+		LogType:    "nginx",
+		StatusCode: &status,
+		Path:       "50%_off",
+	}).whereAny([]string{"method = ?", "method = ?"}, "GET", "HEAD").
+		orderBy("timestamp DESC").limit(50).offset(100)
+
+	query, args := q.build(postgresDialect)
+	assert.Equal(t, `SELECT id, path FROM log_entries WHERE timestamp >= $1 AND log_type = $2 AND status_code = $3`+
+		` AND path LIKE $4 AND (method = $5 OR method = $6) ORDER BY timestamp DESC LIMIT $7 OFFSET $8`, query)
+	assert.Equal(t, []interface{}{start, "nginx", 404, `%50\%\_off%`, "GET", "HEAD", 50, 100}, args)
+
+	query, args = q.build(mysqlDialect)
+	assert.Equal(t, `SELECT id, path FROM log_entries WHERE timestamp >= ? AND log_type = ? AND status_code = ?`+
+		` AND path LIKE ? AND (method = ? OR method = ?) ORDER BY timestamp DESC LIMIT ? OFFSET ?`, query)
+	assert.Len(t, args, 8)
+
+	// Building leaves the query's own arguments alone
+	_, again := q.build(mysqlDialect)
+	assert.Equal(t, args, again)
+
+	query, args = selectFrom("log_entries", "method", "COUNT(*) AS entries").
+		where("log_type IN ("+placeholders(2)+")", anySlice([]string{"nginx", "apache"})...).
+		groupBy("method").build(postgresDialect)
+	assert.Equal(t, `SELECT method, COUNT(*) AS entries FROM log_entries WHERE log_type IN ($1, $2) GROUP BY method`, query)
+	assert.Equal(t, []interface{}{"nginx", "apache"}, args)
+}
+
+func TestBuildDelete(t *testing.T) {
+	q := filterQuery(postgresDialect, selectFrom("log_entries"), &models.LogFilter{LogType: "nginx", SourceIP: "192.0.2.1"})
+	query, args := q.buildDelete(postgresDialect)
+	assert.Equal(t, `DELETE FROM log_entries WHERE log_type = $1 AND source_ip = $2`, query)
+	assert.Equal(t, []interface{}{"nginx", "192.0.2.1"}, args)
+
+	query, args = selectFrom("log_entries").buildDelete(mysqlDialect)
+	assert.Equal(t, `DELETE FROM log_entries`, query)
+	assert.Empty(t, args)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetReportFile returns the metadata of the report file with the given
+// name, or sql.ErrNoRows
+func (d *Database) GetReportFile(name string) (*models.ReportFile, error) {
+	var file models.ReportFile
+	err := d.DB.QueryRow(d.rebind(`SELECT name, size, sha256, content_type, created_at
+		FROM report_files WHERE name = ?`), name).
+		Scan(&file.Name, &file.Size, &file.SHA256, &file.ContentType, &file.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report file: %w", err)
+	}
+	return &file, nil
+}
+
+// SaveReportFile stores the metadata of a report file, replacing any for
+// a file with the same name
+func (d *Database) SaveReportFile(file *models.ReportFile) error {
+	query := `INSERT INTO report_files (name, size, sha256, content_type, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE size = VALUES(size), sha256 = VALUES(sha256),
+			content_type = VALUES(content_type), created_at = VALUES(created_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO report_files (name, size, sha256, content_type, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (name) DO UPDATE
+			SET size = EXCLUDED.size, sha256 = EXCLUDED.sha256,
+				content_type = EXCLUDED.content_type, created_at = EXCLUDED.created_at`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), file.Name, file.Size, file.SHA256, file.ContentType, file.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save report file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// withTimeout bounds ctx by a timeout in seconds, or only by ctx itself
+// when the timeout is not positive
+func withTimeout(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+}
+
+// readContext bounds a log query by the configured query timeout
+func (d *Database) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, d.Config.Database.QueryTimeout)
+}
+
+// writeContext bounds a batch insert or delete by the configured write
+// timeout
+func (d *Database) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, d.Config.Database.WriteTimeout)
+}
+
+// InsertBatch stores entries in one transaction and sets their IDs. An
+// entry of a file line that is stored already is skipped and its ID left
+// 0, so storing the entries of a file again only adds the missing ones.
+// Lines are claimed in ingested_lines by file and line number, whatever
+// the timestamp their entry was given.
+func (d *Database) InsertBatch(ctx context.Context, entries []*models.LogEntry) error {
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+	// Until the current partition exists, entries go to the catch-all one
+	d.maintainPartitions()
+
+	query := d.rebind(`INSERT INTO log_entries (
+			timestamp, log_type, source_ip, method, path, status_code,
+			response_size, user_agent, referer, processing_time, raw_log, metadata,
+			file_hash, line_number
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	claim := d.rebind(`INSERT INTO ingested_lines (file_hash, line_number) VALUES (?, ?)`)
+	postgres := d.dialect() == postgresDialect
+	switch d.dialect() {
+	case postgresDialect:
+		query += " RETURNING id"
+		claim += " ON CONFLICT DO NOTHING"
+	case sqliteDialect:
+		claim += " ON CONFLICT DO NOTHING"
+	default:
+		claim += " ON DUPLICATE KEY UPDATE line_number = line_number"
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to insert log entries: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to insert log entries: %w", err)
+	}
+	defer stmt.Close()
+
+	var claimStmt *sql.Stmt
+	if slices.ContainsFunc(entries, func(e *models.LogEntry) bool { return e.FileHash != "" }) {
+		if claimStmt, err = tx.PrepareContext(ctx, claim); err != nil {
+			return fmt.Errorf("failed to insert log entries: %w", err)
+		}
+		defer claimStmt.Close()
+	}
+
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		// Entries not read from a file are never skipped
+		var fileHash sql.NullString
+		var lineNumber sql.NullInt64
+		if entry.FileHash != "" {
+			fileHash = sql.NullString{String: entry.FileHash, Valid: true}
+			lineNumber = sql.NullInt64{Int64: entry.LineNumber, Valid: true}
+			result, err := claimStmt.ExecContext(ctx, fileHash, lineNumber)
+			if err != nil {
+				return fmt.Errorf("failed to record log entry line: %w", err)
+			}
+			if claimed, err := result.RowsAffected(); err != nil {
+				return fmt.Errorf("failed to record log entry line: %w", err)
+			} else if claimed == 0 {
+				continue
+			}
+		}
+		args := []interface{}{
+			entry.Timestamp, entry.LogType, entry.SourceIP, entry.Method,
+			entry.Path, entry.StatusCode, entry.ResponseSize, entry.UserAgent,
+			entry.Referer, entry.ProcessingTime, entry.RawLog, entry.Metadata,
+			fileHash, lineNumber,
+		}
+		if postgres {
+			err = stmt.QueryRowContext(ctx, args...).Scan(&ids[i])
+		} else {
+			var result sql.Result
+			if result, err = stmt.ExecContext(ctx, args...); err == nil {
+				ids[i], err = result.LastInsertId()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert log entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert log entries: %w", err)
+	}
+	for i, entry := range entries {
+		entry.ID = ids[i]
+	}
+	return nil
+}
+
+// forgetLines removes from ingested_lines the lines of the entries a
+// condition selects, before they are deleted in the same transaction, so
+// that reading their file again stores them anew
+func (d *Database) forgetLines(ctx context.Context, tx *sql.Tx, condition string, args ...interface{}) error {
+	query := d.rebind(`DELETE FROM ingested_lines WHERE (file_hash, line_number) IN (
+		SELECT file_hash, line_number FROM log_entries WHERE file_hash IS NOT NULL AND ` + condition + `)`)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to forget the lines of deleted log entries: %w", err)
+	}
+	return nil
+}
+
+// deleteEntries deletes the entries a condition selects and forgets their
+// lines in one transaction, so a failure leaves both in place
+func (d *Database) deleteEntries(ctx context.Context, condition string, args ...interface{}) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete log entries: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := d.forgetLines(ctx, tx, condition, args...); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, d.rebind(`DELETE FROM log_entries WHERE `+condition), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete log entries: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete log entries: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete log entries: %w", err)
+	}
+	return deleted, nil
+}
+
+// Find returns entries matching the filter, most recent first
+func (d *Database) Find(ctx context.Context, filter *models.LogFilter) ([]*models.LogEntry, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	q := pageQuery(filterQuery(d.dialect(), selectFrom("log_entries", entryColumns, "created_at", "updated_at"), filter), filter)
+	var entries []*models.LogEntry
+	err := d.eachEntry(ctx, q.limit(filter.Limit).offset(filter.Offset), func(entry *models.LogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if filter.Before != nil {
+		slices.Reverse(entries)
+	}
+	return entries, nil
+}
+
+// pageQuery orders the query as the filter lists entries and narrows it
+// to the entries after or before the filter's position. Entries before it
+// are selected in the opposite order, so that a limit keeps the nearest,
+// and must be reversed.
+func pageQuery(q *selectQuery, filter *models.LogFilter) *selectQuery {
+	column := "timestamp"
+	if slices.Contains(models.SortFields, filter.SortBy) {
+		column = filter.SortBy
+	}
+	descending := !filter.SortAscending
+	position := filter.After
+	if filter.Before != nil {
+		position, descending = filter.Before, !descending
+	}
+
+	direction, op := "ASC", ">"
+	if descending {
+		direction, op = "DESC", "<"
+	}
+	if position != nil {
+		q.where("(timestamp "+op+" ? OR (timestamp = ? AND id "+op+" ?))", position.Timestamp, position.Timestamp, position.ID)
+	}
+	return q.orderBy(column + " " + direction + ", id " + direction)
+}
+
+// Stream calls fn with each entry matching the filter, most recent first,
+// as the rows are read
+func (d *Database) Stream(ctx context.Context, filter *models.LogFilter, fn func(*models.LogEntry) error) error {
+	if filter.Before != nil {
+		// Entries before the position are read oldest first, so the page
+		// is read whole to be reversed
+		page := *filter
+		if page.Limit <= 0 {
+			page.Limit = math.MaxInt32
+		}
+		entries, err := d.Find(ctx, &page)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	q := pageQuery(filterQuery(d.dialect(), selectFrom("log_entries", entryColumns, "created_at", "updated_at"), filter), filter)
+	if filter.Limit > 0 {
+		q = q.limit(filter.Limit)
+	}
+	return d.eachEntry(ctx, q.offset(filter.Offset), fn)
+}
+
+// eachEntry runs a query selecting entryColumns, created_at and
+// updated_at and calls fn with each entry as it is read
+func (d *Database) eachEntry(ctx context.Context, q *selectQuery, fn func(*models.LogEntry) error) error {
+	rows, err := d.queryContext(ctx, q)
+	if err != nil {
+		return fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.LogType, &entry.SourceIP, &entry.Method,
+			&entry.Path, &entry.StatusCode, &entry.ResponseSize, &entry.UserAgent, &entry.Referer,
+			&entry.ProcessingTime, &entry.RawLog, &entry.Metadata, &entry.FileHash, &entry.LineNumber,
+			&entry.CreatedAt, &entry.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan log entry: %w", err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Count counts the entries matching the filter
+func (d *Database) Count(ctx context.Context, filter *models.LogFilter) (int64, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	query, args := filterQuery(d.dialect(), selectFrom("log_entries", "COUNT(*)"), filter).build(d.dialect())
+	var count int64
+	if err := d.DB.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count logs: %w", err)
+	}
+	return count, nil
+}
+
+// Aggregate counts entries matching the filter by the value of a field,
+// most common first
+func (d *Database) Aggregate(ctx context.Context, filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error) {
+	condition, ok := facetConditions[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown facet field: %s", field)
+	}
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	column := d.dialect().quote(field)
+	q := filterQuery(d.dialect(), selectFrom("log_entries", column, "COUNT(*) AS entries"), filter).where(condition)
+	rows, err := d.queryContext(ctx, q.groupBy(column).orderBy("entries DESC, "+column).limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query facets: %w", err)
+	}
+	defer rows.Close()
+
+	var facets []models.FacetCount
+	for rows.Next() {
+		var facet models.FacetCount
+		if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet: %w", err)
+		}
+		facets = append(facets, facet)
+	}
+	return facets, rows.Err()
+}
+
+// DeleteOlderThan removes entries older than cutoff
+func (d *Database) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	if d.timescale {
+		return d.dropChunksBefore(ctx, cutoff)
+	}
+	if d.isPartitionedNow() {
+		return d.dropPartitionsBefore(ctx, cutoff)
+	}
+	return d.deleteEntries(ctx, "timestamp < ?", cutoff)
+}
+
+// DeleteMatching removes the entries matching the filter
+func (d *Database) DeleteMatching(ctx context.Context, filter *models.LogFilter) (int64, error) {
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	q := filterQuery(d.dialect(), selectFrom("log_entries"), filter)
+	condition := "1 = 1"
+	if len(q.conditions) > 0 {
+		condition = strings.Join(q.conditions, " AND ")
+	}
+	return d.deleteEntries(ctx, condition, q.args...)
+}
+
+// ScanAfter returns up to limit entries with IDs above afterID in ID order
+func (d *Database) ScanAfter(ctx context.Context, afterID int64, limit int) ([]*models.LogEntry, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	return d.scanEntries(ctx, selectFrom("log_entries", entryColumns).where("id > ?", afterID).orderBy("id").limit(limit))
+}
+
+// scanEntries runs a query selecting entryColumns
+func (d *Database) scanEntries(ctx context.Context, q *selectQuery) ([]*models.LogEntry, error) {
+	rows, err := d.queryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan log entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.LogType, &entry.SourceIP, &entry.Method,
+			&entry.Path, &entry.StatusCode, &entry.ResponseSize, &entry.UserAgent, &entry.Referer,
+			&entry.ProcessingTime, &entry.RawLog, &entry.Metadata, &entry.FileHash, &entry.LineNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteByIDs removes the entries with the given IDs
+func (d *Database) DeleteByIDs(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	return d.deleteEntries(ctx, "id IN ("+placeholders(len(ids))+")", anySlice(ids)...)
+}
+
+// UpdateContent replaces the content of stored entries in one transaction
+func (d *Database) UpdateContent(ctx context.Context, entries []*models.LogEntry) error {
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update log entries: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, d.rebind(`UPDATE log_entries
+		SET source_ip = ?, path = ?, user_agent = ?, referer = ?, raw_log = ?, metadata = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`))
+	if err != nil {
+		return fmt.Errorf("failed to update log entries: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.ExecContext(ctx, entry.SourceIP, entry.Path, entry.UserAgent, entry.Referer,
+			entry.RawLog, entry.Metadata, entry.ID); err != nil {
+			return fmt.Errorf("failed to update log entry %d: %w", entry.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update log entries: %w", err)
+	}
+	return nil
+}
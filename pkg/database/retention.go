@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// cutoffScope returns the condition selecting the log types a retention
+// cutoff applies to, with its arguments, which is empty for every log type
+func cutoffScope(cutoff models.RetentionCutoff) (string, []interface{}) {
+	if cutoff.LogType != "" {
+		return "log_type = ?", []interface{}{cutoff.LogType}
+	}
+	if len(cutoff.Except) > 0 {
+		return "log_type NOT IN (" + placeholders(len(cutoff.Except)) + ")", anySlice(cutoff.Except)
+	}
+	return "", nil
+}
+
+// GetRetentionStats counts the entries a cutoff applies to and those of
+// them it expires
+func (d *Database) GetRetentionStats(cutoff models.RetentionCutoff) (*models.RetentionStats, error) {
+	scope, scopeArgs := cutoffScope(cutoff)
+	query := `SELECT COUNT(*), MIN(timestamp),
+		COALESCE(SUM(CASE WHEN timestamp < ? THEN 1 ELSE 0 END), 0) FROM log_entries`
+	if scope != "" {
+		query += " WHERE " + scope
+	}
+
+	var stats models.RetentionStats
+	var oldest sqliteTime
+	args := append([]interface{}{cutoff.Before}, scopeArgs...)
+	if err := d.DB.QueryRow(d.rebind(query), args...).Scan(&stats.TotalEntries, &oldest, &stats.ExpiredEntries); err != nil {
+		return nil, fmt.Errorf("failed to query retention stats: %w", err)
+	}
+	if oldest.Valid {
+		stats.OldestEntry = &oldest.Time
+	}
+	return &stats, nil
+}
+
+// ExpireEntries removes the entries a cutoff expires. A cutoff of every
+// log type drops whole chunks or partitions where it can.
+func (d *Database) ExpireEntries(ctx context.Context, cutoff models.RetentionCutoff) (int64, error) {
+	scope, scopeArgs := cutoffScope(cutoff)
+	if scope == "" {
+		return d.DeleteOlderThan(ctx, cutoff.Before)
+	}
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	args := append([]interface{}{cutoff.Before}, scopeArgs...)
+	return d.deleteEntries(ctx, "timestamp < ? AND "+scope, args...)
+}
+
+// ScanExpired returns up to limit of the entries a cutoff expires with IDs
+// above afterID, in ID order
+func (d *Database) ScanExpired(ctx context.Context, cutoff models.RetentionCutoff, afterID int64, limit int) ([]*models.LogEntry, error) {
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	q := selectFrom("log_entries", entryColumns).where("timestamp < ?", cutoff.Before).where("id > ?", afterID)
+	if scope, scopeArgs := cutoffScope(cutoff); scope != "" {
+		q.where(scope, scopeArgs...)
+	}
+	return d.scanEntries(ctx, q.orderBy("id").limit(limit))
+}
+
+// GetRetentionPolicies returns the stored retention policies ordered by log
+// type
+func (d *Database) GetRetentionPolicies() ([]*models.RetentionPolicy, error) {
+	rows, err := d.DB.Query(`SELECT log_type, days, COALESCE(updated_by, ''), updated_at
+		FROM retention_policies ORDER BY log_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.RetentionPolicy
+	for rows.Next() {
+		var policy models.RetentionPolicy
+		if err := rows.Scan(&policy.LogType, &policy.Days, &policy.UpdatedBy, &policy.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// SetRetentionPolicy stores a policy, replacing any for the same log type
+func (d *Database) SetRetentionPolicy(policy *models.RetentionPolicy) error {
+	query := `INSERT INTO retention_policies (log_type, days, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE days = VALUES(days), updated_by = VALUES(updated_by), updated_at = VALUES(updated_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO retention_policies (log_type, days, updated_by, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (log_type) DO UPDATE
+			SET days = EXCLUDED.days, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), policy.LogType, policy.Days, policy.UpdatedBy, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set retention policy: %w", err)
+	}
+	return nil
+}
+
+// DeleteRetentionPolicy removes a policy, reporting whether it existed
+func (d *Database) DeleteRetentionPolicy(logType string) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM retention_policies WHERE log_type = ?`), logType)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+	return affected > 0, nil
+}
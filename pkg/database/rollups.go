@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// maxRollupPath is the length paths are cut to in traffic rollups, which
+// key their rows by path
+const maxRollupPath = 500
+
+// rollupTables are the tables of the traffic rollup intervals
+var rollupTables = map[string]string{
+	models.RollupHour: "traffic_rollups_hourly",
+	models.RollupDay:  "traffic_rollups_daily",
+}
+
+func rollupTable(interval string) (string, error) {
+	table, ok := rollupTables[interval]
+	if !ok {
+		return "", fmt.Errorf("unknown rollup interval %q", interval)
+	}
+	return table, nil
+}
+
+// RefreshTrafficRollups replaces a bucket's rollups with totals of its
+// entries in one transaction, so readers never see the bucket half done
+func (d *Database) RefreshTrafficRollups(ctx context.Context, interval string, bucket time.Time) error {
+	table, err := rollupTable(interval)
+	if err != nil {
+		return err
+	}
+	bucket = bucket.UTC()
+	end := bucket.Add(models.RollupIntervals[interval])
+
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, d.rebind(`DELETE FROM `+table+` WHERE bucket = ?`), bucket); err != nil {
+		return fmt.Errorf("failed to clear traffic rollups: %w", err)
+	}
+
+	// The bucket is passed in rather than computed from the timestamps,
+	// which needs no dialect's date functions
+	bucketValue := "?"
+	if d.dialect() == postgresDialect {
+		bucketValue = "CAST(? AS TIMESTAMP)"
+	}
+	path := fmt.Sprintf("SUBSTR(COALESCE(path, ''), 1, %d)", maxRollupPath)
+	query := `INSERT INTO ` + table + ` (bucket, log_type, path, status_code, requests, errors, bytes, unique_ips)
+		SELECT ` + bucketValue + `, log_type, ` + path + `, COALESCE(status_code, 0), COUNT(*),
+			SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END),
+			COALESCE(SUM(response_size), 0),
+			COUNT(DISTINCT NULLIF(source_ip, ''))
+		FROM log_entries WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY log_type, ` + path + `, COALESCE(status_code, 0)`
+	if _, err := tx.ExecContext(ctx, d.rebind(query), bucket, bucket, end); err != nil {
+		return fmt.Errorf("failed to refresh traffic rollups: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit traffic rollups: %w", err)
+	}
+	return nil
+}
+
+// SummarizeTraffic sums the rollups of the query's buckets
+func (d *Database) SummarizeTraffic(ctx context.Context, query *models.TrafficQuery) ([]models.TrafficRollup, error) {
+	table, err := rollupTable(query.Interval)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range query.GroupBy {
+		if !slices.Contains(models.TrafficGroups, field) {
+			return nil, fmt.Errorf("cannot group traffic by %q", field)
+		}
+	}
+	ctx, cancel := d.readContext(ctx)
+	defer cancel()
+
+	columns := append(slices.Clone(query.GroupBy),
+		"COALESCE(SUM(requests), 0) AS total_requests", "COALESCE(SUM(errors), 0)",
+		"COALESCE(SUM(bytes), 0)", "COALESCE(SUM(unique_ips), 0)")
+	q := selectFrom(table, columns...).where("bucket >= ?", query.Start.UTC()).where("bucket < ?", query.End.UTC())
+	if query.LogType != "" {
+		q.where("log_type = ?", query.LogType)
+	}
+	if len(query.GroupBy) > 0 {
+		group := strings.Join(query.GroupBy, ", ")
+		order := "total_requests DESC, " + group
+		if slices.Contains(query.GroupBy, "bucket") {
+			order = group
+		}
+		q.groupBy(group).orderBy(order)
+	}
+	if query.Limit > 0 {
+		q.limit(query.Limit)
+	}
+
+	rows, err := d.queryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traffic rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []models.TrafficRollup
+	for rows.Next() {
+		var rollup models.TrafficRollup
+		var bucket sqliteTime
+		var dest []interface{}
+		for _, field := range query.GroupBy {
+			switch field {
+			case "bucket":
+				dest = append(dest, &bucket)
+			case "log_type":
+				dest = append(dest, &rollup.LogType)
+			case "path":
+				dest = append(dest, &rollup.Path)
+			case "status_code":
+				dest = append(dest, &rollup.StatusCode)
+			}
+		}
+		dest = append(dest, &rollup.Requests, &rollup.Errors, &rollup.Bytes, &rollup.UniqueIPs)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan traffic rollup: %w", err)
+		}
+		if bucket.Valid {
+			rollup.Bucket = bucket.Time.UTC()
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+// DeleteTrafficRollups removes the interval's rollups of buckets before
+// cutoff
+func (d *Database) DeleteTrafficRollups(ctx context.Context, interval string, cutoff time.Time) (int64, error) {
+	table, err := rollupTable(interval)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := d.writeContext(ctx)
+	defer cancel()
+
+	result, err := d.DB.ExecContext(ctx, d.rebind(`DELETE FROM `+table+` WHERE bucket < ?`), cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete traffic rollups: %w", err)
+	}
+	return result.RowsAffected()
+}
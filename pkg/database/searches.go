@@ -0,0 +1,98 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// savedSearchColumns are the columns of saved_searches, in the order
+// scanSavedSearch reads them
+const savedSearchColumns = `owner, name, COALESCE(description, ''), filter_json, created_at, updated_at`
+
+// GetSavedSearches returns an owner's saved searches, ordered by name
+func (d *Database) GetSavedSearches(owner string) ([]*models.SavedSearch, error) {
+	rows, err := d.DB.Query(d.rebind(`SELECT `+savedSearchColumns+` FROM saved_searches WHERE owner = ? ORDER BY name`), owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []*models.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+// GetSavedSearch returns the saved search with the given owner and name,
+// or sql.ErrNoRows
+func (d *Database) GetSavedSearch(owner, name string) (*models.SavedSearch, error) {
+	search, err := scanSavedSearch(d.DB.QueryRow(d.rebind(`SELECT `+savedSearchColumns+`
+		FROM saved_searches WHERE owner = ? AND name = ?`), owner, name))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return search, nil
+}
+
+func scanSavedSearch(row interface{ Scan(...interface{}) error }) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	var filter string
+	if err := row.Scan(&search.Owner, &search.Name, &search.Description, &filter, &search.CreatedAt, &search.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &search.Filter); err != nil {
+		return nil, fmt.Errorf("invalid filter of saved search %q: %w", search.Name, err)
+	}
+	return &search, nil
+}
+
+// SaveSavedSearch stores a saved search, replacing any with the same owner
+// and name but keeping its created_at
+func (d *Database) SaveSavedSearch(search *models.SavedSearch) error {
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search filter: %w", err)
+	}
+
+	query := `INSERT INTO saved_searches (owner, name, description, filter_json, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE description = VALUES(description), filter_json = VALUES(filter_json), updated_at = VALUES(updated_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO saved_searches (owner, name, description, filter_json, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (owner, name) DO UPDATE
+			SET description = EXCLUDED.description, filter_json = EXCLUDED.filter_json, updated_at = EXCLUDED.updated_at`
+	}
+
+	_, err = d.DB.Exec(d.rebind(query), search.Owner, search.Name, search.Description, string(filter), search.CreatedAt, search.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save saved search: %w", err)
+	}
+	return nil
+}
+
+// DeleteSavedSearch removes a saved search, reporting whether it existed
+func (d *Database) DeleteSavedSearch(owner, name string) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM saved_searches WHERE owner = ? AND name = ?`), owner, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDefaults are the connection parameters a SQLite database is opened
+// with unless the DSN or params set them. WAL lets reads run alongside the
+// single writer, and writers wait for each other instead of failing.
+var sqliteDefaults = url.Values{
+	"_busy_timeout": {"5000"},
+	"_journal_mode": {"WAL"},
+	"_foreign_keys": {"on"},
+	"_txlock":       {"immediate"},
+}
+
+// sqliteConnector opens connections to a SQLite database file
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func newSQLiteConnector(cfg *config.Config) (driver.Connector, error) {
+	dsn, err := sqliteDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConnector{dsn: dsn, driver: &sqlite3.SQLiteDriver{}}, nil
+}
+
+// sqliteDSN returns the configured DSN, or the database file, as a file:
+// URI with params and the defaults applied
+func sqliteDSN(cfg *config.Config) (string, error) {
+	db := cfg.Database
+	dsn := cfg.GetDSN()
+	if dsn == "" {
+		return "", fmt.Errorf("sqlite requires a database file")
+	}
+
+	path, query, _ := strings.Cut(dsn, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid sqlite DSN: %w", err)
+	}
+	params, err := url.ParseQuery(db.Params)
+	if err != nil {
+		return "", fmt.Errorf("invalid database params: %w", err)
+	}
+	for key, value := range params {
+		values[key] = value
+	}
+	for key, value := range sqliteDefaults {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	return path + "?" + values.Encode(), nil
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sqliteConn stores times in UTC. SQLite keeps them as text, which only
+// sorts and compares in time order when every value has the same offset.
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue implements driver.NamedValueChecker
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC()
+	}
+	nv.Value = value
+	return nil
+}
+
+// sqliteTime scans a timestamp that SQLite returns as text because it has
+// lost its column type, such as MIN(timestamp)
+type sqliteTime struct {
+	sql.NullTime
+}
+
+// Scan implements sql.Scanner
+func (t *sqliteTime) Scan(value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return t.NullTime.Scan(value)
+	}
+
+	text = strings.TrimSuffix(text, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if parsed, err := time.ParseInLocation(format, text, time.UTC); err == nil {
+			t.Time, t.Valid = parsed, true
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp %q", text)
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSQLite opens a database in a new file
+func openSQLite(t *testing.T) *Database {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Type:        "sqlite",
+		Database:    filepath.Join(t.TempDir(), "log_analyzer.db"),
+		AutoMigrate: true,
+	}}
+	db, err := NewDatabase(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return openSQLite(t)
+	})
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{Type: "sqlite", Database: "/var/lib/log-analyzer/logs.db"}}
+	dsn, err := sqliteDSN(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "file:/var/lib/log-analyzer/logs.db?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL&_txlock=immediate", dsn)
+
+	// The DSN and params override the defaults
+	cfg.Database.DSN = "file:logs.db?_journal_mode=DELETE&mode=rwc"
+	cfg.Database.Params = "_busy_timeout=100"
+	dsn, err = sqliteDSN(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "file:logs.db?_busy_timeout=100&_foreign_keys=on&_journal_mode=DELETE&_txlock=immediate&mode=rwc", dsn)
+
+	_, err = sqliteDSN(&config.Config{Database: config.DatabaseConfig{Type: "sqlite"}})
+	assert.Error(t, err)
+}
+
+func TestSQLiteTimeRanges(t *testing.T) {
+	db := openSQLite(t)
+
+	// Times in other zones are stored in UTC, so they compare in time order
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	east := time.FixedZone("UTC+5", 5*60*60)
+	for i, ts := range []time.Time{base.Add(-time.Minute).In(east), base, base.Add(time.Minute).In(east)} {
+		require.NoError(t, db.InsertLogEntry(&models.LogEntry{
+			Timestamp: ts, LogType: "nginx", SourceIP: "10.0.0.1", Path: "/" + string(rune('a'+i)),
+			Metadata: models.LogMetadata{"country": "NZ"},
+		}))
+	}
+
+	end := base.Add(time.Hour).In(east)
+	entries, err := db.GetEntriesByTypeOrStatus([]string{"nginx"}, nil, base, end, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/b", entries[0].Path)
+	assert.True(t, entries[0].Timestamp.Equal(base))
+
+	stats, err := db.GetRetentionStats(models.RetentionCutoff{Before: base})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalEntries)
+	assert.Equal(t, int64(1), stats.ExpiredEntries)
+	require.NotNil(t, stats.OldestEntry)
+	assert.True(t, stats.OldestEntry.Equal(base.Add(-time.Minute)))
+
+	countries, err := db.GetCountryActivity(base.Add(-time.Hour), base, end)
+	require.NoError(t, err)
+	require.Len(t, countries, 1)
+	assert.Equal(t, "NZ", countries[0].Country)
+	assert.True(t, countries[0].FirstSeen.Equal(base.Add(-time.Minute)))
+	assert.Equal(t, int64(2), countries[0].Requests)
+}
+
+func TestSQLiteOrphanedAlertEvents(t *testing.T) {
+	db := openSQLite(t)
+	rule := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300, IsActive: true}
+	require.NoError(t, db.CreateAlertRule(rule))
+	require.NoError(t, db.InsertAlertEvent(&models.AlertEvent{RuleID: rule.ID, Message: "fired", Severity: "warning"}))
+
+	// Orphans only appear where the foreign key was not enforced
+	conn, err := db.DB.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(context.Background(), `PRAGMA foreign_keys = OFF`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(),
+		`INSERT INTO alert_history (rule_id, message, severity) VALUES (42, 'orphan', 'warning'), (42, 'orphan', 'warning')`)
+	require.NoError(t, err)
+
+	orphans, err := db.GetOrphanedAlertEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []models.OrphanedAlertEvents{{RuleID: 42, Events: 2}}, orphans)
+}
+
+func TestSQLiteDeleteForgetsLinesInTheSameTransaction(t *testing.T) {
+	db := openSQLite(t)
+	ctx := context.Background()
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	line := func(n int64) *models.LogEntry {
+		return &models.LogEntry{Timestamp: base.Add(time.Duration(n) * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1", FileHash: "abc", LineNumber: n}
+	}
+	require.NoError(t, db.InsertBatch(ctx, []*models.LogEntry{line(1), line(2), line(3)}))
+	lines := func() int {
+		var count int
+		require.NoError(t, db.DB.QueryRow(`SELECT COUNT(*) FROM ingested_lines`).Scan(&count))
+		return count
+	}
+
+	// A delete that fails keeps the lines of the entries it left
+	_, err := db.DB.Exec(`CREATE TRIGGER refuse_delete BEFORE DELETE ON log_entries BEGIN SELECT RAISE(ABORT, 'refused'); END`)
+	require.NoError(t, err)
+	_, err = db.DeleteMatching(ctx, &models.LogFilter{LogType: "nginx"})
+	assert.ErrorContains(t, err, "refused")
+	_, err = db.ExpireEntries(ctx, models.RetentionCutoff{Before: base.Add(time.Hour), LogType: "nginx"})
+	assert.ErrorContains(t, err, "refused")
+	_, err = db.DeleteOlderThan(ctx, base.Add(time.Hour))
+	assert.ErrorContains(t, err, "refused")
+	assert.Equal(t, 3, lines())
+	_, err = db.DB.Exec(`DROP TRIGGER refuse_delete`)
+	require.NoError(t, err)
+
+	// Each way of deleting forgets the lines of what it deleted
+	end := base.Add(90 * time.Second)
+	deleted, err := db.DeleteMatching(ctx, &models.LogFilter{EndTime: &end})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, 2, lines())
+	deleted, err = db.ExpireEntries(ctx, models.RetentionCutoff{Before: base.Add(150 * time.Second), LogType: "nginx"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, 1, lines())
+	deleted, err = db.DeleteOlderThan(ctx, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, 0, lines())
+
+	// Stored again, the lines are not skipped
+	entries := []*models.LogEntry{line(1), line(2)}
+	require.NoError(t, db.InsertBatch(ctx, entries))
+	assert.NotZero(t, entries[0].ID)
+	assert.NotZero(t, entries[1].ID)
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// GetReportTemplates returns the stored report templates without their
+// content, ordered by report type and name
+func (d *Database) GetReportTemplates() ([]*models.ReportTemplate, error) {
+	rows, err := d.DB.Query(`SELECT report_type, name, COALESCE(updated_by, ''), updated_at
+		FROM report_templates ORDER BY report_type, name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.ReportTemplate
+	for rows.Next() {
+		var tmpl models.ReportTemplate
+		if err := rows.Scan(&tmpl.ReportType, &tmpl.Name, &tmpl.UpdatedBy, &tmpl.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report template: %w", err)
+		}
+		templates = append(templates, &tmpl)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetReportTemplate returns the report template with the given report type
+// and name, or sql.ErrNoRows
+func (d *Database) GetReportTemplate(reportType, name string) (*models.ReportTemplate, error) {
+	var tmpl models.ReportTemplate
+	err := d.DB.QueryRow(d.rebind(`SELECT report_type, name, content, COALESCE(updated_by, ''), updated_at
+		FROM report_templates WHERE report_type = ? AND name = ?`), reportType, name).
+		Scan(&tmpl.ReportType, &tmpl.Name, &tmpl.Content, &tmpl.UpdatedBy, &tmpl.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// SaveReportTemplate stores a report template, replacing any with the same
+// report type and name
+func (d *Database) SaveReportTemplate(tmpl *models.ReportTemplate) error {
+	query := `INSERT INTO report_templates (report_type, name, content, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE content = VALUES(content), updated_by = VALUES(updated_by), updated_at = VALUES(updated_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO report_templates (report_type, name, content, updated_by, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (report_type, name) DO UPDATE
+			SET content = EXCLUDED.content, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+	}
+
+	_, err := d.DB.Exec(d.rebind(query), tmpl.ReportType, tmpl.Name, tmpl.Content, tmpl.UpdatedBy, tmpl.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save report template: %w", err)
+	}
+	return nil
+}
+
+// DeleteReportTemplate removes a report template, reporting whether it
+// existed
+func (d *Database) DeleteReportTemplate(reportType, name string) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM report_templates WHERE report_type = ? AND name = ?`), reportType, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete report template: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete report template: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,276 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+const (
+	// defaultChunkInterval is the hours of log entries per chunk
+	defaultChunkInterval = 24
+	// methodRollup is the continuous aggregate of hourly method statistics
+	methodRollup = "log_entries_hourly"
+	// entryRollup is the continuous aggregate of hourly entry totals
+	entryRollup = "log_entries_hourly_totals"
+)
+
+// timescaleQueries create the hourly roll-ups behind GetMethodStats and
+// AggregateLogs. They are refreshed every 30 minutes. Queries also read
+// the hours not materialized yet, so results are always current. Refreshes
+// only recompute invalidated hours, so old entries imported late are
+// picked up too.
+var timescaleQueries = []string{
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS ` + methodRollup + `
+		WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+		SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, log_type, method,
+			COUNT(*) AS requests,
+			SUM(CASE WHEN processing_time > 0 THEN processing_time ELSE 0 END) AS timed_total,
+			SUM(CASE WHEN processing_time > 0 THEN 1 ELSE 0 END) AS timed_requests,
+			MAX(processing_time) AS max_processing_time,
+			SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END) AS errors,
+			SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END) AS server_errors
+		FROM log_entries
+		WHERE method IS NOT NULL AND method <> '' AND status_code > 0
+		GROUP BY bucket, log_type, method
+		WITH NO DATA`,
+	`SELECT add_continuous_aggregate_policy('` + methodRollup + `',
+		start_offset => NULL, end_offset => INTERVAL '1 hour',
+		schedule_interval => INTERVAL '30 minutes', if_not_exists => true)`,
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS ` + entryRollup + `
+		WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+		SELECT time_bucket(INTERVAL '1 hour', timestamp) AS bucket, log_type, method, status_code,
+			COUNT(*) AS entries,
+			COUNT(processing_time) AS timed_entries,
+			SUM(processing_time) AS processing_total,
+			MAX(processing_time) AS max_processing_time,
+			COUNT(response_size) AS sized_entries,
+			SUM(response_size) AS response_total
+		FROM log_entries
+		GROUP BY bucket, log_type, method, status_code
+		WITH NO DATA`,
+	`SELECT add_continuous_aggregate_policy('` + entryRollup + `',
+		start_offset => NULL, end_offset => INTERVAL '1 hour',
+		schedule_interval => INTERVAL '30 minutes', if_not_exists => true)`,
+}
+
+// setupTimescale turns log_entries into a hypertable partitioned by
+// timestamp when TimescaleDB is enabled, or in auto mode when the
+// extension is installed. Time-range queries then only scan the chunks
+// they cover, and retention drops whole chunks instead of deleting rows.
+func (d *Database) setupTimescale() error {
+	cfg := d.Config.Database.Timescale
+	switch cfg.Mode {
+	case "disabled":
+		return nil
+	case "enabled":
+		if _, err := d.DB.Exec(`CREATE EXTENSION IF NOT EXISTS timescaledb`); err != nil {
+			return fmt.Errorf("failed to enable timescaledb: %w", err)
+		}
+	default:
+		var installed bool
+		if err := d.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`).Scan(&installed); err != nil {
+			return fmt.Errorf("failed to detect timescaledb: %w", err)
+		}
+		if !installed {
+			return nil
+		}
+	}
+
+	var compressed sql.NullBool
+	err := d.DB.QueryRow(`SELECT compression_enabled FROM timescaledb_information.hypertables
+		WHERE hypertable_schema = current_schema() AND hypertable_name = 'log_entries'`).Scan(&compressed)
+	if err == sql.ErrNoRows {
+		err = d.createHypertable(cfg.ChunkInterval)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cfg.CompressAfter > 0 {
+		queries := []string{
+			// The policy is replaced so a changed compress_after applies
+			`SELECT remove_compression_policy('log_entries', if_exists => true)`,
+			fmt.Sprintf(`SELECT add_compression_policy('log_entries', INTERVAL '%d days')`, cfg.CompressAfter),
+		}
+		// Settings cannot change once chunks are compressed
+		if !compressed.Bool {
+			queries = append([]string{`ALTER TABLE log_entries SET (timescaledb.compress,
+				timescaledb.compress_segmentby = 'log_type', timescaledb.compress_orderby = 'timestamp DESC, id DESC')`}, queries...)
+		}
+		if err := d.execAll(queries); err != nil {
+			return err
+		}
+	} else if compressed.Bool {
+		if _, err := d.DB.Exec(`SELECT remove_compression_policy('log_entries', if_exists => true)`); err != nil {
+			return fmt.Errorf("failed to remove compression policy: %w", err)
+		}
+	}
+
+	if err := d.execAll(timescaleQueries); err != nil {
+		return err
+	}
+	d.timescale = true
+	return nil
+}
+
+// createHypertable converts log_entries, moving existing entries into chunks
+func (d *Database) createHypertable(chunkInterval int) error {
+	if chunkInterval == 0 {
+		chunkInterval = defaultChunkInterval
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := []string{
+		// Unique indexes of a hypertable must include its time column
+		`ALTER TABLE log_entries DROP CONSTRAINT IF EXISTS log_entries_pkey, ADD PRIMARY KEY (id, timestamp)`,
+		fmt.Sprintf(`SELECT create_hypertable('log_entries', 'timestamp', chunk_time_interval => INTERVAL '%d hours',
+			create_default_indexes => false, migrate_data => true)`, chunkInterval),
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("failed to create log_entries hypertable: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *Database) execAll(queries []string) error {
+	for _, query := range queries {
+		if _, err := d.DB.Exec(query); err != nil {
+			return fmt.Errorf("failed to execute query: %s, error: %w", query, err)
+		}
+	}
+	return nil
+}
+
+// dropChunksBefore removes entries older than cutoff from a hypertable. The
+// chunks entirely before cutoff are dropped and the rest deleted row by
+// row. Roll-up chunks before cutoff are dropped too, and hours emptied by
+// the delete are recomputed on the next refresh.
+func (d *Database) dropChunksBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Dropping chunks reports no row count
+	var count int64
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM log_entries WHERE timestamp < $1`, cutoff).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count old log entries: %w", err)
+	}
+	if err := d.forgetLines(ctx, tx, "timestamp < ?", cutoff); err != nil {
+		return 0, err
+	}
+
+	queries := []string{
+		`SELECT drop_chunks('log_entries', older_than => $1::timestamp)`,
+		`DELETE FROM log_entries WHERE timestamp < $1`,
+		`SELECT drop_chunks('` + methodRollup + `', older_than => $1::timestamp)`,
+		`SELECT drop_chunks('` + entryRollup + `', older_than => $1::timestamp)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, cutoff); err != nil {
+			return 0, fmt.Errorf("failed to delete old log entries: %w", err)
+		}
+	}
+	return count, tx.Commit()
+}
+
+// hourAligned reports whether a window can be answered from hourly roll-ups
+func hourAligned(start, end time.Time) bool {
+	return start.Equal(start.Truncate(time.Hour)) && end.Equal(end.Truncate(time.Hour))
+}
+
+// epochBucket is dialect.epochBucket, with time_bucket on TimescaleDB.
+// Buckets count from the Unix epoch like on other databases, instead of
+// from time_bucket's default origin.
+func (d *Database) epochBucket(column string, seconds int64) string {
+	if !d.timescale {
+		return d.dialect().epochBucket(column, seconds)
+	}
+	return "CAST(EXTRACT(EPOCH FROM time_bucket(INTERVAL '" + strconv.FormatInt(seconds, 10) + " seconds', " +
+		column + ", TIMESTAMP '1970-01-01')) AS BIGINT)"
+}
+
+// rollupGroups and rollupMetrics are aggregateGroups and aggregateMetrics
+// over the rows of the hourly entry totals
+var (
+	rollupGroups = map[string]string{
+		"log_type":    "COALESCE(log_type, '')",
+		"method":      "COALESCE(method, '')",
+		"status_code": "COALESCE(status_code, 0)",
+	}
+	rollupMetrics = map[string]string{
+		"count":               "SUM(entries)",
+		"errors":              "COALESCE(SUM(CASE WHEN status_code >= 400 THEN entries ELSE 0 END), 0)",
+		"avg_processing_time": "COALESCE(SUM(processing_total) / NULLIF(SUM(timed_entries), 0), 0)",
+		"max_processing_time": "COALESCE(MAX(max_processing_time), 0)",
+		"avg_response_size":   "COALESCE(SUM(response_total) / NULLIF(SUM(sized_entries), 0), 0)",
+		"sum_response_size":   "COALESCE(SUM(response_total), 0)",
+	}
+)
+
+// rollupAggregatable reports whether an aggregate can be answered from the
+// hourly entry totals: its buckets and time range are whole hours, and it
+// only filters, groups and measures what the totals keep
+func rollupAggregatable(query *models.AggregateQuery) bool {
+	if query.Interval%time.Hour != 0 {
+		return false
+	}
+	filter := query.Filter
+	if filter.StartTime != nil && !filter.StartTime.Equal(filter.StartTime.Truncate(time.Hour)) {
+		return false
+	}
+	if filter.EndTime != nil && !filter.EndTime.Equal(filter.EndTime.Truncate(time.Hour)) {
+		return false
+	}
+	if filter.SourceIP != "" || filter.Path != "" {
+		return false
+	}
+	for _, field := range query.GroupBy {
+		if _, ok := rollupGroups[field]; !ok {
+			return false
+		}
+	}
+	for _, metric := range query.Metrics {
+		if _, ok := rollupMetrics[metric]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rollupFilterQuery narrows the query to the hourly entry totals matching
+// a filter accepted by rollupAggregatable
+func rollupFilterQuery(q *selectQuery, filter *models.LogFilter) *selectQuery {
+	if filter.StartTime != nil {
+		q.where("bucket >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		q.where("bucket < ?", *filter.EndTime)
+	}
+	if filter.LogType != "" {
+		q.where("log_type = ?", filter.LogType)
+	}
+	if filter.StatusCode != nil {
+		q.where("status_code = ?", *filter.StatusCode)
+	}
+	if filter.MinStatusCode > 0 {
+		q.where("status_code >= ?", filter.MinStatusCode)
+	}
+	if filter.Method != "" {
+		q.where("method = ?", filter.Method)
+	}
+	return q
+}
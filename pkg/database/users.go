@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// userColumns are the columns of users, in the order scanUser reads them
+const userColumns = `subject, COALESCE(email, ''), COALESCE(name, ''), role, last_sign_in_at, COALESCE(updated_by, ''), updated_at`
+
+// GetRoles returns the roles, ordered by level
+func (d *Database) GetRoles() ([]*models.Role, error) {
+	rows, err := d.DB.Query(`SELECT name, level, description FROM roles ORDER BY level`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Name, &role.Level, &role.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, &role)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetUsers returns the users, ordered by subject
+func (d *Database) GetUsers() ([]*models.User, error) {
+	rows, err := d.DB.Query(`SELECT ` + userColumns + ` FROM users ORDER BY subject`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetUser returns the user with the given subject, or sql.ErrNoRows
+func (d *Database) GetUser(subject string) (*models.User, error) {
+	user, err := scanUser(d.DB.QueryRow(d.rebind(`SELECT `+userColumns+` FROM users WHERE subject = ?`), subject))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	var user models.User
+	var lastSignIn sqliteTime
+	if err := row.Scan(&user.Subject, &user.Email, &user.Name, &user.Role, &lastSignIn, &user.UpdatedBy, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastSignIn.Valid {
+		user.LastSignInAt = &lastSignIn.Time
+	}
+	return &user, nil
+}
+
+// SaveUser stores a user, replacing any with the same subject. The role
+// must be one of the roles, which the foreign key enforces.
+func (d *Database) SaveUser(user *models.User) error {
+	query := `INSERT INTO users (subject, email, name, role, last_sign_in_at, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE email = VALUES(email), name = VALUES(name), role = VALUES(role),
+			last_sign_in_at = VALUES(last_sign_in_at), updated_by = VALUES(updated_by), updated_at = VALUES(updated_at)`
+	if d.dialect() != mysqlDialect {
+		query = `INSERT INTO users (subject, email, name, role, last_sign_in_at, updated_by, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (subject) DO UPDATE
+			SET email = EXCLUDED.email, name = EXCLUDED.name, role = EXCLUDED.role,
+				last_sign_in_at = EXCLUDED.last_sign_in_at, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+	}
+
+	var lastSignIn sql.NullTime
+	if user.LastSignInAt != nil {
+		lastSignIn = sql.NullTime{Time: *user.LastSignInAt, Valid: true}
+	}
+	_, err := d.DB.Exec(d.rebind(query), user.Subject, user.Email, user.Name, user.Role, lastSignIn, user.UpdatedBy, user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user, reporting whether they existed
+func (d *Database) DeleteUser(subject string) (bool, error) {
+	result, err := d.DB.Exec(d.rebind(`DELETE FROM users WHERE subject = ?`), subject)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+	return affected > 0, nil
+}
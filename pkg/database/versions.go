@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+const versionColumns = `id, kind, object_id, version, action, actor, state, restored_from, created_at`
+
+// AppendConfigVersion stores a version as the latest of its object and
+// sets its ID and Version
+func (d *Database) AppendConfigVersion(version *models.ConfigVersion) error {
+	d.versionMu.Lock()
+	defer d.versionMu.Unlock()
+
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to append config version: %w", err)
+	}
+	defer tx.Rollback()
+
+	// (kind, object_id, version) is unique, so another server appending
+	// concurrently makes one insert fail instead of repeating a number
+	var latest int
+	if err := tx.QueryRow(d.rebind(`SELECT COALESCE(MAX(version), 0) FROM config_versions
+		WHERE kind = ? AND object_id = ?`), version.Kind, version.ObjectID).Scan(&latest); err != nil {
+		return fmt.Errorf("failed to number config version: %w", err)
+	}
+
+	var state, restoredFrom interface{}
+	if len(version.State) > 0 {
+		state = string(version.State)
+	}
+	if version.RestoredFrom > 0 {
+		restoredFrom = version.RestoredFrom
+	}
+	query := d.rebind(`INSERT INTO config_versions (kind, object_id, version, action, actor, state, restored_from, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	args := []interface{}{version.Kind, version.ObjectID, latest + 1, version.Action, version.Actor,
+		state, restoredFrom, version.CreatedAt}
+
+	var id int64
+	if d.dialect() == postgresDialect {
+		err = tx.QueryRow(query+" RETURNING id", args...).Scan(&id)
+	} else {
+		var result sql.Result
+		if result, err = tx.Exec(query, args...); err == nil {
+			id, err = result.LastInsertId()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append config version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to append config version: %w", err)
+	}
+	version.ID = id
+	version.Version = latest + 1
+	return nil
+}
+
+// GetConfigVersions returns up to limit versions of an object, of a kind
+// of object or of everything, most recent first
+func (d *Database) GetConfigVersions(kind, objectID string, limit int) ([]*models.ConfigVersion, error) {
+	query := `SELECT ` + versionColumns + ` FROM config_versions`
+	var args []interface{}
+	if kind != "" {
+		query += ` WHERE kind = ?`
+		args = append(args, kind)
+		if objectID != "" {
+			query += ` AND object_id = ?`
+			args = append(args, objectID)
+		}
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := d.DB.Query(d.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*models.ConfigVersion
+	for rows.Next() {
+		version, err := scanConfigVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan config version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetConfigVersion returns a version of an object, or sql.ErrNoRows
+func (d *Database) GetConfigVersion(kind, objectID string, version int) (*models.ConfigVersion, error) {
+	row := d.DB.QueryRow(d.rebind(`SELECT `+versionColumns+` FROM config_versions
+		WHERE kind = ? AND object_id = ? AND version = ?`), kind, objectID, version)
+	v, err := scanConfigVersion(row)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get config version: %w", err)
+	}
+	return v, err
+}
+
+// scanConfigVersion scans a row selected with versionColumns
+func scanConfigVersion(row interface{ Scan(...interface{}) error }) (*models.ConfigVersion, error) {
+	var version models.ConfigVersion
+	var state sql.NullString
+	var restoredFrom sql.NullInt64
+	if err := row.Scan(&version.ID, &version.Kind, &version.ObjectID, &version.Version, &version.Action,
+		&version.Actor, &state, &restoredFrom, &version.CreatedAt); err != nil {
+		return nil, err
+	}
+	if state.Valid {
+		version.State = []byte(state.String)
+	}
+	version.RestoredFrom = int(restoredFrom.Int64)
+	return &version, nil
+}
@@ -0,0 +1,124 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+)
+
+func testMasterKey(t *testing.T) *LocalKey {
+	key, err := NewLocalKey([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	return key
+}
+
+func entry(project, raw string, metadata map[string]interface{}) *models.LogEntry {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	if project != "" {
+		metadata["project"] = project
+	}
+	return &models.LogEntry{Timestamp: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), LogType: "nginx",
+		Path: "/checkout", StatusCode: 200, RawLog: raw, Metadata: metadata}
+}
+
+func TestStoreEncryptsAtRest(t *testing.T) {
+	backend := memory.New()
+	store := NewStore(backend, NewEncryptor(testMasterKey(t), backend, "", []string{"payments"}, []string{"email", "card"}))
+
+	payments := entry("payments", "GET /checkout card=4111", map[string]interface{}{"email": "a@example.com", "card": map[string]interface{}{"last4": "4111"}, "country": "DE"})
+	other := entry("search", "GET /search?q=x", map[string]interface{}{"email": "b@example.com"})
+	other.Path = "/search"
+	require.NoError(t, store.InsertLogEntries([]*models.LogEntry{payments, other}))
+	assert.NotZero(t, payments.ID, "IDs reach the caller's entries")
+	assert.Equal(t, "GET /checkout card=4111", payments.RawLog, "the caller's entry is left alone")
+
+	stored, err := backend.QueryLogs(&models.LogFilter{Limit: 10, Path: "/checkout"})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.True(t, strings.HasPrefix(stored[0].RawLog, prefix))
+	assert.NotContains(t, stored[0].RawLog, "4111")
+	assert.True(t, strings.HasPrefix(stored[0].Metadata["email"].(string), prefix))
+	assert.Equal(t, "DE", stored[0].Metadata["country"], "unselected fields stay in plain text")
+	assert.Equal(t, "payments", stored[0].Metadata["project"])
+
+	logs, err := store.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	for _, log := range logs {
+		if log.Metadata["project"] == "payments" {
+			assert.Equal(t, "GET /checkout card=4111", log.RawLog)
+			assert.Equal(t, "a@example.com", log.Metadata["email"])
+			assert.Equal(t, map[string]interface{}{"last4": "4111"}, log.Metadata["card"])
+		} else {
+			assert.Equal(t, "GET /search?q=x", log.RawLog, "other projects are stored as they are")
+		}
+	}
+
+	facets, err := store.GetFacets(&models.LogFilter{}, "path", 10)
+	require.NoError(t, err)
+	assert.Len(t, facets, 2, "aggregates need no decryption")
+}
+
+func TestDataKeyIsShared(t *testing.T) {
+	backend := memory.New()
+	first := NewStore(backend, NewEncryptor(testMasterKey(t), backend, "", []string{"payments"}, nil))
+	require.NoError(t, first.InsertLogEntry(entry("payments", "first", nil)))
+
+	key, err := backend.GetDataKey("payments")
+	require.NoError(t, err)
+	assert.Equal(t, testMasterKey(t).ID(), key.MasterKeyID)
+	assert.Len(t, key.WrappedKey, 12+32+16, "the data key is stored sealed with a nonce")
+
+	// A restarted server unwraps the stored key rather than making another
+	second := NewStore(backend, NewEncryptor(testMasterKey(t), backend, "", []string{"payments"}, nil))
+	later := entry("payments", "second", nil)
+	later.Timestamp = later.Timestamp.Add(time.Minute)
+	require.NoError(t, second.InsertLogEntry(later))
+	logs, err := second.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "second", logs[0].RawLog)
+	assert.Equal(t, "first", logs[1].RawLog)
+
+	// Entries can be read after a project stops being encrypted
+	reader := NewStore(backend, NewEncryptor(testMasterKey(t), backend, "", nil, nil))
+	logs, err = reader.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "first", logs[1].RawLog)
+
+	otherKey, err := NewLocalKey([]byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+	wrong := NewStore(backend, NewEncryptor(otherKey, backend, "", nil, nil))
+	_, err = wrong.QueryLogs(&models.LogFilter{Limit: 10})
+	assert.ErrorContains(t, err, "wrong master key")
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	backend := memory.New()
+	enc := NewEncryptor(testMasterKey(t), backend, "tenant", []string{"payments"}, []string{"email"})
+
+	encrypted, err := enc.Encrypt(entry("", "secret", map[string]interface{}{"tenant": "payments", "email": "a@example.com"}))
+	require.NoError(t, err)
+
+	// A value moved to another field no longer decrypts
+	moved := *encrypted
+	moved.Metadata = map[string]interface{}{"email": encrypted.RawLog}
+	assert.Error(t, enc.Decrypt(&moved))
+
+	corrupt := *encrypted
+	corrupt.Metadata = nil
+	corrupt.RawLog = encrypted.RawLog[:len(encrypted.RawLog)-4] + "AAAA"
+	assert.Error(t, enc.Decrypt(&corrupt))
+
+	require.NoError(t, enc.Decrypt(encrypted))
+	assert.Equal(t, "secret", encrypted.RawLog)
+	assert.Equal(t, "a@example.com", encrypted.Metadata["email"])
+}
@@ -0,0 +1,62 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsTimeout bounds each call to KMS
+const kmsTimeout = 10 * time.Second
+
+// KMSKey is an AWS KMS key. Data keys are sent to KMS to be wrapped and
+// unwrapped, so the master key never leaves it.
+type KMSKey struct {
+	KeyID  string
+	client *kms.Client
+}
+
+// NewKMSKey returns the KMS key with a key ID, ARN or alias, used with the
+// AWS SDK's default credentials. endpoint replaces
+// https://kms.<region>.amazonaws.com, such as for a VPC endpoint.
+func NewKMSKey(ctx context.Context, keyID, region, endpoint string) (*KMSKey, error) {
+	cfg, err := awsauth.Config(ctx, region, awsauth.Credentials{})
+	if err != nil {
+		return nil, err
+	}
+	client := kms.NewFromConfig(cfg, func(o *kms.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(strings.TrimSuffix(endpoint, "/"))
+		}
+	})
+	return &KMSKey{KeyID: keyID, client: client}, nil
+}
+
+func (k *KMSKey) ID() string {
+	return "aws_kms:" + k.KeyID
+}
+
+func (k *KMSKey) Wrap(dataKey []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	output, err := k.client.Encrypt(ctx, &kms.EncryptInput{KeyId: aws.String(k.KeyID), Plaintext: dataKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return output.CiphertextBlob, nil
+}
+
+func (k *KMSKey) Unwrap(wrapped []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	output, err := k.client.Decrypt(ctx, &kms.DecryptInput{KeyId: aws.String(k.KeyID), CiphertextBlob: wrapped})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return output.Plaintext, nil
+}
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMSKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request")
+		assert.Contains(t, r.Header.Get("Authorization"), "x-amz-target")
+
+		var request struct {
+			KeyId          string
+			Plaintext      []byte
+			CiphertextBlob []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "alias/logs", request.KeyId)
+
+		// The fake KMS wraps by reversing
+		reverse := func(b []byte) []byte {
+			out := make([]byte, len(b))
+			for i := range b {
+				out[len(b)-1-i] = b[i]
+			}
+			return out
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			json.NewEncoder(w).Encode(map[string]interface{}{"CiphertextBlob": reverse(request.Plaintext), "KeyId": "arn:aws:kms:eu-west-1:1:key/abc"})
+		case "TrentService.Decrypt":
+			if string(request.CiphertextBlob) == "denied" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type":"com.amazonaws.kms#AccessDeniedException","message":"not allowed"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"Plaintext": reverse(request.CiphertextBlob)})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	key, err := NewKMSKey(context.Background(), "alias/logs", "eu-west-1", server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "aws_kms:alias/logs", key.ID())
+
+	wrapped, err := key.Wrap([]byte("data-key"))
+	require.NoError(t, err)
+	assert.Equal(t, "yek-atad", string(wrapped))
+
+	dataKey, err := key.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "data-key", string(dataKey))
+
+	_, err = key.Unwrap([]byte("denied"))
+	var apiErr smithy.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "AccessDeniedException", apiErr.ErrorCode())
+	assert.Equal(t, "not allowed", apiErr.ErrorMessage())
+}
@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+)
+
+// MasterKey wraps the data keys of projects, so the database holding them
+// cannot decrypt anything without it
+type MasterKey interface {
+	// ID names the key. It is recorded with each data key it wraps.
+	ID() string
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// NewMasterKey returns the configured master key
+func NewMasterKey(cfg config.MasterKeyConfig) (MasterKey, error) {
+	switch cfg.Provider {
+	case "", "local":
+		return LoadLocalKey(cfg.KeyFile)
+	case "aws_kms":
+		return NewKMSKey(context.Background(), cfg.KeyID, cfg.Region, cfg.Endpoint)
+	}
+	return nil, fmt.Errorf("unsupported master key provider: %s", cfg.Provider)
+}
+
+// LocalKey is a master key held by the server, wrapping data keys with
+// AES-256-GCM
+type LocalKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// LoadLocalKey reads a base64-encoded 32-byte key from a file, such as
+// one created with "openssl rand -base64 32"
+func LoadLocalKey(path string) (*LocalKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("master key %s is not base64: %w", path, err)
+	}
+	return NewLocalKey(key)
+}
+
+// NewLocalKey returns a master key of 32 bytes
+func NewLocalKey(key []byte) (*LocalKey, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, not %d", len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	// A fingerprint tells keys apart without revealing them
+	sum := sha256.Sum256(key)
+	return &LocalKey{id: "local:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+func (k *LocalKey) ID() string {
+	return k.id
+}
+
+func (k *LocalKey) Wrap(dataKey []byte) ([]byte, error) {
+	return seal(k.aead, dataKey, []byte(k.id))
+}
+
+func (k *LocalKey) Unwrap(wrapped []byte) ([]byte, error) {
+	dataKey, err := open(k.aead, wrapped, []byte(k.id))
+	if err != nil {
+		return nil, errors.New("failed to unwrap data key: wrong master key or corrupt data key")
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, which it prepends
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts what seal returned
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
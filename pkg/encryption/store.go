@@ -0,0 +1,136 @@
+package encryption
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+)
+
+// Store encrypts entries as they are stored in the storage it wraps and
+// decrypts them as they are read. Queries that return neither raw logs nor
+// metadata pass through untouched.
+type Store struct {
+	storage.Storage
+	enc *Encryptor
+}
+
+var _ storage.Storage = (*Store)(nil)
+
+// NewStore wraps s so the entries enc is configured for are encrypted at
+// rest
+func NewStore(s storage.Storage, enc *Encryptor) *Store {
+	return &Store{Storage: s, enc: enc}
+}
+
+// encryptAll returns encrypted copies of entries
+func (s *Store) encryptAll(entries []*models.LogEntry) ([]*models.LogEntry, error) {
+	encrypted := make([]*models.LogEntry, len(entries))
+	for i, entry := range entries {
+		var err error
+		if encrypted[i], err = s.enc.Encrypt(entry); err != nil {
+			return nil, err
+		}
+	}
+	return encrypted, nil
+}
+
+// setIDs copies the IDs the backend gave the stored copies of entries
+func setIDs(entries, stored []*models.LogEntry) {
+	for i, entry := range entries {
+		entry.ID = stored[i].ID
+	}
+}
+
+// decrypted decrypts entries read by a query
+func (s *Store) decrypted(entries []*models.LogEntry, err error) ([]*models.LogEntry, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enc.DecryptAll(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Store) InsertLogEntry(entry *models.LogEntry) error {
+	encrypted, err := s.enc.Encrypt(entry)
+	if err != nil {
+		return err
+	}
+	if err := s.Storage.InsertLogEntry(encrypted); err != nil {
+		return err
+	}
+	entry.ID = encrypted.ID
+	return nil
+}
+
+func (s *Store) InsertLogEntries(entries []*models.LogEntry) error {
+	encrypted, err := s.encryptAll(entries)
+	if err != nil {
+		return err
+	}
+	if err := s.Storage.InsertLogEntries(encrypted); err != nil {
+		return err
+	}
+	setIDs(entries, encrypted)
+	return nil
+}
+
+func (s *Store) InsertBatch(ctx context.Context, entries []*models.LogEntry) error {
+	encrypted, err := s.encryptAll(entries)
+	if err != nil {
+		return err
+	}
+	if err := s.Storage.InsertBatch(ctx, encrypted); err != nil {
+		return err
+	}
+	setIDs(entries, encrypted)
+	return nil
+}
+
+func (s *Store) UpdateContent(ctx context.Context, entries []*models.LogEntry) error {
+	encrypted, err := s.encryptAll(entries)
+	if err != nil {
+		return err
+	}
+	return s.Storage.UpdateContent(ctx, encrypted)
+}
+
+func (s *Store) QueryLogs(filter *models.LogFilter) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.QueryLogs(filter))
+}
+
+func (s *Store) Find(ctx context.Context, filter *models.LogFilter) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.Find(ctx, filter))
+}
+
+func (s *Store) Stream(ctx context.Context, filter *models.LogFilter, fn func(*models.LogEntry) error) error {
+	return s.Storage.Stream(ctx, filter, func(entry *models.LogEntry) error {
+		if err := s.enc.Decrypt(entry); err != nil {
+			return err
+		}
+		return fn(entry)
+	})
+}
+
+func (s *Store) ScanAfter(ctx context.Context, afterID int64, limit int) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.ScanAfter(ctx, afterID, limit))
+}
+
+func (s *Store) ScanExpired(ctx context.Context, cutoff models.RetentionCutoff, afterID int64, limit int) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.ScanExpired(ctx, cutoff, afterID, limit))
+}
+
+func (s *Store) GetSourceActivity(start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.GetSourceActivity(start, end, limit))
+}
+
+func (s *Store) GetEntriesByTypeOrStatus(logTypes []string, statusCodes []int, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.GetEntriesByTypeOrStatus(logTypes, statusCodes, start, end, limit))
+}
+
+func (s *Store) GetEntriesByPathPrefix(prefixes []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	return s.decrypted(s.Storage.GetEntriesByPathPrefix(prefixes, start, end, limit))
+}
@@ -0,0 +1,166 @@
+package erasure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Certificate records a completed erasure for the data protection
+// officer. It names the subject only by hash, and is signed so that it
+// can be shown to be unaltered.
+type Certificate struct {
+	ID            string    `json:"id"`
+	SubjectType   string    `json:"subject_type"`
+	SubjectSHA256 string    `json:"subject_sha256"`
+	Mode          string    `json:"mode"`
+	RequestedBy   string    `json:"requested_by"`
+	RequestedAt   time.Time `json:"requested_at"`
+	CompletedAt   time.Time `json:"completed_at"`
+	Result
+	// ReportsRedacted are the saved reports the subject was removed from,
+	// ReportsRetained the archived compliance pack files kept unchanged
+	ReportsRedacted []string `json:"reports_redacted"`
+	ReportsRetained []string `json:"reports_retained"`
+	// ArchivedMatched counts the subject's entries in cold storage
+	// archives, which were purged or anonymized in ArchiveFiles
+	ArchivedMatched int64    `json:"archived_entries_matched"`
+	ArchiveFiles    []string `json:"archive_files_rewritten"`
+	Statement       string   `json:"statement"`
+	KeyID           string   `json:"key_id"`
+	Signature       string   `json:"signature"`
+}
+
+// Sign sets the certificate's statement, key ID and signature
+func (c *Certificate) Sign(key ed25519.PrivateKey) error {
+	c.Statement = c.statement()
+	c.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	payload, err := c.payload()
+	if err != nil {
+		return err
+	}
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// Verify reports whether the certificate is unaltered since it was signed
+// with the private key of pub
+func (c *Certificate) Verify(pub ed25519.PublicKey) bool {
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil || c.KeyID != KeyID(pub) {
+		return false
+	}
+	payload, err := c.payload()
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, payload, signature)
+}
+
+// payload is the certificate as JSON without its signature
+func (c *Certificate) payload() ([]byte, error) {
+	unsigned := *c
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode erasure certificate: %w", err)
+	}
+	return data, nil
+}
+
+func (c *Certificate) statement() string {
+	changed := fmt.Sprintf("%d entries were purged", c.Purged)
+	if c.Mode == ModeAnonymize {
+		changed = fmt.Sprintf("%d entries were anonymized", c.Anonymized)
+	}
+	statement := fmt.Sprintf("All %d stored log entries were searched for the %s subject with SHA-256 %s. %d entries matched and %s. The subject was removed from %d saved reports.",
+		c.Scanned, c.SubjectType, c.SubjectSHA256, c.Matched, changed, len(c.ReportsRedacted))
+	if c.ArchivedMatched > 0 {
+		statement += fmt.Sprintf(" %d archived entries in %d cold storage files were %sd.", c.ArchivedMatched, len(c.ArchiveFiles), c.Mode)
+	}
+	if len(c.ReportsRetained) > 0 {
+		statement += fmt.Sprintf(" %d files of archived compliance packs mention the subject and are retained unchanged as records.", len(c.ReportsRetained))
+	}
+	return statement
+}
+
+// KeyID identifies a signing key by the start of its public key's SHA-256
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "ed25519:" + hex.EncodeToString(sum[:8])
+}
+
+// PublicKeyPEM encodes a public key for distribution to verifiers
+func PublicKeyPEM(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadOrCreateKey reads the PEM PKCS #8 signing key at path, generating
+// one readable only by its owner if there is none
+func LoadOrCreateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("signing key %s is not a PEM private key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+func createKey(path string) (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create signing key directory: %w", err)
+	}
+	// O_EXCL keeps a key written meanwhile by another replica
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return LoadOrCreateKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return key, nil
+}
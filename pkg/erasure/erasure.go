@@ -0,0 +1,300 @@
+// Package erasure carries out right-to-erasure requests. Every stored
+// entry of a data subject, identified by an IP address or a user
+// identifier extracted into metadata, is purged or anonymized, and the
+// result is certified with a signature a data protection officer can
+// verify.
+package erasure
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Modes of erasure
+const (
+	// ModePurge deletes the subject's entries
+	ModePurge = "purge"
+	// ModeAnonymize keeps the subject's entries for statistics with the
+	// subject removed from them
+	ModeAnonymize = "anonymize"
+)
+
+// Kinds of subject
+const (
+	SubjectIP         = "ip"
+	SubjectIdentifier = "identifier"
+)
+
+// Redacted replaces an erased user identifier
+const Redacted = "[redacted]"
+
+// Subject is the data subject of an erasure request
+type Subject struct {
+	Kind  string
+	Value string
+	// fields are the metadata fields holding user identifiers
+	fields []string
+	// replacement replaces the value in anonymized entries
+	replacement string
+}
+
+// NewIPSubject returns the subject with an IP address. Anonymized entries
+// keep the address with its host part zeroed.
+func NewIPSubject(ip string) (*Subject, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ip)
+	}
+	return &Subject{Kind: SubjectIP, Value: parsed.String(), replacement: AnonymizeIP(parsed)}, nil
+}
+
+// NewIdentifierSubject returns the subject with a user identifier, which
+// entries hold in one of fields of their metadata
+func NewIdentifierSubject(identifier string, fields []string) (*Subject, error) {
+	if strings.TrimSpace(identifier) == "" {
+		return nil, fmt.Errorf("identifier is required")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no identifier fields are configured")
+	}
+	return &Subject{Kind: SubjectIdentifier, Value: identifier, fields: fields, replacement: Redacted}, nil
+}
+
+// AnonymizeIP zeroes the host part of an address: the last octet of an
+// IPv4 address, or all but the first 48 bits of an IPv6 one
+func AnonymizeIP(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// SHA256 identifies the subject without naming it, as the hex SHA-256 of
+// its kind and value joined by a colon, such as "ip:192.0.2.1"
+func (s *Subject) SHA256() string {
+	sum := sha256.Sum256([]byte(s.Kind + ":" + s.Value))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether an entry belongs to the subject: for an IP, the
+// entry's source IP or any of its text mentioning it; for an identifier,
+// one of the identifier fields holding it
+func (s *Subject) Matches(entry *models.LogEntry) bool {
+	if s.Kind == SubjectIdentifier {
+		for _, field := range s.fields {
+			if value, ok := entry.Metadata[field]; ok && fmt.Sprint(value) == s.Value {
+				return true
+			}
+		}
+		return false
+	}
+
+	if net.ParseIP(entry.SourceIP).String() == s.Value {
+		return true
+	}
+	for _, text := range []string{entry.RawLog, entry.Path, entry.UserAgent, entry.Referer} {
+		if containsToken(text, s.Value) {
+			return true
+		}
+	}
+	for _, value := range entry.Metadata {
+		if text, ok := value.(string); ok && containsToken(text, s.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Anonymize removes the subject from an entry in place
+func (s *Subject) Anonymize(entry *models.LogEntry) {
+	if s.Kind == SubjectIP && net.ParseIP(entry.SourceIP).String() == s.Value {
+		entry.SourceIP = s.replacement
+	}
+	entry.RawLog = replaceToken(entry.RawLog, s.Value, s.replacement)
+	entry.Path = replaceToken(entry.Path, s.Value, s.replacement)
+	entry.UserAgent = replaceToken(entry.UserAgent, s.Value, s.replacement)
+	entry.Referer = replaceToken(entry.Referer, s.Value, s.replacement)
+	for key, value := range entry.Metadata {
+		if s.isField(key) && fmt.Sprint(value) == s.Value {
+			entry.Metadata[key] = s.replacement
+		} else if text, ok := value.(string); ok {
+			entry.Metadata[key] = replaceToken(text, s.Value, s.replacement)
+		}
+	}
+}
+
+// isField reports whether a metadata field holds user identifiers
+func (s *Subject) isField(key string) bool {
+	for _, field := range s.fields {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact removes the subject from the content of a saved report, and
+// reports whether it was found
+func (s *Subject) Redact(data []byte) ([]byte, bool) {
+	text := string(data)
+	if !containsToken(text, s.Value) {
+		return data, false
+	}
+	return []byte(replaceToken(text, s.Value, s.replacement)), true
+}
+
+// Store is the storage an erasure works on
+type Store interface {
+	ScanAfter(ctx context.Context, afterID int64, limit int) ([]*models.LogEntry, error)
+	DeleteByIDs(ctx context.Context, ids []int64) (int64, error)
+	UpdateContent(ctx context.Context, entries []*models.LogEntry) error
+}
+
+// Result counts the entries an erasure visited and changed
+type Result struct {
+	Scanned    int64 `json:"entries_scanned"`
+	Matched    int64 `json:"entries_matched"`
+	Purged     int64 `json:"entries_purged"`
+	Anonymized int64 `json:"entries_anonymized"`
+}
+
+// Erase purges or anonymizes every stored entry of the subject, visiting
+// entries batchSize at a time. progress, if not nil, is called with the
+// counts so far after each batch.
+func Erase(ctx context.Context, store Store, subject *Subject, mode string, batchSize int, progress func(Result)) (*Result, error) {
+	if mode != ModePurge && mode != ModeAnonymize {
+		return nil, fmt.Errorf("mode must be %s or %s", ModePurge, ModeAnonymize)
+	}
+
+	result := &Result{}
+	var afterID int64
+	for {
+		entries, err := store.ScanAfter(ctx, afterID, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(entries) == 0 {
+			return result, nil
+		}
+		afterID = entries[len(entries)-1].ID
+		result.Scanned += int64(len(entries))
+
+		var matched []*models.LogEntry
+		for _, entry := range entries {
+			if subject.Matches(entry) {
+				matched = append(matched, entry)
+			}
+		}
+		result.Matched += int64(len(matched))
+
+		if len(matched) > 0 && mode == ModePurge {
+			ids := make([]int64, len(matched))
+			for i, entry := range matched {
+				ids[i] = entry.ID
+			}
+			deleted, err := store.DeleteByIDs(ctx, ids)
+			result.Purged += deleted
+			if err != nil {
+				return result, err
+			}
+		} else if len(matched) > 0 {
+			for _, entry := range matched {
+				subject.Anonymize(entry)
+			}
+			if err := store.UpdateContent(ctx, matched); err != nil {
+				return result, err
+			}
+			result.Anonymized += int64(len(matched))
+		}
+
+		if progress != nil {
+			progress(*result)
+		}
+	}
+}
+
+// Archived returns the function a cold storage archive rewrite applies to
+// each archived entry: dropping the subject's entries when purging, or
+// anonymizing them
+func (s *Subject) Archived(mode string) func(*models.LogEntry) (keep, changed bool) {
+	return func(entry *models.LogEntry) (bool, bool) {
+		if !s.Matches(entry) {
+			return true, false
+		}
+		if mode == ModePurge {
+			return false, false
+		}
+		s.Anonymize(entry)
+		return true, true
+	}
+}
+
+// isTokenChar reports whether c continues a word, number or address
+func isTokenChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// tokenAt reports whether token at s[i:] stands alone, so 192.0.2.1 is
+// not found in 192.0.2.10 nor 42 in 420. A dot or colon between token
+// characters continues an address.
+func tokenAt(s string, i int, token string) bool {
+	if i > 0 {
+		before := s[i-1]
+		if isTokenChar(before) || (before == '.' || before == ':') && i > 1 && isTokenChar(s[i-2]) {
+			return false
+		}
+	}
+	if end := i + len(token); end < len(s) {
+		after := s[end]
+		if isTokenChar(after) || (after == '.' || after == ':') && end+1 < len(s) && isTokenChar(s[end+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsToken reports whether token stands alone somewhere in s
+func containsToken(s, token string) bool {
+	for i := 0; i+len(token) <= len(s); {
+		j := strings.Index(s[i:], token)
+		if j < 0 {
+			return false
+		}
+		if tokenAt(s, i+j, token) {
+			return true
+		}
+		i += j + 1
+	}
+	return false
+}
+
+// replaceToken replaces every occurrence of token standing alone in s
+func replaceToken(s, token, replacement string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i+len(token) <= len(s); {
+		j := strings.Index(s[i:], token)
+		if j < 0 {
+			break
+		}
+		if tokenAt(s, i+j, token) {
+			b.WriteString(s[last : i+j])
+			b.WriteString(replacement)
+			last = i + j + len(token)
+			i = last
+			continue
+		}
+		i += j + 1
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
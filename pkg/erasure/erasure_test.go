@@ -0,0 +1,147 @@
+package erasure
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	assert.True(t, containsToken("192.0.2.1 - - [GET /]", "192.0.2.1"))
+	assert.True(t, containsToken("for=192.0.2.1, 10.0.0.1", "192.0.2.1"))
+	assert.False(t, containsToken("192.0.2.10 - -", "192.0.2.1"))
+	assert.False(t, containsToken("10.192.0.2.1", "192.0.2.1"))
+	assert.False(t, containsToken("/orders/420", "42"))
+	assert.True(t, containsToken("/orders/42?x=1", "42"))
+	assert.True(t, containsToken("sentence ends with 42.", "42"))
+
+	assert.Equal(t, "a 192.0.2.0 b 192.0.2.10 c 192.0.2.0", replaceToken("a 192.0.2.1 b 192.0.2.10 c 192.0.2.1", "192.0.2.1", "192.0.2.0"))
+	assert.Equal(t, "unchanged", replaceToken("unchanged", "192.0.2.1", "192.0.2.0"))
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	assert.Equal(t, "192.0.2.0", AnonymizeIP(net.ParseIP("192.0.2.77")))
+	assert.Equal(t, "2001:db8:1::", AnonymizeIP(net.ParseIP("2001:db8:1:2::5")))
+}
+
+func TestSubjects(t *testing.T) {
+	_, err := NewIPSubject("not-an-ip")
+	assert.Error(t, err)
+	_, err = NewIdentifierSubject(" ", []string{"user_id"})
+	assert.Error(t, err)
+
+	ip, err := NewIPSubject("2001:DB8::1")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip.Value, "addresses are canonical")
+	assert.True(t, ip.Matches(&models.LogEntry{SourceIP: "2001:db8:0::1"}))
+
+	user, err := NewIdentifierSubject("42", []string{"user_id"})
+	require.NoError(t, err)
+	assert.True(t, user.Matches(&models.LogEntry{Metadata: models.LogMetadata{"user_id": float64(42)}}))
+	assert.False(t, user.Matches(&models.LogEntry{Metadata: models.LogMetadata{"count": float64(42)}}))
+}
+
+func TestErase(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(ip, raw string, metadata models.LogMetadata) *models.LogEntry {
+		return &models.LogEntry{Timestamp: base, LogType: "nginx", SourceIP: ip, Path: "/", RawLog: raw, Metadata: metadata}
+	}
+	seed := func() *memory.Store {
+		store := memory.New()
+		require.NoError(t, store.InsertBatch(ctx, []*models.LogEntry{
+			entry("192.0.2.1", "192.0.2.1 GET /", nil),
+			entry("192.0.2.10", "192.0.2.10 GET /", nil),
+			entry("10.0.0.1", "10.0.0.1 GET / forwarded for 192.0.2.1", nil),
+			entry("10.0.0.2", "10.0.0.2 GET / user=alice", models.LogMetadata{"user": "alice"}),
+		}))
+		return store
+	}
+
+	t.Run("purge", func(t *testing.T) {
+		store := seed()
+		subject, err := NewIPSubject("192.0.2.1")
+		require.NoError(t, err)
+
+		var batches int
+		result, err := Erase(ctx, store, subject, ModePurge, 3, func(Result) { batches++ })
+		require.NoError(t, err)
+		assert.Equal(t, &Result{Scanned: 4, Matched: 2, Purged: 2}, result)
+		assert.Equal(t, 2, batches)
+
+		remaining, err := store.ScanAfter(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, remaining, 2)
+		assert.Equal(t, "192.0.2.10", remaining[0].SourceIP)
+		assert.Equal(t, "10.0.0.2", remaining[1].SourceIP)
+	})
+
+	t.Run("anonymize", func(t *testing.T) {
+		store := seed()
+		subject, err := NewIdentifierSubject("alice", []string{"user_id", "user"})
+		require.NoError(t, err)
+
+		result, err := Erase(ctx, store, subject, ModeAnonymize, 10, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &Result{Scanned: 4, Matched: 1, Anonymized: 1}, result)
+
+		entries, err := store.ScanAfter(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		assert.Equal(t, "10.0.0.2 GET / user=[redacted]", entries[3].RawLog)
+		assert.Equal(t, Redacted, entries[3].Metadata["user"])
+	})
+
+	_, err := Erase(ctx, seed(), &Subject{Kind: SubjectIP, Value: "192.0.2.1"}, "shred", 10, nil)
+	assert.Error(t, err)
+}
+
+func TestCertificate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "erasure.key")
+	key, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+	again, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the generated key is kept")
+
+	cert := &Certificate{
+		ID:            "abc",
+		SubjectType:   SubjectIP,
+		SubjectSHA256: "ff",
+		Mode:          ModePurge,
+		Result:        Result{Scanned: 10, Matched: 2, Purged: 2},
+	}
+	require.NoError(t, cert.Sign(key))
+	assert.Contains(t, cert.Statement, "2 entries were purged")
+
+	pub := key.Public().(ed25519.PublicKey)
+	assert.True(t, cert.Verify(pub))
+	cert.Purged = 1
+	assert.False(t, cert.Verify(pub), "altered certificates fail")
+}
+
+func TestArchived(t *testing.T) {
+	subject, err := NewIPSubject("192.0.2.1")
+	require.NoError(t, err)
+
+	keep, changed := subject.Archived(ModePurge)(&models.LogEntry{SourceIP: "192.0.2.1"})
+	assert.False(t, keep)
+	assert.False(t, changed)
+	keep, changed = subject.Archived(ModePurge)(&models.LogEntry{SourceIP: "192.0.2.2"})
+	assert.True(t, keep)
+	assert.False(t, changed)
+
+	entry := &models.LogEntry{SourceIP: "192.0.2.1", RawLog: "192.0.2.1 GET /"}
+	keep, changed = subject.Archived(ModeAnonymize)(entry)
+	assert.True(t, keep)
+	assert.True(t, changed)
+	assert.Equal(t, "192.0.2.0", entry.SourceIP)
+}
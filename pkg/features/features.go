@@ -0,0 +1,233 @@
+// Package features gates pipeline stages while they are rolled out. Each
+// flag has a built-in default that the configuration file can change,
+// globally or for chosen projects, and that overrides stored in the
+// database change again at runtime without a restart.
+//
+// An entry belongs to the project named by one of its metadata fields, by
+// default "project". Whether a flag is on for a project is decided by the
+// first of these that applies:
+//
+//  1. a database override for the project
+//  2. a database override for all projects
+//  3. the project being listed in the flag's configured projects
+//  4. the flag's configured enabled setting
+//  5. the flag's default
+package features
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Flags gating stages of the ingestion pipeline
+const (
+	// StreamAlerts evaluates alert rules, including IP scores, on stored entries
+	StreamAlerts = "stream_alerts"
+	// Forwarding relays stored entries to the SIEM destinations
+	Forwarding = "forwarding"
+)
+
+// Flag describes a feature flag
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Flags lists every flag the server knows
+var Flags = []Flag{
+	{Name: StreamAlerts, Description: "Evaluate alert rules on entries as they are stored", Default: true},
+	{Name: Forwarding, Description: "Forward stored entries to SIEM destinations", Default: true},
+}
+
+// Lookup returns the flag with the given name
+func Lookup(name string) (Flag, bool) {
+	for _, flag := range Flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
+
+// DefaultProjectField is the metadata field naming an entry's project
+const DefaultProjectField = "project"
+
+// Setting is how the configuration file sets a flag. A nil Enabled keeps
+// the flag's default.
+type Setting struct {
+	Enabled  *bool
+	Projects []string
+}
+
+// State is how a flag is set, for display
+type State struct {
+	Flag
+	Enabled   bool                      `json:"enabled"`
+	Projects  []string                  `json:"projects,omitempty"`
+	Overrides []*models.FeatureOverride `json:"overrides,omitempty"`
+}
+
+// Set holds the configured flags and the database overrides. It is safe
+// for concurrent use.
+type Set struct {
+	projectField string
+
+	mu        sync.RWMutex
+	settings  map[string]*setting
+	overrides map[string]map[string]*models.FeatureOverride
+}
+
+type setting struct {
+	enabled  bool
+	projects map[string]bool
+}
+
+// NewSet returns a set of the known flags at their defaults. projectField
+// names the metadata field holding an entry's project; empty uses
+// DefaultProjectField.
+func NewSet(projectField string) *Set {
+	if projectField == "" {
+		projectField = DefaultProjectField
+	}
+	set := &Set{
+		projectField: projectField,
+		settings:     make(map[string]*setting, len(Flags)),
+		overrides:    make(map[string]map[string]*models.FeatureOverride),
+	}
+	for _, flag := range Flags {
+		set.settings[flag.Name] = &setting{enabled: flag.Default, projects: map[string]bool{}}
+	}
+	return set
+}
+
+// Configure applies the configured setting of a flag
+func (s *Set) Configure(name string, cfg Setting) error {
+	flag, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown feature flag %q", name)
+	}
+	enabled := flag.Default
+	if cfg.Enabled != nil {
+		enabled = *cfg.Enabled
+	}
+
+	projects := make(map[string]bool, len(cfg.Projects))
+	for _, project := range cfg.Projects {
+		projects[project] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings[name] = &setting{enabled: enabled, projects: projects}
+	return nil
+}
+
+// SetOverrides replaces every override, such as with those loaded from
+// the database. Overrides of unknown flags are ignored.
+func (s *Set) SetOverrides(overrides []*models.FeatureOverride) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrides = make(map[string]map[string]*models.FeatureOverride)
+	for _, override := range overrides {
+		s.setOverride(override)
+	}
+}
+
+// Override adds or replaces an override
+func (s *Set) Override(override *models.FeatureOverride) error {
+	if _, ok := Lookup(override.Flag); !ok {
+		return fmt.Errorf("unknown feature flag %q", override.Flag)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setOverride(override)
+	return nil
+}
+
+func (s *Set) setOverride(override *models.FeatureOverride) {
+	if _, ok := s.settings[override.Flag]; !ok {
+		return
+	}
+	byProject, ok := s.overrides[override.Flag]
+	if !ok {
+		byProject = make(map[string]*models.FeatureOverride)
+		s.overrides[override.Flag] = byProject
+	}
+	c := *override
+	byProject[c.Project] = &c
+}
+
+// RemoveOverride removes the override of a flag for a project, or with an
+// empty project the one for all projects
+func (s *Set) RemoveOverride(name, project string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.overrides[name], project)
+}
+
+// Enabled reports whether a flag is on for a project. An empty project
+// stands for entries without one, which only global settings apply to.
+// Unknown flags are off.
+func (s *Set) Enabled(name, project string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cfg, ok := s.settings[name]
+	if !ok {
+		return false
+	}
+	if override, ok := s.overrides[name][project]; ok && project != "" {
+		return override.Enabled
+	}
+	if override, ok := s.overrides[name][""]; ok {
+		return override.Enabled
+	}
+	if cfg.projects[project] {
+		return true
+	}
+	return cfg.enabled
+}
+
+// Project returns the project an entry belongs to, or "" if it names none
+func (s *Set) Project(entry *models.LogEntry) string {
+	if project, ok := entry.Metadata[s.projectField].(string); ok {
+		return project
+	}
+	return ""
+}
+
+// EnabledFor reports whether a flag is on for the entry's project
+func (s *Set) EnabledFor(name string, entry *models.LogEntry) bool {
+	return s.Enabled(name, s.Project(entry))
+}
+
+// States returns every flag with its configuration and overrides, in the
+// order of Flags
+func (s *Set) States() []State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]State, 0, len(Flags))
+	for _, flag := range Flags {
+		cfg := s.settings[flag.Name]
+		state := State{Flag: flag, Enabled: cfg.enabled}
+		for project := range cfg.projects {
+			state.Projects = append(state.Projects, project)
+		}
+		sort.Strings(state.Projects)
+
+		for _, override := range s.overrides[flag.Name] {
+			c := *override
+			state.Overrides = append(state.Overrides, &c)
+		}
+		sort.Slice(state.Overrides, func(i, j int) bool { return state.Overrides[i].Project < state.Overrides[j].Project })
+		states = append(states, state)
+	}
+	return states
+}
@@ -0,0 +1,92 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestDefaults(t *testing.T) {
+	set := NewSet("")
+	for _, flag := range Flags {
+		assert.Equal(t, flag.Default, set.Enabled(flag.Name, ""), flag.Name)
+		assert.Equal(t, flag.Default, set.Enabled(flag.Name, "shop"), flag.Name)
+	}
+	assert.False(t, set.Enabled("unknown", ""))
+	assert.Error(t, set.Configure("unknown", Setting{}))
+	assert.Error(t, set.Override(&models.FeatureOverride{Flag: "unknown"}))
+}
+
+func TestPrecedence(t *testing.T) {
+	set := NewSet("")
+	require.NoError(t, set.Configure(Forwarding, Setting{Enabled: boolPtr(false), Projects: []string{"shop"}}))
+
+	// Configured projects are enabled while the flag is off elsewhere
+	assert.True(t, set.Enabled(Forwarding, "shop"))
+	assert.False(t, set.Enabled(Forwarding, "blog"))
+	assert.False(t, set.Enabled(Forwarding, ""))
+
+	// A global override beats the configuration
+	require.NoError(t, set.Override(&models.FeatureOverride{Flag: Forwarding, Enabled: true}))
+	assert.True(t, set.Enabled(Forwarding, "blog"))
+	assert.True(t, set.Enabled(Forwarding, ""))
+
+	// A project override beats the global one
+	require.NoError(t, set.Override(&models.FeatureOverride{Flag: Forwarding, Project: "blog", Enabled: false}))
+	assert.False(t, set.Enabled(Forwarding, "blog"))
+	assert.True(t, set.Enabled(Forwarding, "wiki"))
+
+	set.RemoveOverride(Forwarding, "")
+	assert.False(t, set.Enabled(Forwarding, "wiki"))
+	assert.True(t, set.Enabled(Forwarding, "shop"))
+
+	set.SetOverrides(nil)
+	assert.False(t, set.Enabled(Forwarding, "blog"))
+	assert.True(t, set.Enabled(Forwarding, "shop"))
+
+	// An unset enabled keeps the default
+	require.NoError(t, set.Configure(StreamAlerts, Setting{Projects: []string{"shop"}}))
+	assert.True(t, set.Enabled(StreamAlerts, "blog"))
+}
+
+func TestEnabledFor(t *testing.T) {
+	set := NewSet("namespace")
+	require.NoError(t, set.Configure(StreamAlerts, Setting{Enabled: boolPtr(false), Projects: []string{"payments"}}))
+
+	entry := &models.LogEntry{Metadata: models.LogMetadata{"namespace": "payments", "project": "other"}}
+	assert.Equal(t, "payments", set.Project(entry))
+	assert.True(t, set.EnabledFor(StreamAlerts, entry))
+
+	assert.Equal(t, "", set.Project(&models.LogEntry{}))
+	assert.False(t, set.EnabledFor(StreamAlerts, &models.LogEntry{}))
+	assert.False(t, set.EnabledFor(StreamAlerts, &models.LogEntry{Metadata: models.LogMetadata{"namespace": 7.0}}))
+}
+
+func TestStates(t *testing.T) {
+	set := NewSet("")
+	require.NoError(t, set.Configure(Forwarding, Setting{Enabled: boolPtr(false), Projects: []string{"shop", "blog"}}))
+	set.SetOverrides([]*models.FeatureOverride{
+		{Flag: Forwarding, Project: "wiki", Enabled: true},
+		{Flag: Forwarding, Enabled: false},
+		{Flag: "retired", Enabled: true},
+	})
+
+	states := set.States()
+	require.Len(t, states, len(Flags))
+	var forwarding State
+	for _, state := range states {
+		if state.Name == Forwarding {
+			forwarding = state
+		}
+	}
+	assert.False(t, forwarding.Enabled)
+	assert.Equal(t, []string{"blog", "shop"}, forwarding.Projects)
+	require.Len(t, forwarding.Overrides, 2)
+	assert.Equal(t, "", forwarding.Overrides[0].Project)
+	assert.Equal(t, "wiki", forwarding.Overrides[1].Project)
+}
@@ -0,0 +1,271 @@
+package forward
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Header values identifying this platform as the reporting device
+const (
+	deviceVendor  = "LogAnalyzer"
+	deviceProduct = "Server Log Analyzer"
+	deviceVersion = "1.0"
+)
+
+// Export formats
+const (
+	FormatCEF  = "cef"
+	FormatLEEF = "leef"
+)
+
+// securityEvent is an entry described in terms CEF and LEEF share
+type securityEvent struct {
+	vendor, product, version string
+	signatureID              string
+	name                     string
+	// severity is 0-10, or the source's own severity text
+	severity string
+	fields   []eventField
+}
+
+// eventField is an extension attribute with its CEF and LEEF key
+type eventField struct {
+	cefKey, leefKey string
+	value           string
+}
+
+// describeEvent classifies an entry and collects its attributes
+func describeEvent(entry *models.LogEntry) securityEvent {
+	event := securityEvent{vendor: deviceVendor, product: deviceProduct, version: deviceVersion, severity: "1"}
+	add := func(cefKey, leefKey, value string) {
+		if value != "" {
+			event.fields = append(event.fields, eventField{cefKey: cefKey, leefKey: leefKey, value: value})
+		}
+	}
+
+	switch entry.LogType {
+	case "cef", "leef":
+		// Keep the original device's identity
+		event.vendor = metadataString(entry, "device_vendor")
+		event.product = metadataString(entry, "device_product")
+		event.version = metadataString(entry, "device_version")
+		event.signatureID = metadataString(entry, "signature_id")
+		if event.signatureID == "" {
+			event.signatureID = metadataString(entry, "event_id")
+		}
+		event.name = metadataString(entry, "name")
+		if severity := metadataString(entry, "severity"); severity != "" {
+			event.severity = severity
+		} else if severity := metadataString(entry, "sev"); severity != "" {
+			event.severity = severity
+		}
+	case "windows_event":
+		event.signatureID = metadataString(entry, "event_id")
+		event.name = entry.Path
+		event.severity = windowsSeverity(entry)
+		add("dhost", "identHostName", metadataString(entry, "computer"))
+		add("duser", "usrName", metadataString(entry, "TargetUserName"))
+	case "aws_vpc_flow":
+		action := metadataString(entry, "action")
+		event.signatureID = "vpc-flow-" + strings.ToLower(action)
+		event.name = "VPC flow " + strings.ToLower(action)
+		if action == "REJECT" {
+			event.severity = "4"
+		}
+		add("dst", "dst", metadataString(entry, "dstaddr"))
+		add("spt", "srcPort", metadataString(entry, "srcport"))
+		add("dpt", "dstPort", metadataString(entry, "dstport"))
+		add("proto", "proto", entry.Method)
+		add("act", "action", action)
+		add("cnt", "srcPackets", metadataString(entry, "packets"))
+	default:
+		if entry.StatusCode != 0 {
+			event.signatureID = "http-" + strconv.Itoa(entry.StatusCode)
+			event.name, event.severity = httpEventName(entry.StatusCode)
+		} else {
+			event.signatureID = entry.LogType
+			event.name = "Log event"
+		}
+	}
+	if event.signatureID == "" {
+		event.signatureID = entry.LogType
+	}
+	if event.name == "" {
+		event.name = entry.LogType + " event"
+	}
+
+	add("src", "src", entry.SourceIP)
+	if entry.StatusCode != 0 {
+		add("requestMethod", "requestMethod", entry.Method)
+		add("request", "url", entry.Path)
+		add("cn1", "httpStatus", strconv.Itoa(entry.StatusCode))
+		add("out", "dstBytes", strconv.FormatInt(entry.ResponseSize, 10))
+	} else if entry.Path != "" && entry.Path != event.name {
+		add("msg", "msg", entry.Path)
+	}
+	add("requestClientApplication", "userAgent", entry.UserAgent)
+	add("cat", "cat", entry.LogType)
+	return event
+}
+
+func httpEventName(status int) (string, string) {
+	switch status {
+	case 401:
+		return "Unauthorized request", "5"
+	case 403:
+		return "Forbidden request", "5"
+	case 407:
+		return "Proxy authentication required", "5"
+	case 429:
+		return "Rate limited request", "3"
+	}
+	if status >= 500 {
+		return "Server error", "3"
+	}
+	return "HTTP request", "1"
+}
+
+func windowsSeverity(entry *models.LogEntry) string {
+	if metadataString(entry, "audit") == "failure" {
+		return "6"
+	}
+	switch metadataString(entry, "level") {
+	case "critical":
+		return "9"
+	case "error":
+		return "7"
+	case "warning":
+		return "5"
+	}
+	return "3"
+}
+
+// CEFLine renders an entry as an ArcSight Common Event Format line
+func CEFLine(entry *models.LogEntry) string {
+	event := describeEvent(entry)
+
+	var b strings.Builder
+	b.WriteString("CEF:0")
+	for _, value := range []string{event.vendor, event.product, event.version, event.signatureID, event.name, event.severity} {
+		b.WriteByte('|')
+		b.WriteString(escapeHeader(value))
+	}
+	b.WriteByte('|')
+
+	b.WriteString("rt=")
+	b.WriteString(strconv.FormatInt(entry.Timestamp.UnixMilli(), 10))
+	for _, field := range event.fields {
+		b.WriteByte(' ')
+		b.WriteString(field.cefKey)
+		b.WriteByte('=')
+		b.WriteString(escapeCEFValue(field.value))
+		if field.cefKey == "cn1" {
+			b.WriteString(" cn1Label=httpStatus")
+		}
+	}
+	return b.String()
+}
+
+// leefTimeLayout renders devTime as leefTimeFormat declares it
+const (
+	leefTimeLayout = "Jan 02 2006 15:04:05.000 MST"
+	leefTimeFormat = "MMM dd yyyy HH:mm:ss.SSS z"
+)
+
+// LEEFLine renders an entry as an IBM QRadar LEEF 1.0 line with
+// tab-separated attributes
+func LEEFLine(entry *models.LogEntry) string {
+	event := describeEvent(entry)
+
+	var b strings.Builder
+	b.WriteString("LEEF:1.0")
+	for _, value := range []string{event.vendor, event.product, event.version, event.signatureID} {
+		b.WriteByte('|')
+		b.WriteString(escapeHeader(value))
+	}
+	b.WriteByte('|')
+
+	attrs := []string{
+		"devTime=" + entry.Timestamp.UTC().Format(leefTimeLayout),
+		"devTimeFormat=" + leefTimeFormat,
+		"sev=" + leefSeverity(event.severity),
+		"eventName=" + leefValue(event.name),
+	}
+	for _, field := range event.fields {
+		attrs = append(attrs, field.leefKey+"="+leefValue(field.value))
+	}
+	b.WriteString(strings.Join(attrs, "\t"))
+	return b.String()
+}
+
+// Format renders an entry in the named export format
+func Format(format string, entry *models.LogEntry) (string, error) {
+	switch format {
+	case FormatCEF:
+		return CEFLine(entry), nil
+	case FormatLEEF:
+		return LEEFLine(entry), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// leefSeverity maps a CEF severity onto LEEF's 1-10 scale
+func leefSeverity(severity string) string {
+	if n, err := strconv.Atoi(severity); err == nil {
+		return strconv.Itoa(min(max(n, 1), 10))
+	}
+	switch strings.ToLower(severity) {
+	case "low":
+		return "3"
+	case "medium":
+		return "5"
+	case "high":
+		return "8"
+	case "very-high":
+		return "10"
+	}
+	return "1"
+}
+
+var (
+	headerEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefEscaper    = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefEscaper   = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func escapeHeader(value string) string { return headerEscaper.Replace(value) }
+
+func escapeCEFValue(value string) string { return cefEscaper.Replace(value) }
+
+// leefValue strips the attribute delimiter and line breaks from a value
+func leefValue(value string) string { return leefEscaper.Replace(value) }
+
+// syslogSeverity maps an event severity onto a syslog severity level
+func syslogSeverity(severity string) int {
+	n, _ := strconv.Atoi(leefSeverity(severity))
+	switch {
+	case n >= 8:
+		return 2 // critical
+	case n >= 6:
+		return 3 // error
+	case n >= 4:
+		return 4 // warning
+	}
+	return 6 // informational
+}
+
+// syslogMessage wraps a formatted event in an RFC 3164 header using the
+// local0 facility
+func syslogMessage(entry *models.LogEntry, format, hostname string, now time.Time) (string, error) {
+	body, err := Format(format, entry)
+	if err != nil {
+		return "", err
+	}
+	priority := 16*8 + syslogSeverity(describeEvent(entry).severity)
+	return fmt.Sprintf("<%d>%s %s %s", priority, now.Format(time.Stamp), hostname, body), nil
+}
@@ -0,0 +1,187 @@
+package forward
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCEFLine(t *testing.T) {
+	line := CEFLine(testEntry)
+
+	assert.True(t, strings.HasPrefix(line, "CEF:0|LogAnalyzer|Server Log Analyzer|1.0|http-401|Unauthorized request|5|"), line)
+	assert.Contains(t, line, "rt=1696946136000 src=203.0.113.9 requestMethod=POST request=/login?next\\=/admin cn1=401 cn1Label=httpStatus out=512")
+	assert.Contains(t, line, "requestClientApplication=curl/8.0 cat=nginx")
+}
+
+func TestCEFLineEscaping(t *testing.T) {
+	entry := &models.LogEntry{
+		Timestamp: testEntry.Timestamp,
+		LogType:   "cef",
+		Path:      "line one\nline=two \\ end",
+		Metadata: models.LogMetadata{
+			"device_vendor":  "Acme|Corp",
+			"device_product": "Firewall",
+			"device_version": "2.0",
+			"signature_id":   "100",
+			"name":           "Blocked \\ port",
+			"severity":       "High",
+		},
+	}
+
+	line := CEFLine(entry)
+	assert.True(t, strings.HasPrefix(line, `CEF:0|Acme\|Corp|Firewall|2.0|100|Blocked \\ port|High|`), line)
+	assert.Contains(t, line, `msg=line one\nline\=two \\ end`)
+	assert.NotContains(t, line, "\n")
+}
+
+func TestLEEFLine(t *testing.T) {
+	entry := &models.LogEntry{
+		Timestamp: testEntry.Timestamp,
+		LogType:   "aws_vpc_flow",
+		SourceIP:  "198.51.100.7",
+		Method:    "TCP",
+		Path:      "10.0.1.5:22",
+		Metadata: models.LogMetadata{
+			"action":  "REJECT",
+			"dstaddr": "10.0.1.5",
+			"srcport": 49152,
+			"dstport": float64(22),
+		},
+	}
+
+	line := LEEFLine(entry)
+	header, attrs, ok := strings.Cut(line, "|vpc-flow-reject|")
+	require.True(t, ok, line)
+	assert.Equal(t, "LEEF:1.0|LogAnalyzer|Server Log Analyzer|1.0", header)
+
+	fields := make(map[string]string)
+	for _, attr := range strings.Split(attrs, "\t") {
+		key, value, _ := strings.Cut(attr, "=")
+		fields[key] = value
+	}
+	assert.Equal(t, "Oct 10 2023 13:55:36.000 UTC", fields["devTime"])
+	assert.Equal(t, "MMM dd yyyy HH:mm:ss.SSS z", fields["devTimeFormat"])
+	assert.Equal(t, "4", fields["sev"])
+	assert.Equal(t, "198.51.100.7", fields["src"])
+	assert.Equal(t, "10.0.1.5", fields["dst"])
+	assert.Equal(t, "49152", fields["srcPort"])
+	assert.Equal(t, "22", fields["dstPort"])
+	assert.Equal(t, "TCP", fields["proto"])
+	assert.Equal(t, "REJECT", fields["action"])
+}
+
+func TestWindowsEventSeverity(t *testing.T) {
+	entry := &models.LogEntry{
+		Timestamp: testEntry.Timestamp,
+		LogType:   "windows_event",
+		Path:      "An account failed to log on.",
+		Metadata: models.LogMetadata{
+			"event_id":       4625,
+			"audit":          "failure",
+			"computer":       "DC01",
+			"TargetUserName": "administrator",
+		},
+	}
+
+	line := CEFLine(entry)
+	assert.True(t, strings.HasPrefix(line, "CEF:0|LogAnalyzer|Server Log Analyzer|1.0|4625|An account failed to log on.|6|"), line)
+	assert.Contains(t, line, "dhost=DC01 duser=administrator")
+	assert.NotContains(t, line, "msg=")
+
+	assert.Contains(t, LEEFLine(entry), "sev=6\t")
+}
+
+func TestLEEFSeverity(t *testing.T) {
+	assert.Equal(t, "1", leefSeverity("0"))
+	assert.Equal(t, "10", leefSeverity("12"))
+	assert.Equal(t, "8", leefSeverity("High"))
+	assert.Equal(t, "1", leefSeverity("unknown"))
+}
+
+func TestFormatRejectsUnknown(t *testing.T) {
+	_, err := Format("json", testEntry)
+	assert.Error(t, err)
+}
+
+func TestSyslogMessage(t *testing.T) {
+	now := time.Date(2023, 10, 3, 9, 5, 1, 0, time.UTC)
+	message, err := syslogMessage(testEntry, FormatCEF, "web01", now)
+	require.NoError(t, err)
+	// local0 facility with warning severity for a CEF severity of 5
+	assert.True(t, strings.HasPrefix(message, "<132>Oct  3 09:05:01 web01 CEF:0|"), message)
+}
+
+func TestNewDestinationSyslog(t *testing.T) {
+	dest, err := NewDestination(config.ForwardDestination{Name: "qradar", Type: DestinationSyslog, URL: "udp://qradar.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, FormatCEF, dest.Format)
+
+	_, err = NewDestination(config.ForwardDestination{Name: "qradar", Type: DestinationSyslog, URL: "udp://qradar:514", Format: "json"})
+	assert.Error(t, err)
+	_, err = NewDestination(config.ForwardDestination{Name: "qradar", Type: DestinationSyslog, URL: "https://qradar:514"})
+	assert.Error(t, err)
+	_, err = NewDestination(config.ForwardDestination{Name: "qradar", Type: DestinationSyslog})
+	assert.Error(t, err)
+
+	network, address, err := parseSyslogURL("udp://qradar.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "udp", network)
+	assert.Equal(t, "qradar.example.com:514", address)
+}
+
+func TestSendSyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var lines []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- lines
+	}()
+
+	cfg := &config.ForwardDestination{Type: DestinationSyslog, URL: "tcp://" + listener.Addr().String(), Format: FormatLEEF}
+	require.NoError(t, sendSyslog(cfg, []*models.LogEntry{testEntry, testEntry}, time.Now()))
+
+	select {
+	case lines := <-received:
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], " LEEF:1.0|LogAnalyzer|")
+		assert.Contains(t, lines[1], "httpStatus=401")
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog receiver got nothing")
+	}
+}
+
+func TestSendSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	cfg := &config.ForwardDestination{Type: DestinationSyslog, URL: "udp://" + conn.LocalAddr().String(), Format: FormatCEF}
+	require.NoError(t, sendSyslog(cfg, []*models.LogEntry{testEntry}, time.Now()))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), " CEF:0|LogAnalyzer|")
+	assert.NotContains(t, string(buf[:n]), "\n")
+}
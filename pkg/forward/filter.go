@@ -0,0 +1,55 @@
+package forward
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// securityStatusCodes are HTTP responses that record denied or throttled access
+var securityStatusCodes = map[int]bool{
+	401: true,
+	403: true,
+	407: true,
+	429: true,
+}
+
+// SecurityRelevant reports whether an entry is of interest to a SIEM:
+// CEF and LEEF events, Windows security and audit failure events, rejected
+// network flows, and requests that were denied or throttled
+func SecurityRelevant(entry *models.LogEntry) bool {
+	switch entry.LogType {
+	case "cef", "leef":
+		return true
+	case "windows_event":
+		return metadataString(entry, "audit") == "failure" || metadataString(entry, "channel") == "Security"
+	case "aws_vpc_flow":
+		return metadataString(entry, "action") == "REJECT"
+	}
+	return securityStatusCodes[entry.StatusCode]
+}
+
+// SecurityCandidates returns the log types and status codes that
+// SecurityRelevant can accept, for narrowing a query before filtering
+func SecurityCandidates() ([]string, []int) {
+	codes := make([]int, 0, len(securityStatusCodes))
+	for code := range securityStatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return []string{"cef", "leef", "windows_event", "aws_vpc_flow"}, codes
+}
+
+func metadataString(entry *models.LogEntry, key string) string {
+	value, ok := entry.Metadata[key]
+	if !ok || value == nil {
+		return ""
+	}
+	// Metadata read back from the database decodes numbers as float64
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,244 @@
+package forward
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// defaultSentinelLogType names the custom table entries land in,
+// which Log Analytics suffixes with _CL
+const defaultSentinelLogType = "LogAnalyzer"
+
+// document is the flat representation of an entry shared by formats
+// without a schema of their own
+func document(entry *models.LogEntry) map[string]interface{} {
+	doc := map[string]interface{}{
+		"timestamp": entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		"log_type":  entry.LogType,
+		"raw_log":   entry.RawLog,
+	}
+	set := func(key string, value interface{}, present bool) {
+		if present {
+			doc[key] = value
+		}
+	}
+	set("source_ip", entry.SourceIP, entry.SourceIP != "")
+	set("method", entry.Method, entry.Method != "")
+	set("path", entry.Path, entry.Path != "")
+	set("status_code", entry.StatusCode, entry.StatusCode != 0)
+	set("response_size", entry.ResponseSize, entry.ResponseSize != 0)
+	set("user_agent", entry.UserAgent, entry.UserAgent != "")
+	set("referer", entry.Referer, entry.Referer != "")
+	set("processing_time", entry.ProcessingTime, entry.ProcessingTime != 0)
+	set("metadata", entry.Metadata, len(entry.Metadata) > 0)
+	return doc
+}
+
+// encodeSplunk renders HTTP Event Collector events, one JSON object per
+// event, sent to the configured /services/collector/event endpoint
+func encodeSplunk(cfg *config.ForwardDestination, entries []*models.LogEntry, now time.Time) (*request, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, entry := range entries {
+		sourceType := cfg.SourceType
+		if sourceType == "" {
+			sourceType = "log_analyzer:" + entry.LogType
+		}
+		event := map[string]interface{}{
+			"time":       float64(entry.Timestamp.UnixNano()) / 1e9,
+			"source":     "log_analyzer",
+			"sourcetype": sourceType,
+			"event":      document(entry),
+		}
+		if cfg.Index != "" {
+			event["index"] = cfg.Index
+		}
+		if err := encoder.Encode(event); err != nil {
+			return nil, fmt.Errorf("failed to encode HEC event: %w", err)
+		}
+	}
+
+	return &request{
+		url:  cfg.URL,
+		body: body.Bytes(),
+		headers: map[string]string{
+			"Authorization": "Splunk " + cfg.Token,
+			"Content-Type":  "application/json",
+		},
+	}, nil
+}
+
+// elasticDocument maps an entry onto Elastic Common Schema fields
+func elasticDocument(entry *models.LogEntry) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@timestamp": entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		"event": map[string]interface{}{
+			"dataset":  "log_analyzer." + entry.LogType,
+			"original": entry.RawLog,
+		},
+		"labels": map[string]string{"log_type": entry.LogType},
+	}
+	if entry.ProcessingTime > 0 {
+		doc["event"].(map[string]interface{})["duration"] = int64(entry.ProcessingTime * 1e9)
+	}
+	if entry.SourceIP != "" {
+		doc["source"] = map[string]string{"ip": entry.SourceIP}
+	}
+
+	if entry.StatusCode != 0 {
+		doc["http"] = map[string]interface{}{
+			"request":  map[string]interface{}{"method": entry.Method, "referrer": entry.Referer},
+			"response": map[string]interface{}{"status_code": entry.StatusCode, "body": map[string]int64{"bytes": entry.ResponseSize}},
+		}
+		if entry.Path != "" {
+			path, query, _ := strings.Cut(entry.Path, "?")
+			doc["url"] = map[string]string{"original": entry.Path, "path": path, "query": query}
+		}
+	} else if entry.Path != "" {
+		// Application logs keep their message in Path
+		doc["message"] = entry.Path
+	}
+	if entry.UserAgent != "" {
+		doc["user_agent"] = map[string]string{"original": entry.UserAgent}
+	}
+	if len(entry.Metadata) > 0 {
+		doc["log_analyzer"] = map[string]interface{}{"metadata": entry.Metadata}
+	}
+	return doc
+}
+
+// encodeElastic renders a _bulk request that creates one document per
+// entry, which works for both indices and data streams
+func encodeElastic(cfg *config.ForwardDestination, entries []*models.LogEntry, now time.Time) (*request, error) {
+	index := cfg.Index
+	if index == "" {
+		index = "logs-log_analyzer-default"
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, entry := range entries {
+		if err := encoder.Encode(map[string]interface{}{"create": map[string]string{"_index": index}}); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(elasticDocument(entry)); err != nil {
+			return nil, fmt.Errorf("failed to encode Elastic document: %w", err)
+		}
+	}
+
+	headers := map[string]string{"Content-Type": "application/x-ndjson"}
+	if cfg.Token != "" {
+		headers["Authorization"] = "ApiKey " + cfg.Token
+	}
+	return &request{
+		url:     strings.TrimRight(cfg.URL, "/") + "/_bulk",
+		body:    body.Bytes(),
+		headers: headers,
+	}, nil
+}
+
+// bulkErrors reports the first failed item of a _bulk response, which
+// answers 200 even when documents are rejected
+func bulkErrors(body []byte) error {
+	var response struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || !response.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				if failed == 0 {
+					first = fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason)
+				}
+				failed++
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d documents rejected, first: %s", failed, len(response.Items), first)
+}
+
+// encodeSentinel renders a Log Analytics HTTP Data Collector API request,
+// signed with the workspace shared key
+func encodeSentinel(cfg *config.ForwardDestination, entries []*models.LogEntry, now time.Time) (*request, error) {
+	records := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, document(entry))
+	}
+	body, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Sentinel records: %w", err)
+	}
+
+	logType := cfg.Index
+	if logType == "" {
+		logType = defaultSentinelLogType
+	}
+	url := cfg.URL
+	if url == "" {
+		url = fmt.Sprintf("https://%s.ods.opinsights.azure.com/api/logs?api-version=2016-04-01", cfg.WorkspaceID)
+	}
+
+	date := now.UTC().Format(http.TimeFormat)
+	signature, err := sentinelSignature(cfg.WorkspaceID, cfg.Token, date, len(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &request{
+		url:  url,
+		body: body,
+		headers: map[string]string{
+			"Authorization":        signature,
+			"Content-Type":         "application/json",
+			"Log-Type":             logType,
+			"x-ms-date":            date,
+			"time-generated-field": "timestamp",
+		},
+	}, nil
+}
+
+// sentinelSignature builds the SharedKey authorization header
+func sentinelSignature(workspaceID, sharedKey, date string, contentLength int) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(sharedKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid shared key: %w", err)
+	}
+
+	stringToSign := "POST\n" + strconv.Itoa(contentLength) + "\napplication/json\nx-ms-date:" + date + "\n/api/logs"
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return "SharedKey " + workspaceID + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func validateSharedKey(sharedKey string) error {
+	if sharedKey == "" {
+		return fmt.Errorf("token (the workspace shared key) is required")
+	}
+	if _, err := base64.StdEncoding.DecodeString(sharedKey); err != nil {
+		return fmt.Errorf("token must be the base64 workspace shared key")
+	}
+	return nil
+}
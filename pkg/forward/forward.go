@@ -0,0 +1,295 @@
+// Package forward relays parsed log entries to external SIEMs in their
+// native ingestion formats.
+package forward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Supported destination types
+const (
+	DestinationSplunkHEC = "splunk_hec"
+	DestinationElastic   = "elastic"
+	DestinationSentinel  = "sentinel"
+	DestinationSyslog    = "syslog"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5 * time.Second
+	// queueSize bounds entries waiting per destination; beyond it entries
+	// are dropped rather than slowing ingestion
+	queueSize = 10000
+)
+
+// sentinelLogTypePattern matches the custom log types Log Analytics accepts
+var sentinelLogTypePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,100}$`)
+
+// request is an HTTP request body with its headers, built for a batch
+type request struct {
+	url     string
+	body    []byte
+	headers map[string]string
+}
+
+// encoder renders a batch of entries in a destination's format
+type encoder func(cfg *config.ForwardDestination, entries []*models.LogEntry, now time.Time) (*request, error)
+
+// Destination is a configured SIEM with its pending entries
+type Destination struct {
+	config.ForwardDestination
+	encode   encoder
+	logTypes map[string]bool
+	queue    chan *models.LogEntry
+
+	sent    atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+}
+
+// Stats counts a destination's forwarded, failed and dropped entries
+type Stats struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Sent    int64  `json:"sent"`
+	Failed  int64  `json:"failed"`
+	Dropped int64  `json:"dropped"`
+	Queued  int    `json:"queued"`
+}
+
+// NewDestination validates a destination configuration
+func NewDestination(cfg config.ForwardDestination) (*Destination, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("forwarding destination name is required")
+	}
+
+	var encode encoder
+	switch cfg.Type {
+	case DestinationSplunkHEC:
+		encode = encodeSplunk
+	case DestinationElastic:
+		encode = encodeElastic
+	case DestinationSentinel:
+		encode = encodeSentinel
+		if cfg.WorkspaceID == "" {
+			return nil, fmt.Errorf("destination %s: workspace_id is required", cfg.Name)
+		}
+		if err := validateSharedKey(cfg.Token); err != nil {
+			return nil, fmt.Errorf("destination %s: %w", cfg.Name, err)
+		}
+		if cfg.Index != "" && !sentinelLogTypePattern.MatchString(cfg.Index) {
+			return nil, fmt.Errorf("destination %s: index must be letters, digits and underscores", cfg.Name)
+		}
+	case DestinationSyslog:
+		if cfg.Format == "" {
+			cfg.Format = FormatCEF
+		}
+		if cfg.Format != FormatCEF && cfg.Format != FormatLEEF {
+			return nil, fmt.Errorf("destination %s: format must be cef or leef", cfg.Name)
+		}
+		if _, _, err := parseSyslogURL(cfg.URL); cfg.URL != "" && err != nil {
+			return nil, fmt.Errorf("destination %s: %w", cfg.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("destination %s: unsupported type %s", cfg.Name, cfg.Type)
+	}
+	if cfg.URL == "" && cfg.Type != DestinationSentinel {
+		return nil, fmt.Errorf("destination %s: url is required", cfg.Name)
+	}
+	if cfg.Token == "" && cfg.Type == DestinationSplunkHEC {
+		return nil, fmt.Errorf("destination %s: token is required", cfg.Name)
+	}
+	if cfg.BatchSize < 0 || cfg.FlushInterval < 0 {
+		return nil, fmt.Errorf("destination %s: batch_size and flush_interval cannot be negative", cfg.Name)
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	dest := &Destination{
+		ForwardDestination: cfg,
+		encode:             encode,
+		queue:              make(chan *models.LogEntry, queueSize),
+	}
+	if len(cfg.LogTypes) > 0 {
+		dest.logTypes = make(map[string]bool)
+		for _, logType := range cfg.LogTypes {
+			dest.logTypes[logType] = true
+		}
+	}
+	return dest, nil
+}
+
+// Accepts reports whether the destination's filters let an entry through
+func (d *Destination) Accepts(entry *models.LogEntry) bool {
+	if d.logTypes != nil && !d.logTypes[entry.LogType] {
+		return false
+	}
+	return !d.SecurityOnly || SecurityRelevant(entry)
+}
+
+func (d *Destination) flushInterval() time.Duration {
+	if d.FlushInterval > 0 {
+		return time.Duration(d.FlushInterval) * time.Second
+	}
+	return defaultFlushInterval
+}
+
+// Forwarder fans entries out to the configured destinations
+type Forwarder struct {
+	destinations []*Destination
+	client       *http.Client
+}
+
+// NewForwarder validates the configured destinations
+func NewForwarder(destinations []config.ForwardDestination) (*Forwarder, error) {
+	forwarder := &Forwarder{client: &http.Client{Timeout: 30 * time.Second}}
+
+	seen := make(map[string]bool)
+	for _, cfg := range destinations {
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate forwarding destination: %s", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		dest, err := NewDestination(cfg)
+		if err != nil {
+			return nil, err
+		}
+		forwarder.destinations = append(forwarder.destinations, dest)
+	}
+	return forwarder, nil
+}
+
+// Enabled reports whether any destination is configured
+func (f *Forwarder) Enabled() bool {
+	return len(f.destinations) > 0
+}
+
+// Enqueue queues an entry for every destination that accepts it, dropping
+// it for destinations whose queue is full
+func (f *Forwarder) Enqueue(entry *models.LogEntry) {
+	for _, dest := range f.destinations {
+		if !dest.Accepts(entry) {
+			continue
+		}
+		select {
+		case dest.queue <- entry:
+		default:
+			dest.dropped.Add(1)
+		}
+	}
+}
+
+// Run sends batches to each destination until the context is cancelled,
+// then flushes what is still queued. Failed batches are reported to
+// onError and not retried.
+func (f *Forwarder) Run(ctx context.Context, onError func(dest string, err error)) {
+	for _, dest := range f.destinations {
+		go f.run(ctx, dest, onError)
+	}
+}
+
+func (f *Forwarder) run(ctx context.Context, dest *Destination, onError func(string, error)) {
+	ticker := time.NewTicker(dest.flushInterval())
+	defer ticker.Stop()
+
+	batch := make([]*models.LogEntry, 0, dest.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := f.send(dest, batch); err != nil {
+			dest.failed.Add(int64(len(batch)))
+			onError(dest.Name, err)
+		} else {
+			dest.sent.Add(int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry := <-dest.queue:
+			batch = append(batch, entry)
+			if len(batch) >= dest.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-dest.queue:
+					batch = append(batch, entry)
+					if len(batch) >= dest.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (f *Forwarder) send(dest *Destination, entries []*models.LogEntry) error {
+	if dest.Type == DestinationSyslog {
+		return sendSyslog(&dest.ForwardDestination, entries, time.Now())
+	}
+
+	req, err := dest.encode(&dest.ForwardDestination, entries, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, req.url, bytes.NewReader(req.body))
+	if err != nil {
+		return err
+	}
+	for key, value := range req.headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if dest.Type == DestinationElastic {
+		return bulkErrors(body)
+	}
+	return nil
+}
+
+// Stats returns the counters for every destination
+func (f *Forwarder) Stats() []Stats {
+	stats := make([]Stats, 0, len(f.destinations))
+	for _, dest := range f.destinations {
+		stats = append(stats, Stats{
+			Name:    dest.Name,
+			Type:    dest.Type,
+			Sent:    dest.sent.Load(),
+			Failed:  dest.failed.Load(),
+			Dropped: dest.dropped.Load(),
+			Queued:  len(dest.queue),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,225 @@
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntry = &models.LogEntry{
+	Timestamp:      time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC),
+	LogType:        "nginx",
+	SourceIP:       "203.0.113.9",
+	Method:         "POST",
+	Path:           "/login?next=/admin",
+	StatusCode:     401,
+	ResponseSize:   512,
+	UserAgent:      "curl/8.0",
+	ProcessingTime: 0.25,
+	RawLog:         `203.0.113.9 - - [10/Oct/2023:13:55:36 +0000] "POST /login?next=/admin HTTP/1.1" 401 512 "-" "curl/8.0"`,
+	Metadata:       models.LogMetadata{"protocol": "HTTP/1.1"},
+}
+
+// sharedKey is a base64 workspace key for Sentinel tests
+var sharedKey = base64.StdEncoding.EncodeToString([]byte("not-a-real-workspace-key"))
+
+func TestEncodeSplunk(t *testing.T) {
+	cfg := &config.ForwardDestination{URL: "https://splunk:8088/services/collector/event", Token: "hec-token", Index: "web"}
+	req, err := encodeSplunk(cfg, []*models.LogEntry{testEntry, testEntry}, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, cfg.URL, req.url)
+	assert.Equal(t, "Splunk hec-token", req.headers["Authorization"])
+
+	scanner := bufio.NewScanner(bytes.NewReader(req.body))
+	var events []map[string]interface{}
+	for scanner.Scan() {
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, float64(testEntry.Timestamp.Unix()), events[0]["time"])
+	assert.Equal(t, "log_analyzer:nginx", events[0]["sourcetype"])
+	assert.Equal(t, "web", events[0]["index"])
+	event := events[0]["event"].(map[string]interface{})
+	assert.Equal(t, "203.0.113.9", event["source_ip"])
+	assert.Equal(t, float64(401), event["status_code"])
+	assert.Equal(t, "HTTP/1.1", event["metadata"].(map[string]interface{})["protocol"])
+}
+
+func TestEncodeElastic(t *testing.T) {
+	cfg := &config.ForwardDestination{URL: "https://elastic:9200/", Token: "api-key"}
+	req, err := encodeElastic(cfg, []*models.LogEntry{testEntry}, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://elastic:9200/_bulk", req.url)
+	assert.Equal(t, "ApiKey api-key", req.headers["Authorization"])
+	assert.Equal(t, "application/x-ndjson", req.headers["Content-Type"])
+
+	lines := bytes.Split(bytes.TrimSpace(req.body), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"create":{"_index":"logs-log_analyzer-default"}}`, string(lines[0]))
+
+	var doc struct {
+		Timestamp string `json:"@timestamp"`
+		Source    struct{ IP string }
+		Event     struct{ Duration int64 }
+		HTTP      struct {
+			Request  struct{ Method string }
+			Response struct {
+				StatusCode int `json:"status_code"`
+			}
+		}
+		URL struct{ Path, Query string }
+	}
+	require.NoError(t, json.Unmarshal(lines[1], &doc))
+	assert.Equal(t, "2023-10-10T13:55:36Z", doc.Timestamp)
+	assert.Equal(t, "203.0.113.9", doc.Source.IP)
+	assert.Equal(t, int64(250000000), doc.Event.Duration)
+	assert.Equal(t, "POST", doc.HTTP.Request.Method)
+	assert.Equal(t, 401, doc.HTTP.Response.StatusCode)
+	assert.Equal(t, "/login", doc.URL.Path)
+	assert.Equal(t, "next=/admin", doc.URL.Query)
+}
+
+func TestBulkErrors(t *testing.T) {
+	assert.NoError(t, bulkErrors([]byte(`{"errors":false,"items":[{"create":{"status":201}}]}`)))
+
+	err := bulkErrors([]byte(`{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 documents rejected")
+	assert.Contains(t, err.Error(), "mapper_parsing_exception")
+}
+
+func TestEncodeSentinel(t *testing.T) {
+	cfg := &config.ForwardDestination{WorkspaceID: "workspace", Token: sharedKey}
+	now := time.Date(2023, 10, 10, 14, 0, 0, 0, time.UTC)
+	req, err := encodeSentinel(cfg, []*models.LogEntry{testEntry}, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://workspace.ods.opinsights.azure.com/api/logs?api-version=2016-04-01", req.url)
+	assert.Equal(t, "LogAnalyzer", req.headers["Log-Type"])
+	assert.Equal(t, "Tue, 10 Oct 2023 14:00:00 GMT", req.headers["x-ms-date"])
+	assert.Equal(t, "timestamp", req.headers["time-generated-field"])
+
+	// The signature covers the body length and date with the decoded key
+	key, _ := base64.StdEncoding.DecodeString(sharedKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("POST\n" + strconv.Itoa(len(req.body)) + "\napplication/json\nx-ms-date:Tue, 10 Oct 2023 14:00:00 GMT\n/api/logs"))
+	assert.Equal(t, "SharedKey workspace:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.headers["Authorization"])
+
+	var records []map[string]interface{}
+	require.NoError(t, json.Unmarshal(req.body, &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "2023-10-10T13:55:36Z", records[0]["timestamp"])
+}
+
+func TestNewDestinationValidation(t *testing.T) {
+	_, err := NewDestination(config.ForwardDestination{Name: "s", Type: DestinationSplunkHEC, URL: "https://splunk"})
+	assert.ErrorContains(t, err, "token is required")
+	_, err = NewDestination(config.ForwardDestination{Name: "e", Type: DestinationElastic})
+	assert.ErrorContains(t, err, "url is required")
+	_, err = NewDestination(config.ForwardDestination{Name: "a", Type: DestinationSentinel, WorkspaceID: "w", Token: "not base64!"})
+	assert.ErrorContains(t, err, "base64")
+	_, err = NewDestination(config.ForwardDestination{Name: "a", Type: DestinationSentinel, WorkspaceID: "w", Token: sharedKey, Index: "bad-name"})
+	assert.Error(t, err)
+	_, err = NewDestination(config.ForwardDestination{Name: "x", Type: "graylog", URL: "udp://x"})
+	assert.ErrorContains(t, err, "unsupported type")
+
+	_, err = NewForwarder([]config.ForwardDestination{
+		{Name: "e", Type: DestinationElastic, URL: "http://a"},
+		{Name: "e", Type: DestinationElastic, URL: "http://b"},
+	})
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestSecurityRelevant(t *testing.T) {
+	assert.True(t, SecurityRelevant(testEntry))
+	assert.True(t, SecurityRelevant(&models.LogEntry{LogType: "cef"}))
+	assert.True(t, SecurityRelevant(&models.LogEntry{LogType: "aws_vpc_flow", Metadata: models.LogMetadata{"action": "REJECT"}}))
+	assert.False(t, SecurityRelevant(&models.LogEntry{LogType: "aws_vpc_flow", Metadata: models.LogMetadata{"action": "ACCEPT"}}))
+	assert.True(t, SecurityRelevant(&models.LogEntry{LogType: "windows_event", Metadata: models.LogMetadata{"audit": "failure", "channel": "Application"}}))
+	assert.False(t, SecurityRelevant(&models.LogEntry{LogType: "windows_event", Metadata: models.LogMetadata{"channel": "System"}}))
+	assert.False(t, SecurityRelevant(&models.LogEntry{LogType: "nginx", StatusCode: 200}))
+}
+
+func TestForwarderBatchesAndFilters(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Splunk token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		batches = append(batches, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	forwarder, err := NewForwarder([]config.ForwardDestination{{
+		Name: "splunk", Type: DestinationSplunkHEC, URL: server.URL, Token: "token",
+		SecurityOnly: true, BatchSize: 2, FlushInterval: 60,
+	}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	forwarder.Run(ctx, func(dest string, err error) { errs = append(errs, err) })
+
+	for i := 0; i < 3; i++ {
+		forwarder.Enqueue(testEntry)
+	}
+	forwarder.Enqueue(&models.LogEntry{LogType: "nginx", StatusCode: 200})
+
+	// The first two fill a batch; cancelling flushes the rest
+	require.Eventually(t, func() bool { return forwarder.Stats()[0].Sent == 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.Eventually(t, func() bool { return forwarder.Stats()[0].Sent == 3 }, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, batches, 2)
+	assert.Empty(t, errs)
+	assert.Equal(t, Stats{Name: "splunk", Type: DestinationSplunkHEC, Sent: 3}, forwarder.Stats()[0])
+}
+
+func TestForwarderReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"text":"Invalid token","code":4}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	forwarder, err := NewForwarder([]config.ForwardDestination{{Name: "splunk", Type: DestinationSplunkHEC, URL: server.URL, Token: "bad", BatchSize: 1}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	failures := make(chan error, 1)
+	forwarder.Run(ctx, func(dest string, err error) { failures <- err })
+	forwarder.Enqueue(testEntry)
+
+	select {
+	case err := <-failures:
+		assert.Contains(t, err.Error(), "unexpected status 403")
+		assert.Contains(t, err.Error(), "Invalid token")
+	case <-time.After(time.Second):
+		t.Fatal("expected a forwarding failure")
+	}
+	assert.Equal(t, int64(1), forwarder.Stats()[0].Failed)
+}
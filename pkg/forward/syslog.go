@@ -0,0 +1,77 @@
+package forward
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+const syslogDialTimeout = 10 * time.Second
+
+// parseSyslogURL returns the network and address of a udp:// or tcp://
+// syslog receiver
+func parseSyslogURL(raw string) (string, string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid syslog url: %w", err)
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return "", "", fmt.Errorf("syslog url must start with udp:// or tcp://")
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("syslog url must include a host")
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "514")
+	}
+	return u.Scheme, address, nil
+}
+
+// sendSyslog writes one CEF or LEEF message per entry. TCP messages are
+// newline framed; UDP sends each message as its own datagram.
+func sendSyslog(cfg *config.ForwardDestination, entries []*models.LogEntry, now time.Time) error {
+	network, address, err := parseSyslogURL(cfg.URL)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	conn, err := net.DialTimeout(network, address, syslogDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(now.Add(30 * time.Second)); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		message, err := syslogMessage(entry, cfg.Format, hostname, now.Local())
+		if err != nil {
+			return err
+		}
+		if network == "udp" {
+			if _, err := conn.Write([]byte(message)); err != nil {
+				return err
+			}
+			continue
+		}
+		buf.WriteString(message)
+		buf.WriteByte('\n')
+	}
+	if buf.Len() > 0 {
+		_, err = conn.Write(buf.Bytes())
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+// Package geoip looks up the country and city of client IPs in MaxMind
+// GeoLite2 or GeoIP2 databases, read with maxminddb-golang. Both the City
+// and Country editions work, as do other databases with the same country
+// and city fields, such as DB-IP's lite databases.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is where an IP address is, with names in English. City is
+// empty in Country databases and for addresses located to a country only.
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 code, such as "DE"
+	Country     string
+	CountryName string
+	City        string
+}
+
+// Reader looks up addresses in a database held in memory. It is safe for
+// concurrent use.
+type Reader struct {
+	db *maxminddb.Reader
+	// DatabaseType is the edition, such as "GeoLite2-City"
+	DatabaseType string
+	// BuildEpoch is when the database was built, in seconds since 1970
+	BuildEpoch uint64
+}
+
+// Open reads a database from a .mmdb file
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	reader, err := New(buf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GeoIP database %s: %w", path, err)
+	}
+	return reader, nil
+}
+
+// New reads a database from the contents of a .mmdb file
+func New(buf []byte) (*Reader, error) {
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db, DatabaseType: db.Metadata.DatabaseType, BuildEpoch: uint64(db.Metadata.BuildEpoch)}, nil
+}
+
+// place is a country or city with its names by language
+type place struct {
+	ISOCode string            `maxminddb:"iso_code"`
+	Names   map[string]string `maxminddb:"names"`
+}
+
+// record holds the fields of a database record the server reads
+type record struct {
+	City              place `maxminddb:"city"`
+	Country           place `maxminddb:"country"`
+	RegisteredCountry place `maxminddb:"registered_country"`
+}
+
+// Lookup returns the location of an IP address, and whether the database
+// has one for it
+func (r *Reader) Lookup(ip string) (Location, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, false
+	}
+	var found record
+	// IPv6 addresses fail to look up in IPv4 databases
+	if err := r.db.Lookup(parsed, &found); err != nil {
+		return Location{}, false
+	}
+	country := found.Country
+	// Some networks, such as anycast ones, only have a registered country
+	if country.ISOCode == "" {
+		country = found.RegisteredCountry
+	}
+	location := Location{Country: country.ISOCode, CountryName: country.Names["en"], City: found.City.Names["en"]}
+	return location, location.Country != ""
+}
@@ -0,0 +1,281 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metadataMarker precedes the metadata at the end of a database
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSectionSeparator is the 16 zero bytes between the search tree and
+// the data section
+const dataSectionSeparator = 16
+
+// Data section types of the MaxMind DB format, in which the tests write
+// their databases
+const (
+	typePointer = 1
+	typeString  = 2
+	typeUint32  = 6
+	typeMap     = 7
+	typeUint64  = 9
+	typeArray   = 11
+)
+
+// pointer is a value written as a pointer to an earlier value
+type pointer int
+
+// dataWriter writes a data section
+type dataWriter struct {
+	bytes.Buffer
+}
+
+func (w *dataWriter) control(kind, size int) {
+	extra := []byte{}
+	switch {
+	case size >= 65821:
+		n := size - 65821
+		extra = []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+		size = 31
+	case size >= 285:
+		n := size - 285
+		extra = []byte{byte(n >> 8), byte(n)}
+		size = 30
+	case size >= 29:
+		extra = []byte{byte(size - 29)}
+		size = 29
+	}
+	if kind > 7 {
+		w.WriteByte(byte(size))
+		w.WriteByte(byte(kind - 7))
+	} else {
+		w.WriteByte(byte(kind<<5 | size))
+	}
+	w.Write(extra)
+}
+
+// write writes a value and returns its offset
+func (w *dataWriter) write(value interface{}) int {
+	offset := w.Len()
+	switch v := value.(type) {
+	case string:
+		w.control(typeString, len(v))
+		w.WriteString(v)
+	case uint32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, v)
+		w.control(typeUint32, 4)
+		w.Write(b)
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		w.control(typeUint64, 8)
+		w.Write(b)
+	case pointer:
+		w.WriteByte(byte(typePointer<<5 | 1<<3 | (int(v)-2048)>>16&0x7))
+		w.WriteByte(byte((int(v) - 2048) >> 8))
+		w.WriteByte(byte(int(v) - 2048))
+	case []interface{}:
+		w.control(typeArray, len(v))
+		for _, item := range v {
+			w.write(item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.control(typeMap, len(v))
+		for _, key := range keys {
+			w.write(key)
+			w.write(v[key])
+		}
+	default:
+		panic(fmt.Sprintf("unsupported value %T", value))
+	}
+	return offset
+}
+
+// treeNode is a node of a search tree being built; each record is a
+// child node, a data offset or nil for no data
+type treeNode struct {
+	records [2]interface{}
+	index   int
+}
+
+// buildDatabase writes a database mapping networks to data offsets
+func buildDatabase(t *testing.T, ipVersion, recordSize int, data *dataWriter, networks map[string]int) []byte {
+	t.Helper()
+	root := &treeNode{}
+	for cidr, offset := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		ones, _ := network.Mask.Size()
+		ip := network.IP
+		if ipVersion == 6 {
+			// IPv4 networks go under ::/96
+			if ipv4 := ip.To4(); ipv4 != nil {
+				ones += 96
+				ip = append(make(net.IP, 12), ipv4...)
+			}
+		}
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := ip[i/8] >> (7 - uint(i%8)) & 1
+			if i == ones-1 {
+				node.records[bit] = offset
+				break
+			}
+			child, ok := node.records[bit].(*treeNode)
+			if !ok {
+				child = &treeNode{}
+				node.records[bit] = child
+			}
+			node = child
+		}
+	}
+
+	var nodes []*treeNode
+	queue := []*treeNode{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		node.index = len(nodes)
+		nodes = append(nodes, node)
+		for _, record := range node.records {
+			if child, ok := record.(*treeNode); ok {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	count := len(nodes)
+	for _, node := range nodes {
+		var values [2]uint32
+		for i, record := range node.records {
+			switch v := record.(type) {
+			case *treeNode:
+				values[i] = uint32(v.index)
+			case int:
+				values[i] = uint32(count + dataSectionSeparator + v)
+			default:
+				values[i] = uint32(count)
+			}
+		}
+		switch recordSize {
+		case 24:
+			out.Write([]byte{byte(values[0] >> 16), byte(values[0] >> 8), byte(values[0])})
+			out.Write([]byte{byte(values[1] >> 16), byte(values[1] >> 8), byte(values[1])})
+		case 28:
+			out.Write([]byte{byte(values[0] >> 16), byte(values[0] >> 8), byte(values[0])})
+			out.WriteByte(byte(values[0]>>20&0xf0 | values[1]>>24&0x0f))
+			out.Write([]byte{byte(values[1] >> 16), byte(values[1] >> 8), byte(values[1])})
+		default:
+			binary.Write(&out, binary.BigEndian, values)
+		}
+	}
+	out.Write(make([]byte, dataSectionSeparator))
+	out.Write(data.Bytes())
+
+	out.Write(metadataMarker)
+	metadata := &dataWriter{}
+	metadata.write(map[string]interface{}{
+		"binary_format_major_version": uint32(2),
+		"build_epoch":                 uint64(1700000000),
+		"database_type":               "GeoLite2-City",
+		"ip_version":                  uint32(ipVersion),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  uint32(count),
+		"record_size":                 uint32(recordSize),
+	})
+	out.Write(metadata.Bytes())
+	return out.Bytes()
+}
+
+func names(name string) map[string]interface{} {
+	return map[string]interface{}{"names": map[string]interface{}{"en": name, "de": name + "?"}}
+}
+
+func TestLookup(t *testing.T) {
+	data := &dataWriter{}
+	// Pointers reach at least 2048 bytes in with two bytes
+	data.Write(make([]byte, 2100))
+	gb := names("United Kingdom")
+	gb["iso_code"] = "GB"
+	gbOffset := data.write(gb)
+	london := data.write(map[string]interface{}{"city": names("London"), "country": pointer(gbOffset)})
+	// A name long enough for an extended size
+	llanfair := data.write(map[string]interface{}{
+		"city":    names("Llanfairpwllgwyngyllgogerychwyrndrobwllllantysiliogogogoch"),
+		"country": pointer(gbOffset),
+	})
+	au := names("Australia")
+	au["iso_code"] = "AU"
+	anycast := data.write(map[string]interface{}{"registered_country": au})
+	de := names("Germany")
+	de["iso_code"] = "DE"
+	germany := data.write(map[string]interface{}{"country": de})
+
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			t.Run(fmt.Sprintf("IPv%d/%d", ipVersion, recordSize), func(t *testing.T) {
+				networks := map[string]int{"81.2.69.0/24": london, "81.2.70.0/23": llanfair, "1.1.1.0/24": anycast}
+				if ipVersion == 6 {
+					networks["2001:db8::/32"] = germany
+				}
+				reader, err := New(buildDatabase(t, ipVersion, recordSize, data, networks))
+				require.NoError(t, err)
+				assert.Equal(t, "GeoLite2-City", reader.DatabaseType)
+				assert.Equal(t, uint64(1700000000), reader.BuildEpoch)
+
+				location, ok := reader.Lookup("81.2.69.142")
+				assert.True(t, ok)
+				assert.Equal(t, Location{Country: "GB", CountryName: "United Kingdom", City: "London"}, location)
+
+				location, ok = reader.Lookup("81.2.71.1")
+				assert.True(t, ok)
+				assert.Equal(t, "Llanfairpwllgwyngyllgogerychwyrndrobwllllantysiliogogogoch", location.City)
+
+				location, ok = reader.Lookup("1.1.1.1")
+				assert.True(t, ok, "registered country")
+				assert.Equal(t, Location{Country: "AU", CountryName: "Australia"}, location)
+
+				location, ok = reader.Lookup("2001:db8::1")
+				assert.Equal(t, ipVersion == 6, ok)
+				if ok {
+					assert.Equal(t, "DE", location.Country)
+				}
+
+				for _, ip := range []string{"81.2.72.1", "10.0.0.1", "2001:db9::1", "not an ip", ""} {
+					_, ok := reader.Lookup(ip)
+					assert.False(t, ok, ip)
+				}
+			})
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "empty.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0644))
+	_, err = Open(path)
+	assert.ErrorContains(t, err, "invalid MaxMind DB file")
+
+	_, err = New(buildDatabase(t, 4, 20, &dataWriter{}, nil))
+	assert.ErrorContains(t, err, "unknown record size: 20")
+}
@@ -0,0 +1,167 @@
+// Package graphql serves GraphQL queries over stored logs and stats with
+// gqlgen. The schema is in schema.graphql and the code executing it is
+// generated from it; the server provides the resolvers. Queries are
+// limited in depth and complexity, and can be persisted.
+package graphql
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// SDL is the schema in the GraphQL schema definition language
+//
+//go:embed schema.graphql
+var SDL string
+
+// Request is a GraphQL request as sent over HTTP. Its variables are
+// decoded with json.Decoder.UseNumber, which Int arguments require.
+type Request = graphql.RawParams
+
+// Response is the result of a request. Data is left out when the request
+// failed before it executed.
+type Response = graphql.Response
+
+// Limits bound the queries an executor runs
+type Limits struct {
+	// MaxDepth bounds how deeply fields nest, and MaxComplexity how many
+	// field values a query can return counting every list item; zero
+	// leaves them unbounded
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Executor runs requests against the schema within limits
+type Executor struct {
+	exec *executor.Executor
+}
+
+// NewExecutor returns an executor of the schema with the server's
+// resolvers. Requests sending a persisted query's hash in place of the
+// query are resolved with persisted, and fail when it is nil.
+func NewExecutor(resolvers ResolverRoot, limits Limits, persisted *PersistedQueries) *Executor {
+	exec := executor.New(NewExecutableSchema(Config{Resolvers: resolvers, Complexity: listComplexity()}))
+	exec.Use(persisted)
+	exec.Use(depthLimit(limits.MaxDepth))
+	exec.Use(&complexityLimit{max: limits.MaxComplexity})
+	return &Executor{exec: exec}
+}
+
+// Execute runs the request's query operation. Introspection, mutations
+// and subscriptions are not supported.
+func (e *Executor) Execute(ctx context.Context, request *Request) *Response {
+	ctx = graphql.StartOperationTrace(ctx)
+	now := graphql.Now()
+	request.ReadTime = graphql.TraceTiming{Start: now, End: now}
+
+	rc, errs := e.exec.CreateOperationContext(ctx, request)
+	if errs != nil {
+		return e.exec.DispatchError(graphql.WithOperationContext(ctx, rc), errs)
+	}
+	responses, ctx := e.exec.DispatchOperation(ctx, rc)
+	return responses(ctx)
+}
+
+// listComplexity counts list fields as many items as their limit allows
+func listComplexity() ComplexityRoot {
+	var c ComplexityRoot
+	c.Query.Logs = func(child int, _, _ *time.Time, _ *string, _ *int, _, _, _ *string, limit, _ int) int {
+		return 1 + limit*child
+	}
+	c.Query.Facets = func(child int, _ string, _, _ *time.Time, _ *string, _ *int, _, _, _ *string, limit int) int {
+		return 1 + limit*child
+	}
+	c.Query.MethodStats = func(child int, _, _ *time.Time, _, _ *string, _ string, limit int) int {
+		return 1 + limit*child
+	}
+	return c
+}
+
+// limitError is the error of a query over a limit
+func limitError(code, format string, args ...interface{}) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, code)
+	return err
+}
+
+// depthLimit rejects queries whose fields nest more deeply than it allows
+type depthLimit int
+
+func (depthLimit) ExtensionName() string { return "DepthLimit" }
+
+func (depthLimit) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (d depthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(rc.Operation.SelectionSet); d > 0 && depth > int(d) {
+		return limitError("QUERY_TOO_DEEP", "Query is nested %d levels deep, more than the maximum of %d.", depth, int(d))
+	}
+	return nil
+}
+
+// selectionDepth is how many levels of fields a selection set nests.
+// Fragments were checked for cycles as the query was validated.
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		var d int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d = selectionDepth(s.Definition.SelectionSet)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
+
+// complexityLimit rejects queries more complex than it allows, and
+// reports the complexity of those it lets run under the response's
+// extensions
+type complexityLimit struct {
+	schema graphql.ExecutableSchema
+	max    int
+}
+
+const complexityExtension = "complexity"
+
+func (*complexityLimit) ExtensionName() string { return "ComplexityLimit" }
+
+func (c *complexityLimit) Validate(schema graphql.ExecutableSchema) error {
+	c.schema = schema
+	return nil
+}
+
+func (c *complexityLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	n := complexity.Calculate(c.schema, rc.Operation, rc.Variables)
+	if c.max > 0 && n > c.max {
+		return limitError("QUERY_TOO_COMPLEX", "Query has a complexity of %d, more than the maximum of %d.", n, c.max)
+	}
+	rc.Stats.SetExtension(complexityExtension, n)
+	return nil
+}
+
+func (*complexityLimit) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if n, ok := graphql.GetOperationContext(ctx).Stats.GetExtension(complexityExtension).(int); ok {
+		graphql.RegisterExtension(ctx, complexityExtension, n)
+	}
+	return next(ctx)
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+	graphql.ResponseInterceptor
+} = &complexityLimit{}
@@ -0,0 +1,253 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var entries = []*models.LogEntry{
+	{ID: 1, Timestamp: time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), LogType: "nginx", Method: "GET", Path: "/", StatusCode: 200,
+		Metadata: map[string]interface{}{"upstream": "app-1"}},
+	{ID: 2, Timestamp: time.Date(2024, 1, 6, 11, 0, 0, 0, time.UTC), LogType: "nginx", Method: "POST", Path: "/login", StatusCode: 401},
+	{ID: 3, Timestamp: time.Date(2024, 1, 6, 10, 0, 0, 0, time.UTC), LogType: "apache", Method: "GET", Path: "/about", StatusCode: 200},
+}
+
+// resolvers serve the entries above, and fail to report processing
+type resolvers struct{}
+
+func (resolvers) LogEntry() LogEntryResolver { return logEntryResolver{} }
+func (resolvers) Query() QueryResolver       { return queryResolver{} }
+
+type logEntryResolver struct{}
+
+func (logEntryResolver) ID(_ context.Context, entry *models.LogEntry) (*string, error) {
+	id := fmt.Sprint(entry.ID)
+	return &id, nil
+}
+
+func (logEntryResolver) Metadata(_ context.Context, entry *models.LogEntry, key *string) (interface{}, error) {
+	if key != nil {
+		return entry.Metadata[*key], nil
+	}
+	return entry.Metadata, nil
+}
+
+type queryResolver struct{}
+
+func (queryResolver) Logs(_ context.Context, _, _ *time.Time, logType *string, _ *int, _, _, _ *string, limit, offset int) ([]*models.LogEntry, error) {
+	var logs []*models.LogEntry
+	for _, entry := range entries[min(offset, len(entries)):] {
+		if (logType == nil || entry.LogType == *logType) && len(logs) < limit {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+func (queryResolver) Facets(_ context.Context, field string, _, _ *time.Time, _ *string, _ *int, _, _, _ *string, _ int) ([]*models.FacetCount, error) {
+	return []*models.FacetCount{{Value: strings.ToLower(field), Count: 3}}, nil
+}
+
+func (queryResolver) MethodStats(_ context.Context, _, _ *time.Time, _, _ *string, groupBy string, _ int) ([]*models.MethodStats, error) {
+	return []*models.MethodStats{{Method: "GET", Path: groupBy, Requests: 2}}, nil
+}
+
+func (queryResolver) Stats(context.Context) (*Stats, error) {
+	total, connected := float64(len(entries)), true
+	return &Stats{TotalLogs: &total, Connected: &connected}, nil
+}
+
+func (queryResolver) Processing(context.Context) (*Processing, error) {
+	return nil, errors.New("processing stats are unavailable")
+}
+
+// run executes the query and returns the response as JSON
+func run(t *testing.T, e *Executor, request *Request) string {
+	t.Helper()
+	data, err := json.Marshal(e.Execute(context.Background(), request))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	e := NewExecutor(resolvers{}, Limits{}, nil)
+
+	got := run(t, e, &Request{Query: `{ logs(limit: 2) { id path upstream: metadata(key: "upstream") } stats { totalLogs connected } }`})
+	assert.JSONEq(t, `{"data": {
+		"logs": [{"id": "1", "path": "/", "upstream": "app-1"}, {"id": "2", "path": "/login", "upstream": null}],
+		"stats": {"totalLogs": 3, "connected": true}
+	}, "extensions": {"complexity": 10}}`, got)
+	assert.True(t, strings.HasPrefix(got, `{"data":{"logs":[{"id":"1","path":`), "fields are returned in selection order")
+
+	got = run(t, e, &Request{Query: `
+		# Variables, enums, fragments and directives
+		query Find($type: String, $withTime: Boolean = false) {
+			logs(logType: $type) { ...Entry timestamp @include(if: $withTime) }
+			facets(field: STATUS_CODE, limit: 1) { __typename ... on Facet { value count } }
+			methodStats(groupBy: PATH) { method path requests @skip(if: true) }
+		}
+		fragment Entry on LogEntry { method statusCode }`,
+		Variables: map[string]interface{}{"type": "apache"}})
+	assert.JSONEq(t, `{"data": {
+		"logs": [{"method": "GET", "statusCode": 200}],
+		"facets": [{"__typename": "Facet", "value": "status_code", "count": 3}],
+		"methodStats": [{"method": "GET", "path": "PATH"}]
+	}, "extensions": {"complexity": 456}}`, got)
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	e := NewExecutor(resolvers{}, Limits{}, nil)
+	response := e.Execute(context.Background(), &Request{Query: `{ stats { totalLogs } processing { workers } }`})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "processing stats are unavailable", response.Errors[0].Message)
+	assert.Equal(t, "processing", response.Errors[0].Path.String())
+
+	assert.JSONEq(t, `{"stats": {"totalLogs": 3}, "processing": null}`, string(response.Data), "other fields are still returned")
+
+	response = e.Execute(context.Background(), &Request{Query: `{ __schema { types { name } } }`})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "introspection disabled", response.Errors[0].Message)
+}
+
+func TestExecuteInvalidRequests(t *testing.T) {
+	e := NewExecutor(resolvers{}, Limits{}, nil)
+	tests := []struct {
+		request Request
+		want    string
+	}{
+		{Request{}, "Must provide query string."},
+		{Request{Query: `{ logs { path `}, "Expected Name, found <EOF>"},
+		{Request{Query: `{ logs { host } }`}, `Cannot query field "host" on type "LogEntry".`},
+		{Request{Query: `{ logs }`}, `must have a selection of subfields`},
+		{Request{Query: `{ facets { value } }`}, `argument "field" of type "FacetField!" is required`},
+		{Request{Query: `{ logs(limit: "ten") { path } }`}, "Int cannot represent non-integer value"},
+		{Request{Query: `{ facets(field: HOST) { value } }`}, `Value "HOST" does not exist in "FacetField!" enum.`},
+		{Request{Query: `{ logs(sort: TIME) { path } }`}, `Unknown argument "sort"`},
+		{Request{Query: `query($n: Int!) { logs(limit: $n) { path } }`}, `must be defined`},
+		{Request{Query: `query($t: String) { logs(startTime: $t) { path } }`}, `used in position expecting type "Time"`},
+		{Request{Query: `{ logs { ...Missing } }`}, `Unknown fragment "Missing".`},
+		{Request{Query: `{ logs { path: method path } }`}, `conflict`},
+		{Request{Query: `mutation { logs { path } }`}, "Schema does not support operation type \"mutation\""},
+		{Request{Query: `query A { logs { path } } query B { stats { totalLogs } }`}, "operation  not found"},
+		{Request{Query: `query A { logs { path } }`, OperationName: "B"}, `operation B not found`},
+	}
+	for _, tt := range tests {
+		response := e.Execute(context.Background(), &tt.request)
+		require.NotEmpty(t, response.Errors, tt.request.Query)
+		assert.Contains(t, response.Errors[0].Message, tt.want, tt.request.Query)
+		assert.Nil(t, response.Data, "invalid requests do not execute")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	e := NewExecutor(resolvers{}, Limits{MaxComplexity: 100, MaxDepth: 2}, nil)
+
+	// Each entry costs its fields, and the list up to limit entries
+	response := e.Execute(context.Background(), &Request{Query: `{ logs(limit: 40) { path method } }`})
+	require.Empty(t, response.Errors)
+	assert.Equal(t, 81, response.Extensions["complexity"])
+
+	response = e.Execute(context.Background(), &Request{Query: `query($n: Int!) { logs(limit: $n) { path method } }`,
+		Variables: map[string]interface{}{"n": json.Number("50")}})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "Query has a complexity of 101, more than the maximum of 100.", response.Errors[0].Message)
+	assert.Equal(t, "QUERY_TOO_COMPLEX", response.Errors[0].Extensions["code"])
+
+	e = NewExecutor(resolvers{}, Limits{MaxDepth: 1}, nil)
+	response = e.Execute(context.Background(), &Request{Query: `{ logs(limit: 1) { ...Entry } } fragment Entry on LogEntry { path }`})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "Query is nested 2 levels deep, more than the maximum of 1.", response.Errors[0].Message)
+	assert.Equal(t, "QUERY_TOO_DEEP", response.Errors[0].Extensions["code"])
+}
+
+// byHash is a request sending a persisted query's hash
+func byHash(hash string) *Request {
+	return &Request{Extensions: map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": hash},
+	}}
+}
+
+func TestPersistedQueries(t *testing.T) {
+	query := `{ stats { totalLogs } }`
+	persisted := NewPersistedQueries(1, false)
+	e := NewExecutor(resolvers{}, Limits{}, persisted)
+
+	// Unknown hashes ask the client to send the query along
+	response := e.Execute(context.Background(), byHash(Hash(query)))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "PersistedQueryNotFound", response.Errors[0].Message)
+	assert.Equal(t, "PERSISTED_QUERY_NOT_FOUND", response.Errors[0].Extensions["code"])
+
+	register := byHash(Hash(query))
+	register.Query = query
+	assert.Empty(t, e.Execute(context.Background(), register).Errors)
+	assert.Equal(t, `{"data":{"stats":{"totalLogs":3}},"extensions":{"complexity":2}}`, run(t, e, byHash(Hash(query))))
+
+	wrong := byHash(Hash("{ logs { path } }"))
+	wrong.Query = query
+	assert.Equal(t, "provided sha does not match query", e.Execute(context.Background(), wrong).Errors[0].Message)
+
+	unsupported := byHash(Hash(query))
+	unsupported.Extensions["persistedQuery"] = map[string]interface{}{"version": float64(2), "sha256Hash": Hash(query)}
+	assert.Equal(t, "Unsupported persisted query version.", e.Execute(context.Background(), unsupported).Errors[0].Message)
+
+	// Registering past the capacity forgets the oldest registration, but
+	// not queries the server added
+	added := persisted.Add(`{ logs { path } }`)
+	other := `{ stats { connected } }`
+	register = byHash(Hash(other))
+	register.Query = other
+	assert.Empty(t, e.Execute(context.Background(), register).Errors)
+	assert.Equal(t, "PersistedQueryNotFound", e.Execute(context.Background(), byHash(Hash(query))).Errors[0].Message)
+	assert.Empty(t, e.Execute(context.Background(), byHash(added)).Errors)
+	assert.Equal(t, 2, persisted.Len())
+
+	// Without persisted queries, hashes are not understood
+	e = NewExecutor(resolvers{}, Limits{}, nil)
+	assert.Equal(t, "PersistedQueryNotSupported", e.Execute(context.Background(), byHash(added)).Errors[0].Message)
+}
+
+func TestPersistedQueriesOnly(t *testing.T) {
+	dir := t.TempDir()
+	query := "query Totals {\n  stats { totalLogs }\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "totals.graphql"), []byte(query), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a query"), 0644))
+
+	persisted := NewPersistedQueries(100, true)
+	n, err := persisted.AddDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	e := NewExecutor(resolvers{}, Limits{}, persisted)
+
+	assert.Empty(t, e.Execute(context.Background(), byHash(Hash(query))).Errors)
+	assert.Empty(t, e.Execute(context.Background(), &Request{Query: query}).Errors, "the text of a persisted query is accepted")
+
+	response := e.Execute(context.Background(), &Request{Query: `{ logs { path } }`})
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "PERSISTED_QUERY_REQUIRED", response.Errors[0].Extensions["code"])
+
+	request := byHash(Hash(`{ logs { path } }`))
+	request.Query = `{ logs { path } }`
+	assert.Equal(t, "PERSISTED_QUERY_REQUIRED", e.Execute(context.Background(), request).Errors[0].Extensions["code"], "clients cannot register queries")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.graphql"), []byte("{ logs {"), 0644))
+	_, err = persisted.AddDir(dir)
+	assert.ErrorContains(t, err, "broken.graphql")
+}
+
+func TestSDL(t *testing.T) {
+	assert.Contains(t, SDL, "type Query {\n")
+	assert.Contains(t, SDL, "  stats: Stats\n")
+	assert.Contains(t, SDL, "type LogEntry {\n")
+}
@@ -0,0 +1,30 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graphql
+
+import (
+	"time"
+)
+
+// What this server has parsed since it started
+type Processing struct {
+	TotalProcessed *float64   `json:"totalProcessed,omitempty"`
+	Errors         *float64   `json:"errors,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	Workers        *int       `json:"workers,omitempty"`
+	BatchSize      *int       `json:"batchSize,omitempty"`
+	// Fraction of the ingestion queue in use during the latest measurement
+	QueueFill *float64 `json:"queueFill,omitempty"`
+}
+
+type Query struct {
+}
+
+// Totals of the stored entries
+type Stats struct {
+	TotalLogs *float64 `json:"totalLogs,omitempty"`
+	// Sum of response sizes
+	TotalSize    *float64 `json:"totalSize,omitempty"`
+	DatabaseType *string  `json:"databaseType,omitempty"`
+	Connected    *bool    `json:"connected,omitempty"`
+}
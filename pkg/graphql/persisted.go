@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// PersistedQueryRef identifies a persisted query in a request's
+// extensions, as Apollo clients send it
+type PersistedQueryRef struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// PersistedQueries are queries known by the hex SHA-256 hash of their
+// text, so clients can send the hash in place of the query. Queries added
+// by the server stay; clients register others by sending the query with
+// its hash, and the oldest of those are forgotten past the capacity.
+type PersistedQueries struct {
+	mu         sync.RWMutex
+	only       bool
+	capacity   int
+	added      map[string]string
+	registered map[string]string
+	order      []string // registered hashes, oldest first
+}
+
+// NewPersistedQueries keeps up to capacity queries registered by clients.
+// With only set, clients cannot register queries and every request must
+// be for a query the server added.
+func NewPersistedQueries(capacity int, only bool) *PersistedQueries {
+	return &PersistedQueries{
+		only:       only,
+		capacity:   capacity,
+		added:      make(map[string]string),
+		registered: make(map[string]string),
+	}
+}
+
+// Hash is the hash a query is persisted under
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Add persists a query and returns its hash
+func (p *PersistedQueries) Add(query string) string {
+	hash := Hash(query)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.added[hash] = query
+	return hash
+}
+
+// AddDir persists each .graphql file in the directory and returns how
+// many there were
+func (p *PersistedQueries) AddDir(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.graphql"))
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read persisted query: %w", err)
+		}
+		if _, err := parser.ParseQuery(&ast.Source{Name: filepath.Base(file), Input: string(query)}); err != nil {
+			return 0, fmt.Errorf("persisted query %s: %w", filepath.Base(file), err)
+		}
+		p.Add(string(query))
+	}
+	return len(files), nil
+}
+
+// Len is how many queries are persisted
+func (p *PersistedQueries) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.added) + len(p.registered)
+}
+
+func (p *PersistedQueries) get(hash string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if query, ok := p.added[hash]; ok {
+		return query, true
+	}
+	query, ok := p.registered[hash]
+	return query, ok
+}
+
+func (p *PersistedQueries) register(hash, query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.added[hash]; ok {
+		return
+	}
+	if _, ok := p.registered[hash]; ok {
+		return
+	}
+	p.registered[hash] = query
+	p.order = append(p.order, hash)
+	for len(p.order) > p.capacity {
+		delete(p.registered, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+func (*PersistedQueries) ExtensionName() string { return "PersistedQueries" }
+
+func (*PersistedQueries) Validate(graphql.ExecutableSchema) error { return nil }
+
+// MutateOperationParameters resolves the query a request runs from the
+// hash in its extensions, and registers the queries clients send with
+// their hash
+func (p *PersistedQueries) MutateOperationParameters(_ context.Context, request *graphql.RawParams) *gqlerror.Error {
+	query, err := p.resolve(request)
+	if err != nil {
+		return err
+	}
+	request.Query = query
+	return nil
+}
+
+// resolve returns the query a request runs
+func (p *PersistedQueries) resolve(request *graphql.RawParams) (string, *gqlerror.Error) {
+	extension, sent := request.Extensions["persistedQuery"]
+	if !sent {
+		if request.Query == "" {
+			return "", gqlerror.Errorf("Must provide query string.")
+		}
+		if p != nil && p.only {
+			if _, ok := p.get(Hash(request.Query)); !ok {
+				return "", persistedError("Only persisted queries are allowed.", "PERSISTED_QUERY_REQUIRED")
+			}
+		}
+		return request.Query, nil
+	}
+
+	if p == nil {
+		return "", persistedError("PersistedQueryNotSupported", "PERSISTED_QUERY_NOT_SUPPORTED")
+	}
+	var ref PersistedQueryRef
+	if body, err := json.Marshal(extension); err != nil || json.Unmarshal(body, &ref) != nil {
+		return "", persistedError("Invalid persisted query extension.", "BAD_REQUEST")
+	}
+	if ref.Version != 1 {
+		return "", persistedError("Unsupported persisted query version.", "PERSISTED_QUERY_NOT_SUPPORTED")
+	}
+	hash := strings.ToLower(ref.SHA256Hash)
+	if request.Query == "" {
+		query, ok := p.get(hash)
+		if !ok {
+			return "", persistedError("PersistedQueryNotFound", "PERSISTED_QUERY_NOT_FOUND")
+		}
+		return query, nil
+	}
+
+	if Hash(request.Query) != hash {
+		return "", persistedError("provided sha does not match query", "BAD_REQUEST")
+	}
+	if _, ok := p.get(hash); !ok {
+		if p.only {
+			return "", persistedError("Only persisted queries are allowed.", "PERSISTED_QUERY_REQUIRED")
+		}
+		if _, err := parser.ParseQuery(&ast.Source{Input: request.Query}); err != nil {
+			return "", gqlerror.WrapIfUnwrapped(err)
+		}
+		p.register(hash, request.Query)
+	}
+	return request.Query, nil
+}
+
+func persistedError(message, code string) *gqlerror.Error {
+	err := gqlerror.Errorf("%s", message)
+	errcode.Set(err, code)
+	return err
+}
+
+var _ graphql.OperationParameterMutator = &PersistedQueries{}
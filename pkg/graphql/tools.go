@@ -0,0 +1,6 @@
+//go:build tools
+
+package graphql
+
+// gqlgen generates the executor; importing it here keeps it in go.mod
+import _ "github.com/99designs/gqlgen"
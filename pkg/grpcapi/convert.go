@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// FromEntry converts a stored entry. Metadata values other than strings
+// are encoded as JSON.
+func FromEntry(entry *models.LogEntry) *LogEntry {
+	converted := &LogEntry{
+		Id:             entry.ID,
+		Timestamp:      Timestamp(entry.Timestamp),
+		LogType:        entry.LogType,
+		SourceIp:       entry.SourceIP,
+		Method:         entry.Method,
+		Path:           entry.Path,
+		StatusCode:     int32(entry.StatusCode),
+		ResponseSize:   entry.ResponseSize,
+		UserAgent:      entry.UserAgent,
+		Referer:        entry.Referer,
+		ProcessingTime: entry.ProcessingTime,
+		RawLog:         entry.RawLog,
+		CreatedAt:      Timestamp(entry.CreatedAt),
+	}
+	if len(entry.Metadata) > 0 {
+		converted.Metadata = make(map[string]string, len(entry.Metadata))
+		for key, value := range entry.Metadata {
+			if text, ok := value.(string); ok {
+				converted.Metadata[key] = text
+			} else if data, err := json.Marshal(value); err == nil {
+				converted.Metadata[key] = string(data)
+			}
+		}
+	}
+	return converted
+}
+
+// Model converts an entry for storing. Its metadata values are kept as
+// strings.
+func (x *LogEntry) Model() *models.LogEntry {
+	entry := &models.LogEntry{
+		ID:             x.GetId(),
+		Timestamp:      Time(x.GetTimestamp()),
+		LogType:        x.GetLogType(),
+		SourceIP:       x.GetSourceIp(),
+		Method:         x.GetMethod(),
+		Path:           x.GetPath(),
+		StatusCode:     int(x.GetStatusCode()),
+		ResponseSize:   x.GetResponseSize(),
+		UserAgent:      x.GetUserAgent(),
+		Referer:        x.GetReferer(),
+		ProcessingTime: x.GetProcessingTime(),
+		RawLog:         x.GetRawLog(),
+		CreatedAt:      Time(x.GetCreatedAt()),
+	}
+	if len(x.GetMetadata()) > 0 {
+		entry.Metadata = make(models.LogMetadata, len(x.Metadata))
+		for key, value := range x.Metadata {
+			entry.Metadata[key] = value
+		}
+	}
+	return entry
+}
+
+// Model converts a filter; a nil filter matches every entry
+func (x *LogFilter) Model() *models.LogFilter {
+	filter := &models.LogFilter{}
+	if x == nil {
+		return filter
+	}
+	filter.StartTime = timePointer(x.StartTime)
+	filter.EndTime = timePointer(x.EndTime)
+	filter.LogType = x.LogType
+	if x.StatusCode != nil {
+		statusCode := int(*x.StatusCode)
+		filter.StatusCode = &statusCode
+	}
+	filter.MinStatusCode = int(x.MinStatusCode)
+	filter.SourceIP = x.SourceIp
+	filter.Path = x.Path
+	filter.ExactPath = x.ExactPath
+	filter.Method = x.Method
+	return filter
+}
+
+// FromFilter converts a filter, leaving out its limit, offset, sort and
+// positions
+func FromFilter(filter *models.LogFilter) *LogFilter {
+	converted := &LogFilter{
+		LogType:       filter.LogType,
+		MinStatusCode: int32(filter.MinStatusCode),
+		SourceIp:      filter.SourceIP,
+		Path:          filter.Path,
+		ExactPath:     filter.ExactPath,
+		Method:        filter.Method,
+	}
+	if filter.StartTime != nil {
+		converted.StartTime = timestamppb.New(*filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		converted.EndTime = timestamppb.New(*filter.EndTime)
+	}
+	if filter.StatusCode != nil {
+		statusCode := int32(*filter.StatusCode)
+		converted.StatusCode = &statusCode
+	}
+	return converted
+}
+
+// Timestamp converts a time, leaving the zero time unset
+func Timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// Time converts a timestamp in UTC, or returns the zero time if it is unset
+func Time(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func timePointer(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,190 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestLogEntryRoundTrip(t *testing.T) {
+	entry := &LogEntry{
+		Id:             42,
+		Timestamp:      timestamppb.New(time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)),
+		LogType:        "nginx",
+		SourceIp:       "192.0.2.1",
+		Method:         "GET",
+		Path:           "/api",
+		StatusCode:     -1,
+		ResponseSize:   1024,
+		ProcessingTime: 0.25,
+		RawLog:         "raw",
+		Metadata:       map[string]string{"region": "eu", "empty": ""},
+	}
+	data, err := proto.Marshal(entry)
+	require.NoError(t, err)
+
+	decoded := &LogEntry{}
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	assert.True(t, proto.Equal(entry, decoded))
+}
+
+func TestLogFilterKeepsZeroStatusCode(t *testing.T) {
+	statusCode := int32(0)
+	data, err := proto.Marshal(&LogFilter{StatusCode: &statusCode})
+	require.NoError(t, err)
+
+	decoded := &LogFilter{}
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	require.NotNil(t, decoded.StatusCode)
+	assert.Equal(t, 0, *decoded.Model().StatusCode)
+
+	assert.Nil(t, (&LogFilter{}).Model().StatusCode, "an unset status code matches any")
+}
+
+func TestModelConversion(t *testing.T) {
+	entry := &models.LogEntry{
+		LogType:  "apache",
+		Metadata: models.LogMetadata{"user": "alice", "retries": 3.0, "tags": []interface{}{"a"}},
+	}
+	converted := FromEntry(entry)
+	assert.Equal(t, map[string]string{"user": "alice", "retries": "3", "tags": `["a"]`}, converted.Metadata)
+	assert.Equal(t, models.LogMetadata{"user": "alice", "retries": "3", "tags": `["a"]`}, converted.Model().Metadata)
+	assert.Nil(t, converted.Timestamp, "the zero time is left unset")
+	assert.True(t, converted.Model().Timestamp.IsZero())
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	statusCode := 404
+	filter := FromFilter(&models.LogFilter{StartTime: &start, StatusCode: &statusCode, Method: "GET", Limit: 10})
+	assert.Equal(t, &models.LogFilter{StartTime: &start, StatusCode: &statusCode, Method: "GET"}, filter.Model())
+	assert.Equal(t, &models.LogFilter{}, (*LogFilter)(nil).Model())
+}
+
+// fakeService records the calls it gets
+type fakeService struct {
+	UnimplementedLogServiceServer
+	ingested []*IngestRequest
+	query    *QueryRequest
+}
+
+func (f *fakeService) Ingest(stream LogService_IngestServer) error {
+	response := &IngestResponse{}
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(response)
+		}
+		if err != nil {
+			return err
+		}
+		f.ingested = append(f.ingested, request)
+		response.Accepted += int64(len(request.Lines) + len(request.Entries))
+	}
+}
+
+func (f *fakeService) Query(request *QueryRequest, stream LogService_QueryServer) error {
+	f.query = request
+	for i := int32(1); i <= request.Limit; i++ {
+		if err := stream.Send(&LogEntry{Id: int64(i)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeService) Stats(ctx context.Context, request *StatsRequest) (*StatsResponse, error) {
+	if len(request.Facets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "facets are required")
+	}
+	return &StatsResponse{TotalLogs: 7, Facets: []*Facet{{Field: request.Facets[0], Values: []*FacetCount{{Value: "GET", Count: 5}}}}}, nil
+}
+
+func (f *fakeService) GenerateReport(request *ReportRequest, stream LogService_GenerateReportServer) error {
+	if err := stream.Send(&Job{Id: "job", Status: "running"}); err != nil {
+		return err
+	}
+	return stream.Send(&Job{Id: "job", Status: "completed", ResultJson: `{"format":"` + request.Format + `"}`, FinishedAt: timestamppb.Now()})
+}
+
+// startService serves the service in memory and returns a client of it
+func startService(t *testing.T, srv LogServiceServer) LogServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(srv)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return NewLogServiceClient(conn)
+}
+
+func TestService(t *testing.T) {
+	srv := &fakeService{}
+	client := startService(t, srv)
+	ctx := context.Background()
+
+	ingest, err := client.Ingest(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ingest.Send(&IngestRequest{LogType: "nginx", Lines: []string{"a", "b"}}))
+	require.NoError(t, ingest.Send(&IngestRequest{Entries: []*LogEntry{{LogType: "generic", Path: "/"}}}))
+	response, err := ingest.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), response.Accepted)
+	require.Len(t, srv.ingested, 2)
+	assert.Equal(t, "/", srv.ingested[1].Entries[0].Path)
+
+	query, err := client.Query(ctx, &QueryRequest{Filter: &LogFilter{Method: "GET"}, Limit: 3, Sort: "status_code:asc"})
+	require.NoError(t, err)
+	var ids []int64
+	for {
+		entry, err := query.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, entry.Id)
+	}
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Equal(t, "GET", srv.query.Filter.Method)
+	assert.Equal(t, "status_code:asc", srv.query.Sort)
+
+	stats, err := client.Stats(ctx, &StatsRequest{Facets: []string{"method"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), stats.TotalLogs)
+	assert.Equal(t, int64(5), stats.Facets[0].Values[0].Count)
+	_, err = client.Stats(ctx, &StatsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	report, err := client.GenerateReport(ctx, &ReportRequest{Format: "csv"})
+	require.NoError(t, err)
+	var statuses []string
+	for {
+		job, err := report.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		statuses = append(statuses, job.Status)
+		if job.FinishedAt != nil {
+			assert.JSONEq(t, `{"format":"csv"}`, job.ResultJson)
+		}
+	}
+	assert.Equal(t, []string{"running", "completed"}, statuses)
+}
@@ -0,0 +1,330 @@
+// The gRPC API of the log analyzer, for services that ingest and query
+// logs without going through multipart uploads and JSON. Clients can
+// generate their stubs from this file, and field numbers must not change.
+// Run go generate after changing it.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: logs.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	LogService_Ingest_FullMethodName         = "/loganalyzer.v1.LogService/Ingest"
+	LogService_Query_FullMethodName          = "/loganalyzer.v1.LogService/Query"
+	LogService_Stats_FullMethodName          = "/loganalyzer.v1.LogService/Stats"
+	LogService_GenerateReport_FullMethodName = "/loganalyzer.v1.LogService/GenerateReport"
+)
+
+// LogServiceClient is the client API for LogService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LogServiceClient interface {
+	// Ingest parses and stores the lines and entries of each message as it
+	// arrives. The server stops reading while ingestion is paused by load
+	// shedding, so clients are held back rather than refused.
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (LogService_IngestClient, error)
+	// Query streams the entries matching a filter, newest first unless
+	// sorted otherwise
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (LogService_QueryClient, error)
+	// Stats counts stored entries and those matching a filter, by facet
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// GenerateReport starts a report job and streams its progress until it
+	// finishes. The job carries on if the client goes away.
+	GenerateReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (LogService_GenerateReportClient, error)
+}
+
+type logServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogServiceClient(cc grpc.ClientConnInterface) LogServiceClient {
+	return &logServiceClient{cc}
+}
+
+func (c *logServiceClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (LogService_IngestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogService_ServiceDesc.Streams[0], LogService_Ingest_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logServiceIngestClient{stream}
+	return x, nil
+}
+
+type LogService_IngestClient interface {
+	Send(*IngestRequest) error
+	CloseAndRecv() (*IngestResponse, error)
+	grpc.ClientStream
+}
+
+type logServiceIngestClient struct {
+	grpc.ClientStream
+}
+
+func (x *logServiceIngestClient) Send(m *IngestRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *logServiceIngestClient) CloseAndRecv() (*IngestResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(IngestResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (LogService_QueryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogService_ServiceDesc.Streams[1], LogService_Query_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logServiceQueryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogService_QueryClient interface {
+	Recv() (*LogEntry, error)
+	grpc.ClientStream
+}
+
+type logServiceQueryClient struct {
+	grpc.ClientStream
+}
+
+func (x *logServiceQueryClient) Recv() (*LogEntry, error) {
+	m := new(LogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, LogService_Stats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logServiceClient) GenerateReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (LogService_GenerateReportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogService_ServiceDesc.Streams[2], LogService_GenerateReport_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logServiceGenerateReportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogService_GenerateReportClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type logServiceGenerateReportClient struct {
+	grpc.ClientStream
+}
+
+func (x *logServiceGenerateReportClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LogServiceServer is the server API for LogService service.
+// All implementations must embed UnimplementedLogServiceServer
+// for forward compatibility
+type LogServiceServer interface {
+	// Ingest parses and stores the lines and entries of each message as it
+	// arrives. The server stops reading while ingestion is paused by load
+	// shedding, so clients are held back rather than refused.
+	Ingest(LogService_IngestServer) error
+	// Query streams the entries matching a filter, newest first unless
+	// sorted otherwise
+	Query(*QueryRequest, LogService_QueryServer) error
+	// Stats counts stored entries and those matching a filter, by facet
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// GenerateReport starts a report job and streams its progress until it
+	// finishes. The job carries on if the client goes away.
+	GenerateReport(*ReportRequest, LogService_GenerateReportServer) error
+	mustEmbedUnimplementedLogServiceServer()
+}
+
+// UnimplementedLogServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLogServiceServer struct {
+}
+
+func (UnimplementedLogServiceServer) Ingest(LogService_IngestServer) error {
+	return status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedLogServiceServer) Query(*QueryRequest, LogService_QueryServer) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedLogServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedLogServiceServer) GenerateReport(*ReportRequest, LogService_GenerateReportServer) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateReport not implemented")
+}
+func (UnimplementedLogServiceServer) mustEmbedUnimplementedLogServiceServer() {}
+
+// UnsafeLogServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogServiceServer will
+// result in compilation errors.
+type UnsafeLogServiceServer interface {
+	mustEmbedUnimplementedLogServiceServer()
+}
+
+func RegisterLogServiceServer(s grpc.ServiceRegistrar, srv LogServiceServer) {
+	s.RegisterService(&LogService_ServiceDesc, srv)
+}
+
+func _LogService_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LogServiceServer).Ingest(&logServiceIngestServer{stream})
+}
+
+type LogService_IngestServer interface {
+	SendAndClose(*IngestResponse) error
+	Recv() (*IngestRequest, error)
+	grpc.ServerStream
+}
+
+type logServiceIngestServer struct {
+	grpc.ServerStream
+}
+
+func (x *logServiceIngestServer) SendAndClose(m *IngestResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *logServiceIngestServer) Recv() (*IngestRequest, error) {
+	m := new(IngestRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _LogService_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogServiceServer).Query(m, &logServiceQueryServer{stream})
+}
+
+type LogService_QueryServer interface {
+	Send(*LogEntry) error
+	grpc.ServerStream
+}
+
+type logServiceQueryServer struct {
+	grpc.ServerStream
+}
+
+func (x *logServiceQueryServer) Send(m *LogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LogService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogService_GenerateReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogServiceServer).GenerateReport(m, &logServiceGenerateReportServer{stream})
+}
+
+type LogService_GenerateReportServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
+
+type logServiceGenerateReportServer struct {
+	grpc.ServerStream
+}
+
+func (x *logServiceGenerateReportServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LogService_ServiceDesc is the grpc.ServiceDesc for LogService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loganalyzer.v1.LogService",
+	HandlerType: (*LogServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Stats",
+			Handler:    _LogService_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ingest",
+			Handler:       _LogService_Ingest_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Query",
+			Handler:       _LogService_Query_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GenerateReport",
+			Handler:       _LogService_GenerateReport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "logs.proto",
+}
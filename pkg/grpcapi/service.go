@@ -0,0 +1,16 @@
+// Package grpcapi is the gRPC API of the log analyzer, described by
+// logs.proto: streaming ingestion, streaming queries, stats and report
+// generation. logs.pb.go and logs_grpc.pb.go are generated from it;
+// convert.go converts its messages to and from the models.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative logs.proto
+
+import "google.golang.org/grpc"
+
+// NewServer returns a gRPC server of the service
+func NewServer(srv LogServiceServer, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	RegisterLogServiceServer(server, srv)
+	return server
+}
@@ -0,0 +1,225 @@
+// Package hec decodes Splunk HTTP Event Collector requests, so logging
+// drivers, forwarders and libraries configured for HEC can send events
+// without changes.
+package hec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// LogType is the log type of events stored without a parser. Their entries
+// carry the event as a free-text message.
+const LogType = "hec"
+
+// Event is a received event. Event holds a string or, for structured
+// events, the decoded JSON value.
+type Event struct {
+	Time       time.Time
+	Host       string
+	Source     string
+	SourceType string
+	Index      string
+	Event      any
+	Fields     map[string]any // indexed fields
+}
+
+// Status codes HEC reports in response bodies
+const (
+	CodeSuccess       = 0
+	CodeTokenRequired = 2
+	CodeInvalidAuth   = 3
+	CodeInvalidToken  = 4
+	CodeNoData        = 5
+	CodeInvalidFormat = 6
+	CodeServerBusy    = 9
+	CodeEventRequired = 12
+	CodeEventBlank    = 13
+	CodeHealthy       = 17
+)
+
+// Error is a rejected request, with the HEC code and text to reply with
+type Error struct {
+	Code  int
+	Text  string
+	Event int // index of the event at fault, or -1
+}
+
+func (e *Error) Error() string {
+	if e.Event >= 0 {
+		return fmt.Sprintf("%s (event %d)", e.Text, e.Event)
+	}
+	return e.Text
+}
+
+// rawEvent is an event as sent. time is seconds since the epoch, as a
+// number or a string, with an optional fraction.
+type rawEvent struct {
+	Time       json.RawMessage `json:"time"`
+	Host       string          `json:"host"`
+	Source     string          `json:"source"`
+	SourceType string          `json:"sourcetype"`
+	Index      string          `json:"index"`
+	Event      json.RawMessage `json:"event"`
+	Fields     map[string]any  `json:"fields"`
+}
+
+// Decode reads the events of a request body: JSON event objects, one
+// after another, optionally gzipped. Events without a time are given the
+// current time.
+func Decode(body []byte, contentEncoding string) ([]Event, error) {
+	switch strings.ToLower(contentEncoding) {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, &Error{Code: CodeInvalidFormat, Text: "Invalid gzip body", Event: -1}
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, &Error{Code: CodeInvalidFormat, Text: "Invalid gzip body", Event: -1}
+		}
+	default:
+		return nil, &Error{Code: CodeInvalidFormat, Text: fmt.Sprintf("Unsupported content encoding %q", contentEncoding), Event: -1}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, &Error{Code: CodeNoData, Text: "No data", Event: -1}
+	}
+
+	now := time.Now().UTC()
+	var events []Event
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	for i := 0; ; i++ {
+		var raw rawEvent
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, &Error{Code: CodeInvalidFormat, Text: "Invalid data format", Event: i}
+		}
+
+		event, err := raw.decode(i, now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
+
+func (raw rawEvent) decode(i int, now time.Time) (Event, error) {
+	event := Event{
+		Time:       now,
+		Host:       raw.Host,
+		Source:     raw.Source,
+		SourceType: raw.SourceType,
+		Index:      raw.Index,
+		Fields:     raw.Fields,
+	}
+
+	if len(raw.Event) == 0 || string(raw.Event) == "null" {
+		return event, &Error{Code: CodeEventRequired, Text: "Event field is required", Event: i}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw.Event))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event.Event); err != nil {
+		return event, &Error{Code: CodeInvalidFormat, Text: "Invalid data format", Event: i}
+	}
+	if s, ok := event.Event.(string); ok && strings.TrimSpace(s) == "" {
+		return event, &Error{Code: CodeEventBlank, Text: "Event field cannot be blank", Event: i}
+	}
+
+	if len(raw.Time) > 0 && string(raw.Time) != "null" {
+		seconds, err := strconv.ParseFloat(strings.Trim(string(raw.Time), `"`), 64)
+		if err != nil || seconds < 0 {
+			return event, &Error{Code: CodeInvalidFormat, Text: "Invalid data format", Event: i}
+		}
+		whole, fraction := math.Modf(seconds)
+		event.Time = time.Unix(int64(whole), int64(math.Round(fraction*1e6))*1e3).UTC()
+	}
+	return event, nil
+}
+
+// Line returns the event as a line of text. Events that are not strings
+// are encoded as JSON.
+func (e Event) Line() string {
+	if s, ok := e.Event.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(e.Event)
+	if err != nil {
+		return fmt.Sprint(e.Event)
+	}
+	return string(encoded)
+}
+
+// ParseFunc parses a line of a log type, such as Processor.ParseLine
+type ParseFunc func(line, logType string) (*models.LogEntry, error)
+
+// ToLogEntry converts an event. With a log type the event is parsed;
+// events without one, or that do not parse, are stored as LogType
+// messages. Indexed fields, the fields of an object event, and the host,
+// source, sourcetype and index are added to the metadata in that order of
+// precedence, without replacing fields the parser extracted. The event's
+// time is used unless the parser found one.
+func ToLogEntry(event Event, logType string, parse ParseFunc) *models.LogEntry {
+	line := event.Line()
+
+	var parsed *models.LogEntry
+	if logType != "" && parse != nil {
+		if e, err := parse(line, logType); err == nil && e != nil {
+			parsed = e
+		}
+	}
+	if parsed == nil {
+		now := time.Now()
+		parsed = &models.LogEntry{
+			LogType:   LogType,
+			Path:      line,
+			RawLog:    line,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	if parsed.Timestamp.IsZero() {
+		parsed.Timestamp = event.Time
+	}
+
+	if parsed.Metadata == nil {
+		parsed.Metadata = make(models.LogMetadata, len(event.Fields)+4)
+	}
+	add := func(key string, value any) {
+		if _, exists := parsed.Metadata[key]; !exists {
+			parsed.Metadata[key] = value
+		}
+	}
+	for key, value := range event.Fields {
+		add(key, value)
+	}
+	if fields, ok := event.Event.(map[string]any); ok {
+		for key, value := range fields {
+			add(key, value)
+		}
+	}
+	for key, value := range map[string]string{
+		"host":       event.Host,
+		"source":     event.Source,
+		"sourcetype": event.SourceType,
+		"index":      event.Index,
+	} {
+		if value != "" {
+			add(key, value)
+		}
+	}
+	return parsed
+}
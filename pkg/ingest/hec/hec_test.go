@@ -0,0 +1,104 @@
+package hec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestDecode(t *testing.T) {
+	body := `{"time": 1700000000.25, "host": "web-1", "sourcetype": "nginx", "event": "GET / 200"}
+		{"time": "1700000001", "source": "app", "index": "main", "event": {"msg": "started", "level": "info"}, "fields": {"env": "prod"}}{"event": "no time"}`
+
+	events, err := Decode([]byte(body), "")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, time.Unix(1700000000, 250e6).UTC(), events[0].Time)
+	assert.Equal(t, "web-1", events[0].Host)
+	assert.Equal(t, "nginx", events[0].SourceType)
+	assert.Equal(t, "GET / 200", events[0].Line())
+	assert.Equal(t, time.Unix(1700000001, 0).UTC(), events[1].Time)
+	assert.Equal(t, "main", events[1].Index)
+	assert.Equal(t, "prod", events[1].Fields["env"])
+	assert.JSONEq(t, `{"msg": "started", "level": "info"}`, events[1].Line())
+	assert.WithinDuration(t, time.Now(), events[2].Time, time.Minute)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(body))
+	zw.Close()
+	events, err = Decode(gz.Bytes(), "gzip")
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	for _, tc := range []struct {
+		body     string
+		encoding string
+		code     int
+		event    int
+	}{
+		{" \n", "", CodeNoData, -1},
+		{`{"event": "ok"} {"time": 1}`, "", CodeEventRequired, 1},
+		{`{"event": null}`, "", CodeEventRequired, 0},
+		{`{"event": "  "}`, "", CodeEventBlank, 0},
+		{`{"event": "ok"} {"event": `, "", CodeInvalidFormat, 1},
+		{`["not", "an", "event"]`, "", CodeInvalidFormat, 0},
+		{`{"time": "soon", "event": "x"}`, "", CodeInvalidFormat, 0},
+		{body, "gzip", CodeInvalidFormat, -1},
+		{body, "br", CodeInvalidFormat, -1},
+	} {
+		_, err := Decode([]byte(tc.body), tc.encoding)
+		var hecErr *Error
+		require.True(t, errors.As(err, &hecErr), tc.body)
+		assert.Equal(t, tc.code, hecErr.Code, tc.body)
+		assert.Equal(t, tc.event, hecErr.Event, tc.body)
+	}
+}
+
+func TestToLogEntry(t *testing.T) {
+	sent := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := Event{
+		Time:       sent,
+		Host:       "web-1",
+		SourceType: "nginx",
+		Event:      "line",
+		Fields:     map[string]any{"status": "field", "env": "prod"},
+	}
+
+	parse := func(line, logType string) (*models.LogEntry, error) {
+		if logType != "nginx" {
+			return nil, errors.New("unparsable")
+		}
+		return &models.LogEntry{LogType: "nginx", Path: "/", RawLog: line, Metadata: models.LogMetadata{"status": "parsed"}}, nil
+	}
+
+	got := ToLogEntry(event, "nginx", parse)
+	assert.Equal(t, "nginx", got.LogType)
+	assert.Equal(t, sent, got.Timestamp, "the event time is used when the line has none")
+	assert.Equal(t, "parsed", got.Metadata["status"], "fields do not replace parsed fields")
+	assert.Equal(t, "prod", got.Metadata["env"])
+	assert.Equal(t, "web-1", got.Metadata["host"])
+	assert.Equal(t, "nginx", got.Metadata["sourcetype"])
+
+	got = ToLogEntry(event, "apache", parse)
+	assert.Equal(t, LogType, got.LogType, "events that do not parse are kept as messages")
+	assert.Equal(t, "line", got.Path)
+	assert.Equal(t, "field", got.Metadata["status"])
+
+	event.Event = map[string]any{"msg": "started", "host": "from-event", "env": "staging"}
+	got = ToLogEntry(event, "", parse)
+	assert.Equal(t, LogType, got.LogType)
+	assert.Equal(t, "from-event", got.Metadata["host"], "event fields take precedence over the host")
+	assert.Equal(t, "prod", got.Metadata["env"], "indexed fields take precedence over event fields")
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(got.RawLog), &decoded))
+	assert.Equal(t, "started", decoded["msg"])
+}
@@ -0,0 +1,279 @@
+// Package loki decodes requests to Loki's push API, so Promtail, Grafana
+// Alloy and other Loki clients can ship logs without changes. Both
+// snappy-compressed protobuf and JSON bodies are accepted.
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/golang/snappy"
+)
+
+// LogType is the log type of lines stored without a parser. Their entries
+// carry the line as a free-text message.
+const LogType = "loki"
+
+// Stream is a set of lines sharing labels
+type Stream struct {
+	Labels  map[string]string
+	Entries []Entry
+}
+
+// Entry is a pushed line
+type Entry struct {
+	Timestamp time.Time
+	Line      string
+	Metadata  map[string]string // structured metadata
+}
+
+// Decode reads a push request body. Protobuf bodies must be
+// snappy-compressed, as Loki requires; JSON bodies may be gzipped.
+func Decode(body []byte, contentType, contentEncoding string) ([]Stream, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && contentType != "" {
+		return nil, fmt.Errorf("invalid content type %q", contentType)
+	}
+
+	switch strings.ToLower(contentEncoding) {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+	case "snappy":
+		if body, err = decodeSnappy(body); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+
+	switch mediaType {
+	case "application/json":
+		return decodeJSON(body)
+	case "", "application/x-protobuf":
+		// Clients send protobuf snappy-compressed without saying so
+		if contentEncoding == "" {
+			if body, err = decodeSnappy(body); err != nil {
+				return nil, err
+			}
+		}
+		return decodeProto(body)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// maxDecodedSize bounds what a snappy block may claim to decompress to
+const maxDecodedSize = 256 << 20
+
+// decodeSnappy decompresses a snappy block, the framing-less format
+// Prometheus and Loki clients compress protobuf bodies with
+func decodeSnappy(src []byte) ([]byte, error) {
+	n, err := snappy.DecodedLen(src)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxDecodedSize {
+		return nil, snappy.ErrTooLarge
+	}
+	return snappy.Decode(nil, src)
+}
+
+// jsonPush is the JSON push body. Streams have labels as a map and values
+// of [unix nanoseconds, line] or [unix nanoseconds, line, metadata]; the
+// older form with a label string and timestamped entries is also accepted.
+type jsonPush struct {
+	Streams []struct {
+		Stream  map[string]string   `json:"stream"`
+		Values  [][]json.RawMessage `json:"values"`
+		Labels  string              `json:"labels"`
+		Entries []struct {
+			Timestamp time.Time `json:"ts"`
+			Line      string    `json:"line"`
+		} `json:"entries"`
+	} `json:"streams"`
+}
+
+func decodeJSON(body []byte) ([]Stream, error) {
+	var push jsonPush
+	if err := json.Unmarshal(body, &push); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	streams := make([]Stream, 0, len(push.Streams))
+	for _, s := range push.Streams {
+		stream := Stream{Labels: s.Stream}
+		if s.Labels != "" {
+			labels, err := ParseLabels(s.Labels)
+			if err != nil {
+				return nil, err
+			}
+			stream.Labels = labels
+		}
+
+		for _, value := range s.Values {
+			entry, err := decodeJSONValue(value)
+			if err != nil {
+				return nil, err
+			}
+			stream.Entries = append(stream.Entries, entry)
+		}
+		for _, e := range s.Entries {
+			stream.Entries = append(stream.Entries, Entry{Timestamp: e.Timestamp.UTC(), Line: e.Line})
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+func decodeJSONValue(value []json.RawMessage) (Entry, error) {
+	var entry Entry
+	if len(value) < 2 || len(value) > 3 {
+		return entry, fmt.Errorf("invalid value: expected [timestamp, line] or [timestamp, line, metadata]")
+	}
+
+	var ts string
+	if err := json.Unmarshal(value[0], &ts); err != nil {
+		return entry, fmt.Errorf("invalid timestamp %s: must be a string of unix nanoseconds", value[0])
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return entry, fmt.Errorf("invalid timestamp %q: must be unix nanoseconds", ts)
+	}
+	entry.Timestamp = time.Unix(0, nanos).UTC()
+
+	if err := json.Unmarshal(value[1], &entry.Line); err != nil {
+		return entry, fmt.Errorf("invalid line: %w", err)
+	}
+	if len(value) == 3 {
+		if err := json.Unmarshal(value[2], &entry.Metadata); err != nil {
+			return entry, fmt.Errorf("invalid structured metadata: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// ParseLabels parses a label set in Prometheus notation, such as
+// {job="nginx", host="web-1"}
+func ParseLabels(s string) (map[string]string, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid labels %q: %s", s, reason)
+	}
+
+	rest := strings.TrimSpace(s)
+	if !strings.HasPrefix(rest, "{") || !strings.HasSuffix(rest, "}") {
+		return nil, invalid("must be enclosed in braces")
+	}
+	rest = strings.TrimSpace(rest[1 : len(rest)-1])
+
+	labels := make(map[string]string)
+	for rest != "" {
+		name, after, ok := strings.Cut(rest, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !validLabelName(name) {
+			return nil, invalid("expected name=\"value\"")
+		}
+		after = strings.TrimSpace(after)
+		if !strings.HasPrefix(after, `"`) {
+			return nil, invalid("label values must be quoted")
+		}
+
+		// Find the closing quote, skipping escaped characters
+		end := -1
+		for i := 1; i < len(after); i++ {
+			if after[i] == '\\' {
+				i++
+				continue
+			}
+			if after[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, invalid("unterminated value")
+		}
+		value, err := strconv.Unquote(after[:end+1])
+		if err != nil {
+			return nil, invalid("bad escape in value")
+		}
+		labels[name] = value
+
+		rest = strings.TrimSpace(after[end+1:])
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, invalid("expected a comma between labels")
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+	return labels, nil
+}
+
+func validLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFunc parses a line of a log type, such as Processor.ParseLine
+type ParseFunc func(line, logType string) (*models.LogEntry, error)
+
+// ToLogEntry converts a pushed line. With a log type the line is parsed;
+// lines without one, or that do not parse, are stored as LogType messages.
+// Stream labels and structured metadata are added to the metadata without
+// replacing fields the parser extracted, and the push timestamp is used
+// unless the parser found one.
+func ToLogEntry(labels map[string]string, entry Entry, logType string, parse ParseFunc) *models.LogEntry {
+	var parsed *models.LogEntry
+	if logType != "" && parse != nil {
+		if e, err := parse(entry.Line, logType); err == nil && e != nil {
+			parsed = e
+		}
+	}
+	if parsed == nil {
+		now := time.Now()
+		parsed = &models.LogEntry{
+			LogType:   LogType,
+			Path:      entry.Line,
+			RawLog:    entry.Line,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	if parsed.Timestamp.IsZero() {
+		parsed.Timestamp = entry.Timestamp
+	}
+	if parsed.Metadata == nil {
+		parsed.Metadata = make(models.LogMetadata, len(labels)+len(entry.Metadata))
+	}
+	for _, fields := range []map[string]string{labels, entry.Metadata} {
+		for key, value := range fields {
+			if _, exists := parsed.Metadata[key]; !exists {
+				parsed.Metadata[key] = value
+			}
+		}
+	}
+	return parsed
+}
@@ -0,0 +1,157 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecodeSnappy(t *testing.T) {
+	line := bytes.Repeat([]byte("GET /health 200\n"), 100)
+	decoded, err := decodeSnappy(snappy.Encode(nil, line))
+	require.NoError(t, err)
+	assert.Equal(t, line, decoded)
+
+	huge := binary.AppendUvarint(nil, maxDecodedSize+1)
+	_, err = decodeSnappy(append(huge, 0x00, 'x'))
+	assert.ErrorIs(t, err, snappy.ErrTooLarge)
+	_, err = decodeSnappy([]byte{5, 0x08, 'a', 'b', 'c'})
+	assert.ErrorIs(t, err, snappy.ErrCorrupt, "shorter than announced")
+}
+
+// appendBytes appends a length-delimited field
+func appendBytes(b []byte, number protowire.Number, value []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(b, number, protowire.BytesType), value)
+}
+
+// appendVarint appends a varint field
+func appendVarint(b []byte, number protowire.Number, value uint64) []byte {
+	return protowire.AppendVarint(protowire.AppendTag(b, number, protowire.VarintType), value)
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	ts := appendVarint(appendVarint(nil, 1, 1700000000), 2, 500)
+	pair := appendBytes(appendBytes(nil, 1, []byte("trace_id")), 2, []byte("abc123"))
+	entry := appendBytes(nil, 1, ts)
+	entry = appendBytes(entry, 2, []byte("GET /health 200"))
+	entry = appendBytes(entry, 3, pair)
+
+	stream := appendBytes(nil, 1, []byte(`{job="nginx", host="web-1"}`))
+	stream = appendBytes(stream, 2, entry)
+	stream = appendBytes(stream, 2, appendBytes(nil, 2, []byte("second")))
+	stream = appendVarint(stream, 3, 12345) // hash, ignored
+	push := appendBytes(nil, 1, stream)
+
+	streams, err := Decode(snappy.Encode(nil, push), "application/x-protobuf", "")
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	assert.Equal(t, map[string]string{"job": "nginx", "host": "web-1"}, streams[0].Labels)
+	require.Len(t, streams[0].Entries, 2)
+	assert.Equal(t, time.Unix(1700000000, 500).UTC(), streams[0].Entries[0].Timestamp)
+	assert.Equal(t, "GET /health 200", streams[0].Entries[0].Line)
+	assert.Equal(t, map[string]string{"trace_id": "abc123"}, streams[0].Entries[0].Metadata)
+	assert.Equal(t, "second", streams[0].Entries[1].Line)
+
+	_, err = Decode(snappy.Encode(nil, push[:len(push)-3]), "application/x-protobuf", "")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = Decode(push, "application/x-protobuf", "")
+	assert.True(t, errors.Is(err, snappy.ErrCorrupt), "protobuf must be snappy-compressed")
+}
+
+func TestDecodeJSON(t *testing.T) {
+	body := `{"streams": [
+		{"stream": {"job": "app"}, "values": [
+			["1700000000000000000", "started"],
+			["1700000001000000000", "request", {"trace_id": "abc"}]
+		]},
+		{"labels": "{job=\"legacy\"}", "entries": [{"ts": "2023-11-14T22:13:20Z", "line": "old"}]}
+	]}`
+
+	streams, err := Decode([]byte(body), "application/json; charset=utf-8", "")
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	assert.Equal(t, "app", streams[0].Labels["job"])
+	require.Len(t, streams[0].Entries, 2)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), streams[0].Entries[0].Timestamp)
+	assert.Equal(t, "request", streams[0].Entries[1].Line)
+	assert.Equal(t, "abc", streams[0].Entries[1].Metadata["trace_id"])
+	assert.Equal(t, "legacy", streams[1].Labels["job"])
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), streams[1].Entries[0].Timestamp)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(body))
+	zw.Close()
+	streams, err = Decode(gz.Bytes(), "application/json", "gzip")
+	require.NoError(t, err)
+	assert.Len(t, streams, 2)
+
+	for _, invalid := range []string{
+		`{"streams": [{"stream": {}, "values": [[1700000000, "numeric timestamp"]]}]}`,
+		`{"streams": [{"stream": {}, "values": [["soon", "line"]]}]}`,
+		`{"streams": [{"stream": {}, "values": [["1700000000000000000"]]}]}`,
+		`{"streams": [`,
+	} {
+		_, err := Decode([]byte(invalid), "application/json", "")
+		assert.Error(t, err, invalid)
+	}
+
+	_, err = Decode([]byte(body), "text/plain", "")
+	assert.Error(t, err)
+	_, err = Decode([]byte(body), "application/json", "br")
+	assert.Error(t, err)
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels(`{job="nginx",path="C:\\logs", msg="say \"hi\"",  env="prod" }`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"job": "nginx", "path": `C:\logs`, "msg": `say "hi"`, "env": "prod"}, labels)
+
+	labels, err = ParseLabels("{}")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	for _, invalid := range []string{`job="nginx"`, `{job=nginx}`, `{job="nginx}`, `{job="a" env="b"}`, `{1job="a"}`, `{="a"}`} {
+		_, err := ParseLabels(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestToLogEntry(t *testing.T) {
+	pushed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	labels := map[string]string{"job": "nginx", "status": "label"}
+	entry := Entry{Timestamp: pushed, Line: "line", Metadata: map[string]string{"trace_id": "abc"}}
+
+	parse := func(line, logType string) (*models.LogEntry, error) {
+		if logType != "nginx" {
+			return nil, errors.New("unparsable")
+		}
+		return &models.LogEntry{LogType: "nginx", Path: "/", RawLog: line, Metadata: models.LogMetadata{"status": "parsed"}}, nil
+	}
+
+	got := ToLogEntry(labels, entry, "nginx", parse)
+	assert.Equal(t, "nginx", got.LogType)
+	assert.Equal(t, pushed, got.Timestamp, "the push time is used when the line has none")
+	assert.Equal(t, "parsed", got.Metadata["status"], "labels do not replace parsed fields")
+	assert.Equal(t, "nginx", got.Metadata["job"])
+	assert.Equal(t, "abc", got.Metadata["trace_id"])
+
+	got = ToLogEntry(labels, entry, "apache", parse)
+	assert.Equal(t, LogType, got.LogType, "lines that do not parse are kept as messages")
+	assert.Equal(t, "line", got.Path)
+	assert.Equal(t, "label", got.Metadata["status"])
+
+	got = ToLogEntry(nil, entry, "", parse)
+	assert.Equal(t, LogType, got.LogType)
+	assert.Equal(t, "line", got.RawLog)
+}
@@ -0,0 +1,142 @@
+package loki
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeProto decodes a logproto.PushRequest:
+//
+//	PushRequest    { repeated StreamAdapter streams = 1; }
+//	StreamAdapter  { string labels = 1; repeated EntryAdapter entries = 2; uint64 hash = 3; }
+//	EntryAdapter   { Timestamp timestamp = 1; string line = 2; repeated LabelPairAdapter structuredMetadata = 3; }
+//	LabelPairAdapter { string name = 1; string value = 2; }
+func decodeProto(body []byte) ([]Stream, error) {
+	var streams []Stream
+	err := eachField(body, func(number protowire.Number, value field) error {
+		if number != 1 || value.typ != protowire.BytesType {
+			return nil
+		}
+		stream, err := decodeProtoStream(value.bytes)
+		streams = append(streams, stream)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+func decodeProtoStream(data []byte) (Stream, error) {
+	var stream Stream
+	err := eachField(data, func(number protowire.Number, value field) error {
+		if value.typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		switch number {
+		case 1:
+			stream.Labels, err = ParseLabels(string(value.bytes))
+		case 2:
+			var entry Entry
+			entry, err = decodeProtoEntry(value.bytes)
+			stream.Entries = append(stream.Entries, entry)
+		}
+		return err
+	})
+	return stream, err
+}
+
+func decodeProtoEntry(data []byte) (Entry, error) {
+	var entry Entry
+	err := eachField(data, func(number protowire.Number, value field) error {
+		if value.typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		switch number {
+		case 1:
+			entry.Timestamp, err = decodeProtoTimestamp(value.bytes)
+		case 2:
+			entry.Line = string(value.bytes)
+		case 3:
+			var name, label string
+			name, label, err = decodeProtoLabelPair(value.bytes)
+			if entry.Metadata == nil {
+				entry.Metadata = make(map[string]string)
+			}
+			entry.Metadata[name] = label
+		}
+		return err
+	})
+	return entry, err
+}
+
+// decodeProtoTimestamp decodes a google.protobuf.Timestamp
+// { int64 seconds = 1; int32 nanos = 2; }
+func decodeProtoTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos int64
+	err := eachField(data, func(number protowire.Number, value field) error {
+		switch {
+		case number == 1 && value.typ == protowire.VarintType:
+			seconds = int64(value.varint)
+		case number == 2 && value.typ == protowire.VarintType:
+			nanos = int64(int32(value.varint))
+		}
+		return nil
+	})
+	return time.Unix(seconds, nanos).UTC(), err
+}
+
+func decodeProtoLabelPair(data []byte) (name, value string, err error) {
+	err = eachField(data, func(number protowire.Number, f field) error {
+		switch {
+		case number == 1 && f.typ == protowire.BytesType:
+			name = string(f.bytes)
+		case number == 2 && f.typ == protowire.BytesType:
+			value = string(f.bytes)
+		}
+		return nil
+	})
+	return name, value, err
+}
+
+// field is the value of a message field: bytes for length-delimited
+// fields and varint for varint ones
+type field struct {
+	typ    protowire.Type
+	bytes  []byte
+	varint uint64
+}
+
+// eachField calls fn with each field of a message, in the order they
+// were encoded
+func eachField(data []byte, fn func(number protowire.Number, value field) error) error {
+	for len(data) > 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		value := field{typ: typ}
+		switch typ {
+		case protowire.BytesType:
+			value.bytes, n = protowire.ConsumeBytes(data)
+		case protowire.VarintType:
+			value.varint, n = protowire.ConsumeVarint(data)
+		default:
+			n = protowire.ConsumeFieldValue(number, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonRequest is the OTLP/JSON encoding of an ExportLogsServiceRequest.
+// Fields are in lowerCamelCase, 64-bit integers may be strings and trace
+// and span IDs are hex.
+type jsonRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []jsonKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"scope"`
+			LogRecords []jsonLogRecord `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+type jsonLogRecord struct {
+	TimeUnixNano         jsonInt        `json:"timeUnixNano"`
+	ObservedTimeUnixNano jsonInt        `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 *jsonAnyValue  `json:"body"`
+	Attributes           []jsonKeyValue `json:"attributes"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+}
+
+type jsonKeyValue struct {
+	Key   string        `json:"key"`
+	Value *jsonAnyValue `json:"value"`
+}
+
+type jsonAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	IntValue    *jsonInt `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []*jsonAnyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []jsonKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+	BytesValue *string `json:"bytesValue"`
+}
+
+// jsonInt is a 64-bit integer encoded as a number or a string
+type jsonInt int64
+
+func (i *jsonInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = jsonInt(v)
+	return nil
+}
+
+func decodeJSON(body []byte) ([]Record, error) {
+	var request jsonRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	var records []Record
+	for _, rl := range request.ResourceLogs {
+		resource := jsonAttributes(rl.Resource.Attributes)
+		for _, sl := range rl.ScopeLogs {
+			scope := Scope{Name: sl.Scope.Name, Version: sl.Scope.Version}
+			for _, lr := range sl.LogRecords {
+				record := Record{
+					Resource:       resource,
+					Scope:          scope,
+					Timestamp:      unixNano(uint64(lr.TimeUnixNano)),
+					SeverityNumber: lr.SeverityNumber,
+					SeverityText:   lr.SeverityText,
+					Body:           lr.Body.value(),
+					TraceID:        strings.ToLower(lr.TraceID),
+					SpanID:         strings.ToLower(lr.SpanID),
+				}
+				if record.Timestamp.IsZero() {
+					record.Timestamp = unixNano(uint64(lr.ObservedTimeUnixNano))
+				}
+				if len(lr.Attributes) > 0 {
+					record.Attributes = jsonAttributes(lr.Attributes)
+				}
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+func jsonAttributes(kvs []jsonKeyValue) map[string]any {
+	attributes := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		attributes[kv.Key] = kv.Value.value()
+	}
+	return attributes
+}
+
+func (v *jsonAnyValue) value() any {
+	switch {
+	case v == nil:
+		return nil
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.ArrayValue != nil:
+		values := make([]any, 0, len(v.ArrayValue.Values))
+		for _, item := range v.ArrayValue.Values {
+			values = append(values, item.value())
+		}
+		return values
+	case v.KvlistValue != nil:
+		return jsonAttributes(v.KvlistValue.Values)
+	case v.BytesValue != nil:
+		// Already base64, as bytes are returned from protobuf too
+		return *v.BytesValue
+	}
+	return nil
+}
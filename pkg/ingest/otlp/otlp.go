@@ -0,0 +1,189 @@
+// Package otlp decodes OTLP/HTTP log export requests, so services
+// instrumented with OpenTelemetry SDKs, and OpenTelemetry Collectors, can
+// export their logs directly. Both protobuf and JSON bodies are accepted.
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// LogType is the log type of records stored without a parser. Their
+// entries carry the record's body as a free-text message.
+const LogType = "otlp"
+
+// Content types of OTLP/HTTP requests
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Scope is the instrumentation scope that emitted a record
+type Scope struct {
+	Name    string
+	Version string
+}
+
+// Record is an exported log record with the resource and scope it was
+// exported under. Attribute and body values are strings, bools, int64s,
+// float64s, []any or map[string]any; bytes are base64 strings.
+type Record struct {
+	Resource       map[string]any
+	Scope          Scope
+	Timestamp      time.Time // when the event occurred or, failing that, was observed
+	SeverityNumber int
+	SeverityText   string
+	Body           any
+	Attributes     map[string]any
+	TraceID        string // hex
+	SpanID         string // hex
+}
+
+// Decode reads an export request body. Bodies may be gzipped.
+func Decode(body []byte, contentType, contentEncoding string) ([]Record, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && contentType != "" {
+		return nil, fmt.Errorf("invalid content type %q", contentType)
+	}
+
+	switch strings.ToLower(contentEncoding) {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+
+	switch mediaType {
+	case ContentTypeJSON:
+		return decodeJSON(body)
+	case "", ContentTypeProtobuf:
+		return decodeProto(body)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// Line returns the record's body as a line of text. Bodies that are not
+// strings are encoded as JSON.
+func (r Record) Line() string {
+	switch body := r.Body.(type) {
+	case nil:
+		return ""
+	case string:
+		return body
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Sprint(body)
+		}
+		return string(encoded)
+	}
+}
+
+// Level returns the record's severity as a lower case level name, such as
+// "info" or "error"
+func (r Record) Level() string {
+	if r.SeverityText != "" {
+		return strings.ToLower(r.SeverityText)
+	}
+	// Severity numbers come in ranges of four, from TRACE to FATAL
+	levels := []string{"trace", "debug", "info", "warn", "error", "fatal"}
+	if r.SeverityNumber >= 1 && r.SeverityNumber <= 24 {
+		return levels[(r.SeverityNumber-1)/4]
+	}
+	return ""
+}
+
+// Attribute returns a string attribute of the record or, failing that, of
+// its resource
+func (r Record) Attribute(name string) string {
+	if value, ok := r.Attributes[name].(string); ok {
+		return value
+	}
+	value, _ := r.Resource[name].(string)
+	return value
+}
+
+// ParseFunc parses a line of a log type, such as Processor.ParseLine
+type ParseFunc func(line, logType string) (*models.LogEntry, error)
+
+// ToLogEntry converts a record. With a log type the body is parsed;
+// records without one, or whose body does not parse, are stored as
+// LogType messages. Record attributes, the fields of a map body, resource
+// attributes, the level, trace and span IDs and the scope are added to the
+// metadata in that order of precedence, without replacing fields the
+// parser extracted. The record's timestamp is used unless the parser
+// found one.
+func ToLogEntry(record Record, logType string, parse ParseFunc) *models.LogEntry {
+	line := record.Line()
+
+	var parsed *models.LogEntry
+	if logType != "" && parse != nil {
+		if e, err := parse(line, logType); err == nil && e != nil {
+			parsed = e
+		}
+	}
+	now := time.Now()
+	if parsed == nil {
+		parsed = &models.LogEntry{
+			LogType:   LogType,
+			Path:      line,
+			RawLog:    line,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	if parsed.Timestamp.IsZero() {
+		parsed.Timestamp = record.Timestamp
+		if parsed.Timestamp.IsZero() {
+			parsed.Timestamp = now
+		}
+	}
+
+	if parsed.Metadata == nil {
+		parsed.Metadata = make(models.LogMetadata, len(record.Attributes)+len(record.Resource)+4)
+	}
+	add := func(key string, value any) {
+		if _, exists := parsed.Metadata[key]; !exists {
+			parsed.Metadata[key] = value
+		}
+	}
+	for key, value := range record.Attributes {
+		add(key, value)
+	}
+	if fields, ok := record.Body.(map[string]any); ok {
+		for key, value := range fields {
+			add(key, value)
+		}
+	}
+	for key, value := range record.Resource {
+		add(key, value)
+	}
+	for key, value := range map[string]string{
+		"level":    record.Level(),
+		"trace_id": record.TraceID,
+		"span_id":  record.SpanID,
+		"scope":    record.Scope.Name,
+	} {
+		if value != "" {
+			add(key, value)
+		}
+	}
+	return parsed
+}
@@ -0,0 +1,207 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func keyValue(key string, value *commonpb.AnyValue) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: value}
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	occurred := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	observed := occurred.Add(time.Second)
+
+	kvlist := &commonpb.KeyValueList{Values: []*commonpb.KeyValue{
+		keyValue("user", stringValue("alice")),
+		keyValue("ok", &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}),
+	}}
+	array := &commonpb.ArrayValue{Values: []*commonpb.AnyValue{
+		{Value: &commonpb.AnyValue_IntValue{IntValue: 7}},
+		{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: 0.5}},
+	}}
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(occurred.UnixNano()),
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: kvlist}},
+		Attributes: []*commonpb.KeyValue{
+			keyValue("http.status_code", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 500}}),
+			keyValue("tags", &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: array}}),
+			keyValue("raw", &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte{0xde, 0xad}}}),
+		},
+		TraceId: []byte{0x01, 0x02},
+		SpanId:  []byte{0xab},
+		Flags:   1,
+	}
+	second := &logspb.LogRecord{
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityText:         "WARN",
+		Body:                 stringValue("disk almost full"),
+	}
+	request, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{keyValue("service.name", stringValue("shop"))}},
+		ScopeLogs: []*logspb.ScopeLogs{{
+			Scope:      &commonpb.InstrumentationScope{Name: "checkout", Version: "1.2.0"},
+			LogRecords: []*logspb.LogRecord{record, second},
+		}},
+	}}})
+	require.NoError(t, err)
+
+	records, err := Decode(request, ContentTypeProtobuf, "")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	got := records[0]
+	assert.Equal(t, map[string]any{"service.name": "shop"}, got.Resource)
+	assert.Equal(t, Scope{Name: "checkout", Version: "1.2.0"}, got.Scope)
+	assert.Equal(t, occurred, got.Timestamp)
+	assert.Equal(t, "error", got.Level())
+	assert.Equal(t, map[string]any{"user": "alice", "ok": true}, got.Body)
+	assert.Equal(t, map[string]any{
+		"http.status_code": int64(500),
+		"tags":             []any{int64(7), 0.5},
+		"raw":              "3q0=",
+	}, got.Attributes)
+	assert.Equal(t, "0102", got.TraceID)
+	assert.Equal(t, "ab", got.SpanID)
+
+	assert.Equal(t, observed, records[1].Timestamp, "the observed time stands in for a missing event time")
+	assert.Equal(t, "warn", records[1].Level())
+	assert.Equal(t, "disk almost full", records[1].Line())
+	assert.Equal(t, "shop", records[1].Attribute("service.name"))
+
+	_, err = Decode(request[:len(request)-3], ContentTypeProtobuf, "")
+	assert.Error(t, err)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	body := `{"resourceLogs": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "shop"}}]},
+		"scopeLogs": [{
+			"scope": {"name": "checkout"},
+			"logRecords": [
+				{
+					"timeUnixNano": "1709294400000000500",
+					"severityNumber": 9,
+					"body": {"stringValue": "order placed"},
+					"attributes": [
+						{"key": "order.id", "value": {"intValue": "42"}},
+						{"key": "amount", "value": {"doubleValue": 9.5}},
+						{"key": "items", "value": {"arrayValue": {"values": [{"stringValue": "book"}]}}},
+						{"key": "customer", "value": {"kvlistValue": {"values": [{"key": "tier", "value": {"stringValue": "gold"}}]}}}
+					],
+					"traceId": "5B8EFFF798038103D269B633813FC60C",
+					"spanId": "EEE19B7EC3C1B174"
+				},
+				{"observedTimeUnixNano": 1709294401000000000, "body": {"boolValue": true}}
+			]
+		}]
+	}]}`
+
+	records, err := Decode([]byte(body), "application/json; charset=utf-8", "")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	got := records[0]
+	assert.Equal(t, time.Unix(1709294400, 500).UTC(), got.Timestamp)
+	assert.Equal(t, "info", got.Level())
+	assert.Equal(t, "order placed", got.Line())
+	assert.Equal(t, "shop", got.Resource["service.name"])
+	assert.Equal(t, "checkout", got.Scope.Name)
+	assert.Equal(t, map[string]any{
+		"order.id": int64(42),
+		"amount":   9.5,
+		"items":    []any{"book"},
+		"customer": map[string]any{"tier": "gold"},
+	}, got.Attributes)
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", got.TraceID)
+	assert.Equal(t, "eee19b7ec3c1b174", got.SpanID)
+
+	assert.Equal(t, time.Unix(1709294401, 0).UTC(), records[1].Timestamp)
+	assert.Equal(t, "true", records[1].Line())
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(body))
+	zw.Close()
+	records, err = Decode(gz.Bytes(), ContentTypeJSON, "gzip")
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	for _, invalid := range []string{
+		`{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"timeUnixNano": "soon"}]}]}]}`,
+		`{"resourceLogs": [`,
+	} {
+		_, err := Decode([]byte(invalid), ContentTypeJSON, "")
+		assert.Error(t, err, invalid)
+	}
+
+	_, err = Decode([]byte(body), "text/plain", "")
+	assert.Error(t, err)
+	_, err = Decode([]byte(body), ContentTypeJSON, "snappy")
+	assert.Error(t, err)
+}
+
+func TestToLogEntry(t *testing.T) {
+	occurred := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := Record{
+		Resource:     map[string]any{"service.name": "shop", "status": "resource"},
+		Scope:        Scope{Name: "checkout"},
+		Timestamp:    occurred,
+		SeverityText: "ERROR",
+		Body:         "line",
+		Attributes:   map[string]any{"status": "attribute"},
+		TraceID:      "0102",
+	}
+
+	parse := func(line, logType string) (*models.LogEntry, error) {
+		if logType != "nginx" {
+			return nil, errors.New("unparsable")
+		}
+		return &models.LogEntry{LogType: "nginx", Path: "/", RawLog: line, Metadata: models.LogMetadata{"status": "parsed"}}, nil
+	}
+
+	got := ToLogEntry(record, "nginx", parse)
+	assert.Equal(t, "nginx", got.LogType)
+	assert.Equal(t, occurred, got.Timestamp, "the record time is used when the line has none")
+	assert.Equal(t, "parsed", got.Metadata["status"], "attributes do not replace parsed fields")
+	assert.Equal(t, "shop", got.Metadata["service.name"])
+	assert.Equal(t, "error", got.Metadata["level"])
+	assert.Equal(t, "0102", got.Metadata["trace_id"])
+	assert.Equal(t, "checkout", got.Metadata["scope"])
+	assert.NotContains(t, got.Metadata, "span_id")
+
+	got = ToLogEntry(record, "apache", parse)
+	assert.Equal(t, LogType, got.LogType, "bodies that do not parse are kept as messages")
+	assert.Equal(t, "line", got.Path)
+	assert.Equal(t, "attribute", got.Metadata["status"], "record attributes win over resource attributes")
+
+	record.Body = map[string]any{"msg": "structured", "status": "body"}
+	record.Attributes = nil
+	got = ToLogEntry(record, "", parse)
+	assert.Equal(t, LogType, got.LogType)
+	assert.JSONEq(t, `{"msg": "structured", "status": "body"}`, got.RawLog)
+	assert.Equal(t, "structured", got.Metadata["msg"])
+	assert.Equal(t, "body", got.Metadata["status"], "body fields win over resource attributes")
+
+	got = ToLogEntry(Record{Body: "no time"}, "", nil)
+	assert.WithinDuration(t, time.Now(), got.Timestamp, time.Minute)
+}
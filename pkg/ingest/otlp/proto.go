@@ -0,0 +1,93 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// decodeProto decodes an ExportLogsServiceRequest with the generated OTLP
+// types
+func decodeProto(body []byte) ([]Record, error) {
+	var request collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid protobuf body: %w", err)
+	}
+
+	var records []Record
+	for _, rl := range request.GetResourceLogs() {
+		var resource map[string]any
+		if rl.GetResource() != nil {
+			resource = protoAttributes(rl.GetResource().GetAttributes())
+		}
+		for _, sl := range rl.GetScopeLogs() {
+			scope := Scope{Name: sl.GetScope().GetName(), Version: sl.GetScope().GetVersion()}
+			for _, lr := range sl.GetLogRecords() {
+				record := Record{
+					Resource:       resource,
+					Scope:          scope,
+					Timestamp:      unixNano(lr.GetTimeUnixNano()),
+					SeverityNumber: int(lr.GetSeverityNumber()),
+					SeverityText:   lr.GetSeverityText(),
+					Body:           protoValue(lr.GetBody()),
+					TraceID:        hex.EncodeToString(lr.GetTraceId()),
+					SpanID:         hex.EncodeToString(lr.GetSpanId()),
+				}
+				if record.Timestamp.IsZero() {
+					record.Timestamp = unixNano(lr.GetObservedTimeUnixNano())
+				}
+				if len(lr.GetAttributes()) > 0 {
+					record.Attributes = protoAttributes(lr.GetAttributes())
+				}
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+func protoAttributes(kvs []*commonpb.KeyValue) map[string]any {
+	attributes := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		attributes[kv.GetKey()] = protoValue(kv.GetValue())
+	}
+	return attributes
+}
+
+func protoValue(v *commonpb.AnyValue) any {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return value.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return value.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return value.DoubleValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(value.ArrayValue.GetValues()))
+		for _, item := range value.ArrayValue.GetValues() {
+			values = append(values, protoValue(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return protoAttributes(value.KvlistValue.GetValues())
+	case *commonpb.AnyValue_BytesValue:
+		// As in OTLP/JSON
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	}
+	return nil
+}
+
+// unixNano converts OTLP nanoseconds since the epoch; zero is unset
+func unixNano(nanos uint64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(nanos)).UTC()
+}
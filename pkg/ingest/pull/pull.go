@@ -0,0 +1,400 @@
+// Package pull downloads log files from HTTP(S) and SFTP URLs. Recurring
+// pulls resume where the last one stopped, so a growing file is read once.
+package pull
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/s3"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/sftp"
+)
+
+// maxLine is the longest partial line held back for the next pull, the
+// longest line the processor accepts
+const maxLine = 1 << 20
+
+// Source is a remote log file and the credentials to read it with
+type Source struct {
+	// URL is an http, https or sftp URL. Credentials in it are used
+	// unless Username or Password are set.
+	URL      string
+	Username string
+	Password string
+	// Token is sent as a bearer token to HTTP(S) servers
+	Token string
+	// PrivateKey logs in to SFTP servers, see sftp.Config
+	PrivateKey []byte
+	// HostKeys and KnownHostsFile are the SFTP server keys to accept
+	HostKeys       []string
+	KnownHostsFile string
+	// Timeout bounds connecting and waiting for each response
+	Timeout time.Duration
+}
+
+// Validate checks that the URL can be pulled from
+func (s Source) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Host == "" {
+		return errors.New("url needs a host")
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return nil
+	case "sftp":
+		if strings.Trim(u.Path, "/~") == "" {
+			return errors.New("sftp url needs a file path")
+		}
+		if s.username(u) == "" {
+			return errors.New("sftp needs a username")
+		}
+		if s.password(u) == "" && len(s.PrivateKey) == 0 {
+			return errors.New("sftp needs a password or private key")
+		}
+		if len(s.HostKeys) == 0 && s.KnownHostsFile == "" {
+			return errors.New("sftp needs the server's host key or a known hosts file")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported url scheme %q; use http, https or sftp", u.Scheme)
+}
+
+func (s Source) username(u *url.URL) string {
+	if s.Username != "" {
+		return s.Username
+	}
+	return u.User.Username()
+}
+
+func (s Source) password(u *url.URL) string {
+	if s.Password != "" {
+		return s.Password
+	}
+	password, _ := u.User.Password()
+	return password
+}
+
+// Redact returns the URL as recorded with pulled entries: without
+// credentials or the query string, which may hold a signature
+func Redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// Position is how far a recurring pull has read its file
+type Position struct {
+	Offset       int64  `json:"offset"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Download is a remote file being read
+type Download struct {
+	// Offset is where the content read starts in the file
+	Offset int64
+	// Size is the file's length, -1 if the server did not say
+	Size int64
+	// Compressed is set for gzip files, which are read whole
+	Compressed bool
+
+	etag         string
+	lastModified string
+	raw          *countingReader
+	lines        *completeLines
+	body         io.Reader
+	closer       io.Closer
+}
+
+func (d *Download) Read(p []byte) (int, error) {
+	return d.body.Read(p)
+}
+
+func (d *Download) Close() error {
+	return d.closer.Close()
+}
+
+// Next is the position the next pull resumes from once this download was
+// read to its end
+func (d *Download) Next() Position {
+	next := Position{Offset: d.Offset + d.raw.n, ETag: d.etag, LastModified: d.lastModified}
+	if d.lines != nil {
+		next.Offset = d.Offset + d.lines.n
+	}
+	return next
+}
+
+// Open starts reading src. Without a position the whole file is read and
+// gzip files are decompressed. With one, reading resumes at its offset and
+// stops after the last complete line, leaving a line still being written
+// for the next pull; a file that was replaced or truncated since is read
+// from the start.
+func Open(ctx context.Context, src Source, from *Position) (*Download, error) {
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(src.URL)
+
+	var pos Position
+	if from != nil {
+		pos = *from
+	}
+	var d *Download
+	var err error
+	if u.Scheme == "sftp" {
+		d, err = openSFTP(ctx, src, u, pos)
+	} else {
+		d, err = openHTTP(ctx, src, pos)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a file read from its start can be recognized as gzip
+	if d.Offset == 0 {
+		decompressed, err := s3.Decompress(io.NopCloser(d.body))
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+		d.Compressed = d.raw.n >= 2 && isGzip(d.raw.peeked)
+		d.body = decompressed
+	}
+	if from != nil && !d.Compressed {
+		d.lines = &completeLines{r: d.body}
+		d.body = d.lines
+	}
+	return d, nil
+}
+
+func isGzip(magic []byte) bool {
+	return len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+func newDownload(body io.ReadCloser, offset, size int64) *Download {
+	raw := &countingReader{r: body}
+	return &Download{Offset: offset, Size: size, raw: raw, body: raw, closer: body}
+}
+
+// openHTTP requests the file from pos.Offset. If-Range makes a server send
+// the whole file when it no longer matches the validators of the last pull.
+func openHTTP(ctx context.Context, src Source, pos Position) (*Download, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if src.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+src.Token)
+	} else if src.Username != "" || src.Password != "" {
+		req.SetBasicAuth(src.Username, src.Password)
+	}
+	if pos.Offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", pos.Offset))
+		// Weak ETags cannot validate a range
+		if pos.ETag != "" && !strings.HasPrefix(pos.ETag, "W/") {
+			req.Header.Set("If-Range", pos.ETag)
+		} else if pos.LastModified != "" {
+			req.Header.Set("If-Range", pos.LastModified)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = src.Timeout
+	transport.DisableKeepAlives = true
+	client := &http.Client{Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if pos.Offset > 0 && resp.Header.Get("Accept-Ranges") != "bytes" && resp.ContentLength >= pos.Offset {
+			// The server ignores ranges rather than reporting a changed
+			// file, so the part read before is skipped
+			if _, err := io.CopyN(io.Discard, resp.Body, pos.Offset); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			d := newDownload(resp.Body, pos.Offset, resp.ContentLength)
+			d.etag, d.lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+			return d, nil
+		}
+		d := newDownload(resp.Body, 0, resp.ContentLength)
+		d.etag, d.lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		return d, nil
+	case http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != pos.Offset {
+			resp.Body.Close()
+			return nil, fmt.Errorf("GET %s: unexpected Content-Range %q", Redact(src.URL), resp.Header.Get("Content-Range"))
+		}
+		d := newDownload(resp.Body, start, size)
+		d.etag, d.lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		return d, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		_, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err == nil && size >= pos.Offset {
+			// Nothing was written since the last pull
+			d := newDownload(io.NopCloser(bytes.NewReader(nil)), pos.Offset, size)
+			d.etag, d.lastModified = pos.ETag, pos.LastModified
+			return d, nil
+		}
+		// The file is shorter than what was read: it was truncated
+		return openHTTP(ctx, src, Position{})
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	return nil, fmt.Errorf("GET %s: %s: %s", Redact(src.URL), resp.Status, strings.TrimSpace(string(snippet)))
+}
+
+// parseContentRange reads "bytes start-end/size" or "bytes */size"; size
+// is -1 when the server gives it as *
+func parseContentRange(value string) (start, size int64, err error) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	rangePart, sizePart, found := strings.Cut(spec, "/")
+	if !ok || !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	size = -1
+	if sizePart != "*" {
+		if size, err = strconv.ParseInt(sizePart, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+		}
+	}
+	if rangePart == "*" {
+		return 0, size, nil
+	}
+	first, _, _ := strings.Cut(rangePart, "-")
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	return start, size, nil
+}
+
+// openSFTP opens the file at the URL's path, which is relative to the
+// login directory when it starts with /~/
+func openSFTP(ctx context.Context, src Source, u *url.URL, pos Position) (*Download, error) {
+	client, err := sftp.Dial(ctx, u.Host, sftp.Config{
+		User:           src.username(u),
+		Password:       src.password(u),
+		PrivateKey:     src.PrivateKey,
+		HostKeys:       src.HostKeys,
+		KnownHostsFile: src.KnownHostsFile,
+		Timeout:        src.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	path := u.Path
+	if strings.HasPrefix(path, "/~/") {
+		path = path[len("/~/"):]
+	}
+	f, err := client.Open(path)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	offset := pos.Offset
+	if f.Size() >= 0 && f.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		client.Close()
+		return nil, err
+	}
+	d := newDownload(&sftpFile{File: f, client: client}, offset, f.Size())
+	if !f.ModTime().IsZero() {
+		d.lastModified = f.ModTime().UTC().Format(http.TimeFormat)
+	}
+	return d, nil
+}
+
+// sftpFile ends the session when the file is closed
+type sftpFile struct {
+	*sftp.File
+	client *sftp.Client
+}
+
+func (f *sftpFile) Close() error {
+	f.File.Close()
+	return f.client.Close()
+}
+
+// countingReader counts the bytes read from the server and keeps the
+// first two to recognize gzip by
+type countingReader struct {
+	r      io.Reader
+	n      int64
+	peeked []byte
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if len(c.peeked) < 2 {
+		c.peeked = append(c.peeked, p[:min(n, 2-len(c.peeked))]...)
+	}
+	c.n += int64(n)
+	return n, err
+}
+
+// completeLines passes on data up to the last newline read, so a line
+// still being written at the end is left for the next pull
+type completeLines struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+	n     int64
+	err   error
+	// long is set while passing on a line too long to hold
+	long bool
+}
+
+func (c *completeLines) Read(p []byte) (int, error) {
+	for {
+		end := bytes.LastIndexByte(c.buf, '\n') + 1
+		if end > 0 {
+			c.long = false
+		} else if c.long || len(c.buf) > maxLine {
+			// A line too long to be parsed is passed on rather than held
+			c.long = true
+			end = len(c.buf)
+		}
+		if end > 0 {
+			n := copy(p, c.buf[:end])
+			c.buf = c.buf[n:]
+			c.n += int64(n)
+			return n, nil
+		}
+		if c.err != nil {
+			return 0, c.err
+		}
+
+		if c.chunk == nil {
+			c.chunk = make([]byte, 64<<10)
+		}
+		n, err := c.r.Read(c.chunk)
+		c.buf = append(c.buf, c.chunk[:n]...)
+		c.err = err
+	}
+}
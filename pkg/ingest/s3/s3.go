@@ -0,0 +1,264 @@
+// Package s3 lists and downloads objects from Amazon S3 and S3-compatible
+// stores such as MinIO, for load balancer and CDN logs that are only
+// delivered to buckets. It also uploads objects, for reports kept in a
+// bucket. Requests go through the AWS SDK.
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Client reads objects from one region
+type Client struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+}
+
+// NewClient creates a client for a region. Requests are signed with
+// credentials, or with those the SDK finds when no access key is given,
+// and are sent unsigned when there are none, which reads public buckets.
+// endpoint replaces https://s3.<region>.amazonaws.com for S3-compatible
+// stores, which are then addressed by path rather than hostname.
+func NewClient(ctx context.Context, region string, credentials awsauth.Credentials, endpoint string) (*Client, error) {
+	cfg, err := awsauth.Config(ctx, region, credentials)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.AccessKeyID == "" {
+		if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+			cfg.Credentials = aws.AnonymousCredentials{}
+		}
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.HTTPClient = awshttp.NewBuildableClient().WithTimeout(5 * time.Minute)
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(strings.TrimSuffix(endpoint, "/"))
+			o.UsePathStyle = true
+		}
+	})
+	return &Client{client: client, presign: s3.NewPresignClient(client)}, nil
+}
+
+// Object is a listed object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// notFound is the error for a missing object or bucket, which matches
+// os.ErrNotExist
+type notFound struct {
+	error
+}
+
+func (e notFound) Is(target error) bool {
+	return target == os.ErrNotExist
+}
+
+func (e notFound) Unwrap() error {
+	return e.error
+}
+
+// wrapError marks errors for missing objects and buckets
+func wrapError(err error) error {
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound {
+		return notFound{err}
+	}
+	return err
+}
+
+// ListObjects calls fn for each object under prefix in key order, paging
+// through ListObjectsV2. Folder placeholder keys ending in "/" are
+// skipped. An error from fn stops the listing and is returned.
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix string, fn func(Object) error) error {
+	pages := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return wrapError(err)
+		}
+		for _, content := range page.Contents {
+			key := aws.ToString(content.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			if err := fn(Object{Key: key, Size: aws.ToInt64(content.Size), LastModified: aws.ToTime(content.LastModified)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetObject opens an object for reading. Gzip-compressed objects, such as
+// ALB and CloudFront logs, are decompressed transparently.
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	body, _, err := c.OpenObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := Decompress(body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("s3: %s: %w", key, err)
+	}
+	return decompressed, nil
+}
+
+// OpenObject opens an object for reading as stored, with its size and
+// modification time
+func (c *Client) OpenObject(ctx context.Context, bucket, key string) (io.ReadCloser, Object, error) {
+	output, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, Object{}, wrapError(err)
+	}
+	return output.Body, Object{Key: key, Size: aws.ToInt64(output.ContentLength), LastModified: aws.ToTime(output.LastModified)}, nil
+}
+
+// HeadObject returns an object's size and modification time
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (Object, error) {
+	output, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return Object{}, wrapError(err)
+	}
+	return Object{Key: key, Size: aws.ToInt64(output.ContentLength), LastModified: aws.ToTime(output.LastModified)}, nil
+}
+
+// PutObject uploads an object, replacing any with the same key
+func (c *Client) PutObject(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	return c.PutObjectReader(ctx, bucket, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// PutObjectReader uploads an object of size bytes read from body. body may
+// be read more than once, to sign it and to send it again on a retry.
+func (c *Client) PutObjectReader(ctx context.Context, bucket, key string, body io.ReadSeeker, size int64, contentType string) error {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		Body:          &sectionReader{r: body, size: size},
+		ContentLength: aws.Int64(size),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	_, err := c.client.PutObject(ctx, input)
+	return wrapError(err)
+}
+
+// sectionReader reads the first size bytes of r, and seeks within them so
+// the SDK can hash the body and rewind it
+type sectionReader struct {
+	r      io.ReadSeeker
+	size   int64
+	offset int64
+}
+
+func (s *sectionReader) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.size-s.offset {
+		p = p[:s.size-s.offset]
+	}
+	n, err := s.r.Read(p)
+	s.offset += int64(n)
+	return n, err
+}
+
+func (s *sectionReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	}
+	if offset < 0 || offset > s.size {
+		return 0, errors.New("s3: seek outside the body")
+	}
+	if _, err := s.r.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	s.offset = offset
+	return offset, nil
+}
+
+// ListDirectory returns the objects directly under prefix and the prefixes
+// of the "directories" below it, treating "/" as the separator
+func (c *Client) ListDirectory(ctx context.Context, bucket, prefix string) ([]Object, []string, error) {
+	var objects []Object
+	var prefixes []string
+	pages := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, nil, wrapError(err)
+		}
+		for _, content := range page.Contents {
+			if key := aws.ToString(content.Key); !strings.HasSuffix(key, "/") {
+				objects = append(objects, Object{Key: key, Size: aws.ToInt64(content.Size), LastModified: aws.ToTime(content.LastModified)})
+			}
+		}
+		for _, common := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(common.Prefix))
+		}
+	}
+	return objects, prefixes, nil
+}
+
+// PresignGetObject returns a URL that downloads an object without
+// credentials until expires has passed
+func (c *Client) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	request, err := c.presign.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)},
+		s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Decompress detects gzip by its magic bytes, since objects are often
+// stored without a Content-Encoding. Closing the result closes r.
+func Decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{buffered, r}, nil
+	}
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{gz, r}, nil
+}
@@ -0,0 +1,160 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient creates a client of the store at endpoint, signing with an
+// access key when given one
+func newClient(t *testing.T, accessKeyID, endpoint string) *Client {
+	// Without an access key no other credentials are found
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	c, err := NewClient(context.Background(), "us-east-1", awsauth.Credentials{AccessKeyID: accessKeyID, SecretAccessKey: "secret"}, endpoint)
+	require.NoError(t, err)
+	return c
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestListAndGetObjects(t *testing.T) {
+	objects := map[string][]byte{
+		"alb/2023/10/09/a.log.gz": gzipped(t, "line one\nline two\n"),
+		"alb/2023/10/09/b.log":    []byte("plain line\n"),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		if r.URL.Path == "/logs" && r.URL.Query().Get("list-type") == "2" {
+			assert.Equal(t, "alb/", r.URL.Query().Get("prefix"))
+			// Two pages, the first with a folder placeholder
+			if r.URL.Query().Get("continuation-token") == "" {
+				fmt.Fprint(w, `<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>page2</NextContinuationToken>
+<Contents><Key>alb/</Key><Size>0</Size></Contents>
+<Contents><Key>alb/2023/10/09/a.log.gz</Key><Size>40</Size><LastModified>2023-10-09T00:05:00.000Z</LastModified></Contents>
+</ListBucketResult>`)
+				return
+			}
+			assert.Equal(t, "page2", r.URL.Query().Get("continuation-token"))
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>
+<Contents><Key>alb/2023/10/09/b.log</Key><Size>11</Size></Contents>
+</ListBucketResult>`)
+			return
+		}
+		body, ok := objects[strings.TrimPrefix(r.URL.Path, "/logs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	c := newClient(t, "AKID", server.URL)
+	ctx := context.Background()
+
+	var listed []Object
+	require.NoError(t, c.ListObjects(ctx, "logs", "alb/", func(o Object) error {
+		listed = append(listed, o)
+		return nil
+	}))
+	require.Len(t, listed, 2)
+	assert.Equal(t, "alb/2023/10/09/a.log.gz", listed[0].Key)
+	assert.Equal(t, int64(40), listed[0].Size)
+	assert.Equal(t, time.Date(2023, 10, 9, 0, 5, 0, 0, time.UTC), listed[0].LastModified)
+	assert.Equal(t, "alb/2023/10/09/b.log", listed[1].Key)
+
+	for key, want := range map[string]string{"alb/2023/10/09/a.log.gz": "line one\nline two\n", "alb/2023/10/09/b.log": "plain line\n"} {
+		body, err := c.GetObject(ctx, "logs", key)
+		require.NoError(t, err)
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		body.Close()
+		assert.Equal(t, want, string(got), key)
+	}
+
+	_, err := c.GetObject(ctx, "logs", "missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	var apiErr smithy.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "NoSuchKey", apiErr.ErrorCode())
+
+	// Errors from the callback stop the listing
+	stop := errors.New("stop")
+	calls := 0
+	err = c.ListObjects(ctx, "logs", "alb/", func(Object) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+
+	// Without credentials requests are sent unsigned
+	err = newClient(t, "", server.URL).ListObjects(ctx, "logs", "alb/", func(Object) error { return nil })
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "AccessDenied", apiErr.ErrorCode())
+}
+
+func TestPutAndPresign(t *testing.T) {
+	var put []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/reports/a b.csv", r.URL.Path)
+		assert.Equal(t, "text/csv", r.Header.Get("Content-Type"))
+		put, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	c := newClient(t, "AKID", server.URL)
+
+	// Only size bytes of the body are sent
+	require.NoError(t, c.PutObjectReader(context.Background(), "reports", "a b.csv", strings.NewReader("a,b\n1,2\nextra"), 8, "text/csv"))
+	assert.Equal(t, "a,b\n1,2\n", string(put))
+
+	signed, err := c.PresignGetObject(context.Background(), "reports", "a b.csv", time.Hour)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/a b.csv", u.Path)
+	assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	assert.True(t, strings.HasPrefix(u.Query().Get("X-Amz-Credential"), "AKID/"))
+}
+
+func TestDecompressEmpty(t *testing.T) {
+	body, err := Decompress(io.NopCloser(strings.NewReader("")))
+	require.NoError(t, err)
+	got, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,186 @@
+// Package sftp reads files from SFTP servers, over golang.org/x/crypto/ssh
+// and github.com/pkg/sftp. It adds what pulling logs needs on top: host
+// keys given as fingerprints, keys or a known_hosts file, logging in with a
+// key or a password, and a timeout on every read.
+package sftp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Config holds how to reach and log in to a server
+type Config struct {
+	User string
+	// Password is used for password and keyboard-interactive login
+	Password string
+	// PrivateKey is an unencrypted PEM key in the OpenSSH format or as
+	// PKCS #1, PKCS #8 or SEC 1
+	PrivateKey []byte
+	// HostKeys are the accepted server keys, each a SHA256 fingerprint as
+	// ssh-keygen -l prints it or a key as known_hosts lists it
+	HostKeys []string
+	// KnownHostsFile is an OpenSSH known_hosts file to find keys in
+	KnownHostsFile string
+	// Timeout bounds connecting and every read after that
+	Timeout time.Duration
+}
+
+// Client is an SFTP session over one SSH connection
+type Client struct {
+	conn    net.Conn
+	ssh     *ssh.Client
+	sftp    *sftp.Client
+	timeout time.Duration
+	stop    func() bool
+}
+
+// Dial connects to address (host or host:port), checks the server's host
+// key and logs in. ctx bounds the whole session: cancelling it closes the
+// connection.
+func Dial(ctx context.Context, address string, cfg Config) (*Client, error) {
+	address = defaultPort(address)
+	hostKeys, err := newHostKeys(address, cfg.HostKeys, cfg.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	var auth []ssh.AuthMethod
+	if len(cfg.PrivateKey) > 0 {
+		key, err := ssh.ParsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(key))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password), ssh.KeyboardInteractive(answerWith(cfg.Password)))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("ssh: a password or private key is required")
+	}
+
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, timeout: cfg.Timeout}
+	c.stop = context.AfterFunc(ctx, func() { conn.Close() })
+
+	if err := c.connect(address, cfg.User, hostKeys, auth); err != nil {
+		c.stop()
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect(address, user string, hostKeys *hostKeys, auth []ssh.AuthMethod) error {
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	sshConn, channels, requests, err := ssh.NewClientConn(c.conn, address, &ssh.ClientConfig{
+		User:              user,
+		Auth:              auth,
+		HostKeyCallback:   hostKeys.check,
+		HostKeyAlgorithms: hostKeys.algorithms(),
+	})
+	if err != nil {
+		return err
+	}
+	c.ssh = ssh.NewClient(sshConn, channels, requests)
+	if c.sftp, err = sftp.NewClient(c.ssh); err != nil {
+		c.ssh.Close()
+		return err
+	}
+	// Reads are timed by File.Read from here on
+	c.conn.SetDeadline(time.Time{})
+	return nil
+}
+
+// answerWith answers every hidden keyboard-interactive prompt with the
+// password, as servers that only allow PAM logins ask for it this way
+func answerWith(password string) ssh.KeyboardInteractiveChallenge {
+	return func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i := range questions {
+			if !echos[i] {
+				answers[i] = password
+			}
+		}
+		return answers, nil
+	}
+}
+
+// Close ends the session
+func (c *Client) Close() error {
+	c.stop()
+	c.sftp.Close()
+	return c.ssh.Close()
+}
+
+// File is a file opened for reading
+type File struct {
+	*sftp.File
+	c       *Client
+	size    int64
+	modTime time.Time
+}
+
+// Open opens path for reading. Relative paths start at the login
+// directory.
+func (c *Client) Open(path string) (*File, error) {
+	f, err := c.sftp.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{File: f, c: c, size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// Size is the file's size when it was opened
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// ModTime is the file's modification time when it was opened
+func (f *File) ModTime() time.Time {
+	return f.modTime
+}
+
+// Read reads from the file, closing the connection if the server does not
+// answer within the timeout
+func (f *File) Read(p []byte) (int, error) {
+	if f.c.timeout <= 0 {
+		return f.File.Read(p)
+	}
+	timer := time.AfterFunc(f.c.timeout, func() { f.c.conn.Close() })
+	n, err := f.File.Read(p)
+	if !timer.Stop() && err != nil && err != io.EOF {
+		err = errors.New("sftp: read timed out")
+	}
+	return n, err
+}
+
+// defaultPort adds the SSH port to an address without one
+func defaultPort(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), strconv.Itoa(22))
+}
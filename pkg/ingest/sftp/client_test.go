@@ -0,0 +1,226 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newKey(t *testing.T) ssh.PublicKey {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	return key
+}
+
+// serve starts an SSH server on a loopback port whose sftp subsystem
+// serves the local file system, and returns its address and host key
+func serve(t *testing.T, password string) (string, ssh.PublicKey) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, given []byte) (*ssh.Permissions, error) {
+			if conn.User() == "logs" && string(given) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("wrong password")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+	return listener.Addr().String(), hostKey.PublicKey()
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for request := range requests {
+				ok := request.Type == "subsystem" && string(request.Payload[4:]) == "sftp"
+				request.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+						server.Close()
+					}
+				}
+			}
+		}()
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	var content bytes.Buffer
+	for i := 0; content.Len() < 1<<20; i++ {
+		fmt.Fprintf(&content, "10.0.0.%d - - [01/Jan/2024:00:00:00 +0000] \"GET /page/%d HTTP/1.1\" 200 512\n", i%250, i)
+	}
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, os.WriteFile(path, content.Bytes(), 0o600))
+	addr, hostKey := serve(t, "secret")
+	cfg := Config{
+		User:     "logs",
+		Password: "secret",
+		HostKeys: []string{ssh.FingerprintSHA256(hostKey)},
+		Timeout:  5 * time.Second,
+	}
+
+	c, err := Dial(context.Background(), addr, cfg)
+	require.NoError(t, err)
+	defer c.Close()
+
+	f, err := c.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(content.Len()), f.Size())
+	got, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(content.Bytes(), got), "file read in full")
+	require.NoError(t, f.Close())
+
+	f, err = c.Open(path)
+	require.NoError(t, err)
+	_, err = f.Seek(int64(content.Len()-100), io.SeekStart)
+	require.NoError(t, err)
+	got, err = io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, content.Bytes()[content.Len()-100:], got)
+
+	_, err = c.Open(path + ".1")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	cfg.Password = "wrong"
+	_, err = Dial(context.Background(), addr, cfg)
+	assert.ErrorContains(t, err, "unable to authenticate")
+
+	cfg.Password = "secret"
+	cfg.HostKeys = []string{strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newKey(t))))}
+	_, err = Dial(context.Background(), addr, cfg)
+	assert.Error(t, err, "other host keys are refused")
+}
+
+func TestHostKeys(t *testing.T) {
+	const address = "web-1:2222"
+	remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2222}
+	key, other := newKey(t), newKey(t)
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+
+	h, err := newHostKeys(address, []string{authorized}, "")
+	require.NoError(t, err)
+	assert.NoError(t, h.check(address, remote, key))
+	assert.ErrorContains(t, h.check(address, remote, other), "is not known")
+	assert.Equal(t, []string{ssh.KeyAlgoED25519}, h.algorithms(), "the known key's type is asked for")
+
+	h, err = newHostKeys(address, []string{ssh.FingerprintSHA256(key) + "="}, "")
+	require.NoError(t, err)
+	assert.NoError(t, h.check(address, remote, key), "fingerprints are matched with or without padding")
+	assert.Nil(t, h.algorithms())
+
+	_, err = newHostKeys(address, nil, "")
+	assert.ErrorContains(t, err, "no host key known", "servers are never trusted blindly")
+	_, err = newHostKeys(address, []string{"ssh-ed25519 not-base64"}, "")
+	assert.ErrorContains(t, err, "invalid host key")
+}
+
+func TestKnownHostsFile(t *testing.T) {
+	const address = "web-1:2222"
+	remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 2222}
+	key, other := newKey(t), newKey(t)
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	write := func(lines ...string) {
+		require.NoError(t, os.WriteFile(knownHosts, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	}
+
+	// known_hosts names servers on other ports [host]:port, here hashed
+	write("# servers", knownhosts.Line([]string{"other.example.com"}, other),
+		knownhosts.Line([]string{knownhosts.HashHostname(knownhosts.Normalize(address))}, key))
+	h, err := newHostKeys(address, nil, knownHosts)
+	require.NoError(t, err)
+	assert.NoError(t, h.check(address, remote, key))
+	assert.Error(t, h.check(address, remote, other))
+	assert.Equal(t, []string{ssh.KeyAlgoED25519}, h.algorithms())
+
+	write(knownhosts.Line([]string{"other.example.com"}, key))
+	_, err = newHostKeys(address, nil, knownHosts)
+	assert.ErrorContains(t, err, "no host key known")
+
+	// Revoked keys are refused even when configured
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	write("@revoked * " + authorized)
+	h, err = newHostKeys(address, []string{authorized}, knownHosts)
+	require.NoError(t, err)
+	var revoked *knownhosts.RevokedError
+	assert.ErrorAs(t, h.check(address, remote, key), &revoked)
+}
+
+func TestDialNeedsCredentials(t *testing.T) {
+	_, err := Dial(context.Background(), "web-1", Config{User: "logs", HostKeys: []string{"SHA256:x"}})
+	assert.ErrorContains(t, err, "a password or private key is required")
+
+	_, err = Dial(context.Background(), "web-1", Config{User: "logs", PrivateKey: []byte("not a key"), HostKeys: []string{"SHA256:x"}})
+	assert.Error(t, err)
+}
+
+func TestDefaultPort(t *testing.T) {
+	assert.Equal(t, "web-1:22", defaultPort("web-1"))
+	assert.Equal(t, "web-1:2222", defaultPort("web-1:2222"))
+	assert.Equal(t, "[2001:db8::1]:22", defaultPort("[2001:db8::1]"))
+}
+
+func TestDialCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	// Accepts and then says nothing
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cfg := Config{User: "logs", Password: "secret", HostKeys: []string{"SHA256:x"}}
+	_, err = Dial(ctx, listener.Addr().String(), cfg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,109 @@
+package sftp
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// hostKeys accepts the server keys configured for a host, given as
+// fingerprints or keys, or listed for it in a known_hosts file
+type hostKeys struct {
+	host         string
+	fixed        []ssh.PublicKey
+	fingerprints []string
+	knownHosts   ssh.HostKeyCallback
+	// listed are the keys the known_hosts file has for the host
+	listed []ssh.PublicKey
+}
+
+func newHostKeys(address string, keys []string, knownHostsFile string) (*hostKeys, error) {
+	h := &hostKeys{host: knownhosts.Normalize(address)}
+	for _, key := range keys {
+		if strings.HasPrefix(key, "SHA256:") {
+			h.fingerprints = append(h.fingerprints, strings.TrimRight(key, "="))
+			continue
+		}
+		public, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key: %w", err)
+		}
+		h.fixed = append(h.fixed, public)
+	}
+	if knownHostsFile != "" {
+		callback, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known hosts: %w", err)
+		}
+		h.knownHosts = callback
+		h.listed = listedKeys(callback, address)
+	}
+	if len(h.fixed) == 0 && len(h.fingerprints) == 0 && len(h.listed) == 0 {
+		return nil, fmt.Errorf("no host key known for %s", h.host)
+	}
+	return h, nil
+}
+
+// listedKeys returns the keys a known_hosts callback has for an address,
+// which it reports when asked to accept a key it does not list
+func listedKeys(callback ssh.HostKeyCallback, address string) []ssh.PublicKey {
+	probe, _ := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	var keyErr *knownhosts.KeyError
+	if !errors.As(callback(address, &net.TCPAddr{IP: net.IPv4zero}, probe), &keyErr) {
+		return nil
+	}
+	var keys []ssh.PublicKey
+	for _, known := range keyErr.Want {
+		keys = append(keys, known.Key)
+	}
+	return keys
+}
+
+// check is the ssh.HostKeyCallback accepting the known keys. A key the
+// known_hosts file revokes is refused even if it is configured.
+func (h *hostKeys) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if h.knownHosts != nil {
+		err := h.knownHosts(hostname, remote, key)
+		var revoked *knownhosts.RevokedError
+		if err == nil || errors.As(err, &revoked) {
+			return err
+		}
+	}
+	for _, fixed := range h.fixed {
+		if ssh.FixedHostKey(fixed)(hostname, remote, key) == nil {
+			return nil
+		}
+	}
+	if slices.Contains(h.fingerprints, ssh.FingerprintSHA256(key)) {
+		return nil
+	}
+	return fmt.Errorf("ssh: host key %s %s of %s is not known", key.Type(), ssh.FingerprintSHA256(key), h.host)
+}
+
+// algorithms lists the host key algorithms to offer, those of the known
+// keys so the server proves the key that is expected. With fingerprints,
+// whose key types are unknown, the defaults are offered.
+func (h *hostKeys) algorithms() []string {
+	if len(h.fingerprints) > 0 {
+		return nil
+	}
+	var algorithms []string
+	for _, key := range append(slices.Clip(h.fixed), h.listed...) {
+		keyAlgorithms := []string{key.Type()}
+		if key.Type() == ssh.KeyAlgoRSA {
+			keyAlgorithms = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, algorithm := range keyAlgorithms {
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+	return algorithms
+}
@@ -0,0 +1,305 @@
+// Package syslog receives syslog messages over UDP and TCP and streams
+// them to the log processor, so devices and forwarders such as rsyslog can
+// ship logs without file uploads.
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+)
+
+const (
+	// DefaultLogType parses the whole message, header included
+	DefaultLogType = "syslog"
+
+	// maxMessageSize matches the longest line the processor accepts
+	maxMessageSize = 1024 * 1024
+	// maxDatagramSize is the largest UDP payload
+	maxDatagramSize = 65535
+	// Received messages are handed to the sink in batches of up to
+	// batchSize, or after batchInterval
+	batchSize     = 500
+	batchInterval = time.Second
+)
+
+// Sink processes newline-separated records of a log type, such as
+// Processor.ProcessFile
+type Sink func(r io.Reader, logType string) error
+
+// Listener is a syslog port to receive on
+type Listener struct {
+	Protocol string // "udp" or "tcp"
+	Address  string // host:port
+	// LogType parses received messages. Any type other than syslog, such
+	// as nginx for access logs sent with nginx's syslog: target, receives
+	// only the message with the syslog header removed.
+	LogType string
+}
+
+// Receiver accepts messages on a listener and batches them into the sink
+type Receiver struct {
+	listener Listener
+	sink     Sink
+
+	packetConn net.PacketConn
+	tcp        net.Listener
+	messages   chan string
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// New validates the listener
+func New(listener Listener, sink Sink) (*Receiver, error) {
+	if listener.Protocol != "udp" && listener.Protocol != "tcp" {
+		return nil, fmt.Errorf("syslog listener %s: unsupported protocol %q", listener.Address, listener.Protocol)
+	}
+	if _, _, err := net.SplitHostPort(listener.Address); err != nil {
+		return nil, fmt.Errorf("syslog listener: invalid address %q: %w", listener.Address, err)
+	}
+	if listener.LogType == "" {
+		listener.LogType = DefaultLogType
+	}
+
+	return &Receiver{
+		listener: listener,
+		sink:     sink,
+		messages: make(chan string, batchSize),
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Start binds the listener and receives in the background until the
+// context is cancelled. Errors after binding are reported to onError.
+func (r *Receiver) Start(ctx context.Context, onError func(error)) error {
+	var err error
+	if r.listener.Protocol == "udp" {
+		r.packetConn, err = net.ListenPacket("udp", r.listener.Address)
+	} else {
+		r.tcp, err = net.Listen("tcp", r.listener.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen for syslog on %s/%s: %w", r.listener.Address, r.listener.Protocol, err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	if r.packetConn != nil {
+		go func() {
+			defer wg.Done()
+			r.serveUDP(onError)
+		}()
+	} else {
+		go func() {
+			defer wg.Done()
+			r.serveTCP(&wg, onError)
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.close()
+		// Deliver what was received before shutting down
+		wg.Wait()
+		close(r.messages)
+	}()
+	go r.deliver(onError)
+	return nil
+}
+
+// Addr is the bound address, useful when listening on port 0
+func (r *Receiver) Addr() net.Addr {
+	if r.packetConn != nil {
+		return r.packetConn.LocalAddr()
+	}
+	if r.tcp != nil {
+		return r.tcp.Addr()
+	}
+	return nil
+}
+
+func (r *Receiver) close() {
+	if r.packetConn != nil {
+		r.packetConn.Close()
+	}
+	if r.tcp != nil {
+		r.tcp.Close()
+	}
+	r.mu.Lock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.mu.Unlock()
+}
+
+// serveUDP treats each datagram as one message
+func (r *Receiver) serveUDP(onError func(error)) {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := r.packetConn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				onError(err)
+			}
+			return
+		}
+		r.receive(string(buf[:n]))
+	}
+}
+
+func (r *Receiver) serveTCP(wg *sync.WaitGroup, onError func(error)) {
+	for {
+		conn, err := r.tcp.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			onError(err)
+			continue
+		}
+
+		r.mu.Lock()
+		r.conns[conn] = struct{}{}
+		r.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.readFrames(conn); err != nil && !errors.Is(err, net.ErrClosed) {
+				onError(fmt.Errorf("syslog connection from %s: %w", conn.RemoteAddr(), err))
+			}
+			r.mu.Lock()
+			delete(r.conns, conn)
+			r.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// readFrames reads RFC 6587 frames until the connection closes. A frame
+// starting with a digit uses octet counting ("LEN SP MSG"); any other is
+// terminated by a newline.
+func (r *Receiver) readFrames(conn net.Conn) error {
+	reader := bufio.NewReaderSize(conn, 64*1024)
+	for {
+		first, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var message string
+		if first[0] >= '0' && first[0] <= '9' {
+			message, err = readOctetCounted(reader)
+		} else {
+			message, err = readLine(reader)
+		}
+		if err == io.EOF && message == "" {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		r.receive(message)
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func readOctetCounted(reader *bufio.Reader) (string, error) {
+	prefix, err := reader.ReadString(' ')
+	if err != nil {
+		return "", fmt.Errorf("invalid octet-counted frame")
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	if err != nil || length <= 0 || length > maxMessageSize {
+		return "", fmt.Errorf("invalid octet-counted frame length %q", strings.TrimSpace(prefix))
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return "", fmt.Errorf("truncated octet-counted frame: %w", err)
+	}
+	return string(buf), nil
+}
+
+// readLine reads a newline-terminated frame, discarding anything past
+// maxMessageSize
+func readLine(reader *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if b.Len() < maxMessageSize {
+			b.Write(chunk[:min(len(chunk), maxMessageSize-b.Len())])
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return b.String(), err
+	}
+}
+
+// receive queues a message with its framing removed. Embedded line breaks
+// are replaced so the message stays one record.
+func (r *Receiver) receive(message string) {
+	message = strings.TrimRight(message, "\r\n\x00")
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	if r.listener.LogType != DefaultLogType {
+		if _, text, err := logprocessor.ParseSyslog(message, time.Now()); err == nil {
+			message = text
+		}
+	}
+	r.messages <- strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(message)
+}
+
+// deliver hands queued messages to the sink in batches
+func (r *Receiver) deliver(onError func(error)) {
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	var batch strings.Builder
+	count := 0
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		if err := r.sink(strings.NewReader(batch.String()), r.listener.LogType); err != nil {
+			onError(fmt.Errorf("syslog %s/%s: %w", r.listener.Address, r.listener.Protocol, err))
+		}
+		batch.Reset()
+		count = 0
+	}
+
+	for {
+		select {
+		case message, ok := <-r.messages:
+			if !ok {
+				flush()
+				return
+			}
+			batch.WriteString(message)
+			batch.WriteByte('\n')
+			count++
+			if count >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
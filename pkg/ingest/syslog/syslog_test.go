@@ -0,0 +1,120 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector is a Sink recording the lines it receives
+type collector struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *collector) sink(r io.Reader, logType string) error {
+	scanner := bufio.NewScanner(r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for scanner.Scan() {
+		c.lines = append(c.lines, logType+": "+scanner.Text())
+	}
+	return scanner.Err()
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.lines)
+}
+
+// start runs a receiver on a free port and returns a function stopping it
+// and waiting for the received lines
+func start(t *testing.T, listener Listener) (*Receiver, *collector, func(int) []string) {
+	t.Helper()
+	c := &collector{}
+	r, err := New(listener, c.sink)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, r.Start(ctx, func(err error) { t.Errorf("unexpected error: %v", err) }))
+
+	wait := func(n int) []string {
+		t.Helper()
+		require.Eventually(t, func() bool { return c.count() >= n }, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.lines
+	}
+	return r, c, wait
+}
+
+func TestNewValidatesListener(t *testing.T) {
+	_, err := New(Listener{Protocol: "sctp", Address: ":514"}, nil)
+	assert.Error(t, err)
+	_, err = New(Listener{Protocol: "udp", Address: "514"}, nil)
+	assert.Error(t, err)
+
+	r, err := New(Listener{Protocol: "udp", Address: ":514"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLogType, r.listener.LogType)
+}
+
+func TestReceiveUDP(t *testing.T) {
+	r, _, wait := start(t, Listener{Protocol: "udp", Address: "127.0.0.1:0"})
+
+	conn, err := net.Dial("udp", r.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed\n"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("<165>1 2003-10-11T22:14:15.003Z host app - - - line one\nline two"))
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"syslog: <34>Oct 11 22:14:15 mymachine su: 'su root' failed",
+		"syslog: <165>1 2003-10-11T22:14:15.003Z host app - - - line one line two",
+	}, wait(2))
+}
+
+func TestReceiveTCPFraming(t *testing.T) {
+	r, _, wait := start(t, Listener{Protocol: "tcp", Address: "127.0.0.1:0"})
+
+	conn, err := net.Dial("tcp", r.Addr().String())
+	require.NoError(t, err)
+	// Octet counting allows newlines inside a message; frames may be mixed
+	// with newline-terminated ones
+	_, err = conn.Write([]byte("28 <13>Oct 11 22:14:15 h a: x\ny" +
+		"<13>Oct 11 22:14:16 h a: second\n" +
+		"<13>Oct 11 22:14:17 h a: unterminated"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Equal(t, []string{
+		"syslog: <13>Oct 11 22:14:15 h a: x y",
+		"syslog: <13>Oct 11 22:14:16 h a: second",
+		"syslog: <13>Oct 11 22:14:17 h a: unterminated",
+	}, wait(3))
+}
+
+func TestReceiveStripsHeaderForOtherLogTypes(t *testing.T) {
+	r, _, wait := start(t, Listener{Protocol: "udp", Address: "127.0.0.1:0", LogType: "nginx"})
+
+	conn, err := net.Dial("udp", r.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	access := `192.168.1.100 - - [10/Oct/2023:13:55:36 +0000] "GET / HTTP/1.1" 200 612 "-" "curl/8.0"`
+	_, err = conn.Write([]byte("<190>Oct 11 22:14:15 web01 nginx: " + access))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"nginx: " + access}, wait(1))
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package watcher
+
+import "os"
+
+// fileIdentity falls back to the path where inodes are unavailable, so
+// rotated files are picked up as new files
+func fileIdentity(path string, info os.FileInfo) string {
+	return "path:" + path
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileIdentity identifies a file by device and inode, which survive a
+// rename during rotation
+func fileIdentity(path string, info os.FileInfo) string {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino)
+	}
+	return "path:" + path
+}
@@ -0,0 +1,325 @@
+// Package watcher tails log files in local directories and streams their
+// new lines to the log processor, resuming from persisted offsets after a
+// restart.
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	defaultPattern      = "*.log"
+	defaultPollInterval = time.Second
+	// maxChunk bounds how much of a file is handed to the sink at once;
+	// a chunk this size without a newline is passed on as it is
+	maxChunk = 4 * 1024 * 1024
+)
+
+// Source is a directory whose files matching Pattern hold one log type
+type Source struct {
+	Dir     string
+	Pattern string
+	LogType string
+}
+
+// Sink processes complete records of a log type, such as
+// Processor.ProcessFile
+type Sink func(r io.Reader, logType string) error
+
+// Options tune a Watcher
+type Options struct {
+	// OffsetsFile persists read offsets across restarts; empty disables it
+	OffsetsFile  string
+	PollInterval time.Duration
+	// FromBeginning reads files present on first start in full; otherwise
+	// only lines written after startup are read. Files that appear later,
+	// such as after rotation, are always read in full.
+	FromBeginning bool
+	// Retry reads a chunk the sink failed on again on the next poll,
+	// rather than moving past it, for sinks that fail only while their
+	// destination is unavailable
+	Retry bool
+}
+
+// tailedFile is an open file with the offset read up to. Files are keyed
+// by identity rather than path so a rotated file is drained through its
+// open handle after it is renamed.
+type tailedFile struct {
+	id      string
+	path    string
+	logType string
+	file    *os.File
+	offset  int64
+}
+
+// savedOffset is a file's persisted position
+type savedOffset struct {
+	ID     string `json:"id"`
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+}
+
+// Watcher polls its sources for new and rotated files and new lines
+type Watcher struct {
+	sources []Source
+	sink    Sink
+	opts    Options
+
+	files   map[string]*tailedFile
+	saved   map[string]savedOffset
+	started bool
+	dirty   bool
+}
+
+// New validates the sources and loads persisted offsets
+func New(sources []Source, sink Sink, opts Options) (*Watcher, error) {
+	for i := range sources {
+		if sources[i].Dir == "" {
+			return nil, fmt.Errorf("watch source path is required")
+		}
+		if sources[i].LogType == "" {
+			return nil, fmt.Errorf("watch source %s: log_type is required", sources[i].Dir)
+		}
+		if sources[i].Pattern == "" {
+			sources[i].Pattern = defaultPattern
+		}
+		if _, err := filepath.Match(sources[i].Pattern, ""); err != nil {
+			return nil, fmt.Errorf("watch source %s: invalid pattern %q", sources[i].Dir, sources[i].Pattern)
+		}
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	w := &Watcher{
+		sources: sources,
+		sink:    sink,
+		opts:    opts,
+		files:   make(map[string]*tailedFile),
+		saved:   make(map[string]savedOffset),
+	}
+	if err := w.loadOffsets(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run polls until the context is cancelled, then saves offsets and closes
+// the files. Errors are reported to onError and polling continues.
+func (w *Watcher) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+	defer w.close(onError)
+
+	for {
+		w.poll(onError)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll picks up new, rotated and truncated files and reads what was
+// appended since the last poll
+func (w *Watcher) poll(onError func(error)) {
+	seen := make(map[string]bool)
+	for _, source := range w.sources {
+		paths, err := filepath.Glob(filepath.Join(source.Dir, source.Pattern))
+		if err != nil {
+			onError(err)
+			continue
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			id := fileIdentity(path, info)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			if tf, ok := w.files[id]; ok {
+				tf.path = path
+				if info.Size() < tf.offset {
+					// Truncated in place, e.g. by copytruncate
+					tf.offset = 0
+					w.dirty = true
+				}
+				continue
+			}
+			if err := w.open(id, path, source.LogType, info.Size()); err != nil {
+				onError(err)
+			}
+		}
+	}
+
+	for _, id := range w.trackedIDs() {
+		tf := w.files[id]
+		// Files no longer matched were rotated away or deleted; drain what
+		// was written before that and stop tracking them
+		gone := !seen[id]
+		if err := w.read(tf, gone); err != nil {
+			onError(fmt.Errorf("%s: %w", tf.path, err))
+		}
+		if gone {
+			tf.file.Close()
+			delete(w.files, id)
+			w.dirty = true
+		}
+	}
+
+	w.started = true
+	if err := w.saveOffsets(); err != nil {
+		onError(err)
+	}
+}
+
+// open starts tailing a file from its saved offset, or from the start or
+// end depending on when it appeared
+func (w *Watcher) open(id, path, logType string, size int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	offset := int64(0)
+	if saved, ok := w.saved[id]; ok {
+		// An offset past the end means the file was truncated or replaced
+		if saved.Offset <= size {
+			offset = saved.Offset
+		}
+	} else if !w.started && !w.opts.FromBeginning {
+		offset = size
+	}
+
+	w.files[id] = &tailedFile{id: id, path: path, logType: logType, file: file, offset: offset}
+	w.dirty = true
+	return nil
+}
+
+// read hands complete lines past the file's offset to the sink. A trailing
+// partial line waits for the rest unless the file is being drained.
+func (w *Watcher) read(tf *tailedFile, drain bool) error {
+	buf := make([]byte, maxChunk)
+	for {
+		n, err := tf.file.ReadAt(buf, tf.offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+
+		chunk := buf[:n]
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			chunk = chunk[:i+1]
+		} else if n < maxChunk && !drain {
+			return nil
+		}
+
+		// Unless retrying, the offset advances even if processing fails,
+		// so a bad record cannot stall the file
+		err = w.sink(bytes.NewReader(chunk), tf.logType)
+		if err == nil || !w.opts.Retry {
+			tf.offset += int64(len(chunk))
+			w.dirty = true
+		}
+		if err != nil {
+			return err
+		}
+		if n < maxChunk && len(chunk) == n {
+			return nil
+		}
+	}
+}
+
+func (w *Watcher) trackedIDs() []string {
+	ids := make([]string, 0, len(w.files))
+	for id := range w.files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (w *Watcher) close(onError func(error)) {
+	if err := w.saveOffsets(); err != nil {
+		onError(err)
+	}
+	for id, tf := range w.files {
+		tf.file.Close()
+		delete(w.files, id)
+	}
+}
+
+func (w *Watcher) loadOffsets() error {
+	if w.opts.OffsetsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(w.opts.OffsetsFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read offsets: %w", err)
+	}
+
+	var state struct {
+		Files []savedOffset `json:"files"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse offsets file %s: %w", w.opts.OffsetsFile, err)
+	}
+	for _, saved := range state.Files {
+		w.saved[saved.ID] = saved
+	}
+	return nil
+}
+
+// saveOffsets writes the tracked files' offsets when they changed,
+// replacing the file atomically
+func (w *Watcher) saveOffsets() error {
+	if w.opts.OffsetsFile == "" || !w.dirty {
+		return nil
+	}
+
+	var state struct {
+		Files []savedOffset `json:"files"`
+	}
+	state.Files = []savedOffset{}
+	for _, id := range w.trackedIDs() {
+		tf := w.files[id]
+		state.Files = append(state.Files, savedOffset{ID: id, Path: tf.path, Offset: tf.offset})
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.opts.OffsetsFile), 0755); err != nil {
+		return fmt.Errorf("failed to create offsets directory: %w", err)
+	}
+	tmp := w.opts.OffsetsFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write offsets: %w", err)
+	}
+	if err := os.Rename(tmp, w.opts.OffsetsFile); err != nil {
+		return fmt.Errorf("failed to write offsets: %w", err)
+	}
+	w.dirty = false
+	return nil
+}
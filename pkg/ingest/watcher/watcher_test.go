@@ -0,0 +1,188 @@
+package watcher
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector is a Sink recording the lines it receives
+type collector struct {
+	lines []string
+}
+
+func (c *collector) sink(r io.Reader, logType string) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		c.lines = append(c.lines, logType+": "+scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// take returns and clears the lines received so far
+func (c *collector) take() []string {
+	lines := c.lines
+	c.lines = nil
+	return lines
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func noError(t *testing.T) func(error) {
+	return func(err error) { t.Errorf("unexpected error: %v", err) }
+}
+
+func newTestWatcher(t *testing.T, dir, offsets string, c *collector, fromBeginning bool) *Watcher {
+	t.Helper()
+	w, err := New([]Source{{Dir: dir, LogType: "nginx"}}, c.sink, Options{OffsetsFile: offsets, FromBeginning: fromBeginning})
+	require.NoError(t, err)
+	return w
+}
+
+func TestWatcherTailsNewLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	appendFile(t, path, "old line\n")
+
+	c := &collector{}
+	w := newTestWatcher(t, dir, "", c, false)
+
+	// Existing content is skipped unless reading from the beginning
+	w.poll(noError(t))
+	assert.Empty(t, c.take())
+
+	appendFile(t, path, "first\nsecond\npart")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: first", "nginx: second"}, c.take())
+
+	// A partial line waits for its newline
+	w.poll(noError(t))
+	assert.Empty(t, c.take())
+	appendFile(t, path, "ial\n")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: partial"}, c.take())
+
+	// Files that appear later are read from the start
+	appendFile(t, filepath.Join(dir, "error.log"), "new file\n")
+	appendFile(t, filepath.Join(dir, "ignored.txt"), "not matched\n")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: new file"}, c.take())
+}
+
+func TestWatcherFromBeginning(t *testing.T) {
+	dir := t.TempDir()
+	appendFile(t, filepath.Join(dir, "access.log"), "old line\n")
+
+	c := &collector{}
+	w := newTestWatcher(t, dir, "", c, true)
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: old line"}, c.take())
+}
+
+func TestWatcherFollowsRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	appendFile(t, path, "")
+
+	c := &collector{}
+	w := newTestWatcher(t, dir, "", c, false)
+	w.poll(noError(t))
+
+	// Lines written just before the rename are drained from the rotated
+	// file, which no longer matches the pattern, and the new file is
+	// read from its start
+	appendFile(t, path, "before rotation\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "after rotation\n")
+
+	w.poll(noError(t))
+	assert.ElementsMatch(t, []string{"nginx: before rotation", "nginx: after rotation"}, c.take())
+	assert.Len(t, w.files, 1)
+
+	// Truncation in place restarts from the beginning
+	require.NoError(t, os.Truncate(path, 0))
+	appendFile(t, path, "truncated\n")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: truncated"}, c.take())
+}
+
+func TestWatcherResumesFromSavedOffsets(t *testing.T) {
+	dir := t.TempDir()
+	offsets := filepath.Join(t.TempDir(), "state", "offsets.json")
+	path := filepath.Join(dir, "access.log")
+	appendFile(t, path, "")
+
+	c := &collector{}
+	w := newTestWatcher(t, dir, offsets, c, false)
+	w.poll(noError(t))
+	appendFile(t, path, "one\n")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: one"}, c.take())
+	w.close(noError(t))
+
+	// Lines written while stopped are read after a restart, even though
+	// the file existed at startup
+	appendFile(t, path, "two\n")
+	w = newTestWatcher(t, dir, offsets, c, false)
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: two"}, c.take())
+
+	// A saved offset past the end of the file means it was replaced
+	id := w.trackedIDs()[0]
+	w.close(noError(t))
+	require.NoError(t, os.WriteFile(offsets, []byte(`{"files":[{"id":"`+id+`","offset":1000}]}`), 0644))
+	w = newTestWatcher(t, dir, offsets, c, false)
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: one", "nginx: two"}, c.take())
+}
+
+func TestWatcherRetriesFailedChunks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	appendFile(t, path, "one\n")
+
+	c := &collector{}
+	failing := true
+	sink := func(r io.Reader, logType string) error {
+		if failing {
+			return errors.New("server unavailable")
+		}
+		return c.sink(r, logType)
+	}
+	w, err := New([]Source{{Dir: dir, LogType: "nginx"}}, sink, Options{FromBeginning: true, Retry: true})
+	require.NoError(t, err)
+
+	var errs []error
+	w.poll(func(err error) { errs = append(errs, err) })
+	assert.Len(t, errs, 1)
+
+	failing = false
+	appendFile(t, path, "two\n")
+	w.poll(noError(t))
+	assert.Equal(t, []string{"nginx: one", "nginx: two"}, c.take(), "the failed chunk is read again")
+}
+
+func TestNewValidatesSources(t *testing.T) {
+	c := &collector{}
+	_, err := New([]Source{{Dir: "/var/log", Pattern: "[", LogType: "nginx"}}, c.sink, Options{})
+	assert.Error(t, err)
+	_, err = New([]Source{{Dir: "/var/log"}}, c.sink, Options{})
+	assert.Error(t, err)
+
+	w, err := New([]Source{{Dir: "/var/log", LogType: "nginx"}}, c.sink, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPattern, w.sources[0].Pattern)
+}
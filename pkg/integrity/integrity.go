@@ -0,0 +1,270 @@
+// Package integrity checks invariants of the stored data that nothing
+// enforces at write time: entries are not timestamped in the future, fired
+// alerts belong to a rule, roll-ups agree with the entries they summarize
+// and archived reports still match their manifests. Each violated
+// invariant becomes a finding with a suggestion for repairing it.
+package integrity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+)
+
+// Checks, in the order they run
+const (
+	CheckFutureTimestamps = "future_timestamps"
+	CheckOrphanedAlerts   = "orphaned_alerts"
+	CheckRollupTotals     = "rollup_totals"
+	CheckReportFiles      = "report_files"
+)
+
+// Checks lists every check
+var Checks = []string{CheckFutureTimestamps, CheckOrphanedAlerts, CheckRollupTotals, CheckReportFiles}
+
+const (
+	// maxSamples bounds the examples a finding lists
+	maxSamples = 10
+	// rollupSettle is how long after an hour ends its roll-up is checked.
+	// The roll-up is refreshed every 30 minutes, so an hour changed since
+	// the last refresh may briefly disagree with its entries.
+	rollupSettle = 2 * time.Hour
+	// rollupView is the TimescaleDB continuous aggregate of entries
+	rollupView = "log_entries_hourly"
+)
+
+// Store is the storage the checks read
+type Store interface {
+	storage.IntegrityStore
+	GetFacets(filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error)
+}
+
+// Packs lists archived compliance packs and verifies their files against
+// their manifests. *reporting.Reporter implements it.
+type Packs interface {
+	CompliancePacks() ([]string, error)
+	VerifyCompliancePack(period string) ([]reporting.ManifestMismatch, error)
+}
+
+// Options tunes the checks
+type Options struct {
+	// FutureSkew is how far ahead of the clock an entry may be timestamped
+	// before it counts as in the future
+	FutureSkew time.Duration
+	// RollupTolerance is the fraction by which an hour's roll-up total may
+	// differ from its entries
+	RollupTolerance float64
+	// RollupLookback is how many hours of roll-ups are checked
+	RollupLookback time.Duration
+}
+
+// DefaultOptions allows 5 minutes of clock skew and roll-ups 1% off, and
+// checks the last day of roll-ups
+func DefaultOptions() Options {
+	return Options{
+		FutureSkew:      5 * time.Minute,
+		RollupTolerance: 0.01,
+		RollupLookback:  24 * time.Hour,
+	}
+}
+
+// Result is the outcome of running every check
+type Result struct {
+	CheckedAt time.Time                 `json:"checked_at"`
+	Findings  []models.IntegrityFinding `json:"findings"`
+	// Errors holds the checks that could not run, by check
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Checker runs the checks and keeps the latest result
+type Checker struct {
+	store Store
+	packs Packs
+	opts  Options
+	now   func() time.Time
+
+	mu   sync.Mutex
+	last *Result
+}
+
+// NewChecker creates a checker. Report files are not checked when packs
+// is nil.
+func NewChecker(store Store, packs Packs, opts Options) *Checker {
+	return &Checker{store: store, packs: packs, opts: opts, now: time.Now}
+}
+
+// Run runs every check. A check that fails is recorded in the result's
+// Errors and the others still run.
+func (c *Checker) Run() *Result {
+	now := c.now()
+	result := &Result{CheckedAt: now, Findings: []models.IntegrityFinding{}}
+
+	checks := map[string]func(time.Time) (*models.IntegrityFinding, error){
+		CheckFutureTimestamps: c.checkFutureTimestamps,
+		CheckOrphanedAlerts:   c.checkOrphanedAlerts,
+		CheckRollupTotals:     c.checkRollupTotals,
+		CheckReportFiles:      c.checkReportFiles,
+	}
+	for _, check := range Checks {
+		finding, err := checks[check](now)
+		if err != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[check] = err.Error()
+			continue
+		}
+		if finding != nil {
+			finding.Check = check
+			result.Findings = append(result.Findings, *finding)
+		}
+	}
+
+	c.mu.Lock()
+	c.last = result
+	c.mu.Unlock()
+	return result
+}
+
+// Last returns the result of the latest run, or nil before the first
+func (c *Checker) Last() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// checkFutureTimestamps finds entries timestamped after the clock, which
+// usually come from a host with a wrong clock or time zone
+func (c *Checker) checkFutureTimestamps(now time.Time) (*models.IntegrityFinding, error) {
+	cutoff := now.Add(c.opts.FutureSkew)
+	types, err := c.store.GetFacets(&models.LogFilter{StartTime: &cutoff}, "log_type", maxSamples)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	finding := &models.IntegrityFinding{Severity: "warning"}
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		finding.Count += t.Count
+		names = append(names, t.Value)
+		finding.Samples = append(finding.Samples, fmt.Sprintf("%s: %d entries", t.Value, t.Count))
+	}
+	finding.Message = fmt.Sprintf("%d entries are timestamped after %s", finding.Count, cutoff.UTC().Format(time.RFC3339))
+	finding.Repair = fmt.Sprintf("Check the clock and time zone of the hosts sending %s logs, then delete the entries timestamped after %s and import them again",
+		strings.Join(names, ", "), cutoff.UTC().Format(time.RFC3339))
+	return finding, nil
+}
+
+// checkOrphanedAlerts finds fired alerts whose rule no longer exists
+func (c *Checker) checkOrphanedAlerts(time.Time) (*models.IntegrityFinding, error) {
+	orphans, err := c.store.GetOrphanedAlertEvents()
+	if err != nil {
+		return nil, err
+	}
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	finding := &models.IntegrityFinding{Severity: "warning"}
+	ids := make([]string, 0, len(orphans))
+	for _, orphan := range orphans {
+		finding.Count += orphan.Events
+		ids = append(ids, fmt.Sprint(orphan.RuleID))
+		if len(finding.Samples) < maxSamples {
+			finding.Samples = append(finding.Samples, fmt.Sprintf("rule %d: %d alerts", orphan.RuleID, orphan.Events))
+		}
+	}
+	finding.Message = fmt.Sprintf("%d alerts in alert_history belong to rules that do not exist", finding.Count)
+	finding.Repair = fmt.Sprintf("Delete them with DELETE FROM alert_history WHERE rule_id IN (%s), and check that the foreign key on alert_history.rule_id is enforced",
+		strings.Join(ids, ", "))
+	return finding, nil
+}
+
+// checkRollupTotals finds settled hours whose roll-up total differs from
+// their entries by more than the tolerance
+func (c *Checker) checkRollupTotals(now time.Time) (*models.IntegrityFinding, error) {
+	end := now.Add(-rollupSettle).Truncate(time.Hour)
+	start := end.Add(-c.opts.RollupLookback)
+	totals, err := c.store.GetRollupTotals(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var off []models.RollupTotal
+	for _, total := range totals {
+		if math.Abs(float64(total.Rollup-total.Raw)) > c.opts.RollupTolerance*float64(total.Raw) {
+			off = append(off, total)
+		}
+	}
+	if len(off) == 0 {
+		return nil, nil
+	}
+
+	finding := &models.IntegrityFinding{Severity: "warning", Count: int64(len(off))}
+	for _, total := range off {
+		if len(finding.Samples) < maxSamples {
+			finding.Samples = append(finding.Samples, fmt.Sprintf("%s: roll-up %d, entries %d",
+				total.Bucket.UTC().Format(time.RFC3339), total.Rollup, total.Raw))
+		}
+	}
+	first := off[0].Bucket.UTC()
+	last := off[len(off)-1].Bucket.UTC().Add(time.Hour)
+	finding.Message = fmt.Sprintf("%d hourly roll-ups differ from their entries by more than %g%%", len(off), c.opts.RollupTolerance*100)
+	finding.Repair = fmt.Sprintf("Recompute them with CALL refresh_continuous_aggregate('%s', '%s', '%s')",
+		rollupView, first.Format(time.RFC3339), last.Format(time.RFC3339))
+	return finding, nil
+}
+
+// checkReportFiles finds files of archived compliance packs that are
+// missing or no longer match their manifest
+func (c *Checker) checkReportFiles(time.Time) (*models.IntegrityFinding, error) {
+	if c.packs == nil {
+		return nil, nil
+	}
+	periods, err := c.packs.CompliancePacks()
+	if err != nil {
+		return nil, err
+	}
+
+	finding := &models.IntegrityFinding{Severity: "critical"}
+	var affected []string
+	for _, period := range periods {
+		mismatches, err := c.packs.VerifyCompliancePack(period)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify compliance pack %s: %w", period, err)
+		}
+		if len(mismatches) == 0 {
+			continue
+		}
+		affected = append(affected, period)
+		for _, mismatch := range mismatches {
+			finding.Count++
+			if len(finding.Samples) < maxSamples {
+				problem := "modified"
+				if mismatch.Actual == "" {
+					problem = "missing"
+				}
+				finding.Samples = append(finding.Samples, fmt.Sprintf("%s/%s: %s", period, mismatch.File, problem))
+			}
+		}
+	}
+	if finding.Count == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(affected)
+	finding.Message = fmt.Sprintf("%d files of compliance packs %s are missing or do not match their manifest",
+		finding.Count, strings.Join(affected, ", "))
+	finding.Repair = "Restore the files from a backup of the report store; a regenerated pack reflects the data stored now, not the data it was generated from"
+	return finding, nil
+}
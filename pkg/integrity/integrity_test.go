@@ -0,0 +1,131 @@
+package integrity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reporting"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+// rollupStore adds roll-up totals to the memory store, which has none
+type rollupStore struct {
+	*memory.Store
+	totals     []models.RollupTotal
+	start, end time.Time
+}
+
+func (s *rollupStore) GetRollupTotals(start, end time.Time) ([]models.RollupTotal, error) {
+	s.start, s.end = start, end
+	return s.totals, nil
+}
+
+type fakePacks map[string][]reporting.ManifestMismatch
+
+func (p fakePacks) CompliancePacks() ([]string, error) {
+	periods := []string{}
+	for period := range p {
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+func (p fakePacks) VerifyCompliancePack(period string) ([]reporting.ManifestMismatch, error) {
+	if period == "broken" {
+		return nil, errors.New("unreadable manifest")
+	}
+	return p[period], nil
+}
+
+func newChecker(store Store, packs Packs) *Checker {
+	c := NewChecker(store, packs, DefaultOptions())
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestCleanData(t *testing.T) {
+	store := memory.New()
+	require.NoError(t, store.InsertLogEntry(&models.LogEntry{Timestamp: now.Add(4 * time.Minute), LogType: "nginx", SourceIP: "10.0.0.1"}))
+
+	c := newChecker(store, fakePacks{"2024-02": nil})
+	assert.Nil(t, c.Last())
+	result := c.Run()
+	assert.Empty(t, result.Findings, "entries within the clock skew are fine")
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, now, result.CheckedAt)
+	assert.Same(t, result, c.Last())
+}
+
+func TestFindings(t *testing.T) {
+	store := &rollupStore{Store: memory.New()}
+	for _, entry := range []*models.LogEntry{
+		{Timestamp: now.Add(time.Hour), LogType: "nginx", SourceIP: "10.0.0.1"},
+		{Timestamp: now.Add(2 * time.Hour), LogType: "nginx", SourceIP: "10.0.0.1"},
+		{Timestamp: now.Add(time.Hour), LogType: "syslog", SourceIP: "10.0.0.2"},
+		{Timestamp: now, LogType: "syslog", SourceIP: "10.0.0.2"},
+	} {
+		require.NoError(t, store.InsertLogEntry(entry))
+	}
+	rule := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300}
+	require.NoError(t, store.CreateAlertRule(rule))
+	for _, ruleID := range []int64{rule.ID, 42, 42} {
+		require.NoError(t, store.InsertAlertEvent(&models.AlertEvent{RuleID: ruleID, Message: "fired", Severity: "warning"}))
+	}
+	hour := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	store.totals = []models.RollupTotal{
+		{Bucket: hour, Rollup: 995, Raw: 1000},
+		{Bucket: hour.Add(time.Hour), Rollup: 900, Raw: 1000},
+		{Bucket: hour.Add(3 * time.Hour), Rollup: 0, Raw: 12},
+	}
+	packs := fakePacks{
+		"2024-01": nil,
+		"2024-02": {
+			{File: "access.html", Expected: "aa", Actual: "bb"},
+			{File: "access.json", Expected: "cc"},
+		},
+	}
+
+	result := newChecker(store, packs).Run()
+	assert.Empty(t, result.Errors)
+	require.Len(t, result.Findings, 4)
+
+	future := result.Findings[0]
+	assert.Equal(t, CheckFutureTimestamps, future.Check)
+	assert.Equal(t, int64(3), future.Count)
+	assert.Equal(t, []string{"nginx: 2 entries", "syslog: 1 entries"}, future.Samples)
+	assert.Contains(t, future.Repair, "nginx, syslog logs")
+
+	orphans := result.Findings[1]
+	assert.Equal(t, CheckOrphanedAlerts, orphans.Check)
+	assert.Equal(t, int64(2), orphans.Count)
+	assert.Contains(t, orphans.Repair, "WHERE rule_id IN (42)")
+
+	rollups := result.Findings[2]
+	assert.Equal(t, CheckRollupTotals, rollups.Check)
+	assert.Equal(t, int64(2), rollups.Count, "within 1% is tolerated")
+	assert.Equal(t, "2024-03-01T07:00:00Z: roll-up 900, entries 1000", rollups.Samples[0])
+	assert.Contains(t, rollups.Repair, "'log_entries_hourly', '2024-03-01T07:00:00Z', '2024-03-01T10:00:00Z'")
+	// Only whole hours that have settled are checked
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), store.end)
+	assert.Equal(t, store.end.Add(-24*time.Hour), store.start)
+
+	reports := result.Findings[3]
+	assert.Equal(t, CheckReportFiles, reports.Check)
+	assert.Equal(t, "critical", reports.Severity)
+	assert.Equal(t, int64(2), reports.Count)
+	assert.Equal(t, []string{"2024-02/access.html: modified", "2024-02/access.json: missing"}, reports.Samples)
+}
+
+func TestFailedCheck(t *testing.T) {
+	result := newChecker(memory.New(), fakePacks{"broken": nil}).Run()
+	assert.Empty(t, result.Findings)
+	assert.Equal(t, map[string]string{
+		CheckReportFiles: "failed to verify compliance pack broken: unreadable manifest",
+	}, result.Errors)
+}
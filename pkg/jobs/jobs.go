@@ -0,0 +1,228 @@
+// Package jobs runs long operations in the background and tracks their
+// progress so API clients can poll them by ID.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	// StatusQueued is a job waiting for its turn to run
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// maxErrors bounds how many item errors a job keeps
+const maxErrors = 100
+
+// Snapshot is a job's state at one point in time
+type Snapshot struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	// Total is the number of items once known, Done those finished and
+	// Failed those among them that failed
+	Total  int64 `json:"total"`
+	Done   int64 `json:"done"`
+	Failed int64 `json:"failed"`
+	Bytes  int64 `json:"bytes"`
+	// Errors holds the first item errors and Error why the job failed
+	Errors  []string               `json:"errors,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	// Progress is how far a job got through its current item, for jobs
+	// that report it, such as the lines of a file read so far
+	Progress interface{} `json:"progress,omitempty"`
+	// Result is what a job that produces one returned
+	Result     interface{} `json:"result,omitempty"`
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
+
+// Job is a running or finished job. Its methods are safe for concurrent use.
+type Job struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+	// changed is closed on the job's next change
+	changed chan struct{}
+}
+
+// Changed returns a channel closed the next time the job changes, so
+// watchers can follow its progress without polling. Take it before the
+// Snapshot it follows up on, so no change is missed in between.
+func (j *Job) Changed() <-chan struct{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.changed == nil {
+		j.changed = make(chan struct{})
+	}
+	return j.changed
+}
+
+// notify wakes the job's watchers. It must be called with mu held.
+func (j *Job) notify() {
+	if j.changed != nil {
+		close(j.changed)
+		j.changed = nil
+	}
+}
+
+// SetTotal records how many items the job will process
+func (j *Job) SetTotal(total int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snapshot.Total = total
+	j.notify()
+}
+
+// Advance marks an item done after processing bytes of it. A non-nil err
+// marks it failed.
+func (j *Job) Advance(bytes int64, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snapshot.Done++
+	j.snapshot.Bytes += bytes
+	if err != nil {
+		j.snapshot.Failed++
+		if len(j.snapshot.Errors) < maxErrors {
+			j.snapshot.Errors = append(j.snapshot.Errors, err.Error())
+		}
+	}
+	j.notify()
+}
+
+// SetProgress records how far the job got through its current item
+func (j *Job) SetProgress(progress interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snapshot.Progress = progress
+	j.notify()
+}
+
+// SetResult records what the job produced, shown once it has finished
+func (j *Job) SetResult(result interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snapshot.Result = result
+	j.notify()
+}
+
+// WaitForSlot takes one of slots, a channel whose capacity bounds how many
+// jobs run at once, marking the job queued while they all are taken. It
+// returns the function giving the slot back, or ctx's error if ctx ends
+// first.
+func (j *Job) WaitForSlot(ctx context.Context, slots chan struct{}) (func(), error) {
+	release := func() { <-slots }
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	j.setStatus(StatusQueued)
+	select {
+	case slots <- struct{}{}:
+		j.setStatus(StatusRunning)
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (j *Job) setStatus(status string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snapshot.Status = status
+	j.notify()
+}
+
+// Snapshot returns a copy of the job's state
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := j.snapshot
+	s.Errors = append([]string(nil), s.Errors...)
+	return s
+}
+
+func (j *Job) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.snapshot.FinishedAt = &now
+	j.snapshot.Status = StatusCompleted
+	if err != nil {
+		j.snapshot.Status = StatusFailed
+		j.snapshot.Error = err.Error()
+	}
+	j.notify()
+}
+
+// Tracker runs jobs and keeps finished ones for a retention period
+type Tracker struct {
+	retention time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewTracker keeps finished jobs for retention
+func NewTracker(retention time.Duration) *Tracker {
+	return &Tracker{retention: retention, jobs: make(map[string]*Job)}
+}
+
+// Start runs fn in the background as a new job. The job fails if fn
+// returns an error; failures of single items are reported with Advance.
+func (t *Tracker) Start(ctx context.Context, kind string, details map[string]interface{}, fn func(ctx context.Context, job *Job) error) *Job {
+	job := &Job{snapshot: Snapshot{
+		ID:        newID(),
+		Kind:      kind,
+		Status:    StatusRunning,
+		Details:   details,
+		StartedAt: time.Now(),
+	}}
+
+	t.mu.Lock()
+	t.prune()
+	t.jobs[job.snapshot.ID] = job
+	t.mu.Unlock()
+
+	go func() {
+		job.finish(fn(ctx, job))
+	}()
+	return job
+}
+
+// Get returns the job with the given ID
+func (t *Tracker) Get(id string) (*Job, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	job, ok := t.jobs[id]
+	return job, ok
+}
+
+// prune drops jobs finished longer ago than the retention period
+func (t *Tracker) prune() {
+	cutoff := time.Now().Add(-t.retention)
+	for id, job := range t.jobs {
+		if finished := job.Snapshot().FinishedAt; finished != nil && finished.Before(cutoff) {
+			delete(t.jobs, id)
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitFinished(t *testing.T, job *Job) Snapshot {
+	t.Helper()
+	require.Eventually(t, func() bool { return job.Snapshot().FinishedAt != nil }, 5*time.Second, time.Millisecond)
+	return job.Snapshot()
+}
+
+func TestJobProgress(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	release := make(chan struct{})
+
+	job := tracker.Start(context.Background(), "test", map[string]interface{}{"bucket": "logs"}, func(ctx context.Context, job *Job) error {
+		job.SetTotal(3)
+		job.Advance(10, nil)
+		job.Advance(5, errors.New("b.log: corrupt"))
+		<-release
+		job.Advance(1, nil)
+		return nil
+	})
+
+	snapshot := job.Snapshot()
+	assert.Len(t, snapshot.ID, 24)
+	assert.Equal(t, "test", snapshot.Kind)
+	assert.Equal(t, StatusRunning, snapshot.Status)
+
+	got, ok := tracker.Get(snapshot.ID)
+	require.True(t, ok)
+	assert.Same(t, job, got)
+	_, ok = tracker.Get("missing")
+	assert.False(t, ok)
+
+	close(release)
+	snapshot = waitFinished(t, job)
+	assert.Equal(t, StatusCompleted, snapshot.Status)
+	assert.Equal(t, int64(3), snapshot.Total)
+	assert.Equal(t, int64(3), snapshot.Done)
+	assert.Equal(t, int64(1), snapshot.Failed)
+	assert.Equal(t, int64(16), snapshot.Bytes)
+	assert.Equal(t, []string{"b.log: corrupt"}, snapshot.Errors)
+	assert.Empty(t, snapshot.Error)
+	assert.Nil(t, snapshot.Result)
+}
+
+func TestJobResult(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	job := tracker.Start(context.Background(), "test", nil, func(ctx context.Context, job *Job) error {
+		job.SetResult(map[string]int{"fired": 2})
+		return nil
+	})
+
+	snapshot := waitFinished(t, job)
+	assert.Equal(t, map[string]int{"fired": 2}, snapshot.Result)
+}
+
+func TestJobChanged(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	step := make(chan struct{})
+	job := tracker.Start(context.Background(), "test", nil, func(ctx context.Context, job *Job) error {
+		<-step
+		job.SetProgress(map[string]int{"lines": 1000})
+		<-step
+		return nil
+	})
+
+	changed := job.Changed()
+	select {
+	case <-changed:
+		t.Fatal("changed before the job did")
+	default:
+	}
+
+	step <- struct{}{}
+	<-changed
+	assert.Equal(t, map[string]int{"lines": 1000}, job.Snapshot().Progress)
+
+	// Each change closes a new channel
+	changed = job.Changed()
+	close(step)
+	<-changed
+	assert.NotNil(t, waitFinished(t, job).FinishedAt)
+}
+
+func TestJobFailure(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	job := tracker.Start(context.Background(), "test", nil, func(ctx context.Context, job *Job) error {
+		return errors.New("access denied")
+	})
+
+	snapshot := waitFinished(t, job)
+	assert.Equal(t, StatusFailed, snapshot.Status)
+	assert.Equal(t, "access denied", snapshot.Error)
+}
+
+func TestJobWaitsForSlot(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	slots := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	run := func(ctx context.Context, job *Job) error {
+		done, err := job.WaitForSlot(ctx, slots)
+		if err != nil {
+			return err
+		}
+		defer done()
+		<-release
+		return nil
+	}
+	first := tracker.Start(context.Background(), "test", nil, run)
+	require.Eventually(t, func() bool { return len(slots) == 1 }, 5*time.Second, time.Millisecond)
+	second := tracker.Start(context.Background(), "test", nil, run)
+	require.Eventually(t, func() bool { return second.Snapshot().Status == StatusQueued }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, StatusRunning, first.Snapshot().Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	third := tracker.Start(ctx, "test", nil, run)
+	require.Eventually(t, func() bool { return third.Snapshot().Status == StatusQueued }, 5*time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, StatusFailed, waitFinished(t, third).Status)
+
+	release <- struct{}{}
+	assert.Equal(t, StatusCompleted, waitFinished(t, first).Status)
+	require.Eventually(t, func() bool { return second.Snapshot().Status == StatusRunning }, 5*time.Second, time.Millisecond)
+	close(release)
+	assert.Equal(t, StatusCompleted, waitFinished(t, second).Status)
+}
+
+func TestTrackerPrunesFinishedJobs(t *testing.T) {
+	tracker := NewTracker(0)
+	first := tracker.Start(context.Background(), "test", nil, func(context.Context, *Job) error { return nil })
+	waitFinished(t, first)
+
+	tracker.Start(context.Background(), "test", nil, func(context.Context, *Job) error { return nil })
+	_, ok := tracker.Get(first.Snapshot().ID)
+	assert.False(t, ok)
+}
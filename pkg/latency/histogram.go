@@ -0,0 +1,77 @@
+package latency
+
+import (
+	"math"
+	"sort"
+)
+
+// bucketGrowth is the ratio between the bounds of consecutive buckets
+const bucketGrowth = 1.01
+
+// Histogram counts response times in buckets 1% wide, so the percentiles
+// of any number of requests are kept in bounded memory, within 1% of the
+// exact value. The zero value is empty and ready to use.
+type Histogram struct {
+	buckets map[int]int64
+	count   int64
+	max     float64
+}
+
+// Add counts a response time in milliseconds. Times that are not positive
+// are ignored.
+func (h *Histogram) Add(ms float64) {
+	if ms <= 0 || math.IsInf(ms, 0) || math.IsNaN(ms) {
+		return
+	}
+	if h.buckets == nil {
+		h.buckets = make(map[int]int64)
+	}
+	h.buckets[int(math.Ceil(math.Log(ms)/math.Log(bucketGrowth)))]++
+	h.count++
+	h.max = math.Max(h.max, ms)
+}
+
+// Merge adds the counts of other
+func (h *Histogram) Merge(other *Histogram) {
+	if other.count == 0 {
+		return
+	}
+	if h.buckets == nil {
+		h.buckets = make(map[int]int64, len(other.buckets))
+	}
+	for bucket, n := range other.buckets {
+		h.buckets[bucket] += n
+	}
+	h.count += other.count
+	h.max = math.Max(h.max, other.max)
+}
+
+// Count returns the number of response times counted
+func (h *Histogram) Count() int64 {
+	return h.count
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) by the
+// nearest-rank method, as the upper bound of its bucket, or 0 when the
+// histogram is empty
+func (h *Histogram) Percentile(p float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	keys := make([]int, 0, len(h.buckets))
+	for bucket := range h.buckets {
+		keys = append(keys, bucket)
+	}
+	sort.Ints(keys)
+
+	target := rank(h.count, p)
+	var seen int64
+	for _, bucket := range keys {
+		seen += h.buckets[bucket]
+		if seen >= target {
+			return math.Min(math.Pow(bucketGrowth, float64(bucket)), h.max)
+		}
+	}
+	return h.max
+}
@@ -0,0 +1,159 @@
+// Package latency checks response times against the latency budgets teams
+// register for their paths, such as "/api/search within 500ms at p95".
+// Paths are compared after normalization, so every request to
+// /api/users/42 counts towards the budget of /api/users/{id}.
+package latency
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Placeholders replacing variable path segments
+const (
+	PlaceholderID   = "{id}"
+	PlaceholderUUID = "{uuid}"
+	PlaceholderHash = "{hash}"
+)
+
+var (
+	numericSegment = regexp.MustCompile(`^\d+$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// hashSegment matches hex digests and object IDs, which have digits
+	hashSegment = regexp.MustCompile(`^[0-9a-fA-F]{12,}$`)
+)
+
+// NormalizePath drops the query string and trailing slash of a request
+// path and replaces segments that identify a record, numbers, UUIDs and
+// hex digests, with placeholders. Normalizing a normalized path returns
+// it unchanged.
+func NormalizePath(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == "/" {
+		return "/"
+	}
+
+	segments := strings.Split(strings.TrimRight(path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case numericSegment.MatchString(segment):
+			segments[i] = PlaceholderID
+		case uuidSegment.MatchString(segment):
+			segments[i] = PlaceholderUUID
+		case hashSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789"):
+			segments[i] = PlaceholderHash
+		}
+	}
+	normalized := strings.Join(segments, "/")
+	if normalized == "" {
+		return "/"
+	}
+	return normalized
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) of values by the
+// nearest-rank method, or 0 for no values. It sorts values.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	return values[rank(int64(len(values)), p)-1]
+}
+
+// rank is the 1-based nearest rank of the p-th percentile of n values
+func rank(n int64, p float64) int64 {
+	r := int64(math.Ceil(p / 100 * float64(n)))
+	return min(max(r, 1), n)
+}
+
+// Result is how a path fared against its budget. Observed is the
+// percentile the budget names over Requests requests that reported a
+// response time, in milliseconds.
+type Result struct {
+	Budget   *models.LatencyBudget `json:"budget"`
+	Requests int64                 `json:"requests"`
+	Observed float64               `json:"observed_ms"`
+	// Violated is set when Observed exceeds the budget, by Overage
+	// percent of it
+	Violated bool    `json:"violated"`
+	Overage  float64 `json:"overage"`
+}
+
+// NewResult compares an observed percentile with a budget
+func NewResult(budget *models.LatencyBudget, requests int64, observed float64) Result {
+	result := Result{Budget: budget, Requests: requests, Observed: observed}
+	if requests > 0 && observed > budget.ThresholdMs {
+		result.Violated = true
+		result.Overage = (observed - budget.ThresholdMs) / budget.ThresholdMs * 100
+	}
+	return result
+}
+
+// Check measures entries against every budget. Entries without a response
+// time are left out. Violations come first, most over budget first, then
+// the budgets met and those without requests, by path.
+func Check(budgets []*models.LatencyBudget, entries []*models.LogEntry) []Result {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	paths := make(map[string][]float64)
+	for _, budget := range budgets {
+		paths[budget.Path] = nil
+	}
+	for _, entry := range entries {
+		if entry.ProcessingTime <= 0 {
+			continue
+		}
+		path := NormalizePath(entry.Path)
+		if samples, ok := paths[path]; ok {
+			paths[path] = append(samples, entry.ProcessingTime*1000)
+		}
+	}
+
+	results := make([]Result, 0, len(budgets))
+	for _, budget := range budgets {
+		samples := paths[budget.Path]
+		results = append(results, NewResult(budget, int64(len(samples)), Percentile(samples, budget.Percentile)))
+	}
+	SortResults(results)
+	return results
+}
+
+// SortResults orders results as Check returns them
+func SortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Violated != b.Violated {
+			return a.Violated
+		}
+		if a.Violated && a.Overage != b.Overage {
+			return a.Overage > b.Overage
+		}
+		if (a.Requests == 0) != (b.Requests == 0) {
+			return a.Requests > 0
+		}
+		if a.Budget.Path != b.Budget.Path {
+			return a.Budget.Path < b.Budget.Path
+		}
+		return a.Budget.Percentile < b.Budget.Percentile
+	})
+}
+
+// Violations returns the violated results
+func Violations(results []Result) []Result {
+	var violations []Result
+	for _, result := range results {
+		if result.Violated {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,113 @@
+package latency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"":                       "/",
+		"/":                      "/",
+		"/?q=1":                  "/",
+		"/api/search?q=shoes":    "/api/search",
+		"/api/search/":           "/api/search",
+		"/api/users/42":          "/api/users/{id}",
+		"/api/users/42/orders/7": "/api/users/{id}/orders/{id}",
+		"/api/orders/3f2b8c1e-9d4a-4b6e-8f00-12ab34cd56ef": "/api/orders/{uuid}",
+		"/static/app.5f3a9c2e7b1d.js":                      "/static/app.5f3a9c2e7b1d.js",
+		"/blobs/5f3a9c2e7b1d4e6f":                          "/blobs/{hash}",
+		"/api/v2/status":                                   "/api/v2/status",
+		"/api/deadbeefcafebabe":                            "/api/deadbeefcafebabe",
+		"/api/users/{id}":                                  "/api/users/{id}",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, NormalizePath(path), path)
+		assert.Equal(t, want, NormalizePath(want), "normalizing %s again", want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Zero(t, Percentile(nil, 95))
+
+	values := []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	assert.Equal(t, 5.0, Percentile(values, 50))
+	assert.Equal(t, 10.0, Percentile(values, 95))
+	assert.Equal(t, 9.0, Percentile(values, 90))
+	assert.Equal(t, 1.0, Percentile(values, 1))
+	assert.Equal(t, 10.0, Percentile(values, 100))
+}
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	assert.Zero(t, h.Percentile(95))
+
+	var values []float64
+	for i := 1; i <= 1000; i++ {
+		h.Add(float64(i))
+		values = append(values, float64(i))
+	}
+	h.Add(0)
+	h.Add(-3)
+	assert.Equal(t, int64(1000), h.Count(), "times that are not positive are ignored")
+
+	for _, p := range []float64{50, 90, 95, 99, 99.9, 100} {
+		exact := Percentile(values, p)
+		assert.InEpsilon(t, exact, h.Percentile(p), 0.01, "p%v", p)
+	}
+	assert.Equal(t, 1000.0, h.Percentile(100), "never above the largest time")
+
+	var other, merged Histogram
+	other.Add(5000)
+	merged.Merge(&h)
+	merged.Merge(&other)
+	assert.Equal(t, int64(1001), merged.Count())
+	assert.Equal(t, 5000.0, merged.Percentile(100))
+	assert.Equal(t, int64(1000), h.Count(), "merging leaves the source unchanged")
+}
+
+func TestCheck(t *testing.T) {
+	search := &models.LatencyBudget{ID: 1, Path: "/api/search", Percentile: 95, ThresholdMs: 500}
+	users := &models.LatencyBudget{ID: 2, Path: "/api/users/{id}", Percentile: 50, ThresholdMs: 100}
+	idle := &models.LatencyBudget{ID: 3, Path: "/api/idle", Percentile: 99, ThresholdMs: 100}
+	orders := &models.LatencyBudget{ID: 4, Path: "/api/orders", Percentile: 50, ThresholdMs: 100}
+
+	var entries []*models.LogEntry
+	for i := 1; i <= 20; i++ {
+		entries = append(entries,
+			&models.LogEntry{Path: "/api/search?q=x", ProcessingTime: float64(i) * 0.04},
+			&models.LogEntry{Path: "/api/users/7", ProcessingTime: 0.05},
+			&models.LogEntry{Path: "/api/orders", ProcessingTime: 0.5},
+		)
+	}
+	entries = append(entries, &models.LogEntry{Path: "/api/idle"})
+
+	assert.Nil(t, Check(nil, entries))
+
+	results := Check([]*models.LatencyBudget{idle, users, search, orders}, entries)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, orders, results[0].Budget, "most over budget first")
+	assert.True(t, results[0].Violated)
+	assert.InDelta(t, 400, results[0].Overage, 0.001)
+
+	assert.Equal(t, search, results[1].Budget)
+	assert.True(t, results[1].Violated)
+	assert.Equal(t, int64(20), results[1].Requests)
+	assert.InDelta(t, 760, results[1].Observed, 0.001)
+	assert.InDelta(t, 52, results[1].Overage, 0.001)
+
+	assert.Equal(t, users, results[2].Budget)
+	assert.False(t, results[2].Violated)
+	assert.InDelta(t, 50, results[2].Observed, 0.001)
+
+	assert.Equal(t, idle, results[3].Budget, "budgets without requests last")
+	assert.Zero(t, results[3].Requests)
+	assert.False(t, results[3].Violated)
+
+	assert.Len(t, Violations(results), 2)
+}
@@ -0,0 +1,174 @@
+// Package loadshed pauses ingestion while the process is short of memory
+// or CPU, so a burst of uploads is refused with a retry hint rather than
+// running the whole service out of memory mid-ingest.
+package loadshed
+
+import (
+	"context"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// resumeRatio is the share of a limit usage must fall below before
+// ingestion resumes, so the guard does not flap around the limit
+const resumeRatio = 0.9
+
+// Limits are the thresholds past which ingestion pauses. A zero limit is
+// not checked.
+type Limits struct {
+	MaxHeapBytes uint64
+	MaxCPU       float64 // share of GOMAXPROCS capacity, 0-1
+}
+
+// Usage is a measurement of the process
+type Usage struct {
+	HeapBytes uint64  `json:"heap_bytes"`
+	CPU       float64 `json:"cpu"` // share of GOMAXPROCS capacity over the last interval
+}
+
+// Status is whether ingestion is paused, and why
+type Status struct {
+	Overloaded bool       `json:"overloaded"`
+	Reason     string     `json:"reason,omitempty"`
+	Usage      Usage      `json:"usage"`
+	Since      *time.Time `json:"since,omitempty"`
+}
+
+// Guard samples usage and reports overload. It is safe for concurrent use.
+type Guard struct {
+	limits Limits
+	sample func() Usage
+
+	mu       sync.RWMutex
+	status   Status
+	resumed  chan struct{} // closed when an overload ends
+	cpuTotal float64
+	cpuIdle  float64
+}
+
+// New returns a guard sampling the Go runtime's heap and CPU metrics
+func New(limits Limits) *Guard {
+	g := &Guard{limits: limits, resumed: make(chan struct{})}
+	g.sample = g.runtimeUsage
+	return g
+}
+
+var sampleNames = []string{
+	"/memory/classes/heap/objects:bytes",
+	"/cpu/classes/total:cpu-seconds",
+	"/cpu/classes/idle:cpu-seconds",
+}
+
+// runtimeUsage reads the live heap and the CPU used since the last call.
+// It is only called from Run.
+func (g *Guard) runtimeUsage() Usage {
+	samples := make([]metrics.Sample, len(sampleNames))
+	for i, name := range sampleNames {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	var usage Usage
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		usage.HeapBytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindFloat64 && samples[2].Value.Kind() == metrics.KindFloat64 {
+		total, idle := samples[1].Value.Float64(), samples[2].Value.Float64()
+		if elapsed := total - g.cpuTotal; elapsed > 0 && g.cpuTotal > 0 {
+			usage.CPU = min(max(1-(idle-g.cpuIdle)/elapsed, 0), 1)
+		}
+		g.cpuTotal, g.cpuIdle = total, idle
+	}
+	return usage
+}
+
+// Run samples usage every interval until the context is done, calling
+// onChange when ingestion pauses or resumes
+func (g *Guard) Run(ctx context.Context, interval time.Duration, onChange func(Status)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if g.Update(g.sample()) && onChange != nil {
+			onChange(g.Status())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update records a measurement, pausing ingestion when it crosses a limit
+// and resuming once usage is back below resumeRatio of every limit. It
+// reports whether ingestion paused or resumed.
+func (g *Guard) Update(usage Usage) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.status.Usage = usage
+	if reason := g.exceeded(usage, 1); reason != "" {
+		paused := !g.status.Overloaded
+		if paused {
+			g.status.Overloaded = true
+			now := time.Now()
+			g.status.Since = &now
+		}
+		g.status.Reason = reason
+		return paused
+	}
+	if g.status.Overloaded && g.exceeded(usage, resumeRatio) == "" {
+		g.status = Status{Usage: usage}
+		close(g.resumed)
+		g.resumed = make(chan struct{})
+		return true
+	}
+	return false
+}
+
+// exceeded names the first limit usage crosses when the limits are scaled
+func (g *Guard) exceeded(usage Usage, scale float64) string {
+	if g.limits.MaxHeapBytes > 0 && float64(usage.HeapBytes) > float64(g.limits.MaxHeapBytes)*scale {
+		return fmt.Sprintf("heap usage of %.1f MB exceeds %.1f MB", float64(usage.HeapBytes)/(1<<20), float64(g.limits.MaxHeapBytes)/(1<<20))
+	}
+	if g.limits.MaxCPU > 0 && usage.CPU > g.limits.MaxCPU*scale {
+		return fmt.Sprintf("CPU usage of %.0f%% exceeds %.0f%%", usage.CPU*100, g.limits.MaxCPU*100)
+	}
+	return ""
+}
+
+// Status returns the latest measurement and whether ingestion is paused
+func (g *Guard) Status() Status {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.status
+}
+
+// Overloaded reports whether ingestion is paused, and why
+func (g *Guard) Overloaded() (bool, string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.status.Overloaded, g.status.Reason
+}
+
+// Wait blocks while ingestion is paused. It returns the context's error if
+// the context ends first.
+func (g *Guard) Wait(ctx context.Context) error {
+	for {
+		g.mu.RLock()
+		overloaded, resumed := g.status.Overloaded, g.resumed
+		g.mu.RUnlock()
+		if !overloaded {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumed:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package loadshed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	g := New(Limits{MaxHeapBytes: 100 << 20, MaxCPU: 0.8})
+
+	assert.False(t, g.Update(Usage{HeapBytes: 50 << 20, CPU: 0.5}))
+	overloaded, _ := g.Overloaded()
+	assert.False(t, overloaded)
+
+	assert.True(t, g.Update(Usage{HeapBytes: 120 << 20, CPU: 0.5}), "crossing a limit pauses")
+	overloaded, reason := g.Overloaded()
+	assert.True(t, overloaded)
+	assert.Contains(t, reason, "heap usage of 120.0 MB exceeds 100.0 MB")
+	since := g.Status().Since
+	require.NotNil(t, since)
+
+	assert.False(t, g.Update(Usage{HeapBytes: 50 << 20, CPU: 0.9}), "staying overloaded is not a change")
+	_, reason = g.Overloaded()
+	assert.Contains(t, reason, "CPU usage of 90% exceeds 80%")
+	assert.Equal(t, since, g.Status().Since)
+
+	assert.False(t, g.Update(Usage{HeapBytes: 95 << 20, CPU: 0.5}), "usage just under a limit stays paused")
+	overloaded, _ = g.Overloaded()
+	assert.True(t, overloaded)
+
+	assert.True(t, g.Update(Usage{HeapBytes: 80 << 20, CPU: 0.5}), "usage well under every limit resumes")
+	status := g.Status()
+	assert.False(t, status.Overloaded)
+	assert.Empty(t, status.Reason)
+	assert.Nil(t, status.Since)
+	assert.Equal(t, uint64(80<<20), status.Usage.HeapBytes)
+}
+
+func TestUpdateUnlimited(t *testing.T) {
+	g := New(Limits{MaxCPU: 0.5})
+	assert.False(t, g.Update(Usage{HeapBytes: 1 << 40, CPU: 0.1}), "a zero limit is not checked")
+}
+
+func TestWait(t *testing.T) {
+	g := New(Limits{MaxCPU: 0.5})
+	require.NoError(t, g.Wait(context.Background()), "wait returns at once when not overloaded")
+
+	g.Update(Usage{CPU: 0.9})
+	done := make(chan error, 1)
+	go func() { done <- g.Wait(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("wait returned while overloaded")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	g.Update(Usage{CPU: 0.1})
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after resuming")
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	g := New(Limits{MaxCPU: 0.5})
+	g.Update(Usage{CPU: 0.9})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, g.Wait(ctx), context.Canceled)
+}
+
+func TestRuntimeUsage(t *testing.T) {
+	g := New(Limits{})
+	first := g.runtimeUsage()
+	assert.NotZero(t, first.HeapBytes)
+	assert.Zero(t, first.CPU, "CPU usage needs a previous sample")
+
+	usage := g.runtimeUsage()
+	assert.GreaterOrEqual(t, usage.CPU, 0.0)
+	assert.LessOrEqual(t, usage.CPU, 1.0)
+}
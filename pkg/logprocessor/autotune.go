@@ -0,0 +1,192 @@
+package logprocessor
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// loadCounters accumulate worker activity between calls to TakeLoad
+type loadCounters struct {
+	lines atomic.Int64
+	parse atomic.Int64 // ns spent parsing
+	busy  atomic.Int64 // ns workers held a slot, parsing or waiting to queue
+	since atomic.Int64 // unix ns of the last TakeLoad
+}
+
+func (c *loadCounters) parsed(d time.Duration) {
+	c.lines.Add(1)
+	c.parse.Add(int64(d))
+}
+
+// Load is the work the processor did over a period
+type Load struct {
+	Lines     int64
+	ParseTime time.Duration // spent parsing, summed over workers
+	BusyTime  time.Duration // workers held a slot, summed over workers
+	Elapsed   time.Duration
+	Workers   int
+	QueueFill float64 // share of the processed entry queue in use, 0-1
+}
+
+// ParseLatency is the mean time to parse a line
+func (l Load) ParseLatency() time.Duration {
+	if l.Lines == 0 {
+		return 0
+	}
+	return l.ParseTime / time.Duration(l.Lines)
+}
+
+// Utilization is the share of worker capacity in use, 0-1. Workers
+// blocked on a full queue count as busy.
+func (l Load) Utilization() float64 {
+	if l.Elapsed <= 0 || l.Workers == 0 {
+		return 0
+	}
+	return min(float64(l.BusyTime)/(float64(l.Elapsed)*float64(l.Workers)), 1)
+}
+
+func (p *Processor) pool() chan struct{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.workerPool
+}
+
+// Workers returns how many lines are parsed concurrently
+func (p *Processor) Workers() int {
+	return cap(p.pool())
+}
+
+// SetWorkers changes how many lines are parsed concurrently. Lines already
+// being parsed finish on the previous workers.
+func (p *Processor) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cap(p.workerPool) != n {
+		p.workerPool = make(chan struct{}, n)
+	}
+}
+
+// TakeLoad returns the work done since the previous call, or since the
+// processor was created
+func (p *Processor) TakeLoad() Load {
+	now := time.Now().UnixNano()
+	since := p.load.since.Swap(now)
+	if since == 0 {
+		since = p.stats.StartTime.UnixNano()
+	}
+	return Load{
+		Lines:     p.load.lines.Swap(0),
+		ParseTime: time.Duration(p.load.parse.Swap(0)),
+		BusyTime:  time.Duration(p.load.busy.Swap(0)),
+		Elapsed:   time.Duration(now - since),
+		Workers:   p.Workers(),
+		QueueFill: float64(len(p.processedLogs)) / float64(cap(p.processedLogs)),
+	}
+}
+
+// WriteLoad is the work storing entries over a period
+type WriteLoad struct {
+	Entries int64
+	Time    time.Duration
+}
+
+// WriteLatency is the mean time to store an entry
+func (w WriteLoad) WriteLatency() time.Duration {
+	if w.Entries == 0 {
+		return 0
+	}
+	return w.Time / time.Duration(w.Entries)
+}
+
+// TuningBounds limit the values a Tuner chooses
+type TuningBounds struct {
+	MinWorkers, MaxWorkers     int
+	MinBatchSize, MaxBatchSize int
+}
+
+// Thresholds of the tuner's decisions
+const (
+	queueBacklog    = 0.8  // queue fill at which storage is the bottleneck
+	highUtilization = 0.85 // worker utilization at which workers are added
+	lowUtilization  = 0.4  // worker utilization at which workers are removed
+	latencyChange   = 0.1  // relative write latency change treated as real
+)
+
+// Tuner chooses the worker count and the storage batch size from the
+// measured load. Workers follow demand: they are added while nearly all are
+// busy and removed while most are idle, but never added while entries
+// queue up for storage, since parsing faster would only lengthen the
+// queue. The batch size hill-climbs on the write latency per entry,
+// doubling or halving in the direction that last made writes cheaper.
+type Tuner struct {
+	bounds      TuningBounds
+	workers     int
+	batchSize   int
+	growBatch   bool
+	lastLatency time.Duration
+}
+
+// NewTuner starts tuning from the given values, clamped to the bounds
+func NewTuner(bounds TuningBounds, workers, batchSize int) *Tuner {
+	return &Tuner{
+		bounds:    bounds,
+		workers:   clamp(workers, bounds.MinWorkers, bounds.MaxWorkers),
+		batchSize: clamp(batchSize, bounds.MinBatchSize, bounds.MaxBatchSize),
+		growBatch: true,
+	}
+}
+
+// Adjust takes the load since the previous call and returns the worker
+// count and batch size to use next. Idle periods change nothing.
+func (t *Tuner) Adjust(load Load, writes WriteLoad) (workers, batchSize int) {
+	if load.Lines > 0 {
+		t.workers = clamp(t.nextWorkers(load), t.bounds.MinWorkers, t.bounds.MaxWorkers)
+	}
+	if writes.Entries > 0 {
+		t.batchSize = clamp(t.nextBatchSize(writes.WriteLatency()), t.bounds.MinBatchSize, t.bounds.MaxBatchSize)
+	}
+	return t.workers, t.batchSize
+}
+
+func (t *Tuner) nextWorkers(load Load) int {
+	switch utilization := load.Utilization(); {
+	case load.QueueFill >= queueBacklog:
+		return t.workers - 1
+	case utilization >= highUtilization:
+		return t.workers + max(1, t.workers/4)
+	case utilization < lowUtilization:
+		return t.workers - 1
+	}
+	return t.workers
+}
+
+func (t *Tuner) nextBatchSize(latency time.Duration) int {
+	last := t.lastLatency
+	t.lastLatency = latency
+	if last > 0 {
+		change := float64(latency-last) / float64(last)
+		if change > -latencyChange && change < latencyChange {
+			return t.batchSize
+		}
+		if change >= latencyChange {
+			// The last step made writes dearer, so step back
+			t.growBatch = !t.growBatch
+		}
+	}
+
+	// Hold at the bounds until writes get dearer
+	if t.growBatch && t.batchSize >= t.bounds.MaxBatchSize || !t.growBatch && t.batchSize <= t.bounds.MinBatchSize {
+		return t.batchSize
+	}
+	if t.growBatch {
+		return t.batchSize * 2
+	}
+	return t.batchSize / 2
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
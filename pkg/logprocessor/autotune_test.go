@@ -0,0 +1,101 @@
+package logprocessor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBounds = TuningBounds{MinWorkers: 2, MaxWorkers: 16, MinBatchSize: 10, MaxBatchSize: 400}
+
+// busy is a second of load at the given worker utilization
+func busy(workers int, utilization, queueFill float64) Load {
+	return Load{
+		Lines:     1000,
+		BusyTime:  time.Duration(utilization * float64(workers) * float64(time.Second)),
+		Elapsed:   time.Second,
+		Workers:   workers,
+		QueueFill: queueFill,
+	}
+}
+
+func TestTunerWorkers(t *testing.T) {
+	tuner := NewTuner(testBounds, 8, 100)
+
+	workers, _ := tuner.Adjust(busy(8, 0.95, 0), WriteLoad{})
+	assert.Equal(t, 10, workers, "busy workers grow by a quarter")
+
+	workers, _ = tuner.Adjust(busy(10, 0.6, 0), WriteLoad{})
+	assert.Equal(t, 10, workers, "moderate load holds")
+
+	workers, _ = tuner.Adjust(busy(10, 0.99, 0.9), WriteLoad{})
+	assert.Equal(t, 9, workers, "a storage backlog sheds workers even when they are busy")
+
+	workers, _ = tuner.Adjust(busy(9, 0.1, 0), WriteLoad{})
+	assert.Equal(t, 8, workers, "idle workers shrink")
+
+	workers, _ = tuner.Adjust(Load{Elapsed: time.Second, Workers: 8}, WriteLoad{})
+	assert.Equal(t, 8, workers, "no lines changes nothing")
+
+	for i := 0; i < 20; i++ {
+		workers, _ = tuner.Adjust(busy(workers, 1, 0), WriteLoad{})
+	}
+	assert.Equal(t, testBounds.MaxWorkers, workers)
+	for i := 0; i < 20; i++ {
+		workers, _ = tuner.Adjust(busy(workers, 0, 0), WriteLoad{})
+	}
+	assert.Equal(t, testBounds.MinWorkers, workers)
+}
+
+func TestTunerBatchSize(t *testing.T) {
+	tuner := NewTuner(testBounds, 4, 50)
+	writes := func(perEntry time.Duration) WriteLoad {
+		return WriteLoad{Entries: 100, Time: 100 * perEntry}
+	}
+
+	_, batch := tuner.Adjust(Load{}, writes(time.Millisecond))
+	assert.Equal(t, 100, batch, "the first measurement explores upwards")
+
+	_, batch = tuner.Adjust(Load{}, writes(500*time.Microsecond))
+	assert.Equal(t, 200, batch, "cheaper writes keep growing")
+
+	_, batch = tuner.Adjust(Load{}, writes(520*time.Microsecond))
+	assert.Equal(t, 200, batch, "a small change holds")
+
+	_, batch = tuner.Adjust(Load{}, writes(800*time.Microsecond))
+	assert.Equal(t, 100, batch, "dearer writes step back")
+
+	_, batch = tuner.Adjust(Load{}, WriteLoad{})
+	assert.Equal(t, 100, batch, "no writes changes nothing")
+
+	tuner = NewTuner(testBounds, 4, 1000)
+	_, batch = tuner.Adjust(Load{}, writes(time.Millisecond))
+	assert.Equal(t, testBounds.MaxBatchSize, batch, "starting values are clamped")
+}
+
+func TestProcessorLoad(t *testing.T) {
+	processor := NewProcessor(3)
+	assert.Equal(t, 3, processor.Workers())
+	processor.TakeLoad()
+
+	logs := strings.Repeat("2023-10-10 13:55:36 INFO started\n", 5)
+	require.NoError(t, processor.ProcessFile(strings.NewReader(logs), "generic"))
+
+	load := processor.TakeLoad()
+	assert.EqualValues(t, 5, load.Lines)
+	assert.Equal(t, 3, load.Workers)
+	assert.Positive(t, load.Elapsed)
+	assert.GreaterOrEqual(t, load.BusyTime, load.ParseTime)
+	assert.InDelta(t, 5.0/1000, load.QueueFill, 0.0001)
+
+	load = processor.TakeLoad()
+	assert.Zero(t, load.Lines, "taking the load resets it")
+
+	processor.SetWorkers(8)
+	assert.Equal(t, 8, processor.Workers())
+	require.NoError(t, processor.ProcessFile(strings.NewReader(logs), "generic"))
+	assert.EqualValues(t, 5, processor.TakeLoad().Lines)
+}
@@ -0,0 +1,284 @@
+package logprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// cefHeaderFields names the pipe-delimited CEF header fields after the version
+var cefHeaderFields = []string{"device_vendor", "device_product", "device_version", "signature_id", "name", "severity"}
+
+// leefHeaderFields names the pipe-delimited LEEF header fields after the version
+var leefHeaderFields = []string{"device_vendor", "device_product", "device_version", "event_id"}
+
+// parseCEFLog parses ArcSight Common Event Format lines, optionally preceded
+// by a syslog header:
+// CEF:0|Vendor|Product|1.0|100|Blocked request|7|src=10.0.0.1 request=/admin
+func (p *Processor) parseCEFLog(line string) (*models.LogEntry, error) {
+	idx := strings.Index(line, "CEF:")
+	if idx < 0 {
+		return nil, fmt.Errorf("invalid CEF format: missing CEF: prefix")
+	}
+
+	header, rest, err := splitEscapedHeader(line[idx+len("CEF:"):], len(cefHeaderFields)+1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF format: %w", err)
+	}
+
+	entry := newSecurityEntry("cef", line)
+	entry.Metadata["cef_version"] = header[0]
+	for i, name := range cefHeaderFields {
+		entry.Metadata[name] = header[i+1]
+	}
+
+	extensions := parseCEFExtensions(rest)
+	p.applySecurityExtensions(entry, extensions)
+
+	if entry.Path == "" {
+		entry.Path = header[5] // event name
+	}
+	return entry, nil
+}
+
+// parseLEEFLog parses IBM QRadar Log Event Extended Format 1.0 and 2.0 lines:
+// LEEF:1.0|Vendor|Product|1.0|EventID|src=10.0.0.1<TAB>usrName=bob
+func (p *Processor) parseLEEFLog(line string) (*models.LogEntry, error) {
+	idx := strings.Index(line, "LEEF:")
+	if idx < 0 {
+		return nil, fmt.Errorf("invalid LEEF format: missing LEEF: prefix")
+	}
+
+	header, rest, err := splitEscapedHeader(line[idx+len("LEEF:"):], len(leefHeaderFields)+1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEEF format: %w", err)
+	}
+
+	version := header[0]
+	delimiter := "\t"
+	if strings.HasPrefix(version, "2") {
+		// LEEF 2.0 carries the attribute delimiter as an extra header field
+		delimField, ext, err := splitEscapedHeader(rest, 1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LEEF format: %w", err)
+		}
+		if d := parseLEEFDelimiter(delimField[0]); d != "" {
+			delimiter = d
+		}
+		rest = ext
+	}
+
+	entry := newSecurityEntry("leef", line)
+	entry.Metadata["leef_version"] = version
+	for i, name := range leefHeaderFields {
+		entry.Metadata[name] = header[i+1]
+	}
+
+	extensions := make(map[string]string)
+	for _, attr := range strings.Split(rest, delimiter) {
+		key, value, ok := cutUnescaped(attr, '=')
+		if !ok || key == "" {
+			continue
+		}
+		extensions[key] = unescapeExtensionValue(value)
+	}
+	p.applySecurityExtensions(entry, extensions)
+
+	if entry.Path == "" {
+		entry.Path = header[4] // event ID
+	}
+	return entry, nil
+}
+
+func newSecurityEntry(logType, line string) *models.LogEntry {
+	return &models.LogEntry{
+		LogType:   logType,
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// applySecurityExtensions maps well-known CEF/LEEF extension keys onto
+// LogEntry fields and keeps everything else in Metadata
+func (p *Processor) applySecurityExtensions(entry *models.LogEntry, extensions map[string]string) {
+	for key, value := range extensions {
+		switch key {
+		case "src", "sourceAddress":
+			if p.isValidIP(value) {
+				entry.SourceIP = value
+				continue
+			}
+		case "rt", "start", "devTime", "end":
+			if entry.Timestamp.IsZero() {
+				if t, err := parseSecurityTimestamp(value); err == nil {
+					entry.Timestamp = t
+					continue
+				}
+			}
+		case "request", "url":
+			entry.Path = value
+			continue
+		case "requestMethod":
+			entry.Method = value
+			continue
+		case "requestClientApplication", "userAgent":
+			entry.UserAgent = value
+			continue
+		case "out", "bytesOut", "dstBytes":
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+				entry.ResponseSize = size
+				continue
+			}
+		}
+		entry.Metadata[key] = convertValue(value)
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+}
+
+// splitEscapedHeader splits the first n pipe-delimited header fields,
+// honouring \| and \\ escapes, and returns the remainder of the line
+func splitEscapedHeader(s string, n int) ([]string, string, error) {
+	fields := make([]string, 0, n)
+	var current strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\') {
+			current.WriteByte(s[i+1])
+			i++
+			continue
+		}
+		if c == '|' {
+			fields = append(fields, current.String())
+			current.Reset()
+			if len(fields) == n {
+				return fields, s[i+1:], nil
+			}
+			continue
+		}
+		current.WriteByte(c)
+	}
+
+	return nil, "", fmt.Errorf("expected %d header fields, got %d", n, len(fields))
+}
+
+// parseCEFExtensions parses space-separated key=value pairs where values may
+// themselves contain spaces; a new pair starts at the next unescaped "key="
+func parseCEFExtensions(s string) map[string]string {
+	extensions := make(map[string]string)
+	s = strings.TrimSpace(s)
+
+	var key string
+	valueStart := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] != '=' {
+			continue
+		}
+
+		// Walk back from '=' to find the start of the key; an unescaped '='
+		// inside a value token is tolerated and kept as part of the value
+		keyStart := i
+		for keyStart > 0 && s[keyStart-1] != ' ' {
+			keyStart--
+		}
+		if keyStart < valueStart || keyStart == i {
+			continue
+		}
+		if key != "" {
+			extensions[key] = unescapeExtensionValue(strings.TrimSpace(s[valueStart:keyStart]))
+		}
+		key = s[keyStart:i]
+		valueStart = i + 1
+	}
+
+	if key != "" {
+		extensions[key] = unescapeExtensionValue(strings.TrimSpace(s[valueStart:]))
+	}
+	return extensions
+}
+
+// cutUnescaped splits s around the first occurrence of sep not preceded by a backslash
+func cutUnescaped(s string, sep byte) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+func unescapeExtensionValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			switch value[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(value[i+1])
+			}
+			i++
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// parseLEEFDelimiter decodes the LEEF 2.0 delimiter field, which is either a
+// single character or a hex code such as x09 or 0x5E
+func parseLEEFDelimiter(field string) string {
+	if len(field) == 1 {
+		return field
+	}
+	hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(field), "0x"), "x")
+	if code, err := strconv.ParseUint(hex, 16, 8); err == nil {
+		return string(rune(code))
+	}
+	return ""
+}
+
+// parseSecurityTimestamp handles the epoch-millisecond and
+// "MMM dd yyyy HH:mm:ss" forms used by CEF and LEEF
+func parseSecurityTimestamp(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+
+	formats := []string{
+		"Jan 02 2006 15:04:05.000 MST",
+		"Jan 02 2006 15:04:05 MST",
+		"Jan 02 2006 15:04:05.000",
+		"Jan 02 2006 15:04:05",
+		time.RFC3339Nano,
+		time.RFC3339,
+	}
+	for _, format := range formats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse timestamp: %s", value)
+}
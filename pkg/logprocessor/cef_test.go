@@ -0,0 +1,88 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCEFLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `Oct 10 13:55:36 fw01 CEF:0|Security|WAF\|Edge|1.0|100|SQL injection blocked|8|src=10.0.0.5 rt=1696946136000 requestMethod=POST request=/login?user=a\=b msg=Blocked by rule 942100 out=512 cs1Label=rule`
+
+	entry, err := processor.parseCEFLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "cef", entry.LogType)
+	assert.Equal(t, "10.0.0.5", entry.SourceIP)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/login?user=a=b", entry.Path)
+	assert.Equal(t, int64(512), entry.ResponseSize)
+	assert.Equal(t, "Security", entry.Metadata["device_vendor"])
+	assert.Equal(t, "WAF|Edge", entry.Metadata["device_product"])
+	assert.Equal(t, "SQL injection blocked", entry.Metadata["name"])
+	assert.Equal(t, "8", entry.Metadata["severity"])
+	assert.Equal(t, "Blocked by rule 942100", entry.Metadata["msg"])
+	assert.Equal(t, "rule", entry.Metadata["cs1Label"])
+}
+
+func TestParseCEFLogWithoutRequest(t *testing.T) {
+	processor := NewProcessor(1)
+
+	entry, err := processor.parseCEFLog(`CEF:0|Vendor|IDS|2.1|200|Port scan detected|5|src=192.168.1.9 dpt=22`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Port scan detected", entry.Path)
+	assert.Equal(t, 22, entry.Metadata["dpt"])
+}
+
+func TestParseCEFLogInvalid(t *testing.T) {
+	processor := NewProcessor(1)
+
+	_, err := processor.parseCEFLog("plain text line")
+	assert.Error(t, err)
+
+	_, err = processor.parseCEFLog("CEF:0|Vendor|Product")
+	assert.Error(t, err)
+}
+
+func TestParseLEEFLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := "LEEF:1.0|IBM|QRadar|7.3|LoginFailure|src=172.16.0.4\tdevTime=Oct 10 2023 13:55:36\tusrName=alice\turl=/admin"
+
+	entry, err := processor.parseLEEFLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "leef", entry.LogType)
+	assert.Equal(t, "172.16.0.4", entry.SourceIP)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "/admin", entry.Path)
+	assert.Equal(t, "alice", entry.Metadata["usrName"])
+	assert.Equal(t, "LoginFailure", entry.Metadata["event_id"])
+}
+
+func TestParseLEEF2LogCustomDelimiter(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `LEEF:2.0|Vendor|Firewall|1.0|Deny|^|src=10.1.1.1^dst=10.2.2.2^msg=a\=b`
+
+	entry, err := processor.parseLEEFLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.1.1.1", entry.SourceIP)
+	assert.Equal(t, "10.2.2.2", entry.Metadata["dst"])
+	assert.Equal(t, "a=b", entry.Metadata["msg"])
+	assert.Equal(t, "Deny", entry.Path)
+}
+
+func TestParseLEEFDelimiter(t *testing.T) {
+	assert.Equal(t, "^", parseLEEFDelimiter("^"))
+	assert.Equal(t, "\t", parseLEEFDelimiter("x09"))
+	assert.Equal(t, "^", parseLEEFDelimiter("0x5E"))
+	assert.Equal(t, "", parseLEEFDelimiter("zz"))
+}
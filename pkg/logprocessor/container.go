@@ -0,0 +1,252 @@
+package logprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// dockerLogLine is a line written by Docker's json-file logging driver
+type dockerLogLine struct {
+	Log    string            `json:"log"`
+	Stream string            `json:"stream"`
+	Time   string            `json:"time"`
+	Attrs  map[string]string `json:"attrs"`
+}
+
+// containerLogName matches /var/log/containers/<pod>_<namespace>_<container>-<id>.log
+var containerLogName = regexp.MustCompile(`^(.+)_([^_]+)_(.+)-([0-9a-f]{64})\.log$`)
+
+// parseDockerLog parses Docker json-file lines such as
+// {"log":"GET /health 200\n","stream":"stdout","time":"2023-10-10T13:55:36.123456789Z"}
+func (p *Processor) parseDockerLog(line string) (*models.LogEntry, error) {
+	entry := newContainerEntry("docker", line)
+	if err := p.applyDockerLine(entry, line); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// parseKubernetesLog parses container logs as found on Kubernetes nodes: CRI
+// lines ("<time> <stream> <P|F> <payload>") or Docker json-file lines,
+// optionally prefixed with the source log file as emitted by tail/grep -H,
+// e.g. /var/log/containers/web-7d9f_shop_nginx-<id>.log:2023-10-10T13:55:36Z stdout F ...
+// Pod, namespace and container are taken from that file name.
+func (p *Processor) parseKubernetesLog(line string) (*models.LogEntry, error) {
+	entry := newContainerEntry("kubernetes", line)
+
+	body := line
+	if source, rest, ok := cutLogSource(line); ok {
+		applyKubernetesSource(entry, source)
+		body = rest
+	}
+
+	if strings.HasPrefix(body, "{") {
+		if err := p.applyDockerLine(entry, body); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+
+	// CRI format: timestamp, stream, partial/full tag, payload
+	parts := strings.SplitN(body, " ", 4)
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid CRI log format: expected timestamp, stream and tag")
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid CRI timestamp: %s", parts[0])
+	}
+	if parts[1] != "stdout" && parts[1] != "stderr" {
+		return nil, fmt.Errorf("invalid CRI stream: %s", parts[1])
+	}
+
+	entry.Timestamp = t
+	entry.Metadata["stream"] = parts[1]
+	if strings.HasPrefix(parts[2], "P") {
+		// Partial lines are split by the runtime at 16KB and can't be
+		// rejoined when parsing line by line
+		entry.Metadata["partial"] = true
+	}
+
+	payload := ""
+	if len(parts) == 4 {
+		payload = parts[3]
+	}
+	p.applyContainerPayload(entry, payload)
+	return entry, nil
+}
+
+func newContainerEntry(logType, line string) *models.LogEntry {
+	return &models.LogEntry{
+		LogType:   logType,
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func (p *Processor) applyDockerLine(entry *models.LogEntry, line string) error {
+	var docker dockerLogLine
+	if err := json.Unmarshal([]byte(line), &docker); err != nil {
+		return fmt.Errorf("invalid docker log format: %w", err)
+	}
+	if docker.Time == "" {
+		return fmt.Errorf("invalid docker log format: missing time")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, docker.Time)
+	if err != nil {
+		return fmt.Errorf("invalid docker timestamp: %s", docker.Time)
+	}
+	entry.Timestamp = t
+
+	if docker.Stream != "" {
+		entry.Metadata["stream"] = docker.Stream
+	}
+	for key, value := range docker.Attrs {
+		entry.Metadata[key] = value
+	}
+
+	p.applyContainerPayload(entry, docker.Log)
+	return nil
+}
+
+// applyContainerPayload parses the application output wrapped by the
+// container runtime. JSON objects are mapped field by field and other lines
+// are treated like generic logs. The runtime timestamp is kept unless the
+// payload carries its own.
+func (p *Processor) applyContainerPayload(entry *models.LogEntry, payload string) {
+	payload = strings.TrimRight(payload, "\r\n")
+
+	if strings.HasPrefix(payload, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &fields); err == nil {
+			p.applyJSONFields(entry, fields)
+			return
+		}
+	}
+
+	// Generic "date time level message" lines; anything else is kept whole
+	message := payload
+	if parts := strings.Fields(payload); len(parts) >= 3 {
+		if t, err := p.parseGenericTimestamp(parts[0] + " " + parts[1]); err == nil {
+			entry.Timestamp = t
+			entry.Metadata["level"] = strings.ToLower(parts[2])
+			message = strings.Join(parts[3:], " ")
+		}
+	}
+
+	entry.Path = message
+	for key, value := range p.extractKeyValuePairs(message) {
+		entry.Metadata[key] = value
+	}
+}
+
+// applyJSONFields maps well-known structured logging keys onto LogEntry
+// fields and keeps everything else in Metadata
+func (p *Processor) applyJSONFields(entry *models.LogEntry, fields map[string]interface{}) {
+	var message string
+	for key, value := range fields {
+		str, isString := value.(string)
+		switch key {
+		case "time", "ts", "timestamp", "@timestamp":
+			if isString {
+				if t, err := p.parseLogfmtTimestamp(str); err == nil {
+					entry.Timestamp = t
+					continue
+				}
+			}
+		case "msg", "message":
+			if isString {
+				message = str
+				continue
+			}
+		case "level", "lvl", "severity":
+			if isString {
+				entry.Metadata["level"] = strings.ToLower(str)
+				continue
+			}
+		case "method":
+			if isString {
+				entry.Method = strings.ToUpper(str)
+				continue
+			}
+		case "path", "uri", "url":
+			if isString {
+				entry.Path = str
+				continue
+			}
+		case "status", "status_code":
+			if code, ok := value.(float64); ok {
+				entry.StatusCode = int(code)
+				continue
+			}
+		case "ip", "remote_addr", "client_ip", "source_ip":
+			if isString && p.isValidIP(str) {
+				entry.SourceIP = str
+				continue
+			}
+		case "duration", "latency", "elapsed":
+			if seconds, ok := value.(float64); ok {
+				entry.ProcessingTime = seconds
+				continue
+			}
+			if isString {
+				if d, err := time.ParseDuration(str); err == nil {
+					entry.ProcessingTime = d.Seconds()
+					continue
+				}
+			}
+		}
+		entry.Metadata[key] = value
+	}
+
+	if entry.Path == "" {
+		entry.Path = message
+	} else if message != "" {
+		entry.Metadata["msg"] = message
+	}
+}
+
+// cutLogSource splits a "<file>.log:" prefix from a line
+func cutLogSource(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "/") {
+		return "", line, false
+	}
+	idx := strings.Index(line, ".log:")
+	if idx < 0 {
+		return "", line, false
+	}
+	return line[:idx+len(".log")], line[idx+len(".log:"):], true
+}
+
+// applyKubernetesSource extracts pod, namespace and container from either
+// /var/log/containers/<pod>_<namespace>_<container>-<id>.log or
+// /var/log/pods/<namespace>_<pod>_<uid>/<container>/<n>.log
+func applyKubernetesSource(entry *models.LogEntry, source string) {
+	entry.Metadata["source_file"] = source
+
+	if m := containerLogName.FindStringSubmatch(path.Base(source)); m != nil {
+		entry.Metadata["pod"] = m[1]
+		entry.Metadata["namespace"] = m[2]
+		entry.Metadata["container"] = m[3]
+		entry.Metadata["container_id"] = m[4]
+		return
+	}
+
+	dir := path.Dir(source)
+	podDir := path.Base(path.Dir(dir))
+	if parts := strings.SplitN(podDir, "_", 3); len(parts) == 3 {
+		entry.Metadata["namespace"] = parts[0]
+		entry.Metadata["pod"] = parts[1]
+		entry.Metadata["pod_uid"] = parts[2]
+		entry.Metadata["container"] = path.Base(dir)
+	}
+}
@@ -0,0 +1,104 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDockerLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `{"log":"2023-10-10 13:55:40 ERROR failed to connect user_id=42\n","stream":"stderr","time":"2023-10-10T13:55:36.123456789Z","attrs":{"tag":"api"}}`
+
+	entry, err := processor.parseDockerLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "docker", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 40, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "failed to connect user_id=42", entry.Path)
+	assert.Equal(t, "stderr", entry.Metadata["stream"])
+	assert.Equal(t, "api", entry.Metadata["tag"])
+	assert.Equal(t, "error", entry.Metadata["level"])
+	assert.Equal(t, 42, entry.Metadata["user_id"])
+}
+
+func TestParseDockerLogJSONPayload(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `{"log":"{\"level\":\"WARN\",\"msg\":\"slow request\",\"method\":\"get\",\"path\":\"/api/orders\",\"status\":503,\"remote_addr\":\"10.1.2.3\",\"duration\":1.25}\n","stream":"stdout","time":"2023-10-10T13:55:36Z"}`
+
+	entry, err := processor.parseDockerLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/orders", entry.Path)
+	assert.Equal(t, 503, entry.StatusCode)
+	assert.Equal(t, "10.1.2.3", entry.SourceIP)
+	assert.Equal(t, 1.25, entry.ProcessingTime)
+	assert.Equal(t, "warn", entry.Metadata["level"])
+	assert.Equal(t, "slow request", entry.Metadata["msg"])
+}
+
+func TestParseDockerLogInvalid(t *testing.T) {
+	processor := NewProcessor(1)
+
+	_, err := processor.parseDockerLog(`not json`)
+	assert.Error(t, err)
+
+	_, err = processor.parseDockerLog(`{"log":"hello","stream":"stdout"}`)
+	assert.Error(t, err)
+}
+
+func TestParseKubernetesLogCRI(t *testing.T) {
+	processor := NewProcessor(1)
+
+	id := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	line := "/var/log/containers/web-7d9f8_shop_nginx-" + id + ".log:2023-10-10T13:55:36.5Z stdout F upstream timed out"
+
+	entry, err := processor.parseKubernetesLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "kubernetes", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 500000000, time.UTC), entry.Timestamp)
+	assert.Equal(t, "upstream timed out", entry.Path)
+	assert.Equal(t, "web-7d9f8", entry.Metadata["pod"])
+	assert.Equal(t, "shop", entry.Metadata["namespace"])
+	assert.Equal(t, "nginx", entry.Metadata["container"])
+	assert.Equal(t, id, entry.Metadata["container_id"])
+	assert.Equal(t, "stdout", entry.Metadata["stream"])
+	assert.Nil(t, entry.Metadata["partial"])
+}
+
+func TestParseKubernetesLogPodsPathAndDocker(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `/var/log/pods/shop_web-7d9f8_5c1e/app/0.log:{"log":"{\"msg\":\"ready\"}","stream":"stdout","time":"2023-10-10T13:55:36Z"}`
+
+	entry, err := processor.parseKubernetesLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ready", entry.Path)
+	assert.Equal(t, "shop", entry.Metadata["namespace"])
+	assert.Equal(t, "web-7d9f8", entry.Metadata["pod"])
+	assert.Equal(t, "5c1e", entry.Metadata["pod_uid"])
+	assert.Equal(t, "app", entry.Metadata["container"])
+}
+
+func TestParseKubernetesLogPartialAndInvalid(t *testing.T) {
+	processor := NewProcessor(1)
+
+	entry, err := processor.parseKubernetesLog("2023-10-10T13:55:36Z stderr P first half of a long line")
+	require.NoError(t, err)
+	assert.Equal(t, true, entry.Metadata["partial"])
+	assert.Equal(t, "stderr", entry.Metadata["stream"])
+
+	_, err = processor.parseKubernetesLog("2023-10-10T13:55:36Z console F hello")
+	assert.Error(t, err)
+
+	_, err = processor.parseKubernetesLog("yesterday stdout F hello")
+	assert.Error(t, err)
+}
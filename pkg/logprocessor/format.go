@@ -0,0 +1,392 @@
+package logprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Well-known access log format names accepted in place of a directive
+var namedAccessLogFormats = map[string]map[string]string{
+	"apache": {
+		"common":   `%h %l %u %t "%r" %>s %b`,
+		"combined": `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`,
+	},
+	"nginx": {
+		"combined": `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"`,
+	},
+}
+
+// AccessLogFormat is a parser compiled from an Apache LogFormat or nginx
+// log_format directive
+type AccessLogFormat struct {
+	directive string
+	pattern   *regexp.Regexp
+	fields    []formatField
+}
+
+// formatField applies one captured directive value to a log entry
+type formatField struct {
+	name  string
+	apply func(p *Processor, entry *models.LogEntry, value string) error
+}
+
+var (
+	apacheDirective = regexp.MustCompile(`%[<>]?(?:!?[0-9,]+)?(?:\{([^}]*)\})?([a-zA-Z%])`)
+	nginxVariable   = regexp.MustCompile(`\$(?:\{([a-z0-9_]+)\}|([a-z0-9_]+))`)
+)
+
+// CompileAccessLogFormat compiles a LogFormat (apache) or log_format (nginx)
+// directive such as `%h %l %u %t "%r" %>s %b %D` into a parser. The names
+// "common" and "combined" select the standard formats.
+func CompileAccessLogFormat(logType, directive string) (*AccessLogFormat, error) {
+	if named, ok := namedAccessLogFormats[logType][directive]; ok {
+		directive = named
+	}
+
+	var matches [][]int
+	var fieldFor func(m []string) (formatField, error)
+	switch logType {
+	case "apache":
+		matches = apacheDirective.FindAllStringSubmatchIndex(directive, -1)
+		fieldFor = apacheField
+	case "nginx":
+		matches = nginxVariable.FindAllStringSubmatchIndex(directive, -1)
+		fieldFor = nginxField
+	default:
+		return nil, fmt.Errorf("custom formats are not supported for log type %s", logType)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("log format %q has no fields", directive)
+	}
+
+	format := &AccessLogFormat{directive: directive}
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, m := range matches {
+		literal := directive[last:m[0]]
+		expr.WriteString(regexp.QuoteMeta(literal))
+		last = m[1]
+
+		groups := make([]string, len(m)/2)
+		for i := range groups {
+			if m[2*i] >= 0 {
+				groups[i] = directive[m[2*i]:m[2*i+1]]
+			}
+		}
+
+		if groups[0] == "%%" {
+			expr.WriteString("%")
+			continue
+		}
+
+		field, err := fieldFor(groups)
+		if err != nil {
+			return nil, err
+		}
+		format.fields = append(format.fields, field)
+
+		// Quoted values may contain spaces and escaped quotes; bracketed
+		// timestamps contain a space before the zone
+		switch {
+		case strings.HasSuffix(literal, `"`):
+			expr.WriteString(`((?:[^"\\]|\\.)*)`)
+		case strings.HasSuffix(literal, "[") || field.name == "%t" || field.name == "$time_local":
+			expr.WriteString(`([^\]]+\]?)`)
+		default:
+			expr.WriteString(`(\S*)`)
+		}
+	}
+	expr.WriteString(regexp.QuoteMeta(directive[last:]))
+	expr.WriteString("$")
+
+	pattern, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile log format %q: %w", directive, err)
+	}
+	format.pattern = pattern
+	return format, nil
+}
+
+// Directive returns the format string the parser was compiled from
+func (f *AccessLogFormat) Directive() string {
+	return f.directive
+}
+
+func (f *AccessLogFormat) parse(p *Processor, line, logType string) (*models.LogEntry, error) {
+	match := f.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("invalid %s log format: line does not match %q", logType, f.directive)
+	}
+
+	entry := &models.LogEntry{
+		LogType:   logType,
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	for i, field := range f.fields {
+		value := match[i+1]
+		if strings.Contains(value, `\`) {
+			value = unescapeAccessLogValue(value)
+		}
+		if value == "-" || value == "" {
+			continue
+		}
+		if err := field.apply(p, entry, value); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", field.name, value, err)
+		}
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	return entry, nil
+}
+
+// apacheField maps an Apache format directive, given as its {argument} and
+// letter, to the entry field it fills
+func apacheField(groups []string) (formatField, error) {
+	arg, letter := groups[1], groups[2]
+	name := "%" + letter
+	if arg != "" {
+		name = "%{" + arg + "}" + letter
+	}
+
+	switch letter {
+	case "h", "a":
+		return formatField{name, setSourceIP}, nil
+	case "t":
+		if arg != "" {
+			return formatField{name, setMetadata("time")}, nil
+		}
+		return formatField{name, setApacheTime}, nil
+	case "r":
+		return formatField{name, setRequestLine}, nil
+	case "s":
+		return formatField{name, setStatus}, nil
+	case "b", "B", "O":
+		return formatField{name, setResponseSize}, nil
+	case "D":
+		return formatField{name, setDuration(time.Microsecond)}, nil
+	case "T":
+		switch arg {
+		case "ms":
+			return formatField{name, setDuration(time.Millisecond)}, nil
+		case "us":
+			return formatField{name, setDuration(time.Microsecond)}, nil
+		default:
+			return formatField{name, setDuration(time.Second)}, nil
+		}
+	case "m":
+		return formatField{name, setMethod}, nil
+	case "U":
+		return formatField{name, setPath}, nil
+	case "i":
+		switch strings.ToLower(arg) {
+		case "referer":
+			return formatField{name, setReferer}, nil
+		case "user-agent":
+			return formatField{name, setUserAgent}, nil
+		}
+		return formatField{name, setMetadata(metadataKey(arg))}, nil
+	case "l":
+		return formatField{name, setMetadata("ident")}, nil
+	case "u":
+		return formatField{name, setMetadata("remote_user")}, nil
+	case "v", "V":
+		return formatField{name, setMetadata("server_name")}, nil
+	case "H":
+		return formatField{name, setMetadata("protocol")}, nil
+	case "q":
+		return formatField{name, setMetadata("query")}, nil
+	case "I":
+		return formatField{name, setMetadata("bytes_received")}, nil
+	case "e", "n", "o", "C", "x":
+		if arg == "" {
+			return formatField{}, fmt.Errorf("log format directive %s requires a {name}", name)
+		}
+		return formatField{name, setMetadata(metadataKey(arg))}, nil
+	case "A", "p", "P", "f", "k", "L", "R", "X":
+		return formatField{name, setMetadata(name)}, nil
+	default:
+		return formatField{}, fmt.Errorf("unsupported log format directive: %s", name)
+	}
+}
+
+// nginxField maps an nginx variable to the entry field it fills; unknown
+// variables are kept in metadata under their own name
+func nginxField(groups []string) (formatField, error) {
+	variable := groups[1]
+	if variable == "" {
+		variable = groups[2]
+	}
+	name := "$" + variable
+
+	switch variable {
+	case "remote_addr", "realip_remote_addr":
+		return formatField{name, setSourceIP}, nil
+	case "time_local":
+		return formatField{name, setApacheTime}, nil
+	case "time_iso8601":
+		return formatField{name, setISOTime}, nil
+	case "msec":
+		return formatField{name, setEpochTime}, nil
+	case "request":
+		return formatField{name, setRequestLine}, nil
+	case "status":
+		return formatField{name, setStatus}, nil
+	case "body_bytes_sent", "bytes_sent":
+		return formatField{name, setResponseSize}, nil
+	case "request_time":
+		return formatField{name, setDuration(time.Second)}, nil
+	case "request_method":
+		return formatField{name, setMethod}, nil
+	case "uri", "request_uri":
+		return formatField{name, setPath}, nil
+	case "http_referer":
+		return formatField{name, setReferer}, nil
+	case "http_user_agent":
+		return formatField{name, setUserAgent}, nil
+	case "server_protocol":
+		return formatField{name, setMetadata("protocol")}, nil
+	default:
+		return formatField{name, setMetadata(variable)}, nil
+	}
+}
+
+func setSourceIP(p *Processor, entry *models.LogEntry, value string) error {
+	if p.isValidIP(value) {
+		entry.SourceIP = value
+	} else {
+		// %h is a hostname when HostnameLookups is enabled
+		entry.Metadata["remote_host"] = value
+	}
+	return nil
+}
+
+func setApacheTime(p *Processor, entry *models.LogEntry, value string) error {
+	t, err := p.parseApacheTimestamp(value)
+	if err != nil {
+		return err
+	}
+	entry.Timestamp = t
+	return nil
+}
+
+func setISOTime(p *Processor, entry *models.LogEntry, value string) error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return err
+	}
+	entry.Timestamp = t
+	return nil
+}
+
+func setEpochTime(p *Processor, entry *models.LogEntry, value string) error {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	entry.Timestamp = time.UnixMilli(int64(seconds * 1000)).UTC()
+	return nil
+}
+
+func setRequestLine(p *Processor, entry *models.LogEntry, value string) error {
+	requestParts := strings.Fields(value)
+	if len(requestParts) < 2 {
+		return fmt.Errorf("invalid request format")
+	}
+	entry.Method = requestParts[0]
+	entry.Path = requestParts[1]
+	if len(requestParts) > 2 {
+		entry.Metadata["protocol"] = requestParts[2]
+	}
+	return nil
+}
+
+func setStatus(p *Processor, entry *models.LogEntry, value string) error {
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	entry.StatusCode = code
+	return nil
+}
+
+func setResponseSize(p *Processor, entry *models.LogEntry, value string) error {
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	entry.ResponseSize = size
+	return nil
+}
+
+// setDuration stores a request duration given in unit as ProcessingTime seconds
+func setDuration(unit time.Duration) func(*Processor, *models.LogEntry, string) error {
+	return func(p *Processor, entry *models.LogEntry, value string) error {
+		// nginx logs "0.012, 0.034" when several upstreams were tried
+		value = strings.TrimSpace(strings.Split(value, ",")[0])
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		entry.ProcessingTime = n * unit.Seconds()
+		return nil
+	}
+}
+
+func setMethod(p *Processor, entry *models.LogEntry, value string) error {
+	entry.Method = value
+	return nil
+}
+
+func setPath(p *Processor, entry *models.LogEntry, value string) error {
+	entry.Path = value
+	return nil
+}
+
+func setReferer(p *Processor, entry *models.LogEntry, value string) error {
+	entry.Referer = value
+	return nil
+}
+
+func setUserAgent(p *Processor, entry *models.LogEntry, value string) error {
+	entry.UserAgent = value
+	return nil
+}
+
+func setMetadata(key string) func(*Processor, *models.LogEntry, string) error {
+	return func(p *Processor, entry *models.LogEntry, value string) error {
+		entry.Metadata[key] = convertValue(value)
+		return nil
+	}
+}
+
+// metadataKey turns a header or variable name such as X-Request-ID into x_request_id
+func metadataKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+}
+
+// unescapeAccessLogValue reverses the \" and \\ escaping Apache and nginx
+// apply to quoted values
+func unescapeAccessLogValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && (value[i+1] == '"' || value[i+1] == '\\') {
+			b.WriteByte(value[i+1])
+			i++
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApacheCustomFormat(t *testing.T) {
+	processor := NewProcessor(1)
+	require.NoError(t, processor.SetAccessLogFormat("apache", `%h %l %u %t "%r" %>s %b %D "%{X-Request-ID}i"`))
+
+	line := `192.168.1.100 - frank [10/Oct/2023:13:55:36 +0000] "GET /api/users HTTP/1.1" 200 - 1500 "abc-123"`
+
+	entry, err := processor.parseApacheLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "apache", entry.LogType)
+	assert.Equal(t, "192.168.1.100", entry.SourceIP)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/users", entry.Path)
+	assert.Equal(t, 200, entry.StatusCode)
+	assert.Equal(t, int64(0), entry.ResponseSize)
+	assert.InDelta(t, 0.0015, entry.ProcessingTime, 1e-9)
+	assert.Equal(t, "frank", entry.Metadata["remote_user"])
+	assert.Equal(t, "HTTP/1.1", entry.Metadata["protocol"])
+	assert.Equal(t, "abc-123", entry.Metadata["x_request_id"])
+	assert.Equal(t, line, entry.RawLog)
+}
+
+func TestApacheNamedFormatAndEscapes(t *testing.T) {
+	processor := NewProcessor(1)
+	require.NoError(t, processor.SetAccessLogFormat("apache", "combined"))
+
+	line := `10.0.0.1 - - [10/Oct/2023:13:55:36 +0000] "GET /search?q=a+b HTTP/1.1" 404 512 "-" "curl \"quoted\" agent"`
+
+	entry, err := processor.parseApacheLog(line)
+	require.NoError(t, err)
+	assert.Equal(t, "/search?q=a+b", entry.Path)
+	assert.Equal(t, 404, entry.StatusCode)
+	assert.Equal(t, int64(512), entry.ResponseSize)
+	assert.Empty(t, entry.Referer)
+	assert.Equal(t, `curl "quoted" agent`, entry.UserAgent)
+
+	_, err = processor.parseApacheLog(`10.0.0.1 - - "GET / HTTP/1.1" 200`)
+	assert.Error(t, err)
+}
+
+func TestNginxCustomFormat(t *testing.T) {
+	processor := NewProcessor(1)
+	require.NoError(t, processor.SetAccessLogFormat("nginx",
+		`$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_user_agent" rt=$request_time uct=$upstream_connect_time host=$host`))
+
+	line := `192.168.1.101 - - [10/Oct/2023:13:55:37 +0000] "POST /api/login HTTP/2.0" 401 567 "Mozilla/5.0 (X11)" rt=0.045 uct=0.001 host=shop.example.com`
+
+	entry, err := processor.parseNginxLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "nginx", entry.LogType)
+	assert.Equal(t, "192.168.1.101", entry.SourceIP)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/api/login", entry.Path)
+	assert.Equal(t, 401, entry.StatusCode)
+	assert.Equal(t, int64(567), entry.ResponseSize)
+	assert.Equal(t, "Mozilla/5.0 (X11)", entry.UserAgent)
+	assert.Equal(t, 0.045, entry.ProcessingTime)
+	assert.Equal(t, 0.001, entry.Metadata["upstream_connect_time"])
+	assert.Equal(t, "shop.example.com", entry.Metadata["host"])
+}
+
+func TestNginxISOTimeAndUpstreamList(t *testing.T) {
+	processor := NewProcessor(1)
+	require.NoError(t, processor.SetAccessLogFormat("nginx", `$time_iso8601 $remote_addr $request_method $request_uri $status $request_time`))
+
+	entry, err := processor.parseNginxLog(`2023-10-10T13:55:37+00:00 10.0.0.2 GET /health 200 0.010,0.020`)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 37, 0, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/health", entry.Path)
+	assert.Equal(t, 0.01, entry.ProcessingTime)
+}
+
+func TestCompileAccessLogFormatErrors(t *testing.T) {
+	_, err := CompileAccessLogFormat("apache", "no directives here")
+	assert.Error(t, err)
+
+	_, err = CompileAccessLogFormat("apache", "%h %J")
+	assert.Error(t, err)
+
+	_, err = CompileAccessLogFormat("apache", "%h %e")
+	assert.Error(t, err)
+
+	_, err = CompileAccessLogFormat("generic", "%h")
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package logprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// parseLogfmtLog parses logfmt lines such as
+// ts=2023-10-10T13:55:38Z level=info msg="user login" user_id=42
+func (p *Processor) parseLogfmtLog(line string) (*models.LogEntry, error) {
+	pairs, err := splitLogfmt(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid logfmt format: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("invalid logfmt format: no key=value pairs found")
+	}
+
+	entry := &models.LogEntry{
+		LogType:   "logfmt",
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	var message string
+	for _, pair := range pairs {
+		switch pair.key {
+		case "ts", "time", "timestamp":
+			if t, err := p.parseLogfmtTimestamp(pair.value); err == nil {
+				entry.Timestamp = t
+				continue
+			}
+		case "msg", "message":
+			message = pair.value
+			continue
+		case "level", "lvl", "severity":
+			entry.Metadata["level"] = strings.ToLower(pair.value)
+			continue
+		case "method":
+			entry.Method = strings.ToUpper(pair.value)
+			continue
+		case "path", "uri", "url":
+			entry.Path = pair.value
+			continue
+		case "status", "status_code":
+			if code, err := strconv.Atoi(pair.value); err == nil {
+				entry.StatusCode = code
+				continue
+			}
+		case "ip", "remote_addr", "client_ip", "source_ip":
+			if p.isValidIP(pair.value) {
+				entry.SourceIP = pair.value
+				continue
+			}
+		case "duration", "latency", "elapsed":
+			if d, err := time.ParseDuration(pair.value); err == nil {
+				entry.ProcessingTime = d.Seconds()
+				continue
+			}
+			if f, err := strconv.ParseFloat(pair.value, 64); err == nil {
+				entry.ProcessingTime = f
+				continue
+			}
+		}
+
+		if pair.quoted {
+			entry.Metadata[pair.key] = pair.value
+		} else {
+			entry.Metadata[pair.key] = convertValue(pair.value)
+		}
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	// Like the generic parser, the message lives in the path field unless the
+	// line carried an explicit request path
+	if entry.Path == "" {
+		entry.Path = message
+	} else if message != "" {
+		entry.Metadata["msg"] = message
+	}
+
+	return entry, nil
+}
+
+func (p *Processor) parseLogfmtTimestamp(value string) (time.Time, error) {
+	for _, format := range []string{time.RFC3339Nano, time.RFC3339} {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	return p.parseGenericTimestamp(value)
+}
+
+type logfmtPair struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+// splitLogfmt tokenizes a logfmt line, honouring double-quoted values with
+// backslash escapes. Bare keys without a value are recorded as "true".
+func splitLogfmt(line string) ([]logfmtPair, error) {
+	var pairs []logfmtPair
+	i := 0
+	n := len(line)
+
+	for i < n {
+		for i < n && line[i] == ' ' {
+			i++
+		}
+		if i >= n {
+			break
+		}
+
+		start := i
+		for i < n && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("empty key at position %d", start)
+		}
+
+		if i >= n || line[i] == ' ' {
+			pairs = append(pairs, logfmtPair{key: key, value: "true"})
+			continue
+		}
+		i++ // skip '='
+
+		if i < n && line[i] == '"' {
+			i++
+			var value strings.Builder
+			closed := false
+			for i < n {
+				c := line[i]
+				if c == '\\' && i+1 < n {
+					switch line[i+1] {
+					case 'n':
+						value.WriteByte('\n')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(line[i+1])
+					}
+					i += 2
+					continue
+				}
+				if c == '"' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteByte(c)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value for key %q", key)
+			}
+			pairs = append(pairs, logfmtPair{key: key, value: value.String(), quoted: true})
+			continue
+		}
+
+		start = i
+		for i < n && line[i] != ' ' {
+			i++
+		}
+		pairs = append(pairs, logfmtPair{key: key, value: line[start:i]})
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,66 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogfmtLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `ts=2023-10-10T13:55:38.123Z level=INFO msg="user login \"ok\"" user_id=12345 ratio=0.5 cached`
+
+	entry, err := processor.parseLogfmtLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "logfmt", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 38, 123000000, time.UTC), entry.Timestamp)
+	assert.Equal(t, `user login "ok"`, entry.Path)
+	assert.Equal(t, "info", entry.Metadata["level"])
+	assert.Equal(t, 12345, entry.Metadata["user_id"])
+	assert.Equal(t, 0.5, entry.Metadata["ratio"])
+	assert.Equal(t, true, entry.Metadata["cached"])
+	assert.Equal(t, line, entry.RawLog)
+}
+
+func TestParseLogfmtLogRequestFields(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `time=2023-10-10T13:55:38Z level=warn msg="slow request" method=get path=/api/users status=503 remote_addr=10.0.0.5 duration=1.5s id="007"`
+
+	entry, err := processor.parseLogfmtLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/users", entry.Path)
+	assert.Equal(t, 503, entry.StatusCode)
+	assert.Equal(t, "10.0.0.5", entry.SourceIP)
+	assert.Equal(t, 1.5, entry.ProcessingTime)
+	assert.Equal(t, "slow request", entry.Metadata["msg"])
+	// Quoted values are kept verbatim
+	assert.Equal(t, "007", entry.Metadata["id"])
+}
+
+func TestParseLogfmtLogInvalid(t *testing.T) {
+	processor := NewProcessor(1)
+
+	_, err := processor.parseLogfmtLog(`level=info msg="unterminated`)
+	assert.Error(t, err)
+
+	_, err = processor.parseLogfmtLog(`=value`)
+	assert.Error(t, err)
+}
+
+func TestSplitLogfmt(t *testing.T) {
+	pairs, err := splitLogfmt(`a=1 b="two words" c= d`)
+	require.NoError(t, err)
+	require.Len(t, pairs, 4)
+
+	assert.Equal(t, logfmtPair{key: "a", value: "1"}, pairs[0])
+	assert.Equal(t, logfmtPair{key: "b", value: "two words", quoted: true}, pairs[1])
+	assert.Equal(t, logfmtPair{key: "c", value: ""}, pairs[2])
+	assert.Equal(t, logfmtPair{key: "d", value: "true"}, pairs[3])
+}
@@ -0,0 +1,104 @@
+package logprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// parseLTSVLog parses Labeled Tab-Separated Values lines as emitted by
+// nginx/apache LTSV log formats, e.g.
+// time:[10/Oct/2023:13:55:36 +0000]\thost:192.168.1.100\treq:GET / HTTP/1.1\tstatus:200
+func (p *Processor) parseLTSVLog(line string) (*models.LogEntry, error) {
+	fields := strings.Split(line, "\t")
+
+	entry := &models.LogEntry{
+		LogType:   "ltsv",
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	labelCount := 0
+	for _, field := range fields {
+		label, value, ok := strings.Cut(field, ":")
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid LTSV format: field %q is not label:value", field)
+		}
+		labelCount++
+
+		// "-" is the conventional placeholder for an empty value
+		if value == "-" {
+			continue
+		}
+
+		switch label {
+		case "time":
+			t, err := p.parseLTSVTimestamp(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp: %w", err)
+			}
+			entry.Timestamp = t
+		case "host", "remote_addr":
+			if !p.isValidIP(value) {
+				return nil, fmt.Errorf("invalid IP address: %s", value)
+			}
+			entry.SourceIP = value
+		case "req":
+			requestParts := strings.Fields(value)
+			if len(requestParts) < 2 {
+				return nil, fmt.Errorf("invalid request format: %s", value)
+			}
+			entry.Method = requestParts[0]
+			entry.Path = requestParts[1]
+		case "method":
+			entry.Method = value
+		case "uri":
+			entry.Path = value
+		case "status":
+			statusCode, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code: %s", value)
+			}
+			entry.StatusCode = statusCode
+		case "size":
+			entry.ResponseSize, _ = strconv.ParseInt(value, 10, 64)
+		case "ua":
+			entry.UserAgent = value
+		case "referer":
+			entry.Referer = value
+		case "reqtime":
+			entry.ProcessingTime, _ = strconv.ParseFloat(value, 64)
+		default:
+			entry.Metadata[label] = convertValue(value)
+		}
+	}
+
+	if labelCount == 0 {
+		return nil, fmt.Errorf("invalid LTSV format: no labels found")
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	return entry, nil
+}
+
+func (p *Processor) parseLTSVTimestamp(value string) (time.Time, error) {
+	if strings.HasPrefix(value, "[") {
+		return p.parseApacheTimestamp(value)
+	}
+	for _, format := range []string{time.RFC3339Nano, time.RFC3339} {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := p.parseApacheTimestamp(value); err == nil {
+		return t, nil
+	}
+	return p.parseGenericTimestamp(value)
+}
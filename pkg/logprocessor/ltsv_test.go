@@ -0,0 +1,58 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLTSVLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := "time:[10/Oct/2023:13:55:36 +0000]\thost:192.168.1.100\treq:GET /api/users HTTP/1.1\tstatus:200\tsize:1234\treferer:https://example.com\tua:Mozilla/5.0 (X11; Linux x86_64)\treqtime:0.045\tvhost:example.com"
+
+	entry, err := processor.parseLTSVLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ltsv", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, "192.168.1.100", entry.SourceIP)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/users", entry.Path)
+	assert.Equal(t, 200, entry.StatusCode)
+	assert.Equal(t, int64(1234), entry.ResponseSize)
+	assert.Equal(t, "https://example.com", entry.Referer)
+	assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64)", entry.UserAgent)
+	assert.Equal(t, 0.045, entry.ProcessingTime)
+	assert.Equal(t, "example.com", entry.Metadata["vhost"])
+}
+
+func TestParseLTSVLogPlaceholdersAndISOTime(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := "time:2023-10-10T13:55:36Z\thost:10.0.0.1\tmethod:POST\turi:/login\tstatus:401\treferer:-\tua:-"
+
+	entry, err := processor.parseLTSVLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/login", entry.Path)
+	assert.Equal(t, 401, entry.StatusCode)
+	assert.Empty(t, entry.Referer)
+	assert.Empty(t, entry.UserAgent)
+}
+
+func TestParseLTSVLogInvalid(t *testing.T) {
+	processor := NewProcessor(1)
+
+	_, err := processor.parseLTSVLog("not an ltsv line")
+	assert.Error(t, err)
+
+	_, err = processor.parseLTSVLog("host:999.1.1.1\tstatus:200")
+	assert.Error(t, err)
+
+	_, err = processor.parseLTSVLog("host:10.0.0.1\tstatus:abc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package logprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// csvParser is a downstream parser for "timestamp,ip,path" lines
+type csvParser struct{}
+
+func (csvParser) Name() string { return "csv" }
+
+func (csvParser) Parse(line string) (*models.LogEntry, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected 3 fields")
+	}
+	return &models.LogEntry{LogType: "csv", SourceIP: fields[1], Path: fields[2], RawLog: line}, nil
+}
+
+// blockParser reads multi-line records separated by "---"
+type blockParser struct{ csvParser }
+
+func (blockParser) Name() string { return "block" }
+
+func (blockParser) SplitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.Index(data, []byte("---")); i >= 0 {
+		return i + 3, bytes.TrimSpace(data[:i]), nil
+	}
+	if atEOF && len(bytes.TrimSpace(data)) > 0 {
+		return len(data), bytes.TrimSpace(data), nil
+	}
+	return 0, nil, nil
+}
+
+func (blockParser) Parse(record string) (*models.LogEntry, error) {
+	return csvParser{}.Parse(strings.ReplaceAll(record, "\n", ","))
+}
+
+func drain(p *Processor) []*models.LogEntry {
+	var entries []*models.LogEntry
+	for {
+		select {
+		case entry := <-p.GetProcessedLogs():
+			entries = append(entries, entry)
+		default:
+			return entries
+		}
+	}
+}
+
+func TestBuiltinParsersRegistered(t *testing.T) {
+	processor := NewProcessor(1)
+	assert.Equal(t, SupportedLogTypes, processor.LogTypes())
+
+	parser, ok := processor.parsers.Parser("windows_event")
+	require.True(t, ok)
+	_, splits := parser.(RecordSplitter)
+	assert.True(t, splits)
+}
+
+func TestRegisterParser(t *testing.T) {
+	processor := NewProcessor(2)
+	require.NoError(t, processor.RegisterParser(csvParser{}))
+	assert.True(t, processor.SupportsLogType("csv"))
+	assert.Equal(t, "csv", processor.LogTypes()[len(processor.LogTypes())-1])
+
+	err := processor.ProcessFile(strings.NewReader("2023-10-10,10.0.0.1,/a\n2023-10-10,10.0.0.2,/b\n"), "csv")
+	require.NoError(t, err)
+	entries := drain(processor)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "csv", entries[0].LogType)
+
+	assert.ErrorContains(t, processor.RegisterParser(csvParser{}), "already registered")
+	assert.ErrorContains(t, processor.RegisterParser(NewParser("apache", csvParser{}.Parse)), "already registered")
+	assert.ErrorContains(t, processor.RegisterParser(NewParser("", csvParser{}.Parse)), "name is required")
+}
+
+func TestRegisteredParserSplitsRecords(t *testing.T) {
+	processor := NewProcessor(1)
+	require.NoError(t, processor.RegisterParser(blockParser{}))
+
+	input := "2023-10-10\n10.0.0.1\n/a\n---\n2023-10-10\n10.0.0.2\n/b\n"
+	require.NoError(t, processor.ProcessFile(bufio.NewReader(strings.NewReader(input)), "block"))
+
+	entries := drain(processor)
+	require.Len(t, entries, 2)
+	paths := []string{entries[0].Path, entries[1].Path}
+	assert.ElementsMatch(t, []string{"/a", "/b"}, paths)
+}
+
+func TestProcessFileUnsupportedLogType(t *testing.T) {
+	processor := NewProcessor(1)
+	err := processor.ProcessFile(strings.NewReader("line\n"), "csv")
+	assert.ErrorContains(t, err, "unsupported log type: csv")
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
@@ -21,10 +22,17 @@ type Processor struct {
 	processedLogs chan *models.LogEntry
 	// Channel for errors
 	errors chan error
-	// Worker pool for concurrent processing
+	// Worker pool for concurrent processing, replaced by SetWorkers
 	workerPool chan struct{}
+	// Work done since the last TakeLoad
+	load loadCounters
 	// Statistics
 	stats *ProcessingStats
+	// Custom access log formats by log type, replacing the built-in
+	// combined format parsers
+	accessFormats map[string]*AccessLogFormat
+	// Parsers by log type, built-in and registered
+	parsers *Registry
 }
 
 // ProcessingStats tracks processing statistics
@@ -38,19 +46,129 @@ type ProcessingStats struct {
 	StartTime       time.Time
 }
 
+// DefaultQueueSize is how many processed entries wait for storing before
+// parsing waits too, unless NewProcessorWithQueue sets another size
+const DefaultQueueSize = 1000
+
 func NewProcessor(workerCount int) *Processor {
-	return &Processor{
-		processedLogs: make(chan *models.LogEntry, 1000),
+	return NewProcessorWithQueue(workerCount, DefaultQueueSize)
+}
+
+// NewProcessorWithQueue returns a processor whose queue of processed
+// entries holds queueSize of them. Once it is full, ProcessFile and Submit
+// wait for the consumer of GetProcessedLogs, so a large file is read no
+// faster than it is stored and memory use stays bounded.
+func NewProcessorWithQueue(workerCount, queueSize int) *Processor {
+	p := &Processor{
+		processedLogs: make(chan *models.LogEntry, queueSize),
 		errors:        make(chan error, 100),
 		workerPool:    make(chan struct{}, workerCount),
 		stats: &ProcessingStats{
 			StartTime: time.Now(),
 		},
+		accessFormats: make(map[string]*AccessLogFormat),
+		parsers:       NewRegistry(),
+	}
+	for _, parser := range p.builtinParsers() {
+		if err := p.parsers.Register(parser); err != nil {
+			panic(err)
+		}
+	}
+	return p
+}
+
+// SetAccessLogFormat makes the apache or nginx parser use the given
+// LogFormat/log_format directive instead of the combined log format
+func (p *Processor) SetAccessLogFormat(logType, directive string) error {
+	format, err := CompileAccessLogFormat(logType, directive)
+	if err != nil {
+		return err
 	}
+
+	p.mu.Lock()
+	p.accessFormats[logType] = format
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Processor) accessFormat(logType string) *AccessLogFormat {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.accessFormats[logType]
 }
 
 // ProcessFile processes a log file with the specified format
 func (p *Processor) ProcessFile(reader io.Reader, logType string) error {
+	return p.ProcessFileWithMetadata(reader, logType, nil)
+}
+
+// ProcessFileWithMetadata processes a log file like ProcessFile and adds
+// metadata to every entry, such as where the file came from. Fields the
+// parser extracted are not replaced.
+func (p *Processor) ProcessFileWithMetadata(reader io.Reader, logType string, metadata models.LogMetadata) error {
+	_, err := p.ProcessFileWithOptions(reader, logType, FileOptions{Metadata: metadata})
+	return err
+}
+
+// FileOptions change how ProcessFileWithOptions processes a file
+type FileOptions struct {
+	// Metadata is added to every entry as ProcessFileWithMetadata does
+	Metadata models.LogMetadata
+	// MaxErrorRate aborts processing once more than this percentage of
+	// the records read failed to parse, checked from ErrorRateLines
+	// records on, so a file of the wrong type or not a log at all is not
+	// read to its end. 0 never aborts.
+	MaxErrorRate   float64
+	ErrorRateLines int
+	// FileHash identifies the file being read. When set, every entry
+	// carries it and the number of the record it was parsed from,
+	// counting non-blank records from 1, so storage skips the records of
+	// the file it stored already.
+	FileHash string
+	// Progress, when set, is called with the counts so far every
+	// ProgressLines records read and once the file is done
+	Progress      func(FileResult)
+	ProgressLines int
+	// Queued, when set, is called with every entry before it is queued
+	// for storing
+	Queued func(*models.LogEntry)
+}
+
+// defaultProgressLines is how often Progress is called when
+// ProgressLines is not set
+const defaultProgressLines = 1000
+
+// FileResult counts the records of a processed file
+type FileResult struct {
+	Lines   int64 `json:"lines"`
+	Entries int64 `json:"entries"` // entries queued for storing
+	Errors  int64 `json:"errors"`  // records that failed to parse
+}
+
+// ErrorRateError is returned when processing was aborted for too many
+// records failing to parse
+type ErrorRateError struct {
+	Lines        int64
+	Errors       int64
+	MaxErrorRate float64
+}
+
+func (e *ErrorRateError) Error() string {
+	return fmt.Sprintf("aborted after %d lines: %d (%.1f%%) failed to parse, more than the %g%% allowed; check the log type",
+		e.Lines, e.Errors, 100*float64(e.Errors)/float64(e.Lines), e.MaxErrorRate)
+}
+
+// ProcessFileWithOptions processes a log file like ProcessFile and
+// reports how many of its records were read and failed to parse. Entries
+// parsed before processing is aborted for its error rate are kept.
+func (p *Processor) ProcessFileWithOptions(reader io.Reader, logType string, opts FileOptions) (FileResult, error) {
+	metadata := opts.Metadata
+	var result FileResult
+	parser, ok := p.parsers.Parser(logType)
+	if !ok {
+		return result, fmt.Errorf("unsupported log type: %s", logType)
+	}
+
 	scanner := bufio.NewScanner(reader)
 	
 	// Use a larger buffer for long log lines
@@ -58,8 +176,27 @@ func (p *Processor) ProcessFile(reader io.Reader, logType string) error {
 	buf := make([]byte, maxCapacity)
 	scanner.Buffer(buf, maxCapacity)
 
+	// Some formats have records spanning several lines
+	if splitter, ok := parser.(RecordSplitter); ok {
+		scanner.Split(splitter.SplitRecords)
+	}
+
 	var wg sync.WaitGroup
 	lineCount := 0
+	// Records parsed so far, those among them that failed and the entries
+	// queued for storing
+	var parsed, failed, queued atomic.Int64
+	progressLines := opts.ProgressLines
+	if progressLines <= 0 {
+		progressLines = defaultProgressLines
+	}
+	finish := func() {
+		wg.Wait()
+		result.Lines, result.Entries, result.Errors = int64(lineCount), queued.Load(), failed.Load()
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -67,56 +204,132 @@ func (p *Processor) ProcessFile(reader io.Reader, logType string) error {
 			continue
 		}
 
+		if opts.MaxErrorRate > 0 {
+			done, errs := parsed.Load(), failed.Load()
+			if done >= int64(opts.ErrorRateLines) && float64(errs) > opts.MaxErrorRate/100*float64(done) {
+				finish()
+				return result, &ErrorRateError{Lines: result.Lines, Errors: result.Errors, MaxErrorRate: opts.MaxErrorRate}
+			}
+		}
+
+		if opts.Progress != nil && lineCount > 0 && lineCount%progressLines == 0 {
+			opts.Progress(FileResult{Lines: int64(lineCount), Entries: queued.Load(), Errors: failed.Load()})
+		}
+
 		lineCount++
 		wg.Add(1)
 
 		// Acquire worker slot
-		p.workerPool <- struct{}{}
+		pool := p.pool()
+		pool <- struct{}{}
+		acquired := time.Now()
 
 		go func(line string, lineNum int) {
 			defer wg.Done()
-			defer func() { <-p.workerPool }()
-
-			entry, err := p.parseLogLine(line, logType)
+			defer func() {
+				p.load.busy.Add(int64(time.Since(acquired)))
+				<-pool
+			}()
+
+			start := time.Now()
+			entry, err := parser.Parse(line)
+			p.load.parsed(time.Since(start))
+			defer parsed.Add(1)
 			if err != nil {
-				p.errors <- fmt.Errorf("line %d: %w", lineNum, err)
+				failed.Add(1)
+				// Errors nobody reads are dropped rather than stalling parsing
+				select {
+				case p.errors <- fmt.Errorf("line %d: %w", lineNum, err):
+				default:
+				}
 				p.stats.incrementErrors()
 				return
 			}
 
 			if entry != nil {
+				if len(metadata) > 0 && entry.Metadata == nil {
+					entry.Metadata = make(models.LogMetadata, len(metadata))
+				}
+				for key, value := range metadata {
+					if _, exists := entry.Metadata[key]; !exists {
+						entry.Metadata[key] = value
+					}
+				}
+				if opts.FileHash != "" {
+					entry.FileHash, entry.LineNumber = opts.FileHash, int64(lineNum)
+				}
+				if opts.Queued != nil {
+					opts.Queued(entry)
+				}
 				p.processedLogs <- entry
+				queued.Add(1)
 				p.stats.incrementProcessed(logType)
 			}
 		}(line, lineCount)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file: %w", err)
+		finish()
+		return result, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// Wait for all workers to complete
-	wg.Wait()
+	finish()
 
-	return nil
+	return result, nil
+}
+
+// SupportedLogTypes lists the built-in log types; a Processor also accepts
+// those added with RegisterParser
+var SupportedLogTypes = []string{"apache", "nginx", "generic", "logfmt", "ltsv", "cef", "leef", "docker", "kubernetes", "windows_event", "envoy", "traefik", "aws_vpc_flow", "syslog"}
+
+// MessageLogTypes lists the application log types whose entries carry a
+// free-text message (stored in Path) rather than a request path. "loki",
+// "otlp" and "hec" hold lines pushed through the Loki API, records
+// exported over OTLP and HEC events received without a parser.
+var MessageLogTypes = []string{"generic", "logfmt", "docker", "kubernetes", "windows_event", "syslog", "loki", "otlp", "hec"}
+
+// IsMessageLogType reports whether entries of logType carry a free-text message
+func IsMessageLogType(logType string) bool {
+	for _, t := range MessageLogTypes {
+		if t == logType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLine parses one line with the parser registered for the log type,
+// for records that arrive on their own rather than in a file
+func (p *Processor) ParseLine(line, logType string) (*models.LogEntry, error) {
+	return p.parseLogLine(line, logType)
+}
+
+// Submit queues entries parsed outside ProcessFile for storing, waiting
+// while the queue is full
+func (p *Processor) Submit(entries []*models.LogEntry) {
+	for _, entry := range entries {
+		p.processedLogs <- entry
+		p.stats.incrementProcessed(entry.LogType)
+	}
 }
 
-// parseLogLine parses a single log line based on the log type
+// parseLogLine parses a single log line with the parser registered for
+// the log type
 func (p *Processor) parseLogLine(line, logType string) (*models.LogEntry, error) {
-	switch logType {
-	case "apache":
-		return p.parseApacheLog(line)
-	case "nginx":
-		return p.parseNginxLog(line)
-	case "generic":
-		return p.parseGenericLog(line)
-	default:
+	parser, ok := p.parsers.Parser(logType)
+	if !ok {
 		return nil, fmt.Errorf("unsupported log type: %s", logType)
 	}
+	return parser.Parse(line)
 }
 
 // parseApacheLog parses Apache access log format
 func (p *Processor) parseApacheLog(line string) (*models.LogEntry, error) {
+	if format := p.accessFormat("apache"); format != nil {
+		return format.parse(p, line, "apache")
+	}
+
 	// Apache Combined Log Format:
 	// %h %l %u %t \"%r\" %>s %b \"%{Referer}i\" \"%{User-Agent}i\"
 	
@@ -182,6 +395,10 @@ func (p *Processor) parseApacheLog(line string) (*models.LogEntry, error) {
 
 // parseNginxLog parses Nginx access log format
 func (p *Processor) parseNginxLog(line string) (*models.LogEntry, error) {
+	if format := p.accessFormat("nginx"); format != nil {
+		return format.parse(p, line, "nginx")
+	}
+
 	// Nginx Combined Log Format:
 	// $remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent" "$request_time"
 	
@@ -266,11 +483,13 @@ func (p *Processor) parseGenericLog(line string) (*models.LogEntry, error) {
 		timestamp = time.Now()
 	}
 
-	_ = parts[2] // level
 	message := strings.Join(parts[3:], " ")
 
 	// Extract key-value pairs from message
 	metadata := p.extractKeyValuePairs(message)
+	if _, ok := metadata["level"]; !ok {
+		metadata["level"] = strings.ToLower(parts[2])
+	}
 
 	entry := &models.LogEntry{
 		Timestamp: timestamp,
@@ -389,31 +608,37 @@ func (p *Processor) extractKeyValuePairs(message string) models.LogMetadata {
 	
 	for _, match := range matches {
 		if len(match) == 3 {
-			key := match[1]
-			value := match[2]
-			
-			// Try to convert to appropriate type
-			if intVal, err := strconv.Atoi(value); err == nil {
-				metadata[key] = intVal
-			} else if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
-				metadata[key] = floatVal
-			} else if boolVal, err := strconv.ParseBool(value); err == nil {
-				metadata[key] = boolVal
-			} else {
-				metadata[key] = value
-			}
+			metadata[match[1]] = convertValue(match[2])
 		}
 	}
 	
 	return metadata
 }
 
+// convertValue converts a raw string value to an int, float or bool when possible
+func convertValue(value string) interface{} {
+	if intVal, err := strconv.Atoi(value); err == nil {
+		return intVal
+	} else if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+		return floatVal
+	} else if boolVal, err := strconv.ParseBool(value); err == nil {
+		return boolVal
+	}
+	return value
+}
+
 // GetProcessedLogs returns the channel for processed log entries
 func (p *Processor) GetProcessedLogs() <-chan *models.LogEntry {
 	return p.processedLogs
 }
 
-// GetErrors returns the channel for processing errors
+// QueueSize is how many processed entries can wait for storing
+func (p *Processor) QueueSize() int {
+	return cap(p.processedLogs)
+}
+
+// GetErrors returns the channel for processing errors. Errors are dropped
+// while it is full.
 func (p *Processor) GetErrors() <-chan error {
 	return p.errors
 }
@@ -437,7 +662,7 @@ func (p *Processor) GetStats() *ProcessingStats {
 func (p *Processor) Close() {
 	close(p.processedLogs)
 	close(p.errors)
-	close(p.workerPool)
+	close(p.pool())
 }
 
 // Stats methods
@@ -1,10 +1,13 @@
 package logprocessor
 
 import (
+	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -76,6 +79,7 @@ func TestParseGenericLog(t *testing.T) {
 	assert.Contains(t, entry.Metadata, "ip")
 	assert.Equal(t, 12345, entry.Metadata["user_id"])
 	assert.Equal(t, "192.168.1.102", entry.Metadata["ip"])
+	assert.Equal(t, "info", entry.Metadata["level"])
 }
 
 func TestParseLogLineInvalidType(t *testing.T) {
@@ -309,3 +313,127 @@ func BenchmarkParseGenericLog(b *testing.B) {
 		}
 	}
 }
+
+func TestProcessFileWaitsForFullQueue(t *testing.T) {
+	processor := NewProcessorWithQueue(2, 5)
+	assert.Equal(t, 5, processor.QueueSize())
+
+	line := `192.168.1.1 - - [25/Dec/2023:10:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`
+	done := make(chan error, 1)
+	go func() {
+		done <- processor.ProcessFile(strings.NewReader(strings.Repeat(line+"\n", 50)), "nginx")
+	}()
+
+	// Nothing is stored, so parsing stops once the queue is full
+	select {
+	case err := <-done:
+		t.Fatalf("ProcessFile returned with a full queue: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 5, len(processor.GetProcessedLogs()))
+
+	received := 0
+	for received < 50 {
+		select {
+		case <-processor.GetProcessedLogs():
+			received++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of 50 entries", received)
+		}
+	}
+	require.NoError(t, <-done)
+}
+
+func TestProcessFileWithUnreadErrors(t *testing.T) {
+	processor := NewProcessor(1)
+
+	// More invalid lines than the error channel holds
+	done := make(chan error, 1)
+	go func() {
+		done <- processor.ProcessFile(strings.NewReader(strings.Repeat("not a log line\n", 500)), "nginx")
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ProcessFile stalled on errors nobody reads")
+	}
+	assert.Equal(t, int64(500), processor.GetStats().Errors)
+}
+
+func TestProcessFileAbortsOnErrorRate(t *testing.T) {
+	processor := NewProcessor(4)
+	opts := FileOptions{MaxErrorRate: 50, ErrorRateLines: 100}
+
+	result, err := processor.ProcessFileWithOptions(strings.NewReader(strings.Repeat("\x00\x01binary\n", 10000)), "nginx", opts)
+	var rateErr *ErrorRateError
+	require.ErrorAs(t, err, &rateErr)
+	assert.Less(t, result.Lines, int64(1000), "stops soon after the first 100 lines")
+	assert.Equal(t, result.Lines, result.Errors)
+	assert.Equal(t, result.Lines, rateErr.Lines)
+	assert.Contains(t, err.Error(), "check the log type")
+
+	// A few bad lines among good ones are reported but do not abort
+	line := `192.168.1.1 - - [25/Dec/2023:10:00:00 +0000] "GET /index.html HTTP/1.1" 200 1024 "-" "curl/8.0"` + "\n"
+	input := strings.Repeat(strings.Repeat(line, 3)+"garbage\n", 50)
+	go func() {
+		for range processor.GetProcessedLogs() {
+		}
+	}()
+	result, err = processor.ProcessFileWithOptions(strings.NewReader(input), "nginx", opts)
+	require.NoError(t, err)
+	assert.Equal(t, FileResult{Lines: 200, Entries: 150, Errors: 50}, result)
+}
+
+func TestProcessFileReportsProgress(t *testing.T) {
+	processor := NewProcessor(4)
+	line := `192.168.1.1 - - [25/Dec/2023:10:00:00 +0000] "GET /index.html HTTP/1.1" 200 1024 "-" "curl/8.0"` + "\n"
+	input := strings.Repeat(strings.Repeat(line, 3)+"garbage\n", 25)
+	go func() {
+		for range processor.GetProcessedLogs() {
+		}
+	}()
+
+	var progress []FileResult
+	opts := FileOptions{ProgressLines: 40, Progress: func(result FileResult) {
+		progress = append(progress, result)
+	}}
+	result, err := processor.ProcessFileWithOptions(strings.NewReader(input), "nginx", opts)
+	require.NoError(t, err)
+
+	// Every 40 lines read, then once done
+	require.Len(t, progress, 3)
+	assert.Equal(t, int64(40), progress[0].Lines)
+	assert.Equal(t, int64(80), progress[1].Lines)
+	assert.LessOrEqual(t, progress[0].Entries+progress[0].Errors, progress[0].Lines)
+	assert.Equal(t, FileResult{Lines: 100, Entries: 75, Errors: 25}, progress[2])
+	assert.Equal(t, result, progress[2])
+
+	// Queued sees every entry before storing does
+	var queued atomic.Int64
+	opts = FileOptions{Queued: func(entry *models.LogEntry) {
+		queued.Add(1)
+	}}
+	result, err = processor.ProcessFileWithOptions(strings.NewReader(input), "nginx", opts)
+	require.NoError(t, err)
+	assert.Equal(t, result.Entries, queued.Load())
+}
+
+func TestProcessFileNumbersLines(t *testing.T) {
+	processor := NewProcessor(4)
+	line := `192.168.1.1 - - [25/Dec/2023:10:00:00 +0000] "GET /page/%d HTTP/1.1" 200 1024 "-" "curl/8.0"` + "\n"
+	input := fmt.Sprintf(line, 1) + "\n" + fmt.Sprintf(line, 2) + "garbage\n" + fmt.Sprintf(line, 4)
+
+	_, err := processor.ProcessFileWithOptions(strings.NewReader(input), "nginx", FileOptions{FileHash: "abc"})
+	require.NoError(t, err)
+
+	// Blank lines are not counted
+	lines := map[string]int64{}
+	for i := 0; i < 3; i++ {
+		entry := <-processor.GetProcessedLogs()
+		assert.Equal(t, "abc", entry.FileHash)
+		lines[entry.Path] = entry.LineNumber
+	}
+	assert.Equal(t, map[string]int64{"/page/1": 1, "/page/2": 2, "/page/4": 4}, lines)
+}
@@ -0,0 +1,155 @@
+package logprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// quotedValue matches a double-quoted access log value, allowing escapes
+const quotedValue = `"((?:[^"\\]|\\.)*)"`
+
+// envoyLogPattern matches Envoy's default access log format:
+// [%START_TIME%] "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%"
+// %RESPONSE_CODE% %RESPONSE_FLAGS% %BYTES_RECEIVED% %BYTES_SENT% %DURATION%
+// %RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)% "%REQ(X-FORWARDED-FOR)%" "%REQ(USER-AGENT)%"
+// "%REQ(X-REQUEST-ID)%" "%REQ(:AUTHORITY)%" "%UPSTREAM_HOST%"
+// Fields appended after these, as mesh configurations often do, are ignored.
+var envoyLogPattern = regexp.MustCompile(`^\[([^\]]+)\] ` + quotedValue +
+	` (\d+) (\S+) (\d+) (\d+) (\d+) (\S+) ` +
+	quotedValue + ` ` + quotedValue + ` ` + quotedValue + ` ` + quotedValue + ` ` + quotedValue +
+	`(?:\s.*)?$`)
+
+// traefikLogPattern matches Traefik's common log format with its extra fields:
+// <client> - <user> [<time>] "<request>" <status> <size> "<referer>" "<user agent>"
+// <request count> "<router>" "<server URL>" <duration>ms
+var traefikLogPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] ` + quotedValue +
+	` (\d+) (\S+) ` + quotedValue + ` ` + quotedValue +
+	` (\d+) ` + quotedValue + ` ` + quotedValue + ` (\d+(?:\.\d+)?)ms\s*$`)
+
+// parseEnvoyLog parses Envoy proxy access logs in the default format
+func (p *Processor) parseEnvoyLog(line string) (*models.LogEntry, error) {
+	m := envoyLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("invalid Envoy log format")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, m[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	entry := newProxyEntry("envoy", line, timestamp)
+	applyProxyRequestLine(entry, m[2])
+
+	if entry.StatusCode, err = strconv.Atoi(m[3]); err != nil {
+		return nil, fmt.Errorf("invalid status code: %w", err)
+	}
+	// Envoy logs 0 when no response was sent (reset or timeout); the
+	// response flags say why
+	setProxyMetadata(entry, "response_flags", m[4])
+	setProxyMetadata(entry, "bytes_received", m[5])
+	entry.ResponseSize, _ = strconv.ParseInt(m[6], 10, 64)
+
+	duration, _ := strconv.ParseFloat(m[7], 64)
+	entry.ProcessingTime = duration / 1000
+	if upstream, err := strconv.ParseFloat(m[8], 64); err == nil {
+		entry.Metadata["upstream_response_time"] = upstream / 1000
+	}
+
+	// The client is the first hop recorded in X-Forwarded-For
+	forwardedFor := unescapeAccessLogValue(m[9])
+	if client := strings.TrimSpace(strings.Split(forwardedFor, ",")[0]); p.isValidIP(client) {
+		entry.SourceIP = client
+	}
+	setProxyMetadata(entry, "forwarded_for", forwardedFor)
+	entry.UserAgent = proxyValue(m[10])
+	setProxyMetadata(entry, "request_id", m[11])
+	setProxyMetadata(entry, "host", m[12])
+	setProxyMetadata(entry, "upstream_host", m[13])
+
+	return entry, nil
+}
+
+// parseTraefikLog parses Traefik access logs in the common log format
+func (p *Processor) parseTraefikLog(line string) (*models.LogEntry, error) {
+	m := traefikLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("invalid Traefik log format")
+	}
+
+	timestamp, err := p.parseApacheTimestamp(m[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	entry := newProxyEntry("traefik", line, timestamp)
+	if p.isValidIP(m[1]) {
+		entry.SourceIP = m[1]
+	} else {
+		setProxyMetadata(entry, "remote_host", m[1])
+	}
+	setProxyMetadata(entry, "remote_user", m[2])
+	applyProxyRequestLine(entry, m[4])
+
+	if entry.StatusCode, err = strconv.Atoi(m[5]); err != nil {
+		return nil, fmt.Errorf("invalid status code: %w", err)
+	}
+	entry.ResponseSize, _ = strconv.ParseInt(m[6], 10, 64)
+	entry.Referer = proxyValue(m[7])
+	entry.UserAgent = proxyValue(m[8])
+
+	setProxyMetadata(entry, "request_count", m[9])
+	setProxyMetadata(entry, "router", m[10])
+	setProxyMetadata(entry, "upstream_host", m[11])
+
+	duration, _ := strconv.ParseFloat(m[12], 64)
+	entry.ProcessingTime = duration / 1000
+
+	return entry, nil
+}
+
+func newProxyEntry(logType, line string, timestamp time.Time) *models.LogEntry {
+	return &models.LogEntry{
+		Timestamp: timestamp,
+		LogType:   logType,
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// applyProxyRequestLine sets the method, path and protocol from a quoted
+// request line; TCP proxies log "- - -"
+func applyProxyRequestLine(entry *models.LogEntry, request string) {
+	parts := strings.Fields(unescapeAccessLogValue(request))
+	if len(parts) > 0 && parts[0] != "-" {
+		entry.Method = parts[0]
+	}
+	if len(parts) > 1 && parts[1] != "-" {
+		entry.Path = parts[1]
+	}
+	if len(parts) > 2 && parts[2] != "-" {
+		entry.Metadata["protocol"] = parts[2]
+	}
+}
+
+// proxyValue unescapes a logged value, treating "-" as empty
+func proxyValue(value string) string {
+	value = unescapeAccessLogValue(value)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+func setProxyMetadata(entry *models.LogEntry, key, value string) {
+	if value = proxyValue(value); value != "" {
+		entry.Metadata[key] = convertValue(value)
+	}
+}
@@ -0,0 +1,78 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvoyLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `[2023-10-10T13:55:36.310Z] "POST /api/v1/locations HTTP/2" 204 - 154 0 226 100 "10.0.35.28, 10.0.0.1" "nsq2http" "cc21d9b0-cf5c-432b-8c7e-98aeb7988cd2" "locations" "tcp://10.0.2.1:80"`
+
+	entry, err := processor.parseEnvoyLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "envoy", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 310000000, time.UTC), entry.Timestamp)
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/api/v1/locations", entry.Path)
+	assert.Equal(t, 204, entry.StatusCode)
+	assert.Equal(t, int64(0), entry.ResponseSize)
+	assert.InDelta(t, 0.226, entry.ProcessingTime, 1e-9)
+	assert.Equal(t, "10.0.35.28", entry.SourceIP)
+	assert.Equal(t, "nsq2http", entry.UserAgent)
+	assert.InDelta(t, 0.1, entry.Metadata["upstream_response_time"], 1e-9)
+	assert.Equal(t, 154, entry.Metadata["bytes_received"])
+	assert.Equal(t, "locations", entry.Metadata["host"])
+	assert.Equal(t, "tcp://10.0.2.1:80", entry.Metadata["upstream_host"])
+	assert.Equal(t, "HTTP/2", entry.Metadata["protocol"])
+	assert.NotContains(t, entry.Metadata, "response_flags")
+}
+
+func TestParseEnvoyLogResetAndExtraFields(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `[2023-10-10T13:55:37.000Z] "GET /slow HTTP/1.1" 0 UC 0 0 5001 - "-" "curl/8.0" "id-1" "api" "-" outbound|80||api.default.svc.cluster.local`
+
+	entry, err := processor.parseEnvoyLog(line)
+	require.NoError(t, err)
+	assert.Equal(t, 0, entry.StatusCode)
+	assert.Equal(t, "UC", entry.Metadata["response_flags"])
+	assert.NotContains(t, entry.Metadata, "upstream_response_time")
+	assert.NotContains(t, entry.Metadata, "upstream_host")
+	assert.Empty(t, entry.SourceIP)
+
+	_, err = processor.parseEnvoyLog(`192.168.1.1 - - [10/Oct/2023:13:55:36 +0000] "GET / HTTP/1.1" 200 1`)
+	assert.Error(t, err)
+}
+
+func TestParseTraefikLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `192.168.1.100 - frank [10/Oct/2023:13:55:36 +0000] "GET /api/users?page=2 HTTP/1.1" 502 21 "-" "Mozilla/5.0 \"test\"" 4821 "api-router@docker" "http://172.17.0.3:8080" 34ms`
+
+	entry, err := processor.parseTraefikLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "traefik", entry.LogType)
+	assert.Equal(t, "192.168.1.100", entry.SourceIP)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 0, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/api/users?page=2", entry.Path)
+	assert.Equal(t, 502, entry.StatusCode)
+	assert.Equal(t, int64(21), entry.ResponseSize)
+	assert.Empty(t, entry.Referer)
+	assert.Equal(t, `Mozilla/5.0 "test"`, entry.UserAgent)
+	assert.InDelta(t, 0.034, entry.ProcessingTime, 1e-9)
+	assert.Equal(t, "frank", entry.Metadata["remote_user"])
+	assert.Equal(t, 4821, entry.Metadata["request_count"])
+	assert.Equal(t, "api-router@docker", entry.Metadata["router"])
+	assert.Equal(t, "http://172.17.0.3:8080", entry.Metadata["upstream_host"])
+
+	_, err = processor.parseTraefikLog(`192.168.1.100 - - [10/Oct/2023:13:55:36 +0000] "GET / HTTP/1.1" 200 1 "-" "curl"`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package logprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// syslogFacilities names the facility codes of RFC 5424 section 6.2.1
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogSeverities names the severity codes as log levels
+var syslogSeverities = []string{
+	"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug",
+}
+
+// SyslogHeader is the header of an RFC 5424 or RFC 3164 syslog message.
+// Facility and Severity are -1 when the message has no priority, as in
+// lines written to /var/log/syslog.
+type SyslogHeader struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData holds RFC 5424 SD-PARAMs keyed as "SD-ID.PARAM-NAME"
+	StructuredData map[string]string
+}
+
+// ParseSyslog splits a syslog message into its header and free-text
+// message. RFC 3164 timestamps carry no year, so the one placing the
+// message closest before now is used.
+func ParseSyslog(line string, now time.Time) (*SyslogHeader, string, error) {
+	header := &SyslogHeader{Facility: -1, Severity: -1}
+	rest := line
+
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 2 || end > 4 {
+			return nil, "", fmt.Errorf("invalid syslog priority")
+		}
+		pri, err := strconv.Atoi(rest[1:end])
+		if err != nil || pri > 191 {
+			return nil, "", fmt.Errorf("invalid syslog priority: %s", rest[1:end])
+		}
+		header.Facility, header.Severity = pri/8, pri%8
+		rest = rest[end+1:]
+	}
+
+	if strings.HasPrefix(rest, "1 ") {
+		message, err := parseRFC5424(header, rest[2:])
+		return header, message, err
+	}
+	message, err := parseRFC3164(header, rest, now)
+	return header, message, err
+}
+
+// parseRFC5424 parses TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]
+func parseRFC5424(header *SyslogHeader, rest string) (string, error) {
+	fields := make([]string, 5)
+	for i := range fields {
+		field, remainder, ok := strings.Cut(rest, " ")
+		if !ok && i < len(fields)-1 {
+			return "", fmt.Errorf("invalid RFC 5424 syslog header")
+		}
+		if field != "-" {
+			fields[i] = field
+		}
+		rest = remainder
+	}
+
+	if fields[0] != "" {
+		t, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return "", fmt.Errorf("invalid syslog timestamp: %w", err)
+		}
+		header.Timestamp = t
+	}
+	header.Hostname, header.AppName, header.ProcID, header.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else if strings.HasPrefix(rest, "[") {
+		var err error
+		header.StructuredData, rest, err = parseStructuredData(rest)
+		if err != nil {
+			return "", err
+		}
+	} else if rest != "" {
+		return "", fmt.Errorf("invalid syslog structured data")
+	}
+
+	message := strings.TrimPrefix(rest, " ")
+	return strings.TrimPrefix(message, "\ufeff"), nil
+}
+
+// parseStructuredData parses SD-ELEMENTs such as
+// [exampleSDID@32473 iut="3" eventSource="Application"] and returns the rest
+func parseStructuredData(rest string) (map[string]string, string, error) {
+	params := make(map[string]string)
+	for strings.HasPrefix(rest, "[") {
+		rest = rest[1:]
+		end := strings.IndexAny(rest, " ]")
+		if end <= 0 {
+			return nil, "", fmt.Errorf("invalid syslog structured data")
+		}
+		id := rest[:end]
+		rest = rest[end:]
+
+		for strings.HasPrefix(rest, " ") {
+			rest = rest[1:]
+			name, value, ok := strings.Cut(rest, `="`)
+			if !ok || name == "" {
+				return nil, "", fmt.Errorf("invalid syslog structured data parameter")
+			}
+			rest = value
+
+			var b strings.Builder
+			closed := false
+			for i := 0; i < len(rest); i++ {
+				c := rest[i]
+				if c == '\\' && i+1 < len(rest) && strings.IndexByte(`"\]`, rest[i+1]) >= 0 {
+					b.WriteByte(rest[i+1])
+					i++
+					continue
+				}
+				if c == '"' {
+					rest = rest[i+1:]
+					closed = true
+					break
+				}
+				b.WriteByte(c)
+			}
+			if !closed {
+				return nil, "", fmt.Errorf("unterminated syslog structured data value")
+			}
+			params[id+"."+name] = b.String()
+		}
+
+		if !strings.HasPrefix(rest, "]") {
+			return nil, "", fmt.Errorf("invalid syslog structured data")
+		}
+		rest = rest[1:]
+	}
+	return params, rest, nil
+}
+
+// parseRFC3164 parses Mmm dd hh:mm:ss [HOSTNAME] TAG[PID]: MSG
+func parseRFC3164(header *SyslogHeader, rest string, now time.Time) (string, error) {
+	if len(rest) < len(time.Stamp) {
+		return "", fmt.Errorf("invalid syslog format")
+	}
+	t, err := time.ParseInLocation(time.Stamp, rest[:len(time.Stamp)], now.Location())
+	if err != nil {
+		return "", fmt.Errorf("invalid syslog timestamp: %w", err)
+	}
+	t = t.AddDate(now.Year(), 0, 0)
+	// A timestamp well after now belongs to last year, e.g. December
+	// messages read in January
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	header.Timestamp = t
+	rest = strings.TrimPrefix(rest[len(time.Stamp):], " ")
+
+	// The hostname is absent when the first word is already the tag
+	if word, remainder, ok := strings.Cut(rest, " "); ok && !strings.HasSuffix(word, ":") && !strings.Contains(word, "[") {
+		header.Hostname = word
+		rest = remainder
+	}
+
+	tag, message, ok := strings.Cut(rest, ": ")
+	if !ok || tag == "" || strings.Contains(tag, " ") {
+		// No tag; everything after the hostname is the message
+		return rest, nil
+	}
+	if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+		header.ProcID = tag[open+1 : len(tag)-1]
+		tag = tag[:open]
+	}
+	header.AppName = tag
+	return message, nil
+}
+
+// parseSyslogLog parses RFC 5424 and RFC 3164 syslog messages, with or
+// without a priority. The message is stored in Path.
+func (p *Processor) parseSyslogLog(line string) (*models.LogEntry, error) {
+	header, message, err := ParseSyslog(line, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.LogEntry{
+		Timestamp: header.Timestamp,
+		LogType:   "syslog",
+		Path:      message,
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if header.Facility >= 0 {
+		entry.Metadata["facility"] = syslogFacilities[header.Facility]
+		entry.Metadata["level"] = syslogSeverities[header.Severity]
+	}
+	if header.Hostname != "" {
+		entry.Metadata["hostname"] = header.Hostname
+		if p.isValidIP(header.Hostname) {
+			entry.SourceIP = header.Hostname
+		}
+	}
+	for key, value := range map[string]string{"app_name": header.AppName, "procid": header.ProcID, "msgid": header.MsgID} {
+		if value != "" {
+			entry.Metadata[key] = value
+		}
+	}
+	for key, value := range header.StructuredData {
+		entry.Metadata[key] = convertValue(value)
+	}
+	return entry, nil
+}
@@ -0,0 +1,91 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyslogRFC5424(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `<165>1 2003-10-11T22:14:15.003Z 192.0.2.1 evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication"][origin ip="192.0.2.1"] ` + "\ufeff" + `An application event log entry`
+
+	entry, err := processor.parseSyslogLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "syslog", entry.LogType)
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), entry.Timestamp)
+	assert.Equal(t, "192.0.2.1", entry.SourceIP)
+	assert.Equal(t, "An application event log entry", entry.Path)
+	assert.Equal(t, "local4", entry.Metadata["facility"])
+	assert.Equal(t, "notice", entry.Metadata["level"])
+	assert.Equal(t, "evntslog", entry.Metadata["app_name"])
+	assert.Equal(t, "1234", entry.Metadata["procid"])
+	assert.Equal(t, "ID47", entry.Metadata["msgid"])
+	assert.Equal(t, 3, entry.Metadata["exampleSDID@32473.iut"])
+	assert.Equal(t, `App"lication`, entry.Metadata["exampleSDID@32473.eventSource"])
+	assert.Equal(t, "192.0.2.1", entry.Metadata["origin.ip"])
+}
+
+func TestParseSyslogRFC5424NilValues(t *testing.T) {
+	header, message, err := ParseSyslog("<14>1 - - - - - -", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, header.Facility)
+	assert.Equal(t, 6, header.Severity)
+	assert.True(t, header.Timestamp.IsZero())
+	assert.Empty(t, header.Hostname)
+	assert.Empty(t, message)
+}
+
+func TestParseSyslogRFC3164(t *testing.T) {
+	now := time.Date(2023, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	header, message, err := ParseSyslog("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8", now)
+	require.NoError(t, err)
+	assert.Equal(t, 4, header.Facility)
+	assert.Equal(t, 2, header.Severity)
+	assert.Equal(t, time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC), header.Timestamp)
+	assert.Equal(t, "mymachine", header.Hostname)
+	assert.Equal(t, "su", header.AppName)
+	assert.Equal(t, "230", header.ProcID)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", message)
+
+	// Lines from /var/log/syslog have no priority
+	header, message, err = ParseSyslog("Oct  9 06:25:01 web01 CRON[1234]: (root) CMD (run-parts /etc/cron.hourly)", now)
+	require.NoError(t, err)
+	assert.Equal(t, -1, header.Facility)
+	assert.Equal(t, "web01", header.Hostname)
+	assert.Equal(t, "CRON", header.AppName)
+	assert.Equal(t, "(root) CMD (run-parts /etc/cron.hourly)", message)
+
+	// Forwarders may omit the hostname
+	header, message, err = ParseSyslog("<13>Oct 11 22:14:15 sshd[99]: Accepted publickey for deploy", now)
+	require.NoError(t, err)
+	assert.Empty(t, header.Hostname)
+	assert.Equal(t, "sshd", header.AppName)
+	assert.Equal(t, "Accepted publickey for deploy", message)
+}
+
+func TestParseSyslogRFC3164YearRollover(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	header, _, err := ParseSyslog("Dec 31 23:59:59 host app: last message of the year", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), header.Timestamp)
+}
+
+func TestParseSyslogInvalid(t *testing.T) {
+	for _, line := range []string{
+		"<999>Oct 11 22:14:15 host app: message",
+		"<abc>Oct 11 22:14:15 host app: message",
+		"<14>1 yesterday host app - - - message",
+		`<14>1 - - - - - [id key="unterminated] message`,
+		"not a syslog line",
+	} {
+		_, _, err := ParseSyslog(line, time.Now())
+		assert.Error(t, err, line)
+	}
+}
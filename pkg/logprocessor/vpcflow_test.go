@@ -0,0 +1,58 @@
+package logprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVPCFlowLog(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := "2 123456789010 eni-1235b8ca123456789 203.0.113.12 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 REJECT OK"
+
+	entry, err := processor.parseVPCFlowLog(line)
+	require.NoError(t, err)
+
+	assert.Equal(t, "aws_vpc_flow", entry.LogType)
+	assert.Equal(t, time.Unix(1418530010, 0).UTC(), entry.Timestamp)
+	assert.Equal(t, "203.0.113.12", entry.SourceIP)
+	assert.Equal(t, "TCP", entry.Method)
+	assert.Equal(t, "172.31.16.21:22", entry.Path)
+	assert.Equal(t, int64(4249), entry.ResponseSize)
+	assert.Equal(t, "REJECT", entry.Metadata["action"])
+	assert.Equal(t, "123456789010", entry.Metadata["account_id"])
+	assert.Equal(t, "eni-1235b8ca123456789", entry.Metadata["interface_id"])
+	assert.Equal(t, 22, entry.Metadata["dstport"])
+	assert.Equal(t, 20, entry.Metadata["packets"])
+	assert.Equal(t, int64(60), entry.Metadata["duration"])
+	assert.Equal(t, FlowInbound, entry.Metadata["direction"])
+}
+
+func TestParseVPCFlowLogSkipsHeaderAndNoData(t *testing.T) {
+	processor := NewProcessor(1)
+
+	for _, line := range []string{
+		"version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status",
+		"2 123456789010 eni-1235b8ca123456789 - - - - - - - 1431280876 1431280934 - NODATA",
+	} {
+		entry, err := processor.parseVPCFlowLog(line)
+		assert.NoError(t, err)
+		assert.Nil(t, entry)
+	}
+
+	_, err := processor.parseVPCFlowLog("3 123456789010 eni-1235b8ca123456789 10.0.0.1 10.0.0.2 1 2 6 1 1 1 2 ACCEPT OK")
+	assert.Error(t, err)
+	_, err = processor.parseVPCFlowLog("2 123456789010 eni-1235b8ca123456789 10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestFlowDirection(t *testing.T) {
+	assert.Equal(t, FlowInbound, FlowDirection("198.51.100.7", "10.0.1.5"))
+	assert.Equal(t, FlowOutbound, FlowDirection("192.168.0.4", "93.184.216.34"))
+	assert.Equal(t, FlowInternal, FlowDirection("10.0.1.5", "172.16.0.9"))
+	assert.Equal(t, FlowExternal, FlowDirection("198.51.100.7", "93.184.216.34"))
+	assert.Equal(t, FlowInternal, FlowDirection("fd00::1", "fd00::2"))
+}
@@ -0,0 +1,218 @@
+package logprocessor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// windowsEvent is the subset of the Windows event schema
+// (http://schemas.microsoft.com/win/2004/08/events/event) kept on entries
+type windowsEvent struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID       string `xml:"EventID"`
+		Level         string `xml:"Level"`
+		Task          string `xml:"Task"`
+		Opcode        string `xml:"Opcode"`
+		Keywords      string `xml:"Keywords"`
+		EventRecordID string `xml:"EventRecordID"`
+		TimeCreated   struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		Execution struct {
+			ProcessID string `xml:"ProcessID,attr"`
+			ThreadID  string `xml:"ThreadID,attr"`
+		} `xml:"Execution"`
+		Channel  string `xml:"Channel"`
+		Computer string `xml:"Computer"`
+		Security struct {
+			UserID string `xml:"UserID,attr"`
+		} `xml:"Security"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"EventData"`
+	// Present when exported with display information (Event Viewer "Save
+	// as XML", wevtutil qe /f:renderedxml)
+	RenderingInfo struct {
+		Message string `xml:"Message"`
+		Level   string `xml:"Level"`
+	} `xml:"RenderingInfo"`
+}
+
+// windowsEventLevels names the standard event levels
+var windowsEventLevels = map[string]string{
+	"0": "info", // LogAlways, used by security audit events
+	"1": "critical",
+	"2": "error",
+	"3": "warning",
+	"4": "info",
+	"5": "verbose",
+}
+
+// Keywords bits set on security audit events
+const (
+	windowsAuditFailure = 0x10000000000000
+	windowsAuditSuccess = 0x20000000000000
+)
+
+// windowsSourceAddressFields are the EventData fields that hold the remote
+// address in logon, RDP and network events
+var windowsSourceAddressFields = []string{"IpAddress", "SourceAddress", "ClientAddress", "SourceNetworkAddress"}
+
+// parseWindowsEvent parses a single <Event> element exported from the
+// Windows event log as XML (wevtutil qe /f:xml, Event Viewer "Save as XML")
+func (p *Processor) parseWindowsEvent(line string) (*models.LogEntry, error) {
+	var event windowsEvent
+	if err := xml.Unmarshal([]byte(line), &event); err != nil {
+		return nil, fmt.Errorf("invalid Windows event XML: %w", err)
+	}
+	system := event.System
+	if system.EventID == "" || system.Provider.Name == "" {
+		return nil, fmt.Errorf("invalid Windows event: missing EventID or Provider")
+	}
+
+	entry := &models.LogEntry{
+		LogType:   "windows_event",
+		RawLog:    line,
+		Metadata:  make(models.LogMetadata),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	entry.Timestamp = time.Now()
+	if system.TimeCreated.SystemTime != "" {
+		t, err := time.Parse(time.RFC3339Nano, system.TimeCreated.SystemTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Windows event time: %w", err)
+		}
+		entry.Timestamp = t
+	}
+
+	entry.Metadata["event_id"] = convertValue(strings.TrimSpace(system.EventID))
+	entry.Metadata["provider"] = system.Provider.Name
+	entry.Metadata["level"] = windowsEventLevel(system.Level, event.RenderingInfo.Level)
+	if keywords, err := strconv.ParseUint(strings.TrimPrefix(system.Keywords, "0x"), 16, 64); err == nil {
+		switch {
+		case keywords&windowsAuditFailure != 0:
+			entry.Metadata["audit"] = "failure"
+		case keywords&windowsAuditSuccess != 0:
+			entry.Metadata["audit"] = "success"
+		}
+	}
+	for key, value := range map[string]string{
+		"computer":        system.Computer,
+		"channel":         system.Channel,
+		"event_record_id": system.EventRecordID,
+		"task":            system.Task,
+		"opcode":          system.Opcode,
+		"keywords":        system.Keywords,
+		"process_id":      system.Execution.ProcessID,
+		"thread_id":       system.Execution.ThreadID,
+		"user_id":         system.Security.UserID,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.Metadata[key] = convertValue(value)
+		}
+	}
+
+	// Classic events have unnamed Data elements; keep them by position
+	data := make(map[string]string)
+	for i, field := range event.EventData.Data {
+		name := field.Name
+		if name == "" {
+			name = fmt.Sprintf("data_%d", i)
+		}
+		value := strings.TrimSpace(field.Value)
+		data[name] = value
+		if value != "" && value != "-" {
+			entry.Metadata[name] = convertValue(value)
+		}
+	}
+
+	for _, field := range windowsSourceAddressFields {
+		if ip := strings.TrimPrefix(data[field], "::ffff:"); p.isValidIP(ip) {
+			entry.SourceIP = ip
+			break
+		}
+	}
+
+	entry.Path = strings.TrimSpace(event.RenderingInfo.Message)
+	if entry.Path == "" {
+		entry.Path = fmt.Sprintf("%s %s", system.Provider.Name, strings.TrimSpace(system.EventID))
+	} else if first, _, ok := strings.Cut(entry.Path, "\n"); ok {
+		// Rendered messages run to many lines of detail already in Metadata
+		entry.Path = strings.TrimSpace(first)
+	}
+
+	return entry, nil
+}
+
+func windowsEventLevel(level, rendered string) string {
+	if name, ok := windowsEventLevels[strings.TrimSpace(level)]; ok {
+		return name
+	}
+	if rendered != "" {
+		return strings.ToLower(rendered)
+	}
+	return "info"
+}
+
+// scanWindowsEvents is a bufio.SplitFunc returning each <Event> element of
+// an XML export, whether events are one per line or pretty-printed inside
+// an <Events> root
+func scanWindowsEvents(data []byte, atEOF bool) (int, []byte, error) {
+	start := indexEventStart(data)
+	if start < 0 {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		// Keep a possible partial "<Event" at the end of the buffer
+		if keep := len("<Event"); len(data) > keep {
+			return len(data) - keep, nil, nil
+		}
+		return 0, nil, nil
+	}
+
+	end := bytes.Index(data[start:], []byte("</Event>"))
+	if end < 0 {
+		if atEOF {
+			return 0, nil, fmt.Errorf("unterminated Windows event")
+		}
+		return start, nil, nil
+	}
+	end += start + len("</Event>")
+	return end, data[start:end], nil
+}
+
+// indexEventStart finds the next <Event> start tag, skipping <Events>,
+// <EventData> and other elements that share the prefix
+func indexEventStart(data []byte) int {
+	offset := 0
+	for {
+		i := bytes.Index(data[offset:], []byte("<Event"))
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		next := i + len("<Event")
+		if next < len(data) {
+			switch data[next] {
+			case '>', ' ', '\t', '\r', '\n':
+				return i
+			}
+		}
+		offset = next
+	}
+}
@@ -0,0 +1,99 @@
+package logprocessor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const windowsLogonFailure = `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Microsoft-Windows-Security-Auditing" Guid="{54849625-5478-4994-A5BA-3E3B0328C30D}"/>
+    <EventID>4625</EventID>
+    <Version>0</Version>
+    <Level>0</Level>
+    <Task>12544</Task>
+    <Opcode>0</Opcode>
+    <Keywords>0x8010000000000000</Keywords>
+    <TimeCreated SystemTime="2023-10-10T13:55:36.1234567Z"/>
+    <EventRecordID>102934</EventRecordID>
+    <Execution ProcessID="684" ThreadID="1524"/>
+    <Channel>Security</Channel>
+    <Computer>DC01.corp.example.com</Computer>
+    <Security/>
+  </System>
+  <EventData>
+    <Data Name="TargetUserName">administrator</Data>
+    <Data Name="LogonType">10</Data>
+    <Data Name="IpAddress">203.0.113.7</Data>
+    <Data Name="IpPort">-</Data>
+  </EventData>
+  <RenderingInfo Culture="en-US">
+    <Message>An account failed to log on.
+
+Subject: ...</Message>
+    <Level>Information</Level>
+  </RenderingInfo>
+</Event>`
+
+func TestParseWindowsEvent(t *testing.T) {
+	processor := NewProcessor(1)
+
+	entry, err := processor.parseWindowsEvent(windowsLogonFailure)
+	require.NoError(t, err)
+
+	assert.Equal(t, "windows_event", entry.LogType)
+	assert.Equal(t, time.Date(2023, 10, 10, 13, 55, 36, 123456700, time.UTC), entry.Timestamp.UTC())
+	assert.Equal(t, "203.0.113.7", entry.SourceIP)
+	assert.Equal(t, "An account failed to log on.", entry.Path)
+	assert.Equal(t, 4625, entry.Metadata["event_id"])
+	assert.Equal(t, "Microsoft-Windows-Security-Auditing", entry.Metadata["provider"])
+	assert.Equal(t, "info", entry.Metadata["level"])
+	assert.Equal(t, "failure", entry.Metadata["audit"])
+	assert.Equal(t, "DC01.corp.example.com", entry.Metadata["computer"])
+	assert.Equal(t, "Security", entry.Metadata["channel"])
+	assert.Equal(t, "administrator", entry.Metadata["TargetUserName"])
+	assert.Equal(t, 10, entry.Metadata["LogonType"])
+	assert.NotContains(t, entry.Metadata, "IpPort")
+}
+
+func TestParseWindowsEventClassicData(t *testing.T) {
+	processor := NewProcessor(1)
+
+	line := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Service Control Manager'/><EventID Qualifiers='49152'>7000</EventID><Level>2</Level><TimeCreated SystemTime='2023-10-10T14:00:00.000Z'/><Computer>web01</Computer></System><EventData><Data>Spooler</Data><Data>%%1053</Data></EventData></Event>`
+
+	entry, err := processor.parseWindowsEvent(line)
+	require.NoError(t, err)
+	assert.Equal(t, "Service Control Manager 7000", entry.Path)
+	assert.Equal(t, "error", entry.Metadata["level"])
+	assert.Equal(t, "Spooler", entry.Metadata["data_0"])
+	assert.Empty(t, entry.SourceIP)
+
+	_, err = processor.parseWindowsEvent(`<Event><System></System></Event>`)
+	assert.Error(t, err)
+	_, err = processor.parseWindowsEvent(`not xml`)
+	assert.Error(t, err)
+}
+
+func TestProcessFileWindowsEvents(t *testing.T) {
+	processor := NewProcessor(2)
+
+	// Event Viewer wraps pretty-printed events in an <Events> root
+	export := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<Events>` + windowsLogonFailure + `
+<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System><Provider Name="EventLog"/><EventID>6005</EventID><Level>4</Level></System></Event>
+</Events>`
+
+	require.NoError(t, processor.ProcessFile(strings.NewReader(export), "windows_event"))
+
+	var paths []string
+	for i := 0; i < 2; i++ {
+		entry := <-processor.GetProcessedLogs()
+		paths = append(paths, entry.Path)
+	}
+	assert.ElementsMatch(t, []string{"An account failed to log on.", "EventLog 6005"}, paths)
+	assert.Equal(t, int64(0), processor.GetStats().Errors)
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// AggregateGroups are the fields entries can be grouped by in aggregates
+var AggregateGroups = []string{"log_type", "method", "path", "status_code", "source_ip"}
+
+// AggregateMetrics are the values aggregates compute for each group.
+// Errors are entries with a status code of at least 400.
+var AggregateMetrics = []string{
+	"count", "errors", "unique_ips",
+	"avg_processing_time", "max_processing_time",
+	"avg_response_size", "sum_response_size",
+}
+
+// AggregateQuery computes Metrics, each one of AggregateMetrics, over the
+// entries matching Filter, grouped by the GroupBy fields, each one of
+// AggregateGroups, and by time buckets of Interval, at least a second,
+// when it is positive. Buckets start on whole multiples of Interval since
+// the Unix epoch, in UTC. Rows come bucket by bucket, and within a bucket
+// by the first metric, highest first, then by the groups' values. Limit
+// keeps that many rows when it is positive. The filter's Limit, Offset,
+// order and position are ignored.
+type AggregateQuery struct {
+	Filter   LogFilter
+	GroupBy  []string
+	Metrics  []string
+	Interval time.Duration
+	Limit    int
+}
+
+// AggregateRow is a group of entries: its bucket, when bucketed, the
+// values of the fields it is grouped by and the metrics of its entries
+type AggregateRow struct {
+	Bucket  *time.Time             `json:"bucket,omitempty"`
+	Group   map[string]interface{} `json:"group"`
+	Metrics map[string]float64     `json:"metrics"`
+}
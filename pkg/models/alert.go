@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AlertRule represents a threshold rule evaluated against ingested logs
+type AlertRule struct {
+	ID                int64           `json:"id" db:"id"`
+	Name              string          `json:"name" db:"name"`
+	Description       string          `json:"description" db:"description"`
+	ConditionType     string          `json:"condition_type" db:"condition_type"`
+	ThresholdValue    float64         `json:"threshold_value" db:"threshold_value"`
+	TimeWindow        int             `json:"time_window" db:"time_window"`                         // seconds
+	ForDuration       int             `json:"for_duration" db:"for_duration"`                       // seconds the breach must persist before firing
+	RecoveryThreshold *float64        `json:"recovery_threshold,omitempty" db:"recovery_threshold"` // value to drop to before resolving
+	Expression        *AlertCondition `json:"expression,omitempty" db:"expression"`                 // boolean expression for composite rules
+	IsActive          bool            `json:"is_active" db:"is_active"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// AlertCondition is a node in a composite rule expression. Leaf nodes
+// compare a metric against a threshold; branch nodes combine their
+// children with "and" or "or".
+type AlertCondition struct {
+	Operator   string            `json:"operator,omitempty"`
+	Conditions []*AlertCondition `json:"conditions,omitempty"`
+	Metric     string            `json:"metric,omitempty"`
+	Comparator string            `json:"comparator,omitempty"` // >, >=, <, <=
+	Threshold  float64           `json:"threshold,omitempty"`
+}
+
+// AlertEvent represents a single firing of an alert rule
+type AlertEvent struct {
+	ID          int64     `json:"id" db:"id"`
+	RuleID      int64     `json:"rule_id" db:"rule_id"`
+	RuleName    string    `json:"rule_name"`
+	Message     string    `json:"message" db:"message"`
+	Severity    string    `json:"severity" db:"severity"`
+	Value       float64   `json:"value"`
+	Threshold   float64   `json:"threshold"`
+	TriggeredAt time.Time `json:"triggered_at" db:"triggered_at"`
+
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+
+	// Window holds every metric over the rule's window when it fired and
+	// Details the per-condition values of composite rules
+	Window  map[string]float64 `json:"window,omitempty"`
+	Details map[string]float64 `json:"details,omitempty"`
+}
+
+// Rule evaluation states
+const (
+	RuleStateOK      = "ok"
+	RuleStatePending = "pending"
+	RuleStateFiring  = "firing"
+)
+
+// RuleEvaluation records the outcome of a single rule evaluation
+type RuleEvaluation struct {
+	RuleID      int64     `json:"rule_id"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	Value       float64   `json:"value"`
+	Threshold   float64   `json:"threshold"`
+	State       string    `json:"state"`
+	// Details holds the individual metric values of composite rules
+	Details map[string]float64 `json:"details,omitempty"`
+}
+
+// Value implements driver.Valuer for database storage
+func (c AlertCondition) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner for database retrieval
+func (c *AlertCondition) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("unsupported alert condition type: %T", value)
+	}
+}
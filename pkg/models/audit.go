@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditRecord is an entry in the append-only audit log. Hash covers the
+// record's fields and PrevHash, the hash of the record before it, so
+// changing, removing or reordering any earlier record breaks the chain.
+type AuditRecord struct {
+	Seq        int64           `json:"seq" db:"seq"`
+	RecordedAt time.Time       `json:"recorded_at" db:"recorded_at"`
+	Action     string          `json:"action" db:"action"`
+	Actor      string          `json:"actor" db:"actor"`
+	Subject    string          `json:"subject" db:"subject"`
+	Details    json.RawMessage `json:"details,omitempty" db:"details"` // compact JSON object
+	PrevHash   string          `json:"prev_hash" db:"prev_hash"`
+	Hash       string          `json:"hash" db:"hash"`
+}
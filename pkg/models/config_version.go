@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Kinds of configuration objects whose versions are kept. Alert rules are
+// identified by their ID, feature overrides by their flag, followed by
+// ":" and the project for a project's override.
+const (
+	ConfigKindAlertRule       = "alert_rule"
+	ConfigKindFeatureOverride = "feature_override"
+)
+
+// ConfigVersion is the state of a configuration object, such as an alert
+// rule, after one change to it. Versions of an object are numbered from 1.
+type ConfigVersion struct {
+	ID           int64           `json:"id" db:"id"`
+	Kind         string          `json:"kind" db:"kind"`
+	ObjectID     string          `json:"object_id" db:"object_id"`
+	Version      int             `json:"version" db:"version"`
+	Action       string          `json:"action" db:"action"`
+	Actor        string          `json:"actor" db:"actor"`
+	State        json.RawMessage `json:"state" db:"state"`                           // the object as JSON, null once deleted
+	RestoredFrom int             `json:"restored_from,omitempty" db:"restored_from"` // the version a rollback returned to
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DataKey is a project's key for encrypting log content at rest, stored
+// wrapped by the master key so the database alone cannot decrypt it
+type DataKey struct {
+	Project     string    `json:"project" db:"project"`
+	WrappedKey  []byte    `json:"-" db:"wrapped_key"`
+	MasterKeyID string    `json:"master_key_id" db:"master_key_id"` // the master key that wrapped it
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
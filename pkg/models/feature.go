@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// FeatureOverride turns a feature flag on or off at runtime, for one
+// project or, with an empty Project, for all of them
+type FeatureOverride struct {
+	Flag      string    `json:"flag" db:"flag"`
+	Project   string    `json:"project,omitempty" db:"project"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	UpdatedBy string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// IngestedFile is a log file that was processed in full, recognized by the
+// SHA-256 of its content when it is uploaded again
+type IngestedFile struct {
+	SHA256     string    `json:"sha256" db:"sha256"`
+	Filename   string    `json:"filename" db:"filename"`
+	LogType    string    `json:"log_type" db:"log_type"`
+	Size       int64     `json:"size" db:"size"`
+	IngestedAt time.Time `json:"ingested_at" db:"ingested_at"`
+}
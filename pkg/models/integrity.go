@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// IntegrityFinding is a violated invariant of the stored data, with how
+// many records violate it and a suggestion for repairing them
+type IntegrityFinding struct {
+	Check    string   `json:"check"`
+	Count    int64    `json:"count"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Repair   string   `json:"repair"`
+	Samples  []string `json:"samples,omitempty"`
+}
+
+// OrphanedAlertEvents counts the fired alerts of a rule that no longer
+// exists
+type OrphanedAlertEvents struct {
+	RuleID int64 `json:"rule_id"`
+	Events int64 `json:"events"`
+}
+
+// RollupTotal compares the requests a roll-up recorded for an hour with
+// the entries stored for it
+type RollupTotal struct {
+	Bucket time.Time `json:"bucket"`
+	Rollup int64     `json:"rollup"`
+	Raw    int64     `json:"raw"`
+}
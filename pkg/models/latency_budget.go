@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LatencyBudget is the response time a team commits to for a path: the
+// Percentile-th percentile of its response times must stay within
+// ThresholdMs. Path is normalized, with placeholders such as {id} for the
+// segments that vary between requests.
+type LatencyBudget struct {
+	ID          int64     `json:"id" db:"id"`
+	Path        string    `json:"path" db:"path"`
+	Percentile  float64   `json:"percentile" db:"percentile"`
+	ThresholdMs float64   `json:"threshold_ms" db:"threshold_ms"`
+	Team        string    `json:"team" db:"team"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
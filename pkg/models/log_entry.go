@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -21,6 +22,10 @@ type LogEntry struct {
 	ProcessingTime float64             `json:"processing_time" db:"processing_time"`
 	RawLog      string                 `json:"raw_log" db:"raw_log"`
 	Metadata    LogMetadata            `json:"metadata" db:"metadata"`
+	// FileHash and LineNumber identify the line of an uploaded file the
+	// entry was read from, so uploading the file again skips it
+	FileHash    string                 `json:"file_hash,omitempty" db:"file_hash"`
+	LineNumber  int64                  `json:"line_number,omitempty" db:"line_number"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
 }
@@ -112,15 +117,102 @@ type IPStats struct {
 	Count int64  `json:"count"`
 }
 
+// MethodStats aggregates requests by HTTP method, and by path when Path is set
+type MethodStats struct {
+	Method          string  `json:"method"`
+	Path            string  `json:"path,omitempty"`
+	Requests        int64   `json:"requests"`
+	AvgResponseTime float64 `json:"avg_response_time"`
+	MaxResponseTime float64 `json:"max_response_time"`
+	ErrorRate       float64 `json:"error_rate"`
+	ServerErrorRate float64 `json:"server_error_rate"`
+}
+
+// CountryActivity is a client country's requests, with FirstSeen taken
+// over a longer lookback than Requests and UniqueIPs
+type CountryActivity struct {
+	Country   string    `json:"country"`
+	FirstSeen time.Time `json:"first_seen"`
+	Requests  int64     `json:"requests"`
+	UniqueIPs int64     `json:"unique_ips"`
+}
+
+// RetentionStats describes how far back stored entries reach
+type RetentionStats struct {
+	TotalEntries int64      `json:"total_entries"`
+	OldestEntry  *time.Time `json:"oldest_entry"`
+	// ExpiredEntries are older than the retention cutoff
+	ExpiredEntries int64 `json:"expired_entries"`
+}
+
+// FacetFields are the fields GetFacets counts entries by
+var FacetFields = []string{"log_type", "method", "path", "source_ip", "status_code"}
+
+// FacetCount is how many entries have a value of a field
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
 // LogFilter represents filtering options for log queries
 type LogFilter struct {
 	StartTime    *time.Time `json:"start_time"`
 	EndTime      *time.Time `json:"end_time"`
 	LogType      string     `json:"log_type"`
 	StatusCode   *int       `json:"status_code"`
+	// MinStatusCode selects entries with at least this status code, such
+	// as 400 for errors
+	MinStatusCode int       `json:"min_status_code,omitempty"`
 	SourceIP     string     `json:"source_ip"`
 	Path         string     `json:"path"`
+	// ExactPath matches Path in full instead of as part of the path
+	ExactPath    bool       `json:"exact_path,omitempty"`
 	Method       string     `json:"method"`
 	Limit        int        `json:"limit"`
 	Offset       int        `json:"offset"`
+	// SortBy lists entries by one of SortFields instead of by time, and
+	// SortAscending lists them lowest first instead of highest. Entries
+	// with the same value are listed by ID in the same direction.
+	SortBy        string    `json:"sort_by,omitempty"`
+	SortAscending bool      `json:"sort_ascending,omitempty"`
+	// After and Before page by position instead of Offset, when entries
+	// are listed by time. After selects the entries listed after the
+	// position and Before those listed before it; either way they are
+	// returned in the filter's order, and with Before they are the Limit
+	// nearest the position.
+	After        *LogPosition `json:"-"`
+	Before       *LogPosition `json:"-"`
+}
+
+// Matches reports whether an entry meets the filter's conditions. Limit,
+// Offset, the sort and positions do not apply to single entries.
+func (f *LogFilter) Matches(entry *LogEntry) bool {
+	switch {
+	case f.StartTime != nil && entry.Timestamp.Before(*f.StartTime),
+		f.EndTime != nil && !entry.Timestamp.Before(*f.EndTime),
+		f.LogType != "" && entry.LogType != f.LogType,
+		f.StatusCode != nil && entry.StatusCode != *f.StatusCode,
+		f.MinStatusCode > 0 && entry.StatusCode < f.MinStatusCode,
+		f.SourceIP != "" && entry.SourceIP != f.SourceIP,
+		f.Path != "" && f.ExactPath && entry.Path != f.Path,
+		f.Path != "" && !f.ExactPath && !strings.Contains(entry.Path, f.Path),
+		f.Method != "" && entry.Method != f.Method:
+		return false
+	}
+	return true
+}
+
+// SortFields are the fields entries can be listed by
+var SortFields = []string{"timestamp", "status_code", "response_size", "processing_time"}
+
+// LogPosition is the place of an entry among entries listed by time, with
+// ties broken by ID
+type LogPosition struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+}
+
+// PositionOf is the position of an entry
+func PositionOf(entry *LogEntry) *LogPosition {
+	return &LogPosition{Timestamp: entry.Timestamp, ID: entry.ID}
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// MaintenanceWindow is a period of planned work. Reports mark it and leave
+// it out of availability figures; windows with SilenceAlerts also suppress
+// alert notifications while active.
+type MaintenanceWindow struct {
+	ID            int64     `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name"`
+	Description   string    `json:"description" db:"description"`
+	StartsAt      time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt        time.Time `json:"ends_at" db:"ends_at"`
+	SilenceAlerts bool      `json:"silence_alerts" db:"silence_alerts"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Contains reports whether t falls within the window
+func (m *MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(m.StartsAt) && t.Before(m.EndsAt)
+}
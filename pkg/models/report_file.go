@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ReportFile is the metadata of a generated report file, recorded when it
+// is saved to the report store
+type ReportFile struct {
+	Name        string    `json:"name" db:"name"`
+	Size        int64     `json:"size" db:"size"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
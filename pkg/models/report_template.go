@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ReportTemplate is an HTML template uploaded to replace the built-in one
+// of a report type, selected by name when a report is generated
+type ReportTemplate struct {
+	ReportType string `json:"report_type" db:"report_type"`
+	Name       string `json:"name" db:"name"`
+	// Content is the template's source, in html/template syntax
+	Content   string    `json:"content,omitempty" db:"content"`
+	UpdatedBy string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// RetentionPolicy keeps the entries of a log type for Days days, in place
+// of the configured retention
+type RetentionPolicy struct {
+	LogType   string    `json:"log_type" db:"log_type"`
+	Days      int       `json:"days" db:"days"`
+	UpdatedBy string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RetentionCutoff applies a retention cutoff to the entries of LogType or,
+// with an empty LogType, to those of every log type but the Except ones.
+// Entries older than Before are expired.
+type RetentionCutoff struct {
+	LogType string
+	Except  []string
+	Before  time.Time
+}
+
+// Applies reports whether the cutoff applies to entries of a log type
+func (c RetentionCutoff) Applies(logType string) bool {
+	if c.LogType != "" {
+		return logType == c.LogType
+	}
+	for _, except := range c.Except {
+		if logType == except {
+			return false
+		}
+	}
+	return true
+}
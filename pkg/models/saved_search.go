@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SavedSearch is a named log filter a user saved to run again. Searches
+// are their owner's own; without sign-in the owner is empty.
+type SavedSearch struct {
+	Owner       string `json:"owner,omitempty" db:"owner"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description,omitempty" db:"description"`
+	// Filter holds the conditions and order of the search, without a
+	// Limit, Offset or position
+	Filter    LogFilter `json:"filter" db:"filter_json"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Intervals of traffic rollup buckets
+const (
+	RollupHour = "hour"
+	RollupDay  = "day"
+)
+
+// RollupIntervals are the bucket lengths of the traffic rollup intervals.
+// Buckets start on whole hours and days in UTC.
+var RollupIntervals = map[string]time.Duration{
+	RollupHour: time.Hour,
+	RollupDay:  24 * time.Hour,
+}
+
+// TrafficGroups are the fields traffic rollups can be grouped by
+var TrafficGroups = []string{"bucket", "log_type", "path", "status_code"}
+
+// TrafficRollup totals the entries of a bucket with the same log type,
+// path and status code, or a sum of such rollups. Errors are requests
+// with a status code of at least 400 and Bytes their response sizes.
+// UniqueIPs counts the source IPs of one rollup exactly; summed over
+// several, an IP is counted once for each rollup it appears in.
+type TrafficRollup struct {
+	Bucket     time.Time `json:"bucket"`
+	LogType    string    `json:"log_type"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	Requests   int64     `json:"requests"`
+	Errors     int64     `json:"errors"`
+	Bytes      int64     `json:"bytes"`
+	UniqueIPs  int64     `json:"unique_ips"`
+}
+
+// TrafficQuery sums the rollups of an interval whose buckets start in
+// [Start, End), of LogType when it is set. Rollups are summed by the
+// GroupBy fields, one of TrafficGroups each, and the fields not grouped by
+// are left zero. Limit keeps the busiest rows when it is positive.
+type TrafficQuery struct {
+	Interval string
+	Start    time.Time
+	End      time.Time
+	LogType  string
+	GroupBy  []string
+	Limit    int
+}
+
+// RollupBucket returns the start of the bucket of an interval holding t
+func RollupBucket(interval string, t time.Time) time.Time {
+	return t.UTC().Truncate(RollupIntervals[interval])
+}
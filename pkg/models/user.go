@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// User is a user of the dashboard and API, identified by the subject of
+// their OpenID Connect tokens
+type User struct {
+	Subject string `json:"subject" db:"subject"`
+	Email   string `json:"email,omitempty" db:"email"`
+	Name    string `json:"name,omitempty" db:"name"`
+	// Role is the name of one of the roles
+	Role         string     `json:"role" db:"role"`
+	LastSignInAt *time.Time `json:"last_sign_in_at,omitempty" db:"last_sign_in_at"`
+	UpdatedBy    string     `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Role is a set of permissions. A role is allowed everything the roles of
+// a lower level are.
+type Role struct {
+	Name        string `json:"name" db:"name"`
+	Level       int    `json:"level" db:"level"`
+	Description string `json:"description" db:"description"`
+}
@@ -0,0 +1,309 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Supported channel types
+const (
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelTeams   = "teams"
+	// ChannelPlugin channels hand alerts to a plugin, rendered as for a
+	// webhook channel
+	ChannelPlugin = "plugin"
+)
+
+// DefaultTemplate is used for channels that don't configure their own
+const DefaultTemplate = `{{if .Repeat}}Reminder #{{.Repeat}}{{if .Escalated}} (escalated){{end}}: not yet acknowledged
+{{end}}[{{upper .Event.Severity}}] {{.Rule.Name}}
+{{.Event.Message}}
+{{- if .TopPaths}}
+Top paths:{{range .TopPaths}}
+  {{.Path}} ({{.Count}}){{end}}
+{{- end}}
+{{- if .TopIPs}}
+Top IPs:{{range .TopIPs}}
+  {{.IP}} ({{.Count}}){{end}}
+{{- end}}
+Details: {{.Links.Rule}}
+Acknowledge: POST {{.Links.Acknowledge}}`
+
+// AlertContext is the data available to notification templates
+type AlertContext struct {
+	Rule     *models.AlertRule
+	Event    *models.AlertEvent
+	TopPaths []models.PathStats
+	TopIPs   []models.IPStats
+	Links    Links
+
+	// Repeat counts reminders for an unacknowledged alert, 0 for the first
+	// notification; Escalated alerts are also sent to the escalation channel
+	Repeat    int
+	Escalated bool
+}
+
+// Links are deep links back into the platform for the alert
+type Links struct {
+	Rule        string
+	History     string
+	Logs        string
+	Acknowledge string
+}
+
+// NewLinks builds the deep links for an alert relative to the public base URL
+func NewLinks(baseURL string, event *models.AlertEvent, window time.Duration) Links {
+	baseURL = strings.TrimRight(baseURL, "/")
+	since := event.TriggeredAt.Add(-window).UTC().Format(time.RFC3339)
+
+	return Links{
+		Rule:        fmt.Sprintf("%s/api/v1/alerts/rules/%d/evaluations", baseURL, event.RuleID),
+		History:     baseURL + "/api/v1/alerts/history",
+		Logs:        fmt.Sprintf("%s/api/v1/logs?start_time=%s", baseURL, since),
+		Acknowledge: fmt.Sprintf("%s/api/v1/alerts/history/%d/acknowledge", baseURL, event.ID),
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate compiles a notification template, reporting syntax errors
+func ParseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// Channel is a configured notification destination with its compiled template
+type Channel struct {
+	config.NotificationChannel
+	tmpl *template.Template
+}
+
+// NewChannel validates a channel configuration and compiles its template
+func NewChannel(cfg config.NotificationChannel) (*Channel, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("notification channel name is required")
+	}
+	switch cfg.Type {
+	case ChannelWebhook, ChannelSlack, ChannelTeams:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("channel %s: url is required", cfg.Name)
+		}
+	case ChannelPlugin:
+		if cfg.Plugin == "" {
+			return nil, fmt.Errorf("channel %s: plugin is required", cfg.Name)
+		}
+	default:
+		return nil, fmt.Errorf("channel %s: unsupported type %s", cfg.Name, cfg.Type)
+	}
+
+	tmpl, err := ParseTemplate(cfg.Name, cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: invalid template: %w", cfg.Name, err)
+	}
+	return &Channel{NotificationChannel: cfg, tmpl: tmpl}, nil
+}
+
+// Render executes the channel template and wraps it in the payload format
+// the channel type expects
+func (c *Channel) Render(data *AlertContext) ([]byte, error) {
+	var body bytes.Buffer
+	if err := c.tmpl.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render template for channel %s: %w", c.Name, err)
+	}
+	text := body.String()
+
+	switch c.Type {
+	case ChannelSlack:
+		return json.Marshal(slackPayload(text, data.Event))
+	case ChannelTeams:
+		return json.Marshal(map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "http://schema.org/extensions",
+			"summary":    data.Rule.Name,
+			"themeColor": severityColor(data.Event.Severity),
+			"text":       text,
+		})
+	default:
+		// Webhook templates produce the full request body; without a custom
+		// template send the text alongside the structured alert
+		if c.Template != "" {
+			return body.Bytes(), nil
+		}
+		return json.Marshal(map[string]interface{}{
+			"text":      text,
+			"event":     data.Event,
+			"rule":      data.Rule,
+			"top_paths": data.TopPaths,
+			"top_ips":   data.TopIPs,
+		})
+	}
+}
+
+// AcknowledgeActionID identifies the Slack button that acknowledges an alert
+const AcknowledgeActionID = "acknowledge_alert"
+
+// slackPayload adds an Acknowledge button to stored alerts. Clicks are
+// delivered to the Slack app's interactivity URL, which should point at
+// /api/v1/alerts/slack/actions.
+func slackPayload(text string, event *models.AlertEvent) map[string]interface{} {
+	payload := map[string]interface{}{"text": text}
+	if event.ID == 0 {
+		return payload
+	}
+
+	payload["blocks"] = []map[string]interface{}{
+		{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		},
+		{
+			"type": "actions",
+			"elements": []map[string]interface{}{
+				{
+					"type":      "button",
+					"action_id": AcknowledgeActionID,
+					"text":      map[string]string{"type": "plain_text", "text": "Acknowledge"},
+					"value":     strconv.FormatInt(event.ID, 10),
+					"style":     "primary",
+				},
+			},
+		},
+	}
+	return payload
+}
+
+func severityColor(severity string) string {
+	if severity == "critical" {
+		return "D9534F"
+	}
+	return "F0AD4E"
+}
+
+// Notifier delivers alerts to the configured channels. The escalation
+// channel, if any, only receives escalated alerts.
+type Notifier struct {
+	channels   []*Channel
+	escalation string
+	client     *http.Client
+	plugins    PluginSender
+}
+
+// PluginSender delivers the payload rendered for a plugin channel to the
+// named plugin
+type PluginSender func(plugin, channel string, payload []byte) error
+
+// NewNotifier validates and compiles the configured channels
+func NewNotifier(channels []config.NotificationChannel, escalationChannel string) (*Notifier, error) {
+	notifier := &Notifier{
+		escalation: escalationChannel,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+
+	seen := make(map[string]bool)
+	for _, cfg := range channels {
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate notification channel: %s", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		ch, err := NewChannel(cfg)
+		if err != nil {
+			return nil, err
+		}
+		notifier.channels = append(notifier.channels, ch)
+	}
+
+	if escalationChannel != "" && !seen[escalationChannel] {
+		return nil, fmt.Errorf("escalation channel %s is not configured", escalationChannel)
+	}
+
+	return notifier, nil
+}
+
+// SetPluginSender sets how plugin channels deliver alerts. Until it is
+// set, sending to them fails.
+func (n *Notifier) SetPluginSender(send PluginSender) {
+	n.plugins = send
+}
+
+// Channels returns the configured channels
+func (n *Notifier) Channels() []*Channel {
+	return n.channels
+}
+
+// Channel looks up a channel by name
+func (n *Notifier) Channel(name string) (*Channel, bool) {
+	for _, ch := range n.channels {
+		if ch.Name == name {
+			return ch, true
+		}
+	}
+	return nil, false
+}
+
+// Notify sends the alert to every channel, skipping the escalation channel
+// unless the alert has been escalated, and returns the combined errors
+func (n *Notifier) Notify(data *AlertContext) error {
+	var errs []string
+	for _, ch := range n.channels {
+		if ch.Name == n.escalation && !data.Escalated {
+			continue
+		}
+		if err := n.send(ch, data); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notification failures: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) send(ch *Channel, data *AlertContext) error {
+	payload, err := ch.Render(data)
+	if err != nil {
+		return err
+	}
+
+	if ch.Type == ChannelPlugin {
+		if n.plugins == nil {
+			return fmt.Errorf("channel %s: plugins are not running", ch.Name)
+		}
+		if err := n.plugins(ch.Plugin, ch.Name, payload); err != nil {
+			return fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		return nil
+	}
+
+	resp, err := n.client.Post(ch.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("channel %s: %w", ch.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("channel %s: unexpected status %d", ch.Name, resp.StatusCode)
+	}
+	return nil
+}
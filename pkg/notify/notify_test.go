@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlertContext() *AlertContext {
+	event := &models.AlertEvent{
+		ID:          42,
+		RuleID:      7,
+		RuleName:    "High error rate",
+		Message:     "High error rate: error_rate is 12.50 over the last 60s (threshold 5.00)",
+		Severity:    "critical",
+		Value:       12.5,
+		Threshold:   5,
+		TriggeredAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Window:      map[string]float64{"error_rate": 12.5, "request_count": 80},
+	}
+
+	return &AlertContext{
+		Rule:     &models.AlertRule{ID: 7, Name: "High error rate", ConditionType: "error_rate", TimeWindow: 60},
+		Event:    event,
+		TopPaths: []models.PathStats{{Path: "/api/login", Count: 9}},
+		TopIPs:   []models.IPStats{{IP: "10.0.0.5", Count: 6}},
+		Links:    NewLinks("http://logs.example.com/", event, time.Minute),
+	}
+}
+
+func TestNewLinks(t *testing.T) {
+	links := testAlertContext().Links
+
+	assert.Equal(t, "http://logs.example.com/api/v1/alerts/rules/7/evaluations", links.Rule)
+	assert.Equal(t, "http://logs.example.com/api/v1/alerts/history", links.History)
+	assert.Equal(t, "http://logs.example.com/api/v1/logs?start_time=2024-01-02T09:59:00Z", links.Logs)
+	assert.Equal(t, "http://logs.example.com/api/v1/alerts/history/42/acknowledge", links.Acknowledge)
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	ch, err := NewChannel(config.NotificationChannel{Name: "ops", Type: ChannelSlack, URL: "http://example.com"})
+	require.NoError(t, err)
+
+	payload, err := ch.Render(testAlertContext())
+	require.NoError(t, err)
+
+	var body struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type     string `json:"type"`
+			Elements []struct {
+				ActionID string `json:"action_id"`
+				Value    string `json:"value"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &body))
+	assert.Contains(t, body.Text, "[CRITICAL] High error rate")
+	assert.Contains(t, body.Text, "/api/login (9)")
+	assert.Contains(t, body.Text, "10.0.0.5 (6)")
+	assert.Contains(t, body.Text, "http://logs.example.com/api/v1/alerts/rules/7/evaluations")
+	assert.NotContains(t, body.Text, "Reminder")
+
+	// Stored alerts get an acknowledge button
+	require.Len(t, body.Blocks, 2)
+	require.Len(t, body.Blocks[1].Elements, 1)
+	assert.Equal(t, AcknowledgeActionID, body.Blocks[1].Elements[0].ActionID)
+	assert.Equal(t, "42", body.Blocks[1].Elements[0].Value)
+
+	data := testAlertContext()
+	data.Repeat = 3
+	data.Escalated = true
+	payload, err = ch.Render(data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, &body))
+	assert.Contains(t, body.Text, "Reminder #3 (escalated): not yet acknowledged")
+}
+
+func TestRenderCustomTemplates(t *testing.T) {
+	tmpl := `{{.Rule.Name}} at {{printf "%.1f" (index .Event.Window "error_rate")}}%{{range .TopPaths}} {{.Path}}{{end}}`
+
+	teams, err := NewChannel(config.NotificationChannel{Name: "teams", Type: ChannelTeams, URL: "http://example.com", Template: tmpl})
+	require.NoError(t, err)
+
+	payload, err := teams.Render(testAlertContext())
+	require.NoError(t, err)
+
+	var card map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, "High error rate at 12.5% /api/login", card["text"])
+
+	// Webhook templates control the whole body
+	webhook, err := NewChannel(config.NotificationChannel{
+		Name:     "hook",
+		Type:     ChannelWebhook,
+		URL:      "http://example.com",
+		Template: `{"rule":{{json .Rule.Name}},"value":{{.Event.Value}}}`,
+	})
+	require.NoError(t, err)
+
+	payload, err = webhook.Render(testAlertContext())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"rule":"High error rate","value":12.5}`, string(payload))
+}
+
+func TestNewChannelValidation(t *testing.T) {
+	_, err := NewChannel(config.NotificationChannel{Name: "x", Type: "pager", URL: "http://example.com"})
+	assert.Error(t, err)
+
+	_, err = NewChannel(config.NotificationChannel{Name: "x", Type: ChannelSlack})
+	assert.Error(t, err)
+
+	_, err = NewChannel(config.NotificationChannel{Name: "x", Type: ChannelSlack, URL: "http://example.com", Template: "{{.Rule.Name"})
+	assert.Error(t, err)
+
+	_, err = NewNotifier([]config.NotificationChannel{
+		{Name: "dup", Type: ChannelSlack, URL: "http://example.com"},
+		{Name: "dup", Type: ChannelTeams, URL: "http://example.com"},
+	}, "")
+	assert.Error(t, err)
+
+	_, err = NewNotifier([]config.NotificationChannel{
+		{Name: "ops", Type: ChannelSlack, URL: "http://example.com"},
+	}, "pager")
+	assert.Error(t, err)
+}
+
+func TestNotify(t *testing.T) {
+	var received []string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+	}))
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	notifier, err := NewNotifier([]config.NotificationChannel{
+		{Name: "good", Type: ChannelWebhook, URL: ok.URL, Template: "{{.Rule.Name}}"},
+		{Name: "bad", Type: ChannelSlack, URL: failing.URL},
+		{Name: "pager", Type: ChannelWebhook, URL: ok.URL, Template: "escalated {{.Rule.Name}}"},
+	}, "pager")
+	require.NoError(t, err)
+
+	err = notifier.Notify(testAlertContext())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel bad")
+	assert.Equal(t, []string{"High error rate"}, received)
+
+	// Escalated alerts also go to the escalation channel
+	received = nil
+	data := testAlertContext()
+	data.Escalated = true
+	notifier.Notify(data)
+	assert.Equal(t, []string{"High error rate", "escalated High error rate"}, received)
+}
+
+func TestNotifyPluginChannel(t *testing.T) {
+	_, err := NewChannel(config.NotificationChannel{Name: "x", Type: ChannelPlugin})
+	assert.Error(t, err, "plugin channels name their plugin")
+
+	notifier, err := NewNotifier([]config.NotificationChannel{
+		{Name: "pagerduty", Type: ChannelPlugin, Plugin: "oncall", Template: "{{.Rule.Name}}"},
+	}, "")
+	require.NoError(t, err)
+	assert.ErrorContains(t, notifier.Notify(testAlertContext()), "plugins are not running")
+
+	var sent []string
+	notifier.SetPluginSender(func(plugin, channel string, payload []byte) error {
+		sent = append(sent, plugin+"/"+channel+": "+string(payload))
+		return nil
+	})
+	require.NoError(t, notifier.Notify(testAlertContext()))
+	assert.Equal(t, []string{"oncall/pagerduty: High error rate"}, sent)
+}
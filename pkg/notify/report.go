@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+)
+
+// Report schedules deliveries can subscribe to
+var ReportSchedules = []string{"daily", "weekly"}
+
+// ReportSummary is what the card posted for a generated report shows
+type ReportSummary struct {
+	Title     string
+	TimeRange string
+	// Requests counts the report's requests; ErrorRate is the percentage
+	// of them with a 4xx or 5xx status
+	Requests  int64
+	ErrorRate float64
+	// TopOffender is the source IP with the most error responses and
+	// OffenderErrors their number; empty when there were none
+	TopOffender    string
+	OffenderErrors int64
+	// Link downloads the report's files
+	Link string
+}
+
+// ReportDeliverer posts report summaries to the chat channels subscribed
+// to a report's schedule
+type ReportDeliverer struct {
+	deliveries []config.ReportDelivery
+	client     *http.Client
+}
+
+// NewReportDeliverer validates the configured deliveries
+func NewReportDeliverer(deliveries []config.ReportDelivery) (*ReportDeliverer, error) {
+	seen := make(map[string]bool)
+	for _, delivery := range deliveries {
+		if delivery.Name == "" {
+			return nil, fmt.Errorf("report delivery name is required")
+		}
+		if seen[delivery.Name] {
+			return nil, fmt.Errorf("duplicate report delivery: %s", delivery.Name)
+		}
+		seen[delivery.Name] = true
+
+		if delivery.Type != ChannelSlack && delivery.Type != ChannelTeams {
+			return nil, fmt.Errorf("report delivery %s: unsupported type %s", delivery.Name, delivery.Type)
+		}
+		if delivery.URL == "" {
+			return nil, fmt.Errorf("report delivery %s: url is required", delivery.Name)
+		}
+		if len(delivery.Schedules) == 0 {
+			return nil, fmt.Errorf("report delivery %s: schedules are required", delivery.Name)
+		}
+		for _, schedule := range delivery.Schedules {
+			if !slices.Contains(ReportSchedules, schedule) {
+				return nil, fmt.Errorf("report delivery %s: unknown schedule %s, must be one of: %s",
+					delivery.Name, schedule, strings.Join(ReportSchedules, ", "))
+			}
+		}
+	}
+
+	return &ReportDeliverer{
+		deliveries: deliveries,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Deliver posts the summary of a report of schedule to each delivery
+// subscribed to it and returns the combined errors
+func (d *ReportDeliverer) Deliver(schedule string, summary *ReportSummary) error {
+	var errs []string
+	for _, delivery := range d.deliveries {
+		if !slices.Contains(delivery.Schedules, schedule) {
+			continue
+		}
+		if err := d.post(delivery, summary); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("report delivery failures: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (d *ReportDeliverer) post(delivery config.ReportDelivery, summary *ReportSummary) error {
+	payload, err := RenderReportCard(delivery.Type, summary)
+	if err != nil {
+		return fmt.Errorf("report delivery %s: %w", delivery.Name, err)
+	}
+
+	resp, err := d.client.Post(delivery.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("report delivery %s: %w", delivery.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("report delivery %s: unexpected status %d", delivery.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// RenderReportCard renders a report summary as a Slack message with
+// blocks or a Teams message card
+func RenderReportCard(channelType string, summary *ReportSummary) ([]byte, error) {
+	facts := [][2]string{
+		{"Total requests", fmt.Sprintf("%d", summary.Requests)},
+		{"Error rate", fmt.Sprintf("%.2f%%", summary.ErrorRate)},
+	}
+	if summary.TopOffender != "" {
+		facts = append(facts, [2]string{"Top offender", fmt.Sprintf("%s (%d errors)", summary.TopOffender, summary.OffenderErrors)})
+	}
+	heading := summary.Title
+	if summary.TimeRange != "" {
+		heading += ": " + summary.TimeRange
+	}
+
+	switch channelType {
+	case ChannelSlack:
+		fields := make([]map[string]string, 0, len(facts))
+		var text strings.Builder
+		text.WriteString(heading)
+		for _, fact := range facts {
+			fields = append(fields, map[string]string{"type": "mrkdwn", "text": "*" + fact[0] + "*\n" + fact[1]})
+			fmt.Fprintf(&text, "\n%s: %s", fact[0], fact[1])
+		}
+		blocks := []map[string]interface{}{
+			{"type": "header", "text": map[string]string{"type": "plain_text", "text": heading}},
+			{"type": "section", "fields": fields},
+		}
+		if summary.Link != "" {
+			blocks = append(blocks, map[string]interface{}{
+				"type": "actions",
+				"elements": []map[string]interface{}{
+					{
+						"type": "button",
+						"text": map[string]string{"type": "plain_text", "text": "Download report"},
+						"url":  summary.Link,
+					},
+				},
+			})
+		}
+		// text is the fallback shown in notifications
+		return json.Marshal(map[string]interface{}{"text": text.String(), "blocks": blocks})
+	case ChannelTeams:
+		cardFacts := make([]map[string]string, 0, len(facts))
+		for _, fact := range facts {
+			cardFacts = append(cardFacts, map[string]string{"name": fact[0], "value": fact[1]})
+		}
+		card := map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			"summary":  heading,
+			"title":    heading,
+			"sections": []map[string]interface{}{{"facts": cardFacts}},
+		}
+		if summary.Link != "" {
+			card["potentialAction"] = []map[string]interface{}{
+				{
+					"@type":   "OpenUri",
+					"name":    "Download report",
+					"targets": []map[string]string{{"os": "default", "uri": summary.Link}},
+				},
+			}
+		}
+		return json.Marshal(card)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", channelType)
+	}
+}
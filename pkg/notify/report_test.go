@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+)
+
+func testReportSummary() *ReportSummary {
+	return &ReportSummary{
+		Title:          "Daily Log Analysis Report",
+		TimeRange:      "2024-03-01 to 2024-03-02",
+		Requests:       1200,
+		ErrorRate:      2.5,
+		TopOffender:    "192.0.2.7",
+		OffenderErrors: 18,
+		Link:           "http://logs.example.com/api/v1/reports/daily_2024-03-02_02-00-00/bundle",
+	}
+}
+
+func TestRenderReportCard(t *testing.T) {
+	payload, err := RenderReportCard(ChannelSlack, testReportSummary())
+	require.NoError(t, err)
+
+	var message struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type   string `json:"type"`
+			Fields []struct {
+				Text string `json:"text"`
+			} `json:"fields"`
+			Elements []struct {
+				URL string `json:"url"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &message))
+	assert.Contains(t, message.Text, "Total requests: 1200")
+	require.Len(t, message.Blocks, 3)
+	assert.Equal(t, "header", message.Blocks[0].Type)
+	require.Len(t, message.Blocks[1].Fields, 3)
+	assert.Equal(t, "*Error rate*\n2.50%", message.Blocks[1].Fields[1].Text)
+	assert.Equal(t, "*Top offender*\n192.0.2.7 (18 errors)", message.Blocks[1].Fields[2].Text)
+	assert.Equal(t, testReportSummary().Link, message.Blocks[2].Elements[0].URL)
+
+	payload, err = RenderReportCard(ChannelTeams, testReportSummary())
+	require.NoError(t, err)
+
+	var card map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, "Daily Log Analysis Report: 2024-03-01 to 2024-03-02", card["title"])
+	assert.Contains(t, string(payload), `{"name":"Total requests","value":"1200"}`)
+	assert.Contains(t, string(payload), `"uri":"`+testReportSummary().Link+`"`)
+
+	// Without errors there is no offender to show
+	summary := testReportSummary()
+	summary.TopOffender, summary.OffenderErrors = "", 0
+	payload, err = RenderReportCard(ChannelTeams, summary)
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "Top offender")
+
+	_, err = RenderReportCard(ChannelWebhook, summary)
+	assert.Error(t, err)
+}
+
+func TestNewReportDelivererValidation(t *testing.T) {
+	valid := config.ReportDelivery{Name: "ops", Type: ChannelSlack, URL: "http://example.com", Schedules: []string{"daily"}}
+
+	for _, change := range []func(*config.ReportDelivery){
+		func(d *config.ReportDelivery) { d.Name = "" },
+		func(d *config.ReportDelivery) { d.Type = ChannelWebhook },
+		func(d *config.ReportDelivery) { d.URL = "" },
+		func(d *config.ReportDelivery) { d.Schedules = nil },
+		func(d *config.ReportDelivery) { d.Schedules = []string{"monthly"} },
+	} {
+		delivery := valid
+		change(&delivery)
+		_, err := NewReportDeliverer([]config.ReportDelivery{delivery})
+		assert.Error(t, err)
+	}
+
+	_, err := NewReportDeliverer([]config.ReportDelivery{valid, valid})
+	assert.Error(t, err)
+
+	_, err = NewReportDeliverer([]config.ReportDelivery{valid})
+	assert.NoError(t, err)
+}
+
+func TestDeliverReport(t *testing.T) {
+	var received []string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.URL.Path+" "+string(body))
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	deliverer, err := NewReportDeliverer([]config.ReportDelivery{
+		{Name: "daily", Type: ChannelSlack, URL: ok.URL + "/daily", Schedules: []string{"daily"}},
+		{Name: "both", Type: ChannelTeams, URL: ok.URL + "/both", Schedules: []string{"daily", "weekly"}},
+		{Name: "broken", Type: ChannelSlack, URL: failing.URL, Schedules: []string{"weekly"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, deliverer.Deliver("daily", testReportSummary()))
+	require.Len(t, received, 2)
+	assert.Contains(t, received[0], "/daily ")
+	assert.Contains(t, received[1], "/both ")
+	assert.Contains(t, received[1], "MessageCard")
+
+	// The other deliveries still get the report when one fails
+	received = nil
+	err = deliverer.Deliver("weekly", testReportSummary())
+	assert.ErrorContains(t, err, "broken")
+	require.Len(t, received, 1)
+	assert.Contains(t, received[0], "/both ")
+}
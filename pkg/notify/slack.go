@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// slackRequestMaxAge bounds how old a signed Slack request may be, to
+// prevent replays
+const slackRequestMaxAge = 5 * time.Minute
+
+// VerifySlackSignature checks the X-Slack-Signature of an interactivity
+// request against the app's signing secret
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid slack request timestamp")
+	}
+	age := now.Sub(time.Unix(ts, 0))
+	if age > slackRequestMaxAge || age < -slackRequestMaxAge {
+		return fmt.Errorf("slack request timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid slack signature")
+	}
+	return nil
+}
+
+// SlackAction is an acknowledge button click from a Slack message
+type SlackAction struct {
+	EventID int64
+	User    string
+}
+
+// ParseSlackAcknowledge extracts the acknowledge action from the payload
+// form value of a Slack block_actions request
+func ParseSlackAcknowledge(payload string) (*SlackAction, error) {
+	var req struct {
+		Type string `json:"type"`
+		User struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"user"`
+		Actions []struct {
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return nil, fmt.Errorf("invalid slack payload: %w", err)
+	}
+
+	for _, action := range req.Actions {
+		if action.ActionID != AcknowledgeActionID {
+			continue
+		}
+		id, err := strconv.ParseInt(action.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert ID: %s", action.Value)
+		}
+
+		user := req.User.Username
+		if user == "" {
+			user = req.User.ID
+		}
+		return &SlackAction{EventID: id, User: "slack:" + user}, nil
+	}
+
+	return nil, fmt.Errorf("no acknowledge action in slack payload")
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signSlack(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload=%7B%7D")
+	signature := signSlack("secret", "1700000000", body)
+
+	assert.NoError(t, VerifySlackSignature("secret", "1700000000", signature, body, now))
+	assert.Error(t, VerifySlackSignature("other", "1700000000", signature, body, now))
+	assert.Error(t, VerifySlackSignature("secret", "1700000000", signature, []byte("payload=x"), now))
+	assert.Error(t, VerifySlackSignature("secret", "1700000000", signature, body, now.Add(10*time.Minute)))
+	assert.Error(t, VerifySlackSignature("secret", "not-a-time", signature, body, now))
+}
+
+func TestParseSlackAcknowledge(t *testing.T) {
+	action, err := ParseSlackAcknowledge(`{"type":"block_actions","user":{"id":"U1","username":"alice"},"actions":[{"action_id":"acknowledge_alert","value":"42"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), action.EventID)
+	assert.Equal(t, "slack:alice", action.User)
+
+	_, err = ParseSlackAcknowledge(`{"actions":[{"action_id":"other","value":"42"}]}`)
+	assert.Error(t, err)
+
+	_, err = ParseSlackAcknowledge(`{"actions":[{"action_id":"acknowledge_alert","value":"x"}]}`)
+	assert.Error(t, err)
+}
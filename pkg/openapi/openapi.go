@@ -0,0 +1,321 @@
+// Package openapi builds OpenAPI 3 documents describing an HTTP API. The
+// schemas of request and response bodies are derived from the Go types
+// the API encodes as JSON, so the document follows them as they change.
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI version of documents built by this package
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+
+	// types are the Go types of the named schemas
+	types map[string]reflect.Type
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds a path's operations by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one method of a path
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// Security overrides the document's; an empty list means none
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path", "query" or "header"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's body by media type
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema. Ref refers to a schema of the document's
+// components and excludes the other fields.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the document's named schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way clients authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"` // "http" or "apiKey"
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// SecurityRequirement names security schemes that together authenticate
+// a request
+type SecurityRequirement map[string][]string
+
+// New returns an empty document
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// pathParam matches a parameter of a path template such as /users/{id},
+// which may have a pattern as in /reports/{name:.+}
+var pathParam = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Add adds an operation to a path. The path's parameters are added to the
+// operation unless it describes them, and its ID defaults to one made of
+// the method and path.
+func (d *Document) Add(method, path string, op *Operation) {
+	path = pathParam.ReplaceAllString(path, "{$1}")
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		if !hasParameter(op.Parameters, match[1], "path") {
+			op.Parameters = append(op.Parameters, &Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, path)
+	}
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{"default": {Description: "Response"}}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+func hasParameter(params []*Parameter, name, in string) bool {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// operationID makes an ID such as get_api_v1_logs_id from a method and
+// path
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		id += "_" + part
+	}
+	return id
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	rawType    = reflect.TypeOf(json.RawMessage{})
+	marshaler  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	durationTy = reflect.TypeOf(time.Duration(0))
+)
+
+// Schema returns the schema of v's type as encoding/json encodes it.
+// Named struct types are added to the document's components under their
+// type name and referred to, so each is described once.
+func (d *Document) Schema(v interface{}) *Schema {
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+// Ref returns a reference to the named schema of the document's
+// components
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawType:
+		return &Schema{}
+	case durationTy:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := d.schemaOf(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		// Types that encode themselves are described by what they encode
+		if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+			return &Schema{}
+		}
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return Ref(d.register(t))
+	default:
+		// Interfaces hold any value
+		return &Schema{}
+	}
+}
+
+// register adds a named struct type to the components and returns its
+// schema's name: the type's name, qualified by its package's when another
+// package's type has it
+func (d *Document) register(t reflect.Type) string {
+	name := t.Name()
+	if other, ok := d.types[name]; ok && other != t {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	if _, ok := d.types[name]; ok {
+		return name
+	}
+	// Registered before its fields, so types that refer to themselves end
+	d.types[name] = t
+	schema := &Schema{}
+	d.Components.Schemas[name] = schema
+	*schema = *d.structSchema(t)
+	return name
+}
+
+// structSchema describes a struct's encoded fields, with those of
+// embedded structs inlined
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, property := range d.structSchema(embedded).Properties {
+					schema.Properties[key] = property
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := d.schemaOf(field.Type)
+		if strings.Contains(opts, "string") && property.Ref == "" {
+			property = &Schema{Type: "string", Nullable: property.Nullable}
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
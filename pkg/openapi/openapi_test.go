@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type entry struct {
+	base
+	Timestamp time.Time              `json:"timestamp"`
+	Status    *int                   `json:"status,omitempty"`
+	Tags      []string               `json:"tags"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	Parent    *entry                 `json:"parent"`
+	Secret    string                 `json:"-"`
+	Count     int64                  `json:"count,string"`
+	hidden    bool
+}
+
+func TestSchemaOfStruct(t *testing.T) {
+	doc := New("Test", "1.0")
+	assert.Equal(t, Ref("entry"), doc.Schema(entry{}))
+	assert.Equal(t, &Schema{Type: "array", Items: Ref("entry")}, doc.Schema([]*entry{}))
+
+	schema := doc.Components.Schemas["entry"]
+	require.NotNil(t, schema)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, schema.Properties["id"], "embedded fields are inlined")
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["timestamp"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32", Nullable: true}, schema.Properties["status"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{}}, schema.Properties["metadata"])
+	assert.Equal(t, Ref("entry"), schema.Properties["parent"], "a type can refer to itself")
+	assert.Equal(t, &Schema{Type: "string"}, schema.Properties["count"])
+	assert.NotContains(t, schema.Properties, "Secret")
+	assert.NotContains(t, schema.Properties, "hidden")
+	assert.Len(t, schema.Properties, 7)
+}
+
+func TestSchemaOfAnonymousStruct(t *testing.T) {
+	doc := New("Test", "1.0")
+	schema := doc.Schema(struct {
+		Name string `json:"name"`
+	}{})
+	assert.Equal(t, &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}, schema)
+	assert.Empty(t, doc.Components.Schemas)
+}
+
+func TestAddDescribesPathParameters(t *testing.T) {
+	doc := New("Test", "1.0")
+	doc.Add("GET", "/reports/{id}/files/{name:.+}", &Operation{
+		Parameters: []*Parameter{{Name: "id", In: "path", Description: "Report ID", Required: true, Schema: &Schema{Type: "string"}}},
+	})
+
+	op := doc.Paths["/reports/{id}/files/{name}"]["get"]
+	require.NotNil(t, op)
+	assert.Equal(t, "get_reports_id_files_name", op.OperationID)
+	require.Len(t, op.Parameters, 2)
+	assert.Equal(t, "Report ID", op.Parameters[0].Description)
+	assert.Equal(t, "name", op.Parameters[1].Name)
+	assert.True(t, op.Parameters[1].Required)
+	assert.Contains(t, op.Responses, "default")
+}
+
+func TestDocumentJSON(t *testing.T) {
+	doc := New("Test", "1.0")
+	doc.Add("POST", "/items", &Operation{
+		RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: doc.Schema(entry{})}}},
+		Responses:   map[string]*Response{"201": {Description: "Created"}},
+	})
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, Version, decoded["openapi"])
+	assert.NotContains(t, decoded, "types")
+	assert.Contains(t, string(data), `"$ref":"#/components/schemas/entry"`)
+	assert.Contains(t, string(data), `"operationId":"post_items"`)
+}
@@ -0,0 +1,323 @@
+package patterns
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Wildcard marks a template position where messages differ
+const Wildcard = "<*>"
+
+// Default miner settings, following the Drain paper
+const (
+	DefaultDepth       = 4
+	DefaultSimilarity  = 0.4
+	DefaultMaxChildren = 100
+	DefaultMaxExamples = 3
+)
+
+// Pattern is a message template with the number of messages it matched.
+// ID is derived from the pattern's first message and stays the same while
+// the template generalises, so it identifies the pattern for the miner's life.
+type Pattern struct {
+	ID        string    `json:"id"`
+	Template  string    `json:"template"`
+	Count     int64     `json:"count"`
+	Examples  []string  `json:"examples"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Miner groups similar log messages into templates using the Drain
+// fixed-depth parse tree: messages are routed by token count and their
+// leading tokens, then matched against the templates in that leaf.
+type Miner struct {
+	mu          sync.Mutex
+	depth       int
+	similarity  float64
+	maxChildren int
+	maxExamples int
+	root        *node
+	clusters    []*cluster
+	byID        map[string]*cluster
+}
+
+type node struct {
+	children map[string]*node
+	clusters []*cluster
+}
+
+type cluster struct {
+	id        string
+	tokens    []string
+	count     int64
+	examples  []string
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// NewMiner creates a miner with the default settings
+func NewMiner() *Miner {
+	return &Miner{
+		depth:       DefaultDepth,
+		similarity:  DefaultSimilarity,
+		maxChildren: DefaultMaxChildren,
+		maxExamples: DefaultMaxExamples,
+		root:        newNode(),
+		byID:        make(map[string]*cluster),
+	}
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+// Add assigns a message to a pattern, creating one if no existing template
+// is similar enough. It returns the resulting pattern and whether it is new.
+func (m *Miner) Add(message string, seen time.Time) (Pattern, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, created := m.add(message, seen)
+	return c.pattern(), created
+}
+
+// Assign is Add without building the resulting pattern, for callers on the
+// ingestion path that only need the pattern ID
+func (m *Miner) Assign(message string, seen time.Time) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, created := m.add(message, seen)
+	return c.id, created
+}
+
+// Pattern returns the pattern with the given ID
+func (m *Miner) Pattern(id string) (Pattern, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.byID[id]
+	if !ok {
+		return Pattern{}, false
+	}
+	return c.pattern(), true
+}
+
+func (m *Miner) add(message string, seen time.Time) (*cluster, bool) {
+	tokens := Tokenize(message)
+	leaf := m.leaf(tokens)
+	c := m.bestMatch(leaf.clusters, tokens)
+	created := c == nil
+	if created {
+		c = &cluster{
+			id:        m.newID(strings.Join(tokens, " ")),
+			tokens:    append([]string(nil), tokens...),
+			firstSeen: seen,
+		}
+		leaf.clusters = append(leaf.clusters, c)
+		m.clusters = append(m.clusters, c)
+		m.byID[c.id] = c
+	} else {
+		for i, token := range tokens {
+			if c.tokens[i] != token {
+				c.tokens[i] = Wildcard
+			}
+		}
+	}
+
+	c.count++
+	if seen.After(c.lastSeen) {
+		c.lastSeen = seen
+	}
+	if seen.Before(c.firstSeen) {
+		c.firstSeen = seen
+	}
+	if len(c.examples) < m.maxExamples {
+		c.examples = append(c.examples, message)
+	}
+
+	return c, created
+}
+
+// newID derives a pattern ID from its first template, disambiguating the
+// rare case where a different pattern already started from the same text
+func (m *Miner) newID(template string) string {
+	id := TemplateID(template)
+	for n := 1; m.byID[id] != nil; n++ {
+		id = TemplateID(fmt.Sprintf("%s#%d", template, n))
+	}
+	return id
+}
+
+// Patterns returns all patterns, most frequent first
+func (m *Miner) Patterns() []Pattern {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	patterns := make([]Pattern, 0, len(m.clusters))
+	for _, c := range m.clusters {
+		patterns = append(patterns, c.pattern())
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+// Top returns the n most frequent patterns
+func (m *Miner) Top(n int) []Pattern {
+	patterns := m.Patterns()
+	if len(patterns) > n {
+		patterns = patterns[:n]
+	}
+	return patterns
+}
+
+// leaf walks the parse tree for a token sequence, growing it as needed
+func (m *Miner) leaf(tokens []string) *node {
+	current := m.child(m.root, fmt.Sprintf("len:%d", len(tokens)))
+
+	for i := 0; i < m.depth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if hasDigit(key) {
+			key = Wildcard
+		}
+		current = m.child(current, key)
+	}
+	return current
+}
+
+func (m *Miner) child(parent *node, key string) *node {
+	if n, ok := parent.children[key]; ok {
+		return n
+	}
+	// Bound the fan-out so variable leading tokens don't explode the tree
+	if len(parent.children) >= m.maxChildren {
+		key = Wildcard
+		if n, ok := parent.children[key]; ok {
+			return n
+		}
+	}
+
+	n := newNode()
+	parent.children[key] = n
+	return n
+}
+
+// bestMatch returns the most similar cluster above the similarity
+// threshold, preferring more general templates on ties
+func (m *Miner) bestMatch(clusters []*cluster, tokens []string) *cluster {
+	var best *cluster
+	bestScore, bestWildcards := -1.0, -1
+
+	for _, c := range clusters {
+		score, wildcards := similarity(c.tokens, tokens)
+		if score > bestScore || (score == bestScore && wildcards > bestWildcards) {
+			best, bestScore, bestWildcards = c, score, wildcards
+		}
+	}
+
+	if best == nil || bestScore < m.similarity {
+		return nil
+	}
+	return best
+}
+
+// similarity is the share of positions where the template matches the
+// message exactly; wildcard positions neither match nor penalise
+func similarity(template, tokens []string) (float64, int) {
+	if len(tokens) == 0 {
+		return 1, 0
+	}
+
+	equal, wildcards := 0, 0
+	for i, token := range template {
+		if token == Wildcard {
+			wildcards++
+			continue
+		}
+		if token == tokens[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens)), wildcards
+}
+
+func (c *cluster) pattern() Pattern {
+	template := strings.Join(c.tokens, " ")
+	return Pattern{
+		ID:        c.id,
+		Template:  template,
+		Count:     c.count,
+		Examples:  append([]string(nil), c.examples...),
+		FirstSeen: c.firstSeen,
+		LastSeen:  c.lastSeen,
+	}
+}
+
+// TemplateID is a short stable identifier for a template
+func TemplateID(template string) string {
+	h := fnv.New64a()
+	h.Write([]byte(template))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Variable tokens are replaced with typed placeholders before mining so
+// messages that differ only in IDs, addresses or numbers share a template
+var masks = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`), "<UUID>"},
+	{regexp.MustCompile(`^\d{1,3}(\.\d{1,3}){3}(:\d+)?$`), "<IP>"},
+	{regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`), "<EMAIL>"},
+	{regexp.MustCompile(`^(0x[0-9a-fA-F]+|[0-9a-fA-F]{12,})$`), "<HEX>"},
+	{regexp.MustCompile(`^[-+]?\d+([.,]\d+)?([a-zA-Zµ%]{1,3})?$`), "<NUM>"},
+}
+
+// Tokenize splits a message on whitespace and masks variable tokens.
+// Surrounding punctuation is kept so "host=10.0.0.1," still masks the address.
+func Tokenize(message string) []string {
+	fields := strings.Fields(message)
+	tokens := make([]string, len(fields))
+	for i, field := range fields {
+		tokens[i] = maskToken(field)
+	}
+	return tokens
+}
+
+func maskToken(token string) string {
+	// Mask the value of key=value tokens independently of the key
+	if key, value, ok := strings.Cut(token, "="); ok && key != "" && value != "" {
+		return key + "=" + maskToken(value)
+	}
+
+	start := strings.IndexFunc(token, isTokenChar)
+	if start < 0 {
+		return token
+	}
+	end := strings.LastIndexFunc(token, isTokenChar) + 1
+	if core := token[start:end]; hasDigit(core) || strings.Contains(core, "@") {
+		for _, mask := range masks {
+			if mask.pattern.MatchString(core) {
+				return token[:start] + mask.placeholder + token[end:]
+			}
+		}
+	}
+	return token
+}
+
+func isTokenChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
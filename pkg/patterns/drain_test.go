@@ -0,0 +1,83 @@
+package patterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeMasksVariables(t *testing.T) {
+	tokens := Tokenize(`request 550e8400-e29b-41d4-a716-446655440000 from 10.0.0.1:5432, took 12ms user=bob@example.com addr=0xdeadbeef size=42`)
+	assert.Equal(t, []string{
+		"request", "<UUID>", "from", "<IP>,", "took", "<NUM>",
+		"user=<EMAIL>", "addr=<HEX>", "size=<NUM>",
+	}, tokens)
+
+	assert.Equal(t, []string{"(<NUM>)", "v2", "done."}, Tokenize("(3) v2 done."))
+}
+
+func TestMinerGroupsSimilarMessages(t *testing.T) {
+	miner := NewMiner()
+	base := time.Date(2023, 10, 10, 13, 0, 0, 0, time.UTC)
+
+	first, created := miner.Add("failed to connect to db1.internal", base)
+	assert.True(t, created)
+	assert.Equal(t, "failed to connect to db1.internal", first.Template)
+
+	merged, created := miner.Add("failed to connect to cache-2.internal", base.Add(time.Minute))
+	assert.False(t, created)
+	assert.Equal(t, "failed to connect to <*>", merged.Template)
+
+	miner.Add("failed to connect to queue.internal", base.Add(2*time.Minute))
+	miner.Add("user 42 logged in", base)
+	miner.Add("user 7 logged in", base)
+
+	patterns := miner.Patterns()
+	require.Len(t, patterns, 2)
+
+	assert.Equal(t, "failed to connect to <*>", patterns[0].Template)
+	assert.Equal(t, int64(3), patterns[0].Count)
+	assert.Len(t, patterns[0].Examples, 3)
+	assert.Equal(t, base, patterns[0].FirstSeen)
+	assert.Equal(t, base.Add(2*time.Minute), patterns[0].LastSeen)
+	// The ID is kept from the first message as the template generalises
+	assert.Equal(t, first.ID, patterns[0].ID)
+	assert.Equal(t, TemplateID("failed to connect to db1.internal"), patterns[0].ID)
+
+	pattern, ok := miner.Pattern(first.ID)
+	require.True(t, ok)
+	assert.Equal(t, "failed to connect to <*>", pattern.Template)
+
+	id, created := miner.Assign("user 9 logged in", base)
+	assert.False(t, created)
+	assert.Equal(t, patterns[1].ID, id)
+
+	assert.Equal(t, "user <NUM> logged in", patterns[1].Template)
+	assert.Equal(t, int64(2), patterns[1].Count)
+}
+
+func TestMinerKeepsDissimilarMessagesApart(t *testing.T) {
+	miner := NewMiner()
+	now := time.Now()
+
+	miner.Add("cache warmed in 3s", now)
+	miner.Add("cache miss for key session", now)
+	miner.Add("disk almost full on /var", now)
+
+	assert.Len(t, miner.Patterns(), 3)
+	assert.Len(t, miner.Top(2), 2)
+}
+
+func TestMinerLimitsExamples(t *testing.T) {
+	miner := NewMiner()
+	for i := 0; i < 10; i++ {
+		miner.Add("job finished", time.Now())
+	}
+
+	patterns := miner.Patterns()
+	require.Len(t, patterns, 1)
+	assert.Equal(t, int64(10), patterns[0].Count)
+	assert.Len(t, patterns[0].Examples, DefaultMaxExamples)
+}
@@ -0,0 +1,509 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// States of a plugin
+const (
+	StateStarting   = "starting"
+	StateRunning    = "running"
+	StateRestarting = "restarting"
+	StateStopped    = "stopped"
+)
+
+const (
+	defaultTimeout = 5 * time.Second
+	// Restarts wait minBackoff, doubling up to maxBackoff while the plugin
+	// keeps failing
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	// exportQueueSize bounds batches waiting for an exporter; beyond it
+	// batches are dropped rather than slowing ingestion
+	exportQueueSize = 100
+	// maxLogLine is the longest line of standard error logged as one
+	maxLogLine = 64 << 10
+)
+
+// ErrNotRunning is returned for calls to a plugin that is restarting or
+// stopped
+var ErrNotRunning = errors.New("plugin is not running")
+
+// Logger receives the messages of plugins and their supervision;
+// *logrus.Logger satisfies it
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// Status describes a plugin, what it provides and how its calls fare.
+// Dropped counts entries not exported because the exporter fell behind or
+// failed.
+type Status struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Version   string     `json:"version,omitempty"`
+	Parsers   []string   `json:"parsers,omitempty"`
+	Enricher  bool       `json:"enricher"`
+	Notifier  bool       `json:"notifier"`
+	Exporter  bool       `json:"exporter"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Restarts  int        `json:"restarts"`
+	LastError string     `json:"last_error,omitempty"`
+	Calls     int64      `json:"calls"`
+	Failures  int64      `json:"failures"`
+	Exported  int64      `json:"exported"`
+	Dropped   int64      `json:"dropped"`
+}
+
+// Client is the server's connection to one plugin. While the manager runs
+// it, a plugin that exits or disconnects is restarted or reconnected.
+type Client struct {
+	cfg     config.PluginConfig
+	timeout time.Duration
+	logger  Logger
+
+	mu        sync.Mutex
+	conn      *connection
+	desc      Description
+	state     string
+	startedAt time.Time
+	restarts  int
+	lastError string
+
+	calls    atomic.Int64
+	failures atomic.Int64
+	exported atomic.Int64
+	dropped  atomic.Int64
+	exports  chan []*models.LogEntry
+}
+
+func newClient(cfg config.PluginConfig, logger Logger) *Client {
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	return &Client{
+		cfg:     cfg,
+		timeout: timeout,
+		logger:  logger,
+		state:   StateStarting,
+		exports: make(chan []*models.LogEntry, exportQueueSize),
+	}
+}
+
+// Name returns the name the plugin is configured under
+func (c *Client) Name() string {
+	return c.cfg.Name
+}
+
+// Description returns what the plugin declared when it first started.
+// What it provides is fixed until the server restarts.
+func (c *Client) Description() Description {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.desc
+}
+
+// Parse parses a line of one of the plugin's log types
+func (c *Client) Parse(logType, line string) (*models.LogEntry, error) {
+	var reply ParseReply
+	if err := c.call(MethodParse, ParseArgs{LogType: logType, Line: line}, &reply); err != nil {
+		return nil, err
+	}
+	if entry := reply.Entry; entry != nil {
+		if entry.LogType == "" {
+			entry.LogType = logType
+		}
+		if entry.RawLog == "" {
+			entry.RawLog = line
+		}
+	}
+	return reply.Entry, nil
+}
+
+// Enrich sends entries to the plugin and returns the entries it wants
+// stored
+func (c *Client) Enrich(entries []*models.LogEntry) ([]*models.LogEntry, error) {
+	var reply Entries
+	if err := c.call(MethodEnrich, Entries{Entries: entries}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Entries, nil
+}
+
+// Notify delivers a rendered alert to the plugin
+func (c *Client) Notify(channel string, body []byte) error {
+	return c.call(MethodNotify, NotifyArgs{Channel: channel, Body: string(body)}, &Empty{})
+}
+
+// export queues entries for the plugin's exporter, dropping them if too
+// many batches are waiting
+func (c *Client) export(entries []*models.LogEntry) {
+	select {
+	case c.exports <- entries:
+	default:
+		c.dropped.Add(int64(len(entries)))
+	}
+}
+
+// runExports sends queued batches to the exporter until ctx is done
+func (c *Client) runExports(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entries := <-c.exports:
+			if err := c.call(MethodExport, Entries{Entries: entries}, &Empty{}); err != nil {
+				c.dropped.Add(int64(len(entries)))
+				c.logger.Warnf("Failed to export %d log entries: %v", len(entries), err)
+				continue
+			}
+			c.exported.Add(int64(len(entries)))
+		}
+	}
+}
+
+// Restart disconnects from the plugin, which is then restarted, or
+// reconnected to, as if it had failed
+func (c *Client) Restart() error {
+	c.mu.Lock()
+	cn := c.conn
+	c.mu.Unlock()
+	if cn == nil {
+		return fmt.Errorf("plugin %s: %w", c.cfg.Name, ErrNotRunning)
+	}
+	cn.fail(errors.New("restart requested"))
+	return nil
+}
+
+// Status returns the plugin's state and counters
+func (c *Client) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := Status{
+		Name:      c.cfg.Name,
+		State:     c.state,
+		Version:   c.desc.Version,
+		Parsers:   c.desc.Parsers,
+		Enricher:  c.desc.Enricher,
+		Notifier:  c.desc.Notifier,
+		Exporter:  c.desc.Exporter,
+		Restarts:  c.restarts,
+		LastError: c.lastError,
+		Calls:     c.calls.Load(),
+		Failures:  c.failures.Load(),
+		Exported:  c.exported.Load(),
+		Dropped:   c.dropped.Load(),
+	}
+	if c.state == StateRunning {
+		startedAt := c.startedAt
+		status.StartedAt = &startedAt
+	}
+	return status
+}
+
+// call makes a call on the current connection
+func (c *Client) call(method string, args, reply interface{}) error {
+	c.mu.Lock()
+	cn := c.conn
+	c.mu.Unlock()
+	if cn == nil || cn.failed() {
+		return fmt.Errorf("plugin %s: %w", c.cfg.Name, ErrNotRunning)
+	}
+
+	c.calls.Add(1)
+	if err := c.callConn(cn, method, args, reply); err != nil {
+		c.failures.Add(1)
+		return fmt.Errorf("plugin %s: %w", c.cfg.Name, err)
+	}
+	return nil
+}
+
+// callConn makes a call within the plugin's timeout. Errors other than
+// those the plugin returned, or arguments that cannot be encoded, mean the
+// connection is broken and the plugin is restarted.
+func (c *Client) callConn(cn *connection, method string, args, reply interface{}) error {
+	call := cn.rpc.Go(method, args, reply, make(chan *rpc.Call, 1))
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-call.Done:
+	case <-timer.C:
+		return fmt.Errorf("%s timed out after %s", method, c.timeout)
+	}
+	if call.Error == nil {
+		return nil
+	}
+
+	var serverErr rpc.ServerError
+	var unsupportedValue *json.UnsupportedValueError
+	var unsupportedType *json.UnsupportedTypeError
+	if !errors.As(call.Error, &serverErr) && !errors.As(call.Error, &unsupportedValue) && !errors.As(call.Error, &unsupportedType) {
+		cn.fail(call.Error)
+	}
+	return call.Error
+}
+
+// connect starts or dials the plugin and asks it to describe itself
+func (c *Client) connect() error {
+	cn, err := c.dial()
+	if err != nil {
+		return err
+	}
+
+	var desc Description
+	args := DescribeArgs{Name: c.cfg.Name, Config: c.cfg.Config}
+	if err := c.callConn(cn, MethodDescribe, args, &desc); err != nil {
+		cn.close(c.timeout)
+		return fmt.Errorf("describe: %w", err)
+	}
+	if desc.ProtocolVersion != ProtocolVersion {
+		cn.close(c.timeout)
+		return fmt.Errorf("plugin speaks protocol version %d, the server version %d", desc.ProtocolVersion, ProtocolVersion)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == StateStarting {
+		c.desc = desc
+	} else if desc.Version != c.desc.Version || !sameCapabilities(desc, c.desc) {
+		c.logger.Warnf("Plugin %s now describes itself as version %s; new capabilities take effect when the server restarts", c.cfg.Name, desc.Version)
+	}
+	c.conn = cn
+	c.state = StateRunning
+	c.startedAt = time.Now()
+	return nil
+}
+
+func sameCapabilities(a, b Description) bool {
+	a.Version, b.Version = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// dial connects to the plugin's address, or starts its command with pipes
+// for standard input and output
+func (c *Client) dial() (*connection, error) {
+	if c.cfg.Address != "" {
+		conn, err := net.DialTimeout("tcp", c.cfg.Address, c.timeout)
+		if err != nil {
+			return nil, err
+		}
+		return &connection{rpc: jsonrpc.NewClient(conn), lost: make(chan struct{})}, nil
+	}
+
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdinW.Close()
+		return nil, err
+	}
+
+	cmd := exec.Command(c.cfg.Command, c.cfg.Args...)
+	cmd.Stdin = stdinR
+	cmd.Stdout = stdoutW
+	cmd.Stderr = &lineWriter{log: func(line string) { c.logger.Infof("Plugin %s: %s", c.cfg.Name, line) }}
+	err = cmd.Start()
+	// The plugin has its own copies of its ends of the pipes
+	stdinR.Close()
+	stdoutW.Close()
+	if err != nil {
+		stdinW.Close()
+		stdoutR.Close()
+		return nil, err
+	}
+
+	cn := &connection{
+		rpc:    jsonrpc.NewClient(&pipe{r: stdoutR, w: stdinW}),
+		cmd:    cmd,
+		exited: make(chan struct{}),
+		lost:   make(chan struct{}),
+	}
+	go func() {
+		err := cmd.Wait()
+		if err == nil {
+			err = errors.New("plugin exited")
+		} else {
+			err = fmt.Errorf("plugin exited: %w", err)
+		}
+		cn.fail(err)
+		close(cn.exited)
+	}()
+	return cn, nil
+}
+
+// supervise restarts the plugin whenever its connection is lost, until ctx
+// is done
+func (c *Client) supervise(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		c.mu.Lock()
+		cn := c.conn
+		c.mu.Unlock()
+
+		if cn != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cn.lost:
+			}
+			cn.close(c.timeout)
+
+			c.mu.Lock()
+			c.conn = nil
+			c.state = StateRestarting
+			c.lastError = cn.err.Error()
+			// Only plugins that keep failing soon after starting back off
+			if time.Since(c.startedAt) > maxBackoff {
+				backoff = minBackoff
+			}
+			c.mu.Unlock()
+			c.logger.Warnf("Plugin %s stopped, restarting in %s: %v", c.cfg.Name, backoff, cn.err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if err := c.connect(); err != nil {
+			backoff = min(2*backoff, maxBackoff)
+			c.mu.Lock()
+			c.lastError = err.Error()
+			c.mu.Unlock()
+			c.logger.Warnf("Failed to restart plugin %s, retrying in %s: %v", c.cfg.Name, backoff, err)
+			continue
+		}
+		backoff = min(2*backoff, maxBackoff)
+
+		c.mu.Lock()
+		c.restarts++
+		c.mu.Unlock()
+		c.logger.Infof("Plugin %s restarted", c.cfg.Name)
+	}
+}
+
+// shutdown asks the plugin to shut down and disconnects from it
+func (c *Client) shutdown() {
+	c.mu.Lock()
+	cn := c.conn
+	c.conn = nil
+	c.state = StateStopped
+	c.mu.Unlock()
+	if cn == nil {
+		return
+	}
+
+	if err := c.callConn(cn, MethodShutdown, Empty{}, &Empty{}); err != nil {
+		c.logger.Warnf("Plugin %s did not shut down cleanly: %v", c.cfg.Name, err)
+	}
+	cn.close(c.timeout)
+}
+
+// connection is one connection to a plugin, and its process if the server
+// started it
+type connection struct {
+	rpc    *rpc.Client
+	cmd    *exec.Cmd
+	exited chan struct{} // closed once cmd exits
+	lost   chan struct{} // closed once the connection fails, with err
+	once   sync.Once
+	err    error
+}
+
+func (cn *connection) fail(err error) {
+	cn.once.Do(func() {
+		cn.err = err
+		close(cn.lost)
+	})
+}
+
+// failed reports whether the connection has failed
+func (cn *connection) failed() bool {
+	select {
+	case <-cn.lost:
+		return true
+	default:
+		return false
+	}
+}
+
+// close disconnects, giving a started plugin the timeout to exit before
+// killing it
+func (cn *connection) close(timeout time.Duration) {
+	cn.fail(errors.New("disconnected"))
+	cn.rpc.Close()
+	if cn.cmd == nil {
+		return
+	}
+
+	select {
+	case <-cn.exited:
+	case <-time.After(timeout):
+		cn.cmd.Process.Kill()
+		<-cn.exited
+	}
+}
+
+// pipe joins the plugin's standard output and input into a connection
+type pipe struct {
+	r *os.File
+	w *os.File
+}
+
+func (p *pipe) Read(b []byte) (int, error)  { return p.r.Read(b) }
+func (p *pipe) Write(b []byte) (int, error) { return p.w.Write(b) }
+
+func (p *pipe) Close() error {
+	werr := p.w.Close()
+	if err := p.r.Close(); err != nil {
+		return err
+	}
+	return werr
+}
+
+// lineWriter logs what a plugin writes to standard error line by line
+type lineWriter struct {
+	log func(line string)
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimRight(w.buf[:i], "\r"); len(line) > 0 {
+			w.log(string(line))
+		}
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxLogLine {
+		w.log(string(w.buf))
+		w.buf = nil
+	}
+	return len(p), nil
+}
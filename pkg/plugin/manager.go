@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Manager is the registry of configured plugins. It starts them, keeps
+// them running and routes the server's calls to the plugins providing each
+// extension.
+type Manager struct {
+	clients []*Client
+	logger  Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager registers the configured plugins without starting them
+func NewManager(plugins []config.PluginConfig, logger Logger) (*Manager, error) {
+	m := &Manager{logger: logger}
+	seen := make(map[string]bool, len(plugins))
+	for _, cfg := range plugins {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("plugin name is required")
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate plugin: %s", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		if (cfg.Command == "") == (cfg.Address == "") {
+			return nil, fmt.Errorf("plugin %s requires either command or address", cfg.Name)
+		}
+		m.clients = append(m.clients, newClient(cfg, logger))
+	}
+	return m, nil
+}
+
+// Enabled reports whether any plugins are configured
+func (m *Manager) Enabled() bool {
+	return len(m.clients) > 0
+}
+
+// Start starts every plugin and learns what each provides. If one fails
+// to start, those already started are stopped. Plugins are then restarted
+// whenever they fail, until Close.
+func (m *Manager) Start(ctx context.Context) error {
+	for i, c := range m.clients {
+		if err := c.connect(); err != nil {
+			for _, started := range m.clients[:i] {
+				started.shutdown()
+			}
+			return fmt.Errorf("failed to start plugin %s: %w", c.Name(), err)
+		}
+		m.logger.Infof("Started plugin %s %s", c.Name(), c.Description().Version)
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	for _, c := range m.clients {
+		c := c
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			c.supervise(ctx)
+		}()
+		if c.Description().Exporter {
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				c.runExports(ctx)
+			}()
+		}
+	}
+	return nil
+}
+
+// Close stops supervising the plugins and shuts them down
+func (m *Manager) Close() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	for _, c := range m.clients {
+		c.shutdown()
+	}
+}
+
+// Plugin looks up a plugin by name
+func (m *Manager) Plugin(name string) (*Client, bool) {
+	for _, c := range m.clients {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Plugins returns the plugins in configuration order
+func (m *Manager) Plugins() []*Client {
+	return m.clients
+}
+
+// Status returns the status of every plugin
+func (m *Manager) Status() []Status {
+	statuses := make([]Status, 0, len(m.clients))
+	for _, c := range m.clients {
+		statuses = append(statuses, c.Status())
+	}
+	return statuses
+}
+
+// Enrich passes entries through each enricher in configuration order. An
+// enricher that fails is skipped, so its errors never lose entries.
+func (m *Manager) Enrich(entries []*models.LogEntry) ([]*models.LogEntry, error) {
+	var errs []error
+	for _, c := range m.clients {
+		if len(entries) == 0 {
+			break
+		}
+		if !c.Description().Enricher {
+			continue
+		}
+		enriched, err := c.Enrich(entries)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		entries = enriched
+	}
+	return entries, errors.Join(errs...)
+}
+
+// Export queues stored entries for every exporter
+func (m *Manager) Export(entries []*models.LogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	for _, c := range m.clients {
+		if c.Description().Exporter {
+			c.export(entries)
+		}
+	}
+}
+
+// Notify delivers an alert rendered for a notification channel to the
+// plugin behind it
+func (m *Manager) Notify(plugin, channel string, body []byte) error {
+	c, ok := m.Plugin(plugin)
+	if !ok {
+		return fmt.Errorf("plugin %s is not configured", plugin)
+	}
+	if !c.Description().Notifier {
+		return fmt.Errorf("plugin %s does not deliver notifications", plugin)
+	}
+	return c.Notify(channel, body)
+}
@@ -0,0 +1,89 @@
+// Package plugin runs third-party extensions outside the server process, so
+// organizations can keep proprietary parsers, enrichers, notification
+// channels and exporters in their own repositories and still run the stock
+// binary.
+//
+// A plugin is a program that speaks JSON-RPC 1.0 (net/rpc/jsonrpc) either
+// on its standard input and output, when the server starts it from a
+// command, or on a TCP address it listens on. Requests go to the methods
+// of the "Plugin" service below. Serve and Listen implement the protocol
+// for plugins written in Go.
+package plugin
+
+import "github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+
+// ProtocolVersion is the version of the protocol plugins must speak.
+// Plugins report it in their Description.
+const ProtocolVersion = 1
+
+// ServiceName prefixes every method, e.g. "Plugin.Parse"
+const ServiceName = "Plugin"
+
+// Methods of the Plugin service
+const (
+	// MethodDescribe is called with DescribeArgs once a plugin starts,
+	// and again after every restart, and returns a Description
+	MethodDescribe = ServiceName + ".Describe"
+	// MethodParse parses one line of a log type the plugin registered
+	MethodParse = ServiceName + ".Parse"
+	// MethodEnrich adds to a batch of parsed entries before they are
+	// stored, and returns the entries to store
+	MethodEnrich = ServiceName + ".Enrich"
+	// MethodNotify delivers an alert to a notification channel of type
+	// plugin
+	MethodNotify = ServiceName + ".Notify"
+	// MethodExport receives batches of entries after they are stored
+	MethodExport = ServiceName + ".Export"
+	// MethodShutdown asks the plugin to release its resources before the
+	// server disconnects
+	MethodShutdown = ServiceName + ".Shutdown"
+)
+
+// DescribeArgs are sent when a plugin starts
+type DescribeArgs struct {
+	// Name is the name the plugin is configured under
+	Name string `json:"name"`
+	// Config is the plugin's config section of the server configuration
+	Config map[string]interface{} `json:"config"`
+}
+
+// Description tells the server what a plugin provides
+type Description struct {
+	Version         string `json:"version"`
+	ProtocolVersion int    `json:"protocol_version"`
+	// Parsers are the log types the plugin parses, which must not clash
+	// with built-in log types or those of other plugins
+	Parsers  []string `json:"parsers,omitempty"`
+	Enricher bool     `json:"enricher,omitempty"`
+	Notifier bool     `json:"notifier,omitempty"`
+	Exporter bool     `json:"exporter,omitempty"`
+}
+
+// ParseArgs is a line of a log type the plugin registered
+type ParseArgs struct {
+	LogType string `json:"log_type"`
+	Line    string `json:"line"`
+}
+
+// ParseReply holds the parsed entry. A null entry skips the line; return
+// an error for lines that fail to parse. The log type and raw log default
+// to those of the request.
+type ParseReply struct {
+	Entry *models.LogEntry `json:"entry"`
+}
+
+// Entries is a batch of log entries, for Enrich and Export
+type Entries struct {
+	Entries []*models.LogEntry `json:"entries"`
+}
+
+// NotifyArgs is an alert for a notification channel. Body is the message
+// as a webhook channel would send it: the alert as JSON, or the output of
+// the channel's template if it has one.
+type NotifyArgs struct {
+	Channel string `json:"channel"`
+	Body    string `json:"body"`
+}
+
+// Empty is the argument or reply of methods without one
+type Empty struct{}
@@ -0,0 +1,228 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servePluginEnv makes the test binary serve testPlugin, so tests can
+// start it as a plugin command
+const servePluginEnv = "PLUGIN_TEST_SERVE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnv) == "1" {
+		fmt.Fprintln(os.Stderr, "serving")
+		if err := Serve(&testPlugin{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testPlugin parses "key=value" lines, tags entries, and appends the
+// notifications and exports it receives to the file in its config
+type testPlugin struct {
+	file string
+}
+
+func (p *testPlugin) Describe(name string, cfg map[string]interface{}) (*Description, error) {
+	p.file, _ = cfg["file"].(string)
+	return &Description{Version: "1.2.0", Parsers: []string{"kv"}, Enricher: true, Notifier: true, Exporter: true}, nil
+}
+
+func (p *testPlugin) Parse(logType, line string) (*models.LogEntry, error) {
+	switch {
+	case line == "crash":
+		os.Exit(3)
+	case line == "":
+		return nil, nil
+	case !strings.Contains(line, "="):
+		return nil, errors.New("not a key=value line")
+	}
+	key, value, _ := strings.Cut(line, "=")
+	return &models.LogEntry{Path: "/" + key, Metadata: models.LogMetadata{key: value}}, nil
+}
+
+func (p *testPlugin) Enrich(entries []*models.LogEntry) ([]*models.LogEntry, error) {
+	var kept []*models.LogEntry
+	for _, entry := range entries {
+		if entry.Path == "/drop" {
+			continue
+		}
+		entry.Metadata = models.LogMetadata{"team": "web"}
+		kept = append(kept, entry)
+	}
+	return kept, nil
+}
+
+func (p *testPlugin) Notify(channel, body string) error {
+	return p.write(channel + ": " + body)
+}
+
+func (p *testPlugin) Export(entries []*models.LogEntry) error {
+	return p.write(fmt.Sprintf("exported %d", len(entries)))
+}
+
+func (p *testPlugin) write(line string) error {
+	f, err := os.OpenFile(p.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, line)
+	return err
+}
+
+// testLogger collects log messages
+type testLogger struct {
+	messages chan string
+}
+
+func newTestLogger() *testLogger {
+	return &testLogger{messages: make(chan string, 100)}
+}
+
+func (l *testLogger) Infof(format string, args ...interface{}) {
+	select {
+	case l.messages <- fmt.Sprintf(format, args...):
+	default:
+	}
+}
+
+func (l *testLogger) Warnf(format string, args ...interface{}) {
+	l.Infof(format, args...)
+}
+
+func readLines(t *testing.T, file string) []string {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestCommandPlugin(t *testing.T) {
+	t.Setenv(servePluginEnv, "1")
+	file := filepath.Join(t.TempDir(), "received")
+	logger := newTestLogger()
+	manager, err := NewManager([]config.PluginConfig{{
+		Name:    "kv",
+		Command: os.Args[0],
+		Config:  map[string]interface{}{"file": file},
+	}}, logger)
+	require.NoError(t, err)
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Close()
+
+	client, ok := manager.Plugin("kv")
+	require.True(t, ok)
+	assert.Equal(t, Description{Version: "1.2.0", ProtocolVersion: ProtocolVersion, Parsers: []string{"kv"},
+		Enricher: true, Notifier: true, Exporter: true}, client.Description())
+	assert.Eventually(t, func() bool {
+		select {
+		case message := <-logger.messages:
+			return message == "Plugin kv: serving"
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond, "standard error is logged")
+
+	entry, err := client.Parse("kv", "user=alice")
+	require.NoError(t, err)
+	assert.Equal(t, "/user", entry.Path)
+	assert.Equal(t, "kv", entry.LogType, "the log type defaults to the request's")
+	assert.Equal(t, "user=alice", entry.RawLog)
+	entry, err = client.Parse("kv", "")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	_, err = client.Parse("kv", "garbage")
+	assert.ErrorContains(t, err, "not a key=value line")
+
+	entries, err := manager.Enrich([]*models.LogEntry{{Path: "/a"}, {Path: "/drop"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "web", entries[0].Metadata["team"])
+
+	manager.Export(entries)
+	require.NoError(t, manager.Notify("kv", "pager", []byte(`{"text":"down"}`)))
+	assert.Error(t, manager.Notify("missing", "pager", nil))
+	assert.Eventually(t, func() bool { return len(readLines(t, file)) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{`pager: {"text":"down"}`, "exported 1"}, readLines(t, file))
+
+	status := manager.Status()[0]
+	assert.Equal(t, StateRunning, status.State)
+	assert.Equal(t, int64(1), status.Exported)
+	assert.Equal(t, int64(1), status.Failures, "the garbage line")
+
+	// A plugin that exits is restarted
+	_, err = client.Parse("kv", "crash")
+	assert.Error(t, err)
+	_, err = client.Parse("kv", "user=bob")
+	assert.ErrorIs(t, err, ErrNotRunning)
+	assert.Eventually(t, func() bool { return client.Status().Restarts == 1 }, 10*time.Second, 20*time.Millisecond)
+	entry, err = client.Parse("kv", "user=bob")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", entry.Metadata["user"])
+
+	manager.Close()
+	assert.Equal(t, StateStopped, client.Status().State)
+	_, err = client.Parse("kv", "user=carol")
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+// incomplete declares an enricher it does not implement
+type incomplete struct{}
+
+func (incomplete) Describe(string, map[string]interface{}) (*Description, error) {
+	return &Description{Enricher: true}, nil
+}
+
+func TestAddressPlugin(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go Listen(&testPlugin{}, listener)
+
+	manager, err := NewManager([]config.PluginConfig{{Name: "kv", Address: listener.Addr().String()}}, newTestLogger())
+	require.NoError(t, err)
+	require.NoError(t, manager.Start(context.Background()))
+	client, _ := manager.Plugin("kv")
+	entry, err := client.Parse("kv", "a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "/a", entry.Path)
+	manager.Close()
+
+	other, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer other.Close()
+	go Listen(incomplete{}, other)
+	manager, err = NewManager([]config.PluginConfig{{Name: "broken", Address: other.Addr().String()}}, newTestLogger())
+	require.NoError(t, err)
+	assert.ErrorContains(t, manager.Start(context.Background()), "does not implement Enrich")
+}
+
+func TestNewManagerValidation(t *testing.T) {
+	for _, plugins := range [][]config.PluginConfig{
+		{{Command: "plugin"}},
+		{{Name: "a", Command: "plugin"}, {Name: "a", Address: "localhost:9000"}},
+		{{Name: "a"}},
+		{{Name: "a", Command: "plugin", Address: "localhost:9000"}},
+	} {
+		_, err := NewManager(plugins, newTestLogger())
+		assert.Error(t, err, plugins)
+	}
+}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Plugin is implemented by plugins written in Go. Describe is called with
+// the plugin's configuration when it starts; the plugin then implements
+// Parser, Enricher, Notifier and Exporter for what its Description
+// declares, and io.Closer to be told when the server disconnects.
+type Plugin interface {
+	Describe(name string, config map[string]interface{}) (*Description, error)
+}
+
+// Parser parses the log types a plugin declares
+type Parser interface {
+	Parse(logType, line string) (*models.LogEntry, error)
+}
+
+// Enricher adds to parsed entries before they are stored. It returns the
+// entries to store, so it may also drop entries.
+type Enricher interface {
+	Enrich(entries []*models.LogEntry) ([]*models.LogEntry, error)
+}
+
+// Notifier delivers alerts to notification channels of type plugin
+type Notifier interface {
+	Notify(channel, body string) error
+}
+
+// Exporter receives entries once they are stored
+type Exporter interface {
+	Export(entries []*models.LogEntry) error
+}
+
+// Serve serves p on standard input and output until the server
+// disconnects. Plugins started from a command call it from main; they must
+// not write anything else to standard output, and what they write to
+// standard error is logged by the server.
+func Serve(p Plugin) error {
+	return ServeConn(p, stdio{})
+}
+
+// Listen serves p to every server that connects to the listener, for
+// plugins the server reaches at an address
+func Listen(p Plugin, listener net.Listener) error {
+	server, err := newServer(p)
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// ServeConn serves p on one connection until it is closed
+func ServeConn(p Plugin, conn io.ReadWriteCloser) error {
+	server, err := newServer(p)
+	if err != nil {
+		return err
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+func newServer(p Plugin) (*rpc.Server, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, &service{plugin: p}); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// stdio is the connection of a plugin started from a command
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdio) Close() error                { return os.Stdin.Close() }
+
+// service adapts a Plugin to the RPC methods
+type service struct {
+	plugin Plugin
+}
+
+func (s *service) Describe(args DescribeArgs, reply *Description) error {
+	desc, err := s.plugin.Describe(args.Name, args.Config)
+	if err != nil {
+		return err
+	}
+	if desc == nil {
+		desc = &Description{}
+	}
+
+	_, parser := s.plugin.(Parser)
+	_, enricher := s.plugin.(Enricher)
+	_, notifier := s.plugin.(Notifier)
+	_, exporter := s.plugin.(Exporter)
+	switch {
+	case len(desc.Parsers) > 0 && !parser:
+		return fmt.Errorf("plugin declares parsers but does not implement Parse")
+	case desc.Enricher && !enricher:
+		return fmt.Errorf("plugin declares an enricher but does not implement Enrich")
+	case desc.Notifier && !notifier:
+		return fmt.Errorf("plugin declares a notifier but does not implement Notify")
+	case desc.Exporter && !exporter:
+		return fmt.Errorf("plugin declares an exporter but does not implement Export")
+	}
+
+	*reply = *desc
+	reply.ProtocolVersion = ProtocolVersion
+	return nil
+}
+
+func (s *service) Parse(args ParseArgs, reply *ParseReply) error {
+	parser, ok := s.plugin.(Parser)
+	if !ok {
+		return errors.New("plugin has no parsers")
+	}
+	entry, err := parser.Parse(args.LogType, args.Line)
+	if err != nil {
+		return err
+	}
+	reply.Entry = entry
+	return nil
+}
+
+func (s *service) Enrich(args Entries, reply *Entries) error {
+	enricher, ok := s.plugin.(Enricher)
+	if !ok {
+		return errors.New("plugin is not an enricher")
+	}
+	entries, err := enricher.Enrich(args.Entries)
+	if err != nil {
+		return err
+	}
+	reply.Entries = entries
+	return nil
+}
+
+func (s *service) Notify(args NotifyArgs, _ *Empty) error {
+	notifier, ok := s.plugin.(Notifier)
+	if !ok {
+		return errors.New("plugin is not a notifier")
+	}
+	return notifier.Notify(args.Channel, args.Body)
+}
+
+func (s *service) Export(args Entries, _ *Empty) error {
+	exporter, ok := s.plugin.(Exporter)
+	if !ok {
+		return errors.New("plugin is not an exporter")
+	}
+	return exporter.Export(args.Entries)
+}
+
+func (s *service) Shutdown(_ Empty, _ *Empty) error {
+	if closer, ok := s.plugin.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package ratelimit limits how often each client may make requests, with
+// a token bucket per key, so one runaway client cannot starve the others.
+// Limiters sharing buckets, such as those of a Redis cache, limit each
+// key's requests across replicas instead.
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Quota is a token bucket: it holds up to Burst requests and refills at
+// Rate requests per second. A zero Rate does not limit.
+type Quota struct {
+	Rate  float64
+	Burst int
+}
+
+// Buckets keeps token buckets every replica sees. Take takes a token from
+// the bucket under key, which holds up to burst tokens and refills at rate
+// tokens per second, and returns whether it had one and otherwise how long
+// until it will.
+type Buckets interface {
+	Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error)
+}
+
+// bucket is the state of a key's bucket as of updated
+type bucket struct {
+	key     string
+	quota   Quota
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens earned since the bucket was last updated
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.quota.Burst), b.tokens+elapsed*b.quota.Rate)
+		b.updated = now
+	}
+}
+
+// Limiter keeps a bucket per key. It is safe for concurrent use.
+type Limiter struct {
+	quota   Quota
+	quotas  map[string]Quota
+	maxKeys int
+
+	shared Buckets
+	name   string
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// used orders buckets from the most to the least recently used
+	used *list.List
+}
+
+// New returns a limiter giving keys quota, except those with their own in
+// quotas. At most maxKeys buckets are kept; beyond that the least
+// recently used are dropped, along with any behind them that have
+// refilled, as those are the same as new ones.
+func New(quota Quota, quotas map[string]Quota, maxKeys int) *Limiter {
+	return &Limiter{quota: quota, quotas: quotas, maxKeys: maxKeys, buckets: make(map[string]*list.Element), used: list.New()}
+}
+
+// Share keeps the keys' buckets in buckets, under keys starting with name,
+// so replicas sharing them share each key's quota. While buckets fails,
+// the limiter's own buckets limit requests instead.
+func (l *Limiter) Share(buckets Buckets, name string) {
+	l.shared, l.name = buckets, name
+}
+
+// QuotaOf is the quota of a key
+func (l *Limiter) QuotaOf(key string) Quota {
+	if quota, ok := l.quotas[key]; ok {
+		return quota
+	}
+	return l.quota
+}
+
+// Allow takes a request from a key's quota. When it is used up it
+// returns false and how long until it allows a request again.
+func (l *Limiter) Allow(ctx context.Context, key string, now time.Time) (bool, time.Duration) {
+	quota := l.QuotaOf(key)
+	if quota.Rate <= 0 {
+		return true, 0
+	}
+	if l.shared != nil {
+		if ok, wait, err := l.allowShared(ctx, key, quota, now); err == nil {
+			return ok, wait
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b *bucket
+	if element, ok := l.buckets[key]; ok {
+		l.used.MoveToFront(element)
+		b = element.Value.(*bucket)
+	} else {
+		if len(l.buckets) >= l.maxKeys {
+			l.evict(now)
+		}
+		b = &bucket{key: key, quota: quota, tokens: float64(quota.Burst), updated: now}
+		l.buckets[key] = l.used.PushFront(b)
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / quota.Rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// allowShared takes a request from the key's shared bucket
+func (l *Limiter) allowShared(ctx context.Context, key string, quota Quota, now time.Time) (bool, time.Duration, error) {
+	return l.shared.Take(ctx, "ratelimit:"+l.name+":"+key, quota.Rate, quota.Burst, now)
+}
+
+// evict makes room for a bucket, dropping the least recently used and
+// then those behind it that have refilled. The caller holds mu.
+func (l *Limiter) evict(now time.Time) {
+	for element := l.used.Back(); element != nil; element = l.used.Back() {
+		b := element.Value.(*bucket)
+		if len(l.buckets) < l.maxKeys {
+			b.refill(now)
+			if b.tokens < float64(b.quota.Burst) {
+				return
+			}
+		}
+		l.used.Remove(element)
+		delete(l.buckets, b.key)
+	}
+}
+
+// Len is how many buckets are kept
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	start = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	ctx   = context.Background()
+)
+
+func TestAllow(t *testing.T) {
+	l := New(Quota{Rate: 2, Burst: 3}, nil, 100)
+
+	// A burst is allowed, then requests wait for the bucket to refill
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow(ctx, "ip:192.0.2.1", start)
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow(ctx, "ip:192.0.2.1", start)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket
+	ok, _ = l.Allow(ctx, "ip:192.0.2.2", start)
+	assert.True(t, ok)
+
+	ok, _ = l.Allow(ctx, "ip:192.0.2.1", start.Add(500*time.Millisecond))
+	assert.True(t, ok)
+	ok, wait = l.Allow(ctx, "ip:192.0.2.1", start.Add(600*time.Millisecond))
+	assert.False(t, ok)
+	assert.Equal(t, 400*time.Millisecond, wait)
+
+	// The bucket refills to its burst, not beyond
+	for i := 0; i < 3; i++ {
+		ok, _ = l.Allow(ctx, "ip:192.0.2.1", start.Add(time.Hour))
+		assert.True(t, ok)
+	}
+	ok, _ = l.Allow(ctx, "ip:192.0.2.1", start.Add(time.Hour))
+	assert.False(t, ok)
+}
+
+func TestQuotas(t *testing.T) {
+	l := New(Quota{Rate: 1, Burst: 1}, map[string]Quota{
+		"hec:k8s":    {Rate: 100, Burst: 5},
+		"user:admin": {},
+	}, 100)
+
+	for i := 0; i < 5; i++ {
+		ok, _ := l.Allow(ctx, "hec:k8s", start)
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow(ctx, "hec:k8s", start)
+	assert.False(t, ok)
+	assert.Equal(t, 10*time.Millisecond, wait)
+
+	// A zero rate does not limit
+	for i := 0; i < 1000; i++ {
+		ok, _ := l.Allow(ctx, "user:admin", start)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 1, l.Len(), "unlimited keys keep no bucket")
+	assert.Equal(t, Quota{Rate: 1, Burst: 1}, l.QuotaOf("ip:192.0.2.1"))
+}
+
+func TestEviction(t *testing.T) {
+	l := New(Quota{Rate: 1, Burst: 2}, nil, 2)
+	l.Allow(ctx, "a", start)
+	l.Allow(ctx, "b", start.Add(time.Millisecond))
+	l.Allow(ctx, "b", start.Add(time.Millisecond))
+
+	// Neither bucket has refilled, so the least recently used goes
+	l.Allow(ctx, "c", start.Add(2*time.Millisecond))
+	assert.Equal(t, 2, l.Len())
+	ok, _ := l.Allow(ctx, "b", start.Add(3*time.Millisecond))
+	assert.False(t, ok, "b was kept, so it is still empty")
+
+	// Refilled buckets are dropped first
+	l.Allow(ctx, "d", start.Add(time.Hour))
+	assert.Equal(t, 1, l.Len())
+}
+
+// shared is buckets every replica sees, failing while err is set
+type shared struct {
+	buckets map[string]*bucket
+	err     error
+}
+
+func (s *shared) Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (bool, time.Duration, error) {
+	if s.err != nil {
+		return false, 0, s.err
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{key: key, quota: Quota{Rate: rate, Burst: burst}, tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+func TestShared(t *testing.T) {
+	buckets := &shared{buckets: make(map[string]*bucket)}
+	// Two replicas share the quota of bursts of 4 and 2 requests a second
+	replicas := []*Limiter{New(Quota{Rate: 2, Burst: 4}, nil, 100), New(Quota{Rate: 2, Burst: 4}, nil, 100)}
+	for _, l := range replicas {
+		l.Share(buckets, "query")
+	}
+
+	for i := 0; i < 4; i++ {
+		ok, _ := replicas[i%2].Allow(ctx, "ip:192.0.2.1", start)
+		assert.True(t, ok)
+	}
+	ok, wait := replicas[0].Allow(ctx, "ip:192.0.2.1", start)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait, "until the shared bucket has a token again")
+	assert.Contains(t, buckets.buckets, "ratelimit:query:ip:192.0.2.1")
+	assert.Zero(t, replicas[0].Len(), "no buckets are kept while sharing")
+
+	ok, _ = replicas[1].Allow(ctx, "ip:192.0.2.1", start.Add(500*time.Millisecond))
+	assert.True(t, ok)
+	ok, _ = replicas[0].Allow(ctx, "ip:192.0.2.1", start.Add(500*time.Millisecond))
+	assert.False(t, ok, "the bucket refills at the rate, not by windows")
+
+	// While the shared buckets fail, each replica limits with its own
+	buckets.err = errors.New("connection refused")
+	for i := 0; i < 4; i++ {
+		ok, _ := replicas[0].Allow(ctx, "ip:192.0.2.1", start)
+		assert.True(t, ok)
+	}
+	ok, _ = replicas[0].Allow(ctx, "ip:192.0.2.1", start)
+	assert.False(t, ok)
+	assert.Equal(t, 1, replicas[0].Len())
+}
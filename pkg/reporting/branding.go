@@ -0,0 +1,120 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"time"
+)
+
+// maxLogoSize is the largest logo file reports accept. HTML reports embed
+// the logo, so it adds to the size of every one.
+const maxLogoSize = 512 << 10
+
+// Branding is shown on HTML and PDF reports for the organization they
+// are for
+type Branding struct {
+	// Title is shown above each report's own title
+	Title string
+	// Logo is shown in the header; nil for none
+	Logo *Logo
+	// Footer replaces the line at the foot of reports
+	Footer string
+}
+
+// Logo is an image shown on reports
+type Logo struct {
+	data        []byte
+	contentType string
+	// Width and Height are in pixels
+	Width  int
+	Height int
+	// pdfRGB is the image flattened onto white as zlib-compressed 8-bit
+	// RGB samples, as PDF draws it
+	pdfRGB []byte
+}
+
+// LoadLogo reads a PNG, JPEG or GIF logo
+func LoadLogo(path string) (*Logo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxLogoSize {
+		return nil, fmt.Errorf("logo %s is larger than %d KB", path, maxLogoSize>>10)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("logo %s is not a PNG, JPEG or GIF image: %w", path, err)
+	}
+
+	bounds := img.Bounds()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	row := make([]byte, 0, 3*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Premultiplied colors over white: c + (1 - alpha) * white
+			c := color.RGBA64Model.Convert(img.At(x, y)).(color.RGBA64)
+			background := 0xffff - uint32(c.A)
+			row = append(row,
+				byte((uint32(c.R)+background)>>8),
+				byte((uint32(c.G)+background)>>8),
+				byte((uint32(c.B)+background)>>8))
+		}
+		zw.Write(row)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &Logo{
+		data:        data,
+		contentType: "image/" + format,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		pdfRGB:      buf.Bytes(),
+	}, nil
+}
+
+// URL returns the logo as a data URL, so reports show it without fetching
+// anything
+func (l *Logo) URL() template.URL {
+	return template.URL("data:" + l.contentType + ";base64," + base64.StdEncoding.EncodeToString(l.data))
+}
+
+// SetBranding shows branding on the HTML and PDF reports generated from
+// then on
+func (r *Reporter) SetBranding(branding Branding) {
+	r.branding = branding
+}
+
+// templateFuncs are the functions report templates, built-in or
+// uploaded, can call. They read the reporter's locale and branding when
+// a report is rendered.
+func (r *Reporter) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"brand":     func() Branding { return r.branding },
+		"date":      func(t time.Time) string { return r.locale.Date(t) },
+		"datetime":  func(t time.Time) string { return r.locale.DateTime(t) },
+		"timestamp": func(t time.Time) string { return r.locale.Timestamp(t) },
+		"clock":     func(t time.Time) string { return r.locale.Time(t) },
+		"longdate":  func(t time.Time) string { return r.locale.Long(t) },
+		"number":    func(v interface{}) string { return r.locale.Number(v) },
+		"decimal":   func(places int, v float64) string { return r.locale.Decimal(places, v) },
+		"localize":  func(s string) string { return r.locale.Localize(s) },
+	}
+}
@@ -0,0 +1,107 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// writeLogo writes a 4x2 PNG whose left half is opaque red and right half
+// transparent
+func writeLogo(t *testing.T) string {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(file, img))
+	require.NoError(t, file.Close())
+	return path
+}
+
+func TestLoadLogo(t *testing.T) {
+	logo, err := LoadLogo(writeLogo(t))
+	require.NoError(t, err)
+	assert.Equal(t, 4, logo.Width)
+	assert.Equal(t, 2, logo.Height)
+	assert.True(t, strings.HasPrefix(string(logo.URL()), "data:image/png;base64,iVBORw0KGgo"))
+
+	// Transparent pixels are drawn on white
+	zr, err := zlib.NewReader(bytes.NewReader(logo.pdfRGB))
+	require.NoError(t, err)
+	rgb, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{255, 0, 0, 255, 0, 0, 255, 255, 255, 255, 255, 255}, rgb[:12])
+	assert.Len(t, rgb, 4*2*3)
+
+	text := filepath.Join(t.TempDir(), "logo.txt")
+	require.NoError(t, os.WriteFile(text, []byte("not an image"), 0o644))
+	_, err = LoadLogo(text)
+	assert.ErrorContains(t, err, "is not a PNG, JPEG or GIF image")
+	_, err = LoadLogo(filepath.Join(t.TempDir(), "missing.png"))
+	assert.Error(t, err)
+}
+
+func TestBrandedReports(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+	logo, err := LoadLogo(writeLogo(t))
+	require.NoError(t, err)
+	reporter.SetBranding(Branding{Title: "Acme GmbH", Logo: logo, Footer: "Acme GmbH - Vertraulich"})
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{Title: "Daily", GeneratedAt: base, LogEntries: []*models.LogEntry{
+		{Timestamp: base, SourceIP: "192.0.2.1", Path: "/", StatusCode: 200},
+	}}
+	location, err := reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	html, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<img class="brand-logo" src="data:image/png;base64,`)
+	assert.Contains(t, string(html), `<p class="brand-title">Acme GmbH</p>`)
+	assert.Contains(t, string(html), "<p>Acme GmbH - Vertraulich</p>")
+	assert.NotContains(t, string(html), "Report generated by")
+
+	location, err = reporter.GenerateSecurityPDFReport(&SecurityReportData{
+		Title:       "Weekly",
+		GeneratedAt: base,
+		Options:     DefaultSecurityOptions(),
+		Summary:     AnalyzeSecurity(securityFixture(), DefaultSecurityOptions()),
+	}, "weekly")
+	require.NoError(t, err)
+	pdf, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(pdf), "(Acme GmbH) Tj")
+	assert.Contains(t, string(pdf), "(Acme GmbH - Vertraulich) Tj")
+	// Small logos are drawn a point per pixel, not scaled up
+	assert.Contains(t, string(pdf), "q 4 0 0 2 40 800 cm /Logo Do Q")
+	assert.Contains(t, string(pdf), "/XObject << /Logo ")
+	assert.Contains(t, string(pdf), "/Subtype /Image /Width 4 /Height 2 /ColorSpace /DeviceRGB")
+
+	// Unbranded reports keep the usual footer
+	reporter.SetBranding(Branding{})
+	location, err = reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	html, err = os.ReadFile(location)
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "brand-logo\" src")
+	assert.Contains(t, string(html), "Report generated by Go-Based Server Log Analyzer & Reporting Platform")
+}
@@ -0,0 +1,140 @@
+package reporting
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// runTimestampFormat is the timestamp in the names of a report's files
+const runTimestampFormat = "2006-01-02_15-04-05"
+
+// BundleManifestFile describes the files of a bundle
+const BundleManifestFile = "manifest.json"
+
+// runTimestamp is the timestamp in the names of the files generated from
+// data. Files generated from the same data share it, so a report's HTML,
+// CSV and summary form one run.
+func runTimestamp(data *ReportData) string {
+	at := data.GeneratedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return at.Format(runTimestampFormat)
+}
+
+// reportFilePattern matches the name of a report file: its report name,
+// a marker such as "_summary" for the files other than the main report, its
+// timestamp and an extension, or two for compressed files such as ".csv.gz"
+var reportFilePattern = regexp.MustCompile(`^(.+?)(_summary|_comparison|_latency|_useragents|_errors|_security)?_(\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\d{2})(\.[A-Za-z0-9]+){0,2}$`)
+
+// ReportID identifies the run a report file belongs to: its report name
+// and timestamp, such as "daily_2024-01-15_02-00-00" for
+// "daily_summary_2024-01-15_02-00-00.html"
+func ReportID(filename string) (string, bool) {
+	match := reportFilePattern.FindStringSubmatch(filename)
+	if match == nil {
+		return "", false
+	}
+	if _, err := time.Parse(runTimestampFormat, match[3]); err != nil {
+		return "", false
+	}
+	return match[1] + "_" + match[3], true
+}
+
+// BundleEntry is a file of a bundle as its manifest lists it
+type BundleEntry struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+	Modified time.Time `json:"modified"`
+}
+
+// BundleManifest is the manifest.json of a bundle
+type BundleManifest struct {
+	ReportID string        `json:"report_id"`
+	Created  time.Time     `json:"created"`
+	Files    []BundleEntry `json:"files"`
+}
+
+// ReportFiles returns the top-level files of a report run by name, or an
+// error matching os.ErrNotExist if it has none
+func (r *Reporter) ReportFiles(id string) ([]reportstore.Object, error) {
+	if check, ok := ReportID(id); !ok || check != id || strings.Contains(id, "/") {
+		return nil, fmt.Errorf("invalid report id %q: %w", id, os.ErrNotExist)
+	}
+
+	objects, err := r.store.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []reportstore.Object
+	for _, object := range objects {
+		if fileID, ok := ReportID(object.Name); ok && !object.Dir && fileID == id {
+			files = append(files, object)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files for report %s: %w", id, os.ErrNotExist)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// WriteBundle streams a ZIP of a report run's files followed by a
+// manifest.json listing their sizes and SHA-256 checksums
+func (r *Reporter) WriteBundle(w io.Writer, id string, files []reportstore.Object) error {
+	archive := zip.NewWriter(w)
+	manifest := BundleManifest{ReportID: id, Created: time.Now().UTC(), Files: []BundleEntry{}}
+
+	for _, file := range files {
+		entry, err := r.addToBundle(archive, file)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", file.Name, err)
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	out, err := archive.CreateHeader(&zip.FileHeader{Name: BundleManifestFile, Method: zip.Deflate, Modified: manifest.Created})
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(encoded); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func (r *Reporter) addToBundle(archive *zip.Writer, file reportstore.Object) (BundleEntry, error) {
+	in, _, err := r.store.Open(file.Name)
+	if err != nil {
+		return BundleEntry{}, err
+	}
+	defer in.Close()
+
+	out, err := archive.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: file.ModTime})
+	if err != nil {
+		return BundleEntry{}, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), in)
+	if err != nil {
+		return BundleEntry{}, err
+	}
+	return BundleEntry{Name: file.Name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil)), Modified: file.ModTime.UTC()}, nil
+}
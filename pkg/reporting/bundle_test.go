@@ -0,0 +1,103 @@
+package reporting
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestReportID(t *testing.T) {
+	tests := map[string]string{
+		"daily_2024-01-15_02-00-00.html":             "daily_2024-01-15_02-00-00",
+		"daily_summary_2024-01-15_02-00-00.html":     "daily_2024-01-15_02-00-00",
+		"weekly_comparison_2024-01-15_02-00-00.html": "weekly_2024-01-15_02-00-00",
+		"daily_latency_2024-01-15_02-00-00.csv":      "daily_2024-01-15_02-00-00",
+		"daily_2024-01-15_02-00-00.csv.gz":           "daily_2024-01-15_02-00-00",
+		"daily_errors_2024-01-15_02-00-00.csv":       "daily_2024-01-15_02-00-00",
+		"weekly_security_2024-01-15_02-00-00.pdf":    "weekly_2024-01-15_02-00-00",
+		"v1.2_release_2024-01-15_02-00-00.csv":       "v1.2_release_2024-01-15_02-00-00",
+		"daily_2024-01-15_02-00-00":                  "daily_2024-01-15_02-00-00",
+	}
+	for filename, want := range tests {
+		id, ok := ReportID(filename)
+		assert.True(t, ok, filename)
+		assert.Equal(t, want, id, filename)
+	}
+
+	for _, filename := range []string{"daily.html", "_2024-01-15_02-00-00.html", "daily_2024-13-15_02-00-00.html", "MANIFEST.sha256"} {
+		_, ok := ReportID(filename)
+		assert.False(t, ok, filename)
+	}
+}
+
+func TestReportBundle(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: time.Date(2024, 1, 15, 2, 0, 0, 0, time.Local),
+		LogEntries:  []*models.LogEntry{{Timestamp: time.Now(), LogType: "nginx", SourceIP: "10.0.0.1", Path: "/", StatusCode: 200}},
+	}
+	// Files generated from the same data form one run, however long it takes
+	_, err = reporter.GenerateCombinedReport(data, "daily")
+	require.NoError(t, err)
+	_, err = reporter.GenerateHTMLReport(&ReportData{Title: "Other", GeneratedAt: data.GeneratedAt.Add(time.Second)}, "daily")
+	require.NoError(t, err)
+
+	_, err = reporter.ReportFiles("daily_2024-01-15_02-00-09")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = reporter.ReportFiles("../daily_2024-01-15_02-00-00")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	files, err := reporter.ReportFiles("daily_2024-01-15_02-00-00")
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"daily_2024-01-15_02-00-00.csv", "daily_2024-01-15_02-00-00.html",
+		"daily_latency_2024-01-15_02-00-00.csv", "daily_summary_2024-01-15_02-00-00.html",
+		"daily_useragents_2024-01-15_02-00-00.csv"}, names)
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.WriteBundle(&buf, "daily_2024-01-15_02-00-00", files))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 6)
+	assert.Equal(t, BundleManifestFile, archive.File[5].Name)
+
+	var manifest BundleManifest
+	require.NoError(t, json.Unmarshal(readZipFile(t, archive.File[5]), &manifest))
+	assert.Equal(t, "daily_2024-01-15_02-00-00", manifest.ReportID)
+	require.Len(t, manifest.Files, 5)
+	for i, entry := range manifest.Files {
+		content := readZipFile(t, archive.File[i])
+		sum := sha256.Sum256(content)
+		assert.Equal(t, names[i], entry.Name)
+		assert.Equal(t, int64(len(content)), entry.Size)
+		assert.Equal(t, hex.EncodeToString(sum[:]), entry.SHA256)
+	}
+}
+
+func readZipFile(t *testing.T, file *zip.File) []byte {
+	t.Helper()
+	r, err := file.Open()
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
@@ -0,0 +1,56 @@
+package reporting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// Catalog records the metadata of the report files a Reporter saves.
+// storage.ReportFileStore implements it.
+type Catalog interface {
+	SaveReportFile(file *models.ReportFile) error
+}
+
+// SetCatalog records the size and SHA-256 of every file saved from now on
+// in catalog
+func (r *Reporter) SetCatalog(catalog Catalog) {
+	r.catalog = catalog
+}
+
+// put saves a report file and records its metadata
+func (r *Reporter) put(name string, data []byte) error {
+	if err := r.store.Put(name, data); err != nil {
+		return err
+	}
+	return r.record(name, data)
+}
+
+// record records the metadata of a saved report file
+func (r *Reporter) record(name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	return r.recordSum(name, int64(len(data)), sum[:])
+}
+
+// recordSum records the metadata of a saved report file of size bytes
+// with the SHA-256 sum
+func (r *Reporter) recordSum(name string, size int64, sum []byte) error {
+	if r.catalog == nil {
+		return nil
+	}
+	file := &models.ReportFile{
+		Name:        name,
+		Size:        size,
+		SHA256:      hex.EncodeToString(sum),
+		ContentType: reportstore.ContentType(name),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := r.catalog.SaveReportFile(file); err != nil {
+		return fmt.Errorf("failed to record metadata of %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package reporting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+)
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+	catalog := memory.New()
+	reporter.SetCatalog(catalog)
+
+	data := &ReportData{Title: "Daily", GeneratedAt: time.Date(2024, 1, 15, 2, 0, 0, 0, time.Local)}
+	_, err = reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+
+	name := "daily_2024-01-15_02-00-00.html"
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+
+	file, err := catalog.GetReportFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), file.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256)
+	assert.Equal(t, "text/html; charset=utf-8", file.ContentType)
+	assert.WithinDuration(t, time.Now(), file.CreatedAt, time.Minute)
+}
@@ -0,0 +1,238 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// maxComparisonNew bounds how many new paths and client IPs a comparison
+// report lists
+const maxComparisonNew = 10
+
+// Metrics a comparison report compares
+const (
+	MetricRequests        = "requests"
+	MetricErrorRate       = "error_rate"
+	MetricP95ResponseTime = "p95_response_time"
+	MetricUniqueIPs       = "unique_ips"
+)
+
+// ComparisonOptions set how much worse a metric must get to count as a
+// regression
+type ComparisonOptions struct {
+	// ErrorRatePoints is the rise in error rate, in percentage points,
+	// default 1
+	ErrorRatePoints float64
+	// LatencyIncrease is the rise in p95 response time, in percent of the
+	// previous period's, default 20
+	LatencyIncrease float64
+	// TrafficDrop is the fall in requests, in percent of the previous
+	// period's, default 50
+	TrafficDrop float64
+}
+
+// DefaultComparisonOptions returns the default options
+func DefaultComparisonOptions() ComparisonOptions {
+	return ComparisonOptions{
+		ErrorRatePoints: 1,
+		LatencyIncrease: 20,
+		TrafficDrop:     50,
+	}
+}
+
+// ComparisonPeriod is a period a comparison report compares: the entries
+// read for it and, when the traffic rollups cover it, its totals
+type ComparisonPeriod struct {
+	Start   time.Time
+	End     time.Time
+	Entries []*models.LogEntry
+	Traffic *TrafficTotals
+}
+
+// ComparisonReportData contains the data for a period comparison report
+type ComparisonReportData struct {
+	Title       string
+	GeneratedAt time.Time
+	Summary     *ComparisonSummary
+}
+
+// ComparisonSummary is what changed from the previous period to the
+// current one
+type ComparisonSummary struct {
+	Current  ComparisonRange `json:"current"`
+	Previous ComparisonRange `json:"previous"`
+	Metrics  []MetricChange  `json:"metrics"`
+	// Regressions counts the metrics that got worse beyond their threshold
+	Regressions int `json:"regressions"`
+	// NewPaths are the busiest paths requested in the current period but
+	// not the previous one, and NewIPs the busiest client IPs first seen
+	// in it; NewIPCount counts all of those
+	NewPaths   []PathSummary `json:"new_paths"`
+	NewIPs     []IPSummary   `json:"new_ips"`
+	NewIPCount int64         `json:"new_ip_count"`
+}
+
+// ComparisonRange is a compared period and the entries read for it
+type ComparisonRange struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Entries int64     `json:"entries"`
+}
+
+// MetricChange is a metric of both periods and how it changed
+type MetricChange struct {
+	Metric   string  `json:"metric"`
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Change   float64 `json:"change"`
+	// PercentChange is Change as a percentage of Previous, 0 when Previous
+	// is 0
+	PercentChange float64 `json:"percent_change"`
+	Regression    bool    `json:"regression"`
+}
+
+// Label names the metric in reports
+func (m MetricChange) Label() string {
+	switch m.Metric {
+	case MetricRequests:
+		return "Requests"
+	case MetricErrorRate:
+		return "Error rate (%)"
+	case MetricP95ResponseTime:
+		return "P95 response time (s)"
+	case MetricUniqueIPs:
+		return "Unique client IPs"
+	}
+	return m.Metric
+}
+
+// periodFigures are the metrics of one period
+type periodFigures struct {
+	requests  int64
+	errorRate float64
+	p95       float64
+	ips       map[string]int64
+	paths     map[string]int64
+}
+
+func figuresOf(period *ComparisonPeriod) periodFigures {
+	figures := periodFigures{ips: make(map[string]int64), paths: make(map[string]int64)}
+	var errors int64
+	for _, entry := range period.Entries {
+		if entry.StatusCode >= 400 {
+			errors++
+		}
+		if entry.SourceIP != "" {
+			figures.ips[entry.SourceIP]++
+		}
+		figures.paths[entry.Path]++
+	}
+	figures.requests = int64(len(period.Entries))
+	// The rollups count every entry, not just those read
+	if period.Traffic != nil {
+		figures.requests, errors = period.Traffic.Requests, period.Traffic.Errors
+	}
+	if figures.requests > 0 {
+		figures.errorRate = float64(errors) / float64(figures.requests) * 100
+	}
+	figures.p95 = percentileResponseTime(period.Entries, 95)
+	return figures
+}
+
+// ComparePeriods works out the changes in requests, error rate, p95
+// response time and unique client IPs from previous to current, and the
+// paths and client IPs new in current. Zero options take their defaults.
+func ComparePeriods(current, previous *ComparisonPeriod, opts ComparisonOptions) *ComparisonSummary {
+	defaults := DefaultComparisonOptions()
+	if opts.ErrorRatePoints <= 0 {
+		opts.ErrorRatePoints = defaults.ErrorRatePoints
+	}
+	if opts.LatencyIncrease <= 0 {
+		opts.LatencyIncrease = defaults.LatencyIncrease
+	}
+	if opts.TrafficDrop <= 0 {
+		opts.TrafficDrop = defaults.TrafficDrop
+	}
+
+	now, before := figuresOf(current), figuresOf(previous)
+	summary := &ComparisonSummary{
+		Current:  ComparisonRange{Start: current.Start, End: current.End, Entries: int64(len(current.Entries))},
+		Previous: ComparisonRange{Start: previous.Start, End: previous.End, Entries: int64(len(previous.Entries))},
+		Metrics: []MetricChange{
+			compareMetric(MetricRequests, float64(now.requests), float64(before.requests)),
+			compareMetric(MetricErrorRate, now.errorRate, before.errorRate),
+			compareMetric(MetricP95ResponseTime, now.p95, before.p95),
+			compareMetric(MetricUniqueIPs, float64(len(now.ips)), float64(len(before.ips))),
+		},
+		NewPaths: []PathSummary{},
+		NewIPs:   []IPSummary{},
+	}
+
+	for i := range summary.Metrics {
+		metric := &summary.Metrics[i]
+		switch metric.Metric {
+		case MetricRequests:
+			metric.Regression = metric.Previous > 0 && -metric.PercentChange >= opts.TrafficDrop
+		case MetricErrorRate:
+			metric.Regression = metric.Change >= opts.ErrorRatePoints
+		case MetricP95ResponseTime:
+			metric.Regression = metric.Previous > 0 && metric.PercentChange >= opts.LatencyIncrease
+		}
+		if metric.Regression {
+			summary.Regressions++
+		}
+	}
+
+	newPaths := make(map[string]int64)
+	for path, count := range now.paths {
+		if _, ok := before.paths[path]; !ok {
+			newPaths[path] = count
+		}
+	}
+	summary.NewPaths = append(summary.NewPaths, topPaths(newPaths, int64(len(current.Entries)), maxComparisonNew)...)
+
+	for ip, count := range now.ips {
+		if _, ok := before.ips[ip]; !ok {
+			summary.NewIPCount++
+			summary.NewIPs = append(summary.NewIPs, IPSummary{
+				IP:         ip,
+				Count:      count,
+				Percentage: float64(count) / float64(len(current.Entries)) * 100,
+			})
+		}
+	}
+	sort.Slice(summary.NewIPs, func(i, j int) bool {
+		if summary.NewIPs[i].Count != summary.NewIPs[j].Count {
+			return summary.NewIPs[i].Count > summary.NewIPs[j].Count
+		}
+		return summary.NewIPs[i].IP < summary.NewIPs[j].IP
+	})
+	if len(summary.NewIPs) > maxComparisonNew {
+		summary.NewIPs = summary.NewIPs[:maxComparisonNew]
+	}
+	return summary
+}
+
+func compareMetric(metric string, current, previous float64) MetricChange {
+	change := MetricChange{Metric: metric, Current: current, Previous: previous, Change: current - previous}
+	if previous != 0 {
+		change.PercentChange = change.Change / previous * 100
+	}
+	return change
+}
+
+// GenerateComparisonReport generates an HTML report of what changed from
+// the previous period. It shares the timestamp of reports generated at
+// the same time, so a scheduled report's comparison is part of its run.
+func (r *Reporter) GenerateComparisonReport(data *ComparisonReportData, reportName string) (string, error) {
+	at := data.GeneratedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	filename := fmt.Sprintf("%s_comparison_%s.html", reportName, at.Format(runTimestampFormat))
+
+	return r.renderTemplate("comparison.html", filename, data)
+}
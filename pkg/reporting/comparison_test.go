@@ -0,0 +1,136 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// comparisonFixture has a quiet previous week and a current week with
+// more errors, slower responses, a new path and a new client
+func comparisonFixture(start time.Time) (current, previous *ComparisonPeriod) {
+	previous = &ComparisonPeriod{Start: start, End: start.AddDate(0, 0, 7)}
+	current = &ComparisonPeriod{Start: previous.End, End: previous.End.AddDate(0, 0, 7)}
+	for i := 0; i < 100; i++ {
+		status := 200
+		if i < 2 {
+			status = 500
+		}
+		previous.Entries = append(previous.Entries, &models.LogEntry{Timestamp: start.Add(time.Duration(i) * time.Minute),
+			SourceIP: "192.0.2.1", Path: "/", StatusCode: status, ProcessingTime: 0.1})
+	}
+	for i := 0; i < 80; i++ {
+		entry := &models.LogEntry{Timestamp: current.Start.Add(time.Duration(i) * time.Minute),
+			SourceIP: "192.0.2.1", Path: "/", StatusCode: 200, ProcessingTime: 0.2}
+		if i < 8 {
+			entry.StatusCode = 502
+		}
+		if i >= 70 {
+			entry.SourceIP, entry.Path = "198.51.100.9", "/wp-login.php"
+		}
+		current.Entries = append(current.Entries, entry)
+	}
+	return current, previous
+}
+
+func metricOf(summary *ComparisonSummary, name string) MetricChange {
+	for _, metric := range summary.Metrics {
+		if metric.Metric == name {
+			return metric
+		}
+	}
+	return MetricChange{}
+}
+
+func TestComparePeriods(t *testing.T) {
+	current, previous := comparisonFixture(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	summary := ComparePeriods(current, previous, ComparisonOptions{})
+	assert.Equal(t, int64(80), summary.Current.Entries)
+
+	requests := metricOf(summary, MetricRequests)
+	assert.Equal(t, 100.0, requests.Previous)
+	assert.InDelta(t, -20, requests.PercentChange, 1e-9)
+	assert.False(t, requests.Regression, "a 20% drop is within the default threshold")
+
+	errorRate := metricOf(summary, MetricErrorRate)
+	assert.InDelta(t, 2, errorRate.Previous, 1e-9)
+	assert.InDelta(t, 10, errorRate.Current, 1e-9)
+	assert.InDelta(t, 8, errorRate.Change, 1e-9)
+	assert.True(t, errorRate.Regression)
+
+	p95 := metricOf(summary, MetricP95ResponseTime)
+	assert.Greater(t, p95.Current, p95.Previous)
+	assert.True(t, p95.Regression)
+
+	ips := metricOf(summary, MetricUniqueIPs)
+	assert.Equal(t, 2.0, ips.Current)
+	assert.False(t, ips.Regression)
+	assert.Equal(t, 2, summary.Regressions)
+
+	require.Len(t, summary.NewPaths, 1)
+	assert.Equal(t, "/wp-login.php", summary.NewPaths[0].Path)
+	assert.Equal(t, int64(10), summary.NewPaths[0].Count)
+	require.Len(t, summary.NewIPs, 1)
+	assert.Equal(t, "198.51.100.9", summary.NewIPs[0].IP)
+	assert.Equal(t, int64(1), summary.NewIPCount)
+
+	// Stricter thresholds flag the drop in traffic too
+	summary = ComparePeriods(current, previous, ComparisonOptions{TrafficDrop: 10})
+	assert.True(t, metricOf(summary, MetricRequests).Regression)
+	assert.Equal(t, 3, summary.Regressions)
+}
+
+func TestComparePeriodsUsesTrafficTotals(t *testing.T) {
+	current, previous := comparisonFixture(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+	current.Traffic = &TrafficTotals{Requests: 1000, Errors: 10}
+	previous.Traffic = &TrafficTotals{Requests: 500, Errors: 5}
+
+	summary := ComparePeriods(current, previous, ComparisonOptions{})
+	requests := metricOf(summary, MetricRequests)
+	assert.Equal(t, 1000.0, requests.Current)
+	assert.InDelta(t, 100, requests.PercentChange, 1e-9)
+	errorRate := metricOf(summary, MetricErrorRate)
+	assert.InDelta(t, 0, errorRate.Change, 1e-9)
+	assert.False(t, errorRate.Regression)
+
+	// A period with nothing before it has no percentage change
+	previous.Entries, previous.Traffic = nil, nil
+	summary = ComparePeriods(current, previous, ComparisonOptions{})
+	assert.Equal(t, 0.0, metricOf(summary, MetricRequests).PercentChange)
+	assert.False(t, metricOf(summary, MetricP95ResponseTime).Regression)
+	assert.Len(t, summary.NewPaths, 2)
+}
+
+func TestGenerateComparisonReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	current, previous := comparisonFixture(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+	at := time.Date(2024, 3, 18, 2, 0, 0, 0, time.UTC)
+	location, err := reporter.GenerateComparisonReport(&ComparisonReportData{
+		Title:       "Weekly",
+		GeneratedAt: at,
+		Summary:     ComparePeriods(current, previous, ComparisonOptions{}),
+	}, "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "weekly_comparison_2024-03-18_02-00-00.html", filepath.Base(location))
+
+	id, ok := ReportID(filepath.Base(location))
+	assert.True(t, ok)
+	assert.Equal(t, "weekly_2024-03-18_02-00-00", id)
+
+	html, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Error rate (%) &#9888;")
+	assert.Contains(t, string(html), "/wp-login.php")
+	assert.Contains(t, string(html), "198.51.100.9")
+}
@@ -0,0 +1,486 @@
+package reporting
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// DefaultAdminPaths are path prefixes of common administrative interfaces
+var DefaultAdminPaths = []string{
+	"/admin", "/administrator", "/wp-admin", "/wp-login.php", "/phpmyadmin",
+	"/manage", "/manager", "/console", "/actuator", "/_admin",
+}
+
+// ManifestFile lists the SHA-256 checksum of every file in a compliance
+// pack, in the format sha256sum -c reads
+const ManifestFile = "MANIFEST.sha256"
+
+// maxOffHoursEvents bounds the off-hours accesses listed individually
+const maxOffHoursEvents = 100
+
+// ComplianceOptions sets which paths are administrative and when access
+// counts as off-hours
+type ComplianceOptions struct {
+	AdminPaths []string
+	// Business hours are [BusinessStart, BusinessEnd) on BusinessDays in
+	// Location
+	BusinessStart int
+	BusinessEnd   int
+	BusinessDays  []time.Weekday
+	Location      *time.Location
+}
+
+// DefaultComplianceOptions treats weekdays 08:00-18:00 UTC as business hours
+func DefaultComplianceOptions() ComplianceOptions {
+	return ComplianceOptions{
+		AdminPaths:    DefaultAdminPaths,
+		BusinessStart: 8,
+		BusinessEnd:   18,
+		BusinessDays:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Location:      time.UTC,
+	}
+}
+
+// ComplianceReportData is a monthly PCI DSS / SOC 2 access review
+type ComplianceReportData struct {
+	Title        string                `json:"title"`
+	GeneratedAt  time.Time             `json:"generated_at"`
+	Period       string                `json:"period"` // YYYY-MM
+	PeriodStart  time.Time             `json:"period_start"`
+	PeriodEnd    time.Time             `json:"period_end"`
+	AdminAccess  *AdminAccessSummary   `json:"admin_access"`
+	OffHours     *OffHoursSummary      `json:"off_hours"`
+	NewCountries *NewCountrySummary    `json:"new_countries"`
+	Retention    *RetentionAttestation `json:"retention"`
+	// RetentionByLogType attests the log types kept for their own period
+	RetentionByLogType []*RetentionAttestation `json:"retention_by_log_type,omitempty"`
+}
+
+// AdminAccessSummary reviews requests to administrative paths
+type AdminAccessSummary struct {
+	Requests int64 `json:"requests"`
+	// Allowed requests were answered below 400; Denied with 401 or 403
+	Allowed   int64             `json:"allowed"`
+	Denied    int64             `json:"denied"`
+	UniqueIPs int64             `json:"unique_ips"`
+	Paths     []AdminPathAccess `json:"paths"`
+	TopIPs    []AccessByIP      `json:"top_ips"`
+}
+
+// AdminPathAccess is the access to one administrative path prefix
+type AdminPathAccess struct {
+	Prefix    string `json:"prefix"`
+	Requests  int64  `json:"requests"`
+	Allowed   int64  `json:"allowed"`
+	Denied    int64  `json:"denied"`
+	UniqueIPs int64  `json:"unique_ips"`
+}
+
+// AccessByIP is one client's administrative access
+type AccessByIP struct {
+	IP        string    `json:"ip"`
+	Requests  int64     `json:"requests"`
+	Allowed   int64     `json:"allowed"`
+	Denied    int64     `json:"denied"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// OffHoursSummary reviews administrative access outside business hours
+type OffHoursSummary struct {
+	BusinessHours string       `json:"business_hours"`
+	Requests      int64        `json:"requests"`
+	Allowed       int64        `json:"allowed"`
+	TopIPs        []AccessByIP `json:"top_ips"`
+	// Events are the most recent allowed off-hours accesses
+	Events []OffHoursAccess `json:"events"`
+}
+
+// OffHoursAccess is an allowed administrative request outside business hours
+type OffHoursAccess struct {
+	Timestamp  time.Time `json:"timestamp"`
+	IP         string    `json:"ip"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+}
+
+// NewCountrySummary lists client countries not seen before the period
+type NewCountrySummary struct {
+	LookbackDays int `json:"lookback_days"`
+	// Available is false when no entries carry a country
+	Available      bool                     `json:"available"`
+	KnownCountries int                      `json:"known_countries"`
+	Countries      []models.CountryActivity `json:"countries"`
+}
+
+// RetentionAttestation states whether stored logs follow the retention policy
+type RetentionAttestation struct {
+	// LogType is empty for the default retention of every other log type
+	LogType        string     `json:"log_type,omitempty"`
+	RetentionDays  int        `json:"retention_days"`
+	TotalEntries   int64      `json:"total_entries"`
+	OldestEntry    *time.Time `json:"oldest_entry"`
+	ExpiredEntries int64      `json:"expired_entries"`
+	// Compliant is true when nothing older than the grace period is kept
+	Compliant bool   `json:"compliant"`
+	Statement string `json:"statement"`
+}
+
+// MonthPeriod returns the bounds of a YYYY-MM month in loc
+func MonthPeriod(period string, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", period, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("period must be YYYY-MM: %w", err)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// adminPrefix returns the longest administrative prefix covering path. A
+// prefix covers the path itself and anything below it, so /admin does not
+// cover /administrator.
+func adminPrefix(path string, prefixes []string) string {
+	path, _, _ = strings.Cut(path, "?")
+	lower := strings.ToLower(path)
+
+	best := ""
+	for _, prefix := range prefixes {
+		p := strings.ToLower(strings.TrimSuffix(prefix, "/"))
+		if p == "" || len(p) <= len(best) {
+			continue
+		}
+		if lower == p || strings.HasPrefix(lower, p+"/") {
+			best = prefix
+		}
+	}
+	return best
+}
+
+func (o ComplianceOptions) businessHours(t time.Time) bool {
+	t = t.In(o.Location)
+	for _, day := range o.BusinessDays {
+		if t.Weekday() == day {
+			return t.Hour() >= o.BusinessStart && t.Hour() < o.BusinessEnd
+		}
+	}
+	return false
+}
+
+func (o ComplianceOptions) describeBusinessHours() string {
+	days := make([]string, len(o.BusinessDays))
+	for i, day := range o.BusinessDays {
+		days[i] = day.String()[:3]
+	}
+	return fmt.Sprintf("%s %02d:00-%02d:00 %s", strings.Join(days, ", "), o.BusinessStart, o.BusinessEnd, o.Location)
+}
+
+// tallyAccess counts an entry towards its source IP
+func tallyAccess(tallies map[string]*AccessByIP, entry *models.LogEntry, allowed, denied bool) {
+	access, ok := tallies[entry.SourceIP]
+	if !ok {
+		access = &AccessByIP{IP: entry.SourceIP, FirstSeen: entry.Timestamp}
+		tallies[entry.SourceIP] = access
+	}
+	if entry.Timestamp.Before(access.FirstSeen) {
+		access.FirstSeen = entry.Timestamp
+	}
+	if entry.Timestamp.After(access.LastSeen) {
+		access.LastSeen = entry.Timestamp
+	}
+	access.Requests++
+	if allowed {
+		access.Allowed++
+	}
+	if denied {
+		access.Denied++
+	}
+}
+
+func topAccess(tallies map[string]*AccessByIP, n int) []AccessByIP {
+	access := make([]AccessByIP, 0, len(tallies))
+	for _, tally := range tallies {
+		access = append(access, *tally)
+	}
+	sort.Slice(access, func(i, j int) bool {
+		if access[i].Requests != access[j].Requests {
+			return access[i].Requests > access[j].Requests
+		}
+		return access[i].IP < access[j].IP
+	})
+	if len(access) > n {
+		access = access[:n]
+	}
+	return access
+}
+
+// AnalyzeAdminAccess reviews requests to administrative paths overall and
+// outside business hours. Entries outside the admin paths are ignored.
+func AnalyzeAdminAccess(entries []*models.LogEntry, opts ComplianceOptions) (*AdminAccessSummary, *OffHoursSummary) {
+	admin := &AdminAccessSummary{}
+	offHours := &OffHoursSummary{BusinessHours: opts.describeBusinessHours()}
+
+	type prefixTally struct {
+		access AdminPathAccess
+		ips    map[string]bool
+	}
+	prefixes := make(map[string]*prefixTally)
+	ips := make(map[string]*AccessByIP)
+	offHoursIPs := make(map[string]*AccessByIP)
+
+	for _, entry := range entries {
+		prefix := adminPrefix(entry.Path, opts.AdminPaths)
+		if prefix == "" || entry.StatusCode == 0 {
+			continue
+		}
+		allowed := entry.StatusCode < 400
+		denied := entry.StatusCode == 401 || entry.StatusCode == 403
+
+		admin.Requests++
+		if allowed {
+			admin.Allowed++
+		}
+		if denied {
+			admin.Denied++
+		}
+
+		tally, ok := prefixes[prefix]
+		if !ok {
+			tally = &prefixTally{access: AdminPathAccess{Prefix: prefix}, ips: make(map[string]bool)}
+			prefixes[prefix] = tally
+		}
+		tally.access.Requests++
+		if allowed {
+			tally.access.Allowed++
+		}
+		if denied {
+			tally.access.Denied++
+		}
+		tally.ips[entry.SourceIP] = true
+
+		tallyAccess(ips, entry, allowed, denied)
+
+		if opts.businessHours(entry.Timestamp) {
+			continue
+		}
+		offHours.Requests++
+		tallyAccess(offHoursIPs, entry, allowed, denied)
+		if allowed {
+			offHours.Allowed++
+			offHours.Events = append(offHours.Events, OffHoursAccess{
+				Timestamp:  entry.Timestamp,
+				IP:         entry.SourceIP,
+				Method:     entry.Method,
+				Path:       entry.Path,
+				StatusCode: entry.StatusCode,
+			})
+		}
+	}
+
+	for _, tally := range prefixes {
+		tally.access.UniqueIPs = int64(len(tally.ips))
+		admin.Paths = append(admin.Paths, tally.access)
+	}
+	sort.Slice(admin.Paths, func(i, j int) bool {
+		if admin.Paths[i].Requests != admin.Paths[j].Requests {
+			return admin.Paths[i].Requests > admin.Paths[j].Requests
+		}
+		return admin.Paths[i].Prefix < admin.Paths[j].Prefix
+	})
+	admin.UniqueIPs = int64(len(ips))
+	admin.TopIPs = topAccess(ips, 20)
+	offHours.TopIPs = topAccess(offHoursIPs, 20)
+
+	sort.Slice(offHours.Events, func(i, j int) bool {
+		return offHours.Events[i].Timestamp.After(offHours.Events[j].Timestamp)
+	})
+	if len(offHours.Events) > maxOffHoursEvents {
+		offHours.Events = offHours.Events[:maxOffHoursEvents]
+	}
+	return admin, offHours
+}
+
+// NewCountries picks the countries first seen at or after periodStart
+func NewCountries(activity []models.CountryActivity, periodStart time.Time, lookbackDays int) *NewCountrySummary {
+	summary := &NewCountrySummary{LookbackDays: lookbackDays, Available: len(activity) > 0}
+	for _, country := range activity {
+		if country.FirstSeen.Before(periodStart) {
+			summary.KnownCountries++
+			continue
+		}
+		summary.Countries = append(summary.Countries, country)
+	}
+	sort.Slice(summary.Countries, func(i, j int) bool {
+		return summary.Countries[i].FirstSeen.Before(summary.Countries[j].FirstSeen)
+	})
+	return summary
+}
+
+// AttestRetention checks stored logs against the retention policy. Entries
+// up to graceDays past retention are tolerated, since cleanup runs
+// periodically rather than continuously.
+func AttestRetention(stats *models.RetentionStats, retentionDays, graceDays int, now time.Time) *RetentionAttestation {
+	return AttestLogTypeRetention("", stats, retentionDays, graceDays, now)
+}
+
+// AttestLogTypeRetention checks the stored entries of a log type against
+// its own retention period, as AttestRetention does
+func AttestLogTypeRetention(logType string, stats *models.RetentionStats, retentionDays, graceDays int, now time.Time) *RetentionAttestation {
+	attestation := &RetentionAttestation{
+		LogType:        logType,
+		RetentionDays:  retentionDays,
+		TotalEntries:   stats.TotalEntries,
+		OldestEntry:    stats.OldestEntry,
+		ExpiredEntries: stats.ExpiredEntries,
+		Compliant:      true,
+	}
+
+	entries, subject := "log entries", "Log entries"
+	if logType != "" {
+		entries, subject = logType+" entries", logType+" entries"
+	}
+	limit := now.AddDate(0, 0, -(retentionDays + graceDays))
+	switch {
+	case stats.OldestEntry == nil:
+		attestation.Statement = fmt.Sprintf("No %s are stored. Entries are kept for %d days.", entries, retentionDays)
+	case stats.OldestEntry.Before(limit):
+		attestation.Compliant = false
+		attestation.Statement = fmt.Sprintf("%s from %s are stored, beyond the %d day retention period and the %d day cleanup grace period.",
+			subject, stats.OldestEntry.Format("2006-01-02"), retentionDays, graceDays)
+	default:
+		attestation.Statement = fmt.Sprintf("%s are kept for %d days. The oldest stored entry is from %s, and %d entries await the next cleanup.",
+			subject, retentionDays, stats.OldestEntry.Format("2006-01-02"), stats.ExpiredEntries)
+	}
+	return attestation
+}
+
+// GenerateCompliancePack renders the report as HTML and JSON into its own
+// directory under compliance/ with a checksum manifest, and returns the
+// directory's and files' locations. An existing pack for the period is
+// never replaced; on local disk the files are made read-only.
+func (r *Reporter) GenerateCompliancePack(data *ComplianceReportData) (string, []string, error) {
+	dir := "compliance/" + data.Period
+
+	var html bytes.Buffer
+	if err := r.templates.ExecuteTemplate(&html, "compliance.html", data); err != nil {
+		return "", nil, fmt.Errorf("failed to execute compliance template: %w", err)
+	}
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode compliance report: %w", err)
+	}
+
+	files := []reportstore.File{
+		{Name: "compliance.html", Data: html.Bytes()},
+		{Name: "compliance.json", Data: jsonData},
+	}
+	var manifest strings.Builder
+	for _, file := range files {
+		sum := sha256.Sum256(file.Data)
+		fmt.Fprintf(&manifest, "%s  %s\n", hex.EncodeToString(sum[:]), file.Name)
+	}
+	// The manifest goes last, so a pack without one is incomplete
+	files = append(files, reportstore.File{Name: ManifestFile, Data: []byte(manifest.String())})
+
+	if err := r.store.Archive(dir, files); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", nil, fmt.Errorf("compliance pack for %s: %w", data.Period, os.ErrExist)
+		}
+		return "", nil, fmt.Errorf("failed to archive compliance pack: %w", err)
+	}
+	for _, file := range files {
+		if err := r.record(dir+"/"+file.Name, file.Data); err != nil {
+			return "", nil, err
+		}
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = r.store.Location(dir + "/" + file.Name)
+	}
+	return r.store.Location(dir), paths, nil
+}
+
+// ManifestMismatch is a pack file whose contents no longer match the manifest
+type ManifestMismatch struct {
+	File     string `json:"file"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"` // empty when the file is missing
+}
+
+// VerifyCompliancePack recomputes the checksums of a pack's files and
+// returns those that differ from its manifest
+func (r *Reporter) VerifyCompliancePack(period string) ([]ManifestMismatch, error) {
+	// Only YYYY-MM names can reach the archive directory
+	if _, _, err := MonthPeriod(period, time.UTC); err != nil {
+		return nil, fmt.Errorf("no compliance pack for %q: %w", period, os.ErrNotExist)
+	}
+	dir := "compliance/" + period
+
+	manifest, _, err := r.store.Open(dir + "/" + ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	defer manifest.Close()
+
+	mismatches := []ManifestMismatch{}
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		expected, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || name != path.Base(name) || name == ".." {
+			return nil, fmt.Errorf("malformed manifest line: %q", scanner.Text())
+		}
+		actual, err := r.storedSHA256(dir + "/" + name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if actual != expected {
+			mismatches = append(mismatches, ManifestMismatch{File: name, Expected: expected, Actual: actual})
+		}
+	}
+	return mismatches, scanner.Err()
+}
+
+// CompliancePacks returns the periods with an archived pack, newest first
+func (r *Reporter) CompliancePacks() ([]string, error) {
+	entries, err := r.store.List("compliance")
+	if err != nil {
+		return nil, err
+	}
+
+	periods := []string{}
+	for _, entry := range entries {
+		if _, _, err := MonthPeriod(entry.Name, time.UTC); entry.Dir && err == nil {
+			periods = append(periods, entry.Name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(periods)))
+	return periods, nil
+}
+
+func (r *Reporter) storedSHA256(name string) (string, error) {
+	file, _, err := r.store.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,225 @@
+package reporting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminEntry(ip, path string, status int, ts time.Time) *models.LogEntry {
+	return &models.LogEntry{Timestamp: ts, SourceIP: ip, Method: "GET", Path: path, StatusCode: status}
+}
+
+func TestAdminPrefix(t *testing.T) {
+	prefixes := []string{"/admin", "/admin/users/", "/wp-login.php"}
+
+	assert.Equal(t, "/admin", adminPrefix("/admin", prefixes))
+	assert.Equal(t, "/admin", adminPrefix("/ADMIN/settings?tab=1", prefixes))
+	assert.Equal(t, "/admin/users/", adminPrefix("/admin/users/42", prefixes))
+	assert.Equal(t, "/wp-login.php", adminPrefix("/wp-login.php?redirect_to=/", prefixes))
+	assert.Equal(t, "", adminPrefix("/administrator", prefixes))
+	assert.Equal(t, "", adminPrefix("/blog/admin", prefixes))
+}
+
+func TestAnalyzeAdminAccess(t *testing.T) {
+	// Monday 2023-10-09 in UTC
+	businessHours := time.Date(2023, 10, 9, 10, 0, 0, 0, time.UTC)
+	lateNight := time.Date(2023, 10, 9, 23, 30, 0, 0, time.UTC)
+	weekend := time.Date(2023, 10, 14, 11, 0, 0, 0, time.UTC)
+
+	entries := []*models.LogEntry{
+		adminEntry("10.0.0.1", "/admin/login", 200, businessHours),
+		adminEntry("10.0.0.1", "/admin/users", 200, lateNight),
+		adminEntry("203.0.113.5", "/wp-admin/", 403, lateNight),
+		adminEntry("203.0.113.5", "/wp-admin/", 401, weekend),
+		adminEntry("10.0.0.2", "/admin", 302, weekend),
+		adminEntry("10.0.0.3", "/products", 200, lateNight),
+	}
+
+	opts := DefaultComplianceOptions()
+	admin, offHours := AnalyzeAdminAccess(entries, opts)
+
+	assert.Equal(t, int64(5), admin.Requests)
+	assert.Equal(t, int64(3), admin.Allowed)
+	assert.Equal(t, int64(2), admin.Denied)
+	assert.Equal(t, int64(3), admin.UniqueIPs)
+	require.Len(t, admin.Paths, 2)
+	assert.Equal(t, AdminPathAccess{Prefix: "/admin", Requests: 3, Allowed: 3, UniqueIPs: 2}, admin.Paths[0])
+	assert.Equal(t, AdminPathAccess{Prefix: "/wp-admin", Requests: 2, Denied: 2, UniqueIPs: 1}, admin.Paths[1])
+
+	require.Len(t, admin.TopIPs, 3)
+	assert.Equal(t, "10.0.0.1", admin.TopIPs[0].IP)
+	assert.Equal(t, businessHours, admin.TopIPs[0].FirstSeen)
+	assert.Equal(t, lateNight, admin.TopIPs[0].LastSeen)
+
+	assert.Equal(t, "Mon, Tue, Wed, Thu, Fri 08:00-18:00 UTC", offHours.BusinessHours)
+	assert.Equal(t, int64(4), offHours.Requests)
+	assert.Equal(t, int64(2), offHours.Allowed)
+	require.Len(t, offHours.Events, 2)
+	assert.Equal(t, "/admin", offHours.Events[0].Path, "most recent first")
+	assert.Equal(t, "/admin/users", offHours.Events[1].Path)
+
+	// Business hours follow the configured timezone
+	opts.Location = time.FixedZone("UTC+2", 2*60*60)
+	_, offHours = AnalyzeAdminAccess(entries[:1], opts)
+	assert.Equal(t, int64(0), offHours.Requests)
+	_, offHours = AnalyzeAdminAccess([]*models.LogEntry{adminEntry("10.0.0.1", "/admin", 200, businessHours.Add(7*time.Hour))}, opts)
+	assert.Equal(t, int64(1), offHours.Requests)
+}
+
+func TestNewCountries(t *testing.T) {
+	periodStart := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	activity := []models.CountryActivity{
+		{Country: "DE", FirstSeen: periodStart.AddDate(0, 0, 9), Requests: 4, UniqueIPs: 1},
+		{Country: "US", FirstSeen: periodStart.AddDate(0, -2, 0), Requests: 900, UniqueIPs: 40},
+		{Country: "BR", FirstSeen: periodStart.AddDate(0, 0, 2), Requests: 12, UniqueIPs: 3},
+	}
+
+	summary := NewCountries(activity, periodStart, 90)
+	assert.True(t, summary.Available)
+	assert.Equal(t, 1, summary.KnownCountries)
+	require.Len(t, summary.Countries, 2)
+	assert.Equal(t, "BR", summary.Countries[0].Country)
+	assert.Equal(t, "DE", summary.Countries[1].Country)
+
+	assert.False(t, NewCountries(nil, periodStart, 90).Available)
+}
+
+func TestAttestRetention(t *testing.T) {
+	now := time.Date(2023, 11, 1, 5, 0, 0, 0, time.UTC)
+
+	oldest := now.AddDate(0, 0, -100)
+	attestation := AttestRetention(&models.RetentionStats{TotalEntries: 50, OldestEntry: &oldest, ExpiredEntries: 7}, 90, 31, now)
+	assert.True(t, attestation.Compliant)
+	assert.Contains(t, attestation.Statement, "7 entries await the next cleanup")
+
+	oldest = now.AddDate(0, 0, -200)
+	attestation = AttestRetention(&models.RetentionStats{TotalEntries: 50, OldestEntry: &oldest, ExpiredEntries: 30}, 90, 31, now)
+	assert.False(t, attestation.Compliant)
+
+	attestation = AttestRetention(&models.RetentionStats{}, 90, 31, now)
+	assert.True(t, attestation.Compliant)
+	assert.Nil(t, attestation.OldestEntry)
+}
+
+func TestAttestLogTypeRetention(t *testing.T) {
+	now := time.Date(2023, 11, 1, 5, 0, 0, 0, time.UTC)
+
+	// 200 days is within a year of retention
+	oldest := now.AddDate(0, 0, -200)
+	attestation := AttestLogTypeRetention("syslog", &models.RetentionStats{TotalEntries: 50, OldestEntry: &oldest}, 365, 31, now)
+	assert.True(t, attestation.Compliant)
+	assert.Equal(t, "syslog", attestation.LogType)
+	assert.Contains(t, attestation.Statement, "syslog entries are kept for 365 days")
+
+	attestation = AttestLogTypeRetention("nginx", &models.RetentionStats{TotalEntries: 50, OldestEntry: &oldest}, 30, 31, now)
+	assert.False(t, attestation.Compliant)
+	assert.Contains(t, attestation.Statement, "nginx entries from")
+}
+
+func TestMonthPeriod(t *testing.T) {
+	start, end, err := MonthPeriod("2023-12", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	_, _, err = MonthPeriod("../etc", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestCompliancePackArchive(t *testing.T) {
+	outputDir := t.TempDir()
+	// Packs are read-only; let the temporary directory be removed
+	t.Cleanup(func() {
+		filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				os.Chmod(path, 0755)
+			}
+			return nil
+		})
+	})
+
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(outputDir))
+	require.NoError(t, err)
+
+	start, end, err := MonthPeriod("2023-10", time.UTC)
+	require.NoError(t, err)
+	ts := time.Date(2023, 10, 9, 23, 30, 0, 0, time.UTC)
+	admin, offHours := AnalyzeAdminAccess([]*models.LogEntry{adminEntry("10.0.0.1", "/admin", 200, ts)}, DefaultComplianceOptions())
+	data := &ComplianceReportData{
+		Title:        "Compliance Access Review",
+		GeneratedAt:  time.Now(),
+		Period:       "2023-10",
+		PeriodStart:  start,
+		PeriodEnd:    end,
+		AdminAccess:  admin,
+		OffHours:     offHours,
+		NewCountries: NewCountries([]models.CountryActivity{{Country: "DE", FirstSeen: ts, Requests: 1, UniqueIPs: 1}}, start, 90),
+		Retention:    AttestRetention(&models.RetentionStats{TotalEntries: 1, OldestEntry: &ts}, 90, 31, end),
+		RetentionByLogType: []*RetentionAttestation{
+			AttestLogTypeRetention("syslog", &models.RetentionStats{}, 365, 31, end),
+		},
+	}
+
+	dir, files, err := reporter.GenerateCompliancePack(data)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, "compliance", "2023-10"), dir)
+	require.Len(t, files, 3)
+
+	html, err := os.ReadFile(filepath.Join(dir, "compliance.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Off-Hours Administrative Access")
+	assert.Contains(t, string(html), "DE")
+	assert.Contains(t, string(html), "<td>syslog</td>")
+
+	var decoded ComplianceReportData
+	raw, err := os.ReadFile(filepath.Join(dir, "compliance.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, int64(1), decoded.OffHours.Allowed)
+
+	manifest, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(manifest)), "\n")
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^[0-9a-f]{64}  compliance.html$`, lines[0])
+
+	info, err := os.Stat(filepath.Join(dir, "compliance.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0444), info.Mode().Perm())
+
+	// An archived period is never replaced
+	_, _, err = reporter.GenerateCompliancePack(data)
+	assert.ErrorIs(t, err, os.ErrExist)
+
+	periods, err := reporter.CompliancePacks()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-10"}, periods)
+
+	mismatches, err := reporter.VerifyCompliancePack("2023-10")
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+
+	// Tampering is detected
+	require.NoError(t, os.Chmod(dir, 0755))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "compliance.json"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compliance.json"), []byte("{}"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "compliance.html")))
+	mismatches, err = reporter.VerifyCompliancePack("2023-10")
+	require.NoError(t, err)
+	require.Len(t, mismatches, 2)
+	assert.Equal(t, "compliance.html", mismatches[0].File)
+	assert.Empty(t, mismatches[0].Actual)
+	assert.Equal(t, "compliance.json", mismatches[1].File)
+
+	_, err = reporter.VerifyCompliancePack("../2023-10")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,455 @@
+package reporting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/patterns"
+)
+
+// WebLogTypes are the access log types a correlation report treats as the
+// web tier by default
+var WebLogTypes = []string{"nginx", "apache", "envoy", "traefik"}
+
+// TraceIDFields are the metadata fields, in order of preference, that
+// carry the ID tying a request to the application logs it caused
+var TraceIDFields = []string{"trace_id", "traceid", "trace", "request_id", "x_request_id", "correlation_id"}
+
+// errorLevels are the application log levels counted as errors
+var errorLevels = map[string]bool{
+	"error": true, "err": true, "fatal": true, "panic": true,
+	"critical": true, "crit": true, "alert": true, "emergency": true, "emerg": true,
+}
+
+// Bounds on what a correlation report lists
+const (
+	maxCorrelationSpikes   = 20
+	maxCorrelationPaths    = 5
+	maxCorrelationPatterns = 10
+	maxCorrelationTraces   = 10
+)
+
+// CorrelationOptions tune how web tier 5xx spikes are found and matched
+type CorrelationOptions struct {
+	// Bucket is the interval 5xx responses are counted in, default 1m
+	Bucket time.Duration
+	// MinErrors is the fewest 5xx responses in a bucket that can make it
+	// a spike, default 5
+	MinErrors int64
+	// Factor is how many times the median bucket's 5xx responses a bucket
+	// needs to be a spike, default 3
+	Factor float64
+	// Slack widens each spike on both sides when matching application
+	// logs, since they may lead or trail the responses, default 2m
+	Slack time.Duration
+}
+
+// DefaultCorrelationOptions returns the default options
+func DefaultCorrelationOptions() CorrelationOptions {
+	return CorrelationOptions{
+		Bucket:    time.Minute,
+		MinErrors: 5,
+		Factor:    3,
+		Slack:     2 * time.Minute,
+	}
+}
+
+// CorrelationReportData contains the data for a cross-tier correlation
+// report
+type CorrelationReportData struct {
+	Title       string
+	GeneratedAt time.Time
+	TimeRange   string
+	WebLogTypes []string
+	AppLogTypes []string
+	Summary     *CorrelationSummary
+}
+
+// CorrelationSummary lines up spikes in the web tier's 5xx responses with
+// what the application logged around them
+type CorrelationSummary struct {
+	WebRequests     int64 `json:"web_requests"`
+	WebServerErrors int64 `json:"web_server_errors"`
+	AppEntries      int64 `json:"app_entries"`
+	AppErrors       int64 `json:"app_errors"`
+	// Baseline is the median 5xx responses per bucket
+	Baseline float64            `json:"baseline"`
+	Bucket   string             `json:"bucket"`
+	Spikes   []CorrelationSpike `json:"spikes"`
+}
+
+// CorrelationSpike is a run of buckets with unusually many 5xx responses
+type CorrelationSpike struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Requests     int64     `json:"requests"`
+	ServerErrors int64     `json:"server_errors"`
+	// PeakErrors is the most 5xx responses in one of the spike's buckets
+	PeakErrors int64         `json:"peak_errors"`
+	TopPaths   []PathSummary `json:"top_paths"`
+	// AppErrors counts application errors from Slack before the spike to
+	// Slack after it
+	AppErrors int64               `json:"app_errors"`
+	Patterns  []CorrelatedPattern `json:"patterns"`
+	Traces    []CorrelatedTrace   `json:"traces"`
+}
+
+// CorrelatedPattern is an application error pattern logged around a spike
+type CorrelatedPattern struct {
+	Template string `json:"template"`
+	Example  string `json:"example"`
+	Count    int64  `json:"count"`
+	// Expected is how often the pattern is logged in a period as long
+	// outside spikes, so patterns far above it stand out
+	Expected float64 `json:"expected"`
+}
+
+// Unusual reports whether the pattern was logged more than twice as often
+// as usual around the spike
+func (p CorrelatedPattern) Unusual() bool {
+	return float64(p.Count) > 2*p.Expected
+}
+
+// CorrelatedTrace is a trace or request ID found both on 5xx responses
+// during a spike and in the application logs around it
+type CorrelatedTrace struct {
+	ID           string `json:"id"`
+	ServerErrors int64  `json:"server_errors"`
+	AppEntries   int64  `json:"app_entries"`
+	AppErrors    int64  `json:"app_errors"`
+	Path         string `json:"path"`
+	Message      string `json:"message"`
+}
+
+// TraceID returns the trace or request ID of an entry, or "" if it has none
+func TraceID(entry *models.LogEntry) string {
+	for _, field := range TraceIDFields {
+		if value, ok := entry.Metadata[field]; ok {
+			if id := strings.TrimSpace(fmt.Sprint(value)); id != "" && id != "-" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// IsAppError reports whether an application log entry is logged at an
+// error level or above
+func IsAppError(entry *models.LogEntry) bool {
+	level, _ := entry.Metadata["level"].(string)
+	return errorLevels[strings.ToLower(level)]
+}
+
+// AnalyzeCorrelation finds the spikes in the web tier's 5xx responses over
+// [start, end) and matches each with the application error patterns and
+// the trace IDs logged around it. app may extend Slack beyond the range,
+// for spikes at its edges. Zero options other than Slack take their
+// defaults.
+func AnalyzeCorrelation(web, app []*models.LogEntry, start, end time.Time, opts CorrelationOptions) *CorrelationSummary {
+	defaults := DefaultCorrelationOptions()
+	if opts.Bucket <= 0 {
+		opts.Bucket = defaults.Bucket
+	}
+	if opts.MinErrors <= 0 {
+		opts.MinErrors = defaults.MinErrors
+	}
+	if opts.Factor <= 0 {
+		opts.Factor = defaults.Factor
+	}
+	if opts.Slack < 0 {
+		opts.Slack = 0
+	}
+
+	summary := &CorrelationSummary{Bucket: opts.Bucket.String(), Spikes: []CorrelationSpike{}}
+	bucketOf := func(t time.Time) int64 { return int64(t.Sub(start) / opts.Bucket) }
+
+	requests := make(map[int64]int64)
+	errors := make(map[int64]int64)
+	for _, entry := range web {
+		if entry.Timestamp.Before(start) || !entry.Timestamp.Before(end) {
+			continue
+		}
+		summary.WebRequests++
+		requests[bucketOf(entry.Timestamp)]++
+		if entry.StatusCode >= 500 {
+			summary.WebServerErrors++
+			errors[bucketOf(entry.Timestamp)]++
+		}
+	}
+	for _, entry := range app {
+		if entry.Timestamp.Before(start) || !entry.Timestamp.Before(end) {
+			continue
+		}
+		summary.AppEntries++
+		if IsAppError(entry) {
+			summary.AppErrors++
+		}
+	}
+
+	buckets := int64(math.Ceil(float64(end.Sub(start)) / float64(opts.Bucket)))
+	summary.Baseline = medianCount(errors, buckets)
+	spikes := findSpikes(errors, summary.Baseline, opts)
+	if len(spikes) == 0 {
+		return summary
+	}
+
+	// Keep the largest spikes, listed in time order
+	if len(spikes) > maxCorrelationSpikes {
+		sort.SliceStable(spikes, func(i, j int) bool { return spikes[i].errors > spikes[j].errors })
+		spikes = spikes[:maxCorrelationSpikes]
+		sort.Slice(spikes, func(i, j int) bool { return spikes[i].first < spikes[j].first })
+	}
+
+	miner := patterns.NewMiner()
+	appPatterns := make([]string, len(app))
+	for i, entry := range app {
+		if IsAppError(entry) && entry.Path != "" {
+			appPatterns[i], _ = miner.Assign(entry.Path, entry.Timestamp)
+		}
+	}
+
+	for _, s := range spikes {
+		spike := CorrelationSpike{
+			Start:      start.Add(time.Duration(s.first) * opts.Bucket),
+			End:        start.Add(time.Duration(s.last+1) * opts.Bucket),
+			PeakErrors: s.peak,
+		}
+		for bucket := s.first; bucket <= s.last; bucket++ {
+			spike.Requests += requests[bucket]
+			spike.ServerErrors += errors[bucket]
+		}
+		summary.Spikes = append(summary.Spikes, spike)
+	}
+
+	outside := end.Sub(start)
+	for _, spike := range summary.Spikes {
+		outside -= spike.End.Sub(spike.Start) + 2*opts.Slack
+	}
+	traces := correlateWeb(summary.Spikes, web)
+	correlateApp(summary.Spikes, traces, app, appPatterns, miner, opts.Slack, outside)
+	return summary
+}
+
+// spikeRun is a run of consecutive spike buckets
+type spikeRun struct {
+	first, last int64
+	errors      int64
+	peak        int64
+}
+
+func findSpikes(errors map[int64]int64, baseline float64, opts CorrelationOptions) []spikeRun {
+	var hot []int64
+	for bucket, count := range errors {
+		if count >= opts.MinErrors && float64(count) > baseline*opts.Factor {
+			hot = append(hot, bucket)
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool { return hot[i] < hot[j] })
+
+	var runs []spikeRun
+	for _, bucket := range hot {
+		count := errors[bucket]
+		if n := len(runs); n > 0 && runs[n-1].last == bucket-1 {
+			runs[n-1].last = bucket
+			runs[n-1].errors += count
+			runs[n-1].peak = max(runs[n-1].peak, count)
+			continue
+		}
+		runs = append(runs, spikeRun{first: bucket, last: bucket, errors: count, peak: count})
+	}
+	return runs
+}
+
+// medianCount is the median of counts over n buckets, those missing from
+// the map counting zero
+func medianCount(counts map[int64]int64, n int64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	values := make([]int64, 0, len(counts))
+	for _, count := range counts {
+		values = append(values, count)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	zeros := n - int64(len(values))
+	at := func(i int64) float64 {
+		if i < zeros {
+			return 0
+		}
+		return float64(values[i-zeros])
+	}
+	if n%2 == 1 {
+		return at(n / 2)
+	}
+	return (at(n/2-1) + at(n/2)) / 2
+}
+
+// spikeAt returns the index of the spike whose period, widened by slack,
+// contains t, or -1
+func spikeAt(spikes []CorrelationSpike, t time.Time, slack time.Duration) int {
+	i := sort.Search(len(spikes), func(i int) bool { return spikes[i].End.Add(slack).After(t) })
+	if i < len(spikes) && !t.Before(spikes[i].Start.Add(-slack)) {
+		return i
+	}
+	return -1
+}
+
+// correlateWeb lists the paths behind each spike's 5xx responses and the
+// trace IDs they carried, returning each spike's traces by ID
+func correlateWeb(spikes []CorrelationSpike, web []*models.LogEntry) []map[string]*CorrelatedTrace {
+	paths := make([]map[string]int64, len(spikes))
+	traces := make([]map[string]*CorrelatedTrace, len(spikes))
+	for _, entry := range web {
+		if entry.StatusCode < 500 {
+			continue
+		}
+		i := spikeAt(spikes, entry.Timestamp, 0)
+		if i < 0 {
+			continue
+		}
+		if paths[i] == nil {
+			paths[i] = make(map[string]int64)
+		}
+		paths[i][entry.Path]++
+
+		if id := TraceID(entry); id != "" {
+			if traces[i] == nil {
+				traces[i] = make(map[string]*CorrelatedTrace)
+			}
+			trace, ok := traces[i][id]
+			if !ok {
+				trace = &CorrelatedTrace{ID: id, Path: entry.Path}
+				traces[i][id] = trace
+			}
+			trace.ServerErrors++
+		}
+	}
+
+	for i := range spikes {
+		spikes[i].TopPaths = topPaths(paths[i], spikes[i].ServerErrors, maxCorrelationPaths)
+	}
+	return traces
+}
+
+// correlateApp counts the application errors and patterns logged around
+// each spike and keeps the traces the application logged too. outside is
+// the time not around any spike, to work out how often each pattern is
+// usually logged.
+func correlateApp(spikes []CorrelationSpike, traces []map[string]*CorrelatedTrace, app []*models.LogEntry, appPatterns []string, miner *patterns.Miner, slack, outside time.Duration) {
+	during := make([]map[string]int64, len(spikes))
+	usual := make(map[string]int64)
+	for k, entry := range app {
+		i := spikeAt(spikes, entry.Timestamp, slack)
+		if i < 0 {
+			if appPatterns[k] != "" {
+				usual[appPatterns[k]]++
+			}
+			continue
+		}
+
+		spike := &spikes[i]
+		isError := IsAppError(entry)
+		if isError {
+			spike.AppErrors++
+		}
+		if appPatterns[k] != "" {
+			if during[i] == nil {
+				during[i] = make(map[string]int64)
+			}
+			during[i][appPatterns[k]]++
+		}
+		if id := TraceID(entry); id != "" {
+			if trace, ok := traces[i][id]; ok {
+				trace.AppEntries++
+				if isError {
+					trace.AppErrors++
+				}
+				if trace.Message == "" || (isError && trace.AppErrors == 1) {
+					trace.Message = entry.Path
+				}
+			}
+		}
+	}
+
+	for i := range spikes {
+		spike := &spikes[i]
+		window := spike.End.Sub(spike.Start) + 2*slack
+		spike.Patterns = []CorrelatedPattern{}
+		spike.Traces = []CorrelatedTrace{}
+		for id, count := range during[i] {
+			pattern, _ := miner.Pattern(id)
+			correlated := CorrelatedPattern{Template: pattern.Template, Count: count}
+			if len(pattern.Examples) > 0 {
+				correlated.Example = pattern.Examples[0]
+			}
+			if outside > 0 {
+				correlated.Expected = float64(usual[id]) * float64(window) / float64(outside)
+			}
+			spike.Patterns = append(spike.Patterns, correlated)
+		}
+		sort.Slice(spike.Patterns, func(a, b int) bool {
+			pa, pb := spike.Patterns[a], spike.Patterns[b]
+			if pa.Count != pb.Count {
+				return pa.Count > pb.Count
+			}
+			return pa.Template < pb.Template
+		})
+		if len(spike.Patterns) > maxCorrelationPatterns {
+			spike.Patterns = spike.Patterns[:maxCorrelationPatterns]
+		}
+
+		// Only traces the application logged tie the tiers together
+		for _, trace := range traces[i] {
+			if trace.AppEntries > 0 {
+				spike.Traces = append(spike.Traces, *trace)
+			}
+		}
+		sort.Slice(spike.Traces, func(a, b int) bool {
+			ta, tb := spike.Traces[a], spike.Traces[b]
+			if ta.AppErrors != tb.AppErrors {
+				return ta.AppErrors > tb.AppErrors
+			}
+			return ta.ID < tb.ID
+		})
+		if len(spike.Traces) > maxCorrelationTraces {
+			spike.Traces = spike.Traces[:maxCorrelationTraces]
+		}
+	}
+}
+
+// topPaths returns the n most common paths with their share of total
+func topPaths(counts map[string]int64, total int64, n int) []PathSummary {
+	var paths []PathSummary
+	for path, count := range counts {
+		paths = append(paths, PathSummary{
+			Path:       path,
+			Count:      count,
+			Percentage: float64(count) / float64(total) * 100,
+		})
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Count != paths[j].Count {
+			return paths[i].Count > paths[j].Count
+		}
+		return paths[i].Path < paths[j].Path
+	})
+	if len(paths) > n {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+// GenerateCorrelationReport generates an HTML report correlating web tier
+// 5xx spikes with application errors
+func (r *Reporter) GenerateCorrelationReport(data *CorrelationReportData, reportName string) (string, error) {
+	// Generate filename with timestamp
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	filename := fmt.Sprintf("%s_correlation_%s.html", reportName, timestamp)
+
+	return r.renderTemplate("correlation.html", filename, data)
+}
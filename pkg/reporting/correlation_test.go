@@ -0,0 +1,134 @@
+package reporting
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// correlationFixture has an hour of web traffic with a steady trickle of
+// 5xx responses and a spike at 10:30, and application logs with a
+// recurring timeout and a database error that starts with the spike
+func correlationFixture(start time.Time) (web, app []*models.LogEntry) {
+	for minute := 0; minute < 60; minute++ {
+		ts := start.Add(time.Duration(minute) * time.Minute)
+		for i := 0; i < 20; i++ {
+			web = append(web, &models.LogEntry{Timestamp: ts, LogType: "nginx", Path: "/", StatusCode: 200})
+		}
+		web = append(web, &models.LogEntry{Timestamp: ts, LogType: "nginx", Path: "/api/slow", StatusCode: 504})
+		if minute%10 == 0 {
+			app = append(app, &models.LogEntry{Timestamp: ts, LogType: "generic", Path: fmt.Sprintf("upstream timeout after %dms", 3000+minute),
+				Metadata: models.LogMetadata{"level": "error"}})
+		}
+	}
+
+	// Two minutes of failing checkouts, each request traced into the app
+	for minute := 30; minute < 32; minute++ {
+		ts := start.Add(time.Duration(minute) * time.Minute)
+		for i := 0; i < 10; i++ {
+			id := fmt.Sprintf("req-%d-%d", minute, i)
+			web = append(web, &models.LogEntry{Timestamp: ts.Add(time.Second), LogType: "nginx", Path: "/api/checkout",
+				StatusCode: 502, Metadata: models.LogMetadata{"request_id": id}})
+			app = append(app,
+				&models.LogEntry{Timestamp: ts, LogType: "generic", Path: "handling checkout",
+					Metadata: models.LogMetadata{"level": "info", "request_id": id}},
+				&models.LogEntry{Timestamp: ts.Add(time.Second), LogType: "generic", Path: fmt.Sprintf("database connection refused pool=%d", i),
+					Metadata: models.LogMetadata{"level": "ERROR", "request_id": id}},
+			)
+		}
+	}
+	// A request the app logged but that succeeded
+	web = append(web, &models.LogEntry{Timestamp: start.Add(30 * time.Minute), LogType: "nginx", Path: "/api/checkout",
+		StatusCode: 200, Metadata: models.LogMetadata{"request_id": "ok-1"}})
+	app = append(app, &models.LogEntry{Timestamp: start.Add(30 * time.Minute), LogType: "generic", Path: "handling checkout",
+		Metadata: models.LogMetadata{"level": "info", "request_id": "ok-1"}})
+	return web, app
+}
+
+func TestAnalyzeCorrelation(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	web, app := correlationFixture(start)
+
+	summary := AnalyzeCorrelation(web, app, start, start.Add(time.Hour), DefaultCorrelationOptions())
+	assert.Equal(t, int64(80), summary.WebServerErrors)
+	assert.Equal(t, int64(26), summary.AppErrors)
+	assert.Equal(t, 1.0, summary.Baseline)
+	assert.Equal(t, "1m0s", summary.Bucket)
+
+	require.Len(t, summary.Spikes, 1)
+	spike := summary.Spikes[0]
+	assert.Equal(t, start.Add(30*time.Minute), spike.Start)
+	assert.Equal(t, start.Add(32*time.Minute), spike.End)
+	assert.Equal(t, int64(22), spike.ServerErrors)
+	assert.Equal(t, int64(11), spike.PeakErrors)
+	assert.Equal(t, int64(63), spike.Requests)
+	require.NotEmpty(t, spike.TopPaths)
+	assert.Equal(t, "/api/checkout", spike.TopPaths[0].Path)
+	assert.Equal(t, int64(20), spike.TopPaths[0].Count)
+
+	// The timeout logged at 10:30 falls within the spike's slack too
+	assert.Equal(t, int64(21), spike.AppErrors)
+	require.Len(t, spike.Patterns, 2)
+	assert.Equal(t, "database connection refused pool=<NUM>", spike.Patterns[0].Template)
+	assert.Equal(t, int64(20), spike.Patterns[0].Count)
+	assert.Zero(t, spike.Patterns[0].Expected, "never logged outside the spike")
+	assert.True(t, spike.Patterns[0].Unusual())
+	assert.Equal(t, int64(1), spike.Patterns[1].Count)
+	assert.InDelta(t, 5.0/54*6, spike.Patterns[1].Expected, 1e-9)
+	assert.False(t, spike.Patterns[1].Unusual())
+
+	require.Len(t, spike.Traces, maxCorrelationTraces)
+	trace := spike.Traces[0]
+	assert.Equal(t, "req-30-0", trace.ID)
+	assert.Equal(t, int64(1), trace.ServerErrors)
+	assert.Equal(t, int64(2), trace.AppEntries)
+	assert.Equal(t, int64(1), trace.AppErrors)
+	assert.Equal(t, "/api/checkout", trace.Path)
+	assert.Equal(t, "database connection refused pool=0", trace.Message, "the error is preferred")
+	for _, trace := range spike.Traces {
+		assert.NotEqual(t, "ok-1", trace.ID, "only traces of 5xx responses")
+	}
+}
+
+func TestAnalyzeCorrelationWithoutSpikes(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	web, _ := correlationFixture(start)
+
+	// The trickle alone never stands out
+	summary := AnalyzeCorrelation(web[:60*21], nil, start, start.Add(time.Hour), CorrelationOptions{})
+	assert.Empty(t, summary.Spikes)
+	assert.Equal(t, int64(60), summary.WebServerErrors)
+
+	summary = AnalyzeCorrelation(nil, nil, start, start.Add(time.Hour), CorrelationOptions{})
+	assert.Empty(t, summary.Spikes)
+	assert.Zero(t, summary.Baseline)
+}
+
+func TestCorrelationReport(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	web, app := correlationFixture(start)
+	report, err := reporter.GenerateCorrelationReport(&CorrelationReportData{
+		Title:       "Checkout outage",
+		GeneratedAt: start,
+		WebLogTypes: WebLogTypes,
+		AppLogTypes: []string{"generic"},
+		Summary:     AnalyzeCorrelation(web, app, start, start.Add(time.Hour), DefaultCorrelationOptions()),
+	}, "outage")
+	require.NoError(t, err)
+
+	html, err := os.ReadFile(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "2024-03-01 10:30 &ndash; 10:32")
+	assert.Contains(t, string(html), "database connection refused pool=&lt;NUM&gt;")
+	assert.Contains(t, string(html), "req-30-0")
+	assert.Contains(t, string(html), "nginx, apache, envoy, traefik")
+}
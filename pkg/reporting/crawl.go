@@ -0,0 +1,161 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/robots"
+)
+
+// CrawlReportData contains the data for a robots.txt compliance report
+type CrawlReportData struct {
+	Title       string
+	GeneratedAt time.Time
+	TimeRange   string
+	// RobotsSource is the URL the robots.txt was fetched from, or "uploaded"
+	RobotsSource string
+	Summary      *CrawlSummary
+}
+
+// CrawlSummary cross-references crawler traffic with robots.txt
+type CrawlSummary struct {
+	BotRequests        int64      `json:"bot_requests"`
+	BotBytes           int64      `json:"bot_bytes"`
+	DisallowedRequests int64      `json:"disallowed_requests"`
+	Bots               []BotCrawl `json:"bots"`
+	Sitemaps           []string   `json:"sitemaps,omitempty"`
+}
+
+// BotCrawl is one crawler's crawl budget consumption and compliance
+type BotCrawl struct {
+	Bot string `json:"bot"`
+	// Group is the robots.txt user agent whose rules apply, "*" for the
+	// default group, or empty when none do
+	Group       string  `json:"group"`
+	Requests    int64   `json:"requests"`
+	Bytes       int64   `json:"bytes"`
+	UniquePaths int64   `json:"unique_paths"`
+	Share       float64 `json:"share"`
+	// Requests answered with 3xx, 4xx and 5xx spend budget without
+	// fetching content
+	RedirectRequests int64 `json:"redirect_requests"`
+	ErrorRequests    int64 `json:"error_requests"`
+	// DisallowedRequests fetched paths robots.txt disallows for the bot
+	DisallowedRequests int64         `json:"disallowed_requests"`
+	DisallowedPaths    []PathSummary `json:"disallowed_paths,omitempty"`
+	CrawlDelay         float64       `json:"crawl_delay,omitempty"`
+	// PeakRequestsPerMinute is the most requests in any minute, to compare
+	// against the crawl delay
+	PeakRequestsPerMinute int64     `json:"peak_requests_per_minute"`
+	FirstSeen             time.Time `json:"first_seen"`
+	LastSeen              time.Time `json:"last_seen"`
+}
+
+// maxDisallowedPaths bounds the disallowed paths listed per crawler
+const maxDisallowedPaths = 10
+
+type botTally struct {
+	crawl      BotCrawl
+	paths      map[string]bool
+	disallowed map[string]int64
+	minutes    map[int64]int64
+}
+
+// AnalyzeCrawl tallies crawler requests per bot and checks each against the
+// robots.txt rules for that bot
+func AnalyzeCrawl(rules *robots.Robots, entries []*models.LogEntry) *CrawlSummary {
+	summary := &CrawlSummary{Sitemaps: rules.Sitemaps}
+	tallies := make(map[string]*botTally)
+	groups := make(map[string]*robots.Group)
+
+	for _, entry := range entries {
+		bot, ok := robots.DetectBot(entry.UserAgent)
+		if !ok {
+			continue
+		}
+
+		key := strings.ToLower(bot)
+		tally, ok := tallies[key]
+		if !ok {
+			group := rules.GroupFor(entry.UserAgent)
+			groups[key] = group
+			tally = &botTally{
+				crawl:      BotCrawl{Bot: bot, FirstSeen: entry.Timestamp, LastSeen: entry.Timestamp},
+				paths:      make(map[string]bool),
+				disallowed: make(map[string]int64),
+				minutes:    make(map[int64]int64),
+			}
+			if group != nil {
+				tally.crawl.Group = group.UserAgents[0]
+				tally.crawl.CrawlDelay = group.CrawlDelay
+			}
+			tallies[key] = tally
+		}
+
+		crawl := &tally.crawl
+		crawl.Requests++
+		crawl.Bytes += entry.ResponseSize
+		tally.paths[entry.Path] = true
+		tally.minutes[entry.Timestamp.Unix()/60]++
+		if entry.Timestamp.Before(crawl.FirstSeen) {
+			crawl.FirstSeen = entry.Timestamp
+		}
+		if entry.Timestamp.After(crawl.LastSeen) {
+			crawl.LastSeen = entry.Timestamp
+		}
+
+		switch {
+		case entry.StatusCode >= 400:
+			crawl.ErrorRequests++
+		case entry.StatusCode >= 300:
+			crawl.RedirectRequests++
+		}
+
+		if allowed, _ := groups[key].Allowed(entry.Path); !allowed {
+			crawl.DisallowedRequests++
+			tally.disallowed[entry.Path]++
+			summary.DisallowedRequests++
+		}
+
+		summary.BotRequests++
+		summary.BotBytes += entry.ResponseSize
+	}
+
+	for _, tally := range tallies {
+		crawl := tally.crawl
+		crawl.UniquePaths = int64(len(tally.paths))
+		crawl.Share = float64(crawl.Requests) / float64(summary.BotRequests) * 100
+		for _, count := range tally.minutes {
+			if count > crawl.PeakRequestsPerMinute {
+				crawl.PeakRequestsPerMinute = count
+			}
+		}
+		crawl.DisallowedPaths = topDisallowedPaths(tally.disallowed, crawl.DisallowedRequests)
+		summary.Bots = append(summary.Bots, crawl)
+	}
+	sort.Slice(summary.Bots, func(i, j int) bool {
+		if summary.Bots[i].Requests != summary.Bots[j].Requests {
+			return summary.Bots[i].Requests > summary.Bots[j].Requests
+		}
+		return summary.Bots[i].Bot < summary.Bots[j].Bot
+	})
+
+	return summary
+}
+
+func topDisallowedPaths(counts map[string]int64, total int64) []PathSummary {
+	return topPaths(counts, total, maxDisallowedPaths)
+}
+
+// GenerateCrawlReport generates an HTML robots.txt compliance and crawl
+// budget report
+func (r *Reporter) GenerateCrawlReport(data *CrawlReportData, reportName string) (string, error) {
+	// Generate filename with timestamp
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	filename := fmt.Sprintf("%s_robots_%s.html", reportName, timestamp)
+
+	return r.renderTemplate("robots.html", filename, data)
+}
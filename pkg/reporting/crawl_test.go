@@ -0,0 +1,69 @@
+package reporting
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/robots"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeCrawl(t *testing.T) {
+	rules, err := robots.Parse(strings.NewReader("User-agent: *\nDisallow: /cart\n\nUser-agent: Googlebot\nDisallow: /internal\nCrawl-delay: 5\n"))
+	require.NoError(t, err)
+
+	const googlebot = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	const bingbot = "Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)"
+	base := time.Date(2023, 10, 10, 13, 0, 0, 0, time.UTC)
+	request := func(userAgent, path string, status int, at time.Duration) *models.LogEntry {
+		return &models.LogEntry{
+			Timestamp:    base.Add(at),
+			UserAgent:    userAgent,
+			Path:         path,
+			StatusCode:   status,
+			ResponseSize: 100,
+		}
+	}
+
+	entries := []*models.LogEntry{
+		request(googlebot, "/", 200, 0),
+		request(googlebot, "/internal/a", 200, time.Second),
+		request(googlebot, "/internal/a", 200, 2*time.Second),
+		request(googlebot, "/cart", 301, time.Minute),
+		request(googlebot, "/missing", 404, 2*time.Minute),
+		request(bingbot, "/cart", 200, 0),
+		request(bingbot, "/", 200, time.Minute),
+		// Visitors are not crawlers
+		request("Mozilla/5.0 (Windows NT 10.0; Win64; x64)", "/cart", 200, 0),
+	}
+
+	summary := AnalyzeCrawl(rules, entries)
+	assert.Equal(t, int64(7), summary.BotRequests)
+	assert.Equal(t, int64(700), summary.BotBytes)
+	assert.Equal(t, int64(3), summary.DisallowedRequests)
+	require.Len(t, summary.Bots, 2)
+
+	google := summary.Bots[0]
+	assert.Equal(t, "Googlebot", google.Bot)
+	assert.Equal(t, "googlebot", google.Group)
+	assert.Equal(t, int64(5), google.Requests)
+	assert.Equal(t, int64(4), google.UniquePaths)
+	assert.InDelta(t, 71.43, google.Share, 0.01)
+	assert.Equal(t, int64(1), google.RedirectRequests)
+	assert.Equal(t, int64(1), google.ErrorRequests)
+	assert.Equal(t, int64(2), google.DisallowedRequests)
+	assert.Equal(t, []PathSummary{{Path: "/internal/a", Count: 2, Percentage: 100}}, google.DisallowedPaths)
+	assert.Equal(t, 5.0, google.CrawlDelay)
+	assert.Equal(t, int64(3), google.PeakRequestsPerMinute)
+	assert.Equal(t, base, google.FirstSeen)
+	assert.Equal(t, base.Add(2*time.Minute), google.LastSeen)
+
+	bing := summary.Bots[1]
+	assert.Equal(t, "bingbot", bing.Bot)
+	assert.Equal(t, "*", bing.Group)
+	assert.Equal(t, int64(1), bing.DisallowedRequests)
+	assert.Equal(t, "/cart", bing.DisallowedPaths[0].Path)
+}
@@ -0,0 +1,110 @@
+package reporting
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// EntryCursor calls fn with each entry of an export in turn and stops at
+// the first error fn returns. storage.LogRepository's Stream bound to a
+// filter is one.
+type EntryCursor func(fn func(*models.LogEntry) error) error
+
+// SliceCursor returns a cursor over entries already in memory
+func SliceCursor(entries []*models.LogEntry) EntryCursor {
+	return func(fn func(*models.LogEntry) error) error {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// GenerateCSVStream generates the CSV export GenerateCSVReport does, of
+// the entries cursor yields instead of data.LogEntries. Each row is
+// written as it is read and the file is spooled to disk, so exports of
+// millions of entries are never held in memory. With compress the file is
+// gzipped and named .csv.gz.
+func (r *Reporter) GenerateCSVStream(data *ReportData, reportName string, cursor EntryCursor, compress bool) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_%s.csv", reportName, timestamp)
+	if compress {
+		filename += ".gz"
+	}
+
+	err := r.putStream(filename, func(w io.Writer) error {
+		var gz *gzip.Writer
+		if compress {
+			gz = gzip.NewWriter(w)
+			w = gz
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.Write(entryCSVHeader); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		row := r.entryCSVRow()
+		err := cursor(func(entry *models.LogEntry) error {
+			if err := writer.Write(row(entry)); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+		if gz != nil {
+			return gz.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return r.store.Location(filename), nil
+}
+
+// putStream saves the file write produces and records its metadata. The
+// file is spooled to a temporary file rather than memory and hashed on
+// the way. Nothing is saved when write fails.
+func (r *Reporter) putStream(name string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp("", "report-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	buffered := bufio.NewWriter(io.MultiWriter(tmp, hash))
+	if err := write(buffered); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to seek temporary file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek temporary file: %w", err)
+	}
+	if err := r.store.PutReader(name, tmp, size); err != nil {
+		return fmt.Errorf("failed to save %s: %w", name, err)
+	}
+	return r.recordSum(name, size, hash.Sum(nil))
+}
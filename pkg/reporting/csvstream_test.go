@@ -0,0 +1,108 @@
+package reporting
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/memory"
+)
+
+// countingCursor yields n generated entries without keeping them
+func countingCursor(n int) EntryCursor {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return func(fn func(*models.LogEntry) error) error {
+		for i := 0; i < n; i++ {
+			entry := &models.LogEntry{
+				Timestamp:  base.Add(time.Duration(i) * time.Second),
+				LogType:    "nginx",
+				SourceIP:   fmt.Sprintf("192.0.2.%d", i%250),
+				Method:     "GET",
+				Path:       fmt.Sprintf("/items/%d", i),
+				StatusCode: 200,
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestGenerateCSVStream(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+	catalog := memory.New()
+	reporter.SetCatalog(catalog)
+	data := &ReportData{Title: "Daily", GeneratedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+
+	location, err := reporter.GenerateCSVStream(data, "daily", countingCursor(10000), false)
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.csv", filepath.Base(location))
+	records := readCSV(t, location)
+	require.Len(t, records, 10001)
+	assert.Equal(t, entryCSVHeader, records[0])
+	assert.Equal(t, "/items/0", records[1][5])
+	assert.Equal(t, "/items/9999", records[10000][5])
+
+	location, err = reporter.GenerateCSVStream(data, "daily", countingCursor(10000), true)
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.csv.gz", filepath.Base(location))
+	compressed, err := os.ReadFile(location)
+	require.NoError(t, err)
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	records, err = csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 10001)
+	assert.Less(t, len(compressed), len(content)/4)
+
+	// The catalog has the size and checksum of the stored file
+	file, err := catalog.GetReportFile("daily_2024-03-01_10-00-00.csv.gz")
+	require.NoError(t, err)
+	sum := sha256.Sum256(compressed)
+	assert.Equal(t, int64(len(compressed)), file.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256)
+
+	// Entries in memory give the same file as GenerateCSVReport
+	entries := []*models.LogEntry{{Timestamp: data.GeneratedAt, SourceIP: "192.0.2.1", Path: "/", StatusCode: 200}}
+	data.LogEntries = entries
+	location, err = reporter.GenerateCSVReport(data, "memory")
+	require.NoError(t, err)
+	want, err := os.ReadFile(location)
+	require.NoError(t, err)
+	location, err = reporter.GenerateCSVStream(data, "streamed", SliceCursor(entries), false)
+	require.NoError(t, err)
+	got, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+
+	// A failing cursor saves nothing
+	failed := errors.New("connection reset")
+	_, err = reporter.GenerateCSVStream(data, "failed", func(fn func(*models.LogEntry) error) error {
+		if err := countingCursor(5)(fn); err != nil {
+			return err
+		}
+		return failed
+	}, false)
+	assert.ErrorIs(t, err, failed)
+	_, err = os.Stat(filepath.Join(dir, "failed_2024-03-01_10-00-00.csv"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
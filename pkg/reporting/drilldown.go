@@ -0,0 +1,161 @@
+package reporting
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logsPath is the API that lists the log entries a drill-down link selects
+const logsPath = "/api/v1/logs"
+
+// SetPublicURL makes drill-down links absolute, so reports opened outside
+// the server, such as from a bucket or a download, still link back to it.
+// Links are relative to the server serving the report until it is set.
+func (r *Reporter) SetPublicURL(publicURL string) {
+	r.publicURL = strings.TrimRight(publicURL, "/")
+}
+
+// drillDown builds links to the entries of a report that match a row
+type drillDown struct {
+	base  string
+	scope url.Values
+	// start and end are the report's period, zero without entries
+	start, end time.Time
+}
+
+// newDrillDown scopes links to the report's filters and period. Without a
+// filtered period, the period runs from the first entry to the last.
+func (r *Reporter) newDrillDown(data *ReportData) *drillDown {
+	scope := url.Values{}
+	var start, end time.Time
+	for _, entry := range data.LogEntries {
+		if start.IsZero() || entry.Timestamp.Before(start) {
+			start = entry.Timestamp
+		}
+		if entry.Timestamp.After(end) {
+			end = entry.Timestamp
+		}
+	}
+	// The end of a filter is exclusive
+	end = end.Add(time.Nanosecond)
+
+	if filter := data.Filters; filter != nil {
+		if filter.StartTime != nil {
+			start = *filter.StartTime
+		}
+		if filter.EndTime != nil {
+			end = *filter.EndTime
+		}
+		setValue(scope, "log_type", filter.LogType)
+		setValue(scope, "source_ip", filter.SourceIP)
+		setValue(scope, "path", filter.Path)
+		setValue(scope, "method", filter.Method)
+		if filter.StatusCode != nil {
+			scope.Set("status_code", strconv.Itoa(*filter.StatusCode))
+		}
+		if filter.MinStatusCode > 0 {
+			scope.Set("min_status_code", strconv.Itoa(filter.MinStatusCode))
+		}
+	}
+	d := &drillDown{base: r.publicURL + logsPath, scope: scope}
+	if len(data.LogEntries) > 0 {
+		d.start, d.end = start, end
+		scope.Set("start_time", formatLinkTime(start))
+		scope.Set("end_time", formatLinkTime(end))
+	}
+	return d
+}
+
+// link returns the link to the report's entries that also match the
+// alternating keys and values of slice
+func (d *drillDown) link(slice ...string) string {
+	query := url.Values{}
+	for key, values := range d.scope {
+		query[key] = values
+	}
+	for i := 0; i+1 < len(slice); i += 2 {
+		query.Set(slice[i], slice[i+1])
+	}
+	return d.base + "?" + query.Encode()
+}
+
+// pathLink links to the entries of exactly one path
+func (d *drillDown) pathLink(path string) string {
+	if path == "" {
+		return ""
+	}
+	return d.link("path", path, "exact_path", "true")
+}
+
+func formatLinkTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func setValue(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+// prepareDrillDown links each aggregate row of the summary to the entries
+// it counts
+func (r *Reporter) prepareDrillDown(data *ReportData) {
+	d := r.newDrillDown(data)
+	summary := &data.Summary
+
+	for i := range summary.TopPaths {
+		summary.TopPaths[i].Link = d.pathLink(summary.TopPaths[i].Path)
+	}
+	for i := range summary.TopIPs {
+		if ip := summary.TopIPs[i].IP; ip != "" {
+			summary.TopIPs[i].Link = d.link("source_ip", ip)
+		}
+	}
+	for i := range summary.MethodBreakdown {
+		summary.MethodBreakdown[i].Link = d.link("method", summary.MethodBreakdown[i].Method)
+	}
+	for i := range summary.PathMethodBreakdown {
+		row := &summary.PathMethodBreakdown[i]
+		row.Link = d.link("path", row.Path, "exact_path", "true", "method", row.Method)
+	}
+
+	summary.StatusCodeLinks = make(map[string]string, len(summary.StatusCodeBreakdown))
+	for code := range summary.StatusCodeBreakdown {
+		// Entries without a status code cannot be selected by it
+		summary.StatusCodeLinks[code] = ""
+		if code != "0" {
+			summary.StatusCodeLinks[code] = d.link("status_code", code)
+		}
+	}
+
+	// An hour of day selects a single clock hour only when all of its
+	// entries fall within one
+	hours := make(map[int]time.Time)
+	recurring := make(map[int]bool)
+	for _, entry := range data.LogEntries {
+		t := entry.Timestamp
+		hour := t.Hour()
+		start := time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+		if seen, ok := hours[hour]; ok && !seen.Equal(start) {
+			recurring[hour] = true
+		}
+		hours[hour] = start
+	}
+	for i := range summary.HourlyTraffic {
+		hour := summary.HourlyTraffic[i].Hour
+		start, ok := hours[hour]
+		if !ok || recurring[hour] {
+			continue
+		}
+		end := start.Add(time.Hour)
+		if d.start.After(start) {
+			start = d.start
+		}
+		if d.end.Before(end) {
+			end = d.end
+		}
+		summary.HourlyTraffic[i].Link = d.link("start_time", formatLinkTime(start), "end_time", formatLinkTime(end))
+	}
+}
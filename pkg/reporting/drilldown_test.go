@@ -0,0 +1,117 @@
+package reporting
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// linkQuery parses a drill-down link, checking it targets the logs API
+func linkQuery(t *testing.T, link, base string) url.Values {
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, base+logsPath, u.Scheme+"://"+u.Host+u.Path)
+	return u.Query()
+}
+
+func TestDrillDownLinks(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, ip, path string, status int) *models.LogEntry {
+		return &models.LogEntry{Timestamp: day.Add(offset), LogType: "nginx", SourceIP: ip, Method: "GET", Path: path, StatusCode: status}
+	}
+	data := &ReportData{LogEntries: []*models.LogEntry{
+		entry(9*time.Hour+15*time.Minute, "10.0.0.1", "/", 200),
+		entry(9*time.Hour+45*time.Minute, "10.0.0.1", "/api/orders", 500),
+		entry(10*time.Hour, "10.0.0.2", "/", 200),
+		entry(34*time.Hour, "10.0.0.2", "/", 0),
+	}}
+
+	reporter := &Reporter{}
+	reporter.SetPublicURL("https://logs.example.com/")
+	reporter.prepareSummary(data)
+	base := "https://logs.example.com"
+
+	top := data.Summary.TopPaths[0]
+	require.Equal(t, "/", top.Path)
+	query := linkQuery(t, top.Link, base)
+	assert.Equal(t, "/", query.Get("path"))
+	assert.Equal(t, "true", query.Get("exact_path"), "/ must not match every path")
+	assert.Equal(t, "2024-01-15T09:15:00Z", query.Get("start_time"))
+	assert.Equal(t, "2024-01-16T10:00:00.000000001Z", query.Get("end_time"), "the last entry is included")
+
+	query = linkQuery(t, data.Summary.TopIPs[0].Link, base)
+	assert.NotEmpty(t, query.Get("source_ip"))
+	assert.Empty(t, query.Get("path"))
+
+	query = linkQuery(t, data.Summary.StatusCodeLinks["500"], base)
+	assert.Equal(t, "500", query.Get("status_code"))
+	assert.Empty(t, data.Summary.StatusCodeLinks["0"], "entries without a status code cannot be selected")
+
+	// 09:00 occurs on one day only, clipped to the report's period
+	query = linkQuery(t, data.Summary.HourlyTraffic[9].Link, base)
+	assert.Equal(t, "2024-01-15T09:15:00Z", query.Get("start_time"))
+	assert.Equal(t, "2024-01-15T10:00:00Z", query.Get("end_time"))
+	assert.Empty(t, data.Summary.HourlyTraffic[10].Link, "10:00 occurs on both days")
+	assert.Empty(t, data.Summary.HourlyTraffic[11].Link, "11:00 has no entries")
+}
+
+func TestDrillDownFilters(t *testing.T) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	status := 404
+	data := &ReportData{
+		Filters: &models.LogFilter{StartTime: &start, EndTime: &end, LogType: "nginx", Path: "/api", StatusCode: &status},
+		LogEntries: []*models.LogEntry{
+			{Timestamp: start.Add(time.Hour), LogType: "nginx", SourceIP: "10.0.0.1", Method: "GET", Path: "/api/users", StatusCode: 404},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	link := data.Summary.TopIPs[0].Link
+	assert.Regexp(t, `^/api/v1/logs\?`, link, "links are relative without a public URL")
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	query := u.Query()
+	assert.Equal(t, "10.0.0.1", query.Get("source_ip"))
+	assert.Equal(t, "nginx", query.Get("log_type"))
+	assert.Equal(t, "/api", query.Get("path"), "the report's filters still apply")
+	assert.Equal(t, "404", query.Get("status_code"))
+	assert.Equal(t, "2024-01-15T00:00:00Z", query.Get("start_time"))
+	assert.Equal(t, "2024-01-16T00:00:00Z", query.Get("end_time"))
+
+	u, err = url.Parse(data.Summary.TopPaths[0].Link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users", u.Query().Get("path"), "a row's slice replaces the report's filter")
+}
+
+func TestDrillDownHTML(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC),
+		LogEntries: []*models.LogEntry{
+			{Timestamp: time.Date(2024, 1, 14, 9, 0, 0, 0, time.UTC), LogType: "nginx", SourceIP: "10.0.0.1", Method: "GET", Path: "/a b", StatusCode: 200},
+		},
+	}
+	_, err = reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+
+	html, err := os.ReadFile(filepath.Join(dir, "daily_2024-01-15_02-00-00.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<a class="drill-down" href="/api/v1/logs?end_time=`)
+	assert.Contains(t, string(html), `path=%2Fa&#43;b`)
+	assert.Contains(t, string(html), `const hourlyLinks = [`)
+}
@@ -0,0 +1,101 @@
+package reporting
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// erasureDir holds the certificates of completed erasures
+const erasureDir = "erasures"
+
+// RedactReports rewrites every saved text report that redact changes, and
+// returns the names of those rewritten and of the archived compliance
+// pack files it would have changed. Packs are immutable records and keep
+// their contents; certificates of earlier erasures are skipped.
+func (r *Reporter) RedactReports(redact func([]byte) ([]byte, bool)) (redacted, retained []string, err error) {
+	names, err := r.storedFiles("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redacted, retained = []string{}, []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, erasureDir+"/") || !isText(name) {
+			continue
+		}
+		data, err := r.readStored(name)
+		if err != nil {
+			return redacted, retained, err
+		}
+		changed, ok := redact(data)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(name, "compliance/") {
+			retained = append(retained, name)
+			continue
+		}
+		if err := r.put(name, changed); err != nil {
+			return redacted, retained, fmt.Errorf("failed to redact %s: %w", name, err)
+		}
+		redacted = append(redacted, name)
+	}
+	return redacted, retained, nil
+}
+
+// SaveErasureCertificate stores the certificate of an erasure and returns
+// where it is kept
+func (r *Reporter) SaveErasureCertificate(id string, data []byte) (string, error) {
+	name := erasureDir + "/" + id + ".json"
+	if err := r.put(name, data); err != nil {
+		return "", fmt.Errorf("failed to save erasure certificate: %w", err)
+	}
+	return r.store.Location(name), nil
+}
+
+// ErasureCertificate reads the certificate of an erasure
+func (r *Reporter) ErasureCertificate(id string) ([]byte, error) {
+	return r.readStored(erasureDir + "/" + id + ".json")
+}
+
+// storedFiles returns the names of the reports in dir and below it
+func (r *Reporter) storedFiles(dir string) ([]string, error) {
+	objects, err := r.store.List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, object := range objects {
+		name := path.Join(dir, object.Name)
+		if !object.Dir {
+			names = append(names, name)
+			continue
+		}
+		nested, err := r.storedFiles(name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, nested...)
+	}
+	return names, nil
+}
+
+func (r *Reporter) readStored(name string) ([]byte, error) {
+	file, _, err := r.store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// isText reports whether a report holds text a subject may appear in
+func isText(name string) bool {
+	contentType := reportstore.ContentType(name)
+	return strings.HasPrefix(contentType, "text/") || strings.HasPrefix(contentType, "application/json")
+}
@@ -0,0 +1,51 @@
+package reporting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactReports(t *testing.T) {
+	store := reportstore.NewLocal(t.TempDir())
+	reporter, err := NewReporter("../../web/templates", store)
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"daily_2024-03-01_00-00-00.html":    "<td>192.0.2.1</td>",
+		"daily_2024-03-01_00-00-00.csv":     "10.0.0.1,GET",
+		"metrics/daily.json":                `{"top_ip":"192.0.2.1"}`,
+		"compliance/2024-02/compliance.csv": "192.0.2.1,/admin",
+		"chart.png":                         "192.0.2.1",
+	}
+	for name, content := range files {
+		require.NoError(t, store.Put(name, []byte(content)))
+	}
+
+	redact := func(data []byte) ([]byte, bool) {
+		if !bytes.Contains(data, []byte("192.0.2.1")) {
+			return data, false
+		}
+		return bytes.ReplaceAll(data, []byte("192.0.2.1"), []byte("192.0.2.0")), true
+	}
+	redacted, retained, err := reporter.RedactReports(redact)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"daily_2024-03-01_00-00-00.html", "metrics/daily.json"}, redacted)
+	assert.Equal(t, []string{"compliance/2024-02/compliance.csv"}, retained, "packs are not rewritten")
+
+	data, err := reporter.readStored("daily_2024-03-01_00-00-00.html")
+	require.NoError(t, err)
+	assert.Equal(t, "<td>192.0.2.0</td>", string(data))
+	data, err = reporter.readStored("chart.png")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", string(data), "only text reports are searched")
+
+	_, err = reporter.SaveErasureCertificate("abc", []byte(`{"id":"abc"}`))
+	require.NoError(t, err)
+	data, err = reporter.ErasureCertificate("abc")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"abc"}`, string(data))
+}
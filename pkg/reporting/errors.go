@@ -0,0 +1,376 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Bounds of the error report's lists
+const (
+	maxErrorPaths   = 10
+	maxErrorIPs     = 10
+	maxErrorPairs   = 25
+	maxErrorSamples = 3
+	// maxErrorTrendBuckets bounds the points of the error trend: it is
+	// counted in the shortest interval that keeps within it, or per day
+	maxErrorTrendBuckets = 48
+)
+
+// errorTrendIntervals are the intervals the error trend may be counted in
+var errorTrendIntervals = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute,
+	time.Hour, 6 * time.Hour, 24 * time.Hour,
+}
+
+// ErrorReportData contains the data for an error report
+type ErrorReportData struct {
+	Title       string
+	GeneratedAt time.Time
+	TimeRange   string
+	Summary     *ErrorSummary
+	// Truncated is set when the period had more errors than were analyzed
+	Truncated bool
+}
+
+// ErrorSummary breaks down the 4xx and 5xx responses of a period
+type ErrorSummary struct {
+	Start time.Time
+	End   time.Time
+	// Errors counts the error entries analyzed, split into ClientErrors
+	// (4xx) and ServerErrors (5xx)
+	Errors       int64
+	ClientErrors int64
+	ServerErrors int64
+	// Requests and ErrorRate cover every request of the period, from the
+	// traffic rollups; Requests is 0 when they do not cover the period
+	Requests  int64
+	ErrorRate float64
+	// TopPaths and TopIPs are the paths and clients with the most errors
+	TopPaths []PathSummary
+	TopIPs   []IPSummary
+	// Trend counts errors per TrendInterval across the period
+	Trend         []ErrorTrendPoint
+	TrendInterval time.Duration
+	// Pairs are the most common path and status code combinations
+	Pairs []ErrorPair
+}
+
+// TrendPeriod names the trend's interval, such as "5 minutes"
+func (s *ErrorSummary) TrendPeriod() string {
+	switch {
+	case s.TrendInterval >= 24*time.Hour:
+		return pluralPeriod(int(s.TrendInterval/(24*time.Hour)), "day")
+	case s.TrendInterval >= time.Hour:
+		return pluralPeriod(int(s.TrendInterval/time.Hour), "hour")
+	default:
+		return pluralPeriod(int(s.TrendInterval/time.Minute), "minute")
+	}
+}
+
+func pluralPeriod(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// ErrorTrendPoint counts the errors of one interval of the trend
+type ErrorTrendPoint struct {
+	Start        time.Time
+	ClientErrors int64
+	ServerErrors int64
+	// Link drills down into the interval's errors
+	Link string
+}
+
+// Errors counts the interval's errors of either kind
+func (p ErrorTrendPoint) Errors() int64 {
+	return p.ClientErrors + p.ServerErrors
+}
+
+// ErrorPair is a path answered with an error status code: how often,
+// when first and last, and sample log lines
+type ErrorPair struct {
+	Path       string
+	StatusCode int
+	Count      int64
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	// Samples are the raw log lines of the latest occurrences, most
+	// recent first
+	Samples []string
+	// Link drills down into the pair's entries
+	Link string
+}
+
+// AnalyzeErrors breaks down the entries with a 4xx or 5xx status code over
+// [start, end). Without a period, it runs from the first entry to the
+// last.
+func AnalyzeErrors(entries []*models.LogEntry, start, end time.Time) *ErrorSummary {
+	summary := &ErrorSummary{Start: start, End: end}
+	paths := make(map[string]int64)
+	ips := make(map[string]int64)
+	type pairKey struct {
+		path   string
+		status int
+	}
+	pairs := make(map[pairKey]*ErrorPair)
+	samples := make(map[pairKey][]*models.LogEntry)
+	var failed []*models.LogEntry
+
+	for _, entry := range entries {
+		if entry.StatusCode < 400 {
+			continue
+		}
+		failed = append(failed, entry)
+		summary.Errors++
+		if entry.StatusCode >= 500 {
+			summary.ServerErrors++
+		} else {
+			summary.ClientErrors++
+		}
+		paths[entry.Path]++
+		ips[entry.SourceIP]++
+
+		key := pairKey{entry.Path, entry.StatusCode}
+		pair, ok := pairs[key]
+		if !ok {
+			pair = &ErrorPair{Path: entry.Path, StatusCode: entry.StatusCode, FirstSeen: entry.Timestamp, LastSeen: entry.Timestamp}
+			pairs[key] = pair
+		}
+		pair.Count++
+		if entry.Timestamp.Before(pair.FirstSeen) {
+			pair.FirstSeen = entry.Timestamp
+		}
+		if entry.Timestamp.After(pair.LastSeen) {
+			pair.LastSeen = entry.Timestamp
+		}
+		if entry.RawLog != "" {
+			samples[key] = latestSamples(samples[key], entry)
+		}
+	}
+
+	if len(failed) > 0 && (summary.Start.IsZero() || summary.End.IsZero()) {
+		first, last := entryPeriod(failed)
+		if summary.Start.IsZero() {
+			summary.Start = first
+		}
+		if summary.End.IsZero() {
+			// The end is exclusive
+			summary.End = last.Add(time.Nanosecond)
+		}
+	}
+
+	summary.TopPaths = topPaths(paths, summary.Errors, maxErrorPaths)
+	summary.TopIPs = topIPs(ips, summary.Errors, maxErrorIPs)
+	summary.TrendInterval, summary.Trend = errorTrend(failed, summary.Start, summary.End)
+
+	summary.Pairs = make([]ErrorPair, 0, len(pairs))
+	for key, pair := range pairs {
+		for _, sample := range samples[key] {
+			pair.Samples = append(pair.Samples, sample.RawLog)
+		}
+		summary.Pairs = append(summary.Pairs, *pair)
+	}
+	sort.Slice(summary.Pairs, func(i, j int) bool {
+		a, b := summary.Pairs[i], summary.Pairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.StatusCode < b.StatusCode
+	})
+	if len(summary.Pairs) > maxErrorPairs {
+		summary.Pairs = summary.Pairs[:maxErrorPairs]
+	}
+	return summary
+}
+
+// latestSamples adds entry to samples, keeping the maxErrorSamples most
+// recent entries, most recent first
+func latestSamples(samples []*models.LogEntry, entry *models.LogEntry) []*models.LogEntry {
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].Timestamp.Before(entry.Timestamp)
+	})
+	if i >= maxErrorSamples {
+		return samples
+	}
+	samples = append(samples, nil)
+	copy(samples[i+1:], samples[i:])
+	samples[i] = entry
+	if len(samples) > maxErrorSamples {
+		samples = samples[:maxErrorSamples]
+	}
+	return samples
+}
+
+// entryPeriod returns the timestamps of the first and last entries
+func entryPeriod(entries []*models.LogEntry) (first, last time.Time) {
+	for _, entry := range entries {
+		if first.IsZero() || entry.Timestamp.Before(first) {
+			first = entry.Timestamp
+		}
+		if entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+	return first, last
+}
+
+// errorTrend counts errors per interval from start to end, including
+// intervals without errors
+func errorTrend(failed []*models.LogEntry, start, end time.Time) (time.Duration, []ErrorTrendPoint) {
+	if !end.After(start) {
+		return 0, nil
+	}
+	interval := errorTrendIntervals[len(errorTrendIntervals)-1]
+	for _, candidate := range errorTrendIntervals {
+		if end.Sub(start.Truncate(candidate)) <= time.Duration(maxErrorTrendBuckets)*candidate {
+			interval = candidate
+			break
+		}
+	}
+
+	first := start.Truncate(interval)
+	trend := make([]ErrorTrendPoint, 0, int(end.Sub(first)/interval)+1)
+	for at := first; at.Before(end); at = at.Add(interval) {
+		trend = append(trend, ErrorTrendPoint{Start: at})
+	}
+	for _, entry := range failed {
+		if entry.Timestamp.Before(first) || !entry.Timestamp.Before(end) {
+			continue
+		}
+		point := &trend[int(entry.Timestamp.Sub(first)/interval)]
+		if entry.StatusCode >= 500 {
+			point.ServerErrors++
+		} else {
+			point.ClientErrors++
+		}
+	}
+	return interval, trend
+}
+
+// topIPs returns the n most common IPs with their share of total
+func topIPs(counts map[string]int64, total int64, n int) []IPSummary {
+	var ips []IPSummary
+	for ip, count := range counts {
+		ips = append(ips, IPSummary{
+			IP:         ip,
+			Count:      count,
+			Percentage: float64(count) / float64(total) * 100,
+		})
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		if ips[i].Count != ips[j].Count {
+			return ips[i].Count > ips[j].Count
+		}
+		return ips[i].IP < ips[j].IP
+	})
+	if len(ips) > n {
+		ips = ips[:n]
+	}
+	return ips
+}
+
+// errorSummary analyzes a report's errors over its filtered period, with
+// the rollups' totals when the report has them and drill-down links
+func (r *Reporter) errorSummary(data *ReportData) *ErrorSummary {
+	var start, end time.Time
+	if data.Filters != nil {
+		if data.Filters.StartTime != nil {
+			start = *data.Filters.StartTime
+		}
+		if data.Filters.EndTime != nil {
+			end = *data.Filters.EndTime
+		}
+	}
+	summary := AnalyzeErrors(data.LogEntries, start, end)
+	if data.Traffic != nil && data.Traffic.Requests > 0 {
+		summary.Requests = data.Traffic.Requests
+		summary.ErrorRate = float64(data.Traffic.Errors) / float64(data.Traffic.Requests) * 100
+	}
+
+	d := r.newDrillDown(data)
+	for i := range summary.TopPaths {
+		summary.TopPaths[i].Link = d.pathLink(summary.TopPaths[i].Path)
+	}
+	for i := range summary.TopIPs {
+		if ip := summary.TopIPs[i].IP; ip != "" {
+			summary.TopIPs[i].Link = d.link("source_ip", ip)
+		}
+	}
+	for i := range summary.Trend {
+		point := &summary.Trend[i]
+		if point.Errors() > 0 {
+			point.Link = d.link("start_time", formatLinkTime(point.Start),
+				"end_time", formatLinkTime(point.Start.Add(summary.TrendInterval)))
+		}
+	}
+	for i := range summary.Pairs {
+		pair := &summary.Pairs[i]
+		if pair.Path != "" {
+			pair.Link = d.link("path", pair.Path, "exact_path", "true", "status_code", strconv.Itoa(pair.StatusCode))
+		}
+	}
+	return summary
+}
+
+// GenerateErrorReport generates an HTML report of a report's 4xx and 5xx
+// responses
+func (r *Reporter) GenerateErrorReport(data *ReportData, reportName string) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_errors_%s.html", reportName, timestamp)
+
+	return r.renderCustom(data.Template, "errors.html", filename, &ErrorReportData{
+		Title:       data.Title,
+		GeneratedAt: data.GeneratedAt,
+		TimeRange:   data.TimeRange,
+		Summary:     r.errorSummary(data),
+		Truncated:   data.Truncated,
+	})
+}
+
+// GenerateErrorCSVReport generates a CSV of the most common path and
+// status code pairs of a report's errors, with sample log lines
+func (r *Reporter) GenerateErrorCSVReport(data *ReportData, reportName string) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_errors_%s.csv", reportName, timestamp)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := []string{"Path", "Status Code", "Requests", "First Seen", "Last Seen", "Sample Log Lines"}
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, pair := range AnalyzeErrors(data.LogEntries, time.Time{}, time.Time{}).Pairs {
+		record := []string{
+			pair.Path,
+			strconv.Itoa(pair.StatusCode),
+			strconv.FormatInt(pair.Count, 10),
+			pair.FirstSeen.Format("2006-01-02 15:04:05"),
+			pair.LastSeen.Format("2006-01-02 15:04:05"),
+			strings.Join(pair.Samples, "\n"),
+		}
+		if err := writer.Write(record); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save CSV file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
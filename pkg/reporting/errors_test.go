@@ -0,0 +1,131 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// errorFixture has, from 10:00, a request a minute for an hour: every
+// third one a 404 on /missing from 192.0.2.1, every tenth one a 503 on
+// /api/orders from 192.0.2.2 and the others 200s
+func errorFixture() []*models.LogEntry {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []*models.LogEntry
+	for i := 0; i < 60; i++ {
+		entry := &models.LogEntry{Timestamp: base.Add(time.Duration(i) * time.Minute), SourceIP: "192.0.2.9", Path: "/", StatusCode: 200}
+		switch {
+		case i%10 == 0:
+			entry.SourceIP, entry.Path, entry.StatusCode = "192.0.2.2", "/api/orders", 503
+		case i%3 == 0:
+			entry.SourceIP, entry.Path, entry.StatusCode = "192.0.2.1", "/missing", 404
+		}
+		entry.RawLog = fmt.Sprintf("line %d", i)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	summary := AnalyzeErrors(errorFixture(), start, start.Add(time.Hour))
+
+	// 0, 10, ... 50 are 503s; 3, 6, ... 57 but 30 are 404s
+	assert.Equal(t, int64(24), summary.Errors)
+	assert.Equal(t, int64(18), summary.ClientErrors)
+	assert.Equal(t, int64(6), summary.ServerErrors)
+
+	require.Len(t, summary.TopPaths, 2)
+	assert.Equal(t, "/missing", summary.TopPaths[0].Path)
+	assert.InDelta(t, 75.0, summary.TopPaths[0].Percentage, 1e-9)
+	require.Len(t, summary.TopIPs, 2)
+	assert.Equal(t, IPSummary{IP: "192.0.2.2", Count: 6, Percentage: 25}, summary.TopIPs[1])
+
+	assert.Equal(t, 5*time.Minute, summary.TrendInterval, "an hour in minutes would be more than 48 points")
+	assert.Equal(t, "5 minutes", summary.TrendPeriod())
+	require.Len(t, summary.Trend, 12)
+	assert.Equal(t, ErrorTrendPoint{Start: start, ClientErrors: 1, ServerErrors: 1}, summary.Trend[0])
+	var trendErrors int64
+	for _, point := range summary.Trend {
+		trendErrors += point.Errors()
+	}
+	assert.Equal(t, summary.Errors, trendErrors)
+
+	require.Len(t, summary.Pairs, 2)
+	pair := summary.Pairs[1]
+	assert.Equal(t, "/api/orders", pair.Path)
+	assert.Equal(t, 503, pair.StatusCode)
+	assert.Equal(t, int64(6), pair.Count)
+	assert.Equal(t, start, pair.FirstSeen)
+	assert.Equal(t, start.Add(50*time.Minute), pair.LastSeen)
+	assert.Equal(t, []string{"line 50", "line 40", "line 30"}, pair.Samples)
+
+	// Without a period, the trend runs from the first error to the last
+	summary = AnalyzeErrors(errorFixture(), time.Time{}, time.Time{})
+	assert.Equal(t, start, summary.Start)
+	assert.Equal(t, start.Add(57*time.Minute+time.Nanosecond), summary.End)
+
+	summary = AnalyzeErrors(nil, time.Time{}, time.Time{})
+	assert.Zero(t, summary.Errors)
+	assert.Empty(t, summary.Trend)
+	assert.Empty(t, summary.Pairs)
+}
+
+func TestErrorReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+		LogEntries:  errorFixture(),
+		Filters:     &models.LogFilter{StartTime: &start, EndTime: &end, MinStatusCode: 400},
+		Traffic:     &TrafficTotals{Requests: 200, Errors: 24},
+	}
+
+	location, err := reporter.GenerateErrorReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_errors_2024-03-01_11-00-00.html", filepath.Base(location))
+	html, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "12.00%")
+	assert.Contains(t, string(html), "Errors per 5 minutes.")
+	assert.Contains(t, string(html), "<code>line 57</code>")
+	assert.Contains(t, string(html), "min_status_code=400", "links only list errors")
+	assert.Contains(t, string(html), "status_code=503")
+	assert.NotContains(t, string(html), "were analyzed")
+
+	data.Truncated = true
+	location, err = reporter.GenerateErrorReport(data, "truncated")
+	require.NoError(t, err)
+	html, err = os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Only the most recent 24 errors of the period were analyzed.")
+	data.Truncated = false
+
+	location, err = reporter.GenerateErrorCSVReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_errors_2024-03-01_11-00-00.csv", filepath.Base(location))
+	content, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Path", "Status Code", "Requests", "First Seen", "Last Seen", "Sample Log Lines"},
+		{"/missing", "404", "18", "2024-03-01 10:03:00", "2024-03-01 10:57:00", "line 57\nline 54\nline 51"},
+		{"/api/orders", "503", "6", "2024-03-01 10:00:00", "2024-03-01 10:50:00", "line 50\nline 40\nline 30"},
+	}, records)
+}
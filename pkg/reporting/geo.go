@@ -0,0 +1,165 @@
+package reporting
+
+import (
+	"sort"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/geoip"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// maxGeoRows bounds how many countries and cities each geo table lists
+const maxGeoRows = 15
+
+// Locator finds where client IPs are. geoip.Reader implements it.
+type Locator interface {
+	Lookup(ip string) (geoip.Location, bool)
+}
+
+// SetLocator breaks reports down by the country and city of client IPs
+// and adds a Country column to CSV exports. Reports have no geo section
+// until it is set.
+func (r *Reporter) SetLocator(locator Locator) {
+	r.locator = locator
+}
+
+// CountrySummary counts the requests from a country, with their share of
+// all requests and how many of them failed with a 4xx or 5xx
+type CountrySummary struct {
+	// Country is the ISO code, such as "DE", and Name its English name
+	Country    string
+	Name       string
+	Count      int64
+	Percentage float64
+	Errors     int64
+	ErrorRate  float64
+}
+
+// CitySummary counts the requests from a city
+type CitySummary struct {
+	City       string
+	Country    string
+	Count      int64
+	Percentage float64
+}
+
+// GeoBreakdown summarizes where a report's requests came from
+type GeoBreakdown struct {
+	// Countries are the busiest countries
+	Countries []CountrySummary
+	// Cities are the busiest cities; City databases only
+	Cities []CitySummary
+	// ErrorRates are the countries with failed requests, highest error
+	// rate first
+	ErrorRates []CountrySummary
+	// UnknownRequests came from IPs the database has no location for,
+	// such as private addresses
+	UnknownRequests int64
+	UnknownShare    float64
+}
+
+// AnalyzeGeo breaks the entries' requests down by the location of their
+// client IPs. It returns nil without a locator.
+func AnalyzeGeo(entries []*models.LogEntry, locator Locator) *GeoBreakdown {
+	if locator == nil {
+		return nil
+	}
+
+	type cityKey struct{ city, country string }
+	countries := make(map[string]*CountrySummary)
+	cities := make(map[cityKey]int64)
+	geo := &GeoBreakdown{}
+	lookup := cachedLookup(locator)
+	for _, entry := range entries {
+		location, ok := lookup(entry.SourceIP)
+		if !ok {
+			geo.UnknownRequests++
+			continue
+		}
+		country, seen := countries[location.Country]
+		if !seen {
+			country = &CountrySummary{Country: location.Country, Name: location.CountryName}
+			countries[location.Country] = country
+		}
+		country.Count++
+		if entry.StatusCode >= 400 {
+			country.Errors++
+		}
+		if location.City != "" {
+			cities[cityKey{location.City, location.Country}]++
+		}
+	}
+
+	total := float64(len(entries))
+	if total > 0 {
+		geo.UnknownShare = float64(geo.UnknownRequests) / total * 100
+	}
+	for _, country := range countries {
+		country.Percentage = float64(country.Count) / total * 100
+		country.ErrorRate = float64(country.Errors) / float64(country.Count) * 100
+		geo.Countries = append(geo.Countries, *country)
+		if country.Errors > 0 {
+			geo.ErrorRates = append(geo.ErrorRates, *country)
+		}
+	}
+	sort.Slice(geo.Countries, func(i, j int) bool {
+		if geo.Countries[i].Count != geo.Countries[j].Count {
+			return geo.Countries[i].Count > geo.Countries[j].Count
+		}
+		return geo.Countries[i].Country < geo.Countries[j].Country
+	})
+	sort.Slice(geo.ErrorRates, func(i, j int) bool {
+		if geo.ErrorRates[i].ErrorRate != geo.ErrorRates[j].ErrorRate {
+			return geo.ErrorRates[i].ErrorRate > geo.ErrorRates[j].ErrorRate
+		}
+		if geo.ErrorRates[i].Count != geo.ErrorRates[j].Count {
+			return geo.ErrorRates[i].Count > geo.ErrorRates[j].Count
+		}
+		return geo.ErrorRates[i].Country < geo.ErrorRates[j].Country
+	})
+
+	for key, count := range cities {
+		geo.Cities = append(geo.Cities, CitySummary{
+			City:       key.city,
+			Country:    key.country,
+			Count:      count,
+			Percentage: float64(count) / total * 100,
+		})
+	}
+	sort.Slice(geo.Cities, func(i, j int) bool {
+		if geo.Cities[i].Count != geo.Cities[j].Count {
+			return geo.Cities[i].Count > geo.Cities[j].Count
+		}
+		if geo.Cities[i].City != geo.Cities[j].City {
+			return geo.Cities[i].City < geo.Cities[j].City
+		}
+		return geo.Cities[i].Country < geo.Cities[j].Country
+	})
+
+	if len(geo.Countries) > maxGeoRows {
+		geo.Countries = geo.Countries[:maxGeoRows]
+	}
+	if len(geo.ErrorRates) > maxGeoRows {
+		geo.ErrorRates = geo.ErrorRates[:maxGeoRows]
+	}
+	if len(geo.Cities) > maxGeoRows {
+		geo.Cities = geo.Cities[:maxGeoRows]
+	}
+	return geo
+}
+
+// cachedLookup returns a lookup that locates each distinct IP once
+func cachedLookup(locator Locator) func(ip string) (geoip.Location, bool) {
+	type result struct {
+		location geoip.Location
+		ok       bool
+	}
+	cache := make(map[string]result)
+	return func(ip string) (geoip.Location, bool) {
+		cached, seen := cache[ip]
+		if !seen {
+			cached.location, cached.ok = locator.Lookup(ip)
+			cache[ip] = cached
+		}
+		return cached.location, cached.ok
+	}
+}
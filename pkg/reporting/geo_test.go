@@ -0,0 +1,124 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/geoip"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// fakeLocator locates IPs from a map and counts its lookups
+type fakeLocator struct {
+	locations map[string]geoip.Location
+	lookups   int
+}
+
+func (l *fakeLocator) Lookup(ip string) (geoip.Location, bool) {
+	l.lookups++
+	location, ok := l.locations[ip]
+	return location, ok
+}
+
+func geoFixture() (*fakeLocator, []*models.LogEntry) {
+	locator := &fakeLocator{locations: map[string]geoip.Location{
+		"81.2.69.142":  {Country: "GB", CountryName: "United Kingdom", City: "London"},
+		"81.2.70.1":    {Country: "GB", CountryName: "United Kingdom", City: "Manchester"},
+		"89.160.20.12": {Country: "SE", CountryName: "Sweden", City: "Linköping"},
+		"1.1.1.1":      {Country: "AU", CountryName: "Australia"},
+	}}
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []*models.LogEntry
+	add := func(ip string, status, n int) {
+		for i := 0; i < n; i++ {
+			entries = append(entries, &models.LogEntry{Timestamp: base.Add(time.Duration(len(entries)) * time.Second), SourceIP: ip, Path: "/", StatusCode: status})
+		}
+	}
+	add("81.2.69.142", 200, 5)
+	add("81.2.69.142", 500, 1)
+	add("81.2.70.1", 200, 2)
+	add("89.160.20.12", 404, 2)
+	add("89.160.20.12", 200, 2)
+	add("1.1.1.1", 200, 1)
+	add("10.0.0.1", 200, 3)
+	return locator, entries
+}
+
+func TestAnalyzeGeo(t *testing.T) {
+	locator, entries := geoFixture()
+	geo := AnalyzeGeo(entries, locator)
+	require.NotNil(t, geo)
+	assert.Equal(t, 5, locator.lookups, "each IP is looked up once")
+
+	assert.Equal(t, int64(3), geo.UnknownRequests)
+	assert.InDelta(t, 18.75, geo.UnknownShare, 1e-9)
+
+	require.Len(t, geo.Countries, 3)
+	assert.Equal(t, CountrySummary{Country: "GB", Name: "United Kingdom", Count: 8, Percentage: 50, Errors: 1, ErrorRate: 12.5}, geo.Countries[0])
+	assert.Equal(t, "SE", geo.Countries[1].Country)
+	assert.Equal(t, "AU", geo.Countries[2].Country)
+
+	require.Len(t, geo.Cities, 3, "countries without a city are left out")
+	assert.Equal(t, CitySummary{City: "London", Country: "GB", Count: 6, Percentage: 37.5}, geo.Cities[0])
+	assert.Equal(t, "Linköping", geo.Cities[1].City)
+
+	require.Len(t, geo.ErrorRates, 2, "countries without errors are left out")
+	assert.Equal(t, "SE", geo.ErrorRates[0].Country)
+	assert.Equal(t, 50.0, geo.ErrorRates[0].ErrorRate)
+	assert.Equal(t, "GB", geo.ErrorRates[1].Country)
+
+	assert.Nil(t, AnalyzeGeo(entries, nil))
+}
+
+func TestReportGeo(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+	locator, entries := geoFixture()
+	data := &ReportData{Title: "Daily", GeneratedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LogEntries: entries}
+
+	// Without a database there is no geo section and the country is empty
+	summary, err := reporter.GenerateSummaryReport(data, "daily")
+	require.NoError(t, err)
+	html, err := os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "Geography")
+	location, err := reporter.GenerateCSVReport(data, "daily")
+	require.NoError(t, err)
+	records := readCSV(t, filepath.Join(dir, filepath.Base(location)))
+	assert.Equal(t, "Country", records[0][3])
+	assert.Equal(t, "", records[1][3])
+
+	reporter.SetLocator(locator)
+	summary, err = reporter.GenerateSummaryReport(data, "daily")
+	require.NoError(t, err)
+	html, err = os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Geography")
+	assert.Contains(t, string(html), "United Kingdom (GB)")
+	assert.Contains(t, string(html), "Linköping")
+	assert.Contains(t, string(html), "3 requests (18.8%) came from IPs without a known location")
+
+	location, err = reporter.GenerateCSVReport(data, "daily")
+	require.NoError(t, err)
+	records = readCSV(t, filepath.Join(dir, filepath.Base(location)))
+	assert.Equal(t, []string{"81.2.69.142", "GB"}, records[1][2:4])
+	assert.Equal(t, []string{"10.0.0.1", ""}, records[len(records)-1][2:4])
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	return records
+}
@@ -0,0 +1,163 @@
+package reporting
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// JSONReport is a report as a single JSON document, for tools that
+// consume reports rather than people reading them
+type JSONReport struct {
+	Title       string             `json:"title"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	TimeRange   string             `json:"time_range,omitempty"`
+	Filters     *models.LogFilter  `json:"filters,omitempty"`
+	Summary     JSONSummary        `json:"summary"`
+	Entries     []*models.LogEntry `json:"entries"`
+	// Truncated is set when the period had more entries than Entries
+	Truncated bool `json:"truncated"`
+}
+
+// JSONSummary is the summary of a JSON report. Response times are in
+// the entries' unit, and rates and shares are percentages.
+type JSONSummary struct {
+	TotalRequests        int64                `json:"total_requests"`
+	UniqueIPs            int64                `json:"unique_ips"`
+	AvgResponseTime      float64              `json:"avg_response_time"`
+	P95ResponseTime      float64              `json:"p95_response_time"`
+	ErrorRate            float64              `json:"error_rate"`
+	Availability         float64              `json:"availability"`
+	AdjustedAvailability float64              `json:"adjusted_availability"`
+	MaintenanceRequests  int64                `json:"maintenance_requests"`
+	StatusCodes          map[string]int64     `json:"status_codes"`
+	TopPaths             []JSONPathCount      `json:"top_paths"`
+	TopIPs               []JSONIPCount        `json:"top_ips"`
+	HourlyTraffic        []JSONHourCount      `json:"hourly_traffic"`
+	Latency              LatencyPercentiles   `json:"latency"`
+	PathLatency          []LatencyPercentiles `json:"path_latency"`
+}
+
+// JSONPathCount counts the requests for a path
+type JSONPathCount struct {
+	Path       string  `json:"path"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// JSONIPCount counts the requests from a client IP
+type JSONIPCount struct {
+	IP         string  `json:"ip"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// JSONHourCount counts the requests logged in an hour of the day
+type JSONHourCount struct {
+	Hour  int   `json:"hour"`
+	Count int64 `json:"count"`
+}
+
+// BuildJSONReport summarizes data as a JSON report
+func (r *Reporter) BuildJSONReport(data *ReportData) *JSONReport {
+	r.prepareSummary(data)
+
+	summary := data.Summary
+	report := &JSONReport{
+		Title:       data.Title,
+		GeneratedAt: data.GeneratedAt,
+		TimeRange:   data.TimeRange,
+		Filters:     data.Filters,
+		Truncated:   data.Truncated,
+		Summary: JSONSummary{
+			TotalRequests:        summary.TotalRequests,
+			UniqueIPs:            summary.UniqueIPs,
+			AvgResponseTime:      summary.AvgResponseTime,
+			P95ResponseTime:      summary.P95ResponseTime,
+			ErrorRate:            summary.ErrorRate,
+			Availability:         summary.Availability,
+			AdjustedAvailability: summary.AdjustedAvailability,
+			MaintenanceRequests:  summary.MaintenanceRequests,
+			StatusCodes:          summary.StatusCodeBreakdown,
+			TopPaths:             []JSONPathCount{},
+			TopIPs:               []JSONIPCount{},
+			HourlyTraffic:        []JSONHourCount{},
+			Latency:              summary.Latency,
+			PathLatency:          summary.PathLatency,
+		},
+		Entries: data.LogEntries,
+	}
+	for _, path := range summary.TopPaths {
+		report.Summary.TopPaths = append(report.Summary.TopPaths, JSONPathCount{path.Path, path.Count, path.Percentage})
+	}
+	for _, ip := range summary.TopIPs {
+		report.Summary.TopIPs = append(report.Summary.TopIPs, JSONIPCount{ip.IP, ip.Count, ip.Percentage})
+	}
+	for _, hour := range summary.HourlyTraffic {
+		report.Summary.HourlyTraffic = append(report.Summary.HourlyTraffic, JSONHourCount{hour.Hour, hour.Count})
+	}
+	if report.Summary.PathLatency == nil {
+		report.Summary.PathLatency = []LatencyPercentiles{}
+	}
+	if report.Entries == nil {
+		report.Entries = []*models.LogEntry{}
+	}
+	return report
+}
+
+// GenerateJSONReport generates the summary and entries of a report as a
+// single JSON document
+func (r *Reporter) GenerateJSONReport(data *ReportData, reportName string) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_%s.json", reportName, timestamp)
+	return r.exportToJSON(r.BuildJSONReport(data), filename)
+}
+
+// GenerateNDJSONReport generates the entries of a report as NDJSON, one
+// JSON entry per line
+func (r *Reporter) GenerateNDJSONReport(data *ReportData, reportName string) (string, error) {
+	return r.GenerateNDJSONStream(data, reportName, SliceCursor(data.LogEntries), false)
+}
+
+// GenerateNDJSONStream generates the NDJSON export GenerateNDJSONReport
+// does, of the entries cursor yields instead of data.LogEntries, in the
+// way GenerateCSVStream does. With compress the file is named .ndjson.gz.
+func (r *Reporter) GenerateNDJSONStream(data *ReportData, reportName string, cursor EntryCursor, compress bool) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_%s.ndjson", reportName, timestamp)
+	if compress {
+		filename += ".gz"
+	}
+
+	err := r.putStream(filename, func(w io.Writer) error {
+		var gz *gzip.Writer
+		if compress {
+			gz = gzip.NewWriter(w)
+			w = gz
+		}
+		// Encode ends each entry with a newline
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		err := cursor(func(entry *models.LogEntry) error {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("failed to write NDJSON entry: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if gz != nil {
+			return gz.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return r.store.Location(filename), nil
+}
@@ -0,0 +1,150 @@
+package reporting
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestGenerateJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: base,
+		Filters:     &models.LogFilter{LogType: "nginx"},
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, SourceIP: "192.0.2.1", Method: "GET", Path: "/", StatusCode: 200, ProcessingTime: 0.1},
+			{Timestamp: base.Add(time.Hour), SourceIP: "192.0.2.1", Method: "GET", Path: "/login", StatusCode: 500, ProcessingTime: 0.3},
+			{Timestamp: base.Add(time.Hour), SourceIP: "192.0.2.2", Method: "GET", Path: "/<script>", StatusCode: 404},
+		},
+	}
+
+	location, err := reporter.GenerateJSONReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.json", filepath.Base(location))
+	content, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"/<script>"`, "HTML is not escaped")
+
+	var report struct {
+		Title   string `json:"title"`
+		Filters struct {
+			LogType string `json:"log_type"`
+		} `json:"filters"`
+		Summary struct {
+			TotalRequests int64            `json:"total_requests"`
+			UniqueIPs     int64            `json:"unique_ips"`
+			StatusCodes   map[string]int64 `json:"status_codes"`
+			TopIPs        []JSONIPCount    `json:"top_ips"`
+			HourlyTraffic []JSONHourCount  `json:"hourly_traffic"`
+			Latency       struct {
+				Requests int64 `json:"requests"`
+			} `json:"latency"`
+		} `json:"summary"`
+		Entries []models.LogEntry `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, "Daily", report.Title)
+	assert.Equal(t, "nginx", report.Filters.LogType)
+	assert.Equal(t, int64(3), report.Summary.TotalRequests)
+	assert.Equal(t, int64(2), report.Summary.UniqueIPs)
+	assert.Equal(t, map[string]int64{"200": 1, "404": 1, "500": 1}, report.Summary.StatusCodes)
+	require.NotEmpty(t, report.Summary.TopIPs)
+	assert.Equal(t, "192.0.2.1", report.Summary.TopIPs[0].IP)
+	assert.Equal(t, int64(2), report.Summary.TopIPs[0].Count)
+	assert.InDelta(t, 66.67, report.Summary.TopIPs[0].Percentage, 0.01)
+	assert.Equal(t, JSONHourCount{Hour: 11, Count: 2}, report.Summary.HourlyTraffic[11])
+	assert.Equal(t, int64(2), report.Summary.Latency.Requests)
+	require.Len(t, report.Entries, 3)
+	assert.Equal(t, "/login", report.Entries[1].Path)
+
+	// A report without entries still has lists
+	location, err = reporter.GenerateJSONReport(&ReportData{Title: "Empty", GeneratedAt: base}, "empty")
+	require.NoError(t, err)
+	content, err = os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"entries": []`)
+	assert.Contains(t, string(content), `"top_paths": []`)
+	assert.Contains(t, string(content), `"truncated": false`)
+	assert.NotContains(t, string(content), `"filters"`)
+
+	// ExportToFile exports report data as its JSON report
+	location, err = reporter.ExportToFile(data, "JSON", "export.json")
+	require.NoError(t, err)
+	assert.Equal(t, "export.json", filepath.Base(location))
+	var exported JSONReport
+	require.NoError(t, json.Unmarshal(mustReadFile(t, location), &exported))
+	assert.Equal(t, "Daily", exported.Title)
+	assert.Equal(t, int64(3), exported.Summary.TotalRequests)
+	assert.Len(t, exported.Entries, 3)
+	assert.False(t, exported.Truncated)
+	data.Truncated = true
+	assert.True(t, reporter.BuildJSONReport(data).Truncated, "reports that read part of their period say so")
+	location, err = reporter.ExportToFile(map[string]int{"requests": 3}, "json", "counts.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"requests": 3}`, string(mustReadFile(t, location)))
+}
+
+func TestGenerateNDJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+	data := &ReportData{Title: "Daily", GeneratedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+
+	entries := func(content []byte) []models.LogEntry {
+		var entries []models.LogEntry
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			var entry models.LogEntry
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), scanner.Text())
+			entries = append(entries, entry)
+		}
+		require.NoError(t, scanner.Err())
+		return entries
+	}
+
+	data.LogEntries = []*models.LogEntry{
+		{SourceIP: "192.0.2.1", Path: "/a", StatusCode: 200, RawLog: "GET /a\nHTTP/1.1"},
+		{SourceIP: "192.0.2.2", Path: "/b", StatusCode: 404},
+	}
+	location, err := reporter.GenerateNDJSONReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.ndjson", filepath.Base(location))
+	written := entries(mustReadFile(t, location))
+	require.Len(t, written, 2, "newlines in fields stay escaped")
+	assert.Equal(t, "GET /a\nHTTP/1.1", written[0].RawLog)
+	assert.Equal(t, "/b", written[1].Path)
+
+	location, err = reporter.GenerateNDJSONStream(data, "daily", countingCursor(1000), true)
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.ndjson.gz", filepath.Base(location))
+	gz, err := gzip.NewReader(bytes.NewReader(mustReadFile(t, location)))
+	require.NoError(t, err)
+	var content bytes.Buffer
+	_, err = content.ReadFrom(gz)
+	require.NoError(t, err)
+	written = entries(content.Bytes())
+	require.Len(t, written, 1000)
+	assert.Equal(t, "/items/999", written[999].Path)
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return content
+}
@@ -0,0 +1,92 @@
+package reporting
+
+import (
+	"sort"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/latency"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// maxLatencyPaths bounds how many paths a report lists percentiles of
+const maxLatencyPaths = 10
+
+// LatencyPercentiles are the percentiles of the response times of a set
+// of requests, in the same unit as AvgResponseTime. Path is the
+// normalized path they were made to, or empty for all of them.
+type LatencyPercentiles struct {
+	Path string `json:"path,omitempty"`
+	// Requests counts the requests with a response time
+	Requests int64   `json:"requests"`
+	P50      float64 `json:"p50"`
+	P90      float64 `json:"p90"`
+	P95      float64 `json:"p95"`
+	P99      float64 `json:"p99"`
+}
+
+func percentilesOf(path string, histogram *latency.Histogram) LatencyPercentiles {
+	// The histogram counts milliseconds
+	return LatencyPercentiles{
+		Path:     path,
+		Requests: histogram.Count(),
+		P50:      histogram.Percentile(50) / 1000,
+		P90:      histogram.Percentile(90) / 1000,
+		P95:      histogram.Percentile(95) / 1000,
+		P99:      histogram.Percentile(99) / 1000,
+	}
+}
+
+// AnalyzeLatency returns the response time percentiles of the entries
+// overall and of the n normalized paths with the most timed requests,
+// busiest first. Entries without a response time are left out.
+func AnalyzeLatency(entries []*models.LogEntry, n int) (LatencyPercentiles, []LatencyPercentiles) {
+	var overall latency.Histogram
+	paths := make(map[string]*latency.Histogram)
+	for _, entry := range entries {
+		if entry.ProcessingTime <= 0 {
+			continue
+		}
+		ms := entry.ProcessingTime * 1000
+		overall.Add(ms)
+
+		path := latency.NormalizePath(entry.Path)
+		histogram, ok := paths[path]
+		if !ok {
+			histogram = &latency.Histogram{}
+			paths[path] = histogram
+		}
+		histogram.Add(ms)
+	}
+
+	byPath := make([]LatencyPercentiles, 0, len(paths))
+	for path, histogram := range paths {
+		byPath = append(byPath, LatencyPercentiles{Path: path, Requests: histogram.Count()})
+	}
+	sort.Slice(byPath, func(i, j int) bool {
+		if byPath[i].Requests != byPath[j].Requests {
+			return byPath[i].Requests > byPath[j].Requests
+		}
+		return byPath[i].Path < byPath[j].Path
+	})
+	if len(byPath) > n {
+		byPath = byPath[:n]
+	}
+	// Only the paths kept need their percentiles worked out
+	for i := range byPath {
+		byPath[i] = percentilesOf(byPath[i].Path, paths[byPath[i].Path])
+	}
+	return percentilesOf("", &overall), byPath
+}
+
+// prepareLatencyPercentiles works out the response time percentiles
+// overall and of the busiest paths
+func (r *Reporter) prepareLatencyPercentiles(data *ReportData) {
+	data.Summary.Latency, data.Summary.PathLatency = AnalyzeLatency(data.LogEntries, maxLatencyPaths)
+}
+
+// prepareLatencyBudgets checks the report's entries against the latency
+// budgets of their paths
+func (r *Reporter) prepareLatencyBudgets(data *ReportData) {
+	results := latency.Check(data.LatencyBudgets, data.LogEntries)
+	data.Summary.LatencyBudgets = results
+	data.Summary.LatencyBudgetViolations = len(latency.Violations(results))
+}
@@ -0,0 +1,133 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportLatencyBudgets(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: base,
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, Path: "/api/search?q=a", StatusCode: 200, ProcessingTime: 0.9},
+			{Timestamp: base, Path: "/api/search?q=b", StatusCode: 200, ProcessingTime: 0.2},
+			{Timestamp: base, Path: "/api/users/3", StatusCode: 200, ProcessingTime: 0.01},
+		},
+		LatencyBudgets: []*models.LatencyBudget{
+			{ID: 1, Path: "/api/users/{id}", Percentile: 95, ThresholdMs: 100, Team: "accounts"},
+			{ID: 2, Path: "/api/search", Percentile: 95, ThresholdMs: 500, Team: "search"},
+		},
+	}
+
+	report, err := reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	require.Len(t, data.Summary.LatencyBudgets, 2)
+	assert.Equal(t, 1, data.Summary.LatencyBudgetViolations)
+	assert.Equal(t, "/api/search", data.Summary.LatencyBudgets[0].Budget.Path, "violations first")
+	assert.InDelta(t, 900, data.Summary.LatencyBudgets[0].Observed, 1e-9)
+
+	html, err := os.ReadFile(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Latency Budgets")
+	assert.Contains(t, string(html), "1 of 2 budgeted paths exceeded")
+	assert.Contains(t, string(html), "Over by 80.0%")
+
+	summary, err := reporter.GenerateSummaryReport(&ReportData{Title: "Empty", GeneratedAt: base}, "empty")
+	require.NoError(t, err)
+	html, err = os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "Latency Budgets", "left out without budgets")
+}
+
+func TestAnalyzeLatency(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []*models.LogEntry
+	// 100 searches taking 10ms to 1s, and a few user lookups
+	for i := 1; i <= 100; i++ {
+		entries = append(entries, &models.LogEntry{Timestamp: base, Path: fmt.Sprintf("/api/search?q=%d", i), ProcessingTime: float64(i) / 100})
+	}
+	for i := 1; i <= 3; i++ {
+		entries = append(entries, &models.LogEntry{Timestamp: base, Path: fmt.Sprintf("/api/users/%d", i), ProcessingTime: 0.005})
+	}
+	entries = append(entries,
+		&models.LogEntry{Timestamp: base, Path: "/health", ProcessingTime: 0.001},
+		&models.LogEntry{Timestamp: base, Path: "/untimed"},
+	)
+
+	overall, paths := AnalyzeLatency(entries, 2)
+	assert.Equal(t, int64(104), overall.Requests, "untimed requests are left out")
+	assert.InEpsilon(t, 0.48, overall.P50, 0.01)
+	assert.InEpsilon(t, 0.99, overall.P99, 0.01)
+
+	require.Len(t, paths, 2)
+	assert.Equal(t, "/api/search", paths[0].Path)
+	assert.Equal(t, int64(100), paths[0].Requests)
+	assert.InEpsilon(t, 0.5, paths[0].P50, 0.01)
+	assert.InEpsilon(t, 0.9, paths[0].P90, 0.01)
+	assert.InEpsilon(t, 0.95, paths[0].P95, 0.01)
+	assert.InEpsilon(t, 0.99, paths[0].P99, 0.01)
+	assert.Equal(t, "/api/users/{id}", paths[1].Path)
+	assert.InEpsilon(t, 0.005, paths[1].P99, 0.01)
+
+	overall, paths = AnalyzeLatency(nil, 10)
+	assert.Zero(t, overall.P95)
+	assert.Empty(t, paths)
+}
+
+func TestReportLatencyPercentiles(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: base,
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, Path: "/api/search", StatusCode: 200, ProcessingTime: 0.25},
+			{Timestamp: base, Path: "/", StatusCode: 200, ProcessingTime: 0.5},
+		},
+	}
+
+	report, err := reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), data.Summary.Latency.Requests)
+	require.Len(t, data.Summary.PathLatency, 2)
+	html, err := os.ReadFile(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Response Time Percentiles")
+	assert.Contains(t, string(html), "0.250s")
+
+	location, err := reporter.GenerateLatencyCSVReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_latency_2024-03-01_10-00-00.csv", filepath.Base(location))
+	content, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"Path", "Requests", "P50", "P90", "P95", "P99"}, records[0])
+	assert.Equal(t, []string{"", "2", "0.250", "0.500", "0.500", "0.500"}, records[1])
+	assert.Equal(t, "/", records[2][0])
+
+	summary, err := reporter.GenerateSummaryReport(&ReportData{Title: "Empty", GeneratedAt: base}, "empty")
+	require.NoError(t, err)
+	html, err = os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "Response Time Percentiles", "left out without response times")
+}
@@ -0,0 +1,213 @@
+package reporting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locale is how HTML and PDF reports show dates and numbers. Labels stay
+// in English.
+type locale struct {
+	// date, clock and long are time layouts; "January" in long is
+	// replaced by the locale's month name
+	date  string
+	clock string
+	// clockSeconds is clock with seconds
+	clockSeconds string
+	long         string
+	months       []string
+	// decimal separates the fraction, group thousands; an empty group
+	// leaves digits ungrouped
+	decimal string
+	group   string
+}
+
+// defaultLocale keeps the ISO dates and plain numbers reports have always
+// shown
+var defaultLocale = &locale{
+	date: "2006-01-02", clock: "15:04", clockSeconds: "15:04:05",
+	long:    "January 2, 2006 at 3:04 PM",
+	decimal: ".",
+}
+
+// locales are the locales reports can be shown in, by BCP 47 tag. Groups
+// use a no-break space where the locale uses a space, so numbers do not
+// wrap.
+var locales = map[string]*locale{
+	"en-US": {
+		date: "01/02/2006", clock: "3:04 PM", clockSeconds: "3:04:05 PM",
+		long:    "January 2, 2006 at 3:04 PM",
+		decimal: ".", group: ",",
+	},
+	"en-GB": {
+		date: "02/01/2006", clock: "15:04", clockSeconds: "15:04:05",
+		long:    "2 January 2006 at 15:04",
+		decimal: ".", group: ",",
+	},
+	"de-DE": {
+		date: "02.01.2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2. January 2006, 15:04",
+		months: []string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		decimal: ",", group: ".",
+	},
+	"es-ES": {
+		date: "02/01/2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 de January de 2006, 15:04",
+		months: []string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		decimal: ",", group: ".",
+	},
+	"fr-FR": {
+		date: "02/01/2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 January 2006, 15:04",
+		months: []string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		decimal: ",", group: "\u00a0",
+	},
+	"it-IT": {
+		date: "02/01/2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 January 2006, 15:04",
+		months: []string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		decimal: ",", group: ".",
+	},
+	"nl-NL": {
+		date: "02-01-2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 January 2006, 15:04",
+		months: []string{"januari", "februari", "maart", "april", "mei", "juni",
+			"juli", "augustus", "september", "oktober", "november", "december"},
+		decimal: ",", group: ".",
+	},
+	"pt-BR": {
+		date: "02/01/2006", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 de January de 2006, 15:04",
+		months: []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		decimal: ",", group: ".",
+	},
+	"sv-SE": {
+		date: "2006-01-02", clock: "15:04", clockSeconds: "15:04:05",
+		long: "2 January 2006, 15:04",
+		months: []string{"januari", "februari", "mars", "april", "maj", "juni",
+			"juli", "augusti", "september", "oktober", "november", "december"},
+		decimal: ",", group: "\u00a0",
+	},
+}
+
+// Locales lists the locales reports can be shown in
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLocale shows dates and numbers in HTML and PDF reports the way a
+// locale writes them. An empty name keeps ISO dates and ungrouped numbers.
+func (r *Reporter) SetLocale(name string) error {
+	if name == "" {
+		r.locale = defaultLocale
+		return nil
+	}
+	l, ok := locales[name]
+	if !ok {
+		return fmt.Errorf("unsupported locale %q, must be one of: %s", name, strings.Join(Locales(), ", "))
+	}
+	r.locale = l
+	return nil
+}
+
+// Date formats the day of t
+func (l *locale) Date(t time.Time) string {
+	return t.Format(l.date)
+}
+
+// DateTime formats t to the minute
+func (l *locale) DateTime(t time.Time) string {
+	return t.Format(l.date + " " + l.clock)
+}
+
+// Timestamp formats t to the second
+func (l *locale) Timestamp(t time.Time) string {
+	return t.Format(l.date + " " + l.clockSeconds)
+}
+
+// Time formats the time of day of t
+func (l *locale) Time(t time.Time) string {
+	return t.Format(l.clock)
+}
+
+// Long formats t with the month spelled out, as reports say when they
+// were generated
+func (l *locale) Long(t time.Time) string {
+	if l.months == nil {
+		return t.Format(l.long)
+	}
+	parts := strings.Split(l.long, "January")
+	for i, part := range parts {
+		parts[i] = t.Format(part)
+	}
+	return strings.Join(parts, l.months[t.Month()-1])
+}
+
+// Number formats a count, or a float rounded to a whole number
+func (l *locale) Number(v interface{}) string {
+	switch n := v.(type) {
+	case int:
+		return l.Localize(strconv.Itoa(n))
+	case int32:
+		return l.Localize(strconv.FormatInt(int64(n), 10))
+	case int64:
+		return l.Localize(strconv.FormatInt(n, 10))
+	case uint64:
+		return l.Localize(strconv.FormatUint(n, 10))
+	case float64:
+		return l.Decimal(0, n)
+	}
+	return fmt.Sprint(v)
+}
+
+// Decimal formats v with places digits after the separator
+func (l *locale) Decimal(places int, v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', places, 64)
+	}
+	return l.Localize(strconv.FormatFloat(v, 'f', places, 64))
+}
+
+// Localize swaps the separators of a number formatted the Go way, such as
+// by printf, for the locale's and groups its whole part
+func (l *locale) Localize(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	whole, rest := s, ""
+	if i := strings.IndexAny(s, ".eE"); i >= 0 {
+		whole, rest = s[:i], s[i:]
+	}
+	if strings.Trim(whole, "0123456789") != "" {
+		return sign + s
+	}
+	if l.group != "" && len(whole) > 3 {
+		var b strings.Builder
+		for i, digit := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteString(l.group)
+			}
+			b.WriteRune(digit)
+		}
+		whole = b.String()
+	}
+	if strings.HasPrefix(rest, ".") {
+		rest = l.decimal + rest[1:]
+	}
+	return sign + whole + rest
+}
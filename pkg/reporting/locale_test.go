@@ -0,0 +1,80 @@
+package reporting
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestLocale(t *testing.T) {
+	at := time.Date(2024, 3, 1, 14, 5, 9, 0, time.UTC)
+	tests := []struct {
+		locale    string
+		long      string
+		timestamp string
+		decimal   string
+		number    string
+	}{
+		{"", "March 1, 2024 at 2:05 PM", "2024-03-01 14:05:09", "-1234567.89", "1234567"},
+		{"en-US", "March 1, 2024 at 2:05 PM", "03/01/2024 2:05:09 PM", "-1,234,567.89", "1,234,567"},
+		{"en-GB", "1 March 2024 at 14:05", "01/03/2024 14:05:09", "-1,234,567.89", "1,234,567"},
+		{"de-DE", "1. März 2024, 14:05", "01.03.2024 14:05:09", "-1.234.567,89", "1.234.567"},
+		{"fr-FR", "1 mars 2024, 14:05", "01/03/2024 14:05:09", "-1 234 567,89", "1 234 567"},
+		{"pt-BR", "1 de março de 2024, 14:05", "01/03/2024 14:05:09", "-1.234.567,89", "1.234.567"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			reporter := &Reporter{}
+			require.NoError(t, reporter.SetLocale(tt.locale))
+			l := reporter.locale
+			assert.Equal(t, tt.long, l.Long(at))
+			assert.Equal(t, tt.timestamp, l.Timestamp(at))
+			assert.Equal(t, tt.decimal, l.Decimal(2, -1234567.891))
+			assert.Equal(t, tt.number, l.Number(int64(1234567)))
+			assert.Equal(t, tt.number, l.Number(1234567.2))
+		})
+	}
+
+	l := locales["de-DE"]
+	assert.Equal(t, "999", l.Number(999))
+	assert.Equal(t, "1.000", l.Number(1000))
+	assert.Equal(t, "0,5", l.Decimal(1, 0.5))
+	assert.Equal(t, "+12,5", l.Localize("+12.5"))
+	assert.Equal(t, "1,235e+06", l.Localize("1.235e+06"))
+	assert.Equal(t, "NaN", l.Decimal(1, math.NaN()))
+	assert.Equal(t, "n/a", l.Number("n/a"))
+
+	assert.ErrorContains(t, (&Reporter{}).SetLocale("xx-XX"), `unsupported locale "xx-XX", must be one of: de-DE, en-GB`)
+}
+
+func TestLocalizedHTMLReport(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+	require.NoError(t, reporter.SetLocale("de-DE"))
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{Title: "Daily", GeneratedAt: base}
+	for i := 0; i < 1500; i++ {
+		data.LogEntries = append(data.LogEntries, &models.LogEntry{
+			Timestamp: base, SourceIP: "192.0.2.1", Method: "GET", Path: "/", StatusCode: 200, ProcessingTime: 0.25,
+		})
+	}
+	location, err := reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	html, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Generated on 1. März 2024, 10:00")
+	assert.Contains(t, string(html), `<div class="stat-number">1.500</div>`)
+	assert.Contains(t, string(html), `<div class="stat-number">0,25</div>`)
+	assert.Contains(t, string(html), "100,00%")
+	// Chart data stays in the numbers JavaScript reads
+	assert.Contains(t, string(html), " 1500 ,")
+	assert.Contains(t, string(html), "style=\"width: 100%\"")
+}
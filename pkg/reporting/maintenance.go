@@ -0,0 +1,57 @@
+package reporting
+
+import (
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// prepareAvailability computes availability over all requests and over the
+// requests outside maintenance windows, and marks maintenance traffic in the
+// hourly breakdown so planned work is visible on the chart
+func (r *Reporter) prepareAvailability(data *ReportData) {
+	var total, failed, adjustedTotal, adjustedFailed int64
+	var maintenanceByHour [24]int64
+
+	for _, entry := range data.LogEntries {
+		serverError := entry.StatusCode >= 500
+		total++
+		if serverError {
+			failed++
+		}
+
+		if inMaintenance(data.Maintenance, entry) {
+			maintenanceByHour[entry.Timestamp.Hour()]++
+			continue
+		}
+		adjustedTotal++
+		if serverError {
+			adjustedFailed++
+		}
+	}
+
+	data.Summary.Availability = availability(total, failed)
+	data.Summary.AdjustedAvailability = availability(adjustedTotal, adjustedFailed)
+	data.Summary.MaintenanceRequests = total - adjustedTotal
+
+	for i := range data.Summary.HourlyTraffic {
+		hour := data.Summary.HourlyTraffic[i].Hour
+		data.Summary.HourlyTraffic[i].MaintenanceCount = maintenanceByHour[hour]
+	}
+}
+
+func inMaintenance(windows []*models.MaintenanceWindow, entry *models.LogEntry) bool {
+	for _, window := range windows {
+		if window.Contains(entry.Timestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+// availability returns the percentage of successful requests, treating a
+// period without traffic as fully available
+func availability(total, failed int64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(total-failed) / float64(total) * 100
+}
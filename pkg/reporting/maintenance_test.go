@@ -0,0 +1,47 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareAvailabilityExcludesMaintenance(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, StatusCode: 200},
+			{Timestamp: base.Add(time.Minute), StatusCode: 200},
+			{Timestamp: base.Add(2 * time.Minute), StatusCode: 404},
+			// During the deploy
+			{Timestamp: base.Add(time.Hour), StatusCode: 503},
+			{Timestamp: base.Add(time.Hour + time.Minute), StatusCode: 502},
+		},
+		Maintenance: []*models.MaintenanceWindow{
+			{Name: "deploy", StartsAt: base.Add(time.Hour), EndsAt: base.Add(time.Hour + 30*time.Minute)},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	assert.InDelta(t, 60.0, data.Summary.Availability, 1e-9)
+	assert.InDelta(t, 100.0, data.Summary.AdjustedAvailability, 1e-9)
+	assert.Equal(t, int64(2), data.Summary.MaintenanceRequests)
+
+	assert.Equal(t, int64(3), data.Summary.HourlyTraffic[10].Count)
+	assert.Equal(t, int64(0), data.Summary.HourlyTraffic[10].MaintenanceCount)
+	assert.Equal(t, int64(2), data.Summary.HourlyTraffic[11].MaintenanceCount)
+}
+
+func TestPrepareAvailabilityWithoutTraffic(t *testing.T) {
+	data := &ReportData{}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	assert.Equal(t, 100.0, data.Summary.Availability)
+	assert.Equal(t, 100.0, data.Summary.AdjustedAvailability)
+}
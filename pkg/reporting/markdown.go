@@ -0,0 +1,222 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// markdownEscaper escapes the characters Markdown would read as formatting
+// in table cells and headings
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+	"\r\n", " ", "\n", " ", "\r", " ",
+)
+
+// mdText escapes text for a table cell or heading
+func mdText(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// mdCode shows text as code in a table cell, so paths, IPs and user agents
+// appear as logged
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	// A space keeps a backtick at either end from joining the fence
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// markdownTable is a table of a Markdown report. Columns listed in right
+// are right-aligned, as numbers are.
+type markdownTable struct {
+	header []string
+	right  []int
+	rows   [][]string
+}
+
+func (t *markdownTable) write(b *strings.Builder) {
+	b.WriteString("|")
+	for _, column := range t.header {
+		b.WriteString(" " + mdText(column) + " |")
+	}
+	b.WriteString("\n|")
+	for i := range t.header {
+		align := " --- |"
+		for _, right := range t.right {
+			if right == i {
+				align = " ---: |"
+			}
+		}
+		b.WriteString(align)
+	}
+	b.WriteString("\n")
+	for _, row := range t.rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// GenerateMarkdownReport generates the summary sections of a report as
+// Markdown tables, for pasting into wikis, issues and pull requests.
+// Entries are left out.
+func (r *Reporter) GenerateMarkdownReport(data *ReportData, reportName string) (string, error) {
+	r.prepareSummary(data)
+
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_%s.md", reportName, timestamp)
+
+	if err := r.put(filename, []byte(markdownReport(data))); err != nil {
+		return "", fmt.Errorf("failed to save Markdown file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
+
+// markdownReport lays out a prepared report as Markdown
+func markdownReport(data *ReportData) string {
+	var b strings.Builder
+	summary := data.Summary
+
+	fmt.Fprintf(&b, "# %s\n\n", mdText(data.Title))
+	fmt.Fprintf(&b, "Generated %s", data.GeneratedAt.Format("2006-01-02 15:04:05 MST"))
+	if data.TimeRange != "" {
+		fmt.Fprintf(&b, " for %s", mdText(data.TimeRange))
+	}
+	b.WriteString(".\n")
+	if data.Truncated {
+		fmt.Fprintf(&b, "\nOnly the most recent %d entries of the period were read. Figures other than the traffic totals leave the older ones out.\n", len(data.LogEntries))
+	}
+
+	section := func(title string, table *markdownTable) {
+		if len(table.rows) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		table.write(&b)
+	}
+
+	overview := &markdownTable{header: []string{"Metric", "Value"}, right: []int{1}, rows: [][]string{
+		{"Total requests", fmt.Sprintf("%d", summary.TotalRequests)},
+		{"Unique IPs", fmt.Sprintf("%d", summary.UniqueIPs)},
+		{"Average response time", fmt.Sprintf("%.3f", summary.AvgResponseTime)},
+		{"P95 response time", fmt.Sprintf("%.3f", summary.P95ResponseTime)},
+		{"Error rate", fmt.Sprintf("%.2f%%", summary.ErrorRate)},
+		{"Availability", fmt.Sprintf("%.2f%%", summary.Availability)},
+	}}
+	if summary.MaintenanceRequests > 0 {
+		overview.rows = append(overview.rows,
+			[]string{"Availability outside maintenance", fmt.Sprintf("%.2f%%", summary.AdjustedAvailability)},
+			[]string{"Requests during maintenance", fmt.Sprintf("%d", summary.MaintenanceRequests)},
+		)
+	}
+	section("Summary", overview)
+
+	latency := &markdownTable{header: []string{"Path", "Requests", "P50", "P90", "P95", "P99"}, right: []int{1, 2, 3, 4, 5}}
+	if summary.Latency.Requests > 0 {
+		for _, row := range append([]LatencyPercentiles{summary.Latency}, summary.PathLatency...) {
+			path := mdCode(row.Path)
+			if row.Path == "" {
+				path = "All requests"
+			}
+			latency.rows = append(latency.rows, []string{
+				path,
+				fmt.Sprintf("%d", row.Requests),
+				fmt.Sprintf("%.3f", row.P50),
+				fmt.Sprintf("%.3f", row.P90),
+				fmt.Sprintf("%.3f", row.P95),
+				fmt.Sprintf("%.3f", row.P99),
+			})
+		}
+	}
+	section("Response Times", latency)
+
+	statuses := &markdownTable{header: []string{"Status Code", "Requests", "Percentage"}, right: []int{1, 2}}
+	codes := make([]string, 0, len(summary.StatusCodeBreakdown))
+	var total int64
+	for code, count := range summary.StatusCodeBreakdown {
+		codes = append(codes, code)
+		total += count
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		count := summary.StatusCodeBreakdown[code]
+		statuses.rows = append(statuses.rows, []string{
+			mdText(code),
+			fmt.Sprintf("%d", count),
+			fmt.Sprintf("%.2f%%", float64(count)/float64(total)*100),
+		})
+	}
+	section("Status Codes", statuses)
+
+	paths := &markdownTable{header: []string{"Path", "Requests", "Percentage"}, right: []int{1, 2}}
+	for _, path := range summary.TopPaths {
+		paths.rows = append(paths.rows, []string{mdCode(path.Path), fmt.Sprintf("%d", path.Count), fmt.Sprintf("%.2f%%", path.Percentage)})
+	}
+	section("Top Paths", paths)
+
+	ips := &markdownTable{header: []string{"IP", "Requests", "Percentage"}, right: []int{1, 2}}
+	for _, ip := range summary.TopIPs {
+		ips.rows = append(ips.rows, []string{mdCode(ip.IP), fmt.Sprintf("%d", ip.Count), fmt.Sprintf("%.2f%%", ip.Percentage)})
+	}
+	section("Top IPs", ips)
+
+	methods := &markdownTable{header: []string{"Method", "Requests", "Avg Response Time", "Error Rate"}, right: []int{1, 2, 3}}
+	for _, method := range summary.MethodBreakdown {
+		methods.rows = append(methods.rows, []string{
+			mdText(method.Method),
+			fmt.Sprintf("%d", method.Requests),
+			fmt.Sprintf("%.3f", method.AvgResponseTime),
+			fmt.Sprintf("%.2f%%", method.ErrorRate),
+		})
+	}
+	section("HTTP Methods", methods)
+
+	userAgents := &markdownTable{header: []string{"Category", "Name", "Requests", "Percentage"}, right: []int{2, 3}}
+	for _, group := range userAgentGroups(summary.UserAgents) {
+		for _, ua := range group.summaries {
+			name := mdText(ua.Name)
+			if group.category == "User Agent" {
+				name = mdCode(ua.Name)
+			}
+			userAgents.rows = append(userAgents.rows, []string{group.category, name, fmt.Sprintf("%d", ua.Count), fmt.Sprintf("%.2f%%", ua.Percentage)})
+		}
+	}
+	section("User Agents", userAgents)
+
+	if summary.Geo != nil {
+		countries := &markdownTable{header: []string{"Country", "Requests", "Percentage", "Error Rate"}, right: []int{1, 2, 3}}
+		for _, country := range summary.Geo.Countries {
+			countries.rows = append(countries.rows, []string{
+				fmt.Sprintf("%s (%s)", mdText(country.Name), mdText(country.Country)),
+				fmt.Sprintf("%d", country.Count),
+				fmt.Sprintf("%.2f%%", country.Percentage),
+				fmt.Sprintf("%.2f%%", country.ErrorRate),
+			})
+		}
+		section("Countries", countries)
+	}
+
+	// Only hours with requests, so a short report stays short
+	hourly := &markdownTable{header: []string{"Hour", "Requests"}, right: []int{1}}
+	for _, hour := range summary.HourlyTraffic {
+		if hour.Count > 0 {
+			hourly.rows = append(hourly.rows, []string{fmt.Sprintf("%02d:00", hour.Hour), fmt.Sprintf("%d", hour.Count)})
+		}
+	}
+	section("Hourly Traffic", hourly)
+
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestMarkdownEscaping(t *testing.T) {
+	assert.Equal(t, `a\|b \*c\* \_d\_ \<e\> \[f\](g) \#1 \\ x`, mdText("a|b *c* _d_ <e> [f](g) #1 \\\nx"))
+	assert.Equal(t, "`/search?q=a\\|b`", mdCode("/search?q=a|b"))
+	assert.Equal(t, "``/a`b``", mdCode("/a`b"))
+	assert.Equal(t, "`` `quoted` ``", mdCode("`quoted`"))
+	assert.Equal(t, "", mdCode(""))
+}
+
+func TestGenerateMarkdownReport(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{Title: "Daily | shop", GeneratedAt: base, TimeRange: "2024-03-01 - 2024-03-02"}
+	for i, path := range []string{"/", "/", "/search?q=a|b", "/login"} {
+		status := 200
+		if path == "/login" {
+			status = 500
+		}
+		data.LogEntries = append(data.LogEntries, &models.LogEntry{
+			Timestamp:      base.Add(time.Duration(i) * time.Minute),
+			SourceIP:       "192.0.2.1",
+			Method:         "GET",
+			Path:           path,
+			StatusCode:     status,
+			UserAgent:      "curl/8.4.0",
+			ProcessingTime: 0.1,
+		})
+	}
+
+	location, err := reporter.GenerateMarkdownReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_10-00-00.md", filepath.Base(location))
+	content, err := os.ReadFile(location)
+	require.NoError(t, err)
+	markdown := string(content)
+
+	assert.True(t, strings.HasPrefix(markdown, "# Daily \\| shop\n\nGenerated 2024-03-01 10:00:00 UTC for 2024-03-01 - 2024-03-02.\n"))
+	assert.Contains(t, markdown, "## Summary\n\n| Metric | Value |\n| --- | ---: |\n| Total requests | 4 |\n")
+	assert.Contains(t, markdown, "| Error rate | 25.00% |\n")
+	assert.Contains(t, markdown, "| All requests | 4 | 0.100 |")
+	assert.Contains(t, markdown, "| 500 | 1 | 25.00% |\n")
+	assert.Contains(t, markdown, "| `/` | 2 | 50.00% |\n")
+	assert.Contains(t, markdown, "| `/search?q=a\\|b` | 1 | 25.00% |\n")
+	assert.Contains(t, markdown, "| `192.0.2.1` | 4 | 100.00% |\n")
+	assert.Contains(t, markdown, "| User Agent | `curl/8.4.0` | 4 | 100.00% |\n")
+	assert.Contains(t, markdown, "## Hourly Traffic\n\n| Hour | Requests |\n| --- | ---: |\n| 10:00 | 4 |\n")
+	assert.NotContains(t, markdown, "maintenance", "no maintenance rows without maintenance")
+	assert.NotContains(t, markdown, "## Countries", "no geography without a GeoIP database")
+
+	// Every table row has as many cells as its header
+	var columns int
+	for _, line := range strings.Split(markdown, "\n") {
+		if !strings.HasPrefix(line, "|") {
+			columns = 0
+			continue
+		}
+		cells := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|") - 1
+		if columns == 0 {
+			columns = cells
+		}
+		assert.Equal(t, columns, cells, line)
+	}
+
+	// A report without entries has only its summary
+	location, err = reporter.GenerateMarkdownReport(&ReportData{Title: "Empty", GeneratedAt: base}, "empty")
+	require.NoError(t, err)
+	content, err = os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "| Total requests | 0 |")
+	assert.NotContains(t, string(content), "## Top Paths")
+	assert.NotContains(t, string(content), "## Response Times")
+	assert.NotContains(t, string(content), "Only the most recent")
+
+	// A truncated report says how many entries it read
+	data.Truncated = true
+	assert.Contains(t, markdownReport(data), "Only the most recent 4 entries of the period were read.")
+}
@@ -0,0 +1,92 @@
+package reporting
+
+import "sort"
+
+// MethodSummary compares requests by HTTP method, and by path when Path is set
+type MethodSummary struct {
+	Method          string
+	Path            string
+	Requests        int64
+	AvgResponseTime float64
+	ErrorRate       float64
+	// Link drills down into the entries behind the row
+	Link string
+}
+
+type methodTally struct {
+	requests  int64
+	errors    int64
+	totalTime float64
+	timed     int64
+}
+
+func (t *methodTally) summary(method, path string) MethodSummary {
+	summary := MethodSummary{
+		Method:    method,
+		Path:      path,
+		Requests:  t.requests,
+		ErrorRate: float64(t.errors) / float64(t.requests) * 100,
+	}
+	if t.timed > 0 {
+		summary.AvgResponseTime = t.totalTime / float64(t.timed)
+	}
+	return summary
+}
+
+// prepareMethodBreakdown compares HTTP methods overall and, for top paths
+// served with more than one method, per path, so a degrading write path is
+// not hidden in the path's average
+func (r *Reporter) prepareMethodBreakdown(data *ReportData) {
+	methods := make(map[string]*methodTally)
+	paths := make(map[string]map[string]*methodTally)
+
+	for _, entry := range data.LogEntries {
+		// Entries without a status code are not HTTP requests
+		if entry.Method == "" || entry.StatusCode == 0 {
+			continue
+		}
+
+		byMethod, ok := paths[entry.Path]
+		if !ok {
+			byMethod = make(map[string]*methodTally)
+			paths[entry.Path] = byMethod
+		}
+		for _, tallies := range []map[string]*methodTally{methods, byMethod} {
+			tally, ok := tallies[entry.Method]
+			if !ok {
+				tally = &methodTally{}
+				tallies[entry.Method] = tally
+			}
+			tally.requests++
+			if entry.StatusCode >= 400 {
+				tally.errors++
+			}
+			if entry.ProcessingTime > 0 {
+				tally.totalTime += entry.ProcessingTime
+				tally.timed++
+			}
+		}
+	}
+
+	data.Summary.MethodBreakdown = sortMethodSummaries(methods, "")
+
+	for _, top := range data.Summary.TopPaths {
+		if byMethod := paths[top.Path]; len(byMethod) > 1 {
+			data.Summary.PathMethodBreakdown = append(data.Summary.PathMethodBreakdown, sortMethodSummaries(byMethod, top.Path)...)
+		}
+	}
+}
+
+func sortMethodSummaries(tallies map[string]*methodTally, path string) []MethodSummary {
+	var summaries []MethodSummary
+	for method, tally := range tallies {
+		summaries = append(summaries, tally.summary(method, path))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Requests != summaries[j].Requests {
+			return summaries[i].Requests > summaries[j].Requests
+		}
+		return summaries[i].Method < summaries[j].Method
+	})
+	return summaries
+}
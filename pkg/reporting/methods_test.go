@@ -0,0 +1,52 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareMethodBreakdown(t *testing.T) {
+	request := func(method, path string, status int, took float64) *models.LogEntry {
+		return &models.LogEntry{LogType: "nginx", Method: method, Path: path, StatusCode: status, ProcessingTime: took}
+	}
+
+	data := &ReportData{
+		LogEntries: []*models.LogEntry{
+			request("GET", "/api/orders", 200, 0.05),
+			request("GET", "/api/orders", 200, 0.05),
+			request("GET", "/api/orders", 200, 0),
+			request("POST", "/api/orders", 500, 2.0),
+			request("POST", "/api/orders", 201, 1.0),
+			request("GET", "/", 200, 0.01),
+			// Flow logs carry a protocol, not an HTTP method
+			{LogType: "aws_vpc_flow", Method: "TCP", Path: "10.0.0.1:22"},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	require.Len(t, data.Summary.MethodBreakdown, 2)
+	get := data.Summary.MethodBreakdown[0]
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, int64(4), get.Requests)
+	assert.InDelta(t, 0.0367, get.AvgResponseTime, 0.0001)
+	assert.Equal(t, 0.0, get.ErrorRate)
+
+	post := data.Summary.MethodBreakdown[1]
+	assert.Contains(t, post.Link, "method=POST")
+	post.Link = ""
+	assert.Equal(t, MethodSummary{Method: "POST", Requests: 2, AvgResponseTime: 1.5, ErrorRate: 50}, post)
+
+	// Only /api/orders is served with more than one method
+	require.Len(t, data.Summary.PathMethodBreakdown, 2)
+	getOrders := data.Summary.PathMethodBreakdown[0]
+	assert.Contains(t, getOrders.Link, "method=GET")
+	getOrders.Link = ""
+	assert.Equal(t, MethodSummary{Method: "GET", Path: "/api/orders", Requests: 3, AvgResponseTime: 0.05}, getOrders)
+	assert.Equal(t, "POST", data.Summary.PathMethodBreakdown[1].Method)
+	assert.Equal(t, 50.0, data.Summary.PathMethodBreakdown[1].ErrorRate)
+}
@@ -0,0 +1,140 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// flowDirections is the order directions are listed in reports
+var flowDirections = []string{
+	logprocessor.FlowInbound,
+	logprocessor.FlowOutbound,
+	logprocessor.FlowInternal,
+	logprocessor.FlowExternal,
+}
+
+// NetworkSummary breaks VPC flow log records down by action and direction
+type NetworkSummary struct {
+	Flows         int64
+	Accepted      int64
+	Rejected      int64
+	Bytes         int64
+	RejectedBytes int64
+	Directions    []DirectionSummary
+	// TopRejectedSources ranks source IPs by rejected flows, as a
+	// percentage of all rejected flows
+	TopRejectedSources  []IPSummary
+	TopDestinationPorts []PortSummary
+}
+
+type DirectionSummary struct {
+	Direction string
+	Flows     int64
+	Accepted  int64
+	Rejected  int64
+	Bytes     int64
+}
+
+type PortSummary struct {
+	// Port is the destination port with its protocol, e.g. "TCP/22"
+	Port     string
+	Flows    int64
+	Rejected int64
+	Bytes    int64
+}
+
+// prepareNetworkSummary summarizes the flow log entries of a report; it is
+// left nil when there are none
+func (r *Reporter) prepareNetworkSummary(data *ReportData) {
+	summary := &NetworkSummary{}
+	directions := make(map[string]*DirectionSummary)
+	ports := make(map[string]*PortSummary)
+	rejectedSources := make(map[string]int64)
+
+	for _, entry := range data.LogEntries {
+		if entry.LogType != "aws_vpc_flow" {
+			continue
+		}
+		rejected := flowMetadata(entry, "action") == "REJECT"
+
+		summary.Flows++
+		summary.Bytes += entry.ResponseSize
+		if rejected {
+			summary.Rejected++
+			summary.RejectedBytes += entry.ResponseSize
+			rejectedSources[entry.SourceIP]++
+		} else {
+			summary.Accepted++
+		}
+
+		direction := flowMetadata(entry, "direction")
+		ds, ok := directions[direction]
+		if !ok {
+			ds = &DirectionSummary{Direction: direction}
+			directions[direction] = ds
+		}
+		ds.Flows++
+		ds.Bytes += entry.ResponseSize
+		if rejected {
+			ds.Rejected++
+		} else {
+			ds.Accepted++
+		}
+
+		port := entry.Method + "/" + flowMetadata(entry, "dstport")
+		ps, ok := ports[port]
+		if !ok {
+			ps = &PortSummary{Port: port}
+			ports[port] = ps
+		}
+		ps.Flows++
+		ps.Bytes += entry.ResponseSize
+		if rejected {
+			ps.Rejected++
+		}
+	}
+
+	if summary.Flows == 0 {
+		return
+	}
+
+	for _, direction := range flowDirections {
+		if ds, ok := directions[direction]; ok {
+			summary.Directions = append(summary.Directions, *ds)
+		}
+	}
+
+	for _, ps := range ports {
+		summary.TopDestinationPorts = append(summary.TopDestinationPorts, *ps)
+	}
+	sort.Slice(summary.TopDestinationPorts, func(i, j int) bool {
+		a, b := summary.TopDestinationPorts[i], summary.TopDestinationPorts[j]
+		if a.Flows != b.Flows {
+			return a.Flows > b.Flows
+		}
+		return a.Port < b.Port
+	})
+	if len(summary.TopDestinationPorts) > 10 {
+		summary.TopDestinationPorts = summary.TopDestinationPorts[:10]
+	}
+
+	summary.TopRejectedSources = r.getTopIPs(rejectedSources, 10)
+	for i := range summary.TopRejectedSources {
+		summary.TopRejectedSources[i].Percentage = float64(summary.TopRejectedSources[i].Count) / float64(summary.Rejected) * 100
+	}
+
+	data.Summary.Network = summary
+}
+
+// flowMetadata returns a flow log metadata value as a string; numbers read
+// back from the database are float64
+func flowMetadata(entry *models.LogEntry, key string) string {
+	value, ok := entry.Metadata[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,62 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareNetworkSummary(t *testing.T) {
+	flow := func(src, action, direction string, port interface{}, bytes int64) *models.LogEntry {
+		return &models.LogEntry{
+			LogType:      "aws_vpc_flow",
+			SourceIP:     src,
+			Method:       "TCP",
+			ResponseSize: bytes,
+			// Ports read back from the database are float64
+			Metadata: models.LogMetadata{"action": action, "direction": direction, "dstport": port},
+		}
+	}
+
+	data := &ReportData{
+		LogEntries: []*models.LogEntry{
+			flow("203.0.113.5", "REJECT", "inbound", 22, 60),
+			flow("203.0.113.5", "REJECT", "inbound", 22.0, 60),
+			flow("198.51.100.2", "REJECT", "inbound", 3389, 40),
+			flow("198.51.100.2", "ACCEPT", "inbound", 443, 1000),
+			flow("10.0.0.4", "ACCEPT", "outbound", 443, 500),
+			{LogType: "nginx", SourceIP: "10.0.0.4", Path: "/"},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	network := data.Summary.Network
+	require.NotNil(t, network)
+	assert.Equal(t, int64(5), network.Flows)
+	assert.Equal(t, int64(2), network.Accepted)
+	assert.Equal(t, int64(3), network.Rejected)
+	assert.Equal(t, int64(1660), network.Bytes)
+	assert.Equal(t, int64(160), network.RejectedBytes)
+
+	require.Len(t, network.Directions, 2)
+	assert.Equal(t, DirectionSummary{Direction: "inbound", Flows: 4, Accepted: 1, Rejected: 3, Bytes: 1160}, network.Directions[0])
+	assert.Equal(t, "outbound", network.Directions[1].Direction)
+
+	require.Len(t, network.TopDestinationPorts, 3)
+	assert.Equal(t, PortSummary{Port: "TCP/22", Flows: 2, Rejected: 2, Bytes: 120}, network.TopDestinationPorts[0])
+	assert.Equal(t, "TCP/443", network.TopDestinationPorts[1].Port)
+
+	require.Len(t, network.TopRejectedSources, 2)
+	assert.Equal(t, "203.0.113.5", network.TopRejectedSources[0].IP)
+	assert.InDelta(t, 66.67, network.TopRejectedSources[0].Percentage, 0.01)
+}
+
+func TestPrepareNetworkSummaryWithoutFlows(t *testing.T) {
+	data := &ReportData{LogEntries: []*models.LogEntry{{LogType: "apache", Path: "/"}}}
+	(&Reporter{}).prepareSummary(data)
+	assert.Nil(t, data.Summary.Network)
+}
@@ -0,0 +1,124 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/latency"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// OpenMetricsContentType is the media type of WriteOpenMetrics' output
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// KPISnapshotReports are the scheduled reports whose key numbers are kept
+var KPISnapshotReports = []string{"daily", "weekly"}
+
+// KPISnapshot is the key numbers of the latest report of a kind, kept so
+// monitoring can scrape them without parsing the HTML report
+type KPISnapshot struct {
+	Report          string    `json:"report"`
+	GeneratedAt     time.Time `json:"generated_at"`
+	TimeRange       string    `json:"time_range"`
+	TotalRequests   int64     `json:"total_requests"`
+	ErrorRate       float64   `json:"error_rate"` // percent
+	P95ResponseTime float64   `json:"p95_response_time"`
+	UniqueIPs       int64     `json:"unique_ips"`
+}
+
+// kpiSnapshotName is where the latest snapshot of a report is stored.
+// Snapshots live in a directory so they are not listed with the reports.
+func kpiSnapshotName(report string) string {
+	return "metrics/" + report + ".json"
+}
+
+// SaveKPISnapshot stores the key numbers of a report whose summary has
+// been prepared, replacing the previous snapshot of its kind
+func (r *Reporter) SaveKPISnapshot(report string, data *ReportData) error {
+	snapshot := KPISnapshot{
+		Report:          report,
+		GeneratedAt:     data.GeneratedAt,
+		TimeRange:       data.TimeRange,
+		TotalRequests:   data.Summary.TotalRequests,
+		ErrorRate:       data.Summary.ErrorRate,
+		P95ResponseTime: data.Summary.P95ResponseTime,
+		UniqueIPs:       data.Summary.UniqueIPs,
+	}
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := r.put(kpiSnapshotName(report), encoded); err != nil {
+		return fmt.Errorf("failed to save KPI snapshot: %w", err)
+	}
+	return nil
+}
+
+// LatestKPISnapshot returns the snapshot of the latest report of a kind,
+// or an error matching os.ErrNotExist if none has been saved
+func (r *Reporter) LatestKPISnapshot(report string) (*KPISnapshot, error) {
+	file, _, err := r.store.Open(kpiSnapshotName(report))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snapshot KPISnapshot
+	if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid KPI snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// WriteOpenMetrics renders snapshots in the OpenMetrics text format, one
+// gauge family per number with a report label. Response times are in
+// seconds and the error rate is a ratio, as the format's conventions ask.
+func WriteOpenMetrics(w io.Writer, snapshots []*KPISnapshot) error {
+	families := []struct {
+		name, unit, help string
+		value            func(*KPISnapshot) float64
+	}{
+		{"log_analyzer_report_requests", "", "Requests in the report's period.",
+			func(s *KPISnapshot) float64 { return float64(s.TotalRequests) }},
+		{"log_analyzer_report_error_ratio", "", "Share of requests answered with a 4xx or 5xx status.",
+			func(s *KPISnapshot) float64 { return s.ErrorRate / 100 }},
+		{"log_analyzer_report_response_time_p95_seconds", "seconds", "95th percentile of response times.",
+			func(s *KPISnapshot) float64 { return s.P95ResponseTime }},
+		{"log_analyzer_report_unique_ips", "", "Distinct source IPs in the report's period.",
+			func(s *KPISnapshot) float64 { return float64(s.UniqueIPs) }},
+		{"log_analyzer_report_generated_timestamp_seconds", "seconds", "Time the report was generated.",
+			func(s *KPISnapshot) float64 { return float64(s.GeneratedAt.UnixMilli()) / 1000 }},
+	}
+
+	var b strings.Builder
+	for _, family := range families {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", family.name)
+		if family.unit != "" {
+			fmt.Fprintf(&b, "# UNIT %s %s\n", family.name, family.unit)
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.name, family.help)
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(&b, "%s{report=%q} %s\n", family.name, snapshot.Report,
+				strconv.FormatFloat(family.value(snapshot), 'f', -1, 64))
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// percentileResponseTime returns the p-th percentile of the entries'
+// response times, leaving out entries without one
+func percentileResponseTime(entries []*models.LogEntry, p float64) float64 {
+	var histogram latency.Histogram
+	for _, entry := range entries {
+		// The histogram counts milliseconds
+		histogram.Add(entry.ProcessingTime * 1000)
+	}
+	return histogram.Percentile(p) / 1000
+}
@@ -0,0 +1,72 @@
+package reporting
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestKPISnapshot(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+
+	_, err = reporter.LatestKPISnapshot("daily")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	data := &ReportData{GeneratedAt: time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC), TimeRange: "2024-03-01 to 2024-03-02"}
+	for i := 1; i <= 100; i++ {
+		status := 200
+		if i%10 == 0 {
+			status = 500
+		}
+		data.LogEntries = append(data.LogEntries, &models.LogEntry{
+			SourceIP:       []string{"10.0.0.1", "10.0.0.2"}[i%2],
+			StatusCode:     status,
+			ProcessingTime: float64(i) / 1000,
+		})
+	}
+	reporter.prepareSummary(data)
+	assert.InEpsilon(t, 0.095, data.Summary.P95ResponseTime, 0.01)
+
+	require.NoError(t, reporter.SaveKPISnapshot("daily", data))
+	snapshot, err := reporter.LatestKPISnapshot("daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), snapshot.TotalRequests)
+	assert.Equal(t, 10.0, snapshot.ErrorRate)
+	assert.Equal(t, int64(2), snapshot.UniqueIPs)
+	assert.Equal(t, data.GeneratedAt, snapshot.GeneratedAt.UTC())
+
+	// Snapshots are not listed with the reports
+	files, err := reporter.Store().List("")
+	require.NoError(t, err)
+	for _, file := range files {
+		assert.True(t, file.Dir, file.Name)
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, WriteOpenMetrics(&b, []*KPISnapshot{
+		{Report: "daily", GeneratedAt: time.Unix(1709344800, 0), TotalRequests: 1200, ErrorRate: 2.5, P95ResponseTime: 0.42, UniqueIPs: 87},
+		{Report: "weekly", GeneratedAt: time.Unix(1709002800, 500000000), TotalRequests: 9000},
+	}))
+
+	output := b.String()
+	assert.Contains(t, output, "# TYPE log_analyzer_report_requests gauge\n"+
+		"# HELP log_analyzer_report_requests Requests in the report's period.\n"+
+		"log_analyzer_report_requests{report=\"daily\"} 1200\n"+
+		"log_analyzer_report_requests{report=\"weekly\"} 9000\n")
+	assert.Contains(t, output, "log_analyzer_report_error_ratio{report=\"daily\"} 0.025\n")
+	assert.Contains(t, output, "# UNIT log_analyzer_report_response_time_p95_seconds seconds\n")
+	assert.Contains(t, output, "log_analyzer_report_response_time_p95_seconds{report=\"daily\"} 0.42\n")
+	assert.Contains(t, output, "log_analyzer_report_unique_ips{report=\"daily\"} 87\n")
+	assert.Contains(t, output, "log_analyzer_report_generated_timestamp_seconds{report=\"weekly\"} 1709002800.5\n")
+	assert.True(t, strings.HasSuffix(output, "\n# EOF\n"))
+}
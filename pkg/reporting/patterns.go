@@ -0,0 +1,22 @@
+package reporting
+
+import (
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/patterns"
+)
+
+// maxReportPatterns bounds the message patterns listed in a report
+const maxReportPatterns = 10
+
+// prepareMessagePatterns clusters the messages of application log entries
+// into templates so recurring and new error types stand out at volume
+func (r *Reporter) prepareMessagePatterns(data *ReportData) {
+	miner := patterns.NewMiner()
+	for _, entry := range data.LogEntries {
+		if !logprocessor.IsMessageLogType(entry.LogType) || entry.Path == "" {
+			continue
+		}
+		miner.Add(entry.Path, entry.Timestamp)
+	}
+	data.Summary.MessagePatterns = miner.Top(maxReportPatterns)
+}
@@ -0,0 +1,31 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareMessagePatterns(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, LogType: "generic", Path: "failed to connect to db1"},
+			{Timestamp: base.Add(time.Minute), LogType: "kubernetes", Path: "failed to connect to db2"},
+			{Timestamp: base.Add(2 * time.Minute), LogType: "logfmt", Path: "cache warmed in 3s"},
+			// Access log paths are not messages
+			{Timestamp: base, LogType: "nginx", Path: "/api/users"},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	require.Len(t, data.Summary.MessagePatterns, 2)
+	assert.Equal(t, "failed to connect to <*>", data.Summary.MessagePatterns[0].Template)
+	assert.Equal(t, int64(2), data.Summary.MessagePatterns[0].Count)
+	assert.Equal(t, "cache warmed in <NUM>", data.Summary.MessagePatterns[1].Template)
+}
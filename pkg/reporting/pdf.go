@@ -0,0 +1,235 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Layout of PDF reports, in points
+const (
+	pdfPageWidth  = 595 // A4
+	pdfPageHeight = 842
+	pdfMargin     = 40
+	pdfFontSize   = 8
+	// pdfLineHeight is the height of a line as a multiple of its font size
+	pdfLineHeight = 1.4
+)
+
+// pdfColumns is how many Courier characters, 0.6 of the font size wide,
+// fit across a page
+const pdfColumns = (pdfPageWidth - 2*pdfMargin) * 10 / (6 * pdfFontSize)
+
+// PDF fonts, by their resource names
+const (
+	pdfFontText = "F1" // Courier
+	pdfFontBold = "F2" // Helvetica-Bold
+)
+
+// The largest a logo is drawn, in points
+const (
+	pdfLogoWidth  = 160
+	pdfLogoHeight = 40
+)
+
+// pdfDocument lays out text on A4 pages. It uses the standard Courier and
+// Helvetica-Bold fonts, which every PDF reader provides, so no fonts are
+// embedded, and tables line up in Courier's fixed width. Text outside
+// Latin-1 is replaced by "?".
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	// y is the baseline of the next line on the last page
+	y float64
+	// logo is drawn at the top of the first page and footer at the foot
+	// of every page
+	logo   *Logo
+	footer string
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{}
+	d.newPage()
+	return d
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// newPDF starts a PDF report with the reporter's branding
+func (r *Reporter) newPDF() *pdfDocument {
+	d := newPDFDocument()
+	d.logo, d.footer = r.branding.Logo, r.branding.Footer
+	if d.logo != nil {
+		_, height := d.logoSize()
+		d.y -= height + pdfFontSize*pdfLineHeight
+	}
+	if r.branding.Title != "" {
+		d.line(pdfFontBold, 10, r.branding.Title)
+	}
+	return d
+}
+
+// logoSize is the size the logo is drawn at, scaled down to fit
+// pdfLogoWidth by pdfLogoHeight
+func (d *pdfDocument) logoSize() (float64, float64) {
+	scale := min(1, float64(pdfLogoWidth)/float64(d.logo.Width), float64(pdfLogoHeight)/float64(d.logo.Height))
+	return float64(d.logo.Width) * scale, float64(d.logo.Height) * scale
+}
+
+// line writes a line of text, starting a new page when the page is full
+func (d *pdfDocument) line(font string, size float64, text string) {
+	if d.y-size*pdfLineHeight < pdfMargin {
+		d.newPage()
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %g Tf %d %g Td (%s) Tj ET\n", font, size, pdfMargin, d.y-size, pdfString(text))
+	d.y -= size * pdfLineHeight
+}
+
+// space leaves a blank line
+func (d *pdfDocument) space() {
+	d.y -= pdfFontSize * pdfLineHeight
+}
+
+// title writes the document's title
+func (d *pdfDocument) title(text string) {
+	d.line(pdfFontBold, 18, text)
+	d.space()
+}
+
+// heading starts a section, on a new page when only its heading would fit
+func (d *pdfDocument) heading(text string) {
+	if d.y-4*pdfFontSize*pdfLineHeight < pdfMargin {
+		d.newPage()
+	} else {
+		d.space()
+	}
+	d.line(pdfFontBold, 12, text)
+	d.space()
+}
+
+// text writes a paragraph, wrapped at the page width
+func (d *pdfDocument) text(text string) {
+	var line string
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > pdfColumns {
+			d.line(pdfFontText, pdfFontSize, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		d.line(pdfFontText, pdfFontSize, line)
+	}
+}
+
+// table writes rows under a header, each cell padded or cut to the width
+// of its column in characters
+func (d *pdfDocument) table(header []string, widths []int, rows [][]string) {
+	d.line(pdfFontText, pdfFontSize, pdfRow(header, widths))
+	rule := make([]string, len(widths))
+	for i, width := range widths {
+		rule[i] = strings.Repeat("-", width)
+	}
+	d.line(pdfFontText, pdfFontSize, pdfRow(rule, widths))
+	for _, row := range rows {
+		d.line(pdfFontText, pdfFontSize, pdfRow(row, widths))
+	}
+}
+
+func pdfRow(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		runes := []rune(cell)
+		if len(runes) > widths[i] {
+			runes = append(runes[:max(widths[i]-3, 0)], []rune("...")...)
+		}
+		b.WriteString(string(runes))
+		if i < len(cells)-1 {
+			b.WriteString(strings.Repeat(" ", widths[i]-len(runes)))
+		}
+	}
+	return b.String()
+}
+
+// pdfString escapes text for a PDF string literal in WinAnsiEncoding
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			// Latin-1 above ASCII is escaped to keep the file ASCII
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// bytes returns the document as a PDF file
+func (d *pdfDocument) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// Objects 1 to 4 are the catalog, the page tree and the fonts; each
+	// page is then followed by its contents, and the logo comes last
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	logo := 5 + 2*len(d.pages)
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		resources := fmt.Sprintf("/Font << /%s 3 0 R /%s 4 0 R >>", pdfFontText, pdfFontBold)
+		if i == 0 && d.logo != nil {
+			width, height := d.logoSize()
+			fmt.Fprintf(&content, "q %g 0 0 %g %d %g cm /Logo Do Q\n", width, height, pdfMargin, pdfPageHeight-pdfMargin-height)
+			resources += fmt.Sprintf(" /XObject << /Logo %d 0 R >>", logo)
+		}
+		content.Write(page.Bytes())
+		if d.footer != "" {
+			fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", pdfFontText, pdfFontSize, pdfMargin, pdfMargin/2, pdfString(d.footer))
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << %s >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+	if d.logo != nil {
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB "+
+			"/BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			d.logo.Width, d.logo.Height, len(d.logo.pdfRGB), d.logo.pdfRGB))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
@@ -1,22 +1,31 @@
 package reporting
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"html/template"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/latency"
 	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/patterns"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
 )
 
 // Reporter handles report generation
 type Reporter struct {
 	templates *template.Template
-	outputDir string
+	store     reportstore.Store
+	catalog   Catalog
+	publicURL string
+	locator   Locator
+	locale    *locale
+	branding  Branding
 }
 
 // ReportData contains all data needed for report generation
@@ -28,52 +37,135 @@ type ReportData struct {
 	LogEntries  []*models.LogEntry
 	Filters     *models.LogFilter
 	Summary     ReportSummary
+	// Maintenance windows overlapping the report period
+	Maintenance []*models.MaintenanceWindow
+	// LatencyBudgets are checked against the report's response times
+	LatencyBudgets []*models.LatencyBudget
+	// Traffic are totals of the whole period from traffic rollups; nil
+	// when the period is not made of whole hours or rollups are disabled
+	Traffic *TrafficTotals
+	// Truncated is set when the period had more entries than LogEntries
+	// holds, the most recent ones, so figures worked out from the entries
+	// rather than from Traffic leave the older ones out
+	Truncated bool
+	// Template replaces the built-in HTML template of the report type;
+	// nil for the built-in one. See ParseTemplate.
+	Template *template.Template
 }
 
 type ReportSummary struct {
 	TotalRequests    int64
 	UniqueIPs        int64
 	AvgResponseTime  float64
+	// P95ResponseTime is the 95th percentile of the entries' response
+	// times, in the same unit as AvgResponseTime
+	P95ResponseTime float64
+	// Latency is the percentiles of all response times, PathLatency those
+	// of the busiest paths
+	Latency     LatencyPercentiles
+	PathLatency []LatencyPercentiles
 	ErrorRate        float64
 	TopPaths         []PathSummary
 	TopIPs           []IPSummary
 	StatusCodeBreakdown map[string]int64
+	// StatusCodeLinks drill down into the entries of each status code
+	StatusCodeLinks  map[string]string
 	HourlyTraffic    []HourlyTraffic
+	// Availability is the share of requests without a 5xx response;
+	// AdjustedAvailability leaves out requests during maintenance windows
+	Availability         float64
+	AdjustedAvailability float64
+	MaintenanceRequests  int64
+	// MessagePatterns groups application log messages into templates
+	MessagePatterns []patterns.Pattern
+	// MethodBreakdown compares HTTP methods; PathMethodBreakdown does so
+	// for top paths served with more than one method
+	MethodBreakdown     []MethodSummary
+	PathMethodBreakdown []MethodSummary
+	// UserAgents breaks requests down by browser, operating system, device
+	// and bot
+	UserAgents UserAgentBreakdown
+	// Geo breaks requests down by country and city; nil without a GeoIP
+	// database
+	Geo *GeoBreakdown
+	// Network summarizes VPC flow logs; nil when the report has none
+	Network *NetworkSummary
+	// LatencyBudgets is how each budgeted path fared, violations first
+	LatencyBudgets          []latency.Result
+	LatencyBudgetViolations int
 }
 
 type PathSummary struct {
 	Path  string
 	Count int64
 	Percentage float64
+	// Link drills down into the entries behind the row
+	Link string
 }
 
 type IPSummary struct {
 	IP    string
 	Count int64
 	Percentage float64
+	// Link drills down into the entries behind the row
+	Link string
 }
 
 type HourlyTraffic struct {
 	Hour  int
 	Count int64
+	// MaintenanceCount is the part of Count logged during maintenance windows
+	MaintenanceCount int64
+	// Link drills down into the hour's entries; it is empty when the hour
+	// of day recurs on several days of the report
+	Link string
 }
 
-func NewReporter(templateDir, outputDir string) (*Reporter, error) {
+// NewReporter creates a reporter that saves reports to store. Generated
+// reports are returned by their location in the store.
+func NewReporter(templateDir string, store reportstore.Store) (*Reporter, error) {
+	r := &Reporter{
+		store:  store,
+		locale: defaultLocale,
+	}
+
 	// Parse HTML templates
-	templates, err := template.ParseGlob(filepath.Join(templateDir, "*.html"))
+	templates, err := template.New("").Funcs(r.templateFuncs()).ParseGlob(filepath.Join(templateDir, "*.html"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse templates: %w", err)
 	}
+	r.templates = templates
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create output directory: %w", err)
-	}
+	return r, nil
+}
+
+// Store returns the store reports are saved to
+func (r *Reporter) Store() reportstore.Store {
+	return r.store
+}
 
-	return &Reporter{
-		templates: templates,
-		outputDir: outputDir,
-	}, nil
+// renderTemplate executes a template and saves the result as filename
+func (r *Reporter) renderTemplate(name, filename string, data interface{}) (string, error) {
+	return r.renderCustom(nil, name, filename, data)
+}
+
+// renderCustom executes custom, or the built-in template name when custom
+// is nil, and saves the result as filename
+func (r *Reporter) renderCustom(custom *template.Template, name, filename string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	if custom != nil {
+		err = custom.Execute(&buf, data)
+	} else {
+		err = r.templates.ExecuteTemplate(&buf, name, data)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save report: %w", err)
+	}
+	return r.store.Location(filename), nil
 }
 
 // GenerateHTMLReport generates an HTML report
@@ -82,59 +174,69 @@ func (r *Reporter) GenerateHTMLReport(data *ReportData, reportName string) (stri
 	r.prepareSummary(data)
 
 	// Generate filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	timestamp := runTimestamp(data)
 	filename := fmt.Sprintf("%s_%s.html", reportName, timestamp)
-	filepath := filepath.Join(r.outputDir, filename)
-
-	// Create output file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create report file: %w", err)
-	}
-	defer file.Close()
 
-	// Execute template
-	if err := r.templates.ExecuteTemplate(file, "report.html", data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	return filepath, nil
+	return r.renderCustom(data.Template, "report.html", filename, data)
 }
 
 // GenerateCSVReport generates a CSV report
 func (r *Reporter) GenerateCSVReport(data *ReportData, reportName string) (string, error) {
 	// Generate filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	timestamp := runTimestamp(data)
 	filename := fmt.Sprintf("%s_%s.csv", reportName, timestamp)
-	filepath := filepath.Join(r.outputDir, filename)
-
-	// Create output file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create CSV file: %w", err)
-	}
-	defer file.Close()
 
 	// Create CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
 
 	// Write header
-	header := []string{
-		"Timestamp", "Log Type", "Source IP", "Method", "Path",
-		"Status Code", "Response Size", "User Agent", "Referer",
-		"Processing Time", "Raw Log",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(entryCSVHeader); err != nil {
 		return "", fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	// Write data rows
+	row := r.entryCSVRow()
 	for _, entry := range data.LogEntries {
-		row := []string{
+		if err := writer.Write(row(entry)); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save CSV file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
+
+// entryCSVHeader is the header of CSV exports of log entries
+var entryCSVHeader = []string{
+	"Timestamp", "Log Type", "Source IP", "Country", "Method", "Path",
+	"Status Code", "Response Size", "User Agent", "Referer",
+	"Processing Time", "Raw Log",
+}
+
+// entryCSVRow returns a function formatting entries as CSV rows, with the
+// country left empty without a GeoIP database
+func (r *Reporter) entryCSVRow() func(entry *models.LogEntry) []string {
+	country := func(string) string { return "" }
+	if r.locator != nil {
+		lookup := cachedLookup(r.locator)
+		country = func(ip string) string {
+			location, _ := lookup(ip)
+			return location.Country
+		}
+	}
+	return func(entry *models.LogEntry) []string {
+		return []string{
 			entry.Timestamp.Format("2006-01-02 15:04:05"),
 			entry.LogType,
 			entry.SourceIP,
+			country(entry.SourceIP),
 			entry.Method,
 			entry.Path,
 			fmt.Sprintf("%d", entry.StatusCode),
@@ -144,12 +246,80 @@ func (r *Reporter) GenerateCSVReport(data *ReportData, reportName string) (strin
 			fmt.Sprintf("%.3f", entry.ProcessingTime),
 			entry.RawLog,
 		}
-		if err := writer.Write(row); err != nil {
+	}
+}
+
+// GenerateLatencyCSVReport generates a CSV of the response time
+// percentiles of all requests and of the busiest paths
+func (r *Reporter) GenerateLatencyCSVReport(data *ReportData, reportName string) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_latency_%s.csv", reportName, timestamp)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"Path", "Requests", "P50", "P90", "P95", "P99"}); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	overall, paths := AnalyzeLatency(data.LogEntries, maxLatencyPaths)
+	// All requests come first, with an empty path
+	for _, row := range append([]LatencyPercentiles{overall}, paths...) {
+		record := []string{
+			row.Path,
+			fmt.Sprintf("%d", row.Requests),
+			fmt.Sprintf("%.3f", row.P50),
+			fmt.Sprintf("%.3f", row.P90),
+			fmt.Sprintf("%.3f", row.P95),
+			fmt.Sprintf("%.3f", row.P99),
+		}
+		if err := writer.Write(record); err != nil {
 			return "", fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
-	return filepath, nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save CSV file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
+
+// GenerateUserAgentCSVReport generates a CSV breaking requests down by
+// client type, browser, operating system, device, bot and user agent
+func (r *Reporter) GenerateUserAgentCSVReport(data *ReportData, reportName string) (string, error) {
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_useragents_%s.csv", reportName, timestamp)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"Category", "Name", "Requests", "Percentage"}); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, group := range userAgentGroups(AnalyzeUserAgents(data.LogEntries)) {
+		for _, summary := range group.summaries {
+			record := []string{
+				group.category,
+				summary.Name,
+				fmt.Sprintf("%d", summary.Count),
+				fmt.Sprintf("%.2f", summary.Percentage),
+			}
+			if err := writer.Write(record); err != nil {
+				return "", fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save CSV file: %w", err)
+	}
+	return r.store.Location(filename), nil
 }
 
 // GenerateSummaryReport generates a summary report with statistics
@@ -158,23 +328,10 @@ func (r *Reporter) GenerateSummaryReport(data *ReportData, reportName string) (s
 	r.prepareSummary(data)
 
 	// Generate filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	timestamp := runTimestamp(data)
 	filename := fmt.Sprintf("%s_summary_%s.html", reportName, timestamp)
-	filepath := filepath.Join(r.outputDir, filename)
-
-	// Create output file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create summary file: %w", err)
-	}
-	defer file.Close()
 
-	// Execute summary template
-	if err := r.templates.ExecuteTemplate(file, "summary.html", data); err != nil {
-		return "", fmt.Errorf("failed to execute summary template: %w", err)
-	}
-
-	return filepath, nil
+	return r.renderTemplate("summary.html", filename, data)
 }
 
 // prepareSummary prepares summary data for reports
@@ -205,6 +362,8 @@ func (r *Reporter) prepareSummary(data *ReportData) {
 	if timeCount > 0 {
 		data.Summary.AvgResponseTime = totalTime / float64(timeCount)
 	}
+	data.Summary.P95ResponseTime = percentileResponseTime(data.LogEntries, 95)
+	r.prepareLatencyPercentiles(data)
 
 	// Calculate error rate
 	var errorCount int64
@@ -227,6 +386,15 @@ func (r *Reporter) prepareSummary(data *ReportData) {
 	// Top IPs
 	data.Summary.TopIPs = r.getTopIPs(ipCounts, 10)
 
+	// User agents
+	data.Summary.UserAgents = AnalyzeUserAgents(data.LogEntries)
+
+	// Countries and cities
+	data.Summary.Geo = AnalyzeGeo(data.LogEntries, r.locator)
+
+	// Latency and errors per HTTP method
+	r.prepareMethodBreakdown(data)
+
 	// Status code breakdown
 	statusCounts := make(map[string]int64)
 	for _, entry := range data.LogEntries {
@@ -237,6 +405,24 @@ func (r *Reporter) prepareSummary(data *ReportData) {
 
 	// Hourly traffic
 	data.Summary.HourlyTraffic = r.getHourlyTraffic(data.LogEntries)
+
+	// Totals of every entry, where the entries stop at the report limit
+	r.applyTrafficTotals(data)
+
+	// Availability with and without planned maintenance
+	r.prepareAvailability(data)
+
+	// Message patterns for application logs
+	r.prepareMessagePatterns(data)
+
+	// Accepted and rejected traffic for network flow logs
+	r.prepareNetworkSummary(data)
+
+	// Paths over their latency budgets
+	r.prepareLatencyBudgets(data)
+
+	// Links from each row to the entries behind it
+	r.prepareDrillDown(data)
 }
 
 // getTopItems returns top N items by count
@@ -347,6 +533,20 @@ func (r *Reporter) GenerateCombinedReport(data *ReportData, reportName string) (
 	}
 	generatedFiles = append(generatedFiles, csvFile)
 
+	// Generate latency percentiles
+	latencyFile, err := r.GenerateLatencyCSVReport(data, reportName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate latency report: %w", err)
+	}
+	generatedFiles = append(generatedFiles, latencyFile)
+
+	// Generate user agent breakdown
+	userAgentFile, err := r.GenerateUserAgentCSVReport(data, reportName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate user agent report: %w", err)
+	}
+	generatedFiles = append(generatedFiles, userAgentFile)
+
 	// Generate summary report
 	summaryFile, err := r.GenerateSummaryReport(data, reportName)
 	if err != nil {
@@ -359,26 +559,38 @@ func (r *Reporter) GenerateCombinedReport(data *ReportData, reportName string) (
 
 // ExportToFile exports data to a specific format
 func (r *Reporter) ExportToFile(data interface{}, format, filename string) (string, error) {
-	filepath := filepath.Join(r.outputDir, filename)
-
 	switch strings.ToLower(format) {
 	case "csv":
-		return r.exportToCSV(data, filepath)
+		return r.exportToCSV(data, filename)
 	case "json":
-		return r.exportToJSON(data, filepath)
+		return r.exportToJSON(data, filename)
 	default:
 		return "", fmt.Errorf("unsupported export format: %s", format)
 	}
 }
 
-func (r *Reporter) exportToCSV(data interface{}, filepath string) (string, error) {
+func (r *Reporter) exportToCSV(data interface{}, filename string) (string, error) {
 	// Implementation depends on data structure
 	// This is a placeholder for CSV export logic
 	return "", fmt.Errorf("CSV export not implemented for this data type")
 }
 
-func (r *Reporter) exportToJSON(data interface{}, filepath string) (string, error) {
-	// Implementation depends on data structure
-	// This is a placeholder for JSON export logic
-	return "", fmt.Errorf("JSON export not implemented for this data type")
+// exportToJSON saves data as an indented JSON document. Report data is
+// exported as its JSON report.
+func (r *Reporter) exportToJSON(data interface{}, filename string) (string, error) {
+	if report, ok := data.(*ReportData); ok {
+		data = r.BuildJSONReport(report)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save JSON file: %w", err)
+	}
+	return r.store.Location(filename), nil
 }
@@ -0,0 +1,55 @@
+package reporting
+
+import (
+	"strconv"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// TrafficTotals are a report period's totals read from traffic rollups.
+// Unlike LogEntries, which stop at the report limit, they count every
+// entry of the period.
+type TrafficTotals struct {
+	Requests int64
+	Errors   int64
+	// StatusCodes are summed by status code, TopPaths by path and Hourly
+	// by hour
+	StatusCodes []models.TrafficRollup
+	TopPaths    []models.TrafficRollup
+	Hourly      []models.TrafficRollup
+}
+
+// applyTrafficTotals replaces the request counts, error rate, status codes,
+// top paths and hourly traffic worked out from the entries with the
+// rollups' totals. The other figures still come from the entries.
+func (r *Reporter) applyTrafficTotals(data *ReportData) {
+	totals := data.Traffic
+	if totals == nil {
+		return
+	}
+	summary := &data.Summary
+
+	summary.TotalRequests = totals.Requests
+	summary.ErrorRate = 0
+	if totals.Requests > 0 {
+		summary.ErrorRate = float64(totals.Errors) / float64(totals.Requests) * 100
+	}
+
+	summary.StatusCodeBreakdown = make(map[string]int64, len(totals.StatusCodes))
+	for _, rollup := range totals.StatusCodes {
+		summary.StatusCodeBreakdown[strconv.Itoa(rollup.StatusCode)] += rollup.Requests
+	}
+
+	pathCounts := make(map[string]int64, len(totals.TopPaths))
+	for _, rollup := range totals.TopPaths {
+		pathCounts[rollup.Path] += rollup.Requests
+	}
+	summary.TopPaths = r.getTopItems(pathCounts, 10)
+
+	for i := range summary.HourlyTraffic {
+		summary.HourlyTraffic[i].Count = 0
+	}
+	for _, rollup := range totals.Hourly {
+		summary.HourlyTraffic[rollup.Bucket.Hour()].Count += rollup.Requests
+	}
+}
@@ -0,0 +1,50 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficTotalsReplaceSampledCounts(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		// The entries stop at the report limit
+		LogEntries: []*models.LogEntry{
+			{Timestamp: base, Path: "/a", StatusCode: 200, SourceIP: "192.0.2.1"},
+			{Timestamp: base.Add(time.Minute), Path: "/a", StatusCode: 500, SourceIP: "192.0.2.2"},
+		},
+		Traffic: &TrafficTotals{
+			Requests: 40,
+			Errors:   4,
+			StatusCodes: []models.TrafficRollup{
+				{StatusCode: 200, Requests: 36},
+				{StatusCode: 500, Requests: 4},
+			},
+			TopPaths: []models.TrafficRollup{
+				{Path: "/b", Requests: 30},
+				{Path: "/a", Requests: 10},
+			},
+			Hourly: []models.TrafficRollup{
+				{Bucket: base.Add(-time.Hour), Requests: 25},
+				{Bucket: base, Requests: 15},
+			},
+		},
+	}
+
+	reporter := &Reporter{}
+	reporter.prepareSummary(data)
+
+	summary := data.Summary
+	assert.Equal(t, int64(40), summary.TotalRequests)
+	assert.InDelta(t, 10.0, summary.ErrorRate, 1e-9)
+	assert.Equal(t, map[string]int64{"200": 36, "500": 4}, summary.StatusCodeBreakdown)
+	assert.Equal(t, "/b", summary.TopPaths[0].Path)
+	assert.InDelta(t, 75.0, summary.TopPaths[0].Percentage, 1e-9)
+	assert.Equal(t, int64(25), summary.HourlyTraffic[9].Count)
+	assert.Equal(t, int64(15), summary.HourlyTraffic[10].Count)
+	// Figures rollups do not hold still come from the entries
+	assert.Equal(t, int64(2), summary.UniqueIPs)
+}
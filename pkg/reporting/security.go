@@ -0,0 +1,452 @@
+package reporting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/threat"
+)
+
+// Bounds of the security report's lists
+const (
+	maxSecurityRows    = 10
+	maxAttackIPs       = 5
+	maxAttackSamples   = 10
+	maxBruteForcePaths = 5
+)
+
+// SecurityOptions sets when failed logins count as password guessing
+type SecurityOptions struct {
+	// BruteForceThreshold is how many failed requests to authentication
+	// endpoints a client must make within BruteForceWindow to be flagged
+	BruteForceThreshold int64
+	BruteForceWindow    time.Duration
+}
+
+// DefaultSecurityOptions flags clients failing to log in 10 times within
+// 5 minutes
+func DefaultSecurityOptions() SecurityOptions {
+	return SecurityOptions{BruteForceThreshold: 10, BruteForceWindow: 5 * time.Minute}
+}
+
+// SecurityReportData contains the data for a security report
+type SecurityReportData struct {
+	Title       string
+	GeneratedAt time.Time
+	TimeRange   string
+	Options     SecurityOptions
+	Summary     *SecuritySummary
+}
+
+// SecuritySummary flags the suspicious activity in a period's requests
+type SecuritySummary struct {
+	Requests int64 `json:"requests"`
+	// SuspiciousRequests matched an attack signature or came from a
+	// scanner, and SuspiciousIPs made them
+	SuspiciousRequests int64 `json:"suspicious_requests"`
+	SuspiciousIPs      int64 `json:"suspicious_ips"`
+	// Attacks has an entry for each signature category, even when no
+	// request matched it
+	Attacks    []AttackSummary    `json:"attacks"`
+	Scanners   []ScannerSummary   `json:"scanners"`
+	BruteForce []BruteForceSource `json:"brute_force"`
+	// DeniedSources are the clients refused most often
+	DeniedSources []DeniedSource `json:"denied_sources"`
+}
+
+// AttackSummary is the requests matching one kind of attack signature
+type AttackSummary struct {
+	Category string `json:"category"`
+	Requests int64  `json:"requests"`
+	// Succeeded counts the attempts answered with a 2xx, which deserve a
+	// closer look
+	Succeeded int64         `json:"succeeded"`
+	TopIPs    []IPSummary   `json:"top_ips"`
+	Samples   []AttackEvent `json:"samples"`
+}
+
+// attackLabels name the signature categories for people
+var attackLabels = map[string]string{
+	threat.SQLInjection:  "SQL injection",
+	threat.XSS:           "Cross-site scripting",
+	threat.PathTraversal: "Path traversal",
+}
+
+// Label names the attack for people
+func (a AttackSummary) Label() string {
+	return attackLabels[a.Category]
+}
+
+// AttackEvent is a request matching an attack signature
+type AttackEvent struct {
+	Timestamp  time.Time `json:"timestamp"`
+	SourceIP   string    `json:"source_ip"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+}
+
+// ScannerSummary is the requests of one vulnerability scanner
+type ScannerSummary struct {
+	Scanner  string `json:"scanner"`
+	Requests int64  `json:"requests"`
+	// IPs are the busiest clients running the scanner, of IPCount
+	IPs       []string  `json:"ips"`
+	IPCount   int64     `json:"ip_count"`
+	Paths     int64     `json:"paths"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// BruteForceSource is a client failing to authenticate repeatedly
+type BruteForceSource struct {
+	IP string `json:"ip"`
+	// Failures counts its 401 and 403 responses from authentication
+	// endpoints, PeakFailures the most within the brute force window
+	Failures     int64 `json:"failures"`
+	PeakFailures int64 `json:"peak_failures"`
+	// Successes counts the client's requests to authentication endpoints
+	// that were not refused, a sign a guess may have worked
+	Successes int64     `json:"successes"`
+	Paths     []string  `json:"paths"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DeniedSource counts the requests of a client that were refused
+type DeniedSource struct {
+	IP           string `json:"ip"`
+	Denied       int64  `json:"denied"`
+	Unauthorized int64  `json:"unauthorized"`
+	Forbidden    int64  `json:"forbidden"`
+	RateLimited  int64  `json:"rate_limited"`
+	// Requests counts all of the client's requests
+	Requests int64 `json:"requests"`
+}
+
+type attackTally struct {
+	summary AttackSummary
+	ips     map[string]int64
+}
+
+type scannerTally struct {
+	summary ScannerSummary
+	ips     map[string]int64
+	paths   map[string]bool
+}
+
+type authTally struct {
+	source   BruteForceSource
+	failures []time.Time
+	paths    map[string]int64
+}
+
+// AnalyzeSecurity looks for attack signatures, scanners, password guessing
+// and refused clients in the entries
+func AnalyzeSecurity(entries []*models.LogEntry, opts SecurityOptions) *SecuritySummary {
+	summary := &SecuritySummary{Requests: int64(len(entries))}
+	attacks := make(map[string]*attackTally, len(threat.Categories))
+	for _, category := range threat.Categories {
+		attacks[category] = &attackTally{summary: AttackSummary{Category: category}, ips: make(map[string]int64)}
+	}
+	scanners := make(map[string]*scannerTally)
+	auth := make(map[string]*authTally)
+	denied := make(map[string]*DeniedSource)
+	requests := make(map[string]int64)
+	suspicious := make(map[string]bool)
+	// Paths repeat, so each is matched against the signatures once
+	signatures := make(map[string][]string)
+
+	for _, entry := range entries {
+		requests[entry.SourceIP]++
+
+		categories, ok := signatures[entry.Path]
+		if !ok {
+			categories = threat.Signatures(entry.Path)
+			signatures[entry.Path] = categories
+		}
+		for _, category := range categories {
+			tally := attacks[category]
+			tally.summary.Requests++
+			if entry.StatusCode >= 200 && entry.StatusCode < 300 {
+				tally.summary.Succeeded++
+			}
+			tally.ips[entry.SourceIP]++
+			tally.summary.Samples = latestAttacks(tally.summary.Samples, AttackEvent{
+				Timestamp:  entry.Timestamp,
+				SourceIP:   entry.SourceIP,
+				Method:     entry.Method,
+				Path:       entry.Path,
+				StatusCode: entry.StatusCode,
+			})
+		}
+
+		scanner, isScanner := threat.DetectScanner(entry.UserAgent)
+		if isScanner {
+			tally, ok := scanners[scanner]
+			if !ok {
+				tally = &scannerTally{
+					summary: ScannerSummary{Scanner: scanner, FirstSeen: entry.Timestamp, LastSeen: entry.Timestamp},
+					ips:     make(map[string]int64),
+					paths:   make(map[string]bool),
+				}
+				scanners[scanner] = tally
+			}
+			tally.summary.Requests++
+			tally.ips[entry.SourceIP]++
+			tally.paths[entry.Path] = true
+			if entry.Timestamp.Before(tally.summary.FirstSeen) {
+				tally.summary.FirstSeen = entry.Timestamp
+			}
+			if entry.Timestamp.After(tally.summary.LastSeen) {
+				tally.summary.LastSeen = entry.Timestamp
+			}
+		}
+
+		if len(categories) > 0 || isScanner {
+			summary.SuspiciousRequests++
+			suspicious[entry.SourceIP] = true
+		}
+
+		if threat.IsAuthPath(entry.Path) {
+			tally, ok := auth[entry.SourceIP]
+			if !ok {
+				tally = &authTally{source: BruteForceSource{IP: entry.SourceIP}, paths: make(map[string]int64)}
+				auth[entry.SourceIP] = tally
+			}
+			if entry.StatusCode == 401 || entry.StatusCode == 403 {
+				tally.failures = append(tally.failures, entry.Timestamp)
+				tally.paths[entry.Path]++
+			} else if entry.StatusCode < 400 {
+				tally.source.Successes++
+			}
+		}
+
+		if threat.Denied(entry.StatusCode) {
+			source, ok := denied[entry.SourceIP]
+			if !ok {
+				source = &DeniedSource{IP: entry.SourceIP}
+				denied[entry.SourceIP] = source
+			}
+			source.Denied++
+			switch entry.StatusCode {
+			case 401:
+				source.Unauthorized++
+			case 403:
+				source.Forbidden++
+			case 429:
+				source.RateLimited++
+			}
+		}
+	}
+	summary.SuspiciousIPs = int64(len(suspicious))
+
+	for _, category := range threat.Categories {
+		tally := attacks[category]
+		if tally.summary.Requests > 0 {
+			tally.summary.TopIPs = topIPs(tally.ips, tally.summary.Requests, maxAttackIPs)
+		}
+		summary.Attacks = append(summary.Attacks, tally.summary)
+	}
+
+	summary.Scanners = []ScannerSummary{}
+	for _, tally := range scanners {
+		scanner := tally.summary
+		scanner.IPCount = int64(len(tally.ips))
+		scanner.Paths = int64(len(tally.paths))
+		for _, ip := range topIPs(tally.ips, scanner.Requests, maxAttackIPs) {
+			scanner.IPs = append(scanner.IPs, ip.IP)
+		}
+		summary.Scanners = append(summary.Scanners, scanner)
+	}
+	sort.Slice(summary.Scanners, func(i, j int) bool {
+		if summary.Scanners[i].Requests != summary.Scanners[j].Requests {
+			return summary.Scanners[i].Requests > summary.Scanners[j].Requests
+		}
+		return summary.Scanners[i].Scanner < summary.Scanners[j].Scanner
+	})
+
+	summary.BruteForce = []BruteForceSource{}
+	for _, tally := range auth {
+		source := tally.source
+		source.Failures = int64(len(tally.failures))
+		source.PeakFailures = peakWithin(tally.failures, opts.BruteForceWindow)
+		if source.Failures == 0 || source.PeakFailures < opts.BruteForceThreshold {
+			continue
+		}
+		source.FirstSeen, source.LastSeen = tally.failures[0], tally.failures[len(tally.failures)-1]
+		for _, path := range topPaths(tally.paths, source.Failures, maxBruteForcePaths) {
+			source.Paths = append(source.Paths, path.Path)
+		}
+		summary.BruteForce = append(summary.BruteForce, source)
+	}
+	sort.Slice(summary.BruteForce, func(i, j int) bool {
+		a, b := summary.BruteForce[i], summary.BruteForce[j]
+		if a.PeakFailures != b.PeakFailures {
+			return a.PeakFailures > b.PeakFailures
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.IP < b.IP
+	})
+	if len(summary.BruteForce) > maxSecurityRows {
+		summary.BruteForce = summary.BruteForce[:maxSecurityRows]
+	}
+
+	summary.DeniedSources = []DeniedSource{}
+	for ip, source := range denied {
+		source.Requests = requests[ip]
+		summary.DeniedSources = append(summary.DeniedSources, *source)
+	}
+	sort.Slice(summary.DeniedSources, func(i, j int) bool {
+		a, b := summary.DeniedSources[i], summary.DeniedSources[j]
+		if a.Denied != b.Denied {
+			return a.Denied > b.Denied
+		}
+		return a.IP < b.IP
+	})
+	if len(summary.DeniedSources) > maxSecurityRows {
+		summary.DeniedSources = summary.DeniedSources[:maxSecurityRows]
+	}
+	return summary
+}
+
+// latestAttacks adds event to samples, keeping the maxAttackSamples most
+// recent events, most recent first
+func latestAttacks(samples []AttackEvent, event AttackEvent) []AttackEvent {
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].Timestamp.Before(event.Timestamp)
+	})
+	if i >= maxAttackSamples {
+		return samples
+	}
+	samples = append(samples, AttackEvent{})
+	copy(samples[i+1:], samples[i:])
+	samples[i] = event
+	if len(samples) > maxAttackSamples {
+		samples = samples[:maxAttackSamples]
+	}
+	return samples
+}
+
+// peakWithin sorts times and returns the most of them within any span of
+// window
+func peakWithin(times []time.Time, window time.Duration) int64 {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	var peak int64
+	start := 0
+	for end := range times {
+		for times[end].Sub(times[start]) >= window {
+			start++
+		}
+		peak = max(peak, int64(end-start+1))
+	}
+	return peak
+}
+
+// GenerateSecurityReport generates an HTML security report
+func (r *Reporter) GenerateSecurityReport(data *SecurityReportData, reportName string) (string, error) {
+	filename := fmt.Sprintf("%s_security_%s.html", reportName, securityTimestamp(data))
+
+	return r.renderTemplate("security.html", filename, data)
+}
+
+// GenerateSecurityPDFReport generates a security report as a PDF, with
+// the sections of the HTML report as plain tables
+func (r *Reporter) GenerateSecurityPDFReport(data *SecurityReportData, reportName string) (string, error) {
+	filename := fmt.Sprintf("%s_security_%s.pdf", reportName, securityTimestamp(data))
+
+	if err := r.put(filename, r.securityPDF(data)); err != nil {
+		return "", fmt.Errorf("failed to save PDF file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
+
+func securityTimestamp(data *SecurityReportData) string {
+	at := data.GeneratedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return at.Format(runTimestampFormat)
+}
+
+// securityPDF lays out a security report as a PDF
+func (r *Reporter) securityPDF(data *SecurityReportData) []byte {
+	summary := data.Summary
+	l := r.locale
+	formatCount := func(n int64) string { return l.Number(n) }
+	doc := r.newPDF()
+	doc.title(data.Title + " - Security Report")
+	doc.text("Generated on " + l.Long(data.GeneratedAt))
+	if data.TimeRange != "" {
+		doc.text(data.TimeRange)
+	}
+	doc.text(fmt.Sprintf("%s suspicious requests from %s clients out of %s requests.",
+		formatCount(summary.SuspiciousRequests), formatCount(summary.SuspiciousIPs), formatCount(summary.Requests)))
+
+	doc.heading("Attack Signatures")
+	var rows [][]string
+	for _, attack := range summary.Attacks {
+		rows = append(rows, []string{attack.Label(), formatCount(attack.Requests), formatCount(attack.Succeeded)})
+	}
+	doc.table([]string{"Signature", "Requests", "Answered 2xx"}, []int{30, 10, 12}, rows)
+	for _, attack := range summary.Attacks {
+		if len(attack.Samples) == 0 {
+			continue
+		}
+		doc.space()
+		doc.text("Latest " + attack.Label() + " attempts:")
+		rows = nil
+		for _, event := range attack.Samples {
+			rows = append(rows, []string{l.Timestamp(event.Timestamp), event.SourceIP,
+				strconv.Itoa(event.StatusCode), event.Method + " " + event.Path})
+		}
+		doc.table([]string{"Time", "Client", "Status", "Request"}, []int{19, 15, 6, 64}, rows)
+	}
+
+	doc.heading("Scanners")
+	if len(summary.Scanners) == 0 {
+		doc.text("No requests came from known vulnerability scanners.")
+	} else {
+		rows = nil
+		for _, scanner := range summary.Scanners {
+			rows = append(rows, []string{scanner.Scanner, formatCount(scanner.Requests), formatCount(scanner.Paths),
+				formatCount(scanner.IPCount), l.DateTime(scanner.FirstSeen), l.DateTime(scanner.LastSeen)})
+		}
+		doc.table([]string{"Scanner", "Requests", "Paths", "Clients", "First Seen", "Last Seen"}, []int{20, 10, 8, 8, 16, 16}, rows)
+	}
+
+	doc.heading("Brute Force")
+	doc.text(fmt.Sprintf("Clients failing to authenticate %d times within %s.", data.Options.BruteForceThreshold, data.Options.BruteForceWindow))
+	if len(summary.BruteForce) > 0 {
+		rows = nil
+		for _, source := range summary.BruteForce {
+			path := ""
+			if len(source.Paths) > 0 {
+				path = source.Paths[0]
+			}
+			rows = append(rows, []string{source.IP, formatCount(source.Failures), formatCount(source.PeakFailures), formatCount(source.Successes),
+				l.DateTime(source.LastSeen), path})
+		}
+		doc.table([]string{"Client", "Failures", "Peak", "Accepted", "Last Seen", "Endpoint"}, []int{15, 8, 6, 8, 16, 44}, rows)
+	} else {
+		doc.text("No client did.")
+	}
+
+	doc.heading("Top Denied Clients")
+	if len(summary.DeniedSources) == 0 {
+		doc.text("No requests were refused.")
+	} else {
+		rows = nil
+		for _, source := range summary.DeniedSources {
+			rows = append(rows, []string{source.IP, formatCount(source.Denied), formatCount(source.Unauthorized), formatCount(source.Forbidden),
+				formatCount(source.RateLimited), formatCount(source.Requests)})
+		}
+		doc.table([]string{"Client", "Denied", "401", "403", "429", "Requests"}, []int{39, 10, 10, 10, 10, 10}, rows)
+	}
+	return doc.bytes()
+}
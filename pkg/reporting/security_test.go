@@ -0,0 +1,149 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/threat"
+)
+
+// securityFixture has a sqlmap scan, a password guessing client, a client
+// guessing too slowly to be flagged and ordinary traffic
+func securityFixture() []*models.LogEntry {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []*models.LogEntry
+	add := func(at time.Duration, ip, method, path string, status int, userAgent string) {
+		entries = append(entries, &models.LogEntry{
+			Timestamp: base.Add(at), SourceIP: ip, Method: method, Path: path, StatusCode: status, UserAgent: userAgent,
+		})
+	}
+
+	for i := 0; i < 5; i++ {
+		add(time.Duration(i)*time.Second, "198.51.100.7", "GET", fmt.Sprintf("/item?id=%d%%20UNION%%20SELECT%%20password%%20FROM%%20users", i), 500, "sqlmap/1.7.2#stable")
+	}
+	add(10*time.Second, "198.51.100.7", "GET", "/item?id=1'%20OR%20'1'='1", 200, "sqlmap/1.7.2#stable")
+	add(20*time.Second, "198.51.100.8", "GET", "/static/../../etc/passwd", 400, "curl/8.4.0")
+	// 12 failures within two minutes, then a login that went through
+	for i := 0; i < 12; i++ {
+		add(time.Minute+time.Duration(i)*10*time.Second, "203.0.113.5", "POST", "/login", 401, "python-requests/2.31")
+	}
+	add(5*time.Minute, "203.0.113.5", "POST", "/login", 302, "python-requests/2.31")
+	// 12 failures an hour apart
+	for i := 0; i < 12; i++ {
+		add(time.Duration(i)*time.Hour, "203.0.113.6", "POST", "/login", 401, "Mozilla/5.0")
+	}
+	for i := 0; i < 20; i++ {
+		add(time.Duration(i)*time.Minute, "192.0.2.1", "GET", "/", 200, "Mozilla/5.0")
+	}
+	add(time.Minute, "192.0.2.2", "GET", "/admin", 403, "Mozilla/5.0")
+	add(2*time.Minute, "192.0.2.2", "GET", "/api", 429, "Mozilla/5.0")
+	return entries
+}
+
+func TestAnalyzeSecurity(t *testing.T) {
+	summary := AnalyzeSecurity(securityFixture(), DefaultSecurityOptions())
+
+	assert.Equal(t, int64(54), summary.Requests)
+	assert.Equal(t, int64(7), summary.SuspiciousRequests)
+	assert.Equal(t, int64(2), summary.SuspiciousIPs)
+
+	require.Len(t, summary.Attacks, 3)
+	sqli := summary.Attacks[0]
+	assert.Equal(t, threat.SQLInjection, sqli.Category)
+	assert.Equal(t, "SQL injection", sqli.Label())
+	assert.Equal(t, int64(6), sqli.Requests)
+	assert.Equal(t, int64(1), sqli.Succeeded)
+	assert.Equal(t, []IPSummary{{IP: "198.51.100.7", Count: 6, Percentage: 100}}, sqli.TopIPs)
+	require.Len(t, sqli.Samples, 6)
+	assert.Equal(t, "/item?id=1'%20OR%20'1'='1", sqli.Samples[0].Path, "most recent first")
+	assert.Zero(t, summary.Attacks[1].Requests)
+	assert.Equal(t, int64(1), summary.Attacks[2].Requests)
+
+	require.Len(t, summary.Scanners, 1)
+	assert.Equal(t, "sqlmap", summary.Scanners[0].Scanner)
+	assert.Equal(t, int64(6), summary.Scanners[0].Requests)
+	assert.Equal(t, []string{"198.51.100.7"}, summary.Scanners[0].IPs)
+
+	require.Len(t, summary.BruteForce, 1, "failures an hour apart are not flagged")
+	source := summary.BruteForce[0]
+	assert.Equal(t, "203.0.113.5", source.IP)
+	assert.Equal(t, int64(12), source.Failures)
+	assert.Equal(t, int64(12), source.PeakFailures)
+	assert.Equal(t, int64(1), source.Successes)
+	assert.Equal(t, []string{"/login"}, source.Paths)
+
+	// A shorter window splits the burst
+	opts := DefaultSecurityOptions()
+	opts.BruteForceWindow = time.Minute
+	assert.Empty(t, AnalyzeSecurity(securityFixture(), opts).BruteForce)
+
+	require.Len(t, summary.DeniedSources, 3)
+	assert.Equal(t, DeniedSource{IP: "203.0.113.5", Denied: 12, Unauthorized: 12, Requests: 13}, summary.DeniedSources[0])
+	assert.Equal(t, DeniedSource{IP: "192.0.2.2", Denied: 2, Forbidden: 1, RateLimited: 1, Requests: 2}, summary.DeniedSources[2])
+}
+
+func TestSecurityReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	data := &SecurityReportData{
+		Title:       "Weekly",
+		GeneratedAt: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+		Options:     DefaultSecurityOptions(),
+		Summary:     AnalyzeSecurity(securityFixture(), DefaultSecurityOptions()),
+	}
+
+	location, err := reporter.GenerateSecurityReport(data, "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "weekly_security_2024-03-02_09-00-00.html", filepath.Base(location))
+	html, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Latest SQL injection Attempts")
+	assert.Contains(t, string(html), "sqlmap")
+	assert.Contains(t, string(html), "203.0.113.5")
+
+	location, err = reporter.GenerateSecurityPDFReport(data, "weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "weekly_security_2024-03-02_09-00-00.pdf", filepath.Base(location))
+	pdf, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "(Weekly - Security Report) Tj")
+	assert.Contains(t, string(pdf), `GET /item?id=1'%20OR%20'1'='1`)
+}
+
+func TestPDFDocument(t *testing.T) {
+	doc := newPDFDocument()
+	doc.title("Café (test) \\ ✓")
+	for i := 0; i < 100; i++ {
+		doc.text(fmt.Sprintf("line %d", i))
+	}
+	pdf := string(doc.bytes())
+
+	assert.Contains(t, pdf, `(Caf\351 \(test\) \\ ?) Tj`)
+	assert.Contains(t, pdf, "/Count 2", "100 lines take two pages")
+
+	// Each cross-reference offset points at its object
+	xref := pdf[strings.LastIndex(pdf, "\nxref\n")+1:]
+	lines := strings.Split(xref, "\n")[3:]
+	for i := 1; i <= 8; i++ {
+		offset, err := strconv.Atoi(lines[i-1][:10])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", i)), "object %d", i)
+	}
+
+	assert.Equal(t, "abc  de...", pdfRow([]string{"abc", "defghij"}, []int{4, 5}))
+}
@@ -0,0 +1,76 @@
+package reporting
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+func TestParseTemplate(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"errors", "standard"}, TemplateReportTypes())
+
+	// The built-in templates are valid custom templates of their type
+	for reportType, file := range builtinTemplates {
+		content, err := os.ReadFile("../../web/templates/" + file)
+		require.NoError(t, err)
+		_, err = reporter.ParseTemplate(reportType, "copy", string(content))
+		assert.NoError(t, err, reportType)
+	}
+
+	_, err = reporter.ParseTemplate("standard", "branded", `<h1>{{.Title}}</h1>{{range .Summary.TopPaths}}{{.Path}}{{end}}`)
+	assert.NoError(t, err)
+	_, err = reporter.ParseTemplate("errors", "branded", `{{.Summary.Errors}}`)
+	assert.NoError(t, err)
+
+	_, err = reporter.ParseTemplate("standard", "broken", `<h1>{{.Title}</h1>`)
+	assert.ErrorContains(t, err, "bad character")
+	_, err = reporter.ParseTemplate("standard", "typo", `{{.Summary.TotalRequest}}`)
+	assert.ErrorContains(t, err, "TotalRequest", "fields the report lacks are refused")
+	_, err = reporter.ParseTemplate("errors", "wrong-type", `{{.LogEntries}}`)
+	assert.ErrorContains(t, err, "LogEntries", "error reports get error report data")
+	_, err = reporter.ParseTemplate("security", "branded", `<h1>{{.Title}}</h1>`)
+	assert.ErrorContains(t, err, `report type "security" has no templates`)
+}
+
+func TestGenerateReportWithTemplate(t *testing.T) {
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(t.TempDir()))
+	require.NoError(t, err)
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	data := &ReportData{Title: "Daily", GeneratedAt: base, LogEntries: []*models.LogEntry{
+		{Timestamp: base, SourceIP: "192.0.2.1", Path: "/", StatusCode: 200},
+		{Timestamp: base, SourceIP: "192.0.2.2", Path: "/checkout", StatusCode: 503},
+	}}
+
+	data.Template, err = reporter.ParseTemplate("standard", "branded", `<h1>Acme: {{.Title}}</h1><p>{{.Summary.TotalRequests}} requests</p>`)
+	require.NoError(t, err)
+	location, err := reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	html, err := os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>Acme: Daily</h1><p>2 requests</p>", string(html))
+
+	data.Template, err = reporter.ParseTemplate("errors", "branded", `<h1>Acme errors: {{.Summary.Errors}}</h1>`)
+	require.NoError(t, err)
+	location, err = reporter.GenerateErrorReport(data, "daily")
+	require.NoError(t, err)
+	html, err = os.ReadFile(location)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>Acme errors: 1</h1>", string(html))
+
+	// Without a template the built-in one is used
+	data.Template = nil
+	location, err = reporter.GenerateHTMLReport(data, "daily")
+	require.NoError(t, err)
+	html, err = os.ReadFile(location)
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "Acme")
+	assert.Contains(t, string(html), "Daily")
+}
@@ -0,0 +1,121 @@
+package reporting
+
+import (
+	"sort"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/useragent"
+)
+
+// maxUserAgents bounds how many rows each user agent breakdown lists
+const maxUserAgents = 10
+
+// UserAgentSummary counts the requests of a user agent, or of a browser,
+// operating system, kind of client or bot, with their share of all
+// requests
+type UserAgentSummary struct {
+	Name       string
+	Count      int64
+	Percentage float64
+}
+
+// UserAgentBreakdown summarizes who made a report's requests
+type UserAgentBreakdown struct {
+	// TopUserAgents are the most common user agents as logged
+	TopUserAgents []UserAgentSummary
+	// Kinds counts browsers, bots, tools and unknown clients
+	Kinds []UserAgentSummary
+	// Browsers, OperatingSystems and Devices break down browser requests
+	Browsers         []UserAgentSummary
+	OperatingSystems []UserAgentSummary
+	Devices          []UserAgentSummary
+	// Bots are the busiest crawlers and scripted clients
+	Bots []UserAgentSummary
+	// AutomatedRequests counts the requests of bots and tools, and
+	// AutomatedShare is their percentage of all requests
+	AutomatedRequests int64
+	AutomatedShare    float64
+}
+
+// AnalyzeUserAgents breaks the entries' requests down by user agent
+func AnalyzeUserAgents(entries []*models.LogEntry) UserAgentBreakdown {
+	raw := make(map[string]int64)
+	for _, entry := range entries {
+		raw[entry.UserAgent]++
+	}
+
+	kinds := make(map[string]int64)
+	browsers := make(map[string]int64)
+	systems := make(map[string]int64)
+	devices := make(map[string]int64)
+	bots := make(map[string]int64)
+	var breakdown UserAgentBreakdown
+	// Each distinct user agent is parsed once
+	for userAgent, count := range raw {
+		agent := useragent.Parse(userAgent)
+		kinds[agent.Kind] += count
+		switch agent.Kind {
+		case useragent.KindBrowser:
+			browsers[agent.Name] += count
+			systems[agent.OS] += count
+			devices[agent.Device] += count
+		case useragent.KindBot, useragent.KindTool:
+			bots[agent.Name] += count
+			breakdown.AutomatedRequests += count
+		}
+	}
+
+	total := int64(len(entries))
+	delete(raw, "")
+	breakdown.TopUserAgents = topUserAgents(raw, total, maxUserAgents)
+	breakdown.Kinds = topUserAgents(kinds, total, len(kinds))
+	breakdown.Browsers = topUserAgents(browsers, total, maxUserAgents)
+	breakdown.OperatingSystems = topUserAgents(systems, total, maxUserAgents)
+	breakdown.Devices = topUserAgents(devices, total, len(devices))
+	breakdown.Bots = topUserAgents(bots, total, maxUserAgents)
+	if total > 0 {
+		breakdown.AutomatedShare = float64(breakdown.AutomatedRequests) / float64(total) * 100
+	}
+	return breakdown
+}
+
+// topUserAgents returns the n largest counts, busiest first, with their
+// share of total
+func topUserAgents(counts map[string]int64, total int64, n int) []UserAgentSummary {
+	summaries := make([]UserAgentSummary, 0, len(counts))
+	for name, count := range counts {
+		summaries = append(summaries, UserAgentSummary{
+			Name:       name,
+			Count:      count,
+			Percentage: float64(count) / float64(total) * 100,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	if len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries
+}
+
+// userAgentGroup is a breakdown's rows of one category, as the CSV and
+// XLSX reports list them
+type userAgentGroup struct {
+	category  string
+	summaries []UserAgentSummary
+}
+
+func userAgentGroups(breakdown UserAgentBreakdown) []userAgentGroup {
+	return []userAgentGroup{
+		{"Client Type", breakdown.Kinds},
+		{"Browser", breakdown.Browsers},
+		{"Operating System", breakdown.OperatingSystems},
+		{"Device", breakdown.Devices},
+		{"Bot", breakdown.Bots},
+		{"User Agent", breakdown.TopUserAgents},
+	}
+}
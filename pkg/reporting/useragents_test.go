@@ -0,0 +1,98 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+const (
+	chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	safariIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1"
+	googlebot     = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+// userAgentFixture has 6 Chrome, 2 Safari, 1 Googlebot, 1 curl and 2
+// requests without a user agent
+func userAgentFixture() []*models.LogEntry {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []*models.LogEntry
+	add := func(userAgent string, n int) {
+		for i := 0; i < n; i++ {
+			entries = append(entries, &models.LogEntry{Timestamp: base, Path: "/", StatusCode: 200, UserAgent: userAgent})
+		}
+	}
+	add(chromeWindows, 6)
+	add(safariIPhone, 2)
+	add(googlebot, 1)
+	add("curl/8.4.0", 1)
+	add("", 2)
+	return entries
+}
+
+func TestAnalyzeUserAgents(t *testing.T) {
+	breakdown := AnalyzeUserAgents(userAgentFixture())
+
+	require.Len(t, breakdown.Kinds, 4)
+	for i, want := range []UserAgentSummary{
+		{Name: "browser", Count: 8, Percentage: 800.0 / 12},
+		{Name: "unknown", Count: 2, Percentage: 200.0 / 12},
+		{Name: "bot", Count: 1, Percentage: 100.0 / 12},
+		{Name: "tool", Count: 1, Percentage: 100.0 / 12},
+	} {
+		assert.Equal(t, want.Name, breakdown.Kinds[i].Name)
+		assert.Equal(t, want.Count, breakdown.Kinds[i].Count)
+		assert.InDelta(t, want.Percentage, breakdown.Kinds[i].Percentage, 1e-9)
+	}
+	assert.Equal(t, "Chrome", breakdown.Browsers[0].Name)
+	assert.Equal(t, int64(6), breakdown.Browsers[0].Count)
+	assert.Equal(t, "Windows", breakdown.OperatingSystems[0].Name)
+	assert.Equal(t, "iOS", breakdown.OperatingSystems[1].Name)
+	assert.Equal(t, []string{"desktop", "mobile"}, []string{breakdown.Devices[0].Name, breakdown.Devices[1].Name})
+	assert.Equal(t, []string{"Googlebot", "curl"}, []string{breakdown.Bots[0].Name, breakdown.Bots[1].Name})
+	assert.Equal(t, int64(2), breakdown.AutomatedRequests)
+	assert.InDelta(t, 200.0/12, breakdown.AutomatedShare, 1e-9)
+
+	require.Len(t, breakdown.TopUserAgents, 4, "requests without a user agent are left out")
+	assert.Equal(t, chromeWindows, breakdown.TopUserAgents[0].Name)
+
+	breakdown = AnalyzeUserAgents(nil)
+	assert.Empty(t, breakdown.Kinds)
+	assert.Zero(t, breakdown.AutomatedShare)
+}
+
+func TestReportUserAgents(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	data := &ReportData{Title: "Daily", GeneratedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), LogEntries: userAgentFixture()}
+	summary, err := reporter.GenerateSummaryReport(data, "daily")
+	require.NoError(t, err)
+	html, err := os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "2 requests (16.7%) came from bots and scripted tools")
+	assert.Contains(t, string(html), "Googlebot")
+
+	location, err := reporter.GenerateUserAgentCSVReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_useragents_2024-03-01_10-00-00.csv", filepath.Base(location))
+	content, err := os.ReadFile(filepath.Join(dir, filepath.Base(location)))
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Category", "Name", "Requests", "Percentage"}, records[0])
+	assert.Equal(t, []string{"Client Type", "browser", "8", "66.67"}, records[1])
+	assert.Contains(t, records, []string{"Bot", "curl", "1", "8.33"})
+	assert.Contains(t, records, []string{"Device", "mobile", "2", "16.67"})
+	assert.Equal(t, []string{"User Agent", chromeWindows, "6", "50.00"}, records[len(records)-4])
+}
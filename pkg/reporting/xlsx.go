@@ -0,0 +1,340 @@
+package reporting
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCellText is the longest text an Excel cell holds
+const maxCellText = 32767
+
+// Cell styles, indexes into the cellXfs of xlsxStyles
+const (
+	styleDefault = iota
+	styleHeader
+	styleDateTime
+	styleDecimal
+)
+
+// decimal is a number shown with two decimal places
+type decimal float64
+
+// sheet is a worksheet of an XLSX workbook. Its header row stays in view
+// while scrolling and filters its columns.
+type sheet struct {
+	name   string
+	header []string
+	// widths are the column widths in characters
+	widths []float64
+	// rows hold strings, ints, int64s, float64s, decimals and time.Times
+	rows [][]interface{}
+}
+
+// GenerateXLSXReport generates an Excel workbook with a sheet each for the
+// entries, top paths, top IPs, status codes, hourly traffic and user
+// agents. Numbers and timestamps are stored as such, so they sort and
+// pivot without being converted first.
+func (r *Reporter) GenerateXLSXReport(data *ReportData, reportName string) (string, error) {
+	r.prepareSummary(data)
+
+	timestamp := runTimestamp(data)
+	filename := fmt.Sprintf("%s_%s.xlsx", reportName, timestamp)
+
+	var buf bytes.Buffer
+	if err := writeWorkbook(&buf, reportSheets(data), data.GeneratedAt); err != nil {
+		return "", fmt.Errorf("failed to write XLSX file: %w", err)
+	}
+	if err := r.put(filename, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to save XLSX file: %w", err)
+	}
+	return r.store.Location(filename), nil
+}
+
+// reportSheets lays out a prepared report as worksheets
+func reportSheets(data *ReportData) []sheet {
+	entries := sheet{
+		name: "Entries",
+		header: []string{"Timestamp", "Log Type", "Source IP", "Method", "Path", "Status Code",
+			"Response Size", "User Agent", "Referer", "Processing Time", "Raw Log"},
+		widths: []float64{20, 10, 16, 8, 40, 12, 14, 40, 30, 16, 80},
+	}
+	for _, entry := range data.LogEntries {
+		entries.rows = append(entries.rows, []interface{}{
+			entry.Timestamp, entry.LogType, entry.SourceIP, entry.Method, entry.Path, entry.StatusCode,
+			entry.ResponseSize, entry.UserAgent, entry.Referer, entry.ProcessingTime, entry.RawLog,
+		})
+	}
+
+	paths := sheet{name: "Top Paths", header: []string{"Path", "Requests", "Percentage"}, widths: []float64{60, 12, 12}}
+	for _, path := range data.Summary.TopPaths {
+		paths.rows = append(paths.rows, []interface{}{path.Path, path.Count, decimal(path.Percentage)})
+	}
+
+	ips := sheet{name: "Top IPs", header: []string{"IP", "Requests", "Percentage"}, widths: []float64{40, 12, 12}}
+	for _, ip := range data.Summary.TopIPs {
+		ips.rows = append(ips.rows, []interface{}{ip.IP, ip.Count, decimal(ip.Percentage)})
+	}
+
+	statuses := sheet{name: "Status Codes", header: []string{"Status Code", "Requests"}, widths: []float64{12, 12}}
+	codes := make([]string, 0, len(data.Summary.StatusCodeBreakdown))
+	for code := range data.Summary.StatusCodeBreakdown {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		var value interface{} = code
+		if n, err := strconv.Atoi(code); err == nil {
+			value = n
+		}
+		statuses.rows = append(statuses.rows, []interface{}{value, data.Summary.StatusCodeBreakdown[code]})
+	}
+
+	hourly := sheet{
+		name:   "Hourly Traffic",
+		header: []string{"Hour", "Requests", "Maintenance Requests"},
+		widths: []float64{8, 12, 22},
+	}
+	for _, hour := range data.Summary.HourlyTraffic {
+		hourly.rows = append(hourly.rows, []interface{}{hour.Hour, hour.Count, hour.MaintenanceCount})
+	}
+
+	userAgents := sheet{
+		name:   "User Agents",
+		header: []string{"Category", "Name", "Requests", "Percentage"},
+		widths: []float64{18, 60, 12, 12},
+	}
+	for _, group := range userAgentGroups(data.Summary.UserAgents) {
+		for _, summary := range group.summaries {
+			userAgents.rows = append(userAgents.rows, []interface{}{group.category, summary.Name, summary.Count, decimal(summary.Percentage)})
+		}
+	}
+
+	return []sheet{entries, paths, ips, statuses, hourly, userAgents}
+}
+
+// xlsxPart is a file of a workbook's ZIP package
+type xlsxPart struct {
+	name  string
+	write func(io.Writer) error
+}
+
+// writeWorkbook writes sheets as an Office Open XML workbook, its parts
+// dated modified
+func writeWorkbook(w io.Writer, sheets []sheet, modified time.Time) error {
+	parts := []xlsxPart{
+		{"[Content_Types].xml", func(w io.Writer) error { return writeContentTypes(w, len(sheets)) }},
+		{"_rels/.rels", writeString(xlsxRootRels)},
+		{"xl/workbook.xml", func(w io.Writer) error { return writeWorkbookPart(w, sheets) }},
+		{"xl/_rels/workbook.xml.rels", func(w io.Writer) error { return writeWorkbookRels(w, len(sheets)) }},
+		{"xl/styles.xml", writeString(xlsxStyles)},
+	}
+	for i := range sheets {
+		parts = append(parts, xlsxPart{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheets[i].write})
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		out, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if err := part.write(out); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+const xlsxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const xlsxRootRels = xlsxHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell styles: the default, bold headers, date and
+// time, and two decimal places
+const xlsxStyles = xlsxHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+func writeContentTypes(w io.Writer, sheets int) error {
+	var b strings.Builder
+	b.WriteString(xlsxHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeWorkbookPart(w io.Writer, sheets []sheet) error {
+	var b strings.Builder
+	b.WriteString(xlsxHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(s.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets>`)
+	// The filter of each header row is a defined name Excel expects
+	b.WriteString(`<definedNames>`)
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">'%s'!%s</definedName>`,
+			i, escapeXML(strings.ReplaceAll(s.name, "'", "''")), s.filterRange(true))
+	}
+	b.WriteString(`</definedNames></workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeWorkbookRels(w io.Writer, sheets int) error {
+	var b strings.Builder
+	b.WriteString(xlsxHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// filterRange is the range of the header and rows, with absolute
+// references as a defined name needs
+func (s *sheet) filterRange(absolute bool) string {
+	column, row := columnName(len(s.header)-1), strconv.Itoa(len(s.rows)+1)
+	if absolute {
+		return "$A$1:$" + column + "$" + row
+	}
+	return "A1:" + column + row
+}
+
+// write writes the worksheet part
+func (s *sheet) write(w io.Writer) error {
+	b := bytes.NewBufferString(xlsxHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`<selection pane="bottomLeft" activeCell="A2" sqref="A2"/></sheetView></sheetViews>`)
+	b.WriteString(`<cols>`)
+	for i, width := range s.widths {
+		fmt.Fprintf(b, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+
+	header := make([]interface{}, len(s.header))
+	for i, title := range s.header {
+		header[i] = title
+	}
+	writeRow(b, 1, header, styleHeader)
+	for i, row := range s.rows {
+		writeRow(b, i+2, row, styleDefault)
+		// Rows of large reports are flushed as they are written
+		if b.Len() > 1<<16 {
+			if _, err := w.Write(b.Bytes()); err != nil {
+				return err
+			}
+			b.Reset()
+		}
+	}
+
+	fmt.Fprintf(b, `</sheetData><autoFilter ref="%s"/></worksheet>`, s.filterRange(false))
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeRow(b *bytes.Buffer, number int, values []interface{}, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, number)
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(number)
+		switch v := value.(type) {
+		case int:
+			writeNumber(b, ref, style, strconv.Itoa(v))
+		case int64:
+			writeNumber(b, ref, style, strconv.FormatInt(v, 10))
+		case float64:
+			writeNumber(b, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		case decimal:
+			writeNumber(b, ref, styleDecimal, strconv.FormatFloat(float64(v), 'f', -1, 64))
+		case time.Time:
+			if v.IsZero() {
+				continue
+			}
+			writeNumber(b, ref, styleDateTime, strconv.FormatFloat(excelTime(v), 'f', -1, 64))
+		default:
+			text := fmt.Sprint(v)
+			if text == "" {
+				continue
+			}
+			if len(text) > maxCellText {
+				text = strings.ToValidUTF8(text[:maxCellText], "")
+			}
+			fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escapeXML(text))
+		}
+	}
+	b.WriteString(`</row>`)
+}
+
+func writeNumber(b *bytes.Buffer, ref string, style int, value string) {
+	fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, value)
+}
+
+// excelEpoch is day 0 of Excel's date serial numbers, as the 1900 date
+// system counts them from March 1900 on
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelTime is the date serial number of the wall clock time of t, the
+// time the CSV report shows
+func excelTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return float64(wall.Sub(excelEpoch)) / float64(24*time.Hour)
+}
+
+// columnName is the letters of the column at a 0-based index: A, B, ...,
+// Z, AA
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// escapeXML escapes text for XML, replacing characters XML cannot hold
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package reporting
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/reportstore"
+)
+
+// readWorkbook returns the parts of a workbook by name, checking each is
+// well-formed XML
+func readWorkbook(t *testing.T, path string) map[string]string {
+	archive, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer archive.Close()
+
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		in, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(in)
+		in.Close()
+		require.NoError(t, err)
+
+		decoder := xml.NewDecoder(strings.NewReader(string(content)))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, file.Name)
+		}
+		parts[file.Name] = string(content)
+	}
+	return parts
+}
+
+func TestXLSXReport(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("../../web/templates", reportstore.NewLocal(dir))
+	require.NoError(t, err)
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	data := &ReportData{
+		Title:       "Daily",
+		GeneratedAt: at,
+		LogEntries: []*models.LogEntry{
+			{Timestamp: at, LogType: "nginx", SourceIP: "192.0.2.1", Method: "GET", Path: "/a?x=1&y=<2>",
+				StatusCode: 500, ResponseSize: 1024, ProcessingTime: 0.25, RawLog: "bell\x07 \"quoted\""},
+			{Timestamp: at.Add(time.Hour), LogType: "nginx", SourceIP: "192.0.2.2", Method: "GET", Path: "/b", StatusCode: 200},
+		},
+	}
+
+	location, err := reporter.GenerateXLSXReport(data, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-03-01_12-00-00.xlsx", filepath.Base(location))
+	parts := readWorkbook(t, filepath.Join(dir, "daily_2024-03-01_12-00-00.xlsx"))
+
+	for _, name := range []string{"Entries", "Top Paths", "Top IPs", "Status Codes", "Hourly Traffic", "User Agents"} {
+		assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="`+name+`"`)
+	}
+
+	entries := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, entries, `state="frozen"`)
+	assert.Contains(t, entries, `<autoFilter ref="A1:K3"/>`)
+	// 2024-03-01 12:00 is day 45352 and a half
+	assert.Contains(t, entries, `<c r="A2" s="2"><v>45352.5</v></c>`)
+	assert.Contains(t, entries, `<c r="F2" s="0"><v>500</v></c>`)
+	assert.Contains(t, entries, `<c r="G2" s="0"><v>1024</v></c>`)
+	assert.Contains(t, entries, `<c r="J2" s="0"><v>0.25</v></c>`)
+	assert.Contains(t, entries, `/a?x=1&amp;y=&lt;2&gt;`)
+	assert.Contains(t, entries, "bell�")
+
+	statuses := parts["xl/worksheets/sheet4.xml"]
+	assert.Contains(t, statuses, `<row r="2"><c r="A2" s="0"><v>200</v></c><c r="B2" s="0"><v>1</v></c></row>`)
+	paths := parts["xl/worksheets/sheet2.xml"]
+	assert.Contains(t, paths, `<c r="C2" s="3"><v>50</v></c>`)
+	hourly := parts["xl/worksheets/sheet5.xml"]
+	assert.Contains(t, hourly, `<c r="A14" s="0"><v>12</v></c><c r="B14" s="0"><v>1</v></c>`)
+	userAgents := parts["xl/worksheets/sheet6.xml"]
+	assert.Contains(t, userAgents, `<autoFilter ref="A1:D2"/>`, "the entries have no user agent")
+}
+
+func TestColumnName(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 10: "K", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, columnName(index))
+	}
+}
@@ -0,0 +1,201 @@
+package reportstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+// Azure keeps reports in an Azure Blob Storage container under a name
+// prefix, authorizing requests with the storage account's shared key
+type Azure struct {
+	container *container.Client
+	prefix    string
+	now       func() time.Time
+}
+
+// NewAzure returns a store of the reports under prefix in a container.
+// accountKey is the base64 shared key. endpoint replaces
+// https://<account>.blob.core.windows.net, such as for Azurite.
+func NewAzure(account, accountKey, containerName, prefix, endpoint string) (*Azure, error) {
+	credential, err := container.NewSharedKeyCredential(account, accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure account key: %w", err)
+	}
+	if endpoint == "" {
+		endpoint = "https://" + account + ".blob.core.windows.net"
+	}
+	containerURL := strings.TrimSuffix(endpoint, "/") + "/" + containerName
+	client, err := container.NewClientWithSharedKeyCredential(containerURL, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure endpoint: %w", err)
+	}
+	return &Azure{container: client, prefix: strings.Trim(prefix, "/"), now: time.Now}, nil
+}
+
+// azureError marks errors for missing blobs and containers, so they match
+// os.ErrNotExist
+func azureError(err error) error {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	}
+	return err
+}
+
+func (a *Azure) blobName(name string) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	return path.Join(a.prefix, name), nil
+}
+
+// Put uploads the report as a block blob
+func (a *Azure) Put(name string, data []byte) error {
+	return a.PutReader(name, bytes.NewReader(data), int64(len(data)))
+}
+
+// PutReader uploads the report from r as a block blob, in blocks when it
+// is large
+func (a *Azure) PutReader(name string, r io.ReadSeeker, size int64) error {
+	blobName, err := a.blobName(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, err = a.container.NewBlockBlobClient(blobName).UploadStream(ctx, io.LimitReader(r, size), &blockblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: to.Ptr(ContentType(name))},
+	})
+	return azureError(err)
+}
+
+// Archive uploads the files in order, after checking that nothing is
+// stored under the directory. Callers put an integrity manifest last, so
+// an interrupted upload is recognisable by its absence.
+func (a *Azure) Archive(dir string, files []File) error {
+	existing, err := a.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%s: %w", dir, os.ErrExist)
+	}
+	for _, file := range files {
+		if err := validName(file.Name); err != nil || strings.Contains(file.Name, "/") {
+			return fmt.Errorf("invalid archive file name %q", file.Name)
+		}
+		if err := a.Put(path.Join(dir, file.Name), file.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open downloads the report
+func (a *Azure) Open(name string) (io.ReadCloser, Object, error) {
+	blobName, err := a.blobName(name)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	resp, err := a.container.NewBlobClient(blobName).DownloadStream(ctx, nil)
+	if err != nil {
+		cancel()
+		return nil, Object{}, azureError(err)
+	}
+	object := Object{Name: path.Base(blobName), Size: deref(resp.ContentLength), ModTime: deref(resp.LastModified)}
+	return cancelCloser{resp.Body, cancel}, object, nil
+}
+
+// Stat returns the report's size and modification time
+func (a *Azure) Stat(name string) (Object, error) {
+	blobName, err := a.blobName(name)
+	if err != nil {
+		return Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	props, err := a.container.NewBlobClient(blobName).GetProperties(ctx, nil)
+	if err != nil {
+		return Object{}, azureError(err)
+	}
+	return Object{Name: path.Base(blobName), Size: deref(props.ContentLength), ModTime: deref(props.LastModified)}, nil
+}
+
+// List lists the blobs under the directory's prefix, with "/" as the
+// delimiter
+func (a *Azure) List(dir string) ([]Object, error) {
+	prefix := a.prefix
+	if dir != "" {
+		var err error
+		if prefix, err = a.blobName(dir); err != nil {
+			return nil, err
+		}
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	objects := []Object{}
+	pages := a.container.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{Prefix: to.Ptr(prefix)})
+	for pages.More() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, azureError(err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			object := Object{Name: path.Base(deref(item.Name))}
+			if item.Properties != nil {
+				object.Size = deref(item.Properties.ContentLength)
+				object.ModTime = deref(item.Properties.LastModified)
+			}
+			objects = append(objects, object)
+		}
+		for _, p := range page.Segment.BlobPrefixes {
+			objects = append(objects, Object{Name: path.Base(deref(p.Name)), Dir: true})
+		}
+	}
+	return objects, nil
+}
+
+// SignedURL returns the blob's URL with a read-only service SAS token
+func (a *Azure) SignedURL(name string, expires time.Duration) (string, error) {
+	blobName, err := a.blobName(name)
+	if err != nil {
+		return "", err
+	}
+	return a.container.NewBlobClient(blobName).GetSASURL(sas.BlobPermissions{Read: true}, a.now().Add(expires), nil)
+}
+
+// Location returns the blob's URL
+func (a *Azure) Location(name string) string {
+	blobPath := &url.URL{Path: path.Join(a.prefix, name)}
+	return a.container.URL() + "/" + blobPath.EscapedPath()
+}
+
+// deref returns the value of an optional response field, or its zero value
+func deref[T any](p *T) T {
+	var value T
+	if p != nil {
+		value = *p
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package reportstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/s3"
+)
+
+// requestTimeout bounds each request to object storage
+const requestTimeout = 2 * time.Minute
+
+// Bucket keeps reports in an S3 bucket, or a bucket of another store that
+// speaks the S3 API such as MinIO, under a key prefix
+type Bucket struct {
+	client *s3.Client
+	scheme string // for locations, such as s3 or gs
+	bucket string
+	prefix string
+}
+
+// NewBucket returns a store of the reports under prefix in a bucket
+func NewBucket(client *s3.Client, scheme, bucket, prefix string) *Bucket {
+	return &Bucket{client: client, scheme: scheme, bucket: bucket, prefix: strings.Trim(prefix, "/")}
+}
+
+func (b *Bucket) key(name string) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	return path.Join(b.prefix, name), nil
+}
+
+// Put uploads the report
+func (b *Bucket) Put(name string, data []byte) error {
+	key, err := b.key(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return b.client.PutObject(ctx, b.bucket, key, data, ContentType(name))
+}
+
+// PutReader uploads the report from r
+func (b *Bucket) PutReader(name string, r io.ReadSeeker, size int64) error {
+	key, err := b.key(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return b.client.PutObjectReader(ctx, b.bucket, key, r, size, ContentType(name))
+}
+
+// Archive uploads the files in order, after checking that nothing is
+// stored under the directory. Callers put an integrity manifest last, so
+// an interrupted upload is recognisable by its absence.
+func (b *Bucket) Archive(dir string, files []File) error {
+	existing, err := b.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%s: %w", dir, os.ErrExist)
+	}
+	for _, file := range files {
+		if err := validName(file.Name); err != nil || strings.Contains(file.Name, "/") {
+			return fmt.Errorf("invalid archive file name %q", file.Name)
+		}
+		if err := b.Put(path.Join(dir, file.Name), file.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open downloads the report
+func (b *Bucket) Open(name string) (io.ReadCloser, Object, error) {
+	key, err := b.key(name)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	body, object, err := b.client.OpenObject(ctx, b.bucket, key)
+	if err != nil {
+		cancel()
+		return nil, Object{}, err
+	}
+	return cancelCloser{body, cancel}, bucketObject(object), nil
+}
+
+// Stat returns the report's size and modification time
+func (b *Bucket) Stat(name string) (Object, error) {
+	key, err := b.key(name)
+	if err != nil {
+		return Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	object, err := b.client.HeadObject(ctx, b.bucket, key)
+	if err != nil {
+		return Object{}, err
+	}
+	return bucketObject(object), nil
+}
+
+// List lists the keys under the directory's prefix
+func (b *Bucket) List(dir string) ([]Object, error) {
+	prefix := b.prefix
+	if dir != "" {
+		var err error
+		if prefix, err = b.key(dir); err != nil {
+			return nil, err
+		}
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	objects, prefixes, err := b.client.ListDirectory(ctx, b.bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]Object, 0, len(objects)+len(prefixes))
+	for _, object := range objects {
+		listed = append(listed, bucketObject(object))
+	}
+	for _, p := range prefixes {
+		listed = append(listed, Object{Name: path.Base(p), Dir: true})
+	}
+	return listed, nil
+}
+
+// SignedURL presigns a download of the report
+func (b *Bucket) SignedURL(name string, expires time.Duration) (string, error) {
+	key, err := b.key(name)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return b.client.PresignGetObject(ctx, b.bucket, key, expires)
+}
+
+// Location returns the report's URI, such as s3://bucket/prefix/name
+func (b *Bucket) Location(name string) string {
+	return b.scheme + "://" + b.bucket + "/" + path.Join(b.prefix, name)
+}
+
+func bucketObject(object s3.Object) Object {
+	return Object{Name: path.Base(object.Key), Size: object.Size, ModTime: object.LastModified}
+}
+
+// cancelCloser releases a request's context once its body is closed
+type cancelCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,172 @@
+package reportstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCS keeps reports in a Google Cloud Storage bucket under a name prefix
+type GCS struct {
+	client *storage.Client
+	bucket string
+	prefix string
+	now    func() time.Time
+}
+
+// NewGCS returns a store of the reports under prefix in a bucket
+func NewGCS(client *storage.Client, bucket, prefix string) *GCS {
+	return &GCS{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/"), now: time.Now}
+}
+
+// gcsError marks errors for missing objects and buckets, so they match
+// os.ErrNotExist
+func gcsError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+		return fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	}
+	return err
+}
+
+func (g *GCS) object(name string) (*storage.ObjectHandle, error) {
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+	return g.client.Bucket(g.bucket).Object(path.Join(g.prefix, name)), nil
+}
+
+// Put uploads the report
+func (g *GCS) Put(name string, data []byte) error {
+	return g.PutReader(name, bytes.NewReader(data), int64(len(data)))
+}
+
+// PutReader uploads the report from r, in chunks when it is large
+func (g *GCS) PutReader(name string, r io.ReadSeeker, size int64) error {
+	object, err := g.object(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	w := object.NewWriter(ctx)
+	w.ContentType = ContentType(name)
+	if _, err := io.Copy(w, io.LimitReader(r, size)); err != nil {
+		w.Close()
+		return err
+	}
+	return gcsError(w.Close())
+}
+
+// Archive uploads the files in order, after checking that nothing is
+// stored under the directory. Callers put an integrity manifest last, so
+// an interrupted upload is recognisable by its absence.
+func (g *GCS) Archive(dir string, files []File) error {
+	existing, err := g.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%s: %w", dir, os.ErrExist)
+	}
+	for _, file := range files {
+		if err := validName(file.Name); err != nil || strings.Contains(file.Name, "/") {
+			return fmt.Errorf("invalid archive file name %q", file.Name)
+		}
+		if err := g.Put(path.Join(dir, file.Name), file.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open downloads the report
+func (g *GCS) Open(name string) (io.ReadCloser, Object, error) {
+	object, err := g.object(name)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	r, err := object.NewReader(ctx)
+	if err != nil {
+		cancel()
+		return nil, Object{}, gcsError(err)
+	}
+	return cancelCloser{r, cancel}, Object{Name: path.Base(name), Size: r.Attrs.Size, ModTime: r.Attrs.LastModified}, nil
+}
+
+// Stat returns the report's size and modification time
+func (g *GCS) Stat(name string) (Object, error) {
+	object, err := g.object(name)
+	if err != nil {
+		return Object{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	attrs, err := object.Attrs(ctx)
+	if err != nil {
+		return Object{}, gcsError(err)
+	}
+	return Object{Name: path.Base(name), Size: attrs.Size, ModTime: attrs.Updated}, nil
+}
+
+// List lists the objects under the directory's prefix, with "/" as the
+// delimiter
+func (g *GCS) List(dir string) ([]Object, error) {
+	prefix := g.prefix
+	if dir != "" {
+		if err := validName(dir); err != nil {
+			return nil, err
+		}
+		prefix = path.Join(g.prefix, dir)
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	objects := []Object{}
+	it := g.client.Bucket(g.bucket).Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, gcsError(err)
+		}
+		if attrs.Prefix != "" {
+			objects = append(objects, Object{Name: path.Base(attrs.Prefix), Dir: true})
+		} else if !strings.HasSuffix(attrs.Name, "/") {
+			objects = append(objects, Object{Name: path.Base(attrs.Name), Size: attrs.Size, ModTime: attrs.Updated})
+		}
+	}
+}
+
+// SignedURL returns a V4 signed URL downloading the report. The client's
+// service account signs it, with its key or through the IAM API.
+func (g *GCS) SignedURL(name string, expires time.Duration) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	return g.client.Bucket(g.bucket).SignedURL(path.Join(g.prefix, name), &storage.SignedURLOptions{
+		Method:  http.MethodGet,
+		Expires: g.now().Add(expires),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+// Location returns the report's URI, such as gs://bucket/prefix/name
+func (g *GCS) Location(name string) string {
+	return "gs://" + g.bucket + "/" + path.Join(g.prefix, name)
+}
@@ -0,0 +1,185 @@
+package reportstore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local keeps reports in a directory
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a store of the reports in dir
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+func (l *Local) path(name string) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(name)), nil
+}
+
+// Put writes the report to a temporary file and renames it into place, so
+// it is never served half written
+func (l *Local) Put(name string, data []byte) error {
+	return l.PutReader(name, bytes.NewReader(data), int64(len(data)))
+}
+
+// PutReader copies the report to a temporary file and renames it into
+// place as Put does
+func (l *Local) PutReader(name string, r io.ReadSeeker, size int64) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, io.LimitReader(r, size)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Archive builds the directory under a temporary name and renames it into
+// place, so a failed run leaves nothing behind. The files and directory
+// are made read-only.
+func (l *Local) Archive(dir string, files []File) error {
+	path, err := l.path(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s: %w", dir, os.ErrExist)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		os.Chmod(tmp, 0755)
+		os.RemoveAll(tmp)
+	}()
+
+	for _, file := range files {
+		if err := validName(file.Name); err != nil || strings.Contains(file.Name, "/") {
+			return fmt.Errorf("invalid archive file name %q", file.Name)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, file.Name), file.Data, 0444); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(tmp, 0555); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Open opens the report file
+func (l *Local) Open(name string) (io.ReadCloser, Object, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, Object{}, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, Object{}, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return file, fileObject(info), nil
+}
+
+// Stat returns the report file's size and modification time
+func (l *Local) Stat(name string) (Object, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Object{}, err
+	}
+	if info.IsDir() {
+		return Object{}, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return fileObject(info), nil
+}
+
+// List reads the directory, leaving out temporary files
+func (l *Local) List(dir string) ([]Object, error) {
+	path := l.dir
+	if dir != "" {
+		var err error
+		if path, err = l.path(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return []Object{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		objects = append(objects, fileObject(info))
+	}
+	return objects, nil
+}
+
+// SignedURL is not supported; local reports are served by the server
+func (l *Local) SignedURL(name string, expires time.Duration) (string, error) {
+	return "", ErrNoSignedURLs
+}
+
+// Location returns the report's file path
+func (l *Local) Location(name string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(name))
+}
+
+func fileObject(info os.FileInfo) Object {
+	return Object{Name: info.Name(), Size: info.Size(), ModTime: info.ModTime(), Dir: info.IsDir()}
+}
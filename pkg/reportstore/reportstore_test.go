@@ -0,0 +1,438 @@
+package reportstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/s3"
+)
+
+// testStore exercises the behavior every store shares
+func testStore(t *testing.T, store Store) {
+	require.NoError(t, store.Put("daily_2024-01-15.html", []byte("<html>daily</html>")))
+	require.NoError(t, store.Put("daily_2024-01-15.html", []byte("<html>replaced</html>")))
+
+	body, info, err := store.Open("daily_2024-01-15.html")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "<html>replaced</html>", string(data))
+	assert.Equal(t, int64(len(data)), info.Size)
+
+	info, err = store.Stat("daily_2024-01-15.html")
+	require.NoError(t, err)
+	assert.Equal(t, "daily_2024-01-15.html", info.Name)
+	assert.Equal(t, int64(len(data)), info.Size)
+
+	_, err = store.Stat("missing.html")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, _, err = store.Open("missing.html")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	files := []File{{Name: "report.json", Data: []byte("{}")}, {Name: "MANIFEST", Data: []byte("sum  report.json\n")}}
+	require.NoError(t, store.Archive("compliance/2023-10", files))
+	assert.ErrorIs(t, store.Archive("compliance/2023-10", files), os.ErrExist)
+
+	listed, err := store.List("")
+	require.NoError(t, err)
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Name < listed[j].Name })
+	require.Len(t, listed, 2)
+	assert.Equal(t, Object{Name: "compliance", Dir: true}, Object{Name: listed[0].Name, Dir: listed[0].Dir})
+	assert.Equal(t, "daily_2024-01-15.html", listed[1].Name)
+	assert.False(t, listed[1].Dir)
+
+	listed, err = store.List("compliance")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "2023-10", listed[0].Name)
+	assert.True(t, listed[0].Dir)
+
+	listed, err = store.List("compliance/2023-10")
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	listed, err = store.List("weekly")
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	// PutReader reads size bytes, so a spooled file need not be in memory
+	content := strings.Repeat("2024-01-15 00:00:00,/index.html\n", 1000)
+	require.NoError(t, store.PutReader("weekly/entries.csv", strings.NewReader(content+"ignored"), int64(len(content))))
+	body, info, err = store.Open("weekly/entries.csv")
+	require.NoError(t, err)
+	data, err = io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, int64(len(content)), info.Size)
+	require.NoError(t, store.PutReader("weekly/empty.csv", strings.NewReader(""), 0))
+	info, err = store.Stat("weekly/empty.csv")
+	require.NoError(t, err)
+	assert.Zero(t, info.Size)
+
+	for _, invalid := range []string{"", "../etc/passwd", "/etc/passwd", "a/../../b", "a//b"} {
+		assert.Error(t, store.Put(invalid, nil), invalid)
+		assert.Error(t, store.PutReader(invalid, strings.NewReader(""), 0), invalid)
+		_, _, err := store.Open(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				os.Chmod(path, 0755)
+			}
+			return nil
+		})
+	})
+
+	store := NewLocal(dir)
+	testStore(t, store)
+
+	assert.Equal(t, filepath.Join(dir, "compliance", "2023-10", "MANIFEST"), store.Location("compliance/2023-10/MANIFEST"))
+	info, err := os.Stat(filepath.Join(dir, "compliance", "2023-10", "report.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0444), info.Mode().Perm(), "archived files are read-only")
+
+	_, err = store.SignedURL("daily_2024-01-15.html", time.Minute)
+	assert.ErrorIs(t, err, ErrNoSignedURLs)
+}
+
+// objectServer is an in-memory bucket answering S3 and Azure Blob requests
+// for the calls the stores make
+type objectServer struct {
+	t       *testing.T
+	azure   bool
+	mu      sync.Mutex
+	objects map[string][]byte // by path below the bucket
+	headers []http.Header
+}
+
+func (o *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.headers = append(o.headers, r.Header.Clone())
+
+	bucket := "/reports"
+	if o.azure {
+		bucket = "/devstoreaccount1/reports"
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, bucket)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+	modified := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		o.list(w, r.URL.Query().Get("prefix"), modified)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		require.NoError(o.t, err)
+		o.objects[key] = data
+		if o.azure {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := o.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (o *objectServer) list(w http.ResponseWriter, prefix string, modified time.Time) {
+	var keys []string
+	prefixes := map[string]bool{}
+	for key := range o.objects {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if dir, _, nested := strings.Cut(rest, "/"); nested {
+			prefixes[prefix+dir+"/"] = true
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if o.azure {
+		b.WriteString("<EnumerationResults><Blobs>")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "<Blob><Name>%s</Name><Properties><Last-Modified>%s</Last-Modified><Content-Length>%d</Content-Length></Properties></Blob>",
+				key, modified.Format(http.TimeFormat), len(o.objects[key]))
+		}
+		for p := range prefixes {
+			fmt.Fprintf(&b, "<BlobPrefix><Name>%s</Name></BlobPrefix>", p)
+		}
+		b.WriteString("</Blobs><NextMarker/></EnumerationResults>")
+	} else {
+		b.WriteString("<ListBucketResult><IsTruncated>false</IsTruncated>")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>%s</LastModified></Contents>",
+				key, len(o.objects[key]), modified.Format(time.RFC3339))
+		}
+		for p := range prefixes {
+			fmt.Fprintf(&b, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", p)
+		}
+		b.WriteString("</ListBucketResult>")
+	}
+	io.WriteString(w, b.String())
+}
+
+func TestBucket(t *testing.T) {
+	backend := &objectServer{t: t, objects: map[string][]byte{"other/x.html": []byte("outside the prefix")}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	client, err := s3.NewClient(context.Background(), "us-east-1", awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, server.URL)
+	require.NoError(t, err)
+	store := NewBucket(client, "s3", "reports", "/prod/")
+	testStore(t, store)
+
+	assert.Contains(t, backend.objects, "prod/daily_2024-01-15.html")
+	assert.Contains(t, backend.objects, "prod/compliance/2023-10/MANIFEST")
+	assert.Equal(t, "s3://reports/prod/compliance/2023-10", store.Location("compliance/2023-10"))
+	for _, header := range backend.headers {
+		assert.True(t, strings.HasPrefix(header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	}
+
+	signed, err := store.SignedURL("daily_2024-01-15.html", 15*time.Minute)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/prod/daily_2024-01-15.html", u.Path)
+	assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+}
+
+func TestAzure(t *testing.T) {
+	backend := &objectServer{t: t, azure: true, objects: map[string][]byte{}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	key := base64.StdEncoding.EncodeToString([]byte("account key"))
+	store, err := NewAzure("devstoreaccount1", key, "reports", "", server.URL+"/devstoreaccount1")
+	require.NoError(t, err)
+	testStore(t, store)
+
+	for _, header := range backend.headers {
+		assert.True(t, strings.HasPrefix(header.Get("Authorization"), "SharedKey devstoreaccount1:"))
+	}
+	assert.Equal(t, server.URL+"/devstoreaccount1/reports/compliance/2023-10", store.Location("compliance/2023-10"))
+
+	store.now = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) }
+	signed, err := store.SignedURL("daily_2024-01-15.html", 15*time.Minute)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/devstoreaccount1/reports/daily_2024-01-15.html", u.Path)
+	assert.Equal(t, "2024-01-15T12:15:00Z", u.Query().Get("se"))
+	assert.Equal(t, "r", u.Query().Get("sp"))
+	assert.NotEmpty(t, u.Query().Get("sig"))
+
+	_, err = NewAzure("account", "not base64!", "reports", "", "")
+	assert.Error(t, err)
+}
+
+// gcsServer is an in-memory Cloud Storage bucket answering the JSON API
+// calls and XML API downloads the GCS store makes, and issuing tokens
+type gcsServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	tokens  []string
+}
+
+func (g *gcsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	modified := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	resource := func(name string) map[string]string {
+		return map[string]string{"bucket": "reports", "name": name, "size": fmt.Sprint(len(g.objects[name])), "updated": modified.Format(time.RFC3339)}
+	}
+	if r.URL.Path != "/token" {
+		g.tokens = append(g.tokens, r.Header.Get("Authorization"))
+	}
+
+	switch {
+	case r.URL.Path == "/token":
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "gcs-token", "token_type": "Bearer", "expires_in": 3600})
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/reports/o":
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(g.t, err)
+		parts := multipart.NewReader(r.Body, params["boundary"])
+		var metadata struct{ Name string }
+		part, err := parts.NextPart()
+		require.NoError(g.t, err)
+		require.NoError(g.t, json.NewDecoder(part).Decode(&metadata))
+		part, err = parts.NextPart()
+		require.NoError(g.t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(g.t, err)
+		g.objects[metadata.Name] = data
+		json.NewEncoder(w).Encode(resource(metadata.Name))
+	case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/reports/o":
+		prefix := r.URL.Query().Get("prefix")
+		var items []map[string]string
+		prefixes := map[string]bool{}
+		for name := range g.objects {
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				continue
+			}
+			if dir, _, nested := strings.Cut(rest, "/"); nested {
+				prefixes[prefix+dir+"/"] = true
+				continue
+			}
+			items = append(items, resource(name))
+		}
+		listed := []string{}
+		for p := range prefixes {
+			listed = append(listed, p)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items, "prefixes": listed})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/reports/o/"):
+		name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/reports/o/")
+		if _, ok := g.objects[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": 404, "message": "No such object"}})
+			return
+		}
+		json.NewEncoder(w).Encode(resource(name))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/reports/"):
+		data, ok := g.objects[strings.TrimPrefix(r.URL.Path, "/reports/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGCS(t *testing.T) {
+	backend := &gcsServer{t: t, objects: map[string][]byte{}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	// A service account key whose tokens come from the fake server
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "reports@project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":      server.URL + "/token",
+	})
+	require.NoError(t, err)
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(credentialsFile, credentials, 0o600))
+
+	store, err := New(config.ReportStorageConfig{Type: "gcs", Bucket: "reports", Prefix: "prod", CredentialsFile: credentialsFile, Endpoint: server.URL + "/storage/v1/"})
+	require.NoError(t, err)
+	testStore(t, store)
+
+	assert.Contains(t, backend.objects, "prod/compliance/2023-10/MANIFEST")
+	assert.Equal(t, "gs://reports/prod/compliance/2023-10", store.Location("compliance/2023-10"))
+	for _, token := range backend.tokens {
+		assert.Equal(t, "Bearer gcs-token", token)
+	}
+
+	signed, err := store.SignedURL("daily_2024-01-15.html", 15*time.Minute)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/prod/daily_2024-01-15.html", u.Path)
+	expires, err := strconv.Atoi(u.Query().Get("X-Goog-Expires"))
+	require.NoError(t, err)
+	assert.InDelta(t, 900, expires, 1)
+	assert.True(t, strings.HasPrefix(u.Query().Get("X-Goog-Credential"), "reports@project.iam.gserviceaccount.com/"))
+}
+
+func TestSeeker(t *testing.T) {
+	backend := &objectServer{t: t, objects: map[string][]byte{}}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	client, err := s3.NewClient(context.Background(), "us-east-1", awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, server.URL)
+	require.NoError(t, err)
+	store := NewBucket(client, "s3", "reports", "")
+	require.NoError(t, store.Put("big.csv", []byte("0123456789")))
+
+	body, info, err := store.Open("big.csv")
+	require.NoError(t, err)
+	content := NewSeeker(store, "big.csv", body, info.Size)
+	defer content.Close()
+
+	read := func(offset int64, whence int, n int) string {
+		_, err := content.Seek(offset, whence)
+		require.NoError(t, err)
+		buf := make([]byte, n)
+		n, err = io.ReadFull(content, buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+	end, err := content.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), end)
+	assert.Equal(t, "678", read(6, io.SeekStart, 3), "seeking forward skips")
+	assert.Equal(t, "9", read(0, io.SeekCurrent, 1))
+	assert.Equal(t, "234", read(2, io.SeekStart, 3), "seeking back opens the report again")
+
+	_, err = content.Seek(10, io.SeekStart)
+	require.NoError(t, err)
+	n, err := content.Read(make([]byte, 1))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.EOF, err)
+
+	file, err := os.Open("reportstore_test.go")
+	require.NoError(t, err)
+	assert.Same(t, file, NewSeeker(store, "x", file, 0), "seekable bodies are used as they are")
+	file.Close()
+}
@@ -0,0 +1,78 @@
+package reportstore
+
+import (
+	"errors"
+	"io"
+)
+
+// seeker makes a report streamed from object storage seekable, so range
+// requests can be served from it. Seeking only moves the offset; the next
+// read skips forward through the stream to it, or opens the report again
+// to go back.
+type seeker struct {
+	store  Store
+	name   string
+	body   io.ReadCloser
+	size   int64
+	read   int64 // bytes of body consumed
+	offset int64 // where the next read starts
+}
+
+// NewSeeker wraps a report opened from store, whose size is known, as an
+// io.ReadSeekCloser. Bodies that can seek already are returned as they are.
+func NewSeeker(store Store, name string, body io.ReadCloser, size int64) io.ReadSeekCloser {
+	if rsc, ok := body.(io.ReadSeekCloser); ok {
+		return rsc
+	}
+	return &seeker{store: store, name: name, body: body, size: size}
+}
+
+// Seek implements io.Seeker
+func (s *seeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	s.offset = offset
+	return offset, nil
+}
+
+// Read implements io.Reader
+func (s *seeker) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+	if s.offset < s.read {
+		body, _, err := s.store.Open(s.name)
+		if err != nil {
+			return 0, err
+		}
+		s.body.Close()
+		s.body, s.read = body, 0
+	}
+	if s.offset > s.read {
+		skipped, err := io.CopyN(io.Discard, s.body, s.offset-s.read)
+		s.read += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.body.Read(p)
+	s.read += int64(n)
+	s.offset += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (s *seeker) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,132 @@
+// Package reportstore keeps generated reports on local disk or in object
+// storage, through the AWS, Google Cloud and Azure SDKs. With a bucket every replica of the server lists and serves the
+// same reports without a shared volume, and downloads can be redirected to
+// signed URLs.
+package reportstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/awsauth"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/ingest/s3"
+	"google.golang.org/api/option"
+)
+
+// ErrNoSignedURLs is returned by stores that cannot sign download URLs
+var ErrNoSignedURLs = errors.New("store does not support signed URLs")
+
+// Object is a stored report, or with Dir a directory of them
+type Object struct {
+	Name    string // relative to the listed directory
+	Size    int64
+	ModTime time.Time
+	Dir     bool
+}
+
+// File is a report file to archive
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Store keeps reports by slash-separated name, such as
+// "daily_2024-01-15_00-00-00.html" or "compliance/2023-10/MANIFEST".
+// Missing reports are reported with errors matching os.ErrNotExist.
+type Store interface {
+	// Put writes a report, replacing any with the same name
+	Put(name string, data []byte) error
+	// PutReader writes a report of size bytes read from r as Put does,
+	// without holding it in memory. r may be read more than once.
+	PutReader(name string, r io.ReadSeeker, size int64) error
+	// Archive writes files into a new directory, returning an error
+	// matching os.ErrExist if the directory already exists
+	Archive(dir string, files []File) error
+	// Open opens a report for reading
+	Open(name string) (io.ReadCloser, Object, error)
+	// Stat returns a report's size and modification time
+	Stat(name string) (Object, error)
+	// List returns the reports and directories directly in a directory,
+	// "" for the top level. A missing directory is empty.
+	List(dir string) ([]Object, error)
+	// SignedURL returns a URL that downloads a report without credentials
+	// until expires has passed
+	SignedURL(name string, expires time.Duration) (string, error)
+	// Location describes where a report is kept, such as its path or URL
+	Location(name string) string
+}
+
+// New creates the store the configuration selects
+func New(cfg config.ReportStorageConfig) (Store, error) {
+	switch cfg.Type {
+	case "", "local":
+		return NewLocal(cfg.Dir), nil
+	case "s3":
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		client, err := s3.NewClient(context.Background(), region, bucketCredentials(cfg), cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return NewBucket(client, "s3", cfg.Bucket, cfg.Prefix), nil
+	case "gcs":
+		var options []option.ClientOption
+		if cfg.CredentialsFile != "" {
+			options = append(options, option.WithCredentialsFile(cfg.CredentialsFile))
+		}
+		if cfg.Endpoint != "" {
+			options = append(options, option.WithEndpoint(cfg.Endpoint))
+		}
+		client, err := storage.NewClient(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+		return NewGCS(client, cfg.Bucket, cfg.Prefix), nil
+	case "azure":
+		return NewAzure(cfg.Account, cfg.AccountKey, cfg.Bucket, cfg.Prefix, cfg.Endpoint)
+	default:
+		return nil, fmt.Errorf("unsupported reports storage type: %s", cfg.Type)
+	}
+}
+
+func bucketCredentials(cfg config.ReportStorageConfig) awsauth.Credentials {
+	return awsauth.Credentials{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+	}
+}
+
+// validName rejects names that are empty, absolute or that leave the store
+func validName(name string) error {
+	if name == "" || path.IsAbs(name) || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid report name %q", name)
+	}
+	return nil
+}
+
+// Go's own table lacks the types of some report formats, and the
+// system's may too
+func init() {
+	mime.AddExtensionType(".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	mime.AddExtensionType(".ndjson", "application/x-ndjson")
+	mime.AddExtensionType(".md", "text/markdown; charset=utf-8")
+}
+
+// ContentType guesses a report's media type from its extension
+func ContentType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,86 @@
+// Package retention decides how long log entries are kept. Every log type
+// is kept for the default number of days unless a policy gives it its own,
+// such as 30 days of nginx access logs but a year of security-relevant
+// syslog. Policies set at runtime replace the configured ones.
+package retention
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Source tells where the retention of a log type comes from
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+// Rule is the retention of one log type
+type Rule struct {
+	LogType   string     `json:"log_type"`
+	Days      int        `json:"days"`
+	Source    string     `json:"source"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Policy is the retention of every log type
+type Policy struct {
+	DefaultDays int    `json:"default_days"`
+	Rules       []Rule `json:"policies"`
+}
+
+// Resolve combines the configured retention with the stored policies,
+// which take precedence. Rules are ordered by log type.
+func Resolve(defaultDays int, configured map[string]int, stored []*models.RetentionPolicy) *Policy {
+	rules := make(map[string]Rule, len(configured)+len(stored))
+	for logType, days := range configured {
+		rules[logType] = Rule{LogType: logType, Days: days, Source: SourceConfig}
+	}
+	for _, policy := range stored {
+		updatedAt := policy.UpdatedAt
+		rules[policy.LogType] = Rule{LogType: policy.LogType, Days: policy.Days, Source: SourceAPI,
+			UpdatedBy: policy.UpdatedBy, UpdatedAt: &updatedAt}
+	}
+
+	p := &Policy{DefaultDays: defaultDays, Rules: make([]Rule, 0, len(rules))}
+	for _, rule := range rules {
+		p.Rules = append(p.Rules, rule)
+	}
+	sort.Slice(p.Rules, func(i, j int) bool { return p.Rules[i].LogType < p.Rules[j].LogType })
+	return p
+}
+
+// Days returns how many days entries of a log type are kept
+func (p *Policy) Days(logType string) int {
+	for _, rule := range p.Rules {
+		if rule.LogType == logType {
+			return rule.Days
+		}
+	}
+	return p.DefaultDays
+}
+
+// Cutoffs returns the cutoff of each log type with its own retention,
+// followed by the default cutoff of every other log type
+func (p *Policy) Cutoffs(now time.Time) []models.RetentionCutoff {
+	cutoffs := make([]models.RetentionCutoff, 0, len(p.Rules)+1)
+	except := make([]string, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		cutoffs = append(cutoffs, models.RetentionCutoff{LogType: rule.LogType, Before: now.AddDate(0, 0, -rule.Days)})
+		except = append(except, rule.LogType)
+	}
+	return append(cutoffs, models.RetentionCutoff{Except: except, Before: now.AddDate(0, 0, -p.DefaultDays)})
+}
+
+// Earliest returns the earliest cutoff, before which entries of every log
+// type are expired
+func (p *Policy) Earliest(now time.Time) time.Time {
+	days := p.DefaultDays
+	for _, rule := range p.Rules {
+		days = max(days, rule.Days)
+	}
+	return now.AddDate(0, 0, -days)
+}
@@ -0,0 +1,43 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	updated := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	p := Resolve(90, map[string]int{"nginx": 30, "syslog": 365}, []*models.RetentionPolicy{
+		{LogType: "syslog", Days: 730, UpdatedBy: "alice", UpdatedAt: updated},
+		{LogType: "apache", Days: 14},
+	})
+
+	require.Len(t, p.Rules, 3)
+	assert.Equal(t, Rule{LogType: "apache", Days: 14, Source: SourceAPI, UpdatedAt: &time.Time{}}, p.Rules[0])
+	assert.Equal(t, Rule{LogType: "nginx", Days: 30, Source: SourceConfig}, p.Rules[1])
+	assert.Equal(t, Rule{LogType: "syslog", Days: 730, Source: SourceAPI, UpdatedBy: "alice", UpdatedAt: &updated}, p.Rules[2],
+		"stored policies replace configured ones")
+
+	assert.Equal(t, 30, p.Days("nginx"))
+	assert.Equal(t, 90, p.Days("generic"))
+}
+
+func TestCutoffs(t *testing.T) {
+	now := time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC)
+	p := Resolve(90, map[string]int{"nginx": 30, "syslog": 365}, nil)
+
+	assert.Equal(t, []models.RetentionCutoff{
+		{LogType: "nginx", Before: now.AddDate(0, 0, -30)},
+		{LogType: "syslog", Before: now.AddDate(0, 0, -365)},
+		{Except: []string{"nginx", "syslog"}, Before: now.AddDate(0, 0, -90)},
+	}, p.Cutoffs(now))
+	assert.Equal(t, now.AddDate(0, 0, -365), p.Earliest(now))
+
+	p = Resolve(90, nil, nil)
+	assert.Equal(t, []models.RetentionCutoff{{Except: []string{}, Before: now.AddDate(0, 0, -90)}}, p.Cutoffs(now))
+	assert.Equal(t, now.AddDate(0, 0, -90), p.Earliest(now))
+}
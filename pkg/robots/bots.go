@@ -0,0 +1,54 @@
+package robots
+
+import "strings"
+
+// crawlerKeywords identify crawler product tokens in user agents
+var crawlerKeywords = []string{"bot", "crawler", "spider"}
+
+// knownCrawlers are crawler tokens that carry none of the keywords
+var knownCrawlers = []string{
+	"slurp",
+	"facebookexternalhit",
+	"mediapartners-google",
+	"google-inspectiontool",
+	"ia_archiver",
+}
+
+// DetectBot returns the product token naming the crawler in a user agent,
+// such as "Googlebot" for "Mozilla/5.0 (compatible; Googlebot/2.1;
+// +http://www.google.com/bot.html)", and whether the user agent is a crawler
+func DetectBot(userAgent string) (string, bool) {
+	tokens := strings.FieldsFunc(userAgent, func(r rune) bool {
+		return r == ' ' || r == ';' || r == '(' || r == ')' || r == ','
+	})
+
+	for _, token := range tokens {
+		// Contact URLs and addresses often mention "bot" too
+		if strings.HasPrefix(token, "+") || strings.Contains(token, ":") || strings.Contains(token, "@") {
+			continue
+		}
+		name, _, _ := strings.Cut(token, "/")
+		lower := strings.ToLower(name)
+		if lower == "" {
+			continue
+		}
+
+		for _, known := range knownCrawlers {
+			if lower == known {
+				return name, true
+			}
+		}
+		for _, keyword := range crawlerKeywords {
+			if strings.Contains(lower, keyword) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Keywords returns substrings that every user agent DetectBot recognizes
+// contains, for narrowing a search before detection
+func Keywords() []string {
+	return append(append([]string{}, crawlerKeywords...), knownCrawlers...)
+}
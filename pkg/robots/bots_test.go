@@ -0,0 +1,35 @@
+package robots
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectBot(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		bot       string
+	}{
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "Googlebot"},
+		{"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm) Chrome/116.0.1938.76 Safari/537.36", "bingbot"},
+		{"Mozilla/5.0 (compatible; Yahoo! Slurp; http://help.yahoo.com/help/us/ysearch/slurp)", "Slurp"},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", "facebookexternalhit"},
+		{"Mozilla/5.0 (compatible; Baiduspider/2.0; +http://www.baidu.com/search/spider.html)", "Baiduspider"},
+		{"Mozilla/5.0 (compatible; MyCrawler/1.0; admin@crawler.example.com)", "MyCrawler"},
+	}
+	for _, tt := range tests {
+		bot, ok := DetectBot(tt.userAgent)
+		assert.True(t, ok, tt.userAgent)
+		assert.Equal(t, tt.bot, bot)
+	}
+
+	for _, userAgent := range []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Safari/537.36",
+		"curl/8.1.2",
+		"",
+	} {
+		_, ok := DetectBot(userAgent)
+		assert.False(t, ok, userAgent)
+	}
+}
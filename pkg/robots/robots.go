@@ -0,0 +1,187 @@
+// Package robots parses robots.txt files and decides whether a crawler may
+// fetch a path, following the Robots Exclusion Protocol (RFC 9309).
+package robots
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxSize is the amount of a robots.txt file that is parsed; like major
+// crawlers, anything past 500 KiB is ignored
+const MaxSize = 500 * 1024
+
+// Rule is a single allow or disallow line
+type Rule struct {
+	Allow   bool   `json:"allow"`
+	Pattern string `json:"pattern"`
+}
+
+// Group holds the rules for the user agents it names
+type Group struct {
+	UserAgents []string `json:"user_agents"`
+	Rules      []Rule   `json:"rules"`
+	// CrawlDelay is the requested delay between fetches in seconds, or 0
+	CrawlDelay float64 `json:"crawl_delay,omitempty"`
+}
+
+// Robots is a parsed robots.txt file
+type Robots struct {
+	Groups   []*Group `json:"groups"`
+	Sitemaps []string `json:"sitemaps,omitempty"`
+}
+
+// Parse reads a robots.txt file. Unknown fields and malformed lines are
+// ignored, as crawlers do.
+func Parse(r io.Reader) (*Robots, error) {
+	robots := &Robots{}
+	var group *Group
+	// Consecutive user-agent lines share a group
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, MaxSize))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			if !inAgents {
+				group = &Group{}
+				robots.Groups = append(robots.Groups, group)
+				inAgents = true
+			}
+			group.UserAgents = append(group.UserAgents, strings.ToLower(value))
+			continue
+		case "allow", "disallow":
+			// An empty disallow allows everything, which is the default
+			if group != nil && value != "" {
+				group.Rules = append(group.Rules, Rule{Allow: field == "allow", Pattern: value})
+			}
+		case "crawl-delay":
+			if delay, err := strconv.ParseFloat(value, 64); err == nil && group != nil && delay > 0 {
+				group.CrawlDelay = delay
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+		inAgents = false
+	}
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return nil, err
+	}
+
+	return robots, nil
+}
+
+// GroupFor returns the rules that apply to a crawler: every group naming
+// the longest product token found in its user agent, merged, or the "*"
+// groups when none match. The returned group's UserAgents holds just the
+// matched token, and is nil when no group applies.
+func (r *Robots) GroupFor(userAgent string) *Group {
+	userAgent = strings.ToLower(userAgent)
+
+	best := ""
+	for _, group := range r.Groups {
+		for _, agent := range group.UserAgents {
+			if agent != "*" && agent != "" && len(agent) > len(best) && strings.Contains(userAgent, agent) {
+				best = agent
+			}
+		}
+	}
+	if best == "" {
+		best = "*"
+	}
+
+	var merged *Group
+	for _, group := range r.Groups {
+		for _, agent := range group.UserAgents {
+			if agent != best {
+				continue
+			}
+			if merged == nil {
+				merged = &Group{UserAgents: []string{best}}
+			}
+			merged.Rules = append(merged.Rules, group.Rules...)
+			if group.CrawlDelay > 0 {
+				merged.CrawlDelay = group.CrawlDelay
+			}
+			break
+		}
+	}
+	return merged
+}
+
+// Allowed reports whether the group permits fetching path, which may include
+// a query string. The longest matching rule wins and allow wins ties.
+func (g *Group) Allowed(path string) (bool, *Rule) {
+	if path == "" {
+		path = "/"
+	}
+	if g == nil || path == "/robots.txt" {
+		return true, nil
+	}
+
+	var match *Rule
+	for i := range g.Rules {
+		rule := &g.Rules[i]
+		if !matchPattern(rule.Pattern, path) {
+			continue
+		}
+		if match == nil || len(rule.Pattern) > len(match.Pattern) ||
+			(len(rule.Pattern) == len(match.Pattern) && rule.Allow && !match.Allow) {
+			match = rule
+		}
+	}
+	return match == nil || match.Allow, match
+}
+
+// Allowed reports whether a crawler with the given user agent may fetch path
+func (r *Robots) Allowed(userAgent, path string) bool {
+	allowed, _ := r.GroupFor(userAgent).Allowed(path)
+	return allowed
+}
+
+// matchPattern matches a path against a rule pattern, where "*" matches any
+// run of characters and a trailing "$" anchors the end of the path
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	if len(parts) == 1 {
+		return !anchored || pos == len(path)
+	}
+
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return len(path)-pos >= len(part) && strings.HasSuffix(path, part)
+		}
+		j := strings.Index(path[pos:], part)
+		if j < 0 {
+			return false
+		}
+		pos += j + len(part)
+	}
+	return true
+}
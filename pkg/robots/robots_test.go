@@ -0,0 +1,107 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleRobots = `# Example robots.txt
+User-agent: *
+Disallow: /admin/
+Disallow: /search
+Allow: /search/about
+Disallow: /*.pdf$
+
+User-agent: Googlebot
+User-agent: bingbot
+Disallow: /private
+Crawl-delay: 2
+
+user-agent: googlebot
+allow: /private/press
+
+Sitemap: https://www.example.com/sitemap.xml
+`
+
+func TestParse(t *testing.T) {
+	robots, err := Parse(strings.NewReader(exampleRobots))
+	require.NoError(t, err)
+
+	require.Len(t, robots.Groups, 3)
+	assert.Equal(t, []string{"*"}, robots.Groups[0].UserAgents)
+	assert.Len(t, robots.Groups[0].Rules, 4)
+	assert.Equal(t, []string{"googlebot", "bingbot"}, robots.Groups[1].UserAgents)
+	assert.Equal(t, 2.0, robots.Groups[1].CrawlDelay)
+	assert.Equal(t, []string{"https://www.example.com/sitemap.xml"}, robots.Sitemaps)
+}
+
+func TestGroupFor(t *testing.T) {
+	robots, err := Parse(strings.NewReader(exampleRobots))
+	require.NoError(t, err)
+
+	// Groups naming the same crawler are merged
+	google := robots.GroupFor("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)")
+	require.NotNil(t, google)
+	assert.Equal(t, []string{"googlebot"}, google.UserAgents)
+	assert.Len(t, google.Rules, 2)
+	assert.Equal(t, 2.0, google.CrawlDelay)
+
+	other := robots.GroupFor("Mozilla/5.0 (compatible; DuckDuckBot/1.1)")
+	require.NotNil(t, other)
+	assert.Equal(t, []string{"*"}, other.UserAgents)
+
+	empty, err := Parse(strings.NewReader("Sitemap: https://www.example.com/sitemap.xml"))
+	require.NoError(t, err)
+	assert.Nil(t, empty.GroupFor("Googlebot"))
+	assert.True(t, empty.Allowed("Googlebot", "/anything"))
+}
+
+func TestAllowed(t *testing.T) {
+	robots, err := Parse(strings.NewReader(exampleRobots))
+	require.NoError(t, err)
+
+	const crawler = "DuckDuckBot/1.1"
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/admin/users", false},
+		{"/admin", true},
+		{"/search?q=logs", false},
+		{"/search/about", true},
+		{"/docs/manual.pdf", false},
+		{"/docs/manual.pdf?download=1", true},
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, robots.Allowed(crawler, tt.path), tt.path)
+	}
+
+	// Googlebot only follows its own group
+	assert.True(t, robots.Allowed("Googlebot/2.1", "/admin/users"))
+	assert.False(t, robots.Allowed("Googlebot/2.1", "/private/reports"))
+	assert.True(t, robots.Allowed("Googlebot/2.1", "/private/press/2023"))
+	assert.False(t, robots.Allowed("bingbot/2.0", "/private/press/2023"))
+}
+
+func TestAllowWinsTies(t *testing.T) {
+	robots, err := Parse(strings.NewReader("User-agent: *\nDisallow: /page\nAllow: /page\n"))
+	require.NoError(t, err)
+	assert.True(t, robots.Allowed("AnyBot", "/page"))
+}
+
+func TestMatchPattern(t *testing.T) {
+	assert.True(t, matchPattern("/fish", "/fish.html"))
+	assert.False(t, matchPattern("/fish", "/Fish"))
+	assert.True(t, matchPattern("/fish*.php", "/fish/salmon.php?id=1"))
+	assert.True(t, matchPattern("/*.php$", "/filename.php"))
+	assert.False(t, matchPattern("/*.php$", "/filename.php?parameters"))
+	assert.True(t, matchPattern("/a*b*c$", "/axxbyyc"))
+	assert.False(t, matchPattern("/a*b*c$", "/axxcyyb"))
+	assert.True(t, matchPattern("/exact$", "/exact"))
+	assert.False(t, matchPattern("/exact$", "/exactly"))
+}
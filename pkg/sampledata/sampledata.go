@@ -0,0 +1,385 @@
+// Package sampledata generates realistic web server traffic for demos and
+// load tests: a daily and weekly cycle, a few popular pages with a long
+// tail, crawlers and scanners, and short outages of mostly server errors.
+package sampledata
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// MaxSpan is the longest time range traffic is generated over
+const MaxSpan = 366 * 24 * time.Hour
+
+// LogTypes are the log types generated entries can be stored as; both
+// write their raw lines in the combined log format
+var LogTypes = []string{"nginx", "apache"}
+
+// Options shape the generated traffic
+type Options struct {
+	// Start and End bound the entries' timestamps, [Start, End)
+	Start time.Time
+	End   time.Time
+	// Entries is how many entries to generate
+	Entries int
+	LogType string
+	// BotShare is the fraction of requests made by crawlers and scanners
+	BotShare float64
+	// ErrorBursts is how many outages of 5 to 30 minutes occur, during
+	// which traffic rises with retries and most requests fail
+	ErrorBursts int
+	// Seed makes the traffic reproducible: the same options generate the
+	// same entries
+	Seed int64
+}
+
+// Validate checks that traffic can be generated with the options
+func (o Options) Validate() error {
+	if !o.End.After(o.Start) {
+		return errors.New("end must be after start")
+	}
+	if o.End.Sub(o.Start) > MaxSpan {
+		return fmt.Errorf("the time range cannot be longer than %d days", int(MaxSpan.Hours()/24))
+	}
+	if o.Entries < 1 {
+		return errors.New("entries must be at least 1")
+	}
+	if o.LogType != "nginx" && o.LogType != "apache" {
+		return fmt.Errorf("log type must be one of %s", strings.Join(LogTypes, ", "))
+	}
+	if o.BotShare < 0 || o.BotShare > 1 {
+		return errors.New("bot share must be between 0 and 1")
+	}
+	if o.ErrorBursts < 0 {
+		return errors.New("error bursts cannot be negative")
+	}
+	return nil
+}
+
+// bucket is the resolution of the traffic curve
+const bucket = time.Minute
+
+// burst is an outage
+type burst struct {
+	start, end time.Time
+}
+
+// page is a path visitors request. {id} and {term} are replaced with a
+// product number and a search term.
+type page struct {
+	path    string
+	method  string
+	size    int64   // typical response bytes
+	latency float64 // typical seconds to respond
+	static  bool
+}
+
+// pages are ordered by popularity, which falls off like Zipf's law
+var pages = []page{
+	{"/", "GET", 18000, 0.040, false},
+	{"/static/js/app.js", "GET", 240000, 0.002, true},
+	{"/static/css/main.css", "GET", 52000, 0.002, true},
+	{"/api/v1/products", "GET", 9000, 0.080, false},
+	{"/products/{id}", "GET", 24000, 0.060, false},
+	{"/images/products/{id}.jpg", "GET", 85000, 0.003, true},
+	{"/search?q={term}", "GET", 16000, 0.150, false},
+	{"/api/v1/cart", "POST", 600, 0.090, false},
+	{"/favicon.ico", "GET", 4300, 0.001, true},
+	{"/login", "POST", 350, 0.120, false},
+	{"/account", "GET", 12000, 0.070, false},
+	{"/checkout", "POST", 1200, 0.300, false},
+	{"/api/v1/orders", "POST", 800, 0.250, false},
+	{"/blog/{id}", "GET", 30000, 0.050, false},
+	{"/about", "GET", 11000, 0.030, false},
+	{"/contact", "GET", 9000, 0.030, false},
+}
+
+// probes are paths scanners try, which the site does not have
+var probes = []string{"/wp-login.php", "/.env", "/.git/config", "/phpmyadmin/", "/admin.php", "/xmlrpc.php", "/config.json", "/api/v1/users?debug=1"}
+
+var searchTerms = []string{"shoes", "jacket", "gift+card", "sale", "backpack", "running", "wool+socks", "headphones"}
+
+var browsers = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36 Edg/119.0.0.0",
+}
+
+// bots are crawlers, which walk every page alike, and scanners, which
+// probe for weaknesses
+var bots = []struct {
+	userAgent string
+	scanner   bool
+}{
+	{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", false},
+	{"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", false},
+	{"Mozilla/5.0 (compatible; AhrefsBot/7.0; +http://ahrefs.com/robot/)", false},
+	{"Mozilla/5.0 (compatible; SemrushBot/7~bl; +http://www.semrush.com/bot.html)", false},
+	{"Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)", false},
+	{"DuckDuckBot/1.1; (+http://duckduckgo.com/duckduckbot.html)", false},
+	{"python-requests/2.31.0", true},
+	{"curl/8.4.0", true},
+}
+
+var referers = []string{"", "", "https://www.google.com/", "https://www.bing.com/", "https://shop.example.com/", "https://shop.example.com/products", "https://t.co/"}
+
+// client is a visitor with a fixed address and user agent
+type client struct {
+	ip        string
+	userAgent string
+	scanner   bool
+}
+
+// generator holds the state of one run
+type generator struct {
+	opts    Options
+	rng     *rand.Rand
+	bursts  []burst
+	humans  []client
+	bots    []client
+	byHuman *rand.Zipf
+	byPage  *rand.Zipf
+}
+
+// Generate produces the entries in time order and passes them to emit in
+// batches of up to batchSize. It stops at the first error emit returns.
+func Generate(opts Options, batchSize int, emit func([]*models.LogEntry) error) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	g := newGenerator(opts)
+
+	// Entries are spread over the range in proportion to the traffic
+	// curve, rounding cumulatively so exactly opts.Entries are generated
+	weights := g.weights()
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	batch := make([]*models.LogEntry, 0, batchSize)
+	var cumulative float64
+	allocated := 0
+	for i, w := range weights {
+		cumulative += w
+		count := int(math.Round(float64(opts.Entries)*cumulative/total)) - allocated
+		allocated += count
+
+		start := opts.Start.Add(time.Duration(i) * bucket)
+		width := min(bucket, opts.End.Sub(start))
+		offsets := make([]time.Duration, count)
+		for j := range offsets {
+			offsets[j] = time.Duration(g.rng.Int63n(int64(width)))
+		}
+		sort.Slice(offsets, func(a, b int) bool { return offsets[a] < offsets[b] })
+
+		for _, offset := range offsets {
+			batch = append(batch, g.entry(start.Add(offset).Truncate(time.Millisecond)))
+			if len(batch) == batchSize {
+				if err := emit(batch); err != nil {
+					return err
+				}
+				batch = make([]*models.LogEntry, 0, batchSize)
+			}
+		}
+	}
+	if len(batch) > 0 {
+		return emit(batch)
+	}
+	return nil
+}
+
+func newGenerator(opts Options) *generator {
+	g := &generator{opts: opts, rng: rand.New(rand.NewSource(opts.Seed))}
+
+	span := opts.End.Sub(opts.Start)
+	for i := 0; i < opts.ErrorBursts; i++ {
+		start := opts.Start.Add(time.Duration(g.rng.Int63n(int64(span))))
+		length := time.Duration(5+g.rng.Intn(26)) * time.Minute
+		g.bursts = append(g.bursts, burst{start: start, end: start.Add(length)})
+	}
+
+	// Roughly 40 requests per visitor, so larger runs have more visitors
+	humans := min(max(opts.Entries/40, 20), 20000)
+	for i := 0; i < humans; i++ {
+		g.humans = append(g.humans, client{ip: g.publicIP(), userAgent: browsers[g.rng.Intn(len(browsers))]})
+	}
+	for _, bot := range bots {
+		for i := 0; i < 1+g.rng.Intn(3); i++ {
+			g.bots = append(g.bots, client{ip: g.publicIP(), userAgent: bot.userAgent, scanner: bot.scanner})
+		}
+	}
+
+	g.byHuman = rand.NewZipf(g.rng, 1.2, 1, uint64(len(g.humans)-1))
+	g.byPage = rand.NewZipf(g.rng, 1.1, 1, uint64(len(pages)-1))
+	return g
+}
+
+// publicIP returns an address outside private, loopback and reserved
+// ranges
+func (g *generator) publicIP() string {
+	for {
+		a := 1 + g.rng.Intn(223)
+		switch {
+		case a == 10, a == 100, a == 127, a == 169, a == 172, a == 192, a == 198, a == 203:
+			continue
+		}
+		return fmt.Sprintf("%d.%d.%d.%d", a, g.rng.Intn(256), g.rng.Intn(256), 1+g.rng.Intn(254))
+	}
+}
+
+// weights is the relative traffic of each minute of the range
+func (g *generator) weights() []float64 {
+	span := g.opts.End.Sub(g.opts.Start)
+	weights := make([]float64, (span+bucket-1)/bucket)
+	for i := range weights {
+		t := g.opts.Start.Add(time.Duration(i) * bucket)
+		width := min(bucket, g.opts.End.Sub(t))
+
+		// Quietest at 04:00, busiest at 16:00
+		hour := float64(t.Hour()) + float64(t.Minute())/60
+		w := 0.15 + 0.85*(0.5-0.5*math.Cos(2*math.Pi*(hour-4)/24))
+		if day := t.Weekday(); day == time.Saturday || day == time.Sunday {
+			w *= 0.6
+		}
+		if g.inBurst(t) {
+			// Clients retry failed requests
+			w *= 1.5
+		}
+		w *= 0.85 + 0.3*g.rng.Float64()
+		weights[i] = w * float64(width) / float64(bucket)
+	}
+	return weights
+}
+
+func (g *generator) inBurst(t time.Time) bool {
+	for _, b := range g.bursts {
+		if !t.Before(b.start) && t.Before(b.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// entry generates one request at t
+func (g *generator) entry(t time.Time) *models.LogEntry {
+	var c client
+	var p page
+	var path string
+	bot := g.rng.Float64() < g.opts.BotShare
+	if bot {
+		c = g.bots[g.rng.Intn(len(g.bots))]
+		switch {
+		case c.scanner:
+			p = page{method: "GET", size: 150, latency: 0.005}
+			path = probes[g.rng.Intn(len(probes))]
+		case g.rng.Float64() < 0.05:
+			p = page{method: "GET", size: 120, latency: 0.001, static: true}
+			path = "/robots.txt"
+		default:
+			// Crawlers fetch pages alike, not by popularity
+			p = pages[g.rng.Intn(len(pages))]
+			p.method = "GET"
+			path = g.expand(p.path)
+		}
+	} else {
+		c = g.humans[g.byHuman.Uint64()]
+		p = pages[g.byPage.Uint64()]
+		path = g.expand(p.path)
+	}
+
+	status, size, latency := g.response(t, c, p, path)
+	referer := ""
+	if !bot {
+		referer = referers[g.rng.Intn(len(referers))]
+	}
+
+	entry := &models.LogEntry{
+		Timestamp:      t,
+		LogType:        g.opts.LogType,
+		SourceIP:       c.ip,
+		Method:         p.method,
+		Path:           path,
+		StatusCode:     status,
+		ResponseSize:   size,
+		UserAgent:      c.userAgent,
+		Referer:        referer,
+		ProcessingTime: math.Round(latency*1000) / 1000,
+		Metadata:       models.LogMetadata{"synthetic": true},
+	}
+	entry.RawLog = combinedLine(entry)
+	return entry
+}
+
+// expand fills in a path template
+func (g *generator) expand(path string) string {
+	path = strings.ReplaceAll(path, "{id}", strconv.Itoa(1+g.rng.Intn(5000)))
+	return strings.ReplaceAll(path, "{term}", searchTerms[g.rng.Intn(len(searchTerms))])
+}
+
+// response picks the status, size and latency of a request
+func (g *generator) response(t time.Time, c client, p page, path string) (int, int64, float64) {
+	size := int64(float64(p.size) * (0.5 + g.rng.Float64()))
+	latency := p.latency * math.Exp(0.6*g.rng.NormFloat64())
+	r := g.rng.Float64()
+
+	if g.inBurst(t) && !p.static && r < 0.6 {
+		switch {
+		case r < 0.25:
+			return 502, 157, latency
+		case r < 0.45:
+			return 503, 197, latency
+		default:
+			// Upstream timeouts
+			return 504, 167, 30 + g.rng.Float64()*30
+		}
+	}
+	if c.scanner {
+		return 404, size, latency
+	}
+
+	switch {
+	case r < 0.004:
+		return 500, 180, latency * 4
+	case r < 0.005:
+		return 503, 197, latency
+	case r < 0.035:
+		return 404, 150, latency
+	case p.static && r < 0.15:
+		return 304, 0, latency
+	case path == "/login" && r < 0.2:
+		return 401, 60, latency
+	case path == "/account" && r < 0.1:
+		return 302, 0, latency
+	case p.method == "POST" && path != "/login":
+		return 201, size, latency
+	}
+	return 200, size, latency
+}
+
+// combinedLine writes an entry in the combined log format, as nginx and
+// Apache log it by default
+func combinedLine(entry *models.LogEntry) string {
+	referer := entry.Referer
+	if referer == "" {
+		referer = "-"
+	}
+	return fmt.Sprintf(`%s - - [%s] "%s %s HTTP/1.1" %d %d "%s" "%s"`,
+		entry.SourceIP, entry.Timestamp.Format("02/Jan/2006:15:04:05 -0700"), entry.Method, entry.Path,
+		entry.StatusCode, entry.ResponseSize, referer, entry.UserAgent)
+}
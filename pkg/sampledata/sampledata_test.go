@@ -0,0 +1,127 @@
+package sampledata
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monday is the start of a week
+var monday = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+func generate(t *testing.T, opts Options) []*models.LogEntry {
+	t.Helper()
+	var entries []*models.LogEntry
+	require.NoError(t, Generate(opts, 1000, func(batch []*models.LogEntry) error {
+		assert.LessOrEqual(t, len(batch), 1000)
+		entries = append(entries, batch...)
+		return nil
+	}))
+	return entries
+}
+
+func TestGenerate(t *testing.T) {
+	opts := Options{Start: monday, End: monday.Add(7 * 24 * time.Hour), Entries: 20000, LogType: "nginx", BotShare: 0.1, Seed: 1}
+	entries := generate(t, opts)
+	require.Len(t, entries, 20000)
+
+	var day, night, weekday, weekend, bot int
+	for i, entry := range entries {
+		require.False(t, entry.Timestamp.Before(opts.Start) || !entry.Timestamp.Before(opts.End))
+		if i > 0 {
+			require.False(t, entry.Timestamp.Before(entries[i-1].Timestamp), "entries are in time order")
+		}
+		assert.Equal(t, "nginx", entry.LogType)
+		assert.Equal(t, true, entry.Metadata["synthetic"])
+
+		switch hour := entry.Timestamp.Hour(); {
+		case hour >= 14 && hour < 18:
+			day++
+		case hour >= 2 && hour < 6:
+			night++
+		}
+		if entry.Timestamp.Weekday() == time.Saturday || entry.Timestamp.Weekday() == time.Sunday {
+			weekend++
+		} else {
+			weekday++
+		}
+		for _, b := range bots {
+			if entry.UserAgent == b.userAgent {
+				bot++
+			}
+		}
+	}
+	assert.Greater(t, day, 3*night, "traffic follows the daily cycle")
+	assert.Less(t, float64(weekend)/2, 0.8*float64(weekday)/5, "weekends are quieter")
+	assert.InDelta(t, 0.1, float64(bot)/float64(len(entries)), 0.02)
+
+	// The same options generate the same traffic
+	again := generate(t, opts)
+	assert.Equal(t, entries[1234], again[1234])
+}
+
+func TestErrorBursts(t *testing.T) {
+	serverErrors := func(bursts int) int {
+		entries := generate(t, Options{Start: monday, End: monday.Add(24 * time.Hour), Entries: 20000, LogType: "apache", ErrorBursts: bursts, Seed: 2})
+		count := 0
+		for _, entry := range entries {
+			if entry.StatusCode >= 500 {
+				count++
+			}
+		}
+		return count
+	}
+	assert.Greater(t, serverErrors(3), 5*serverErrors(0))
+}
+
+func TestRawLinesParse(t *testing.T) {
+	processor := logprocessor.NewProcessor(1)
+	entries := generate(t, Options{Start: monday, End: monday.Add(time.Hour), Entries: 200, LogType: "nginx", BotShare: 0.2, Seed: 3})
+	for _, entry := range entries {
+		parsed, err := processor.ParseLine(entry.RawLog, "nginx")
+		require.NoError(t, err, entry.RawLog)
+		assert.Equal(t, entry.SourceIP, parsed.SourceIP)
+		assert.Equal(t, entry.Path, parsed.Path)
+		assert.Equal(t, entry.StatusCode, parsed.StatusCode)
+		assert.Equal(t, entry.UserAgent, parsed.UserAgent)
+		assert.True(t, entry.Timestamp.Truncate(time.Second).Equal(parsed.Timestamp))
+	}
+}
+
+func TestGenerateStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	batches := 0
+	err := Generate(Options{Start: monday, End: monday.Add(time.Hour), Entries: 500, LogType: "nginx"}, 100, func([]*models.LogEntry) error {
+		batches++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, batches)
+}
+
+func TestValidate(t *testing.T) {
+	valid := Options{Start: monday, End: monday.Add(time.Hour), Entries: 1, LogType: "nginx"}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		change func(*Options)
+		want   string
+	}{
+		{func(o *Options) { o.End = o.Start }, "end must be after start"},
+		{func(o *Options) { o.End = o.Start.Add(400 * 24 * time.Hour) }, "366 days"},
+		{func(o *Options) { o.Entries = 0 }, "entries"},
+		{func(o *Options) { o.LogType = "syslog" }, "log type"},
+		{func(o *Options) { o.BotShare = 1.5 }, "bot share"},
+		{func(o *Options) { o.ErrorBursts = -1 }, "error bursts"},
+	}
+	for _, tt := range tests {
+		opts := valid
+		tt.change(&opts)
+		assert.ErrorContains(t, opts.Validate(), tt.want)
+	}
+}
@@ -0,0 +1,401 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// Score components
+const (
+	ComponentRequestRate = "request_rate"
+	ComponentErrorRatio  = "error_ratio"
+	ComponentPathEntropy = "path_entropy"
+	ComponentUADiversity = "ua_diversity"
+	ComponentGeoVelocity = "geo_velocity"
+)
+
+const (
+	// DefaultMinRequests is the fewest requests an IP needs to be scored
+	DefaultMinRequests = 5
+
+	// maxTrackedPaths bounds the distinct paths kept per IP; further paths
+	// are counted as seen once, which is what scanners produce anyway
+	maxTrackedPaths = 1000
+
+	// maxTrackedUserAgents bounds the distinct user agents kept per IP
+	maxTrackedUserAgents = 100
+
+	// rateSaturation is the multiple of the median request rate at which
+	// the request rate component saturates
+	rateSaturation = 100
+
+	// entropySaturation is the path entropy, in bits, at which the path
+	// component saturates (256 equally visited paths)
+	entropySaturation = 8
+
+	// uaSaturation is the number of extra user agents at which the user
+	// agent component saturates
+	uaSaturation = 4
+
+	// ImpossibleTravelKmh is the speed at which the geo velocity component
+	// saturates, faster than a commercial flight
+	ImpossibleTravelKmh = 1000
+
+	// minRateMinutes keeps a burst of a few requests in the same second
+	// from looking like an extreme rate
+	minRateMinutes = 1.0
+)
+
+// Weights sets how much each component contributes to the score
+type Weights map[string]float64
+
+// DefaultWeights balances the components, favouring error-heavy and
+// high-rate behaviour
+var DefaultWeights = Weights{
+	ComponentRequestRate: 0.25,
+	ComponentErrorRatio:  0.25,
+	ComponentPathEntropy: 0.2,
+	ComponentUADiversity: 0.15,
+	ComponentGeoVelocity: 0.15,
+}
+
+// Location is a point on the globe
+type Location struct {
+	Lat float64
+	Lon float64
+}
+
+type sighting struct {
+	at       time.Time
+	location Location
+}
+
+// Activity aggregates the requests of one source IP
+type Activity struct {
+	IP         string
+	Requests   int64
+	Errors     int64
+	Paths      map[string]int64
+	OtherPaths int64
+	UserAgents map[string]int64
+	First      time.Time
+	Last       time.Time
+
+	// Geo velocity needs coordinates on the entries; see EntryLocation
+	firstSeenAt *sighting
+	lastSeenAt  *sighting
+	maxVelocity float64
+}
+
+// NewActivity creates an empty activity for an IP
+func NewActivity(ip string) *Activity {
+	return &Activity{
+		IP:         ip,
+		Paths:      make(map[string]int64),
+		UserAgents: make(map[string]int64),
+	}
+}
+
+// Add records an entry. Entries should arrive in time order for geo
+// velocity to be accurate.
+func (a *Activity) Add(entry *models.LogEntry) {
+	a.Requests++
+	if entry.StatusCode >= 400 {
+		a.Errors++
+	}
+	a.addPath(entry.Path, 1)
+	if entry.UserAgent != "" {
+		if _, ok := a.UserAgents[entry.UserAgent]; ok || len(a.UserAgents) < maxTrackedUserAgents {
+			a.UserAgents[entry.UserAgent]++
+		}
+	}
+	a.addTime(entry.Timestamp, entry.Timestamp)
+
+	if location, ok := EntryLocation(entry); ok {
+		a.addSighting(&sighting{at: entry.Timestamp, location: location}, nil)
+	}
+}
+
+// Merge adds another activity for the same IP covering a later period
+func (a *Activity) Merge(other *Activity) {
+	a.Requests += other.Requests
+	a.Errors += other.Errors
+	for path, count := range other.Paths {
+		a.addPath(path, count)
+	}
+	a.OtherPaths += other.OtherPaths
+	for ua, count := range other.UserAgents {
+		if _, ok := a.UserAgents[ua]; ok || len(a.UserAgents) < maxTrackedUserAgents {
+			a.UserAgents[ua] += count
+		}
+	}
+	if other.Requests > 0 {
+		a.addTime(other.First, other.Last)
+	}
+
+	a.maxVelocity = math.Max(a.maxVelocity, other.maxVelocity)
+	if other.firstSeenAt != nil {
+		a.addSighting(other.firstSeenAt, other.lastSeenAt)
+	}
+}
+
+func (a *Activity) addPath(path string, count int64) {
+	if _, ok := a.Paths[path]; ok || len(a.Paths) < maxTrackedPaths {
+		a.Paths[path] += count
+		return
+	}
+	a.OtherPaths += count
+}
+
+func (a *Activity) addTime(first, last time.Time) {
+	if a.First.IsZero() || first.Before(a.First) {
+		a.First = first
+	}
+	if last.After(a.Last) {
+		a.Last = last
+	}
+}
+
+// addSighting extends the travel path with a span of sightings, tracking
+// the fastest movement between consecutive ones
+func (a *Activity) addSighting(first, last *sighting) {
+	if last == nil {
+		last = first
+	}
+	if a.lastSeenAt != nil {
+		a.maxVelocity = math.Max(a.maxVelocity, velocity(*a.lastSeenAt, *first))
+	} else {
+		a.firstSeenAt = first
+	}
+	a.lastSeenAt = last
+}
+
+// velocity returns the speed in km/h needed to travel between sightings
+func velocity(from, to sighting) float64 {
+	distance := haversineKm(from.location, to.location)
+	if distance < 1 {
+		return 0
+	}
+	hours := to.at.Sub(from.at).Hours()
+	if hours <= 0 {
+		return ImpossibleTravelKmh
+	}
+	return distance / hours
+}
+
+func haversineKm(a, b Location) float64 {
+	const earthRadiusKm = 6371
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// locationKeys are the metadata fields carrying client coordinates: CEF
+// source coordinates and the latitude/longitude fields written by CDNs and
+// GeoIP enrichment
+var locationKeys = [][2]string{{"slat", "slong"}, {"latitude", "longitude"}, {"lat", "lon"}}
+
+// EntryLocation returns the client coordinates recorded on an entry
+func EntryLocation(entry *models.LogEntry) (Location, bool) {
+	for _, keys := range locationKeys {
+		lat, okLat := metadataFloat(entry.Metadata, keys[0])
+		lon, okLon := metadataFloat(entry.Metadata, keys[1])
+		if okLat && okLon && lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 {
+			return Location{Lat: lat, Lon: lon}, true
+		}
+	}
+	return Location{}, false
+}
+
+func metadataFloat(metadata models.LogMetadata, key string) (float64, bool) {
+	switch v := metadata[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// RequestsPerMinute is the IP's request rate over the time it was active
+func (a *Activity) RequestsPerMinute() float64 {
+	minutes := math.Max(a.Last.Sub(a.First).Minutes(), minRateMinutes)
+	return float64(a.Requests) / minutes
+}
+
+// PathEntropy is the Shannon entropy, in bits, of the IP's path distribution
+func (a *Activity) PathEntropy() float64 {
+	if a.Requests == 0 {
+		return 0
+	}
+	total := float64(a.Requests)
+	var entropy float64
+	for _, count := range a.Paths {
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	// Untracked paths are counted as visited once each
+	if a.OtherPaths > 0 {
+		p := 1 / total
+		entropy -= float64(a.OtherPaths) * p * math.Log2(p)
+	}
+	return entropy
+}
+
+// IPScore is the suspiciousness of a source IP, from 0 to 100
+type IPScore struct {
+	IP                string             `json:"ip"`
+	Score             float64            `json:"score"`
+	Requests          int64              `json:"requests"`
+	RequestsPerMinute float64            `json:"requests_per_minute"`
+	ErrorRatio        float64            `json:"error_ratio"`
+	PathEntropy       float64            `json:"path_entropy"`
+	UserAgents        int                `json:"user_agents"`
+	GeoVelocity       *float64           `json:"geo_velocity_kmh,omitempty"`
+	Components        map[string]float64 `json:"components"`
+	Reasons           []string           `json:"reasons,omitempty"`
+	FirstSeen         time.Time          `json:"first_seen"`
+	LastSeen          time.Time          `json:"last_seen"`
+}
+
+// Scorer combines per-IP behaviour into a suspiciousness score. The request
+// rate is judged against the median IP, so the score picks out outliers
+// rather than penalising busy sites.
+type Scorer struct {
+	Weights     Weights
+	MinRequests int64
+}
+
+// NewScorer creates a scorer with the default weights
+func NewScorer() *Scorer {
+	return &Scorer{Weights: DefaultWeights, MinRequests: DefaultMinRequests}
+}
+
+// ScoreEntries groups entries by source IP and scores each IP
+func (s *Scorer) ScoreEntries(entries []*models.LogEntry) []IPScore {
+	byIP := make(map[string]*Activity)
+	var activities []*Activity
+	for _, entry := range entries {
+		if entry.SourceIP == "" {
+			continue
+		}
+		activity, ok := byIP[entry.SourceIP]
+		if !ok {
+			activity = NewActivity(entry.SourceIP)
+			byIP[entry.SourceIP] = activity
+			activities = append(activities, activity)
+		}
+		activity.Add(entry)
+	}
+	return s.Score(activities)
+}
+
+// Score scores IPs with at least MinRequests requests, highest first
+func (s *Scorer) Score(activities []*Activity) []IPScore {
+	var eligible []*Activity
+	var rates []float64
+	for _, activity := range activities {
+		if activity.Requests >= s.MinRequests {
+			eligible = append(eligible, activity)
+			rates = append(rates, activity.RequestsPerMinute())
+		}
+	}
+	medianRate := median(rates)
+
+	scores := make([]IPScore, 0, len(eligible))
+	for _, activity := range eligible {
+		scores = append(scores, s.score(activity, medianRate))
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+	return scores
+}
+
+func (s *Scorer) score(a *Activity, medianRate float64) IPScore {
+	result := IPScore{
+		IP:                a.IP,
+		Requests:          a.Requests,
+		RequestsPerMinute: a.RequestsPerMinute(),
+		ErrorRatio:        float64(a.Errors) / float64(a.Requests),
+		PathEntropy:       a.PathEntropy(),
+		UserAgents:        len(a.UserAgents),
+		Components:        make(map[string]float64),
+		FirstSeen:         a.First,
+		LastSeen:          a.Last,
+	}
+
+	rateMultiple := result.RequestsPerMinute / math.Max(medianRate, 1)
+	result.Components[ComponentRequestRate] = clamp(math.Log10(rateMultiple) / math.Log10(rateSaturation))
+	result.Components[ComponentErrorRatio] = result.ErrorRatio
+	result.Components[ComponentPathEntropy] = clamp(result.PathEntropy / entropySaturation)
+	result.Components[ComponentUADiversity] = clamp(float64(result.UserAgents-1) / uaSaturation)
+	// Without two located sightings geo velocity is unknown rather than zero
+	if a.firstSeenAt != nil && a.firstSeenAt != a.lastSeenAt {
+		kmh := a.maxVelocity
+		result.GeoVelocity = &kmh
+		result.Components[ComponentGeoVelocity] = clamp(kmh / ImpossibleTravelKmh)
+	}
+
+	var weighted, totalWeight float64
+	for component, value := range result.Components {
+		weight := s.Weights[component]
+		weighted += weight * value
+		totalWeight += weight
+	}
+	if totalWeight > 0 {
+		result.Score = math.Round(weighted/totalWeight*1000) / 10
+	}
+
+	result.Reasons = reasons(result, rateMultiple)
+	return result
+}
+
+// reasons explains the components that contributed most to a score
+func reasons(score IPScore, rateMultiple float64) []string {
+	var reasons []string
+	if score.Components[ComponentRequestRate] >= 0.5 {
+		reasons = append(reasons, fmt.Sprintf("request rate %.0fx the median", rateMultiple))
+	}
+	if score.Components[ComponentErrorRatio] >= 0.5 {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% of requests failed", score.ErrorRatio*100))
+	}
+	if score.Components[ComponentPathEntropy] >= 0.5 {
+		reasons = append(reasons, fmt.Sprintf("requests spread over many paths (%.1f bits)", score.PathEntropy))
+	}
+	if score.Components[ComponentUADiversity] >= 0.5 {
+		reasons = append(reasons, fmt.Sprintf("%d different user agents", score.UserAgents))
+	}
+	if score.Components[ComponentGeoVelocity] >= 0.5 {
+		reasons = append(reasons, fmt.Sprintf("moved at %.0f km/h between requests", *score.GeoVelocity))
+	}
+	return reasons
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
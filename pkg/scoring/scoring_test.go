@@ -0,0 +1,114 @@
+package scoring
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+var base = time.Date(2023, 10, 10, 13, 0, 0, 0, time.UTC)
+
+// browse simulates an ordinary visitor making a request a minute
+func browse(ip string, requests int) []*models.LogEntry {
+	var entries []*models.LogEntry
+	for i := 0; i < requests; i++ {
+		entries = append(entries, &models.LogEntry{
+			SourceIP:   ip,
+			Timestamp:  base.Add(time.Duration(i) * time.Minute),
+			Path:       []string{"/", "/products", "/cart"}[i%3],
+			StatusCode: 200,
+			UserAgent:  "Mozilla/5.0",
+		})
+	}
+	return entries
+}
+
+func TestScoreEntriesRanksScannerFirst(t *testing.T) {
+	var entries []*models.LogEntry
+	for i := 0; i < 10; i++ {
+		entries = append(entries, browse(fmt.Sprintf("10.0.0.%d", i), 10)...)
+	}
+	// A scanner probing hundreds of paths within a minute with rotating agents
+	for i := 0; i < 300; i++ {
+		entries = append(entries, &models.LogEntry{
+			SourceIP:   "203.0.113.9",
+			Timestamp:  base.Add(time.Duration(i) * 100 * time.Millisecond),
+			Path:       fmt.Sprintf("/wp-admin/%d.php", i),
+			StatusCode: 404,
+			UserAgent:  fmt.Sprintf("scanner/%d", i%5),
+		})
+	}
+	// Too few requests to judge
+	entries = append(entries, browse("10.0.1.1", 2)...)
+
+	scores := NewScorer().ScoreEntries(entries)
+	require.Len(t, scores, 11)
+
+	top := scores[0]
+	assert.Equal(t, "203.0.113.9", top.IP)
+	assert.Greater(t, top.Score, 80.0)
+	assert.Equal(t, 1.0, top.ErrorRatio)
+	assert.Equal(t, 5, top.UserAgents)
+	assert.Nil(t, top.GeoVelocity)
+	assert.NotContains(t, top.Components, ComponentGeoVelocity)
+	assert.Len(t, top.Reasons, 4)
+
+	for _, score := range scores[1:] {
+		assert.Less(t, score.Score, 20.0, score.IP)
+	}
+}
+
+func TestGeoVelocity(t *testing.T) {
+	located := func(at time.Duration, lat, lon float64) *models.LogEntry {
+		return &models.LogEntry{
+			SourceIP:   "198.51.100.4",
+			Timestamp:  base.Add(at),
+			Path:       "/login",
+			StatusCode: 200,
+			Metadata:   models.LogMetadata{"latitude": lat, "longitude": lon},
+		}
+	}
+
+	// New York, then London ten minutes later
+	activity := NewActivity("198.51.100.4")
+	activity.Add(located(0, 40.71, -74.01))
+	activity.Add(located(5*time.Minute, 40.71, -74.01))
+	later := NewActivity("198.51.100.4")
+	later.Add(located(10*time.Minute, 51.51, -0.13))
+	activity.Merge(later)
+
+	scorer := &Scorer{Weights: DefaultWeights, MinRequests: 1}
+	scores := scorer.Score([]*Activity{activity})
+	require.Len(t, scores, 1)
+	require.NotNil(t, scores[0].GeoVelocity)
+	// About 5,570 km in the five minutes since the last sighting in New York
+	assert.InDelta(t, 5570*12, *scores[0].GeoVelocity, 1000)
+	assert.Equal(t, 1.0, scores[0].Components[ComponentGeoVelocity])
+	assert.Contains(t, scores[0].Reasons[len(scores[0].Reasons)-1], "km/h")
+}
+
+func TestEntryLocation(t *testing.T) {
+	location, ok := EntryLocation(&models.LogEntry{Metadata: models.LogMetadata{"slat": "48.85", "slong": 2}})
+	require.True(t, ok)
+	assert.Equal(t, Location{Lat: 48.85, Lon: 2}, location)
+
+	_, ok = EntryLocation(&models.LogEntry{Metadata: models.LogMetadata{"lat": 120.0, "lon": 0.0}})
+	assert.False(t, ok)
+	_, ok = EntryLocation(&models.LogEntry{})
+	assert.False(t, ok)
+}
+
+func TestPathEntropyCountsUntrackedPaths(t *testing.T) {
+	activity := NewActivity("10.0.0.1")
+	for i := 0; i < maxTrackedPaths+24; i++ {
+		activity.Add(&models.LogEntry{Path: fmt.Sprintf("/%d", i), Timestamp: base})
+	}
+	assert.Len(t, activity.Paths, maxTrackedPaths)
+	assert.Equal(t, int64(24), activity.OtherPaths)
+	assert.InDelta(t, 10.0, activity.PathEntropy(), 1e-9)
+}
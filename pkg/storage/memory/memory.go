@@ -0,0 +1,1818 @@
+// Package memory is an in-memory storage backend. It keeps nothing across
+// restarts and is meant for development, tests and as a reference for
+// backend authors; select it with database type "memory".
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+)
+
+func init() {
+	storage.Register("memory", func(cfg *config.Config) (storage.Storage, error) {
+		return New(), nil
+	})
+}
+
+// Store holds everything in memory. Records are copied in and out, so
+// callers never share them with the store.
+type Store struct {
+	mu sync.RWMutex
+
+	entries      []*models.LogEntry
+	rules        []*models.AlertRule
+	events       []*models.AlertEvent
+	evaluations  []models.RuleEvaluation
+	windows      []*models.MaintenanceWindow
+	budgets      []*models.LatencyBudget
+	overrides    []*models.FeatureOverride
+	retention    []*models.RetentionPolicy
+	versions     []*models.ConfigVersion
+	auditRecords []*models.AuditRecord
+	files        map[string]*models.IngestedFile
+	reportFiles  map[string]*models.ReportFile
+	templates    []*models.ReportTemplate
+	searches     []*models.SavedSearch
+	users        map[string]*models.User
+	dataKeys     map[string]*models.DataKey
+	rollups      map[string][]models.TrafficRollup
+	nextID       int64
+}
+
+var _ storage.Storage = (*Store)(nil)
+
+// roles are the roles the SQL migrations create
+var roles = []models.Role{
+	{Name: "viewer", Level: 1, Description: "Reads logs, stats, alerts and reports"},
+	{Name: "analyst", Level: 2, Description: "Also uploads logs, generates reports and manages alert rules"},
+	{Name: "admin", Level: 3, Description: "Also manages users, retention, ingestion sources and the server"},
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{}
+}
+
+func (s *Store) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// copyEntry copies an entry, passing metadata through JSON as a database
+// would, so numbers come back as float64
+func copyEntry(entry *models.LogEntry) *models.LogEntry {
+	c := *entry
+	if entry.Metadata != nil {
+		c.Metadata = nil
+		if data, err := json.Marshal(entry.Metadata); err == nil {
+			json.Unmarshal(data, &c.Metadata)
+		}
+	}
+	return &c
+}
+
+func inRange(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// recent returns copies of the most recent limit entries in [start, end)
+// that match, oldest first
+func (s *Store) recent(match func(*models.LogEntry) bool, start, end time.Time, limit int) []*models.LogEntry {
+	var entries []*models.LogEntry
+	for _, entry := range s.entries {
+		if inRange(entry.Timestamp, start, end) && match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	result := make([]*models.LogEntry, len(entries))
+	for i, entry := range entries {
+		result[len(entries)-1-i] = copyEntry(entry)
+	}
+	return result
+}
+
+// InsertLogEntry stores an entry and sets its ID, or leaves it 0 if an
+// entry of the same file line is stored already
+func (s *Store) InsertLogEntry(entry *models.LogEntry) error {
+	return s.InsertLogEntries([]*models.LogEntry{entry})
+}
+
+// lineKey identifies the file line an entry was read from. The timestamp
+// is no part of it, as lines without one are given the time they are read.
+type lineKey struct {
+	fileHash   string
+	lineNumber int64
+}
+
+func entryLine(entry *models.LogEntry) lineKey {
+	return lineKey{entry.FileHash, entry.LineNumber}
+}
+
+// InsertLogEntries stores entries and sets their IDs, skipping those of
+// file lines stored already as InsertLogEntry does
+func (s *Store) InsertLogEntries(entries []*models.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines map[lineKey]bool
+	if slices.ContainsFunc(entries, func(e *models.LogEntry) bool { return e.FileHash != "" }) {
+		lines = make(map[lineKey]bool)
+		for _, stored := range s.entries {
+			if stored.FileHash != "" {
+				lines[entryLine(stored)] = true
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		entry.ID = 0
+		if entry.FileHash != "" {
+			if lines[entryLine(entry)] {
+				continue
+			}
+			lines[entryLine(entry)] = true
+		}
+		stored := copyEntry(entry)
+		stored.ID = s.newID()
+		stored.CreatedAt = now
+		stored.UpdatedAt = now
+		s.entries = append(s.entries, stored)
+		entry.ID = stored.ID
+	}
+	return nil
+}
+
+// QueryLogs returns entries matching the filter, most recent first
+func (s *Store) QueryLogs(filter *models.LogFilter) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*models.LogEntry
+	for _, entry := range s.entries {
+		if filter.Matches(entry) &&
+			(filter.After == nil || listedBefore(filter, atPosition(filter.After), entry)) &&
+			(filter.Before == nil || listedBefore(filter, entry, atPosition(filter.Before))) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return listedBefore(filter, entries[i], entries[j]) })
+
+	offset := max(filter.Offset, 0)
+	if offset >= len(entries) {
+		return nil, nil
+	}
+	limit := max(filter.Limit, 0)
+	if filter.Before != nil {
+		// The page before the position is the part nearest it
+		entries = entries[:len(entries)-offset]
+		entries = entries[max(len(entries)-limit, 0):]
+	} else {
+		entries = entries[offset:]
+		entries = entries[:min(len(entries), limit)]
+	}
+
+	result := make([]*models.LogEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = copyEntry(entry)
+	}
+	return result, nil
+}
+
+// listedBefore reports whether the filter lists entry a before b
+func listedBefore(filter *models.LogFilter, a, b *models.LogEntry) bool {
+	c := compareBy(filter.SortBy, a, b)
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if filter.SortAscending {
+		return c < 0
+	}
+	return c > 0
+}
+
+// compareBy compares entries by the field they are listed by
+func compareBy(field string, a, b *models.LogEntry) int {
+	switch field {
+	case "status_code":
+		return cmp.Compare(a.StatusCode, b.StatusCode)
+	case "response_size":
+		return cmp.Compare(a.ResponseSize, b.ResponseSize)
+	case "processing_time":
+		return cmp.Compare(a.ProcessingTime, b.ProcessingTime)
+	}
+	return a.Timestamp.Compare(b.Timestamp)
+}
+
+// atPosition is an entry at a position, to compare entries with
+func atPosition(position *models.LogPosition) *models.LogEntry {
+	return &models.LogEntry{Timestamp: position.Timestamp, ID: position.ID}
+}
+
+// GetLogMessages returns the most recent entries of the given log types,
+// most recent first, with only their timestamp, log type and message
+func (s *Store) GetLogMessages(logTypes []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.recent(func(e *models.LogEntry) bool { return slices.Contains(logTypes, e.LogType) }, start, end, limit)
+	slices.Reverse(entries)
+	for i, entry := range entries {
+		entries[i] = &models.LogEntry{Timestamp: entry.Timestamp, LogType: entry.LogType, Path: entry.Path}
+	}
+	return entries, nil
+}
+
+// GetSourceActivity returns the most recent entries with a source IP,
+// oldest first
+func (s *Store) GetSourceActivity(start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.recent(func(e *models.LogEntry) bool { return e.SourceIP != "" }, start, end, limit)
+	for i, entry := range entries {
+		entries[i] = &models.LogEntry{
+			Timestamp: entry.Timestamp, SourceIP: entry.SourceIP, Path: entry.Path,
+			StatusCode: entry.StatusCode, UserAgent: entry.UserAgent, Metadata: entry.Metadata,
+		}
+	}
+	return entries, nil
+}
+
+// GetUserAgentActivity returns the most recent entries whose user agent
+// contains any of the substrings, case-insensitively, oldest first
+func (s *Store) GetUserAgentActivity(substrings []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.recent(func(e *models.LogEntry) bool {
+		userAgent := strings.ToLower(e.UserAgent)
+		for _, substring := range substrings {
+			if strings.Contains(userAgent, strings.ToLower(substring)) {
+				return true
+			}
+		}
+		return false
+	}, start, end, limit)
+	for i, entry := range entries {
+		entries[i] = &models.LogEntry{
+			Timestamp: entry.Timestamp, SourceIP: entry.SourceIP, Path: entry.Path,
+			StatusCode: entry.StatusCode, ResponseSize: entry.ResponseSize, UserAgent: entry.UserAgent,
+		}
+	}
+	return entries, nil
+}
+
+// GetEntriesByTypeOrStatus returns the most recent entries of one of the
+// log types or with one of the status codes, oldest first
+func (s *Store) GetEntriesByTypeOrStatus(logTypes []string, statusCodes []int, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.recent(func(e *models.LogEntry) bool {
+		return slices.Contains(logTypes, e.LogType) || slices.Contains(statusCodes, e.StatusCode)
+	}, start, end, limit), nil
+}
+
+// GetEntriesByPathPrefix returns the most recent entries whose path starts
+// with any of the prefixes, oldest first
+func (s *Store) GetEntriesByPathPrefix(prefixes []string, start, end time.Time, limit int) ([]*models.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.recent(func(e *models.LogEntry) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(e.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}, start, end, limit), nil
+}
+
+// GetStats returns the number of entries and their total response size
+func (s *Store) GetStats() (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var totalSize int64
+	for _, entry := range s.entries {
+		totalSize += entry.ResponseSize
+	}
+	return map[string]interface{}{
+		"total_logs":    int64(len(s.entries)),
+		"total_size":    totalSize,
+		"database_type": "memory",
+		"connected":     true,
+	}, nil
+}
+
+// methodGroup accumulates one row of GetMethodStats
+type methodGroup struct {
+	stats        models.MethodStats
+	timed        int64
+	totalTime    float64
+	errors       int64
+	serverErrors int64
+}
+
+// GetMethodStats aggregates requests by method, or by path and method,
+// busiest first
+func (s *Store) GetMethodStats(start, end time.Time, logType, path string, byPath bool, limit int) ([]models.MethodStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make(map[[2]string]*methodGroup)
+	var keys [][2]string
+	for _, entry := range s.entries {
+		if entry.Method == "" || entry.StatusCode <= 0 || !inRange(entry.Timestamp, start, end) ||
+			(logType != "" && entry.LogType != logType) || (path != "" && entry.Path != path) {
+			continue
+		}
+
+		key := [2]string{"", entry.Method}
+		if byPath {
+			key[0] = entry.Path
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &methodGroup{stats: models.MethodStats{Path: key[0], Method: key[1]}}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+
+		group.stats.Requests++
+		if entry.ProcessingTime > 0 {
+			group.timed++
+			group.totalTime += entry.ProcessingTime
+		}
+		group.stats.MaxResponseTime = max(group.stats.MaxResponseTime, entry.ProcessingTime)
+		if entry.StatusCode >= 400 {
+			group.errors++
+		}
+		if entry.StatusCode >= 500 {
+			group.serverErrors++
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return groups[keys[i]].stats.Requests > groups[keys[j]].stats.Requests })
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	stats := make([]models.MethodStats, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		if group.timed > 0 {
+			group.stats.AvgResponseTime = group.totalTime / float64(group.timed)
+		}
+		group.stats.ErrorRate = float64(group.errors) / float64(group.stats.Requests) * 100
+		group.stats.ServerErrorRate = float64(group.serverErrors) / float64(group.stats.Requests) * 100
+		stats = append(stats, group.stats)
+	}
+	return stats, nil
+}
+
+// countTop counts values and returns the busiest limit of them
+func countTop(values []string, limit int) ([]string, map[string]int64) {
+	counts := make(map[string]int64)
+	var keys []string
+	for _, value := range values {
+		if counts[value] == 0 {
+			keys = append(keys, value)
+		}
+		counts[value]++
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, counts
+}
+
+// GetTopOffenders counts entries inserted since the given time by path and
+// by source IP, busiest first
+func (s *Store) GetTopOffenders(since time.Time, minStatus, limit int) ([]models.PathStats, []models.IPStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var paths, ips []string
+	for _, entry := range s.entries {
+		if !entry.CreatedAt.Before(since) && entry.StatusCode >= minStatus {
+			paths = append(paths, entry.Path)
+			ips = append(ips, entry.SourceIP)
+		}
+	}
+
+	topPaths, pathCounts := countTop(paths, limit)
+	pathStats := make([]models.PathStats, len(topPaths))
+	for i, path := range topPaths {
+		pathStats[i] = models.PathStats{Path: path, Count: pathCounts[path]}
+	}
+
+	topIPs, ipCounts := countTop(ips, limit)
+	ipStats := make([]models.IPStats, len(topIPs))
+	for i, ip := range topIPs {
+		ipStats[i] = models.IPStats{IP: ip, Count: ipCounts[ip]}
+	}
+	return pathStats, ipStats, nil
+}
+
+// GetCountryActivity groups entries in [since, end) by their country
+// metadata field, counting requests and unique IPs from start onwards
+func (s *Store) GetCountryActivity(since, start, end time.Time) ([]models.CountryActivity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	countries := make(map[string]*models.CountryActivity)
+	ips := make(map[string]map[string]bool)
+	for _, entry := range s.entries {
+		value, ok := entry.Metadata["country"]
+		if !ok || value == nil || !inRange(entry.Timestamp, since, end) {
+			continue
+		}
+		country := fmt.Sprint(value)
+		if country == "" {
+			continue
+		}
+
+		activity, ok := countries[country]
+		if !ok {
+			activity = &models.CountryActivity{Country: country, FirstSeen: entry.Timestamp}
+			countries[country] = activity
+			ips[country] = make(map[string]bool)
+		}
+		if entry.Timestamp.Before(activity.FirstSeen) {
+			activity.FirstSeen = entry.Timestamp
+		}
+		if !entry.Timestamp.Before(start) {
+			activity.Requests++
+			if entry.SourceIP != "" {
+				ips[country][entry.SourceIP] = true
+			}
+		}
+	}
+
+	activity := make([]models.CountryActivity, 0, len(countries))
+	for country, a := range countries {
+		a.UniqueIPs = int64(len(ips[country]))
+		activity = append(activity, *a)
+	}
+	sort.Slice(activity, func(i, j int) bool { return activity[i].Country < activity[j].Country })
+	return activity, nil
+}
+
+// facetValues return the value of each facet field, empty for none
+var facetValues = map[string]func(*models.LogEntry) string{
+	"log_type":  func(e *models.LogEntry) string { return e.LogType },
+	"method":    func(e *models.LogEntry) string { return e.Method },
+	"path":      func(e *models.LogEntry) string { return e.Path },
+	"source_ip": func(e *models.LogEntry) string { return e.SourceIP },
+	"status_code": func(e *models.LogEntry) string {
+		if e.StatusCode <= 0 {
+			return ""
+		}
+		return strconv.Itoa(e.StatusCode)
+	},
+}
+
+func (s *Store) GetFacets(filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error) {
+	value, ok := facetValues[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown facet field: %s", field)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, entry := range s.entries {
+		if v := value(entry); v != "" && filter.Matches(entry) {
+			counts[v]++
+		}
+	}
+
+	facets := make([]models.FacetCount, 0, len(counts))
+	for v, count := range counts {
+		facets = append(facets, models.FacetCount{Value: v, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	if len(facets) > limit {
+		facets = facets[:max(limit, 0)]
+	}
+	return facets, nil
+}
+
+// GetRetentionStats counts the entries a cutoff applies to and those of
+// them it expires
+func (s *Store) GetRetentionStats(cutoff models.RetentionCutoff) (*models.RetentionStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &models.RetentionStats{}
+	for _, entry := range s.entries {
+		if !cutoff.Applies(entry.LogType) {
+			continue
+		}
+		stats.TotalEntries++
+		if stats.OldestEntry == nil || entry.Timestamp.Before(*stats.OldestEntry) {
+			oldest := entry.Timestamp
+			stats.OldestEntry = &oldest
+		}
+		if entry.Timestamp.Before(cutoff.Before) {
+			stats.ExpiredEntries++
+		}
+	}
+	return stats, nil
+}
+
+// ExpireEntries removes the entries a cutoff expires unless ctx is done
+func (s *Store) ExpireEntries(ctx context.Context, cutoff models.RetentionCutoff) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.entries)
+	s.entries = slices.DeleteFunc(s.entries, func(e *models.LogEntry) bool {
+		return e.Timestamp.Before(cutoff.Before) && cutoff.Applies(e.LogType)
+	})
+	return int64(before - len(s.entries)), nil
+}
+
+// ScanExpired returns up to limit of the entries a cutoff expires with IDs
+// above afterID in ID order unless ctx is done
+func (s *Store) ScanExpired(ctx context.Context, cutoff models.RetentionCutoff, afterID int64, limit int) ([]*models.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*models.LogEntry
+	for _, entry := range s.entries {
+		if entry.ID > afterID && entry.Timestamp.Before(cutoff.Before) && cutoff.Applies(entry.LogType) && len(entries) < limit {
+			entries = append(entries, copyEntry(entry))
+		}
+	}
+	return entries, nil
+}
+
+// DeleteLogsBefore removes entries older than cutoff
+func (s *Store) DeleteLogsBefore(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.entries)
+	s.entries = slices.DeleteFunc(s.entries, func(e *models.LogEntry) bool { return e.Timestamp.Before(cutoff) })
+	return int64(before - len(s.entries)), nil
+}
+
+// InsertBatch stores entries as InsertLogEntries does unless ctx is done
+func (s *Store) InsertBatch(ctx context.Context, entries []*models.LogEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.InsertLogEntries(entries)
+}
+
+// Find returns entries as QueryLogs does unless ctx is done
+func (s *Store) Find(ctx context.Context, filter *models.LogFilter) ([]*models.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.QueryLogs(filter)
+}
+
+// Stream calls fn with each entry Find returns, every matching entry
+// with a Limit of 0, until ctx is done
+func (s *Store) Stream(ctx context.Context, filter *models.LogFilter, fn func(*models.LogEntry) error) error {
+	all := *filter
+	if all.Limit <= 0 {
+		all.Limit = math.MaxInt
+	}
+	entries, err := s.Find(ctx, &all)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count counts the entries matching the filter unless ctx is done
+func (s *Store) Count(ctx context.Context, filter *models.LogFilter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, entry := range s.entries {
+		if filter.Matches(entry) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteOlderThan removes entries as DeleteLogsBefore does unless ctx is
+// done
+func (s *Store) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return s.DeleteLogsBefore(cutoff)
+}
+
+// Aggregate counts entries as GetFacets does unless ctx is done
+func (s *Store) Aggregate(ctx context.Context, filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetFacets(filter, field, limit)
+}
+
+// AggregateLogs computes the query's metrics over groups of the entries
+// matching its filter unless ctx is done
+func (s *Store) AggregateLogs(ctx context.Context, query *models.AggregateQuery) ([]models.AggregateRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("no aggregate metrics")
+	}
+	if query.Interval > 0 && query.Interval < time.Second {
+		return nil, fmt.Errorf("aggregate interval under a second: %s", query.Interval)
+	}
+	for _, field := range query.GroupBy {
+		if !slices.Contains(models.AggregateGroups, field) {
+			return nil, fmt.Errorf("unknown aggregate group: %s", field)
+		}
+	}
+	for _, metric := range query.Metrics {
+		if !slices.Contains(models.AggregateMetrics, metric) {
+			return nil, fmt.Errorf("unknown aggregate metric: %s", metric)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type group struct {
+		bucket  time.Time
+		values  []interface{}
+		entries []*models.LogEntry
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, entry := range s.entries {
+		if !query.Filter.Matches(entry) {
+			continue
+		}
+		var bucket time.Time
+		if query.Interval > 0 {
+			seconds := int64(query.Interval / time.Second)
+			bucket = time.Unix(entry.Timestamp.Unix()-mod(entry.Timestamp.Unix(), seconds), 0).UTC()
+		}
+		values := make([]interface{}, len(query.GroupBy))
+		key := bucket.String()
+		for i, field := range query.GroupBy {
+			values[i] = aggregateValue(entry, field)
+			key += "\x00" + fmt.Sprint(values[i])
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{bucket: bucket, values: values}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.entries = append(g.entries, entry)
+	}
+
+	rows := make([]models.AggregateRow, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		row := models.AggregateRow{Group: make(map[string]interface{}), Metrics: make(map[string]float64)}
+		if query.Interval > 0 {
+			row.Bucket = &g.bucket
+		}
+		for i, field := range query.GroupBy {
+			row.Group[field] = g.values[i]
+		}
+		for _, metric := range query.Metrics {
+			row.Metrics[metric] = aggregateMetric(metric, g.entries)
+		}
+		rows = append(rows, row)
+	}
+	first := query.Metrics[0]
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Bucket != nil && !a.Bucket.Equal(*b.Bucket) {
+			return a.Bucket.Before(*b.Bucket)
+		}
+		if a.Metrics[first] != b.Metrics[first] {
+			return a.Metrics[first] > b.Metrics[first]
+		}
+		for _, field := range query.GroupBy {
+			switch x := a.Group[field].(type) {
+			case int:
+				if y := b.Group[field].(int); x != y {
+					return x < y
+				}
+			case string:
+				if y := b.Group[field].(string); x != y {
+					return x < y
+				}
+			}
+		}
+		return false
+	})
+	if query.Limit > 0 && len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+	}
+	return rows, nil
+}
+
+// mod is the remainder of a divided by b, with the sign of b, so buckets
+// before the epoch start at their lower end as well
+func mod(a, b int64) int64 {
+	return (a%b + b) % b
+}
+
+// aggregateValue is the value of a field aggregates group by
+func aggregateValue(entry *models.LogEntry, field string) interface{} {
+	switch field {
+	case "log_type":
+		return entry.LogType
+	case "method":
+		return entry.Method
+	case "path":
+		return entry.Path
+	case "source_ip":
+		return entry.SourceIP
+	}
+	return entry.StatusCode
+}
+
+// aggregateMetric computes a metric over a group of entries
+func aggregateMetric(metric string, entries []*models.LogEntry) float64 {
+	var total float64
+	ips := make(map[string]bool)
+	for _, entry := range entries {
+		switch metric {
+		case "errors":
+			if entry.StatusCode >= 400 {
+				total++
+			}
+		case "unique_ips":
+			if entry.SourceIP != "" {
+				ips[entry.SourceIP] = true
+			}
+		case "avg_processing_time":
+			total += entry.ProcessingTime
+		case "max_processing_time":
+			total = max(total, entry.ProcessingTime)
+		case "avg_response_size", "sum_response_size":
+			total += float64(entry.ResponseSize)
+		}
+	}
+	switch metric {
+	case "count":
+		return float64(len(entries))
+	case "unique_ips":
+		return float64(len(ips))
+	case "avg_processing_time", "avg_response_size":
+		return total / float64(len(entries))
+	}
+	return total
+}
+
+// ScanAfter returns up to limit entries with IDs above afterID in ID order
+// unless ctx is done
+func (s *Store) ScanAfter(ctx context.Context, afterID int64, limit int) ([]*models.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Entries are kept in the order their IDs were given
+	var entries []*models.LogEntry
+	for _, entry := range s.entries {
+		if entry.ID > afterID && len(entries) < limit {
+			entries = append(entries, copyEntry(entry))
+		}
+	}
+	return entries, nil
+}
+
+// DeleteByIDs removes the entries with the given IDs unless ctx is done
+func (s *Store) DeleteByIDs(ctx context.Context, ids []int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.entries)
+	s.entries = slices.DeleteFunc(s.entries, func(e *models.LogEntry) bool { return slices.Contains(ids, e.ID) })
+	return int64(before - len(s.entries)), nil
+}
+
+// DeleteMatching removes the entries matching the filter unless ctx is
+// done
+func (s *Store) DeleteMatching(ctx context.Context, filter *models.LogFilter) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.entries)
+	s.entries = slices.DeleteFunc(s.entries, func(e *models.LogEntry) bool { return filter.Matches(e) })
+	return int64(before - len(s.entries)), nil
+}
+
+// UpdateContent replaces the content of stored entries unless ctx is done
+func (s *Store) UpdateContent(ctx context.Context, entries []*models.LogEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		for _, stored := range s.entries {
+			if stored.ID != entry.ID {
+				continue
+			}
+			c := copyEntry(entry)
+			stored.SourceIP, stored.Path, stored.UserAgent, stored.Referer = c.SourceIP, c.Path, c.UserAgent, c.Referer
+			stored.RawLog, stored.Metadata = c.RawLog, c.Metadata
+		}
+	}
+	return nil
+}
+
+// GetAlertRules returns rules ordered by ID
+func (s *Store) GetAlertRules(activeOnly bool) ([]*models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rules []*models.AlertRule
+	for _, rule := range s.rules {
+		if !activeOnly || rule.IsActive {
+			c := *rule
+			rules = append(rules, &c)
+		}
+	}
+	return rules, nil
+}
+
+// CreateAlertRule stores a rule and sets its ID
+func (s *Store) CreateAlertRule(rule *models.AlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.ID = s.newID()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+	c := *rule
+	s.rules = append(s.rules, &c)
+	return nil
+}
+
+// UpdateAlertRule replaces the rule with the same ID
+func (s *Store) UpdateAlertRule(rule *models.AlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.rules {
+		if existing.ID == rule.ID {
+			rule.CreatedAt = existing.CreatedAt
+			rule.UpdatedAt = time.Now()
+			c := *rule
+			s.rules[i] = &c
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+// InsertAlertEvent stores the persisted fields of a fired alert and sets
+// its ID
+func (s *Store) InsertAlertEvent(event *models.AlertEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.ID = s.newID()
+	s.events = append(s.events, &models.AlertEvent{
+		ID:          event.ID,
+		RuleID:      event.RuleID,
+		Message:     event.Message,
+		Severity:    event.Severity,
+		TriggeredAt: event.TriggeredAt,
+	})
+	return nil
+}
+
+// GetAlertHistory returns the most recently triggered alerts first
+func (s *Store) GetAlertHistory(limit int) ([]*models.AlertEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make(map[int64]string)
+	for _, rule := range s.rules {
+		names[rule.ID] = rule.Name
+	}
+
+	events := make([]*models.AlertEvent, 0, len(s.events))
+	for _, event := range s.events {
+		c := *event
+		c.RuleName = names[event.RuleID]
+		events = append(events, &c)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].TriggeredAt.After(events[j].TriggeredAt) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// InsertRuleEvaluations stores the outcomes of an evaluation of the rules
+func (s *Store) InsertRuleEvaluations(ctx context.Context, evaluations []models.RuleEvaluation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, evaluation := range evaluations {
+		evaluation.Details = maps.Clone(evaluation.Details)
+		s.evaluations = append(s.evaluations, evaluation)
+	}
+	return nil
+}
+
+// GetRuleEvaluations returns the last limit evaluations of a rule at or
+// after since, oldest first
+func (s *Store) GetRuleEvaluations(ctx context.Context, ruleID int64, since time.Time, limit int) ([]models.RuleEvaluation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var evaluations []models.RuleEvaluation
+	for _, evaluation := range s.evaluations {
+		if evaluation.RuleID == ruleID && !evaluation.EvaluatedAt.Before(since) {
+			evaluation.Details = maps.Clone(evaluation.Details)
+			evaluations = append(evaluations, evaluation)
+		}
+	}
+	sort.SliceStable(evaluations, func(i, j int) bool { return evaluations[i].EvaluatedAt.Before(evaluations[j].EvaluatedAt) })
+	if len(evaluations) > limit {
+		evaluations = evaluations[len(evaluations)-limit:]
+	}
+	return evaluations, nil
+}
+
+// DeleteRuleEvaluations removes evaluations made before cutoff
+func (s *Store) DeleteRuleEvaluations(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.evaluations)
+	s.evaluations = slices.DeleteFunc(s.evaluations, func(evaluation models.RuleEvaluation) bool {
+		return evaluation.EvaluatedAt.Before(cutoff)
+	})
+	return int64(before - len(s.evaluations)), nil
+}
+
+// AcknowledgeAlertEvent marks a fired alert as acknowledged
+func (s *Store) AcknowledgeAlertEvent(id int64, by string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range s.events {
+		if event.ID == id && event.AcknowledgedAt == nil {
+			event.AcknowledgedAt = &at
+			event.AcknowledgedBy = by
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+// CreateMaintenanceWindow stores a window and sets its ID
+func (s *Store) CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window.ID = s.newID()
+	window.CreatedAt = time.Now()
+	c := *window
+	s.windows = append(s.windows, &c)
+	return nil
+}
+
+// GetMaintenanceWindows returns windows overlapping [start, end), ordered
+// by start time
+func (s *Store) GetMaintenanceWindows(start, end time.Time) ([]*models.MaintenanceWindow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var windows []*models.MaintenanceWindow
+	for _, window := range s.windows {
+		if window.StartsAt.Before(end) && window.EndsAt.After(start) {
+			c := *window
+			windows = append(windows, &c)
+		}
+	}
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].StartsAt.Before(windows[j].StartsAt) })
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow removes a window, reporting whether it existed
+func (s *Store) DeleteMaintenanceWindow(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.windows)
+	s.windows = slices.DeleteFunc(s.windows, func(w *models.MaintenanceWindow) bool { return w.ID == id })
+	return len(s.windows) < before, nil
+}
+
+// CreateLatencyBudget stores a budget and sets its ID
+func (s *Store) CreateLatencyBudget(budget *models.LatencyBudget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	budget.ID = s.newID()
+	budget.CreatedAt = time.Now()
+	c := *budget
+	s.budgets = append(s.budgets, &c)
+	return nil
+}
+
+// GetLatencyBudgets returns every budget, ordered by path and percentile
+func (s *Store) GetLatencyBudgets() ([]*models.LatencyBudget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	budgets := make([]*models.LatencyBudget, 0, len(s.budgets))
+	for _, budget := range s.budgets {
+		c := *budget
+		budgets = append(budgets, &c)
+	}
+	sort.SliceStable(budgets, func(i, j int) bool {
+		if budgets[i].Path != budgets[j].Path {
+			return budgets[i].Path < budgets[j].Path
+		}
+		return budgets[i].Percentile < budgets[j].Percentile
+	})
+	return budgets, nil
+}
+
+// DeleteLatencyBudget removes a budget, reporting whether it existed
+func (s *Store) DeleteLatencyBudget(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.budgets)
+	s.budgets = slices.DeleteFunc(s.budgets, func(b *models.LatencyBudget) bool { return b.ID == id })
+	return len(s.budgets) < before, nil
+}
+
+// GetRetentionPolicies returns the stored policies ordered by log type
+func (s *Store) GetRetentionPolicies() ([]*models.RetentionPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := make([]*models.RetentionPolicy, 0, len(s.retention))
+	for _, policy := range s.retention {
+		c := *policy
+		policies = append(policies, &c)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].LogType < policies[j].LogType })
+	return policies, nil
+}
+
+// SetRetentionPolicy stores a policy, replacing any for the same log type
+func (s *Store) SetRetentionPolicy(policy *models.RetentionPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *policy
+	for i, existing := range s.retention {
+		if existing.LogType == c.LogType {
+			s.retention[i] = &c
+			return nil
+		}
+	}
+	s.retention = append(s.retention, &c)
+	return nil
+}
+
+// DeleteRetentionPolicy removes a policy, reporting whether it existed
+func (s *Store) DeleteRetentionPolicy(logType string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.retention)
+	s.retention = slices.DeleteFunc(s.retention, func(p *models.RetentionPolicy) bool { return p.LogType == logType })
+	return len(s.retention) < before, nil
+}
+
+// GetFeatureOverrides returns overrides ordered by flag and project
+func (s *Store) GetFeatureOverrides() ([]*models.FeatureOverride, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	overrides := make([]*models.FeatureOverride, 0, len(s.overrides))
+	for _, override := range s.overrides {
+		c := *override
+		overrides = append(overrides, &c)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Flag != overrides[j].Flag {
+			return overrides[i].Flag < overrides[j].Flag
+		}
+		return overrides[i].Project < overrides[j].Project
+	})
+	return overrides, nil
+}
+
+// SetFeatureOverride stores an override, replacing any for the same flag
+// and project
+func (s *Store) SetFeatureOverride(override *models.FeatureOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *override
+	for i, existing := range s.overrides {
+		if existing.Flag == c.Flag && existing.Project == c.Project {
+			s.overrides[i] = &c
+			return nil
+		}
+	}
+	s.overrides = append(s.overrides, &c)
+	return nil
+}
+
+// DeleteFeatureOverride removes an override, reporting whether it existed
+func (s *Store) DeleteFeatureOverride(flag, project string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.overrides)
+	s.overrides = slices.DeleteFunc(s.overrides, func(o *models.FeatureOverride) bool {
+		return o.Flag == flag && o.Project == project
+	})
+	return len(s.overrides) < before, nil
+}
+
+// AppendAuditRecord seals the record onto the end of the audit chain
+func (s *Store) AppendAuditRecord(record *models.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev *models.AuditRecord
+	if len(s.auditRecords) > 0 {
+		prev = s.auditRecords[len(s.auditRecords)-1]
+	}
+	audit.Seal(record, prev)
+	c := *record
+	s.auditRecords = append(s.auditRecords, &c)
+	return nil
+}
+
+// GetAuditRecords returns up to limit records after afterSeq, in order
+func (s *Store) GetAuditRecords(afterSeq int64, limit int) ([]*models.AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*models.AuditRecord
+	for _, record := range s.auditRecords {
+		if record.Seq > afterSeq && len(records) < limit {
+			c := *record
+			records = append(records, &c)
+		}
+	}
+	return records, nil
+}
+
+// copyVersion copies a version, including its state
+func copyVersion(version *models.ConfigVersion) *models.ConfigVersion {
+	c := *version
+	c.State = slices.Clone(version.State)
+	return &c
+}
+
+// AppendConfigVersion stores a version as the latest of its object
+func (s *Store) AppendConfigVersion(version *models.ConfigVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version.Version = 1
+	for _, existing := range s.versions {
+		if existing.Kind == version.Kind && existing.ObjectID == version.ObjectID {
+			version.Version = max(version.Version, existing.Version+1)
+		}
+	}
+	version.ID = s.newID()
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+	s.versions = append(s.versions, copyVersion(version))
+	return nil
+}
+
+// GetConfigVersions returns versions of an object, a kind or everything,
+// most recent first
+func (s *Store) GetConfigVersions(kind, objectID string, limit int) ([]*models.ConfigVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var versions []*models.ConfigVersion
+	for i := len(s.versions) - 1; i >= 0 && len(versions) < limit; i-- {
+		version := s.versions[i]
+		if (kind == "" || version.Kind == kind) && (objectID == "" || version.ObjectID == objectID) {
+			versions = append(versions, copyVersion(version))
+		}
+	}
+	return versions, nil
+}
+
+// GetConfigVersion returns a version of an object
+func (s *Store) GetConfigVersion(kind, objectID string, version int) (*models.ConfigVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, existing := range s.versions {
+		if existing.Kind == kind && existing.ObjectID == objectID && existing.Version == version {
+			return copyVersion(existing), nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// GetAuditRecord returns the record with the given sequence number
+func (s *Store) GetAuditRecord(seq int64) (*models.AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, record := range s.auditRecords {
+		if record.Seq == seq {
+			c := *record
+			return &c, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// GetIngestedFile returns the processed file with the given SHA-256
+func (s *Store) GetIngestedFile(sha256 string) (*models.IngestedFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, ok := s.files[sha256]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	c := *file
+	return &c, nil
+}
+
+// RecordIngestedFile stores a processed file, keeping the first record of
+// a file processed more than once
+func (s *Store) RecordIngestedFile(file *models.IngestedFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.files[file.SHA256]; exists {
+		return nil
+	}
+	if s.files == nil {
+		s.files = make(map[string]*models.IngestedFile)
+	}
+	c := *file
+	s.files[file.SHA256] = &c
+	return nil
+}
+
+// GetReportFile returns the metadata of the report file with the given name
+func (s *Store) GetReportFile(name string) (*models.ReportFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, ok := s.reportFiles[name]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	c := *file
+	return &c, nil
+}
+
+// SaveReportFile stores the metadata of a report file, replacing any for a
+// file with the same name
+func (s *Store) SaveReportFile(file *models.ReportFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reportFiles == nil {
+		s.reportFiles = make(map[string]*models.ReportFile)
+	}
+	c := *file
+	s.reportFiles[file.Name] = &c
+	return nil
+}
+
+// GetReportTemplates returns templates without their content, ordered by
+// report type and name
+func (s *Store) GetReportTemplates() ([]*models.ReportTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]*models.ReportTemplate, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		c := *tmpl
+		c.Content = ""
+		templates = append(templates, &c)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].ReportType != templates[j].ReportType {
+			return templates[i].ReportType < templates[j].ReportType
+		}
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// GetReportTemplate returns the template with the given report type and
+// name
+func (s *Store) GetReportTemplate(reportType, name string) (*models.ReportTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, tmpl := range s.templates {
+		if tmpl.ReportType == reportType && tmpl.Name == name {
+			c := *tmpl
+			return &c, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// SaveReportTemplate stores a template, replacing any with the same report
+// type and name
+func (s *Store) SaveReportTemplate(tmpl *models.ReportTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *tmpl
+	for i, existing := range s.templates {
+		if existing.ReportType == c.ReportType && existing.Name == c.Name {
+			s.templates[i] = &c
+			return nil
+		}
+	}
+	s.templates = append(s.templates, &c)
+	return nil
+}
+
+// DeleteReportTemplate removes a template, reporting whether it existed
+func (s *Store) DeleteReportTemplate(reportType, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.templates)
+	s.templates = slices.DeleteFunc(s.templates, func(t *models.ReportTemplate) bool {
+		return t.ReportType == reportType && t.Name == name
+	})
+	return len(s.templates) < before, nil
+}
+
+// GetSavedSearches returns an owner's saved searches, ordered by name
+func (s *Store) GetSavedSearches(owner string) ([]*models.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var searches []*models.SavedSearch
+	for _, search := range s.searches {
+		if search.Owner == owner {
+			searches = append(searches, copySavedSearch(search))
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].Name < searches[j].Name })
+	return searches, nil
+}
+
+// GetSavedSearch returns the saved search with the given owner and name
+func (s *Store) GetSavedSearch(owner, name string) (*models.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, search := range s.searches {
+		if search.Owner == owner && search.Name == name {
+			return copySavedSearch(search), nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// SaveSavedSearch stores a saved search, replacing any with the same owner
+// and name but keeping its CreatedAt
+func (s *Store) SaveSavedSearch(search *models.SavedSearch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := copySavedSearch(search)
+	for i, existing := range s.searches {
+		if existing.Owner == c.Owner && existing.Name == c.Name {
+			c.CreatedAt = existing.CreatedAt
+			s.searches[i] = c
+			return nil
+		}
+	}
+	s.searches = append(s.searches, c)
+	return nil
+}
+
+// DeleteSavedSearch removes a saved search, reporting whether it existed
+func (s *Store) DeleteSavedSearch(owner, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.searches)
+	s.searches = slices.DeleteFunc(s.searches, func(search *models.SavedSearch) bool {
+		return search.Owner == owner && search.Name == name
+	})
+	return len(s.searches) < before, nil
+}
+
+// copySavedSearch copies a saved search and the values its filter points
+// to
+func copySavedSearch(search *models.SavedSearch) *models.SavedSearch {
+	c := *search
+	filter := &c.Filter
+	if filter.StartTime != nil {
+		start := *filter.StartTime
+		filter.StartTime = &start
+	}
+	if filter.EndTime != nil {
+		end := *filter.EndTime
+		filter.EndTime = &end
+	}
+	if filter.StatusCode != nil {
+		status := *filter.StatusCode
+		filter.StatusCode = &status
+	}
+	// Positions are not saved, as they are not stored in SQL backends
+	filter.After, filter.Before = nil, nil
+	return &c
+}
+
+// GetRoles returns the roles, ordered by level
+func (s *Store) GetRoles() ([]*models.Role, error) {
+	result := make([]*models.Role, len(roles))
+	for i := range roles {
+		c := roles[i]
+		result[i] = &c
+	}
+	return result, nil
+}
+
+// GetUsers returns the users, ordered by subject
+func (s *Store) GetUsers() ([]*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Subject < users[j].Subject })
+	return users, nil
+}
+
+// GetUser returns the user with the given subject
+func (s *Store) GetUser(subject string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[subject]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return copyUser(user), nil
+}
+
+// SaveUser stores a user, replacing any with the same subject
+func (s *Store) SaveUser(user *models.User) error {
+	if !slices.ContainsFunc(roles, func(r models.Role) bool { return r.Name == user.Role }) {
+		return fmt.Errorf("unknown role %q", user.Role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.users == nil {
+		s.users = make(map[string]*models.User)
+	}
+	s.users[user.Subject] = copyUser(user)
+	return nil
+}
+
+// DeleteUser removes a user, reporting whether they existed
+func (s *Store) DeleteUser(subject string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.users[subject]
+	delete(s.users, subject)
+	return ok, nil
+}
+
+func copyUser(user *models.User) *models.User {
+	c := *user
+	if user.LastSignInAt != nil {
+		at := *user.LastSignInAt
+		c.LastSignInAt = &at
+	}
+	return &c
+}
+
+// GetDataKey returns the data key of a project
+func (s *Store) GetDataKey(project string) (*models.DataKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.dataKeys[project]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	c := *key
+	c.WrappedKey = slices.Clone(key.WrappedKey)
+	return &c, nil
+}
+
+// CreateDataKey stores a project's data key unless it already has one
+func (s *Store) CreateDataKey(key *models.DataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dataKeys == nil {
+		s.dataKeys = make(map[string]*models.DataKey)
+	}
+	if _, ok := s.dataKeys[key.Project]; ok {
+		return nil
+	}
+	c := *key
+	c.WrappedKey = slices.Clone(key.WrappedKey)
+	s.dataKeys[key.Project] = &c
+	return nil
+}
+
+// GetOrphanedAlertEvents counts fired alerts whose rule does not exist.
+// The store does not check rule IDs when alerts are inserted.
+func (s *Store) GetOrphanedAlertEvents() ([]models.OrphanedAlertEvents, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make(map[int64]bool)
+	for _, rule := range s.rules {
+		rules[rule.ID] = true
+	}
+	counts := make(map[int64]int64)
+	for _, event := range s.events {
+		if !rules[event.RuleID] {
+			counts[event.RuleID]++
+		}
+	}
+
+	var orphans []models.OrphanedAlertEvents
+	for ruleID, events := range counts {
+		orphans = append(orphans, models.OrphanedAlertEvents{RuleID: ruleID, Events: events})
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].RuleID < orphans[j].RuleID })
+	return orphans, nil
+}
+
+// GetRollupTotals returns nothing; the store keeps no roll-ups
+func (s *Store) GetRollupTotals(start, end time.Time) ([]models.RollupTotal, error) {
+	return nil, nil
+}
+
+// maxRollupPath is the length paths are cut to in traffic rollups, as the
+// SQL backends do
+const maxRollupPath = 500
+
+// RefreshTrafficRollups replaces a bucket's rollups with totals of its
+// entries
+func (s *Store) RefreshTrafficRollups(ctx context.Context, interval string, bucket time.Time) error {
+	length, ok := models.RollupIntervals[interval]
+	if !ok {
+		return fmt.Errorf("unknown rollup interval %q", interval)
+	}
+	bucket = bucket.UTC()
+	end := bucket.Add(length)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type key struct {
+		logType, path string
+		statusCode    int
+	}
+	totals := make(map[key]*models.TrafficRollup)
+	ips := make(map[key]map[string]bool)
+	var keys []key
+	for _, entry := range s.entries {
+		if !inRange(entry.Timestamp, bucket, end) {
+			continue
+		}
+		path := entry.Path
+		if runes := []rune(path); len(runes) > maxRollupPath {
+			path = string(runes[:maxRollupPath])
+		}
+		k := key{entry.LogType, path, entry.StatusCode}
+		total, ok := totals[k]
+		if !ok {
+			total = &models.TrafficRollup{Bucket: bucket, LogType: k.logType, Path: k.path, StatusCode: k.statusCode}
+			totals[k] = total
+			ips[k] = make(map[string]bool)
+			keys = append(keys, k)
+		}
+		total.Requests++
+		if entry.StatusCode >= 400 {
+			total.Errors++
+		}
+		total.Bytes += entry.ResponseSize
+		if entry.SourceIP != "" {
+			ips[k][entry.SourceIP] = true
+		}
+	}
+
+	if s.rollups == nil {
+		s.rollups = make(map[string][]models.TrafficRollup)
+	}
+	rollups := slices.DeleteFunc(s.rollups[interval], func(rollup models.TrafficRollup) bool {
+		return rollup.Bucket.Equal(bucket)
+	})
+	for _, k := range keys {
+		total := totals[k]
+		total.UniqueIPs = int64(len(ips[k]))
+		rollups = append(rollups, *total)
+	}
+	s.rollups[interval] = rollups
+	return nil
+}
+
+// SummarizeTraffic sums the rollups of the query's buckets
+func (s *Store) SummarizeTraffic(ctx context.Context, query *models.TrafficQuery) ([]models.TrafficRollup, error) {
+	if _, ok := models.RollupIntervals[query.Interval]; !ok {
+		return nil, fmt.Errorf("unknown rollup interval %q", query.Interval)
+	}
+	grouped := make(map[string]bool, len(query.GroupBy))
+	for _, field := range query.GroupBy {
+		if !slices.Contains(models.TrafficGroups, field) {
+			return nil, fmt.Errorf("cannot group traffic by %q", field)
+		}
+		grouped[field] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[models.TrafficRollup]*models.TrafficRollup)
+	var keys []models.TrafficRollup
+	for _, rollup := range s.rollups[query.Interval] {
+		if !inRange(rollup.Bucket, query.Start, query.End) || (query.LogType != "" && rollup.LogType != query.LogType) {
+			continue
+		}
+		var k models.TrafficRollup
+		if grouped["bucket"] {
+			k.Bucket = rollup.Bucket
+		}
+		if grouped["log_type"] {
+			k.LogType = rollup.LogType
+		}
+		if grouped["path"] {
+			k.Path = rollup.Path
+		}
+		if grouped["status_code"] {
+			k.StatusCode = rollup.StatusCode
+		}
+		total, ok := totals[k]
+		if !ok {
+			total = &models.TrafficRollup{Bucket: k.Bucket, LogType: k.LogType, Path: k.Path, StatusCode: k.StatusCode}
+			totals[k] = total
+			keys = append(keys, k)
+		}
+		total.Requests += rollup.Requests
+		total.Errors += rollup.Errors
+		total.Bytes += rollup.Bytes
+		total.UniqueIPs += rollup.UniqueIPs
+	}
+	if len(query.GroupBy) == 0 {
+		if total, ok := totals[models.TrafficRollup{}]; ok {
+			return []models.TrafficRollup{*total}, nil
+		}
+		return []models.TrafficRollup{{}}, nil
+	}
+
+	compareGroups := func(a, b *models.TrafficRollup) int {
+		for _, field := range query.GroupBy {
+			var c int
+			switch field {
+			case "bucket":
+				c = a.Bucket.Compare(b.Bucket)
+			case "log_type":
+				c = strings.Compare(a.LogType, b.LogType)
+			case "path":
+				c = strings.Compare(a.Path, b.Path)
+			case "status_code":
+				c = a.StatusCode - b.StatusCode
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	rollups := make([]models.TrafficRollup, 0, len(keys))
+	for _, k := range keys {
+		rollups = append(rollups, *totals[k])
+	}
+	slices.SortFunc(rollups, func(a, b models.TrafficRollup) int {
+		if !grouped["bucket"] && a.Requests != b.Requests {
+			if a.Requests > b.Requests {
+				return -1
+			}
+			return 1
+		}
+		return compareGroups(&a, &b)
+	})
+	if query.Limit > 0 && len(rollups) > query.Limit {
+		rollups = rollups[:query.Limit]
+	}
+	return rollups, nil
+}
+
+// DeleteTrafficRollups removes the interval's rollups of buckets before
+// cutoff
+func (s *Store) DeleteTrafficRollups(ctx context.Context, interval string, cutoff time.Time) (int64, error) {
+	if _, ok := models.RollupIntervals[interval]; !ok {
+		return 0, fmt.Errorf("unknown rollup interval %q", interval)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rollups == nil {
+		return 0, nil
+	}
+	before := len(s.rollups[interval])
+	s.rollups[interval] = slices.DeleteFunc(s.rollups[interval], func(rollup models.TrafficRollup) bool {
+		return rollup.Bucket.Before(cutoff)
+	})
+	return int64(before - len(s.rollups[interval])), nil
+}
+
+// HealthCheck always succeeds
+func (s *Store) HealthCheck() error {
+	return nil
+}
+
+// Close releases nothing; the data is dropped with the store
+func (s *Store) Close() error {
+	return nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return New()
+	})
+}
+
+func TestRegistered(t *testing.T) {
+	s, err := storage.Open(&config.Config{Database: config.DatabaseConfig{Type: "memory"}})
+	require.NoError(t, err)
+	assert.IsType(t, &Store{}, s)
+	assert.Contains(t, storage.Backends(), "memory")
+
+	_, err = storage.Open(&config.Config{Database: config.DatabaseConfig{Type: "clickhouse"}})
+	assert.EqualError(t, err, "unsupported database type: clickhouse")
+}
+
+func TestRecordsAreCopied(t *testing.T) {
+	s := New()
+	entry := &models.LogEntry{LogType: "generic", Path: "stored", Metadata: models.LogMetadata{"level": "info"}}
+	require.NoError(t, s.InsertLogEntry(entry))
+	entry.Path = "changed"
+	entry.Metadata["level"] = "error"
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "stored", logs[0].Path)
+	assert.Equal(t, "info", logs[0].Metadata["level"])
+}
+
+func TestOrphanedAlertEvents(t *testing.T) {
+	s := New()
+	rule := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300, IsActive: true}
+	require.NoError(t, s.CreateAlertRule(rule))
+	for _, ruleID := range []int64{rule.ID, 42, 7, 42} {
+		require.NoError(t, s.InsertAlertEvent(&models.AlertEvent{RuleID: ruleID, Message: "fired", Severity: "warning"}))
+	}
+
+	orphans, err := s.GetOrphanedAlertEvents()
+	require.NoError(t, err)
+	assert.Equal(t, []models.OrphanedAlertEvents{{RuleID: 7, Events: 1}, {RuleID: 42, Events: 2}}, orphans)
+}
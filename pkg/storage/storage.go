@@ -0,0 +1,412 @@
+// Package storage defines the backend contract the server stores logs,
+// alerts, maintenance windows, latency budgets, feature flag overrides, the versions of
+// configuration objects, report templates, users and the audit log
+// through, and the files already ingested. Backends register a Factory under a database type and are
+// opened with Open; the storagetest package verifies that a backend
+// honours the contract.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/config"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ErrNotFound is returned for a record that does not exist or is not in
+// the state an update needs. It is sql.ErrNoRows so SQL backends can
+// return driver errors unchanged.
+var ErrNotFound = sql.ErrNoRows
+
+// Storage is everything the server needs from a backend.
+//
+// Time ranges are half-open, [start, end). Methods returning "the most
+// recent limit entries, oldest first" select by timestamp descending and
+// return the selection in ascending order.
+type Storage interface {
+	LogStore
+	LogRepository
+	AggregateStore
+	RetentionStore
+	AlertStore
+	MaintenanceStore
+	LatencyBudgetStore
+	FeatureStore
+	ConfigVersionStore
+	AuditStore
+	IngestedFileStore
+	ReportFileStore
+	ReportTemplateStore
+	SavedSearchStore
+	UserStore
+	DataKeyStore
+	IntegrityStore
+	TrafficRollupStore
+
+	// HealthCheck reports whether the backend is reachable
+	HealthCheck() error
+	Close() error
+}
+
+// LogStore stores and retrieves log entries
+type LogStore interface {
+	// InsertLogEntry stores an entry and sets its ID. The backend records
+	// the time of insertion as its CreatedAt. An entry with a FileHash is
+	// skipped, its ID set to 0, when one with the same FileHash and
+	// LineNumber is stored already, whatever its Timestamp. Deleting an
+	// entry lets its line be stored again.
+	InsertLogEntry(entry *models.LogEntry) error
+	// InsertLogEntries stores entries as InsertLogEntry does, all or none
+	InsertLogEntries(entries []*models.LogEntry) error
+	// QueryLogs returns entries matching the filter, most recent first
+	// and by ID, descending, among entries of the same time, or as
+	// SortBy and SortAscending order them, skipping Offset and returning
+	// at most Limit. Path matches as a substring; StartTime and EndTime
+	// bound a half-open range. After and Before select the entries
+	// listed after or before a position.
+	QueryLogs(filter *models.LogFilter) ([]*models.LogEntry, error)
+	// GetLogMessages returns the most recent entries of the given log
+	// types, most recent first, with only Timestamp, LogType and Path set
+	GetLogMessages(logTypes []string, start, end time.Time, limit int) ([]*models.LogEntry, error)
+	// GetSourceActivity returns the most recent entries with a source IP,
+	// oldest first, with Timestamp, SourceIP, Path, StatusCode, UserAgent
+	// and Metadata set
+	GetSourceActivity(start, end time.Time, limit int) ([]*models.LogEntry, error)
+	// GetUserAgentActivity returns the most recent entries whose user
+	// agent contains any of the substrings, case-insensitively, oldest
+	// first, with Timestamp, SourceIP, Path, StatusCode, ResponseSize and
+	// UserAgent set
+	GetUserAgentActivity(substrings []string, start, end time.Time, limit int) ([]*models.LogEntry, error)
+	// GetEntriesByTypeOrStatus returns the most recent complete entries of
+	// one of the log types or with one of the status codes, oldest first
+	GetEntriesByTypeOrStatus(logTypes []string, statusCodes []int, start, end time.Time, limit int) ([]*models.LogEntry, error)
+	// GetEntriesByPathPrefix returns the most recent complete entries whose
+	// path starts with any of the prefixes, oldest first
+	GetEntriesByPathPrefix(prefixes []string, start, end time.Time, limit int) ([]*models.LogEntry, error)
+}
+
+// LogRepository is the log entry access of request handlers. Each call
+// follows its context, giving up once the request is cancelled or past its
+// deadline, and SQL backends also bound it by the configured query or
+// write timeout. The other stores run without a context.
+type LogRepository interface {
+	// InsertBatch stores entries as InsertLogEntries does, all or none
+	InsertBatch(ctx context.Context, entries []*models.LogEntry) error
+	// Find returns entries matching the filter as QueryLogs does
+	Find(ctx context.Context, filter *models.LogFilter) ([]*models.LogEntry, error)
+	// Stream calls fn with each entry matching the filter in Find's order,
+	// reading them from a cursor instead of loading them all, and stops
+	// at the first error fn returns. A Limit of 0 streams every entry.
+	// SQL backends do not bound it by the query timeout, since it lasts
+	// as long as fn takes.
+	Stream(ctx context.Context, filter *models.LogFilter, fn func(*models.LogEntry) error) error
+	// Count counts the entries matching the filter, ignoring its Limit,
+	// Offset, After and Before
+	Count(ctx context.Context, filter *models.LogFilter) (int64, error)
+	// DeleteOlderThan removes entries as DeleteLogsBefore does
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	// DeleteMatching removes the entries matching the filter, ignoring
+	// its Limit, Offset, order and position, and returns how many were
+	// removed
+	DeleteMatching(ctx context.Context, filter *models.LogFilter) (int64, error)
+	// Aggregate counts the entries matching the filter by a field as
+	// GetFacets does
+	Aggregate(ctx context.Context, filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error)
+	// AggregateLogs computes metrics over groups of the entries matching
+	// a filter, as the query describes
+	AggregateLogs(ctx context.Context, query *models.AggregateQuery) ([]models.AggregateRow, error)
+	// ScanAfter returns up to limit entries with IDs above afterID in ID
+	// order, so every entry can be visited in batches
+	ScanAfter(ctx context.Context, afterID int64, limit int) ([]*models.LogEntry, error)
+	// DeleteByIDs removes the entries with the given IDs and returns how
+	// many were removed
+	DeleteByIDs(ctx context.Context, ids []int64) (int64, error)
+	// UpdateContent replaces the source IP, path, user agent, referer, raw
+	// log and metadata of the stored entries with the entries' IDs
+	UpdateContent(ctx context.Context, entries []*models.LogEntry) error
+}
+
+// AggregateStore summarizes stored entries
+type AggregateStore interface {
+	// GetStats returns at least total_logs, total_size (the sum of
+	// response sizes), database_type and connected
+	GetStats() (map[string]interface{}, error)
+	// GetMethodStats aggregates entries with a method and a status code
+	// by method, or by path and method when byPath is set, busiest first.
+	// Averages leave out entries without a processing time.
+	GetMethodStats(start, end time.Time, logType, path string, byPath bool, limit int) ([]models.MethodStats, error)
+	// GetTopOffenders counts entries inserted since the given time with at
+	// least minStatus by path and by source IP, busiest first
+	GetTopOffenders(since time.Time, minStatus, limit int) ([]models.PathStats, []models.IPStats, error)
+	// GetCountryActivity groups entries in [since, end) by their country
+	// metadata field, counting requests and unique IPs from start onwards,
+	// ordered by country
+	GetCountryActivity(since, start, end time.Time) ([]models.CountryActivity, error)
+	// GetFacets counts the entries matching the filter by the value of one
+	// of models.FacetFields, most common first and then by value, and
+	// returns at most limit values. Entries without a value are left out,
+	// and the filter's Limit and Offset are ignored.
+	GetFacets(filter *models.LogFilter, field string, limit int) ([]models.FacetCount, error)
+}
+
+// RetentionStore expires old entries and stores the retention policies of
+// log types
+type RetentionStore interface {
+	// GetRetentionStats counts the entries a cutoff applies to and those
+	// of them it expires
+	GetRetentionStats(cutoff models.RetentionCutoff) (*models.RetentionStats, error)
+	// ExpireEntries removes the entries a cutoff expires and returns how
+	// many were removed
+	ExpireEntries(ctx context.Context, cutoff models.RetentionCutoff) (int64, error)
+	// ScanExpired returns up to limit of the entries a cutoff expires
+	// with IDs above afterID, in ID order, so they can be archived first
+	ScanExpired(ctx context.Context, cutoff models.RetentionCutoff, afterID int64, limit int) ([]*models.LogEntry, error)
+	// DeleteLogsBefore removes entries older than cutoff and returns how
+	// many were removed
+	DeleteLogsBefore(cutoff time.Time) (int64, error)
+	// GetRetentionPolicies returns the stored policies ordered by log type
+	GetRetentionPolicies() ([]*models.RetentionPolicy, error)
+	// SetRetentionPolicy stores a policy, replacing any for the same log
+	// type
+	SetRetentionPolicy(policy *models.RetentionPolicy) error
+	// DeleteRetentionPolicy reports whether the policy existed
+	DeleteRetentionPolicy(logType string) (bool, error)
+}
+
+// AlertStore stores alert rules and fired alerts
+type AlertStore interface {
+	// GetAlertRules returns rules ordered by ID
+	GetAlertRules(activeOnly bool) ([]*models.AlertRule, error)
+	// CreateAlertRule stores a rule and sets its ID
+	CreateAlertRule(rule *models.AlertRule) error
+	// UpdateAlertRule replaces the rule with the same ID and returns
+	// ErrNotFound if there is none
+	UpdateAlertRule(rule *models.AlertRule) error
+	// InsertAlertEvent stores a fired alert and sets its ID
+	InsertAlertEvent(event *models.AlertEvent) error
+	// GetAlertHistory returns the most recently triggered alerts first,
+	// with their rule's name
+	GetAlertHistory(limit int) ([]*models.AlertEvent, error)
+	// AcknowledgeAlertEvent returns ErrNotFound if the alert does not
+	// exist or was already acknowledged
+	AcknowledgeAlertEvent(id int64, by string, at time.Time) error
+	// InsertRuleEvaluations stores the outcomes of an evaluation of the
+	// rules
+	InsertRuleEvaluations(ctx context.Context, evaluations []models.RuleEvaluation) error
+	// GetRuleEvaluations returns the last limit evaluations of a rule at
+	// or after since, oldest first
+	GetRuleEvaluations(ctx context.Context, ruleID int64, since time.Time, limit int) ([]models.RuleEvaluation, error)
+	// DeleteRuleEvaluations removes evaluations made before cutoff and
+	// returns how many
+	DeleteRuleEvaluations(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// MaintenanceStore stores maintenance windows
+type MaintenanceStore interface {
+	// CreateMaintenanceWindow stores a window and sets its ID
+	CreateMaintenanceWindow(window *models.MaintenanceWindow) error
+	// GetMaintenanceWindows returns windows overlapping [start, end),
+	// ordered by start time
+	GetMaintenanceWindows(start, end time.Time) ([]*models.MaintenanceWindow, error)
+	// DeleteMaintenanceWindow reports whether the window existed
+	DeleteMaintenanceWindow(id int64) (bool, error)
+}
+
+// LatencyBudgetStore stores the latency budgets of paths
+type LatencyBudgetStore interface {
+	// CreateLatencyBudget stores a budget and sets its ID
+	CreateLatencyBudget(budget *models.LatencyBudget) error
+	// GetLatencyBudgets returns every budget, ordered by path and
+	// percentile
+	GetLatencyBudgets() ([]*models.LatencyBudget, error)
+	// DeleteLatencyBudget reports whether the budget existed
+	DeleteLatencyBudget(id int64) (bool, error)
+}
+
+// FeatureStore stores feature flag overrides
+type FeatureStore interface {
+	// GetFeatureOverrides returns overrides ordered by flag and project
+	GetFeatureOverrides() ([]*models.FeatureOverride, error)
+	// SetFeatureOverride stores an override, replacing any for the same
+	// flag and project
+	SetFeatureOverride(override *models.FeatureOverride) error
+	// DeleteFeatureOverride reports whether the override existed
+	DeleteFeatureOverride(flag, project string) (bool, error)
+}
+
+// ConfigVersionStore keeps every version of configuration objects,
+// identified by their kind and an ID unique within it
+type ConfigVersionStore interface {
+	// AppendConfigVersion stores a version as the latest of its object,
+	// setting its ID, its Version and, if unset, its CreatedAt. Concurrent
+	// appends must not number two versions of an object the same.
+	AppendConfigVersion(version *models.ConfigVersion) error
+	// GetConfigVersions returns up to limit versions, most recent first,
+	// of one object, of every object of the kind when objectID is empty,
+	// or of every object when kind is empty too
+	GetConfigVersions(kind, objectID string, limit int) ([]*models.ConfigVersion, error)
+	// GetConfigVersion returns ErrNotFound for a version never stored
+	GetConfigVersion(kind, objectID string, version int) (*models.ConfigVersion, error)
+}
+
+// AuditStore is the append-only audit chain
+type AuditStore interface {
+	// AppendAuditRecord seals the record onto the end of the chain with
+	// audit.Seal and stores it. Concurrent appends must not fork the chain.
+	AppendAuditRecord(record *models.AuditRecord) error
+	// GetAuditRecords returns up to limit records after afterSeq, in order
+	GetAuditRecords(afterSeq int64, limit int) ([]*models.AuditRecord, error)
+	// GetAuditRecord returns ErrNotFound for an unknown sequence number
+	GetAuditRecord(seq int64) (*models.AuditRecord, error)
+}
+
+// IngestedFileStore remembers which files were processed in full, so the
+// same file uploaded again can be recognized
+type IngestedFileStore interface {
+	// GetIngestedFile returns ErrNotFound for a file never recorded
+	GetIngestedFile(sha256 string) (*models.IngestedFile, error)
+	// RecordIngestedFile stores a processed file. Recording a file again
+	// keeps the first record.
+	RecordIngestedFile(file *models.IngestedFile) error
+}
+
+// ReportFileStore keeps the metadata of generated report files, so
+// downloads can be validated without reading the files
+type ReportFileStore interface {
+	// GetReportFile returns ErrNotFound for a file never saved
+	GetReportFile(name string) (*models.ReportFile, error)
+	// SaveReportFile stores a file's metadata, replacing any for a file
+	// with the same name
+	SaveReportFile(file *models.ReportFile) error
+}
+
+// ReportTemplateStore stores custom HTML templates of report types,
+// identified by their report type and a name unique within it
+type ReportTemplateStore interface {
+	// GetReportTemplates returns every template without its Content,
+	// ordered by report type and name
+	GetReportTemplates() ([]*models.ReportTemplate, error)
+	// GetReportTemplate returns ErrNotFound for a template never saved
+	GetReportTemplate(reportType, name string) (*models.ReportTemplate, error)
+	// SaveReportTemplate stores a template, replacing any with the same
+	// report type and name
+	SaveReportTemplate(tmpl *models.ReportTemplate) error
+	// DeleteReportTemplate reports whether the template existed
+	DeleteReportTemplate(reportType, name string) (bool, error)
+}
+
+// SavedSearchStore stores the saved searches of users, identified by their
+// owner and a name unique to the owner
+type SavedSearchStore interface {
+	// GetSavedSearches returns an owner's searches, ordered by name
+	GetSavedSearches(owner string) ([]*models.SavedSearch, error)
+	// GetSavedSearch returns ErrNotFound for a search never saved
+	GetSavedSearch(owner, name string) (*models.SavedSearch, error)
+	// SaveSavedSearch stores a search, replacing any of the same owner
+	// and name but keeping its CreatedAt
+	SaveSavedSearch(search *models.SavedSearch) error
+	// DeleteSavedSearch reports whether the search existed
+	DeleteSavedSearch(owner, name string) (bool, error)
+}
+
+// UserStore stores the users of the dashboard and API and their roles
+type UserStore interface {
+	// GetRoles returns the roles users can have, ordered by level
+	GetRoles() ([]*models.Role, error)
+	// GetUsers returns every user, ordered by subject
+	GetUsers() ([]*models.User, error)
+	// GetUser returns ErrNotFound for a user never saved
+	GetUser(subject string) (*models.User, error)
+	// SaveUser stores a user, replacing any with the same subject. A
+	// Role that is not one of the roles is an error.
+	SaveUser(user *models.User) error
+	// DeleteUser reports whether the user existed
+	DeleteUser(subject string) (bool, error)
+}
+
+// DataKeyStore keeps the wrapped data keys of projects whose log content
+// is encrypted
+type DataKeyStore interface {
+	// GetDataKey returns ErrNotFound for a project without a key
+	GetDataKey(project string) (*models.DataKey, error)
+	// CreateDataKey stores a project's key unless it already has one, in
+	// which case the existing key is kept
+	CreateDataKey(key *models.DataKey) error
+}
+
+// IntegrityStore reports records that break the data's invariants
+type IntegrityStore interface {
+	// GetOrphanedAlertEvents counts fired alerts whose rule does not
+	// exist by rule ID, ordered by rule ID
+	GetOrphanedAlertEvents() ([]models.OrphanedAlertEvents, error)
+	// GetRollupTotals compares the hours of [start, end) that a roll-up
+	// of entries with a method and a status code covers against the raw
+	// entries, ordered by hour. Backends without roll-ups return none.
+	GetRollupTotals(start, end time.Time) ([]models.RollupTotal, error)
+}
+
+// TrafficRollupStore keeps hourly and daily totals of entries by log type,
+// path and status code, so stats need not scan every entry. Intervals are
+// models.RollupHour and models.RollupDay.
+type TrafficRollupStore interface {
+	// RefreshTrafficRollups recomputes the rollups of the interval's
+	// bucket starting at bucket from the stored entries, replacing those
+	// stored before. Paths are cut to 500 characters.
+	RefreshTrafficRollups(ctx context.Context, interval string, bucket time.Time) error
+	// SummarizeTraffic sums rollups as the query asks, busiest first and
+	// then by the grouped fields, or in bucket order when grouped by
+	// bucket. Without GroupBy it returns a single total, zero when there
+	// are no rollups.
+	SummarizeTraffic(ctx context.Context, query *models.TrafficQuery) ([]models.TrafficRollup, error)
+	// DeleteTrafficRollups removes the interval's rollups of buckets
+	// before cutoff and returns how many were removed
+	DeleteTrafficRollups(ctx context.Context, interval string, cutoff time.Time) (int64, error)
+}
+
+// Factory opens a backend for the configuration
+type Factory func(cfg *config.Config) (Storage, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a backend available under a database type. It is meant
+// to be called from the backend package's init function and panics if the
+// type is already registered.
+func Register(databaseType string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := factories[databaseType]; exists {
+		panic(fmt.Sprintf("storage: backend %q registered twice", databaseType))
+	}
+	factories[databaseType] = factory
+}
+
+// Open opens the backend registered for the configured database type
+func Open(cfg *config.Config) (Storage, error) {
+	mu.RLock()
+	factory, ok := factories[cfg.Database.Type]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
+	}
+	return factory(cfg)
+}
+
+// Backends lists the registered database types
+func Backends() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	types := make([]string, 0, len(factories))
+	for databaseType := range factories {
+		types = append(types, databaseType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,1576 @@
+// Package storagetest is the conformance suite for storage backends. A
+// backend passes when Run succeeds against it:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Storage {
+//			return openEmptyBackend(t)
+//		})
+//	}
+//
+// Timestamps used by the suite are whole seconds in UTC, so backends may
+// store times with second precision.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/audit"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/logprocessor"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opener returns an empty backend for one test. It should register any
+// cleanup with t.Cleanup.
+type Opener func(t *testing.T) storage.Storage
+
+// Run verifies that a backend honours the storage contract. Each test
+// opens its own backend.
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Storage)
+	}{
+		{"InsertAndQueryLogs", testInsertAndQueryLogs},
+		{"QueryLogsPaging", testQueryLogsPaging},
+		{"QueryLogsByPosition", testQueryLogsByPosition},
+		{"QueryLogsSorted", testQueryLogsSorted},
+		{"InsertLogEntries", testInsertLogEntries},
+		{"FileLinesStoredOnce", testFileLinesStoredOnce},
+		{"TimestamplessFileReadAgain", testTimestamplessFileReadAgain},
+		{"LogMessages", testLogMessages},
+		{"SourceActivity", testSourceActivity},
+		{"UserAgentActivity", testUserAgentActivity},
+		{"EntriesByTypeOrStatus", testEntriesByTypeOrStatus},
+		{"EntriesByPathPrefix", testEntriesByPathPrefix},
+		{"Stats", testStats},
+		{"MethodStats", testMethodStats},
+		{"TopOffenders", testTopOffenders},
+		{"CountryActivity", testCountryActivity},
+		{"Facets", testFacets},
+		{"AggregateLogs", testAggregateLogs},
+		{"DeleteMatching", testDeleteMatching},
+		{"Retention", testRetention},
+		{"RetentionByLogType", testRetentionByLogType},
+		{"RetentionPolicies", testRetentionPolicies},
+		{"LogRepository", testLogRepository},
+		{"Stream", testStream},
+		{"ScanAndRewrite", testScanAndRewrite},
+		{"AlertRules", testAlertRules},
+		{"AlertHistory", testAlertHistory},
+		{"RuleEvaluations", testRuleEvaluations},
+		{"MaintenanceWindows", testMaintenanceWindows},
+		{"LatencyBudgets", testLatencyBudgets},
+		{"FeatureOverrides", testFeatureOverrides},
+		{"ConfigVersions", testConfigVersions},
+		{"AuditChain", testAuditChain},
+		{"ConcurrentAuditAppends", testConcurrentAuditAppends},
+		{"IngestedFiles", testIngestedFiles},
+		{"ReportFiles", testReportFiles},
+		{"ReportTemplates", testReportTemplates},
+		{"SavedSearches", testSavedSearches},
+		{"Users", testUsers},
+		{"DataKeys", testDataKeys},
+		{"Integrity", testIntegrity},
+		{"TrafficRollups", testTrafficRollups},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := open(t)
+			require.NoError(t, s.HealthCheck())
+			tt.test(t, s)
+		})
+	}
+}
+
+// base is the reference time entries are placed around
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return base.Add(time.Duration(minutes) * time.Minute)
+}
+
+func insert(t *testing.T, s storage.Storage, entries ...*models.LogEntry) {
+	t.Helper()
+	for _, entry := range entries {
+		require.NoError(t, s.InsertLogEntry(entry))
+	}
+}
+
+func request(minute int, ip, method, path string, status int) *models.LogEntry {
+	return &models.LogEntry{
+		Timestamp:  at(minute),
+		LogType:    "nginx",
+		SourceIP:   ip,
+		Method:     method,
+		Path:       path,
+		StatusCode: status,
+		RawLog:     fmt.Sprintf("%s %s %s %d", ip, method, path, status),
+	}
+}
+
+// message is an entry without a source IP, as application logs are stored
+func message(minute int, logType, text string) *models.LogEntry {
+	return &models.LogEntry{Timestamp: at(minute), LogType: logType, Path: text, RawLog: text}
+}
+
+// timestamps lists the entries' times for order assertions
+func timestamps(entries []*models.LogEntry) []time.Time {
+	times := make([]time.Time, len(entries))
+	for i, entry := range entries {
+		times[i] = entry.Timestamp.UTC()
+	}
+	return times
+}
+
+func paths(entries []*models.LogEntry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Path
+	}
+	return result
+}
+
+func testInsertAndQueryLogs(t *testing.T, s storage.Storage) {
+	entry := &models.LogEntry{
+		Timestamp:      at(0),
+		LogType:        "apache",
+		SourceIP:       "192.0.2.10",
+		Method:         "POST",
+		Path:           "/api/orders",
+		StatusCode:     201,
+		ResponseSize:   512,
+		UserAgent:      "curl/8.0",
+		Referer:        "https://example.com/",
+		ProcessingTime: 0.25,
+		RawLog:         "raw line",
+		Metadata:       models.LogMetadata{"country": "DE", "bytes_in": 42},
+	}
+	insert(t, s, entry,
+		request(1, "192.0.2.11", "GET", "/api/orders/7", 200),
+		request(2, "192.0.2.10", "GET", "/health", 503),
+	)
+	require.NotZero(t, entry.ID)
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+	assert.Equal(t, []time.Time{at(2), at(1), at(0)}, timestamps(logs), "most recent first")
+
+	got := logs[2]
+	assert.Equal(t, entry.ID, got.ID)
+	assert.Equal(t, "apache", got.LogType)
+	assert.Equal(t, "192.0.2.10", got.SourceIP)
+	assert.Equal(t, "POST", got.Method)
+	assert.Equal(t, "/api/orders", got.Path)
+	assert.Equal(t, 201, got.StatusCode)
+	assert.Equal(t, int64(512), got.ResponseSize)
+	assert.Equal(t, "curl/8.0", got.UserAgent)
+	assert.Equal(t, "https://example.com/", got.Referer)
+	assert.InDelta(t, 0.25, got.ProcessingTime, 1e-9)
+	assert.Equal(t, "raw line", got.RawLog)
+	assert.Equal(t, "DE", got.Metadata["country"])
+	assert.EqualValues(t, 42, got.Metadata["bytes_in"])
+
+	status := 503
+	start, end := at(1), at(2)
+	for name, tc := range map[string]struct {
+		filter models.LogFilter
+		want   []string
+	}{
+		"log type":    {models.LogFilter{LogType: "apache"}, []string{"/api/orders"}},
+		"status code": {models.LogFilter{StatusCode: &status}, []string{"/health"}},
+		"min status":  {models.LogFilter{MinStatusCode: 400}, []string{"/health"}},
+		"source IP":   {models.LogFilter{SourceIP: "192.0.2.10"}, []string{"/health", "/api/orders"}},
+		"path":        {models.LogFilter{Path: "orders"}, []string{"/api/orders/7", "/api/orders"}},
+		"exact path":  {models.LogFilter{Path: "/api/orders", ExactPath: true}, []string{"/api/orders"}},
+		"method":      {models.LogFilter{Method: "GET"}, []string{"/health", "/api/orders/7"}},
+		"time range":  {models.LogFilter{StartTime: &start, EndTime: &end}, []string{"/api/orders/7"}},
+	} {
+		tc.filter.Limit = 10
+		logs, err := s.QueryLogs(&tc.filter)
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.want, paths(logs), name)
+	}
+}
+
+func testQueryLogsPaging(t *testing.T, s storage.Storage) {
+	for i := 0; i < 5; i++ {
+		insert(t, s, request(i, "192.0.2.1", "GET", fmt.Sprintf("/page/%d", i), 200))
+	}
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/page/3", "/page/2"}, paths(logs))
+
+	logs, err = s.QueryLogs(&models.LogFilter{Limit: 2, Offset: 5})
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+}
+
+func testQueryLogsByPosition(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	// Two entries share each time, so the ID orders them
+	for i := 0; i < 6; i++ {
+		insert(t, s, request(i/2, "192.0.2.1", "GET", fmt.Sprintf("/page/%d", i), 200))
+	}
+	insert(t, s, request(9, "192.0.2.2", "GET", "/other", 200))
+
+	filter := &models.LogFilter{SourceIP: "192.0.2.1", Limit: 4}
+	first, err := s.QueryLogs(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/page/5", "/page/4", "/page/3", "/page/2"}, paths(first))
+
+	filter.After = models.PositionOf(first[2])
+	second, err := s.QueryLogs(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/page/2", "/page/1", "/page/0"}, paths(second), "after an entry of a time shared with the next")
+
+	filter.After = models.PositionOf(second[2])
+	logs, err := s.QueryLogs(filter)
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+
+	filter.After, filter.Before = nil, models.PositionOf(second[2])
+	filter.Limit = 2
+	logs, err = s.QueryLogs(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/page/2", "/page/1"}, paths(logs), "the entries nearest the position, most recent first")
+
+	streamed := 0
+	require.NoError(t, s.Stream(ctx, filter, func(*models.LogEntry) error {
+		streamed++
+		return nil
+	}))
+	assert.Equal(t, 2, streamed)
+
+	count, err := s.Count(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count, "the position does not narrow the count")
+}
+
+func testQueryLogsSorted(t *testing.T, s storage.Storage) {
+	entries := []*models.LogEntry{
+		request(0, "192.0.2.1", "GET", "/a", 500),
+		request(1, "192.0.2.1", "GET", "/b", 200),
+		request(2, "192.0.2.1", "GET", "/c", 404),
+		request(3, "192.0.2.1", "GET", "/d", 200),
+	}
+	for i, entry := range entries {
+		entry.ResponseSize = int64(100 * (4 - i))
+		entry.ProcessingTime = []float64{0.5, 0.25, 2, 1}[i]
+	}
+	insert(t, s, entries...)
+
+	sorted := func(filter *models.LogFilter) []string {
+		t.Helper()
+		filter.Limit = 10
+		logs, err := s.QueryLogs(filter)
+		require.NoError(t, err)
+		return paths(logs)
+	}
+	assert.Equal(t, []string{"/a", "/c", "/d", "/b"}, sorted(&models.LogFilter{SortBy: "status_code"}), "by ID, descending, among the same status")
+	assert.Equal(t, []string{"/b", "/d", "/c", "/a"}, sorted(&models.LogFilter{SortBy: "status_code", SortAscending: true}))
+	assert.Equal(t, []string{"/d", "/c", "/b", "/a"}, sorted(&models.LogFilter{SortBy: "response_size", SortAscending: true}))
+	assert.Equal(t, []string{"/c", "/d", "/a", "/b"}, sorted(&models.LogFilter{SortBy: "processing_time"}))
+	assert.Equal(t, []string{"/a", "/b", "/c", "/d"}, sorted(&models.LogFilter{SortBy: "timestamp", SortAscending: true}))
+
+	logs, err := s.QueryLogs(&models.LogFilter{SortAscending: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/c", "/d"}, sorted(&models.LogFilter{SortAscending: true, After: models.PositionOf(logs[1])}), "oldest first after a position")
+	assert.Equal(t, []string{"/a", "/b"}, sorted(&models.LogFilter{SortAscending: true, Before: models.PositionOf(logs[2])}))
+}
+
+func testInsertLogEntries(t *testing.T, s storage.Storage) {
+	batch := []*models.LogEntry{
+		request(0, "192.0.2.1", "GET", "/a", 200),
+		request(1, "192.0.2.2", "GET", "/b", 404),
+		request(2, "192.0.2.3", "POST", "/c", 500),
+	}
+	require.NoError(t, s.InsertLogEntries(batch))
+	require.NoError(t, s.InsertLogEntries(nil))
+
+	ids := map[int64]bool{}
+	for _, entry := range batch {
+		assert.NotZero(t, entry.ID)
+		ids[entry.ID] = true
+	}
+	assert.Len(t, ids, len(batch), "each entry gets its own ID")
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/c", "/b", "/a"}, paths(logs))
+	for _, entry := range logs {
+		assert.True(t, ids[entry.ID])
+	}
+}
+
+func testFileLinesStoredOnce(t *testing.T, s storage.Storage) {
+	line := func(minute int, hash string, number int64) *models.LogEntry {
+		entry := request(minute, "192.0.2.1", "GET", fmt.Sprintf("/%s/%d", hash, number), 200)
+		entry.FileHash, entry.LineNumber = hash, number
+		return entry
+	}
+	require.NoError(t, s.InsertLogEntries([]*models.LogEntry{line(0, "aa", 1), line(1, "aa", 2)}))
+
+	// A file read again after being cut short, next to another one
+	again := []*models.LogEntry{line(0, "aa", 1), line(1, "aa", 2), line(2, "aa", 3), line(0, "bb", 1)}
+	require.NoError(t, s.InsertBatch(context.Background(), again))
+	assert.Zero(t, again[0].ID)
+	assert.Zero(t, again[1].ID)
+	assert.NotZero(t, again[2].ID)
+	assert.NotZero(t, again[3].ID)
+
+	single := line(2, "aa", 3)
+	require.NoError(t, s.InsertLogEntry(single))
+	assert.Zero(t, single.ID)
+
+	// Entries not read from a file are never skipped
+	insert(t, s, request(0, "192.0.2.1", "GET", "/", 200), request(0, "192.0.2.1", "GET", "/", 200))
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/aa/1", "/aa/2", "/aa/3", "/bb/1", "/", "/"}, paths(logs))
+	for _, entry := range logs {
+		if entry.Path == "/aa/3" {
+			assert.Equal(t, "aa", entry.FileHash)
+			assert.Equal(t, int64(3), entry.LineNumber)
+		}
+	}
+}
+
+func testTimestamplessFileReadAgain(t *testing.T, s storage.Storage) {
+	lines := []string{
+		`level=info msg="cache warmed" duration=12ms`,
+		`level=warn msg="slow query" table=orders`,
+	}
+	processor := logprocessor.NewProcessor(1)
+	t.Cleanup(processor.Close)
+	// Lines without a timestamp are given the time they are read, so each
+	// read of the file gives them another
+	read := func(later time.Duration) []*models.LogEntry {
+		var entries []*models.LogEntry
+		for i, line := range lines {
+			entry, err := processor.ParseLine(line, "logfmt")
+			require.NoError(t, err)
+			entry.Timestamp = entry.Timestamp.UTC().Truncate(time.Second).Add(later)
+			entry.FileHash, entry.LineNumber = "cc", int64(i+1)
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	require.NoError(t, s.InsertBatch(context.Background(), read(0)))
+	again := read(time.Hour)
+	require.NoError(t, s.InsertBatch(context.Background(), again))
+	for _, entry := range again {
+		assert.Zero(t, entry.ID, "line %d is stored already", entry.LineNumber)
+	}
+	count, err := s.Count(context.Background(), &models.LogFilter{LogType: "logfmt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Deleted entries are stored again when their file is read again
+	deleted, err := s.DeleteMatching(context.Background(), &models.LogFilter{LogType: "logfmt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	restored := read(2 * time.Hour)
+	require.NoError(t, s.InsertBatch(context.Background(), restored))
+	for _, entry := range restored {
+		assert.NotZero(t, entry.ID)
+	}
+}
+
+func testLogMessages(t *testing.T, s storage.Storage) {
+	insert(t, s,
+		message(0, "generic", "first"),
+		message(1, "logfmt", "second"),
+		message(2, "generic", "third"),
+		message(3, "generic", "outside the range"),
+		request(1, "192.0.2.1", "GET", "/", 200),
+	)
+
+	entries, err := s.GetLogMessages([]string{"generic", "logfmt"}, at(0), at(3), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"third", "second"}, paths(entries), "most recent first")
+	assert.Equal(t, "logfmt", entries[1].LogType)
+
+	entries, err = s.GetLogMessages(nil, at(0), at(3), 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func testSourceActivity(t *testing.T, s storage.Storage) {
+	entry := request(1, "192.0.2.1", "GET", "/a", 404)
+	entry.UserAgent = "bot/1.0"
+	entry.Metadata = models.LogMetadata{"latitude": 52.5}
+	insert(t, s,
+		request(0, "192.0.2.1", "GET", "/old", 200),
+		entry,
+		message(2, "generic", "no source IP"),
+		request(3, "192.0.2.2", "GET", "/b", 200),
+	)
+
+	entries, err := s.GetSourceActivity(at(0), at(10), 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"/a", "/b"}, paths(entries), "the most recent, oldest first")
+	assert.Equal(t, "192.0.2.1", entries[0].SourceIP)
+	assert.Equal(t, 404, entries[0].StatusCode)
+	assert.Equal(t, "bot/1.0", entries[0].UserAgent)
+	assert.EqualValues(t, 52.5, entries[0].Metadata["latitude"])
+}
+
+func testUserAgentActivity(t *testing.T, s storage.Storage) {
+	agents := []string{"Mozilla/5.0 (compatible; Googlebot/2.1)", "curl/8.0", "Mozilla/5.0 (compatible; bingbot/2.0)"}
+	for i, agent := range agents {
+		entry := request(i, "192.0.2.1", "GET", fmt.Sprintf("/%d", i), 200)
+		entry.UserAgent = agent
+		entry.ResponseSize = int64(100 * (i + 1))
+		insert(t, s, entry)
+	}
+
+	entries, err := s.GetUserAgentActivity([]string{"GOOGLEBOT", "bingbot"}, at(0), at(10), 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"/0", "/2"}, paths(entries))
+	assert.Equal(t, int64(300), entries[1].ResponseSize)
+	assert.Equal(t, agents[2], entries[1].UserAgent)
+}
+
+func testEntriesByTypeOrStatus(t *testing.T, s storage.Storage) {
+	cef := message(0, "cef", "cef event")
+	cef.Metadata = models.LogMetadata{"severity": "7"}
+	insert(t, s,
+		cef,
+		request(1, "192.0.2.1", "GET", "/login", 401),
+		request(2, "192.0.2.1", "GET", "/ok", 200),
+		request(3, "192.0.2.1", "GET", "/forbidden", 403),
+	)
+
+	entries, err := s.GetEntriesByTypeOrStatus([]string{"cef"}, []int{401, 403}, at(0), at(10), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cef event", "/login", "/forbidden"}, paths(entries))
+	assert.Equal(t, "7", entries[0].Metadata["severity"])
+	assert.NotZero(t, entries[0].ID)
+
+	entries, err = s.GetEntriesByTypeOrStatus([]string{"cef"}, []int{401, 403}, at(0), at(10), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/forbidden"}, paths(entries))
+
+	entries, err = s.GetEntriesByTypeOrStatus(nil, nil, at(0), at(10), 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func testEntriesByPathPrefix(t *testing.T, s storage.Storage) {
+	insert(t, s,
+		request(0, "192.0.2.1", "GET", "/admin/users", 200),
+		request(1, "192.0.2.1", "GET", "/wp-admin/", 403),
+		request(2, "192.0.2.1", "GET", "/blog/admin", 200),
+		request(3, "192.0.2.1", "GET", "/admin_old", 200),
+		request(4, "192.0.2.1", "GET", "/adminXold", 200),
+	)
+
+	entries, err := s.GetEntriesByPathPrefix([]string{"/admin", "/wp-admin"}, at(0), at(10), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/admin/users", "/wp-admin/", "/admin_old", "/adminXold"}, paths(entries))
+
+	// LIKE wildcards in prefixes match literally
+	entries, err = s.GetEntriesByPathPrefix([]string{"/admin_"}, at(0), at(10), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/admin_old"}, paths(entries))
+}
+
+func testStats(t *testing.T, s storage.Storage) {
+	first := request(0, "192.0.2.1", "GET", "/", 200)
+	first.ResponseSize = 100
+	second := request(1, "192.0.2.1", "GET", "/", 200)
+	second.ResponseSize = 250
+	insert(t, s, first, second)
+
+	stats, err := s.GetStats()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats["total_logs"])
+	assert.EqualValues(t, 350, stats["total_size"])
+	assert.NotEmpty(t, stats["database_type"])
+	assert.Equal(t, true, stats["connected"])
+}
+
+func testMethodStats(t *testing.T, s storage.Storage) {
+	timed := func(minute int, method, path string, status int, seconds float64) *models.LogEntry {
+		entry := request(minute, "192.0.2.1", method, path, status)
+		entry.ProcessingTime = seconds
+		return entry
+	}
+	other := timed(4, "GET", "/items", 200, 9)
+	other.LogType = "apache"
+	insert(t, s,
+		timed(0, "GET", "/items", 200, 0.2),
+		timed(1, "GET", "/items", 500, 0.4),
+		timed(2, "GET", "/cart", 404, 0),
+		timed(3, "POST", "/cart", 201, 1),
+		other,
+		message(5, "generic", "no method"),
+	)
+
+	stats, err := s.GetMethodStats(at(0), at(10), "nginx", "", false, 10)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	get := stats[0]
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, int64(3), get.Requests)
+	assert.InDelta(t, 0.3, get.AvgResponseTime, 1e-9, "untimed requests are left out of the average")
+	assert.InDelta(t, 0.4, get.MaxResponseTime, 1e-9)
+	assert.InDelta(t, 200.0/3, get.ErrorRate, 1e-9)
+	assert.InDelta(t, 100.0/3, get.ServerErrorRate, 1e-9)
+	assert.Equal(t, "POST", stats[1].Method)
+
+	stats, err = s.GetMethodStats(at(0), at(10), "nginx", "/cart", true, 10)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	for _, stat := range stats {
+		assert.Equal(t, "/cart", stat.Path)
+		assert.Equal(t, int64(1), stat.Requests)
+	}
+
+	stats, err = s.GetMethodStats(at(0), at(10), "", "", false, 1)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(4), stats[0].Requests)
+}
+
+func testTopOffenders(t *testing.T, s storage.Storage) {
+	since := time.Now().Add(-time.Hour)
+	insert(t, s,
+		request(0, "192.0.2.1", "GET", "/missing", 404),
+		request(1, "192.0.2.1", "GET", "/missing", 404),
+		request(2, "192.0.2.2", "GET", "/broken", 500),
+		request(3, "192.0.2.3", "GET", "/ok", 200),
+	)
+
+	paths, ips, err := s.GetTopOffenders(since, 400, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PathStats{{Path: "/missing", Count: 2}, {Path: "/broken", Count: 1}}, paths)
+	assert.Equal(t, []models.IPStats{{IP: "192.0.2.1", Count: 2}, {IP: "192.0.2.2", Count: 1}}, ips)
+
+	paths, ips, err = s.GetTopOffenders(since, 400, 1)
+	require.NoError(t, err)
+	assert.Len(t, paths, 1)
+	assert.Len(t, ips, 1)
+
+	// Offenders are counted by when entries were stored, not logged
+	paths, _, err = s.GetTopOffenders(time.Now().Add(time.Hour), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func testCountryActivity(t *testing.T, s storage.Storage) {
+	from := func(minute int, ip, country string) *models.LogEntry {
+		entry := request(minute, ip, "GET", "/", 200)
+		entry.Metadata = models.LogMetadata{"country": country}
+		return entry
+	}
+	insert(t, s,
+		from(-120, "192.0.2.1", "US"),
+		from(5, "192.0.2.1", "US"),
+		from(10, "192.0.2.2", "DE"),
+		from(11, "192.0.2.2", "DE"),
+		from(12, "192.0.2.3", "DE"),
+		request(13, "192.0.2.4", "GET", "/", 200),
+		from(-600, "192.0.2.5", "FR"),
+	)
+
+	activity, err := s.GetCountryActivity(at(-300), at(0), at(60))
+	require.NoError(t, err)
+	require.Len(t, activity, 2, "countries before since are ignored")
+
+	assert.Equal(t, "DE", activity[0].Country)
+	assert.Equal(t, at(10), activity[0].FirstSeen.UTC())
+	assert.Equal(t, int64(3), activity[0].Requests)
+	assert.Equal(t, int64(2), activity[0].UniqueIPs)
+
+	assert.Equal(t, "US", activity[1].Country)
+	assert.Equal(t, at(-120), activity[1].FirstSeen.UTC())
+	assert.Equal(t, int64(1), activity[1].Requests)
+	assert.Equal(t, int64(1), activity[1].UniqueIPs)
+}
+
+func testFacets(t *testing.T, s storage.Storage) {
+	other := request(4, "192.0.2.2", "GET", "/items/2", 200)
+	other.LogType = "apache"
+	insert(t, s,
+		request(0, "192.0.2.1", "GET", "/items/1", 200),
+		request(1, "192.0.2.1", "GET", "/items/1", 404),
+		request(2, "192.0.2.2", "POST", "/cart", 200),
+		request(3, "192.0.2.3", "GET", "/items/1", 500),
+		other,
+		message(5, "generic", "no request"),
+	)
+
+	facets, err := s.GetFacets(&models.LogFilter{}, "status_code", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetCount{{Value: "200", Count: 3}, {Value: "404", Count: 1}, {Value: "500", Count: 1}}, facets,
+		"entries without a status code are left out and ties are ordered by value")
+
+	facets, err = s.GetFacets(&models.LogFilter{LogType: "nginx", Path: "/items"}, "source_ip", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetCount{{Value: "192.0.2.1", Count: 2}, {Value: "192.0.2.3", Count: 1}}, facets)
+
+	end := at(3)
+	facets, err = s.GetFacets(&models.LogFilter{EndTime: &end, Limit: 1, Offset: 5}, "method", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetCount{{Value: "GET", Count: 2}}, facets, "the filter's paging is ignored")
+
+	facets, err = s.GetFacets(&models.LogFilter{}, "log_type", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetCount{{Value: "nginx", Count: 4}, {Value: "apache", Count: 1}, {Value: "generic", Count: 1}}, facets)
+
+	_, err = s.GetFacets(&models.LogFilter{}, "raw_log", 10)
+	assert.Error(t, err)
+}
+
+func testAggregateLogs(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	entries := []*models.LogEntry{
+		request(0, "192.0.2.1", "GET", "/a", 200),
+		request(1, "192.0.2.2", "GET", "/a", 500),
+		request(2, "192.0.2.1", "GET", "/a", 200),
+		request(3, "192.0.2.1", "POST", "/b", 404),
+		message(3, "app", "started"),
+	}
+	for i, entry := range entries {
+		entry.ProcessingTime = float64(i + 1)
+		entry.ResponseSize = int64(100 * (i + 1))
+	}
+	insert(t, s, entries...)
+
+	rows, err := s.AggregateLogs(ctx, &models.AggregateQuery{
+		Filter:  models.LogFilter{LogType: "nginx"},
+		GroupBy: []string{"path", "status_code"},
+		Metrics: []string{"count", "avg_processing_time", "sum_response_size", "unique_ips", "errors"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Nil(t, rows[0].Bucket)
+	assert.Equal(t, map[string]interface{}{"path": "/a", "status_code": 200}, rows[0].Group, "the largest count first")
+	assert.Equal(t, map[string]float64{"count": 2, "avg_processing_time": 2, "sum_response_size": 400, "unique_ips": 1, "errors": 0}, rows[0].Metrics)
+	assert.Equal(t, map[string]interface{}{"path": "/a", "status_code": 500}, rows[1].Group, "then by the groups' values")
+	assert.Equal(t, float64(1), rows[1].Metrics["errors"])
+	assert.Equal(t, map[string]interface{}{"path": "/b", "status_code": 404}, rows[2].Group)
+
+	rows, err = s.AggregateLogs(ctx, &models.AggregateQuery{
+		Filter:   models.LogFilter{LogType: "nginx"},
+		Metrics:  []string{"count", "max_processing_time"},
+		Interval: 2 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.NotNil(t, rows[0].Bucket)
+	assert.True(t, rows[0].Bucket.Equal(at(0)), "buckets start on whole multiples of the interval")
+	assert.Empty(t, rows[0].Group)
+	assert.Equal(t, map[string]float64{"count": 2, "max_processing_time": 2}, rows[0].Metrics)
+	assert.True(t, rows[1].Bucket.Equal(at(2)))
+	assert.Equal(t, map[string]float64{"count": 2, "max_processing_time": 4}, rows[1].Metrics)
+
+	rows, err = s.AggregateLogs(ctx, &models.AggregateQuery{GroupBy: []string{"log_type"}, Metrics: []string{"count"}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "nginx", rows[0].Group["log_type"])
+
+	_, err = s.AggregateLogs(ctx, &models.AggregateQuery{GroupBy: []string{"raw_log"}, Metrics: []string{"count"}})
+	assert.Error(t, err)
+	_, err = s.AggregateLogs(ctx, &models.AggregateQuery{Metrics: []string{"median"}})
+	assert.Error(t, err)
+}
+
+func testDeleteMatching(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	insert(t, s,
+		request(0, "192.0.2.1", "GET", "/a", 200),
+		request(1, "192.0.2.1", "GET", "/b", 200),
+		request(2, "192.0.2.2", "GET", "/a", 200),
+		request(3, "192.0.2.1", "GET", "/a", 200),
+		message(1, "app", "started"),
+	)
+
+	start, end := at(1), at(3)
+	deleted, err := s.DeleteMatching(ctx, &models.LogFilter{StartTime: &start, EndTime: &end, SourceIP: "192.0.2.1", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted, "every match, whatever the limit")
+
+	deleted, err = s.DeleteMatching(ctx, &models.LogFilter{LogType: "nginx", Path: "/a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started"}, paths(logs))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.DeleteMatching(cancelled, &models.LogFilter{})
+	assert.Error(t, err)
+}
+
+func testRetention(t *testing.T, s storage.Storage) {
+	stats, err := s.GetRetentionStats(models.RetentionCutoff{Before: at(0)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.TotalEntries)
+	assert.Nil(t, stats.OldestEntry)
+
+	insert(t, s,
+		request(-20, "192.0.2.1", "GET", "/old", 200),
+		request(-10, "192.0.2.1", "GET", "/older", 200),
+		request(0, "192.0.2.1", "GET", "/cutoff", 200),
+		request(10, "192.0.2.1", "GET", "/new", 200),
+	)
+
+	stats, err = s.GetRetentionStats(models.RetentionCutoff{Before: at(0)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.TotalEntries)
+	assert.Equal(t, int64(2), stats.ExpiredEntries)
+	require.NotNil(t, stats.OldestEntry)
+	assert.Equal(t, at(-20), stats.OldestEntry.UTC())
+
+	deleted, err := s.DeleteLogsBefore(at(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/new", "/cutoff"}, paths(logs))
+}
+
+func testRetentionByLogType(t *testing.T, s storage.Storage) {
+	syslog := message(-20, "syslog", "kernel: old")
+	insert(t, s,
+		request(-20, "192.0.2.1", "GET", "/old", 200),
+		request(0, "192.0.2.1", "GET", "/new", 200),
+		syslog,
+		message(-20, "generic", "old"),
+		message(0, "generic", "new"),
+	)
+
+	stats, err := s.GetRetentionStats(models.RetentionCutoff{LogType: "syslog", Before: at(-30)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.TotalEntries)
+	assert.Equal(t, int64(0), stats.ExpiredEntries)
+	require.NotNil(t, stats.OldestEntry)
+	assert.Equal(t, at(-20), stats.OldestEntry.UTC())
+
+	others := models.RetentionCutoff{Except: []string{"syslog", "generic"}, Before: at(-10)}
+	stats, err = s.GetRetentionStats(others)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.TotalEntries)
+	assert.Equal(t, int64(1), stats.ExpiredEntries)
+
+	// Expired entries can be read before they are removed
+	expired, err := s.ScanExpired(context.Background(), others, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "/old", expired[0].Path)
+	expired, err = s.ScanExpired(context.Background(), others, expired[0].ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	expired, err = s.ScanExpired(context.Background(), models.RetentionCutoff{Before: at(-10)}, 0, 2)
+	require.NoError(t, err)
+	assert.Len(t, expired, 2)
+
+	deleted, err := s.ExpireEntries(context.Background(), others)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	deleted, err = s.ExpireEntries(context.Background(), models.RetentionCutoff{LogType: "generic", Before: at(-10)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	logs, err := s.QueryLogs(&models.LogFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, logs, 3)
+	for _, entry := range logs {
+		assert.True(t, entry.ID == syslog.ID || !entry.Timestamp.Before(at(0)), "expired %s entry kept", entry.LogType)
+	}
+
+	// A cutoff of every log type expires them all
+	deleted, err = s.ExpireEntries(context.Background(), models.RetentionCutoff{Before: at(-10)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func testRetentionPolicies(t *testing.T, s storage.Storage) {
+	require.NoError(t, s.SetRetentionPolicy(&models.RetentionPolicy{LogType: "syslog", Days: 365, UpdatedBy: "alice", UpdatedAt: at(0)}))
+	require.NoError(t, s.SetRetentionPolicy(&models.RetentionPolicy{LogType: "nginx", Days: 30, UpdatedAt: at(0)}))
+
+	// Setting a policy again replaces it
+	require.NoError(t, s.SetRetentionPolicy(&models.RetentionPolicy{LogType: "syslog", Days: 180, UpdatedBy: "bob", UpdatedAt: at(5)}))
+
+	policies, err := s.GetRetentionPolicies()
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "nginx", policies[0].LogType, "ordered by log type")
+	assert.Equal(t, 30, policies[0].Days)
+	assert.Equal(t, "syslog", policies[1].LogType)
+	assert.Equal(t, 180, policies[1].Days)
+	assert.Equal(t, "bob", policies[1].UpdatedBy)
+	assert.Equal(t, at(5), policies[1].UpdatedAt.UTC())
+
+	found, err := s.DeleteRetentionPolicy("syslog")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteRetentionPolicy("syslog")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	policies, err = s.GetRetentionPolicies()
+	require.NoError(t, err)
+	assert.Len(t, policies, 1)
+}
+
+func testLogRepository(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	batch := []*models.LogEntry{
+		request(-10, "192.0.2.1", "GET", "/old", 200),
+		request(0, "192.0.2.1", "GET", "/items/1", 404),
+		request(1, "192.0.2.2", "POST", "/items/2", 200),
+	}
+	require.NoError(t, s.InsertBatch(ctx, batch))
+	for _, entry := range batch {
+		assert.NotZero(t, entry.ID)
+	}
+
+	logs, err := s.Find(ctx, &models.LogFilter{Path: "/items", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/items/2"}, paths(logs))
+
+	count, err := s.Count(ctx, &models.LogFilter{Path: "/items", Limit: 1, Offset: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count, "the filter's paging is ignored")
+
+	facets, err := s.Aggregate(ctx, &models.LogFilter{}, "source_ip", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetCount{{Value: "192.0.2.1", Count: 2}}, facets)
+
+	deleted, err := s.DeleteOlderThan(ctx, at(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// A cancelled request gives up instead of touching the store
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.Find(cancelled, &models.LogFilter{Limit: 10})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = s.Count(cancelled, &models.LogFilter{})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = s.Aggregate(cancelled, &models.LogFilter{}, "method", 10)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = s.DeleteOlderThan(cancelled, at(10))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, s.InsertBatch(cancelled, []*models.LogEntry{request(2, "192.0.2.3", "GET", "/lost", 200)}), context.Canceled)
+
+	count, err = s.Count(ctx, &models.LogFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testStream(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	entries := []*models.LogEntry{
+		request(0, "192.0.2.1", "GET", "/items/1", 200),
+		request(1, "192.0.2.2", "GET", "/items/2", 500),
+		request(2, "192.0.2.1", "GET", "/other", 200),
+		request(3, "192.0.2.3", "GET", "/items/3", 200),
+	}
+	entries[0].Metadata = map[string]interface{}{"country": "DE"}
+	insert(t, s, entries...)
+
+	var streamed []*models.LogEntry
+	collect := func(entry *models.LogEntry) error {
+		streamed = append(streamed, entry)
+		return nil
+	}
+	require.NoError(t, s.Stream(ctx, &models.LogFilter{Path: "/items"}, collect))
+	assert.Equal(t, []string{"/items/3", "/items/2", "/items/1"}, paths(streamed), "every match without a limit, as Find orders them")
+	assert.Equal(t, "DE", streamed[2].Metadata["country"])
+	assert.NotZero(t, streamed[2].ID)
+
+	streamed = nil
+	require.NoError(t, s.Stream(ctx, &models.LogFilter{Path: "/items", Limit: 1, Offset: 1}, collect))
+	assert.Equal(t, []string{"/items/2"}, paths(streamed))
+
+	// An error from fn stops the stream
+	stop := errors.New("stop")
+	calls := 0
+	err := s.Stream(ctx, &models.LogFilter{}, func(*models.LogEntry) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, s.Stream(cancelled, &models.LogFilter{}, collect), context.Canceled)
+}
+
+func testScanAndRewrite(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	entries := []*models.LogEntry{
+		request(0, "192.0.2.1", "GET", "/a", 200),
+		request(1, "192.0.2.2", "GET", "/b", 200),
+		request(2, "192.0.2.1", "GET", "/c", 200),
+	}
+	entries[0].Metadata = map[string]interface{}{"user_id": "alice"}
+	insert(t, s, entries...)
+
+	// Batches continue after the last ID of the previous one
+	first, err := s.ScanAfter(ctx, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/a", "/b"}, paths(first))
+	rest, err := s.ScanAfter(ctx, first[1].ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/c"}, paths(rest))
+	assert.Equal(t, "alice", first[0].Metadata["user_id"])
+
+	first[0].SourceIP = "192.0.2.0"
+	first[0].RawLog = "redacted"
+	first[0].Metadata = map[string]interface{}{"user_id": "[redacted]"}
+	require.NoError(t, s.UpdateContent(ctx, first[:1]))
+
+	deleted, err := s.DeleteByIDs(ctx, []int64{rest[0].ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	deleted, err = s.DeleteByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	all, err := s.ScanAfter(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "192.0.2.0", all[0].SourceIP)
+	assert.Equal(t, "redacted", all[0].RawLog)
+	assert.Equal(t, "[redacted]", all[0].Metadata["user_id"])
+	assert.Equal(t, "/a", all[0].Path, "the entry keeps its ID and other fields")
+	assert.Equal(t, entries[1].ID, all[1].ID)
+}
+
+func testAlertRules(t *testing.T, s storage.Storage) {
+	recovery := 5.0
+	active := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300,
+		ForDuration: 60, RecoveryThreshold: &recovery, IsActive: true}
+	composite := &models.AlertRule{Name: "composite", ConditionType: "composite", TimeWindow: 60, IsActive: false,
+		Expression: &models.AlertCondition{Operator: "and", Conditions: []*models.AlertCondition{
+			{Metric: "error_rate", Comparator: ">", Threshold: 5},
+			{Metric: "request_count", Comparator: ">=", Threshold: 100},
+		}}}
+	require.NoError(t, s.CreateAlertRule(active))
+	require.NoError(t, s.CreateAlertRule(composite))
+	require.NotZero(t, active.ID)
+	require.NotEqual(t, active.ID, composite.ID)
+
+	rules, err := s.GetAlertRules(false)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, active.ID, rules[0].ID, "ordered by ID")
+	assert.Equal(t, "5xx", rules[0].Name)
+	assert.Equal(t, 60, rules[0].ForDuration)
+	require.NotNil(t, rules[0].RecoveryThreshold)
+	assert.Equal(t, 5.0, *rules[0].RecoveryThreshold)
+	assert.Nil(t, rules[0].Expression)
+	require.NotNil(t, rules[1].Expression)
+	assert.Equal(t, composite.Expression, rules[1].Expression)
+
+	rules, err = s.GetAlertRules(true)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "5xx", rules[0].Name)
+
+	updated := *active
+	updated.ThresholdValue = 20
+	updated.RecoveryThreshold = nil
+	updated.IsActive = false
+	require.NoError(t, s.UpdateAlertRule(&updated))
+	// Updating a rule to what it already is still finds it
+	require.NoError(t, s.UpdateAlertRule(&updated))
+
+	rules, err = s.GetAlertRules(false)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, 20.0, rules[0].ThresholdValue)
+	assert.Nil(t, rules[0].RecoveryThreshold)
+	assert.False(t, rules[0].IsActive)
+	assert.Equal(t, "composite", rules[1].Name, "other rules are untouched")
+
+	missing := updated
+	missing.ID = composite.ID + 1000
+	assert.ErrorIs(t, s.UpdateAlertRule(&missing), storage.ErrNotFound)
+}
+
+func testAlertHistory(t *testing.T, s storage.Storage) {
+	rule := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300, IsActive: true}
+	require.NoError(t, s.CreateAlertRule(rule))
+
+	first := &models.AlertEvent{RuleID: rule.ID, Message: "first", Severity: "warning", TriggeredAt: at(0)}
+	second := &models.AlertEvent{RuleID: rule.ID, Message: "second", Severity: "critical", TriggeredAt: at(5)}
+	require.NoError(t, s.InsertAlertEvent(first))
+	require.NoError(t, s.InsertAlertEvent(second))
+	require.NotZero(t, first.ID)
+
+	history, err := s.GetAlertHistory(10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "second", history[0].Message, "most recent first")
+	assert.Equal(t, "critical", history[0].Severity)
+	assert.Equal(t, "5xx", history[0].RuleName)
+	assert.Equal(t, at(5), history[0].TriggeredAt.UTC())
+	assert.Nil(t, history[0].AcknowledgedAt)
+
+	require.NoError(t, s.AcknowledgeAlertEvent(first.ID, "alice", at(10)))
+	assert.ErrorIs(t, s.AcknowledgeAlertEvent(first.ID, "bob", at(11)), storage.ErrNotFound, "already acknowledged")
+	assert.ErrorIs(t, s.AcknowledgeAlertEvent(first.ID+second.ID+100, "bob", at(11)), storage.ErrNotFound)
+
+	history, err = s.GetAlertHistory(1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	history, err = s.GetAlertHistory(10)
+	require.NoError(t, err)
+	require.NotNil(t, history[1].AcknowledgedAt)
+	assert.Equal(t, at(10), history[1].AcknowledgedAt.UTC())
+	assert.Equal(t, "alice", history[1].AcknowledgedBy)
+}
+
+func testRuleEvaluations(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	evaluation := func(ruleID int64, minute int, state string) models.RuleEvaluation {
+		return models.RuleEvaluation{RuleID: ruleID, EvaluatedAt: at(minute), Value: float64(minute), Threshold: 10, State: state}
+	}
+	composite := evaluation(1, 2, models.RuleStateFiring)
+	composite.Details = map[string]float64{"error_rate": 12.5}
+	require.NoError(t, s.InsertRuleEvaluations(ctx, []models.RuleEvaluation{
+		evaluation(1, 0, models.RuleStateOK), evaluation(2, 0, models.RuleStateOK),
+	}))
+	require.NoError(t, s.InsertRuleEvaluations(ctx, []models.RuleEvaluation{
+		evaluation(1, 1, models.RuleStatePending), composite,
+	}))
+	require.NoError(t, s.InsertRuleEvaluations(ctx, nil))
+
+	evaluations, err := s.GetRuleEvaluations(ctx, 1, at(0), 10)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 3)
+	assert.Equal(t, at(0), evaluations[0].EvaluatedAt.UTC(), "oldest first")
+	assert.Equal(t, models.RuleStatePending, evaluations[1].State)
+	assert.Equal(t, 1.0, evaluations[1].Value)
+	assert.Equal(t, 10.0, evaluations[1].Threshold)
+	assert.Equal(t, map[string]float64{"error_rate": 12.5}, evaluations[2].Details)
+	assert.Nil(t, evaluations[0].Details)
+
+	evaluations, err = s.GetRuleEvaluations(ctx, 1, at(0), 2)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 2, "the latest within the limit")
+	assert.Equal(t, at(1), evaluations[0].EvaluatedAt.UTC())
+	evaluations, err = s.GetRuleEvaluations(ctx, 1, at(2), 10)
+	require.NoError(t, err)
+	assert.Len(t, evaluations, 1)
+
+	deleted, err := s.DeleteRuleEvaluations(ctx, at(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	evaluations, err = s.GetRuleEvaluations(ctx, 2, at(-10), 10)
+	require.NoError(t, err)
+	assert.Empty(t, evaluations)
+}
+
+func testMaintenanceWindows(t *testing.T, s storage.Storage) {
+	late := &models.MaintenanceWindow{Name: "late", StartsAt: at(60), EndsAt: at(90), SilenceAlerts: true}
+	early := &models.MaintenanceWindow{Name: "early", Description: "upgrade", StartsAt: at(0), EndsAt: at(30)}
+	require.NoError(t, s.CreateMaintenanceWindow(late))
+	require.NoError(t, s.CreateMaintenanceWindow(early))
+	require.NotZero(t, late.ID)
+
+	windows, err := s.GetMaintenanceWindows(at(-10), at(120))
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+	assert.Equal(t, "early", windows[0].Name, "ordered by start")
+	assert.Equal(t, "upgrade", windows[0].Description)
+	assert.False(t, windows[0].SilenceAlerts)
+	assert.True(t, windows[1].SilenceAlerts)
+	assert.Equal(t, at(60), windows[1].StartsAt.UTC())
+
+	// Windows touching the range without overlapping it are left out
+	windows, err = s.GetMaintenanceWindows(at(30), at(60))
+	require.NoError(t, err)
+	assert.Empty(t, windows)
+
+	found, err := s.DeleteMaintenanceWindow(early.ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteMaintenanceWindow(early.ID)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	windows, err = s.GetMaintenanceWindows(at(-10), at(120))
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, "late", windows[0].Name)
+}
+
+func testLatencyBudgets(t *testing.T, s storage.Storage) {
+	search := &models.LatencyBudget{Path: "/api/search", Percentile: 99, ThresholdMs: 1200, Team: "search"}
+	users := &models.LatencyBudget{Path: "/api/users/{id}", Percentile: 95, ThresholdMs: 250.5, Description: "profile page"}
+	fast := &models.LatencyBudget{Path: "/api/search", Percentile: 50, ThresholdMs: 200}
+	for _, budget := range []*models.LatencyBudget{search, users, fast} {
+		require.NoError(t, s.CreateLatencyBudget(budget))
+	}
+	require.NotZero(t, search.ID)
+
+	budgets, err := s.GetLatencyBudgets()
+	require.NoError(t, err)
+	require.Len(t, budgets, 3)
+	assert.Equal(t, fast.ID, budgets[0].ID, "ordered by path and percentile")
+	assert.Equal(t, search.ID, budgets[1].ID)
+	assert.Equal(t, "search", budgets[1].Team)
+	assert.Equal(t, "/api/users/{id}", budgets[2].Path)
+	assert.Equal(t, 95.0, budgets[2].Percentile)
+	assert.Equal(t, 250.5, budgets[2].ThresholdMs)
+	assert.Equal(t, "profile page", budgets[2].Description)
+
+	found, err := s.DeleteLatencyBudget(fast.ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteLatencyBudget(fast.ID)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	budgets, err = s.GetLatencyBudgets()
+	require.NoError(t, err)
+	assert.Len(t, budgets, 2)
+}
+
+func testFeatureOverrides(t *testing.T, s storage.Storage) {
+	require.NoError(t, s.SetFeatureOverride(&models.FeatureOverride{Flag: "geoip", Project: "shop", Enabled: true, UpdatedBy: "alice", UpdatedAt: at(0)}))
+	require.NoError(t, s.SetFeatureOverride(&models.FeatureOverride{Flag: "geoip", Enabled: false, UpdatedAt: at(0)}))
+	require.NoError(t, s.SetFeatureOverride(&models.FeatureOverride{Flag: "anomaly_detection", Project: "shop", Enabled: true, UpdatedAt: at(0)}))
+
+	// Setting an override again replaces it
+	require.NoError(t, s.SetFeatureOverride(&models.FeatureOverride{Flag: "geoip", Project: "shop", Enabled: false, UpdatedBy: "bob", UpdatedAt: at(5)}))
+
+	overrides, err := s.GetFeatureOverrides()
+	require.NoError(t, err)
+	require.Len(t, overrides, 3)
+	assert.Equal(t, "anomaly_detection", overrides[0].Flag, "ordered by flag")
+	assert.Equal(t, "", overrides[1].Project, "then by project")
+	assert.Equal(t, "shop", overrides[2].Project)
+	assert.False(t, overrides[2].Enabled)
+	assert.Equal(t, "bob", overrides[2].UpdatedBy)
+	assert.Equal(t, at(5), overrides[2].UpdatedAt.UTC())
+
+	found, err := s.DeleteFeatureOverride("geoip", "shop")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteFeatureOverride("geoip", "shop")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	overrides, err = s.GetFeatureOverrides()
+	require.NoError(t, err)
+	assert.Len(t, overrides, 2)
+}
+
+func testConfigVersions(t *testing.T, s storage.Storage) {
+	first := &models.ConfigVersion{Kind: "alert_rule", ObjectID: "1", Action: "created", Actor: "alice",
+		State: json.RawMessage(`{"name":"5xx","threshold_value":10}`), CreatedAt: at(0)}
+	other := &models.ConfigVersion{Kind: "feature_override", ObjectID: "geoip", Action: "created", Actor: "bob",
+		State: json.RawMessage(`{"enabled":true}`), CreatedAt: at(1)}
+	second := &models.ConfigVersion{Kind: "alert_rule", ObjectID: "1", Action: "updated", Actor: "bob",
+		State: json.RawMessage(`{"name":"5xx","threshold_value":20}`), CreatedAt: at(2)}
+	deleted := &models.ConfigVersion{Kind: "feature_override", ObjectID: "geoip", Action: "deleted", Actor: "bob", CreatedAt: at(3)}
+	rollback := &models.ConfigVersion{Kind: "alert_rule", ObjectID: "1", Action: "rolled_back", Actor: "carol",
+		State: first.State, RestoredFrom: 1}
+	for _, version := range []*models.ConfigVersion{first, other, second, deleted, rollback} {
+		require.NoError(t, s.AppendConfigVersion(version))
+		require.NotZero(t, version.ID)
+	}
+	assert.Equal(t, []int{1, 1, 2, 2, 3}, []int{first.Version, other.Version, second.Version, deleted.Version, rollback.Version},
+		"numbered per object")
+	assert.False(t, rollback.CreatedAt.IsZero())
+
+	versions, err := s.GetConfigVersions("alert_rule", "1", 10)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{versions[0].Version, versions[1].Version, versions[2].Version}, "most recent first")
+	assert.Equal(t, 1, versions[0].RestoredFrom)
+	assert.Equal(t, "carol", versions[0].Actor)
+	assert.JSONEq(t, `{"name":"5xx","threshold_value":20}`, string(versions[1].State))
+	assert.Equal(t, at(2), versions[1].CreatedAt.UTC())
+	assert.Zero(t, versions[1].RestoredFrom)
+
+	versions, err = s.GetConfigVersions("", "", 2)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, rollback.ID, versions[0].ID)
+	assert.Equal(t, deleted.ID, versions[1].ID)
+
+	versions, err = s.GetConfigVersions("feature_override", "", 10)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	version, err := s.GetConfigVersion("feature_override", "geoip", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", version.Action)
+	assert.Empty(t, version.State, "a deleted object has no state")
+
+	_, err = s.GetConfigVersion("feature_override", "geoip", 3)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func testIngestedFiles(t *testing.T, s storage.Storage) {
+	sum := strings.Repeat("ab", 32)
+	_, err := s.GetIngestedFile(sum)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, s.RecordIngestedFile(&models.IngestedFile{SHA256: sum, Filename: "access.log.1", LogType: "nginx", Size: 1024, IngestedAt: at(0)}))
+	// Recording the same content again keeps the first record
+	require.NoError(t, s.RecordIngestedFile(&models.IngestedFile{SHA256: sum, Filename: "copy.log", LogType: "nginx", Size: 1024, IngestedAt: at(5)}))
+
+	file, err := s.GetIngestedFile(sum)
+	require.NoError(t, err)
+	assert.Equal(t, "access.log.1", file.Filename)
+	assert.Equal(t, "nginx", file.LogType)
+	assert.Equal(t, int64(1024), file.Size)
+	assert.Equal(t, at(0), file.IngestedAt.UTC())
+}
+
+func testReportFiles(t *testing.T, s storage.Storage) {
+	_, err := s.GetReportFile("daily_2024-01-15_02-00-00.html")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, s.SaveReportFile(&models.ReportFile{Name: "daily_2024-01-15_02-00-00.html", Size: 100, SHA256: strings.Repeat("ab", 32), ContentType: "text/html; charset=utf-8", CreatedAt: at(0)}))
+	// Saving a file again replaces its metadata
+	require.NoError(t, s.SaveReportFile(&models.ReportFile{Name: "daily_2024-01-15_02-00-00.html", Size: 120, SHA256: strings.Repeat("cd", 32), ContentType: "text/html; charset=utf-8", CreatedAt: at(5)}))
+	require.NoError(t, s.SaveReportFile(&models.ReportFile{Name: "compliance/2024-01/MANIFEST", Size: 64, SHA256: strings.Repeat("ef", 32), ContentType: "application/octet-stream", CreatedAt: at(1)}))
+
+	file, err := s.GetReportFile("daily_2024-01-15_02-00-00.html")
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), file.Size)
+	assert.Equal(t, strings.Repeat("cd", 32), file.SHA256)
+	assert.Equal(t, "text/html; charset=utf-8", file.ContentType)
+	assert.Equal(t, at(5), file.CreatedAt.UTC())
+
+	file, err = s.GetReportFile("compliance/2024-01/MANIFEST")
+	require.NoError(t, err)
+	assert.Equal(t, int64(64), file.Size)
+}
+
+func testReportTemplates(t *testing.T, s storage.Storage) {
+	_, err := s.GetReportTemplate("standard", "branded")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	branded := &models.ReportTemplate{ReportType: "standard", Name: "branded", Content: "<h1>{{.Title}}</h1>", UpdatedBy: "alice", UpdatedAt: at(0)}
+	require.NoError(t, s.SaveReportTemplate(branded))
+	require.NoError(t, s.SaveReportTemplate(&models.ReportTemplate{ReportType: "errors", Name: "branded", Content: "<h1>Errors</h1>", UpdatedAt: at(0)}))
+	require.NoError(t, s.SaveReportTemplate(&models.ReportTemplate{ReportType: "standard", Name: "appendix", Content: "<p></p>", UpdatedAt: at(0)}))
+
+	// Saving a template again replaces it
+	require.NoError(t, s.SaveReportTemplate(&models.ReportTemplate{ReportType: "standard", Name: "branded", Content: "<h1>{{.Title}}!</h1>", UpdatedBy: "bob", UpdatedAt: at(5)}))
+
+	tmpl, err := s.GetReportTemplate("standard", "branded")
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>{{.Title}}!</h1>", tmpl.Content)
+	assert.Equal(t, "bob", tmpl.UpdatedBy)
+	assert.Equal(t, at(5), tmpl.UpdatedAt.UTC())
+
+	templates, err := s.GetReportTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, 3)
+	assert.Equal(t, "errors", templates[0].ReportType, "ordered by report type")
+	assert.Equal(t, "appendix", templates[1].Name, "then by name")
+	assert.Equal(t, "branded", templates[2].Name)
+	assert.Empty(t, templates[2].Content, "listed without content")
+
+	found, err := s.DeleteReportTemplate("standard", "branded")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteReportTemplate("standard", "branded")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = s.GetReportTemplate("standard", "branded")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	tmpl, err = s.GetReportTemplate("errors", "branded")
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>Errors</h1>", tmpl.Content)
+}
+
+func testSavedSearches(t *testing.T, s storage.Storage) {
+	_, err := s.GetSavedSearch("alice", "errors")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	start, status := at(0), 502
+	gateway := &models.SavedSearch{
+		Owner: "alice", Name: "errors", Description: "Gateway errors",
+		Filter:    models.LogFilter{StartTime: &start, LogType: "nginx", StatusCode: &status, Path: "/api", SortBy: "processing_time"},
+		CreatedAt: at(0), UpdatedAt: at(0),
+	}
+	require.NoError(t, s.SaveSavedSearch(gateway))
+	require.NoError(t, s.SaveSavedSearch(&models.SavedSearch{Owner: "alice", Name: "admin", CreatedAt: at(1), UpdatedAt: at(1)}))
+	require.NoError(t, s.SaveSavedSearch(&models.SavedSearch{Owner: "bob", Name: "errors", CreatedAt: at(1), UpdatedAt: at(1)}))
+
+	// Saving a search again replaces it but keeps when it was created
+	gateway.Filter.Method, gateway.UpdatedAt, gateway.CreatedAt = "POST", at(5), at(5)
+	require.NoError(t, s.SaveSavedSearch(gateway))
+
+	search, err := s.GetSavedSearch("alice", "errors")
+	require.NoError(t, err)
+	assert.Equal(t, "Gateway errors", search.Description)
+	require.NotNil(t, search.Filter.StartTime)
+	assert.Equal(t, at(0), search.Filter.StartTime.UTC())
+	require.NotNil(t, search.Filter.StatusCode)
+	assert.Equal(t, 502, *search.Filter.StatusCode)
+	assert.Equal(t, "/api", search.Filter.Path)
+	assert.Equal(t, "POST", search.Filter.Method)
+	assert.Equal(t, "processing_time", search.Filter.SortBy)
+	assert.Equal(t, at(0), search.CreatedAt.UTC())
+	assert.Equal(t, at(5), search.UpdatedAt.UTC())
+
+	searches, err := s.GetSavedSearches("alice")
+	require.NoError(t, err)
+	require.Len(t, searches, 2)
+	assert.Equal(t, "admin", searches[0].Name, "ordered by name")
+	assert.Equal(t, "errors", searches[1].Name)
+
+	found, err := s.DeleteSavedSearch("alice", "errors")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteSavedSearch("alice", "errors")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, err = s.GetSavedSearch("bob", "errors")
+	assert.NoError(t, err, "each owner's searches are their own")
+}
+
+func testUsers(t *testing.T, s storage.Storage) {
+	roles, err := s.GetRoles()
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	assert.Equal(t, []string{"viewer", "analyst", "admin"}, []string{roles[0].Name, roles[1].Name, roles[2].Name}, "ordered by level")
+	assert.Less(t, roles[0].Level, roles[1].Level)
+	assert.NotEmpty(t, roles[2].Description)
+
+	_, err = s.GetUser("u-1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	signedIn := at(3)
+	require.NoError(t, s.SaveUser(&models.User{Subject: "u-2", Email: "bob@example.com", Role: "viewer", UpdatedAt: at(0)}))
+	require.NoError(t, s.SaveUser(&models.User{Subject: "u-1", Email: "alice@example.com", Name: "Alice", Role: "viewer", LastSignInAt: &signedIn, UpdatedAt: at(3)}))
+
+	// Saving a user again replaces them
+	require.NoError(t, s.SaveUser(&models.User{Subject: "u-1", Email: "alice@example.com", Name: "Alice", Role: "admin", LastSignInAt: &signedIn, UpdatedBy: "bob@example.com", UpdatedAt: at(5)}))
+	user, err := s.GetUser("u-1")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Role)
+	assert.Equal(t, "Alice", user.Name)
+	assert.Equal(t, "bob@example.com", user.UpdatedBy)
+	assert.Equal(t, at(5), user.UpdatedAt.UTC())
+	require.NotNil(t, user.LastSignInAt)
+	assert.Equal(t, at(3), user.LastSignInAt.UTC())
+
+	assert.Error(t, s.SaveUser(&models.User{Subject: "u-3", Role: "superuser", UpdatedAt: at(0)}), "roles must exist")
+
+	users, err := s.GetUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "u-1", users[0].Subject, "ordered by subject")
+	assert.Equal(t, "bob@example.com", users[1].Email)
+	assert.Nil(t, users[1].LastSignInAt)
+
+	found, err := s.DeleteUser("u-2")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = s.DeleteUser("u-2")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, err = s.GetUser("u-2")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func testDataKeys(t *testing.T, s storage.Storage) {
+	_, err := s.GetDataKey("payments")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, s.CreateDataKey(&models.DataKey{Project: "payments", WrappedKey: []byte{1, 2, 0, 3}, MasterKeyID: "local", CreatedAt: at(0)}))
+	// Creating a key again keeps the first
+	require.NoError(t, s.CreateDataKey(&models.DataKey{Project: "payments", WrappedKey: []byte{4, 5, 6}, MasterKeyID: "other", CreatedAt: at(5)}))
+
+	key, err := s.GetDataKey("payments")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 0, 3}, key.WrappedKey)
+	assert.Equal(t, "local", key.MasterKeyID)
+	assert.Equal(t, at(0), key.CreatedAt.UTC())
+
+	_, err = s.GetDataKey("billing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func testIntegrity(t *testing.T, s storage.Storage) {
+	rule := &models.AlertRule{Name: "5xx", ConditionType: "error_rate", ThresholdValue: 10, TimeWindow: 300, IsActive: true}
+	require.NoError(t, s.CreateAlertRule(rule))
+	require.NoError(t, s.InsertAlertEvent(&models.AlertEvent{RuleID: rule.ID, Message: "fired", Severity: "warning", TriggeredAt: at(0)}))
+
+	orphans, err := s.GetOrphanedAlertEvents()
+	require.NoError(t, err)
+	assert.Empty(t, orphans, "every alert has its rule")
+
+	// Backends with roll-ups have none for hours without entries
+	totals, err := s.GetRollupTotals(at(-120), at(120))
+	require.NoError(t, err)
+	assert.Empty(t, totals)
+}
+
+func testTrafficRollups(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	first := request(0, "192.0.2.1", "GET", "/a", 200)
+	first.ResponseSize = 100
+	second := request(10, "192.0.2.2", "GET", "/a", 200)
+	second.ResponseSize = 50
+	insert(t, s, first, second,
+		request(20, "192.0.2.1", "GET", "/a", 500),
+		message(30, "app", "boot"),
+		request(70, "192.0.2.3", "GET", "/b", 404),
+		request(-10, "192.0.2.4", "GET", "/c", 200))
+
+	hour := at(0)
+	for _, bucket := range []time.Time{hour, hour.Add(time.Hour)} {
+		require.NoError(t, s.RefreshTrafficRollups(ctx, models.RollupHour, bucket))
+	}
+	require.NoError(t, s.RefreshTrafficRollups(ctx, models.RollupDay, models.RollupBucket(models.RollupDay, hour)))
+
+	summarize := func(query models.TrafficQuery) []models.TrafficRollup {
+		t.Helper()
+		if query.Interval == "" {
+			query.Interval = models.RollupHour
+		}
+		if query.End.IsZero() {
+			query.Start, query.End = hour, hour.Add(2*time.Hour)
+		}
+		rollups, err := s.SummarizeTraffic(ctx, &query)
+		require.NoError(t, err)
+		return rollups
+	}
+
+	total := summarize(models.TrafficQuery{})
+	require.Len(t, total, 1)
+	assert.Equal(t, int64(5), total[0].Requests, "the hour before was not refreshed")
+	assert.Equal(t, int64(2), total[0].Errors)
+	assert.Equal(t, int64(150), total[0].Bytes)
+
+	series := summarize(models.TrafficQuery{GroupBy: []string{"bucket"}})
+	require.Len(t, series, 2)
+	assert.Equal(t, hour, series[0].Bucket.UTC())
+	assert.Equal(t, int64(4), series[0].Requests)
+	assert.Equal(t, hour.Add(time.Hour), series[1].Bucket.UTC())
+	assert.Equal(t, int64(1), series[1].Requests)
+
+	top := summarize(models.TrafficQuery{GroupBy: []string{"path"}, Limit: 1})
+	require.Len(t, top, 1)
+	assert.Equal(t, "/a", top[0].Path)
+	assert.Equal(t, int64(3), top[0].Requests)
+
+	statuses := summarize(models.TrafficQuery{LogType: "nginx", GroupBy: []string{"status_code"}})
+	var codes []int
+	for _, rollup := range statuses {
+		codes = append(codes, rollup.StatusCode)
+	}
+	assert.Equal(t, []int{200, 404, 500}, codes, "busiest first, then by status code")
+
+	cells := summarize(models.TrafficQuery{GroupBy: []string{"log_type", "path", "status_code"}})
+	require.NotEmpty(t, cells)
+	assert.Equal(t, models.TrafficRollup{LogType: "nginx", Path: "/a", StatusCode: 200, Requests: 2, Bytes: 150, UniqueIPs: 2}, cells[0])
+
+	day := summarize(models.TrafficQuery{Interval: models.RollupDay, Start: hour.Add(-12 * time.Hour), End: hour.Add(12 * time.Hour)})
+	assert.Equal(t, int64(6), day[0].Requests)
+
+	// Refreshing replaces a bucket's rollups
+	insert(t, s, request(40, "192.0.2.5", "GET", "/a", 200))
+	require.NoError(t, s.RefreshTrafficRollups(ctx, models.RollupHour, hour))
+	assert.Equal(t, int64(6), summarize(models.TrafficQuery{})[0].Requests)
+
+	removed, err := s.DeleteTrafficRollups(ctx, models.RollupHour, hour.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), removed)
+	assert.Equal(t, int64(1), summarize(models.TrafficQuery{})[0].Requests)
+
+	_, err = s.SummarizeTraffic(ctx, &models.TrafficQuery{Interval: "week"})
+	assert.Error(t, err)
+	_, err = s.SummarizeTraffic(ctx, &models.TrafficQuery{Interval: models.RollupHour, GroupBy: []string{"source_ip"}})
+	assert.Error(t, err)
+}
+
+func appendAudit(t *testing.T, s storage.Storage, subject string) *models.AuditRecord {
+	t.Helper()
+	record, err := audit.NewRecord(audit.ActionAlertAcknowledged, "alice", subject, map[string]interface{}{"note": "a \"quoted\" value"})
+	require.NoError(t, err)
+	require.NoError(t, s.AppendAuditRecord(record))
+	return record
+}
+
+func testAuditChain(t *testing.T, s storage.Storage) {
+	first := appendAudit(t, s, "alert:1")
+	assert.Equal(t, int64(1), first.Seq)
+	assert.Equal(t, audit.GenesisHash, first.PrevHash)
+	second := appendAudit(t, s, "alert:2")
+	appendAudit(t, s, "alert:3")
+	assert.Equal(t, first.Hash, second.PrevHash)
+
+	records, err := s.GetAuditRecords(0, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	verifier := audit.NewVerifier()
+	for _, record := range records {
+		require.NoError(t, verifier.Add(record), "stored records must hash as they were sealed")
+	}
+	assert.Equal(t, first.Details, records[0].Details)
+
+	records, err = s.GetAuditRecords(1, 1)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "alert:2", records[0].Subject)
+
+	record, err := s.GetAuditRecord(2)
+	require.NoError(t, err)
+	assert.Equal(t, second.Hash, record.Hash)
+	_, err = s.GetAuditRecord(4)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func testConcurrentAuditAppends(t *testing.T, s storage.Storage) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			record, err := audit.NewRecord(audit.ActionLogsUploaded, "system", fmt.Sprintf("file-%d", i), nil)
+			if err == nil {
+				err = s.AppendAuditRecord(record)
+			}
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	records, err := s.GetAuditRecords(0, 100)
+	require.NoError(t, err)
+	require.Len(t, records, 20)
+	verifier := audit.NewVerifier()
+	for _, record := range records {
+		require.NoError(t, verifier.Add(record), "concurrent appends must not fork the chain")
+	}
+}
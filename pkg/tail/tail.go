@@ -0,0 +1,103 @@
+// Package tail fans newly stored log entries out to live subscribers, such
+// as operators following their access logs during an incident.
+package tail
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+)
+
+// ErrTooManySubscribers is returned by Subscribe when the hub is full
+var ErrTooManySubscribers = errors.New("too many live tail subscribers")
+
+// Hub delivers published entries to the subscribers whose filter they
+// match. Publishing never waits for a subscriber: entries a subscriber has
+// no room for are dropped and counted. Its methods are safe for concurrent
+// use.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	max         int
+	buffer      int
+}
+
+// NewHub returns a hub of at most max subscribers, each buffering up to
+// buffer entries it has not yet received
+func NewHub(max, buffer int) *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{}), max: max, buffer: buffer}
+}
+
+// Subscription is a subscriber's feed of entries
+type Subscription struct {
+	hub     *Hub
+	filter  models.LogFilter
+	entries chan *models.LogEntry
+	dropped atomic.Int64
+	closed  sync.Once
+}
+
+// Subscribe starts a feed of the entries matching filter, or of every
+// entry with an empty filter. The caller must Close it when done.
+func (h *Hub) Subscribe(filter models.LogFilter) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.subscribers) >= h.max {
+		return nil, ErrTooManySubscribers
+	}
+	sub := &Subscription{hub: h, filter: filter, entries: make(chan *models.LogEntry, h.buffer)}
+	h.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Publish offers stored entries to every subscriber. Entries are shared
+// between subscribers and must not be changed afterwards.
+func (h *Hub) Publish(entries []*models.LogEntry) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		for _, entry := range entries {
+			if !sub.filter.Matches(entry) {
+				continue
+			}
+			select {
+			case sub.entries <- entry:
+			default:
+				sub.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// Subscribers is the number of open subscriptions
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Entries delivers the subscription's entries in the order they were
+// published. It is closed by Close.
+func (s *Subscription) Entries() <-chan *models.LogEntry {
+	return s.entries
+}
+
+// Dropped returns how many entries were dropped since it was last called,
+// because the subscriber fell behind
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// Close ends the subscription. It may be called more than once.
+func (s *Subscription) Close() {
+	s.closed.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+		delete(s.hub.subscribers, s)
+		close(s.entries)
+	})
+}
@@ -0,0 +1,73 @@
+package tail
+
+import (
+	"testing"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func received(sub *Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case entry := <-sub.Entries():
+			ids = append(ids, entry.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestPublishFiltersEntries(t *testing.T) {
+	hub := NewHub(10, 10)
+	all, err := hub.Subscribe(models.LogFilter{})
+	require.NoError(t, err)
+	defer all.Close()
+	errors, err := hub.Subscribe(models.LogFilter{MinStatusCode: 500, Path: "/api/"})
+	require.NoError(t, err)
+	defer errors.Close()
+
+	hub.Publish([]*models.LogEntry{
+		{ID: 1, Path: "/api/orders", StatusCode: 200},
+		{ID: 2, Path: "/api/orders", StatusCode: 503},
+		{ID: 3, Path: "/static/app.js", StatusCode: 500},
+	})
+
+	assert.Equal(t, []int64{1, 2, 3}, received(all))
+	assert.Equal(t, []int64{2}, received(errors))
+}
+
+func TestPublishDropsForSlowSubscribers(t *testing.T) {
+	hub := NewHub(10, 2)
+	sub, err := hub.Subscribe(models.LogFilter{})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	hub.Publish([]*models.LogEntry{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}})
+
+	assert.Equal(t, []int64{1, 2}, received(sub))
+	assert.Equal(t, int64(2), sub.Dropped())
+	assert.Equal(t, int64(0), sub.Dropped())
+}
+
+func TestSubscribeLimit(t *testing.T) {
+	hub := NewHub(1, 1)
+	sub, err := hub.Subscribe(models.LogFilter{})
+	require.NoError(t, err)
+
+	_, err = hub.Subscribe(models.LogFilter{})
+	assert.ErrorIs(t, err, ErrTooManySubscribers)
+
+	sub.Close()
+	sub.Close()
+	assert.Equal(t, 0, hub.Subscribers())
+	_, ok := <-sub.Entries()
+	assert.False(t, ok)
+
+	hub.Publish([]*models.LogEntry{{ID: 1}})
+	again, err := hub.Subscribe(models.LogFilter{})
+	require.NoError(t, err)
+	again.Close()
+}
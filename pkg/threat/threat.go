@@ -0,0 +1,136 @@
+// Package threat recognizes attacks in access logs: injection and
+// traversal signatures in request paths, vulnerability scanners by their
+// user agents, and requests to authentication endpoints, for security
+// reports. Signatures match common payloads, so encoded or novel attacks
+// can go unnoticed and unusual but harmless paths can match.
+package threat
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Attack signatures a path can match
+const (
+	SQLInjection  = "sql_injection"
+	XSS           = "xss"
+	PathTraversal = "path_traversal"
+)
+
+// Categories lists the signature categories in the order reports show them
+var Categories = []string{SQLInjection, XSS, PathTraversal}
+
+// signatures match the decoded, lowercased path and query of a request
+var signatures = []struct {
+	category string
+	pattern  *regexp.Regexp
+}{
+	{SQLInjection, regexp.MustCompile(`union(\s|/\*.*?\*/)+(all(\s|/\*.*?\*/)+)?select\b` +
+		`|\bselect\s.+\sfrom\s+\w` +
+		`|['"]\s*(or|and)\s+['"]?\w+['"]?\s*(=|like)` +
+		`|\b(or|and)\s+\d+\s*=\s*\d+` +
+		`|\b(sleep|benchmark|pg_sleep)\s*\(` +
+		`|waitfor\s+delay` +
+		`|information_schema` +
+		`|;\s*(drop|insert|update|delete|truncate)\s` +
+		`|['"]\s*(--|#|/\*)`)},
+	{XSS, regexp.MustCompile(`<\s*(script|iframe|svg|img|body|object|embed)\b` +
+		`|javascript:` +
+		`|\bon(error|load|mouseover|focus|click)\s*=` +
+		`|document\.(cookie|location)` +
+		`|\balert\s*\(`)},
+	{PathTraversal, regexp.MustCompile(`\.\.[/\\]` +
+		`|/etc/(passwd|shadow|hosts)` +
+		`|/proc/self/` +
+		`|(win|boot)\.ini` +
+		`|c:\\windows`)},
+}
+
+// Signatures returns the categories of the attack signatures a request
+// path, including its query, matches. The path is URL-decoded up to twice
+// first, which also uncovers double-encoded payloads.
+func Signatures(path string) []string {
+	decoded := strings.ToLower(path)
+	for i := 0; i < 2; i++ {
+		next, err := url.QueryUnescape(decoded)
+		if err != nil || next == decoded {
+			break
+		}
+		decoded = next
+	}
+
+	var matched []string
+	for _, signature := range signatures {
+		if signature.pattern.MatchString(decoded) {
+			matched = append(matched, signature.category)
+		}
+	}
+	return matched
+}
+
+// scanners are the lowercased tokens of vulnerability scanners and
+// reconnaissance tools, with the name reported for each
+var scanners = []struct{ token, name string }{
+	{"sqlmap", "sqlmap"},
+	{"nikto", "Nikto"},
+	{"nmap", "Nmap"},
+	{"masscan", "masscan"},
+	{"zgrab", "ZGrab"},
+	{"nuclei", "Nuclei"},
+	{"wpscan", "WPScan"},
+	{"dirbuster", "DirBuster"},
+	{"gobuster", "Gobuster"},
+	{"feroxbuster", "feroxbuster"},
+	{"fuzz faster u fool", "ffuf"},
+	{"ffuf", "ffuf"},
+	{"wfuzz", "Wfuzz"},
+	{"acunetix", "Acunetix"},
+	{"nessus", "Nessus"},
+	{"openvas", "OpenVAS"},
+	{"qualys", "Qualys"},
+	{"netsparker", "Netsparker"},
+	{"burp", "Burp Suite"},
+	{"owasp zap", "OWASP ZAP"},
+	{"zaproxy", "OWASP ZAP"},
+	{"w3af", "w3af"},
+	{"arachni", "Arachni"},
+	{"skipfish", "skipfish"},
+	{"whatweb", "WhatWeb"},
+	{"jaeles", "Jaeles"},
+	{"hydra", "Hydra"},
+	{"censysinspect", "Censys"},
+	{"expanse", "Expanse"},
+}
+
+// DetectScanner returns the name of the vulnerability scanner a user
+// agent belongs to, and whether it belongs to one
+func DetectScanner(userAgent string) (string, bool) {
+	lower := strings.ToLower(userAgent)
+	for _, scanner := range scanners {
+		if strings.Contains(lower, scanner.token) {
+			return scanner.name, true
+		}
+	}
+	return "", false
+}
+
+// authPattern matches the paths of login, token and password endpoints
+var authPattern = regexp.MustCompile(`(^|/)(login|log-in|logon|signin|sign-in|sign_in|auth|authenticate|` +
+	`session|sessions|token|oauth|sso|wp-login|xmlrpc|password|j_security_check)(\.(php|aspx?|jsp|do))?([/?;]|$)`)
+
+// IsAuthPath reports whether a request path is an authentication
+// endpoint, where repeated failures suggest password guessing
+func IsAuthPath(path string) bool {
+	return authPattern.MatchString(strings.ToLower(path))
+}
+
+// Denied reports whether a status code refused the request: 401, 403,
+// 429 or nginx's 444, which closes the connection
+func Denied(statusCode int) bool {
+	switch statusCode {
+	case 401, 403, 429, 444:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package threat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatures(t *testing.T) {
+	tests := map[string][]string{
+		"/products?id=1%20UNION%20SELECT%20username,password%20FROM%20users": {SQLInjection},
+		"/products?id=1'%20OR%20'1'='1":                                      {SQLInjection},
+		"/search?q=1%20and%201=1":                                            {SQLInjection},
+		"/item?id=5;%20DROP%20TABLE%20users":                                 {SQLInjection},
+		"/login?user=admin'--":                                               {SQLInjection},
+		"/search?q=<script>alert(1)</script>":                                {XSS},
+		"/search?q=%3Cimg%20src%3Dx%20onerror%3Dalert(1)%3E":                 {XSS},
+		"/redirect?to=javascript:document.cookie":                            {XSS},
+		"/static/../../../../etc/passwd":                                     {PathTraversal},
+		"/download?file=%252e%252e%252fconfig":                               {PathTraversal},
+		"/files?name=..\\..\\windows\\win.ini":                               {PathTraversal},
+		"/item?id=1%27%20or%20%271%27=%271&next=<script>":                    {SQLInjection, XSS},
+		"/api/users/42":                                                      nil,
+		"/search?q=select+a+color":                                           nil,
+		"/blog/how-to-select-from-a-menu":                                    nil,
+		"/docs/v1.2/index.html":                                              nil,
+		"/search?q=50%":                                                      nil,
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, Signatures(path), path)
+	}
+}
+
+func TestDetectScanner(t *testing.T) {
+	tests := map[string]string{
+		"sqlmap/1.7.2#stable (https://sqlmap.org)":                                        "sqlmap",
+		"Mozilla/5.00 (Nikto/2.1.6) (Evasions:None) (Test:000003)":                        "Nikto",
+		"Mozilla/5.0 (compatible; Nmap Scripting Engine; https://nmap.org/book/nse.html)": "Nmap",
+		"Fuzz Faster U Fool v2.0.0":                                                       "ffuf",
+		"Mozilla/5.0 zgrab/0.x":                                                           "ZGrab",
+	}
+	for userAgent, want := range tests {
+		name, ok := DetectScanner(userAgent)
+		assert.True(t, ok, userAgent)
+		assert.Equal(t, want, name, userAgent)
+	}
+
+	for _, userAgent := range []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"curl/8.4.0",
+		"",
+	} {
+		_, ok := DetectScanner(userAgent)
+		assert.False(t, ok, userAgent)
+	}
+}
+
+func TestIsAuthPath(t *testing.T) {
+	for _, path := range []string{"/login", "/api/v1/auth/token", "/wp-login.php", "/users/sign_in", "/oauth/token?grant_type=password", "/xmlrpc.php"} {
+		assert.True(t, IsAuthPath(path), path)
+	}
+	for _, path := range []string{"/", "/blog/authors", "/catalog", "/api/tokens-list/x", "/static/login.css"} {
+		assert.False(t, IsAuthPath(path), path)
+	}
+}
+
+func TestDenied(t *testing.T) {
+	for _, code := range []int{401, 403, 429, 444} {
+		assert.True(t, Denied(code), code)
+	}
+	for _, code := range []int{200, 302, 404, 500} {
+		assert.False(t, Denied(code), code)
+	}
+}
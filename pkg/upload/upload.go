@@ -0,0 +1,423 @@
+// Package upload stores large log files uploaded in chunks, so uploads
+// over unreliable links can resume where they stopped. Uploads are kept on
+// disk and survive restarts.
+package upload
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown or expired upload IDs
+	ErrNotFound = errors.New("upload not found")
+	// ErrTooLarge is returned when a chunk would exceed the declared or
+	// maximum size
+	ErrTooLarge = errors.New("upload too large")
+	// ErrChecksumMismatch is returned when a chunk or the completed file
+	// does not match its SHA-256
+	ErrChecksumMismatch = errors.New("sha256 mismatch")
+	// ErrCompleted is returned when appending to a completed upload
+	ErrCompleted = errors.New("upload already completed")
+	// ErrIncomplete is returned when completing before all declared bytes
+	// arrived
+	ErrIncomplete = errors.New("upload incomplete")
+)
+
+// OffsetError is returned when a chunk does not start where the upload
+// ends. Offset is where the next chunk must start.
+type OffsetError struct {
+	Offset int64
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("chunk must start at offset %d", e.Offset)
+}
+
+// Upload describes an upload in progress
+type Upload struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	LogType  string `json:"log_type"`
+	// Size is the declared total size, 0 when unknown
+	Size int64 `json:"size,omitempty"`
+	// MaxErrorRate is the percentage of lines failing to parse that fails
+	// processing the upload, 0 for none
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+	// Offset is the number of bytes received
+	Offset    int64     `json:"offset"`
+	Completed bool      `json:"completed"`
+	SHA256    string    `json:"sha256,omitempty"` // set when completed
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// HashState is the SHA-256 state of the received bytes, kept so
+	// completing does not read the whole file again
+	HashState []byte `json:"hash_state"`
+}
+
+// Store keeps uploads in a directory as <id>.part with <id>.json metadata.
+// The metadata is written after the data, so a crash mid-chunk loses only
+// that chunk.
+type Store struct {
+	dir     string
+	maxSize int64
+
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock serializes operations on one upload. It is removed from the
+// store once no operation holds or waits for it, so completed and aborted
+// uploads leave no lock behind.
+type uploadLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewStore creates dir if needed. maxSize limits an upload, 0 for no limit.
+func NewStore(dir string, maxSize int64) (*Store, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	return &Store{dir: dir, maxSize: maxSize, locks: make(map[string]*uploadLock)}, nil
+}
+
+// Create starts an upload. size is the declared total, 0 when unknown.
+func (s *Store) Create(filename, logType string, size int64, maxErrorRate float64) (*Upload, error) {
+	if filename != "" {
+		filename = filepath.Base(filename)
+	}
+	if size < 0 || (s.maxSize > 0 && size > s.maxSize) {
+		return nil, ErrTooLarge
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	u := &Upload{
+		ID:           id,
+		Filename:     filename,
+		LogType:      logType,
+		Size:         size,
+		MaxErrorRate: maxErrorRate,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		HashState:    state,
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	f.Close()
+	if err := s.save(u); err != nil {
+		os.Remove(s.dataPath(id))
+		return nil, err
+	}
+	return u, nil
+}
+
+// Get returns an upload
+func (s *Store) Get(id string) (*Upload, error) {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.load(id)
+}
+
+// Append writes a chunk starting at offset. The chunk is kept only when it
+// is received in full and, if chunkSHA256 is set, matches it.
+func (s *Store) Append(id string, offset int64, r io.Reader, chunkSHA256 string) (*Upload, error) {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	u, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Completed {
+		return nil, ErrCompleted
+	}
+	if offset != u.Offset {
+		return nil, &OffsetError{Offset: u.Offset}
+	}
+
+	limit := int64(-1)
+	if u.Size > 0 {
+		limit = u.Size - u.Offset
+	}
+	if s.maxSize > 0 && (limit < 0 || s.maxSize-u.Offset < limit) {
+		limit = s.maxSize - u.Offset
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer f.Close()
+	// Bytes past the recorded offset are from an interrupted chunk
+	if err := f.Truncate(u.Offset); err != nil {
+		return nil, fmt.Errorf("failed to truncate upload: %w", err)
+	}
+	if _, err := f.Seek(u.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek upload: %w", err)
+	}
+
+	fileHash, err := restoreHash(u.HashState)
+	if err != nil {
+		return nil, err
+	}
+	chunkHash := sha256.New()
+
+	src := r
+	if limit >= 0 {
+		// Read one byte past the limit to detect oversized chunks
+		src = io.LimitReader(r, limit+1)
+	}
+	n, err := io.Copy(io.MultiWriter(f, fileHash, chunkHash), src)
+	fail := func(cause error) (*Upload, error) {
+		f.Truncate(u.Offset)
+		return nil, cause
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to receive chunk: %w", err))
+	}
+	if limit >= 0 && n > limit {
+		return fail(ErrTooLarge)
+	}
+	if chunkSHA256 != "" && !strings.EqualFold(chunkSHA256, hex.EncodeToString(chunkHash.Sum(nil))) {
+		return fail(ErrChecksumMismatch)
+	}
+	if err := f.Sync(); err != nil {
+		return fail(fmt.Errorf("failed to write chunk: %w", err))
+	}
+
+	state, err := fileHash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fail(err)
+	}
+	u.Offset += n
+	u.HashState = state
+	u.UpdatedAt = time.Now().UTC()
+	if err := s.save(u); err != nil {
+		return fail(err)
+	}
+	return u, nil
+}
+
+// Complete verifies the received file against its SHA-256 and marks the
+// upload complete
+func (s *Store) Complete(id, sha256Hex string) (*Upload, error) {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	u, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Completed {
+		return nil, ErrCompleted
+	}
+	if u.Size > 0 && u.Offset != u.Size {
+		return nil, ErrIncomplete
+	}
+
+	fileHash, err := restoreHash(u.HashState)
+	if err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(fileHash.Sum(nil))
+	if !strings.EqualFold(sha256Hex, sum) {
+		return nil, ErrChecksumMismatch
+	}
+
+	u.Completed = true
+	u.SHA256 = sum
+	u.UpdatedAt = time.Now().UTC()
+	if err := s.save(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Open reads a completed upload
+func (s *Store) Open(id string) (*os.File, error) {
+	u, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Completed {
+		return nil, ErrIncomplete
+	}
+	return os.Open(s.dataPath(id))
+}
+
+// Remove deletes an upload
+func (s *Store) Remove(id string) error {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	return s.remove(id)
+}
+
+func (s *Store) remove(id string) error {
+	if err := os.Remove(s.metaPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(s.dataPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Expire removes uploads not changed since before cutoff and returns how
+// many were removed. Completed uploads are normally removed once processed,
+// so only those left by a restart during processing remain.
+func (s *Store) Expire(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list uploads: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !validID(id) {
+			continue
+		}
+		unlock, err := s.lock(id)
+		if err != nil {
+			continue
+		}
+		u, err := s.load(id)
+		if err == nil && u.UpdatedAt.Before(cutoff) {
+			if err := s.remove(id); err == nil {
+				removed++
+			}
+		}
+		unlock()
+	}
+	return removed, nil
+}
+
+// lock serializes operations on one upload
+func (s *Store) lock(id string) (func(), error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &uploadLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) load(id string) (*Upload, error) {
+	data, err := os.ReadFile(s.metaPath(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	var u Upload
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("failed to decode upload %s: %w", id, err)
+	}
+	return &u, nil
+}
+
+// save replaces the metadata atomically
+func (s *Store) save(u *Upload) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	tmp := s.metaPath(u.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0640); err != nil {
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	if err := os.Rename(tmp, s.metaPath(u.ID)); err != nil {
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".part")
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func restoreHash(state []byte) (hash.Hash, error) {
+	h := sha256.New()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, fmt.Errorf("failed to restore upload checksum: %w", err)
+	}
+	return h, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validID accepts only IDs made by newID, so IDs cannot name other files
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,144 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sum(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestChunkedUpload(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, 0)
+	require.NoError(t, err)
+
+	u, err := store.Create("/var/log/access.log", "nginx", 0, 25)
+	require.NoError(t, err)
+	assert.Equal(t, "access.log", u.Filename)
+	assert.Len(t, u.ID, 32)
+
+	u, err = store.Append(u.ID, 0, strings.NewReader("first line\n"), sum("first line\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), u.Offset)
+
+	// A repeated chunk is rejected with the offset to resume from
+	_, err = store.Append(u.ID, 0, strings.NewReader("first line\n"), "")
+	var offsetErr *OffsetError
+	require.True(t, errors.As(err, &offsetErr))
+	assert.Equal(t, int64(11), offsetErr.Offset)
+
+	// A corrupted chunk is discarded
+	_, err = store.Append(u.ID, 11, strings.NewReader("second linX\n"), sum("second line\n"))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Uploads survive a restart
+	store, err = NewStore(dir, 0)
+	require.NoError(t, err)
+	u, err = store.Get(u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), u.Offset)
+	assert.Equal(t, 25.0, u.MaxErrorRate)
+
+	_, err = store.Append(u.ID, 11, strings.NewReader("second line\n"), "")
+	require.NoError(t, err)
+
+	_, err = store.Complete(u.ID, sum("wrong"))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = store.Open(u.ID)
+	assert.ErrorIs(t, err, ErrIncomplete)
+
+	u, err = store.Complete(u.ID, strings.ToUpper(sum("first line\nsecond line\n")))
+	require.NoError(t, err)
+	assert.True(t, u.Completed)
+	assert.Equal(t, sum("first line\nsecond line\n"), u.SHA256)
+
+	_, err = store.Append(u.ID, u.Offset, strings.NewReader("more\n"), "")
+	assert.ErrorIs(t, err, ErrCompleted)
+
+	f, err := store.Open(u.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "first line\nsecond line\n", string(data))
+
+	require.NoError(t, store.Remove(u.ID))
+	_, err = store.Get(u.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Empty(t, store.locks, "no lock is kept once operations finish")
+}
+
+func TestUploadSizeLimits(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	_, err = store.Create("big.log", "generic", 11, 0)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	u, err := store.Create("declared.log", "generic", 4, 0)
+	require.NoError(t, err)
+	_, err = store.Append(u.ID, 0, strings.NewReader("12345"), "")
+	assert.ErrorIs(t, err, ErrTooLarge)
+	u, err = store.Append(u.ID, 0, strings.NewReader("12"), "")
+	require.NoError(t, err)
+	_, err = store.Complete(u.ID, sum("12"))
+	assert.ErrorIs(t, err, ErrIncomplete)
+
+	// Without a declared size the store maximum applies
+	u, err = store.Create("undeclared.log", "generic", 0, 0)
+	require.NoError(t, err)
+	_, err = store.Append(u.ID, 0, strings.NewReader("12345678901"), "")
+	assert.ErrorIs(t, err, ErrTooLarge)
+	u, err = store.Get(u.ID)
+	require.NoError(t, err)
+	assert.Zero(t, u.Offset)
+}
+
+func TestUploadIDsAreValidated(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	for _, id := range []string{"", "../etc/passwd", "0123456789abcdef0123456789abcdeg"} {
+		_, err := store.Get(id)
+		assert.ErrorIs(t, err, ErrNotFound, id)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	stale, err := store.Create("stale.log", "generic", 0, 0)
+	require.NoError(t, err)
+	done, err := store.Create("done.log", "generic", 0, 0)
+	require.NoError(t, err)
+	_, err = store.Complete(done.ID, sum(""))
+	require.NoError(t, err)
+
+	removed, err := store.Expire(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	removed, err = store.Expire(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	_, err = store.Get(stale.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get(done.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
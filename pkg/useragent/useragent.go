@@ -0,0 +1,148 @@
+// Package useragent classifies the user agents of requests as browsers,
+// crawlers or scripted clients and names the browser, operating system
+// and kind of device they report, for the user agent breakdowns of
+// reports. Classification reads well-known product tokens, so rare or
+// spoofed user agents may be misclassified.
+package useragent
+
+import (
+	"strings"
+
+	"github.com/ShashankBejjanki1241/Go-Based-Server-Log-Analyzer-Reporting-Platform/pkg/robots"
+)
+
+// Kinds of client
+const (
+	KindBrowser = "browser"
+	// KindBot is a crawler announcing itself, such as Googlebot
+	KindBot = "bot"
+	// KindTool is an HTTP library or command line client, such as curl
+	KindTool    = "tool"
+	KindUnknown = "unknown"
+)
+
+// Kinds of device browsers run on
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+)
+
+// Other names a browser or operating system that is not recognized
+const Other = "Other"
+
+// Agent is what a user agent says about the client
+type Agent struct {
+	Kind string `json:"kind"`
+	// Name is the browser, crawler or tool
+	Name string `json:"name"`
+	// OS and Device are set for browsers
+	OS     string `json:"os,omitempty"`
+	Device string `json:"device,omitempty"`
+}
+
+// tools are the product tokens of HTTP libraries and command line
+// clients, lowercased, with the name reported for each
+var tools = []struct{ token, name string }{
+	{"curl", "curl"},
+	{"wget", "Wget"},
+	{"python-requests", "python-requests"},
+	{"python-urllib", "Python urllib"},
+	{"python-httpx", "HTTPX"},
+	{"aiohttp", "aiohttp"},
+	{"go-http-client", "Go http client"},
+	{"okhttp", "OkHttp"},
+	{"java", "Java"},
+	{"apache-httpclient", "Apache HttpClient"},
+	{"libwww-perl", "libwww-perl"},
+	{"axios", "axios"},
+	{"node-fetch", "node-fetch"},
+	{"undici", "undici"},
+	{"httpie", "HTTPie"},
+	{"postmanruntime", "Postman"},
+	{"insomnia", "Insomnia"},
+	{"guzzlehttp", "Guzzle"},
+	{"ruby", "Ruby"},
+	{"powershell", "PowerShell"},
+}
+
+// browsers are the tokens naming browsers, in the order they are looked
+// for: browsers built on Chrome report Chrome and Safari too, and Chrome
+// reports Safari
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Vivaldi/", "Vivaldi"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Chromium/", "Chrome"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+	{"Safari/", "Safari"},
+}
+
+// systems are the tokens naming operating systems, in the order they are
+// looked for: Android and ChromeOS report Linux, and iOS reports Mac OS X
+var systems = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse classifies a user agent. An empty user agent is of KindUnknown.
+func Parse(userAgent string) Agent {
+	if strings.TrimSpace(userAgent) == "" || userAgent == "-" {
+		return Agent{Kind: KindUnknown}
+	}
+	if bot, ok := robots.DetectBot(userAgent); ok {
+		return Agent{Kind: KindBot, Name: bot}
+	}
+
+	// Scripted clients lead with their product token
+	product, _, _ := strings.Cut(strings.Fields(userAgent)[0], "/")
+	product = strings.ToLower(product)
+	for _, tool := range tools {
+		if product == tool.token || strings.HasPrefix(product, tool.token+"-") {
+			return Agent{Kind: KindTool, Name: tool.name}
+		}
+	}
+
+	if !strings.HasPrefix(userAgent, "Mozilla/") && !strings.HasPrefix(userAgent, "Opera") {
+		return Agent{Kind: KindUnknown, Name: Other}
+	}
+
+	agent := Agent{Kind: KindBrowser, Name: Other, OS: Other, Device: DeviceDesktop}
+	for _, browser := range browsers {
+		if strings.Contains(userAgent, browser.token) {
+			agent.Name = browser.name
+			break
+		}
+	}
+	for _, system := range systems {
+		if strings.Contains(userAgent, system.token) {
+			agent.OS = system.name
+			break
+		}
+	}
+	switch {
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet") ||
+		(agent.OS == "Android" && !strings.Contains(userAgent, "Mobile")):
+		agent.Device = DeviceTablet
+	case strings.Contains(userAgent, "Mobi") || strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPod"):
+		agent.Device = DeviceMobile
+	}
+	return agent
+}
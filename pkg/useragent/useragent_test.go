@@ -0,0 +1,47 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      Agent
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			Agent{Kind: KindBrowser, Name: "Chrome", OS: "Windows", Device: DeviceDesktop}},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			Agent{Kind: KindBrowser, Name: "Edge", OS: "Windows", Device: DeviceDesktop}},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			Agent{Kind: KindBrowser, Name: "Safari", OS: "macOS", Device: DeviceDesktop}},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			Agent{Kind: KindBrowser, Name: "Safari", OS: "iOS", Device: DeviceMobile}},
+		{"Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			Agent{Kind: KindBrowser, Name: "Chrome", OS: "iOS", Device: DeviceTablet}},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			Agent{Kind: KindBrowser, Name: "Chrome", OS: "Android", Device: DeviceMobile}},
+		{"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36",
+			Agent{Kind: KindBrowser, Name: "Samsung Internet", OS: "Android", Device: DeviceTablet}},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			Agent{Kind: KindBrowser, Name: "Firefox", OS: "Linux", Device: DeviceDesktop}},
+		{"Mozilla/5.0 (Windows NT 6.1; Trident/7.0; rv:11.0) like Gecko",
+			Agent{Kind: KindBrowser, Name: "Internet Explorer", OS: "Windows", Device: DeviceDesktop}},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			Agent{Kind: KindBot, Name: "Googlebot"}},
+		{"curl/8.4.0", Agent{Kind: KindTool, Name: "curl"}},
+		{"python-requests/2.31.0", Agent{Kind: KindTool, Name: "python-requests"}},
+		{"Go-http-client/1.1", Agent{Kind: KindTool, Name: "Go http client"}},
+		{"Java/17.0.2", Agent{Kind: KindTool, Name: "Java"}},
+		{"Apache-HttpClient/4.5.14 (Java/17.0.2)", Agent{Kind: KindTool, Name: "Apache HttpClient"}},
+		{"SomeApp/1.0", Agent{Kind: KindUnknown, Name: Other}},
+		{"-", Agent{Kind: KindUnknown}},
+		{"", Agent{Kind: KindUnknown}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Parse(tt.userAgent), tt.userAgent)
+	}
+}
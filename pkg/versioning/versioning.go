@@ -0,0 +1,145 @@
+// Package versioning compares the versions of configuration objects the
+// server keeps, such as alert rules, so a change can be reviewed field by
+// field and undone.
+package versioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Actions a version records
+const (
+	// ActionBaseline records an object as it was before its first
+	// recorded change, so that change can be undone too
+	ActionBaseline   = "baseline"
+	ActionCreated    = "created"
+	ActionUpdated    = "updated"
+	ActionDeleted    = "deleted"
+	ActionRolledBack = "rolled_back"
+)
+
+// Change operations
+const (
+	OpAdded   = "added"
+	OpRemoved = "removed"
+	OpChanged = "changed"
+)
+
+// Change is one difference between two versions of an object. Path names
+// the field with dots between object keys and [n] for list items, and is
+// empty when the object as a whole appeared, disappeared or changed type.
+type Change struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff lists the changes from one JSON document to another, in path
+// order. An empty or null document is an object that does not exist.
+func Diff(before, after json.RawMessage) ([]Change, error) {
+	b, err := decode(before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid earlier version: %w", err)
+	}
+	a, err := decode(after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid later version: %w", err)
+	}
+
+	changes := []Change{}
+	diff(&changes, "", b, a)
+	return changes, nil
+}
+
+// Equal reports whether two JSON documents describe the same value
+func Equal(a, b json.RawMessage) bool {
+	changes, err := Diff(a, b)
+	return err == nil && len(changes) == 0
+}
+
+func decode(document json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(document)) == 0 {
+		return nil, nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diff(changes *[]Change, path string, before, after interface{}) {
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		*changes = append(*changes, Change{Path: path, Op: OpAdded, After: after})
+		return
+	case after == nil:
+		*changes = append(*changes, Change{Path: path, Op: OpRemoved, Before: before})
+		return
+	}
+
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(a))
+		for key := range b {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := b[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			diff(changes, child, b[key], a[key])
+		}
+		return
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(b), len(a)); i++ {
+			var itemBefore, itemAfter interface{}
+			if i < len(b) {
+				itemBefore = b[i]
+			}
+			if i < len(a) {
+				itemAfter = a[i]
+			}
+			diff(changes, path+"["+strconv.Itoa(i)+"]", itemBefore, itemAfter)
+		}
+		return
+	case json.Number:
+		// 5 and 5.0 are the same number
+		if a, ok := after.(json.Number); ok {
+			bf, errB := b.Float64()
+			af, errA := a.Float64()
+			if errB == nil && errA == nil && bf == af {
+				return
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Op: OpChanged, Before: before, After: after})
+	}
+}
@@ -0,0 +1,52 @@
+package versioning
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := json.RawMessage(`{"name":"5xx","threshold_value":10,"is_active":true,
+		"expression":{"operator":"and","conditions":[{"metric":"error_rate","threshold":5}]}}`)
+	after := json.RawMessage(`{"name":"5xx","threshold_value":10.0,"is_active":false,"description":"paged",
+		"expression":{"operator":"and","conditions":[{"metric":"error_rate","threshold":50},{"metric":"request_count"}]}}`)
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "description", Op: OpAdded, After: "paged"},
+		{Path: "expression.conditions[0].threshold", Op: OpChanged, Before: json.Number("5"), After: json.Number("50")},
+		{Path: "expression.conditions[1]", Op: OpAdded, After: map[string]interface{}{"metric": "request_count"}},
+		{Path: "is_active", Op: OpChanged, Before: true, After: false},
+	}, changes, "10 and 10.0 are the same threshold")
+
+	data, err := json.Marshal(changes[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path":"expression.conditions[0].threshold","op":"changed","before":5,"after":50}`, string(data))
+}
+
+func TestDiffWholeObject(t *testing.T) {
+	state := json.RawMessage(`{"flag":"geoip","enabled":true}`)
+
+	changes, err := Diff(nil, state)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpAdded, changes[0].Op)
+	assert.Equal(t, "", changes[0].Path)
+
+	changes, err = Diff(state, json.RawMessage("null"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OpRemoved, changes[0].Op)
+
+	changes, err = Diff(state, json.RawMessage(`{"enabled": true, "flag": "geoip"}`))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.True(t, Equal(state, json.RawMessage(`{"enabled":true,"flag":"geoip"}`)))
+
+	_, err = Diff(state, json.RawMessage(`{`))
+	assert.Error(t, err)
+}